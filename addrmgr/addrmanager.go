@@ -5,11 +5,13 @@
 package addrmgr
 
 import (
+	"bytes"
 	"container/list"
 	crand "crypto/rand" // for seeding
 	"encoding/base32"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand"
@@ -24,6 +26,7 @@ import (
 
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 	"github.com/ltcsuite/ltcd/wire"
+	"golang.org/x/crypto/sha3"
 )
 
 // AddrManager provides a concurrency safe address manager for caching potential
@@ -622,6 +625,34 @@ func (a *AddrManager) NumAddresses() int {
 	return a.numAddresses()
 }
 
+// AddressCounts houses the number of addresses in the new and tried tables
+// of the address manager.
+type AddressCounts struct {
+	New   int
+	Tried int
+}
+
+// NetworkCounts returns the number of addresses in the new and tried tables
+// for each network which has known addresses.  It is safe for concurrent
+// access.
+func (a *AddrManager) NetworkCounts() map[NetworkType]AddressCounts {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	counts := make(map[NetworkType]AddressCounts)
+	for _, ka := range a.addrIndex {
+		netType := NetType(ka.na)
+		c := counts[netType]
+		if ka.tried {
+			c.Tried++
+		} else {
+			c.New++
+		}
+		counts[netType] = c
+	}
+	return counts
+}
+
 // NeedMoreAddresses returns whether or not the address manager needs more
 // addresses.
 func (a *AddrManager) NeedMoreAddresses() bool {
@@ -665,6 +696,49 @@ func (a *AddrManager) AddressCache() []*wire.NetAddress {
 	return allAddr[0:numAddresses]
 }
 
+// GoodAddresses returns up to the requested number of randomly selected
+// addresses from the tried buckets, that is, addresses which have previously
+// been connected to successfully.  Just like the address cache that is shared
+// with peers, no more than getAddrPercent of all known addresses and at most
+// getAddrMax addresses are returned so the full table is never leaked.
+func (a *AddrManager) GoodAddresses(count int) []*wire.NetAddress {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	maxAddresses := len(a.addrIndex) * getAddrPercent / 100
+	if maxAddresses > getAddrMax {
+		maxAddresses = getAddrMax
+	}
+	if count > maxAddresses {
+		count = maxAddresses
+	}
+	if count <= 0 {
+		return nil
+	}
+
+	goodAddr := make([]*wire.NetAddress, 0, a.nTried)
+	for _, bucket := range a.addrTried {
+		for e := bucket.Front(); e != nil; e = e.Next() {
+			ka := e.Value.(*KnownAddress)
+			if ka.isBad() {
+				continue
+			}
+			goodAddr = append(goodAddr, ka.na)
+		}
+	}
+	if count > len(goodAddr) {
+		count = len(goodAddr)
+	}
+
+	// Fisher-Yates shuffle the first count addresses since the rest are
+	// thrown away.
+	for i := 0; i < count; i++ {
+		j := rand.Intn(len(goodAddr)-i) + i
+		goodAddr[i], goodAddr[j] = goodAddr[j], goodAddr[i]
+	}
+	return goodAddr[:count]
+}
+
 // reset resets the address manager by reinitialising the random source
 // and allocating fresh empty bucket storage.
 func (a *AddrManager) reset() {
@@ -681,10 +755,64 @@ func (a *AddrManager) reset() {
 	}
 }
 
+// torV3Version is the version byte encoded in Tor v3 .onion addresses.
+const torV3Version = 0x03
+
+// torV3Checksum returns the checksum encoded in the Tor v3 .onion address of
+// the passed public key.
+func torV3Checksum(pubKey []byte) []byte {
+	h := sha3.New256()
+	h.Write([]byte(".onion checksum"))
+	h.Write(pubKey)
+	h.Write([]byte{torV3Version})
+	return h.Sum(nil)[:2]
+}
+
+// decodeTorV3 returns the public key encoded in the passed Tor v3 .onion
+// address without the .onion suffix, which is the base32 encoding of the
+// public key, its checksum, and the version.
+func decodeTorV3(host string) ([]byte, error) {
+	data, err := base32.StdEncoding.DecodeString(strings.ToUpper(host))
+	if err != nil {
+		return nil, err
+	}
+	pubKey, checksum, version := data[:32], data[32:34], data[34]
+	if version != torV3Version {
+		return nil, fmt.Errorf("unsupported onion address version %d",
+			version)
+	}
+	if !bytes.Equal(checksum, torV3Checksum(pubKey)) {
+		return nil, errors.New("invalid onion address checksum")
+	}
+	return pubKey, nil
+}
+
 // HostToNetAddress returns a netaddress given a host address.  If the address
-// is a Tor .onion address this will be taken care of.  Else if the host is
-// not an IP address it will be resolved (via Tor if required).
+// is a Tor .onion address or an I2P .b32.i2p address this will be taken care
+// of.  Else if the host is not an IP address it will be resolved (via Tor if
+// required).
 func (a *AddrManager) HostToNetAddress(host string, port uint16, services wire.ServiceFlag) (*wire.NetAddress, error) {
+	// Tor v3 address is 56 char base32 + ".onion"
+	if len(host) == 62 && host[56:] == ".onion" {
+		pubKey, err := decodeTorV3(host[:56])
+		if err != nil {
+			return nil, err
+		}
+		return wire.NewNetAddressNetID(wire.NetIDTorV3, pubKey, port,
+			services), nil
+	}
+
+	// I2P address is 52 char unpadded base32 + ".b32.i2p"
+	if len(host) == 60 && host[52:] == ".b32.i2p" {
+		data, err := base32.StdEncoding.DecodeString(
+			strings.ToUpper(host[:52]) + "====")
+		if err != nil {
+			return nil, err
+		}
+		return wire.NewNetAddressNetID(wire.NetIDI2P, data, port,
+			services), nil
+	}
+
 	// Tor address is 16 char base32 + ".onion"
 	var ip net.IP
 	if len(host) == 22 && host[16:] == ".onion" {
@@ -714,8 +842,21 @@ func (a *AddrManager) HostToNetAddress(host string, port uint16, services wire.S
 
 // ipString returns a string for the ip from the provided NetAddress. If the
 // ip is in the range used for Tor addresses then it will be transformed into
-// the relevant .onion address.
+// the relevant .onion address.  Tor v3 and I2P addresses are likewise
+// returned as their .onion and .b32.i2p addresses.
 func ipString(na *wire.NetAddress) string {
+	if IsTorV3(na) && len(na.Addr) == 32 {
+		data := make([]byte, 0, 35)
+		data = append(data, na.Addr...)
+		data = append(data, torV3Checksum(na.Addr)...)
+		data = append(data, torV3Version)
+		base32 := base32.StdEncoding.EncodeToString(data)
+		return strings.ToLower(base32) + ".onion"
+	}
+	if IsI2P(na) && len(na.Addr) == 32 {
+		base32 := base32.StdEncoding.EncodeToString(na.Addr)
+		return strings.ToLower(strings.TrimRight(base32, "=")) + ".b32.i2p"
+	}
 	if IsOnionCatTor(na) {
 		// We know now that na.IP is long enough.
 		base32 := base32.StdEncoding.EncodeToString(na.IP[6:])
@@ -808,6 +949,15 @@ func (a *AddrManager) find(addr *wire.NetAddress) *KnownAddress {
 	return a.addrIndex[NetAddressKey(addr)]
 }
 
+// HaveAddress returns whether the given address is known to the address
+// manager.
+func (a *AddrManager) HaveAddress(addr *wire.NetAddress) bool {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	return a.find(addr) != nil
+}
+
 // Attempt increases the given address' attempt counter and updates
 // the last attempt time.
 func (a *AddrManager) Attempt(addr *wire.NetAddress) {
@@ -979,8 +1129,8 @@ func getReachabilityFrom(localAddr, remoteAddr *wire.NetAddress) int {
 		return Unreachable
 	}
 
-	if IsOnionCatTor(remoteAddr) {
-		if IsOnionCatTor(localAddr) {
+	if IsTor(remoteAddr) {
+		if IsTor(localAddr) {
 			return Private
 		}
 
@@ -991,6 +1141,14 @@ func getReachabilityFrom(localAddr, remoteAddr *wire.NetAddress) int {
 		return Default
 	}
 
+	if IsI2P(remoteAddr) {
+		if IsI2P(localAddr) {
+			return Private
+		}
+
+		return Default
+	}
+
 	if IsRFC4380(remoteAddr) {
 		if !IsRoutable(localAddr) {
 			return Default
@@ -1060,15 +1218,14 @@ func (a *AddrManager) GetBestLocalAddress(remoteAddr *wire.NetAddress) *wire.Net
 		}
 	}
 	if bestAddress != nil {
-		log.Debugf("Suggesting address %s:%d for %s:%d", bestAddress.IP,
-			bestAddress.Port, remoteAddr.IP, remoteAddr.Port)
+		log.Debugf("Suggesting address %s for %s",
+			NetAddressKey(bestAddress), NetAddressKey(remoteAddr))
 	} else {
-		log.Debugf("No worthy address for %s:%d", remoteAddr.IP,
-			remoteAddr.Port)
+		log.Debugf("No worthy address for %s", NetAddressKey(remoteAddr))
 
 		// Send something unroutable if nothing suitable.
 		var ip net.IP
-		if !IsIPv4(remoteAddr) && !IsOnionCatTor(remoteAddr) {
+		if !IsIPv4(remoteAddr) && !IsTor(remoteAddr) {
 			ip = net.IPv6zero
 		} else {
 			ip = net.IPv4zero
@@ -5,9 +5,12 @@
 package addrmgr_test
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"os"
 	"reflect"
 	"testing"
 	"time"
@@ -314,6 +317,291 @@ func TestGood(t *testing.T) {
 	}
 }
 
+func TestGoodAddresses(t *testing.T) {
+	n := addrmgr.New("testgoodaddresses", lookupFunc)
+	if addrs := n.GoodAddresses(10); len(addrs) != 0 {
+		t.Fatalf("GoodAddresses: got %d addresses from an empty "+
+			"manager, want 0", len(addrs))
+	}
+
+	// Add addresses and mark half of them as good.
+	addrsToAdd := 400
+	good := make(map[string]*wire.NetAddress)
+	for i := 0; i < addrsToAdd; i++ {
+		s := fmt.Sprintf("%d.173.147.%d:9333", i/64+60, i%64+60)
+		addr, err := n.DeserializeNetAddress(s)
+		if err != nil {
+			t.Fatalf("Failed to turn %s into an address: %v", s, err)
+		}
+		addr.Services = wire.SFNodeNetwork
+		srcAddr := wire.NewNetAddressIPPort(net.IPv4(173, 144, 173, 111), 9333, 0)
+		n.AddAddress(addr, srcAddr)
+		if i%2 == 0 {
+			n.Good(addr)
+			good[addrmgr.NetAddressKey(addr)] = addr
+		}
+	}
+
+	// Ensure only good addresses are returned without duplicates and that
+	// no more than the fraction of all known addresses shared with peers
+	// are returned.
+	maxAddrs := len(n.AddressCache())
+	for _, count := range []int{0, 1, 10, maxAddrs, addrsToAdd} {
+		addrs := n.GoodAddresses(count)
+		wantLen := count
+		if wantLen > maxAddrs {
+			wantLen = maxAddrs
+		}
+		if len(addrs) != wantLen {
+			t.Fatalf("GoodAddresses(%d): got %d addresses, want %d",
+				count, len(addrs), wantLen)
+		}
+		seen := make(map[string]struct{})
+		for _, addr := range addrs {
+			key := addrmgr.NetAddressKey(addr)
+			want, ok := good[key]
+			if !ok {
+				t.Fatalf("GoodAddresses(%d): address %s was not "+
+					"marked good", count, key)
+			}
+			if addr.Services != want.Services ||
+				!addr.Timestamp.Equal(want.Timestamp) {
+
+				t.Fatalf("GoodAddresses(%d): got address %v, want "+
+					"%v", count, addr, want)
+			}
+			if _, ok := seen[key]; ok {
+				t.Fatalf("GoodAddresses(%d): duplicate address %s",
+					count, key)
+			}
+			seen[key] = struct{}{}
+		}
+	}
+}
+
+// TestNetworkCounts ensures the addresses in the new and tried tables are
+// counted for each network.
+func TestNetworkCounts(t *testing.T) {
+	n := addrmgr.New("testnetworkcounts", lookupFunc)
+	if counts := n.NetworkCounts(); len(counts) != 0 {
+		t.Fatalf("NetworkCounts: got %v from an empty manager", counts)
+	}
+
+	tests := []struct {
+		addr  string
+		tried bool
+	}{
+		{addr: "173.194.115.66:9333", tried: true},
+		{addr: "173.194.115.67:9333"},
+		{addr: "173.194.115.68:9333"},
+		{addr: "[2001:470::1]:9333", tried: true},
+		{addr: "[2001:470::2]:9333", tried: true},
+		{addr: "aaaaaaaaaaaaaaaa.onion:9333"},
+		{addr: "[fc32:17ea:e415:c3bf::1]:9333", tried: true},
+	}
+	srcAddr := wire.NewNetAddressIPPort(net.IPv4(173, 144, 173, 111), 9333, 0)
+	for _, test := range tests {
+		addr, err := n.DeserializeNetAddress(test.addr)
+		if err != nil {
+			t.Fatalf("Failed to turn %s into an address: %v",
+				test.addr, err)
+		}
+		n.AddAddress(addr, srcAddr)
+		if test.tried {
+			n.Good(addr)
+		}
+	}
+
+	want := map[addrmgr.NetworkType]addrmgr.AddressCounts{
+		addrmgr.NetworkIPv4:  {New: 2, Tried: 1},
+		addrmgr.NetworkIPv6:  {New: 0, Tried: 2},
+		addrmgr.NetworkOnion: {New: 1, Tried: 0},
+		addrmgr.NetworkCJDNS: {New: 0, Tried: 1},
+	}
+	if counts := n.NetworkCounts(); !reflect.DeepEqual(counts, want) {
+		t.Fatalf("NetworkCounts: got %v, want %v", counts, want)
+	}
+}
+
+// TestCJDNSAddress ensures CJDNS addresses are routable, are stored by the
+// address manager and survive being relayed on the wire as well as being
+// saved to and loaded from the peers file.
+func TestCJDNSAddress(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "testcjdnsaddress")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	na := wire.NewNetAddressIPPort(net.ParseIP("fc32:17ea:e415:c3bf::1"),
+		9333, wire.SFNodeNetwork)
+	if !addrmgr.IsRoutable(na) {
+		t.Fatalf("IsRoutable: %s is not routable", na.IP)
+	}
+	if netType := addrmgr.NetType(na); netType != addrmgr.NetworkCJDNS {
+		t.Fatalf("NetType: got %v, want %v", netType,
+			addrmgr.NetworkCJDNS)
+	}
+
+	// Relay the address in an addr message.
+	msg := wire.NewMsgAddr()
+	if err := msg.AddAddress(na); err != nil {
+		t.Fatalf("AddAddress: unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	pver := wire.ProtocolVersion
+	if err := msg.BtcEncode(&buf, pver, wire.LatestEncoding); err != nil {
+		t.Fatalf("BtcEncode: unexpected error: %v", err)
+	}
+	var relayed wire.MsgAddr
+	if err := relayed.BtcDecode(&buf, pver, wire.LatestEncoding); err != nil {
+		t.Fatalf("BtcDecode: unexpected error: %v", err)
+	}
+	if len(relayed.AddrList) != 1 || !relayed.AddrList[0].IP.Equal(na.IP) ||
+		relayed.AddrList[0].Port != na.Port {
+
+		t.Fatalf("relayed addresses %v, want %v", relayed.AddrList, na)
+	}
+
+	// Add the relayed address and ensure it is loaded from the peers file
+	// after a restart.
+	n := addrmgr.New(dataDir, lookupFunc)
+	n.Start()
+	srcAddr := wire.NewNetAddressIPPort(net.IPv4(173, 144, 173, 111), 9333, 0)
+	n.AddAddress(relayed.AddrList[0], srcAddr)
+	if !n.HaveAddress(na) {
+		t.Fatalf("HaveAddress: %s was not added", na.IP)
+	}
+	if err := n.Stop(); err != nil {
+		t.Fatalf("Address Manager failed to stop: %v", err)
+	}
+
+	n = addrmgr.New(dataDir, lookupFunc)
+	n.Start()
+	defer n.Stop()
+	if !n.HaveAddress(na) {
+		t.Fatalf("HaveAddress: %s was not loaded from the peers file",
+			na.IP)
+	}
+	if key := addrmgr.NetAddressKey(na); key != "[fc32:17ea:e415:c3bf::1]:9333" {
+		t.Fatalf("NetAddressKey: got %s", key)
+	}
+}
+
+// TestTorV3Address ensures Tor v3 addresses, which are not IP addresses, are
+// relayed in addrv2 messages, grouped and reported as onion addresses, and
+// saved to and loaded from the peers file without truncation.
+func TestTorV3Address(t *testing.T) {
+	dataDir, err := ioutil.TempDir("", "testtorv3address")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	const onion = "pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion"
+	n := addrmgr.New(dataDir, lookupFunc)
+	na, err := n.HostToNetAddress(onion, 9333, wire.SFNodeNetwork)
+	if err != nil {
+		t.Fatalf("HostToNetAddress: unexpected error: %v", err)
+	}
+	if !na.NeedsAddrV2() || len(na.Addr) != 32 {
+		t.Fatalf("HostToNetAddress: %v is not a Tor v3 address", na)
+	}
+	if !addrmgr.IsRoutable(na) {
+		t.Fatalf("IsRoutable: %s is not routable", onion)
+	}
+	if netType := addrmgr.NetType(na); netType != addrmgr.NetworkOnion {
+		t.Fatalf("NetType: got %v, want %v", netType,
+			addrmgr.NetworkOnion)
+	}
+	if key := addrmgr.GroupKey(na); key != "tor:9" {
+		t.Fatalf("GroupKey: got %s, want tor:9", key)
+	}
+	if key := addrmgr.NetAddressKey(na); key != onion+":9333" {
+		t.Fatalf("NetAddressKey: got %s, want %s:9333", key, onion)
+	}
+
+	// Addresses with a corrupted checksum or a short public key are
+	// rejected.
+	corrupted := "ag6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion"
+	if _, err := n.HostToNetAddress(corrupted, 9333, 0); err == nil {
+		t.Fatalf("HostToNetAddress: accepted bad checksum %s", corrupted)
+	}
+	if addrmgr.IsValid(&wire.NetAddress{NetID: wire.NetIDTorV3,
+		Addr: na.Addr[:31]}) {
+
+		t.Fatal("IsValid: short Tor v3 address is valid")
+	}
+
+	// Relay the address in an addrv2 message.
+	msg := wire.NewMsgAddrV2()
+	if err := msg.AddAddress(na); err != nil {
+		t.Fatalf("AddAddress: unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	pver := wire.ProtocolVersion
+	if err := msg.BtcEncode(&buf, pver, wire.LatestEncoding); err != nil {
+		t.Fatalf("BtcEncode: unexpected error: %v", err)
+	}
+	var relayed wire.MsgAddrV2
+	if err := relayed.BtcDecode(&buf, pver, wire.LatestEncoding); err != nil {
+		t.Fatalf("BtcDecode: unexpected error: %v", err)
+	}
+	if len(relayed.AddrList) != 1 ||
+		!bytes.Equal(relayed.AddrList[0].Addr, na.Addr) ||
+		relayed.AddrList[0].NetID != wire.NetIDTorV3 ||
+		relayed.AddrList[0].Port != na.Port {
+
+		t.Fatalf("relayed addresses %v, want %v", relayed.AddrList, na)
+	}
+
+	// Add the relayed address and ensure it is loaded from the peers file
+	// after a restart.
+	n.Start()
+	srcAddr := wire.NewNetAddressIPPort(net.IPv4(173, 144, 173, 111), 9333, 0)
+	n.AddAddress(relayed.AddrList[0], srcAddr)
+	if !n.HaveAddress(na) {
+		t.Fatalf("HaveAddress: %s was not added", onion)
+	}
+	if err := n.Stop(); err != nil {
+		t.Fatalf("Address Manager failed to stop: %v", err)
+	}
+
+	n = addrmgr.New(dataDir, lookupFunc)
+	n.Start()
+	defer n.Stop()
+	if !n.HaveAddress(na) {
+		t.Fatalf("HaveAddress: %s was not loaded from the peers file",
+			onion)
+	}
+	counts := n.NetworkCounts()
+	if c := counts[addrmgr.NetworkOnion]; c.New != 1 {
+		t.Fatalf("NetworkCounts: got %d new onion addresses, want 1",
+			c.New)
+	}
+}
+
+// TestI2PAddress ensures I2P addresses are converted to and from their
+// .b32.i2p form.
+func TestI2PAddress(t *testing.T) {
+	const host = "ukeu3k5oycgaauneqgtnvselmt4yemvoilkln7jpvamvfx7dnkdq.b32.i2p"
+	n := addrmgr.New("testi2paddress", lookupFunc)
+	na, err := n.HostToNetAddress(host, 0, wire.SFNodeNetwork)
+	if err != nil {
+		t.Fatalf("HostToNetAddress: unexpected error: %v", err)
+	}
+	if na.NetID != wire.NetIDI2P || !addrmgr.IsRoutable(na) {
+		t.Fatalf("HostToNetAddress: %v is not a routable I2P address", na)
+	}
+	if netType := addrmgr.NetType(na); netType != addrmgr.NetworkI2P {
+		t.Fatalf("NetType: got %v, want %v", netType,
+			addrmgr.NetworkI2P)
+	}
+	if key := addrmgr.NetAddressKey(na); key != host+":0" {
+		t.Fatalf("NetAddressKey: got %s, want %s:0", key, host)
+	}
+}
+
 func TestGetAddress(t *testing.T) {
 	n := addrmgr.New("testgetaddress", lookupFunc)
 
@@ -84,6 +84,13 @@ var (
 	// { magic 6 bytes, 10 bytes base32 decode of key hash }
 	onionCatNet = ipNet("fd87:d87e:eb43::", 48, 128)
 
+	// cjdnsNet defines the IPv6 address block used by the CJDNS and
+	// Yggdrasil overlay networks (FC00::/8).  The addresses are derived
+	// from the public keys of the nodes, so unlike the rest of the RFC4193
+	// unique local range they are globally unique and reachable from any
+	// node joined to the overlay network.
+	cjdnsNet = ipNet("FC00::", 8, 128)
+
 	// zero4Net defines the IPv4 address block for address staring with 0
 	// (0.0.0.0/8).
 	zero4Net = ipNet("0.0.0.0", 8, 32)
@@ -117,6 +124,31 @@ func IsOnionCatTor(na *wire.NetAddress) bool {
 	return onionCatNet.Contains(na.IP)
 }
 
+// IsCJDNS returns whether or not the passed address is in the IPv6 range used
+// by the CJDNS and Yggdrasil overlay networks (fc00::/8).  Note that this range
+// is part of the RFC4193 unique local IPv6 range.
+func IsCJDNS(na *wire.NetAddress) bool {
+	return cjdnsNet.Contains(na.IP)
+}
+
+// IsTorV3 returns whether or not the passed address is a Tor v3 address, which
+// is not an IP address and can only be relayed in addrv2 messages.
+func IsTorV3(na *wire.NetAddress) bool {
+	return na.NetID == wire.NetIDTorV3
+}
+
+// IsTor returns whether or not the passed address is a Tor address, either a
+// Tor v2 address in the OnionCat range or a Tor v3 address.
+func IsTor(na *wire.NetAddress) bool {
+	return IsOnionCatTor(na) || IsTorV3(na)
+}
+
+// IsI2P returns whether or not the passed address is an I2P address, which is
+// not an IP address and can only be relayed in addrv2 messages.
+func IsI2P(na *wire.NetAddress) bool {
+	return na.NetID == wire.NetIDI2P
+}
+
 // IsRFC1918 returns whether or not the passed address is part of the IPv4
 // private network address space as defined by RFC1918 (10.0.0.0/8,
 // 172.16.0.0/12, or 192.168.0.0/16).
@@ -212,7 +244,13 @@ func IsRFC6598(na *wire.NetAddress) bool {
 // considered invalid under the following circumstances:
 // IPv4: It is either a zero or all bits set address.
 // IPv6: It is either a zero or RFC3849 documentation address.
+// Tor v3 and I2P: The address is not 32 bytes.
+// Addresses of any other network which is not an IP network are invalid.
 func IsValid(na *wire.NetAddress) bool {
+	if na.NeedsAddrV2() {
+		return (IsTorV3(na) || IsI2P(na)) && len(na.Addr) == 32
+	}
+
 	// IsUnspecified returns if address is 0, so only all bits set, and
 	// RFC3849 need to be explicitly checked.
 	return na.IP != nil && !(na.IP.IsUnspecified() ||
@@ -220,20 +258,26 @@ func IsValid(na *wire.NetAddress) bool {
 }
 
 // IsRoutable returns whether or not the passed address is routable over
-// the public internet.  This is true as long as the address is valid and is not
-// in any reserved ranges.
+// the public internet or one of the supported overlay networks.  This is true
+// as long as the address is valid and is not in any reserved ranges.
 func IsRoutable(na *wire.NetAddress) bool {
+	if na.NeedsAddrV2() {
+		return IsValid(na)
+	}
 	return IsValid(na) && !(IsRFC1918(na) || IsRFC2544(na) ||
 		IsRFC3927(na) || IsRFC4862(na) || IsRFC3849(na) ||
 		IsRFC4843(na) || IsRFC5737(na) || IsRFC6598(na) ||
-		IsLocal(na) || (IsRFC4193(na) && !IsOnionCatTor(na)))
+		IsLocal(na) || (IsRFC4193(na) && !IsOnionCatTor(na) &&
+		!IsCJDNS(na)))
 }
 
 // GroupKey returns a string representing the network group an address is part
 // of.  This is the /16 for IPv4, the /32 (/36 for he.net) for IPv6, the string
 // "local" for a local address, the string "tor:key" where key is the /4 of the
-// onion address for Tor address, and the string "unroutable" for an unroutable
-// address.
+// onion address for Tor address, the string "i2p:key" where key is the /4 of the
+// destination hash for an I2P address, the string "cjdns:key" where key is the
+// /4 of the address following the fc prefix for a CJDNS address, and the string
+// "unroutable" for an unroutable address.
 func GroupKey(na *wire.NetAddress) string {
 	if IsLocal(na) {
 		return "local"
@@ -241,6 +285,14 @@ func GroupKey(na *wire.NetAddress) string {
 	if !IsRoutable(na) {
 		return "unroutable"
 	}
+	if IsTorV3(na) {
+		// group is keyed off the first 4 bits of the public key just
+		// like Tor v2 addresses.
+		return fmt.Sprintf("tor:%d", na.Addr[0]&((1<<4)-1))
+	}
+	if IsI2P(na) {
+		return fmt.Sprintf("i2p:%d", na.Addr[0]&((1<<4)-1))
+	}
 	if IsIPv4(na) {
 		return na.IP.Mask(net.CIDRMask(16, 32)).String()
 	}
@@ -268,6 +320,12 @@ func GroupKey(na *wire.NetAddress) string {
 		// group is keyed off the first 4 bits of the actual onion key.
 		return fmt.Sprintf("tor:%d", na.IP[6]&((1<<4)-1))
 	}
+	if IsCJDNS(na) {
+		// The address following the fc prefix is derived from the
+		// public key of the node, so group by its first 4 bits just
+		// like Tor addresses.
+		return fmt.Sprintf("cjdns:%d", na.IP[1]>>4)
+	}
 
 	// OK, so now we know ourselves to be a IPv6 address.
 	// bitcoind uses /32 for everything, except for Hurricane Electric's
@@ -279,3 +337,59 @@ func GroupKey(na *wire.NetAddress) string {
 
 	return na.IP.Mask(net.CIDRMask(bits, 128)).String()
 }
+
+// NetworkType identifies the network an address belongs to.
+type NetworkType int
+
+const (
+	// NetworkIPv4 identifies IPv4 addresses.
+	NetworkIPv4 NetworkType = iota
+
+	// NetworkIPv6 identifies IPv6 addresses other than Tor addresses.
+	NetworkIPv6
+
+	// NetworkOnion identifies Tor addresses, which are either Tor v2
+	// addresses encoded in the OnionCat IPv6 range or Tor v3 addresses.
+	NetworkOnion
+
+	// NetworkCJDNS identifies addresses of the CJDNS and Yggdrasil overlay
+	// networks.
+	NetworkCJDNS
+
+	// NetworkI2P identifies I2P addresses.
+	NetworkI2P
+)
+
+// networkTypeStrings is a map of network types back to their names for pretty
+// printing.
+var networkTypeStrings = map[NetworkType]string{
+	NetworkIPv4:  "ipv4",
+	NetworkIPv6:  "ipv6",
+	NetworkOnion: "onion",
+	NetworkCJDNS: "cjdns",
+	NetworkI2P:   "i2p",
+}
+
+// String returns the NetworkType in human-readable form.
+func (t NetworkType) String() string {
+	if s, ok := networkTypeStrings[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown NetworkType (%d)", int(t))
+}
+
+// NetType returns the network the given address belongs to.
+func NetType(na *wire.NetAddress) NetworkType {
+	switch {
+	case IsIPv4(na):
+		return NetworkIPv4
+	case IsTor(na):
+		return NetworkOnion
+	case IsI2P(na):
+		return NetworkI2P
+	case IsCJDNS(na):
+		return NetworkCJDNS
+	default:
+		return NetworkIPv6
+	}
+}
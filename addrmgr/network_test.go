@@ -64,6 +64,8 @@ func TestIPTypes(t *testing.T) {
 			false, false, false, false, false, false, false, true, true, false),
 		newIPTest("fd00:dead::1", false, false, false, false, false, true,
 			false, false, false, false, false, false, false, false, true, false),
+		newIPTest("fc00:dead::1", false, false, false, false, false, true,
+			false, false, false, false, false, false, false, false, true, true),
 		newIPTest("2001::1", false, false, false, false, false, false,
 			true, false, false, false, false, false, false, false, true, true),
 		newIPTest("2001:10:abcd::1:1", false, false, false, false, false, false,
@@ -163,7 +165,7 @@ func TestGroupKey(t *testing.T) {
 		{name: "ipv4 rfc1918 192.168/16", ip: "192.168.1.2", expected: "unroutable"},
 		{name: "ipv6 rfc3849 2001:db8::/32", ip: "2001:db8::1234", expected: "unroutable"},
 		{name: "ipv4 rfc3927 169.254/16", ip: "169.254.1.2", expected: "unroutable"},
-		{name: "ipv6 rfc4193 fc00::/7", ip: "fc00::1234", expected: "unroutable"},
+		{name: "ipv6 rfc4193 fc00::/7", ip: "fd00::1234", expected: "unroutable"},
 		{name: "ipv6 rfc4843 2001:10::/28", ip: "2001:10::1234", expected: "unroutable"},
 		{name: "ipv6 rfc4862 fe80::/64", ip: "fe80::1234", expected: "unroutable"},
 
@@ -183,6 +185,11 @@ func TestGroupKey(t *testing.T) {
 		{name: "ipv6 tor onioncat 2", ip: "fd87:d87e:eb43:1245::6789", expected: "tor:2"},
 		{name: "ipv6 tor onioncat 3", ip: "fd87:d87e:eb43:1345::6789", expected: "tor:3"},
 
+		// CJDNS.
+		{name: "ipv6 cjdns", ip: "fc32:17ea:e415:c3bf::1", expected: "cjdns:3"},
+		{name: "ipv6 cjdns 2", ip: "fc3a:17ea:e415:c3bf::2", expected: "cjdns:3"},
+		{name: "ipv6 cjdns 3", ip: "fcf2:17ea:e415:c3bf::1", expected: "cjdns:15"},
+
 		// IPv6 normal.
 		{name: "ipv6 normal", ip: "2602:100::1", expected: "2602:100::"},
 		{name: "ipv6 normal 2", ip: "2602:0100::1234", expected: "2602:100::"},
@@ -0,0 +1,270 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// banListFilename is the name of the file in the data directory the ban list
+// is persisted to.
+const banListFilename = "banlist.json"
+
+var (
+	// errAlreadyBanned indicates an attempt to ban an IP address or subnet
+	// which is already banned.
+	errAlreadyBanned = errors.New("IP/Subnet already banned")
+
+	// errNotBanned indicates an attempt to unban an IP address or subnet
+	// which is not banned.
+	errNotBanned = errors.New("IP/Subnet was not previously banned")
+)
+
+// bannedSubnet describes a banned IP address or subnet along with when the ban
+// was created and when it expires.
+type bannedSubnet struct {
+	Subnet  *net.IPNet
+	Created time.Time
+	Until   time.Time
+}
+
+// serializedBan is the form in which a banned subnet is persisted.
+type serializedBan struct {
+	Subnet  string `json:"subnet"`
+	Created int64  `json:"created"`
+	Until   int64  `json:"until"`
+}
+
+// bannedSubnets implements sort.Interface to allow a slice of banned subnets
+// to be sorted by their subnets.
+type bannedSubnets []bannedSubnet
+
+// Len returns the number of banned subnets in the slice.  It is part of the
+// sort.Interface implementation.
+func (s bannedSubnets) Len() int { return len(s) }
+
+// Swap swaps the banned subnets at the passed indices.  It is part of the
+// sort.Interface implementation.
+func (s bannedSubnets) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+// Less returns whether the subnet with index i should sort before the subnet
+// with index j.  It is part of the sort.Interface implementation.
+func (s bannedSubnets) Less(i, j int) bool {
+	return s[i].Subnet.String() < s[j].Subnet.String()
+}
+
+// banList houses the IP addresses and subnets which are banned from connecting
+// to the server.  Changes to the list are persisted to a file so bans survive
+// restarts.
+//
+// The ban list is safe for concurrent access.
+type banList struct {
+	mtx      sync.Mutex
+	filePath string
+	bans     map[string]bannedSubnet
+}
+
+// parseSubnet parses the passed string as either an IP address, in which case
+// the returned subnet only contains that address, or a subnet in CIDR
+// notation.
+func parseSubnet(s string) (*net.IPNet, error) {
+	if strings.Contains(s, "/") {
+		_, subnet, err := net.ParseCIDR(s)
+		if err != nil {
+			return nil, err
+		}
+		return subnet, nil
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", s)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// Ban bans the passed subnet until the provided time.
+func (b *banList) Ban(subnet *net.IPNet, until time.Time) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	now := time.Now()
+	key := subnet.String()
+	if ban, ok := b.bans[key]; ok && now.Before(ban.Until) {
+		return errAlreadyBanned
+	}
+	b.bans[key] = bannedSubnet{Subnet: subnet, Created: now, Until: until}
+	return b.save()
+}
+
+// Unban removes the ban of the passed subnet.
+func (b *banList) Unban(subnet *net.IPNet) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	key := subnet.String()
+	if _, ok := b.bans[key]; !ok {
+		return errNotBanned
+	}
+	delete(b.bans, key)
+	return b.save()
+}
+
+// Clear removes all bans.
+func (b *banList) Clear() error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.bans = make(map[string]bannedSubnet)
+	return b.save()
+}
+
+// BannedUntil returns the time until which the passed IP address is banned
+// along with whether or not it is banned.  When the address is contained in
+// several banned subnets, the latest expiration is returned.
+func (b *banList) BannedUntil(ip net.IP) (time.Time, bool) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.removeExpired()
+
+	var until time.Time
+	var banned bool
+	for _, ban := range b.bans {
+		if ban.Subnet.Contains(ip) && ban.Until.After(until) {
+			until = ban.Until
+			banned = true
+		}
+	}
+	return until, banned
+}
+
+// IsBanned returns whether or not the passed IP address is banned.
+func (b *banList) IsBanned(ip net.IP) bool {
+	_, banned := b.BannedUntil(ip)
+	return banned
+}
+
+// Bans returns all of the subnets which are currently banned sorted by subnet.
+func (b *banList) Bans() []bannedSubnet {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	b.removeExpired()
+
+	bans := make([]bannedSubnet, 0, len(b.bans))
+	for _, ban := range b.bans {
+		bans = append(bans, ban)
+	}
+	sort.Sort(bannedSubnets(bans))
+	return bans
+}
+
+// removeExpired removes all bans which have expired.  The change is persisted
+// along with the next change to the list.
+//
+// This function MUST be called with the ban list lock held.
+func (b *banList) removeExpired() {
+	now := time.Now()
+	for key, ban := range b.bans {
+		if !now.Before(ban.Until) {
+			srvrLog.Debugf("Ban of %s expired", key)
+			delete(b.bans, key)
+		}
+	}
+}
+
+// save writes the bans which have not expired to the ban list file.  The file
+// is replaced atomically so a failure can not corrupt the existing list.
+//
+// This function MUST be called with the ban list lock held.
+func (b *banList) save() error {
+	b.removeExpired()
+
+	bans := make([]serializedBan, 0, len(b.bans))
+	for key, ban := range b.bans {
+		bans = append(bans, serializedBan{
+			Subnet:  key,
+			Created: ban.Created.Unix(),
+			Until:   ban.Until.Unix(),
+		})
+	}
+
+	tmpPath := b.filePath + ".tmp"
+	w, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(w).Encode(bans); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, b.filePath)
+}
+
+// load reads the bans from the ban list file.  A missing file results in an
+// empty ban list.
+func (b *banList) load() error {
+	r, err := os.Open(b.filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var bans []serializedBan
+	if err := json.NewDecoder(r).Decode(&bans); err != nil {
+		return err
+	}
+	for _, ban := range bans {
+		subnet, err := parseSubnet(ban.Subnet)
+		if err != nil {
+			return err
+		}
+		b.bans[subnet.String()] = bannedSubnet{
+			Subnet:  subnet,
+			Created: time.Unix(ban.Created, 0),
+			Until:   time.Unix(ban.Until, 0),
+		}
+	}
+	b.removeExpired()
+	return nil
+}
+
+// newBanList returns a ban list which is persisted to the passed file.  The
+// bans previously persisted to the file are loaded.  When the file is
+// malformed, it is ignored and replaced once the list changes.
+func newBanList(filePath string) *banList {
+	b := &banList{
+		filePath: filePath,
+		bans:     make(map[string]bannedSubnet),
+	}
+	if err := b.load(); err != nil {
+		srvrLog.Errorf("Failed to load ban list %s: %v", filePath, err)
+		b.bans = make(map[string]bannedSubnet)
+		return b
+	}
+	if len(b.bans) > 0 {
+		srvrLog.Debugf("Loaded %d %s from file '%s'", len(b.bans),
+			pickNoun(uint64(len(b.bans)), "ban", "bans"), filePath)
+	}
+	return b
+}
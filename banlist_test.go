@@ -0,0 +1,101 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestBanList ensures banned IP addresses and subnets are reported as banned
+// until they expire or are removed and that the bans are persisted.
+func TestBanList(t *testing.T) {
+	dir, err := ioutil.TempDir("", "banlist")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, banListFilename)
+
+	mustParseSubnet := func(s string) *net.IPNet {
+		subnet, err := parseSubnet(s)
+		if err != nil {
+			t.Fatalf("parseSubnet(%q): unexpected error: %v", s, err)
+		}
+		return subnet
+	}
+
+	// Ban a subnet and a single address and ensure only the addresses
+	// within them are banned.
+	b := newBanList(filePath)
+	until := time.Now().Add(time.Hour)
+	if err := b.Ban(mustParseSubnet("10.0.0.0/8"), until); err != nil {
+		t.Fatalf("Ban: unexpected error: %v", err)
+	}
+	if err := b.Ban(mustParseSubnet("2001:db8::1"), until); err != nil {
+		t.Fatalf("Ban: unexpected error: %v", err)
+	}
+	err = b.Ban(mustParseSubnet("10.1.2.3/8"), until)
+	if err != errAlreadyBanned {
+		t.Fatalf("duplicate Ban: got error %v, want %v", err,
+			errAlreadyBanned)
+	}
+	tests := []struct {
+		ip     string
+		banned bool
+	}{
+		{"10.1.2.3", true},
+		{"11.1.2.3", false},
+		{"2001:db8::1", true},
+		{"2001:db8::2", false},
+	}
+	for _, test := range tests {
+		if b.IsBanned(net.ParseIP(test.ip)) != test.banned {
+			t.Fatalf("IsBanned(%s): want %v", test.ip, test.banned)
+		}
+	}
+
+	// The bans must survive reloading the ban list.
+	b = newBanList(filePath)
+	bans := b.Bans()
+	if len(bans) != 2 || bans[0].Subnet.String() != "10.0.0.0/8" ||
+		bans[1].Subnet.String() != "2001:db8::1/128" ||
+		bans[0].Until.Unix() != until.Unix() {
+
+		t.Fatalf("reloaded bans: got %v", bans)
+	}
+
+	// Removed and expired bans must no longer apply.
+	if err := b.Unban(mustParseSubnet("10.0.0.0/8")); err != nil {
+		t.Fatalf("Unban: unexpected error: %v", err)
+	}
+	if b.IsBanned(net.ParseIP("10.1.2.3")) {
+		t.Fatal("IsBanned: unbanned address is banned")
+	}
+	err = b.Unban(mustParseSubnet("10.0.0.0/8"))
+	if err != errNotBanned {
+		t.Fatalf("duplicate Unban: got error %v, want %v", err,
+			errNotBanned)
+	}
+	err = b.Ban(mustParseSubnet("192.168.0.0/16"), time.Now().Add(-time.Second))
+	if err != nil {
+		t.Fatalf("Ban: unexpected error: %v", err)
+	}
+	if b.IsBanned(net.ParseIP("192.168.1.1")) {
+		t.Fatal("IsBanned: expired ban applies")
+	}
+
+	// Clearing the bans must be persisted.
+	if err := b.Clear(); err != nil {
+		t.Fatalf("Clear: unexpected error: %v", err)
+	}
+	if bans := newBanList(filePath).Bans(); len(bans) != 0 {
+		t.Fatalf("cleared bans: got %v", bans)
+	}
+}
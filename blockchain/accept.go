@@ -5,6 +5,8 @@
 package blockchain
 
 import (
+	"fmt"
+
 	"github.com/ltcsuite/ltcd/database"
 	"github.com/ltcsuite/ltcutil"
 )
@@ -29,12 +31,20 @@ func (b *BlockChain) maybeAcceptBlock(block *ltcutil.Block, flags BehaviorFlags)
 	// The height of this block is one more than the referenced previous
 	// block.
 	blockHeight := int32(0)
-	prevNode := b.index.LookupNode(&block.MsgBlock().Header.PrevBlock)
+	prevHash := &block.MsgBlock().Header.PrevBlock
+	prevNode := b.index.LookupNode(prevHash)
 	if prevNode != nil {
 		blockHeight = prevNode.height + 1
 	}
 	block.SetHeight(blockHeight)
 
+	// The block can't be valid when its parent is known to be invalid.
+	if prevNode != nil && b.index.NodeStatus(prevNode).KnownInvalid() {
+		str := fmt.Sprintf("previous block %v is known to be invalid",
+			prevHash)
+		return false, ruleError(ErrInvalidAncestorBlock, str)
+	}
+
 	// The block must pass all of the validation rules which depend on the
 	// position of the block within the block chain.
 	err := b.checkBlockContext(block, prevNode, flags)
@@ -42,6 +52,16 @@ func (b *BlockChain) maybeAcceptBlock(block *ltcutil.Block, flags BehaviorFlags)
 		return false, err
 	}
 
+	// Only the blocks leading up to the base block of a utxo snapshot which
+	// is pending validation may be accepted.
+	if b.utxoSnapshot != nil {
+		err := checkUtxoSnapshotBlock(b.utxoSnapshot, block.Hash(),
+			blockHeight)
+		if err != nil {
+			return false, err
+		}
+	}
+
 	// Insert the block into the database if it's not already there.  Even
 	// though it is possible the block will ultimately fail to connect, it
 	// has already passed all proof-of-work and validity tests which means
@@ -68,14 +88,52 @@ func (b *BlockChain) maybeAcceptBlock(block *ltcutil.Block, flags BehaviorFlags)
 		newNode.workSum.Add(prevNode.workSum, newNode.workSum)
 	}
 
+	// Blocks which were invalidated are not part of the block index after a
+	// restart, so add the block to the index as invalid when it is seen
+	// again in order to allow it to be reconsidered.
+	var invalidated bool
+	err = b.db.View(func(dbTx database.Tx) error {
+		invalidated = dbIsInvalidBlock(dbTx, block.Hash())
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if invalidated {
+		if !dryRun {
+			newNode.status = statusValidateFailed
+			b.index.AddNode(newNode)
+		}
+		str := fmt.Sprintf("block %v has been invalidated", block.Hash())
+		return false, ruleError(ErrKnownInvalidBlock, str)
+	}
+
 	// Connect the passed block to the chain while respecting proper chain
 	// selection according to the chain with the most proof of work.  This
-	// also handles validation of the transaction scripts.
-	isMainChain, err := b.connectBestChain(newNode, block, flags)
+	// also handles validation of the transaction scripts.  The blocks
+	// leading up to the base block of a pending utxo snapshot are only
+	// added to the block index instead since the utxo set already reflects
+	// them.
+	var isMainChain bool
+	if b.utxoSnapshot != nil {
+		isMainChain, err = b.acceptUtxoSnapshotBlock(newNode, block,
+			flags)
+	} else {
+		isMainChain, err = b.connectBestChain(newNode, block, flags)
+	}
 	if err != nil {
 		return false, err
 	}
 
+	// Prune the oldest blocks from the database as needed now that the
+	// main chain has been extended.  Failure to prune does not affect the
+	// validity of the block, so it is only logged.
+	if isMainChain && !dryRun {
+		if err := b.maybePruneBlocks(); err != nil {
+			log.Errorf("Unable to prune blocks: %v", err)
+		}
+	}
+
 	// Notify the caller that the new block was accepted into the block
 	// chain.  The caller would typically want to react by relaying the
 	// inventory to other peers.
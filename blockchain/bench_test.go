@@ -7,6 +7,7 @@ package blockchain
 import (
 	"testing"
 
+	"github.com/ltcsuite/ltcd/txscript"
 	"github.com/ltcsuite/ltcutil"
 )
 
@@ -29,3 +30,30 @@ func BenchmarkIsCoinBaseTx(b *testing.B) {
 		IsCoinBaseTx(tx)
 	}
 }
+
+// benchmarkCheckBlockScripts benchmarks the validation of the scripts of a
+// block with 100 transactions with 5 inputs each using up to the passed number
+// of goroutines.
+func benchmarkCheckBlockScripts(b *testing.B, numWorkers int) {
+	block, view := newScriptValTestBlock(b, 100, 5)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		err := checkBlockScripts(block, view, txscript.ScriptBip16, nil,
+			nil, nil, numWorkers)
+		if err != nil {
+			b.Fatalf("checkBlockScripts: unexpected error: %v", err)
+		}
+	}
+}
+
+// BenchmarkCheckBlockScriptsSerial performs a benchmark of the validation of
+// the scripts of a block using a single goroutine.
+func BenchmarkCheckBlockScriptsSerial(b *testing.B) {
+	benchmarkCheckBlockScripts(b, 1)
+}
+
+// BenchmarkCheckBlockScriptsParallel performs a benchmark of the validation of
+// the scripts of a block using one goroutine per available processor.
+func BenchmarkCheckBlockScriptsParallel(b *testing.B) {
+	benchmarkCheckBlockScripts(b, 0)
+}
@@ -16,6 +16,31 @@ import (
 	"github.com/ltcsuite/ltcd/wire"
 )
 
+// blockStatus is a bit field representing the validation state of a block.
+type blockStatus byte
+
+const (
+	// statusValidateFailed indicates that the block has failed validation
+	// or has been invalidated with InvalidateBlock.
+	statusValidateFailed blockStatus = 1 << iota
+
+	// statusInvalidAncestor indicates that one of the ancestors of the
+	// block has failed validation or has been invalidated, thus the block
+	// is also invalid.
+	statusInvalidAncestor
+
+	// statusNone indicates that the block has no validation state flags
+	// set.
+	statusNone blockStatus = 0
+)
+
+// KnownInvalid returns whether the block is known to be invalid.  This will
+// return false for blocks which are valid as well as those which have not been
+// validated yet.
+func (status blockStatus) KnownInvalid() bool {
+	return status&(statusValidateFailed|statusInvalidAncestor) != 0
+}
+
 // blockNode represents a block within the block chain and is primarily used to
 // aid in selecting the best chain to be the main chain.  The main chain is
 // stored into the block database.
@@ -49,6 +74,12 @@ type blockNode struct {
 	nonce      uint32
 	timestamp  int64
 	merkleRoot chainhash.Hash
+
+	// status is a bitfield representing the validation state of the block.
+	// Unlike the other fields, it may be changed once the node has been
+	// added to the block index, so it must only be accessed using the
+	// concurrent-safe status methods of the block index from then on.
+	status blockStatus
 }
 
 // initBlockNode initializes a block node from the given header and height.  The
@@ -224,3 +255,68 @@ func (bi *blockIndex) AddNode(node *blockNode) {
 	bi.index[node.hash] = node
 	bi.Unlock()
 }
+
+// NodeStatus provides concurrent-safe access to the status field of a node.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) NodeStatus(node *blockNode) blockStatus {
+	bi.RLock()
+	status := node.status
+	bi.RUnlock()
+	return status
+}
+
+// SetStatusFlags turns on the provided status flags of the block node
+// regardless of whether they were on or off previously.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) SetStatusFlags(node *blockNode, flags blockStatus) {
+	bi.Lock()
+	node.status |= flags
+	bi.Unlock()
+}
+
+// UnsetStatusFlags turns off the provided status flags of the block node
+// regardless of whether they were on or off previously.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) UnsetStatusFlags(node *blockNode, flags blockStatus) {
+	bi.Lock()
+	node.status &^= flags
+	bi.Unlock()
+}
+
+// Descendants returns all of the nodes in the block index which descend from
+// the provided node, excluding the node itself.
+//
+// This function is safe for concurrent access.
+func (bi *blockIndex) Descendants(node *blockNode) []*blockNode {
+	bi.RLock()
+	defer bi.RUnlock()
+
+	// Track whether or not each visited node descends from the provided
+	// node so every node in the index only needs to be visited once.
+	var descendants []*blockNode
+	isDescendant := map[*blockNode]bool{node: true}
+	var path []*blockNode
+	for _, n := range bi.index {
+		path = path[:0]
+		descends := false
+		iter := n
+		for iter != nil && iter.height >= node.height {
+			if known, ok := isDescendant[iter]; ok {
+				descends = known
+				break
+			}
+			path = append(path, iter)
+			iter = iter.parent
+		}
+		for _, p := range path {
+			isDescendant[p] = descends
+			if descends {
+				descendants = append(descendants, p)
+			}
+		}
+	}
+	return descendants
+}
@@ -19,9 +19,9 @@ import (
 )
 
 const (
-	// maxOrphanBlocks is the maximum number of orphan blocks that can be
-	// queued.
-	maxOrphanBlocks = 100
+	// DefaultMaxOrphanBlocks is the default maximum number of orphan blocks
+	// that can be queued.
+	DefaultMaxOrphanBlocks = 100
 )
 
 // BlockLocator is used to help locate a specific block.  The algorithm for
@@ -42,10 +42,12 @@ type BlockLocator []*chainhash.Hash
 
 // orphanBlock represents a block that we don't yet have the parent for.  It
 // is a normal block plus an expiration time to prevent caching the orphan
-// forever.
+// forever and a sequence number which orders the orphans by when they were
+// received.
 type orphanBlock struct {
 	block      *ltcutil.Block
 	expiration time.Time
+	seq        uint64
 }
 
 // BestState houses information about the current best block and other info
@@ -98,8 +100,13 @@ type BlockChain struct {
 	chainParams         *chaincfg.Params
 	timeSource          MedianTimeSource
 	sigCache            *txscript.SigCache
+	scriptCache         *txscript.ScriptCache
 	indexManager        IndexManager
 	hashCache           *txscript.HashCache
+	pruneTarget         uint64
+	assumeValid         *chaincfg.Checkpoint
+	scriptWorkers       int
+	utxoCache           *utxoCache
 
 	// The following fields are calculated based upon the provided chain
 	// parameters.  They are also set when the instance is created and
@@ -135,16 +142,27 @@ type BlockChain struct {
 
 	// These fields are related to handling of orphan blocks.  They are
 	// protected by a combination of the chain lock and the orphan lock.
-	orphanLock   sync.RWMutex
-	orphans      map[chainhash.Hash]*orphanBlock
-	prevOrphans  map[chainhash.Hash][]*orphanBlock
-	oldestOrphan *orphanBlock
+	orphanLock  sync.RWMutex
+	orphans     map[chainhash.Hash]*orphanBlock
+	prevOrphans map[chainhash.Hash][]*orphanBlock
+	orphanSeq   uint64
+	maxOrphans  int
 
 	// These fields are related to checkpoint handling.  They are protected
 	// by the chain lock.
 	nextCheckpoint *chaincfg.Checkpoint
 	checkpointNode *blockNode
 
+	// pruneHeight is the height of the most recent block in the main chain
+	// which has been pruned from the database or -1 when no blocks have
+	// been pruned.  It is protected by the chain lock.
+	pruneHeight int32
+
+	// utxoSnapshot is the loaded utxo snapshot whose base block has not
+	// been validated yet or nil when there is no such snapshot.  It is
+	// protected by the chain lock.
+	utxoSnapshot *UtxoSnapshot
+
 	// The state is used as a fairly efficient way to cache information
 	// about the current best chain state that is returned to callers when
 	// requested.  It operates on the principle of MVCC such that any time a
@@ -252,6 +270,17 @@ func (b *BlockChain) GetOrphanRoot(hash *chainhash.Hash) *chainhash.Hash {
 	return orphanRoot
 }
 
+// NumOrphans returns the number of orphan blocks currently held in the orphan
+// pool.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) NumOrphans() int {
+	b.orphanLock.RLock()
+	defer b.orphanLock.RUnlock()
+
+	return len(b.orphans)
+}
+
 // removeOrphanBlock removes the passed orphan block from the orphan pool and
 // previous orphan index.
 func (b *BlockChain) removeOrphanBlock(orphan *orphanBlock) {
@@ -294,25 +323,27 @@ func (b *BlockChain) removeOrphanBlock(orphan *orphanBlock) {
 // blocks and will remove the oldest received orphan block if the limit is
 // exceeded.
 func (b *BlockChain) addOrphanBlock(block *ltcutil.Block) {
-	// Remove expired orphan blocks.
+	// Remove expired orphan blocks and find the oldest received orphan
+	// block so it can be discarded in case the orphan pool fills up.  It
+	// is looked up each time rather than tracked since orphans are also
+	// removed from the pool when their parents arrive.
+	var oldestOrphan *orphanBlock
 	for _, oBlock := range b.orphans {
 		if time.Now().After(oBlock.expiration) {
 			b.removeOrphanBlock(oBlock)
 			continue
 		}
-
-		// Update the oldest orphan block pointer so it can be discarded
-		// in case the orphan pool fills up.
-		if b.oldestOrphan == nil || oBlock.expiration.Before(b.oldestOrphan.expiration) {
-			b.oldestOrphan = oBlock
+		if oldestOrphan == nil || oBlock.seq < oldestOrphan.seq {
+			oldestOrphan = oBlock
 		}
 	}
 
 	// Limit orphan blocks to prevent memory exhaustion.
-	if len(b.orphans)+1 > maxOrphanBlocks {
+	if len(b.orphans)+1 > b.maxOrphans && oldestOrphan != nil {
 		// Remove the oldest orphan to make room for the new one.
-		b.removeOrphanBlock(b.oldestOrphan)
-		b.oldestOrphan = nil
+		log.Debugf("Evicting orphan block %v to make room for orphan "+
+			"block %v", oldestOrphan.block.Hash(), block.Hash())
+		b.removeOrphanBlock(oldestOrphan)
 	}
 
 	// Protect concurrent access.  This is intentionally done here instead
@@ -324,9 +355,11 @@ func (b *BlockChain) addOrphanBlock(block *ltcutil.Block) {
 	// Insert the block into the orphan map with an expiration time
 	// 1 hour from now.
 	expiration := time.Now().Add(time.Hour)
+	b.orphanSeq++
 	oBlock := &orphanBlock{
 		block:      block,
 		expiration: expiration,
+		seq:        b.orphanSeq,
 	}
 	b.orphans[*block.Hash()] = oBlock
 
@@ -597,8 +630,16 @@ func (b *BlockChain) connectBlock(node *blockNode, block *ltcutil.Block, view *U
 	state := newBestState(node, blockSize, blockWeight, numTxns,
 		curTotalTxns+numTxns, node.CalcPastMedianTime())
 
+	// Serialize the utxo entries which were modified by the block so the
+	// utxo cache can be updated with them once the database is updated.
+	serializedUtxos, err := serializeUtxoView(view)
+	if err != nil {
+		return err
+	}
+
 	// Atomically insert info into the database.
-	err := b.db.Update(func(dbTx database.Tx) error {
+	flushUtxos := b.utxoCache.needsFlush()
+	err = b.db.Update(func(dbTx database.Tx) error {
 		// Update best block state.
 		err := dbPutBestState(dbTx, state, node.workSum)
 		if err != nil {
@@ -612,12 +653,16 @@ func (b *BlockChain) connectBlock(node *blockNode, block *ltcutil.Block, view *U
 			return err
 		}
 
-		// Update the utxo set using the state of the utxo view.  This
-		// entails removing all of the utxos spent and adding the new
-		// ones created by the block.
-		err = dbPutUtxoView(dbTx, view)
-		if err != nil {
-			return err
+		// Update the utxo set in the database with the changes held in
+		// the utxo cache along with those of the block when the cache
+		// is flushed.  Otherwise, the changes of the block are only
+		// added to the cache below.
+		if flushUtxos {
+			err = b.utxoCache.flush(dbTx, serializedUtxos,
+				&node.hash, node.height)
+			if err != nil {
+				return err
+			}
 		}
 
 		// Update the transaction spend journal by adding a record for
@@ -643,8 +688,10 @@ func (b *BlockChain) connectBlock(node *blockNode, block *ltcutil.Block, view *U
 		return err
 	}
 
-	// Prune fully spent entries and mark all entries in the view unmodified
-	// now that the modifications have been committed to the database.
+	// Update the utxo cache with the utxos spent and created by the block.
+	// Then prune fully spent entries and mark all entries in the view
+	// unmodified now that the modifications have been committed.
+	b.utxoCache.commit(serializedUtxos, flushUtxos, node.height)
 	view.commit()
 
 	// This node is now the end of the best chain.
@@ -705,6 +752,14 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *ltcutil.Block, view
 	state := newBestState(prevNode, blockSize, blockWeight, numTxns,
 		newTotalTxns, prevNode.CalcPastMedianTime())
 
+	// Serialize the utxo entries which were modified by disconnecting the
+	// block so the utxo cache can be updated with them once the database
+	// is updated.
+	serializedUtxos, err := serializeUtxoView(view)
+	if err != nil {
+		return err
+	}
+
 	err = b.db.Update(func(dbTx database.Tx) error {
 		// Update best block state.
 		err := dbPutBestState(dbTx, state, node.workSum)
@@ -721,8 +776,12 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *ltcutil.Block, view
 
 		// Update the utxo set using the state of the utxo view.  This
 		// entails restoring all of the utxos spent and removing the new
-		// ones created by the block.
-		err = dbPutUtxoView(dbTx, view)
+		// ones created by the block.  The changes held in the utxo
+		// cache are always written along with them, so the utxo set in
+		// the database never corresponds to a block which is no longer
+		// part of the main chain.
+		err = b.utxoCache.flush(dbTx, serializedUtxos, &prevNode.hash,
+			prevNode.height)
 		if err != nil {
 			return err
 		}
@@ -750,8 +809,11 @@ func (b *BlockChain) disconnectBlock(node *blockNode, block *ltcutil.Block, view
 		return err
 	}
 
-	// Prune fully spent entries and mark all entries in the view unmodified
-	// now that the modifications have been committed to the database.
+	// Update the utxo cache with the utxos restored and removed by
+	// disconnecting the block.  Then prune fully spent entries and mark
+	// all entries in the view unmodified now that the modifications have
+	// been committed to the database.
+	b.utxoCache.commit(serializedUtxos, true, prevNode.height)
 	view.commit()
 
 	// This node's parent is now the end of the best chain.
@@ -829,7 +891,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List, flags
 
 		// Load all of the utxos referenced by the block that aren't
 		// already in the view.
-		err = view.fetchInputUtxos(b.db, block)
+		err = view.fetchInputUtxos(b.utxoCache, block)
 		if err != nil {
 			return err
 		}
@@ -888,6 +950,12 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List, flags
 		// not needed.
 		err = b.checkConnectBlock(n, block, view, nil)
 		if err != nil {
+			// Mark the block and its descendants as invalid when it
+			// violates the rules so the chain it is part of is not
+			// selected again.
+			if _, ok := err.(RuleError); ok && flags&BFDryRun != BFDryRun {
+				b.markInvalid(n)
+			}
 			return err
 		}
 	}
@@ -913,7 +981,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List, flags
 
 		// Load all of the utxos referenced by the block that aren't
 		// already in the view.
-		err := view.fetchInputUtxos(b.db, block)
+		err := view.fetchInputUtxos(b.utxoCache, block)
 		if err != nil {
 			return err
 		}
@@ -939,7 +1007,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List, flags
 
 		// Load all of the utxos referenced by the block that aren't
 		// already in the view.
-		err := view.fetchInputUtxos(b.db, block)
+		err := view.fetchInputUtxos(b.utxoCache, block)
 		if err != nil {
 			return err
 		}
@@ -962,7 +1030,11 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List, flags
 	}
 
 	// Log the point where the chain forked and old and new best chain
-	// heads.
+	// heads.  There is nothing to attach or detach when the chain is only
+	// rewound or extended due to blocks being invalidated or reconsidered.
+	if attachNodes.Len() == 0 || detachNodes.Len() == 0 {
+		return nil
+	}
 	firstAttachNode := attachNodes.Front().Value.(*blockNode)
 	firstDetachNode := detachNodes.Front().Value.(*blockNode)
 	lastAttachNode := attachNodes.Back().Value.(*blockNode)
@@ -1021,7 +1093,7 @@ func (b *BlockChain) connectBestChain(node *blockNode, block *ltcutil.Block, fla
 		// utxos, spend them, and add the new utxos being created by
 		// this block.
 		if fastAdd {
-			err := view.fetchInputUtxos(b.db, block)
+			err := view.fetchInputUtxos(b.utxoCache, block)
 			if err != nil {
 				return false, err
 			}
@@ -1227,6 +1299,58 @@ func (b *BlockChain) BlockHeightByHash(hash *chainhash.Hash) (int32, error) {
 	return node.height, nil
 }
 
+// BlockMedianTimeByHash returns the median time of the block with the given
+// hash in the main chain.  This is the median of the timestamps of the block
+// and the blocks before it as used by the consensus rules.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) BlockMedianTimeByHash(hash *chainhash.Hash) (time.Time, error) {
+	node := b.index.LookupNode(hash)
+	if node == nil || !b.bestChain.Contains(node) {
+		str := fmt.Sprintf("block %s is not in the main chain", hash)
+		return time.Time{}, errNotInMainChain(str)
+	}
+
+	return node.CalcPastMedianTime(), nil
+}
+
+// TotalTxnsByHash returns the total number of transactions in the main chain
+// up to and including the block with the given hash.
+//
+// The count is derived from the total number of transactions in the main
+// chain by subtracting the number of transactions in each of the blocks after
+// the given block, so the cost grows with its distance from the end of the
+// main chain.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) TotalTxnsByHash(hash *chainhash.Hash) (uint64, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil || !b.bestChain.Contains(node) {
+		str := fmt.Sprintf("block %s is not in the main chain", hash)
+		return 0, errNotInMainChain(str)
+	}
+
+	totalTxns := b.BestSnapshot().TotalTxns
+	err := b.db.View(func(dbTx database.Tx) error {
+		for n := b.bestChain.Tip(); n != node; n = n.parent {
+			numTxns, err := dbFetchBlockTxCount(dbTx, &n.hash)
+			if err != nil {
+				return err
+			}
+			totalTxns -= numTxns
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return totalTxns, nil
+}
+
 // BlockHashByHeight returns the hash of the block at the given height in the
 // main chain.
 //
@@ -1242,6 +1366,30 @@ func (b *BlockChain) BlockHashByHeight(blockHeight int32) (*chainhash.Hash, erro
 	return &node.hash, nil
 }
 
+// IntervalBlockHashes returns the hashes of the ancestors of the block with
+// the given hash, including the block itself, whose heights are positive
+// multiples of the provided interval, in order of increasing height.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) IntervalBlockHashes(endHash *chainhash.Hash, interval int32) ([]chainhash.Hash, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive - got %d",
+			interval)
+	}
+
+	node := b.index.LookupNode(endHash)
+	if node == nil {
+		return nil, fmt.Errorf("block %s is not known", endHash)
+	}
+
+	hashes := make([]chainhash.Hash, node.height/interval)
+	for i := len(hashes); i > 0; i-- {
+		node = node.Ancestor(int32(i) * interval)
+		hashes[i-1] = node.hash
+	}
+	return hashes, nil
+}
+
 // HeightRange returns a range of block hashes for the given start and end
 // heights.  It is inclusive of the start height and exclusive of the end
 // height.  The end height will be limited to the current main chain height.
@@ -1458,6 +1606,12 @@ type IndexManager interface {
 	// DisconnectBlock is invoked when a block has been disconnected from
 	// the main chain.
 	DisconnectBlock(database.Tx, *ltcutil.Block, *UtxoViewpoint) error
+
+	// CatchUpHeight is invoked when blocks are about to be pruned in order
+	// to determine the height through which the indexes have indexed the
+	// main chain, so the blocks indexes which are still being caught up
+	// need are not pruned.
+	CatchUpHeight(database.Tx) (int32, error)
 }
 
 // Config is a descriptor which specifies the blockchain instance configuration.
@@ -1515,6 +1669,73 @@ type Config struct {
 	// This field can be nil if the caller is not interested in using a
 	// signature cache.
 	HashCache *txscript.HashCache
+
+	// ScriptCache defines a script execution cache to use when validating
+	// the scripts of blocks.  The inputs of the transactions previously
+	// validated with the cache, such as by the mempool, are not validated
+	// again when they are found valid under the script flags of the block.
+	//
+	// This field can be nil if the caller is not interested in using a
+	// script execution cache.
+	ScriptCache *txscript.ScriptCache
+
+	// PruneTarget is the target size in bytes of the block data kept in
+	// the database.  Once the block data exceeds the target, the oldest
+	// blocks are deleted from the database as new blocks are connected to
+	// the main chain while always keeping at least the most recent
+	// minBlocksToKeep blocks.  The block index and utxo set are not
+	// affected.
+	//
+	// This field can be zero to disable pruning.
+	PruneTarget uint64
+
+	// AssumeValid identifies the block the scripts of which, along with
+	// those of all of its ancestors, are assumed to be valid.  The scripts
+	// of the block and its ancestors are not verified when they are
+	// connected to the main chain once the block is in the block index,
+	// while all other validation is still performed.
+	//
+	// This field can be nil to verify the scripts of all blocks.
+	AssumeValid *chaincfg.Checkpoint
+
+	// ScriptWorkers is the maximum number of goroutines used to verify the
+	// scripts of the inputs of a block concurrently.
+	//
+	// This field can be zero to use one goroutine per processor which may
+	// execute simultaneously as reported by runtime.GOMAXPROCS.
+	ScriptWorkers int
+
+	// MaxOrphanBlocks is the maximum number of orphan blocks held in the
+	// orphan pool while waiting for their parents.  The oldest received
+	// orphan is evicted when a new one arrives and the pool is full.
+	//
+	// This field can be zero to use DefaultMaxOrphanBlocks.
+	MaxOrphanBlocks int
+
+	// Reindex specifies which parts of the chain state are rebuilt from
+	// the blocks stored in the database before the chain is used.  A
+	// reindex which was interrupted is always resumed, even when this
+	// field is ReindexNone.  Reindexing is not possible once blocks have
+	// been pruned from the database.
+	Reindex ReindexMode
+
+	// Interrupt specifies a channel the caller can close to signal that
+	// a reindex performed while creating the chain should stop.  New
+	// returns an error in that case and the reindex resumes the next time
+	// the chain is created with the same database.
+	//
+	// This field can be nil if the caller does not desire the behavior.
+	Interrupt <-chan struct{}
+
+	// UtxoCacheMaxSize is the approximate maximum size in bytes of the
+	// cache of the utxo set kept in memory.  The changes to the utxo set
+	// made by connecting blocks are held in the cache and written to the
+	// database in batches once it is full, which greatly reduces the
+	// number of database accesses when many blocks are connected.
+	//
+	// This field can be zero to write the changes of every block to the
+	// database when it is connected.
+	UtxoCacheMaxSize uint64
 }
 
 // New returns a BlockChain instance using the provided configuration details.
@@ -1548,6 +1769,11 @@ func New(config *Config) (*BlockChain, error) {
 		}
 	}
 
+	maxOrphans := config.MaxOrphanBlocks
+	if maxOrphans <= 0 {
+		maxOrphans = DefaultMaxOrphanBlocks
+	}
+
 	params := config.ChainParams
 	targetTimespan := int64(params.TargetTimespan / time.Second)
 	targetTimePerBlock := int64(params.TargetTimePerBlock / time.Second)
@@ -1559,19 +1785,34 @@ func New(config *Config) (*BlockChain, error) {
 		chainParams:         params,
 		timeSource:          config.TimeSource,
 		sigCache:            config.SigCache,
+		scriptCache:         config.ScriptCache,
 		indexManager:        config.IndexManager,
 		minRetargetTimespan: targetTimespan / adjustmentFactor,
 		maxRetargetTimespan: targetTimespan * adjustmentFactor,
 		blocksPerRetarget:   int32(targetTimespan / targetTimePerBlock),
 		index:               newBlockIndex(config.DB, params),
 		hashCache:           config.HashCache,
+		pruneTarget:         config.PruneTarget,
+		assumeValid:         config.AssumeValid,
+		scriptWorkers:       config.ScriptWorkers,
+		utxoCache:           newUtxoCache(config.DB, config.UtxoCacheMaxSize),
+		pruneHeight:         -1,
 		bestChain:           newChainView(nil),
 		orphans:             make(map[chainhash.Hash]*orphanBlock),
 		prevOrphans:         make(map[chainhash.Hash][]*orphanBlock),
+		maxOrphans:          maxOrphans,
 		warningCaches:       newThresholdCaches(vbNumBits),
 		deploymentCaches:    newThresholdCaches(chaincfg.DefinedDeployments),
 	}
 
+	// Reset the chain state stored in the database so it is rebuilt from
+	// the stored blocks below when a reindex is requested.
+	if config.Reindex != ReindexNone {
+		if err := b.startReindex(config.Reindex); err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize the chain state from the passed database.  When the db
 	// does not yet contain any chain state, both it and the chain state
 	// will be initialized to contain only the genesis block.
@@ -1579,6 +1820,19 @@ func New(config *Config) (*BlockChain, error) {
 		return nil, err
 	}
 
+	// Restore the changes to the utxo set which were not written to the
+	// database by the previous instance, such as due to a crash.
+	if err := b.initUtxoCache(); err != nil {
+		return nil, err
+	}
+
+	// Rebuild the chain state from the stored blocks when a reindex was
+	// started, either above or by a previous instance which was
+	// interrupted.
+	if err := b.maybeFinishReindex(config.Interrupt); err != nil {
+		return nil, err
+	}
+
 	// Initialize and catch up all of the currently active optional indexes
 	// as needed.
 	if config.IndexManager != nil {
@@ -5,12 +5,15 @@
 package blockchain
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/ltcsuite/ltcd/chaincfg"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcd/txscript"
 	"github.com/ltcsuite/ltcd/wire"
 	"github.com/ltcsuite/ltcutil"
 )
@@ -800,3 +803,246 @@ func TestLocateInventory(t *testing.T) {
 		}
 	}
 }
+
+// TestForEachUtxo ensures iterating the utxo set visits every unspent output
+// and reports the best block the utxo set represents.
+func TestForEachUtxo(t *testing.T) {
+	// Create a new database and chain instance to run tests against.
+	chain, teardownFunc, err := chainSetup("foreachutxo",
+		&chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Errorf("Failed to setup chain instance: %v", err)
+		return
+	}
+	defer teardownFunc()
+
+	// Add a couple of transactions to the utxo set, one of which has an
+	// output that has already been spent.
+	pkScript := []byte{txscript.OP_TRUE}
+	tx1 := wire.NewMsgTx(1)
+	tx1.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Index: 1}})
+	tx1.AddTxOut(wire.NewTxOut(1000, pkScript))
+	tx1.AddTxOut(wire.NewTxOut(2000, pkScript))
+	tx1.AddTxOut(wire.NewTxOut(3000, pkScript))
+	tx2 := wire.NewMsgTx(1)
+	tx2.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{Index: 2}})
+	tx2.AddTxOut(wire.NewTxOut(4000, pkScript))
+
+	view := NewUtxoViewpoint()
+	view.AddTxOuts(ltcutil.NewTx(tx1), 5)
+	view.AddTxOuts(ltcutil.NewTx(tx2), 7)
+	tx1Hash := tx1.TxHash()
+	view.LookupEntry(&tx1Hash).SpendOutput(1)
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		return dbPutUtxoView(dbTx, view)
+	})
+	if err != nil {
+		t.Fatalf("Failed to store utxo view: %v", err)
+	}
+
+	type utxo struct {
+		outPoint wire.OutPoint
+		amount   int64
+		height   int32
+	}
+	want := map[wire.OutPoint]utxo{}
+	for _, u := range []utxo{
+		{wire.OutPoint{Hash: tx1Hash, Index: 0}, 1000, 5},
+		{wire.OutPoint{Hash: tx1Hash, Index: 2}, 3000, 5},
+		{wire.OutPoint{Hash: tx2.TxHash(), Index: 0}, 4000, 7},
+	} {
+		want[u.outPoint] = u
+	}
+
+	got := map[wire.OutPoint]utxo{}
+	hash, height, err := chain.ForEachUtxo(func(outPoint *wire.OutPoint, entry *UtxoEntry) error {
+		got[*outPoint] = utxo{
+			outPoint: *outPoint,
+			amount:   entry.AmountByIndex(outPoint.Index),
+			height:   entry.BlockHeight(),
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ForEachUtxo: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ForEachUtxo: unexpected utxos -- got %v, want %v",
+			got, want)
+	}
+	genesisHash := chaincfg.RegressionNetParams.GenesisHash
+	if *hash != *genesisHash || height != 0 {
+		t.Fatalf("ForEachUtxo: unexpected best block -- got %v (%d), "+
+			"want %v (0)", hash, height, genesisHash)
+	}
+
+	// Ensure an error returned by the passed function stops the iteration
+	// and is returned unmodified.
+	errStop := errors.New("stop")
+	var visited int
+	_, _, err = chain.ForEachUtxo(func(*wire.OutPoint, *UtxoEntry) error {
+		visited++
+		return errStop
+	})
+	if err != errStop || visited != 1 {
+		t.Fatalf("ForEachUtxo: unexpected result of stopping -- got "+
+			"error %v after %d outputs, want %v after 1", err,
+			visited, errStop)
+	}
+}
+
+// TestTotalTxnsByHash ensures the total number of transactions up to a block
+// in the main chain is derived properly from the transaction counts of the
+// blocks after it.
+func TestTotalTxnsByHash(t *testing.T) {
+	// Create a new database and chain instance to run tests against.
+	chain, teardownFunc, err := chainSetup("totaltxnsbyhash",
+		&chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Errorf("Failed to setup chain instance: %v", err)
+		return
+	}
+	defer teardownFunc()
+
+	// Extend the main chain with blocks which contain one more transaction
+	// than the height of the block.  The blocks are stored directly since
+	// only their transaction counts are of interest.
+	const numBlocks = 10
+	tip := chain.bestChain.Tip()
+	totalTxns := chain.BestSnapshot().TotalTxns
+	wantTotals := map[chainhash.Hash]uint64{tip.hash: totalTxns}
+	for height := int32(1); height <= numBlocks; height++ {
+		msgBlock := wire.NewMsgBlock(&wire.BlockHeader{
+			Version:   1,
+			PrevBlock: tip.hash,
+			Timestamp: time.Unix(tip.timestamp+600, 0),
+		})
+		for i := int32(0); i <= height; i++ {
+			tx := wire.NewMsgTx(1)
+			tx.AddTxIn(&wire.TxIn{PreviousOutPoint: wire.OutPoint{
+				Index: uint32(i),
+			}})
+			tx.AddTxOut(wire.NewTxOut(int64(height), nil))
+			msgBlock.AddTransaction(tx)
+		}
+		err := chain.db.Update(func(dbTx database.Tx) error {
+			return dbTx.StoreBlock(ltcutil.NewBlock(msgBlock))
+		})
+		if err != nil {
+			t.Fatalf("Failed to store block %d: %v", height, err)
+		}
+
+		node := newBlockNode(&msgBlock.Header, height)
+		node.parent = tip
+		chain.index.AddNode(node)
+		tip = node
+		totalTxns += uint64(height + 1)
+		wantTotals[node.hash] = totalTxns
+	}
+	chain.bestChain.SetTip(tip)
+	chain.stateSnapshot = newBestState(tip, 0, 0, numBlocks+1, totalTxns,
+		tip.CalcPastMedianTime())
+
+	for hash, want := range wantTotals {
+		got, err := chain.TotalTxnsByHash(&hash)
+		if err != nil {
+			t.Fatalf("TotalTxnsByHash(%v): unexpected error: %v", hash,
+				err)
+		}
+		if got != want {
+			t.Fatalf("TotalTxnsByHash(%v): got %d, want %d", hash, got,
+				want)
+		}
+	}
+
+	// Ensure blocks which are not in the main chain are rejected.
+	_, err = chain.TotalTxnsByHash(&chainhash.Hash{0x01})
+	if !isNotInMainChainErr(err) {
+		t.Fatalf("TotalTxnsByHash: unexpected error for unknown block "+
+			"-- got %v, want errNotInMainChain", err)
+	}
+}
+
+// TestIntervalBlockHashes ensures that fetching block hashes at specified
+// intervals by end hash works as expected.
+func TestIntervalBlockHashes(t *testing.T) {
+	// Construct a synthetic block chain with a block index consisting of
+	// the following structure.
+	// 	genesis -> 1 -> 2 -> ... -> 15 -> 16  -> 17  -> 18
+	// 	                              \-> 16a -> 17a -> 18a
+	tip := tstTip
+	chain := newFakeChain(&chaincfg.MainNetParams)
+	branch0Nodes := chainedNodes(chain.bestChain.Genesis(), 18)
+	branch1Nodes := chainedNodes(branch0Nodes[14], 3)
+	for _, node := range branch0Nodes {
+		chain.index.AddNode(node)
+	}
+	for _, node := range branch1Nodes {
+		chain.index.AddNode(node)
+	}
+	chain.bestChain.SetTip(tip(branch0Nodes))
+
+	tests := []struct {
+		name        string
+		endHash     chainhash.Hash
+		interval    int32
+		hashes      []chainhash.Hash
+		expectError bool
+	}{
+		{
+			name:     "blocks on main chain",
+			endHash:  branch0Nodes[17].hash,
+			interval: 8,
+			hashes:   nodeHashes(branch0Nodes, 7, 15),
+		},
+		{
+			name:     "blocks on stale chain",
+			endHash:  branch1Nodes[1].hash,
+			interval: 8,
+			hashes: append(nodeHashes(branch0Nodes, 7),
+				nodeHashes(branch1Nodes, 0)...),
+		},
+		{
+			name:     "end is interval block",
+			endHash:  branch0Nodes[15].hash,
+			interval: 8,
+			hashes:   nodeHashes(branch0Nodes, 7, 15),
+		},
+		{
+			name:     "end before first interval",
+			endHash:  branch0Nodes[5].hash,
+			interval: 8,
+			hashes:   []chainhash.Hash{},
+		},
+		{
+			name:        "unknown end block",
+			endHash:     chainhash.Hash{0x01},
+			interval:    8,
+			expectError: true,
+		},
+		{
+			name:        "invalid interval",
+			endHash:     branch0Nodes[17].hash,
+			interval:    0,
+			expectError: true,
+		},
+	}
+	for _, test := range tests {
+		hashes, err := chain.IntervalBlockHashes(&test.endHash, test.interval)
+		if err != nil {
+			if !test.expectError {
+				t.Errorf("%s: unexpected error: %v", test.name, err)
+			}
+			continue
+		}
+		if test.expectError {
+			t.Errorf("%s: did not receive expected error", test.name)
+			continue
+		}
+
+		if !reflect.DeepEqual(hashes, test.hashes) {
+			t.Errorf("%s: unxpected hashes -- got %v, want %v",
+				test.name, hashes, test.hashes)
+		}
+	}
+}
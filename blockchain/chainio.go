@@ -31,6 +31,10 @@ var (
 	// chain state.
 	chainStateKeyName = []byte("chainstate")
 
+	// pruneHeightKeyName is the name of the db key used to store the height
+	// of the most recent block in the main chain which has been pruned.
+	pruneHeightKeyName = []byte("pruneheight")
+
 	// spendJournalBucketName is the name of the db bucket used to house
 	// transactions outputs that are spent in each block.
 	spendJournalBucketName = []byte("spendjournal")
@@ -39,6 +43,10 @@ var (
 	// unspent transaction output set.
 	utxoSetBucketName = []byte("utxoset")
 
+	// invalidBlocksBucketName is the name of the db bucket used to house
+	// the hashes of the blocks which have been invalidated.
+	invalidBlocksBucketName = []byte("invalidblocks")
+
 	// byteOrder is the preferred byte order used for serializing numeric
 	// fields for storage in the database.
 	byteOrder = binary.LittleEndian
@@ -785,9 +793,18 @@ func deserializeUtxoEntry(serialized []byte) (*UtxoEntry, error) {
 // both the entry and the error.
 func dbFetchUtxoEntry(dbTx database.Tx, hash *chainhash.Hash) (*UtxoEntry, error) {
 	// Fetch the unspent transaction output information for the passed
-	// transaction hash.  Return now when there is no entry.
+	// transaction hash.
 	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
-	serializedUtxo := utxoBucket.Get(hash[:])
+	return decodeUtxoEntry(hash, utxoBucket.Get(hash[:]))
+}
+
+// decodeUtxoEntry deserializes the passed utxo entry of the provided Bitcoin
+// transaction hash as stored in the utxo set.
+//
+// When the serialized entry is nil, which means there is no entry for the
+// provided hash, nil will be returned for the both the entry and the error.
+func decodeUtxoEntry(hash *chainhash.Hash, serializedUtxo []byte) (*UtxoEntry, error) {
+	// Return now when there is no entry.
 	if serializedUtxo == nil {
 		return nil, nil
 	}
@@ -818,14 +835,14 @@ func dbFetchUtxoEntry(dbTx database.Tx, hash *chainhash.Hash) (*UtxoEntry, error
 	return entry, nil
 }
 
-// dbPutUtxoView uses an existing database transaction to update the utxo set
-// in the database based on the provided utxo view contents and state.  In
-// particular, only the entries that have been marked as modified are written
-// to the database.
-func dbPutUtxoView(dbTx database.Tx, view *UtxoViewpoint) error {
-	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
-	for txHashIter, entry := range view.entries {
-		// No need to update the database if the entry was not modified.
+// serializeUtxoView returns the serialized utxo entries of the passed view
+// which have been marked as modified keyed by their transaction hash.  The
+// entries of transactions which are now fully spent are nil.
+func serializeUtxoView(view *UtxoViewpoint) (map[chainhash.Hash][]byte, error) {
+	serializedEntries := make(map[chainhash.Hash][]byte)
+	for txHash, entry := range view.entries {
+		// No need to update the utxo set if the entry was not
+		// modified.
 		if entry == nil || !entry.modified {
 			continue
 		}
@@ -834,27 +851,41 @@ func dbPutUtxoView(dbTx database.Tx, view *UtxoViewpoint) error {
 		// spent.
 		serialized, err := serializeUtxoEntry(entry)
 		if err != nil {
-			return err
+			return nil, err
 		}
+		serializedEntries[txHash] = serialized
+	}
+
+	return serializedEntries, nil
+}
+
+// dbPutUtxoEntry uses an existing database transaction to store the passed
+// serialized utxo entry for the provided transaction hash in the utxo set.  The
+// entry is removed instead when it is nil since the transaction is fully spent.
+func dbPutUtxoEntry(dbTx database.Tx, txHash *chainhash.Hash, serialized []byte) error {
+	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+	if serialized == nil {
+		return utxoBucket.Delete(txHash[:])
+	}
+	return utxoBucket.Put(txHash[:], serialized)
+}
 
+// dbPutUtxoView uses an existing database transaction to update the utxo set
+// in the database based on the provided utxo view contents and state.  In
+// particular, only the entries that have been marked as modified are written
+// to the database.
+func dbPutUtxoView(dbTx database.Tx, view *UtxoViewpoint) error {
+	serializedEntries, err := serializeUtxoView(view)
+	if err != nil {
+		return err
+	}
+
+	for txHashIter, serialized := range serializedEntries {
 		// Make a copy of the hash because the iterator changes on each
 		// loop iteration and thus slicing it directly would cause the
 		// data to change out from under the put/delete funcs below.
 		txHash := txHashIter
-
-		// Remove the utxo entry if it is now fully spent.
-		if serialized == nil {
-			if err := utxoBucket.Delete(txHash[:]); err != nil {
-				return err
-			}
-
-			continue
-		}
-
-		// At this point the utxo entry is not fully spent, so store its
-		// serialization in the database.
-		err = utxoBucket.Put(txHash[:], serialized)
-		if err != nil {
+		if err := dbPutUtxoEntry(dbTx, &txHash, serialized); err != nil {
 			return err
 		}
 	}
@@ -1052,6 +1083,61 @@ func dbPutBestState(dbTx database.Tx, snapshot *BestState, workSum *big.Int) err
 	return dbTx.Metadata().Put(chainStateKeyName, serializedData)
 }
 
+// dbPutPruneHeight uses an existing database transaction to update the height
+// of the most recent block in the main chain which has been pruned.
+func dbPutPruneHeight(dbTx database.Tx, height int32) error {
+	var serialized [4]byte
+	byteOrder.PutUint32(serialized[:], uint32(height))
+	return dbTx.Metadata().Put(pruneHeightKeyName, serialized[:])
+}
+
+// dbPutInvalidBlock uses an existing database transaction to mark the block
+// with the provided hash as invalidated.  The bucket which houses the
+// invalidated blocks is created as needed since databases created before the
+// bucket existed do not have it.
+func dbPutInvalidBlock(dbTx database.Tx, hash *chainhash.Hash) error {
+	bucket, err := dbTx.Metadata().CreateBucketIfNotExists(
+		invalidBlocksBucketName)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(hash[:], []byte{})
+}
+
+// dbRemoveInvalidBlock uses an existing database transaction to remove the
+// invalidated mark of the block with the provided hash.
+func dbRemoveInvalidBlock(dbTx database.Tx, hash *chainhash.Hash) error {
+	bucket := dbTx.Metadata().Bucket(invalidBlocksBucketName)
+	if bucket == nil {
+		return nil
+	}
+	return bucket.Delete(hash[:])
+}
+
+// dbIsInvalidBlock uses an existing database transaction to determine whether
+// or not the block with the provided hash is marked as invalidated.
+func dbIsInvalidBlock(dbTx database.Tx, hash *chainhash.Hash) bool {
+	bucket := dbTx.Metadata().Bucket(invalidBlocksBucketName)
+	return bucket != nil && bucket.Get(hash[:]) != nil
+}
+
+// dbFetchPruneHeight uses an existing database transaction to retrieve the
+// height of the most recent block in the main chain which has been pruned.
+// -1 is returned when no blocks have been pruned.
+func dbFetchPruneHeight(dbTx database.Tx) (int32, error) {
+	serialized := dbTx.Metadata().Get(pruneHeightKeyName)
+	if serialized == nil {
+		return -1, nil
+	}
+	if len(serialized) != 4 {
+		return 0, database.Error{
+			ErrorCode:   database.ErrCorruption,
+			Description: "corrupt prune height",
+		}
+	}
+	return int32(byteOrder.Uint32(serialized)), nil
+}
+
 // createChainState initializes both the database and the chain state to the
 // genesis block.  This includes creating the necessary buckets and inserting
 // the genesis block, so it must only be called on an uninitialized database.
@@ -1118,6 +1204,12 @@ func (b *BlockChain) createChainState() error {
 			return err
 		}
 
+		// Record that the utxo set corresponds to the genesis block.
+		err = dbPutUtxoState(dbTx, &node.hash, node.height)
+		if err != nil {
+			return err
+		}
+
 		// Store the genesis block into the database.
 		return dbTx.StoreBlock(genesisBlock)
 	})
@@ -1188,6 +1280,18 @@ func (b *BlockChain) initChainState() error {
 		}
 		b.bestChain.SetTip(tip)
 
+		// Load the height of the most recent pruned block.
+		b.pruneHeight, err = dbFetchPruneHeight(dbTx)
+		if err != nil {
+			return err
+		}
+
+		// Load the utxo snapshot which is pending validation, if any.
+		b.utxoSnapshot, err = dbFetchUtxoSnapshot(dbTx)
+		if err != nil {
+			return err
+		}
+
 		// Load the raw block bytes for the best block.
 		blockBytes, err := dbTx.FetchBlock(&state.hash)
 		if err != nil {
@@ -1251,6 +1355,27 @@ func dbFetchHeaderByHeight(dbTx database.Tx, height int32) (*wire.BlockHeader, e
 	return dbFetchHeaderByHash(dbTx, hash)
 }
 
+// dbFetchBlockTxCount uses an existing database transaction to retrieve the
+// number of transactions in the block with the provided hash.  Only the
+// transaction count which immediately follows the block header is loaded
+// rather than the entire block.
+func dbFetchBlockTxCount(dbTx database.Tx, hash *chainhash.Hash) (uint64, error) {
+	// Every block is larger than the header plus the maximum size of the
+	// transaction count since it must contain at least a coinbase, so the
+	// region is always within the bounds of the block.
+	region := database.BlockRegion{
+		Hash:   hash,
+		Offset: wire.MaxBlockHeaderPayload,
+		Len:    wire.MaxVarIntPayload,
+	}
+	countBytes, err := dbTx.FetchBlockRegion(&region)
+	if err != nil {
+		return 0, err
+	}
+
+	return wire.ReadVarInt(bytes.NewReader(countBytes), 0)
+}
+
 // dbFetchBlockByNode uses an existing database transaction to retrieve the
 // raw block for the provided node, deserialize it, and return a ltcutil.Block
 // with the height set.
@@ -78,6 +78,25 @@ func (b *BlockChain) verifyCheckpoint(height int32, hash *chainhash.Hash) bool {
 	return true
 }
 
+// isAssumedValid returns whether the scripts of the passed block node are
+// assumed to be valid, which is only the case when the assumed valid block is
+// in the block index and the node is the assumed valid block or one of its
+// ancestors.  Nodes are never assumed valid while the assumed valid block is
+// not known since they can't be identified as its ancestors.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) isAssumedValid(node *blockNode) bool {
+	if b.assumeValid == nil {
+		return false
+	}
+
+	assumeValidNode := b.index.LookupNode(b.assumeValid.Hash)
+	if assumeValidNode == nil || node.height > assumeValidNode.height {
+		return false
+	}
+	return assumeValidNode.Ancestor(node.height) == node
+}
+
 // findPreviousCheckpoint finds the most recent checkpoint that is already
 // available in the downloaded portion of the block chain and returns the
 // associated block node.  It returns nil if a checkpoint can't be found (this
@@ -212,6 +212,19 @@ const (
 	// included in the block's coinbase transaction doesn't match the
 	// manually computed witness commitment.
 	ErrWitnessCommitmentMismatch
+
+	// ErrInvalidAncestorBlock indicates that an ancestor of the block is
+	// known to be invalid, either because it failed validation or because
+	// it was invalidated.
+	ErrInvalidAncestorBlock
+
+	// ErrKnownInvalidBlock indicates that the block was previously
+	// invalidated and has not been reconsidered since.
+	ErrKnownInvalidBlock
+
+	// ErrPrevBlockNotFound indicates that the previous block of a block
+	// header which is checked on its own is not known.
+	ErrPrevBlockNotFound
 )
 
 // Map of ErrorCode values back to their constant names for pretty printing.
@@ -257,6 +270,9 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrUnexpectedWitness:         "ErrUnexpectedWitness",
 	ErrInvalidWitnessCommitment:  "ErrInvalidWitnessCommitment",
 	ErrWitnessCommitmentMismatch: "ErrWitnessCommitmentMismatch",
+	ErrInvalidAncestorBlock:      "ErrInvalidAncestorBlock",
+	ErrKnownInvalidBlock:         "ErrKnownInvalidBlock",
+	ErrPrevBlockNotFound:         "ErrPrevBlockNotFound",
 }
 
 // String returns the ErrorCode as a human-readable name.
@@ -52,6 +52,9 @@ func TestErrorCodeStringer(t *testing.T) {
 		{ErrBadCoinbaseHeight, "ErrBadCoinbaseHeight"},
 		{ErrScriptMalformed, "ErrScriptMalformed"},
 		{ErrScriptValidation, "ErrScriptValidation"},
+		{ErrInvalidAncestorBlock, "ErrInvalidAncestorBlock"},
+		{ErrKnownInvalidBlock, "ErrKnownInvalidBlock"},
+		{ErrPrevBlockNotFound, "ErrPrevBlockNotFound"},
 		{0xffff, "Unknown ErrorCode (65535)"},
 	}
 
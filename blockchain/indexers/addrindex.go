@@ -7,6 +7,7 @@ package indexers
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/ltcsuite/ltcd/blockchain"
@@ -64,8 +65,33 @@ const (
 	addrKeyTypeWitnessScriptHash = 3
 
 	// Size of a transaction entry.  It consists of 4 bytes block id + 4
-	// bytes offset + 4 bytes length.
-	txEntrySize = 4 + 4 + 4
+	// bytes offset + 4 bytes length + 1 byte transaction type.
+	txEntrySize = 4 + 4 + 4 + 1
+
+	// addrIndexVersion is the current version of the address index.  It is
+	// stored in the index so indexes created by older versions, which are
+	// not compatible with the current serialization format, are detected.
+	addrIndexVersion = 1
+)
+
+// AddrTxType identifies the role an address has in a transaction.  The types
+// are bit flags, so a transaction which both spends from and pays to an
+// address has both of them set.
+type AddrTxType uint8
+
+const (
+	// AddrTxSend identifies a transaction which spends a previous output
+	// paying to the address.
+	AddrTxSend AddrTxType = 1 << iota
+
+	// AddrTxReceive identifies a transaction which creates an output
+	// paying to the address.
+	AddrTxReceive
+
+	// AddrTxBoth identifies a transaction which either spends a previous
+	// output paying to the address or creates an output paying to it.  It
+	// is used to query all transactions involving an address.
+	AddrTxBoth = AddrTxSend | AddrTxReceive
 )
 
 var (
@@ -73,6 +99,11 @@ var (
 	// to house it.
 	addrIndexKey = []byte("txbyaddridx")
 
+	// addrIndexVersionKeyName is the name of the key in the address index
+	// bucket which houses the version of the index.  It can't be mistaken
+	// for the key of an address index entry since it is shorter.
+	addrIndexVersionKeyName = []byte("version")
+
 	// errUnsupportedAddressType is an error that is used to signal an
 	// unsupported address type has been used.
 	errUnsupportedAddressType = errors.New("address type is not supported " +
@@ -126,28 +157,37 @@ var (
 //
 // The serialized value format is:
 //
-//   [<block id><start offset><tx length>,...]
+//   [<block id><start offset><tx length><tx type>,...]
 //
 //   Field           Type      Size
 //   block id        uint32    4 bytes
 //   start offset    uint32    4 bytes
 //   tx length       uint32    4 bytes
+//   tx type         uint8     1 byte
 //   -----
-//   Total: 12 bytes per indexed tx
+//   Total: 13 bytes per indexed tx
+//
+// The tx type is the AddrTxType which identifies whether the transaction spends
+// from the address, pays to it, or both.
+//
+// The version of the index is stored in the same bucket under the key named
+// "version" as a uint32.
 // -----------------------------------------------------------------------------
 
 // fetchBlockHashFunc defines a callback function to use in order to convert a
 // serialized block ID to an associated block hash.
 type fetchBlockHashFunc func(serializedID []byte) (*chainhash.Hash, error)
 
-// serializeAddrIndexEntry serializes the provided block id and transaction
-// location according to the format described in detail above.
-func serializeAddrIndexEntry(blockID uint32, txLoc wire.TxLoc) []byte {
+// serializeAddrIndexEntry serializes the provided block id, transaction
+// location, and transaction type according to the format described in detail
+// above.
+func serializeAddrIndexEntry(blockID uint32, txLoc wire.TxLoc, txType AddrTxType) []byte {
 	// Serialize the entry.
-	serialized := make([]byte, 12)
+	serialized := make([]byte, txEntrySize)
 	byteOrder.PutUint32(serialized, blockID)
 	byteOrder.PutUint32(serialized[4:], uint32(txLoc.TxStart))
 	byteOrder.PutUint32(serialized[8:], uint32(txLoc.TxLen))
+	serialized[12] = byte(txType)
 	return serialized
 }
 
@@ -182,14 +222,14 @@ func keyForLevel(addrKey [addrKeySize]byte, level uint8) [levelKeySize]byte {
 
 // dbPutAddrIndexEntry updates the address index to include the provided entry
 // according to the level-based scheme described in detail above.
-func dbPutAddrIndexEntry(bucket internalBucket, addrKey [addrKeySize]byte, blockID uint32, txLoc wire.TxLoc) error {
+func dbPutAddrIndexEntry(bucket internalBucket, addrKey [addrKeySize]byte, blockID uint32, txLoc wire.TxLoc, txType AddrTxType) error {
 	// Start with level 0 and its initial max number of entries.
 	curLevel := uint8(0)
 	maxLevelBytes := level0MaxEntries * txEntrySize
 
 	// Simply append the new entry to level 0 and return now when it will
 	// fit.  This is the most common path.
-	newData := serializeAddrIndexEntry(blockID, txLoc)
+	newData := serializeAddrIndexEntry(blockID, txLoc, txType)
 	level0Key := keyForLevel(addrKey, 0)
 	level0Data := bucket.Get(level0Key[:])
 	if len(level0Data)+len(newData) <= maxLevelBytes {
@@ -249,25 +289,37 @@ func dbPutAddrIndexEntry(bucket internalBucket, addrKey [addrKeySize]byte, block
 	return bucket.Put(level0Key[:], newData)
 }
 
-// dbFetchAddrIndexEntries returns block regions for transactions referenced by
-// the given address key and the number of entries skipped since it could have
-// been less in the case where there are less total entries than the requested
-// number of entries to skip.
-func dbFetchAddrIndexEntries(bucket internalBucket, addrKey [addrKeySize]byte, numToSkip, numRequested uint32, reverse bool, fetchBlockHash fetchBlockHashFunc) ([]database.BlockRegion, uint32, error) {
+// addrIndexEntryMatches returns whether or not the serialized address index
+// entry at the passed offset is for a transaction of any of the passed types.
+func addrIndexEntryMatches(serialized []byte, offset int, txType AddrTxType) bool {
+	return AddrTxType(serialized[offset+txEntrySize-1])&txType != 0
+}
+
+// dbFetchAddrIndexEntries returns block regions for transactions of the given
+// types referenced by the given address key and the number of entries skipped
+// since it could have been less in the case where there are less total matching
+// entries than the requested number of entries to skip.
+func dbFetchAddrIndexEntries(bucket internalBucket, addrKey [addrKeySize]byte, txType AddrTxType, numToSkip, numRequested uint32, reverse bool, fetchBlockHash fetchBlockHashFunc) ([]database.BlockRegion, uint32, error) {
 	// When the reverse flag is not set, all levels need to be fetched
 	// because numToSkip and numRequested are counted from the oldest
 	// transactions (highest level) and thus the total count is needed.
-	// However, when the reverse flag is set, only enough records to satisfy
-	// the requested amount are needed.
+	// However, when the reverse flag is set, only enough records of the
+	// requested types to satisfy the requested amount are needed.
 	var level uint8
 	var serialized []byte
-	for !reverse || len(serialized) < int(numToSkip+numRequested)*txEntrySize {
+	var numMatches int
+	for !reverse || numMatches < int(numToSkip+numRequested) {
 		curLevelKey := keyForLevel(addrKey, level)
 		levelData := bucket.Get(curLevelKey[:])
 		if levelData == nil {
 			// Stop when there are no more levels.
 			break
 		}
+		for offset := 0; offset < len(levelData); offset += txEntrySize {
+			if addrIndexEntryMatches(levelData, offset, txType) {
+				numMatches++
+			}
+		}
 
 		// Higher levels contain older transactions, so prepend them.
 		prepended := make([]byte, len(serialized)+len(levelData))
@@ -277,10 +329,18 @@ func dbFetchAddrIndexEntries(bucket internalBucket, addrKey [addrKeySize]byte, n
 		level++
 	}
 
+	// Determine the offsets of the entries of the requested types.
+	entryOffsets := make([]int, 0, numMatches)
+	for offset := 0; offset < len(serialized); offset += txEntrySize {
+		if addrIndexEntryMatches(serialized, offset, txType) {
+			entryOffsets = append(entryOffsets, offset)
+		}
+	}
+
 	// When the requested number of entries to skip is larger than the
 	// number available, skip them all and return now with the actual number
 	// skipped.
-	numEntries := uint32(len(serialized) / txEntrySize)
+	numEntries := uint32(len(entryOffsets))
 	if numToSkip >= numEntries {
 		return nil, numEntries, nil
 	}
@@ -302,11 +362,11 @@ func dbFetchAddrIndexEntries(bucket internalBucket, addrKey [addrKeySize]byte, n
 	results := make([]database.BlockRegion, numToLoad)
 	for i := uint32(0); i < numToLoad; i++ {
 		// Calculate the read offset according to the reverse flag.
-		var offset uint32
+		var offset int
 		if reverse {
-			offset = (numEntries - numToSkip - i - 1) * txEntrySize
+			offset = entryOffsets[numEntries-numToSkip-i-1]
 		} else {
-			offset = (numToSkip + i) * txEntrySize
+			offset = entryOffsets[numToSkip+i]
 		}
 
 		// Deserialize and populate the result.
@@ -569,11 +629,44 @@ func addrToKey(addr ltcutil.Address) ([addrKeySize]byte, error) {
 	return [addrKeySize]byte{}, errUnsupportedAddressType
 }
 
+// unconfirmedAddrTx houses an unconfirmed transaction which involves an address
+// along with the role the address has in it and a sequence number which orders
+// it relative to the other unconfirmed transactions by when it was added.
+type unconfirmedAddrTx struct {
+	tx     *ltcutil.Tx
+	txType AddrTxType
+	seq    uint64
+}
+
+// unconfirmedAddrTxsBySeq provides sort.Interface to sort unconfirmed
+// transactions by the order they were added in.
+type unconfirmedAddrTxsBySeq []unconfirmedAddrTx
+
+// Len returns the number of transactions in the slice.  It is part of the
+// sort.Interface implementation.
+func (s unconfirmedAddrTxsBySeq) Len() int {
+	return len(s)
+}
+
+// Swap swaps the transactions at the passed indices.  It is part of the
+// sort.Interface implementation.
+func (s unconfirmedAddrTxsBySeq) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+// Less returns whether the transaction with index i was added before the
+// transaction with index j.  It is part of the sort.Interface implementation.
+func (s unconfirmedAddrTxsBySeq) Less(i, j int) bool {
+	return s[i].seq < s[j].seq
+}
+
 // AddrIndex implements a transaction by address index.  That is to say, it
 // supports querying all transactions that reference a given address because
 // they are either crediting or debiting the address.  The returned transactions
 // are ordered according to their order of appearance in the blockchain.  In
 // other words, first by block height and then by offset inside the block.
+// Queries may be limited to the transactions which only debit (send) or credit
+// (receive) the address.
 //
 // In addition, support is provided for a memory-only index of unconfirmed
 // transactions such as those which are kept in the memory pool before inclusion
@@ -592,15 +685,20 @@ type AddrIndex struct {
 	//
 	// The txnsByAddr field is used to keep an index of all transactions
 	// which either create an output to a given address or spend from a
-	// previous output to it keyed by the address.
+	// previous output to it keyed by the address.  Each transaction is
+	// stored along with whether it does either or both.
 	//
 	// The addrsByTx field is essentially the reverse and is used to
 	// keep an index of all addresses which a given transaction involves.
 	// This allows fairly efficient updates when transactions are removed
 	// once they are included into a block.
-	unconfirmedLock sync.RWMutex
-	txnsByAddr      map[[addrKeySize]byte]map[chainhash.Hash]*ltcutil.Tx
-	addrsByTx       map[chainhash.Hash]map[[addrKeySize]byte]struct{}
+	//
+	// The nextUnconfirmedSeq field is the sequence number assigned to the
+	// next transaction added to the index.
+	unconfirmedLock    sync.RWMutex
+	txnsByAddr         map[[addrKeySize]byte]map[chainhash.Hash]unconfirmedAddrTx
+	addrsByTx          map[chainhash.Hash]map[[addrKeySize]byte]struct{}
+	nextUnconfirmedSeq uint64
 }
 
 // Ensure the AddrIndex type implements the Indexer interface.
@@ -617,13 +715,39 @@ func (idx *AddrIndex) NeedsInputs() bool {
 	return true
 }
 
-// Init is only provided to satisfy the Indexer interface as there is nothing to
-// initialize for this index.
+// Ensure the AddrIndex type implements the NeedsBlockDataer interface.
+var _ NeedsBlockDataer = (*AddrIndex)(nil)
+
+// NeedsBlockData signals that the index references the stored block data to
+// locate the transactions involving each address.
+//
+// This implements the NeedsBlockDataer interface.
+func (idx *AddrIndex) NeedsBlockData() bool {
+	return true
+}
+
+// Init ensures the existing address index was created by a version which uses
+// the current serialization format.  Older indexes do not record whether each
+// transaction sends from or receives to the address, so they must be dropped
+// and rebuilt.
 //
 // This is part of the Indexer interface.
 func (idx *AddrIndex) Init() error {
-	// Nothing to do.
-	return nil
+	return idx.db.View(func(dbTx database.Tx) error {
+		bucket := dbTx.Metadata().Bucket(addrIndexKey)
+		serialized := bucket.Get(addrIndexVersionKeyName)
+		var version uint32
+		if len(serialized) == 4 {
+			version = byteOrder.Uint32(serialized)
+		}
+		if version != addrIndexVersion {
+			return fmt.Errorf("the existing %s is version %d, "+
+				"but version %d is required -- drop it with "+
+				"--dropaddrindex so it can be rebuilt",
+				addrIndexName, version, addrIndexVersion)
+		}
+		return nil
+	})
 }
 
 // Key returns the database key to use for the index as a byte slice.
@@ -640,26 +764,47 @@ func (idx *AddrIndex) Name() string {
 	return addrIndexName
 }
 
+// Tip returns the hash and height of the most recent block which has been
+// indexed.
+//
+// This is part of the Indexer interface.
+func (idx *AddrIndex) Tip() (*chainhash.Hash, int32, error) {
+	return fetchIndexerTip(idx.db, addrIndexKey)
+}
+
 // Create is invoked when the indexer manager determines the index needs
 // to be created for the first time.  It creates the bucket for the address
-// index.
+// index and stores its version.
 //
 // This is part of the Indexer interface.
 func (idx *AddrIndex) Create(dbTx database.Tx) error {
-	_, err := dbTx.Metadata().CreateBucket(addrIndexKey)
-	return err
+	bucket, err := dbTx.Metadata().CreateBucket(addrIndexKey)
+	if err != nil {
+		return err
+	}
+
+	var serialized [4]byte
+	byteOrder.PutUint32(serialized[:], addrIndexVersion)
+	return bucket.Put(addrIndexVersionKeyName, serialized[:])
+}
+
+// indexedTx identifies a transaction within a block which involves an address
+// along with the role the address has in it.
+type indexedTx struct {
+	txIdx  int
+	txType AddrTxType
 }
 
 // writeIndexData represents the address index data to be written for one block.
 // It consistens of the address mapped to an ordered list of the transactions
 // that involve the address in block.  It is ordered so the transactions can be
 // stored in the order they appear in the block.
-type writeIndexData map[[addrKeySize]byte][]int
+type writeIndexData map[[addrKeySize]byte][]indexedTx
 
 // indexPkScript extracts all standard addresses from the passed public key
-// script and maps each of them to the associated transaction using the passed
-// map.
-func (idx *AddrIndex) indexPkScript(data writeIndexData, pkScript []byte, txIdx int) {
+// script and maps each of them to the associated transaction of the given type
+// using the passed map.
+func (idx *AddrIndex) indexPkScript(data writeIndexData, pkScript []byte, txIdx int, txType AddrTxType) {
 	// Nothing to index if the script is non-standard or otherwise doesn't
 	// contain any addresses.
 	_, addrs, _, err := txscript.ExtractPkScriptAddrs(pkScript,
@@ -678,13 +823,16 @@ func (idx *AddrIndex) indexPkScript(data writeIndexData, pkScript []byte, txIdx
 		// Avoid inserting the transaction more than once.  Since the
 		// transactions are indexed serially any duplicates will be
 		// indexed in a row, so checking the most recent entry for the
-		// address is enough to detect duplicates.
+		// address is enough to detect duplicates.  The type of the
+		// duplicate is combined with that of the existing entry since
+		// a transaction might both spend from and pay to an address.
 		indexedTxns := data[addrKey]
 		numTxns := len(indexedTxns)
-		if numTxns > 0 && indexedTxns[numTxns-1] == txIdx {
+		if numTxns > 0 && indexedTxns[numTxns-1].txIdx == txIdx {
+			indexedTxns[numTxns-1].txType |= txType
 			continue
 		}
-		indexedTxns = append(indexedTxns, txIdx)
+		indexedTxns = append(indexedTxns, indexedTx{txIdx, txType})
 		data[addrKey] = indexedTxns
 	}
 }
@@ -710,12 +858,14 @@ func (idx *AddrIndex) indexBlock(data writeIndexData, block *ltcutil.Block, view
 				}
 
 				pkScript := entry.PkScriptByIndex(origin.Index)
-				idx.indexPkScript(data, pkScript, txIdx)
+				idx.indexPkScript(data, pkScript, txIdx,
+					AddrTxSend)
 			}
 		}
 
 		for _, txOut := range tx.MsgTx().TxOut {
-			idx.indexPkScript(data, txOut.PkScript, txIdx)
+			idx.indexPkScript(data, txOut.PkScript, txIdx,
+				AddrTxReceive)
 		}
 	}
 }
@@ -745,10 +895,10 @@ func (idx *AddrIndex) ConnectBlock(dbTx database.Tx, block *ltcutil.Block, view
 
 	// Add all of the index entries for each address.
 	addrIdxBucket := dbTx.Metadata().Bucket(addrIndexKey)
-	for addrKey, txIdxs := range addrsToTxns {
-		for _, txIdx := range txIdxs {
+	for addrKey, txns := range addrsToTxns {
+		for _, txn := range txns {
 			err := dbPutAddrIndexEntry(addrIdxBucket, addrKey,
-				blockID, txLocs[txIdx])
+				blockID, txLocs[txn.txIdx], txn.txType)
 			if err != nil {
 				return err
 			}
@@ -770,8 +920,8 @@ func (idx *AddrIndex) DisconnectBlock(dbTx database.Tx, block *ltcutil.Block, vi
 
 	// Remove all of the index entries for each address.
 	bucket := dbTx.Metadata().Bucket(addrIndexKey)
-	for addrKey, txIdxs := range addrsToTxns {
-		err := dbRemoveAddrIndexEntries(bucket, addrKey, len(txIdxs))
+	for addrKey, txns := range addrsToTxns {
+		err := dbRemoveAddrIndexEntries(bucket, addrKey, len(txns))
 		if err != nil {
 			return err
 		}
@@ -781,17 +931,17 @@ func (idx *AddrIndex) DisconnectBlock(dbTx database.Tx, block *ltcutil.Block, vi
 }
 
 // TxRegionsForAddress returns a slice of block regions which identify each
-// transaction that involves the passed address according to the specified
-// number to skip, number requested, and whether or not the results should be
-// reversed.  It also returns the number actually skipped since it could be less
-// in the case where there are not enough entries.
+// transaction of the given types that involves the passed address according to
+// the specified number to skip, number requested, and whether or not the
+// results should be reversed.  It also returns the number actually skipped
+// since it could be less in the case where there are not enough entries.
 //
 // NOTE: These results only include transactions confirmed in blocks.  See the
 // UnconfirmedTxnsForAddress method for obtaining unconfirmed transactions
 // that involve a given address.
 //
 // This function is safe for concurrent access.
-func (idx *AddrIndex) TxRegionsForAddress(dbTx database.Tx, addr ltcutil.Address, numToSkip, numRequested uint32, reverse bool) ([]database.BlockRegion, uint32, error) {
+func (idx *AddrIndex) TxRegionsForAddress(dbTx database.Tx, addr ltcutil.Address, txType AddrTxType, numToSkip, numRequested uint32, reverse bool) ([]database.BlockRegion, uint32, error) {
 	addrKey, err := addrToKey(addr)
 	if err != nil {
 		return nil, 0, err
@@ -810,7 +960,7 @@ func (idx *AddrIndex) TxRegionsForAddress(dbTx database.Tx, addr ltcutil.Address
 		var err error
 		addrIdxBucket := dbTx.Metadata().Bucket(addrIndexKey)
 		regions, skipped, err = dbFetchAddrIndexEntries(addrIdxBucket,
-			addrKey, numToSkip, numRequested, reverse,
+			addrKey, txType, numToSkip, numRequested, reverse,
 			fetchBlockHash)
 		return err
 	})
@@ -820,10 +970,10 @@ func (idx *AddrIndex) TxRegionsForAddress(dbTx database.Tx, addr ltcutil.Address
 
 // indexUnconfirmedAddresses modifies the unconfirmed (memory-only) address
 // index to include mappings for the addresses encoded by the passed public key
-// script to the transaction.
+// script to the transaction of the given type with the given sequence number.
 //
 // This function is safe for concurrent access.
-func (idx *AddrIndex) indexUnconfirmedAddresses(pkScript []byte, tx *ltcutil.Tx) {
+func (idx *AddrIndex) indexUnconfirmedAddresses(pkScript []byte, tx *ltcutil.Tx, txType AddrTxType, seq uint64) {
 	// The error is ignored here since the only reason it can fail is if the
 	// script fails to parse and it was already validated before being
 	// admitted to the mempool.
@@ -836,14 +986,20 @@ func (idx *AddrIndex) indexUnconfirmedAddresses(pkScript []byte, tx *ltcutil.Tx)
 			continue
 		}
 
-		// Add a mapping from the address to the transaction.
+		// Add a mapping from the address to the transaction while
+		// combining the type with any existing mapping since a
+		// transaction might both spend from and pay to an address.
 		idx.unconfirmedLock.Lock()
 		addrIndexEntry := idx.txnsByAddr[addrKey]
 		if addrIndexEntry == nil {
-			addrIndexEntry = make(map[chainhash.Hash]*ltcutil.Tx)
+			addrIndexEntry = make(map[chainhash.Hash]unconfirmedAddrTx)
 			idx.txnsByAddr[addrKey] = addrIndexEntry
 		}
-		addrIndexEntry[*tx.Hash()] = tx
+		addrTx := addrIndexEntry[*tx.Hash()]
+		addrTx.tx = tx
+		addrTx.txType |= txType
+		addrTx.seq = seq
+		addrIndexEntry[*tx.Hash()] = addrTx
 
 		// Add a mapping from the transaction to the address.
 		addrsByTxEntry := idx.addrsByTx[*tx.Hash()]
@@ -866,6 +1022,13 @@ func (idx *AddrIndex) indexUnconfirmedAddresses(pkScript []byte, tx *ltcutil.Tx)
 //
 // This function is safe for concurrent access.
 func (idx *AddrIndex) AddUnconfirmedTx(tx *ltcutil.Tx, utxoView *blockchain.UtxoViewpoint) {
+	// Assign the transaction the next sequence number so transactions can
+	// be returned in the order they were added.
+	idx.unconfirmedLock.Lock()
+	seq := idx.nextUnconfirmedSeq
+	idx.nextUnconfirmedSeq++
+	idx.unconfirmedLock.Unlock()
+
 	// Index addresses of all referenced previous transaction outputs.
 	//
 	// The existence checks are elided since this is only called after the
@@ -880,12 +1043,13 @@ func (idx *AddrIndex) AddUnconfirmedTx(tx *ltcutil.Tx, utxoView *blockchain.Utxo
 			continue
 		}
 		pkScript := entry.PkScriptByIndex(txIn.PreviousOutPoint.Index)
-		idx.indexUnconfirmedAddresses(pkScript, tx)
+		idx.indexUnconfirmedAddresses(pkScript, tx, AddrTxSend, seq)
 	}
 
 	// Index addresses of all created outputs.
 	for _, txOut := range tx.MsgTx().TxOut {
-		idx.indexUnconfirmedAddresses(txOut.PkScript, tx)
+		idx.indexUnconfirmedAddresses(txOut.PkScript, tx,
+			AddrTxReceive, seq)
 	}
 }
 
@@ -911,12 +1075,15 @@ func (idx *AddrIndex) RemoveUnconfirmedTx(hash *chainhash.Hash) {
 	delete(idx.addrsByTx, *hash)
 }
 
-// UnconfirmedTxnsForAddress returns all transactions currently in the
-// unconfirmed (memory-only) address index that involve the passed address.
-// Unsupported address types are ignored and will result in no results.
+// UnconfirmedTxnsForAddress returns all transactions of the given types
+// currently in the unconfirmed (memory-only) address index that involve the
+// passed address.  The transactions are ordered by when they were added to the
+// index, oldest first, which also ensures any transactions they depend on come
+// before them.  Unsupported address types are ignored and will result in no
+// results.
 //
 // This function is safe for concurrent access.
-func (idx *AddrIndex) UnconfirmedTxnsForAddress(addr ltcutil.Address) []*ltcutil.Tx {
+func (idx *AddrIndex) UnconfirmedTxnsForAddress(addr ltcutil.Address, txType AddrTxType) []*ltcutil.Tx {
 	// Ignore unsupported address types.
 	addrKey, err := addrToKey(addr)
 	if err != nil {
@@ -929,15 +1096,21 @@ func (idx *AddrIndex) UnconfirmedTxnsForAddress(addr ltcutil.Address) []*ltcutil
 
 	// Return a new slice with the results if there are any.  This ensures
 	// safe concurrency.
-	if txns, exists := idx.txnsByAddr[addrKey]; exists {
-		addressTxns := make([]*ltcutil.Tx, 0, len(txns))
-		for _, tx := range txns {
-			addressTxns = append(addressTxns, tx)
+	var addrTxns []unconfirmedAddrTx
+	for _, addrTx := range idx.txnsByAddr[addrKey] {
+		if addrTx.txType&txType != 0 {
+			addrTxns = append(addrTxns, addrTx)
 		}
-		return addressTxns
 	}
-
-	return nil
+	if len(addrTxns) == 0 {
+		return nil
+	}
+	sort.Sort(unconfirmedAddrTxsBySeq(addrTxns))
+	addressTxns := make([]*ltcutil.Tx, 0, len(addrTxns))
+	for _, addrTx := range addrTxns {
+		addressTxns = append(addressTxns, addrTx.tx)
+	}
+	return addressTxns
 }
 
 // NewAddrIndex returns a new instance of an indexer that is used to create a
@@ -951,7 +1124,7 @@ func NewAddrIndex(db database.DB, chainParams *chaincfg.Params) *AddrIndex {
 	return &AddrIndex{
 		db:          db,
 		chainParams: chainParams,
-		txnsByAddr:  make(map[[addrKeySize]byte]map[chainhash.Hash]*ltcutil.Tx),
+		txnsByAddr:  make(map[[addrKeySize]byte]map[chainhash.Hash]unconfirmedAddrTx),
 		addrsByTx:   make(map[chainhash.Hash]map[[addrKeySize]byte]struct{}),
 	}
 }
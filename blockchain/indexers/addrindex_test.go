@@ -7,9 +7,16 @@ package indexers
 import (
 	"bytes"
 	"fmt"
+	"reflect"
 	"testing"
 
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcd/txscript"
 	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
 )
 
 // addrIndexBucket provides a mock address index database bucket by implementing
@@ -222,7 +229,7 @@ nextTest:
 		for i := 0; i < test.numInsert; i++ {
 			txLoc := wire.TxLoc{TxStart: i * 2}
 			err := dbPutAddrIndexEntry(populatedBucket, test.key,
-				uint32(i), txLoc)
+				uint32(i), txLoc, AddrTxReceive)
 			if err != nil {
 				t.Errorf("dbPutAddrIndexEntry #%d (%s) - "+
 					"unexpected error: %v", testNum,
@@ -274,3 +281,169 @@ nextTest:
 		}
 	}
 }
+
+// TestAddrIndexTxTypes ensures transactions which spend from an address, pay
+// to it, or both are indexed with the appropriate types and that queries for
+// the transactions involving the address can be limited to those types.
+func TestAddrIndexTxTypes(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.RegressionNetParams
+	addr, err := ltcutil.NewAddressPubKeyHash(bytes.Repeat([]byte{0x01},
+		20), params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to create script: %v", err)
+	}
+	addrKey, err := addrToKey(addr)
+	if err != nil {
+		t.Fatalf("unable to create address key: %v", err)
+	}
+	otherScript := []byte{txscript.OP_TRUE}
+
+	// Create a block in which the address receives funds in the second
+	// transaction, sends them in the third, and both sends and receives
+	// in the fourth.  The first transaction is a stand-in for the coinbase
+	// which is never considered to spend anything.
+	newTx := func(prevOut *wire.OutPoint, pkScript []byte) *wire.MsgTx {
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(prevOut, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(1000, pkScript))
+		return tx
+	}
+	fundingTx := newTx(wire.NewOutPoint(&chainhash.Hash{0x01}, 0),
+		pkScript)
+	fundingHash := fundingTx.TxHash()
+	changeFundingTx := newTx(wire.NewOutPoint(&chainhash.Hash{0x02}, 0),
+		pkScript)
+	changeFundingHash := changeFundingTx.TxHash()
+	coinbase := newTx(&wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		otherScript)
+	receiveTx := newTx(wire.NewOutPoint(&chainhash.Hash{0x03}, 0),
+		pkScript)
+	sendTx := newTx(wire.NewOutPoint(&fundingHash, 0), otherScript)
+	bothTx := newTx(wire.NewOutPoint(&changeFundingHash, 0), pkScript)
+	block := ltcutil.NewBlock(&wire.MsgBlock{
+		Transactions: []*wire.MsgTx{coinbase, receiveTx, sendTx, bothTx},
+	})
+	view := blockchain.NewUtxoViewpoint()
+	view.AddTxOuts(ltcutil.NewTx(fundingTx), 1)
+	view.AddTxOuts(ltcutil.NewTx(changeFundingTx), 1)
+
+	idx := NewAddrIndex(nil, params)
+	data := make(writeIndexData)
+	idx.indexBlock(data, block, view)
+	wantIndexed := []indexedTx{
+		{txIdx: 1, txType: AddrTxReceive},
+		{txIdx: 2, txType: AddrTxSend},
+		{txIdx: 3, txType: AddrTxBoth},
+	}
+	if !reflect.DeepEqual(data[addrKey], wantIndexed) {
+		t.Fatalf("indexBlock: got %+v, want %+v", data[addrKey],
+			wantIndexed)
+	}
+
+	// Store the indexed transactions in enough blocks for the entries to
+	// span multiple levels and ensure queries return the transactions of
+	// the requested types.  The ID of each block is used as the first
+	// byte of its hash and the index of each transaction is used as its
+	// offset.
+	bucket := &addrIndexBucket{
+		levels: make(map[[levelKeySize]byte][]byte),
+	}
+	const numBlocks = level0MaxEntries
+	for blockID := uint32(0); blockID < numBlocks; blockID++ {
+		for _, txn := range data[addrKey] {
+			txLoc := wire.TxLoc{TxStart: txn.txIdx}
+			err := dbPutAddrIndexEntry(bucket, addrKey, blockID,
+				txLoc, txn.txType)
+			if err != nil {
+				t.Fatalf("dbPutAddrIndexEntry: unexpected "+
+					"error: %v", err)
+			}
+		}
+	}
+	fetchBlockHash := func(id []byte) (*chainhash.Hash, error) {
+		return &chainhash.Hash{byte(byteOrder.Uint32(id))}, nil
+	}
+	regionFor := func(blockID uint32, txIdx int) database.BlockRegion {
+		return database.BlockRegion{
+			Hash:   &chainhash.Hash{byte(blockID)},
+			Offset: uint32(txIdx),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		txType    AddrTxType
+		numToSkip uint32
+		reverse   bool
+		txIdxs    []int
+	}{
+		{name: "both", txType: AddrTxBoth, txIdxs: []int{1, 2, 3}},
+		{name: "send", txType: AddrTxSend, txIdxs: []int{2, 3}},
+		{name: "receive", txType: AddrTxReceive, txIdxs: []int{1, 3}},
+		{name: "send skip", txType: AddrTxSend, numToSkip: 3,
+			txIdxs: []int{2, 3}},
+		{name: "receive reverse", txType: AddrTxReceive, numToSkip: 1,
+			reverse: true, txIdxs: []int{1, 3}},
+	}
+	for _, test := range tests {
+		// Each block contains the same transactions, so the expected
+		// regions repeat the expected transactions for each block in
+		// the requested order while taking the number to skip into
+		// account.
+		var want []database.BlockRegion
+		for blockID := uint32(0); blockID < numBlocks; blockID++ {
+			for _, txIdx := range test.txIdxs {
+				want = append(want, regionFor(blockID, txIdx))
+			}
+		}
+		if test.reverse {
+			for i, j := 0, len(want)-1; i < j; i, j = i+1, j-1 {
+				want[i], want[j] = want[j], want[i]
+			}
+		}
+		want = want[test.numToSkip:]
+
+		regions, skipped, err := dbFetchAddrIndexEntries(bucket,
+			addrKey, test.txType, test.numToSkip, uint32(len(want)),
+			test.reverse, fetchBlockHash)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if skipped != test.numToSkip {
+			t.Fatalf("%s: skipped %d, want %d", test.name, skipped,
+				test.numToSkip)
+		}
+		if !reflect.DeepEqual(regions, want) {
+			t.Fatalf("%s: got regions %v, want %v", test.name,
+				regions, want)
+		}
+	}
+
+	// Ensure the unconfirmed index also limits the transactions to the
+	// requested types and returns them in the order they were added.
+	for _, tx := range block.Transactions()[1:] {
+		idx.AddUnconfirmedTx(tx, view)
+	}
+	unconfirmedTests := []struct {
+		txType AddrTxType
+		want   []*ltcutil.Tx
+	}{
+		{AddrTxBoth, block.Transactions()[1:]},
+		{AddrTxSend, block.Transactions()[2:]},
+		{AddrTxReceive, []*ltcutil.Tx{block.Transactions()[1],
+			block.Transactions()[3]}},
+	}
+	for _, test := range unconfirmedTests {
+		txns := idx.UnconfirmedTxnsForAddress(addr, test.txType)
+		if !reflect.DeepEqual(txns, test.want) {
+			t.Fatalf("UnconfirmedTxnsForAddress(%d): got %v, want "+
+				"%v", test.txType, txns, test.want)
+		}
+	}
+}
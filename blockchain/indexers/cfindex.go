@@ -6,11 +6,14 @@ package indexers
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/ltcsuite/ltcd/blockchain"
 	"github.com/ltcsuite/ltcd/chaincfg"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
 	"github.com/ltcsuite/ltcutil"
 	"github.com/ltcsuite/ltcutil/gcs"
 	"github.com/ltcsuite/ltcutil/gcs/builder"
@@ -98,6 +101,14 @@ func dbDeleteFilterHeader(dbTx database.Tx, key []byte, h *chainhash.Hash) error
 type CfIndex struct {
 	db          database.DB
 	chainParams *chaincfg.Params
+
+	// verifyFilters enables checking that the basic filter of each block
+	// matches all of the data it commits to before it is stored.
+	verifyFilters bool
+
+	// buildBasicFilter builds the basic filter of a block.  It is only
+	// replaced by tests.
+	buildBasicFilter func(*wire.MsgBlock) (*gcs.Filter, error)
 }
 
 // Ensure the CfIndex type implements the Indexer interface.
@@ -121,6 +132,12 @@ func (idx *CfIndex) Name() string {
 	return cfIndexName
 }
 
+// Tip returns the hash and height of the most recent block which has been
+// indexed. This is part of the Indexer interface.
+func (idx *CfIndex) Tip() (*chainhash.Hash, int32, error) {
+	return fetchIndexerTip(idx.db, cfIndexParentBucketKey)
+}
+
 // Create is invoked when the indexer manager determines the index needs to
 // be created for the first time. It creates buckets for the two hash-based cf
 // indexes (simple, extended).
@@ -207,17 +224,87 @@ func storeFilter(dbTx database.Tx, block *ltcutil.Block, f *gcs.Filter,
 	return dbStoreFilterHeader(dbTx, hkey, h, fh[:])
 }
 
+// verifyBasicFilter ensures the serialized basic filter matches each of the
+// outpoints spent by the block and each of the data pushes of the public key
+// scripts of its outputs, which are the entries the filter commits to.  A nil
+// filter only matches a block without any entries.
+func verifyBasicFilter(block *wire.MsgBlock, f *gcs.Filter) error {
+	var filter *gcs.Filter
+	if f != nil {
+		var err error
+		filter, err = gcs.FromNBytes(builder.DefaultP, f.NBytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	blockHash := block.BlockHash()
+	key := builder.DeriveKey(&blockHash)
+	match := func(entry []byte) error {
+		if filter == nil {
+			return errors.New("filter is missing")
+		}
+		matched, err := filter.Match(key, entry)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return fmt.Errorf("filter does not match entry %x", entry)
+		}
+		return nil
+	}
+
+	for i, tx := range block.Transactions {
+		// The coinbase does not spend any outpoints.
+		if i != 0 {
+			for _, txIn := range tx.TxIn {
+				entry := builder.OutPointToFilterEntry(
+					txIn.PreviousOutPoint)
+				if err := match(entry); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, txOut := range tx.TxOut {
+			pushes, _ := txscript.PushedData(txOut.PkScript)
+			for _, push := range pushes {
+				if err := match(push); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 // ConnectBlock is invoked by the index manager when a new block has been
 // connected to the main chain. This indexer adds a hash-to-cf mapping for
 // every passed block. This is part of the Indexer interface.
+//
+// When filter verification is enabled, an error is returned for a block whose
+// basic filter does not match the data it commits to, so the index is never
+// advanced past it.
 func (idx *CfIndex) ConnectBlock(dbTx database.Tx, block *ltcutil.Block,
 	view *blockchain.UtxoViewpoint) error {
 
-	f, err := builder.BuildBasicFilter(block.MsgBlock())
+	f, err := idx.buildBasicFilter(block.MsgBlock())
 	if err != nil && err != gcs.ErrNoData {
 		return err
 	}
 
+	if idx.verifyFilters {
+		err := verifyBasicFilter(block.MsgBlock(), f)
+		if err != nil {
+			log.Errorf("Basic filter of block %v (height %d) failed "+
+				"verification: %v", block.Hash(), block.Height(),
+				err)
+			return fmt.Errorf("basic filter of block %v failed "+
+				"verification: %v", block.Hash(), err)
+		}
+	}
+
 	if err := storeFilter(dbTx, block, f, false); err != nil {
 		return err
 	}
@@ -232,16 +319,27 @@ func (idx *CfIndex) ConnectBlock(dbTx database.Tx, block *ltcutil.Block,
 
 // DisconnectBlock is invoked by the index manager when a block has been
 // disconnected from the main chain.  This indexer removes the hash-to-cf
-// mapping for every passed block. This is part of the Indexer interface.
+// and hash-to-cf-header mappings for every passed block so the stored filter
+// headers only ever form the chain of the blocks in the main chain. This is
+// part of the Indexer interface.
 func (idx *CfIndex) DisconnectBlock(dbTx database.Tx, block *ltcutil.Block,
 	view *blockchain.UtxoViewpoint) error {
 
-	err := dbDeleteFilter(dbTx, cfBasicIndexKey, block.Hash())
-	if err != nil {
-		return err
+	for _, key := range [][]byte{cfBasicIndexKey, cfExtendedIndexKey} {
+		err := dbDeleteFilter(dbTx, key, block.Hash())
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, key := range [][]byte{cfBasicHeaderKey, cfExtendedHeaderKey} {
+		err := dbDeleteFilterHeader(dbTx, key, block.Hash())
+		if err != nil {
+			return err
+		}
 	}
 
-	return dbDeleteFilter(dbTx, cfExtendedIndexKey, block.Hash())
+	return nil
 }
 
 // FilterByBlockHash returns the serialized contents of a block's basic or
@@ -278,6 +376,30 @@ func (idx *CfIndex) FilterHeaderByBlockHash(h *chainhash.Hash, extended bool) ([
 	return fh, err
 }
 
+// FilterHeadersByBlockHashes returns the serialized contents of the basic or
+// extended committed filter headers of the blocks with the given hashes in the
+// same order.  All of the headers are loaded within a single database
+// transaction so they are consistent with each other.
+func (idx *CfIndex) FilterHeadersByBlockHashes(hashes []*chainhash.Hash, extended bool) ([][]byte, error) {
+	key := cfBasicHeaderKey
+	if extended {
+		key = cfExtendedHeaderKey
+	}
+
+	headers := make([][]byte, 0, len(hashes))
+	err := idx.db.View(func(dbTx database.Tx) error {
+		for _, hash := range hashes {
+			fh, err := dbFetchFilterHeader(dbTx, key, hash)
+			if err != nil {
+				return err
+			}
+			headers = append(headers, fh)
+		}
+		return nil
+	})
+	return headers, err
+}
+
 // NewCfIndex returns a new instance of an indexer that is used to create a
 // mapping of the hashes of all blocks in the blockchain to their respective
 // committed filters.
@@ -285,8 +407,18 @@ func (idx *CfIndex) FilterHeaderByBlockHash(h *chainhash.Hash, extended bool) ([
 // It implements the Indexer interface which plugs into the IndexManager that
 // in turn is used by the blockchain package. This allows the index to be
 // seamlessly maintained along with the chain.
-func NewCfIndex(db database.DB, chainParams *chaincfg.Params) *CfIndex {
-	return &CfIndex{db: db, chainParams: chainParams}
+//
+// When verifyFilters is true, the basic filter of each indexed block is
+// checked to match all of the data it commits to before it is stored.  This
+// is expensive, so it should only be enabled to catch filter construction
+// regressions.
+func NewCfIndex(db database.DB, chainParams *chaincfg.Params, verifyFilters bool) *CfIndex {
+	return &CfIndex{
+		db:               db,
+		chainParams:      chainParams,
+		verifyFilters:    verifyFilters,
+		buildBasicFilter: builder.BuildBasicFilter,
+	}
 }
 
 // DropCfIndex drops the CF index from the provided database if exists.
@@ -0,0 +1,141 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package indexers
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	_ "github.com/ltcsuite/ltcd/database/ffldb"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+	"github.com/ltcsuite/ltcutil/gcs"
+	"github.com/ltcsuite/ltcutil/gcs/builder"
+)
+
+// cfIndexTestBlock returns a block with a coinbase and a transaction spending
+// an output to a pay-to-pubkey-hash script.  It builds on the all-zero hash the
+// index stores the initial filter headers for.
+func cfIndexTestBlock(t *testing.T) *ltcutil.Block {
+	p2pkh, err := txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).AddData(make([]byte, 20)).
+		AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		t.Fatalf("unable to create script: %v", err)
+	}
+
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		SignatureScript:  []byte{txscript.OP_0, txscript.OP_0},
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(wire.NewTxOut(5000000000, p2pkh))
+
+	spend := wire.NewMsgTx(1)
+	spend.AddTxIn(wire.NewTxIn(&wire.OutPoint{
+		Hash:  chainhash.Hash{0x01},
+		Index: 1,
+	}, nil, nil))
+	spend.AddTxOut(wire.NewTxOut(1000, p2pkh))
+	spend.AddTxOut(wire.NewTxOut(0, []byte{txscript.OP_RETURN,
+		txscript.OP_DATA_4, 0x01, 0x02, 0x03, 0x04}))
+
+	var msgBlock wire.MsgBlock
+	msgBlock.Header.Version = 1
+	msgBlock.AddTransaction(coinbase)
+	msgBlock.AddTransaction(spend)
+	block := ltcutil.NewBlock(&msgBlock)
+	block.SetHeight(1)
+	return block
+}
+
+// TestCfIndexVerify ensures that filter verification accepts the filters built
+// for a block and refuses to index a block whose basic filter was built
+// incorrectly, while the filter is indexed as is when verification is
+// disabled.
+func TestCfIndexVerify(t *testing.T) {
+	t.Parallel()
+
+	dbPath, err := ioutil.TempDir("", "cfindexverify")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dbPath)
+	db, err := database.Create("ffldb", filepath.Join(dbPath, "db"),
+		wire.TestNet)
+	if err != nil {
+		t.Fatalf("unable to create db: %v", err)
+	}
+	defer db.Close()
+
+	params := &chaincfg.RegressionNetParams
+	idx := NewCfIndex(db, params, true)
+	if err := db.Update(idx.Create); err != nil {
+		t.Fatalf("Create: unexpected error: %v", err)
+	}
+
+	// The corrupted builder omits the entries of all but the coinbase,
+	// which keeps the key derived from the block hash the same.
+	block := cfIndexTestBlock(t)
+	corruptBuilder := func(msgBlock *wire.MsgBlock) (*gcs.Filter, error) {
+		corrupted := *msgBlock
+		corrupted.Transactions = msgBlock.Transactions[:1]
+		return builder.BuildBasicFilter(&corrupted)
+	}
+	connect := func() error {
+		return db.Update(func(dbTx database.Tx) error {
+			return idx.ConnectBlock(dbTx, block, nil)
+		})
+	}
+	filter := func() []byte {
+		f, err := idx.FilterByBlockHash(block.Hash(), false)
+		if err != nil {
+			t.Fatalf("FilterByBlockHash: unexpected error: %v", err)
+		}
+		return f
+	}
+
+	// The corrupted filter must trip the verification without indexing
+	// the block.
+	idx.buildBasicFilter = corruptBuilder
+	if err := connect(); err == nil {
+		t.Fatal("ConnectBlock: unexpected success for corrupted filter")
+	}
+	if f := filter(); f != nil {
+		t.Fatalf("got filter %x for rejected block, want none", f)
+	}
+
+	// Without verification, the corrupted filter is indexed as is.
+	idx.verifyFilters = false
+	if err := connect(); err != nil {
+		t.Fatalf("ConnectBlock: unexpected error without verification: "+
+			"%v", err)
+	}
+	if f := filter(); f == nil {
+		t.Fatal("no filter indexed without verification")
+	}
+
+	// The correctly built filter must pass the verification.
+	idx.verifyFilters = true
+	idx.buildBasicFilter = builder.BuildBasicFilter
+	if err := connect(); err != nil {
+		t.Fatalf("ConnectBlock: unexpected error: %v", err)
+	}
+	want, err := builder.BuildBasicFilter(block.MsgBlock())
+	if err != nil {
+		t.Fatalf("BuildBasicFilter: unexpected error: %v", err)
+	}
+	if f := filter(); string(f) != string(want.NBytes()) {
+		t.Fatalf("got filter %x, want %x", f, want.NBytes())
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"encoding/binary"
 
 	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 	"github.com/ltcsuite/ltcd/database"
 	"github.com/ltcsuite/ltcutil"
 )
@@ -27,6 +28,13 @@ type NeedsInputser interface {
 	NeedsInputs() bool
 }
 
+// NeedsBlockDataer provides a generic interface for an indexer to specify that
+// its entries reference the stored block data, which means it can't be used
+// when blocks are pruned from the database.
+type NeedsBlockDataer interface {
+	NeedsBlockData() bool
+}
+
 // Indexer provides a generic interface for an indexer that is managed by an
 // index manager such as the Manager type provided by this package.
 type Indexer interface {
@@ -52,6 +60,10 @@ type Indexer interface {
 	// DisconnectBlock is invoked when the index manager is notified that a
 	// block has been disconnected from the main chain.
 	DisconnectBlock(dbTx database.Tx, block *ltcutil.Block, view *blockchain.UtxoViewpoint) error
+
+	// Tip returns the hash and height of the most recent block which has
+	// been indexed.  A height of -1 indicates no blocks have been indexed.
+	Tip() (*chainhash.Hash, int32, error)
 }
 
 // AssertError identifies an error that indicates an internal code consistency
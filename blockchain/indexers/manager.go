@@ -7,6 +7,8 @@ package indexers
 import (
 	"bytes"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/ltcsuite/ltcd/blockchain"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
@@ -15,6 +17,13 @@ import (
 	"github.com/ltcsuite/ltcutil"
 )
 
+const (
+	// catchUpRetryInterval is the interval at which the background catch-up
+	// of the indexes retries when the next block to index is not available
+	// yet because the chain is in the middle of connecting it.
+	catchUpRetryInterval = 100 * time.Millisecond
+)
+
 var (
 	// indexTipsBucketName is the name of the db bucket used to house the
 	// current tip of each index.
@@ -64,6 +73,19 @@ func dbFetchIndexerTip(dbTx database.Tx, idxKey []byte) (*chainhash.Hash, int32,
 	return &hash, height, nil
 }
 
+// fetchIndexerTip retrieves the hash and height of the current tip for the
+// provided index from the database.
+func fetchIndexerTip(db database.DB, idxKey []byte) (*chainhash.Hash, int32, error) {
+	var hash *chainhash.Hash
+	var height int32
+	err := db.View(func(dbTx database.Tx) error {
+		var err error
+		hash, height, err = dbFetchIndexerTip(dbTx, idxKey)
+		return err
+	})
+	return hash, height, err
+}
+
 // dbIndexConnectBlock adds all of the index entries associated with the
 // given block using the provided indexer and updates the tip of the indexer
 // accordingly.  An error will be returned if the current tip for the indexer is
@@ -125,9 +147,32 @@ func dbIndexDisconnectBlock(dbTx database.Tx, indexer Indexer, block *ltcutil.Bl
 // Manager defines an index manager that manages multiple optional indexes and
 // implements the blockchain.IndexManager interface so it can be seamlessly
 // plugged into normal chain processing.
+//
+// Indexes which are behind the main chain when the manager is initialized are
+// caught up in the background while the chain continues to process blocks.
+// Such an index is only updated with connected blocks once its tip reaches the
+// parent of the block being connected, after which it is kept current like any
+// other index.
 type Manager struct {
 	db             database.DB
 	enabledIndexes []Indexer
+
+	// The following fields are protected by the mutex.  The catchingUp map
+	// houses the indexes which are still being caught up in the background
+	// while tipHash and tipHeight track the main chain tip as reported by
+	// the connected and disconnected blocks.
+	//
+	// The mutex must only be acquired while a database write transaction
+	// is held since the chain invokes the manager from within its own
+	// write transactions.
+	mtx        sync.Mutex
+	catchingUp map[Indexer]struct{}
+	tipHash    chainhash.Hash
+	tipHeight  int32
+
+	caughtUp chan struct{}
+	quit     chan struct{}
+	wg       sync.WaitGroup
 }
 
 // Ensure the Manager type implements the blockchain.IndexManager interface.
@@ -218,16 +263,17 @@ func (m *Manager) maybeCreateIndexes(dbTx database.Tx) error {
 }
 
 // Init initializes the enabled indexes.  This is called during chain
-// initialization and primarily consists of catching up all indexes to the
-// current best chain tip.  This is necessary since each index can be disabled
-// and re-enabled at any time and attempting to catch-up indexes at the same
-// time new blocks are being downloaded would lead to an overall longer time to
-// catch up due to the I/O contention.
+// initialization and consists of preparing the indexes and starting to catch
+// up any of them which are behind the current best chain tip in the
+// background.  This is necessary since each index can be disabled and
+// re-enabled at any time, and catching up in the background allows the node
+// to continue normal operation while a new index is built.
 //
 // This is part of the blockchain.IndexManager interface.
 func (m *Manager) Init(chain *blockchain.BlockChain) error {
 	// Nothing to do when no indexes are enabled.
 	if len(m.enabledIndexes) == 0 {
+		close(m.caughtUp)
 		return nil
 	}
 
@@ -236,6 +282,17 @@ func (m *Manager) Init(chain *blockchain.BlockChain) error {
 		return err
 	}
 
+	// Indexes which reference the stored block data can't be used when
+	// blocks are pruned from the database.
+	if chain.PruneEnabled() || chain.PruneHeight() >= 0 {
+		for _, indexer := range m.enabledIndexes {
+			if indexNeedsBlockData(indexer) {
+				return fmt.Errorf("the %s can't be used with a "+
+					"pruned block database", indexer.Name())
+			}
+		}
+	}
+
 	// Create the initial state for the indexes as needed.
 	err := m.db.Update(func(dbTx database.Tx) error {
 		// Create the bucket for the current tips as needed.
@@ -341,14 +398,13 @@ func (m *Manager) Init(chain *blockchain.BlockChain) error {
 	}
 
 	// Fetch the current tip heights for each index along with tracking the
-	// lowest one so the catchup code only needs to start at the earliest
-	// block and is able to skip connecting the block for the indexes that
-	// don't need it.
-	bestHeight := chain.BestSnapshot().Height
-	lowestHeight := bestHeight
-	indexerHeights := make([]int32, len(m.enabledIndexes))
+	// lowest one and the indexes which are behind the best chain tip and
+	// thus need to be caught up.
+	best := chain.BestSnapshot()
+	lowestHeight := best.Height
+	catchingUp := make(map[Indexer]struct{})
 	err = m.db.View(func(dbTx database.Tx) error {
-		for i, indexer := range m.enabledIndexes {
+		for _, indexer := range m.enabledIndexes {
 			idxKey := indexer.Key()
 			hash, height, err := dbFetchIndexerTip(dbTx, idxKey)
 			if err != nil {
@@ -357,10 +413,12 @@ func (m *Manager) Init(chain *blockchain.BlockChain) error {
 
 			log.Debugf("Current %s tip (height %d, hash %v)",
 				indexer.Name(), height, hash)
-			indexerHeights[i] = height
 			if height < lowestHeight {
 				lowestHeight = height
 			}
+			if !hash.IsEqual(&best.Hash) {
+				catchingUp[indexer] = struct{}{}
+			}
 		}
 		return nil
 	})
@@ -368,63 +426,199 @@ func (m *Manager) Init(chain *blockchain.BlockChain) error {
 		return err
 	}
 
+	// The chain does not process any blocks until it is initialized, so
+	// this is the tip the connected and disconnected blocks build on.
+	m.tipHash = best.Hash
+	m.tipHeight = best.Height
+	m.catchingUp = catchingUp
+
 	// Nothing to index if all of the indexes are caught up.
-	if lowestHeight == bestHeight {
+	if len(catchingUp) == 0 {
+		close(m.caughtUp)
 		return nil
 	}
 
-	// Create a progress logger for the indexing process below.
-	progressLogger := newBlockProgressLogger("Indexed", log)
+	// The blocks needed to catch up the indexes must not have been pruned.
+	if pruneHeight := chain.PruneHeight(); lowestHeight < pruneHeight {
+		return fmt.Errorf("unable to catch up indexes from height %d "+
+			"since the blocks through height %d have been pruned",
+			lowestHeight, pruneHeight)
+	}
 
 	// At this point, one or more indexes are behind the current best chain
-	// tip and need to be caught up, so log the details and loop through
-	// each block that needs to be indexed.
-	log.Infof("Catching up indexes from height %d to %d", lowestHeight,
-		bestHeight)
-	for height := lowestHeight + 1; height <= bestHeight; height++ {
-		// Load the block for the height since it is required to index
-		// it.
-		block, err := chain.BlockByHeight(height)
+	// tip and need to be caught up, so log the details and start catching
+	// them up in the background.
+	log.Infof("Catching up indexes from height %d to %d in the background",
+		lowestHeight, best.Height)
+	m.wg.Add(1)
+	go m.catchUpHandler(chain)
+	return nil
+}
+
+// markCaughtUp removes the passed index, which has caught up to the provided
+// height, from the indexes which are being caught up and signals when all of
+// them are caught up.
+//
+// This function MUST be called with the manager lock held.
+func (m *Manager) markCaughtUp(indexer Indexer, height int32) {
+	delete(m.catchingUp, indexer)
+	log.Infof("Caught up %s to height %d", indexer.Name(), height)
+	if len(m.catchingUp) == 0 {
+		close(m.caughtUp)
+	}
+}
+
+// catchUpBlock connects the next block to the indexes which are being caught up
+// and are furthest behind.  The indexes which have reached the main chain tip
+// are marked caught up instead.  It returns the connected block, which is nil
+// when the next block is not available yet, along with whether or not all of
+// the indexes are caught up.
+func (m *Manager) catchUpBlock(chain *blockchain.BlockChain) (*ltcutil.Block, bool, error) {
+	var block *ltcutil.Block
+	var done bool
+	err := m.db.Update(func(dbTx database.Tx) error {
+		m.mtx.Lock()
+		defer m.mtx.Unlock()
+
+		// Fetch the current tip heights of the indexes which are being
+		// caught up while tracking the lowest one.
+		lowestHeight := m.tipHeight
+		indexerHeights := make(map[Indexer]int32, len(m.catchingUp))
+		for _, indexer := range m.enabledIndexes {
+			if _, ok := m.catchingUp[indexer]; !ok {
+				continue
+			}
+
+			hash, height, err := dbFetchIndexerTip(dbTx, indexer.Key())
+			if err != nil {
+				return err
+			}
+			if hash.IsEqual(&m.tipHash) {
+				m.markCaughtUp(indexer, height)
+				continue
+			}
+			indexerHeights[indexer] = height
+			if height < lowestHeight {
+				lowestHeight = height
+			}
+		}
+		if len(m.catchingUp) == 0 {
+			done = true
+			return nil
+		}
+
+		// The chain only updates its view of the main chain once the
+		// transaction which connects or disconnects a block has been
+		// committed, so the next block might not be available yet.
+		// Only blocks the manager has been notified about are indexed
+		// to avoid indexing a block which is being disconnected.
+		height := lowestHeight + 1
+		if height > m.tipHeight {
+			return nil
+		}
+		hash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			return nil
+		}
+		blockBytes, err := dbTx.FetchBlock(hash)
+		if err != nil {
+			return err
+		}
+		block, err = ltcutil.NewBlockFromBytes(blockBytes)
 		if err != nil {
 			return err
 		}
+		block.SetHeight(height)
 
 		// Connect the block for all indexes that need it.
 		var view *blockchain.UtxoViewpoint
-		for i, indexer := range m.enabledIndexes {
+		for _, indexer := range m.enabledIndexes {
 			// Skip indexes that don't need to be updated with this
 			// block.
-			if indexerHeights[i] >= height {
+			indexerHeight, ok := indexerHeights[indexer]
+			if !ok || indexerHeight != lowestHeight {
 				continue
 			}
 
-			err := m.db.Update(func(dbTx database.Tx) error {
-				// When the index requires all of the referenced
-				// txouts and they haven't been loaded yet, they
-				// need to be retrieved from the transaction
-				// index.
-				if view == nil && indexNeedsInputs(indexer) {
-					var err error
-					view, err = makeUtxoView(dbTx, block)
-					if err != nil {
-						return err
-					}
+			// When the index requires all of the referenced txouts
+			// and they haven't been loaded yet, they need to be
+			// retrieved from the transaction index.
+			if view == nil && indexNeedsInputs(indexer) {
+				view, err = makeUtxoView(dbTx, block)
+				if err != nil {
+					return err
 				}
-				return dbIndexConnectBlock(dbTx, indexer, block,
-					view)
-			})
+			}
+			err := dbIndexConnectBlock(dbTx, indexer, block, view)
 			if err != nil {
 				return err
 			}
-			indexerHeights[i] = height
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return block, done, nil
+}
+
+// catchUpHandler catches up the indexes which are behind the main chain tip by
+// indexing the blocks they are missing one at a time until they are caught up.
+// Each block is indexed in its own database transaction so the chain is able
+// to continue processing blocks in the mean time.
+//
+// It must be run as a goroutine.
+func (m *Manager) catchUpHandler(chain *blockchain.BlockChain) {
+	defer m.wg.Done()
+
+	progressLogger := newBlockProgressLogger("Indexed", log)
+	for {
+		block, done, err := m.catchUpBlock(chain)
+		if err != nil {
+			log.Errorf("Unable to catch up indexes: %v", err)
+			return
+		}
+		if done {
+			log.Infof("Indexes caught up")
+			return
+		}
+
+		// Wait a bit before trying again when the next block is not
+		// available yet.
+		if block == nil {
+			select {
+			case <-m.quit:
+				return
+			case <-time.After(catchUpRetryInterval):
+			}
+			continue
 		}
 
 		// Log indexing progress.
 		progressLogger.LogBlockHeight(block)
+
+		select {
+		case <-m.quit:
+			return
+		default:
+		}
 	}
+}
 
-	log.Infof("Indexes caught up to height %d", bestHeight)
-	return nil
+// CaughtUp returns a channel which is closed once all of the enabled indexes
+// have caught up to the main chain tip.
+//
+// This function is safe for concurrent access.
+func (m *Manager) CaughtUp() <-chan struct{} {
+	return m.caughtUp
+}
+
+// Stop stops catching up the indexes in the background and waits for it to
+// finish.  Indexes which are not caught up yet resume catching up from where
+// they left off the next time the manager is initialized.
+func (m *Manager) Stop() {
+	close(m.quit)
+	m.wg.Wait()
 }
 
 // indexNeedsInputs returns whether or not the index needs access to the txouts
@@ -437,6 +631,16 @@ func indexNeedsInputs(index Indexer) bool {
 	return false
 }
 
+// indexNeedsBlockData returns whether or not the index references the stored
+// block data.
+func indexNeedsBlockData(index Indexer) bool {
+	if idx, ok := index.(NeedsBlockDataer); ok {
+		return idx.NeedsBlockData()
+	}
+
+	return false
+}
+
 // dbFetchTx looks up the passed transaction hash in the transaction index and
 // loads it from the database.
 func dbFetchTx(dbTx database.Tx, hash *chainhash.Hash) (*wire.MsgTx, error) {
@@ -503,14 +707,37 @@ func makeUtxoView(dbTx database.Tx, block *ltcutil.Block) (*blockchain.UtxoViewp
 //
 // This is part of the blockchain.IndexManager interface.
 func (m *Manager) ConnectBlock(dbTx database.Tx, block *ltcutil.Block, view *blockchain.UtxoViewpoint) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
 	// Call each of the currently active optional indexes with the block
 	// being connected so they can update accordingly.
 	for _, index := range m.enabledIndexes {
+		// Indexes which are being caught up are only updated once the
+		// block extends their tip, at which point they are caught up.
+		_, catchingUp := m.catchingUp[index]
+		if catchingUp {
+			tipHash, _, err := dbFetchIndexerTip(dbTx, index.Key())
+			if err != nil {
+				return err
+			}
+			prevHash := &block.MsgBlock().Header.PrevBlock
+			if !tipHash.IsEqual(prevHash) {
+				continue
+			}
+		}
+
 		err := dbIndexConnectBlock(dbTx, index, block, view)
 		if err != nil {
 			return err
 		}
+		if catchingUp {
+			m.markCaughtUp(index, block.Height())
+		}
 	}
+
+	m.tipHash = *block.Hash()
+	m.tipHeight = block.Height()
 	return nil
 }
 
@@ -521,17 +748,62 @@ func (m *Manager) ConnectBlock(dbTx database.Tx, block *ltcutil.Block, view *blo
 //
 // This is part of the blockchain.IndexManager interface.
 func (m *Manager) DisconnectBlock(dbTx database.Tx, block *ltcutil.Block, view *blockchain.UtxoViewpoint) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
 	// Call each of the currently active optional indexes with the block
 	// being disconnected so they can update accordingly.
 	for _, index := range m.enabledIndexes {
+		// Indexes which are being caught up only need to be updated
+		// when they have already indexed the block.
+		if _, ok := m.catchingUp[index]; ok {
+			tipHash, _, err := dbFetchIndexerTip(dbTx, index.Key())
+			if err != nil {
+				return err
+			}
+			if !tipHash.IsEqual(block.Hash()) {
+				continue
+			}
+		}
+
 		err := dbIndexDisconnectBlock(dbTx, index, block, view)
 		if err != nil {
 			return err
 		}
 	}
+
+	m.tipHash = block.MsgBlock().Header.PrevBlock
+	m.tipHeight = block.Height() - 1
 	return nil
 }
 
+// CatchUpHeight returns the lowest tip height of the indexes which are still
+// being caught up in the background, or the height of the main chain tip when
+// all of them are caught up.  The blocks after the returned height are needed
+// to catch up the indexes and thus must not be pruned.
+//
+// This is part of the blockchain.IndexManager interface.
+func (m *Manager) CatchUpHeight(dbTx database.Tx) (int32, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	lowestHeight := m.tipHeight
+	for _, indexer := range m.enabledIndexes {
+		if _, ok := m.catchingUp[indexer]; !ok {
+			continue
+		}
+
+		_, height, err := dbFetchIndexerTip(dbTx, indexer.Key())
+		if err != nil {
+			return 0, err
+		}
+		if height < lowestHeight {
+			lowestHeight = height
+		}
+	}
+	return lowestHeight, nil
+}
+
 // NewManager returns a new index manager with the provided indexes enabled.
 //
 // The manager returned satisfies the blockchain.IndexManager interface and thus
@@ -540,6 +812,8 @@ func NewManager(db database.DB, enabledIndexes []Indexer) *Manager {
 	return &Manager{
 		db:             db,
 		enabledIndexes: enabledIndexes,
+		caughtUp:       make(chan struct{}),
+		quit:           make(chan struct{}),
 	}
 }
 
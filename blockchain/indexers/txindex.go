@@ -289,6 +289,9 @@ type TxIndex struct {
 // Ensure the TxIndex type implements the Indexer interface.
 var _ Indexer = (*TxIndex)(nil)
 
+// Ensure the TxIndex type implements the NeedsBlockDataer interface.
+var _ NeedsBlockDataer = (*TxIndex)(nil)
+
 // Init initializes the hash-based transaction index.  In particular, it finds
 // the highest used block ID and stores it for later use when connecting or
 // disconnecting blocks.
@@ -366,6 +369,22 @@ func (idx *TxIndex) Name() string {
 	return txIndexName
 }
 
+// Tip returns the hash and height of the most recent block which has been
+// indexed.
+//
+// This is part of the Indexer interface.
+func (idx *TxIndex) Tip() (*chainhash.Hash, int32, error) {
+	return fetchIndexerTip(idx.db, txIndexKey)
+}
+
+// NeedsBlockData signals that the index references the stored block data to
+// locate each transaction.
+//
+// This implements the NeedsBlockDataer interface.
+func (idx *TxIndex) NeedsBlockData() bool {
+	return true
+}
+
 // Create is invoked when the indexer manager determines the index needs
 // to be created for the first time.  It creates the buckets for the hash-based
 // transaction index and the internal block ID indexes.
@@ -0,0 +1,204 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// markInvalid marks the passed node as having failed validation and all of its
+// descendants as having an invalid ancestor.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) markInvalid(node *blockNode) {
+	b.index.SetStatusFlags(node, statusValidateFailed)
+	for _, n := range b.index.Descendants(node) {
+		b.index.SetStatusFlags(n, statusInvalidAncestor)
+	}
+}
+
+// bestValidNode returns the node in the block index with the most cumulative
+// work which is not known to be invalid.  Nodes in the main chain are preferred
+// over nodes with the same amount of work on side chains.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) bestValidNode() *blockNode {
+	b.index.RLock()
+	defer b.index.RUnlock()
+
+	var best *blockNode
+	for _, node := range b.index.index {
+		if node.status.KnownInvalid() {
+			continue
+		}
+		if best == nil {
+			best = node
+			continue
+		}
+		cmp := node.workSum.Cmp(best.workSum)
+		if cmp > 0 || (cmp == 0 && b.bestChain.Contains(node)) {
+			best = node
+		}
+	}
+	return best
+}
+
+// activateBestChain reorganizes the chain so the valid chain with the most
+// cumulative work becomes the main chain.  A chain which fails to connect due
+// to a rule violation is marked invalid, in which case the next best chain is
+// tried.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) activateBestChain() error {
+	for {
+		node := b.bestValidNode()
+		if node == nil || node == b.bestChain.Tip() {
+			return nil
+		}
+
+		detachNodes, attachNodes := b.getReorganizeNodes(node)
+		err := b.reorganizeChain(detachNodes, attachNodes, BFNone)
+		if err == nil {
+			return nil
+		}
+
+		// Try the next best chain when the block which violated the
+		// rules was marked invalid.  Otherwise, the failure is not
+		// related to the validity of the chain.
+		if !b.index.NodeStatus(node).KnownInvalid() {
+			return err
+		}
+		log.Infof("Unable to activate chain ending at block %v: %v",
+			node.hash, err)
+	}
+}
+
+// InvalidateBlock marks the block with the passed hash and all of its
+// descendants as invalid.  When the block is part of the main chain, the chain
+// is reorganized to the valid chain with the most cumulative work.  The block
+// remains invalid across restarts until it is reconsidered with
+// ReconsiderBlock.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) InvalidateBlock(hash *chainhash.Hash) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return fmt.Errorf("block %v is not known", hash)
+	}
+	if node.parent == nil {
+		return fmt.Errorf("the genesis block can not be invalidated")
+	}
+
+	err := b.db.Update(func(dbTx database.Tx) error {
+		return dbPutInvalidBlock(dbTx, hash)
+	})
+	if err != nil {
+		return err
+	}
+	b.markInvalid(node)
+
+	// Nothing more to do when the block is not part of the main chain.
+	if !b.bestChain.Contains(node) {
+		return nil
+	}
+
+	log.Infof("Block %v invalidated at height %d, reorganizing to the "+
+		"best valid chain", hash, node.height)
+	return b.activateBestChain()
+}
+
+// ReconsiderBlock removes the invalid status of the block with the passed hash
+// along with its ancestors and descendants and reorganizes the chain to the
+// valid chain with the most cumulative work, which might include the
+// reconsidered blocks.
+//
+// Blocks which were invalidated prior to a restart are loaded from the
+// database as long as their parent is known.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) ReconsiderBlock(hash *chainhash.Hash) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil {
+		return b.reconsiderStoredBlock(hash)
+	}
+
+	// Clear the invalid status of the block, its ancestors, and its
+	// descendants while removing the invalidated mark of those which were
+	// invalidated.
+	var nodes []*blockNode
+	for n := node; n != nil; n = n.parent {
+		nodes = append(nodes, n)
+	}
+	nodes = append(nodes, b.index.Descendants(node)...)
+	err := b.db.Update(func(dbTx database.Tx) error {
+		for _, n := range nodes {
+			if b.index.NodeStatus(n)&statusValidateFailed == 0 {
+				continue
+			}
+			if err := dbRemoveInvalidBlock(dbTx, &n.hash); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		b.index.UnsetStatusFlags(n, statusValidateFailed|
+			statusInvalidAncestor)
+	}
+
+	return b.activateBestChain()
+}
+
+// reconsiderStoredBlock removes the invalidated mark of the block with the
+// passed hash, which is not part of the block index, and processes the block
+// again after loading it from the database.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) reconsiderStoredBlock(hash *chainhash.Hash) error {
+	var block *ltcutil.Block
+	err := b.db.View(func(dbTx database.Tx) error {
+		if !dbIsInvalidBlock(dbTx, hash) {
+			return fmt.Errorf("block %v is not known", hash)
+		}
+		blockBytes, err := dbTx.FetchBlock(hash)
+		if err != nil {
+			return err
+		}
+		block, err = ltcutil.NewBlockFromBytes(blockBytes)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	prevHash := &block.MsgBlock().Header.PrevBlock
+	if !b.index.HaveBlock(prevHash) {
+		return fmt.Errorf("previous block %v of block %v is not known",
+			prevHash, hash)
+	}
+
+	err = b.db.Update(func(dbTx database.Tx) error {
+		return dbRemoveInvalidBlock(dbTx, hash)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = b.maybeAcceptBlock(block, BFNone)
+	return err
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/database"
+)
+
+// TestInvalidateReconsiderBlock ensures invalidating the tip of the main chain
+// rolls the chain back one block, that the block remains invalid across
+// restarts, and that reconsidering it makes it the tip again.
+func TestInvalidateReconsiderBlock(t *testing.T) {
+	dbPath := filepath.Join(os.TempDir(), "invalidatetest")
+	_ = os.RemoveAll(dbPath)
+	db, err := database.Create(testDbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Fatalf("unable to create db: %v", err)
+	}
+	defer os.RemoveAll(dbPath)
+	defer func() { db.Close() }()
+
+	paramsCopy := chaincfg.RegressionNetParams
+	newChain := func() *BlockChain {
+		chain, err := New(&Config{
+			DB:          db,
+			ChainParams: &paramsCopy,
+			TimeSource:  NewMedianTime(),
+		})
+		if err != nil {
+			t.Fatalf("failed to create chain instance: %v", err)
+		}
+		return chain
+	}
+	chain := newChain()
+	for i := 0; i < 5; i++ {
+		addPruneTestBlock(t, chain)
+	}
+	tip := chain.BestSnapshot()
+	tipBlock, err := chain.BlockByHash(&tip.Hash)
+	if err != nil {
+		t.Fatalf("BlockByHash: %v", err)
+	}
+	parentHash := tipBlock.MsgBlock().Header.PrevBlock
+
+	// checkTip ensures the tip of the main chain is the expected block.
+	checkTip := func(chain *BlockChain, wantHeight int32, wantHash string) {
+		best := chain.BestSnapshot()
+		if best.Height != wantHeight || best.Hash.String() != wantHash {
+			t.Fatalf("unexpected tip: got %v (height %d), want %v "+
+				"(height %d)", best.Hash, best.Height, wantHash,
+				wantHeight)
+		}
+	}
+
+	// Invalidating the tip must roll the chain back one block.
+	if err := chain.InvalidateBlock(&tip.Hash); err != nil {
+		t.Fatalf("InvalidateBlock: %v", err)
+	}
+	checkTip(chain, tip.Height-1, parentHash.String())
+	if chain.MainChainHasBlock(&tip.Hash) {
+		t.Fatal("MainChainHasBlock: invalidated block in main chain")
+	}
+
+	// Reconsidering the block must make it the tip again.
+	if err := chain.ReconsiderBlock(&tip.Hash); err != nil {
+		t.Fatalf("ReconsiderBlock: %v", err)
+	}
+	checkTip(chain, tip.Height, tip.Hash.String())
+
+	// Invalidating a block deeper in the main chain must also invalidate
+	// its descendants.
+	deepHash, err := chain.BlockHashByHeight(tip.Height - 2)
+	if err != nil {
+		t.Fatalf("BlockHashByHeight: %v", err)
+	}
+	if err := chain.InvalidateBlock(deepHash); err != nil {
+		t.Fatalf("InvalidateBlock: %v", err)
+	}
+	forkHash, err := chain.BlockHashByHeight(tip.Height - 3)
+	if err != nil {
+		t.Fatalf("BlockHashByHeight: %v", err)
+	}
+	checkTip(chain, tip.Height-3, forkHash.String())
+	if err := chain.ReconsiderBlock(deepHash); err != nil {
+		t.Fatalf("ReconsiderBlock: %v", err)
+	}
+	checkTip(chain, tip.Height, tip.Hash.String())
+
+	// The invalidated block must remain invalid after a restart, both
+	// when it is processed again and when it is reconsidered without
+	// being seen again.
+	if err := chain.InvalidateBlock(&tip.Hash); err != nil {
+		t.Fatalf("InvalidateBlock: %v", err)
+	}
+	chain = newChain()
+	checkTip(chain, tip.Height-1, parentHash.String())
+	_, _, err = chain.ProcessBlock(tipBlock, BFNoPoWCheck)
+	if rerr, ok := err.(RuleError); !ok || rerr.ErrorCode != ErrKnownInvalidBlock {
+		t.Fatalf("ProcessBlock: got error %v, want %v", err,
+			ErrKnownInvalidBlock)
+	}
+	checkTip(chain, tip.Height-1, parentHash.String())
+
+	chain = newChain()
+	if err := chain.ReconsiderBlock(&tip.Hash); err != nil {
+		t.Fatalf("ReconsiderBlock: %v", err)
+	}
+	checkTip(chain, tip.Height, tip.Hash.String())
+
+	// The block must no longer be invalid after a restart once it has been
+	// reconsidered.
+	chain = newChain()
+	checkTip(chain, tip.Height, tip.Hash.String())
+
+	// The genesis block can't be invalidated.
+	genesisHash := paramsCopy.GenesisHash
+	if err := chain.InvalidateBlock(genesisHash); err == nil {
+		t.Fatal("InvalidateBlock: genesis block invalidated")
+	}
+}
@@ -224,9 +224,9 @@ func (b *BlockChain) ProcessBlock(block *ltcutil.Block, flags BehaviorFlags) (bo
 	}
 	if !prevHashExists {
 		if !dryRun {
-			log.Infof("Adding orphan block %v with parent %v",
-				blockHash, prevHash)
 			b.addOrphanBlock(block)
+			log.Infof("Added orphan block %v with parent %v (%d "+
+				"orphans)", blockHash, prevHash, b.NumOrphans())
 		}
 
 		return false, true, nil
@@ -0,0 +1,147 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// TestProcessOrphans ensures blocks delivered before their parents are held in
+// the orphan pool and that the chain assembles in order once the missing
+// parent arrives.
+func TestProcessOrphans(t *testing.T) {
+	chain, teardownFunc, err := chainSetup("processorphans",
+		&chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+
+	// Create a chain of blocks extending the genesis block.
+	const numBlocks = 5
+	blocks := make([]*ltcutil.Block, numBlocks)
+	prevHash := chain.chainParams.GenesisHash
+	for i := range blocks {
+		blocks[i] = newCoinbaseOnlyBlock(t, chain.chainParams,
+			prevHash, int32(i+1))
+		prevHash = blocks[i].Hash()
+	}
+
+	// Deliver every block but the first one, children before parents.
+	for i := numBlocks - 1; i > 0; i-- {
+		isMainChain, isOrphan, err := chain.ProcessBlock(blocks[i],
+			BFNoPoWCheck)
+		if err != nil {
+			t.Fatalf("ProcessBlock %d: unexpected error: %v", i+1,
+				err)
+		}
+		if isMainChain || !isOrphan {
+			t.Fatalf("ProcessBlock %d: block is not an orphan", i+1)
+		}
+		if !chain.IsKnownOrphan(blocks[i].Hash()) {
+			t.Fatalf("IsKnownOrphan %d: block is not known", i+1)
+		}
+		if n := chain.NumOrphans(); n != numBlocks-i {
+			t.Fatalf("NumOrphans: got %d, want %d", n, numBlocks-i)
+		}
+	}
+	root := chain.GetOrphanRoot(blocks[numBlocks-1].Hash())
+	if !root.IsEqual(blocks[1].Hash()) {
+		t.Fatalf("GetOrphanRoot: got %v, want %v", root,
+			blocks[1].Hash())
+	}
+
+	// Delivering the missing parent connects all of the orphans.
+	isMainChain, isOrphan, err := chain.ProcessBlock(blocks[0], BFNoPoWCheck)
+	if err != nil {
+		t.Fatalf("ProcessBlock 1: unexpected error: %v", err)
+	}
+	if !isMainChain || isOrphan {
+		t.Fatal("ProcessBlock 1: block did not extend the main chain")
+	}
+	if n := chain.NumOrphans(); n != 0 {
+		t.Fatalf("NumOrphans: got %d after connecting, want 0", n)
+	}
+	best := chain.BestSnapshot()
+	if best.Height != numBlocks || !best.Hash.IsEqual(prevHash) {
+		t.Fatalf("best block: got %v (height %d), want %v (height %d)",
+			best.Hash, best.Height, prevHash, numBlocks)
+	}
+	for i, block := range blocks {
+		hash, err := chain.BlockHashByHeight(int32(i + 1))
+		if err != nil || !hash.IsEqual(block.Hash()) {
+			t.Fatalf("BlockHashByHeight %d: got %v (err %v), want "+
+				"%v", i+1, hash, err, block.Hash())
+		}
+	}
+}
+
+// TestOrphanEviction ensures the orphan pool is limited to the configured
+// number of blocks by evicting the oldest received orphans, and that orphans
+// which were connected are no longer considered for eviction.
+func TestOrphanEviction(t *testing.T) {
+	chain, teardownFunc, err := chainSetup("orphaneviction",
+		&chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.maxOrphans = 2
+
+	const numBlocks = 6
+	blocks := make([]*ltcutil.Block, numBlocks)
+	prevHash := chain.chainParams.GenesisHash
+	for i := range blocks {
+		blocks[i] = newCoinbaseOnlyBlock(t, chain.chainParams,
+			prevHash, int32(i+1))
+		prevHash = blocks[i].Hash()
+	}
+	process := func(i int) {
+		if _, _, err := chain.ProcessBlock(blocks[i], BFNoPoWCheck); err != nil {
+			t.Fatalf("ProcessBlock %d: unexpected error: %v", i+1,
+				err)
+		}
+	}
+
+	// Block 2 becomes an orphan and is connected along with block 1,
+	// which must not leave it behind as the oldest orphan.
+	process(1)
+	process(0)
+	if n := chain.NumOrphans(); n != 0 {
+		t.Fatalf("NumOrphans: got %d after connecting, want 0", n)
+	}
+
+	// Fill the pool with blocks 6 and 5, then add block 4, which evicts
+	// block 6 as the oldest orphan.
+	process(5)
+	process(4)
+	process(3)
+	if n := chain.NumOrphans(); n != 2 {
+		t.Fatalf("NumOrphans: got %d, want 2", n)
+	}
+	if chain.IsKnownOrphan(blocks[5].Hash()) {
+		t.Fatal("IsKnownOrphan: oldest orphan was not evicted")
+	}
+	for _, i := range []int{3, 4} {
+		if !chain.IsKnownOrphan(blocks[i].Hash()) {
+			t.Fatalf("IsKnownOrphan %d: block is not known", i+1)
+		}
+	}
+
+	// Delivering block 3 connects the remaining orphans, while the chain
+	// stops short of the evicted block.
+	process(2)
+	if n := chain.NumOrphans(); n != 0 {
+		t.Fatalf("NumOrphans: got %d after connecting, want 0", n)
+	}
+	best := chain.BestSnapshot()
+	if best.Height != 5 || !best.Hash.IsEqual(blocks[4].Hash()) {
+		t.Fatalf("best block: got %v (height %d), want %v (height 5)",
+			best.Hash, best.Height, blocks[4].Hash())
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+)
+
+const (
+	// minBlocksToKeep is the minimum number of blocks at the end of the
+	// main chain which are never pruned.  This ensures reorganizations of
+	// a reasonable depth can still be handled and matches the number of
+	// blocks nodes which only serve recent blocks are expected to have as
+	// defined by BIP0159.
+	minBlocksToKeep = 288
+
+	// pruneInterval is the number of blocks connected to the main chain
+	// between attempts to prune the database.  Determining which blocks
+	// can be pruned requires scanning the block index of the database, so
+	// it is not done for every block.
+	pruneInterval = 24
+)
+
+// pruneBlocks deletes the oldest blocks from the database until the stored
+// block data is within the provided target size while keeping all blocks above
+// the provided height.  The blocks still needed by indexes which are being
+// caught up are kept as well.  The height of the most recent pruned block in
+// the main chain is updated accordingly.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) pruneBlocks(targetSize uint64, keepHeight int32) error {
+	// The blocks after the one the utxo set in the database corresponds to
+	// are needed to restore the changes held in the utxo cache after a
+	// crash, so write them to the database first when they might be
+	// pruned.
+	if b.utxoCache.flushedHeight <= keepHeight {
+		if err := b.flushUtxoCache(false); err != nil {
+			return err
+		}
+	}
+
+	pruneHeight := b.pruneHeight
+	var numPruned int
+	err := b.db.Update(func(dbTx database.Tx) error {
+		// The blocks after the tips of the indexes which are still being
+		// caught up are needed to index them.
+		if b.indexManager != nil {
+			height, err := b.indexManager.CatchUpHeight(dbTx)
+			if err != nil {
+				return err
+			}
+			if height < keepHeight {
+				keepHeight = height
+			}
+		}
+
+		prunedHashes, err := dbTx.PruneBlocks(targetSize,
+			func(hash *chainhash.Hash) bool {
+				node := b.index.LookupNode(hash)
+				return node != nil && node.height > keepHeight
+			})
+		if err != nil {
+			return err
+		}
+		numPruned = len(prunedHashes)
+
+		// The spend journal entries of the pruned blocks in the main
+		// chain are no longer useful since the blocks themselves are
+		// required to disconnect them.
+		for i := range prunedHashes {
+			hash := &prunedHashes[i]
+			node := b.index.LookupNode(hash)
+			if node == nil || !b.bestChain.Contains(node) {
+				continue
+			}
+			err := dbRemoveSpendJournalEntry(dbTx, hash)
+			if err != nil {
+				return err
+			}
+			if node.height > pruneHeight {
+				pruneHeight = node.height
+			}
+		}
+
+		if pruneHeight == b.pruneHeight {
+			return nil
+		}
+		return dbPutPruneHeight(dbTx, pruneHeight)
+	})
+	if err != nil {
+		return err
+	}
+
+	if numPruned > 0 {
+		b.pruneHeight = pruneHeight
+		log.Infof("Pruned %d blocks (pruned to height %d)", numPruned,
+			pruneHeight)
+	}
+	return nil
+}
+
+// maybePruneBlocks deletes the oldest blocks from the database when pruning is
+// enabled and the stored block data exceeds the prune target.  Blocks within
+// minBlocksToKeep of the end of the main chain are never pruned.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) maybePruneBlocks() error {
+	tip := b.bestChain.Tip()
+	if b.pruneTarget == 0 || tip.height%pruneInterval != 0 {
+		return nil
+	}
+
+	return b.pruneBlocks(b.pruneTarget, tip.height-minBlocksToKeep)
+}
+
+// PruneToHeight deletes the blocks in the main chain at or below the given
+// height from the database regardless of the prune target.  The blocks within
+// minBlocksToKeep of the end of the main chain are never pruned, so the height
+// is limited accordingly.  Also, since blocks are deleted a block file at a
+// time, the blocks which share a file with a more recent block are not pruned.
+//
+// The height of the most recent pruned block in the main chain is returned.
+// It is an error to call this function when pruning is not enabled.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PruneToHeight(height int32) (int32, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	if b.pruneTarget == 0 {
+		return 0, fmt.Errorf("unable to prune blocks since pruning " +
+			"is not enabled")
+	}
+
+	keepHeight := b.bestChain.Tip().height - minBlocksToKeep
+	if height < keepHeight {
+		keepHeight = height
+	}
+	if keepHeight >= 0 {
+		if err := b.pruneBlocks(0, keepHeight); err != nil {
+			return 0, err
+		}
+	}
+	return b.pruneHeight, nil
+}
+
+// HeightByTime returns the height of the first block in the main chain with a
+// timestamp at or after the given time.  Since block timestamps are not
+// strictly increasing, blocks after the returned height might still have
+// earlier timestamps, however, all of the blocks before it claim to have been
+// created before the given time.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) HeightByTime(t time.Time) (int32, error) {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	target := t.Unix()
+	tipHeight := b.bestChain.Height()
+	for height := int32(0); height <= tipHeight; height++ {
+		node := b.bestChain.NodeByHeight(height)
+		if node.timestamp >= target {
+			return height, nil
+		}
+	}
+
+	str := fmt.Sprintf("no block in the main chain has a timestamp at "+
+		"or after %v", t)
+	return 0, errNotInMainChain(str)
+}
+
+// PruneEnabled returns whether or not the chain instance was configured to
+// prune old blocks from the database.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PruneEnabled() bool {
+	return b.pruneTarget != 0
+}
+
+// PruneHeight returns the height of the most recent block in the main chain
+// which has been pruned from the database.  All blocks in the main chain at or
+// below the height are no longer available.  -1 is returned when no blocks have
+// been pruned.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PruneHeight() int32 {
+	b.chainLock.RLock()
+	pruneHeight := b.pruneHeight
+	b.chainLock.RUnlock()
+	return pruneHeight
+}
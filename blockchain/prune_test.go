@@ -0,0 +1,167 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// newCoinbaseOnlyBlock returns a block at the passed height which only
+// contains a coinbase and builds on the block with the passed hash.  The
+// block does not have valid proof of work, so it must be processed with
+// BFNoPoWCheck.
+func newCoinbaseOnlyBlock(t testing.TB, params *chaincfg.Params,
+	prevHash *chainhash.Hash, height int32) *ltcutil.Block {
+
+	coinbaseScript, err := txscript.NewScriptBuilder().
+		AddInt64(int64(height)).AddInt64(0).Script()
+	if err != nil {
+		t.Fatalf("unable to create coinbase script: %v", err)
+	}
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: coinbaseScript,
+		Sequence:        wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(wire.NewTxOut(CalcBlockSubsidy(height, params),
+		[]byte{txscript.OP_TRUE}))
+
+	merkles := BuildMerkleTreeStore([]*ltcutil.Tx{ltcutil.NewTx(coinbase)},
+		false)
+	return ltcutil.NewBlock(&wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    1,
+			PrevBlock:  *prevHash,
+			MerkleRoot: *merkles[len(merkles)-1],
+			Timestamp: params.GenesisBlock.Header.Timestamp.Add(
+				time.Duration(height) * time.Minute),
+			Bits: params.PowLimitBits,
+		},
+		Transactions: []*wire.MsgTx{coinbase},
+	})
+}
+
+// addPruneTestBlock creates a block which only contains a coinbase and extends
+// the main chain of the passed regression test network chain instance.
+func addPruneTestBlock(t *testing.T, chain *BlockChain) {
+	best := chain.BestSnapshot()
+	height := best.Height + 1
+	block := newCoinbaseOnlyBlock(t, chain.chainParams, &best.Hash, height)
+	isMainChain, isOrphan, err := chain.ProcessBlock(block, BFNoPoWCheck)
+	if err != nil {
+		t.Fatalf("unable to process block %d: %v", height, err)
+	}
+	if !isMainChain || isOrphan {
+		t.Fatalf("block %d did not extend the main chain", height)
+	}
+}
+
+// TestPruneBlocks ensures the oldest blocks are pruned from the database once
+// the stored block data exceeds the prune target while the most recent blocks
+// are kept, and that the pruned chain can be reloaded and extended.
+func TestPruneBlocks(t *testing.T) {
+	// Create a database with small block files so the test chain spans
+	// many of them.
+	dbPath := filepath.Join(os.TempDir(), "prunetest")
+	_ = os.RemoveAll(dbPath)
+	db, err := database.Create(testDbType, dbPath, blockDataNet,
+		uint32(4096))
+	if err != nil {
+		t.Fatalf("unable to create db: %v", err)
+	}
+	defer os.RemoveAll(dbPath)
+	defer func() { db.Close() }()
+
+	paramsCopy := chaincfg.RegressionNetParams
+	newChain := func() *BlockChain {
+		chain, err := New(&Config{
+			DB:          db,
+			ChainParams: &paramsCopy,
+			TimeSource:  NewMedianTime(),
+			PruneTarget: 1,
+		})
+		if err != nil {
+			t.Fatalf("failed to create chain instance: %v", err)
+		}
+		return chain
+	}
+	chain := newChain()
+	if chain.PruneHeight() != -1 {
+		t.Fatalf("PruneHeight: got %d before pruning, want -1",
+			chain.PruneHeight())
+	}
+
+	const numBlocks = 400
+	for i := 0; i < numBlocks; i++ {
+		addPruneTestBlock(t, chain)
+	}
+
+	// Ensure blocks were pruned while keeping the most recent blocks.
+	pruneHeight := chain.PruneHeight()
+	if pruneHeight < 0 {
+		t.Fatal("PruneHeight: no blocks were pruned")
+	}
+	if numBlocks-pruneHeight < minBlocksToKeep {
+		t.Fatalf("PruneHeight: pruned to height %d which leaves fewer "+
+			"than %d blocks", pruneHeight, minBlocksToKeep)
+	}
+
+	// checkPruned ensures the blocks in the main chain at or below the
+	// prune height are no longer available while their headers are and
+	// the remaining blocks are still available.
+	checkPruned := func(chain *BlockChain) {
+		tipHeight := chain.BestSnapshot().Height
+		for height := int32(0); height <= tipHeight; height++ {
+			hash, err := chain.BlockHashByHeight(height)
+			if err != nil {
+				t.Fatalf("BlockHashByHeight(%d): %v", height, err)
+			}
+			_, err = chain.BlockByHeight(height)
+			if pruned := err != nil; pruned != (height <= pruneHeight) {
+				t.Fatalf("BlockByHeight(%d): pruned %v with prune "+
+					"height %d (err %v)", height, pruned,
+					pruneHeight, err)
+			}
+
+			err = db.View(func(dbTx database.Tx) error {
+				_, err := dbFetchHeaderByHash(dbTx, hash)
+				return err
+			})
+			if err != nil {
+				t.Fatalf("FetchBlockHeader(%d): %v", height, err)
+			}
+		}
+	}
+	checkPruned(chain)
+
+	// Ensure the tip still validates after pruning.
+	addPruneTestBlock(t, chain)
+
+	// Ensure the pruned chain can be reloaded and extended.
+	tip := chain.BestSnapshot()
+	chain = newChain()
+	if got := chain.BestSnapshot(); got.Hash != tip.Hash {
+		t.Fatalf("BestSnapshot: got tip %v after reload, want %v",
+			got.Hash, tip.Hash)
+	}
+	if chain.PruneHeight() != pruneHeight {
+		t.Fatalf("PruneHeight: got %d after reload, want %d",
+			chain.PruneHeight(), pruneHeight)
+	}
+	checkPruned(chain)
+	addPruneTestBlock(t, chain)
+}
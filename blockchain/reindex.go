@@ -0,0 +1,427 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// ReindexMode identifies which parts of the chain state are rebuilt from the
+// blocks stored in the database when a chain instance is created.
+type ReindexMode byte
+
+// These constants are used to identify a specific ReindexMode.
+const (
+	// ReindexNone indicates the chain state is not rebuilt.
+	ReindexNone ReindexMode = iota
+
+	// ReindexChainState indicates the utxo set and spend journal are
+	// rebuilt by connecting the blocks of the main chain again.  The
+	// blocks were already validated when they were first connected, so
+	// only the utxos they spend and create are processed.
+	ReindexChainState
+
+	// ReindexFull indicates the index of the main chain is rebuilt along
+	// with the utxo set and spend journal by fully validating and
+	// connecting all of the stored blocks again.
+	ReindexFull
+)
+
+// reindexModeStrings is a map of reindex modes back to their constant names for
+// pretty printing.
+var reindexModeStrings = map[ReindexMode]string{
+	ReindexNone:       "ReindexNone",
+	ReindexChainState: "ReindexChainState",
+	ReindexFull:       "ReindexFull",
+}
+
+// String returns the ReindexMode in human-readable form.
+func (m ReindexMode) String() string {
+	if s, ok := reindexModeStrings[m]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown ReindexMode (%d)", int(m))
+}
+
+const (
+	// reindexLogInterval is the minimum time between the messages which
+	// log the progress of a reindex.
+	reindexLogInterval = 10 * time.Second
+)
+
+var (
+	// reindexKeyName is the name of the db key used to store the mode of
+	// a reindex which was started but has not finished yet.
+	reindexKeyName = []byte("reindex")
+
+	// errInterruptRequested indicates a reindex was stopped because an
+	// interrupt was requested.  The reindex resumes from the current best
+	// block when the chain is created again.
+	errInterruptRequested = errors.New("interrupt requested")
+)
+
+// interruptRequested returns true when the provided channel has been closed.
+// This simplifies early shutdown slightly since the caller can just use an if
+// statement instead of a select.
+func interruptRequested(interrupted <-chan struct{}) bool {
+	select {
+	case <-interrupted:
+		return true
+	default:
+	}
+
+	return false
+}
+
+// dbFetchReindexMode uses an existing database transaction to retrieve the mode
+// of the reindex which is in progress.  ReindexNone is returned when there is
+// no such reindex.
+func dbFetchReindexMode(dbTx database.Tx) ReindexMode {
+	serialized := dbTx.Metadata().Get(reindexKeyName)
+	if len(serialized) != 1 {
+		return ReindexNone
+	}
+	return ReindexMode(serialized[0])
+}
+
+// dbPutReindexMode uses an existing database transaction to record the mode of
+// the reindex which is in progress.
+func dbPutReindexMode(dbTx database.Tx, mode ReindexMode) error {
+	return dbTx.Metadata().Put(reindexKeyName, []byte{byte(mode)})
+}
+
+// dbRemoveReindexMode uses an existing database transaction to remove the
+// record of a reindex once it has finished.
+func dbRemoveReindexMode(dbTx database.Tx) error {
+	return dbTx.Metadata().Delete(reindexKeyName)
+}
+
+// startReindex resets the chain state stored in the database to the genesis
+// block according to the provided mode and records that a reindex is in
+// progress, all within a single database transaction.  The stored blocks are
+// left untouched so they can be connected again by finishReindex.
+//
+// A reindex with the ReindexChainState mode keeps the index of the main chain
+// since it identifies the blocks to connect again.  A full reindex which was
+// interrupted before is not downgraded to a reindex of the chain state since
+// the index of the main chain it was rebuilding is incomplete.
+//
+// This must be called before the chain state is loaded.
+func (b *BlockChain) startReindex(mode ReindexMode) error {
+	return b.db.Update(func(dbTx database.Tx) error {
+		// There is nothing to rebuild when the database has not been
+		// initialized for use with chain yet.
+		meta := dbTx.Metadata()
+		if meta.Get(chainStateKeyName) == nil {
+			return nil
+		}
+
+		// The blocks required to rebuild the chain state are no longer
+		// available once any have been pruned.
+		pruneHeight, err := dbFetchPruneHeight(dbTx)
+		if err != nil {
+			return err
+		}
+		if pruneHeight >= 0 {
+			return fmt.Errorf("unable to reindex since blocks up to "+
+				"height %d have been pruned", pruneHeight)
+		}
+
+		if pending := dbFetchReindexMode(dbTx); pending > mode {
+			mode = pending
+		}
+
+		// Recreate the buckets that house the state being rebuilt.
+		buckets := [][]byte{utxoSetBucketName, spendJournalBucketName}
+		if mode == ReindexFull {
+			buckets = append(buckets, hashIndexBucketName,
+				heightIndexBucketName)
+		}
+		for _, bucketName := range buckets {
+			if err := meta.DeleteBucket(bucketName); err != nil {
+				return err
+			}
+			if _, err := meta.CreateBucket(bucketName); err != nil {
+				return err
+			}
+		}
+
+		// Reset the best chain state to the genesis block.  A loaded
+		// utxo snapshot no longer applies since the utxo set is rebuilt
+		// from the blocks.
+		genesisBlock := b.chainParams.GenesisBlock
+		node := newBlockNode(&genesisBlock.Header, 0)
+		if mode == ReindexFull {
+			err := dbPutBlockIndex(dbTx, &node.hash, node.height)
+			if err != nil {
+				return err
+			}
+		}
+		err = meta.Put(chainStateKeyName, serializeBestChainState(
+			bestChainState{
+				hash:      node.hash,
+				height:    uint32(node.height),
+				totalTxns: uint64(len(genesisBlock.Transactions)),
+				workSum:   node.workSum,
+			}))
+		if err != nil {
+			return err
+		}
+		if err := meta.Delete(utxoSnapshotKeyName); err != nil {
+			return err
+		}
+		if err := dbPutUtxoState(dbTx, &node.hash, node.height); err != nil {
+			return err
+		}
+
+		log.Infof("Starting a reindex (%v)", mode)
+		return dbPutReindexMode(dbTx, mode)
+	})
+}
+
+// maybeFinishReindex rebuilds the chain state from the stored blocks when a
+// reindex was started, either by startReindex or by a previous instance of the
+// chain which was interrupted before the reindex finished.  Each block is
+// connected in its own database transaction, so the stored chain state always
+// corresponds to the best block and an interrupted reindex resumes from it.
+//
+// The optional indexes are not updated while reindexing since they already
+// include the stored blocks.  They are caught up or rolled back to the
+// resulting main chain once the reindex is finished as usual.
+//
+// This must be called after the chain state is loaded and before the optional
+// indexes are initialized.
+func (b *BlockChain) maybeFinishReindex(interrupt <-chan struct{}) error {
+	var mode ReindexMode
+	err := b.db.View(func(dbTx database.Tx) error {
+		mode = dbFetchReindexMode(dbTx)
+		return nil
+	})
+	if err != nil || mode == ReindexNone {
+		return err
+	}
+
+	// Pruning is disabled while reindexing since the blocks which have
+	// not been processed yet are not known to be needed.
+	indexManager, pruneTarget := b.indexManager, b.pruneTarget
+	b.indexManager, b.pruneTarget = nil, 0
+	defer func() {
+		b.indexManager, b.pruneTarget = indexManager, pruneTarget
+	}()
+
+	log.Infof("Reindexing from height %d (%v).  This might take a while...",
+		b.bestChain.Height(), mode)
+	progress := reindexProgress{lastLog: time.Now()}
+	switch mode {
+	case ReindexChainState:
+		err = b.reindexChainState(interrupt, &progress)
+	case ReindexFull:
+		err = b.reindexBlocks(interrupt, &progress)
+	default:
+		err = AssertError(fmt.Sprintf("unknown reindex mode %v", mode))
+	}
+
+	// Write the rebuilt utxo set held in the utxo cache to the database,
+	// including when the reindex was interrupted so the progress is kept.
+	if err == nil || err == errInterruptRequested {
+		if flushErr := b.flushUtxoCache(false); flushErr != nil {
+			return flushErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	err = b.db.Update(dbRemoveReindexMode)
+	if err != nil {
+		return err
+	}
+	log.Infof("Reindex finished after %d blocks (height %d, hash %v)",
+		progress.totalBlocks, b.bestChain.Height(),
+		b.bestChain.Tip().hash)
+	return nil
+}
+
+// reindexChainState rebuilds the utxo set and spend journal by connecting the
+// blocks identified by the index of the main chain after the current best
+// block.  The blocks were validated before, so they are connected without
+// validating them again.
+func (b *BlockChain) reindexChainState(interrupt <-chan struct{}, progress *reindexProgress) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	for {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		// Load the next block of the main chain.  The index of the main
+		// chain ends after the final block to connect.
+		tip := b.bestChain.Tip()
+		height := tip.height + 1
+		var block *ltcutil.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			hash, err := dbFetchHashByHeight(dbTx, height)
+			if err != nil {
+				return err
+			}
+			blockBytes, err := dbTx.FetchBlock(hash)
+			if err != nil {
+				return err
+			}
+			block, err = ltcutil.NewBlockFromBytes(blockBytes)
+			return err
+		})
+		if isNotInMainChainErr(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		block.SetHeight(height)
+
+		header := &block.MsgBlock().Header
+		if header.PrevBlock != tip.hash {
+			return fmt.Errorf("block %v at height %d does not "+
+				"connect to the previous block %v", block.Hash(),
+				height, tip.hash)
+		}
+		node := newBlockNode(header, height)
+		node.parent = tip
+		node.workSum.Add(tip.workSum, node.workSum)
+
+		// Spend the utxos referenced by the block and add the ones it
+		// creates.
+		view := NewUtxoViewpoint()
+		view.SetBestHash(&tip.hash)
+		stxos := make([]spentTxOut, 0, countSpentOutputs(block))
+		if err := view.fetchInputUtxos(b.utxoCache, block); err != nil {
+			return err
+		}
+		if err := view.connectTransactions(block, &stxos); err != nil {
+			return err
+		}
+		if err := b.connectBlock(node, block, view, stxos); err != nil {
+			return err
+		}
+
+		progress.logBlock(block)
+	}
+}
+
+// reindexBlocks validates and connects all of the stored blocks again, which
+// rebuilds the index of the main chain along with the utxo set and spend
+// journal.  Side chains are processed as well, so the resulting main chain is
+// the valid chain with the most work among the stored blocks.
+//
+// Blocks which are already in the block index when resuming an interrupted
+// reindex are skipped.
+func (b *BlockChain) reindexBlocks(interrupt <-chan struct{}, progress *reindexProgress) error {
+	// Find the children of all of the stored blocks so the blocks can be
+	// processed in an order which ensures their parents are processed
+	// before them.
+	children := make(map[chainhash.Hash][]chainhash.Hash)
+	err := b.db.View(func(dbTx database.Tx) error {
+		return dbTx.ForEachBlock(func(hash *chainhash.Hash) error {
+			header, err := dbFetchHeaderByHash(dbTx, hash)
+			if err != nil {
+				return err
+			}
+			prevHash := header.PrevBlock
+			children[prevHash] = append(children[prevHash], *hash)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// Process the blocks in breadth first order starting from the genesis
+	// block.  The descendants of invalid blocks are skipped.
+	queue := append([]chainhash.Hash(nil),
+		children[*b.chainParams.GenesisHash]...)
+	for len(queue) > 0 {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		hash := queue[0]
+		queue = queue[1:]
+		if !b.index.HaveBlock(&hash) {
+			var block *ltcutil.Block
+			err := b.db.View(func(dbTx database.Tx) error {
+				blockBytes, err := dbTx.FetchBlock(&hash)
+				if err != nil {
+					return err
+				}
+				block, err = ltcutil.NewBlockFromBytes(blockBytes)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			_, isOrphan, err := b.ProcessBlock(block, BFNone)
+			if _, ok := err.(RuleError); ok {
+				log.Warnf("Skipping invalid block %v and its "+
+					"descendants: %v", hash, err)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if isOrphan {
+				return AssertError(fmt.Sprintf("reindexBlocks: "+
+					"block %v is an orphan", hash))
+			}
+
+			progress.logBlock(block)
+		}
+
+		queue = append(queue, children[hash]...)
+		delete(children, hash)
+	}
+
+	return nil
+}
+
+// reindexProgress tracks the number of blocks processed by a reindex in order
+// to log its progress periodically.
+type reindexProgress struct {
+	totalBlocks    int64
+	receivedBlocks int64
+	receivedTxns   int64
+	lastLog        time.Time
+}
+
+// logBlock accounts for the passed processed block and logs the progress of
+// the reindex once the log interval has passed since the previous message.
+func (p *reindexProgress) logBlock(block *ltcutil.Block) {
+	p.totalBlocks++
+	p.receivedBlocks++
+	p.receivedTxns += int64(len(block.MsgBlock().Transactions))
+
+	now := time.Now()
+	duration := now.Sub(p.lastLog)
+	if duration < reindexLogInterval {
+		return
+	}
+
+	// Truncate the duration to 10s of milliseconds.
+	duration = 10 * time.Millisecond * (duration / (10 * time.Millisecond))
+	log.Infof("Reindexed %d blocks in the last %s (%d transactions, "+
+		"height %d, %s)", p.receivedBlocks, duration, p.receivedTxns,
+		block.Height(), block.MsgBlock().Header.Timestamp)
+
+	p.receivedBlocks = 0
+	p.receivedTxns = 0
+	p.lastLog = now
+}
@@ -0,0 +1,281 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// newReindexTestBlock returns a block at the passed height which builds on the
+// block with the passed hash and, when a previous coinbase is provided, also
+// contains a transaction which spends its output to an anyone can spend
+// output.  The block is solved so it passes the proof of work checks.
+func newReindexTestBlock(t testing.TB, params *chaincfg.Params,
+	prevHash *chainhash.Hash, height int32, prevCoinbase *wire.MsgTx) *ltcutil.Block {
+
+	block := newCoinbaseOnlyBlock(t, params, prevHash, height).MsgBlock()
+	if prevCoinbase != nil {
+		spend := wire.NewMsgTx(1)
+		spend.AddTxIn(wire.NewTxIn(wire.NewOutPoint(
+			&chainhash.Hash{}, 0), nil, nil))
+		spend.TxIn[0].PreviousOutPoint.Hash = prevCoinbase.TxHash()
+		spend.AddTxOut(wire.NewTxOut(prevCoinbase.TxOut[0].Value/2,
+			prevCoinbase.TxOut[0].PkScript))
+		block.AddTransaction(spend)
+
+		utilTxns := make([]*ltcutil.Tx, 0, len(block.Transactions))
+		for _, tx := range block.Transactions {
+			utilTxns = append(utilTxns, ltcutil.NewTx(tx))
+		}
+		merkles := BuildMerkleTreeStore(utilTxns, false)
+		block.Header.MerkleRoot = *merkles[len(merkles)-1]
+	}
+
+	for {
+		err := checkProofOfWork(&block.Header, params.PowLimit, BFNone)
+		if err == nil {
+			break
+		}
+		block.Header.Nonce++
+	}
+	return ltcutil.NewBlock(block)
+}
+
+// dumpBuckets returns the contents of the metadata buckets which house the
+// chain state that is rebuilt by a reindex.
+func dumpBuckets(t *testing.T, db database.DB) map[string]map[string]string {
+	dump := make(map[string]map[string]string)
+	err := db.View(func(dbTx database.Tx) error {
+		bucketNames := [][]byte{utxoSetBucketName,
+			spendJournalBucketName, hashIndexBucketName,
+			heightIndexBucketName}
+		for _, bucketName := range bucketNames {
+			contents := make(map[string]string)
+			bucket := dbTx.Metadata().Bucket(bucketName)
+			err := bucket.ForEach(func(k, v []byte) error {
+				contents[string(k)] = string(v)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			dump[string(bucketName)] = contents
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to dump chain state: %v", err)
+	}
+	return dump
+}
+
+// TestReindex ensures a chain state with a corrupt utxo set is recovered by
+// reindexing the chain state, that the main chain index is rebuilt along with
+// the side chains by a full reindex, and that interrupted reindexes are resumed
+// the next time the chain is created.
+func TestReindex(t *testing.T) {
+	dbPath := filepath.Join(os.TempDir(), "reindextest")
+	_ = os.RemoveAll(dbPath)
+	db, err := database.Create(testDbType, dbPath, blockDataNet)
+	if err != nil {
+		t.Fatalf("unable to create db: %v", err)
+	}
+	defer os.RemoveAll(dbPath)
+	defer func() { db.Close() }()
+
+	paramsCopy := chaincfg.RegressionNetParams
+	newChain := func(mode ReindexMode, interrupt <-chan struct{}) (*BlockChain, error) {
+		return New(&Config{
+			DB:               db,
+			ChainParams:      &paramsCopy,
+			TimeSource:       NewMedianTime(),
+			Reindex:          mode,
+			Interrupt:        interrupt,
+			UtxoCacheMaxSize: 1 << 20,
+		})
+	}
+	chain, err := newChain(ReindexNone, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain instance: %v", err)
+	}
+	chain.TstSetCoinbaseMaturity(1)
+
+	// addBlock adds a block to the passed chain which spends the coinbase
+	// of its parent, if any, when requested.
+	coinbases := make(map[chainhash.Hash]*wire.MsgTx)
+	addBlock := func(chain *BlockChain, prevHash *chainhash.Hash, height int32, spend bool) *ltcutil.Block {
+		var prevCoinbase *wire.MsgTx
+		if spend {
+			prevCoinbase = coinbases[*prevHash]
+		}
+		block := newReindexTestBlock(t, chain.chainParams, prevHash,
+			height, prevCoinbase)
+		_, isOrphan, err := chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatalf("unable to process block %d: %v", height, err)
+		}
+		if isOrphan {
+			t.Fatalf("block %d is an orphan", height)
+		}
+		coinbases[*block.Hash()] = block.MsgBlock().Transactions[0]
+		return block
+	}
+
+	// Create a main chain along with a shorter side chain.  The blocks of
+	// the side chain do not spend any outputs so they differ from the
+	// blocks of the main chain at the same heights.
+	const numBlocks = 30
+	const forkHeight = numBlocks - 5
+	for height := int32(1); height <= numBlocks; height++ {
+		best := chain.BestSnapshot()
+		addBlock(chain, &best.Hash, height, true)
+	}
+	forkHash, err := chain.BlockHashByHeight(forkHeight)
+	if err != nil {
+		t.Fatalf("BlockHashByHeight: %v", err)
+	}
+	sideHash := *forkHash
+	for height := int32(forkHeight + 1); height < numBlocks-1; height++ {
+		sideHash = *addBlock(chain, &sideHash, height, false).Hash()
+	}
+	flushUtxoCache := func(chain *BlockChain) {
+		if err := chain.FlushUtxoCache(); err != nil {
+			t.Fatalf("FlushUtxoCache: %v", err)
+		}
+	}
+	flushUtxoCache(chain)
+	tip := chain.BestSnapshot()
+	want := dumpBuckets(t, db)
+
+	// checkChain ensures the passed chain has the expected tip and chain
+	// state and can be extended, in which case the extended chain is
+	// expected by the following checks.
+	checkChain := func(chain *BlockChain) {
+		if got := chain.BestSnapshot(); got.Hash != tip.Hash ||
+			got.Height != tip.Height || got.TotalTxns != tip.TotalTxns {
+
+			t.Fatalf("unexpected best state %+v, want %+v", got, tip)
+		}
+		flushUtxoCache(chain)
+		if got := dumpBuckets(t, db); !reflect.DeepEqual(got, want) {
+			t.Fatal("chain state does not match the original one")
+		}
+		err := db.View(func(dbTx database.Tx) error {
+			if mode := dbFetchReindexMode(dbTx); mode != ReindexNone {
+				t.Fatalf("reindex %v is still pending", mode)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("View: %v", err)
+		}
+
+		addBlock(chain, &tip.Hash, tip.Height+1, true)
+		flushUtxoCache(chain)
+		tip = chain.BestSnapshot()
+		want = dumpBuckets(t, db)
+	}
+
+	// Corrupt the utxo set by removing some of the entries and modifying
+	// others, and ensure it is recovered by reindexing the chain state.
+	err = db.Update(func(dbTx database.Tx) error {
+		utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+		var numEntries int
+		cursor := utxoBucket.Cursor()
+		for ok := cursor.First(); ok; ok = cursor.Next() {
+			numEntries++
+			var err error
+			if numEntries%2 == 0 {
+				err = cursor.Delete()
+			} else {
+				err = utxoBucket.Put(cursor.Key(), []byte{0x01})
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unable to corrupt the utxo set: %v", err)
+	}
+	if reflect.DeepEqual(dumpBuckets(t, db), want) {
+		t.Fatal("utxo set was not corrupted")
+	}
+	chain, err = newChain(ReindexChainState, nil)
+	if err != nil {
+		t.Fatalf("unable to reindex the chain state: %v", err)
+	}
+	checkChain(chain)
+
+	// Ensure a reindex of the chain state which is interrupted leaves the
+	// chain state at the genesis block and is resumed by the next chain
+	// instance even without requesting a reindex.
+	interrupt := make(chan struct{})
+	close(interrupt)
+	_, err = newChain(ReindexChainState, interrupt)
+	if err != errInterruptRequested {
+		t.Fatalf("unexpected error from interrupted reindex: %v", err)
+	}
+	err = db.View(func(dbTx database.Tx) error {
+		state, err := deserializeBestChainState(
+			dbTx.Metadata().Get(chainStateKeyName))
+		if err != nil {
+			return err
+		}
+		if state.hash != *paramsCopy.GenesisHash {
+			t.Fatalf("interrupted reindex left best block %v",
+				state.hash)
+		}
+		if mode := dbFetchReindexMode(dbTx); mode != ReindexChainState {
+			t.Fatalf("unexpected pending reindex %v", mode)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("View: %v", err)
+	}
+	chain, err = newChain(ReindexNone, nil)
+	if err != nil {
+		t.Fatalf("unable to resume the reindex: %v", err)
+	}
+	checkChain(chain)
+
+	// Ensure an interrupted full reindex is not downgraded by a reindex of
+	// the chain state since the main chain index was removed, and that it
+	// rebuilds the main chain index along with the side chain.
+	_, err = newChain(ReindexFull, interrupt)
+	if err != errInterruptRequested {
+		t.Fatalf("unexpected error from interrupted reindex: %v", err)
+	}
+	chain, err = newChain(ReindexChainState, nil)
+	if err != nil {
+		t.Fatalf("unable to resume the reindex: %v", err)
+	}
+	if !chain.index.HaveBlock(&sideHash) {
+		t.Fatal("side chain block was not reindexed")
+	}
+	checkChain(chain)
+
+	// Ensure reindexing is refused once blocks have been pruned.
+	err = db.Update(func(dbTx database.Tx) error {
+		return dbPutPruneHeight(dbTx, 1)
+	})
+	if err != nil {
+		t.Fatalf("unable to store prune height: %v", err)
+	}
+	if _, err := newChain(ReindexChainState, nil); err == nil {
+		t.Fatal("reindex of a pruned chain was not refused")
+	}
+}
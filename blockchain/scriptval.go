@@ -10,22 +10,30 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 	"github.com/ltcsuite/ltcd/txscript"
 	"github.com/ltcsuite/ltcd/wire"
 	"github.com/ltcsuite/ltcutil"
 )
 
-// txValidateItem holds a transaction along with which input to validate.
+// txValidateItem holds a transaction along with which input to validate.  The
+// witness hash of the transaction is only set when a script cache is used.
 type txValidateItem struct {
 	txInIndex int
 	txIn      *wire.TxIn
 	tx        *ltcutil.Tx
+	wtxid     *chainhash.Hash
 	sigHashes *txscript.TxSigHashes
 }
 
 // txValidator provides a type which asynchronously validates transaction
 // inputs.  It provides several channels for communication and a processing
 // function that is intended to be in run multiple goroutines.
+//
+// The utxo view is only read while the inputs are validated, which is safe for
+// concurrent access since the view is not modified until the validation is
+// complete and each of the inputs spends a distinct output, so no two
+// goroutines ever decompress the same output of an entry.
 type txValidator struct {
 	validateChan chan *txValidateItem
 	quitChan     chan struct{}
@@ -34,6 +42,9 @@ type txValidator struct {
 	flags        txscript.ScriptFlags
 	sigCache     *txscript.SigCache
 	hashCache    *txscript.HashCache
+	scriptCache  *txscript.ScriptCache
+	cacheValid   bool
+	numWorkers   int
 }
 
 // sendResult sends the result of a script pair validation on the internal
@@ -84,6 +95,16 @@ out:
 				break out
 			}
 
+			// Skip executing the script pair when the input is
+			// already known to be valid under the script flags.
+			txInIndex := uint32(txVI.txInIndex)
+			if v.scriptCache != nil &&
+				v.scriptCache.Exists(*txVI.wtxid, txInIndex, v.flags) {
+
+				v.sendResult(nil)
+				continue
+			}
+
 			// Create a new script engine for the script pair.
 			sigScript := txIn.SignatureScript
 			witness := txIn.Witness
@@ -119,6 +140,9 @@ out:
 			}
 
 			// Validation succeeded.
+			if v.scriptCache != nil && v.cacheValid {
+				v.scriptCache.Add(*txVI.wtxid, txInIndex, v.flags)
+			}
 			v.sendResult(nil)
 
 		case <-v.quitChan:
@@ -134,12 +158,13 @@ func (v *txValidator) Validate(items []*txValidateItem) error {
 		return nil
 	}
 
-	// Limit the number of goroutines to do script validation based on the
-	// number of processor cores.  This help ensure the system stays
-	// reasonably responsive under heavy load.
-	maxGoRoutines := runtime.NumCPU() * 3
+	// Limit the number of goroutines to do script validation to the
+	// configured number of workers, which defaults to the number of
+	// processors which may execute simultaneously.  This help ensure the
+	// system stays reasonably responsive under heavy load.
+	maxGoRoutines := v.numWorkers
 	if maxGoRoutines <= 0 {
-		maxGoRoutines = 1
+		maxGoRoutines = runtime.GOMAXPROCS(0)
 	}
 	if maxGoRoutines > len(items) {
 		maxGoRoutines = len(items)
@@ -186,9 +211,15 @@ func (v *txValidator) Validate(items []*txValidateItem) error {
 }
 
 // newTxValidator returns a new instance of txValidator to be used for
-// validating transaction scripts asynchronously.
+// validating transaction scripts asynchronously using up to the passed number
+// of goroutines.  A number of zero uses one goroutine per processor which may
+// execute simultaneously.  The inputs found in the script cache are not
+// validated again, while the inputs found valid are only added to it when
+// cacheValid is set.
 func newTxValidator(utxoView *UtxoViewpoint, flags txscript.ScriptFlags,
-	sigCache *txscript.SigCache, hashCache *txscript.HashCache) *txValidator {
+	sigCache *txscript.SigCache, hashCache *txscript.HashCache,
+	scriptCache *txscript.ScriptCache, cacheValid bool,
+	numWorkers int) *txValidator {
 	return &txValidator{
 		validateChan: make(chan *txValidateItem),
 		quitChan:     make(chan struct{}),
@@ -196,15 +227,19 @@ func newTxValidator(utxoView *UtxoViewpoint, flags txscript.ScriptFlags,
 		utxoView:     utxoView,
 		sigCache:     sigCache,
 		hashCache:    hashCache,
+		scriptCache:  scriptCache,
+		cacheValid:   cacheValid,
 		flags:        flags,
+		numWorkers:   numWorkers,
 	}
 }
 
 // ValidateTransactionScripts validates the scripts for the passed transaction
-// using multiple goroutines.
+// using multiple goroutines.  The inputs found in the script cache, if any, are
+// not validated again while the inputs found valid are added to it.
 func ValidateTransactionScripts(tx *ltcutil.Tx, utxoView *UtxoViewpoint,
 	flags txscript.ScriptFlags, sigCache *txscript.SigCache,
-	hashCache *txscript.HashCache) error {
+	hashCache *txscript.HashCache, scriptCache *txscript.ScriptCache) error {
 
 	// First determine if segwit is active according to the scriptFlags. If
 	// it isn't then we don't need to interact with the HashCache.
@@ -228,6 +263,12 @@ func ValidateTransactionScripts(tx *ltcutil.Tx, utxoView *UtxoViewpoint,
 		cachedHashes, _ = hashCache.GetSigHashes(tx.Hash())
 	}
 
+	// The witness hash identifies the inputs in the script cache.
+	var wtxid *chainhash.Hash
+	if scriptCache != nil {
+		wtxid = tx.WitnessHash()
+	}
+
 	// Collect all of the transaction inputs and required information for
 	// validation.
 	txIns := tx.MsgTx().TxIn
@@ -242,21 +283,32 @@ func ValidateTransactionScripts(tx *ltcutil.Tx, utxoView *UtxoViewpoint,
 			txInIndex: txInIdx,
 			txIn:      txIn,
 			tx:        tx,
+			wtxid:     wtxid,
 			sigHashes: cachedHashes,
 		}
 		txValItems = append(txValItems, txVI)
 	}
 
 	// Validate all of the inputs.
-	validator := newTxValidator(utxoView, flags, sigCache, hashCache)
+	validator := newTxValidator(utxoView, flags, sigCache, hashCache,
+		scriptCache, true, 0)
 	return validator.Validate(txValItems)
 }
 
 // checkBlockScripts executes and validates the scripts for all transactions in
-// the passed block using multiple goroutines.
+// the passed block using up to the passed number of goroutines, or one per
+// processor which may execute simultaneously when it is zero.  The first
+// validation error of any of the inputs is returned.
+//
+// The inputs found in the script cache, such as those of the transactions
+// previously accepted to the mempool, are not validated again.  Since the
+// transactions of a block are not expected to be validated again once it is
+// connected, the inputs of the block are not added to the script cache so they
+// don't evict the entries of unconfirmed transactions.
 func checkBlockScripts(block *ltcutil.Block, utxoView *UtxoViewpoint,
 	scriptFlags txscript.ScriptFlags, sigCache *txscript.SigCache,
-	hashCache *txscript.HashCache) error {
+	hashCache *txscript.HashCache, scriptCache *txscript.ScriptCache,
+	numWorkers int) error {
 
 	// First determine if segwit is active according to the scriptFlags. If
 	// it isn't then we don't need to interact with the HashCache.
@@ -292,6 +344,11 @@ func checkBlockScripts(block *ltcutil.Block, utxoView *UtxoViewpoint,
 			}
 		}
 
+		var wtxid *chainhash.Hash
+		if scriptCache != nil {
+			wtxid = tx.WitnessHash()
+		}
+
 		for txInIdx, txIn := range tx.MsgTx().TxIn {
 			// Skip coinbases.
 			if txIn.PreviousOutPoint.Index == math.MaxUint32 {
@@ -302,6 +359,7 @@ func checkBlockScripts(block *ltcutil.Block, utxoView *UtxoViewpoint,
 				txInIndex: txInIdx,
 				txIn:      txIn,
 				tx:        tx,
+				wtxid:     wtxid,
 				sigHashes: cachedHashes,
 			}
 			txValItems = append(txValItems, txVI)
@@ -309,7 +367,8 @@ func checkBlockScripts(block *ltcutil.Block, utxoView *UtxoViewpoint,
 	}
 
 	// Validate all of the inputs.
-	validator := newTxValidator(utxoView, scriptFlags, sigCache, hashCache)
+	validator := newTxValidator(utxoView, scriptFlags, sigCache, hashCache,
+		scriptCache, false, numWorkers)
 	start := time.Now()
 	if err := validator.Validate(txValItems); err != nil {
 		return err
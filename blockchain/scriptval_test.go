@@ -9,7 +9,11 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/ltcsuite/ltcd/btcec"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
 )
 
 // TestCheckBlockScripts ensures that validating the all of the scripts in a
@@ -41,9 +45,164 @@ func TestCheckBlockScripts(t *testing.T) {
 	}
 
 	scriptFlags := txscript.ScriptBip16
-	err = checkBlockScripts(blocks[0], view, scriptFlags, nil, nil)
+	err = checkBlockScripts(blocks[0], view, scriptFlags, nil, nil, nil, 0)
 	if err != nil {
 		t.Errorf("Transaction script validation failed: %v\n", err)
 		return
 	}
 }
+
+// newScriptValTestBlock returns a block with the passed number of transactions
+// in addition to its coinbase, each of which spends the passed number of
+// pay-to-pubkey-hash outputs with valid signatures, along with a utxo view
+// containing the spent outputs.
+func newScriptValTestBlock(tb testing.TB, numTxns, numInputs int) (*ltcutil.Block, *UtxoViewpoint) {
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		tb.Fatalf("unable to create private key: %v", err)
+	}
+	pubKeyHash := ltcutil.Hash160(privKey.PubKey().SerializeCompressed())
+	pkScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_DUP).
+		AddOp(txscript.OP_HASH160).AddData(pubKeyHash).
+		AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG).
+		Script()
+	if err != nil {
+		tb.Fatalf("unable to create script: %v", err)
+	}
+
+	// Create the transaction with the outputs spent by the block.
+	prevTx := wire.NewMsgTx(1)
+	prevTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{0x01}},
+		nil, nil))
+	for i := 0; i < numTxns*numInputs; i++ {
+		prevTx.AddTxOut(wire.NewTxOut(1000, pkScript))
+	}
+	prevHash := prevTx.TxHash()
+	view := NewUtxoViewpoint()
+	view.AddTxOuts(ltcutil.NewTx(prevTx), 1)
+
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: []byte{txscript.OP_0, txscript.OP_0},
+		Sequence:        wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(wire.NewTxOut(5000000000, pkScript))
+	var msgBlock wire.MsgBlock
+	msgBlock.AddTransaction(coinbase)
+	for i := 0; i < numTxns; i++ {
+		tx := wire.NewMsgTx(1)
+		for j := 0; j < numInputs; j++ {
+			index := uint32(i*numInputs + j)
+			tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, index),
+				nil, nil))
+		}
+		tx.AddTxOut(wire.NewTxOut(int64(numInputs)*1000, pkScript))
+		for j, txIn := range tx.TxIn {
+			sigScript, err := txscript.SignatureScript(tx, j,
+				pkScript, txscript.SigHashAll, privKey, true)
+			if err != nil {
+				tb.Fatalf("unable to sign input: %v", err)
+			}
+			txIn.SignatureScript = sigScript
+		}
+		msgBlock.AddTransaction(tx)
+	}
+	return ltcutil.NewBlock(&msgBlock), view
+}
+
+// TestCheckBlockScriptsBadSignature ensures a single input with a bad
+// signature fails the validation of the scripts of an entire block regardless
+// of the number of goroutines used to validate them.
+func TestCheckBlockScriptsBadSignature(t *testing.T) {
+	t.Parallel()
+
+	for _, numWorkers := range []int{0, 1, 2, 16} {
+		block, view := newScriptValTestBlock(t, 10, 5)
+		err := checkBlockScripts(block, view, txscript.ScriptBip16, nil,
+			nil, nil, numWorkers)
+		if err != nil {
+			t.Fatalf("%d workers: unexpected error for valid block: %v",
+				numWorkers, err)
+		}
+
+		// Replace the signature of the last input in the block with
+		// the otherwise valid signature of another input.
+		txns := block.MsgBlock().Transactions
+		txIns := txns[len(txns)-1].TxIn
+		txIns[len(txIns)-1].SignatureScript = txIns[0].SignatureScript
+		err = checkBlockScripts(block, view, txscript.ScriptBip16, nil,
+			nil, nil, numWorkers)
+		if rerr, ok := err.(RuleError); !ok ||
+			rerr.ErrorCode != ErrScriptValidation {
+
+			t.Fatalf("%d workers: got error %v, want %v", numWorkers,
+				err, ErrScriptValidation)
+		}
+	}
+}
+
+// TestCheckBlockScriptsScriptCache ensures the scripts of the inputs found in
+// the script cache, such as those of transactions previously accepted to the
+// mempool, are not executed again when validating a block unless the block
+// enforces flags they were not validated with.
+func TestCheckBlockScriptsScriptCache(t *testing.T) {
+	t.Parallel()
+
+	// Validate the transactions of the block the same way the mempool
+	// does, but only under the pay-to-script-hash rules for the first one.
+	block, view := newScriptValTestBlock(t, 3, 2)
+	scriptCache := txscript.NewScriptCache(100)
+	for i, tx := range block.Transactions()[1:] {
+		flags := txscript.StandardVerifyFlags
+		if i == 0 {
+			flags = txscript.ScriptBip16
+		}
+		err := ValidateTransactionScripts(tx, view, flags, nil, nil,
+			scriptCache)
+		if err != nil {
+			t.Fatalf("ValidateTransactionScripts: unexpected error: %v",
+				err)
+		}
+	}
+
+	// Replace the scripts of all spent outputs with a script which always
+	// fails so that executing the scripts of any input fails.
+	for _, tx := range block.Transactions()[1:] {
+		for _, txIn := range tx.MsgTx().TxIn {
+			prevOut := &txIn.PreviousOutPoint
+			entry := view.LookupEntry(&prevOut.Hash)
+			entry.sparseOutputs[prevOut.Index].pkScript =
+				[]byte{txscript.OP_FALSE}
+		}
+	}
+
+	// All of the inputs are known to be valid under the flags of the
+	// block before the soft fork so no scripts are executed.
+	stats := scriptCache.Stats()
+	err := checkBlockScripts(block, view, txscript.ScriptBip16, nil, nil,
+		scriptCache, 0)
+	if err != nil {
+		t.Fatalf("checkBlockScripts: unexpected error: %v", err)
+	}
+	hits := scriptCache.Stats().Hits - stats.Hits
+	if hits != 6 {
+		t.Fatalf("got %d script cache hits, want 6", hits)
+	}
+
+	// Once a soft fork activates, the inputs which were only validated
+	// under the previous rules must be validated again.
+	flags := txscript.ScriptBip16 | txscript.ScriptVerifyCheckLockTimeVerify
+	err = checkBlockScripts(block, view, flags, nil, nil, scriptCache, 0)
+	if rerr, ok := err.(RuleError); !ok ||
+		rerr.ErrorCode != ErrScriptValidation {
+
+		t.Fatalf("got error %v, want %v", err, ErrScriptValidation)
+	}
+
+	// The inputs of a block are not added to the script cache.
+	if entries := scriptCache.Stats().Entries; entries != 6 {
+		t.Fatalf("got %d script cache entries, want 6", entries)
+	}
+}
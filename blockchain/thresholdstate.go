@@ -271,6 +271,166 @@ func (b *BlockChain) ThresholdState(deploymentID uint32) (ThresholdState, error)
 	return state, err
 }
 
+// ThresholdStats houses the statistics of the blocks which signal for a rule
+// change in the threshold state retarget window containing the block AFTER the
+// end of the current best chain.
+type ThresholdStats struct {
+	// Period is the number of blocks in each threshold state retarget
+	// window.
+	Period uint32
+
+	// Threshold is the number of blocks in a window which must signal for
+	// the rule change to lock in.
+	Threshold uint32
+
+	// Elapsed is the number of blocks of the current window in the best
+	// chain, while Count is the number of those blocks which signal.
+	Elapsed uint32
+	Count   uint32
+
+	// Possible indicates whether the rule change can still lock in at the
+	// end of the current window.
+	Possible bool
+}
+
+// DeploymentStatus houses the state of a deployment for the block AFTER the
+// end of the current best chain.
+type DeploymentStatus struct {
+	// State is the threshold state of the deployment.
+	State ThresholdState
+
+	// Since is the height of the first block with the current threshold
+	// state.
+	Since int32
+
+	// Stats houses the signalling statistics of the current window.  It is
+	// only set while the deployment is in the ThresholdStarted state.
+	Stats *ThresholdStats
+}
+
+// thresholdStateSince returns the height of the first block which has the
+// same threshold state as the block AFTER the given node.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) thresholdStateSince(prevNode *blockNode, checker thresholdConditionChecker, cache *thresholdStateCache) (int32, error) {
+	state, err := b.thresholdState(prevNode, checker, cache)
+	if err != nil || state == ThresholdDefined {
+		return 0, err
+	}
+
+	// The state is the same for all blocks within a given window, so walk
+	// backwards through the last blocks of the previous windows until one
+	// with a different state is found.
+	confirmationWindow := int32(checker.MinerConfirmationWindow())
+	prevNode = prevNode.Ancestor(prevNode.height -
+		(prevNode.height+1)%confirmationWindow)
+	for {
+		prevWindowNode := prevNode.RelativeAncestor(confirmationWindow)
+		prevState, err := b.thresholdState(prevWindowNode, checker, cache)
+		if err != nil {
+			return 0, err
+		}
+		if prevWindowNode == nil || prevState != state {
+			break
+		}
+		prevNode = prevWindowNode
+	}
+
+	return prevNode.height + 1, nil
+}
+
+// thresholdStats returns the signalling statistics of the threshold state
+// retarget window containing the block AFTER the given node.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func thresholdStats(prevNode *blockNode, checker thresholdConditionChecker) (*ThresholdStats, error) {
+	stats := &ThresholdStats{
+		Period:    checker.MinerConfirmationWindow(),
+		Threshold: checker.RuleChangeActivationThreshold(),
+	}
+	stats.Elapsed = uint32(prevNode.height+1) % stats.Period
+
+	// Count the signalling blocks of the window in the chain.
+	countNode := prevNode
+	for i := uint32(0); i < stats.Elapsed; i++ {
+		condition, err := checker.Condition(countNode)
+		if err != nil {
+			return nil, err
+		}
+		if condition {
+			stats.Count++
+		}
+		countNode = countNode.parent
+	}
+
+	// The rule change can only lock in when the number of the remaining
+	// blocks of the window is enough to reach the threshold.
+	stats.Possible = stats.Period-stats.Threshold >= stats.Elapsed-stats.Count
+	return stats, nil
+}
+
+// deploymentStatus returns the status of the given deployment ID for the block
+// AFTER the passed node.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) deploymentStatus(prevNode *blockNode, deploymentID uint32) (*DeploymentStatus, error) {
+	if deploymentID >= uint32(len(b.chainParams.Deployments)) {
+		return nil, DeploymentError(deploymentID)
+	}
+
+	deployment := &b.chainParams.Deployments[deploymentID]
+	checker := deploymentChecker{deployment: deployment, chain: b}
+	cache := &b.deploymentCaches[deploymentID]
+
+	state, err := b.thresholdState(prevNode, checker, cache)
+	if err != nil {
+		return nil, err
+	}
+	since, err := b.thresholdStateSince(prevNode, checker, cache)
+	if err != nil {
+		return nil, err
+	}
+	status := &DeploymentStatus{State: state, Since: since}
+	if state == ThresholdStarted {
+		status.Stats, err = thresholdStats(prevNode, checker)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// DeploymentStatus returns the threshold state of the given deployment ID for
+// the block AFTER the end of the current best chain along with the height it
+// was reached at and, while the deployment is being signalled for, the
+// signalling statistics of the current threshold state retarget window.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) DeploymentStatus(deploymentID uint32) (*DeploymentStatus, error) {
+	b.chainLock.Lock()
+	status, err := b.deploymentStatus(b.bestChain.Tip(), deploymentID)
+	b.chainLock.Unlock()
+
+	return status, err
+}
+
+// DeploymentStatusByHash returns the status of the given deployment ID for the
+// block AFTER the block with the given hash in the main chain.  See
+// DeploymentStatus for details.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) DeploymentStatusByHash(hash *chainhash.Hash, deploymentID uint32) (*DeploymentStatus, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	node := b.index.LookupNode(hash)
+	if node == nil || !b.bestChain.Contains(node) {
+		str := fmt.Sprintf("block %s is not in the main chain", hash)
+		return nil, errNotInMainChain(str)
+	}
+	return b.deploymentStatus(node, deploymentID)
+}
+
 // IsDeploymentActive returns true if the target deploymentID is active, and
 // false otherwise.
 //
@@ -5,8 +5,11 @@
 package blockchain
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/ltcsuite/ltcd/chaincfg"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 )
 
@@ -132,3 +135,173 @@ nextTest:
 		}
 	}
 }
+
+// TestDeploymentStatus ensures the reported state of a deployment, the height
+// it was reached at and the signalling statistics follow a chain which signals
+// for the deployment through each of the threshold states.
+func TestDeploymentStatus(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.RegressionNetParams
+	deploymentID := uint32(chaincfg.DeploymentTestDummy)
+	bit := params.Deployments[deploymentID].BitNumber
+	signalVersion := int32(vbTopBits | uint32(1)<<bit)
+	window := params.MinerConfirmationWindow
+	threshold := params.RuleChangeActivationThreshold
+
+	chain := newFakeChain(params)
+	node := chain.bestChain.Tip()
+	blockTime := node.Header().Timestamp
+	addBlocks := func(numBlocks uint32, blockVersion int32) {
+		for i := uint32(0); i < numBlocks; i++ {
+			blockTime = blockTime.Add(time.Second)
+			node = newFakeNode(node, blockVersion, 0, blockTime)
+			chain.index.AddNode(node)
+			chain.bestChain.SetTip(node)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		numBlocks uint32
+		version   int32
+		want      DeploymentStatus
+	}{{
+		name: "genesis",
+		want: DeploymentStatus{State: ThresholdDefined},
+	}, {
+		name:      "first window",
+		numBlocks: window - 1,
+		version:   signalVersion,
+		want: DeploymentStatus{
+			State: ThresholdStarted,
+			Since: int32(window),
+			Stats: &ThresholdStats{
+				Period:    window,
+				Threshold: threshold,
+				Possible:  true,
+			},
+		},
+	}, {
+		name:      "started signalling",
+		numBlocks: 10,
+		version:   signalVersion,
+		want: DeploymentStatus{
+			State: ThresholdStarted,
+			Since: int32(window),
+			Stats: &ThresholdStats{
+				Period:    window,
+				Threshold: threshold,
+				Elapsed:   10,
+				Count:     10,
+				Possible:  true,
+			},
+		},
+	}, {
+		name:      "started not signalling",
+		numBlocks: window - threshold + 1,
+		version:   1,
+		want: DeploymentStatus{
+			State: ThresholdStarted,
+			Since: int32(window),
+			Stats: &ThresholdStats{
+				Period:    window,
+				Threshold: threshold,
+				Elapsed:   window - threshold + 11,
+				Count:     10,
+				Possible:  false,
+			},
+		},
+	}, {
+		name:      "second window failed to lock in",
+		numBlocks: threshold - 11,
+		version:   signalVersion,
+		want: DeploymentStatus{
+			State: ThresholdStarted,
+			Since: int32(window),
+			Stats: &ThresholdStats{
+				Period:    window,
+				Threshold: threshold,
+				Possible:  true,
+			},
+		},
+	}, {
+		name:      "locked in",
+		numBlocks: window,
+		version:   signalVersion,
+		want: DeploymentStatus{
+			State: ThresholdLockedIn,
+			Since: int32(window * 3),
+		},
+	}, {
+		name:      "active",
+		numBlocks: window,
+		version:   1,
+		want: DeploymentStatus{
+			State: ThresholdActive,
+			Since: int32(window * 4),
+		},
+	}, {
+		name:      "still active",
+		numBlocks: window + 1,
+		version:   1,
+		want: DeploymentStatus{
+			State: ThresholdActive,
+			Since: int32(window * 4),
+		},
+	}}
+	for _, test := range tests {
+		addBlocks(test.numBlocks, test.version)
+		status, err := chain.DeploymentStatus(deploymentID)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(*status, test.want) {
+			t.Fatalf("%s: got status %+v (stats %+v), want %+v "+
+				"(stats %+v)", test.name, *status, status.Stats,
+				test.want, test.want.Stats)
+		}
+	}
+
+	// Ensure unknown deployments are rejected.
+	_, err := chain.DeploymentStatus(chaincfg.DefinedDeployments)
+	if _, ok := err.(DeploymentError); !ok {
+		t.Fatalf("unknown deployment: got error %v, want DeploymentError",
+			err)
+	}
+}
+
+// TestWarnings ensures the warnings about unknown rules and versions describe
+// the conditions which have been detected.
+func TestWarnings(t *testing.T) {
+	t.Parallel()
+
+	chain := newFakeChain(&chaincfg.RegressionNetParams)
+	tests := []struct {
+		name     string
+		rules    bool
+		versions bool
+		want     string
+	}{{
+		name: "no warnings",
+		want: "",
+	}, {
+		name:  "unknown rules",
+		rules: true,
+		want:  "Warning: unknown new rules activated",
+	}, {
+		name:     "unknown rules and versions",
+		rules:    true,
+		versions: true,
+		want: "Warning: unknown new rules activated; Warning: " +
+			"unknown block versions are being mined, so new rules " +
+			"might be in effect",
+	}}
+	for _, test := range tests {
+		chain.unknownRulesWarned = test.rules
+		chain.unknownVersionsWarned = test.versions
+		if got := chain.Warnings(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,414 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcutil"
+)
+
+const (
+	// utxoCacheEntryOverhead is the approximate number of bytes of memory
+	// used by each entry of the utxo cache in addition to its serialized
+	// utxo entry.  It accounts for the transaction hash the entry is keyed
+	// by along with the bookkeeping of the entry itself.
+	utxoCacheEntryOverhead = chainhash.HashSize + 48
+
+	// utxoFlushInterval is the maximum time the changes to the utxo set
+	// are held in the utxo cache before they are written to the database
+	// when connecting blocks.  This limits the number of blocks which
+	// have to be connected again to restore the utxo set after a crash.
+	utxoFlushInterval = 10 * time.Minute
+
+	// utxoStateSize is the size of the serialized utxo state, which
+	// consists of the hash and height of the block the utxo set in the
+	// database corresponds to.
+	utxoStateSize = chainhash.HashSize + 4
+)
+
+var (
+	// utxoStateKeyName is the name of the db key used to store the hash
+	// and height of the block the utxo set in the database corresponds to.
+	// It lags behind the best block while changes to the utxo set are
+	// held in the utxo cache.
+	utxoStateKeyName = []byte("utxostate")
+)
+
+// dbPutUtxoState uses an existing database transaction to record that the utxo
+// set in the database corresponds to the passed block.
+func dbPutUtxoState(dbTx database.Tx, hash *chainhash.Hash, height int32) error {
+	var serialized [utxoStateSize]byte
+	copy(serialized[:], hash[:])
+	byteOrder.PutUint32(serialized[chainhash.HashSize:], uint32(height))
+	return dbTx.Metadata().Put(utxoStateKeyName, serialized[:])
+}
+
+// dbFetchUtxoState uses an existing database transaction to retrieve the hash
+// and height of the block the utxo set in the database corresponds to.  A nil
+// hash is returned when the database was last used by a version which did not
+// record it, in which case the utxo set corresponds to the best block.
+func dbFetchUtxoState(dbTx database.Tx) (*chainhash.Hash, int32, error) {
+	serialized := dbTx.Metadata().Get(utxoStateKeyName)
+	if serialized == nil {
+		return nil, 0, nil
+	}
+	if len(serialized) != utxoStateSize {
+		return nil, 0, database.Error{
+			ErrorCode: database.ErrCorruption,
+			Description: fmt.Sprintf("corrupt utxo state of %d "+
+				"bytes", len(serialized)),
+		}
+	}
+
+	var hash chainhash.Hash
+	copy(hash[:], serialized[:chainhash.HashSize])
+	height := int32(byteOrder.Uint32(serialized[chainhash.HashSize:]))
+	return &hash, height, nil
+}
+
+// cachedUtxoEntry houses a serialized utxo entry held in the utxo cache.
+type cachedUtxoEntry struct {
+	// serialized is the entry as stored in the utxo set.  It is nil when
+	// the transaction is fully spent or does not exist.
+	serialized []byte
+
+	// modified indicates the entry differs from the utxo set in the
+	// database.
+	modified bool
+}
+
+// utxoCache sits between the utxo views used to validate and connect blocks
+// and the utxo set stored in the database.  It keeps the utxo entries which
+// were loaded from the database in memory along with the changes made to them
+// by connecting blocks, which are written to the database in large batches
+// instead of once per block.
+//
+// The changes are written to the database, which is referred to as flushing
+// the cache, once the cache exceeds its maximum size or the flush interval has
+// passed, as well as whenever a block is disconnected.  The hash and height of
+// the block the utxo set in the database corresponds to are updated within the
+// same database transaction, so the changes which were lost by a crash are
+// restored by connecting the blocks after it again.
+type utxoCache struct {
+	db      database.DB
+	maxSize uint64
+
+	// mtx protects the fields below since the cache is also populated by
+	// lookups which only hold the chain lock for reads.
+	mtx     sync.Mutex
+	entries map[chainhash.Hash]cachedUtxoEntry
+	size    uint64
+
+	// flushedHeight is the height of the block the utxo set in the
+	// database corresponds to and lastFlush is the time it was updated.
+	flushedHeight int32
+	lastFlush     time.Time
+
+	// dbFetches is the total number of entries which were loaded from the
+	// database.
+	dbFetches uint64
+}
+
+// newUtxoCache returns a new utxo cache for the utxo set in the passed
+// database which holds up to about the passed number of bytes.  A maximum size
+// of zero causes the changes of every block to be written to the database
+// when it is connected.
+func newUtxoCache(db database.DB, maxSize uint64) *utxoCache {
+	return &utxoCache{
+		db:        db,
+		maxSize:   maxSize,
+		entries:   make(map[chainhash.Hash]cachedUtxoEntry),
+		lastFlush: time.Now(),
+	}
+}
+
+// put adds the passed serialized entry to the cache or replaces the existing
+// one while accounting for its size.
+//
+// This function MUST be called with the cache lock held.
+func (c *utxoCache) put(txHash *chainhash.Hash, serialized []byte, modified bool) {
+	if existing, ok := c.entries[*txHash]; ok {
+		c.size -= uint64(len(existing.serialized))
+	} else {
+		c.size += utxoCacheEntryOverhead
+	}
+	c.size += uint64(len(serialized))
+	c.entries[*txHash] = cachedUtxoEntry{
+		serialized: serialized,
+		modified:   modified,
+	}
+}
+
+// fetchEntries loads the utxo entries for the passed set of transaction hashes
+// into the passed view, either from the cache or from the database.  The
+// entries loaded from the database are added to the cache unless it is full.
+// Fully spent transactions, or those which otherwise don't exist, will result
+// in a nil entry in the view.
+//
+// This function is safe for concurrent access.
+func (c *utxoCache) fetchEntries(view *UtxoViewpoint, txSet map[chainhash.Hash]struct{}) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	var missing []chainhash.Hash
+	for txHash := range txSet {
+		cached, ok := c.entries[txHash]
+		if !ok {
+			missing = append(missing, txHash)
+			continue
+		}
+
+		hashCopy := txHash
+		entry, err := decodeUtxoEntry(&hashCopy, cached.serialized)
+		if err != nil {
+			return err
+		}
+		view.entries[txHash] = entry
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return c.db.View(func(dbTx database.Tx) error {
+		utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+		for i := range missing {
+			// Copy the serialized entry since it is only valid for
+			// the duration of the database transaction.
+			txHash := &missing[i]
+			var serialized []byte
+			if dbEntry := utxoBucket.Get(txHash[:]); dbEntry != nil {
+				serialized = make([]byte, len(dbEntry))
+				copy(serialized, dbEntry)
+			}
+			c.dbFetches++
+
+			entry, err := decodeUtxoEntry(txHash, serialized)
+			if err != nil {
+				return err
+			}
+			view.entries[*txHash] = entry
+
+			if c.size < c.maxSize {
+				c.put(txHash, serialized, false)
+			}
+		}
+
+		return nil
+	})
+}
+
+// needsFlush returns whether the changes held in the cache should be written
+// to the database along with the next block which is connected.
+//
+// This function is safe for concurrent access.
+func (c *utxoCache) needsFlush() bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	return c.size >= c.maxSize || time.Since(c.lastFlush) >= utxoFlushInterval
+}
+
+// flush uses an existing database transaction to write all of the changes held
+// in the cache to the utxo set in the database, followed by the passed
+// serialized entries which were modified by the block the utxo set corresponds
+// to afterwards.  The cache itself is not updated until commit is called once
+// the database transaction succeeded.
+//
+// This function is safe for concurrent access.
+func (c *utxoCache) flush(dbTx database.Tx, serializedEntries map[chainhash.Hash][]byte, hash *chainhash.Hash, height int32) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for txHashIter, cached := range c.entries {
+		if !cached.modified {
+			continue
+		}
+		if _, ok := serializedEntries[txHashIter]; ok {
+			continue
+		}
+
+		txHash := txHashIter
+		err := dbPutUtxoEntry(dbTx, &txHash, cached.serialized)
+		if err != nil {
+			return err
+		}
+	}
+	for txHashIter, serialized := range serializedEntries {
+		txHash := txHashIter
+		if err := dbPutUtxoEntry(dbTx, &txHash, serialized); err != nil {
+			return err
+		}
+	}
+
+	return dbPutUtxoState(dbTx, hash, height)
+}
+
+// commit updates the cache with the passed serialized entries which were
+// modified by connecting or disconnecting a block.  When the cache was flushed
+// along with the block, all of the entries are marked as matching the database
+// and the cache is emptied if it exceeds its maximum size.
+//
+// This function is safe for concurrent access.
+func (c *utxoCache) commit(serializedEntries map[chainhash.Hash][]byte, flushed bool, height int32) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for txHashIter, serialized := range serializedEntries {
+		txHash := txHashIter
+		c.put(&txHash, serialized, !flushed)
+	}
+	if !flushed {
+		return
+	}
+
+	if c.size >= c.maxSize {
+		c.entries = make(map[chainhash.Hash]cachedUtxoEntry)
+		c.size = 0
+	} else {
+		for txHash, cached := range c.entries {
+			if cached.modified {
+				cached.modified = false
+				c.entries[txHash] = cached
+			}
+		}
+	}
+	c.flushedHeight = height
+	c.lastFlush = time.Now()
+	log.Debugf("Flushed the utxo cache at height %d (%d entries, %d "+
+		"bytes, %d loaded from the database)", height, len(c.entries),
+		c.size, c.dbFetches)
+}
+
+// empty removes all of the entries from the cache.  It must only be called
+// when the cache does not hold any changes.
+//
+// This function is safe for concurrent access.
+func (c *utxoCache) empty() {
+	c.mtx.Lock()
+	c.entries = make(map[chainhash.Hash]cachedUtxoEntry)
+	c.size = 0
+	c.mtx.Unlock()
+}
+
+// flushUtxoCache writes all of the changes held in the utxo cache to the
+// database so the utxo set in the database corresponds to the best block.  The
+// cache is emptied afterwards when requested.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) flushUtxoCache(empty bool) error {
+	tip := b.bestChain.Tip()
+	err := b.db.Update(func(dbTx database.Tx) error {
+		return b.utxoCache.flush(dbTx, nil, &tip.hash, tip.height)
+	})
+	if err != nil {
+		return err
+	}
+	b.utxoCache.commit(nil, true, tip.height)
+
+	if empty {
+		b.utxoCache.empty()
+	}
+	return nil
+}
+
+// FlushUtxoCache writes all of the changes to the utxo set held in memory to
+// the database.  It should be called before the database is closed in order to
+// avoid connecting the most recent blocks again to restore the utxo set the
+// next time the chain is created.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) FlushUtxoCache() error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	return b.flushUtxoCache(false)
+}
+
+// initUtxoCache restores the changes to the utxo set which were held in the
+// utxo cache and lost, such as due to a crash, by connecting the blocks after
+// the block the utxo set in the database corresponds to again.  The blocks were
+// validated before, so only the utxos they spend and create are processed.
+//
+// This must be called after the chain state is loaded.
+func (b *BlockChain) initUtxoCache() error {
+	var utxoHash *chainhash.Hash
+	var utxoHeight int32
+	err := b.db.View(func(dbTx database.Tx) error {
+		var err error
+		utxoHash, utxoHeight, err = dbFetchUtxoState(dbTx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	tip := b.bestChain.Tip()
+	b.utxoCache.flushedHeight = tip.height
+	if utxoHash == nil || *utxoHash == tip.hash {
+		return nil
+	}
+
+	// The blocks are always written to the database before the utxo set
+	// is updated and the utxo set is flushed whenever a block is
+	// disconnected, so the utxo set can only lag behind the main chain.
+	node := b.bestChain.NodeByHeight(utxoHeight)
+	if node == nil || node.hash != *utxoHash {
+		return fmt.Errorf("the utxo set corresponds to block %v (height "+
+			"%d) which is not in the main chain -- the chain state "+
+			"must be reindexed", utxoHash, utxoHeight)
+	}
+	b.utxoCache.flushedHeight = utxoHeight
+
+	log.Infof("Restoring the utxo set from height %d to %d", utxoHeight,
+		tip.height)
+	for node = b.bestChain.Next(node); node != nil; node = b.bestChain.Next(node) {
+		var block *ltcutil.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			block, err = dbFetchBlockByNode(dbTx, node)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		view := NewUtxoViewpoint()
+		view.SetBestHash(&node.parent.hash)
+		if err := view.fetchInputUtxos(b.utxoCache, block); err != nil {
+			return err
+		}
+		if err := view.connectTransactions(block, nil); err != nil {
+			return err
+		}
+		serializedEntries, err := serializeUtxoView(view)
+		if err != nil {
+			return err
+		}
+		b.utxoCache.commit(serializedEntries, false, node.height)
+	}
+
+	return b.flushUtxoCache(false)
+}
+
+// utxoSetSnapshot flushes the utxo cache and returns a read-only database
+// transaction which provides a consistent view of the utxo set as of the best
+// block without blocking the chain from processing new blocks in the meantime.
+// The caller must roll back the transaction once done with it.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) utxoSetSnapshot() (database.Tx, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	if err := b.flushUtxoCache(false); err != nil {
+		return nil, err
+	}
+	return b.db.Begin(false)
+}
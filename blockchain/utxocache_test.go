@@ -0,0 +1,268 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// newUtxoCacheTestBlocks returns a chain of the passed number of blocks which
+// builds on the genesis block of the passed network, where each block spends
+// the coinbase of its parent.
+func newUtxoCacheTestBlocks(tb testing.TB, params *chaincfg.Params, numBlocks int32) []*ltcutil.Block {
+	blocks := make([]*ltcutil.Block, 0, numBlocks)
+	prevHash := params.GenesisHash
+	for height := int32(1); height <= numBlocks; height++ {
+		var block *ltcutil.Block
+		if len(blocks) == 0 {
+			block = newReindexTestBlock(tb, params, prevHash, height,
+				nil)
+		} else {
+			prevCoinbase := blocks[len(blocks)-1].MsgBlock().Transactions[0]
+			block = newReindexTestBlock(tb, params, prevHash, height,
+				prevCoinbase)
+		}
+		blocks = append(blocks, block)
+		prevHash = block.Hash()
+	}
+	return blocks
+}
+
+// newUtxoCacheTestChain returns a regression test network chain instance which
+// uses the passed database and utxo cache size along with a function which
+// processes the passed blocks with it.
+func newUtxoCacheTestChain(tb testing.TB, db database.DB, params *chaincfg.Params,
+	maxSize uint64) (*BlockChain, func([]*ltcutil.Block)) {
+
+	chain, err := New(&Config{
+		DB:               db,
+		ChainParams:      params,
+		TimeSource:       NewMedianTime(),
+		UtxoCacheMaxSize: maxSize,
+	})
+	if err != nil {
+		tb.Fatalf("failed to create chain instance: %v", err)
+	}
+	chain.TstSetCoinbaseMaturity(1)
+
+	processBlocks := func(blocks []*ltcutil.Block) {
+		for _, block := range blocks {
+			_, isOrphan, err := chain.ProcessBlock(block, BFNone)
+			if err != nil {
+				tb.Fatalf("unable to process block %d: %v",
+					block.Height(), err)
+			}
+			if isOrphan {
+				tb.Fatalf("block %d is an orphan", block.Height())
+			}
+		}
+	}
+	return chain, processBlocks
+}
+
+// createUtxoCacheTestDB creates a database with the passed name in the
+// temporary directory.  The returned function closes and removes it.
+func createUtxoCacheTestDB(tb testing.TB, name string) (database.DB, func()) {
+	dbPath := filepath.Join(os.TempDir(), name)
+	_ = os.RemoveAll(dbPath)
+	db, err := database.Create(testDbType, dbPath, blockDataNet)
+	if err != nil {
+		tb.Fatalf("unable to create db: %v", err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dbPath)
+	}
+}
+
+// TestUtxoCacheFlush ensures the changes to the utxo set held in the utxo cache
+// are only written to the database when the cache is flushed, that they are
+// visible through the chain before then, and that the changes which were lost
+// when the chain is not shut down cleanly are restored the next time the chain
+// is created.
+func TestUtxoCacheFlush(t *testing.T) {
+	paramsCopy := chaincfg.RegressionNetParams
+	const numBlocks = 20
+	blocks := newUtxoCacheTestBlocks(t, &paramsCopy, numBlocks)
+
+	// Create the expected chain state by writing the changes of every
+	// block to the database as it is connected.
+	refDB, teardownRef := createUtxoCacheTestDB(t, "utxocacheref")
+	defer teardownRef()
+	_, processRef := newUtxoCacheTestChain(t, refDB, &paramsCopy, 0)
+	for _, block := range blocks {
+		processRef([]*ltcutil.Block{block})
+		err := refDB.View(func(dbTx database.Tx) error {
+			hash, height, err := dbFetchUtxoState(dbTx)
+			if err != nil {
+				return err
+			}
+			if *hash != *block.Hash() || height != block.Height() {
+				t.Fatalf("utxo state %v (height %d) does not match "+
+					"block %d", hash, height, block.Height())
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("View: %v", err)
+		}
+	}
+	want := dumpBuckets(t, refDB)
+
+	db, teardown := createUtxoCacheTestDB(t, "utxocacheflush")
+	defer teardown()
+	chain, processBlocks := newUtxoCacheTestChain(t, db, &paramsCopy, 1<<20)
+
+	// checkUtxoState ensures the utxo set in the database corresponds to the
+	// block at the passed height.
+	checkUtxoState := func(wantHeight int32) {
+		wantHash := paramsCopy.GenesisHash
+		if wantHeight > 0 {
+			wantHash = blocks[wantHeight-1].Hash()
+		}
+		err := db.View(func(dbTx database.Tx) error {
+			hash, height, err := dbFetchUtxoState(dbTx)
+			if err != nil {
+				return err
+			}
+			if *hash != *wantHash || height != wantHeight {
+				t.Fatalf("utxo state %v (height %d), want %v "+
+					"(height %d)", hash, height, wantHash,
+					wantHeight)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("View: %v", err)
+		}
+	}
+
+	// checkCoinbase ensures the coinbase output of the block at the passed
+	// height is unspent in the database and the chain as expected.
+	checkCoinbase := func(chain *BlockChain, height int32, inDB, inChain bool) {
+		txHash := blocks[height-1].Transactions()[0].Hash()
+		err := db.View(func(dbTx database.Tx) error {
+			utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+			if got := utxoBucket.Get(txHash[:]) != nil; got != inDB {
+				t.Fatalf("coinbase of block %d in database: %v, "+
+					"want %v", height, got, inDB)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("View: %v", err)
+		}
+		entry, err := chain.FetchUtxoEntry(txHash)
+		if err != nil {
+			t.Fatalf("FetchUtxoEntry: %v", err)
+		}
+		got := entry != nil && !entry.IsOutputSpent(0)
+		if got != inChain {
+			t.Fatalf("coinbase of block %d unspent: %v, want %v",
+				height, got, inChain)
+		}
+	}
+
+	// The changes are held in the cache until it is flushed mid-chain.
+	const flushHeight = numBlocks / 2
+	processBlocks(blocks[:flushHeight])
+	checkUtxoState(0)
+	checkCoinbase(chain, flushHeight, false, true)
+	if err := chain.FlushUtxoCache(); err != nil {
+		t.Fatalf("FlushUtxoCache: %v", err)
+	}
+	checkUtxoState(flushHeight)
+	checkCoinbase(chain, flushHeight, true, true)
+
+	// The blocks connected after the flush spend the outputs which were
+	// written to the database, which must still be spent in the chain.
+	processBlocks(blocks[flushHeight:])
+	checkUtxoState(flushHeight)
+	checkCoinbase(chain, flushHeight, true, false)
+	checkCoinbase(chain, numBlocks, false, true)
+
+	// Simulate a crash by creating a new chain instance without flushing
+	// the cache and ensure the lost changes are restored.
+	chain, _ = newUtxoCacheTestChain(t, db, &paramsCopy, 1<<20)
+	if got := chain.BestSnapshot(); got.Hash != *blocks[numBlocks-1].Hash() {
+		t.Fatalf("best block %v, want %v", got.Hash,
+			blocks[numBlocks-1].Hash())
+	}
+	checkUtxoState(numBlocks)
+	checkCoinbase(chain, flushHeight, false, false)
+	checkCoinbase(chain, numBlocks, true, true)
+	if got := dumpBuckets(t, db); !reflect.DeepEqual(got, want) {
+		t.Fatal("restored chain state does not match the expected one")
+	}
+	if err := chain.VerifyChain(MaxVerifyLevel, 0, nil); err != nil {
+		t.Fatalf("VerifyChain: %v", err)
+	}
+
+	// Ensure a utxo state which is not in the main chain is rejected.
+	err := db.Update(func(dbTx database.Tx) error {
+		return dbPutUtxoState(dbTx, &chainhash.Hash{0x01}, flushHeight)
+	})
+	if err != nil {
+		t.Fatalf("unable to store utxo state: %v", err)
+	}
+	_, err = New(&Config{
+		DB:          db,
+		ChainParams: &paramsCopy,
+		TimeSource:  NewMedianTime(),
+	})
+	if err == nil {
+		t.Fatal("utxo state outside of the main chain was accepted")
+	}
+}
+
+// benchmarkConnectBlocks benchmarks connecting a chain of blocks which spend the
+// coinbase of their parent to a new database with the passed utxo cache size.
+func benchmarkConnectBlocks(b *testing.B, maxSize uint64) {
+	paramsCopy := chaincfg.RegressionNetParams
+	const numBlocks = 100
+	blocks := newUtxoCacheTestBlocks(b, &paramsCopy, numBlocks)
+
+	var dbFetches uint64
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		db, teardown := createUtxoCacheTestDB(b, "utxocachebench")
+		chain, processBlocks := newUtxoCacheTestChain(b, db, &paramsCopy,
+			maxSize)
+		b.StartTimer()
+
+		processBlocks(blocks)
+		if err := chain.FlushUtxoCache(); err != nil {
+			b.Fatalf("FlushUtxoCache: %v", err)
+		}
+
+		b.StopTimer()
+		dbFetches += chain.utxoCache.dbFetches
+		teardown()
+		b.StartTimer()
+	}
+	b.Logf("%.2f utxo entries loaded from the database per block",
+		float64(dbFetches)/float64(b.N*numBlocks))
+}
+
+// BenchmarkConnectBlocksUtxoCache performs a benchmark of connecting blocks
+// while holding the changes to the utxo set in the utxo cache.
+func BenchmarkConnectBlocksUtxoCache(b *testing.B) {
+	benchmarkConnectBlocks(b, 1<<20)
+}
+
+// BenchmarkConnectBlocksNoUtxoCache performs a benchmark of connecting blocks
+// while writing the changes to the utxo set of every block to the database.
+func BenchmarkConnectBlocksNoUtxoCache(b *testing.B) {
+	benchmarkConnectBlocks(b, 0)
+}
@@ -0,0 +1,576 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+const (
+	// UtxoSnapshotVersion is the version of the utxo snapshot format which
+	// is written by DumpUtxoSnapshot.  Snapshots with any other version are
+	// rejected by LoadUtxoSnapshot.
+	UtxoSnapshotVersion = 1
+
+	// utxoSnapshotHeaderSize is the size of a serialized utxo snapshot
+	// header.
+	utxoSnapshotHeaderSize = 4 + 4 + 4 + chainhash.HashSize + 4 + 8
+)
+
+var (
+	// utxoSnapshotMagic identifies the start of a serialized utxo snapshot.
+	utxoSnapshotMagic = [4]byte{'u', 't', 'x', 'o'}
+
+	// utxoSnapshotKeyName is the name of the db key used to store the
+	// header and commitment of a loaded utxo snapshot whose base block has
+	// not been validated yet.
+	utxoSnapshotKeyName = []byte("utxosnapshot")
+)
+
+// UtxoSnapshot describes a serialized snapshot of the utxo set as of the end
+// of a block in the main chain, which is referred to as its base block.
+type UtxoSnapshot struct {
+	Version    uint32
+	Net        wire.BitcoinNet
+	BaseHash   chainhash.Hash
+	BaseHeight int32
+	NumCoins   uint64
+	Commitment chainhash.Hash
+}
+
+// -----------------------------------------------------------------------------
+// A utxo snapshot consists of a header, a record for every transaction with
+// unspent outputs in ascending order of the transaction hashes, and a
+// commitment to the records.
+//
+// The serialized format of the header is:
+//
+//   <magic><version><network><base hash><base height><num coins>
+//
+//   Field          Type      Size
+//   magic          [4]byte   4
+//   version        uint32    4
+//   network        uint32    4
+//   base hash      [32]byte  32
+//   base height    uint32    4
+//   num coins      uint64    8
+//
+// The magic is the ASCII string "utxo" and the number of coins is the total
+// number of unspent outputs in the snapshot.
+//
+// The serialized format of each transaction record is:
+//
+//   <tx hash><tx version><height code><num outputs>[<output index><amount><pk script>,...]
+//
+//   Field          Type      Size
+//   tx hash        [32]byte  32
+//   tx version     int32     4
+//   height code    VarInt    variable
+//   num outputs    VarInt    variable
+//   output index   VarInt    variable
+//   amount         uint64    8
+//   pk script      VarBytes  variable
+//
+// The height code is the height of the block containing the transaction
+// shifted left one bit with the lowest bit set when the transaction is a
+// coinbase.  The outputs are in ascending order of their index.
+//
+// The commitment is the double sha256 of all of the serialized transaction
+// records.  All integers are little endian.
+// -----------------------------------------------------------------------------
+
+// serializeUtxoSnapshotHeader returns the serialized header of the passed utxo
+// snapshot.
+func serializeUtxoSnapshotHeader(snapshot *UtxoSnapshot) []byte {
+	serialized := make([]byte, utxoSnapshotHeaderSize)
+	offset := copy(serialized, utxoSnapshotMagic[:])
+	byteOrder.PutUint32(serialized[offset:], snapshot.Version)
+	offset += 4
+	byteOrder.PutUint32(serialized[offset:], uint32(snapshot.Net))
+	offset += 4
+	offset += copy(serialized[offset:], snapshot.BaseHash[:])
+	byteOrder.PutUint32(serialized[offset:], uint32(snapshot.BaseHeight))
+	offset += 4
+	byteOrder.PutUint64(serialized[offset:], snapshot.NumCoins)
+	return serialized
+}
+
+// deserializeUtxoSnapshotHeader decodes the passed serialized utxo snapshot
+// header.  An error is returned when the magic or version is not recognized.
+func deserializeUtxoSnapshotHeader(serialized []byte) (*UtxoSnapshot, error) {
+	if len(serialized) < utxoSnapshotHeaderSize {
+		return nil, errors.New("utxo snapshot header is truncated")
+	}
+	if !bytes.Equal(serialized[:4], utxoSnapshotMagic[:]) {
+		return nil, errors.New("data is not a utxo snapshot")
+	}
+	offset := 4
+	snapshot := &UtxoSnapshot{Version: byteOrder.Uint32(serialized[offset:])}
+	offset += 4
+	if snapshot.Version != UtxoSnapshotVersion {
+		return nil, fmt.Errorf("unsupported utxo snapshot version %d",
+			snapshot.Version)
+	}
+	snapshot.Net = wire.BitcoinNet(byteOrder.Uint32(serialized[offset:]))
+	offset += 4
+	offset += copy(snapshot.BaseHash[:], serialized[offset:])
+	snapshot.BaseHeight = int32(byteOrder.Uint32(serialized[offset:]))
+	offset += 4
+	snapshot.NumCoins = byteOrder.Uint64(serialized[offset:])
+	return snapshot, nil
+}
+
+// writeUtxoSnapshotTx writes the transaction record of the unspent outputs of
+// the passed utxo entry to the passed writer.
+func writeUtxoSnapshotTx(w io.Writer, txHash *chainhash.Hash, entry *UtxoEntry) error {
+	outputOrder := make([]int, 0, len(entry.sparseOutputs))
+	for outputIndex, output := range entry.sparseOutputs {
+		if output.spent {
+			continue
+		}
+		outputOrder = append(outputOrder, int(outputIndex))
+	}
+	sort.Ints(outputOrder)
+
+	var buf [8]byte
+	if _, err := w.Write(txHash[:]); err != nil {
+		return err
+	}
+	byteOrder.PutUint32(buf[:4], uint32(entry.version))
+	if _, err := w.Write(buf[:4]); err != nil {
+		return err
+	}
+	heightCode := uint64(entry.blockHeight) << 1
+	if entry.isCoinBase {
+		heightCode |= 0x01
+	}
+	if err := wire.WriteVarInt(w, 0, heightCode); err != nil {
+		return err
+	}
+	if err := wire.WriteVarInt(w, 0, uint64(len(outputOrder))); err != nil {
+		return err
+	}
+
+	for _, outputIndex := range outputOrder {
+		index := uint32(outputIndex)
+		if err := wire.WriteVarInt(w, 0, uint64(index)); err != nil {
+			return err
+		}
+		byteOrder.PutUint64(buf[:], uint64(entry.AmountByIndex(index)))
+		if _, err := w.Write(buf[:]); err != nil {
+			return err
+		}
+		err := wire.WriteVarBytes(w, 0, entry.PkScriptByIndex(index))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readUtxoSnapshotTx reads a transaction record from the passed reader and
+// returns the transaction hash along with a utxo entry for its unspent
+// outputs.  The record must not contain more than the passed number of
+// outputs.
+func readUtxoSnapshotTx(r io.Reader, maxOutputs uint64) (*chainhash.Hash, *UtxoEntry, error) {
+	var txHash chainhash.Hash
+	if _, err := io.ReadFull(r, txHash[:]); err != nil {
+		return nil, nil, err
+	}
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:4]); err != nil {
+		return nil, nil, err
+	}
+	version := int32(byteOrder.Uint32(buf[:4]))
+	heightCode, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	numOutputs, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	if numOutputs == 0 || numOutputs > maxOutputs {
+		return nil, nil, fmt.Errorf("transaction %v has an invalid "+
+			"number of unspent outputs %d", txHash, numOutputs)
+	}
+
+	entry := newUtxoEntry(version, heightCode&0x01 != 0,
+		int32(heightCode>>1))
+	var prevIndex uint64
+	for i := uint64(0); i < numOutputs; i++ {
+		index, err := wire.ReadVarInt(r, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		if index > uint64(wire.MaxPrevOutIndex) ||
+			(i > 0 && index <= prevIndex) {
+
+			return nil, nil, fmt.Errorf("transaction %v has an "+
+				"invalid output index %d", txHash, index)
+		}
+		prevIndex = index
+
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return nil, nil, err
+		}
+		pkScript, err := wire.ReadVarBytes(r, 0, txscript.MaxScriptSize,
+			"pk script")
+		if err != nil {
+			return nil, nil, err
+		}
+		entry.sparseOutputs[uint32(index)] = &utxoOutput{
+			amount:   int64(byteOrder.Uint64(buf[:])),
+			pkScript: pkScript,
+		}
+	}
+	return &txHash, entry, nil
+}
+
+// dbPutUtxoSnapshot uses an existing database transaction to store the header
+// and commitment of a loaded utxo snapshot.
+func dbPutUtxoSnapshot(dbTx database.Tx, snapshot *UtxoSnapshot) error {
+	serialized := serializeUtxoSnapshotHeader(snapshot)
+	serialized = append(serialized, snapshot.Commitment[:]...)
+	return dbTx.Metadata().Put(utxoSnapshotKeyName, serialized)
+}
+
+// dbFetchUtxoSnapshot uses an existing database transaction to retrieve the
+// header and commitment of a loaded utxo snapshot whose base block has not been
+// validated yet.  nil is returned when there is no such snapshot.
+func dbFetchUtxoSnapshot(dbTx database.Tx) (*UtxoSnapshot, error) {
+	serialized := dbTx.Metadata().Get(utxoSnapshotKeyName)
+	if serialized == nil {
+		return nil, nil
+	}
+	if len(serialized) != utxoSnapshotHeaderSize+chainhash.HashSize {
+		return nil, database.Error{
+			ErrorCode:   database.ErrCorruption,
+			Description: "corrupt utxo snapshot",
+		}
+	}
+	snapshot, err := deserializeUtxoSnapshotHeader(serialized)
+	if err != nil {
+		return nil, database.Error{
+			ErrorCode:   database.ErrCorruption,
+			Description: fmt.Sprintf("corrupt utxo snapshot: %v", err),
+		}
+	}
+	copy(snapshot.Commitment[:], serialized[utxoSnapshotHeaderSize:])
+	return snapshot, nil
+}
+
+// DumpUtxoSnapshot writes a snapshot of the utxo set as of the end of the
+// current best block to the passed writer.  The snapshot is taken against a
+// consistent view of the utxo set which does not block the chain from
+// processing new blocks in the meantime.  The changes held in the utxo cache
+// are written to the database beforehand.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) DumpUtxoSnapshot(w io.Writer) (*UtxoSnapshot, error) {
+	if b.PendingUtxoSnapshot() != nil {
+		return nil, errors.New("the utxo set can not be dumped while a " +
+			"loaded utxo snapshot is pending validation")
+	}
+
+	dbTx, err := b.utxoSetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer dbTx.Rollback()
+
+	state, err := deserializeBestChainState(dbTx.Metadata().Get(
+		chainStateKeyName))
+	if err != nil {
+		return nil, err
+	}
+
+	// The number of coins precedes them in the snapshot, so count them in
+	// a separate pass over the same view of the utxo set.
+	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+	var numCoins uint64
+	err = forEachUtxoEntry(utxoBucket, func(_ *chainhash.Hash, entry *UtxoEntry) error {
+		for _, output := range entry.sparseOutputs {
+			if !output.spent {
+				numCoins++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &UtxoSnapshot{
+		Version:    UtxoSnapshotVersion,
+		Net:        b.chainParams.Net,
+		BaseHash:   state.hash,
+		BaseHeight: int32(state.height),
+		NumCoins:   numCoins,
+	}
+	_, err = w.Write(serializeUtxoSnapshotHeader(snapshot))
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	txWriter := io.MultiWriter(w, hasher)
+	err = forEachUtxoEntry(utxoBucket, func(txHash *chainhash.Hash, entry *UtxoEntry) error {
+		return writeUtxoSnapshotTx(txWriter, txHash, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Commitment = chainhash.Hash(sha256.Sum256(hasher.Sum(nil)))
+	if _, err := w.Write(snapshot.Commitment[:]); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// LoadUtxoSnapshot reads a utxo snapshot from the passed reader and loads it
+// into the utxo set, which is only possible for a chain which does not have
+// any blocks after the genesis block.  The snapshot is rejected when it is for
+// a different network or its contents do not match its commitment, in which
+// case the utxo set is left untouched.
+//
+// Once loaded, the snapshot is pending validation of its base block, which is
+// persisted across restarts.  In the meantime, the blocks leading up to the
+// base block are checked and stored without being connected since the utxo set
+// already reflects them, and the base block becomes the end of the main chain
+// once it is accepted.  See acceptUtxoSnapshotBlock for details.
+//
+// Snapshots can't be loaded when optional indexes are enabled since they would
+// have to be built from the blocks which are not connected.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) LoadUtxoSnapshot(r io.Reader) (*UtxoSnapshot, error) {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	if b.utxoSnapshot != nil {
+		return nil, errors.New("a utxo snapshot is already loaded")
+	}
+	if b.indexManager != nil {
+		return nil, errors.New("utxo snapshots may not be loaded " +
+			"while optional indexes are enabled")
+	}
+	if b.bestChain.Height() != 0 {
+		return nil, errors.New("utxo snapshots may only be loaded " +
+			"into a chain without any blocks after the genesis block")
+	}
+
+	// The snapshot is loaded into the utxo set in the database directly,
+	// so the utxo cache must not hold any entries which it replaces.
+	if err := b.flushUtxoCache(true); err != nil {
+		return nil, err
+	}
+
+	serializedHeader := make([]byte, utxoSnapshotHeaderSize)
+	if _, err := io.ReadFull(r, serializedHeader); err != nil {
+		return nil, err
+	}
+	snapshot, err := deserializeUtxoSnapshotHeader(serializedHeader)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot.Net != b.chainParams.Net {
+		return nil, fmt.Errorf("utxo snapshot is for network %v "+
+			"instead of %v", snapshot.Net, b.chainParams.Net)
+	}
+	if snapshot.BaseHeight <= 0 {
+		return nil, fmt.Errorf("utxo snapshot has invalid base height "+
+			"%d", snapshot.BaseHeight)
+	}
+
+	// Load the snapshot into the utxo set within a single database
+	// transaction so nothing is stored unless all of its transaction
+	// records match the commitment.
+	err = b.db.Update(func(dbTx database.Tx) error {
+		utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+		if utxoBucket.Cursor().First() {
+			return errors.New("utxo snapshots may only be loaded " +
+				"into an empty utxo set")
+		}
+
+		hasher := sha256.New()
+		txReader := io.TeeReader(r, hasher)
+		for numCoins := uint64(0); numCoins < snapshot.NumCoins; {
+			txHash, entry, err := readUtxoSnapshotTx(txReader,
+				snapshot.NumCoins-numCoins)
+			if err != nil {
+				return err
+			}
+			numCoins += uint64(len(entry.sparseOutputs))
+
+			if utxoBucket.Get(txHash[:]) != nil {
+				return fmt.Errorf("utxo snapshot contains "+
+					"transaction %v more than once", txHash)
+			}
+			serialized, err := serializeUtxoEntry(entry)
+			if err != nil {
+				return err
+			}
+			if err := utxoBucket.Put(txHash[:], serialized); err != nil {
+				return err
+			}
+		}
+
+		_, err := io.ReadFull(r, snapshot.Commitment[:])
+		if err != nil {
+			return err
+		}
+		commitment := chainhash.Hash(sha256.Sum256(hasher.Sum(nil)))
+		if commitment != snapshot.Commitment {
+			return fmt.Errorf("utxo snapshot commitment %v does not "+
+				"match its contents %v", snapshot.Commitment,
+				commitment)
+		}
+
+		return dbPutUtxoSnapshot(dbTx, snapshot)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.utxoSnapshot = snapshot
+	log.Infof("Loaded utxo snapshot with %d coins at height %d (%v)",
+		snapshot.NumCoins, snapshot.BaseHeight, snapshot.BaseHash)
+	return snapshot, nil
+}
+
+// checkUtxoSnapshotBlock ensures the block with the passed hash at the passed
+// height may be accepted while the passed utxo snapshot is pending validation.
+// That is only the case for the blocks before the base block of the snapshot
+// and the base block itself.
+func checkUtxoSnapshotBlock(snapshot *UtxoSnapshot, hash *chainhash.Hash, height int32) error {
+	if height > snapshot.BaseHeight {
+		return fmt.Errorf("block %v at height %d is after the base "+
+			"block of the utxo snapshot at height %d which is "+
+			"pending validation", hash, height, snapshot.BaseHeight)
+	}
+	if height == snapshot.BaseHeight && *hash != snapshot.BaseHash {
+		return fmt.Errorf("block %v at height %d is not the base "+
+			"block %v of the utxo snapshot which is pending "+
+			"validation", hash, height, snapshot.BaseHash)
+	}
+	return nil
+}
+
+// acceptUtxoSnapshotBlock handles the passed node for a block which passed all
+// of the checks that do not depend on the utxo set while a utxo snapshot is
+// pending validation, and returns whether or not the block became the end of
+// the main chain.
+//
+// The blocks before the base block of the snapshot are added to the block index
+// without being connected, similar to side chain blocks.  Once the base block
+// is accepted, the snapshot is validated: the blocks leading up to it are
+// recorded as the main chain, the utxo set loaded from the snapshot is recorded
+// as corresponding to the base block, which becomes the end of the main chain,
+// and the snapshot is no longer pending.  Blocks which build on the base block
+// are connected as usual afterwards.
+//
+// Since the blocks up to and including the base block are not connected, they
+// have no spend journal entries.  Much like with pruned blocks, this means the
+// chain can't be reorganized to a fork before the base block.
+//
+// The flags modify the behavior of this function as follows:
+//  - BFDryRun: The block index and chain state are not modified.
+//
+// This function MUST be called with the chain state lock held (for writes).
+func (b *BlockChain) acceptUtxoSnapshotBlock(node *blockNode, block *ltcutil.Block, flags BehaviorFlags) (bool, error) {
+	snapshot := b.utxoSnapshot
+	isBaseBlock := node.hash == snapshot.BaseHash
+	if flags&BFDryRun == BFDryRun {
+		return isBaseBlock, nil
+	}
+	if !isBaseBlock {
+		b.index.AddNode(node)
+		return false, nil
+	}
+
+	// The best block is still the genesis block at this point, so the
+	// total number of transactions up to the base block starts with its
+	// transactions.
+	b.stateLock.RLock()
+	totalTxns := b.stateSnapshot.TotalTxns
+	b.stateLock.RUnlock()
+
+	var state *BestState
+	err := b.db.Update(func(dbTx database.Tx) error {
+		for n := node; n.parent != nil; n = n.parent {
+			numTxns, err := dbFetchBlockTxCount(dbTx, &n.hash)
+			if err != nil {
+				return err
+			}
+			totalTxns += numTxns
+
+			err = dbPutBlockIndex(dbTx, &n.hash, n.height)
+			if err != nil {
+				return err
+			}
+		}
+
+		numTxns := uint64(len(block.MsgBlock().Transactions))
+		blockSize := uint64(block.MsgBlock().SerializeSize())
+		blockWeight := uint64(GetBlockWeight(block))
+		state = newBestState(node, blockSize, blockWeight, numTxns,
+			totalTxns, node.CalcPastMedianTime())
+		err := dbPutBestState(dbTx, state, node.workSum)
+		if err != nil {
+			return err
+		}
+
+		// The utxo cache was emptied when the snapshot was loaded, so
+		// this only records that the utxo set in the database now
+		// corresponds to the base block.
+		err = b.utxoCache.flush(dbTx, nil, &node.hash, node.height)
+		if err != nil {
+			return err
+		}
+
+		return dbTx.Metadata().Delete(utxoSnapshotKeyName)
+	})
+	if err != nil {
+		return false, err
+	}
+	b.utxoCache.commit(nil, true, node.height)
+
+	// The base block is now the end of the best chain.
+	b.index.AddNode(node)
+	b.bestChain.SetTip(node)
+	b.stateLock.Lock()
+	b.stateSnapshot = state
+	b.stateLock.Unlock()
+	b.utxoSnapshot = nil
+
+	log.Infof("Validated utxo snapshot with base block %v (height %d)",
+		node.hash, node.height)
+	return true, nil
+}
+
+// PendingUtxoSnapshot returns the loaded utxo snapshot whose base block has not
+// been validated yet, or nil when there is no such snapshot.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) PendingUtxoSnapshot() *UtxoSnapshot {
+	b.chainLock.RLock()
+	snapshot := b.utxoSnapshot
+	b.chainLock.RUnlock()
+	return snapshot
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// TestUtxoSnapshotValidation ensures a chain with a loaded utxo snapshot only
+// accepts the blocks leading up to the base block of the snapshot, including
+// after a restart, that the base block validates the snapshot and becomes the
+// best block, and that blocks spending outputs from the snapshot are connected
+// on top of it afterwards.
+func TestUtxoSnapshotValidation(t *testing.T) {
+	paramsCopy := chaincfg.RegressionNetParams
+	const numBlocks = 20
+	const baseHeight = 15
+	blocks := newUtxoCacheTestBlocks(t, &paramsCopy, numBlocks)
+
+	// Dump a snapshot as of the base block from a chain which connected
+	// the blocks.
+	srcDB, teardownSrc := createUtxoCacheTestDB(t, "utxosnapshotsrc")
+	defer teardownSrc()
+	srcChain, processSrc := newUtxoCacheTestChain(t, srcDB, &paramsCopy, 0)
+	processSrc(blocks[:baseHeight])
+	baseState := srcChain.BestSnapshot()
+	var buf bytes.Buffer
+	if _, err := srcChain.DumpUtxoSnapshot(&buf); err != nil {
+		t.Fatalf("DumpUtxoSnapshot: %v", err)
+	}
+	processSrc(blocks[baseHeight:])
+
+	db, teardown := createUtxoCacheTestDB(t, "utxosnapshotload")
+	defer teardown()
+	chain, _ := newUtxoCacheTestChain(t, db, &paramsCopy, 0)
+	if _, err := chain.LoadUtxoSnapshot(&buf); err != nil {
+		t.Fatalf("LoadUtxoSnapshot: %v", err)
+	}
+
+	// process processes the passed block and ensures it is accepted and
+	// whether or not it is on the main chain is as expected.
+	process := func(chain *BlockChain, block *ltcutil.Block, wantMainChain bool) {
+		isMainChain, isOrphan, err := chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatalf("unable to process block %d: %v",
+				block.Height(), err)
+		}
+		if isOrphan || isMainChain != wantMainChain {
+			t.Fatalf("block %d: got main chain %v orphan %v, want "+
+				"main chain %v", block.Height(), isMainChain,
+				isOrphan, wantMainChain)
+		}
+	}
+
+	// The blocks before the base block are accepted without changing the
+	// best block, and the snapshot remains pending across a restart, after
+	// which the blocks are accepted again.
+	for _, block := range blocks[:baseHeight/2] {
+		process(chain, block, false)
+	}
+	chain, _ = newUtxoCacheTestChain(t, db, &paramsCopy, 0)
+	if chain.PendingUtxoSnapshot() == nil {
+		t.Fatal("utxo snapshot not pending after restart")
+	}
+	for _, block := range blocks[:baseHeight-1] {
+		process(chain, block, false)
+	}
+	if got := chain.BestSnapshot(); got.Height != 0 {
+		t.Fatalf("best block height %d before base block, want 0",
+			got.Height)
+	}
+
+	// A block at the base height other than the base block is rejected.
+	prevBlock := blocks[baseHeight-2]
+	fork := newReindexTestBlock(t, &paramsCopy, prevBlock.Hash(),
+		baseHeight, nil)
+	if _, _, err := chain.ProcessBlock(fork, BFNone); err == nil {
+		t.Fatal("block which is not the base block was accepted")
+	}
+	if chain.PendingUtxoSnapshot() == nil {
+		t.Fatal("utxo snapshot no longer pending after rejected block")
+	}
+
+	// The base block validates the snapshot and the remaining blocks are
+	// connected on top of it.
+	process(chain, blocks[baseHeight-1], true)
+	if chain.PendingUtxoSnapshot() != nil {
+		t.Fatal("utxo snapshot still pending after its base block")
+	}
+	got := chain.BestSnapshot()
+	if got.Hash != baseState.Hash || got.TotalTxns != baseState.TotalTxns {
+		t.Fatalf("best block %v with %d txns, want %v with %d txns",
+			got.Hash, got.TotalTxns, baseState.Hash,
+			baseState.TotalTxns)
+	}
+	for _, block := range blocks[baseHeight:] {
+		process(chain, block, true)
+	}
+
+	// The utxo set matches the one of the chain which connected all of
+	// the blocks, including after a restart.
+	var srcBuf bytes.Buffer
+	want, err := srcChain.DumpUtxoSnapshot(&srcBuf)
+	if err != nil {
+		t.Fatalf("DumpUtxoSnapshot: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		var gotBuf bytes.Buffer
+		got, err := chain.DumpUtxoSnapshot(&gotBuf)
+		if err != nil {
+			t.Fatalf("DumpUtxoSnapshot: %v", err)
+		}
+		if *got != *want {
+			t.Fatalf("utxo snapshot %+v, want %+v", got, want)
+		}
+		chain, _ = newUtxoCacheTestChain(t, db, &paramsCopy, 0)
+	}
+}
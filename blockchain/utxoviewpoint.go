@@ -6,10 +6,12 @@ package blockchain
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 	"github.com/ltcsuite/ltcd/database"
 	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
 	"github.com/ltcsuite/ltcutil"
 )
 
@@ -461,7 +463,7 @@ func (view *UtxoViewpoint) commit() {
 // Upon completion of this function, the view will contain an entry for each
 // requested transaction.  Fully spent transactions, or those which otherwise
 // don't exist, will result in a nil entry in the view.
-func (view *UtxoViewpoint) fetchUtxosMain(db database.DB, txSet map[chainhash.Hash]struct{}) error {
+func (view *UtxoViewpoint) fetchUtxosMain(cache *utxoCache, txSet map[chainhash.Hash]struct{}) error {
 	// Nothing to do if there are no requested hashes.
 	if len(txSet) == 0 {
 		return nil
@@ -469,31 +471,21 @@ func (view *UtxoViewpoint) fetchUtxosMain(db database.DB, txSet map[chainhash.Ha
 
 	// Load the unspent transaction output information for the requested set
 	// of transactions from the point of view of the end of the main chain.
+	// The utxo cache only loads the entries it does not hold from the
+	// database.
 	//
 	// NOTE: Missing entries are not considered an error here and instead
 	// will result in nil entries in the view.  This is intentionally done
 	// since other code uses the presence of an entry in the store as a way
 	// to optimize spend and unspend updates to apply only to the specific
 	// utxos that the caller needs access to.
-	return db.View(func(dbTx database.Tx) error {
-		for hash := range txSet {
-			hashCopy := hash
-			entry, err := dbFetchUtxoEntry(dbTx, &hashCopy)
-			if err != nil {
-				return err
-			}
-
-			view.entries[hash] = entry
-		}
-
-		return nil
-	})
+	return cache.fetchEntries(view, txSet)
 }
 
 // fetchUtxos loads utxo details about provided set of transaction hashes into
-// the view from the database as needed unless they already exist in the view in
-// which case they are ignored.
-func (view *UtxoViewpoint) fetchUtxos(db database.DB, txSet map[chainhash.Hash]struct{}) error {
+// the view from the utxo cache as needed unless they already exist in the view
+// in which case they are ignored.
+func (view *UtxoViewpoint) fetchUtxos(cache *utxoCache, txSet map[chainhash.Hash]struct{}) error {
 	// Nothing to do if there are no requested hashes.
 	if len(txSet) == 0 {
 		return nil
@@ -510,15 +502,15 @@ func (view *UtxoViewpoint) fetchUtxos(db database.DB, txSet map[chainhash.Hash]s
 		txNeededSet[hash] = struct{}{}
 	}
 
-	// Request the input utxos from the database.
-	return view.fetchUtxosMain(db, txNeededSet)
+	// Request the input utxos from the utxo cache.
+	return view.fetchUtxosMain(cache, txNeededSet)
 }
 
 // fetchInputUtxos loads utxo details about the input transactions referenced
-// by the transactions in the given block into the view from the database as
+// by the transactions in the given block into the view from the utxo cache as
 // needed.  In particular, referenced entries that are earlier in the block are
 // added to the view and entries that are already in the view are not modified.
-func (view *UtxoViewpoint) fetchInputUtxos(db database.DB, block *ltcutil.Block) error {
+func (view *UtxoViewpoint) fetchInputUtxos(cache *utxoCache, block *ltcutil.Block) error {
 	// Build a map of in-flight transactions because some of the inputs in
 	// this block could be referencing other transactions earlier in this
 	// block which are not yet in the chain.
@@ -564,8 +556,8 @@ func (view *UtxoViewpoint) fetchInputUtxos(db database.DB, block *ltcutil.Block)
 		}
 	}
 
-	// Request the input utxos from the database.
-	return view.fetchUtxosMain(db, txNeededSet)
+	// Request the input utxos from the utxo cache.
+	return view.fetchUtxosMain(cache, txNeededSet)
 }
 
 // NewUtxoViewpoint returns a new empty unspent transaction output view.
@@ -600,7 +592,7 @@ func (b *BlockChain) FetchUtxoView(tx *ltcutil.Tx) (*UtxoViewpoint, error) {
 	// Request the utxos from the point of view of the end of the main
 	// chain.
 	view := NewUtxoViewpoint()
-	err := view.fetchUtxosMain(b.db, txNeededSet)
+	err := view.fetchUtxosMain(b.utxoCache, txNeededSet)
 	return view, err
 }
 
@@ -618,15 +610,99 @@ func (b *BlockChain) FetchUtxoEntry(txHash *chainhash.Hash) (*UtxoEntry, error)
 	b.chainLock.RLock()
 	defer b.chainLock.RUnlock()
 
-	var entry *UtxoEntry
-	err := b.db.View(func(dbTx database.Tx) error {
-		var err error
-		entry, err = dbFetchUtxoEntry(dbTx, txHash)
-		return err
+	view := NewUtxoViewpoint()
+	err := view.fetchUtxosMain(b.utxoCache, map[chainhash.Hash]struct{}{
+		*txHash: {},
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return entry, nil
+	return view.LookupEntry(txHash), nil
+}
+
+// forEachUtxoEntry invokes the passed function with the hash and utxo entry of
+// every transaction in the passed utxo set bucket in ascending order of the
+// bytes of the transaction hashes.
+//
+// When the passed function returns an error, the iteration is stopped and the
+// error is returned to the caller without modification.
+func forEachUtxoEntry(utxoBucket database.Bucket, fn func(txHash *chainhash.Hash, entry *UtxoEntry) error) error {
+	return utxoBucket.ForEach(func(k, v []byte) error {
+		var txHash chainhash.Hash
+		copy(txHash[:], k)
+		entry, err := deserializeUtxoEntry(v)
+		if err != nil {
+			// Ensure any deserialization errors are returned as
+			// database corruption errors.
+			if isDeserializeErr(err) {
+				return database.Error{
+					ErrorCode: database.ErrCorruption,
+					Description: fmt.Sprintf("corrupt utxo "+
+						"entry for %v: %v", txHash, err),
+				}
+			}
+
+			return err
+		}
+
+		return fn(&txHash, entry)
+	})
+}
+
+// ForEachUtxo invokes the passed function with every unspent transaction output
+// in the utxo set along with the utxo entry of the transaction it belongs to.
+// The outputs are visited in ascending order of the bytes of their transaction
+// hash, which callers may use to estimate the progress of the iteration.
+//
+// The iteration is performed against a consistent snapshot of the utxo set
+// which does not block the chain from processing new blocks in the meantime.
+// The changes held in the utxo cache are written to the database beforehand.
+// The hash and height of the best block the snapshot represents are returned
+// when the iteration completes.
+//
+// When the passed function returns an error, the iteration is stopped and the
+// error is returned to the caller without modification.
+//
+// This function is safe for concurrent access however the entries provided to
+// the passed function are NOT.
+func (b *BlockChain) ForEachUtxo(fn func(outPoint *wire.OutPoint, entry *UtxoEntry) error) (*chainhash.Hash, int32, error) {
+	dbTx, err := b.utxoSetSnapshot()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer dbTx.Rollback()
+
+	state, err := deserializeBestChainState(dbTx.Metadata().Get(
+		chainStateKeyName))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+	err = forEachUtxoEntry(utxoBucket, func(txHash *chainhash.Hash, entry *UtxoEntry) error {
+		// Visit the unspent outputs in order of their index.
+		outputOrder := make([]int, 0, len(entry.sparseOutputs))
+		for outputIndex, output := range entry.sparseOutputs {
+			if output.spent {
+				continue
+			}
+			outputOrder = append(outputOrder, int(outputIndex))
+		}
+		sort.Ints(outputOrder)
+
+		for _, outputIndex := range outputOrder {
+			outPoint := wire.NewOutPoint(txHash,
+				uint32(outputIndex))
+			if err := fn(outPoint, entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return &state.hash, int32(state.height), nil
 }
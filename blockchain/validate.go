@@ -13,6 +13,7 @@ import (
 
 	"github.com/ltcsuite/ltcd/chaincfg"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
 	"github.com/ltcsuite/ltcd/txscript"
 	"github.com/ltcsuite/ltcd/wire"
 	"github.com/ltcsuite/ltcutil"
@@ -850,7 +851,7 @@ func (b *BlockChain) checkBIP0030(node *blockNode, block *ltcutil.Block, view *U
 	for _, tx := range block.Transactions() {
 		fetchSet[*tx.Hash()] = struct{}{}
 	}
-	err := view.fetchUtxos(b.db, fetchSet)
+	err := view.fetchUtxos(b.utxoCache, fetchSet)
 	if err != nil {
 		return err
 	}
@@ -1044,7 +1045,7 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *ltcutil.Block, vi
 	//
 	// These utxo entries are needed for verification of things such as
 	// transaction inputs, counting pay-to-script-hashes, and scripts.
-	err := view.fetchInputUtxos(b.db, block)
+	err := view.fetchInputUtxos(b.utxoCache, block)
 	if err != nil {
 		return err
 	}
@@ -1161,6 +1162,12 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *ltcutil.Block, vi
 		runScripts = false
 	}
 
+	// Likewise, don't run scripts if this node is the assumed valid block
+	// or one of its ancestors.
+	if runScripts && b.isAssumedValid(node) {
+		runScripts = false
+	}
+
 	// Blocks created after the BIP0016 activation time need to have the
 	// pay-to-script-hash checks enabled.
 	var scriptFlags txscript.ScriptFlags
@@ -1181,6 +1188,13 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *ltcutil.Block, vi
 		scriptFlags |= txscript.ScriptVerifyCheckLockTimeVerify
 	}
 
+	// Enforce CHECKTEMPLATEVERIFY on the networks which opt in to it once
+	// the activation height has been reached.  This is part of BIP0119.
+	ctvHeight := b.chainParams.CheckTemplateVerifyHeight
+	if ctvHeight != 0 && node.height >= ctvHeight {
+		scriptFlags |= txscript.ScriptVerifyCheckTemplateVerify
+	}
+
 	// Enforce CHECKSEQUENCEVERIFY during all block validation checks once
 	// the soft-fork deployment is fully active.
 	csvState, err := b.deploymentState(node.parent, chaincfg.DeploymentCSV)
@@ -1233,7 +1247,7 @@ func (b *BlockChain) checkConnectBlock(node *blockNode, block *ltcutil.Block, vi
 	// prevent CPU exhaustion attacks.
 	if runScripts {
 		err := checkBlockScripts(block, view, scriptFlags, b.sigCache,
-			b.hashCache)
+			b.hashCache, b.scriptCache, b.scriptWorkers)
 		if err != nil {
 			return err
 		}
@@ -1270,3 +1284,51 @@ func (b *BlockChain) CheckConnectBlock(block *ltcutil.Block) error {
 	view.SetBestHash(&prevNode.hash)
 	return b.checkConnectBlock(newNode, block, view, nil)
 }
+
+// CheckBlockHeader performs the checks on the passed block header which don't
+// require the rest of the block, such as before doing any work to obtain a
+// block announced by a peer.  This includes the context-free sanity checks such
+// as the proof of work as well as the checks which depend on the position of
+// the header within the block chain such as matching the checkpoints.  The
+// previous block must be known and not be known to be invalid, and neither
+// must the block itself have been invalidated.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) CheckBlockHeader(header *wire.BlockHeader) error {
+	b.chainLock.Lock()
+	defer b.chainLock.Unlock()
+
+	err := checkBlockHeaderSanity(header, b.chainParams.PowLimit,
+		b.timeSource, BFNone)
+	if err != nil {
+		return err
+	}
+
+	prevHash := &header.PrevBlock
+	prevNode := b.index.LookupNode(prevHash)
+	if prevNode == nil {
+		str := fmt.Sprintf("previous block %v is unknown", prevHash)
+		return ruleError(ErrPrevBlockNotFound, str)
+	}
+	if b.index.NodeStatus(prevNode).KnownInvalid() {
+		str := fmt.Sprintf("previous block %v is known to be invalid",
+			prevHash)
+		return ruleError(ErrInvalidAncestorBlock, str)
+	}
+
+	blockHash := header.BlockHash()
+	var invalidated bool
+	err = b.db.View(func(dbTx database.Tx) error {
+		invalidated = dbIsInvalidBlock(dbTx, &blockHash)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if invalidated {
+		str := fmt.Sprintf("block %v has been invalidated", blockHash)
+		return ruleError(ErrKnownInvalidBlock, str)
+	}
+
+	return b.checkBlockHeaderContext(header, prevNode, BFNone)
+}
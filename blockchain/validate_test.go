@@ -12,6 +12,7 @@ import (
 
 	"github.com/ltcsuite/ltcd/chaincfg"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/txscript"
 	"github.com/ltcsuite/ltcd/wire"
 	"github.com/ltcsuite/ltcutil"
 )
@@ -420,3 +421,166 @@ var Block100000 = wire.MsgBlock{
 		},
 	},
 }
+
+// assumeValidTestBlock returns a regression test network block at the passed
+// height which builds on the block with the passed hash and contains a
+// coinbase paying to OP_TRUE followed by the provided transactions.
+func assumeValidTestBlock(t *testing.T, prevHash *chainhash.Hash, height int32, txns ...*wire.MsgTx) *ltcutil.Block {
+	params := &chaincfg.RegressionNetParams
+	coinbaseScript, err := txscript.NewScriptBuilder().
+		AddInt64(int64(height)).AddInt64(0).Script()
+	if err != nil {
+		t.Fatalf("unable to create coinbase script: %v", err)
+	}
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: coinbaseScript,
+		Sequence:        wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(wire.NewTxOut(CalcBlockSubsidy(height, params),
+		[]byte{txscript.OP_TRUE}))
+
+	msgTxns := append([]*wire.MsgTx{coinbase}, txns...)
+	utilTxns := make([]*ltcutil.Tx, 0, len(msgTxns))
+	for _, tx := range msgTxns {
+		utilTxns = append(utilTxns, ltcutil.NewTx(tx))
+	}
+	merkles := BuildMerkleTreeStore(utilTxns, false)
+	return ltcutil.NewBlock(&wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    1,
+			PrevBlock:  *prevHash,
+			MerkleRoot: *merkles[len(merkles)-1],
+			Timestamp: params.GenesisBlock.Header.Timestamp.Add(
+				time.Duration(height) * time.Minute),
+			Bits: params.PowLimitBits,
+		},
+		Transactions: msgTxns,
+	})
+}
+
+// TestAssumeValid ensures the scripts of the assumed valid block and its
+// ancestors are not verified once it is in the block index, while those of the
+// blocks above it, and of all blocks when it is unknown or on another chain,
+// are.
+func TestAssumeValid(t *testing.T) {
+	// invalidSpend returns a transaction spending the coinbase of the
+	// passed block with a signature script which fails to execute.
+	invalidSpend := func(block *ltcutil.Block) *wire.MsgTx {
+		coinbase := block.MsgBlock().Transactions[0]
+		coinbaseHash := coinbase.TxHash()
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0),
+			[]byte{txscript.OP_RETURN}, nil))
+		tx.AddTxOut(wire.NewTxOut(coinbase.TxOut[0].Value,
+			[]byte{txscript.OP_TRUE}))
+		return tx
+	}
+
+	// Create a chain where the blocks at heights 2 and 5 contain invalid
+	// scripts along with a competing chain of 3 blocks which is seen
+	// first.  The blocks of the competing chain differ by their nonce.
+	params := &chaincfg.RegressionNetParams
+	block1 := assumeValidTestBlock(t, params.GenesisHash, 1)
+	block2 := assumeValidTestBlock(t, block1.Hash(), 2, invalidSpend(block1))
+	block3 := assumeValidTestBlock(t, block2.Hash(), 3)
+	block4 := assumeValidTestBlock(t, block3.Hash(), 4)
+	block5 := assumeValidTestBlock(t, block4.Hash(), 5, invalidSpend(block3))
+	var altBlocks []*ltcutil.Block
+	prevHash := params.GenesisHash
+	for height := int32(1); height <= 3; height++ {
+		msgBlock := assumeValidTestBlock(t, prevHash, height).MsgBlock()
+		msgBlock.Header.Nonce = 1
+		altBlock := ltcutil.NewBlock(msgBlock)
+		altBlocks = append(altBlocks, altBlock)
+		prevHash = altBlock.Hash()
+	}
+
+	tests := []struct {
+		name        string
+		assumeValid *chaincfg.Checkpoint
+		reorg       bool
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:        "assumed valid block in chain",
+			assumeValid: &chaincfg.Checkpoint{Height: 3, Hash: block3.Hash()},
+			reorg:       true,
+		},
+		{
+			name:        "assumed valid block unknown",
+			assumeValid: &chaincfg.Checkpoint{Height: 3, Hash: &chainhash.Hash{0x01}},
+		},
+		{
+			name:        "assumed valid block not in chain",
+			assumeValid: &chaincfg.Checkpoint{Height: 3, Hash: altBlocks[2].Hash()},
+		},
+	}
+
+	for _, test := range tests {
+		chain, teardown, err := chainSetup("assumevalid", params)
+		if err != nil {
+			t.Fatalf("%s: failed to setup chain instance: %v", test.name,
+				err)
+		}
+		chain.TstSetCoinbaseMaturity(1)
+		chain.assumeValid = test.assumeValid
+
+		// The blocks up to the one at height 3 end up on a side chain,
+		// so they are only validated once the block at height 4 causes
+		// a reorganization to their chain.
+		for _, block := range append(altBlocks, block1, block2, block3) {
+			_, _, err := chain.ProcessBlock(block, BFNoPoWCheck)
+			if err != nil {
+				teardown()
+				t.Fatalf("%s: unable to process block %v: %v",
+					test.name, block.Hash(), err)
+			}
+		}
+
+		// checkScriptError ensures the passed error is a script
+		// validation failure.
+		checkScriptError := func(height int, err error) {
+			if rerr, ok := err.(RuleError); !ok ||
+				rerr.ErrorCode != ErrScriptValidation {
+
+				teardown()
+				t.Fatalf("%s: block %d: got error %v, want %v",
+					test.name, height, err, ErrScriptValidation)
+			}
+		}
+
+		// The reorganization only succeeds when the invalid scripts of
+		// the block at height 2 are not verified.
+		_, _, err = chain.ProcessBlock(block4, BFNoPoWCheck)
+		if !test.reorg {
+			checkScriptError(4, err)
+			if tip := chain.BestSnapshot().Hash; tip != *altBlocks[2].Hash() {
+				teardown()
+				t.Fatalf("%s: unexpected best block %v", test.name,
+					tip)
+			}
+			teardown()
+			continue
+		}
+		if err != nil {
+			teardown()
+			t.Fatalf("%s: unable to process block 4: %v", test.name,
+				err)
+		}
+		if tip := chain.BestSnapshot().Hash; tip != *block4.Hash() {
+			teardown()
+			t.Fatalf("%s: unexpected best block %v", test.name, tip)
+		}
+
+		// The scripts of blocks above the assumed valid block are
+		// verified again.
+		_, _, err = chain.ProcessBlock(block5, BFNoPoWCheck)
+		checkScriptError(5, err)
+		teardown()
+	}
+}
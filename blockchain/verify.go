@@ -0,0 +1,338 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcutil"
+)
+
+const (
+	// MaxVerifyLevel is the most thorough level of verification performed
+	// by VerifyChain.  Higher levels are clamped to it.
+	MaxVerifyLevel = 4
+
+	// MaxVerifyUtxoDepth is the maximum number of blocks verified by
+	// VerifyChain at the levels which disconnect the blocks from an
+	// in-memory view of the utxo set.  The view, along with the blocks
+	// which are kept to reconnect them at the highest level, grows with
+	// every block, so deeper requests are clamped to it.
+	MaxVerifyUtxoDepth = 288
+)
+
+// VerifyError identifies a block of the main chain which failed verification
+// along with the reason it failed.
+type VerifyError struct {
+	Hash   chainhash.Hash
+	Height int32
+	Err    error
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e VerifyError) Error() string {
+	return fmt.Sprintf("block %v (height %d) failed verification: %v",
+		e.Hash, e.Height, e.Err)
+}
+
+// verifyBlockOutputs ensures the outputs created by the passed block which are
+// not spent by later transactions in the same block are available as unspent
+// outputs created at the height of the block in the provided view.  The view
+// must be from the point of view of the end of the block.
+func verifyBlockOutputs(block *ltcutil.Block, view *UtxoViewpoint) error {
+	spentInBlock := make(map[chainhash.Hash]map[uint32]struct{})
+	for _, tx := range block.Transactions()[1:] {
+		for _, txIn := range tx.MsgTx().TxIn {
+			prevOut := &txIn.PreviousOutPoint
+			spent, ok := spentInBlock[prevOut.Hash]
+			if !ok {
+				spent = make(map[uint32]struct{})
+				spentInBlock[prevOut.Hash] = spent
+			}
+			spent[prevOut.Index] = struct{}{}
+		}
+	}
+
+	for txIdx, tx := range block.Transactions() {
+		entry := view.LookupEntry(tx.Hash())
+		for txOutIdx, txOut := range tx.MsgTx().TxOut {
+			if txscript.IsUnspendable(txOut.PkScript) {
+				continue
+			}
+			if _, ok := spentInBlock[*tx.Hash()][uint32(txOutIdx)]; ok {
+				continue
+			}
+
+			index := uint32(txOutIdx)
+			if entry == nil || entry.IsOutputSpent(index) {
+				return fmt.Errorf("output %v:%d is missing from "+
+					"the utxo set", tx.Hash(), index)
+			}
+			if entry.BlockHeight() != block.Height() ||
+				entry.IsCoinBase() != (txIdx == 0) ||
+				entry.AmountByIndex(index) != txOut.Value ||
+				!bytes.Equal(entry.PkScriptByIndex(index),
+					txOut.PkScript) {
+
+				return fmt.Errorf("output %v:%d does not match "+
+					"the utxo set", tx.Hash(), index)
+			}
+		}
+	}
+
+	return nil
+}
+
+// VerifyChain verifies the blocks of the main chain starting at the current
+// tip and going back the passed number of blocks, or back to the genesis block
+// or the most recent pruned block when the depth is not positive.  The level
+// determines how thorough the verification is:
+//
+//   0 - Load each block from the database
+//   1 - Ensure each block is consistent with its index entry and passes the
+//       context-free sanity checks
+//   2 - Load the spend journal entry of each block and disconnect it from an
+//       in-memory view of the utxo set
+//   3 - Ensure the outputs created by each block match the utxo set before it
+//       is disconnected
+//   4 - Reconnect the blocks to the view again while fully validating them
+//
+// Each level includes the checks of the lower ones.  The depth is limited to
+// MaxVerifyUtxoDepth at level 2 and above.  A VerifyError which identifies the
+// offending block is returned when a block fails verification, and
+// errInterruptRequested is returned when the interrupt channel is closed
+// before verification completes.  Neither the chain state nor the utxo set are
+// modified, although the utxo cache is flushed at level 2 and above.
+//
+// Levels 0 and 1 do not hold off block processing.  Levels 2 and 3 hold it off
+// while the blocks are disconnected so the view of the utxo set does not
+// change, but still allow other readers of the chain state.  Level 4 holds the
+// chain state lock for writes since the full validation updates the
+// deployment threshold state caches.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) VerifyChain(level, depth int32, interrupt <-chan struct{}) error {
+	if level < 0 {
+		level = 0
+	}
+	if level > MaxVerifyLevel {
+		level = MaxVerifyLevel
+	}
+	if level >= 2 && (depth <= 0 || depth > MaxVerifyUtxoDepth) {
+		log.Infof("Limiting verification at level %d to the last %d "+
+			"blocks", level, MaxVerifyUtxoDepth)
+		depth = MaxVerifyUtxoDepth
+	}
+
+	if level < 2 {
+		return b.verifyBlocks(level, depth, interrupt)
+	}
+
+	// Write the changes held in the utxo cache to the database and empty
+	// it so the utxo set stored in the database is what gets verified.
+	// Blocks connected after the flush are held in the cache again, which
+	// is fine since the view loads the utxos through it.
+	b.chainLock.Lock()
+	if err := b.flushUtxoCache(true); err != nil {
+		b.chainLock.Unlock()
+		return err
+	}
+	if level >= 4 {
+		defer b.chainLock.Unlock()
+		return b.verifyUtxoBlocks(level, depth, interrupt)
+	}
+	b.chainLock.Unlock()
+
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+	return b.verifyUtxoBlocks(level, depth, interrupt)
+}
+
+// verifyFinishHeight returns the height of the block the verification of the
+// passed number of blocks back from the passed tip stops at.  The genesis
+// block is never verified since its coinbase is not part of the utxo set and
+// it has no spend journal entry, and neither are pruned blocks since they are
+// no longer available.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) verifyFinishHeight(tip *blockNode, depth int32) int32 {
+	finishHeight := b.pruneHeight
+	if finishHeight < 0 {
+		finishHeight = 0
+	}
+	if depth > 0 && tip.height-depth > finishHeight {
+		finishHeight = tip.height - depth
+	}
+	return finishHeight
+}
+
+// verifyBlocks performs the verification of VerifyChain at levels 0 and 1,
+// which only involve the blocks themselves.  Only the starting point is
+// determined with the chain state lock held, so blocks may be processed while
+// they are verified.  The walk stops early without an error when a block can
+// no longer be loaded because it was pruned in the meantime.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) verifyBlocks(level, depth int32, interrupt <-chan struct{}) error {
+	b.chainLock.RLock()
+	tip := b.bestChain.Tip()
+	finishHeight := b.verifyFinishHeight(tip, depth)
+	b.chainLock.RUnlock()
+	log.Infof("Verifying %d blocks at level %d", tip.height-finishHeight,
+		level)
+
+	// The parent of a node never changes, so the nodes are walked without
+	// the lock.
+	for node := tip; node.height > finishHeight; node = node.parent {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		var block *ltcutil.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			block, err = dbFetchBlockByNode(dbTx, node)
+			return err
+		})
+		if err != nil {
+			b.chainLock.RLock()
+			pruned := node.height <= b.pruneHeight
+			b.chainLock.RUnlock()
+			if pruned {
+				break
+			}
+		}
+		if err == nil && level >= 1 {
+			err = b.verifyBlockSanity(node, block)
+		}
+		if err != nil {
+			return VerifyError{Hash: node.hash, Height: node.height,
+				Err: err}
+		}
+	}
+
+	log.Infof("Chain verification completed successfully")
+	return nil
+}
+
+// verifyUtxoBlocks performs the verification of VerifyChain at level 2 and
+// above, which disconnects the blocks from an in-memory view of the utxo set
+// and, at level 4, reconnects them again.
+//
+// This function MUST be called with the chain state lock held (for reads at
+// levels 2 and 3 and for writes at level 4).
+func (b *BlockChain) verifyUtxoBlocks(level, depth int32, interrupt <-chan struct{}) error {
+	tip := b.bestChain.Tip()
+	finishHeight := b.verifyFinishHeight(tip, depth)
+	log.Infof("Verifying %d blocks at level %d", tip.height-finishHeight,
+		level)
+
+	// Disconnect the blocks from the view in reverse order while checking
+	// them and, when they are reconnected afterwards, keep them around.
+	view := NewUtxoViewpoint()
+	view.SetBestHash(&tip.hash)
+	var blocks []*ltcutil.Block
+	for node := tip; node.height > finishHeight; node = node.parent {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		var block *ltcutil.Block
+		err := b.db.View(func(dbTx database.Tx) error {
+			var err error
+			block, err = dbFetchBlockByNode(dbTx, node)
+			return err
+		})
+		if err == nil {
+			err = b.verifyBlockSanity(node, block)
+		}
+		if err == nil {
+			err = b.verifyDisconnectBlock(block, view, level >= 3)
+		}
+		if err != nil {
+			return VerifyError{Hash: node.hash, Height: node.height,
+				Err: err}
+		}
+		if level >= 4 {
+			blocks = append(blocks, block)
+		}
+	}
+
+	// Reconnect the blocks starting at the one after the final block which
+	// was disconnected with full validation.
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		block := blocks[i]
+		node := b.bestChain.NodeByHeight(block.Height())
+		err := b.checkBlockContext(block, node.parent, BFNone)
+		if err == nil {
+			err = b.checkConnectBlock(node, block, view, nil)
+		}
+		if err != nil {
+			return VerifyError{Hash: node.hash, Height: node.height,
+				Err: err}
+		}
+	}
+
+	log.Infof("Chain verification completed successfully")
+	return nil
+}
+
+// verifyBlockSanity ensures the passed block loaded for the passed node is the
+// block the node represents and that it passes the context-free sanity checks.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) verifyBlockSanity(node *blockNode, block *ltcutil.Block) error {
+	if !block.Hash().IsEqual(&node.hash) {
+		return fmt.Errorf("stored block has hash %v", block.Hash())
+	}
+	return checkBlockSanity(block, b.chainParams.PowLimit, b.timeSource,
+		BFNone)
+}
+
+// verifyDisconnectBlock disconnects the passed block from the provided view
+// using its spend journal entry.  When requested, the outputs created by the
+// block are first checked against the view.  The view must be from the point
+// of view of the end of the block.
+//
+// This function MUST be called with the chain state lock held (for reads).
+func (b *BlockChain) verifyDisconnectBlock(block *ltcutil.Block, view *UtxoViewpoint, checkOutputs bool) error {
+	if checkOutputs {
+		txSet := make(map[chainhash.Hash]struct{})
+		for _, tx := range block.Transactions() {
+			txSet[*tx.Hash()] = struct{}{}
+		}
+		if err := view.fetchUtxos(b.utxoCache, txSet); err != nil {
+			return err
+		}
+		if err := verifyBlockOutputs(block, view); err != nil {
+			return err
+		}
+	}
+
+	// Load the utxos referenced by the block which are needed to
+	// deserialize the spend journal entry.
+	err := view.fetchInputUtxos(b.utxoCache, block)
+	if err != nil {
+		return err
+	}
+	var stxos []spentTxOut
+	err = b.db.View(func(dbTx database.Tx) error {
+		stxos, err = dbFetchSpendJournalEntry(dbTx, block, view)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return view.disconnectTransactions(block, stxos)
+}
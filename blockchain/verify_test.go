@@ -0,0 +1,137 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package blockchain
+
+import (
+	"testing"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// TestVerifyChain ensures a healthy chain passes verification at every level
+// and that blocks whose spend journal entry or created outputs were corrupted
+// fail verification at the expected levels.
+func TestVerifyChain(t *testing.T) {
+	chain, teardownFunc, err := chainSetup("verifychain",
+		&chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatalf("Failed to setup chain instance: %v", err)
+	}
+	defer teardownFunc()
+	chain.TstSetCoinbaseMaturity(1)
+
+	// Create a chain where each block spends the coinbase of its parent.
+	const numBlocks = 20
+	var blocks []*ltcutil.Block
+	for height := int32(1); height <= numBlocks; height++ {
+		best := chain.BestSnapshot()
+		var block *ltcutil.Block
+		if len(blocks) == 0 {
+			block = newReindexTestBlock(t, chain.chainParams,
+				&best.Hash, height, nil)
+		} else {
+			prevCoinbase := blocks[len(blocks)-1].MsgBlock().Transactions[0]
+			block = newReindexTestBlock(t, chain.chainParams,
+				&best.Hash, height, prevCoinbase)
+		}
+		_, isOrphan, err := chain.ProcessBlock(block, BFNone)
+		if err != nil {
+			t.Fatalf("unable to process block %d: %v", height, err)
+		}
+		if isOrphan {
+			t.Fatalf("block %d is an orphan", height)
+		}
+		blocks = append(blocks, block)
+	}
+
+	// verify ensures verifying the chain at the passed level and depth
+	// fails for the block at the passed height, or succeeds when it is
+	// zero.
+	verify := func(level, depth, failHeight int32) {
+		err := chain.VerifyChain(level, depth, nil)
+		if failHeight == 0 {
+			if err != nil {
+				t.Fatalf("level %d depth %d: unexpected error: %v",
+					level, depth, err)
+			}
+			return
+		}
+		verr, ok := err.(VerifyError)
+		if !ok {
+			t.Fatalf("level %d depth %d: unexpected error %v, want "+
+				"failure of block %d", level, depth, err,
+				failHeight)
+		}
+		if verr.Height != failHeight ||
+			verr.Hash != *blocks[failHeight-1].Hash() {
+
+			t.Fatalf("level %d depth %d: block %v (height %d) "+
+				"failed, want height %d", level, depth,
+				verr.Hash, verr.Height, failHeight)
+		}
+	}
+
+	// A healthy chain passes at every level, including levels beyond the
+	// most thorough one, for part of the chain and for the whole chain.
+	for level := int32(0); level <= MaxVerifyLevel+1; level++ {
+		verify(level, 5, 0)
+		verify(level, 0, 0)
+	}
+
+	// Ensure the chain is not modified by the verification.
+	if got := chain.BestSnapshot(); got.Hash != *blocks[numBlocks-1].Hash() {
+		t.Fatalf("best block changed to %v", got.Hash)
+	}
+	if err := chain.VerifyChain(MaxVerifyLevel, 0, nil); err != nil {
+		t.Fatalf("repeated verification failed: %v", err)
+	}
+
+	// Ensure an interrupted verification is stopped.
+	interrupt := make(chan struct{})
+	close(interrupt)
+	if err := chain.VerifyChain(0, 0, interrupt); err != errInterruptRequested {
+		t.Fatalf("unexpected error from interrupted verification: %v",
+			err)
+	}
+
+	// Remove the unspent output created by the spending transaction of a
+	// block from the utxo set.  The blocks themselves are still intact, so
+	// only the levels which check the block against the utxo set fail.
+	const corruptHeight = numBlocks - 2
+	spendTx := blocks[corruptHeight-1].Transactions()[1]
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		utxoBucket := dbTx.Metadata().Bucket(utxoSetBucketName)
+		return utxoBucket.Delete(spendTx.Hash()[:])
+	})
+	if err != nil {
+		t.Fatalf("unable to corrupt the utxo set: %v", err)
+	}
+	for level := int32(0); level <= MaxVerifyLevel; level++ {
+		failHeight := int32(0)
+		if level >= 3 {
+			failHeight = corruptHeight
+		}
+		verify(level, 0, failHeight)
+	}
+	verify(3, 2, 0)
+
+	// Truncate the spend journal entry of an earlier block, which is
+	// detected once the spend journal is loaded.
+	const journalHeight = 10
+	err = chain.db.Update(func(dbTx database.Tx) error {
+		spendBucket := dbTx.Metadata().Bucket(spendJournalBucketName)
+		hash := blocks[journalHeight-1].Hash()
+		serialized := spendBucket.Get(hash[:])
+		return spendBucket.Put(hash[:], serialized[:len(serialized)-1])
+	})
+	if err != nil {
+		t.Fatalf("unable to corrupt the spend journal: %v", err)
+	}
+	verify(1, 0, 0)
+	verify(2, 0, journalHeight)
+	verify(2, numBlocks-journalHeight, 0)
+}
@@ -6,6 +6,7 @@ package blockchain
 
 import (
 	"math"
+	"strings"
 
 	"github.com/ltcsuite/ltcd/chaincfg"
 )
@@ -299,3 +300,24 @@ func (b *BlockChain) warnUnknownVersions(node *blockNode) error {
 
 	return nil
 }
+
+// Warnings returns a description of the warnings about unknown rules being
+// activated and unknown block versions being mined on the main chain.  An
+// empty string is returned when there are no warnings.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) Warnings() string {
+	b.chainLock.RLock()
+	defer b.chainLock.RUnlock()
+
+	var warnings []string
+	if b.unknownRulesWarned {
+		warnings = append(warnings, "Warning: unknown new rules "+
+			"activated")
+	}
+	if b.unknownVersionsWarned {
+		warnings = append(warnings, "Warning: unknown block versions "+
+			"are being mined, so new rules might be in effect")
+	}
+	return strings.Join(warnings, "; ")
+}
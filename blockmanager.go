@@ -6,6 +6,7 @@ package main
 
 import (
 	"container/list"
+	"fmt"
 	"net"
 	"sync"
 	"sync/atomic"
@@ -54,11 +55,29 @@ type newPeerMsg struct {
 }
 
 // blockMsg packages a bitcoin block message and the peer it came from together
-// so the block handler has access to that information.
+// so the block handler has access to that information.  The fromCmpctBlock
+// flag indicates the block was reconstructed from a compact block.
 type blockMsg struct {
-	block *ltcutil.Block
-	peer  *peerpkg.Peer
-	reply chan struct{}
+	block          *ltcutil.Block
+	peer           *peerpkg.Peer
+	fromCmpctBlock bool
+	reply          chan struct{}
+}
+
+// cmpctBlockMsg packages a bitcoin cmpctblock message and the peer it came from
+// together so the block handler has access to that information.
+type cmpctBlockMsg struct {
+	cmpctBlock *wire.MsgCmpctBlock
+	peer       *peerpkg.Peer
+	reply      chan struct{}
+}
+
+// blockTxnMsg packages a bitcoin blocktxn message and the peer it came from
+// together so the block handler has access to that information.
+type blockTxnMsg struct {
+	blockTxn *wire.MsgBlockTxn
+	peer     *peerpkg.Peer
+	reply    chan struct{}
 }
 
 // invMsg packages a bitcoin inv message and the peer it came from together
@@ -94,6 +113,12 @@ type getSyncPeerMsg struct {
 	reply chan int32
 }
 
+// getSyncStatusMsg is a message type to be sent across the message channel for
+// retrieving the progress of the chain synchronization.
+type getSyncStatusMsg struct {
+	reply chan *syncStatus
+}
+
 // processBlockResponse is a response sent to the reply channel of a
 // processBlockMsg.
 type processBlockResponse struct {
@@ -134,6 +159,73 @@ type headerNode struct {
 	hash   *chainhash.Hash
 }
 
+// syncPhase identifies the phase of the chain synchronization the block
+// manager is in.
+type syncPhase int
+
+const (
+	// syncPhaseIdle indicates there is no peer to sync from.
+	syncPhaseIdle syncPhase = iota
+
+	// syncPhaseHeaders indicates the headers of the blocks up to the next
+	// checkpoint are being downloaded.
+	syncPhaseHeaders
+
+	// syncPhaseBlocks indicates blocks are being downloaded and validated.
+	syncPhaseBlocks
+
+	// syncPhaseSynced indicates the chain is believed to be current.
+	syncPhaseSynced
+)
+
+// syncPhaseStrings is a map of sync phases back to their names for pretty
+// printing.
+var syncPhaseStrings = map[syncPhase]string{
+	syncPhaseIdle:    "idle",
+	syncPhaseHeaders: "headers",
+	syncPhaseBlocks:  "blocks",
+	syncPhaseSynced:  "synced",
+}
+
+// String returns the syncPhase in human-readable form.
+func (p syncPhase) String() string {
+	if s, ok := syncPhaseStrings[p]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown syncPhase (%d)", int(p))
+}
+
+// syncStatus describes the progress of the chain synchronization.
+type syncStatus struct {
+	// phase is the current phase of the synchronization.
+	phase syncPhase
+
+	// headerHeight is the height of the best known header, which is ahead
+	// of the validated blocks while headers are downloaded first.
+	headerHeight int32
+
+	// blockHeight is the height of the best validated block.
+	blockHeight int32
+
+	// syncHeight is the height being synced to, which is the best of the
+	// header height and the height advertised by the sync peer.
+	syncHeight int32
+
+	// windowStart and windowEnd are the heights of the first and last
+	// blocks of the current download window.  The window is empty when
+	// windowEnd is less than windowStart.
+	windowStart int32
+	windowEnd   int32
+
+	// blocksInFlight is the number of requested blocks which have not
+	// been received yet.
+	blocksInFlight int
+
+	// progress is the estimated fraction of the blocks up to the sync
+	// height which have been validated, ranging from 0 to 1.
+	progress float64
+}
+
 // PeerNotifier exposes methods to notify peers of status changes to
 // transactions, blocks, etc. Currently server implements this interface.
 type PeerNotifier interface {
@@ -144,6 +236,8 @@ type PeerNotifier interface {
 	RelayInventory(invVect *wire.InvVect, data interface{})
 
 	TransactionConfirmed(tx *ltcutil.Tx)
+
+	AddBanScore(p *peerpkg.Peer, persistent, transient uint32, reason string)
 }
 
 // blockManangerConfig is a configuration struct used to initialize a new
@@ -152,6 +246,7 @@ type blockManagerConfig struct {
 	PeerNotifier PeerNotifier
 	Chain        *blockchain.BlockChain
 	TxMemPool    *mempool.TxPool
+	FeeEstimator *mempool.FeeEstimator
 	ChainParams  *chaincfg.Params
 
 	DisableCheckpoints bool
@@ -165,6 +260,7 @@ type peerSyncState struct {
 	requestQueue    []*wire.InvVect
 	requestedTxns   map[chainhash.Hash]struct{}
 	requestedBlocks map[chainhash.Hash]struct{}
+	cmpctBlock      *partialBlock
 }
 
 // blockManager provides a concurrency safe block manager for handling all
@@ -175,6 +271,7 @@ type blockManager struct {
 	shutdown       int32
 	chain          *blockchain.BlockChain
 	txMemPool      *mempool.TxPool
+	feeEstimator   *mempool.FeeEstimator
 	chainParams    *chaincfg.Params
 	progressLogger *blockProgressLogger
 	msgChan        chan interface{}
@@ -186,7 +283,9 @@ type blockManager struct {
 	requestedTxns   map[chainhash.Hash]struct{}
 	requestedBlocks map[chainhash.Hash]struct{}
 	syncPeer        *peerpkg.Peer
+	syncPhase       syncPhase
 	peerStates      map[*peerpkg.Peer]*peerSyncState
+	hbCmpctPeers    []*peerpkg.Peer
 
 	// The following fields are used for headers-first mode.
 	headersFirstMode bool
@@ -211,6 +310,71 @@ func (b *blockManager) resetHeaderState(newestHash *chainhash.Hash, newestHeight
 	}
 }
 
+// setSyncPhase switches the chain synchronization to the passed phase and
+// logs the change.
+func (b *blockManager) setSyncPhase(phase syncPhase) {
+	if b.syncPhase == phase {
+		return
+	}
+	bmgrLog.Infof("Sync phase changed from %s to %s", b.syncPhase, phase)
+	b.syncPhase = phase
+}
+
+// syncStatus returns the progress of the chain synchronization.  It must only
+// be called from the block handler goroutine.
+func (b *blockManager) syncStatus() *syncStatus {
+	best := b.chain.BestSnapshot()
+	status := &syncStatus{
+		phase:          b.syncPhase,
+		headerHeight:   best.Height,
+		blockHeight:    best.Height,
+		windowStart:    best.Height + 1,
+		windowEnd:      best.Height,
+		blocksInFlight: len(b.requestedBlocks),
+	}
+
+	// The headers downloaded in headers-first mode are ahead of the
+	// blocks.  Once the blocks are fetched, the blocks of the headers
+	// before the start header have been requested.
+	if b.headersFirstMode && b.headerList.Len() > 0 {
+		lastHeader := b.headerList.Back().Value.(*headerNode)
+		if lastHeader.height > status.headerHeight {
+			status.headerHeight = lastHeader.height
+		}
+		if b.syncPhase == syncPhaseBlocks {
+			lastRequested := b.headerList.Back()
+			if b.startHeader != nil {
+				lastRequested = b.startHeader.Prev()
+			}
+			if lastRequested != nil {
+				node := lastRequested.Value.(*headerNode)
+				status.windowEnd = node.height
+			}
+		}
+	} else if b.syncPhase == syncPhaseBlocks {
+		// Blocks announced by inventory are requested in order, so
+		// the requested blocks follow the best block.
+		status.windowEnd = best.Height + int32(status.blocksInFlight)
+	}
+
+	status.syncHeight = status.headerHeight
+	if b.syncPeer != nil && b.syncPeer.LastBlock() > status.syncHeight {
+		status.syncHeight = b.syncPeer.LastBlock()
+	}
+
+	if b.current() {
+		status.phase = syncPhaseSynced
+		status.progress = 1
+		return status
+	}
+	status.progress = 1
+	if status.syncHeight > 0 && best.Height < status.syncHeight {
+		status.progress = float64(best.Height) /
+			float64(status.syncHeight)
+	}
+	return status
+}
+
 // findNextHeaderCheckpoint returns the next checkpoint after the passed height.
 // It returns nil when there is not one either because the height is already
 // later than the final checkpoint or some other reason such as disabled
@@ -326,14 +490,17 @@ func (b *blockManager) startSync() {
 
 			bestPeer.PushGetHeadersMsg(locator, b.nextCheckpoint.Hash)
 			b.headersFirstMode = true
+			b.setSyncPhase(syncPhaseHeaders)
 			bmgrLog.Infof("Downloading headers for blocks %d to "+
 				"%d from peer %s", best.Height+1,
 				b.nextCheckpoint.Height, bestPeer.Addr())
 		} else {
 			bestPeer.PushGetBlocksMsg(locator, &zeroHash)
+			b.setSyncPhase(syncPhaseBlocks)
 		}
 		b.syncPeer = bestPeer
 	} else {
+		b.setSyncPhase(syncPhaseIdle)
 		bmgrLog.Warnf("No sync peer candidates available")
 	}
 }
@@ -430,11 +597,22 @@ func (b *blockManager) handleDonePeerMsg(peer *peerpkg.Peer) {
 		delete(b.requestedBlocks, blockHash)
 	}
 
+	// Remove the peer from the high-bandwidth compact block peers so
+	// another peer can take its place.
+	for i, p := range b.hbCmpctPeers {
+		if p == peer {
+			b.hbCmpctPeers = append(b.hbCmpctPeers[:i],
+				b.hbCmpctPeers[i+1:]...)
+			break
+		}
+	}
+
 	// Attempt to find a new peer to sync from if the quitting peer is the
 	// sync peer.  Also, reset the headers-first state if in headers-first
 	// mode so
 	if b.syncPeer == peer {
 		b.syncPeer = nil
+		b.setSyncPhase(syncPhaseIdle)
 		if b.headersFirstMode {
 			best := b.chain.BestSnapshot()
 			b.resetHeaderState(&best.Hash, best.Height)
@@ -532,6 +710,26 @@ func (b *blockManager) current() bool {
 	return true
 }
 
+// blockBanScore returns the ban score increase for a peer which sent a block
+// that was rejected with the passed error.  Blocks which well-behaved peers
+// might send as well, such as duplicate blocks, blocks on forks that are too
+// old, or blocks on chains which were invalidated locally, and failures
+// unrelated to the block do not increase the score.
+func blockBanScore(err error) uint32 {
+	rerr, ok := err.(blockchain.RuleError)
+	if !ok {
+		return 0
+	}
+	switch rerr.ErrorCode {
+	case blockchain.ErrDuplicateBlock, blockchain.ErrTimeTooNew,
+		blockchain.ErrForkTooOld, blockchain.ErrCheckpointTimeTooOld,
+		blockchain.ErrInvalidAncestorBlock,
+		blockchain.ErrKnownInvalidBlock:
+		return 0
+	}
+	return 100
+}
+
 // handleBlockMsg handles block messages from all peers.
 func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 	peer := bmsg.peer
@@ -551,8 +749,9 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 		// duplicate blocks.
 		if b.chainParams != &chaincfg.RegressionNetParams {
 			bmgrLog.Warnf("Got unrequested block %v from %s -- "+
-				"disconnecting", blockHash, peer.Addr())
-			peer.Disconnect()
+				"ignoring", blockHash, peer.Addr())
+			go b.peerNotifier.AddBanScore(peer, 20, 0,
+				"unrequested block")
 			return
 		}
 	}
@@ -586,10 +785,14 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 	// will fail the insert and thus we'll retry next time we get an inv.
 	delete(state.requestedBlocks, *blockHash)
 	delete(b.requestedBlocks, *blockHash)
+	if state.cmpctBlock != nil && state.cmpctBlock.hash == *blockHash {
+		state.cmpctBlock = nil
+	}
 
 	// Process the block to include validation, best chain selection, orphan
 	// handling, etc.
-	_, isOrphan, err := b.chain.ProcessBlock(bmsg.block, behaviorFlags)
+	isMainChain, isOrphan, err := b.chain.ProcessBlock(bmsg.block,
+		behaviorFlags)
 	if err != nil {
 		// When the error is a rule error, it means the block was simply
 		// rejected as opposed to something actually going wrong, so log
@@ -611,6 +814,16 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 		// send it.
 		code, reason := mempool.ErrToRejectErr(err)
 		peer.PushRejectMsg(wire.CmdBlock, code, reason, blockHash, false)
+
+		// Increase the ban score of the peer for sending an invalid
+		// block.  Peers may relay compact blocks before fully
+		// validating them as BIP0152 allows, so only the header of
+		// those, which was checked before reconstructing the block,
+		// counts against the peer.
+		if score := blockBanScore(err); score > 0 && !bmsg.fromCmpctBlock {
+			go b.peerNotifier.AddBanScore(peer, score, 0,
+				"invalid block")
+		}
 		return
 	}
 
@@ -669,6 +882,12 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 
 		// Clear the rejected transactions.
 		b.rejectedTxns = make(map[chainhash.Hash]struct{})
+
+		// Ask the peer to announce new blocks with compact blocks
+		// since it was the most recent one to deliver a new block.
+		if isMainChain && b.current() && peer.CmpctBlockVersion() != 0 {
+			b.selectHighBandwidthPeer(peer)
+		}
 	}
 
 	// Update the block height for this peer. But only send a message to
@@ -714,6 +933,7 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 				"peer %s: %v", peer.Addr(), err)
 			return
 		}
+		b.setSyncPhase(syncPhaseHeaders)
 		bmgrLog.Infof("Downloading headers for blocks %d to %d from "+
 			"peer %s", prevHeight+1, b.nextCheckpoint.Height,
 			b.syncPeer.Addr())
@@ -735,6 +955,201 @@ func (b *blockManager) handleBlockMsg(bmsg *blockMsg) {
 	}
 }
 
+// requestFullBlock requests the block with the passed hash in full from the
+// peer.  It is used when a compact block announced by the peer can't be
+// reconstructed.  The block must already be tracked as requested.
+func (b *blockManager) requestFullBlock(peer *peerpkg.Peer, blockHash *chainhash.Hash) {
+	iv := wire.NewInvVect(wire.InvTypeBlock, blockHash)
+	if peer.IsWitnessEnabled() {
+		iv.Type = wire.InvTypeWitnessBlock
+	}
+	gdmsg := wire.NewMsgGetData()
+	gdmsg.AddInvVect(iv)
+	peer.QueueMessage(gdmsg, nil)
+}
+
+// handleCmpctBlockMsg handles cmpctblock messages from all peers.  The block is
+// reconstructed from the transactions in the memory pool when possible and
+// any missing transactions are requested from the peer via a getblocktxn
+// message.  The full block is requested instead when the compact block can't
+// be used.  Compact blocks whose header is invalid are ignored without doing
+// any of this work.
+func (b *blockManager) handleCmpctBlockMsg(cmsg *cmpctBlockMsg) {
+	peer := cmsg.peer
+	state, exists := b.peerStates[peer]
+	if !exists {
+		bmgrLog.Warnf("Received cmpctblock message from unknown peer "+
+			"%s", peer)
+		return
+	}
+
+	// Ignore compact blocks for blocks that are already known.
+	header := &cmsg.cmpctBlock.Header
+	blockHash := header.BlockHash()
+	haveBlock, err := b.chain.HaveBlock(&blockHash)
+	if err != nil {
+		bmgrLog.Errorf("Failed to check for block %v: %v", blockHash,
+			err)
+		return
+	}
+	if haveBlock {
+		return
+	}
+
+	// Ensure the header is valid before doing any work for the block so
+	// peers can't cause the memory pool to be scanned, or get a block
+	// accepted as requested, by announcing bogus headers.  When the parent
+	// is not known, the blocks leading up to the announced one are
+	// requested instead the same way they are for orphan blocks.
+	err = b.chain.CheckBlockHeader(header)
+	if err != nil {
+		rerr, ok := err.(blockchain.RuleError)
+		if ok && rerr.ErrorCode == blockchain.ErrPrevBlockNotFound {
+			locator, err := b.chain.LatestBlockLocator()
+			if err != nil {
+				bmgrLog.Warnf("Failed to get block locator for "+
+					"the latest block: %v", err)
+				return
+			}
+			peer.PushGetBlocksMsg(locator, &blockHash)
+			return
+		}
+		if ok {
+			bmgrLog.Infof("Rejected compact block %v from %s: %v",
+				blockHash, peer, err)
+		} else {
+			bmgrLog.Errorf("Failed to check header of compact "+
+				"block %v: %v", blockHash, err)
+		}
+		if score := blockBanScore(err); score > 0 {
+			go b.peerNotifier.AddBanScore(peer, score, 0,
+				"invalid compact block header")
+		}
+		return
+	}
+
+	// Track the block as requested so that either the reconstructed
+	// block or the full block is accepted from the peer.
+	if _, exists := state.requestedBlocks[blockHash]; !exists {
+		b.requestedBlocks[blockHash] = struct{}{}
+		b.limitMap(b.requestedBlocks, maxRequestedBlocks)
+		state.requestedBlocks[blockHash] = struct{}{}
+	}
+
+	// Compact blocks are only useful once the chain is current, so request
+	// the full block otherwise.
+	if !b.current() {
+		b.requestFullBlock(peer, &blockHash)
+		return
+	}
+
+	// Reconstruct as much of the block as possible from the memory pool.
+	txDescs := b.txMemPool.TxDescs()
+	txns := make([]*ltcutil.Tx, 0, len(txDescs))
+	for _, txDesc := range txDescs {
+		txns = append(txns, txDesc.Tx)
+	}
+	partial, err := newPartialBlock(cmsg.cmpctBlock,
+		peer.PreferredCmpctBlockVersion(), txns)
+	if err == errShortIDCollision {
+		bmgrLog.Debugf("Requesting full block %v from %s: %v",
+			blockHash, peer, err)
+		b.requestFullBlock(peer, &blockHash)
+		return
+	}
+	if err != nil {
+		bmgrLog.Warnf("Got invalid compact block %v from %s: %v -- "+
+			"disconnecting", blockHash, peer, err)
+		peer.Disconnect()
+		return
+	}
+
+	missing := partial.missingIndexes()
+	if len(missing) == 0 {
+		b.processPartialBlock(peer, partial)
+		return
+	}
+
+	// Request the transactions which are not in the memory pool.
+	bmgrLog.Debugf("Requesting %d of %d transactions for compact block "+
+		"%v from %s", len(missing), len(partial.txns), blockHash, peer)
+	state.cmpctBlock = partial
+	gbtmsg := wire.NewMsgGetBlockTxn(&blockHash)
+	gbtmsg.Indexes = missing
+	peer.QueueMessage(gbtmsg, nil)
+}
+
+// handleBlockTxnMsg handles blocktxn messages from all peers.  The
+// transactions complete the compact block previously announced by the peer.
+func (b *blockManager) handleBlockTxnMsg(bmsg *blockTxnMsg) {
+	peer := bmsg.peer
+	state, exists := b.peerStates[peer]
+	if !exists {
+		bmgrLog.Warnf("Received blocktxn message from unknown peer %s",
+			peer)
+		return
+	}
+
+	// Ignore transactions for a block that is not being reconstructed.
+	partial := state.cmpctBlock
+	blockHash := &bmsg.blockTxn.BlockHash
+	if partial == nil || partial.hash != *blockHash {
+		bmgrLog.Debugf("Ignoring unrequested blocktxn for block %v "+
+			"from %s", blockHash, peer)
+		return
+	}
+	state.cmpctBlock = nil
+
+	if err := partial.fill(bmsg.blockTxn.Transactions); err != nil {
+		bmgrLog.Debugf("Requesting full block %v from %s: %v",
+			blockHash, peer, err)
+		b.requestFullBlock(peer, blockHash)
+		return
+	}
+
+	b.processPartialBlock(peer, partial)
+}
+
+// processPartialBlock processes a block that has been completely reconstructed
+// from a compact block the same way as a block received in full, except that
+// failing validation does not increase the ban score of the peer.  Since short
+// id collisions with transactions in the memory pool can result in the wrong
+// transactions being used, the full block is requested instead when the
+// reconstructed transactions don't match the merkle root.
+func (b *blockManager) processPartialBlock(peer *peerpkg.Peer, partial *partialBlock) {
+	block := partial.block()
+	merkles := blockchain.BuildMerkleTreeStore(block.Transactions(), false)
+	if !partial.header.MerkleRoot.IsEqual(merkles[len(merkles)-1]) {
+		bmgrLog.Debugf("Requesting full block %v from %s: "+
+			"reconstructed block does not match merkle root",
+			partial.hash, peer)
+		b.requestFullBlock(peer, &partial.hash)
+		return
+	}
+
+	b.handleBlockMsg(&blockMsg{block: block, peer: peer,
+		fromCmpctBlock: true})
+}
+
+// selectHighBandwidthPeer asks the passed peer to announce new blocks by
+// sending compact blocks directly since it was the most recent peer to deliver
+// a new block.  The least recent high-bandwidth peer is asked to stop doing so
+// when the limit is exceeded.
+func (b *blockManager) selectHighBandwidthPeer(peer *peerpkg.Peer) {
+	peers, evicted, added := selectHighBandwidthPeer(b.hbCmpctPeers, peer)
+	b.hbCmpctPeers = peers
+	if evicted != nil {
+		evicted.QueueMessage(wire.NewMsgSendCmpct(false,
+			evicted.CmpctBlockVersion()), nil)
+	}
+	if added {
+		bmgrLog.Debugf("Selected %s as a high-bandwidth compact "+
+			"block peer", peer)
+		peer.QueueMessage(wire.NewMsgSendCmpct(true,
+			peer.CmpctBlockVersion()), nil)
+	}
+}
+
 // fetchHeaderBlocks creates and sends a request to the syncPeer for the next
 // list of blocks to be downloaded based on the current list of headers.
 func (b *blockManager) fetchHeaderBlocks() {
@@ -804,8 +1219,8 @@ func (b *blockManager) handleHeadersMsg(hmsg *headersMsg) {
 	numHeaders := len(msg.Headers)
 	if !b.headersFirstMode {
 		bmgrLog.Warnf("Got %d unrequested headers from %s -- "+
-			"disconnecting", numHeaders, peer.Addr())
-		peer.Disconnect()
+			"ignoring", numHeaders, peer.Addr())
+		go b.peerNotifier.AddBanScore(peer, 20, 0, "unrequested headers")
 		return
 	}
 
@@ -880,6 +1295,7 @@ func (b *blockManager) handleHeadersMsg(hmsg *headersMsg) {
 		b.headerList.Remove(b.headerList.Front())
 		bmgrLog.Infof("Received %v block headers: Fetching blocks",
 			b.headerList.Len())
+		b.setSyncPhase(syncPhaseBlocks)
 		b.progressLogger.SetLastLogTime(time.Now())
 		b.fetchHeaderBlocks()
 		return
@@ -1175,6 +1591,14 @@ out:
 				b.handleBlockMsg(msg)
 				msg.reply <- struct{}{}
 
+			case *cmpctBlockMsg:
+				b.handleCmpctBlockMsg(msg)
+				msg.reply <- struct{}{}
+
+			case *blockTxnMsg:
+				b.handleBlockTxnMsg(msg)
+				msg.reply <- struct{}{}
+
 			case *invMsg:
 				b.handleInvMsg(msg)
 
@@ -1191,6 +1615,9 @@ out:
 				}
 				msg.reply <- peerID
 
+			case getSyncStatusMsg:
+				msg.reply <- b.syncStatus()
+
 			case processBlockMsg:
 				_, isOrphan, err := b.chain.ProcessBlock(
 					msg.block, msg.flags)
@@ -1249,7 +1676,7 @@ func (b *blockManager) handleBlockchainNotification(notification *blockchain.Not
 
 		// Generate the inventory vector and relay it.
 		iv := wire.NewInvVect(wire.InvTypeBlock, block.Hash())
-		b.peerNotifier.RelayInventory(iv, block.MsgBlock().Header)
+		b.peerNotifier.RelayInventory(iv, block)
 
 	// A block has been connected to the main block chain.
 	case blockchain.NTBlockConnected:
@@ -1267,7 +1694,8 @@ func (b *blockManager) handleBlockchainNotification(notification *blockchain.Not
 		// transaction are NOT removed recursively because they are still
 		// valid.
 		for _, tx := range block.Transactions()[1:] {
-			b.txMemPool.RemoveTransaction(tx, false)
+			b.txMemPool.RemoveTransaction(tx, false,
+				mempool.RemovalReasonConfirmed)
 			b.txMemPool.RemoveDoubleSpends(tx)
 			b.txMemPool.RemoveOrphan(tx)
 			b.peerNotifier.TransactionConfirmed(tx)
@@ -1275,6 +1703,11 @@ func (b *blockManager) handleBlockchainNotification(notification *blockchain.Not
 			b.peerNotifier.AnnounceNewTransactions(acceptedTxs)
 		}
 
+		// Register block with the fee estimator, if it exists.
+		if b.feeEstimator != nil {
+			b.feeEstimator.RegisterBlock(block)
+		}
+
 	// A block has been disconnected from the main block chain.
 	case blockchain.NTBlockDisconnected:
 		block, ok := notification.Data.(*ltcutil.Block)
@@ -1292,9 +1725,18 @@ func (b *blockManager) handleBlockchainNotification(notification *blockchain.Not
 				// Remove the transaction and all transactions
 				// that depend on it if it wasn't accepted into
 				// the transaction pool.
-				b.txMemPool.RemoveTransaction(tx, true)
+				b.txMemPool.RemoveTransaction(tx, true,
+					mempool.RemovalReasonInvalid)
 			}
 		}
+
+		// Rollback previous block recorded by the fee estimator.  Blocks
+		// which are too old to be rolled back are left in place and
+		// cause the estimator to start over once the next block is
+		// registered.
+		if b.feeEstimator != nil {
+			b.feeEstimator.Rollback(block.Hash())
+		}
 	}
 }
 
@@ -1333,6 +1775,33 @@ func (b *blockManager) QueueBlock(block *ltcutil.Block, peer *peerpkg.Peer, done
 	b.msgChan <- &blockMsg{block: block, peer: peer, reply: done}
 }
 
+// QueueCmpctBlock adds the passed cmpctblock message and peer to the block
+// handling queue.  Responds to the done channel argument after the message is
+// processed.
+func (b *blockManager) QueueCmpctBlock(cmpctBlock *wire.MsgCmpctBlock, peer *peerpkg.Peer, done chan struct{}) {
+	// Don't accept more blocks if we're shutting down.
+	if atomic.LoadInt32(&b.shutdown) != 0 {
+		done <- struct{}{}
+		return
+	}
+
+	b.msgChan <- &cmpctBlockMsg{cmpctBlock: cmpctBlock, peer: peer,
+		reply: done}
+}
+
+// QueueBlockTxn adds the passed blocktxn message and peer to the block handling
+// queue.  Responds to the done channel argument after the message is
+// processed.
+func (b *blockManager) QueueBlockTxn(blockTxn *wire.MsgBlockTxn, peer *peerpkg.Peer, done chan struct{}) {
+	// Don't accept more blocks if we're shutting down.
+	if atomic.LoadInt32(&b.shutdown) != 0 {
+		done <- struct{}{}
+		return
+	}
+
+	b.msgChan <- &blockTxnMsg{blockTxn: blockTxn, peer: peer, reply: done}
+}
+
 // QueueInv adds the passed inv message and peer to the block handling queue.
 func (b *blockManager) QueueInv(inv *wire.MsgInv, peer *peerpkg.Peer) {
 	// No channel handling here because peers do not need to block on inv
@@ -1400,6 +1869,13 @@ func (b *blockManager) SyncPeerID() int32 {
 	return <-reply
 }
 
+// SyncStatus returns the progress of the chain synchronization.
+func (b *blockManager) SyncStatus() *syncStatus {
+	reply := make(chan *syncStatus)
+	b.msgChan <- getSyncStatusMsg{reply: reply}
+	return <-reply
+}
+
 // ProcessBlock makes use of ProcessBlock on an internal instance of a block
 // chain.  It is funneled through the block manager since btcchain is not safe
 // for concurrent access.
@@ -1435,6 +1911,7 @@ func newBlockManager(config *blockManagerConfig) (*blockManager, error) {
 		peerNotifier:    config.PeerNotifier,
 		chain:           config.Chain,
 		txMemPool:       config.TxMemPool,
+		feeEstimator:    config.FeeEstimator,
 		chainParams:     config.ChainParams,
 		rejectedTxns:    make(map[chainhash.Hash]struct{}),
 		requestedTxns:   make(map[chainhash.Hash]struct{}),
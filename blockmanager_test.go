@@ -0,0 +1,197 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcd/mempool"
+	peerpkg "github.com/ltcsuite/ltcd/peer"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// banScoreNotifier is a PeerNotifier which reports the ban score increases
+// through a channel and ignores all other notifications.
+type banScoreNotifier struct {
+	scores chan uint32
+}
+
+func (n *banScoreNotifier) AnnounceNewTransactions(newTxs []*mempool.TxDesc) {}
+
+func (n *banScoreNotifier) UpdatePeerHeights(latestBlkHash *chainhash.Hash, latestHeight int32, updateSource *peerpkg.Peer) {
+}
+
+func (n *banScoreNotifier) RelayInventory(invVect *wire.InvVect, data interface{}) {}
+
+func (n *banScoreNotifier) TransactionConfirmed(tx *ltcutil.Tx) {}
+
+func (n *banScoreNotifier) AddBanScore(p *peerpkg.Peer, persistent, transient uint32, reason string) {
+	n.scores <- persistent + transient
+}
+
+// solveTestHeader returns a header building on the passed block at the proof
+// of work limit of the passed network.  Its nonce is chosen so the header has
+// valid proof of work when requested.
+func solveTestHeader(params *chaincfg.Params, prevHash *chainhash.Hash, solve bool) *wire.BlockHeader {
+	header := &wire.BlockHeader{
+		Version:   4,
+		PrevBlock: *prevHash,
+		Timestamp: params.GenesisBlock.Header.Timestamp.Add(time.Minute),
+		Bits:      params.PowLimitBits,
+	}
+	for solve {
+		block := ltcutil.NewBlock(&wire.MsgBlock{Header: *header})
+		if blockchain.CheckProofOfWork(block, params.PowLimit) == nil {
+			break
+		}
+		header.Nonce++
+	}
+	return header
+}
+
+// newCmpctBlockTestManager returns a block manager for a new regression test
+// network chain along with a peer known to it, the notifier which reports ban
+// score increases, and a function which removes the chain database.
+func newCmpctBlockTestManager(t *testing.T) (*blockManager, *peerpkg.Peer, *banScoreNotifier, func()) {
+	dbPath, err := ioutil.TempDir("", "ltcdbmgrtest")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	db, err := database.Create("ffldb", filepath.Join(dbPath, "db"),
+		wire.TestNet)
+	if err != nil {
+		os.RemoveAll(dbPath)
+		t.Fatalf("unable to create db: %v", err)
+	}
+	teardown := func() {
+		db.Close()
+		os.RemoveAll(dbPath)
+	}
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &chaincfg.RegressionNetParams,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if err != nil {
+		teardown()
+		t.Fatalf("unable to create chain: %v", err)
+	}
+
+	notifier := &banScoreNotifier{scores: make(chan uint32, 10)}
+	bm, err := newBlockManager(&blockManagerConfig{
+		PeerNotifier: notifier,
+		Chain:        chain,
+		ChainParams:  &chaincfg.RegressionNetParams,
+		MaxPeers:     1,
+	})
+	if err != nil {
+		teardown()
+		t.Fatalf("newBlockManager: unexpected error: %v", err)
+	}
+	peer := peerpkg.NewInboundPeer(&peerpkg.Config{})
+	bm.peerStates[peer] = &peerSyncState{
+		requestedTxns:   make(map[chainhash.Hash]struct{}),
+		requestedBlocks: make(map[chainhash.Hash]struct{}),
+	}
+	return bm, peer, notifier, teardown
+}
+
+// TestCmpctBlockHeaderChecks ensures compact blocks are only tracked as
+// requested once their header passed the checks, and that peers announcing
+// compact blocks with invalid headers are penalized.
+func TestCmpctBlockHeaderChecks(t *testing.T) {
+	setLogLevel("CHAN", "off")
+	setLogLevel("BMGR", "off")
+
+	bm, peer, notifier, teardown := newCmpctBlockTestManager(t)
+	defer teardown()
+	params := &chaincfg.RegressionNetParams
+	sendCmpctBlock := func(header *wire.BlockHeader) {
+		bm.handleCmpctBlockMsg(&cmpctBlockMsg{
+			cmpctBlock: &wire.MsgCmpctBlock{Header: *header},
+			peer:       peer,
+		})
+	}
+
+	// A header with valid proof of work whose parent is unknown is not
+	// tracked as requested.
+	orphan := solveTestHeader(params, &chainhash.Hash{0x01}, true)
+	sendCmpctBlock(orphan)
+	if len(bm.requestedBlocks) != 0 {
+		t.Fatalf("compact block with unknown parent was requested")
+	}
+
+	// A header with insufficient proof of work is not tracked as requested
+	// and increases the ban score of the peer.
+	highHash := solveTestHeader(params, params.GenesisHash, false)
+	highHash.Bits = 0x1d00ffff
+	sendCmpctBlock(highHash)
+	if len(bm.requestedBlocks) != 0 {
+		t.Fatalf("compact block with invalid header was requested")
+	}
+	select {
+	case score := <-notifier.scores:
+		if score != 100 {
+			t.Fatalf("ban score increase: got %d, want 100", score)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ban score of peer was not increased")
+	}
+
+	// A valid header extending the best block is tracked as requested
+	// without affecting the ban score.
+	valid := solveTestHeader(params, params.GenesisHash, true)
+	sendCmpctBlock(valid)
+	if _, ok := bm.requestedBlocks[valid.BlockHash()]; !ok {
+		t.Fatal("compact block with valid header was not requested")
+	}
+	select {
+	case score := <-notifier.scores:
+		t.Fatalf("unexpected ban score increase of %d", score)
+	default:
+	}
+}
+
+// TestCmpctBlockBanScore ensures a block which fails validation only increases
+// the ban score of the peer which sent it when it was sent in full, since
+// BIP0152 allows peers to relay compact blocks before fully validating them.
+func TestCmpctBlockBanScore(t *testing.T) {
+	setLogLevel("CHAN", "off")
+	setLogLevel("BMGR", "off")
+
+	bm, peer, notifier, teardown := newCmpctBlockTestManager(t)
+	defer teardown()
+
+	// The block has a valid header, but no transactions.
+	header := solveTestHeader(&chaincfg.RegressionNetParams,
+		chaincfg.RegressionNetParams.GenesisHash, true)
+	block := ltcutil.NewBlock(&wire.MsgBlock{Header: *header})
+
+	bm.handleBlockMsg(&blockMsg{block: block, peer: peer,
+		fromCmpctBlock: true})
+	bm.handleBlockMsg(&blockMsg{block: block, peer: peer})
+	select {
+	case score := <-notifier.scores:
+		if score != 100 {
+			t.Fatalf("ban score increase: got %d, want 100", score)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ban score of peer was not increased")
+	}
+	select {
+	case score := <-notifier.scores:
+		t.Fatalf("unexpected ban score increase of %d", score)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
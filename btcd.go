@@ -138,8 +138,15 @@ func ltcdMain(serverChan chan<- *server) error {
 	}
 
 	// Create server and start it.
-	server, err := newServer(cfg.Listeners, db, activeNetParams.Params)
+	server, err := newServer(cfg.Listeners, db, activeNetParams.Params,
+		interruptedChan)
 	if err != nil {
+		// Return now if an interrupt signal stopped a reindex while
+		// creating the server.  It resumes on the next start.
+		if interruptRequested(interruptedChan) {
+			return nil
+		}
+
 		// TODO: this logging could do with some beautifying.
 		ltcdLog.Errorf("Unable to start server on %v: %v",
 			cfg.Listeners, err)
@@ -150,6 +157,13 @@ func ltcdMain(serverChan chan<- *server) error {
 		server.Stop()
 		server.WaitForShutdown()
 		srvrLog.Infof("Server shutdown complete")
+
+		// Write the changes to the utxo set held in memory to the
+		// database now that no more blocks are processed.
+		ltcdLog.Infof("Flushing the utxo cache...")
+		if err := server.chain.FlushUtxoCache(); err != nil {
+			ltcdLog.Errorf("Unable to flush the utxo cache: %v", err)
+		}
 	}()
 	server.Start()
 	if serverChan != nil {
@@ -72,6 +72,66 @@ func NewGenerateCmd(numBlocks uint32) *GenerateCmd {
 	}
 }
 
+// GenerateBlockCmd defines the generateblock JSON-RPC command.
+type GenerateBlockCmd struct {
+	Output       string
+	Transactions *[]string
+	IncludeHex   *bool `jsonrpcdefault:"false"`
+}
+
+// NewGenerateBlockCmd returns a new instance which can be used to issue a
+// generateblock JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGenerateBlockCmd(output string, transactions *[]string, includeHex *bool) *GenerateBlockCmd {
+	return &GenerateBlockCmd{
+		Output:       output,
+		Transactions: transactions,
+		IncludeHex:   includeHex,
+	}
+}
+
+// GenerateToAddressCmd defines the generatetoaddress JSON-RPC command.
+type GenerateToAddressCmd struct {
+	NumBlocks int64
+	Address   string
+	MaxTries  *int64 `jsonrpcdefault:"1000000"`
+}
+
+// NewGenerateToAddressCmd returns a new instance which can be used to issue a
+// generatetoaddress JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGenerateToAddressCmd(numBlocks int64, address string, maxTries *int64) *GenerateToAddressCmd {
+	return &GenerateToAddressCmd{
+		NumBlocks: numBlocks,
+		Address:   address,
+		MaxTries:  maxTries,
+	}
+}
+
+// GenerateToDescriptorCmd defines the generatetodescriptor JSON-RPC command.
+type GenerateToDescriptorCmd struct {
+	NumBlocks  int64
+	Descriptor string
+	MaxTries   *int64 `jsonrpcdefault:"1000000"`
+}
+
+// NewGenerateToDescriptorCmd returns a new instance which can be used to issue
+// a generatetodescriptor JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGenerateToDescriptorCmd(numBlocks int64, descriptor string, maxTries *int64) *GenerateToDescriptorCmd {
+	return &GenerateToDescriptorCmd{
+		NumBlocks:  numBlocks,
+		Descriptor: descriptor,
+		MaxTries:   maxTries,
+	}
+}
+
 // GetBestBlockCmd defines the getbestblock JSON-RPC command.
 type GetBestBlockCmd struct{}
 
@@ -81,6 +141,15 @@ func NewGetBestBlockCmd() *GetBestBlockCmd {
 	return &GetBestBlockCmd{}
 }
 
+// GetCacheInfoCmd defines the getcacheinfo JSON-RPC command.
+type GetCacheInfoCmd struct{}
+
+// NewGetCacheInfoCmd returns a new instance which can be used to issue a
+// getcacheinfo JSON-RPC command.
+func NewGetCacheInfoCmd() *GetCacheInfoCmd {
+	return &GetCacheInfoCmd{}
+}
+
 // GetCurrentNetCmd defines the getcurrentnet JSON-RPC command.
 type GetCurrentNetCmd struct{}
 
@@ -111,6 +180,37 @@ func NewGetHeadersCmd(blockLocators []string, hashStop string) *GetHeadersCmd {
 	}
 }
 
+// SetMiningFlagsCmd defines the setminingflags JSON-RPC command.
+type SetMiningFlagsCmd struct {
+	Flags string
+}
+
+// NewSetMiningFlagsCmd returns a new instance which can be used to issue a
+// setminingflags JSON-RPC command.
+func NewSetMiningFlagsCmd(flags string) *SetMiningFlagsCmd {
+	return &SetMiningFlagsCmd{
+		Flags: flags,
+	}
+}
+
+// SetVersionBitCmd defines the setversionbit JSON-RPC command.
+type SetVersionBitCmd struct {
+	Bit    uint8
+	Signal *bool
+}
+
+// NewSetVersionBitCmd returns a new instance which can be used to issue a
+// setversionbit JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSetVersionBitCmd(bit uint8, signal *bool) *SetVersionBitCmd {
+	return &SetVersionBitCmd{
+		Bit:    bit,
+		Signal: signal,
+	}
+}
+
 // VersionCmd defines the version JSON-RPC command.
 //
 // NOTE: This is a btcsuite extension ported from
@@ -131,8 +231,14 @@ func init() {
 	MustRegisterCmd("debuglevel", (*DebugLevelCmd)(nil), flags)
 	MustRegisterCmd("node", (*NodeCmd)(nil), flags)
 	MustRegisterCmd("generate", (*GenerateCmd)(nil), flags)
+	MustRegisterCmd("generateblock", (*GenerateBlockCmd)(nil), flags)
+	MustRegisterCmd("generatetoaddress", (*GenerateToAddressCmd)(nil), flags)
+	MustRegisterCmd("generatetodescriptor", (*GenerateToDescriptorCmd)(nil), flags)
 	MustRegisterCmd("getbestblock", (*GetBestBlockCmd)(nil), flags)
+	MustRegisterCmd("getcacheinfo", (*GetCacheInfoCmd)(nil), flags)
 	MustRegisterCmd("getcurrentnet", (*GetCurrentNetCmd)(nil), flags)
 	MustRegisterCmd("getheaders", (*GetHeadersCmd)(nil), flags)
+	MustRegisterCmd("setminingflags", (*SetMiningFlagsCmd)(nil), flags)
+	MustRegisterCmd("setversionbit", (*SetVersionBitCmd)(nil), flags)
 	MustRegisterCmd("version", (*VersionCmd)(nil), flags)
 }
@@ -114,6 +114,100 @@ func TestBtcdExtCmds(t *testing.T) {
 				NumBlocks: 1,
 			},
 		},
+		{
+			name: "generateblock",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generateblock", "addr")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGenerateBlockCmd("addr", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generateblock","params":["addr"],"id":1}`,
+			unmarshalled: &btcjson.GenerateBlockCmd{
+				Output:     "addr",
+				IncludeHex: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "generateblock optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generateblock", "addr",
+					`["tx1","tx2"]`, true)
+			},
+			staticCmd: func() interface{} {
+				txns := []string{"tx1", "tx2"}
+				return btcjson.NewGenerateBlockCmd("addr", &txns,
+					btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generateblock","params":["addr",["tx1","tx2"],true],"id":1}`,
+			unmarshalled: &btcjson.GenerateBlockCmd{
+				Output:       "addr",
+				Transactions: &[]string{"tx1", "tx2"},
+				IncludeHex:   btcjson.Bool(true),
+			},
+		},
+		{
+			name: "generatetoaddress",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generatetoaddress", 1, "addr")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGenerateToAddressCmd(1, "addr", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generatetoaddress","params":[1,"addr"],"id":1}`,
+			unmarshalled: &btcjson.GenerateToAddressCmd{
+				NumBlocks: 1,
+				Address:   "addr",
+				MaxTries:  btcjson.Int64(1000000),
+			},
+		},
+		{
+			name: "generatetoaddress optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generatetoaddress", 1, "addr", 10)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGenerateToAddressCmd(1, "addr",
+					btcjson.Int64(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generatetoaddress","params":[1,"addr",10],"id":1}`,
+			unmarshalled: &btcjson.GenerateToAddressCmd{
+				NumBlocks: 1,
+				Address:   "addr",
+				MaxTries:  btcjson.Int64(10),
+			},
+		},
+		{
+			name: "generatetodescriptor",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generatetodescriptor", 1, "desc")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGenerateToDescriptorCmd(1, "desc", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generatetodescriptor","params":[1,"desc"],"id":1}`,
+			unmarshalled: &btcjson.GenerateToDescriptorCmd{
+				NumBlocks:  1,
+				Descriptor: "desc",
+				MaxTries:   btcjson.Int64(1000000),
+			},
+		},
+		{
+			name: "generatetodescriptor optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("generatetodescriptor", 1, "desc", 10)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGenerateToDescriptorCmd(1, "desc",
+					btcjson.Int64(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"generatetodescriptor","params":[1,"desc",10],"id":1}`,
+			unmarshalled: &btcjson.GenerateToDescriptorCmd{
+				NumBlocks:  1,
+				Descriptor: "desc",
+				MaxTries:   btcjson.Int64(10),
+			},
+		},
 		{
 			name: "getbestblock",
 			newCmd: func() (interface{}, error) {
@@ -125,6 +219,17 @@ func TestBtcdExtCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getbestblock","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetBestBlockCmd{},
 		},
+		{
+			name: "getcacheinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getcacheinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetCacheInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getcacheinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetCacheInfoCmd{},
+		},
 		{
 			name: "getcurrentnet",
 			newCmd: func() (interface{}, error) {
@@ -176,6 +281,47 @@ func TestBtcdExtCmds(t *testing.T) {
 				HashStop: "000000000000000000ba33b33e1fad70b69e234fc24414dd47113bff38f523f7",
 			},
 		},
+		{
+			name: "setminingflags",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setminingflags", "/pool/")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetMiningFlagsCmd("/pool/")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setminingflags","params":["/pool/"],"id":1}`,
+			unmarshalled: &btcjson.SetMiningFlagsCmd{
+				Flags: "/pool/",
+			},
+		},
+		{
+			name: "setversionbit",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setversionbit", 2)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetVersionBitCmd(2, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setversionbit","params":[2],"id":1}`,
+			unmarshalled: &btcjson.SetVersionBitCmd{
+				Bit:    2,
+				Signal: nil,
+			},
+		},
+		{
+			name: "setversionbit optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setversionbit", 2, false)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetVersionBitCmd(2, btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setversionbit","params":[2,false],"id":1}`,
+			unmarshalled: &btcjson.SetVersionBitCmd{
+				Bit:    2,
+				Signal: btcjson.Bool(false),
+			},
+		},
 		{
 			name: "version",
 			newCmd: func() (interface{}, error) {
@@ -5,6 +5,28 @@
 
 package btcjson
 
+// GenerateBlockResult models the data returned from the generateblock
+// command.
+type GenerateBlockResult struct {
+	Hash string `json:"hash"`
+	Hex  string `json:"hex,omitempty"`
+}
+
+// CacheInfoResult models the statistics of a verification cache included in
+// the getcacheinfo response.
+type CacheInfoResult struct {
+	Entries    uint64 `json:"entries"`
+	MaxEntries uint64 `json:"maxentries"`
+	Hits       uint64 `json:"hits"`
+	Misses     uint64 `json:"misses"`
+}
+
+// GetCacheInfoResult models the data returned from the getcacheinfo command.
+type GetCacheInfoResult struct {
+	SigCache    CacheInfoResult `json:"sigcache"`
+	ScriptCache CacheInfoResult `json:"scriptcache"`
+}
+
 // VersionResult models objects included in the version response.  In the actual
 // result, these objects are keyed by the program or API name.
 //
@@ -23,6 +23,39 @@ func TestBtcdExtCustomResults(t *testing.T) {
 		result   interface{}
 		expected string
 	}{
+		{
+			name: "generateblockresult",
+			result: &btcjson.GenerateBlockResult{
+				Hash: "123",
+			},
+			expected: `{"hash":"123"}`,
+		},
+		{
+			name: "generateblockresult with hex",
+			result: &btcjson.GenerateBlockResult{
+				Hash: "123",
+				Hex:  "00",
+			},
+			expected: `{"hash":"123","hex":"00"}`,
+		},
+		{
+			name: "getcacheinforesult",
+			result: &btcjson.GetCacheInfoResult{
+				SigCache: btcjson.CacheInfoResult{
+					Entries:    1,
+					MaxEntries: 2,
+					Hits:       3,
+					Misses:     4,
+				},
+				ScriptCache: btcjson.CacheInfoResult{
+					Entries:    5,
+					MaxEntries: 6,
+					Hits:       7,
+					Misses:     8,
+				},
+			},
+			expected: `{"sigcache":{"entries":1,"maxentries":2,"hits":3,"misses":4},"scriptcache":{"entries":5,"maxentries":6,"hits":7,"misses":8}}`,
+		},
 		{
 			name: "versionresult",
 			result: &btcjson.VersionResult{
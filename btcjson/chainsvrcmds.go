@@ -29,6 +29,19 @@ const (
 	ANOneTry AddNodeSubCmd = "onetry"
 )
 
+// SetBanSubCmd defines the type used in the setban JSON-RPC command for the
+// sub command field.
+type SetBanSubCmd string
+
+const (
+	// SBAdd indicates the specified IP address or subnet should be banned.
+	SBAdd SetBanSubCmd = "add"
+
+	// SBRemove indicates the ban of the specified IP address or subnet
+	// should be removed.
+	SBRemove SetBanSubCmd = "remove"
+)
+
 // AddNodeCmd defines the addnode JSON-RPC command.
 type AddNodeCmd struct {
 	Addr   string
@@ -44,31 +57,115 @@ func NewAddNodeCmd(addr string, subCmd AddNodeSubCmd) *AddNodeCmd {
 	}
 }
 
+// AddPeerAddressCmd defines the addpeeraddress JSON-RPC command.
+type AddPeerAddressCmd struct {
+	Address string
+	Port    uint16
+	Tried   *bool `jsonrpcdefault:"false"`
+}
+
+// NewAddPeerAddressCmd returns a new instance which can be used to issue an
+// addpeeraddress JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewAddPeerAddressCmd(address string, port uint16, tried *bool) *AddPeerAddressCmd {
+	return &AddPeerAddressCmd{
+		Address: address,
+		Port:    port,
+		Tried:   tried,
+	}
+}
+
+// AnalyzePSBTCmd defines the analyzepsbt JSON-RPC command.
+type AnalyzePSBTCmd struct {
+	PSBT string
+}
+
+// NewAnalyzePSBTCmd returns a new instance which can be used to issue an
+// analyzepsbt JSON-RPC command.
+func NewAnalyzePSBTCmd(psbt string) *AnalyzePSBTCmd {
+	return &AnalyzePSBTCmd{
+		PSBT: psbt,
+	}
+}
+
+// ClearBannedCmd defines the clearbanned JSON-RPC command.
+type ClearBannedCmd struct{}
+
+// NewClearBannedCmd returns a new instance which can be used to issue a
+// clearbanned JSON-RPC command.
+func NewClearBannedCmd() *ClearBannedCmd {
+	return &ClearBannedCmd{}
+}
+
 // TransactionInput represents the inputs to a transaction.  Specifically a
-// transaction hash and output number pair.
+// transaction hash and output number pair along with an optional sequence
+// number which is only used by the createrawtransaction command.
 type TransactionInput struct {
-	Txid string `json:"txid"`
-	Vout uint32 `json:"vout"`
+	Txid     string `json:"txid"`
+	Vout     uint32 `json:"vout"`
+	Sequence *int64 `json:"sequence,omitempty"`
+}
+
+// ConvertToPSBTCmd defines the converttopsbt JSON-RPC command.
+type ConvertToPSBTCmd struct {
+	HexTx         string
+	PermitSigData *bool `jsonrpcdefault:"false"`
+	IsWitness     *bool
+}
+
+// NewConvertToPSBTCmd returns a new instance which can be used to issue a
+// converttopsbt JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewConvertToPSBTCmd(hexTx string, permitSigData, isWitness *bool) *ConvertToPSBTCmd {
+	return &ConvertToPSBTCmd{
+		HexTx:         hexTx,
+		PermitSigData: permitSigData,
+		IsWitness:     isWitness,
+	}
 }
 
 // CreateRawTransactionCmd defines the createrawtransaction JSON-RPC command.
 type CreateRawTransactionCmd struct {
-	Inputs   []TransactionInput
-	Amounts  map[string]float64 `jsonrpcusage:"{\"address\":amount,...}"` // In BTC
-	LockTime *int64
+	Inputs      []TransactionInput
+	Amounts     map[string]interface{} `jsonrpcusage:"{\"address\":amount,\"data\":\"hex\",...}"` // In BTC
+	LockTime    *int64
+	Replaceable *bool `jsonrpcdefault:"false"`
 }
 
 // NewCreateRawTransactionCmd returns a new instance which can be used to issue
 // a createrawtransaction JSON-RPC command.
 //
-// Amounts are in BTC.
-func NewCreateRawTransactionCmd(inputs []TransactionInput, amounts map[string]float64,
-	lockTime *int64) *CreateRawTransactionCmd {
+// Amounts are in BTC and keyed by the destination address, except for the
+// optional "data" key whose value is the hex-encoded data of an OP_RETURN
+// output.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewCreateRawTransactionCmd(inputs []TransactionInput, amounts map[string]interface{},
+	lockTime *int64, replaceable *bool) *CreateRawTransactionCmd {
 
 	return &CreateRawTransactionCmd{
-		Inputs:   inputs,
-		Amounts:  amounts,
-		LockTime: lockTime,
+		Inputs:      inputs,
+		Amounts:     amounts,
+		LockTime:    lockTime,
+		Replaceable: replaceable,
+	}
+}
+
+// DecodePSBTCmd defines the decodepsbt JSON-RPC command.
+type DecodePSBTCmd struct {
+	PSBT string
+}
+
+// NewDecodePSBTCmd returns a new instance which can be used to issue a
+// decodepsbt JSON-RPC command.
+func NewDecodePSBTCmd(psbt string) *DecodePSBTCmd {
+	return &DecodePSBTCmd{
+		PSBT: psbt,
 	}
 }
 
@@ -98,6 +195,105 @@ func NewDecodeScriptCmd(hexScript string) *DecodeScriptCmd {
 	}
 }
 
+// DescriptorRange describes the inclusive range of child indexes addresses are
+// derived for from a ranged output descriptor.  It is specified as either the
+// end index, in which case the range begins at zero, or as a [begin, end] pair.
+type DescriptorRange struct {
+	Begin int64
+	End   int64
+}
+
+// MarshalJSON provides a custom Marshal method for DescriptorRange so it is
+// marshalled as a [begin, end] pair.
+func (r DescriptorRange) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]int64{r.Begin, r.End})
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for DescriptorRange.  This
+// is necessary because the range may be specified as a bare end index.
+func (r *DescriptorRange) UnmarshalJSON(data []byte) error {
+	var end int64
+	if err := json.Unmarshal(data, &end); err == nil {
+		*r = DescriptorRange{End: end}
+		return nil
+	}
+
+	var pair [2]int64
+	if err := json.Unmarshal(data, &pair); err != nil {
+		str := "the range must be an end index or a [begin, end] pair"
+		return makeError(ErrInvalidType, str)
+	}
+	*r = DescriptorRange{Begin: pair[0], End: pair[1]}
+	return nil
+}
+
+// DeriveAddressesCmd defines the deriveaddresses JSON-RPC command.
+type DeriveAddressesCmd struct {
+	Descriptor string
+	Range      *DescriptorRange
+}
+
+// NewDeriveAddressesCmd returns a new instance which can be used to issue a
+// deriveaddresses JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewDeriveAddressesCmd(descriptor string, descRange *DescriptorRange) *DeriveAddressesCmd {
+	return &DeriveAddressesCmd{
+		Descriptor: descriptor,
+		Range:      descRange,
+	}
+}
+
+// DisconnectNodeCmd defines the disconnectnode JSON-RPC command.
+type DisconnectNodeCmd struct {
+	Address *string `jsonrpcdefault:"\"\""`
+	NodeID  *int
+}
+
+// NewDisconnectNodeCmd returns a new instance which can be used to issue a
+// disconnectnode JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewDisconnectNodeCmd(address *string, nodeID *int) *DisconnectNodeCmd {
+	return &DisconnectNodeCmd{
+		Address: address,
+		NodeID:  nodeID,
+	}
+}
+
+// DumpTxOutSetCmd defines the dumptxoutset JSON-RPC command.
+type DumpTxOutSetCmd struct {
+	Path string
+}
+
+// NewDumpTxOutSetCmd returns a new instance which can be used to issue a
+// dumptxoutset JSON-RPC command.
+func NewDumpTxOutSetCmd(path string) *DumpTxOutSetCmd {
+	return &DumpTxOutSetCmd{
+		Path: path,
+	}
+}
+
+// EstimateSmartFeeCmd defines the estimatesmartfee JSON-RPC command.
+type EstimateSmartFeeCmd struct {
+	ConfTarget   int64
+	EstimateMode *string `jsonrpcdefault:"\"CONSERVATIVE\""`
+}
+
+// NewEstimateSmartFeeCmd returns a new instance which can be used to issue an
+// estimatesmartfee JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewEstimateSmartFeeCmd(confTarget int64, estimateMode *string) *EstimateSmartFeeCmd {
+	return &EstimateSmartFeeCmd{
+		ConfTarget:   confTarget,
+		EstimateMode: estimateMode,
+	}
+}
+
 // GetAddedNodeInfoCmd defines the getaddednodeinfo JSON-RPC command.
 type GetAddedNodeInfoCmd struct {
 	DNS  bool
@@ -116,6 +312,15 @@ func NewGetAddedNodeInfoCmd(dns bool, node *string) *GetAddedNodeInfoCmd {
 	}
 }
 
+// GetAddrManInfoCmd defines the getaddrmaninfo JSON-RPC command.
+type GetAddrManInfoCmd struct{}
+
+// NewGetAddrManInfoCmd returns a new instance which can be used to issue a
+// getaddrmaninfo JSON-RPC command.
+func NewGetAddrManInfoCmd() *GetAddrManInfoCmd {
+	return &GetAddrManInfoCmd{}
+}
+
 // GetBestBlockHashCmd defines the getbestblockhash JSON-RPC command.
 type GetBestBlockHashCmd struct{}
 
@@ -127,9 +332,10 @@ func NewGetBestBlockHashCmd() *GetBestBlockHashCmd {
 
 // GetBlockCmd defines the getblock JSON-RPC command.
 type GetBlockCmd struct {
-	Hash      string
-	Verbose   *bool `jsonrpcdefault:"true"`
-	VerboseTx *bool `jsonrpcdefault:"false"`
+	Hash           string
+	Verbose        *bool `jsonrpcdefault:"true"`
+	VerboseTx      *bool `jsonrpcdefault:"false"`
+	VerbosePrevOut *bool `jsonrpcdefault:"false"`
 }
 
 // NewGetBlockCmd returns a new instance which can be used to issue a getblock
@@ -137,11 +343,12 @@ type GetBlockCmd struct {
 //
 // The parameters which are pointers indicate they are optional.  Passing nil
 // for optional parameters will use the default value.
-func NewGetBlockCmd(hash string, verbose, verboseTx *bool) *GetBlockCmd {
+func NewGetBlockCmd(hash string, verbose, verboseTx, verbosePrevOut *bool) *GetBlockCmd {
 	return &GetBlockCmd{
-		Hash:      hash,
-		Verbose:   verbose,
-		VerboseTx: verboseTx,
+		Hash:           hash,
+		Verbose:        verbose,
+		VerboseTx:      verboseTx,
+		VerbosePrevOut: verbosePrevOut,
 	}
 }
 
@@ -163,6 +370,24 @@ func NewGetBlockCountCmd() *GetBlockCountCmd {
 	return &GetBlockCountCmd{}
 }
 
+// GetBlockFilterCmd defines the getblockfilter JSON-RPC command.
+type GetBlockFilterCmd struct {
+	BlockHash  string
+	FilterType *string `jsonrpcdefault:"\"basic\""`
+}
+
+// NewGetBlockFilterCmd returns a new instance which can be used to issue a
+// getblockfilter JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockFilterCmd(blockHash string, filterType *string) *GetBlockFilterCmd {
+	return &GetBlockFilterCmd{
+		BlockHash:  blockHash,
+		FilterType: filterType,
+	}
+}
+
 // GetBlockHashCmd defines the getblockhash JSON-RPC command.
 type GetBlockHashCmd struct {
 	Index int64
@@ -191,6 +416,81 @@ func NewGetBlockHeaderCmd(hash string, verbose *bool) *GetBlockHeaderCmd {
 	}
 }
 
+// GetBlockHeadersCmd defines the getblockheaders JSON-RPC command.
+type GetBlockHeadersCmd struct {
+	Hash    string
+	Count   *int32 `jsonrpcdefault:"2000"`
+	Verbose *bool  `jsonrpcdefault:"true"`
+}
+
+// NewGetBlockHeadersCmd returns a new instance which can be used to issue a
+// getblockheaders JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockHeadersCmd(hash string, count *int32, verbose *bool) *GetBlockHeadersCmd {
+	return &GetBlockHeadersCmd{
+		Hash:    hash,
+		Count:   count,
+		Verbose: verbose,
+	}
+}
+
+// HashOrHeight identifies a block either by its hash or by its height in the
+// main chain.  Value is either a string holding the block hash or an int32
+// holding the block height.
+type HashOrHeight struct {
+	Value interface{}
+}
+
+// MarshalJSON provides a custom Marshal method for HashOrHeight so it is
+// marshalled as either a bare string or a bare number.
+func (h HashOrHeight) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.Value)
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for HashOrHeight.  This is
+// necessary because the value can only be a string or an integer.
+func (h *HashOrHeight) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch val := value.(type) {
+	case string:
+		h.Value = val
+		return nil
+	case float64:
+		if val == float64(int32(val)) {
+			h.Value = int32(val)
+			return nil
+		}
+	}
+
+	str := "the hash_or_height field must be a block hash string or a " +
+		"32-bit integer block height"
+	return makeError(ErrInvalidType, str)
+}
+
+// GetBlockStatsCmd defines the getblockstats JSON-RPC command.
+type GetBlockStatsCmd struct {
+	HashOrHeight HashOrHeight
+	Stats        *[]string
+}
+
+// NewGetBlockStatsCmd returns a new instance which can be used to issue a
+// getblockstats JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetBlockStatsCmd(hashOrHeight HashOrHeight, stats *[]string) *GetBlockStatsCmd {
+	return &GetBlockStatsCmd{
+		HashOrHeight: hashOrHeight,
+		Stats:        stats,
+	}
+}
+
 // TemplateRequest is a request object as defined in BIP22
 // (https://en.bitcoin.it/wiki/BIP_0022), it is optionally provided as an
 // pointer argument to GetBlockTemplateCmd.
@@ -214,6 +514,16 @@ type TemplateRequest struct {
 	// "proposal".
 	Data   string `json:"data,omitempty"`
 	WorkID string `json:"workid,omitempty"`
+
+	// CheckPoW requests the proof of work of a block proposal to be
+	// checked as well.  It is an extension to BIP 0023.
+	CheckPoW bool `json:"checkpow,omitempty"`
+
+	// MustInclude and Exclude are the ids of memory pool transactions
+	// the template must include and must leave out respectively.  They
+	// are an extension to BIP 0022.
+	MustInclude []string `json:"mustinclude,omitempty"`
+	Exclude     []string `json:"exclude,omitempty"`
 }
 
 // convertTemplateRequestField potentially converts the provided value as
@@ -278,33 +588,45 @@ func NewGetBlockTemplateCmd(request *TemplateRequest) *GetBlockTemplateCmd {
 		Request: request,
 	}
 }
+
+// CFilterTypeBasic is the name of the basic committed filter type.  It is
+// the only filter type which may currently be requested via the getcfilter,
+// getcfilterheader and getblockfilter JSON-RPC commands.
+const CFilterTypeBasic = "basic"
+
 // GetCFilterCmd defines the getcfilter JSON-RPC command.
 type GetCFilterCmd struct {
-	Hash     string
-	Extended bool
+	Hash       string
+	FilterType *string `jsonrpcdefault:"\"basic\""`
 }
 
 // NewGetCFilterCmd returns a new instance which can be used to issue a
 // getcfilter JSON-RPC command.
-func NewGetCFilterCmd(hash string, extended bool) *GetCFilterCmd {
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetCFilterCmd(hash string, filterType *string) *GetCFilterCmd {
 	return &GetCFilterCmd{
-		Hash:     hash,
-		Extended: extended,
+		Hash:       hash,
+		FilterType: filterType,
 	}
 }
 
 // GetCFilterHeaderCmd defines the getcfilterheader JSON-RPC command.
 type GetCFilterHeaderCmd struct {
-	Hash     string
-	Extended bool
+	Hash       string
+	FilterType *string `jsonrpcdefault:"\"basic\""`
 }
 
 // NewGetCFilterHeaderCmd returns a new instance which can be used to issue a
 // getcfilterheader JSON-RPC command.
-func NewGetCFilterHeaderCmd(hash string, extended bool) *GetCFilterHeaderCmd {
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetCFilterHeaderCmd(hash string, filterType *string) *GetCFilterHeaderCmd {
 	return &GetCFilterHeaderCmd{
-		Hash:     hash,
-		Extended: extended,
+		Hash:       hash,
+		FilterType: filterType,
 	}
 }
 
@@ -317,6 +639,24 @@ func NewGetChainTipsCmd() *GetChainTipsCmd {
 	return &GetChainTipsCmd{}
 }
 
+// GetChainTxStatsCmd defines the getchaintxstats JSON-RPC command.
+type GetChainTxStatsCmd struct {
+	NumBlocks *int32
+	BlockHash *string
+}
+
+// NewGetChainTxStatsCmd returns a new instance which can be used to issue a
+// getchaintxstats JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetChainTxStatsCmd(numBlocks *int32, blockHash *string) *GetChainTxStatsCmd {
+	return &GetChainTxStatsCmd{
+		NumBlocks: numBlocks,
+		BlockHash: blockHash,
+	}
+}
+
 // GetConnectionCountCmd defines the getconnectioncount JSON-RPC command.
 type GetConnectionCountCmd struct{}
 
@@ -326,6 +666,35 @@ func NewGetConnectionCountCmd() *GetConnectionCountCmd {
 	return &GetConnectionCountCmd{}
 }
 
+// GetDeploymentInfoCmd defines the getdeploymentinfo JSON-RPC command.
+type GetDeploymentInfoCmd struct {
+	BlockHash *string
+}
+
+// NewGetDeploymentInfoCmd returns a new instance which can be used to issue a
+// getdeploymentinfo JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetDeploymentInfoCmd(blockHash *string) *GetDeploymentInfoCmd {
+	return &GetDeploymentInfoCmd{
+		BlockHash: blockHash,
+	}
+}
+
+// GetDescriptorInfoCmd defines the getdescriptorinfo JSON-RPC command.
+type GetDescriptorInfoCmd struct {
+	Descriptor string
+}
+
+// NewGetDescriptorInfoCmd returns a new instance which can be used to issue a
+// getdescriptorinfo JSON-RPC command.
+func NewGetDescriptorInfoCmd(descriptor string) *GetDescriptorInfoCmd {
+	return &GetDescriptorInfoCmd{
+		Descriptor: descriptor,
+	}
+}
+
 // GetDifficultyCmd defines the getdifficulty JSON-RPC command.
 type GetDifficultyCmd struct{}
 
@@ -353,6 +722,22 @@ func NewGetHashesPerSecCmd() *GetHashesPerSecCmd {
 	return &GetHashesPerSecCmd{}
 }
 
+// GetIndexInfoCmd defines the getindexinfo JSON-RPC command.
+type GetIndexInfoCmd struct {
+	IndexName *string
+}
+
+// NewGetIndexInfoCmd returns a new instance which can be used to issue a
+// getindexinfo JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetIndexInfoCmd(indexName *string) *GetIndexInfoCmd {
+	return &GetIndexInfoCmd{
+		IndexName: indexName,
+	}
+}
+
 // GetInfoCmd defines the getinfo JSON-RPC command.
 type GetInfoCmd struct{}
 
@@ -362,6 +747,42 @@ func NewGetInfoCmd() *GetInfoCmd {
 	return &GetInfoCmd{}
 }
 
+// GetMempoolAncestorsCmd defines the getmempoolancestors JSON-RPC command.
+type GetMempoolAncestorsCmd struct {
+	TxID    string
+	Verbose *bool `jsonrpcdefault:"false"`
+}
+
+// NewGetMempoolAncestorsCmd returns a new instance which can be used to issue
+// a getmempoolancestors JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetMempoolAncestorsCmd(txHash string, verbose *bool) *GetMempoolAncestorsCmd {
+	return &GetMempoolAncestorsCmd{
+		TxID:    txHash,
+		Verbose: verbose,
+	}
+}
+
+// GetMempoolDescendantsCmd defines the getmempooldescendants JSON-RPC command.
+type GetMempoolDescendantsCmd struct {
+	TxID    string
+	Verbose *bool `jsonrpcdefault:"false"`
+}
+
+// NewGetMempoolDescendantsCmd returns a new instance which can be used to
+// issue a getmempooldescendants JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetMempoolDescendantsCmd(txHash string, verbose *bool) *GetMempoolDescendantsCmd {
+	return &GetMempoolDescendantsCmd{
+		TxID:    txHash,
+		Verbose: verbose,
+	}
+}
+
 // GetMempoolEntryCmd defines the getmempoolentry JSON-RPC command.
 type GetMempoolEntryCmd struct {
 	TxID string
@@ -429,6 +850,22 @@ func NewGetNetworkHashPSCmd(numBlocks, height *int) *GetNetworkHashPSCmd {
 	}
 }
 
+// GetNodeAddressesCmd defines the getnodeaddresses JSON-RPC command.
+type GetNodeAddressesCmd struct {
+	Count *int `jsonrpcdefault:"1"`
+}
+
+// NewGetNodeAddressesCmd returns a new instance which can be used to issue a
+// getnodeaddresses JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetNodeAddressesCmd(count *int) *GetNodeAddressesCmd {
+	return &GetNodeAddressesCmd{
+		Count: count,
+	}
+}
+
 // GetPeerInfoCmd defines the getpeerinfo JSON-RPC command.
 type GetPeerInfoCmd struct{}
 
@@ -440,7 +877,8 @@ func NewGetPeerInfoCmd() *GetPeerInfoCmd {
 
 // GetRawMempoolCmd defines the getmempool JSON-RPC command.
 type GetRawMempoolCmd struct {
-	Verbose *bool `jsonrpcdefault:"false"`
+	Verbose         *bool `jsonrpcdefault:"false"`
+	MempoolSequence *bool `jsonrpcdefault:"false"`
 }
 
 // NewGetRawMempoolCmd returns a new instance which can be used to issue a
@@ -448,19 +886,49 @@ type GetRawMempoolCmd struct {
 //
 // The parameters which are pointers indicate they are optional.  Passing nil
 // for optional parameters will use the default value.
-func NewGetRawMempoolCmd(verbose *bool) *GetRawMempoolCmd {
+func NewGetRawMempoolCmd(verbose, mempoolSequence *bool) *GetRawMempoolCmd {
 	return &GetRawMempoolCmd{
-		Verbose: verbose,
+		Verbose:         verbose,
+		MempoolSequence: mempoolSequence,
+	}
+}
+
+// Verbosity is the level of detail of the result of a command.  It is
+// unmarshalled from either an integer level or a boolean for compatibility with
+// commands which formerly only accepted a verbose flag, in which case false and
+// true map to levels 0 and 1 respectively.
+type Verbosity int
+
+// UnmarshalJSON provides a custom Unmarshal method for Verbosity.  This is
+// necessary because the value can either be an integer or a boolean.
+func (v *Verbosity) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch val := value.(type) {
+	case bool:
+		*v = 0
+		if val {
+			*v = 1
+		}
+		return nil
+	case float64:
+		if val == float64(int(val)) {
+			*v = Verbosity(val)
+			return nil
+		}
 	}
+
+	str := "the verbosity field must be an integer level or a boolean"
+	return makeError(ErrInvalidType, str)
 }
 
 // GetRawTransactionCmd defines the getrawtransaction JSON-RPC command.
-//
-// NOTE: This field is an int versus a bool to remain compatible with Bitcoin
-// Core even though it really should be a bool.
 type GetRawTransactionCmd struct {
 	Txid    string
-	Verbose *int `jsonrpcdefault:"0"`
+	Verbose *Verbosity `jsonrpcdefault:"0"`
 }
 
 // NewGetRawTransactionCmd returns a new instance which can be used to issue a
@@ -468,13 +936,22 @@ type GetRawTransactionCmd struct {
 //
 // The parameters which are pointers indicate they are optional.  Passing nil
 // for optional parameters will use the default value.
-func NewGetRawTransactionCmd(txHash string, verbose *int) *GetRawTransactionCmd {
+func NewGetRawTransactionCmd(txHash string, verbose *Verbosity) *GetRawTransactionCmd {
 	return &GetRawTransactionCmd{
 		Txid:    txHash,
 		Verbose: verbose,
 	}
 }
 
+// GetSyncStatusCmd defines the getsyncstatus JSON-RPC command.
+type GetSyncStatusCmd struct{}
+
+// NewGetSyncStatusCmd returns a new instance which can be used to issue a
+// getsyncstatus JSON-RPC command.
+func NewGetSyncStatusCmd() *GetSyncStatusCmd {
+	return &GetSyncStatusCmd{}
+}
+
 // GetTxOutCmd defines the gettxout JSON-RPC command.
 type GetTxOutCmd struct {
 	Txid           string
@@ -513,13 +990,32 @@ func NewGetTxOutProofCmd(txIDs []string, blockHash *string) *GetTxOutProofCmd {
 	}
 }
 
+// The following constants define the hash types which may be requested via
+// the gettxoutsetinfo JSON-RPC command.
+const (
+	// TxOutSetHashTypeSerialized requests the hash of the serialized
+	// unspent transaction output set.
+	TxOutSetHashTypeSerialized = "hash_serialized"
+
+	// TxOutSetHashTypeNone requests the hash of the unspent transaction
+	// output set is not calculated.
+	TxOutSetHashTypeNone = "none"
+)
+
 // GetTxOutSetInfoCmd defines the gettxoutsetinfo JSON-RPC command.
-type GetTxOutSetInfoCmd struct{}
+type GetTxOutSetInfoCmd struct {
+	HashType *string `jsonrpcdefault:"\"hash_serialized\""`
+}
 
 // NewGetTxOutSetInfoCmd returns a new instance which can be used to issue a
 // gettxoutsetinfo JSON-RPC command.
-func NewGetTxOutSetInfoCmd() *GetTxOutSetInfoCmd {
-	return &GetTxOutSetInfoCmd{}
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewGetTxOutSetInfoCmd(hashType *string) *GetTxOutSetInfoCmd {
+	return &GetTxOutSetInfoCmd{
+		HashType: hashType,
+	}
 }
 
 // GetWorkCmd defines the getwork JSON-RPC command.
@@ -567,6 +1063,28 @@ func NewInvalidateBlockCmd(blockHash string) *InvalidateBlockCmd {
 	}
 }
 
+// ListBannedCmd defines the listbanned JSON-RPC command.
+type ListBannedCmd struct{}
+
+// NewListBannedCmd returns a new instance which can be used to issue a
+// listbanned JSON-RPC command.
+func NewListBannedCmd() *ListBannedCmd {
+	return &ListBannedCmd{}
+}
+
+// LoadTxOutSetCmd defines the loadtxoutset JSON-RPC command.
+type LoadTxOutSetCmd struct {
+	Path string
+}
+
+// NewLoadTxOutSetCmd returns a new instance which can be used to issue a
+// loadtxoutset JSON-RPC command.
+func NewLoadTxOutSetCmd(path string) *LoadTxOutSetCmd {
+	return &LoadTxOutSetCmd{
+		Path: path,
+	}
+}
+
 // PingCmd defines the ping JSON-RPC command.
 type PingCmd struct{}
 
@@ -589,6 +1107,19 @@ func NewPreciousBlockCmd(blockHash string) *PreciousBlockCmd {
 	}
 }
 
+// PruneBlockChainCmd defines the pruneblockchain JSON-RPC command.
+type PruneBlockChainCmd struct {
+	Height int64
+}
+
+// NewPruneBlockChainCmd returns a new instance which can be used to issue a
+// pruneblockchain JSON-RPC command.
+func NewPruneBlockChainCmd(height int64) *PruneBlockChainCmd {
+	return &PruneBlockChainCmd{
+		Height: height,
+	}
+}
+
 // ReconsiderBlockCmd defines the reconsiderblock JSON-RPC command.
 type ReconsiderBlockCmd struct {
 	BlockHash string
@@ -602,6 +1133,70 @@ func NewReconsiderBlockCmd(blockHash string) *ReconsiderBlockCmd {
 	}
 }
 
+// ScanObject describes an output descriptor the utxo set is scanned for by the
+// scantxoutset JSON-RPC command.  Desc is either an addr(<address>) or a
+// raw(<hex script>) descriptor.
+type ScanObject struct {
+	Desc string `json:"desc"`
+}
+
+// UnmarshalJSON provides a custom Unmarshal method for ScanObject.  This is
+// necessary because a scan object may also be specified as a bare descriptor
+// string.
+func (s *ScanObject) UnmarshalJSON(data []byte) error {
+	var desc string
+	if err := json.Unmarshal(data, &desc); err == nil {
+		s.Desc = desc
+		return nil
+	}
+
+	// Use a type without the custom unmarshal method to decode the object
+	// form.
+	type scanObject ScanObject
+	var obj scanObject
+	if err := json.Unmarshal(data, &obj); err != nil {
+		str := "a scan object must be a descriptor string or an " +
+			"object with a desc field"
+		return makeError(ErrInvalidType, str)
+	}
+	*s = ScanObject(obj)
+	return nil
+}
+
+// ScanTxOutSetCmd defines the scantxoutset JSON-RPC command.
+type ScanTxOutSetCmd struct {
+	Action      string
+	ScanObjects *[]ScanObject
+}
+
+// NewScanTxOutSetCmd returns a new instance which can be used to issue a
+// scantxoutset JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewScanTxOutSetCmd(action string, scanObjects *[]ScanObject) *ScanTxOutSetCmd {
+	return &ScanTxOutSetCmd{
+		Action:      action,
+		ScanObjects: scanObjects,
+	}
+}
+
+// The following constants define the types of transactions which may be
+// requested via the searchrawtransactions JSON-RPC command.
+const (
+	// SearchRawTransactionsTypeSend requests the transactions which spend
+	// a previous output paying to the address.
+	SearchRawTransactionsTypeSend = "send"
+
+	// SearchRawTransactionsTypeReceive requests the transactions which
+	// create an output paying to the address.
+	SearchRawTransactionsTypeReceive = "receive"
+
+	// SearchRawTransactionsTypeBoth requests all transactions which involve
+	// the address.
+	SearchRawTransactionsTypeBoth = "both"
+)
+
 // SearchRawTransactionsCmd defines the searchrawtransactions JSON-RPC command.
 type SearchRawTransactionsCmd struct {
 	Address     string
@@ -611,6 +1206,7 @@ type SearchRawTransactionsCmd struct {
 	VinExtra    *int  `jsonrpcdefault:"0"`
 	Reverse     *bool `jsonrpcdefault:"false"`
 	FilterAddrs *[]string
+	Type        *string `jsonrpcdefault:"\"both\""`
 }
 
 // NewSearchRawTransactionsCmd returns a new instance which can be used to issue a
@@ -618,7 +1214,7 @@ type SearchRawTransactionsCmd struct {
 //
 // The parameters which are pointers indicate they are optional.  Passing nil
 // for optional parameters will use the default value.
-func NewSearchRawTransactionsCmd(address string, verbose, skip, count *int, vinExtra *int, reverse *bool, filterAddrs *[]string) *SearchRawTransactionsCmd {
+func NewSearchRawTransactionsCmd(address string, verbose, skip, count *int, vinExtra *int, reverse *bool, filterAddrs *[]string, txType *string) *SearchRawTransactionsCmd {
 	return &SearchRawTransactionsCmd{
 		Address:     address,
 		Verbose:     verbose,
@@ -627,13 +1223,49 @@ func NewSearchRawTransactionsCmd(address string, verbose, skip, count *int, vinE
 		VinExtra:    vinExtra,
 		Reverse:     reverse,
 		FilterAddrs: filterAddrs,
+		Type:        txType,
 	}
 }
 
+// DefaultMaxFeeRate is the default maximum fee rate in BTC/kvB of transactions
+// submitted with the sendrawtransaction command.
+const DefaultMaxFeeRate = 0.10
+
+// MaxFeeRate is the maximum fee rate in BTC/kvB of a transaction, where zero
+// disables the limit.  It is unmarshalled from either a number or a boolean for
+// compatibility with the former allowhighfees parameter, in which case true
+// disables the limit and false maps to DefaultMaxFeeRate.
+type MaxFeeRate float64
+
+// UnmarshalJSON provides a custom Unmarshal method for MaxFeeRate.  This is
+// necessary because the value can either be a number or a boolean.
+func (r *MaxFeeRate) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch val := value.(type) {
+	case bool:
+		*r = DefaultMaxFeeRate
+		if val {
+			*r = 0
+		}
+		return nil
+	case float64:
+		*r = MaxFeeRate(val)
+		return nil
+	}
+
+	str := "the maxfeerate field must be a number or a boolean"
+	return makeError(ErrInvalidType, str)
+}
+
 // SendRawTransactionCmd defines the sendrawtransaction JSON-RPC command.
 type SendRawTransactionCmd struct {
 	HexTx         string
-	AllowHighFees *bool `jsonrpcdefault:"false"`
+	MaxFeeRate    *MaxFeeRate `jsonrpcdefault:"0.10"`
+	MaxBurnAmount *float64    `jsonrpcdefault:"0"`
 }
 
 // NewSendRawTransactionCmd returns a new instance which can be used to issue a
@@ -641,10 +1273,35 @@ type SendRawTransactionCmd struct {
 //
 // The parameters which are pointers indicate they are optional.  Passing nil
 // for optional parameters will use the default value.
-func NewSendRawTransactionCmd(hexTx string, allowHighFees *bool) *SendRawTransactionCmd {
+func NewSendRawTransactionCmd(hexTx string, maxFeeRate *MaxFeeRate, maxBurnAmount *float64) *SendRawTransactionCmd {
 	return &SendRawTransactionCmd{
 		HexTx:         hexTx,
-		AllowHighFees: allowHighFees,
+		MaxFeeRate:    maxFeeRate,
+		MaxBurnAmount: maxBurnAmount,
+	}
+}
+
+// SetBanCmd defines the setban JSON-RPC command.
+type SetBanCmd struct {
+	SubNet   string
+	SubCmd   SetBanSubCmd `jsonrpcusage:"\"add|remove\""`
+	BanTime  *int64       `jsonrpcdefault:"0"`
+	Absolute *bool        `jsonrpcdefault:"false"`
+}
+
+// NewSetBanCmd returns a new instance which can be used to issue a setban
+// JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewSetBanCmd(subNet string, subCmd SetBanSubCmd, banTime *int64,
+	absolute *bool) *SetBanCmd {
+
+	return &SetBanCmd{
+		SubNet:   subNet,
+		SubCmd:   subCmd,
+		BanTime:  banTime,
+		Absolute: absolute,
 	}
 }
 
@@ -666,6 +1323,22 @@ func NewSetGenerateCmd(generate bool, genProcLimit *int) *SetGenerateCmd {
 	}
 }
 
+// SignMessageWithPrivKeyCmd defines the signmessagewithprivkey JSON-RPC
+// command.
+type SignMessageWithPrivKeyCmd struct {
+	PrivKey string
+	Message string
+}
+
+// NewSignMessageWithPrivKeyCmd returns a new instance which can be used to
+// issue a signmessagewithprivkey JSON-RPC command.
+func NewSignMessageWithPrivKeyCmd(privKey, message string) *SignMessageWithPrivKeyCmd {
+	return &SignMessageWithPrivKeyCmd{
+		PrivKey: privKey,
+		Message: message,
+	}
+}
+
 // StopCmd defines the stop JSON-RPC command.
 type StopCmd struct{}
 
@@ -700,6 +1373,37 @@ func NewSubmitBlockCmd(hexBlock string, options *SubmitBlockOptions) *SubmitBloc
 	}
 }
 
+// SubmitPackageCmd defines the submitpackage JSON-RPC command.
+type SubmitPackageCmd struct {
+	Package []string
+}
+
+// NewSubmitPackageCmd returns a new instance which can be used to issue a
+// submitpackage JSON-RPC command.
+func NewSubmitPackageCmd(pkg []string) *SubmitPackageCmd {
+	return &SubmitPackageCmd{
+		Package: pkg,
+	}
+}
+
+// TestMempoolAcceptCmd defines the testmempoolaccept JSON-RPC command.
+type TestMempoolAcceptCmd struct {
+	RawTxns    []string
+	MaxFeeRate *float64 `jsonrpcdefault:"0.10"`
+}
+
+// NewTestMempoolAcceptCmd returns a new instance which can be used to issue a
+// testmempoolaccept JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewTestMempoolAcceptCmd(rawTxns []string, maxFeeRate *float64) *TestMempoolAcceptCmd {
+	return &TestMempoolAcceptCmd{
+		RawTxns:    rawTxns,
+		MaxFeeRate: maxFeeRate,
+	}
+}
+
 // UptimeCmd defines the uptime JSON-RPC command.
 type UptimeCmd struct{}
 
@@ -708,6 +1412,19 @@ func NewUptimeCmd() *UptimeCmd {
 	return &UptimeCmd{}
 }
 
+// UtxoUpdatePSBTCmd defines the utxoupdatepsbt JSON-RPC command.
+type UtxoUpdatePSBTCmd struct {
+	PSBT string
+}
+
+// NewUtxoUpdatePSBTCmd returns a new instance which can be used to issue a
+// utxoupdatepsbt JSON-RPC command.
+func NewUtxoUpdatePSBTCmd(psbt string) *UtxoUpdatePSBTCmd {
+	return &UtxoUpdatePSBTCmd{
+		PSBT: psbt,
+	}
+}
+
 // ValidateAddressCmd defines the validateaddress JSON-RPC command.
 type ValidateAddressCmd struct {
 	Address string
@@ -769,56 +1486,141 @@ func NewVerifyTxOutProofCmd(proof string) *VerifyTxOutProofCmd {
 	}
 }
 
+// WaitForBlockCmd defines the waitforblock JSON-RPC command.
+type WaitForBlockCmd struct {
+	BlockHash string
+	Timeout   *int64 `jsonrpcdefault:"0"`
+}
+
+// NewWaitForBlockCmd returns a new instance which can be used to issue a
+// waitforblock JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewWaitForBlockCmd(blockHash string, timeout *int64) *WaitForBlockCmd {
+	return &WaitForBlockCmd{
+		BlockHash: blockHash,
+		Timeout:   timeout,
+	}
+}
+
+// WaitForBlockHeightCmd defines the waitforblockheight JSON-RPC command.
+type WaitForBlockHeightCmd struct {
+	Height  int64
+	Timeout *int64 `jsonrpcdefault:"0"`
+}
+
+// NewWaitForBlockHeightCmd returns a new instance which can be used to issue a
+// waitforblockheight JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewWaitForBlockHeightCmd(height int64, timeout *int64) *WaitForBlockHeightCmd {
+	return &WaitForBlockHeightCmd{
+		Height:  height,
+		Timeout: timeout,
+	}
+}
+
+// WaitForNewBlockCmd defines the waitfornewblock JSON-RPC command.
+type WaitForNewBlockCmd struct {
+	Timeout *int64 `jsonrpcdefault:"0"`
+}
+
+// NewWaitForNewBlockCmd returns a new instance which can be used to issue a
+// waitfornewblock JSON-RPC command.
+//
+// The parameters which are pointers indicate they are optional.  Passing nil
+// for optional parameters will use the default value.
+func NewWaitForNewBlockCmd(timeout *int64) *WaitForNewBlockCmd {
+	return &WaitForNewBlockCmd{
+		Timeout: timeout,
+	}
+}
+
 func init() {
 	// No special flags for commands in this file.
 	flags := UsageFlag(0)
 
 	MustRegisterCmd("addnode", (*AddNodeCmd)(nil), flags)
+	MustRegisterCmd("addpeeraddress", (*AddPeerAddressCmd)(nil), flags)
+	MustRegisterCmd("analyzepsbt", (*AnalyzePSBTCmd)(nil), flags)
+	MustRegisterCmd("clearbanned", (*ClearBannedCmd)(nil), flags)
+	MustRegisterCmd("converttopsbt", (*ConvertToPSBTCmd)(nil), flags)
 	MustRegisterCmd("createrawtransaction", (*CreateRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("decodepsbt", (*DecodePSBTCmd)(nil), flags)
 	MustRegisterCmd("decoderawtransaction", (*DecodeRawTransactionCmd)(nil), flags)
 	MustRegisterCmd("decodescript", (*DecodeScriptCmd)(nil), flags)
+	MustRegisterCmd("deriveaddresses", (*DeriveAddressesCmd)(nil), flags)
+	MustRegisterCmd("disconnectnode", (*DisconnectNodeCmd)(nil), flags)
+	MustRegisterCmd("dumptxoutset", (*DumpTxOutSetCmd)(nil), flags)
+	MustRegisterCmd("estimatesmartfee", (*EstimateSmartFeeCmd)(nil), flags)
 	MustRegisterCmd("getaddednodeinfo", (*GetAddedNodeInfoCmd)(nil), flags)
+	MustRegisterCmd("getaddrmaninfo", (*GetAddrManInfoCmd)(nil), flags)
 	MustRegisterCmd("getbestblockhash", (*GetBestBlockHashCmd)(nil), flags)
 	MustRegisterCmd("getblock", (*GetBlockCmd)(nil), flags)
 	MustRegisterCmd("getblockchaininfo", (*GetBlockChainInfoCmd)(nil), flags)
 	MustRegisterCmd("getblockcount", (*GetBlockCountCmd)(nil), flags)
+	MustRegisterCmd("getblockfilter", (*GetBlockFilterCmd)(nil), flags)
 	MustRegisterCmd("getblockhash", (*GetBlockHashCmd)(nil), flags)
 	MustRegisterCmd("getblockheader", (*GetBlockHeaderCmd)(nil), flags)
+	MustRegisterCmd("getblockheaders", (*GetBlockHeadersCmd)(nil), flags)
+	MustRegisterCmd("getblockstats", (*GetBlockStatsCmd)(nil), flags)
 	MustRegisterCmd("getblocktemplate", (*GetBlockTemplateCmd)(nil), flags)
 	MustRegisterCmd("getcfilter", (*GetCFilterCmd)(nil), flags)
 	MustRegisterCmd("getcfilterheader", (*GetCFilterHeaderCmd)(nil), flags)
 	MustRegisterCmd("getchaintips", (*GetChainTipsCmd)(nil), flags)
+	MustRegisterCmd("getchaintxstats", (*GetChainTxStatsCmd)(nil), flags)
 	MustRegisterCmd("getconnectioncount", (*GetConnectionCountCmd)(nil), flags)
+	MustRegisterCmd("getdeploymentinfo", (*GetDeploymentInfoCmd)(nil), flags)
+	MustRegisterCmd("getdescriptorinfo", (*GetDescriptorInfoCmd)(nil), flags)
 	MustRegisterCmd("getdifficulty", (*GetDifficultyCmd)(nil), flags)
 	MustRegisterCmd("getgenerate", (*GetGenerateCmd)(nil), flags)
 	MustRegisterCmd("gethashespersec", (*GetHashesPerSecCmd)(nil), flags)
+	MustRegisterCmd("getindexinfo", (*GetIndexInfoCmd)(nil), flags)
 	MustRegisterCmd("getinfo", (*GetInfoCmd)(nil), flags)
+	MustRegisterCmd("getmempoolancestors", (*GetMempoolAncestorsCmd)(nil), flags)
+	MustRegisterCmd("getmempooldescendants", (*GetMempoolDescendantsCmd)(nil), flags)
 	MustRegisterCmd("getmempoolentry", (*GetMempoolEntryCmd)(nil), flags)
 	MustRegisterCmd("getmempoolinfo", (*GetMempoolInfoCmd)(nil), flags)
 	MustRegisterCmd("getmininginfo", (*GetMiningInfoCmd)(nil), flags)
 	MustRegisterCmd("getnetworkinfo", (*GetNetworkInfoCmd)(nil), flags)
 	MustRegisterCmd("getnettotals", (*GetNetTotalsCmd)(nil), flags)
 	MustRegisterCmd("getnetworkhashps", (*GetNetworkHashPSCmd)(nil), flags)
+	MustRegisterCmd("getnodeaddresses", (*GetNodeAddressesCmd)(nil), flags)
 	MustRegisterCmd("getpeerinfo", (*GetPeerInfoCmd)(nil), flags)
 	MustRegisterCmd("getrawmempool", (*GetRawMempoolCmd)(nil), flags)
 	MustRegisterCmd("getrawtransaction", (*GetRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("getsyncstatus", (*GetSyncStatusCmd)(nil), flags)
 	MustRegisterCmd("gettxout", (*GetTxOutCmd)(nil), flags)
 	MustRegisterCmd("gettxoutproof", (*GetTxOutProofCmd)(nil), flags)
 	MustRegisterCmd("gettxoutsetinfo", (*GetTxOutSetInfoCmd)(nil), flags)
 	MustRegisterCmd("getwork", (*GetWorkCmd)(nil), flags)
 	MustRegisterCmd("help", (*HelpCmd)(nil), flags)
 	MustRegisterCmd("invalidateblock", (*InvalidateBlockCmd)(nil), flags)
+	MustRegisterCmd("listbanned", (*ListBannedCmd)(nil), flags)
+	MustRegisterCmd("loadtxoutset", (*LoadTxOutSetCmd)(nil), flags)
 	MustRegisterCmd("ping", (*PingCmd)(nil), flags)
 	MustRegisterCmd("preciousblock", (*PreciousBlockCmd)(nil), flags)
+	MustRegisterCmd("pruneblockchain", (*PruneBlockChainCmd)(nil), flags)
 	MustRegisterCmd("reconsiderblock", (*ReconsiderBlockCmd)(nil), flags)
+	MustRegisterCmd("scantxoutset", (*ScanTxOutSetCmd)(nil), flags)
 	MustRegisterCmd("searchrawtransactions", (*SearchRawTransactionsCmd)(nil), flags)
 	MustRegisterCmd("sendrawtransaction", (*SendRawTransactionCmd)(nil), flags)
+	MustRegisterCmd("setban", (*SetBanCmd)(nil), flags)
 	MustRegisterCmd("setgenerate", (*SetGenerateCmd)(nil), flags)
+	MustRegisterCmd("signmessagewithprivkey", (*SignMessageWithPrivKeyCmd)(nil), flags)
 	MustRegisterCmd("stop", (*StopCmd)(nil), flags)
 	MustRegisterCmd("submitblock", (*SubmitBlockCmd)(nil), flags)
+	MustRegisterCmd("submitpackage", (*SubmitPackageCmd)(nil), flags)
+	MustRegisterCmd("testmempoolaccept", (*TestMempoolAcceptCmd)(nil), flags)
 	MustRegisterCmd("uptime", (*UptimeCmd)(nil), flags)
+	MustRegisterCmd("utxoupdatepsbt", (*UtxoUpdatePSBTCmd)(nil), flags)
 	MustRegisterCmd("validateaddress", (*ValidateAddressCmd)(nil), flags)
 	MustRegisterCmd("verifychain", (*VerifyChainCmd)(nil), flags)
 	MustRegisterCmd("verifymessage", (*VerifyMessageCmd)(nil), flags)
 	MustRegisterCmd("verifytxoutproof", (*VerifyTxOutProofCmd)(nil), flags)
+	MustRegisterCmd("waitforblock", (*WaitForBlockCmd)(nil), flags)
+	MustRegisterCmd("waitforblockheight", (*WaitForBlockHeightCmd)(nil), flags)
+	MustRegisterCmd("waitfornewblock", (*WaitForNewBlockCmd)(nil), flags)
 }
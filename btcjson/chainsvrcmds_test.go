@@ -40,6 +40,89 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"addnode","params":["127.0.0.1","remove"],"id":1}`,
 			unmarshalled: &btcjson.AddNodeCmd{Addr: "127.0.0.1", SubCmd: btcjson.ANRemove},
 		},
+		{
+			name: "addpeeraddress",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("addpeeraddress", "1.2.3.4", 9333)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewAddPeerAddressCmd("1.2.3.4", 9333, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"addpeeraddress","params":["1.2.3.4",9333],"id":1}`,
+			unmarshalled: &btcjson.AddPeerAddressCmd{
+				Address: "1.2.3.4",
+				Port:    9333,
+				Tried:   btcjson.Bool(false),
+			},
+		},
+		{
+			name: "addpeeraddress optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("addpeeraddress", "1.2.3.4", 9333, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewAddPeerAddressCmd("1.2.3.4", 9333,
+					btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"addpeeraddress","params":["1.2.3.4",9333,true],"id":1}`,
+			unmarshalled: &btcjson.AddPeerAddressCmd{
+				Address: "1.2.3.4",
+				Port:    9333,
+				Tried:   btcjson.Bool(true),
+			},
+		},
+		{
+			name: "analyzepsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("analyzepsbt", "cHNidP8=")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewAnalyzePSBTCmd("cHNidP8=")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"analyzepsbt","params":["cHNidP8="],"id":1}`,
+			unmarshalled: &btcjson.AnalyzePSBTCmd{PSBT: "cHNidP8="},
+		},
+		{
+			name: "clearbanned",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("clearbanned")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewClearBannedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"clearbanned","params":[],"id":1}`,
+			unmarshalled: &btcjson.ClearBannedCmd{},
+		},
+		{
+			name: "converttopsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("converttopsbt", "0100")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewConvertToPSBTCmd("0100", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"converttopsbt","params":["0100"],"id":1}`,
+			unmarshalled: &btcjson.ConvertToPSBTCmd{
+				HexTx:         "0100",
+				PermitSigData: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "converttopsbt optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("converttopsbt", "0100", true, false)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewConvertToPSBTCmd("0100",
+					btcjson.Bool(true), btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"converttopsbt","params":["0100",true,false],"id":1}`,
+			unmarshalled: &btcjson.ConvertToPSBTCmd{
+				HexTx:         "0100",
+				PermitSigData: btcjson.Bool(true),
+				IsWitness:     btcjson.Bool(false),
+			},
+		},
 		{
 			name: "createrawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -50,13 +133,14 @@ func TestChainSvrCmds(t *testing.T) {
 				txInputs := []btcjson.TransactionInput{
 					{Txid: "123", Vout: 1},
 				}
-				amounts := map[string]float64{"456": .0123}
-				return btcjson.NewCreateRawTransactionCmd(txInputs, amounts, nil)
+				amounts := map[string]interface{}{"456": .0123}
+				return btcjson.NewCreateRawTransactionCmd(txInputs, amounts, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"createrawtransaction","params":[[{"txid":"123","vout":1}],{"456":0.0123}],"id":1}`,
 			unmarshalled: &btcjson.CreateRawTransactionCmd{
-				Inputs:  []btcjson.TransactionInput{{Txid: "123", Vout: 1}},
-				Amounts: map[string]float64{"456": .0123},
+				Inputs:      []btcjson.TransactionInput{{Txid: "123", Vout: 1}},
+				Amounts:     map[string]interface{}{"456": .0123},
+				Replaceable: btcjson.Bool(false),
 			},
 		},
 		{
@@ -69,17 +153,50 @@ func TestChainSvrCmds(t *testing.T) {
 				txInputs := []btcjson.TransactionInput{
 					{Txid: "123", Vout: 1},
 				}
-				amounts := map[string]float64{"456": .0123}
-				return btcjson.NewCreateRawTransactionCmd(txInputs, amounts, btcjson.Int64(12312333333))
+				amounts := map[string]interface{}{"456": .0123}
+				return btcjson.NewCreateRawTransactionCmd(txInputs, amounts, btcjson.Int64(12312333333), nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"createrawtransaction","params":[[{"txid":"123","vout":1}],{"456":0.0123},12312333333],"id":1}`,
 			unmarshalled: &btcjson.CreateRawTransactionCmd{
-				Inputs:   []btcjson.TransactionInput{{Txid: "123", Vout: 1}},
-				Amounts:  map[string]float64{"456": .0123},
-				LockTime: btcjson.Int64(12312333333),
+				Inputs:      []btcjson.TransactionInput{{Txid: "123", Vout: 1}},
+				Amounts:     map[string]interface{}{"456": .0123},
+				LockTime:    btcjson.Int64(12312333333),
+				Replaceable: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "createrawtransaction replaceable",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("createrawtransaction", `[{"txid":"123","vout":1,"sequence":5}]`,
+					`{"456":0.0123,"data":"abcd"}`, int64(0), true)
+			},
+			staticCmd: func() interface{} {
+				txInputs := []btcjson.TransactionInput{
+					{Txid: "123", Vout: 1, Sequence: btcjson.Int64(5)},
+				}
+				amounts := map[string]interface{}{"456": .0123, "data": "abcd"}
+				return btcjson.NewCreateRawTransactionCmd(txInputs, amounts, btcjson.Int64(0), btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"createrawtransaction","params":[[{"txid":"123","vout":1,"sequence":5}],{"456":0.0123,"data":"abcd"},0,true],"id":1}`,
+			unmarshalled: &btcjson.CreateRawTransactionCmd{
+				Inputs:      []btcjson.TransactionInput{{Txid: "123", Vout: 1, Sequence: btcjson.Int64(5)}},
+				Amounts:     map[string]interface{}{"456": .0123, "data": "abcd"},
+				LockTime:    btcjson.Int64(0),
+				Replaceable: btcjson.Bool(true),
 			},
 		},
 
+		{
+			name: "decodepsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("decodepsbt", "cHNidP8=")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDecodePSBTCmd("cHNidP8=")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"decodepsbt","params":["cHNidP8="],"id":1}`,
+			unmarshalled: &btcjson.DecodePSBTCmd{PSBT: "cHNidP8="},
+		},
 		{
 			name: "decoderawtransaction",
 			newCmd: func() (interface{}, error) {
@@ -102,6 +219,118 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"decodescript","params":["00"],"id":1}`,
 			unmarshalled: &btcjson.DecodeScriptCmd{HexScript: "00"},
 		},
+		{
+			name: "disconnectnode",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("disconnectnode", "127.0.0.1:9333")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDisconnectNodeCmd(btcjson.String("127.0.0.1:9333"), nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"disconnectnode","params":["127.0.0.1:9333"],"id":1}`,
+			unmarshalled: &btcjson.DisconnectNodeCmd{
+				Address: btcjson.String("127.0.0.1:9333"),
+			},
+		},
+		{
+			name: "disconnectnode nodeid",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("disconnectnode", "", 5)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDisconnectNodeCmd(btcjson.String(""), btcjson.Int(5))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"disconnectnode","params":["",5],"id":1}`,
+			unmarshalled: &btcjson.DisconnectNodeCmd{
+				Address: btcjson.String(""),
+				NodeID:  btcjson.Int(5),
+			},
+		},
+		{
+			name: "deriveaddresses",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("deriveaddresses", "addr(1Address)")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDeriveAddressesCmd("addr(1Address)", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"deriveaddresses","params":["addr(1Address)"],"id":1}`,
+			unmarshalled: &btcjson.DeriveAddressesCmd{
+				Descriptor: "addr(1Address)",
+			},
+		},
+		{
+			name: "deriveaddresses end index",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("deriveaddresses", "pkh(xpub/*)", "4")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDeriveAddressesCmd("pkh(xpub/*)",
+					&btcjson.DescriptorRange{End: 4})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"deriveaddresses","params":["pkh(xpub/*)",[0,4]],"id":1}`,
+			unmarshalled: &btcjson.DeriveAddressesCmd{
+				Descriptor: "pkh(xpub/*)",
+				Range:      &btcjson.DescriptorRange{End: 4},
+			},
+		},
+		{
+			name: "deriveaddresses range",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("deriveaddresses", "pkh(xpub/*)", "[2,5]")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDeriveAddressesCmd("pkh(xpub/*)",
+					&btcjson.DescriptorRange{Begin: 2, End: 5})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"deriveaddresses","params":["pkh(xpub/*)",[2,5]],"id":1}`,
+			unmarshalled: &btcjson.DeriveAddressesCmd{
+				Descriptor: "pkh(xpub/*)",
+				Range:      &btcjson.DescriptorRange{Begin: 2, End: 5},
+			},
+		},
+		{
+			name: "dumptxoutset",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("dumptxoutset", "utxo.dat")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewDumpTxOutSetCmd("utxo.dat")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"dumptxoutset","params":["utxo.dat"],"id":1}`,
+			unmarshalled: &btcjson.DumpTxOutSetCmd{
+				Path: "utxo.dat",
+			},
+		},
+		{
+			name: "estimatesmartfee",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("estimatesmartfee", 6)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewEstimateSmartFeeCmd(6, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"estimatesmartfee","params":[6],"id":1}`,
+			unmarshalled: &btcjson.EstimateSmartFeeCmd{
+				ConfTarget:   6,
+				EstimateMode: btcjson.String("CONSERVATIVE"),
+			},
+		},
+		{
+			name: "estimatesmartfee optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("estimatesmartfee", 6, "ECONOMICAL")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewEstimateSmartFeeCmd(6,
+					btcjson.String("ECONOMICAL"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"estimatesmartfee","params":[6,"ECONOMICAL"],"id":1}`,
+			unmarshalled: &btcjson.EstimateSmartFeeCmd{
+				ConfTarget:   6,
+				EstimateMode: btcjson.String("ECONOMICAL"),
+			},
+		},
 		{
 			name: "getaddednodeinfo",
 			newCmd: func() (interface{}, error) {
@@ -127,6 +356,17 @@ func TestChainSvrCmds(t *testing.T) {
 				Node: btcjson.String("127.0.0.1"),
 			},
 		},
+		{
+			name: "getaddrmaninfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getaddrmaninfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetAddrManInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getaddrmaninfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetAddrManInfoCmd{},
+		},
 		{
 			name: "getbestblockhash",
 			newCmd: func() (interface{}, error) {
@@ -144,13 +384,14 @@ func TestChainSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("getblock", "123")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetBlockCmd("123", nil, nil)
+				return btcjson.NewGetBlockCmd("123", nil, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getblock","params":["123"],"id":1}`,
 			unmarshalled: &btcjson.GetBlockCmd{
-				Hash:      "123",
-				Verbose:   btcjson.Bool(true),
-				VerboseTx: btcjson.Bool(false),
+				Hash:           "123",
+				Verbose:        btcjson.Bool(true),
+				VerboseTx:      btcjson.Bool(false),
+				VerbosePrevOut: btcjson.Bool(false),
 			},
 		},
 		{
@@ -163,13 +404,14 @@ func TestChainSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("getblock", "123", &verbosePtr)
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetBlockCmd("123", btcjson.Bool(true), nil)
+				return btcjson.NewGetBlockCmd("123", btcjson.Bool(true), nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getblock","params":["123",true],"id":1}`,
 			unmarshalled: &btcjson.GetBlockCmd{
-				Hash:      "123",
-				Verbose:   btcjson.Bool(true),
-				VerboseTx: btcjson.Bool(false),
+				Hash:           "123",
+				Verbose:        btcjson.Bool(true),
+				VerboseTx:      btcjson.Bool(false),
+				VerbosePrevOut: btcjson.Bool(false),
 			},
 		},
 		{
@@ -178,13 +420,30 @@ func TestChainSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("getblock", "123", true, true)
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetBlockCmd("123", btcjson.Bool(true), btcjson.Bool(true))
+				return btcjson.NewGetBlockCmd("123", btcjson.Bool(true), btcjson.Bool(true), nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getblock","params":["123",true,true],"id":1}`,
 			unmarshalled: &btcjson.GetBlockCmd{
-				Hash:      "123",
-				Verbose:   btcjson.Bool(true),
-				VerboseTx: btcjson.Bool(true),
+				Hash:           "123",
+				Verbose:        btcjson.Bool(true),
+				VerboseTx:      btcjson.Bool(true),
+				VerbosePrevOut: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getblock required optional3",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblock", "123", true, true, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockCmd("123", btcjson.Bool(true), btcjson.Bool(true), btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblock","params":["123",true,true,true],"id":1}`,
+			unmarshalled: &btcjson.GetBlockCmd{
+				Hash:           "123",
+				Verbose:        btcjson.Bool(true),
+				VerboseTx:      btcjson.Bool(true),
+				VerbosePrevOut: btcjson.Bool(true),
 			},
 		},
 		{
@@ -209,6 +468,35 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getblockcount","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetBlockCountCmd{},
 		},
+		{
+			name: "getblockfilter",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockfilter", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockFilterCmd("123", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockfilter","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.GetBlockFilterCmd{
+				BlockHash:  "123",
+				FilterType: btcjson.String(btcjson.CFilterTypeBasic),
+			},
+		},
+		{
+			name: "getblockfilter optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockfilter", "123", "basic")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockFilterCmd("123",
+					btcjson.String("basic"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockfilter","params":["123","basic"],"id":1}`,
+			unmarshalled: &btcjson.GetBlockFilterCmd{
+				BlockHash:  "123",
+				FilterType: btcjson.String("basic"),
+			},
+		},
 		{
 			name: "getblockhash",
 			newCmd: func() (interface{}, error) {
@@ -234,6 +522,66 @@ func TestChainSvrCmds(t *testing.T) {
 				Verbose: btcjson.Bool(true),
 			},
 		},
+		{
+			name: "getblockheaders",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockheaders", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockHeadersCmd("123", nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockheaders","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.GetBlockHeadersCmd{
+				Hash:    "123",
+				Count:   btcjson.Int32(2000),
+				Verbose: btcjson.Bool(true),
+			},
+		},
+		{
+			name: "getblockheaders optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockheaders", "123", 10, false)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockHeadersCmd("123",
+					btcjson.Int32(10), btcjson.Bool(false))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockheaders","params":["123",10,false],"id":1}`,
+			unmarshalled: &btcjson.GetBlockHeadersCmd{
+				Hash:    "123",
+				Count:   btcjson.Int32(10),
+				Verbose: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getblockstats height",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockstats", btcjson.HashOrHeight{Value: 123})
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockStatsCmd(btcjson.HashOrHeight{Value: 123}, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockstats","params":[123],"id":1}`,
+			unmarshalled: &btcjson.GetBlockStatsCmd{
+				HashOrHeight: btcjson.HashOrHeight{Value: int32(123)},
+			},
+		},
+		{
+			name: "getblockstats hash optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblockstats", `"deadbeef"`, `["txs","totalfee"]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetBlockStatsCmd(
+					btcjson.HashOrHeight{Value: "deadbeef"},
+					&[]string{"txs", "totalfee"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblockstats","params":["deadbeef",["txs","totalfee"]],"id":1}`,
+			unmarshalled: &btcjson.GetBlockStatsCmd{
+				HashOrHeight: btcjson.HashOrHeight{Value: "deadbeef"},
+				Stats:        &[]string{"txs", "totalfee"},
+			},
+		},
 		{
 			name: "getblocktemplate",
 			newCmd: func() (interface{}, error) {
@@ -317,30 +665,107 @@ func TestChainSvrCmds(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "getblocktemplate optional - proposal request",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblocktemplate", `{"mode":"proposal","data":"00","checkpow":true}`)
+			},
+			staticCmd: func() interface{} {
+				template := btcjson.TemplateRequest{
+					Mode:     "proposal",
+					Data:     "00",
+					CheckPoW: true,
+				}
+				return btcjson.NewGetBlockTemplateCmd(&template)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblocktemplate","params":[{"mode":"proposal","data":"00","checkpow":true}],"id":1}`,
+			unmarshalled: &btcjson.GetBlockTemplateCmd{
+				Request: &btcjson.TemplateRequest{
+					Mode:     "proposal",
+					Data:     "00",
+					CheckPoW: true,
+				},
+			},
+		},
+		{
+			name: "getblocktemplate optional - transaction selection",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getblocktemplate", `{"mode":"template","mustinclude":["01"],"exclude":["02","03"]}`)
+			},
+			staticCmd: func() interface{} {
+				template := btcjson.TemplateRequest{
+					Mode:        "template",
+					MustInclude: []string{"01"},
+					Exclude:     []string{"02", "03"},
+				}
+				return btcjson.NewGetBlockTemplateCmd(&template)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getblocktemplate","params":[{"mode":"template","mustinclude":["01"],"exclude":["02","03"]}],"id":1}`,
+			unmarshalled: &btcjson.GetBlockTemplateCmd{
+				Request: &btcjson.TemplateRequest{
+					Mode:        "template",
+					MustInclude: []string{"01"},
+					Exclude:     []string{"02", "03"},
+				},
+			},
+		},
 		{
 			name: "getcfilter",
 			newCmd: func() (interface{}, error) {
-				return btcjson.NewCmd("getcfilter", "123", false)
+				return btcjson.NewCmd("getcfilter", "123")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetCFilterCmd("123", false)
+				return btcjson.NewGetCFilterCmd("123", nil)
 			},
-			marshalled: `{"jsonrpc":"1.0","method":"getcfilter","params":["123",false],"id":1}`,
+			marshalled: `{"jsonrpc":"1.0","method":"getcfilter","params":["123"],"id":1}`,
 			unmarshalled: &btcjson.GetCFilterCmd{
-				Hash:    "123",
+				Hash:       "123",
+				FilterType: btcjson.String(btcjson.CFilterTypeBasic),
+			},
+		},
+		{
+			name: "getcfilter optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getcfilter", "123", "regular")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetCFilterCmd("123",
+					btcjson.String("regular"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcfilter","params":["123","regular"],"id":1}`,
+			unmarshalled: &btcjson.GetCFilterCmd{
+				Hash:       "123",
+				FilterType: btcjson.String("regular"),
 			},
 		},
 		{
 			name: "getcfilterheader",
 			newCmd: func() (interface{}, error) {
-				return btcjson.NewCmd("getcfilterheader", "123", false)
+				return btcjson.NewCmd("getcfilterheader", "123")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetCFilterHeaderCmd("123", false)
+				return btcjson.NewGetCFilterHeaderCmd("123", nil)
 			},
-			marshalled: `{"jsonrpc":"1.0","method":"getcfilterheader","params":["123",false],"id":1}`,
+			marshalled: `{"jsonrpc":"1.0","method":"getcfilterheader","params":["123"],"id":1}`,
 			unmarshalled: &btcjson.GetCFilterHeaderCmd{
-				Hash:    "123",
+				Hash:       "123",
+				FilterType: btcjson.String(btcjson.CFilterTypeBasic),
+			},
+		},
+		{
+			name: "getcfilterheader optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getcfilterheader", "123",
+					"basic")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetCFilterHeaderCmd("123",
+					btcjson.String("basic"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getcfilterheader","params":["123","basic"],"id":1}`,
+			unmarshalled: &btcjson.GetCFilterHeaderCmd{
+				Hash:       "123",
+				FilterType: btcjson.String("basic"),
 			},
 		},
 		{
@@ -354,6 +779,35 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getchaintips","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetChainTipsCmd{},
 		},
+		{
+			name: "getchaintxstats",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getchaintxstats")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetChainTxStatsCmd(nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getchaintxstats","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetChainTxStatsCmd{
+				NumBlocks: nil,
+				BlockHash: nil,
+			},
+		},
+		{
+			name: "getchaintxstats optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getchaintxstats", 1000, "0000afaf")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetChainTxStatsCmd(btcjson.Int32(1000),
+					btcjson.String("0000afaf"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getchaintxstats","params":[1000,"0000afaf"],"id":1}`,
+			unmarshalled: &btcjson.GetChainTxStatsCmd{
+				NumBlocks: btcjson.Int32(1000),
+				BlockHash: btcjson.String("0000afaf"),
+			},
+		},
 		{
 			name: "getconnectioncount",
 			newCmd: func() (interface{}, error) {
@@ -365,49 +819,170 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getconnectioncount","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetConnectionCountCmd{},
 		},
+		{
+			name: "getdeploymentinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdeploymentinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDeploymentInfoCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdeploymentinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetDeploymentInfoCmd{
+				BlockHash: nil,
+			},
+		},
+		{
+			name: "getdeploymentinfo optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdeploymentinfo", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDeploymentInfoCmd(btcjson.String("123"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdeploymentinfo","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.GetDeploymentInfoCmd{
+				BlockHash: btcjson.String("123"),
+			},
+		},
+		{
+			name: "getdescriptorinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getdescriptorinfo", "addr(1Address)")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDescriptorInfoCmd("addr(1Address)")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getdescriptorinfo","params":["addr(1Address)"],"id":1}`,
+			unmarshalled: &btcjson.GetDescriptorInfoCmd{
+				Descriptor: "addr(1Address)",
+			},
+		},
 		{
 			name: "getdifficulty",
 			newCmd: func() (interface{}, error) {
-				return btcjson.NewCmd("getdifficulty")
+				return btcjson.NewCmd("getdifficulty")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetDifficultyCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getdifficulty","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetDifficultyCmd{},
+		},
+		{
+			name: "getgenerate",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getgenerate")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetGenerateCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getgenerate","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetGenerateCmd{},
+		},
+		{
+			name: "gethashespersec",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("gethashespersec")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetHashesPerSecCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"gethashespersec","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetHashesPerSecCmd{},
+		},
+		{
+			name: "getindexinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getindexinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetIndexInfoCmd(nil)
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getindexinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetIndexInfoCmd{},
+		},
+		{
+			name: "getindexinfo optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getindexinfo", "txindex")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetIndexInfoCmd(btcjson.String("txindex"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getindexinfo","params":["txindex"],"id":1}`,
+			unmarshalled: &btcjson.GetIndexInfoCmd{
+				IndexName: btcjson.String("txindex"),
+			},
+		},
+		{
+			name: "getinfo",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getinfo")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetInfoCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetInfoCmd{},
+		},
+		{
+			name: "getmempoolancestors",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getmempoolancestors", "txhash")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetDifficultyCmd()
+				return btcjson.NewGetMempoolAncestorsCmd("txhash", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempoolancestors","params":["txhash"],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolAncestorsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(false),
 			},
-			marshalled:   `{"jsonrpc":"1.0","method":"getdifficulty","params":[],"id":1}`,
-			unmarshalled: &btcjson.GetDifficultyCmd{},
 		},
 		{
-			name: "getgenerate",
+			name: "getmempoolancestors optional",
 			newCmd: func() (interface{}, error) {
-				return btcjson.NewCmd("getgenerate")
+				return btcjson.NewCmd("getmempoolancestors", "txhash", true)
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetGenerateCmd()
+				return btcjson.NewGetMempoolAncestorsCmd("txhash",
+					btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempoolancestors","params":["txhash",true],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolAncestorsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(true),
 			},
-			marshalled:   `{"jsonrpc":"1.0","method":"getgenerate","params":[],"id":1}`,
-			unmarshalled: &btcjson.GetGenerateCmd{},
 		},
 		{
-			name: "gethashespersec",
+			name: "getmempooldescendants",
 			newCmd: func() (interface{}, error) {
-				return btcjson.NewCmd("gethashespersec")
+				return btcjson.NewCmd("getmempooldescendants", "txhash")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetHashesPerSecCmd()
+				return btcjson.NewGetMempoolDescendantsCmd("txhash", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempooldescendants","params":["txhash"],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolDescendantsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(false),
 			},
-			marshalled:   `{"jsonrpc":"1.0","method":"gethashespersec","params":[],"id":1}`,
-			unmarshalled: &btcjson.GetHashesPerSecCmd{},
 		},
 		{
-			name: "getinfo",
+			name: "getmempooldescendants optional",
 			newCmd: func() (interface{}, error) {
-				return btcjson.NewCmd("getinfo")
+				return btcjson.NewCmd("getmempooldescendants", "txhash", true)
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetInfoCmd()
+				return btcjson.NewGetMempoolDescendantsCmd("txhash",
+					btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getmempooldescendants","params":["txhash",true],"id":1}`,
+			unmarshalled: &btcjson.GetMempoolDescendantsCmd{
+				TxID:    "txhash",
+				Verbose: btcjson.Bool(true),
 			},
-			marshalled:   `{"jsonrpc":"1.0","method":"getinfo","params":[],"id":1}`,
-			unmarshalled: &btcjson.GetInfoCmd{},
 		},
 		{
 			name: "getmempoolentry",
@@ -466,6 +1041,32 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"getnettotals","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetNetTotalsCmd{},
 		},
+		{
+			name: "getnodeaddresses",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnodeaddresses")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNodeAddressesCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnodeaddresses","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetNodeAddressesCmd{
+				Count: btcjson.Int(1),
+			},
+		},
+		{
+			name: "getnodeaddresses optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getnodeaddresses", 10)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetNodeAddressesCmd(btcjson.Int(10))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getnodeaddresses","params":[10],"id":1}`,
+			unmarshalled: &btcjson.GetNodeAddressesCmd{
+				Count: btcjson.Int(10),
+			},
+		},
 		{
 			name: "getnetworkhashps",
 			newCmd: func() (interface{}, error) {
@@ -525,11 +1126,12 @@ func TestChainSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("getrawmempool")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetRawMempoolCmd(nil)
+				return btcjson.NewGetRawMempoolCmd(nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getrawmempool","params":[],"id":1}`,
 			unmarshalled: &btcjson.GetRawMempoolCmd{
-				Verbose: btcjson.Bool(false),
+				Verbose:         btcjson.Bool(false),
+				MempoolSequence: btcjson.Bool(false),
 			},
 		},
 		{
@@ -538,11 +1140,27 @@ func TestChainSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("getrawmempool", false)
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetRawMempoolCmd(btcjson.Bool(false))
+				return btcjson.NewGetRawMempoolCmd(btcjson.Bool(false), nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getrawmempool","params":[false],"id":1}`,
 			unmarshalled: &btcjson.GetRawMempoolCmd{
-				Verbose: btcjson.Bool(false),
+				Verbose:         btcjson.Bool(false),
+				MempoolSequence: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "getrawmempool mempool sequence",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getrawmempool", false, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetRawMempoolCmd(btcjson.Bool(false),
+					btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getrawmempool","params":[false,true],"id":1}`,
+			unmarshalled: &btcjson.GetRawMempoolCmd{
+				Verbose:         btcjson.Bool(false),
+				MempoolSequence: btcjson.Bool(true),
 			},
 		},
 		{
@@ -556,7 +1174,7 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled: `{"jsonrpc":"1.0","method":"getrawtransaction","params":["123"],"id":1}`,
 			unmarshalled: &btcjson.GetRawTransactionCmd{
 				Txid:    "123",
-				Verbose: btcjson.Int(0),
+				Verbose: btcjson.VerbosityLevel(0),
 			},
 		},
 		{
@@ -565,13 +1183,40 @@ func TestChainSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("getrawtransaction", "123", 1)
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetRawTransactionCmd("123", btcjson.Int(1))
+				return btcjson.NewGetRawTransactionCmd("123",
+					btcjson.VerbosityLevel(1))
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"getrawtransaction","params":["123",1],"id":1}`,
 			unmarshalled: &btcjson.GetRawTransactionCmd{
 				Txid:    "123",
-				Verbose: btcjson.Int(1),
+				Verbose: btcjson.VerbosityLevel(1),
+			},
+		},
+		{
+			name: "getrawtransaction verbosity 2",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getrawtransaction", "123", 2)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetRawTransactionCmd("123",
+					btcjson.VerbosityLevel(2))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"getrawtransaction","params":["123",2],"id":1}`,
+			unmarshalled: &btcjson.GetRawTransactionCmd{
+				Txid:    "123",
+				Verbose: btcjson.VerbosityLevel(2),
+			},
+		},
+		{
+			name: "getsyncstatus",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("getsyncstatus")
 			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetSyncStatusCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"getsyncstatus","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetSyncStatusCmd{},
 		},
 		{
 			name: "gettxout",
@@ -639,10 +1284,25 @@ func TestChainSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("gettxoutsetinfo")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewGetTxOutSetInfoCmd()
+				return btcjson.NewGetTxOutSetInfoCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"gettxoutsetinfo","params":[],"id":1}`,
+			unmarshalled: &btcjson.GetTxOutSetInfoCmd{
+				HashType: btcjson.String("hash_serialized"),
+			},
+		},
+		{
+			name: "gettxoutsetinfo optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("gettxoutsetinfo", "none")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewGetTxOutSetInfoCmd(btcjson.String("none"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"gettxoutsetinfo","params":["none"],"id":1}`,
+			unmarshalled: &btcjson.GetTxOutSetInfoCmd{
+				HashType: btcjson.String("none"),
 			},
-			marshalled:   `{"jsonrpc":"1.0","method":"gettxoutsetinfo","params":[],"id":1}`,
-			unmarshalled: &btcjson.GetTxOutSetInfoCmd{},
 		},
 		{
 			name: "getwork",
@@ -709,6 +1369,30 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "listbanned",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("listbanned")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewListBannedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"listbanned","params":[],"id":1}`,
+			unmarshalled: &btcjson.ListBannedCmd{},
+		},
+		{
+			name: "loadtxoutset",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("loadtxoutset", "utxo.dat")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewLoadTxOutSetCmd("utxo.dat")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"loadtxoutset","params":["utxo.dat"],"id":1}`,
+			unmarshalled: &btcjson.LoadTxOutSetCmd{
+				Path: "utxo.dat",
+			},
+		},
 		{
 			name: "ping",
 			newCmd: func() (interface{}, error) {
@@ -733,6 +1417,19 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "0123",
 			},
 		},
+		{
+			name: "pruneblockchain",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("pruneblockchain", 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewPruneBlockChainCmd(1000)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"pruneblockchain","params":[1000],"id":1}`,
+			unmarshalled: &btcjson.PruneBlockChainCmd{
+				Height: 1000,
+			},
+		},
 		{
 			name: "reconsiderblock",
 			newCmd: func() (interface{}, error) {
@@ -746,13 +1443,48 @@ func TestChainSvrCmds(t *testing.T) {
 				BlockHash: "123",
 			},
 		},
+		{
+			name: "scantxoutset",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("scantxoutset", "status")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewScanTxOutSetCmd("status", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"scantxoutset","params":["status"],"id":1}`,
+			unmarshalled: &btcjson.ScanTxOutSetCmd{
+				Action: "status",
+			},
+		},
+		{
+			name: "scantxoutset optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("scantxoutset", "start",
+					`["addr(1Address)",{"desc":"raw(51)"}]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewScanTxOutSetCmd("start",
+					&[]btcjson.ScanObject{
+						{Desc: "addr(1Address)"},
+						{Desc: "raw(51)"},
+					})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"scantxoutset","params":["start",[{"desc":"addr(1Address)"},{"desc":"raw(51)"}]],"id":1}`,
+			unmarshalled: &btcjson.ScanTxOutSetCmd{
+				Action: "start",
+				ScanObjects: &[]btcjson.ScanObject{
+					{Desc: "addr(1Address)"},
+					{Desc: "raw(51)"},
+				},
+			},
+		},
 		{
 			name: "searchrawtransactions",
 			newCmd: func() (interface{}, error) {
 				return btcjson.NewCmd("searchrawtransactions", "1Address")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewSearchRawTransactionsCmd("1Address", nil, nil, nil, nil, nil, nil)
+				return btcjson.NewSearchRawTransactionsCmd("1Address", nil, nil, nil, nil, nil, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"searchrawtransactions","params":["1Address"],"id":1}`,
 			unmarshalled: &btcjson.SearchRawTransactionsCmd{
@@ -763,6 +1495,7 @@ func TestChainSvrCmds(t *testing.T) {
 				VinExtra:    btcjson.Int(0),
 				Reverse:     btcjson.Bool(false),
 				FilterAddrs: nil,
+				Type:        btcjson.String("both"),
 			},
 		},
 		{
@@ -772,7 +1505,7 @@ func TestChainSvrCmds(t *testing.T) {
 			},
 			staticCmd: func() interface{} {
 				return btcjson.NewSearchRawTransactionsCmd("1Address",
-					btcjson.Int(0), nil, nil, nil, nil, nil)
+					btcjson.Int(0), nil, nil, nil, nil, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"searchrawtransactions","params":["1Address",0],"id":1}`,
 			unmarshalled: &btcjson.SearchRawTransactionsCmd{
@@ -783,6 +1516,7 @@ func TestChainSvrCmds(t *testing.T) {
 				VinExtra:    btcjson.Int(0),
 				Reverse:     btcjson.Bool(false),
 				FilterAddrs: nil,
+				Type:        btcjson.String("both"),
 			},
 		},
 		{
@@ -792,7 +1526,7 @@ func TestChainSvrCmds(t *testing.T) {
 			},
 			staticCmd: func() interface{} {
 				return btcjson.NewSearchRawTransactionsCmd("1Address",
-					btcjson.Int(0), btcjson.Int(5), nil, nil, nil, nil)
+					btcjson.Int(0), btcjson.Int(5), nil, nil, nil, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"searchrawtransactions","params":["1Address",0,5],"id":1}`,
 			unmarshalled: &btcjson.SearchRawTransactionsCmd{
@@ -803,6 +1537,7 @@ func TestChainSvrCmds(t *testing.T) {
 				VinExtra:    btcjson.Int(0),
 				Reverse:     btcjson.Bool(false),
 				FilterAddrs: nil,
+				Type:        btcjson.String("both"),
 			},
 		},
 		{
@@ -812,7 +1547,7 @@ func TestChainSvrCmds(t *testing.T) {
 			},
 			staticCmd: func() interface{} {
 				return btcjson.NewSearchRawTransactionsCmd("1Address",
-					btcjson.Int(0), btcjson.Int(5), btcjson.Int(10), nil, nil, nil)
+					btcjson.Int(0), btcjson.Int(5), btcjson.Int(10), nil, nil, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"searchrawtransactions","params":["1Address",0,5,10],"id":1}`,
 			unmarshalled: &btcjson.SearchRawTransactionsCmd{
@@ -823,6 +1558,7 @@ func TestChainSvrCmds(t *testing.T) {
 				VinExtra:    btcjson.Int(0),
 				Reverse:     btcjson.Bool(false),
 				FilterAddrs: nil,
+				Type:        btcjson.String("both"),
 			},
 		},
 		{
@@ -832,7 +1568,7 @@ func TestChainSvrCmds(t *testing.T) {
 			},
 			staticCmd: func() interface{} {
 				return btcjson.NewSearchRawTransactionsCmd("1Address",
-					btcjson.Int(0), btcjson.Int(5), btcjson.Int(10), btcjson.Int(1), nil, nil)
+					btcjson.Int(0), btcjson.Int(5), btcjson.Int(10), btcjson.Int(1), nil, nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"searchrawtransactions","params":["1Address",0,5,10,1],"id":1}`,
 			unmarshalled: &btcjson.SearchRawTransactionsCmd{
@@ -843,6 +1579,7 @@ func TestChainSvrCmds(t *testing.T) {
 				VinExtra:    btcjson.Int(1),
 				Reverse:     btcjson.Bool(false),
 				FilterAddrs: nil,
+				Type:        btcjson.String("both"),
 			},
 		},
 		{
@@ -852,7 +1589,7 @@ func TestChainSvrCmds(t *testing.T) {
 			},
 			staticCmd: func() interface{} {
 				return btcjson.NewSearchRawTransactionsCmd("1Address",
-					btcjson.Int(0), btcjson.Int(5), btcjson.Int(10), btcjson.Int(1), btcjson.Bool(true), nil)
+					btcjson.Int(0), btcjson.Int(5), btcjson.Int(10), btcjson.Int(1), btcjson.Bool(true), nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"searchrawtransactions","params":["1Address",0,5,10,1,true],"id":1}`,
 			unmarshalled: &btcjson.SearchRawTransactionsCmd{
@@ -863,6 +1600,7 @@ func TestChainSvrCmds(t *testing.T) {
 				VinExtra:    btcjson.Int(1),
 				Reverse:     btcjson.Bool(true),
 				FilterAddrs: nil,
+				Type:        btcjson.String("both"),
 			},
 		},
 		{
@@ -872,7 +1610,7 @@ func TestChainSvrCmds(t *testing.T) {
 			},
 			staticCmd: func() interface{} {
 				return btcjson.NewSearchRawTransactionsCmd("1Address",
-					btcjson.Int(0), btcjson.Int(5), btcjson.Int(10), btcjson.Int(1), btcjson.Bool(true), &[]string{"1Address"})
+					btcjson.Int(0), btcjson.Int(5), btcjson.Int(10), btcjson.Int(1), btcjson.Bool(true), &[]string{"1Address"}, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"searchrawtransactions","params":["1Address",0,5,10,1,true,["1Address"]],"id":1}`,
 			unmarshalled: &btcjson.SearchRawTransactionsCmd{
@@ -883,6 +1621,29 @@ func TestChainSvrCmds(t *testing.T) {
 				VinExtra:    btcjson.Int(1),
 				Reverse:     btcjson.Bool(true),
 				FilterAddrs: &[]string{"1Address"},
+				Type:        btcjson.String("both"),
+			},
+		},
+		{
+			name: "searchrawtransactions",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("searchrawtransactions", "1Address", 0, 5, 10, 1, true, []string{"1Address"}, "receive")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSearchRawTransactionsCmd("1Address",
+					btcjson.Int(0), btcjson.Int(5), btcjson.Int(10), btcjson.Int(1), btcjson.Bool(true), &[]string{"1Address"},
+					btcjson.String("receive"))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"searchrawtransactions","params":["1Address",0,5,10,1,true,["1Address"],"receive"],"id":1}`,
+			unmarshalled: &btcjson.SearchRawTransactionsCmd{
+				Address:     "1Address",
+				Verbose:     btcjson.Int(0),
+				Skip:        btcjson.Int(5),
+				Count:       btcjson.Int(10),
+				VinExtra:    btcjson.Int(1),
+				Reverse:     btcjson.Bool(true),
+				FilterAddrs: &[]string{"1Address"},
+				Type:        btcjson.String("receive"),
 			},
 		},
 		{
@@ -891,26 +1652,62 @@ func TestChainSvrCmds(t *testing.T) {
 				return btcjson.NewCmd("sendrawtransaction", "1122")
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewSendRawTransactionCmd("1122", nil)
+				return btcjson.NewSendRawTransactionCmd("1122", nil, nil)
 			},
 			marshalled: `{"jsonrpc":"1.0","method":"sendrawtransaction","params":["1122"],"id":1}`,
 			unmarshalled: &btcjson.SendRawTransactionCmd{
 				HexTx:         "1122",
-				AllowHighFees: btcjson.Bool(false),
+				MaxFeeRate:    btcjson.FeeRateLimit(0.10),
+				MaxBurnAmount: btcjson.Float64(0),
 			},
 		},
 		{
 			name: "sendrawtransaction optional",
 			newCmd: func() (interface{}, error) {
-				return btcjson.NewCmd("sendrawtransaction", "1122", false)
+				return btcjson.NewCmd("sendrawtransaction", "1122", 0.5, 0.01)
 			},
 			staticCmd: func() interface{} {
-				return btcjson.NewSendRawTransactionCmd("1122", btcjson.Bool(false))
+				return btcjson.NewSendRawTransactionCmd("1122",
+					btcjson.FeeRateLimit(0.5), btcjson.Float64(0.01))
 			},
-			marshalled: `{"jsonrpc":"1.0","method":"sendrawtransaction","params":["1122",false],"id":1}`,
+			marshalled: `{"jsonrpc":"1.0","method":"sendrawtransaction","params":["1122",0.5,0.01],"id":1}`,
 			unmarshalled: &btcjson.SendRawTransactionCmd{
 				HexTx:         "1122",
-				AllowHighFees: btcjson.Bool(false),
+				MaxFeeRate:    btcjson.FeeRateLimit(0.5),
+				MaxBurnAmount: btcjson.Float64(0.01),
+			},
+		},
+		{
+			name: "setban",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setban", "192.168.0.0/24", btcjson.SBAdd)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetBanCmd("192.168.0.0/24", btcjson.SBAdd, nil, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setban","params":["192.168.0.0/24","add"],"id":1}`,
+			unmarshalled: &btcjson.SetBanCmd{
+				SubNet:   "192.168.0.0/24",
+				SubCmd:   btcjson.SBAdd,
+				BanTime:  btcjson.Int64(0),
+				Absolute: btcjson.Bool(false),
+			},
+		},
+		{
+			name: "setban optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("setban", "192.168.0.1", btcjson.SBAdd, 1500000000, true)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSetBanCmd("192.168.0.1", btcjson.SBAdd,
+					btcjson.Int64(1500000000), btcjson.Bool(true))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"setban","params":["192.168.0.1","add",1500000000,true],"id":1}`,
+			unmarshalled: &btcjson.SetBanCmd{
+				SubNet:   "192.168.0.1",
+				SubCmd:   btcjson.SBAdd,
+				BanTime:  btcjson.Int64(1500000000),
+				Absolute: btcjson.Bool(true),
 			},
 		},
 		{
@@ -941,6 +1738,20 @@ func TestChainSvrCmds(t *testing.T) {
 				GenProcLimit: btcjson.Int(6),
 			},
 		},
+		{
+			name: "signmessagewithprivkey",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("signmessagewithprivkey", "6vKey", "test")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSignMessageWithPrivKeyCmd("6vKey", "test")
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"signmessagewithprivkey","params":["6vKey","test"],"id":1}`,
+			unmarshalled: &btcjson.SignMessageWithPrivKeyCmd{
+				PrivKey: "6vKey",
+				Message: "test",
+			},
+		},
 		{
 			name: "stop",
 			newCmd: func() (interface{}, error) {
@@ -966,6 +1777,48 @@ func TestChainSvrCmds(t *testing.T) {
 				Options:  nil,
 			},
 		},
+		{
+			name: "submitpackage",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("submitpackage", `["1122","3344"]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewSubmitPackageCmd([]string{"1122", "3344"})
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"submitpackage","params":[["1122","3344"]],"id":1}`,
+			unmarshalled: &btcjson.SubmitPackageCmd{
+				Package: []string{"1122", "3344"},
+			},
+		},
+		{
+			name: "testmempoolaccept",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("testmempoolaccept", `["1122","3344"]`)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewTestMempoolAcceptCmd([]string{"1122", "3344"}, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"testmempoolaccept","params":[["1122","3344"]],"id":1}`,
+			unmarshalled: &btcjson.TestMempoolAcceptCmd{
+				RawTxns:    []string{"1122", "3344"},
+				MaxFeeRate: btcjson.Float64(0.10),
+			},
+		},
+		{
+			name: "testmempoolaccept optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("testmempoolaccept", `["1122"]`, 0.5)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewTestMempoolAcceptCmd([]string{"1122"},
+					btcjson.Float64(0.5))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"testmempoolaccept","params":[["1122"],0.5],"id":1}`,
+			unmarshalled: &btcjson.TestMempoolAcceptCmd{
+				RawTxns:    []string{"1122"},
+				MaxFeeRate: btcjson.Float64(0.5),
+			},
+		},
 		{
 			name: "submitblock optional",
 			newCmd: func() (interface{}, error) {
@@ -996,6 +1849,17 @@ func TestChainSvrCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"uptime","params":[],"id":1}`,
 			unmarshalled: &btcjson.UptimeCmd{},
 		},
+		{
+			name: "utxoupdatepsbt",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("utxoupdatepsbt", "cHNidP8=")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewUtxoUpdatePSBTCmd("cHNidP8=")
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"utxoupdatepsbt","params":["cHNidP8="],"id":1}`,
+			unmarshalled: &btcjson.UtxoUpdatePSBTCmd{PSBT: "cHNidP8="},
+		},
 		{
 			name: "validateaddress",
 			newCmd: func() (interface{}, error) {
@@ -1079,6 +1943,90 @@ func TestChainSvrCmds(t *testing.T) {
 				Proof: "test",
 			},
 		},
+		{
+			name: "waitforblock",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("waitforblock", "123")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWaitForBlockCmd("123", nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"waitforblock","params":["123"],"id":1}`,
+			unmarshalled: &btcjson.WaitForBlockCmd{
+				BlockHash: "123",
+				Timeout:   btcjson.Int64(0),
+			},
+		},
+		{
+			name: "waitforblock optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("waitforblock", "123", 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWaitForBlockCmd("123",
+					btcjson.Int64(1000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"waitforblock","params":["123",1000],"id":1}`,
+			unmarshalled: &btcjson.WaitForBlockCmd{
+				BlockHash: "123",
+				Timeout:   btcjson.Int64(1000),
+			},
+		},
+		{
+			name: "waitforblockheight",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("waitforblockheight", 100)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWaitForBlockHeightCmd(100, nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"waitforblockheight","params":[100],"id":1}`,
+			unmarshalled: &btcjson.WaitForBlockHeightCmd{
+				Height:  100,
+				Timeout: btcjson.Int64(0),
+			},
+		},
+		{
+			name: "waitforblockheight optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("waitforblockheight", 100, 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWaitForBlockHeightCmd(100,
+					btcjson.Int64(1000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"waitforblockheight","params":[100,1000],"id":1}`,
+			unmarshalled: &btcjson.WaitForBlockHeightCmd{
+				Height:  100,
+				Timeout: btcjson.Int64(1000),
+			},
+		},
+		{
+			name: "waitfornewblock",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("waitfornewblock")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWaitForNewBlockCmd(nil)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"waitfornewblock","params":[],"id":1}`,
+			unmarshalled: &btcjson.WaitForNewBlockCmd{
+				Timeout: btcjson.Int64(0),
+			},
+		},
+		{
+			name: "waitfornewblock optional",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("waitfornewblock", 1000)
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewWaitForNewBlockCmd(btcjson.Int64(1000))
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"waitfornewblock","params":[1000],"id":1}`,
+			unmarshalled: &btcjson.WaitForNewBlockCmd{
+				Timeout: btcjson.Int64(1000),
+			},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -1149,6 +2097,83 @@ func TestChainSvrCmds(t *testing.T) {
 	}
 }
 
+// TestVerbosity ensures the verbosity of commands may be specified as either an
+// integer level or a boolean for backwards compatibility.
+func TestVerbosity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		param string
+		want  btcjson.Verbosity
+	}{
+		{param: `false`, want: 0},
+		{param: `true`, want: 1},
+		{param: `0`, want: 0},
+		{param: `1`, want: 1},
+		{param: `2`, want: 2},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		request := btcjson.Request{
+			Jsonrpc: "1.0",
+			Method:  "getrawtransaction",
+			Params: []json.RawMessage{json.RawMessage(`"123"`),
+				json.RawMessage(test.param)},
+			ID: 1,
+		}
+		cmd, err := btcjson.UnmarshalCmd(&request)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected error: %v", i,
+				test.param, err)
+			continue
+		}
+		verbose := cmd.(*btcjson.GetRawTransactionCmd).Verbose
+		if verbose == nil || *verbose != test.want {
+			t.Errorf("Test #%d (%s) got verbosity %v, want %v", i,
+				test.param, verbose, test.want)
+		}
+	}
+}
+
+// TestMaxFeeRate ensures the max fee rate of the sendrawtransaction command may
+// be specified as either a number or a boolean for backwards compatibility.
+func TestMaxFeeRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		param string
+		want  btcjson.MaxFeeRate
+	}{
+		{param: `false`, want: btcjson.DefaultMaxFeeRate},
+		{param: `true`, want: 0},
+		{param: `0`, want: 0},
+		{param: `0.5`, want: 0.5},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		request := btcjson.Request{
+			Jsonrpc: "1.0",
+			Method:  "sendrawtransaction",
+			Params: []json.RawMessage{json.RawMessage(`"1122"`),
+				json.RawMessage(test.param)},
+			ID: 1,
+		}
+		cmd, err := btcjson.UnmarshalCmd(&request)
+		if err != nil {
+			t.Errorf("Test #%d (%s) unexpected error: %v", i,
+				test.param, err)
+			continue
+		}
+		maxFeeRate := cmd.(*btcjson.SendRawTransactionCmd).MaxFeeRate
+		if maxFeeRate == nil || *maxFeeRate != test.want {
+			t.Errorf("Test #%d (%s) got max fee rate %v, want %v",
+				i, test.param, maxFeeRate, test.want)
+		}
+	}
+}
+
 // TestChainSvrCmdErrors ensures any errors that occur in the command during
 // custom mashal and unmarshal are as expected.
 func TestChainSvrCmdErrors(t *testing.T) {
@@ -1178,6 +2203,18 @@ func TestChainSvrCmdErrors(t *testing.T) {
 			marshalled: `{"sizelimit":"invalid"}`,
 			err:        btcjson.Error{ErrorCode: btcjson.ErrInvalidType},
 		},
+		{
+			name:       "fractional verbosity",
+			result:     new(btcjson.Verbosity),
+			marshalled: `1.5`,
+			err:        btcjson.Error{ErrorCode: btcjson.ErrInvalidType},
+		},
+		{
+			name:       "string verbosity",
+			result:     new(btcjson.Verbosity),
+			marshalled: `"1"`,
+			err:        btcjson.Error{ErrorCode: btcjson.ErrInvalidType},
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
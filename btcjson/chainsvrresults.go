@@ -6,6 +6,12 @@ package btcjson
 
 import "encoding/json"
 
+// AddPeerAddressResult models the data returned from the addpeeraddress
+// command.
+type AddPeerAddressResult struct {
+	Success bool `json:"success"`
+}
+
 // GetBlockHeaderVerboseResult models the data from the getblockheader command when
 // the verbose flag is set.  When the verbose flag is not set, getblockheader
 // returns a hex-encoded string.
@@ -47,6 +53,36 @@ type GetBlockVerboseResult struct {
 	NextHash      string        `json:"nextblockhash,omitempty"`
 }
 
+// AnalyzePSBTMissing models the data which is missing from an input of a
+// partially signed transaction as returned from the analyzepsbt command.
+type AnalyzePSBTMissing struct {
+	PubKeys       []string `json:"pubkeys,omitempty"`
+	Signatures    []string `json:"signatures,omitempty"`
+	RedeemScript  string   `json:"redeemscript,omitempty"`
+	WitnessScript string   `json:"witnessscript,omitempty"`
+}
+
+// AnalyzePSBTInput models the data of each input of a partially signed
+// transaction returned from the analyzepsbt command.
+type AnalyzePSBTInput struct {
+	HasUtxo bool                `json:"has_utxo"`
+	IsFinal bool                `json:"is_final"`
+	Missing *AnalyzePSBTMissing `json:"missing,omitempty"`
+	Next    string              `json:"next"`
+}
+
+// AnalyzePSBTResult models the data returned from the analyzepsbt command.
+// Only the next role and the error are set when the partially signed
+// transaction is not valid.
+type AnalyzePSBTResult struct {
+	Inputs           []AnalyzePSBTInput `json:"inputs,omitempty"`
+	EstimatedVSize   int64              `json:"estimated_vsize,omitempty"`
+	EstimatedFeeRate *float64           `json:"estimated_feerate,omitempty"`
+	Fee              *float64           `json:"fee,omitempty"`
+	Next             string             `json:"next"`
+	Error            string             `json:"error,omitempty"`
+}
+
 // CreateMultiSigResult models the data returned from the createmultisig
 // command.
 type CreateMultiSigResult struct {
@@ -54,13 +90,112 @@ type CreateMultiSigResult struct {
 	RedeemScript string `json:"redeemScript"`
 }
 
+// DecodeScriptSegwit models the segwit program which pays to a script
+// returned from the decodescript command.
+type DecodeScriptSegwit struct {
+	Asm        string   `json:"asm"`
+	Hex        string   `json:"hex"`
+	ReqSigs    int32    `json:"reqSigs,omitempty"`
+	Type       string   `json:"type"`
+	Addresses  []string `json:"addresses,omitempty"`
+	P2shSegwit string   `json:"p2sh-segwit"`
+}
+
 // DecodeScriptResult models the data returned from the decodescript command.
 type DecodeScriptResult struct {
-	Asm       string   `json:"asm"`
-	ReqSigs   int32    `json:"reqSigs,omitempty"`
-	Type      string   `json:"type"`
-	Addresses []string `json:"addresses,omitempty"`
-	P2sh      string   `json:"p2sh,omitempty"`
+	Asm       string              `json:"asm"`
+	ReqSigs   int32               `json:"reqSigs,omitempty"`
+	Type      string              `json:"type"`
+	Addresses []string            `json:"addresses,omitempty"`
+	P2sh      string              `json:"p2sh,omitempty"`
+	Segwit    *DecodeScriptSegwit `json:"segwit,omitempty"`
+}
+
+// PSBTScript models a script of a partially signed transaction returned from
+// the decodepsbt command.  The type is not set for final signature scripts.
+type PSBTScript struct {
+	Asm  string `json:"asm"`
+	Hex  string `json:"hex"`
+	Type string `json:"type,omitempty"`
+}
+
+// PSBTBip32Deriv models the BIP0032 derivation of a public key of a partially
+// signed transaction returned from the decodepsbt command.
+type PSBTBip32Deriv struct {
+	PubKey            string `json:"pubkey"`
+	MasterFingerprint string `json:"master_fingerprint"`
+	Path              string `json:"path"`
+}
+
+// PSBTXPub models an extended public key of the global map of a partially
+// signed transaction returned from the decodepsbt command.
+type PSBTXPub struct {
+	XPub              string `json:"xpub"`
+	MasterFingerprint string `json:"master_fingerprint"`
+	Path              string `json:"path"`
+}
+
+// PSBTProprietary models an entry reserved for proprietary use of a partially
+// signed transaction returned from the decodepsbt command.
+type PSBTProprietary struct {
+	Identifier string `json:"identifier"`
+	Subtype    uint64 `json:"subtype"`
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+}
+
+// PSBTWitnessUtxo models the output spent by a segwit input of a partially
+// signed transaction returned from the decodepsbt command.
+type PSBTWitnessUtxo struct {
+	Amount       float64            `json:"amount"`
+	ScriptPubKey ScriptPubKeyResult `json:"scriptPubKey"`
+}
+
+// DecodePSBTInput models the data of each input of a partially signed
+// transaction returned from the decodepsbt command.
+type DecodePSBTInput struct {
+	NonWitnessUtxo     *TxRawDecodeResult `json:"non_witness_utxo,omitempty"`
+	WitnessUtxo        *PSBTWitnessUtxo   `json:"witness_utxo,omitempty"`
+	PartialSignatures  map[string]string  `json:"partial_signatures,omitempty"`
+	Sighash            string             `json:"sighash,omitempty"`
+	RedeemScript       *PSBTScript        `json:"redeem_script,omitempty"`
+	WitnessScript      *PSBTScript        `json:"witness_script,omitempty"`
+	Bip32Derivs        []PSBTBip32Deriv   `json:"bip32_derivs,omitempty"`
+	FinalScriptSig     *PSBTScript        `json:"final_scriptSig,omitempty"`
+	FinalScriptWitness []string           `json:"final_scriptwitness,omitempty"`
+	Proprietary        []PSBTProprietary  `json:"proprietary,omitempty"`
+	Unknown            map[string]string  `json:"unknown,omitempty"`
+}
+
+// DecodePSBTOutput models the data of each output of a partially signed
+// transaction returned from the decodepsbt command.
+type DecodePSBTOutput struct {
+	RedeemScript  *PSBTScript       `json:"redeem_script,omitempty"`
+	WitnessScript *PSBTScript       `json:"witness_script,omitempty"`
+	Bip32Derivs   []PSBTBip32Deriv  `json:"bip32_derivs,omitempty"`
+	Proprietary   []PSBTProprietary `json:"proprietary,omitempty"`
+	Unknown       map[string]string `json:"unknown,omitempty"`
+}
+
+// DecodePSBTResult models the data returned from the decodepsbt command.  The
+// fee is only set when the outputs spent by all inputs are known.
+type DecodePSBTResult struct {
+	Tx          TxRawDecodeResult  `json:"tx"`
+	GlobalXPubs []PSBTXPub         `json:"global_xpubs"`
+	PSBTVersion uint32             `json:"psbt_version"`
+	Proprietary []PSBTProprietary  `json:"proprietary"`
+	Unknown     map[string]string  `json:"unknown"`
+	Inputs      []DecodePSBTInput  `json:"inputs"`
+	Outputs     []DecodePSBTOutput `json:"outputs"`
+	Fee         *float64           `json:"fee,omitempty"`
+}
+
+// EstimateSmartFeeResult models the data returned from the estimatesmartfee
+// command.
+type EstimateSmartFeeResult struct {
+	FeeRate float64  `json:"feerate"`
+	Errors  []string `json:"errors,omitempty"`
+	Blocks  int64    `json:"blocks"`
 }
 
 // GetAddedNodeInfoResultAddr models the data of the addresses portion of the
@@ -77,41 +212,129 @@ type GetAddedNodeInfoResult struct {
 	Addresses *[]GetAddedNodeInfoResultAddr `json:"addresses,omitempty"`
 }
 
-// SoftForkDescription describes the current state of a soft-fork which was
-// deployed using a super-majority block signalling.
+// SoftForkDescription describes the current state of a soft-fork.  Buried
+// soft-forks are enforced from a fixed height while the state of BIP0009
+// version bits soft-forks is described by the Bip9 field.
 type SoftForkDescription struct {
-	ID      string `json:"id"`
-	Version uint32 `json:"version"`
-	Reject  struct {
-		Status bool `json:"status"`
-	} `json:"reject"`
+	Type   string                   `json:"type"`
+	Bip9   *Bip9SoftForkDescription `json:"bip9,omitempty"`
+	Height int32                    `json:"height,omitempty"`
+	Active bool                     `json:"active"`
 }
 
 // Bip9SoftForkDescription describes the current state of a defined BIP0009
-// version bits soft-fork.
+// version bits soft-fork.  The statistics are only set while the soft-fork is
+// being signalled for.
 type Bip9SoftForkDescription struct {
-	Status    string `json:"status"`
-	Bit       uint8  `json:"bit"`
-	StartTime int64  `json:"startTime"`
-	Timeout   int64  `json:"timeout"`
-	Since     int32  `json:"since"`
+	Status     string          `json:"status"`
+	Bit        uint8           `json:"bit"`
+	StartTime  int64           `json:"start_time"`
+	Timeout    int64           `json:"timeout"`
+	Since      int32           `json:"since"`
+	Statistics *Bip9Statistics `json:"statistics,omitempty"`
+}
+
+// Bip9Statistics describes the blocks which signal for a BIP0009 version bits
+// soft-fork in the current retarget window.
+type Bip9Statistics struct {
+	Period    uint32 `json:"period"`
+	Threshold uint32 `json:"threshold"`
+	Elapsed   uint32 `json:"elapsed"`
+	Count     uint32 `json:"count"`
+	Possible  bool   `json:"possible"`
 }
 
 // GetBlockChainInfoResult models the data returned from the getblockchaininfo
 // command.
 type GetBlockChainInfoResult struct {
-	Chain                string                              `json:"chain"`
-	Blocks               int32                               `json:"blocks"`
-	Headers              int32                               `json:"headers"`
-	BestBlockHash        string                              `json:"bestblockhash"`
-	Difficulty           float64                             `json:"difficulty"`
-	MedianTime           int64                               `json:"mediantime"`
-	VerificationProgress float64                             `json:"verificationprogress,omitempty"`
-	Pruned               bool                                `json:"pruned"`
-	PruneHeight          int32                               `json:"pruneheight,omitempty"`
-	ChainWork            string                              `json:"chainwork,omitempty"`
-	SoftForks            []*SoftForkDescription              `json:"softforks"`
-	Bip9SoftForks        map[string]*Bip9SoftForkDescription `json:"bip9_softforks"`
+	Chain                string                          `json:"chain"`
+	Blocks               int32                           `json:"blocks"`
+	Headers              int32                           `json:"headers"`
+	BestBlockHash        string                          `json:"bestblockhash"`
+	Difficulty           float64                         `json:"difficulty"`
+	MedianTime           int64                           `json:"mediantime"`
+	VerificationProgress float64                         `json:"verificationprogress,omitempty"`
+	InitialBlockDownload bool                            `json:"initialblockdownload"`
+	Pruned               bool                            `json:"pruned"`
+	PruneHeight          int32                           `json:"pruneheight,omitempty"`
+	ChainWork            string                          `json:"chainwork,omitempty"`
+	OrphanBlocks         int                             `json:"orphanblocks"`
+	SoftForks            map[string]*SoftForkDescription `json:"softforks"`
+}
+
+// GetBlockFilterResult models the data returned from the getblockfilter
+// command.
+type GetBlockFilterResult struct {
+	Filter string `json:"filter"`
+	Header string `json:"header"`
+}
+
+// GetBlockStatsResult models the data returned from the getblockstats command.
+// All amounts are in litoshi and all fee rates are in litoshi per virtual
+// byte.
+type GetBlockStatsResult struct {
+	AverageFee         int64   `json:"avgfee"`
+	AverageFeeRate     int64   `json:"avgfeerate"`
+	AverageTxSize      int64   `json:"avgtxsize"`
+	Hash               string  `json:"blockhash"`
+	FeeRatePercentiles []int64 `json:"feerate_percentiles"`
+	Height             int64   `json:"height"`
+	Ins                int64   `json:"ins"`
+	MaxFee             int64   `json:"maxfee"`
+	MaxFeeRate         int64   `json:"maxfeerate"`
+	MaxTxSize          int64   `json:"maxtxsize"`
+	MedianFee          int64   `json:"medianfee"`
+	MedianTime         int64   `json:"mediantime"`
+	MedianTxSize       int64   `json:"mediantxsize"`
+	MinFee             int64   `json:"minfee"`
+	MinFeeRate         int64   `json:"minfeerate"`
+	MinTxSize          int64   `json:"mintxsize"`
+	Outs               int64   `json:"outs"`
+	Subsidy            int64   `json:"subsidy"`
+	SegWitTotalSize    int64   `json:"swtotal_size"`
+	SegWitTotalWeight  int64   `json:"swtotal_weight"`
+	SegWitTxs          int64   `json:"swtxs"`
+	Time               int64   `json:"time"`
+	TotalOut           int64   `json:"total_out"`
+	TotalSize          int64   `json:"total_size"`
+	TotalWeight        int64   `json:"total_weight"`
+	TotalFee           int64   `json:"totalfee"`
+	Txs                int64   `json:"txs"`
+	UTXOIncrease       int64   `json:"utxo_increase"`
+	UTXOSizeIncrease   int64   `json:"utxo_size_inc"`
+}
+
+// GetChainTxStatsResult models the data returned from the getchaintxstats
+// command.  The window fields are only set when the window contains at least
+// one block and the rate is only set when the window spans a positive amount
+// of time.
+type GetChainTxStatsResult struct {
+	Time                   int64    `json:"time"`
+	TxCount                int64    `json:"txcount"`
+	WindowFinalBlockHash   string   `json:"window_final_block_hash"`
+	WindowFinalBlockHeight int32    `json:"window_final_block_height"`
+	WindowBlockCount       int32    `json:"window_block_count"`
+	WindowTxCount          *int64   `json:"window_tx_count,omitempty"`
+	WindowInterval         *int64   `json:"window_interval,omitempty"`
+	TxRate                 *float64 `json:"txrate,omitempty"`
+}
+
+// GetDeploymentInfoResult models the data returned from the getdeploymentinfo
+// command.
+type GetDeploymentInfoResult struct {
+	Hash        string                          `json:"hash"`
+	Height      int32                           `json:"height"`
+	Deployments map[string]*SoftForkDescription `json:"deployments"`
+}
+
+// GetDescriptorInfoResult models the data returned from the getdescriptorinfo
+// command.
+type GetDescriptorInfoResult struct {
+	Descriptor     string `json:"descriptor"`
+	Checksum       string `json:"checksum"`
+	IsRange        bool   `json:"isrange"`
+	IsSolvable     bool   `json:"issolvable"`
+	HasPrivateKeys bool   `json:"hasprivatekeys"`
 }
 
 // GetBlockTemplateResultTx models the transactions field of the
@@ -171,32 +394,50 @@ type GetBlockTemplateResult struct {
 	// Block proposal from BIP 0023.
 	Capabilities  []string `json:"capabilities,omitempty"`
 	RejectReasion string   `json:"reject-reason,omitempty"`
+
+	// Version bits from BIP 0009.
+	VbAvailable map[string]int `json:"vbavailable"`
+	VbRequired  int            `json:"vbrequired"`
+}
+
+// GetIndexInfoResult models the data returned for each index from the
+// getindexinfo command.
+type GetIndexInfoResult struct {
+	Synced          bool  `json:"synced"`
+	BestBlockHeight int32 `json:"best_block_height"`
 }
 
 // GetMempoolEntryResult models the data returned from the getmempoolentry
 // command.
 type GetMempoolEntryResult struct {
-	Size             int32    `json:"size"`
-	Fee              float64  `json:"fee"`
-	ModifiedFee      float64  `json:"modifiedfee"`
-	Time             int64    `json:"time"`
-	Height           int64    `json:"height"`
-	StartingPriority float64  `json:"startingpriority"`
-	CurrentPriority  float64  `json:"currentpriority"`
-	DescendantCount  int64    `json:"descendantcount"`
-	DescendantSize   int64    `json:"descendantsize"`
-	DescendantFees   float64  `json:"descendantfees"`
-	AncestorCount    int64    `json:"ancestorcount"`
-	AncestorSize     int64    `json:"ancestorsize"`
-	AncestorFees     float64  `json:"ancestorfees"`
-	Depends          []string `json:"depends"`
+	Size              int32    `json:"size"`
+	Fee               float64  `json:"fee"`
+	ModifiedFee       float64  `json:"modifiedfee"`
+	Time              int64    `json:"time"`
+	Height            int64    `json:"height"`
+	StartingPriority  float64  `json:"startingpriority"`
+	CurrentPriority   float64  `json:"currentpriority"`
+	DescendantCount   int64    `json:"descendantcount"`
+	DescendantSize    int64    `json:"descendantsize"`
+	DescendantFees    float64  `json:"descendantfees"`
+	AncestorCount     int64    `json:"ancestorcount"`
+	AncestorSize      int64    `json:"ancestorsize"`
+	AncestorFees      float64  `json:"ancestorfees"`
+	Depends           []string `json:"depends"`
+	BIP125Replaceable string   `json:"bip125-replaceable"`
+	Unbroadcast       bool     `json:"unbroadcast"`
 }
 
 // GetMempoolInfoResult models the data returned from the getmempoolinfo
 // command.
 type GetMempoolInfoResult struct {
-	Size  int64 `json:"size"`
-	Bytes int64 `json:"bytes"`
+	Size             int64   `json:"size"`
+	Bytes            int64   `json:"bytes"`
+	Orphans          int64   `json:"orphans"`
+	MaxMempool       int64   `json:"maxmempool"`
+	MempoolMinFee    float64 `json:"mempoolminfee"`
+	MinRelayTxFee    float64 `json:"minrelaytxfee"`
+	UnbroadcastCount int64   `json:"unbroadcastcount"`
 }
 
 // NetworksResult models the networks data from the getnetworkinfo command.
@@ -236,41 +477,67 @@ type GetNetworkInfoResult struct {
 
 // GetPeerInfoResult models the data returned from the getpeerinfo command.
 type GetPeerInfoResult struct {
-	ID             int32   `json:"id"`
-	Addr           string  `json:"addr"`
-	AddrLocal      string  `json:"addrlocal,omitempty"`
-	Services       string  `json:"services"`
-	RelayTxes      bool    `json:"relaytxes"`
-	LastSend       int64   `json:"lastsend"`
-	LastRecv       int64   `json:"lastrecv"`
-	BytesSent      uint64  `json:"bytessent"`
-	BytesRecv      uint64  `json:"bytesrecv"`
-	ConnTime       int64   `json:"conntime"`
-	TimeOffset     int64   `json:"timeoffset"`
-	PingTime       float64 `json:"pingtime"`
-	PingWait       float64 `json:"pingwait,omitempty"`
-	Version        uint32  `json:"version"`
-	SubVer         string  `json:"subver"`
-	Inbound        bool    `json:"inbound"`
-	StartingHeight int32   `json:"startingheight"`
-	CurrentHeight  int32   `json:"currentheight,omitempty"`
-	BanScore       int32   `json:"banscore"`
-	FeeFilter      int64   `json:"feefilter"`
-	SyncNode       bool    `json:"syncnode"`
+	ID              int32             `json:"id"`
+	Addr            string            `json:"addr"`
+	AddrLocal       string            `json:"addrlocal,omitempty"`
+	Services        string            `json:"services"`
+	RelayTxes       bool              `json:"relaytxes"`
+	LastSend        int64             `json:"lastsend"`
+	LastRecv        int64             `json:"lastrecv"`
+	BytesSent       uint64            `json:"bytessent"`
+	BytesRecv       uint64            `json:"bytesrecv"`
+	BytesSentPerMsg map[string]uint64 `json:"bytessent_per_msg"`
+	BytesRecvPerMsg map[string]uint64 `json:"bytesrecv_per_msg"`
+	ConnTime        int64             `json:"conntime"`
+	TimeOffset      int64             `json:"timeoffset"`
+	PingTime        float64           `json:"pingtime"`
+	MinPing         float64           `json:"minping"`
+	PingWait        float64           `json:"pingwait,omitempty"`
+	Version         uint32            `json:"version"`
+	SubVer          string            `json:"subver"`
+	Inbound         bool              `json:"inbound"`
+	StartingHeight  int32             `json:"startingheight"`
+	CurrentHeight   int32             `json:"currentheight,omitempty"`
+	BanScore        int32             `json:"banscore"`
+	FeeFilter       int64             `json:"feefilter"`
+	SyncNode        bool              `json:"syncnode"`
+}
+
+// MempoolFees models the fees of a memory pool transaction, and of its
+// ancestors and descendants in the memory pool, returned from the
+// getrawmempool command.
+type MempoolFees struct {
+	Base       float64 `json:"base"`
+	Modified   float64 `json:"modified"`
+	Ancestor   float64 `json:"ancestor"`
+	Descendant float64 `json:"descendant"`
 }
 
 // GetRawMempoolVerboseResult models the data returned from the getrawmempool
 // command when the verbose flag is set.  When the verbose flag is not set,
 // getrawmempool returns an array of transaction hashes.
 type GetRawMempoolVerboseResult struct {
-	Size             int32    `json:"size"`
-	Vsize            int32    `json:"vsize"`
-	Fee              float64  `json:"fee"`
-	Time             int64    `json:"time"`
-	Height           int64    `json:"height"`
-	StartingPriority float64  `json:"startingpriority"`
-	CurrentPriority  float64  `json:"currentpriority"`
-	Depends          []string `json:"depends"`
+	Size              int32       `json:"size"`
+	Vsize             int32       `json:"vsize"`
+	Fee               float64     `json:"fee"`
+	Time              int64       `json:"time"`
+	Height            int64       `json:"height"`
+	StartingPriority  float64     `json:"startingpriority"`
+	CurrentPriority   float64     `json:"currentpriority"`
+	Depends           []string    `json:"depends"`
+	Wtxid             string      `json:"wtxid"`
+	Fees              MempoolFees `json:"fees"`
+	BIP125Replaceable string      `json:"bip125-replaceable"`
+	Unbroadcast       bool        `json:"unbroadcast"`
+}
+
+// GetRawMempoolSequenceResult models the data returned from the getrawmempool
+// command when the mempool_sequence flag is set.  The mempool sequence is
+// incremented each time a transaction is added to or removed from the memory
+// pool, so clients can detect changes they missed.
+type GetRawMempoolSequenceResult struct {
+	TxIDs           []string `json:"txids"`
+	MempoolSequence uint64   `json:"mempool_sequence"`
 }
 
 // ScriptPubKeyResult models the scriptPubKey data of a tx script.  It is
@@ -293,6 +560,57 @@ type GetTxOutResult struct {
 	Coinbase      bool               `json:"coinbase"`
 }
 
+// GetTxOutSetInfoResult models the data returned from the gettxoutsetinfo
+// command.  The hash of the unspent transaction output set is only set when it
+// was requested.
+type GetTxOutSetInfoResult struct {
+	Height             int64   `json:"height"`
+	BestBlock          string  `json:"bestblock"`
+	Transactions       int64   `json:"transactions"`
+	TxOuts             int64   `json:"txouts"`
+	HashSerialized     string  `json:"hash_serialized,omitempty"`
+	TotalAmount        float64 `json:"total_amount"`
+	TotalAmountLitoshi int64   `json:"total_amount_litoshi"`
+}
+
+// DumpTxOutSetResult models the data returned from the dumptxoutset command.
+type DumpTxOutSetResult struct {
+	CoinsWritten uint64 `json:"coins_written"`
+	BaseHash     string `json:"base_hash"`
+	BaseHeight   int32  `json:"base_height"`
+	Path         string `json:"path"`
+	TxOutSetHash string `json:"txoutset_hash"`
+}
+
+// LoadTxOutSetResult models the data returned from the loadtxoutset command.
+type LoadTxOutSetResult struct {
+	CoinsLoaded uint64 `json:"coins_loaded"`
+	TipHash     string `json:"tip_hash"`
+	BaseHeight  int32  `json:"base_height"`
+	Path        string `json:"path"`
+}
+
+// GetAddrManInfoResult models the address counts of a network returned from
+// the getaddrmaninfo command.
+type GetAddrManInfoResult struct {
+	New   int `json:"new"`
+	Tried int `json:"tried"`
+	Total int `json:"total"`
+}
+
+// GetSyncStatusResult models the data returned from the getsyncstatus
+// command.  The download window is empty when its end is less than its start.
+type GetSyncStatusResult struct {
+	Phase                string  `json:"phase"`
+	Headers              int32   `json:"headers"`
+	Blocks               int32   `json:"blocks"`
+	SyncHeight           int32   `json:"syncheight"`
+	DownloadWindowStart  int32   `json:"downloadwindowstart"`
+	DownloadWindowEnd    int32   `json:"downloadwindowend"`
+	BlocksInFlight       int     `json:"blocksinflight"`
+	VerificationProgress float64 `json:"verificationprogress"`
+}
+
 // GetNetTotalsResult models the data returned from the getnettotals command.
 type GetNetTotalsResult struct {
 	TotalBytesRecv uint64 `json:"totalbytesrecv"`
@@ -300,6 +618,22 @@ type GetNetTotalsResult struct {
 	TimeMillis     int64  `json:"timemillis"`
 }
 
+// GetNodeAddressesResult models the data returned from the getnodeaddresses
+// command.
+type GetNodeAddressesResult struct {
+	Time     int64  `json:"time"`
+	Services uint64 `json:"services"`
+	Address  string `json:"address"`
+	Port     uint16 `json:"port"`
+}
+
+// ListBannedResult models the data returned from the listbanned command.
+type ListBannedResult struct {
+	Address     string `json:"address"`
+	BanCreated  int64  `json:"ban_created"`
+	BannedUntil int64  `json:"banned_until"`
+}
+
 // ScriptSig models a signature script.  It is defined separately since it only
 // applies to non-coinbase.  Therefore the field in the Vin structure needs
 // to be a pointer.
@@ -312,12 +646,14 @@ type ScriptSig struct {
 // getrawtransaction, decoderawtransaction, and searchrawtransaction use the
 // same structure.
 type Vin struct {
-	Coinbase  string     `json:"coinbase"`
-	Txid      string     `json:"txid"`
-	Vout      uint32     `json:"vout"`
-	ScriptSig *ScriptSig `json:"scriptSig"`
-	Sequence  uint32     `json:"sequence"`
-	Witness   []string   `json:"txinwitness"`
+	Coinbase           string         `json:"coinbase"`
+	Txid               string         `json:"txid"`
+	Vout               uint32         `json:"vout"`
+	ScriptSig          *ScriptSig     `json:"scriptSig"`
+	Sequence           uint32         `json:"sequence"`
+	Witness            []string       `json:"txinwitness"`
+	PrevOut            *PrevOutResult `json:"prevout"`
+	PrevOutUnavailable bool           `json:"prevoutunavailable"`
 }
 
 // IsCoinBase returns a bool to show if a Vin is a Coinbase one or not.
@@ -348,35 +684,52 @@ func (v *Vin) MarshalJSON() ([]byte, error) {
 
 	if v.HasWitness() {
 		txStruct := struct {
-			Txid      string     `json:"txid"`
-			Vout      uint32     `json:"vout"`
-			ScriptSig *ScriptSig `json:"scriptSig"`
-			Witness   []string   `json:"txinwitness"`
-			Sequence  uint32     `json:"sequence"`
+			Txid               string         `json:"txid"`
+			Vout               uint32         `json:"vout"`
+			ScriptSig          *ScriptSig     `json:"scriptSig"`
+			Witness            []string       `json:"txinwitness"`
+			PrevOut            *PrevOutResult `json:"prevout,omitempty"`
+			PrevOutUnavailable bool           `json:"prevoutunavailable,omitempty"`
+			Sequence           uint32         `json:"sequence"`
 		}{
-			Txid:      v.Txid,
-			Vout:      v.Vout,
-			ScriptSig: v.ScriptSig,
-			Witness:   v.Witness,
-			Sequence:  v.Sequence,
+			Txid:               v.Txid,
+			Vout:               v.Vout,
+			ScriptSig:          v.ScriptSig,
+			Witness:            v.Witness,
+			PrevOut:            v.PrevOut,
+			PrevOutUnavailable: v.PrevOutUnavailable,
+			Sequence:           v.Sequence,
 		}
 		return json.Marshal(txStruct)
 	}
 
 	txStruct := struct {
-		Txid      string     `json:"txid"`
-		Vout      uint32     `json:"vout"`
-		ScriptSig *ScriptSig `json:"scriptSig"`
-		Sequence  uint32     `json:"sequence"`
+		Txid               string         `json:"txid"`
+		Vout               uint32         `json:"vout"`
+		ScriptSig          *ScriptSig     `json:"scriptSig"`
+		PrevOut            *PrevOutResult `json:"prevout,omitempty"`
+		PrevOutUnavailable bool           `json:"prevoutunavailable,omitempty"`
+		Sequence           uint32         `json:"sequence"`
 	}{
-		Txid:      v.Txid,
-		Vout:      v.Vout,
-		ScriptSig: v.ScriptSig,
-		Sequence:  v.Sequence,
+		Txid:               v.Txid,
+		Vout:               v.Vout,
+		ScriptSig:          v.ScriptSig,
+		PrevOut:            v.PrevOut,
+		PrevOutUnavailable: v.PrevOutUnavailable,
+		Sequence:           v.Sequence,
 	}
 	return json.Marshal(txStruct)
 }
 
+// PrevOutResult models the output spent by a transaction input.  It is
+// returned by getblock and getrawtransaction for the inputs of each
+// transaction when the previous outputs are requested.
+type PrevOutResult struct {
+	Generated    bool               `json:"generated"`
+	Value        float64            `json:"value"`
+	ScriptPubKey ScriptPubKeyResult `json:"scriptPubKey"`
+}
+
 // PrevOut represents previous output for an input Vin.
 type PrevOut struct {
 	Addresses []string `json:"addresses,omitempty"`
@@ -464,6 +817,7 @@ type Vout struct {
 // GetMiningInfoResult models the data from the getmininginfo command.
 type GetMiningInfoResult struct {
 	Blocks             int64   `json:"blocks"`
+	Chain              string  `json:"chain"`
 	CurrentBlockSize   uint64  `json:"currentblocksize"`
 	CurrentBlockWeight uint64  `json:"currentblockweight"`
 	CurrentBlockTx     uint64  `json:"currentblocktx"`
@@ -472,9 +826,10 @@ type GetMiningInfoResult struct {
 	Generate           bool    `json:"generate"`
 	GenProcLimit       int32   `json:"genproclimit"`
 	HashesPerSec       int64   `json:"hashespersec"`
-	NetworkHashPS      int64   `json:"networkhashps"`
+	NetworkHashPS      float64 `json:"networkhashps"`
 	PooledTx           uint64  `json:"pooledtx"`
 	TestNet            bool    `json:"testnet"`
+	Warnings           string  `json:"warnings"`
 }
 
 // GetWorkResult models the data from the getwork command.
@@ -501,19 +856,48 @@ type InfoChainResult struct {
 
 // TxRawResult models the data from the getrawtransaction command.
 type TxRawResult struct {
-	Hex           string `json:"hex"`
-	Txid          string `json:"txid"`
-	Hash          string `json:"hash,omitempty"`
-	Size          int32  `json:"size,omitempty"`
-	Vsize         int32  `json:"vsize,omitempty"`
-	Version       int32  `json:"version"`
-	LockTime      uint32 `json:"locktime"`
-	Vin           []Vin  `json:"vin"`
-	Vout          []Vout `json:"vout"`
-	BlockHash     string `json:"blockhash,omitempty"`
-	Confirmations uint64 `json:"confirmations,omitempty"`
-	Time          int64  `json:"time,omitempty"`
-	Blocktime     int64  `json:"blocktime,omitempty"`
+	Hex           string   `json:"hex"`
+	Txid          string   `json:"txid"`
+	Hash          string   `json:"hash,omitempty"`
+	Size          int32    `json:"size,omitempty"`
+	Vsize         int32    `json:"vsize,omitempty"`
+	Version       int32    `json:"version"`
+	LockTime      uint32   `json:"locktime"`
+	Vin           []Vin    `json:"vin"`
+	Vout          []Vout   `json:"vout"`
+	Fee           *float64 `json:"fee,omitempty"`
+	BlockHash     string   `json:"blockhash,omitempty"`
+	Confirmations uint64   `json:"confirmations,omitempty"`
+	Time          int64    `json:"time,omitempty"`
+	Blocktime     int64    `json:"blocktime,omitempty"`
+}
+
+// ScanTxOutSetUnspent models an unspent transaction output found by the
+// scantxoutset command.
+type ScanTxOutSetUnspent struct {
+	TxID         string  `json:"txid"`
+	Vout         uint32  `json:"vout"`
+	ScriptPubKey string  `json:"scriptPubKey"`
+	Desc         string  `json:"desc"`
+	Amount       float64 `json:"amount"`
+	Height       int64   `json:"height"`
+}
+
+// ScanTxOutSetResult models the data returned from the scantxoutset command
+// when a scan completes.
+type ScanTxOutSetResult struct {
+	Success     bool                  `json:"success"`
+	TxOuts      int64                 `json:"txouts"`
+	Height      int64                 `json:"height"`
+	BestBlock   string                `json:"bestblock"`
+	Unspents    []ScanTxOutSetUnspent `json:"unspents"`
+	TotalAmount float64               `json:"total_amount"`
+}
+
+// ScanTxOutSetStatusResult models the data returned from the scantxoutset
+// command when the status of a scan in progress is requested.
+type ScanTxOutSetStatusResult struct {
+	Progress float64 `json:"progress"`
 }
 
 // SearchRawTransactionsResult models the data from the searchrawtransaction
@@ -534,6 +918,36 @@ type SearchRawTransactionsResult struct {
 	Blocktime     int64        `json:"blocktime,omitempty"`
 }
 
+// SubmitPackageTxResult models the data for each transaction of the package
+// returned from the submitpackage command.
+type SubmitPackageTxResult struct {
+	TxID  string  `json:"txid"`
+	VSize int64   `json:"vsize"`
+	Fee   float64 `json:"fee"`
+}
+
+// SubmitPackageResult models the data returned from the submitpackage command.
+type SubmitPackageResult struct {
+	TxResults      map[string]SubmitPackageTxResult `json:"tx-results"`
+	PackageFeeRate float64                          `json:"package-feerate"`
+}
+
+// TestMempoolAcceptFees models the fees of a transaction returned from the
+// testmempoolaccept command.
+type TestMempoolAcceptFees struct {
+	Base float64 `json:"base"`
+}
+
+// TestMempoolAcceptResult models the data for each transaction returned from
+// the testmempoolaccept command.
+type TestMempoolAcceptResult struct {
+	TxID         string                 `json:"txid"`
+	Allowed      bool                   `json:"allowed"`
+	RejectReason string                 `json:"reject-reason,omitempty"`
+	VSize        int64                  `json:"vsize,omitempty"`
+	Fees         *TestMempoolAcceptFees `json:"fees,omitempty"`
+}
+
 // TxRawDecodeResult models the data from the decoderawtransaction command.
 type TxRawDecodeResult struct {
 	Txid     string `json:"txid"`
@@ -544,8 +958,21 @@ type TxRawDecodeResult struct {
 }
 
 // ValidateAddressChainResult models the data returned by the chain server
-// validateaddress command.
+// validateaddress command.  Only the validity is set when the address is not
+// valid and the witness fields are only set for segwit addresses.
 type ValidateAddressChainResult struct {
-	IsValid bool   `json:"isvalid"`
-	Address string `json:"address,omitempty"`
+	IsValid        bool   `json:"isvalid"`
+	Address        string `json:"address,omitempty"`
+	ScriptPubKey   string `json:"scriptPubKey,omitempty"`
+	Type           string `json:"type,omitempty"`
+	IsWitness      *bool  `json:"iswitness,omitempty"`
+	WitnessVersion *int32 `json:"witness_version,omitempty"`
+	WitnessProgram string `json:"witness_program,omitempty"`
+}
+
+// WaitForBlockResult models the data returned by the waitforblock,
+// waitforblockheight, and waitfornewblock commands.
+type WaitForBlockResult struct {
+	Hash   string `json:"hash"`
+	Height int32  `json:"height"`
 }
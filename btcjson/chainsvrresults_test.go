@@ -43,6 +43,41 @@ func TestChainSvrCustomResults(t *testing.T) {
 			},
 			expected: `{"txid":"123","vout":1,"scriptSig":{"asm":"0","hex":"00"},"sequence":4294967295}`,
 		},
+		{
+			name: "custom vin marshal with prevout",
+			result: &btcjson.Vin{
+				Txid: "123",
+				Vout: 1,
+				ScriptSig: &btcjson.ScriptSig{
+					Asm: "0",
+					Hex: "00",
+				},
+				PrevOut: &btcjson.PrevOutResult{
+					Value: 1,
+					ScriptPubKey: btcjson.ScriptPubKeyResult{
+						Asm:  "1",
+						Hex:  "51",
+						Type: "nonstandard",
+					},
+				},
+				Sequence: 4294967295,
+			},
+			expected: `{"txid":"123","vout":1,"scriptSig":{"asm":"0","hex":"00"},"prevout":{"generated":false,"value":1,"scriptPubKey":{"asm":"1","hex":"51","type":"nonstandard"}},"sequence":4294967295}`,
+		},
+		{
+			name: "custom vin marshal with unavailable prevout",
+			result: &btcjson.Vin{
+				Txid: "123",
+				Vout: 1,
+				ScriptSig: &btcjson.ScriptSig{
+					Asm: "0",
+					Hex: "00",
+				},
+				PrevOutUnavailable: true,
+				Sequence:           4294967295,
+			},
+			expected: `{"txid":"123","vout":1,"scriptSig":{"asm":"0","hex":"00"},"prevoutunavailable":true,"sequence":4294967295}`,
+		},
 		{
 			name: "custom vinprevout marshal with coinbase",
 			result: &btcjson.VinPrevOut{
@@ -41,6 +41,15 @@ func NewStopNotifyBlocksCmd() *StopNotifyBlocksCmd {
 	return &StopNotifyBlocksCmd{}
 }
 
+// NotifyMempoolEvictedCmd defines the notifymempoolevicted JSON-RPC command.
+type NotifyMempoolEvictedCmd struct{}
+
+// NewNotifyMempoolEvictedCmd returns a new instance which can be used to issue
+// a notifymempoolevicted JSON-RPC command.
+func NewNotifyMempoolEvictedCmd() *NotifyMempoolEvictedCmd {
+	return &NotifyMempoolEvictedCmd{}
+}
+
 // NotifyNewTransactionsCmd defines the notifynewtransactions JSON-RPC command.
 type NotifyNewTransactionsCmd struct {
 	Verbose *bool `jsonrpcdefault:"false"`
@@ -66,6 +75,16 @@ func NewSessionCmd() *SessionCmd {
 	return &SessionCmd{}
 }
 
+// StopNotifyMempoolEvictedCmd defines the stopnotifymempoolevicted JSON-RPC
+// command.
+type StopNotifyMempoolEvictedCmd struct{}
+
+// NewStopNotifyMempoolEvictedCmd returns a new instance which can be used to
+// issue a stopnotifymempoolevicted JSON-RPC command.
+func NewStopNotifyMempoolEvictedCmd() *StopNotifyMempoolEvictedCmd {
+	return &StopNotifyMempoolEvictedCmd{}
+}
+
 // StopNotifyNewTransactionsCmd defines the stopnotifynewtransactions JSON-RPC command.
 type StopNotifyNewTransactionsCmd struct{}
 
@@ -228,11 +247,13 @@ func init() {
 	MustRegisterCmd("authenticate", (*AuthenticateCmd)(nil), flags)
 	MustRegisterCmd("loadtxfilter", (*LoadTxFilterCmd)(nil), flags)
 	MustRegisterCmd("notifyblocks", (*NotifyBlocksCmd)(nil), flags)
+	MustRegisterCmd("notifymempoolevicted", (*NotifyMempoolEvictedCmd)(nil), flags)
 	MustRegisterCmd("notifynewtransactions", (*NotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("notifyreceived", (*NotifyReceivedCmd)(nil), flags)
 	MustRegisterCmd("notifyspent", (*NotifySpentCmd)(nil), flags)
 	MustRegisterCmd("session", (*SessionCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyblocks", (*StopNotifyBlocksCmd)(nil), flags)
+	MustRegisterCmd("stopnotifymempoolevicted", (*StopNotifyMempoolEvictedCmd)(nil), flags)
 	MustRegisterCmd("stopnotifynewtransactions", (*StopNotifyNewTransactionsCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyspent", (*StopNotifySpentCmd)(nil), flags)
 	MustRegisterCmd("stopnotifyreceived", (*StopNotifyReceivedCmd)(nil), flags)
@@ -63,6 +63,28 @@ func TestChainSvrWsCmds(t *testing.T) {
 			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifyblocks","params":[],"id":1}`,
 			unmarshalled: &btcjson.StopNotifyBlocksCmd{},
 		},
+		{
+			name: "notifymempoolevicted",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("notifymempoolevicted")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewNotifyMempoolEvictedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"notifymempoolevicted","params":[],"id":1}`,
+			unmarshalled: &btcjson.NotifyMempoolEvictedCmd{},
+		},
+		{
+			name: "stopnotifymempoolevicted",
+			newCmd: func() (interface{}, error) {
+				return btcjson.NewCmd("stopnotifymempoolevicted")
+			},
+			staticCmd: func() interface{} {
+				return btcjson.NewStopNotifyMempoolEvictedCmd()
+			},
+			marshalled:   `{"jsonrpc":"1.0","method":"stopnotifymempoolevicted","params":[],"id":1}`,
+			unmarshalled: &btcjson.StopNotifyMempoolEvictedCmd{},
+		},
 		{
 			name: "notifynewtransactions",
 			newCmd: func() (interface{}, error) {
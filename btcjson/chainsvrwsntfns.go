@@ -31,6 +31,11 @@ const (
 	// disconnected.
 	FilteredBlockDisconnectedNtfnMethod = "filteredblockdisconnected"
 
+	// MempoolEvictedNtfnMethod is the method used for notifications from
+	// the chain server that a transaction has been removed from the
+	// mempool.
+	MempoolEvictedNtfnMethod = "mempoolevicted"
+
 	// RecvTxNtfnMethod is the legacy, deprecated method used for
 	// notifications from the chain server that a transaction which pays to
 	// a registered address has been processed.
@@ -161,6 +166,57 @@ type BlockDetails struct {
 	Time   int64  `json:"time"`
 }
 
+// Reasons a transaction may be removed from the mempool as reported by the
+// mempoolevicted JSON-RPC notification.
+const (
+	// MempoolEvictedConfirmed indicates the transaction was included in a
+	// block connected to the main chain.
+	MempoolEvictedConfirmed = "confirmed"
+
+	// MempoolEvictedReplaced indicates the transaction was replaced by a
+	// transaction paying a higher fee, or spent an output of such a
+	// replaced transaction.
+	MempoolEvictedReplaced = "replaced"
+
+	// MempoolEvictedConflict indicates the transaction double spent an
+	// output spent by a transaction in a block connected to the main
+	// chain, or spent an output of such a conflicting transaction.
+	MempoolEvictedConflict = "conflict"
+
+	// MempoolEvictedInvalid indicates the transaction was no longer valid,
+	// such as after the block containing it was disconnected from the main
+	// chain.
+	MempoolEvictedInvalid = "invalid"
+
+	// MempoolEvictedOrphanTimeout indicates the orphan transaction expired
+	// before its missing parents were received.
+	MempoolEvictedOrphanTimeout = "orphan_timeout"
+
+	// MempoolEvictedSizeLimit indicates the transaction was evicted
+	// because it paid too low a fee rate, or spent an output of such an
+	// evicted transaction, when the mempool exceeded its maximum size.
+	MempoolEvictedSizeLimit = "size_limit"
+
+	// MempoolEvictedExpired indicates the transaction, or a transaction it
+	// spent an output of, stayed in the mempool for longer than allowed.
+	MempoolEvictedExpired = "expired"
+)
+
+// MempoolEvictedNtfn defines the mempoolevicted JSON-RPC notification.
+type MempoolEvictedNtfn struct {
+	TxID   string
+	Reason string
+}
+
+// NewMempoolEvictedNtfn returns a new instance which can be used to issue a
+// mempoolevicted JSON-RPC notification.
+func NewMempoolEvictedNtfn(txHash string, reason string) *MempoolEvictedNtfn {
+	return &MempoolEvictedNtfn{
+		TxID:   txHash,
+		Reason: reason,
+	}
+}
+
 // RecvTxNtfn defines the recvtx JSON-RPC notification.
 //
 // NOTE: Deprecated. Use RelevantTxAcceptedNtfn and FilteredBlockConnectedNtfn
@@ -294,6 +350,7 @@ func init() {
 	MustRegisterCmd(BlockDisconnectedNtfnMethod, (*BlockDisconnectedNtfn)(nil), flags)
 	MustRegisterCmd(FilteredBlockConnectedNtfnMethod, (*FilteredBlockConnectedNtfn)(nil), flags)
 	MustRegisterCmd(FilteredBlockDisconnectedNtfnMethod, (*FilteredBlockDisconnectedNtfn)(nil), flags)
+	MustRegisterCmd(MempoolEvictedNtfnMethod, (*MempoolEvictedNtfn)(nil), flags)
 	MustRegisterCmd(RecvTxNtfnMethod, (*RecvTxNtfn)(nil), flags)
 	MustRegisterCmd(RedeemingTxNtfnMethod, (*RedeemingTxNtfn)(nil), flags)
 	MustRegisterCmd(RescanFinishedNtfnMethod, (*RescanFinishedNtfn)(nil), flags)
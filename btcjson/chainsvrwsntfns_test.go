@@ -88,6 +88,20 @@ func TestChainSvrWsNtfns(t *testing.T) {
 				Header: "header",
 			},
 		},
+		{
+			name: "mempoolevicted",
+			newNtfn: func() (interface{}, error) {
+				return btcjson.NewCmd("mempoolevicted", "123", "replaced")
+			},
+			staticNtfn: func() interface{} {
+				return btcjson.NewMempoolEvictedNtfn("123", btcjson.MempoolEvictedReplaced)
+			},
+			marshalled: `{"jsonrpc":"1.0","method":"mempoolevicted","params":["123","replaced"],"id":null}`,
+			unmarshalled: &btcjson.MempoolEvictedNtfn{
+				TxID:   "123",
+				Reason: "replaced",
+			},
+		},
 		{
 			name: "recvtx",
 			newNtfn: func() (interface{}, error) {
@@ -75,3 +75,21 @@ func String(v string) *string {
 	*p = v
 	return p
 }
+
+// VerbosityLevel is a helper routine that allocates a new Verbosity value to
+// store v and returns a pointer to it.  This is useful when assigning optional
+// parameters.
+func VerbosityLevel(v int) *Verbosity {
+	p := new(Verbosity)
+	*p = Verbosity(v)
+	return p
+}
+
+// FeeRateLimit is a helper routine that allocates a new MaxFeeRate value to
+// store v and returns a pointer to it.  This is useful when assigning optional
+// parameters.
+func FeeRateLimit(v float64) *MaxFeeRate {
+	p := new(MaxFeeRate)
+	*p = MaxFeeRate(v)
+	return p
+}
@@ -101,6 +101,16 @@ func TestHelpers(t *testing.T) {
 				return &val
 			}(),
 		},
+		{
+			name: "verbosity",
+			f: func() interface{} {
+				return btcjson.VerbosityLevel(2)
+			},
+			expected: func() interface{} {
+				val := btcjson.Verbosity(2)
+				return &val
+			}(),
+		},
 	}
 
 	t.Logf("Running %d tests", len(tests))
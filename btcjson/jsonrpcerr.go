@@ -45,7 +45,10 @@ const (
 const (
 	ErrRPCClientNotConnected      RPCErrorCode = -9
 	ErrRPCClientInInitialDownload RPCErrorCode = -10
+	ErrRPCClientNodeAlreadyAdded  RPCErrorCode = -23
 	ErrRPCClientNodeNotAdded      RPCErrorCode = -24
+	ErrRPCClientNodeNotConnected  RPCErrorCode = -29
+	ErrRPCClientInvalidIPOrSubnet RPCErrorCode = -30
 )
 
 // Wallet JSON errors
@@ -66,6 +69,7 @@ const (
 // more general errors above.
 const (
 	ErrRPCBlockNotFound     RPCErrorCode = -5
+	ErrRPCBlockPruned       RPCErrorCode = -1
 	ErrRPCBlockCount        RPCErrorCode = -5
 	ErrRPCBestBlockHash     RPCErrorCode = -5
 	ErrRPCDifficulty        RPCErrorCode = -5
@@ -79,6 +83,7 @@ const (
 
 // Errors that are specific to ltcd.
 const (
-	ErrRPCNoWallet      RPCErrorCode = -1
-	ErrRPCUnimplemented RPCErrorCode = -1
+	ErrRPCNoWallet           RPCErrorCode = -1
+	ErrRPCUnimplemented      RPCErrorCode = -1
+	ErrRPCMethodNotPermitted RPCErrorCode = -37
 )
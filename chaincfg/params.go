@@ -124,6 +124,11 @@ type Params struct {
 	// as one method to discover peers.
 	DNSSeeds []DNSSeed
 
+	// FixedSeeds defines a list of host:port addresses of peers for the
+	// network which are used to discover peers when none of the DNS seeds
+	// return any addresses.  The host must be a literal IP address.
+	FixedSeeds []string
+
 	// GenesisBlock defines the first block of the chain.
 	GenesisBlock *wire.MsgBlock
 
@@ -144,6 +149,12 @@ type Params struct {
 	BIP0065Height int32
 	BIP0066Height int32
 
+	// CheckTemplateVerifyHeight is the block height at which
+	// OP_CHECKTEMPLATEVERIFY as defined by BIP0119 is enforced.  It is
+	// only enforced on networks which opt in to it by setting a non-zero
+	// height.
+	CheckTemplateVerifyHeight int32
+
 	// CoinbaseMaturity is the number of blocks required before newly mined
 	// coins (coinbase transactions) can be spent.
 	CoinbaseMaturity uint16
@@ -185,6 +196,19 @@ type Params struct {
 	// Checkpoints ordered from oldest to newest.
 	Checkpoints []Checkpoint
 
+	// AssumeValid is the block the scripts of which, along with those of
+	// all of its ancestors, are assumed to be valid and are therefore not
+	// verified when the block is connected to the main chain.  A nil hash
+	// disables the optimization.
+	AssumeValid Checkpoint
+
+	// TxRate is the estimated number of transactions per second on the
+	// network.  It is used to estimate how many transactions the chain
+	// has gained since the best known block when reporting the progress
+	// of the verification of the chain.  Zero considers the best known
+	// block to be the tip of the chain.
+	TxRate float64
+
 	// These fields are related to voting on consensus rule changes as
 	// defined by BIP0009.
 	//
@@ -274,6 +298,8 @@ var MainNetParams = Params{
 		{638902, newHashFromStr("15238656e8ec63d28de29a8c75fcf3a5819afc953dcd9cc45cecc53baec74f38")},
 		{721000, newHashFromStr("198a7b4de1df9478e2463bd99d75b714eab235a2e63e741641dc8a759a9840e5")},
 	},
+	AssumeValid: Checkpoint{721000, newHashFromStr("198a7b4de1df9478e2463bd99d75b714eab235a2e63e741641dc8a759a9840e5")},
+	TxRate:      0.06,
 
 	// Consensus rule change deployments.
 	//
@@ -434,6 +460,8 @@ var TestNet4Params = Params{
 		{99949, newHashFromStr("8dd471cb5aecf5ead91e7e4b1e932c79a0763060f8d93671b6801d115bfc6cde")},
 		{159256, newHashFromStr("ab5b0b9968842f5414804591119d6db829af606864b1959a25d6f5c114afb2b7")},
 	},
+	AssumeValid: Checkpoint{159256, newHashFromStr("ab5b0b9968842f5414804591119d6db829af606864b1959a25d6f5c114afb2b7")},
+	TxRate:      0.01,
 
 	// Consensus rule change deployments.
 	//
@@ -100,6 +100,13 @@ func realMain() error {
 		return err
 	}
 
+	// Stop catching up the indexes before the database is closed.  Any
+	// indexes which are not caught up yet resume catching up when the
+	// database is next loaded.
+	if importer.indexManager != nil {
+		defer importer.indexManager.Stop()
+	}
+
 	// Perform the import asynchronously.  This allows blocks to be
 	// processed and read in parallel.  The results channel returned from
 	// Import contains the statistics about the import including an error
@@ -0,0 +1,92 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinselect
+
+import "github.com/ltcsuite/ltcutil"
+
+// maxBnBTries is the maximum number of steps of the branch and bound search.
+// It bounds the search for large sets of candidates, in which case the best
+// selection found within the limit is returned.
+const maxBnBTries = 100000
+
+// selectBnB returns the indexes of the candidates, which must be sorted by
+// descending effective value, with the smallest total effective value in the
+// range [target, target+costOfChange], or nil when there is no such subset.
+//
+// The search explores a binary tree in which each level decides whether the
+// candidate at that depth is included, depth first and with inclusion
+// explored first.  A branch is cut as soon as it exceeds the range or can no
+// longer reach the target with the remaining candidates.  Excluding a
+// candidate with the same effective value as a previously excluded one is
+// equivalent to excluding that one, so such branches are skipped as well.
+func selectBnB(candidates []candidate, target, costOfChange ltcutil.Amount) []int {
+	var available ltcutil.Amount
+	for _, c := range candidates {
+		available += c.effValue
+	}
+	if available < target {
+		return nil
+	}
+
+	var value ltcutil.Amount
+	var best []int
+	var bestWaste ltcutil.Amount
+	included := make([]bool, 0, len(candidates))
+	for tries := 0; tries < maxBnBTries; tries++ {
+		backtrack := false
+		switch {
+		case value+available < target || value > target+costOfChange:
+			backtrack = true
+
+		case value >= target:
+			// The waste of a selection without change is its
+			// excess, so stop at an exact match.
+			waste := value - target
+			if best == nil || waste < bestWaste {
+				best = best[:0]
+				for i, in := range included {
+					if in {
+						best = append(best, i)
+					}
+				}
+				bestWaste = waste
+				if bestWaste == 0 {
+					return best
+				}
+			}
+			backtrack = true
+		}
+
+		if backtrack {
+			// Walk back to the last included candidate, returning
+			// the excluded ones to the available value, and
+			// explore the branch excluding it instead.
+			for len(included) > 0 && !included[len(included)-1] {
+				included = included[:len(included)-1]
+				available += candidates[len(included)].effValue
+			}
+			if len(included) == 0 {
+				break
+			}
+			included[len(included)-1] = false
+			value -= candidates[len(included)-1].effValue
+			continue
+		}
+
+		depth := len(included)
+		c := candidates[depth]
+		available -= c.effValue
+		if depth > 0 && !included[depth-1] &&
+			c.effValue == candidates[depth-1].effValue {
+
+			included = append(included, false)
+		} else {
+			included = append(included, true)
+			value += c.effValue
+		}
+	}
+
+	return best
+}
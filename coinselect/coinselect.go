@@ -0,0 +1,210 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinselect
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// ErrInsufficientFunds is returned when the candidate coins are not worth
+// enough to fund the target amount along with the fees of spending them.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Algorithm identifies the algorithm which selected the coins.
+type Algorithm int
+
+const (
+	// BranchAndBound is the branch and bound search for a selection which
+	// does not need a change output.
+	BranchAndBound Algorithm = iota
+
+	// Knapsack is the fallback which selects coins with a change output
+	// whenever the change is worth creating.
+	Knapsack
+)
+
+// algorithmStrings is a map of algorithms back to their names for pretty
+// printing.
+var algorithmStrings = map[Algorithm]string{
+	BranchAndBound: "BranchAndBound",
+	Knapsack:       "Knapsack",
+}
+
+// String returns the Algorithm in human-readable form.
+func (a Algorithm) String() string {
+	if s, ok := algorithmStrings[a]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown Algorithm (%d)", int(a))
+}
+
+// Coin houses a candidate output for the selection along with the size, in
+// virtual bytes, the input spending it adds to the transaction.
+type Coin struct {
+	OutPoint  wire.OutPoint
+	Amount    ltcutil.Amount
+	InputSize int
+}
+
+// Config houses the parameters of a selection.  Sizes are in virtual bytes
+// and the fee rate is in satoshi per kilobyte, so the fee of a part of the
+// transaction is its size multiplied by the fee rate divided by 1000.
+type Config struct {
+	// Target is the amount paid to the outputs of the transaction other
+	// than the change output.
+	Target ltcutil.Amount
+
+	// FeeRate is the fee rate the transaction pays.
+	FeeRate ltcutil.Amount
+
+	// BaseSize is the size of the transaction without any inputs and
+	// without the change output.
+	BaseSize int
+
+	// ChangeOutputSize is the size of the change output.
+	ChangeOutputSize int
+
+	// ChangeSpendSize is the size of the input which later spends the
+	// change output.  Along with the change output itself, it makes up
+	// the cost of creating change.
+	ChangeSpendSize int
+
+	// MinChange is the smallest amount worth creating a change output
+	// for.  Smaller amounts are added to the fee instead.
+	MinChange ltcutil.Amount
+}
+
+// Selection houses the coins selected to fund a transaction.  Waste is the
+// amount lost to the selection beyond the fees needed to fund the target:
+// the excess added to the fee when there is no change output, or the cost of
+// creating change otherwise.
+type Selection struct {
+	Coins     []Coin
+	Fee       ltcutil.Amount
+	HasChange bool
+	Change    ltcutil.Amount
+	Waste     ltcutil.Amount
+	Algorithm Algorithm
+}
+
+// fee returns the fee of the passed size at the passed fee rate.
+func fee(feeRate ltcutil.Amount, size int) ltcutil.Amount {
+	return feeRate * ltcutil.Amount(size) / 1000
+}
+
+// candidate houses a coin along with its effective value, which is its amount
+// minus the fee of the input spending it.
+type candidate struct {
+	coin     *Coin
+	effValue ltcutil.Amount
+	inputFee ltcutil.Amount
+}
+
+// byEffValue sorts candidates by descending effective value.  It is used
+// with a stable sort, so candidates of equal value keep the order in which
+// the coins were supplied.
+type byEffValue []candidate
+
+func (s byEffValue) Len() int           { return len(s) }
+func (s byEffValue) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s byEffValue) Less(i, j int) bool { return s[i].effValue > s[j].effValue }
+
+// Select returns the subset of the passed coins which funds the target amount
+// of the passed configuration while wasting the least.
+//
+// The selection first runs a branch and bound search for a subset whose
+// effective value covers the target and the fees without needing a change
+// output, and exceeds them by no more than the cost of creating change.  Of
+// those, the subset with the smallest excess is selected and the excess is
+// added to the fee.  When there is no such subset, the knapsack fallback
+// selects a subset which leaves a change output of at least MinChange when
+// possible.
+//
+// Coins which cost more to spend than they are worth at the fee rate are never
+// selected.  The selection is deterministic: the same coins and configuration
+// always result in the same selection.  ErrInsufficientFunds is returned when
+// the coins are not worth enough to fund the target.
+func Select(coins []Coin, cfg *Config) (*Selection, error) {
+	if cfg.Target <= 0 {
+		return nil, errors.New("target amount must be positive")
+	}
+	if cfg.FeeRate < 0 || cfg.MinChange < 0 {
+		return nil, errors.New("fee rate and minimum change must not " +
+			"be negative")
+	}
+
+	candidates := make([]candidate, 0, len(coins))
+	for i := range coins {
+		coin := &coins[i]
+		inputFee := fee(cfg.FeeRate, coin.InputSize)
+		if coin.Amount-inputFee <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			coin:     coin,
+			effValue: coin.Amount - inputFee,
+			inputFee: inputFee,
+		})
+	}
+	sort.Stable(byEffValue(candidates))
+
+	baseFee := fee(cfg.FeeRate, cfg.BaseSize)
+	changeOutputFee := fee(cfg.FeeRate, cfg.ChangeOutputSize)
+	costOfChange := changeOutputFee + fee(cfg.FeeRate, cfg.ChangeSpendSize)
+	target := cfg.Target + baseFee
+
+	if selected := selectBnB(candidates, target, costOfChange); selected != nil {
+		s, effValue, inputFees := newSelection(candidates, selected,
+			BranchAndBound)
+		s.Waste = effValue - target
+		s.Fee = baseFee + inputFees + s.Waste
+		return s, nil
+	}
+
+	// The fallback funds the change output along with the target and aims
+	// to leave at least the minimum change.  When the change falls short
+	// of it, the change output is dropped and the excess added to the fee.
+	target += changeOutputFee
+	selected := selectKnapsack(candidates, target, cfg.MinChange)
+	if selected == nil {
+		return nil, ErrInsufficientFunds
+	}
+	s, effValue, inputFees := newSelection(candidates, selected, Knapsack)
+	change := effValue - target
+	if change > 0 && change >= cfg.MinChange {
+		s.HasChange = true
+		s.Change = change
+		s.Waste = costOfChange
+		s.Fee = baseFee + inputFees + changeOutputFee
+	} else {
+		s.Waste = change + changeOutputFee
+		s.Fee = baseFee + inputFees + s.Waste
+	}
+	return s, nil
+}
+
+// newSelection returns a selection of the candidates at the passed indexes
+// along with their total effective value and the total fees of the inputs
+// spending them.
+func newSelection(candidates []candidate, selected []int,
+	algorithm Algorithm) (*Selection, ltcutil.Amount, ltcutil.Amount) {
+
+	s := &Selection{
+		Coins:     make([]Coin, 0, len(selected)),
+		Algorithm: algorithm,
+	}
+	var effValue, inputFees ltcutil.Amount
+	for _, i := range selected {
+		s.Coins = append(s.Coins, *candidates[i].coin)
+		effValue += candidates[i].effValue
+		inputFees += candidates[i].inputFee
+	}
+	return s, effValue, inputFees
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinselect
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// TestSelect ensures the coins funding a target are selected by the branch and
+// bound search when a selection without change exists and by the knapsack
+// fallback otherwise, along with the expected fee and change.
+func TestSelect(t *testing.T) {
+	t.Parallel()
+
+	// At a fee rate of 1000 satoshi per kilobyte, spending each of the
+	// coins costs 100 satoshi, so their effective values are 1000000,
+	// 500000, 300000 and 200000.  The last coin costs more to spend than
+	// it is worth.
+	coin := func(index uint32, amount ltcutil.Amount) Coin {
+		return Coin{
+			OutPoint:  wire.OutPoint{Index: index},
+			Amount:    amount,
+			InputSize: 100,
+		}
+	}
+	coins := []Coin{
+		coin(0, 300100),
+		coin(1, 1000100),
+		coin(2, 200100),
+		coin(3, 500100),
+		coin(4, 50),
+	}
+	cfg := func(target, minChange ltcutil.Amount) *Config {
+		return &Config{
+			Target:           target,
+			FeeRate:          1000,
+			BaseSize:         10,
+			ChangeOutputSize: 31,
+			ChangeSpendSize:  68,
+			MinChange:        minChange,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		coins     []Coin
+		cfg       *Config
+		selected  []uint32
+		fee       ltcutil.Amount
+		hasChange bool
+		change    ltcutil.Amount
+		waste     ltcutil.Amount
+		algorithm Algorithm
+		err       error
+	}{
+		{
+			name:      "branch and bound exact match",
+			coins:     coins,
+			cfg:       cfg(700000-10, 1000),
+			selected:  []uint32{3, 2},
+			fee:       210,
+			waste:     0,
+			algorithm: BranchAndBound,
+		},
+		{
+			name:      "branch and bound excess below cost of change",
+			coins:     coins,
+			cfg:       cfg(700000-10-50, 1000),
+			selected:  []uint32{3, 2},
+			fee:       260,
+			waste:     50,
+			algorithm: BranchAndBound,
+		},
+		{
+			name:      "knapsack with change",
+			coins:     coins,
+			cfg:       cfg(1150000-10, 1000),
+			selected:  []uint32{1, 2},
+			fee:       241,
+			hasChange: true,
+			change:    1200000 - 1150031,
+			waste:     99,
+			algorithm: Knapsack,
+		},
+		{
+			name:      "knapsack change below minimum",
+			coins:     []Coin{coins[1], coins[2]},
+			cfg:       cfg(1150000-10, 100000),
+			selected:  []uint32{1, 2},
+			fee:       1200200 - (1150000 - 10),
+			waste:     1200000 - 1150000,
+			algorithm: Knapsack,
+		},
+		{
+			name:  "insufficient funds",
+			coins: coins,
+			cfg:   cfg(2000000, 1000),
+			err:   ErrInsufficientFunds,
+		},
+		{
+			name:  "uneconomical coin",
+			coins: coins[4:],
+			cfg:   cfg(10, 0),
+			err:   ErrInsufficientFunds,
+		},
+	}
+
+	for _, test := range tests {
+		s, err := Select(test.coins, test.cfg)
+		if err != test.err {
+			t.Errorf("%s: unexpected error - got %v, want %v",
+				test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		selected := make([]uint32, 0, len(s.Coins))
+		for _, c := range s.Coins {
+			selected = append(selected, c.OutPoint.Index)
+		}
+		if !reflect.DeepEqual(selected, test.selected) {
+			t.Errorf("%s: selected coins %v, want %v", test.name,
+				selected, test.selected)
+		}
+		if s.Fee != test.fee || s.HasChange != test.hasChange ||
+			s.Change != test.change || s.Waste != test.waste {
+
+			t.Errorf("%s: got fee %d, change %v (%d) and waste %d, "+
+				"want fee %d, change %v (%d) and waste %d",
+				test.name, s.Fee, s.HasChange, s.Change, s.Waste,
+				test.fee, test.hasChange, test.change,
+				test.waste)
+		}
+		if s.Algorithm != test.algorithm {
+			t.Errorf("%s: selected by %v, want %v", test.name,
+				s.Algorithm, test.algorithm)
+		}
+
+		// The selection must be deterministic.
+		again, err := Select(test.coins, test.cfg)
+		if err != nil || !reflect.DeepEqual(again, s) {
+			t.Errorf("%s: selection is not deterministic", test.name)
+		}
+	}
+}
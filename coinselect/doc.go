@@ -0,0 +1,30 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package coinselect implements deterministic selection of the coins which fund
+a transaction from a supplied set of candidate outputs.
+
+Coin Selection Overview
+
+The coins are selected based on their effective value, which is their amount
+minus the fee of the input spending them at the fee rate of the transaction.
+Coins which cost more to spend than they are worth are never selected.
+
+Select first runs a branch and bound search, as used by Bitcoin Core, for a
+subset of the coins which funds the target without a change output.  Since
+creating change costs the fee of the change output along with the fee of
+later spending it, a subset which exceeds the target by less than that cost
+is preferred over one with change, and the excess is added to the fee.  Of
+those subsets, the one which wastes the least is selected.
+
+When there is no such subset, a knapsack solver selects the coins instead,
+aiming to leave change of at least a configured minimum amount.  Its
+randomized passes use a fixed seed, so the selection only depends on the
+candidates and the configuration.
+
+The package has no access to the chain or a wallet.  It is up to the caller
+to supply spendable coins along with the size of the inputs spending them.
+*/
+package coinselect
@@ -0,0 +1,147 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package coinselect
+
+import (
+	"math/rand"
+
+	"github.com/ltcsuite/ltcutil"
+)
+
+const (
+	// knapsackIterations is the number of randomized passes used to
+	// approximate the best subset of the candidates.
+	knapsackIterations = 1000
+
+	// knapsackSeed seeds the pseudorandom passes so the fallback always
+	// selects the same coins for the same input.
+	knapsackSeed = 1
+)
+
+// approximateBestSubset returns which of the passed candidates to include in
+// the subset with the smallest total effective value of at least the target,
+// along with that total.  The subset is approximated by randomized passes
+// which include each candidate with a probability of one half and, when that
+// falls short of the target, add the remaining candidates in order.  The
+// passed total is the total effective value of all of the candidates.
+func approximateBestSubset(rng *rand.Rand, candidates []candidate,
+	total, target ltcutil.Amount) ([]bool, ltcutil.Amount) {
+
+	best := make([]bool, len(candidates))
+	for i := range best {
+		best[i] = true
+	}
+	bestValue := total
+
+	included := make([]bool, len(candidates))
+	for rep := 0; rep < knapsackIterations && bestValue != target; rep++ {
+		for i := range included {
+			included[i] = false
+		}
+		var value ltcutil.Amount
+		reachedTarget := false
+		for pass := 0; pass < 2 && !reachedTarget; pass++ {
+			for i, c := range candidates {
+				include := !included[i]
+				if pass == 0 {
+					include = rng.Intn(2) == 0
+				}
+				if !include {
+					continue
+				}
+
+				value += c.effValue
+				included[i] = true
+				if value < target {
+					continue
+				}
+
+				// Record the subset when it is the best one so
+				// far and remove the candidate again to look
+				// for a smaller subset.
+				reachedTarget = true
+				if value < bestValue {
+					bestValue = value
+					copy(best, included)
+				}
+				value -= c.effValue
+				included[i] = false
+			}
+		}
+	}
+	return best, bestValue
+}
+
+// selectKnapsack returns the indexes of the candidates, which must be sorted
+// by descending effective value, which fund the target while leaving at least
+// the passed minimum change when possible, or nil when the candidates are not
+// worth enough to fund the target.
+//
+// A candidate worth exactly the target is selected on its own.  Otherwise,
+// the subset of the candidates worth less than the target plus the minimum
+// change which comes closest to the target, or to the target plus the minimum
+// change when the target cannot be hit exactly, is selected unless the
+// smallest candidate worth more than that is a better fit.
+func selectKnapsack(candidates []candidate, target, minChange ltcutil.Amount) []int {
+	var smaller []int
+	var smallerTotal ltcutil.Amount
+	lowestLarger := -1
+	for i, c := range candidates {
+		switch {
+		case c.effValue == target:
+			return []int{i}
+
+		case c.effValue < target+minChange:
+			smaller = append(smaller, i)
+			smallerTotal += c.effValue
+
+		default:
+			// The candidates are sorted by descending value, so
+			// the last larger candidate is the smallest one.
+			lowestLarger = i
+		}
+	}
+
+	switch {
+	case smallerTotal == target:
+		return smaller
+
+	case smallerTotal < target:
+		if lowestLarger == -1 {
+			return nil
+		}
+		return []int{lowestLarger}
+	}
+
+	smallerCandidates := make([]candidate, len(smaller))
+	for i, idx := range smaller {
+		smallerCandidates[i] = candidates[idx]
+	}
+	rng := rand.New(rand.NewSource(knapsackSeed))
+	best, bestValue := approximateBestSubset(rng, smallerCandidates,
+		smallerTotal, target)
+	if bestValue != target && smallerTotal >= target+minChange {
+		best, bestValue = approximateBestSubset(rng, smallerCandidates,
+			smallerTotal, target+minChange)
+	}
+
+	// Prefer the smallest larger candidate when the subset neither hits
+	// the target nor leaves the minimum change, or when the candidate is
+	// worth no more than the subset.
+	if lowestLarger != -1 &&
+		((bestValue != target && bestValue < target+minChange) ||
+			candidates[lowestLarger].effValue <= bestValue) {
+
+		return []int{lowestLarger}
+	}
+
+	selected := make([]int, 0, len(smaller))
+	for i, in := range best {
+		if in {
+			selected = append(selected, smaller[i])
+		}
+	}
+	return selected
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/aead/siphash"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	peerpkg "github.com/ltcsuite/ltcd/peer"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+const (
+	// maxHighBandwidthPeers is the maximum number of peers which are asked
+	// to announce new blocks by sending compact blocks directly as
+	// recommended by BIP0152.
+	maxHighBandwidthPeers = 3
+
+	// maxCmpctBlockDepth is the maximum depth of a block from the end of
+	// the main chain for it to be served as a compact block in response to
+	// a getdata request.  Older blocks are served in full.
+	maxCmpctBlockDepth = 5
+
+	// maxBlockTxnDepth is the maximum depth of a block from the end of the
+	// main chain for its transactions to be served in response to a
+	// getblocktxn request.  Older blocks are served in full.
+	maxBlockTxnDepth = 10
+
+	// shortIDMask is the mask used to truncate the siphash of a transaction
+	// to the size of a short id.
+	shortIDMask = 1<<(wire.ShortIDSize*8) - 1
+)
+
+// errShortIDCollision indicates a compact block contains multiple transactions
+// with the same short id which means it can't be reconstructed and the full
+// block needs to be requested instead.
+var errShortIDCollision = errors.New("compact block contains short id " +
+	"collisions")
+
+// shortIDKey returns the siphash key used to derive the short ids of the
+// transactions in a compact block with the provided header and nonce.  As
+// defined by BIP0152, it is the first 16 bytes of the single SHA256 of the
+// serialized header followed by the little-endian nonce.
+func shortIDKey(header *wire.BlockHeader, nonce uint64) [siphash.KeySize]byte {
+	var buf bytes.Buffer
+	buf.Grow(wire.MaxBlockHeaderPayload + 8)
+	_ = header.Serialize(&buf)
+	var nonceBytes [8]byte
+	binary.LittleEndian.PutUint64(nonceBytes[:], nonce)
+	buf.Write(nonceBytes[:])
+
+	var key [siphash.KeySize]byte
+	hash := sha256.Sum256(buf.Bytes())
+	copy(key[:], hash[:siphash.KeySize])
+	return key
+}
+
+// shortID returns the short id of the transaction with the provided hash using
+// the provided key as defined by BIP0152.
+func shortID(key *[siphash.KeySize]byte, txHash *chainhash.Hash) uint64 {
+	return siphash.Sum64(txHash[:], key) & shortIDMask
+}
+
+// cmpctBlockTxHash returns the hash used to identify the passed transaction in
+// compact blocks of the provided version.
+func cmpctBlockTxHash(tx *wire.MsgTx, version uint64) chainhash.Hash {
+	if version == wire.CmpctBlockWitnessVersion {
+		return tx.WitnessHash()
+	}
+	return tx.TxHash()
+}
+
+// newCmpctBlock returns a compact block of the provided version for the passed
+// block.  Only the coinbase transaction is prefilled since it is the only
+// transaction a peer can't possibly already know about.
+func newCmpctBlock(block *ltcutil.Block, version uint64, nonce uint64) (*wire.MsgCmpctBlock, error) {
+	msgBlock := block.MsgBlock()
+	msg := wire.NewMsgCmpctBlock(&msgBlock.Header, nonce)
+	key := shortIDKey(&msgBlock.Header, nonce)
+	for i, tx := range msgBlock.Transactions {
+		if i == 0 {
+			err := msg.AddPrefilledTx(0, tx)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		txHash := cmpctBlockTxHash(tx, version)
+		err := msg.AddShortID(shortID(&key, &txHash))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return msg, nil
+}
+
+// partialBlock houses a block which is being reconstructed from a compact
+// block.  Transactions which are not yet available are nil.
+type partialBlock struct {
+	header     wire.BlockHeader
+	hash       chainhash.Hash
+	txns       []*wire.MsgTx
+	numMissing int
+}
+
+// newPartialBlock reconstructs as much of the block described by the passed
+// compact block as possible from its prefilled transactions and the provided
+// transactions, which are typically the contents of the memory pool.
+//
+// errShortIDCollision is returned when the compact block contains duplicate
+// short ids, in which case the full block must be requested instead.
+func newPartialBlock(msg *wire.MsgCmpctBlock, version uint64, txns []*ltcutil.Tx) (*partialBlock, error) {
+	numTxns := msg.BlockTxCount()
+	if numTxns == 0 {
+		return nil, errors.New("compact block has no transactions")
+	}
+
+	pb := &partialBlock{
+		header:     msg.Header,
+		hash:       msg.Header.BlockHash(),
+		txns:       make([]*wire.MsgTx, numTxns),
+		numMissing: numTxns,
+	}
+
+	// Place the prefilled transactions at their absolute indexes.
+	for _, ptx := range msg.PrefilledTxns {
+		if int(ptx.Index) >= numTxns {
+			return nil, fmt.Errorf("prefilled transaction index %d "+
+				"is out of range for a block with %d "+
+				"transactions", ptx.Index, numTxns)
+		}
+		if pb.txns[ptx.Index] != nil {
+			return nil, fmt.Errorf("duplicate prefilled "+
+				"transaction index %d", ptx.Index)
+		}
+		if ptx.Tx == nil {
+			return nil, fmt.Errorf("prefilled transaction at index "+
+				"%d is missing", ptx.Index)
+		}
+		pb.txns[ptx.Index] = ptx.Tx
+		pb.numMissing--
+	}
+
+	// Map each short id to the index of the remaining transaction it
+	// identifies.  The short ids are listed in order of the indexes which
+	// are not occupied by prefilled transactions.
+	shortIDIndexes := make(map[uint64]int, len(msg.ShortIDs))
+	index := 0
+	for _, id := range msg.ShortIDs {
+		for pb.txns[index] != nil {
+			index++
+		}
+		if _, exists := shortIDIndexes[id]; exists {
+			return nil, errShortIDCollision
+		}
+		shortIDIndexes[id] = index
+		index++
+	}
+
+	// Fill in the transactions which match a short id.  Any short id that
+	// matches more than one of the provided transactions is ambiguous, so
+	// it is left missing in order to be requested from the peer.
+	key := shortIDKey(&msg.Header, msg.Nonce)
+	ambiguous := make(map[int]struct{})
+	for _, tx := range txns {
+		txHash := cmpctBlockTxHash(tx.MsgTx(), version)
+		index, ok := shortIDIndexes[shortID(&key, &txHash)]
+		if !ok {
+			continue
+		}
+		if _, ok := ambiguous[index]; ok {
+			continue
+		}
+		if pb.txns[index] != nil {
+			pb.txns[index] = nil
+			pb.numMissing++
+			ambiguous[index] = struct{}{}
+			continue
+		}
+		pb.txns[index] = tx.MsgTx()
+		pb.numMissing--
+	}
+
+	return pb, nil
+}
+
+// missingIndexes returns the indexes of the transactions which are still
+// needed to complete the block in increasing order.
+func (pb *partialBlock) missingIndexes() []uint32 {
+	indexes := make([]uint32, 0, pb.numMissing)
+	for i, tx := range pb.txns {
+		if tx == nil {
+			indexes = append(indexes, uint32(i))
+		}
+	}
+	return indexes
+}
+
+// fill completes the block with the passed transactions which must be the
+// missing transactions in the order of the indexes returned by missingIndexes.
+func (pb *partialBlock) fill(txns []*wire.MsgTx) error {
+	if len(txns) != pb.numMissing {
+		return fmt.Errorf("received %d transactions for block %v "+
+			"which is missing %d", len(txns), pb.hash,
+			pb.numMissing)
+	}
+
+	next := 0
+	for i, tx := range pb.txns {
+		if tx == nil {
+			pb.txns[i] = txns[next]
+			next++
+		}
+	}
+	pb.numMissing = 0
+	return nil
+}
+
+// block returns the reconstructed block.  It must only be called once no
+// transactions are missing.
+func (pb *partialBlock) block() *ltcutil.Block {
+	msgBlock := wire.NewMsgBlock(&pb.header)
+	msgBlock.Transactions = pb.txns
+	return ltcutil.NewBlock(msgBlock)
+}
+
+// selectHighBandwidthPeer returns the passed list of high-bandwidth compact
+// block peers updated to include the provided peer as the most recent one to
+// deliver a new block.  When the peer was not already in the list, it is added
+// and true is returned along with the least recent peer that was evicted to
+// keep the list within maxHighBandwidthPeers, if any.
+func selectHighBandwidthPeer(peers []*peerpkg.Peer, peer *peerpkg.Peer) ([]*peerpkg.Peer, *peerpkg.Peer, bool) {
+	for i, p := range peers {
+		if p == peer {
+			copy(peers[i:], peers[i+1:])
+			peers[len(peers)-1] = peer
+			return peers, nil, false
+		}
+	}
+
+	var evicted *peerpkg.Peer
+	if len(peers) >= maxHighBandwidthPeers {
+		evicted = peers[0]
+		peers = append(peers[:0], peers[1:]...)
+	}
+	return append(peers, peer), evicted, true
+}
@@ -0,0 +1,220 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	peerpkg "github.com/ltcsuite/ltcd/peer"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// TestShortID ensures transaction short ids are derived as defined by BIP0152.
+func TestShortID(t *testing.T) {
+	t.Parallel()
+
+	// The expected value was calculated independently from the single
+	// SHA256 of the serialized main network genesis block header followed
+	// by the nonce and the reference SipHash-2-4 algorithm.
+	genesis := chaincfg.MainNetParams.GenesisBlock
+	key := shortIDKey(&genesis.Header, 0x0102030405060708)
+	txHash := genesis.Transactions[0].TxHash()
+	if got, want := shortID(&key, &txHash), uint64(0xf3bc64ed0a28); got != want {
+		t.Fatalf("shortID: got %x, want %x", got, want)
+	}
+}
+
+// TestCmpctBlockReconstruction ensures a block can be reconstructed from a
+// compact block when the memory pool holds all but two of its transactions
+// and the missing transactions are provided separately.
+func TestCmpctBlockReconstruction(t *testing.T) {
+	t.Parallel()
+
+	// newTx returns a unique transaction for the provided index.
+	newTx := func(index uint32) *wire.MsgTx {
+		tx := wire.NewMsgTx(1)
+		prevOut := wire.NewOutPoint(&chainhash.Hash{0x01}, index)
+		tx.AddTxIn(wire.NewTxIn(prevOut, nil, [][]byte{{byte(index)}}))
+		tx.AddTxOut(wire.NewTxOut(int64(index), nil))
+		return tx
+	}
+
+	// Create a block with a coinbase and several other transactions.
+	genesis := chaincfg.RegressionNetParams.GenesisBlock
+	msgBlock := wire.NewMsgBlock(&genesis.Header)
+	msgBlock.AddTransaction(genesis.Transactions[0])
+	for i := uint32(0); i < 8; i++ {
+		msgBlock.AddTransaction(newTx(i))
+	}
+	merkles := blockchain.BuildMerkleTreeStore(
+		ltcutil.NewBlock(msgBlock).Transactions(), false)
+	msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
+	block := ltcutil.NewBlock(msgBlock)
+
+	for _, version := range []uint64{wire.CmpctBlockVersion,
+		wire.CmpctBlockWitnessVersion} {
+
+		cmpctBlock, err := newCmpctBlock(block, version, 12345)
+		if err != nil {
+			t.Fatalf("newCmpctBlock: unexpected error: %v", err)
+		}
+		if len(cmpctBlock.PrefilledTxns) != 1 ||
+			len(cmpctBlock.ShortIDs) != 8 {
+
+			t.Fatalf("newCmpctBlock: got %d prefilled and %d "+
+				"short ids, want 1 and 8",
+				len(cmpctBlock.PrefilledTxns),
+				len(cmpctBlock.ShortIDs))
+		}
+
+		// Create a pool which holds all but the transactions at
+		// indexes 3 and 6 along with some unrelated transactions.
+		var pool []*ltcutil.Tx
+		for i, tx := range msgBlock.Transactions[1:] {
+			if i+1 == 3 || i+1 == 6 {
+				continue
+			}
+			pool = append(pool, ltcutil.NewTx(tx))
+		}
+		for i := uint32(100); i < 110; i++ {
+			pool = append(pool, ltcutil.NewTx(newTx(i)))
+		}
+
+		partial, err := newPartialBlock(cmpctBlock, version, pool)
+		if err != nil {
+			t.Fatalf("newPartialBlock: unexpected error: %v", err)
+		}
+		missing := partial.missingIndexes()
+		if !reflect.DeepEqual(missing, []uint32{3, 6}) {
+			t.Fatalf("missingIndexes: got %v, want [3 6]", missing)
+		}
+
+		// Ensure the wrong number of transactions is rejected.
+		err = partial.fill([]*wire.MsgTx{msgBlock.Transactions[3]})
+		if err == nil {
+			t.Fatal("fill: accepted wrong number of transactions")
+		}
+
+		// Complete the block with the missing transactions and ensure
+		// it matches the original block.
+		err = partial.fill([]*wire.MsgTx{msgBlock.Transactions[3],
+			msgBlock.Transactions[6]})
+		if err != nil {
+			t.Fatalf("fill: unexpected error: %v", err)
+		}
+		reconstructed := partial.block()
+		if *reconstructed.Hash() != *block.Hash() {
+			t.Fatalf("block: got hash %v, want %v",
+				reconstructed.Hash(), block.Hash())
+		}
+		if !reflect.DeepEqual(reconstructed.MsgBlock(), msgBlock) {
+			t.Fatal("block: reconstructed block does not match " +
+				"original block")
+		}
+	}
+
+	// Ensure duplicate short ids are reported as collisions.
+	cmpctBlock := wire.NewMsgCmpctBlock(&msgBlock.Header, 1)
+	cmpctBlock.AddPrefilledTx(0, msgBlock.Transactions[0])
+	cmpctBlock.AddShortID(1)
+	cmpctBlock.AddShortID(1)
+	_, err := newPartialBlock(cmpctBlock, wire.CmpctBlockVersion, nil)
+	if err != errShortIDCollision {
+		t.Fatalf("newPartialBlock: unexpected error -- got %v, want %v",
+			err, errShortIDCollision)
+	}
+
+	// Ensure prefilled indexes beyond the end of the block are rejected.
+	cmpctBlock = wire.NewMsgCmpctBlock(&msgBlock.Header, 1)
+	cmpctBlock.AddPrefilledTx(1, msgBlock.Transactions[0])
+	_, err = newPartialBlock(cmpctBlock, wire.CmpctBlockVersion, nil)
+	if err == nil {
+		t.Fatal("newPartialBlock: accepted out of range prefilled index")
+	}
+}
+
+// TestSelectHighBandwidthPeer ensures the high-bandwidth compact block peers
+// are bounded and the least recent peer to deliver a block is evicted first.
+func TestSelectHighBandwidthPeer(t *testing.T) {
+	t.Parallel()
+
+	peers := make([]*peerpkg.Peer, 5)
+	for i := range peers {
+		peers[i] = &peerpkg.Peer{}
+	}
+
+	tests := []struct {
+		name        string
+		peer        *peerpkg.Peer
+		wantPeers   []*peerpkg.Peer
+		wantEvicted *peerpkg.Peer
+		wantAdded   bool
+	}{{
+		name:      "first peer",
+		peer:      peers[0],
+		wantPeers: []*peerpkg.Peer{peers[0]},
+		wantAdded: true,
+	}, {
+		name:      "second peer",
+		peer:      peers[1],
+		wantPeers: []*peerpkg.Peer{peers[0], peers[1]},
+		wantAdded: true,
+	}, {
+		name:      "third peer",
+		peer:      peers[2],
+		wantPeers: []*peerpkg.Peer{peers[0], peers[1], peers[2]},
+		wantAdded: true,
+	}, {
+		name:      "existing peer becomes most recent",
+		peer:      peers[0],
+		wantPeers: []*peerpkg.Peer{peers[1], peers[2], peers[0]},
+	}, {
+		name:        "fourth peer evicts least recent",
+		peer:        peers[3],
+		wantPeers:   []*peerpkg.Peer{peers[2], peers[0], peers[3]},
+		wantEvicted: peers[1],
+		wantAdded:   true,
+	}, {
+		name:        "fifth peer evicts least recent",
+		peer:        peers[4],
+		wantPeers:   []*peerpkg.Peer{peers[0], peers[3], peers[4]},
+		wantEvicted: peers[2],
+		wantAdded:   true,
+	}}
+
+	var hbPeers []*peerpkg.Peer
+	for _, test := range tests {
+		var evicted *peerpkg.Peer
+		var added bool
+		hbPeers, evicted, added = selectHighBandwidthPeer(hbPeers,
+			test.peer)
+		if len(hbPeers) > maxHighBandwidthPeers {
+			t.Fatalf("%s: %d high-bandwidth peers exceeds max %d",
+				test.name, len(hbPeers), maxHighBandwidthPeers)
+		}
+		if len(hbPeers) != len(test.wantPeers) {
+			t.Fatalf("%s: got %d peers, want %d", test.name,
+				len(hbPeers), len(test.wantPeers))
+		}
+		for i, p := range hbPeers {
+			if p != test.wantPeers[i] {
+				t.Fatalf("%s: unexpected peer at index %d",
+					test.name, i)
+			}
+		}
+		if evicted != test.wantEvicted {
+			t.Fatalf("%s: unexpected evicted peer", test.name)
+		}
+		if added != test.wantAdded {
+			t.Fatalf("%s: got added %v, want %v", test.name, added,
+				test.wantAdded)
+		}
+	}
+}
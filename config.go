@@ -20,6 +20,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ltcsuite/ltcd/addrmgr"
 	"github.com/ltcsuite/ltcd/blockchain"
 	"github.com/ltcsuite/ltcd/chaincfg"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
@@ -27,6 +28,9 @@ import (
 	"github.com/ltcsuite/ltcd/database"
 	_ "github.com/ltcsuite/ltcd/database/ffldb"
 	"github.com/ltcsuite/ltcd/mempool"
+	"github.com/ltcsuite/ltcd/mining"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcd/zmq"
 	"github.com/ltcsuite/ltcutil"
 	"github.com/btcsuite/go-socks/socks"
 	flags "github.com/jessevdk/go-flags"
@@ -38,14 +42,24 @@ const (
 	defaultLogLevel              = "info"
 	defaultLogDirname            = "logs"
 	defaultLogFilename           = "ltcd.log"
+	defaultLogFormat             = "text"
+	defaultLogMaxSize            = 10
+	defaultLogMaxBackups         = 3
 	defaultMaxPeers              = 125
+	defaultBlockRelayPeers       = 2
+	defaultI2PSAMPort            = "7656"
 	defaultBanDuration           = time.Hour * 24
 	defaultBanThreshold          = 100
+	defaultBanHalflife           = time.Minute
 	defaultConnectTimeout        = time.Second * 30
 	defaultMaxRPCClients         = 10
 	defaultMaxRPCWebsockets      = 25
 	defaultMaxRPCConcurrentReqs  = 20
+	defaultMaxRPCRequestSize     = 32 * 1024 * 1024
+	defaultMaxRPCBatchSize       = 1000
+	defaultRPCSlowThreshold      = time.Second * 10
 	defaultDbType                = "ffldb"
+	defaultRPCCookieFilename     = ".cookie"
 	defaultFreeTxRelayLimit      = 15.0
 	defaultBlockMinSize          = 0
 	defaultBlockMaxSize          = 750000
@@ -56,12 +70,19 @@ const (
 	blockMaxWeightMin            = 4000
 	blockMaxWeightMax            = blockchain.MaxBlockWeight - 4000
 	defaultGenerate              = false
+	defaultStratumPort           = "3333"
 	defaultMaxOrphanTransactions = 100
 	defaultMaxOrphanTxSize       = 100000
+	defaultMaxMempool            = mempool.DefaultMaxPoolSize / 1000000
+	defaultLimitAncestorSize     = mempool.DefaultMaxAncestorSize / 1000
+	defaultLimitDescendantSize   = mempool.DefaultMaxDescendantSize / 1000
 	defaultSigCacheMaxSize       = 100000
+	defaultScriptCacheMaxSize    = 100000
+	defaultDbCache               = 450
 	sampleConfigFilename         = "sample-ltcd.conf"
 	defaultTxIndex               = false
 	defaultAddrIndex             = false
+	pruneTargetMin               = 550
 )
 
 var (
@@ -95,28 +116,52 @@ type config struct {
 	ConfigFile           string        `short:"C" long:"configfile" description:"Path to configuration file"`
 	DataDir              string        `short:"b" long:"datadir" description:"Directory to store data"`
 	LogDir               string        `long:"logdir" description:"Directory to log output."`
+	LogMaxSize           int           `long:"logmaxsize" description:"Maximum size in MiB of the log file before it is rotated to a compressed backup"`
+	LogMaxBackups        int           `long:"logmaxbackups" description:"Maximum number of compressed log file backups to keep -- 0 keeps all backups"`
+	LogFormat            string        `long:"logformat" description:"Format of log output {text, json} -- json writes each log entry as a JSON object with time, level, subsystem and message fields"`
 	AddPeers             []string      `short:"a" long:"addpeer" description:"Add a peer to connect with at startup"`
 	ConnectPeers         []string      `long:"connect" description:"Connect only to the specified peers at startup"`
 	DisableListen        bool          `long:"nolisten" description:"Disable listening for incoming connections -- NOTE: Listening is automatically disabled if the --connect or --proxy options are used without also specifying listen interfaces via --listen"`
 	Listeners            []string      `long:"listen" description:"Add an interface/port to listen for connections (default all interfaces port: 9333, testnet: 19333)"`
+	OnionListeners       []string      `long:"onionlisten" description:"Add an interface/port to listen for connections forwarded by a tor hidden service, which are treated as connections from onion peers (eg. 127.0.0.1:9335)"`
 	MaxPeers             int           `long:"maxpeers" description:"Max number of inbound and outbound peers"`
+	BlockRelayPeers      uint          `long:"blockrelaypeers" description:"Number of outbound peers which only relay blocks and do not relay transactions or addresses, in addition to the regular outbound peers"`
 	DisableBanning       bool          `long:"nobanning" description:"Disable banning of misbehaving peers"`
 	BanDuration          time.Duration `long:"banduration" description:"How long to ban misbehaving peers.  Valid time units are {s, m, h}.  Minimum 1 second"`
-	BanThreshold         uint32        `long:"banthreshold" description:"Maximum allowed ban score before disconnecting and banning misbehaving peers."`
+	BanThreshold         uint32        `long:"banthreshold" description:"Maximum allowed ban score before disconnecting and banning misbehaving peers.  0 disables banning"`
+	BanHalflife          time.Duration `long:"banhalflife" description:"Time after which the decaying part of the ban score of peers is halved.  Valid time units are {s, m, h}.  Minimum 1 second"`
 	RPCUser              string        `short:"u" long:"rpcuser" description:"Username for RPC connections"`
 	RPCPass              string        `short:"P" long:"rpcpass" default-mask:"-" description:"Password for RPC connections"`
 	RPCLimitUser         string        `long:"rpclimituser" description:"Username for limited RPC connections"`
 	RPCLimitPass         string        `long:"rpclimitpass" default-mask:"-" description:"Password for limited RPC connections"`
+	RPCScopedUsers       []string      `long:"rpcscopeduser" default-mask:"-" description:"Add an RPC user only permitted to call the given methods, or those of the given role {full, limited, readonly} -- format: user:password:method1,method2 or user:password:role"`
 	RPCListeners         []string      `long:"rpclisten" description:"Add an interface/port to listen for RPC connections (default port: 9334, testnet: 19334)"`
 	RPCCert              string        `long:"rpccert" description:"File containing the certificate file"`
 	RPCKey               string        `long:"rpckey" description:"File containing the certificate key"`
 	RPCMaxClients        int           `long:"rpcmaxclients" description:"Max number of RPC clients for standard connections"`
 	RPCMaxWebsockets     int           `long:"rpcmaxwebsockets" description:"Max number of RPC websocket connections"`
 	RPCMaxConcurrentReqs int           `long:"rpcmaxconcurrentreqs" description:"Max number of concurrent RPC requests that may be processed concurrently"`
+	RPCMaxRequestSize    int64         `long:"rpcmaxrequestsize" description:"Max size in bytes of RPC requests and websocket messages"`
+	RPCMaxBatchSize      int           `long:"rpcmaxbatchsize" description:"Max number of requests in a batch of RPC requests"`
+	RPCTimeout           time.Duration `long:"rpctimeout" description:"Cancel RPC calls which take longer than the given duration unless overridden for their method -- 0 means no timeout.  Valid time units are {s, m, h}"`
+	RPCMethodTimeouts    []string      `long:"rpcmethodtimeout" description:"Override the rpctimeout option for an RPC method -- format: method:duration, eg. gettxoutsetinfo:30m"`
+	RPCSlowThreshold     time.Duration `long:"rpcslowthreshold" description:"Log RPC calls which take longer than the given duration -- 0 disables logging slow calls.  Valid time units are {s, m, h}"`
 	RPCQuirks            bool          `long:"rpcquirks" description:"Mirror some JSON-RPC quirks of Bitcoin Core -- NOTE: Discouraged unless interoperability issues need to be worked around"`
-	DisableRPC           bool          `long:"norpc" description:"Disable built-in RPC server -- NOTE: The RPC server is disabled by default if no rpcuser/rpcpass or rpclimituser/rpclimitpass is specified"`
+	DisableRPC           bool          `long:"norpc" description:"Disable built-in RPC server -- NOTE: The RPC server is disabled by default if no rpcuser/rpcpass, rpclimituser/rpclimitpass or rpcscopeduser is specified, unless rpclisten is specified and the cookie is enabled"`
+	DisableRPCCookie     bool          `long:"norpccookie" description:"Do not write a cookie with random RPC credentials to the data directory for local tools to authenticate with when the RPC server is enabled -- NOTE: Specifying rpclisten enables the RPC server with only the cookie unless this is set"`
 	DisableTLS           bool          `long:"notls" description:"Disable TLS for the RPC server -- NOTE: This is only allowed if the RPC server is bound to localhost"`
+	ZMQPubHashBlock      string        `long:"zmqpubhashblock" description:"Publish the hashes of connected blocks on the given ZMQ endpoint (eg. tcp://127.0.0.1:28332)"`
+	ZMQPubHashTx         string        `long:"zmqpubhashtx" description:"Publish the hashes of the transactions accepted to the memory pool or in connected blocks on the given ZMQ endpoint"`
+	ZMQPubRawBlock       string        `long:"zmqpubrawblock" description:"Publish connected blocks on the given ZMQ endpoint"`
+	ZMQPubRawTx          string        `long:"zmqpubrawtx" description:"Publish the transactions accepted to the memory pool or in connected blocks on the given ZMQ endpoint"`
+	ZMQPubSequence       string        `long:"zmqpubsequence" description:"Publish the blocks connected to and disconnected from the main chain and the transactions added to and removed from the memory pool along with the memory pool sequence on the given ZMQ endpoint"`
+	ZMQPubHashBlockHWM   int           `long:"zmqpubhashblockhwm" description:"Max number of hashblock notifications queued for each subscriber -- 0 means no limit"`
+	ZMQPubHashTxHWM      int           `long:"zmqpubhashtxhwm" description:"Max number of hashtx notifications queued for each subscriber -- 0 means no limit"`
+	ZMQPubRawBlockHWM    int           `long:"zmqpubrawblockhwm" description:"Max number of rawblock notifications queued for each subscriber -- 0 means no limit"`
+	ZMQPubRawTxHWM       int           `long:"zmqpubrawtxhwm" description:"Max number of rawtx notifications queued for each subscriber -- 0 means no limit"`
+	ZMQPubSequenceHWM    int           `long:"zmqpubsequencehwm" description:"Max number of sequence notifications queued for each subscriber -- 0 means no limit"`
 	DisableDNSSeed       bool          `long:"nodnsseed" description:"Disable DNS seeding for peers"`
+	NoFixedSeeds         bool          `long:"nofixedseeds" description:"Do not fall back to the fixed seeds of the network when DNS seeding returns no addresses"`
 	ExternalIPs          []string      `long:"externalip" description:"Add an ip to the list of local addresses we claim to listen on to peers"`
 	Proxy                string        `long:"proxy" description:"Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)"`
 	ProxyUser            string        `long:"proxyuser" description:"Username for proxy server"`
@@ -125,12 +170,16 @@ type config struct {
 	OnionProxyUser       string        `long:"onionuser" description:"Username for onion proxy server"`
 	OnionProxyPass       string        `long:"onionpass" default-mask:"-" description:"Password for onion proxy server"`
 	NoOnion              bool          `long:"noonion" description:"Disable connecting to tor hidden services"`
-	TorIsolation         bool          `long:"torisolation" description:"Enable Tor stream isolation by randomizing user credentials for each connection."`
+	I2PSAM               string        `long:"i2psam" description:"Connect to and accept connections from I2P peers through the SAM v3 bridge at the given address (eg. 127.0.0.1:7656)"`
+	CJDNSReachable       bool          `long:"cjdnsreachable" description:"Connect to peers with CJDNS or Yggdrasil (fc00::/8) addresses, which requires this host to be joined to the overlay network -- NOTE: These connections are made directly rather than through a proxy"`
+	OnlyNets             []string      `long:"onlynet" description:"Only make automatic connections to and accept connections from peers on the given network (ipv4, ipv6, onion, i2p or cjdns) -- May be specified multiple times to allow several networks -- NOTE: Peers specified with --connect or --addpeer are always connected to"`
+	TorIsolation         bool          `long:"torisolation" description:"Enable Tor stream isolation by randomizing user credentials for each connection and DNS lookup."`
 	TestNet4             bool          `long:"testnet" description:"Use the test network"`
 	RegressionTest       bool          `long:"regtest" description:"Use the regression test network"`
 	SimNet               bool          `long:"simnet" description:"Use the simulation test network"`
 	AddCheckpoints       []string      `long:"addcheckpoint" description:"Add a custom checkpoint.  Format: '<height>:<hash>'"`
 	DisableCheckpoints   bool          `long:"nocheckpoints" description:"Disable built-in checkpoints.  Don't do this unless you know what you're doing."`
+	AssumeValid          string        `long:"assumevalid" description:"Skip script verification for the main chain blocks up to and including the given block, which is assumed to be valid.  Format: '<hash>' for built-in checkpoints, otherwise '<height>:<hash>'.  The zero hash verifies all scripts.  Ignored when checkpoints are disabled. (default: network dependent)"`
 	DbType               string        `long:"dbtype" description:"Database backend to use for the Block Chain"`
 	Profile              string        `long:"profile" description:"Enable HTTP profiling on given port -- NOTE port must be between 1024 and 65536"`
 	CPUProfile           string        `long:"cpuprofile" description:"Write CPU profile to the specified file"`
@@ -140,8 +189,22 @@ type config struct {
 	FreeTxRelayLimit     float64       `long:"limitfreerelay" description:"Limit relay of transactions with no transaction fee to the given amount in thousands of bytes per minute"`
 	NoRelayPriority      bool          `long:"norelaypriority" description:"Do not require free or low-fee transactions to have high priority for relaying"`
 	MaxOrphanTxs         int           `long:"maxorphantx" description:"Max number of orphan transactions to keep in memory"`
+	MaxOrphanTxSize      int           `long:"maxorphantxsize" description:"Max size in bytes of orphan transactions to keep in memory"`
+	OrphanTTL            time.Duration `long:"orphanttl" description:"How long to keep orphan transactions in memory before they expire.  Valid time units are {s, m, h}.  Minimum 1 second"`
+	MaxOrphanBlocks      int           `long:"maxorphanblocks" description:"Max number of orphan blocks to keep in memory while waiting for their parents -- The oldest orphan block is evicted when the limit is reached"`
+	MaxMempool           int           `long:"maxmempool" description:"Keep the transaction memory pool below the given size in megabytes by evicting the transactions paying the lowest fee rates -- 0 to disable"`
+	MempoolExpiry        time.Duration `long:"mempoolexpiry" description:"Evict transactions from the memory pool along with their descendants once they have been in it for the given amount of time.  Valid time units are {s, m, h} -- 0 to disable"`
+	LimitAncestorCount   int           `long:"limitancestorcount" description:"Do not accept transactions with more than the given number of ancestors in the memory pool, including the transaction itself"`
+	LimitAncestorSize    int           `long:"limitancestorsize" description:"Do not accept transactions whose ancestors in the memory pool, including the transaction itself, exceed the given total virtual size in kilobytes"`
+	LimitDescendantCount int           `long:"limitdescendantcount" description:"Do not accept transactions which would give a transaction in the memory pool more than the given number of descendants, including itself"`
+	LimitDescendantSize  int           `long:"limitdescendantsize" description:"Do not accept transactions which would give a transaction in the memory pool descendants exceeding the given total virtual size in kilobytes, including itself"`
+	AcceptRBF            bool          `long:"acceptrbf" description:"Accept transactions that replace memory pool transactions which signal opt-in replace-by-fee (BIP 125)"`
 	Generate             bool          `long:"generate" description:"Generate (mine) litecoins using the CPU"`
-	MiningAddrs          []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate option is set"`
+	MiningAddrs          []string      `long:"miningaddr" description:"Add the specified payment address to the list of addresses to use for generated blocks -- At least one address is required if the generate or stratumlisten options are set"`
+	MiningFlags          string        `long:"miningflags" description:"Extra data to append to the coinbase flags of generated blocks (max 73 bytes)"`
+	SignalBits           []uint8       `long:"signalbit" description:"Force generated blocks to signal the specified BIP0009 version bit (0-28) regardless of the state of its deployment"`
+	NoSignalBits         []uint8       `long:"nosignalbit" description:"Force generated blocks to not signal the specified BIP0009 version bit (0-28) regardless of the state of its deployment"`
+	StratumListeners     []string      `long:"stratumlisten" description:"Add an interface/port to listen for Stratum mining connections (default port: 3333)"`
 	BlockMinSize         uint32        `long:"blockminsize" description:"Mininum block size in bytes to be used when creating a block"`
 	BlockMaxSize         uint32        `long:"blockmaxsize" description:"Maximum block size in bytes to be used when creating a block"`
 	BlockMinWeight       uint32        `long:"blockminweight" description:"Mininum block weight to be used when creating a block"`
@@ -150,21 +213,101 @@ type config struct {
 	UserAgentComments    []string      `long:"uacomment" description:"Comment to add to the user agent -- See BIP 14 for more information."`
 	NoPeerBloomFilters   bool          `long:"nopeerbloomfilters" description:"Disable bloom filtering support"`
 	NoCFilters           bool          `long:"nocfilters" description:"Disable committed filtering (CF) support"`
+	CfIndexVerify        bool          `long:"cfindexverify" description:"Verify that the basic committed filter of each block matches all of the data it commits to when indexing it and refuse to index blocks whose filters do not -- This is expensive and only useful to catch filter construction regressions"`
 	DropCfIndex          bool          `long:"dropcfindex" description:"Deletes the index used for committed filtering (CF) support from the database on start up and then exits."`
 	SigCacheMaxSize      uint          `long:"sigcachemaxsize" description:"The maximum number of entries in the signature verification cache"`
+	ScriptCacheMaxSize   uint          `long:"scriptcachemaxsize" description:"The maximum number of transaction inputs in the script execution cache, which skips executing the scripts of the transactions in blocks which were already validated by the mempool"`
+	ScriptWorkers        uint          `long:"scriptworkers" description:"The maximum number of goroutines used to verify the scripts of a block concurrently (0 to use one per available processor)"`
 	BlocksOnly           bool          `long:"blocksonly" description:"Do not accept transactions from remote peers."`
 	TxIndex              bool          `long:"txindex" description:"Maintain a full hash-based transaction index which makes all transactions available via the getrawtransaction RPC"`
 	DropTxIndex          bool          `long:"droptxindex" description:"Deletes the hash-based transaction index from the database on start up and then exits."`
 	AddrIndex            bool          `long:"addrindex" description:"Maintain a full address-based transaction index which makes the searchrawtransactions RPC available"`
 	DropAddrIndex        bool          `long:"dropaddrindex" description:"Deletes the address-based transaction index from the database on start up and then exits."`
+	DbCache              uint64        `long:"dbcache" description:"The maximum size in MiB of the cache of the utxo set kept in memory, which holds the changes to the utxo set until they are written to the database in batches (0 to write the changes of every block)"`
+	Prune                uint64        `long:"prune" description:"Delete old blocks from the database to keep the stored block data within the target size in MiB -- Must be at least 550 MiB and is incompatible with --txindex and --addrindex (0 to disable)"`
+	Reindex              bool          `long:"reindex" description:"Rebuild the chain state and the index of the main chain by validating and connecting all of the stored blocks again on start up -- An interrupted reindex resumes on the next start"`
+	ReindexChainState    bool          `long:"reindex-chainstate" description:"Rebuild the utxo set by connecting the already validated blocks of the main chain again on start up -- An interrupted reindex resumes on the next start"`
 	RelayNonStd          bool          `long:"relaynonstd" description:"Relay non-standard transactions regardless of the default settings for the active network."`
 	RejectNonStd         bool          `long:"rejectnonstd" description:"Reject non-standard transactions regardless of the default settings for the active network."`
 	lookup               func(string) ([]net.IP, error)
+	seedLookup           func(string) ([]net.IP, error)
+	onlyNets             map[addrmgr.NetworkType]struct{}
 	oniondial            func(string, string, time.Duration) (net.Conn, error)
 	dial                 func(string, string, time.Duration) (net.Conn, error)
 	addCheckpoints       []chaincfg.Checkpoint
+	assumeValid          *chaincfg.Checkpoint
 	miningAddrs          []ltcutil.Address
 	minRelayTxFee        ltcutil.Amount
+	rpcScopedUsers       []rpcScopedUser
+	rpcMethodTimeouts    map[string]time.Duration
+}
+
+// rpcScopedUser houses the credentials of an RPC user defined with the
+// rpcscopeduser option along with the methods it is permitted to call.
+type rpcScopedUser struct {
+	name     string
+	password string
+	scope    rpcScope
+}
+
+// parseRPCMethodTimeout parses the passed value of the rpcmethodtimeout option,
+// which is the method and the timeout of its calls separated by a colon.
+func parseRPCMethodTimeout(value string) (string, time.Duration, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return "", 0, errors.New("the value must be of the form " +
+			"method:duration")
+	}
+	method := parts[0]
+	if _, ok := rpcHandlers[method]; !ok {
+		return "", 0, fmt.Errorf("unknown method %q", method)
+	}
+	timeout, err := time.ParseDuration(parts[1])
+	if err != nil {
+		return "", 0, err
+	}
+	if timeout < 0 {
+		return "", 0, fmt.Errorf("the timeout of %s may not be less "+
+			"than 0", method)
+	}
+	return method, timeout, nil
+}
+
+// parseRPCScopedUser parses the passed value of the rpcscopeduser option, which
+// is the username, the password and either a comma-separated list of methods or
+// a role, separated by colons.  The password may contain colons.
+func parseRPCScopedUser(value string) (*rpcScopedUser, error) {
+	userEnd := strings.Index(value, ":")
+	scopeStart := strings.LastIndex(value, ":")
+	if userEnd <= 0 || scopeStart == userEnd ||
+		scopeStart == len(value)-1 {
+
+		return nil, errors.New("the value must be of the form " +
+			"user:password:method1,method2 or user:password:role")
+	}
+	user := &rpcScopedUser{
+		name:     value[:userEnd],
+		password: value[userEnd+1 : scopeStart],
+	}
+	if user.password == "" {
+		return nil, errors.New("the password may not be empty")
+	}
+
+	methods := value[scopeStart+1:]
+	if scope, ok := rpcRoleScope(methods); ok {
+		user.scope = scope
+		return user, nil
+	}
+	user.scope = make(rpcScope)
+	for _, method := range strings.Split(methods, ",") {
+		_, isRPC := rpcHandlers[method]
+		_, isWebsocket := wsHandlers[method]
+		if !isRPC && !isWebsocket {
+			return nil, fmt.Errorf("unknown method or role %q", method)
+		}
+		user.scope[method] = struct{}{}
+	}
+	return user, nil
 }
 
 // serviceOptions defines the configuration options for the daemon as a service on
@@ -222,7 +365,7 @@ func supportedSubsystems() []string {
 
 // parseAndSetDebugLevels attempts to parse the specified debug level and set
 // the levels accordingly.  An appropriate error is returned if anything is
-// invalid.
+// invalid, in which case none of the levels are changed.
 func parseAndSetDebugLevels(debugLevel string) error {
 	// When the specified string doesn't have any delimters, treat it as
 	// the log level for all subsystems.
@@ -240,7 +383,9 @@ func parseAndSetDebugLevels(debugLevel string) error {
 	}
 
 	// Split the specified string into subsystem/level pairs while detecting
-	// issues and update the log levels accordingly.
+	// issues.  The log levels are only updated once all of the pairs are
+	// known to be valid.
+	logLevels := make(map[string]string)
 	for _, logLevelPair := range strings.Split(debugLevel, ",") {
 		if !strings.Contains(logLevelPair, "=") {
 			str := "The specified debug level contains an invalid " +
@@ -265,6 +410,9 @@ func parseAndSetDebugLevels(debugLevel string) error {
 			return fmt.Errorf(str, logLevel)
 		}
 
+		logLevels[subsysID] = logLevel
+	}
+	for subsysID, logLevel := range logLevels {
 		setLogLevel(subsysID, logLevel)
 	}
 
@@ -316,6 +464,41 @@ func normalizeAddresses(addrs []string, defaultPort string) []string {
 	return removeDuplicateAddresses(addrs)
 }
 
+// parseOnlyNets returns the set of networks named by the passed --onlynet
+// values, or nil when there are none, in which case all networks are allowed.
+func parseOnlyNets(names []string) (map[addrmgr.NetworkType]struct{}, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	onlyNets := make(map[addrmgr.NetworkType]struct{}, len(names))
+	for _, name := range names {
+		netType := addrmgr.NetworkIPv4
+		for ; netType <= addrmgr.NetworkI2P; netType++ {
+			if strings.ToLower(name) == netType.String() {
+				break
+			}
+		}
+		if netType > addrmgr.NetworkI2P {
+			return nil, fmt.Errorf("unknown network %q -- supported "+
+				"networks are ipv4, ipv6, onion, i2p and cjdns",
+				name)
+		}
+		onlyNets[netType] = struct{}{}
+	}
+	return onlyNets, nil
+}
+
+// onlyNetAllowed returns whether or not automatic connections to and from
+// peers on the passed network are allowed by the --onlynet option.
+func onlyNetAllowed(netType addrmgr.NetworkType) bool {
+	if len(cfg.onlyNets) == 0 {
+		return true
+	}
+	_, ok := cfg.onlyNets[netType]
+	return ok
+}
+
 // newCheckpointFromStr parses checkpoints in the '<height>:<hash>' format.
 func newCheckpointFromStr(checkpoint string) (chaincfg.Checkpoint, error) {
 	parts := strings.Split(checkpoint, ":")
@@ -364,6 +547,75 @@ func parseCheckpoints(checkpointStrings []string) ([]chaincfg.Checkpoint, error)
 	return checkpoints, nil
 }
 
+// parseAssumeValid parses the assumed valid block string, which is either
+// '<height>:<hash>' or the hash of a block with a known height, and returns the
+// block to assume valid.  The default of the network is used when the string
+// is empty, while nil is returned for the zero hash, which disables the
+// optimization.
+func parseAssumeValid(assumeValid string, params *chaincfg.Params, addCheckpoints []chaincfg.Checkpoint) (*chaincfg.Checkpoint, error) {
+	if assumeValid == "" {
+		if params.AssumeValid.Hash == nil {
+			return nil, nil
+		}
+		checkpoint := params.AssumeValid
+		return &checkpoint, nil
+	}
+
+	if strings.Contains(assumeValid, ":") {
+		checkpoint, err := newCheckpointFromStr(assumeValid)
+		if err != nil {
+			return nil, err
+		}
+		if *checkpoint.Hash == (chainhash.Hash{}) {
+			return nil, nil
+		}
+		return &checkpoint, nil
+	}
+
+	hash, err := chainhash.NewHashFromStr(assumeValid)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse assumed valid block "+
+			"%q due to malformed hash", assumeValid)
+	}
+	if *hash == (chainhash.Hash{}) {
+		return nil, nil
+	}
+
+	// Look up the height of the block in the known blocks.
+	known := append([]chaincfg.Checkpoint{params.AssumeValid},
+		params.Checkpoints...)
+	known = append(known, addCheckpoints...)
+	for _, checkpoint := range known {
+		if checkpoint.Hash != nil && checkpoint.Hash.IsEqual(hash) {
+			return &chaincfg.Checkpoint{
+				Height: checkpoint.Height,
+				Hash:   hash,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("unable to determine the height of assumed "+
+		"valid block %v -- use the syntax <height>:<hash>", hash)
+}
+
+// rpcServerEnabled returns whether or not the RPC server is enabled by the
+// passed configuration.  It is enabled when credentials are configured for at
+// least one user.  Otherwise, since the cookie written to the data directory
+// is enough for local clients to authenticate, it is only enabled when the
+// interfaces to listen on are specified and the cookie is not disabled, so the
+// cookie alone does not start the RPC server on every node.
+func rpcServerEnabled(cfg *config) bool {
+	if cfg.DisableRPC {
+		return false
+	}
+	if (cfg.RPCUser != "" && cfg.RPCPass != "") ||
+		(cfg.RPCLimitUser != "" && cfg.RPCLimitPass != "") ||
+		len(cfg.rpcScopedUsers) > 0 {
+
+		return true
+	}
+	return len(cfg.RPCListeners) > 0 && !cfg.DisableRPCCookie
+}
+
 // filesExists reports whether the named file or directory exists.
 func fileExists(name string) bool {
 	if _, err := os.Stat(name); err != nil {
@@ -401,13 +653,26 @@ func loadConfig() (*config, []string, error) {
 		ConfigFile:           defaultConfigFile,
 		DebugLevel:           defaultLogLevel,
 		MaxPeers:             defaultMaxPeers,
+		BlockRelayPeers:      defaultBlockRelayPeers,
 		BanDuration:          defaultBanDuration,
 		BanThreshold:         defaultBanThreshold,
+		BanHalflife:          defaultBanHalflife,
 		RPCMaxClients:        defaultMaxRPCClients,
 		RPCMaxWebsockets:     defaultMaxRPCWebsockets,
 		RPCMaxConcurrentReqs: defaultMaxRPCConcurrentReqs,
+		RPCMaxRequestSize:    defaultMaxRPCRequestSize,
+		RPCMaxBatchSize:      defaultMaxRPCBatchSize,
+		RPCSlowThreshold:     defaultRPCSlowThreshold,
+		ZMQPubHashBlockHWM:   zmq.DefaultHighWaterMark,
+		ZMQPubHashTxHWM:      zmq.DefaultHighWaterMark,
+		ZMQPubRawBlockHWM:    zmq.DefaultHighWaterMark,
+		ZMQPubRawTxHWM:       zmq.DefaultHighWaterMark,
+		ZMQPubSequenceHWM:    zmq.DefaultHighWaterMark,
 		DataDir:              defaultDataDir,
 		LogDir:               defaultLogDir,
+		LogFormat:            defaultLogFormat,
+		LogMaxSize:           defaultLogMaxSize,
+		LogMaxBackups:        defaultLogMaxBackups,
 		DbType:               defaultDbType,
 		RPCKey:               defaultRPCKeyFile,
 		RPCCert:              defaultRPCCertFile,
@@ -419,7 +684,18 @@ func loadConfig() (*config, []string, error) {
 		BlockMaxWeight:       defaultBlockMaxWeight,
 		BlockPrioritySize:    mempool.DefaultBlockPrioritySize,
 		MaxOrphanTxs:         defaultMaxOrphanTransactions,
+		MaxOrphanTxSize:      defaultMaxOrphanTxSize,
+		OrphanTTL:            mempool.DefaultOrphanTTL,
+		MaxOrphanBlocks:      blockchain.DefaultMaxOrphanBlocks,
+		MaxMempool:           defaultMaxMempool,
+		MempoolExpiry:        mempool.DefaultExpiry,
+		LimitAncestorCount:   mempool.DefaultMaxAncestorCount,
+		LimitAncestorSize:    defaultLimitAncestorSize,
+		LimitDescendantCount: mempool.DefaultMaxDescendantCount,
+		LimitDescendantSize:  defaultLimitDescendantSize,
 		SigCacheMaxSize:      defaultSigCacheMaxSize,
+		ScriptCacheMaxSize:   defaultScriptCacheMaxSize,
+		DbCache:              defaultDbCache,
 		Generate:             defaultGenerate,
 		TxIndex:              defaultTxIndex,
 		AddrIndex:            defaultAddrIndex,
@@ -589,9 +865,43 @@ func loadConfig() (*config, []string, error) {
 		os.Exit(0)
 	}
 
+	// Validate the log format.  It must be selected before anything is
+	// logged.
+	switch cfg.LogFormat {
+	case "text":
+	case "json":
+		logFormatJSON = true
+	default:
+		str := "%s: The specified log format [%v] is invalid -- " +
+			"supported formats [text json]"
+		err := fmt.Errorf(str, funcName, cfg.LogFormat)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Validate the log rotation options.
+	if cfg.LogMaxSize < 1 {
+		str := "%s: The logmaxsize option may not be less than 1 -- " +
+			"parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.LogMaxSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.LogMaxBackups < 0 {
+		str := "%s: The logmaxbackups option may not be less than 0 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.LogMaxBackups)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Initialize log rotation.  After log rotation has been initialized, the
 	// logger variables may be used.
-	initLogRotator(filepath.Join(cfg.LogDir, defaultLogFilename))
+	initLogRotator(filepath.Join(cfg.LogDir, defaultLogFilename),
+		cfg.LogMaxSize, cfg.LogMaxBackups)
 
 	// Parse, validate, and set debug log level(s).
 	if err := parseAndSetDebugLevels(cfg.DebugLevel); err != nil {
@@ -632,6 +942,20 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Don't allow ban score halflifes that are too short.
+	if cfg.BanHalflife < time.Second {
+		str := "%s: The banhalflife option may not be less than 1s -- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.BanHalflife)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// A ban threshold of 0 disables banning.
+	if cfg.BanThreshold == 0 {
+		cfg.DisableBanning = true
+	}
+
 	// --addPeer and --connect do not mix.
 	if len(cfg.AddPeers) > 0 && len(cfg.ConnectPeers) > 0 {
 		str := "%s: the --addpeer and --connect options can not be " +
@@ -642,9 +966,10 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	// --proxy or --connect without --listen disables listening.
+	// --proxy or --connect without --listen or --onionlisten disables
+	// listening.
 	if (cfg.Proxy != "" || len(cfg.ConnectPeers) > 0) &&
-		len(cfg.Listeners) == 0 {
+		len(cfg.Listeners) == 0 && len(cfg.OnionListeners) == 0 {
 		cfg.DisableListen = true
 	}
 
@@ -655,8 +980,9 @@ func loadConfig() (*config, []string, error) {
 
 	// Add the default listener if none were specified. The default
 	// listener is all addresses on the listen port for the network
-	// we are to connect to.
-	if len(cfg.Listeners) == 0 {
+	// we are to connect to.  Only listening for connections forwarded
+	// by a tor hidden service is allowed as well.
+	if len(cfg.Listeners) == 0 && len(cfg.OnionListeners) == 0 {
 		cfg.Listeners = []string{
 			net.JoinHostPort("", activeNetParams.DefaultPort),
 		}
@@ -682,9 +1008,35 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	// The RPC server is disabled if no username or password is provided.
-	if (cfg.RPCUser == "" || cfg.RPCPass == "") &&
-		(cfg.RPCLimitUser == "" || cfg.RPCLimitPass == "") {
+	// Parse the RPC users defined with scopes, whose usernames must differ
+	// from each other and the other users.
+	usernames := map[string]struct{}{
+		cfg.RPCUser:      {},
+		cfg.RPCLimitUser: {},
+	}
+	for _, value := range cfg.RPCScopedUsers {
+		user, err := parseRPCScopedUser(value)
+		if err == nil {
+			if _, ok := usernames[user.name]; ok {
+				err = fmt.Errorf("the username %q is already "+
+					"used", user.name)
+			}
+		}
+		if err != nil {
+			str := "%s: the rpcscopeduser option is invalid: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		usernames[user.name] = struct{}{}
+		cfg.rpcScopedUsers = append(cfg.rpcScopedUsers, *user)
+	}
+
+	// The RPC server is disabled if no username or password is provided,
+	// unless it was explicitly asked to listen for connections which are
+	// authenticated with the cookie.
+	if !rpcServerEnabled(&cfg) {
 		cfg.DisableRPC = true
 	}
 
@@ -714,6 +1066,47 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Ensure the RPC request size and batch limits allow requests.
+	if cfg.RPCMaxRequestSize < 1 {
+		str := "%s: The rpcmaxrequestsize option may not be less " +
+			"than 1 -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.RPCMaxRequestSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.RPCMaxBatchSize < 1 {
+		str := "%s: The rpcmaxbatchsize option may not be less " +
+			"than 1 -- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.RPCMaxBatchSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Ensure the RPC timeouts are not negative and parse the timeouts of
+	// specific methods.
+	if cfg.RPCTimeout < 0 {
+		str := "%s: The rpctimeout option may not be less than 0 " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.RPCTimeout)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	cfg.rpcMethodTimeouts = make(map[string]time.Duration)
+	for _, value := range cfg.RPCMethodTimeouts {
+		method, timeout, err := parseRPCMethodTimeout(value)
+		if err != nil {
+			str := "%s: the rpcmethodtimeout option is invalid: %v"
+			err := fmt.Errorf(str, funcName, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		cfg.rpcMethodTimeouts[method] = timeout
+	}
+
 	// Validate the the minrelaytxfee.
 	cfg.minRelayTxFee, err = ltcutil.NewAmount(cfg.MinRelayTxFee)
 	if err != nil {
@@ -724,31 +1117,31 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
-	// Limit the max block size to a sane value.
-	if cfg.BlockMaxSize < blockMaxSizeMin || cfg.BlockMaxSize >
-		blockMaxSizeMax {
-
-		str := "%s: The blockmaxsize option must be in between %d " +
-			"and %d -- parsed [%d]"
+	// Limit the max block size to a sane value.  Values above the
+	// consensus limit are limited to it.
+	if cfg.BlockMaxSize < blockMaxSizeMin {
+		str := "%s: The blockmaxsize option may not be less than %d " +
+			"-- parsed [%d]"
 		err := fmt.Errorf(str, funcName, blockMaxSizeMin,
-			blockMaxSizeMax, cfg.BlockMaxSize)
+			cfg.BlockMaxSize)
 		fmt.Fprintln(os.Stderr, err)
 		fmt.Fprintln(os.Stderr, usageMessage)
 		return nil, nil, err
 	}
+	cfg.BlockMaxSize = minUint32(cfg.BlockMaxSize, blockMaxSizeMax)
 
-	// Limit the max block weight to a sane value.
-	if cfg.BlockMaxWeight < blockMaxWeightMin ||
-		cfg.BlockMaxWeight > blockMaxWeightMax {
-
-		str := "%s: The blockmaxweight option must be in between %d " +
-			"and %d -- parsed [%d]"
+	// Limit the max block weight to a sane value.  Values above the
+	// consensus limit are limited to it.
+	if cfg.BlockMaxWeight < blockMaxWeightMin {
+		str := "%s: The blockmaxweight option may not be less than %d " +
+			"-- parsed [%d]"
 		err := fmt.Errorf(str, funcName, blockMaxWeightMin,
-			blockMaxWeightMax, cfg.BlockMaxWeight)
+			cfg.BlockMaxWeight)
 		fmt.Fprintln(os.Stderr, err)
 		fmt.Fprintln(os.Stderr, usageMessage)
 		return nil, nil, err
 	}
+	cfg.BlockMaxWeight = minUint32(cfg.BlockMaxWeight, blockMaxWeightMax)
 
 	// Limit the max orphan count to a sane vlue.
 	if cfg.MaxOrphanTxs < 0 {
@@ -759,6 +1152,64 @@ func loadConfig() (*config, []string, error) {
 		fmt.Fprintln(os.Stderr, usageMessage)
 		return nil, nil, err
 	}
+	if cfg.MaxOrphanTxSize < 0 {
+		str := "%s: The maxorphantxsize option may not be less than 0 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.MaxOrphanTxSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.OrphanTTL < time.Second {
+		str := "%s: The orphanttl option may not be less than 1s " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.OrphanTTL)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.MaxOrphanBlocks < 1 {
+		str := "%s: The maxorphanblocks option may not be less than 1 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.MaxOrphanBlocks)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.MaxMempool < 0 {
+		str := "%s: The maxmempool option may not be less than 0 " +
+			"-- parsed [%d]"
+		err := fmt.Errorf(str, funcName, cfg.MaxMempool)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.MempoolExpiry < 0 {
+		str := "%s: The mempoolexpiry option may not be less than 0 " +
+			"-- parsed [%v]"
+		err := fmt.Errorf(str, funcName, cfg.MempoolExpiry)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.LimitAncestorCount < 1 || cfg.LimitDescendantCount < 1 {
+		str := "%s: The limitancestorcount and limitdescendantcount " +
+			"options may not be less than 1 -- parsed [%d, %d]"
+		err := fmt.Errorf(str, funcName, cfg.LimitAncestorCount,
+			cfg.LimitDescendantCount)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	if cfg.LimitAncestorSize < 1 || cfg.LimitDescendantSize < 1 {
+		str := "%s: The limitancestorsize and limitdescendantsize " +
+			"options may not be less than 1 -- parsed [%d, %d]"
+		err := fmt.Errorf(str, funcName, cfg.LimitAncestorSize,
+			cfg.LimitDescendantSize)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
 
 	// Limit the block priority and minimum block sizes to max block size.
 	cfg.BlockPrioritySize = minUint32(cfg.BlockPrioritySize, cfg.BlockMaxSize)
@@ -814,6 +1265,16 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// --cfindexverify and --nocfilters do not mix.
+	if cfg.CfIndexVerify && cfg.NoCFilters {
+		err := fmt.Errorf("%s: the --cfindexverify and --nocfilters "+
+			"options may not be activated at the same time",
+			funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// --addrindex and --droptxindex do not mix.
 	if cfg.AddrIndex && cfg.DropTxIndex {
 		err := fmt.Errorf("%s: the --addrindex and --droptxindex "+
@@ -826,6 +1287,28 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Ensure the prune target is sane and that pruning is not combined
+	// with the indexes which reference the stored block data.
+	if cfg.Prune != 0 {
+		if cfg.Prune < pruneTargetMin {
+			str := "%s: the prune target of %d MiB is below the " +
+				"minimum of %d MiB"
+			err := fmt.Errorf(str, funcName, cfg.Prune,
+				pruneTargetMin)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if cfg.TxIndex || cfg.AddrIndex {
+			err := fmt.Errorf("%s: the --prune option may not be "+
+				"activated at the same time as the --txindex "+
+				"or --addrindex options", funcName)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
 	// Check mining addresses are valid and saved parsed versions.
 	cfg.miningAddrs = make([]ltcutil.Address, 0, len(cfg.MiningAddrs))
 	for _, strAddr := range cfg.MiningAddrs {
@@ -847,6 +1330,46 @@ func loadConfig() (*config, []string, error) {
 		cfg.miningAddrs = append(cfg.miningAddrs, addr)
 	}
 
+	// Ensure the extra coinbase data leaves room in the coinbase script for
+	// the block height and extra nonce.
+	if len(cfg.MiningFlags) > mining.MaxExtraCoinbaseDataLen {
+		str := "%s: the miningflags option is %d bytes, but may be at " +
+			"most %d bytes"
+		err := fmt.Errorf(str, funcName, len(cfg.MiningFlags),
+			mining.MaxExtraCoinbaseDataLen)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Ensure the forced version bits are valid and that no bit is forced
+	// to be both set and cleared.
+	signalBits := make(map[uint8]struct{}, len(cfg.SignalBits))
+	for _, bit := range cfg.SignalBits {
+		signalBits[bit] = struct{}{}
+	}
+	for _, bit := range append(cfg.SignalBits, cfg.NoSignalBits...) {
+		if bit > mining.MaxVersionBit {
+			str := "%s: version bit %d is higher than the " +
+				"maximum of %d"
+			err := fmt.Errorf(str, funcName, bit,
+				mining.MaxVersionBit)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+	for _, bit := range cfg.NoSignalBits {
+		if _, ok := signalBits[bit]; ok {
+			str := "%s: version bit %d may not be specified by " +
+				"both the signalbit and nosignalbit options"
+			err := fmt.Errorf(str, funcName, bit)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
 	// Ensure there is at least one mining address when the generate flag is
 	// set.
 	if cfg.Generate && len(cfg.MiningAddrs) == 0 {
@@ -858,16 +1381,58 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Ensure there is at least one mining address when the stratum server
+	// is enabled.
+	if len(cfg.StratumListeners) > 0 && len(cfg.MiningAddrs) == 0 {
+		str := "%s: the stratumlisten option is set, but there are no " +
+			"mining addresses specified "
+		err := fmt.Errorf(str, funcName)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// Ensure the ZMQ endpoints are valid and the high-water marks are not
+	// negative.
+	for _, topic := range zmqTopics(&cfg) {
+		if topic.endpoint == "" {
+			continue
+		}
+		if _, err := zmq.ParseEndpoint(topic.endpoint); err != nil {
+			str := "%s: the zmqpub%s option is invalid: %v"
+			err := fmt.Errorf(str, funcName, topic.topic, err)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+		if topic.highWaterMark < 0 {
+			str := "%s: the zmqpub%shwm option may not be less " +
+				"than 0 -- parsed [%d]"
+			err := fmt.Errorf(str, funcName, topic.topic,
+				topic.highWaterMark)
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, usageMessage)
+			return nil, nil, err
+		}
+	}
+
 	// Add default port to all listener addresses if needed and remove
 	// duplicate addresses.
 	cfg.Listeners = normalizeAddresses(cfg.Listeners,
 		activeNetParams.DefaultPort)
+	cfg.OnionListeners = normalizeAddresses(cfg.OnionListeners,
+		activeNetParams.DefaultPort)
 
 	// Add default port to all rpc listener addresses if needed and remove
 	// duplicate addresses.
 	cfg.RPCListeners = normalizeAddresses(cfg.RPCListeners,
 		activeNetParams.rpcPort)
 
+	// Add default port to all stratum listener addresses if needed and
+	// remove duplicate addresses.
+	cfg.StratumListeners = normalizeAddresses(cfg.StratumListeners,
+		defaultStratumPort)
+
 	// Only allow TLS to be disabled if the RPC is bound to localhost
 	// addresses.
 	if !cfg.DisableRPC && cfg.DisableTLS {
@@ -924,6 +1489,17 @@ func loadConfig() (*config, []string, error) {
 		return nil, nil, err
 	}
 
+	// Parse the assumed valid block.
+	cfg.assumeValid, err = parseAssumeValid(cfg.AssumeValid,
+		activeNetParams.Params, cfg.addCheckpoints)
+	if err != nil {
+		str := "%s: Error parsing assumevalid: %v"
+		err := fmt.Errorf(str, funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
 	// Tor stream isolation requires either proxy or onion proxy to be set.
 	if cfg.TorIsolation && cfg.Proxy == "" && cfg.OnionProxy == "" {
 		str := "%s: Tor stream isolation requires either proxy or " +
@@ -955,11 +1531,8 @@ func loadConfig() (*config, []string, error) {
 		// Tor isolation flag means proxy credentials will be overridden
 		// unless there is also an onion proxy configured in which case
 		// that one will be overridden.
-		torIsolation := false
-		if cfg.TorIsolation && cfg.OnionProxy == "" &&
-			(cfg.ProxyUser != "" || cfg.ProxyPass != "") {
-
-			torIsolation = true
+		torIsolation := cfg.TorIsolation && cfg.OnionProxy == ""
+		if torIsolation && (cfg.ProxyUser != "" || cfg.ProxyPass != "") {
 			fmt.Fprintln(os.Stderr, "Tor isolation set -- "+
 				"overriding specified proxy user credentials")
 		}
@@ -976,9 +1549,7 @@ func loadConfig() (*config, []string, error) {
 		// unless the --noonion flag is set or there is an
 		// onion-specific proxy configured.
 		if !cfg.NoOnion && cfg.OnionProxy == "" {
-			cfg.lookup = func(host string) ([]net.IP, error) {
-				return connmgr.TorLookupIP(host, cfg.Proxy)
-			}
+			cfg.lookup = torLookup(cfg.Proxy, torIsolation)
 		}
 	}
 
@@ -1022,9 +1593,7 @@ func loadConfig() (*config, []string, error) {
 		// not a tor proxy, so override the DNS resolution to use the
 		// onion-specific proxy.
 		if cfg.Proxy != "" {
-			cfg.lookup = func(host string) ([]net.IP, error) {
-				return connmgr.TorLookupIP(host, cfg.OnionProxy)
-			}
+			cfg.lookup = torLookup(cfg.OnionProxy, cfg.TorIsolation)
 		}
 	} else {
 		cfg.oniondial = cfg.dial
@@ -1038,6 +1607,49 @@ func loadConfig() (*config, []string, error) {
 		}
 	}
 
+	// Add the default port to the I2P SAM bridge address if needed.
+	if cfg.I2PSAM != "" {
+		cfg.I2PSAM = normalizeAddress(cfg.I2PSAM, defaultI2PSAMPort)
+	}
+
+	// Parse the networks automatic connections are limited to and ensure
+	// each of them can actually be reached.
+	cfg.onlyNets, err = parseOnlyNets(cfg.OnlyNets)
+	if err != nil {
+		err := fmt.Errorf("%s: invalid --onlynet: %v", funcName, err)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+	_, onlyOnion := cfg.onlyNets[addrmgr.NetworkOnion]
+	_, onlyI2P := cfg.onlyNets[addrmgr.NetworkI2P]
+	_, onlyCJDNS := cfg.onlyNets[addrmgr.NetworkCJDNS]
+	var unreachable string
+	switch {
+	case onlyOnion && cfg.NoOnion:
+		unreachable = "--onlynet=onion requires tor to be enabled"
+	case onlyI2P && cfg.I2PSAM == "":
+		unreachable = "--onlynet=i2p requires --i2psam"
+	case onlyCJDNS && !cfg.CJDNSReachable:
+		unreachable = "--onlynet=cjdns requires --cjdnsreachable"
+	}
+	if unreachable != "" {
+		err := fmt.Errorf("%s: %s", funcName, unreachable)
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, usageMessage)
+		return nil, nil, err
+	}
+
+	// DNS seeds are resolved with the lookup function selected above,
+	// except that they are always resolved through the proxy when one is
+	// specified, even when --noonion leaves other lookups to the system DNS
+	// resolver, so seeding does not bypass the proxy.  The fixed seeds are
+	// used instead when the proxy is unable to resolve them.
+	cfg.seedLookup = cfg.lookup
+	if cfg.Proxy != "" && cfg.NoOnion {
+		cfg.seedLookup = torLookup(cfg.Proxy, cfg.TorIsolation)
+	}
+
 	// Warn about missing config file only after all other configuration is
 	// done.  This prevents the warning on help messages and invalid
 	// options.  Note this should go directly before the return.
@@ -1115,6 +1727,21 @@ func createDefaultConfigFile(destinationPath string) error {
 	return nil
 }
 
+// torLookup returns a DNS resolution function which resolves hosts via the Tor
+// proxy at the provided address.  When stream isolation is requested, each
+// resolution uses unique credentials so it is performed over a separate
+// circuit.
+func torLookup(proxy string, torIsolation bool) func(string) ([]net.IP, error) {
+	if torIsolation {
+		return func(host string) ([]net.IP, error) {
+			return connmgr.TorLookupIPIsolated(host, proxy)
+		}
+	}
+	return func(host string) ([]net.IP, error) {
+		return connmgr.TorLookupIP(host, proxy)
+	}
+}
+
 // ltcdDial connects to the address on the named network using the appropriate
 // dial function depending on the address and configuration options.  For
 // example, .onion addresses will be dialed using the onion specific proxy if
@@ -1125,9 +1752,26 @@ func ltcdDial(addr net.Addr) (net.Conn, error) {
 		return cfg.oniondial(addr.Network(), addr.String(),
 			defaultConnectTimeout)
 	}
+
+	// CJDNS addresses are only reachable through the overlay network
+	// interface of this host, so they are never dialed through a proxy.
+	if isCJDNSAddr(addr) {
+		if !cfg.CJDNSReachable {
+			return nil, errors.New("cjdns has not been enabled")
+		}
+		return net.DialTimeout(addr.Network(), addr.String(),
+			defaultConnectTimeout)
+	}
 	return cfg.dial(addr.Network(), addr.String(), defaultConnectTimeout)
 }
 
+// isCJDNSAddr returns whether or not the passed address is a TCP address in
+// the range used by the CJDNS and Yggdrasil overlay networks.
+func isCJDNSAddr(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	return ok && addrmgr.IsCJDNS(&wire.NetAddress{IP: tcpAddr.IP})
+}
+
 // ltcdLookup resolves the IP of the given host using the correct DNS lookup
 // function depending on the configuration options.  For example, addresses will
 // be resolved using tor when the --proxy flag was specified unless --noonion
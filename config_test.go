@@ -4,9 +4,13 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
 	"testing"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 )
 
 var (
@@ -70,3 +74,136 @@ func TestCreateDefaultConfigFile(t *testing.T) {
 		t.Error("Could not find rpcpass in generated default config file.")
 	}
 }
+
+// TestParseAssumeValid ensures the assumed valid block option resolves to the
+// expected block for each of its accepted forms.
+func TestParseAssumeValid(t *testing.T) {
+	params := &chaincfg.TestNet4Params
+	checkpointHash := params.Checkpoints[0].Hash.String()
+	added := chaincfg.Checkpoint{Height: 200000, Hash: &chainhash.Hash{0x01}}
+	zeroHash := chainhash.Hash{}.String()
+
+	tests := []struct {
+		name        string
+		assumeValid string
+		want        *chaincfg.Checkpoint
+		wantErr     bool
+	}{
+		{
+			name: "network default",
+			want: &params.AssumeValid,
+		},
+		{
+			name:        "zero hash",
+			assumeValid: zeroHash,
+		},
+		{
+			name:        "zero hash with height",
+			assumeValid: "100:" + zeroHash,
+		},
+		{
+			name:        "hash of built-in checkpoint",
+			assumeValid: checkpointHash,
+			want:        &params.Checkpoints[0],
+		},
+		{
+			name:        "hash of added checkpoint",
+			assumeValid: added.Hash.String(),
+			want:        &added,
+		},
+		{
+			name:        "height and hash",
+			assumeValid: "100:" + checkpointHash,
+			want: &chaincfg.Checkpoint{
+				Height: 100,
+				Hash:   params.Checkpoints[0].Hash,
+			},
+		},
+		{
+			name:        "hash with unknown height",
+			assumeValid: chainhash.Hash{0x02}.String(),
+			wantErr:     true,
+		},
+		{
+			name:        "malformed hash",
+			assumeValid: "xyz",
+			wantErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		got, err := parseAssumeValid(test.assumeValid, params,
+			[]chaincfg.Checkpoint{added})
+		if (err != nil) != test.wantErr {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+// TestRPCServerEnabled ensures the RPC server is only enabled when credentials
+// are configured or the interfaces to listen on are specified while the cookie
+// is enabled.
+func TestRPCServerEnabled(t *testing.T) {
+	listeners := []string{"127.0.0.1:19334"}
+	tests := []struct {
+		name string
+		cfg  config
+		want bool
+	}{
+		{
+			name: "nothing configured",
+			want: false,
+		},
+		{
+			name: "admin credentials",
+			cfg:  config{RPCUser: "user", RPCPass: "pass"},
+			want: true,
+		},
+		{
+			name: "admin user without password",
+			cfg:  config{RPCUser: "user"},
+			want: false,
+		},
+		{
+			name: "limited credentials",
+			cfg:  config{RPCLimitUser: "user", RPCLimitPass: "pass"},
+			want: true,
+		},
+		{
+			name: "scoped user",
+			cfg: config{rpcScopedUsers: []rpcScopedUser{{
+				name:     "user",
+				password: "pass",
+			}}},
+			want: true,
+		},
+		{
+			name: "listeners with only the cookie",
+			cfg:  config{RPCListeners: listeners},
+			want: true,
+		},
+		{
+			name: "listeners with the cookie disabled",
+			cfg: config{RPCListeners: listeners,
+				DisableRPCCookie: true},
+			want: false,
+		},
+		{
+			name: "credentials with the rpc server disabled",
+			cfg: config{RPCUser: "user", RPCPass: "pass",
+				RPCListeners: listeners, DisableRPC: true},
+			want: false,
+		},
+	}
+
+	for _, test := range tests {
+		if got := rpcServerEnabled(&test.cfg); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got,
+				test.want)
+		}
+	}
+}
@@ -60,6 +60,10 @@ type ConnReq struct {
 	Addr      net.Addr
 	Permanent bool
 
+	// BlockRelayOnly marks connections which only relay blocks and do not
+	// participate in the relay of transactions or addresses.
+	BlockRelayOnly bool
+
 	conn       net.Conn
 	state      ConnState
 	stateMtx   sync.RWMutex
@@ -123,6 +127,11 @@ type Config struct {
 	// maintain. Defaults to 8.
 	TargetOutbound uint32
 
+	// TargetBlockRelayOnly is the number of block-relay-only outbound
+	// network connections to maintain in addition to TargetOutbound.
+	// Unlike TargetOutbound, it has no default and zero disables them.
+	TargetBlockRelayOnly uint32
+
 	// RetryDuration is the duration to wait before retrying connection
 	// requests. Defaults to 5s.
 	RetryDuration time.Duration
@@ -201,10 +210,10 @@ func (cm *ConnManager) handleFailedConn(c *ConnReq) {
 				"-- retrying connection in: %v", maxFailedAttempts,
 				cm.cfg.RetryDuration)
 			time.AfterFunc(cm.cfg.RetryDuration, func() {
-				cm.NewConnReq()
+				cm.newConnReq(c.BlockRelayOnly)
 			})
 		} else {
-			go cm.NewConnReq()
+			go cm.newConnReq(c.BlockRelayOnly)
 		}
 	}
 }
@@ -249,7 +258,9 @@ out:
 						go cm.cfg.OnDisconnection(connReq)
 					}
 
-					if uint32(len(conns)) < cm.cfg.TargetOutbound && msg.retry {
+					if msg.retry && cm.needsConn(conns,
+						connReq.BlockRelayOnly) {
+
 						cm.handleFailedConn(connReq)
 					}
 				} else {
@@ -272,9 +283,32 @@ out:
 	log.Trace("Connection handler done")
 }
 
+// needsConn returns whether fewer of the passed connections than targeted are
+// of the passed class, block-relay-only or not.  Permanent connections count
+// toward the target of the connections which are not block-relay-only.
+func (cm *ConnManager) needsConn(conns map[uint64]*ConnReq, blockRelayOnly bool) bool {
+	target := cm.cfg.TargetOutbound
+	if blockRelayOnly {
+		target = cm.cfg.TargetBlockRelayOnly
+	}
+	var count uint32
+	for _, c := range conns {
+		if c.BlockRelayOnly == blockRelayOnly {
+			count++
+		}
+	}
+	return count < target
+}
+
 // NewConnReq creates a new connection request and connects to the
 // corresponding address.
 func (cm *ConnManager) NewConnReq() {
+	cm.newConnReq(false)
+}
+
+// newConnReq creates a new connection request of the passed class,
+// block-relay-only or not, and connects to the corresponding address.
+func (cm *ConnManager) newConnReq(blockRelayOnly bool) {
 	if atomic.LoadInt32(&cm.stop) != 0 {
 		return
 	}
@@ -282,7 +316,7 @@ func (cm *ConnManager) NewConnReq() {
 		return
 	}
 
-	c := &ConnReq{}
+	c := &ConnReq{BlockRelayOnly: blockRelayOnly}
 	atomic.StoreUint64(&c.id, atomic.AddUint64(&cm.connReqCount, 1))
 
 	addr, err := cm.cfg.GetNewAddress()
@@ -376,6 +410,9 @@ func (cm *ConnManager) Start() {
 	for i := atomic.LoadUint64(&cm.connReqCount); i < uint64(cm.cfg.TargetOutbound); i++ {
 		go cm.NewConnReq()
 	}
+	for i := uint32(0); i < cm.cfg.TargetBlockRelayOnly; i++ {
+		go cm.newConnReq(true)
+	}
 }
 
 // Wait blocks until the connection manager halts gracefully.
@@ -215,6 +215,64 @@ func TestTargetOutbound(t *testing.T) {
 	cmgr.Stop()
 }
 
+// TestTargetBlockRelayOnly tests that the target number of block-relay-only
+// connections is maintained in addition to the target outbound connections,
+// and that a disconnected block-relay-only connection is replaced by another
+// one.
+func TestTargetBlockRelayOnly(t *testing.T) {
+	targetOutbound := uint32(3)
+	targetBlockRelayOnly := uint32(2)
+	connected := make(chan *ConnReq)
+	cmgr, err := New(&Config{
+		TargetOutbound:       targetOutbound,
+		TargetBlockRelayOnly: targetBlockRelayOnly,
+		Dial:                 mockDialer,
+		GetNewAddress: func() (net.Addr, error) {
+			return &net.TCPAddr{
+				IP:   net.ParseIP("127.0.0.1"),
+				Port: 18555,
+			}, nil
+		},
+		OnConnection: func(c *ConnReq, conn net.Conn) {
+			connected <- c
+		},
+	})
+	if err != nil {
+		t.Fatalf("New error: %v", err)
+	}
+	cmgr.Start()
+	defer cmgr.Stop()
+
+	var blockRelayOnly []*ConnReq
+	for i := uint32(0); i < targetOutbound+targetBlockRelayOnly; i++ {
+		c := <-connected
+		if c.BlockRelayOnly {
+			blockRelayOnly = append(blockRelayOnly, c)
+		}
+	}
+	if uint32(len(blockRelayOnly)) != targetBlockRelayOnly {
+		t.Fatalf("got %d block-relay-only connections, want %d",
+			len(blockRelayOnly), targetBlockRelayOnly)
+	}
+	select {
+	case c := <-connected:
+		t.Fatalf("target block-relay-only: got unexpected connection "+
+			"- %v", c.Addr)
+	case <-time.After(time.Millisecond):
+	}
+
+	cmgr.Disconnect(blockRelayOnly[0].ID())
+	select {
+	case c := <-connected:
+		if !c.BlockRelayOnly {
+			t.Fatal("block-relay-only connection replaced by a " +
+				"regular one")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("block-relay-only connection was not replaced")
+	}
+}
+
 // TestRetryPermanent tests that permanent connection requests are retried.
 //
 // We make a permanent connection request using Connect, disconnect it using
@@ -59,14 +59,46 @@ func decayFactor(t int64) float64 {
 // DynamicBanScore allows these two approaches to be used in tandem.
 //
 // Zero value: Values of type DynamicBanScore are immediately ready for use upon
-// declaration.
+// declaration and decay with the default Halflife.
 type DynamicBanScore struct {
 	lastUnix   int64
 	transient  float64
 	persistent uint32
+	halflife   int64
 	mtx        sync.Mutex
 }
 
+// NewDynamicBanScore returns a new dynamic ban score whose decaying score
+// decays to one half of its value after the provided duration.  The duration
+// is truncated to whole seconds and a duration of less than one second
+// results in the default Halflife.
+func NewDynamicBanScore(halflife time.Duration) *DynamicBanScore {
+	s := &DynamicBanScore{halflife: int64(halflife / time.Second)}
+	if s.halflife == Halflife {
+		s.halflife = 0
+	}
+	return s
+}
+
+// decayFactor returns the decay factor of the decaying score at t seconds.
+// The precalculated values are used when the score decays with the default
+// Halflife.
+func (s *DynamicBanScore) decayFactor(t int64) float64 {
+	if s.halflife <= 0 {
+		return decayFactor(t)
+	}
+	return math.Exp(-1.0 * float64(t) * math.Ln2 / float64(s.halflife))
+}
+
+// lifetime returns the maximum age of the decaying score to be considered a
+// non-zero score (in seconds).  It scales with the halflife of the score.
+func (s *DynamicBanScore) lifetime() int64 {
+	if s.halflife <= 0 {
+		return Lifetime
+	}
+	return Lifetime * s.halflife / Halflife
+}
+
 // String returns the ban score as a human-readable string.
 func (s *DynamicBanScore) String() string {
 	s.mtx.Lock()
@@ -116,10 +148,10 @@ func (s *DynamicBanScore) Reset() {
 // internally and during testing.
 func (s *DynamicBanScore) int(t time.Time) uint32 {
 	dt := t.Unix() - s.lastUnix
-	if s.transient < 1 || dt < 0 || Lifetime < dt {
+	if s.transient < 1 || dt < 0 || s.lifetime() < dt {
 		return s.persistent
 	}
-	return s.persistent + uint32(s.transient*decayFactor(dt))
+	return s.persistent + uint32(s.transient*s.decayFactor(dt))
 }
 
 // increase increases the persistent, the decaying or both scores by the values
@@ -134,10 +166,10 @@ func (s *DynamicBanScore) increase(persistent, transient uint32, t time.Time) ui
 	dt := tu - s.lastUnix
 
 	if transient > 0 {
-		if Lifetime < dt {
+		if s.lifetime() < dt {
 			s.transient = 0
 		} else if s.transient > 1 && dt > 0 {
-			s.transient *= decayFactor(dt)
+			s.transient *= s.decayFactor(dt)
 		}
 		s.transient += float64(transient)
 		s.lastUnix = tu
@@ -66,3 +66,39 @@ func TestDynamicBanScoreReset(t *testing.T) {
 		t.Errorf("Failed to reset ban score.")
 	}
 }
+
+// TestDynamicBanScoreHalflife tests that the decaying score of a DynamicBanScore
+// created with a custom halflife decays and expires accordingly.
+func TestDynamicBanScoreHalflife(t *testing.T) {
+	bs := NewDynamicBanScore(10 * time.Second)
+	base := time.Now()
+
+	r := bs.increase(100, 80, base)
+	if r != 180 {
+		t.Errorf("Unexpected result %d after ban score increase.", r)
+	}
+
+	r = bs.int(base.Add(10 * time.Second))
+	if r != 140 {
+		t.Errorf("Halflife check failed - %d instead of 140", r)
+	}
+
+	r = bs.int(base.Add(20 * time.Second))
+	if r != 120 {
+		t.Errorf("Decay after 20s - %d instead of 120", r)
+	}
+
+	// The lifetime scales with the halflife.
+	r = bs.int(base.Add((Lifetime/6 + 1) * time.Second))
+	if r != 100 {
+		t.Errorf("Zero after max age check failed - %d instead of 100", r)
+	}
+
+	// Durations of less than a second use the default halflife.
+	bs = NewDynamicBanScore(0)
+	bs.increase(0, 50, base)
+	r = bs.int(base.Add(time.Minute))
+	if r != 25 {
+		t.Errorf("Default halflife check failed - %d instead of 25", r)
+	}
+}
@@ -0,0 +1,397 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// i2pSessionTimeout is the time allowed for the SAM bridge to create
+	// a session, which includes building the tunnels of the session.
+	i2pSessionTimeout = 3 * time.Minute
+
+	// i2pConnectTimeout is the time allowed for the SAM bridge to look up
+	// and connect to a destination.
+	i2pConnectTimeout = 2 * time.Minute
+
+	// i2pSuffix is the suffix of the base32 addresses of I2P destinations.
+	i2pSuffix = ".b32.i2p"
+)
+
+var (
+	// ErrI2PSessionClosed is returned when dialing or accepting with an I2P
+	// session which has been closed.
+	ErrI2PSessionClosed = errors.New("i2p session closed")
+
+	// i2pRetryInterval is the time Accept waits before creating a session
+	// again after the SAM bridge failed to create one.
+	i2pRetryInterval = 30 * time.Second
+
+	// i2pBase64 is the base64 encoding I2P uses for destinations.
+	i2pBase64 = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZ" +
+		"abcdefghijklmnopqrstuvwxyz0123456789-~")
+)
+
+// I2PAddr implements the net.Addr interface and represents the base32 address
+// of an I2P destination.  I2P streams do not have ports, so the address always
+// has the port 0.
+type I2PAddr struct {
+	Host string
+}
+
+// Network returns "i2p".  This is part of the net.Addr interface.
+func (a *I2PAddr) Network() string {
+	return "i2p"
+}
+
+// String returns the host along with the port 0.  This is part of the net.Addr
+// interface.
+func (a *I2PAddr) String() string {
+	return net.JoinHostPort(a.Host, "0")
+}
+
+// Ensure I2PAddr implements the net.Addr interface.
+var _ net.Addr = (*I2PAddr)(nil)
+
+// i2pHost returns the base32 address of the passed I2P base64 encoded
+// destination, which is the hash of the destination.
+func i2pHost(dest string) (string, error) {
+	data, err := i2pBase64.DecodeString(dest)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	host := base32.StdEncoding.EncodeToString(hash[:])
+	return strings.ToLower(strings.TrimRight(host, "=")) + i2pSuffix, nil
+}
+
+// i2pConn wraps a stream accepted or dialed through the SAM bridge.  Reads go
+// through the reader used for the SAM handshake since it may have buffered
+// data of the stream.
+type i2pConn struct {
+	net.Conn
+	r      *bufio.Reader
+	local  net.Addr
+	remote net.Addr
+}
+
+// Read reads data from the stream.  This is part of the net.Conn interface.
+func (c *i2pConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// LocalAddr returns the address of the session.  This is part of the net.Conn
+// interface.
+func (c *i2pConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+// RemoteAddr returns the address of the remote destination.  This is part of
+// the net.Conn interface.
+func (c *i2pConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// samConn is a connection to the SAM bridge which has completed the HELLO
+// handshake.
+type samConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// command sends the passed SAM command and returns the reply after ensuring
+// it is the expected one and reports success.
+func (c *samConn) command(cmd, wantReply string) (map[string]string, error) {
+	if _, err := c.Write([]byte(cmd + "\n")); err != nil {
+		return nil, err
+	}
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, wantReply+" ") {
+		return nil, fmt.Errorf("unexpected i2p SAM reply %q", line)
+	}
+	reply := make(map[string]string)
+	for _, field := range strings.Fields(line[len(wantReply):]) {
+		if i := strings.Index(field, "="); i > 0 {
+			reply[field[:i]] = field[i+1:]
+		}
+	}
+	if reply["RESULT"] != "OK" {
+		return nil, fmt.Errorf("i2p SAM %s failed: %s", wantReply, line)
+	}
+	return reply, nil
+}
+
+// I2PSession is a stream session of a SAM v3 bridge, which is used to dial I2P
+// destinations and to accept streams sent to the destination of the session.
+// It implements the net.Listener interface so it can be served along with the
+// other listeners.
+//
+// The session is created on first use, and created again whenever the bridge
+// closes it.  Its destination is transient, so it changes each time.
+type I2PSession struct {
+	samAddr   string
+	onSession func(addr *I2PAddr)
+	quit      chan struct{}
+
+	mtx       sync.Mutex
+	id        string
+	addr      *I2PAddr
+	control   net.Conn
+	accepting net.Conn
+	closed    bool
+}
+
+// Ensure I2PSession implements the net.Listener interface.
+var _ net.Listener = (*I2PSession)(nil)
+
+// NewI2PSession returns an I2P session using the SAM v3 bridge at the passed
+// address.  It does not connect to the bridge until it is used.  The optional
+// onSession callback is invoked with the address of the session each time it
+// is created and must not use the session.
+func NewI2PSession(samAddr string, onSession func(addr *I2PAddr)) *I2PSession {
+	return &I2PSession{
+		samAddr:   samAddr,
+		onSession: onSession,
+		quit:      make(chan struct{}),
+	}
+}
+
+// connect returns a connection to the SAM bridge which has completed the HELLO
+// handshake.
+func (s *I2PSession) connect(timeout time.Duration) (*samConn, error) {
+	conn, err := net.DialTimeout("tcp", s.samAddr, timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+	c := &samConn{Conn: conn, r: bufio.NewReader(conn)}
+	_, err = c.command("HELLO VERSION MIN=3.1 MAX=3.1", "HELLO REPLY")
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// session returns the ID and address of the session, creating it first when
+// there is none.
+func (s *I2PSession) session() (string, *I2PAddr, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.closed {
+		return "", nil, ErrI2PSessionClosed
+	}
+	if s.control != nil {
+		return s.id, s.addr, nil
+	}
+
+	var b [5]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", nil, err
+	}
+	id := hex.EncodeToString(b[:])
+
+	c, err := s.connect(i2pSessionTimeout)
+	if err != nil {
+		return "", nil, err
+	}
+	_, err = c.command("SESSION CREATE STYLE=STREAM ID="+id+
+		" DESTINATION=TRANSIENT SIGNATURE_TYPE=7", "SESSION STATUS")
+	if err != nil {
+		c.Close()
+		return "", nil, err
+	}
+	reply, err := c.command("NAMING LOOKUP NAME=ME", "NAMING REPLY")
+	if err != nil {
+		c.Close()
+		return "", nil, err
+	}
+	host, err := i2pHost(reply["VALUE"])
+	if err != nil {
+		c.Close()
+		return "", nil, err
+	}
+	c.SetDeadline(time.Time{})
+
+	s.id = id
+	s.addr = &I2PAddr{Host: host}
+	s.control = c
+	log.Infof("Created I2P session %s with address %s", id, host)
+	if s.onSession != nil {
+		s.onSession(s.addr)
+	}
+
+	// The session lasts as long as the control connection, so forget it
+	// once the bridge closes the connection in order to create a new one
+	// on next use.
+	go func() {
+		for {
+			if _, err := c.r.ReadString('\n'); err != nil {
+				break
+			}
+		}
+		c.Close()
+		s.mtx.Lock()
+		if s.control == c {
+			s.control = nil
+		}
+		s.mtx.Unlock()
+	}()
+
+	return s.id, s.addr, nil
+}
+
+// Dial connects to the I2P destination with the passed base32 address.
+func (s *I2PSession) Dial(host string) (net.Conn, error) {
+	if !strings.HasSuffix(host, i2pSuffix) {
+		return nil, fmt.Errorf("%s is not an i2p address", host)
+	}
+	id, addr, err := s.session()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := s.connect(i2pConnectTimeout)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := c.command("NAMING LOOKUP NAME="+host, "NAMING REPLY")
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	_, err = c.command("STREAM CONNECT ID="+id+" DESTINATION="+
+		reply["VALUE"]+" SILENT=false", "STREAM STATUS")
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.SetDeadline(time.Time{})
+
+	return &i2pConn{
+		Conn:   c.Conn,
+		r:      c.r,
+		local:  addr,
+		remote: &I2PAddr{Host: host},
+	}, nil
+}
+
+// Accept waits for and returns the next stream sent to the destination of the
+// session.  When the session can't be created, it waits before trying again so
+// an unavailable bridge is not hammered.  This is part of the net.Listener
+// interface.
+func (s *I2PSession) Accept() (net.Conn, error) {
+	id, addr, err := s.session()
+	if err != nil {
+		if err != ErrI2PSessionClosed {
+			select {
+			case <-time.After(i2pRetryInterval):
+			case <-s.quit:
+			}
+		}
+		return nil, err
+	}
+
+	c, err := s.connect(i2pSessionTimeout)
+	if err != nil {
+		return nil, err
+	}
+	_, err = c.command("STREAM ACCEPT ID="+id+" SILENT=false",
+		"STREAM STATUS")
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	// Wait for a stream without a deadline, which is cut short by Close.
+	c.SetDeadline(time.Time{})
+	s.mtx.Lock()
+	if s.closed {
+		s.mtx.Unlock()
+		c.Close()
+		return nil, ErrI2PSessionClosed
+	}
+	s.accepting = c
+	s.mtx.Unlock()
+
+	// The destination of the remote peer is sent before the stream,
+	// followed by the ports.
+	line, err := c.r.ReadString('\n')
+	s.mtx.Lock()
+	s.accepting = nil
+	s.mtx.Unlock()
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		c.Close()
+		return nil, fmt.Errorf("unexpected i2p SAM reply %q", line)
+	}
+	host, err := i2pHost(fields[0])
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return &i2pConn{
+		Conn:   c.Conn,
+		r:      c.r,
+		local:  addr,
+		remote: &I2PAddr{Host: host},
+	}, nil
+}
+
+// Close closes the session, which unblocks a pending Accept.  This is part of
+// the net.Listener interface.
+func (s *I2PSession) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.quit)
+	if s.accepting != nil {
+		s.accepting.Close()
+	}
+	if s.control != nil {
+		s.control.Close()
+		s.control = nil
+	}
+	return nil
+}
+
+// Addr returns the address of the session, or the address of the SAM bridge
+// when the session has not been created yet.  This is part of the
+// net.Listener interface.
+func (s *I2PSession) Addr() net.Addr {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.addr != nil {
+		return s.addr
+	}
+	return &I2PAddr{Host: s.samAddr}
+}
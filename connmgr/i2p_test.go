@@ -0,0 +1,208 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockSAMBridge is a mock I2P SAM v3 bridge which knows a single remote
+// destination.  Streams connected to the remote destination echo back what is
+// written to them, and accepted streams are sent from the remote destination
+// and start with "hello".
+type mockSAMBridge struct {
+	listener   net.Listener
+	ownDest    string
+	remoteDest string
+	remoteHost string
+	commands   chan string
+}
+
+// newMockSAMBridge starts a mock SAM bridge which sends the session and stream
+// commands it receives to the commands channel.  The listener of the returned
+// bridge must be closed to stop it.
+func newMockSAMBridge(t *testing.T) *mockSAMBridge {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+
+	remote := bytes.Repeat([]byte{0x42}, 387)
+	hash := sha256.Sum256(remote)
+	b := &mockSAMBridge{
+		listener:   listener,
+		ownDest:    i2pBase64.EncodeToString(bytes.Repeat([]byte{0x24}, 387)),
+		remoteDest: i2pBase64.EncodeToString(remote),
+		remoteHost: strings.ToLower(strings.TrimRight(
+			base32.StdEncoding.EncodeToString(hash[:]), "=")) + ".b32.i2p",
+		commands: make(chan string, 10),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+// serve handles the commands of a single client of the mock bridge.
+func (b *mockSAMBridge) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimSpace(line)
+		var reply string
+		switch {
+		case strings.HasPrefix(cmd, "HELLO VERSION"):
+			reply = "HELLO REPLY RESULT=OK VERSION=3.1"
+
+		case strings.HasPrefix(cmd, "SESSION CREATE"):
+			b.commands <- cmd
+			reply = "SESSION STATUS RESULT=OK DESTINATION=" + b.ownDest
+
+		case cmd == "NAMING LOOKUP NAME=ME":
+			reply = "NAMING REPLY RESULT=OK NAME=ME VALUE=" + b.ownDest
+
+		case cmd == "NAMING LOOKUP NAME="+b.remoteHost:
+			reply = "NAMING REPLY RESULT=OK NAME=" + b.remoteHost +
+				" VALUE=" + b.remoteDest
+
+		case strings.HasPrefix(cmd, "NAMING LOOKUP"):
+			reply = "NAMING REPLY RESULT=KEY_NOT_FOUND"
+
+		case strings.HasPrefix(cmd, "STREAM CONNECT"):
+			b.commands <- cmd
+			io.WriteString(conn, "STREAM STATUS RESULT=OK\n")
+			io.Copy(conn, r)
+			return
+
+		case strings.HasPrefix(cmd, "STREAM ACCEPT"):
+			b.commands <- cmd
+			io.WriteString(conn, "STREAM STATUS RESULT=OK\n"+
+				b.remoteDest+" FROM_PORT=0 TO_PORT=0\nhello")
+			io.Copy(conn, r)
+			return
+
+		default:
+			reply = "UNKNOWN"
+		}
+		io.WriteString(conn, reply+"\n")
+	}
+}
+
+// nextCommand returns the next command the mock bridge received.
+func (b *mockSAMBridge) nextCommand(t *testing.T) string {
+	select {
+	case cmd := <-b.commands:
+		return cmd
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for SAM command")
+	}
+	return ""
+}
+
+// TestI2PSession ensures an I2P session is created through the SAM bridge on
+// first use and is used to dial destinations and accept streams.
+func TestI2PSession(t *testing.T) {
+	bridge := newMockSAMBridge(t)
+	defer bridge.listener.Close()
+
+	var sessions []string
+	s := NewI2PSession(bridge.listener.Addr().String(), func(addr *I2PAddr) {
+		sessions = append(sessions, addr.Host)
+	})
+	defer s.Close()
+
+	// Dialing creates the session and connects a stream with it.
+	conn, err := s.Dial(bridge.remoteHost)
+	if err != nil {
+		t.Fatalf("Dial: unexpected error: %v", err)
+	}
+	defer conn.Close()
+	create := bridge.nextCommand(t)
+	if !strings.HasPrefix(create, "SESSION CREATE STYLE=STREAM ID=") ||
+		!strings.Contains(create, "DESTINATION=TRANSIENT") {
+
+		t.Fatalf("unexpected session command %q", create)
+	}
+	id := strings.Fields(create)[3]
+	want := "STREAM CONNECT " + id + " DESTINATION=" + bridge.remoteDest +
+		" SILENT=false"
+	if connect := bridge.nextCommand(t); connect != want {
+		t.Fatalf("unexpected connect command %q, want %q", connect, want)
+	}
+	if addr := conn.RemoteAddr().String(); addr != bridge.remoteHost+":0" {
+		t.Fatalf("RemoteAddr: got %s, want %s:0", addr, bridge.remoteHost)
+	}
+	if _, err := io.WriteString(conn, "ping"); err != nil {
+		t.Fatalf("Write: unexpected error: %v", err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("Read: got %q (err %v), want ping", buf, err)
+	}
+
+	// The session reports its own address once created.
+	ownHost, _ := i2pHost(bridge.ownDest)
+	if addr := s.Addr().String(); addr != ownHost+":0" {
+		t.Fatalf("Addr: got %s, want %s:0", addr, ownHost)
+	}
+	if len(sessions) != 1 || sessions[0] != ownHost {
+		t.Fatalf("onSession: got sessions %v, want [%s]", sessions,
+			ownHost)
+	}
+
+	// Accepting reuses the session and returns streams sent by the remote
+	// destination along with the data which followed it.
+	accepted, err := s.Accept()
+	if err != nil {
+		t.Fatalf("Accept: unexpected error: %v", err)
+	}
+	defer accepted.Close()
+	if accept := bridge.nextCommand(t); accept != "STREAM ACCEPT "+id+" SILENT=false" {
+		t.Fatalf("unexpected accept command %q", accept)
+	}
+	if addr := accepted.RemoteAddr().String(); addr != bridge.remoteHost+":0" {
+		t.Fatalf("RemoteAddr: got %s, want %s:0", addr, bridge.remoteHost)
+	}
+	buf = make([]byte, 5)
+	if _, err := io.ReadFull(accepted, buf); err != nil || string(buf) != "hello" {
+		t.Fatalf("Read: got %q (err %v), want hello", buf, err)
+	}
+
+	// Unknown destinations and other networks can't be dialed.
+	unknown := strings.Repeat("a", 52) + ".b32.i2p"
+	if _, err := s.Dial(unknown); err == nil {
+		t.Fatalf("Dial: connected to unknown destination %s", unknown)
+	}
+	if _, err := s.Dial("example.com"); err == nil {
+		t.Fatal("Dial: connected to non-i2p address")
+	}
+
+	// Nothing can be dialed or accepted once the session is closed.
+	s.Close()
+	if _, err := s.Dial(bridge.remoteHost); err != ErrI2PSessionClosed {
+		t.Fatalf("Dial: got error %v, want %v", err, ErrI2PSessionClosed)
+	}
+	if _, err := s.Accept(); err != ErrI2PSessionClosed {
+		t.Fatalf("Accept: got error %v, want %v", err, ErrI2PSessionClosed)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	mrand "math/rand"
 	"net"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ltcsuite/ltcd/chaincfg"
@@ -33,6 +35,70 @@ type LookupFunc func(string) ([]net.IP, error)
 func SeedFromDNS(chainParams *chaincfg.Params, reqServices wire.ServiceFlag,
 	lookupFn LookupFunc, seedFn OnSeed) {
 
+	seedFromDNS(chainParams, reqServices, lookupFn, seedFn, func() {})
+}
+
+// SeedFromDNSWithFallback is like SeedFromDNS except it waits for all of the
+// DNS seeds to be queried and, when none of them returned any addresses,
+// populates the address manager with the fixed seeds of the network instead.
+// It blocks until seeding is complete.
+func SeedFromDNSWithFallback(chainParams *chaincfg.Params,
+	reqServices wire.ServiceFlag, lookupFn LookupFunc, seedFn OnSeed) {
+
+	var wg sync.WaitGroup
+	var numFound int32
+	wg.Add(len(chainParams.DNSSeeds))
+	seedFromDNS(chainParams, reqServices, lookupFn,
+		func(addrs []*wire.NetAddress) {
+			atomic.AddInt32(&numFound, int32(len(addrs)))
+			seedFn(addrs)
+		}, wg.Done)
+	wg.Wait()
+
+	if atomic.LoadInt32(&numFound) != 0 {
+		return
+	}
+	addrs := FixedSeeds(chainParams)
+	if len(addrs) == 0 {
+		return
+	}
+	log.Infof("No addresses found from DNS seeds -- using %d fixed seeds",
+		len(addrs))
+	seedFn(addrs)
+}
+
+// FixedSeeds returns the fixed seeds of the network as addresses with a last
+// seen time randomly selected between 3 and 7 days ago, just like the
+// addresses found from DNS seeds.  Malformed seeds are skipped.
+func FixedSeeds(chainParams *chaincfg.Params) []*wire.NetAddress {
+	randSource := mrand.New(mrand.NewSource(time.Now().UnixNano()))
+	addresses := make([]*wire.NetAddress, 0, len(chainParams.FixedSeeds))
+	for _, seed := range chainParams.FixedSeeds {
+		host, portStr, err := net.SplitHostPort(seed)
+		if err != nil {
+			log.Warnf("Invalid fixed seed %s: %v", seed, err)
+			continue
+		}
+		ip := net.ParseIP(host)
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if ip == nil || err != nil {
+			log.Warnf("Invalid fixed seed %s", seed)
+			continue
+		}
+		addresses = append(addresses, wire.NewNetAddressTimestamp(
+			time.Now().Add(-1*time.Second*time.Duration(secondsIn3Days+
+				randSource.Int31n(secondsIn4Days))),
+			0, ip, uint16(port)))
+	}
+	return addresses
+}
+
+// seedFromDNS queries each of the DNS seeds of the network in a separate
+// goroutine, passing the addresses found to the seed function and invoking
+// the done function once the query is complete.
+func seedFromDNS(chainParams *chaincfg.Params, reqServices wire.ServiceFlag,
+	lookupFn LookupFunc, seedFn OnSeed, done func()) {
+
 	for _, dnsseed := range chainParams.DNSSeeds {
 		var host string
 		if !dnsseed.HasFiltering || reqServices == wire.SFNodeNetwork {
@@ -42,6 +108,8 @@ func SeedFromDNS(chainParams *chaincfg.Params, reqServices wire.ServiceFlag,
 		}
 
 		go func(host string) {
+			defer done()
+
 			randSource := mrand.New(mrand.NewSource(time.Now().UnixNano()))
 
 			seedpeers, err := lookupFn(host)
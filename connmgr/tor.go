@@ -5,8 +5,11 @@
 package connmgr
 
 import (
+	"crypto/rand"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
+	"io"
 	"net"
 )
 
@@ -35,6 +38,10 @@ var (
 	// provided is not recognized.
 	ErrTorUnrecognizedAuthMethod = errors.New("invalid proxy authentication method")
 
+	// ErrTorAuthFailed indicates the Tor proxy rejected the provided
+	// credentials.
+	ErrTorAuthFailed = errors.New("proxy authentication failed")
+
 	torStatusErrors = map[byte]error{
 		torSucceeded:         errors.New("tor succeeded"),
 		torGeneralError:      errors.New("tor general error"),
@@ -52,13 +59,46 @@ var (
 // resolution over the Tor network. Tor itself doesn't support ipv6 so this
 // doesn't either.
 func TorLookupIP(host, proxy string) ([]net.IP, error) {
+	return torLookupIP(host, proxy, "", "")
+}
+
+// TorLookupIPIsolated is like TorLookupIP, except it authenticates to the proxy
+// with a unique set of random credentials.  Tor isolates streams which use
+// different credentials from each other, so each lookup is performed over a
+// separate circuit.
+func TorLookupIPIsolated(host, proxy string) ([]net.IP, error) {
+	username, password, err := torIsolationCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return torLookupIP(host, proxy, username, password)
+}
+
+// torIsolationCredentials returns a random username and password to
+// authenticate to a Tor proxy with in order to isolate a stream.
+func torIsolationCredentials() (string, string, error) {
+	var b [16]byte
+	if _, err := io.ReadFull(rand.Reader, b[:]); err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(b[:8]), hex.EncodeToString(b[8:]), nil
+}
+
+// torLookupIP resolves DNS via the Tor proxy at the provided address.  The
+// username/password authentication method is used when a username is
+// provided.
+func torLookupIP(host, proxy, username, password string) ([]net.IP, error) {
 	conn, err := net.Dial("tcp", proxy)
 	if err != nil {
 		return nil, err
 	}
 	defer conn.Close()
 
-	buf := []byte{'\x05', '\x01', '\x00'}
+	authMethod := byte('\x00')
+	if username != "" {
+		authMethod = '\x02'
+	}
+	buf := []byte{'\x05', '\x01', authMethod}
 	_, err = conn.Write(buf)
 	if err != nil {
 		return nil, err
@@ -72,10 +112,35 @@ func TorLookupIP(host, proxy string) ([]net.IP, error) {
 	if buf[0] != '\x05' {
 		return nil, ErrTorInvalidProxyResponse
 	}
-	if buf[1] != '\x00' {
+	if buf[1] != authMethod {
 		return nil, ErrTorUnrecognizedAuthMethod
 	}
 
+	// Authenticate with the provided credentials as defined by RFC1929.
+	if authMethod == '\x02' {
+		buf = make([]byte, 0, 3+len(username)+len(password))
+		buf = append(buf, '\x01', byte(len(username)))
+		buf = append(buf, username...)
+		buf = append(buf, byte(len(password)))
+		buf = append(buf, password...)
+		_, err = conn.Write(buf)
+		if err != nil {
+			return nil, err
+		}
+
+		buf = make([]byte, 2)
+		_, err = conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		if buf[0] != '\x01' {
+			return nil, ErrTorInvalidProxyResponse
+		}
+		if buf[1] != '\x00' {
+			return nil, ErrTorAuthFailed
+		}
+	}
+
 	buf = make([]byte, 7+len(host))
 	buf[0] = 5      // protocol version
 	buf[1] = '\xF0' // Tor Resolve
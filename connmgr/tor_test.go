@@ -0,0 +1,151 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package connmgr
+
+import (
+	"io"
+	"net"
+	"testing"
+)
+
+// mockTorCredentials houses the credentials a client authenticated to the mock
+// Tor proxy with.  Both are empty when no authentication was used.
+type mockTorCredentials struct {
+	username string
+	password string
+}
+
+// mockTorProxy starts a mock Tor SOCKS5 proxy which resolves every host to the
+// provided IP address and sends the credentials used by each client to the
+// returned channel.  The returned listener must be closed to stop the proxy.
+func mockTorProxy(t *testing.T, ip net.IP) (net.Listener, <-chan mockTorCredentials) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+
+	creds := make(chan mockTorCredentials, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			cred, err := serveMockTorConn(conn, ip)
+			conn.Close()
+			if err == nil {
+				creds <- cred
+			}
+		}
+	}()
+	return listener, creds
+}
+
+// serveMockTorConn handles a single resolve request from a client of the mock
+// Tor proxy and returns the credentials it authenticated with.
+func serveMockTorConn(conn net.Conn, ip net.IP) (mockTorCredentials, error) {
+	var cred mockTorCredentials
+
+	// Choose username/password authentication when offered.
+	buf := make([]byte, 2)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return cred, err
+	}
+	methods := make([]byte, buf[1])
+	if _, err := io.ReadFull(conn, methods); err != nil {
+		return cred, err
+	}
+	method := byte(0x00)
+	for _, m := range methods {
+		if m == 0x02 {
+			method = m
+		}
+	}
+	if _, err := conn.Write([]byte{0x05, method}); err != nil {
+		return cred, err
+	}
+	if method == 0x02 {
+		readString := func() (string, error) {
+			var length [1]byte
+			if _, err := io.ReadFull(conn, length[:]); err != nil {
+				return "", err
+			}
+			s := make([]byte, length[0])
+			_, err := io.ReadFull(conn, s)
+			return string(s), err
+		}
+		var version [1]byte
+		if _, err := io.ReadFull(conn, version[:]); err != nil {
+			return cred, err
+		}
+		var err error
+		if cred.username, err = readString(); err != nil {
+			return cred, err
+		}
+		if cred.password, err = readString(); err != nil {
+			return cred, err
+		}
+		if _, err := conn.Write([]byte{0x01, 0x00}); err != nil {
+			return cred, err
+		}
+	}
+
+	// Read the resolve request and respond with the IP address.
+	buf = make([]byte, 5)
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return cred, err
+	}
+	if _, err := io.ReadFull(conn, make([]byte, int(buf[4])+2)); err != nil {
+		return cred, err
+	}
+	reply := append([]byte{0x05, 0x00, 0x00, 0x01}, ip.To4()...)
+	reply = append(reply, 0x00, 0x00)
+	_, err := conn.Write(reply)
+	return cred, err
+}
+
+// TestTorLookupIP ensures DNS resolution via Tor returns the resolved address
+// and that isolated lookups authenticate with unique credentials.
+func TestTorLookupIP(t *testing.T) {
+	wantIP := net.IPv4(10, 1, 2, 3)
+	listener, creds := mockTorProxy(t, wantIP)
+	defer listener.Close()
+	proxy := listener.Addr().String()
+
+	// Lookups without isolation must not authenticate.
+	ips, err := TorLookupIP("seed.example.com", proxy)
+	if err != nil {
+		t.Fatalf("TorLookupIP: unexpected error: %v", err)
+	}
+	if len(ips) != 1 || !ips[0].Equal(wantIP) {
+		t.Fatalf("TorLookupIP: got %v, want %v", ips, wantIP)
+	}
+	if cred := <-creds; cred != (mockTorCredentials{}) {
+		t.Fatalf("TorLookupIP: got credentials %v, want none", cred)
+	}
+
+	// Each isolated lookup must use unique credentials.
+	seen := make(map[mockTorCredentials]struct{})
+	for i := 0; i < 5; i++ {
+		ips, err := TorLookupIPIsolated("seed.example.com", proxy)
+		if err != nil {
+			t.Fatalf("TorLookupIPIsolated: unexpected error: %v", err)
+		}
+		if len(ips) != 1 || !ips[0].Equal(wantIP) {
+			t.Fatalf("TorLookupIPIsolated: got %v, want %v", ips,
+				wantIP)
+		}
+		cred := <-creds
+		if cred.username == "" || cred.password == "" {
+			t.Fatalf("TorLookupIPIsolated: lookup %d did not "+
+				"authenticate", i)
+		}
+		if _, ok := seen[cred]; ok {
+			t.Fatalf("TorLookupIPIsolated: lookup %d reused "+
+				"credentials %v", i, cred)
+		}
+		seen[cred] = struct{}{}
+	}
+}
@@ -15,6 +15,8 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
@@ -168,6 +170,12 @@ type blockStore struct {
 	// new blocks are written to.
 	writeCursor *writeCursor
 
+	// firstFileNum is the number of the oldest block file which has not
+	// been pruned.  It is only modified once the block files pruned by a
+	// write transaction have been deleted, so it is safe to access during
+	// a write transaction without any additional locking.
+	firstFileNum uint32
+
 	// These functions are set to openFile, openWriteFile, and deleteFile by
 	// default, but are exposed here to allow the whitebox tests to replace
 	// them when working with mock files.
@@ -307,6 +315,45 @@ func (s *blockStore) deleteFile(fileNum uint32) error {
 	return nil
 }
 
+// deletePrunedFiles closes and removes the block files for the passed flat file
+// numbers, which must be the oldest block files in increasing order, and
+// advances the first block file number past those which were removed.
+//
+// The metadata which referenced the blocks in the files must already have been
+// updated, so any errors are simply logged at a warning level rather than
+// being returned.  Any file which fails to be removed, along with all of those
+// after it, will be removed the next time blocks are pruned since they no
+// longer contain any blocks.
+//
+// This function MUST only be called during a write transaction.
+func (s *blockStore) deletePrunedFiles(fileNums []uint32) {
+	for _, fileNum := range fileNums {
+		// Close the file if it is open under the write lock for the
+		// file to prevent it from being closed out from under any
+		// readers.
+		s.obfMutex.Lock()
+		if blockFile, ok := s.openBlockFiles[fileNum]; ok {
+			s.lruMutex.Lock()
+			s.openBlocksLRU.Remove(s.fileNumToLRUElem[fileNum])
+			delete(s.fileNumToLRUElem, fileNum)
+			s.lruMutex.Unlock()
+
+			blockFile.Lock()
+			_ = blockFile.file.Close()
+			blockFile.Unlock()
+			delete(s.openBlockFiles, fileNum)
+		}
+		s.obfMutex.Unlock()
+
+		if err := s.deleteFileFunc(fileNum); err != nil {
+			log.Warnf("Failed to delete pruned block file number "+
+				"%d: %v", fileNum, err)
+			return
+		}
+		s.firstFileNum = fileNum + 1
+	}
+}
+
 // blockFile attempts to return an existing file handle for the passed flat file
 // number if it is already open as well as marking it as most recently used.  It
 // will also open the file when it's not already open subject to the rules
@@ -713,14 +760,30 @@ func (s *blockStore) handleRollback(oldBlockFileNum, oldBlockOffset uint32) {
 }
 
 // scanBlockFiles searches the database directory for all flat block files to
-// find the end of the most recent file.  This position is considered the
-// current write cursor which is also stored in the metadata.  Thus, it is used
-// to detect unexpected shutdowns in the middle of writes so the block files
-// can be reconciled.
-func scanBlockFiles(dbPath string) (int, uint32) {
+// find the oldest file and the end of the most recent file.  The oldest file is
+// not necessarily the first one since older files might have been pruned.  The
+// end of the most recent file is considered the current write cursor which is
+// also stored in the metadata.  Thus, it is used to detect unexpected shutdowns
+// in the middle of writes so the block files can be reconciled.
+func scanBlockFiles(dbPath string) (int, int, uint32) {
+	// Find the oldest block file.  Any errors are ignored since it simply
+	// means there are no block files.
+	firstFile := 0
+	filePaths, _ := filepath.Glob(filepath.Join(dbPath, "*.fdb"))
+	for i, filePath := range filePaths {
+		fileName := strings.TrimSuffix(filepath.Base(filePath), ".fdb")
+		fileNum, err := strconv.ParseUint(fileName, 10, 32)
+		if err != nil {
+			continue
+		}
+		if i == 0 || int(fileNum) < firstFile {
+			firstFile = int(fileNum)
+		}
+	}
+
 	lastFile := -1
 	fileLen := uint32(0)
-	for i := 0; ; i++ {
+	for i := firstFile; ; i++ {
 		filePath := blockFilePath(dbPath, uint32(i))
 		st, err := os.Stat(filePath)
 		if err != nil {
@@ -731,9 +794,9 @@ func scanBlockFiles(dbPath string) (int, uint32) {
 		fileLen = uint32(st.Size())
 	}
 
-	log.Tracef("Scan found latest block file #%d with length %d", lastFile,
-		fileLen)
-	return lastFile, fileLen
+	log.Tracef("Scan found block files #%d to #%d with the latest of "+
+		"length %d", firstFile, lastFile, fileLen)
+	return firstFile, lastFile, fileLen
 }
 
 // newBlockStore returns a new block store with the current block file number
@@ -742,8 +805,9 @@ func newBlockStore(basePath string, network wire.BitcoinNet) *blockStore {
 	// Look for the end of the latest block to file to determine what the
 	// write cursor position is from the viewpoing of the block files on
 	// disk.
-	fileNum, fileOff := scanBlockFiles(basePath)
+	firstFileNum, fileNum, fileOff := scanBlockFiles(basePath)
 	if fileNum == -1 {
+		firstFileNum = 0
 		fileNum = 0
 		fileOff = 0
 	}
@@ -761,6 +825,7 @@ func newBlockStore(basePath string, network wire.BitcoinNet) *blockStore {
 			curFileNum: uint32(fileNum),
 			curOffset:  fileOff,
 		},
+		firstFileNum: uint32(firstFileNum),
 	}
 	store.openFileFunc = store.openFile
 	store.openWriteFileFunc = store.openWriteFile
@@ -74,6 +74,11 @@ var (
 	// writeLocKeyName is the key used to store the current write file
 	// location.
 	writeLocKeyName = []byte("ffldb-writeloc")
+
+	// prunedIdxBucketName is the bucket used internally to retain the
+	// headers of blocks which have been pruned.  It is created the first
+	// time blocks are pruned.
+	prunedIdxBucketName = []byte("ffldb-prunedidx")
 )
 
 // Common error strings.
@@ -970,6 +975,10 @@ type transaction struct {
 	pendingKeys   *treap.Mutable
 	pendingRemove *treap.Mutable
 
+	// Block files that need to be deleted once the metadata which no
+	// longer references them has been committed.
+	pendingPrunedFiles []uint32
+
 	// Active iterators that need to be notified when the pending keys have
 	// been updated so the cursors can properly handle updates to the
 	// transaction state.
@@ -1222,10 +1231,43 @@ func (tx *transaction) HasBlocks(hashes []chainhash.Hash) ([]bool, error) {
 	for i := range hashes {
 		results[i] = tx.hasBlock(&hashes[i])
 	}
-
 	return results, nil
 }
 
+// ForEachBlock invokes the passed function with the hash of every block which
+// exists in the database, including blocks pending to be written on commit.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Tx interface implementation.
+func (tx *transaction) ForEachBlock(fn func(hash *chainhash.Hash) error) error {
+	// Ensure transaction state is valid.
+	if err := tx.checkClosed(); err != nil {
+		return err
+	}
+
+	// The pending blocks exist from the viewpoint of this transaction, but
+	// are not added to the block index until commit.
+	for _, blockData := range tx.pendingBlockData {
+		hash := *blockData.hash
+		if err := fn(&hash); err != nil {
+			return err
+		}
+	}
+
+	cursor := tx.blockIdxBucket.Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		var hash chainhash.Hash
+		copy(hash[:], cursor.Key())
+		if err := fn(&hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // fetchBlockRow fetches the metadata stored in the block index for the provided
 // hash.  It will return ErrBlockNotFound if there is no entry.
 func (tx *transaction) fetchBlockRow(hash *chainhash.Hash) ([]byte, error) {
@@ -1238,6 +1280,21 @@ func (tx *transaction) fetchBlockRow(hash *chainhash.Hash) ([]byte, error) {
 	return blockRow, nil
 }
 
+// fetchPrunedBlockHeader returns the header retained for the pruned block with
+// the provided hash.  It will return nil if the block has not been pruned.
+func (tx *transaction) fetchPrunedBlockHeader(hash *chainhash.Hash) []byte {
+	prunedIdxBucket := tx.metaBucket.Bucket(prunedIdxBucketName)
+	if prunedIdxBucket == nil {
+		return nil
+	}
+
+	blockHdr := prunedIdxBucket.Get(hash[:])
+	if blockHdr == nil {
+		return nil
+	}
+	return blockHdr[0:blockHdrSize:blockHdrSize]
+}
+
 // FetchBlockHeader returns the raw serialized bytes for the block header
 // identified by the given hash.  The raw bytes are in the format returned by
 // Serialize on a wire.BlockHeader.
@@ -1269,9 +1326,13 @@ func (tx *transaction) FetchBlockHeader(hash *chainhash.Hash) ([]byte, error) {
 
 	// Fetch the block index row and slice off the header.  Notice the use
 	// of the cap on the subslice to prevent the caller from accidentally
-	// appending into the db data.
+	// appending into the db data.  The headers of pruned blocks are
+	// retained separately.
 	blockRow, err := tx.fetchBlockRow(hash)
 	if err != nil {
+		if blockHdr := tx.fetchPrunedBlockHeader(hash); blockHdr != nil {
+			return blockHdr, nil
+		}
 		return nil, err
 	}
 	endOffset := blockLocSize + blockHdrSize
@@ -1319,10 +1380,16 @@ func (tx *transaction) FetchBlockHeaders(hashes []chainhash.Hash) ([][]byte, err
 
 		// Fetch the block index row and slice off the header.  Notice
 		// the use of the cap on the subslice to prevent the caller
-		// from accidentally appending into the db data.
+		// from accidentally appending into the db data.  The headers
+		// of pruned blocks are retained separately.
 		blockRow, err := tx.fetchBlockRow(hash)
 		if err != nil {
-			return nil, err
+			blockHdr := tx.fetchPrunedBlockHeader(hash)
+			if blockHdr == nil {
+				return nil, err
+			}
+			headers[i] = blockHdr
+			continue
 		}
 		endOffset := blockLocSize + blockHdrSize
 		headers[i] = blockRow[blockLocSize:endOffset:endOffset]
@@ -1627,6 +1694,127 @@ func (tx *transaction) FetchBlockRegions(regions []database.BlockRegion) ([][]by
 	return blockRegions, nil
 }
 
+// PruneBlocks deletes the oldest flat block files until the total size of the
+// block files is no more than the provided target size in bytes.  The current
+// write file is never deleted and pruning stops at the first file which holds
+// a block the provided keep function returns true for.  The hashes of all of
+// the blocks in the deleted files are returned.
+//
+// The headers of the pruned blocks are retained, so they are still available
+// via FetchBlockHeader and FetchBlockHeaders, while HasBlock reports they no
+// longer exist and attempts to fetch any of their data return
+// ErrBlockNotFound.  The block files are not deleted until the transaction has
+// been committed.
+//
+// Returns the following errors as required by the interface contract:
+//   - ErrTxNotWritable if attempted against a read-only transaction
+//   - ErrTxClosed if the transaction has already been closed
+//
+// This function is part of the database.Tx interface implementation.
+func (tx *transaction) PruneBlocks(targetSize uint64, keep func(hash *chainhash.Hash) bool) ([]chainhash.Hash, error) {
+	// Ensure transaction state is valid.
+	if err := tx.checkClosed(); err != nil {
+		return nil, err
+	}
+
+	// Ensure the transaction is writable.
+	if !tx.writable {
+		str := "prune blocks requires a writable database transaction"
+		return nil, makeDbErr(database.ErrTxNotWritable, str, nil)
+	}
+
+	// Determine the total size of the block files which have not already
+	// been pruned.  All but the current write file are treated as full
+	// since they are only moved past when the next block doesn't fit.
+	//
+	// NOTE: The write cursor isn't modified outside of write transactions,
+	// of which there can be only one at a time, so it is safe to access
+	// here without the lock.
+	store := tx.db.store
+	wc := store.writeCursor
+	firstFileNum := store.firstFileNum
+	if n := len(tx.pendingPrunedFiles); n > 0 {
+		firstFileNum = tx.pendingPrunedFiles[n-1] + 1
+	}
+	maxFileSize := uint64(store.maxBlockFileSize)
+	totalSize := uint64(wc.curFileNum-firstFileNum)*maxFileSize +
+		uint64(wc.curOffset)
+	if totalSize <= targetSize {
+		return nil, nil
+	}
+
+	// Find the blocks held by each of the files which could be pruned.
+	fileBlocks := make(map[uint32][]chainhash.Hash)
+	cursor := tx.blockIdxBucket.Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		loc := deserializeBlockLoc(cursor.Value())
+		if loc.blockFileNum >= wc.curFileNum {
+			continue
+		}
+
+		var hash chainhash.Hash
+		copy(hash[:], cursor.Key())
+		fileBlocks[loc.blockFileNum] = append(
+			fileBlocks[loc.blockFileNum], hash)
+	}
+
+	// Prune the oldest files until the target is reached or a file which
+	// holds a block that needs to be kept is encountered.
+	var prunedFiles []uint32
+	var prunedHashes []chainhash.Hash
+	for fileNum := firstFileNum; fileNum < wc.curFileNum; fileNum++ {
+		if totalSize <= targetSize {
+			break
+		}
+
+		hashes := fileBlocks[fileNum]
+		var keepFile bool
+		for i := range hashes {
+			if keep(&hashes[i]) {
+				keepFile = true
+				break
+			}
+		}
+		if keepFile {
+			break
+		}
+
+		prunedFiles = append(prunedFiles, fileNum)
+		prunedHashes = append(prunedHashes, hashes...)
+		totalSize -= maxFileSize
+	}
+	if len(prunedFiles) == 0 {
+		return nil, nil
+	}
+
+	// Move the headers of the pruned blocks to the pruned block index so
+	// they are retained.
+	prunedIdxBucket, err := tx.metaBucket.CreateBucketIfNotExists(
+		prunedIdxBucketName)
+	if err != nil {
+		return nil, err
+	}
+	for i := range prunedHashes {
+		hash := &prunedHashes[i]
+		blockRow, err := tx.fetchBlockRow(hash)
+		if err != nil {
+			return nil, err
+		}
+		blockHdr := blockRow[blockHdrOffset : blockHdrOffset+blockHdrSize]
+		if err := prunedIdxBucket.Put(hash[:], blockHdr); err != nil {
+			return nil, err
+		}
+		if err := tx.blockIdxBucket.Delete(hash[:]); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Debugf("Pruning %d blocks in block files %d to %d",
+		len(prunedHashes), prunedFiles[0], prunedFiles[len(prunedFiles)-1])
+	tx.pendingPrunedFiles = append(tx.pendingPrunedFiles, prunedFiles...)
+	return prunedHashes, nil
+}
+
 // close marks the transaction closed then releases any pending data, the
 // underlying snapshot, the transaction read lock, and the write lock when the
 // transaction is writable.
@@ -1640,6 +1828,7 @@ func (tx *transaction) close() {
 	// Clear pending keys that would have been written or deleted on commit.
 	tx.pendingKeys = nil
 	tx.pendingRemove = nil
+	tx.pendingPrunedFiles = nil
 
 	// Release the snapshot.
 	if tx.snapshot != nil {
@@ -1694,6 +1883,7 @@ func (tx *transaction) writePendingAndCommit() error {
 	}
 
 	// Loop through all of the pending blocks to store and write them.
+	prunedIdxBucket := tx.metaBucket.Bucket(prunedIdxBucketName)
 	for _, blockData := range tx.pendingBlockData {
 		log.Tracef("Storing block %s", blockData.hash)
 		location, err := tx.db.store.writeBlock(blockData.bytes)
@@ -1713,6 +1903,16 @@ func (tx *transaction) writePendingAndCommit() error {
 			rollback()
 			return err
 		}
+
+		// Remove the retained header when a block which was previously
+		// pruned is stored again.
+		if prunedIdxBucket != nil {
+			err := prunedIdxBucket.Delete(blockData.hash[:])
+			if err != nil {
+				rollback()
+				return err
+			}
+		}
 	}
 
 	// Update the metadata for the current write file and offset.
@@ -1724,7 +1924,17 @@ func (tx *transaction) writePendingAndCommit() error {
 
 	// Atomically update the database cache.  The cache automatically
 	// handles flushing to the underlying persistent storage database.
-	return tx.db.cache.commitTx(tx)
+	prunedFiles := tx.pendingPrunedFiles
+	if err := tx.db.cache.commitTx(tx); err != nil {
+		return err
+	}
+
+	// Delete the block files which were pruned now that the metadata which
+	// no longer references them has been persisted.
+	if len(prunedFiles) > 0 {
+		tx.db.store.deletePrunedFiles(prunedFiles)
+	}
+	return nil
 }
 
 // Commit commits all changes that have been made to the root metadata bucket
@@ -2038,9 +2248,10 @@ func initDB(ldb *leveldb.DB) error {
 	return nil
 }
 
-// openDB opens the database at the provided path.  database.ErrDbDoesNotExist
-// is returned if the database doesn't exist and the create flag is not set.
-func openDB(dbPath string, network wire.BitcoinNet, create bool) (database.DB, error) {
+// openDB opens the database at the provided path with flat block files of up to
+// the provided maximum size.  database.ErrDbDoesNotExist is returned if the
+// database doesn't exist and the create flag is not set.
+func openDB(dbPath string, network wire.BitcoinNet, maxFileSize uint32, create bool) (database.DB, error) {
 	// Error if the database doesn't exist and the create flag is not set.
 	metadataDbPath := filepath.Join(dbPath, metadataDbName)
 	dbExists := fileExists(metadataDbPath)
@@ -2075,6 +2286,7 @@ func openDB(dbPath string, network wire.BitcoinNet, create bool) (database.DB, e
 	// database cache which wraps the underlying leveldb database to provide
 	// write caching.
 	store := newBlockStore(dbPath, network)
+	store.maxBlockFileSize = maxFileSize
 	cache := newDbCache(ldb, store, defaultCacheSize, defaultFlushSecs)
 	pdb := &db{store: store, cache: cache}
 
@@ -537,6 +537,13 @@ func (c *dbCache) needsFlush(tx *transaction) bool {
 		return true
 	}
 
+	// A flush is needed when block files have been pruned since the
+	// metadata which no longer references them must be persisted before
+	// the files are deleted.
+	if len(tx.pendingPrunedFiles) > 0 {
+		return true
+	}
+
 	// A flush is needed when the size of the database cache exceeds the
 	// specified max cache size.  The total calculated size is multiplied by
 	// 1.5 here to account for additional memory consumption that will be
@@ -14,7 +14,8 @@ Usage
 
 This package is a driver to the database package and provides the database type
 of "ffldb".  The parameters the Open and Create functions take are the
-database path as a string and the block network:
+database path as a string, the block network, and optionally the maximum size
+in bytes of the flat files which hold the blocks:
 
 	db, err := database.Open("ffldb", "path/to/database", wire.MainNet)
 	if err != nil {
@@ -18,49 +18,62 @@ const (
 	dbType = "ffldb"
 )
 
-// parseArgs parses the arguments from the database Open/Create methods.
-func parseArgs(funcName string, args ...interface{}) (string, wire.BitcoinNet, error) {
-	if len(args) != 2 {
-		return "", 0, fmt.Errorf("invalid arguments to %s.%s -- "+
-			"expected database path and block network", dbType,
-			funcName)
+// parseArgs parses the arguments from the database Open/Create methods.  The
+// maximum size of the flat block files may optionally be provided as a third
+// argument and defaults to maxBlockFileSize.
+func parseArgs(funcName string, args ...interface{}) (string, wire.BitcoinNet, uint32, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return "", 0, 0, fmt.Errorf("invalid arguments to %s.%s -- "+
+			"expected database path, block network, and "+
+			"optionally a max block file size", dbType, funcName)
 	}
 
 	dbPath, ok := args[0].(string)
 	if !ok {
-		return "", 0, fmt.Errorf("first argument to %s.%s is invalid -- "+
-			"expected database path string", dbType, funcName)
+		return "", 0, 0, fmt.Errorf("first argument to %s.%s is "+
+			"invalid -- expected database path string", dbType,
+			funcName)
 	}
 
 	network, ok := args[1].(wire.BitcoinNet)
 	if !ok {
-		return "", 0, fmt.Errorf("second argument to %s.%s is invalid -- "+
-			"expected block network", dbType, funcName)
+		return "", 0, 0, fmt.Errorf("second argument to %s.%s is "+
+			"invalid -- expected block network", dbType, funcName)
+	}
+
+	maxFileSize := maxBlockFileSize
+	if len(args) == 3 {
+		maxFileSize, ok = args[2].(uint32)
+		if !ok || maxFileSize == 0 {
+			return "", 0, 0, fmt.Errorf("third argument to %s.%s "+
+				"is invalid -- expected non-zero max block "+
+				"file size", dbType, funcName)
+		}
 	}
 
-	return dbPath, network, nil
+	return dbPath, network, maxFileSize, nil
 }
 
 // openDBDriver is the callback provided during driver registration that opens
 // an existing database for use.
 func openDBDriver(args ...interface{}) (database.DB, error) {
-	dbPath, network, err := parseArgs("Open", args...)
+	dbPath, network, maxFileSize, err := parseArgs("Open", args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return openDB(dbPath, network, false)
+	return openDB(dbPath, network, maxFileSize, false)
 }
 
 // createDBDriver is the callback provided during driver registration that
 // creates, initializes, and opens a database for use.
 func createDBDriver(args ...interface{}) (database.DB, error) {
-	dbPath, network, err := parseArgs("Create", args...)
+	dbPath, network, maxFileSize, err := parseArgs("Create", args...)
 	if err != nil {
 		return nil, err
 	}
 
-	return openDB(dbPath, network, true)
+	return openDB(dbPath, network, maxFileSize, true)
 }
 
 // useLogger is the callback provided during driver registration that sets the
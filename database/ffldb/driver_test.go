@@ -37,8 +37,9 @@ func TestCreateOpenFail(t *testing.T) {
 	// Ensure that attempting to open a database with the wrong number of
 	// parameters returns the expected error.
 	wantErr := fmt.Errorf("invalid arguments to %s.Open -- expected "+
-		"database path and block network", dbType)
-	_, err = database.Open(dbType, 1, 2, 3)
+		"database path, block network, and optionally a max block "+
+		"file size", dbType)
+	_, err = database.Open(dbType, 1, 2, 3, 4)
 	if err.Error() != wantErr.Error() {
 		t.Errorf("Open: did not receive expected error - got %v, "+
 			"want %v", err, wantErr)
@@ -67,11 +68,23 @@ func TestCreateOpenFail(t *testing.T) {
 		return
 	}
 
+	// Ensure that attempting to open a database with an invalid type for
+	// the third parameter returns the expected error.
+	wantErr = fmt.Errorf("third argument to %s.Open is invalid -- "+
+		"expected non-zero max block file size", dbType)
+	_, err = database.Open(dbType, "noexist", blockDataNet, "invalid")
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Open: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
 	// Ensure that attempting to create a database with the wrong number of
 	// parameters returns the expected error.
 	wantErr = fmt.Errorf("invalid arguments to %s.Create -- expected "+
-		"database path and block network", dbType)
-	_, err = database.Create(dbType, 1, 2, 3)
+		"database path, block network, and optionally a max block "+
+		"file size", dbType)
+	_, err = database.Create(dbType, 1, 2, 3, 4)
 	if err.Error() != wantErr.Error() {
 		t.Errorf("Create: did not receive expected error - got %v, "+
 			"want %v", err, wantErr)
@@ -100,6 +113,17 @@ func TestCreateOpenFail(t *testing.T) {
 		return
 	}
 
+	// Ensure that attempting to create a database with an invalid type for
+	// the third parameter returns the expected error.
+	wantErr = fmt.Errorf("third argument to %s.Create is invalid -- "+
+		"expected non-zero max block file size", dbType)
+	_, err = database.Create(dbType, "noexist", blockDataNet, "invalid")
+	if err.Error() != wantErr.Error() {
+		t.Errorf("Create: did not receive expected error - got %v, "+
+			"want %v", err, wantErr)
+		return
+	}
+
 	// Ensure operations against a closed database return the expected
 	// error.
 	dbPath := filepath.Join(os.TempDir(), "ffldb-createfail")
@@ -1390,6 +1390,29 @@ func testFetchBlockIO(tc *testContext, tx database.Tx) bool {
 		}
 	}
 
+	// Ensure every block is reported exactly once when iterating the
+	// blocks in the database.
+	unseen := make(map[chainhash.Hash]struct{}, len(allBlockHashes))
+	for i := range allBlockHashes {
+		unseen[allBlockHashes[i]] = struct{}{}
+	}
+	err = tx.ForEachBlock(func(hash *chainhash.Hash) error {
+		if _, ok := unseen[*hash]; !ok {
+			return fmt.Errorf("unexpected or duplicate block %s", hash)
+		}
+		delete(unseen, *hash)
+		return nil
+	})
+	if err != nil {
+		tc.t.Errorf("ForEachBlock: unexpected error: %v", err)
+		return false
+	}
+	if len(unseen) != 0 {
+		tc.t.Errorf("ForEachBlock: %d blocks were not reported",
+			len(unseen))
+		return false
+	}
+
 	// Ensure the bulk block headers fetched from the database match the
 	// expected bytes.
 	blockHeaderData, err := tx.FetchBlockHeaders(allBlockHashes)
@@ -1923,6 +1946,13 @@ func testClosedTxInterface(tc *testContext, tx database.Tx) bool {
 		return false
 	}
 
+	// Ensure ForEachBlock returns expected error.
+	testName = "ForEachBlock on closed tx"
+	err = tx.ForEachBlock(func(*chainhash.Hash) error { return nil })
+	if !checkDbError(tc.t, testName, err, wantErrCode) {
+		return false
+	}
+
 	// ---------------
 	// Commit/Rollback
 	// ---------------
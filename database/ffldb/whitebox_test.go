@@ -8,6 +8,7 @@
 package ffldb
 
 import (
+	"bytes"
 	"compress/bzip2"
 	"encoding/binary"
 	"fmt"
@@ -18,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 	"github.com/ltcsuite/ltcd/database"
 	"github.com/ltcsuite/ltcd/wire"
 	"github.com/ltcsuite/ltcutil"
@@ -179,7 +181,7 @@ func TestCornerCases(t *testing.T) {
 	// directory is needed.
 	testName := "openDB: fail due to file at target location"
 	wantErrCode := database.ErrDriverSpecific
-	idb, err := openDB(dbPath, blockDataNet, true)
+	idb, err := openDB(dbPath, blockDataNet, maxBlockFileSize, true)
 	if !checkDbError(t, testName, err, wantErrCode) {
 		if err == nil {
 			idb.Close()
@@ -191,7 +193,7 @@ func TestCornerCases(t *testing.T) {
 	// Remove the file and create the database to run tests against.  It
 	// should be successful this time.
 	_ = os.RemoveAll(dbPath)
-	idb, err = openDB(dbPath, blockDataNet, true)
+	idb, err = openDB(dbPath, blockDataNet, maxBlockFileSize, true)
 	if err != nil {
 		t.Errorf("openDB: unexpected error: %v", err)
 		return
@@ -704,3 +706,235 @@ func TestFailureScenarios(t *testing.T) {
 	// Test various corruption scenarios.
 	testCorruption(tc)
 }
+
+// TestPruneBlocks ensures pruning deletes the oldest block files while
+// honoring the target size and the blocks which need to be kept, retains the
+// headers of the pruned blocks, and that the database can be reopened after
+// its oldest block files have been deleted.
+func TestPruneBlocks(t *testing.T) {
+	t.Parallel()
+
+	// Create a chain of blocks which each contain a coinbase transaction
+	// padded to make the blocks a few hundred bytes.
+	const numBlocks = 100
+	blocks := make([]*ltcutil.Block, 0, numBlocks)
+	prevHash := *chaincfg.RegressionNetParams.GenesisHash
+	for i := 0; i < numBlocks; i++ {
+		coinbase := wire.NewMsgTx(1)
+		sigScript := make([]byte, 300)
+		binary.LittleEndian.PutUint32(sigScript, uint32(i))
+		coinbase.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex), sigScript, nil))
+		coinbase.AddTxOut(wire.NewTxOut(0, nil))
+
+		msgBlock := wire.NewMsgBlock(wire.NewBlockHeader(1, &prevHash,
+			&chainhash.Hash{}, 0, uint32(i)))
+		msgBlock.AddTransaction(coinbase)
+		block := ltcutil.NewBlock(msgBlock)
+		blocks = append(blocks, block)
+		prevHash = *block.Hash()
+	}
+
+	// Create a database with small block files so the test blocks span
+	// many of them.
+	const maxFileSize = 2048
+	dbPath := filepath.Join(os.TempDir(), "ffldb-pruneblocks")
+	_ = os.RemoveAll(dbPath)
+	idb, err := openDB(dbPath, blockDataNet, maxFileSize, true)
+	if err != nil {
+		t.Fatalf("openDB: unexpected error: %v", err)
+	}
+	defer os.RemoveAll(dbPath)
+	defer func() { idb.Close() }()
+
+	err = idb.Update(func(tx database.Tx) error {
+		for _, block := range blocks[:numBlocks] {
+			if err := tx.StoreBlock(block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error: %v", err)
+	}
+
+	// Ensure pruning requires a writable transaction.
+	err = idb.View(func(tx database.Tx) error {
+		_, err := tx.PruneBlocks(0, nil)
+		return err
+	})
+	if !checkDbError(t, "PruneBlocks", err, database.ErrTxNotWritable) {
+		return
+	}
+
+	// checkPruned ensures the first numPruned test blocks have been pruned
+	// while retaining their headers and the remaining blocks are still
+	// available.
+	checkPruned := func(numPruned int) {
+		err := idb.View(func(tx database.Tx) error {
+			for i, block := range blocks[:numBlocks] {
+				hash := block.Hash()
+				blockBytes, err := block.Bytes()
+				if err != nil {
+					return err
+				}
+				wantHdr := blockBytes[:blockHdrSize]
+				gotHdr, err := tx.FetchBlockHeader(hash)
+				if err != nil {
+					return fmt.Errorf("FetchBlockHeader #%d: %v",
+						i, err)
+				}
+				if !bytes.Equal(gotHdr, wantHdr) {
+					return fmt.Errorf("FetchBlockHeader #%d: "+
+						"unexpected header %x", i, gotHdr)
+				}
+
+				exists, err := tx.HasBlock(hash)
+				if err != nil {
+					return err
+				}
+				_, fetchErr := tx.FetchBlock(hash)
+				if i < numPruned {
+					if exists {
+						return fmt.Errorf("HasBlock #%d: "+
+							"pruned block exists", i)
+					}
+					if !checkDbError(t, "FetchBlock", fetchErr,
+						database.ErrBlockNotFound) {
+						return errSubTestFail
+					}
+					continue
+				}
+				if !exists || fetchErr != nil {
+					return fmt.Errorf("block #%d is unavailable "+
+						"(exists %v, err %v)", i, exists,
+						fetchErr)
+				}
+			}
+
+			// Only the blocks which were not pruned must be
+			// reported when iterating the stored blocks.
+			var numStored int
+			err := tx.ForEachBlock(func(hash *chainhash.Hash) error {
+				for _, block := range blocks[:numPruned] {
+					if *block.Hash() == *hash {
+						return fmt.Errorf("ForEachBlock: "+
+							"pruned block %s reported", hash)
+					}
+				}
+				numStored++
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if numStored != numBlocks-numPruned {
+				return fmt.Errorf("ForEachBlock: reported %d "+
+					"blocks, want %d", numStored,
+					numBlocks-numPruned)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("checkPruned: %v", err)
+		}
+	}
+
+	// prune prunes the blocks towards the provided target size while
+	// keeping the provided block and ensures the pruned blocks are the
+	// ones which follow the provided number of previously pruned blocks
+	// since the blocks are stored in order.
+	prune := func(targetSize uint64, keepHash *chainhash.Hash, numPruned int) int {
+		var pruned []chainhash.Hash
+		err := idb.Update(func(tx database.Tx) error {
+			var err error
+			pruned, err = tx.PruneBlocks(targetSize,
+				func(hash *chainhash.Hash) bool {
+					return keepHash != nil &&
+						*hash == *keepHash
+				})
+			return err
+		})
+		if err != nil {
+			t.Fatalf("PruneBlocks: unexpected error: %v", err)
+		}
+		if len(pruned) == 0 {
+			t.Fatal("PruneBlocks: did not prune any blocks")
+		}
+		prunedSet := make(map[chainhash.Hash]struct{}, len(pruned))
+		for i := range pruned {
+			prunedSet[pruned[i]] = struct{}{}
+		}
+		for i := numPruned; i < numPruned+len(pruned); i++ {
+			if _, ok := prunedSet[*blocks[i].Hash()]; !ok {
+				t.Fatalf("PruneBlocks: block #%d was not pruned",
+					i)
+			}
+		}
+		return numPruned + len(pruned)
+	}
+
+	// Prune towards a target of a few files while keeping one of the
+	// blocks so the pruning stops before the target is reached.
+	numPruned := prune(4*maxFileSize, blocks[60].Hash(), 0)
+	if numPruned > 60 {
+		t.Fatalf("PruneBlocks: pruned %d blocks including the block "+
+			"to keep", numPruned)
+	}
+	checkPruned(numPruned)
+	store := idb.(*db).store
+	firstFileNum := store.firstFileNum
+	for fileNum := uint32(0); fileNum <= firstFileNum; fileNum++ {
+		_, err := os.Stat(blockFilePath(dbPath, fileNum))
+		if exists := err == nil; exists != (fileNum == firstFileNum) {
+			t.Fatalf("block file %d exists %v after pruning to "+
+				"file %d", fileNum, exists, firstFileNum)
+		}
+	}
+
+	// Ensure the database can be reopened without its oldest block files
+	// and pruning resumes after the files which were already deleted.
+	idb.Close()
+	idb, err = openDB(dbPath, blockDataNet, maxFileSize, false)
+	if err != nil {
+		t.Fatalf("openDB: unexpected error reopening: %v", err)
+	}
+	store = idb.(*db).store
+	if store.firstFileNum != firstFileNum {
+		t.Fatalf("openDB: unexpected first block file -- got %d, "+
+			"want %d", store.firstFileNum, firstFileNum)
+	}
+	checkPruned(numPruned)
+
+	numPruned = prune(0, nil, numPruned)
+	checkPruned(numPruned)
+	if store.firstFileNum != store.writeCursor.curFileNum {
+		t.Fatalf("PruneBlocks: unexpected first block file -- got %d, "+
+			"want %d", store.firstFileNum,
+			store.writeCursor.curFileNum)
+	}
+
+	// Ensure a block which was pruned can be stored again.
+	err = idb.Update(func(tx database.Tx) error {
+		return tx.StoreBlock(blocks[0])
+	})
+	if err != nil {
+		t.Fatalf("StoreBlock: unexpected error storing pruned block: %v",
+			err)
+	}
+	err = idb.View(func(tx database.Tx) error {
+		if _, err := tx.FetchBlock(blocks[0].Hash()); err != nil {
+			return err
+		}
+		prunedIdxBucket := tx.Metadata().Bucket(prunedIdxBucketName)
+		if prunedIdxBucket.Get(blocks[0].Hash()[:]) != nil {
+			return fmt.Errorf("header of stored block is still " +
+				"retained")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FetchBlock: unexpected error: %v", err)
+	}
+}
@@ -249,6 +249,22 @@ type Tx interface {
 	// Other errors are possible depending on the implementation.
 	HasBlocks(hashes []chainhash.Hash) ([]bool, error)
 
+	// ForEachBlock invokes the passed function with the hash of every block
+	// which exists in the database, including blocks stored by the
+	// transaction which have not been committed yet.  Blocks deleted by
+	// PruneBlocks are not included.  The order of the hashes is not
+	// specified.
+	//
+	// If the provided function returns an error, the iteration is stopped
+	// and the error is returned to the caller.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrTxClosed if the transaction has already been closed
+	//
+	// Other errors are possible depending on the implementation.
+	ForEachBlock(fn func(hash *chainhash.Hash) error) error
+
 	// FetchBlockHeader returns the raw serialized bytes for the block
 	// header identified by the given hash.  The raw bytes are in the format
 	// returned by Serialize on a wire.BlockHeader.
@@ -389,6 +405,26 @@ type Tx interface {
 	// implementations.
 	FetchBlockRegions(regions []BlockRegion) ([][]byte, error)
 
+	// PruneBlocks deletes the stored blocks, oldest first, until the total
+	// size of the block storage is no more than the provided target size in
+	// bytes.  Blocks for which the provided keep function returns true are
+	// never deleted and, depending on the backend implementation, doing so
+	// might prevent other blocks from being deleted as well.  The hashes
+	// of all of the deleted blocks are returned.
+	//
+	// The headers of deleted blocks are retained and are therefore still
+	// available via FetchBlockHeader and FetchBlockHeaders.  However,
+	// HasBlock reports the deleted blocks as not existing and attempting to
+	// fetch any of their data will return ErrBlockNotFound.
+	//
+	// The interface contract guarantees at least the following errors will
+	// be returned (other implementation-specific errors are possible):
+	//   - ErrTxNotWritable if attempted against a read-only transaction
+	//   - ErrTxClosed if the transaction has already been closed
+	//
+	// Other errors are possible depending on the implementation.
+	PruneBlocks(targetSize uint64, keep func(hash *chainhash.Hash) bool) ([]chainhash.Hash, error)
+
 	// ******************************************************************
 	// Methods related to both atomic metadata storage and block storage.
 	// ******************************************************************
@@ -0,0 +1,82 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// inputCharset is the set of characters a descriptor may contain.  The
+	// checksum treats the characters as groups of 32 so that the most
+	// common character substitutions are always detected.
+	inputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+	// checksumCharset is the set of characters used to encode a checksum.
+	checksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	// checksumLen is the number of characters in an encoded checksum.
+	checksumLen = 8
+)
+
+// checksumGenerator houses the generator of the BCH code used to calculate
+// descriptor checksums.
+var checksumGenerator = [5]uint64{
+	0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a, 0x644d626ffd,
+}
+
+// polymod updates the passed checksum state with the passed 5-bit symbol.
+func polymod(c uint64, symbol int) uint64 {
+	top := c >> 35
+	c = (c&0x7ffffffff)<<5 ^ uint64(symbol)
+	for i := uint(0); i < 5; i++ {
+		if (top>>i)&1 != 0 {
+			c ^= checksumGenerator[i]
+		}
+	}
+	return c
+}
+
+// Checksum returns the checksum of the passed descriptor, which must not
+// include a checksum itself.  An error is returned if the descriptor contains
+// characters which are not allowed in descriptors.
+func Checksum(desc string) (string, error) {
+	c := uint64(1)
+	cls, clsCount := 0, 0
+	for i := 0; i < len(desc); i++ {
+		pos := strings.IndexByte(inputCharset, desc[i])
+		if pos == -1 {
+			return "", fmt.Errorf("invalid character %q in descriptor",
+				desc[i])
+		}
+
+		// Emit a symbol for the position inside the group for every
+		// character and a symbol for the groups of every three
+		// characters.
+		c = polymod(c, pos&31)
+		cls = cls*3 + pos>>5
+		clsCount++
+		if clsCount == 3 {
+			c = polymod(c, cls)
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = polymod(c, cls)
+	}
+	for i := 0; i < checksumLen; i++ {
+		c = polymod(c, 0)
+	}
+	c ^= 1
+
+	var checksum [checksumLen]byte
+	for i := range checksum {
+		checksum[i] = checksumCharset[(c>>(5*uint(checksumLen-1-i)))&31]
+	}
+	return string(checksum[:]), nil
+}
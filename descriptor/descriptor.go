@@ -0,0 +1,399 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ltcsuite/ltcd/btcec"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcutil"
+	"github.com/ltcsuite/ltcutil/hdkeychain"
+)
+
+var (
+	// ErrMissingChecksum describes an error in which a descriptor which is
+	// required to have a checksum does not have one.
+	ErrMissingChecksum = errors.New("descriptor is missing a checksum")
+
+	// ErrInvalidChecksum describes an error in which the checksum of a
+	// descriptor does not match the descriptor.
+	ErrInvalidChecksum = errors.New("descriptor checksum is invalid")
+)
+
+// outputType identifies the kind of output a descriptor describes.
+type outputType int
+
+const (
+	outputPKH outputType = iota
+	outputWPKH
+	outputSHWPKH
+	outputAddr
+)
+
+// unsupportedFunctions houses the names of the descriptor functions which are
+// defined, but not supported by this package.  They are distinguished from
+// unknown functions to provide more helpful errors.
+var unsupportedFunctions = map[string]struct{}{
+	"pk":          {},
+	"combo":       {},
+	"multi":       {},
+	"sortedmulti": {},
+	"wsh":         {},
+	"raw":         {},
+	"tr":          {},
+}
+
+// descriptorKey houses a key expression of a descriptor.  Either pubKey is set
+// for a plain public key, or extKey is set for an extended public key which
+// has already been derived along all of the fixed child indexes of its path.
+// The canonical form of the expression is kept in str.
+type descriptorKey struct {
+	str     string
+	pubKey  []byte
+	extKey  *hdkeychain.ExtendedKey
+	isRange bool
+}
+
+// Descriptor houses a parsed output descriptor.
+type Descriptor struct {
+	output outputType
+	key    *descriptorKey
+	addr   ltcutil.Address
+	net    *chaincfg.Params
+}
+
+// Parse parses the passed output descriptor for the provided network.  When
+// the descriptor has a checksum it must be valid, and when requireChecksum is
+// true ErrMissingChecksum is returned for descriptors without one.
+func Parse(desc string, net *chaincfg.Params, requireChecksum bool) (*Descriptor, error) {
+	if idx := strings.LastIndex(desc, "#"); idx != -1 {
+		checksum, err := Checksum(desc[:idx])
+		if err != nil {
+			return nil, err
+		}
+		if desc[idx+1:] != checksum {
+			return nil, ErrInvalidChecksum
+		}
+		desc = desc[:idx]
+	} else if requireChecksum {
+		return nil, ErrMissingChecksum
+	}
+
+	if err := checkParentheses(desc); err != nil {
+		return nil, err
+	}
+
+	d := &Descriptor{net: net}
+	name, arg, err := splitFunction(desc)
+	if err != nil {
+		return nil, err
+	}
+	switch name {
+	case "pkh":
+		d.output = outputPKH
+		d.key, err = parseKey(arg, net, false)
+
+	case "wpkh":
+		d.output = outputWPKH
+		d.key, err = parseKey(arg, net, true)
+
+	case "sh":
+		var inner string
+		name, inner, err = splitFunction(arg)
+		if err != nil {
+			return nil, err
+		}
+		if name != "wpkh" {
+			return nil, functionError(name, "in sh()")
+		}
+		d.output = outputSHWPKH
+		d.key, err = parseKey(inner, net, true)
+
+	case "addr":
+		d.output = outputAddr
+		d.addr, err = ltcutil.DecodeAddress(arg, net)
+		if err == nil && !d.addr.IsForNet(net) {
+			err = fmt.Errorf("address %s is not for %s", arg,
+				net.Name)
+		}
+
+	default:
+		return nil, functionError(name, "at the top level")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// checkParentheses returns an error describing the first unbalanced
+// parenthesis of the passed descriptor, if any.
+func checkParentheses(desc string) error {
+	var open []int
+	for i := 0; i < len(desc); i++ {
+		switch desc[i] {
+		case '(':
+			open = append(open, i)
+		case ')':
+			if len(open) == 0 {
+				return fmt.Errorf("unbalanced parentheses: "+
+					"unexpected ')' at position %d", i)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		return fmt.Errorf("unbalanced parentheses: '(' at position %d "+
+			"is not closed", open[len(open)-1])
+	}
+	return nil
+}
+
+// functionError returns an error for the passed descriptor function name which
+// is not allowed in the described context.
+func functionError(name, context string) error {
+	if name == "pkh" || name == "wpkh" || name == "sh" || name == "addr" {
+		return fmt.Errorf("%s() is not allowed %s", name, context)
+	}
+	if _, ok := unsupportedFunctions[name]; ok {
+		return fmt.Errorf("unsupported descriptor function %s()", name)
+	}
+	return fmt.Errorf("unknown descriptor function %q", name)
+}
+
+// splitFunction splits the passed expression of the form name(argument) into
+// its name and argument.
+func splitFunction(expr string) (string, string, error) {
+	open := strings.IndexByte(expr, '(')
+	if open == -1 || !strings.HasSuffix(expr, ")") {
+		return "", "", fmt.Errorf("expression %q is not of the form "+
+			"name(argument)", expr)
+	}
+	return expr[:open], expr[open+1 : len(expr)-1], nil
+}
+
+// parseChildIndex parses the passed child index of a derivation path along
+// with whether or not it is hardened.
+func parseChildIndex(s string) (uint32, bool, error) {
+	hardened := strings.HasSuffix(s, "'") || strings.HasSuffix(s, "h")
+	if hardened {
+		s = s[:len(s)-1]
+	}
+	index, err := strconv.ParseUint(s, 10, 32)
+	if err != nil || index >= hdkeychain.HardenedKeyStart {
+		return 0, false, fmt.Errorf("invalid child index %q", s)
+	}
+	return uint32(index), hardened, nil
+}
+
+// formatChildIndex returns the canonical form of the passed child index of a
+// derivation path.
+func formatChildIndex(index uint32, hardened bool) string {
+	str := strconv.FormatUint(uint64(index), 10)
+	if hardened {
+		str += "'"
+	}
+	return str
+}
+
+// parseKeyOrigin validates the passed key origin, which is a hex-encoded
+// fingerprint followed by the derivation path of the key, and returns its
+// canonical form.
+func parseKeyOrigin(origin string) (string, error) {
+	parts := strings.Split(origin, "/")
+	if len(parts[0]) != 8 {
+		return "", fmt.Errorf("key origin fingerprint %q is not 4 "+
+			"bytes", parts[0])
+	}
+	fingerprint, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return "", fmt.Errorf("key origin fingerprint %q is not hex",
+			parts[0])
+	}
+
+	canonical := hex.EncodeToString(fingerprint)
+	for _, part := range parts[1:] {
+		index, hardened, err := parseChildIndex(part)
+		if err != nil {
+			return "", err
+		}
+		canonical += "/" + formatChildIndex(index, hardened)
+	}
+	return canonical, nil
+}
+
+// parseKey parses the passed key expression.  Only compressed public keys are
+// allowed when requireCompressed is true.
+func parseKey(expr string, net *chaincfg.Params, requireCompressed bool) (*descriptorKey, error) {
+	var origin string
+	if strings.HasPrefix(expr, "[") {
+		end := strings.IndexByte(expr, ']')
+		if end == -1 {
+			return nil, fmt.Errorf("key origin %q is not closed", expr)
+		}
+		canonical, err := parseKeyOrigin(expr[1:end])
+		if err != nil {
+			return nil, err
+		}
+		origin = "[" + canonical + "]"
+		expr = expr[end+1:]
+	}
+
+	path := strings.Split(expr, "/")
+	if pubKey, err := hex.DecodeString(path[0]); err == nil {
+		if len(path) > 1 {
+			return nil, fmt.Errorf("public key %s can not have a "+
+				"derivation path", path[0])
+		}
+		if _, err := btcec.ParsePubKey(pubKey, btcec.S256()); err != nil {
+			return nil, fmt.Errorf("invalid public key %s: %v",
+				path[0], err)
+		}
+		if requireCompressed && len(pubKey) != btcec.PubKeyBytesLenCompressed {
+			return nil, fmt.Errorf("public key %s is not compressed",
+				path[0])
+		}
+		return &descriptorKey{
+			str:    origin + hex.EncodeToString(pubKey),
+			pubKey: pubKey,
+		}, nil
+	}
+
+	extKey, err := hdkeychain.NewKeyFromString(path[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid key %s: %v", path[0], err)
+	}
+	if extKey.IsPrivate() {
+		return nil, fmt.Errorf("extended private keys are not supported")
+	}
+	if !extKey.IsForNet(net) {
+		return nil, fmt.Errorf("extended key %s is not for %s",
+			path[0], net.Name)
+	}
+
+	key := &descriptorKey{str: origin + path[0], extKey: extKey}
+	for i, part := range path[1:] {
+		if part == "*" && i == len(path)-2 {
+			key.str += "/*"
+			key.isRange = true
+			break
+		}
+		index, hardened, err := parseChildIndex(part)
+		if err != nil {
+			return nil, err
+		}
+		if hardened {
+			return nil, hdkeychain.ErrDeriveHardFromPublic
+		}
+		key.str += "/" + formatChildIndex(index, false)
+		key.extKey, err = key.extKey.Child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// pubKeyAt returns the serialized public key of the key at the passed child
+// index, which is ignored unless the key is ranged.
+func (k *descriptorKey) pubKeyAt(index uint32) ([]byte, error) {
+	if k.extKey == nil {
+		return k.pubKey, nil
+	}
+
+	extKey := k.extKey
+	if k.isRange {
+		if index >= hdkeychain.HardenedKeyStart {
+			return nil, fmt.Errorf("child index %d is out of range",
+				index)
+		}
+		var err error
+		extKey, err = extKey.Child(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pubKey, err := extKey.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	return pubKey.SerializeCompressed(), nil
+}
+
+// IsRange returns whether or not the descriptor describes the outputs for a
+// range of child indexes.
+func (d *Descriptor) IsRange() bool {
+	return d.key != nil && d.key.isRange
+}
+
+// IsSolvable returns whether or not the descriptor describes everything needed
+// to spend its outputs other than the private keys.  Only addr() descriptors
+// are not solvable since they do not describe the keys of their outputs.
+func (d *Descriptor) IsSolvable() bool {
+	return d.output != outputAddr
+}
+
+// canonical returns the canonical form of the descriptor without a checksum.
+func (d *Descriptor) canonical() string {
+	switch d.output {
+	case outputPKH:
+		return "pkh(" + d.key.str + ")"
+	case outputWPKH:
+		return "wpkh(" + d.key.str + ")"
+	case outputSHWPKH:
+		return "sh(wpkh(" + d.key.str + "))"
+	}
+	return "addr(" + d.addr.EncodeAddress() + ")"
+}
+
+// Checksum returns the checksum of the canonical form of the descriptor.
+func (d *Descriptor) Checksum() string {
+	// The characters of the canonical form are always valid.
+	checksum, _ := Checksum(d.canonical())
+	return checksum
+}
+
+// String returns the canonical form of the descriptor along with its
+// checksum.  Hardened child indexes of key origins are always marked with an
+// apostrophe and hex-encoded values are lowercase.
+func (d *Descriptor) String() string {
+	return d.canonical() + "#" + d.Checksum()
+}
+
+// Address returns the address of the output the descriptor describes for the
+// passed child index, which is ignored unless the descriptor is ranged.
+func (d *Descriptor) Address(index uint32) (ltcutil.Address, error) {
+	if d.output == outputAddr {
+		return d.addr, nil
+	}
+
+	pubKey, err := d.key.pubKeyAt(index)
+	if err != nil {
+		return nil, err
+	}
+	pubKeyHash := ltcutil.Hash160(pubKey)
+	switch d.output {
+	case outputPKH:
+		return ltcutil.NewAddressPubKeyHash(pubKeyHash, d.net)
+
+	case outputWPKH:
+		return ltcutil.NewAddressWitnessPubKeyHash(pubKeyHash, d.net)
+
+	case outputSHWPKH:
+		// The redeem script is the version 0 witness program of the
+		// public key hash.
+		redeemScript := make([]byte, 0, 2+len(pubKeyHash))
+		redeemScript = append(redeemScript, 0x00, byte(len(pubKeyHash)))
+		redeemScript = append(redeemScript, pubKeyHash...)
+		return ltcutil.NewAddressScriptHash(redeemScript, d.net)
+	}
+
+	return nil, fmt.Errorf("unknown output type %d", d.output)
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package descriptor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+)
+
+// The following are descriptors from the examples published with Bitcoin Core.
+const (
+	corePKHXPub  = "pkh([d34db33f/44'/0'/0']xpub6ERApfZwUNrhLCkDtcHTcxd75RbzS1ed54G1LkBUHQVHQKqhMkhgbmJbZRkrgZw4koxb5JaHWkY4ALHY2grBGRjaDMzQLcgJvLJuZZvRcEL/1/*)"
+	coreWPKHXPub = "wpkh([d34db33f/84h/0h/0h]xpub6DJ2dNUysrn5Vt36jH2KLBT2i1auw1tTSSomg8PhqNiUtx8QX2SvC9nrHu81fT41fvDUnhMjEzQgXnQjKEu3oaqMSzhSrHMxyyoEAmUHQbY/0/*)"
+	coreAddr     = "addr(mkmZxiEcEd8ZqjQWVZuC6so5dFMKEFpN2j)"
+)
+
+// testTPub is the master public key of the first BIP0032 test vector encoded
+// for the test networks.
+const testTPub = "tpubD6NzVbkrYhZ4XgiXtGrdW5XDAPFCL9h7we1vwNCpn8tGbBcgfVYjXyhWo4E1xkh56hjod1RhGjxbaTLV3X4FyWuejifB9jusQ46QzG87VKp"
+
+// mustChecksum returns the passed descriptor with its checksum appended.
+func mustChecksum(t *testing.T, desc string) string {
+	checksum, err := Checksum(desc)
+	if err != nil {
+		t.Fatalf("Checksum(%s): unexpected error: %v", desc, err)
+	}
+	return desc + "#" + checksum
+}
+
+// TestChecksum ensures descriptor checksums are calculated as expected.
+func TestChecksum(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		want string
+	}{
+		{"raw(deadbeef)", "89f8spxm"},
+		{corePKHXPub, "ml40v0wf"},
+		{coreWPKHXPub, "cjjspncu"},
+		{coreAddr, "02wpgw69"},
+		{"pkh(" + testTPub + "/0/*)", "t8wxpp7p"},
+		{"wpkh(" + testTPub + "/0/*)", "qvnrzae8"},
+		{"sh(wpkh(" + testTPub + "/0/*))", "xrgx409f"},
+	}
+	for _, test := range tests {
+		got, err := Checksum(test.desc)
+		if err != nil {
+			t.Errorf("Checksum(%s): unexpected error: %v", test.desc,
+				err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("Checksum(%s): got %s, want %s", test.desc, got,
+				test.want)
+		}
+	}
+
+	if _, err := Checksum("raw(deadbeef)\n"); err == nil {
+		t.Error("Checksum: accepted a descriptor with an invalid " +
+			"character")
+	}
+}
+
+// TestDeriveAddresses ensures the addresses derived from ranged descriptors of
+// a known extended public key and from fixed descriptors match the expected
+// addresses.
+func TestDeriveAddresses(t *testing.T) {
+	t.Parallel()
+
+	const childPubKey = "02756de182c5dd4b717ea87e693006da62dbb3cddaa4a5cad2ed1f5bbab755f0f5"
+	tests := []struct {
+		desc    string
+		isRange bool
+		want    []string
+	}{
+		{
+			desc:    "pkh(" + testTPub + "/0/*)#t8wxpp7p",
+			isRange: true,
+			want: []string{
+				"mgiHMN7dJsANUWwLfgbiw7hc4kR5xMjPhw",
+				"mhv1CCCN8vQVCJJ9PbyjAiWvGrpucJDxkU",
+				"mxaHndsi6PLJ5s1DDRZ5CpcXKFo8RUj93A",
+				"mthMAAFoJmrdkG1kGhvhzpry94Yc6W7xFq",
+				"mwYNVHp48GcDKAwYSAhhYqsTjPKXU3Jdm8",
+			},
+		},
+		{
+			desc:    "wpkh(" + testTPub + "/0/*)#qvnrzae8",
+			isRange: true,
+			want: []string{
+				"tltc1qp5wfcq48h6d63wyy9qz0awtpfqwwv4smwfrkuc",
+				"tltc1qrfxr69jqnhwufxgkqgcdep9prq4j4vuweqa9yk",
+				"tltc1qhvd6suvqzjcu9pxjhrwhtrlj85ny3n2mnwxe90",
+				"tltc1qjzgwzugce3mqfvn2cdq8wt8drz50mf6j2t9xn9",
+				"tltc1q4lrflkd0sddm4ktujw8e5syxmlwcdprdcv4ssm",
+			},
+		},
+		{
+			desc:    "sh(wpkh([d34db33f/49'/1h/0']" + testTPub + "/0/*))",
+			isRange: true,
+			want: []string{
+				"QVax9TcT83uCXPT9qkghUvR8CUa56Sv7k8",
+				"QRUdKdYARKFYf6JKTtUv1iiM5KtQaynqJk",
+				"QZUNvp3Pu2yiRrE5ea79RWdhUi2dz7KacD",
+				"QVUfs6Y93yPedRE8tCoZr6iqmQMYLoor1w",
+				"QZrvNHMYVZ52Qv9CuLTQK87odFfwST25LS",
+			},
+		},
+		{
+			desc: "pkh(" + testTPub + "/0/0)",
+			want: []string{"mgiHMN7dJsANUWwLfgbiw7hc4kR5xMjPhw"},
+		},
+		{
+			desc: "wpkh(" + childPubKey + ")",
+			want: []string{"tltc1qp5wfcq48h6d63wyy9qz0awtpfqwwv4smwfrkuc"},
+		},
+		{
+			desc: "addr(QVax9TcT83uCXPT9qkghUvR8CUa56Sv7k8)",
+			want: []string{"QVax9TcT83uCXPT9qkghUvR8CUa56Sv7k8"},
+		},
+	}
+
+	for _, test := range tests {
+		d, err := Parse(test.desc, &chaincfg.TestNet4Params, false)
+		if err != nil {
+			t.Errorf("Parse(%s): unexpected error: %v", test.desc, err)
+			continue
+		}
+		if d.IsRange() != test.isRange {
+			t.Errorf("IsRange(%s): got %v, want %v", test.desc,
+				d.IsRange(), test.isRange)
+			continue
+		}
+		for i, want := range test.want {
+			addr, err := d.Address(uint32(i))
+			if err != nil {
+				t.Errorf("Address(%s, %d): unexpected error: %v",
+					test.desc, i, err)
+				continue
+			}
+			if addr.EncodeAddress() != want {
+				t.Errorf("Address(%s, %d): got %s, want %s",
+					test.desc, i, addr.EncodeAddress(), want)
+			}
+		}
+	}
+}
+
+// TestCanonical ensures parsed descriptors are converted to their canonical
+// form along with the expected checksum and flags.
+func TestCanonical(t *testing.T) {
+	t.Parallel()
+
+	const pubKey = "02f9308a019258c31049344f85f89d5229b531c845836f99b08601f113bce036f9"
+	tests := []struct {
+		desc       string
+		net        *chaincfg.Params
+		want       string
+		isRange    bool
+		isSolvable bool
+	}{
+		{
+			desc:       corePKHXPub + "#ml40v0wf",
+			net:        &chaincfg.MainNetParams,
+			want:       corePKHXPub + "#ml40v0wf",
+			isRange:    true,
+			isSolvable: true,
+		},
+		{
+			desc:       coreWPKHXPub,
+			net:        &chaincfg.MainNetParams,
+			want:       "wpkh([d34db33f/84'/0'/0']xpub6DJ2dNUysrn5Vt36jH2KLBT2i1auw1tTSSomg8PhqNiUtx8QX2SvC9nrHu81fT41fvDUnhMjEzQgXnQjKEu3oaqMSzhSrHMxyyoEAmUHQbY/0/*)#trd0mf0l",
+			isRange:    true,
+			isSolvable: true,
+		},
+		{
+			desc:       "sh(wpkh(" + strings.ToUpper(pubKey) + "))",
+			net:        &chaincfg.MainNetParams,
+			want:       "sh(wpkh(" + pubKey + "))#hyahcv3t",
+			isSolvable: true,
+		},
+		{
+			desc: coreAddr,
+			net:  &chaincfg.TestNet4Params,
+			want: coreAddr + "#02wpgw69",
+		},
+	}
+	for _, test := range tests {
+		d, err := Parse(test.desc, test.net, false)
+		if err != nil {
+			t.Errorf("Parse(%s): unexpected error: %v", test.desc, err)
+			continue
+		}
+		if d.String() != test.want {
+			t.Errorf("String(%s): got %s, want %s", test.desc,
+				d.String(), test.want)
+		}
+		if d.Checksum() != test.want[len(test.want)-8:] {
+			t.Errorf("Checksum(%s): got %s, want %s", test.desc,
+				d.Checksum(), test.want[len(test.want)-8:])
+		}
+		if d.IsRange() != test.isRange || d.IsSolvable() != test.isSolvable {
+			t.Errorf("Parse(%s): got range %v solvable %v, want "+
+				"range %v solvable %v", test.desc, d.IsRange(),
+				d.IsSolvable(), test.isRange, test.isSolvable)
+		}
+	}
+}
+
+// TestParseErrors ensures malformed and unsupported descriptors are rejected.
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	const uncompressedPubKey = "0411db93e1dcdb8a016b49840f8c53bc1eb68a382e97b1482ecad7b148a6909a5cb2e0eaddfb84ccf9744464f82e160bfa9b8b64f9d4c03f999b8643f656b412a3"
+	tests := []struct {
+		name string
+		desc string
+	}{
+		{"invalid checksum", "pkh(" + testTPub + "/0/*)#t8wxpp7q"},
+		{"missing checksum", "pkh(" + testTPub + "/0/*)"},
+		{"unsupported function", mustChecksum(t, "combo("+testTPub+")")},
+		{"unsupported sh", mustChecksum(t, "sh(pkh("+testTPub+"))")},
+		{"hardened child", mustChecksum(t, "pkh("+testTPub+"/0'/*)")},
+		{"hardened range", mustChecksum(t, "pkh("+testTPub+"/*')")},
+		{"range not last", mustChecksum(t, "pkh("+testTPub+"/*/0)")},
+		{"bad child index", mustChecksum(t, "pkh("+testTPub+"/x)")},
+		{"bad origin", mustChecksum(t, "pkh([d34db3/0]"+testTPub+")")},
+		{"unclosed origin", mustChecksum(t, "pkh([d34db33f"+testTPub+")")},
+		{"uncompressed wpkh", mustChecksum(t, "wpkh("+uncompressedPubKey+")")},
+		{"path on pubkey", mustChecksum(t, "pkh("+uncompressedPubKey+"/0)")},
+		{"mainnet xpub", mustChecksum(t, "pkh(xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8)")},
+		{"mainnet address", mustChecksum(t, "addr(LKDxGDJq5fF4FohAB8zJH24mDDNHDNtqsE)")},
+		{"not a function", mustChecksum(t, testTPub)},
+	}
+	for _, test := range tests {
+		_, err := Parse(test.desc, &chaincfg.TestNet4Params, true)
+		if err == nil {
+			t.Errorf("%s: Parse(%s) did not fail", test.name, test.desc)
+		}
+	}
+
+	// Ensure unbalanced parentheses and unknown functions are described
+	// precisely.
+	errTests := []struct {
+		desc string
+		want string
+	}{
+		{"sh(wpkh(" + testTPub + ")", "'(' at position 2 is not closed"},
+		{"pkh(" + testTPub + "))", "unexpected ')' at position 116"},
+		{"foo(" + testTPub + ")", `unknown descriptor function "foo"`},
+		{"sh(foo(" + testTPub + "))", `unknown descriptor function "foo"`},
+		{"wsh(" + testTPub + ")", "unsupported descriptor function wsh()"},
+		{"wpkh(wpkh(" + testTPub + "))", "invalid key"},
+		{"sh(sh(wpkh(" + testTPub + ")))", "sh() is not allowed in sh()"},
+	}
+	for _, test := range errTests {
+		_, err := Parse(test.desc, &chaincfg.TestNet4Params, false)
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Parse(%s): got error %v, want %q", test.desc,
+				err, test.want)
+		}
+	}
+
+	// Ensure the checksum errors are returned as expected.
+	_, err := Parse("pkh("+testTPub+"/0/*)#t8wxpp7q",
+		&chaincfg.TestNet4Params, false)
+	if err != ErrInvalidChecksum {
+		t.Errorf("Parse: got error %v, want %v", err, ErrInvalidChecksum)
+	}
+	_, err = Parse("pkh("+testTPub+"/0/*)", &chaincfg.TestNet4Params, true)
+	if err != ErrMissingChecksum {
+		t.Errorf("Parse: got error %v, want %v", err, ErrMissingChecksum)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package descriptor implements parsing of output descriptors and the derivation
+of the addresses they describe.
+
+Output Descriptors Overview
+
+An output descriptor is a human-readable string which describes a set of
+output scripts along with everything needed to derive them.  The following
+descriptors are supported:
+
+	pkh(KEY)       - pay-to-pubkey-hash output for the key
+	wpkh(KEY)      - pay-to-witness-pubkey-hash output for the key
+	sh(wpkh(KEY))  - pay-to-witness-pubkey-hash output nested in a
+	                 pay-to-script-hash output
+	addr(ADDRESS)  - output paying to the address
+
+KEY is either a hex-encoded public key or an extended public key followed by
+zero or more unhardened child indexes, each preceded by a slash.  The last
+child index of an extended public key may be a '*' which makes the descriptor
+ranged, meaning it describes the outputs for every child index at that
+position.  Any key may be prefixed with key origin information enclosed in
+square brackets, which is validated but otherwise ignored.
+
+Descriptor Checksums
+
+A descriptor may be followed by a '#' and an eight character checksum which
+protects against typos.  Parse validates the checksum when one is present and
+can optionally require it.  The Checksum function calculates the checksum of a
+descriptor and matches the descriptor checksums of Bitcoin Core, so
+descriptors are interchangeable between the two.
+
+The String method of a parsed descriptor returns its canonical form along with
+its checksum.  In the canonical form, hardened child indexes are always marked
+with an apostrophe and hex-encoded values are lowercase.
+*/
+package descriptor
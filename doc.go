@@ -25,6 +25,13 @@ Application Options:
   -C, --configfile=         Path to configuration file
   -b, --datadir=            Directory to store data
       --logdir=             Directory to log output.
+      --logmaxsize=         Maximum size in MiB of the log file before it is
+                            rotated to a compressed backup (10)
+      --logmaxbackups=      Maximum number of compressed log file backups to
+                            keep -- 0 keeps all backups (3)
+      --logformat=          Format of log output {text, json} -- json writes
+                            each log entry as a JSON object with time, level,
+                            subsystem and message fields (text)
   -a, --addpeer=            Add a peer to connect with at startup
       --connect=            Connect only to the specified peers at startup
       --nolisten            Disable listening for incoming connections -- NOTE:
@@ -33,16 +40,30 @@ Application Options:
                             listen interfaces via --listen
       --listen=             Add an interface/port to listen for connections
                             (default all interfaces port: 9333, testnet: 19333)
+      --onionlisten=        Add an interface/port to listen for connections
+                            forwarded by a tor hidden service, which are treated
+                            as connections from onion peers (eg.
+                            127.0.0.1:9335)
       --maxpeers=           Max number of inbound and outbound peers (125)
+      --blockrelaypeers=    Number of outbound peers which only relay blocks
+                            and do not relay transactions or addresses, in
+                            addition to the regular outbound peers (2)
       --nobanning           Disable banning of misbehaving peers
       --banthreshold=       Maximum allowed ban score before disconnecting and
-                            banning misbehaving peers.
+                            banning misbehaving peers.  0 disables banning
+      --banhalflife=        Time after which the decaying part of the ban score
+                            of peers is halved.  Valid time units are {s, m,
+                            h}.  Minimum 1 second (1m0s)
       --banduration=        How long to ban misbehaving peers.  Valid time units
                             are {s, m, h}.  Minimum 1 second (24h0m0s)
   -u, --rpcuser=            Username for RPC connections
   -P, --rpcpass=            Password for RPC connections
       --rpclimituser=       Username for limited RPC connections
       --rpclimitpass=       Password for limited RPC connections
+      --rpcscopeduser=      Add an RPC user only permitted to call the given
+                            methods, or those of the given role {full, limited,
+                            readonly} -- format: user:password:method1,method2
+                            or user:password:role
       --rpclisten=          Add an interface/port to listen for RPC connections
                             (default port: 9334, testnet: 19334)
       --rpccert=            File containing the certificate file
@@ -50,15 +71,59 @@ Application Options:
       --rpcmaxclients=      Max number of RPC clients for standard connections
                             (10)
       --rpcmaxwebsockets=   Max number of RPC websocket connections (25)
+      --rpcmaxrequestsize=  Max size in bytes of RPC requests and websocket
+                            messages (33554432)
+      --rpcmaxbatchsize=    Max number of requests in a batch of RPC requests
+                            (1000)
+      --rpctimeout=         Cancel RPC calls which take longer than the given
+                            duration unless overridden for their method -- 0
+                            means no timeout.  Valid time units are {s, m, h}
+                            (0)
+      --rpcmethodtimeout=   Override the rpctimeout option for an RPC method --
+                            format: method:duration, eg. gettxoutsetinfo:30m
+      --rpcslowthreshold=   Log RPC calls which take longer than the given
+                            duration -- 0 disables logging slow calls.  Valid
+                            time units are {s, m, h} (10s)
       --rpcquirks           Mirror some JSON-RPC quirks of Bitcoin Core -- NOTE:
                             Discouraged unless interoperability issues need to
                             be worked around
       --norpc               Disable built-in RPC server -- NOTE: The RPC server
-                            is disabled by default if no rpcuser/rpcpass or
-                            rpclimituser/rpclimitpass is specified
+                            is disabled by default if no rpcuser/rpcpass,
+                            rpclimituser/rpclimitpass or rpcscopeduser is
+                            specified, unless rpclisten is specified and the
+                            cookie is enabled
+      --norpccookie         Do not write a cookie with random RPC credentials
+                            to the data directory for local tools to
+                            authenticate with when the RPC server is enabled
+                            -- NOTE: Specifying rpclisten enables the RPC
+                            server with only the cookie unless this is set
       --notls               Disable TLS for the RPC server -- NOTE: This is only
                             allowed if the RPC server is bound to localhost
+      --zmqpubhashblock=    Publish the hashes of connected blocks on the given
+                            ZMQ endpoint (eg. tcp://127.0.0.1:28332)
+      --zmqpubhashtx=       Publish the hashes of the transactions accepted to
+                            the memory pool or in connected blocks on the given
+                            ZMQ endpoint
+      --zmqpubrawblock=     Publish connected blocks on the given ZMQ endpoint
+      --zmqpubrawtx=        Publish the transactions accepted to the memory pool
+                            or in connected blocks on the given ZMQ endpoint
+      --zmqpubsequence=     Publish the blocks connected to and disconnected
+                            from the main chain and the transactions added to
+                            and removed from the memory pool along with the
+                            memory pool sequence on the given ZMQ endpoint
+      --zmqpubhashblockhwm= Max number of hashblock notifications queued for
+                            each subscriber -- 0 means no limit (1000)
+      --zmqpubhashtxhwm=    Max number of hashtx notifications queued for each
+                            subscriber -- 0 means no limit (1000)
+      --zmqpubrawblockhwm=  Max number of rawblock notifications queued for each
+                            subscriber -- 0 means no limit (1000)
+      --zmqpubrawtxhwm=     Max number of rawtx notifications queued for each
+                            subscriber -- 0 means no limit (1000)
+      --zmqpubsequencehwm=  Max number of sequence notifications queued for
+                            each subscriber -- 0 means no limit (1000)
       --nodnsseed           Disable DNS seeding for peers
+      --nofixedseeds        Do not fall back to the fixed seeds of the network
+                            when DNS seeding returns no addresses
       --externalip=         Add an ip to the list of local addresses we claim to
                             listen on to peers
       --proxy=              Connect via SOCKS5 proxy (eg. 127.0.0.1:9050)
@@ -69,14 +134,34 @@ Application Options:
       --onionuser=          Username for onion proxy server
       --onionpass=          Password for onion proxy server
       --noonion             Disable connecting to tor hidden services
+      --i2psam=             Connect to and accept connections from I2P peers
+                            through the SAM v3 bridge at the given address (eg.
+                            127.0.0.1:7656)
+      --cjdnsreachable      Connect to peers with CJDNS or Yggdrasil (fc00::/8)
+                            addresses, which requires this host to be joined to
+                            the overlay network -- NOTE: These connections are
+                            made directly rather than through a proxy
+      --onlynet=            Only make automatic connections to and accept
+                            connections from peers on the given network (ipv4,
+                            ipv6, onion, i2p or cjdns) -- May be specified
+                            multiple times to allow several networks -- NOTE:
+                            Peers specified with --connect or --addpeer are
+                            always connected to
       --torisolation        Enable Tor stream isolation by randomizing user
-                            credentials for each connection.
+                            credentials for each connection and DNS lookup.
       --testnet             Use the test network
       --regtest             Use the regression test network
       --simnet              Use the simulation test network
       --addcheckpoint=      Add a custom checkpoint.  Format: '<height>:<hash>'
       --nocheckpoints       Disable built-in checkpoints.  Don't do this unless
                             you know what you're doing.
+      --assumevalid=        Skip script verification for the main chain blocks
+                            up to and including the given block, which is
+                            assumed to be valid.  Format: '<hash>' for built-in
+                            checkpoints, otherwise '<height>:<hash>'.  The zero
+                            hash verifies all scripts.  Ignored when
+                            checkpoints are disabled. (default: network
+                            dependent)
       --uacomment=          Comment to add to the user agent --
                             See BIP 14 for more information.
       --dbtype=             Database backend to use for the Block Chain (ffldb)
@@ -98,21 +183,96 @@ Application Options:
                             high priority for relaying
       --maxorphantx=        Max number of orphan transactions to keep in memory
                             (100)
+      --maxorphantxsize=    Max size in bytes of orphan transactions to keep in
+                            memory (100000)
+      --orphanttl=          How long to keep orphan transactions in memory
+                            before they expire.  Valid time units are {s, m,
+                            h}.  Minimum 1 second (15m0s)
+      --maxorphanblocks=    Max number of orphan blocks to keep in memory while
+                            waiting for their parents -- The oldest orphan block
+                            is evicted when the limit is reached (100)
+      --maxmempool=         Keep the transaction memory pool below the given
+                            size in megabytes by evicting the transactions
+                            paying the lowest fee rates -- 0 to disable (300)
+      --mempoolexpiry=      Evict transactions from the memory pool along with
+                            their descendants once they have been in it for the
+                            given amount of time.  Valid time units are {s, m,
+                            h} -- 0 to disable (336h0m0s)
+      --limitancestorcount= Do not accept transactions with more than the given
+                            number of ancestors in the memory pool, including
+                            the transaction itself (25)
+      --limitancestorsize=  Do not accept transactions whose ancestors in the
+                            memory pool, including the transaction itself,
+                            exceed the given total virtual size in kilobytes
+                            (101)
+      --limitdescendantcount= Do not accept transactions which would give a
+                            transaction in the memory pool more than the given
+                            number of descendants, including itself (25)
+      --limitdescendantsize= Do not accept transactions which would give a
+                            transaction in the memory pool descendants
+                            exceeding the given total virtual size in
+                            kilobytes, including itself (101)
+      --acceptrbf           Accept transactions that replace memory pool
+                            transactions which signal opt-in replace-by-fee
+                            (BIP 125)
       --generate            Generate (mine) bitcoins using the CPU
       --miningaddr=         Add the specified payment address to the list of
                             addresses to use for generated blocks -- At least
-                            one address is required if the generate option is
-                            set
+                            one address is required if the generate or
+                            stratumlisten options are set
+      --miningflags=        Extra data to append to the coinbase flags of
+                            generated blocks (max 73 bytes)
+      --signalbit=          Force generated blocks to signal the specified
+                            BIP0009 version bit (0-28) regardless of the state
+                            of its deployment
+      --nosignalbit=        Force generated blocks to not signal the specified
+                            BIP0009 version bit (0-28) regardless of the state
+                            of its deployment
+      --stratumlisten=      Add an interface/port to listen for Stratum mining
+                            connections (default port: 3333)
       --blockminsize=       Mininum block size in bytes to be used when creating
                             a block
       --blockmaxsize=       Maximum block size in bytes to be used when creating
                             a block (750000)
+      --blockminweight=     Mininum block weight to be used when creating a
+                            block
+      --blockmaxweight=     Maximum block weight to be used when creating a
+                            block (3000000)
       --blockprioritysize=  Size in bytes for high-priority/low-fee transactions
                             when creating a block (50000)
       --nopeerbloomfilters  Disable bloom filtering support.
       --nocfilters          Disable committed filtering (CF) support.
+      --cfindexverify       Verify that the basic committed filter of each
+                            block matches all of the data it commits to when
+                            indexing it and refuse to index blocks whose
+                            filters do not -- This is expensive and only useful
+                            to catch filter construction regressions
       --sigcachemaxsize=    The maximum number of entries in the signature
                             verification cache.
+      --scriptcachemaxsize= The maximum number of transaction inputs in the
+                            script execution cache, which skips executing the
+                            scripts of the transactions in blocks which were
+                            already validated by the mempool
+      --scriptworkers=      The maximum number of goroutines used to verify the
+                            scripts of a block concurrently (0 to use one per
+                            available processor)
+      --dbcache=            The maximum size in MiB of the cache of the utxo
+                            set kept in memory, which holds the changes to the
+                            utxo set until they are written to the database in
+                            batches (0 to write the changes of every block)
+                            (default: 450)
+      --prune=              Delete old blocks from the database to keep the
+                            stored block data within the target size in MiB --
+                            Must be at least 550 MiB and is incompatible with
+                            --txindex and --addrindex (0 to disable)
+      --reindex             Rebuild the chain state and the index of the main
+                            chain by validating and connecting all of the
+                            stored blocks again on start up -- An interrupted
+                            reindex resumes on the next start
+      --reindex-chainstate  Rebuild the utxo set by connecting the already
+                            validated blocks of the main chain again on start
+                            up -- An interrupted reindex resumes on the next
+                            start
       --blocksonly          Do not accept transactions from remote peers.
       --relaynonstd         Relay non-standard transactions regardless of the
                             default settings for the active network.
@@ -73,7 +73,7 @@ func thresholdStateToStatus(state blockchain.ThresholdState) (string, error) {
 	case blockchain.ThresholdStarted:
 		return "started", nil
 	case blockchain.ThresholdLockedIn:
-		return "lockedin", nil
+		return "locked_in", nil
 	case blockchain.ThresholdActive:
 		return "active", nil
 	case blockchain.ThresholdFailed:
@@ -102,19 +102,19 @@ func assertSoftForkStatus(r *rpctest.Harness, t *testing.T, forkKey string, stat
 	}
 
 	// Ensure the key is available.
-	desc, ok := info.Bip9SoftForks[forkKey]
-	if !ok {
+	desc, ok := info.SoftForks[forkKey]
+	if !ok || desc.Bip9 == nil {
 		_, _, line, _ := runtime.Caller(1)
 		t.Fatalf("assertion failed at line %d: softfork status for %q "+
 			"is not in getblockchaininfo results", line, forkKey)
 	}
 
 	// Ensure the status it the expected value.
-	if desc.Status != status {
+	if desc.Bip9.Status != status {
 		_, _, line, _ := runtime.Caller(1)
 		t.Fatalf("assertion failed at line %d: softfork status for %q "+
 			"is %v instead of expected %v", line, forkKey,
-			desc.Status, status)
+			desc.Bip9.Status, status)
 	}
 }
 
@@ -0,0 +1,209 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// This file is ignored during the regular tests due to the following build tag.
+// +build rpctest
+
+package integration
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/integration/rpctest"
+	"github.com/ltcsuite/ltcd/peer"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil/gcs"
+	"github.com/ltcsuite/ltcutil/gcs/builder"
+)
+
+// cfTestPeer houses a peer connected to a test harness along with the
+// channels the committed filter messages it receives are delivered on.
+type cfTestPeer struct {
+	*peer.Peer
+	cfHeaders chan *wire.MsgCFHeaders
+	cfCheckpt chan *wire.MsgCFCheckpt
+}
+
+// connectCFTestPeer connects a new outbound peer to the passed harness and
+// waits for the version handshake to complete.
+func connectCFTestPeer(r *rpctest.Harness, t *testing.T) *cfTestPeer {
+	verack := make(chan struct{})
+	p := &cfTestPeer{
+		cfHeaders: make(chan *wire.MsgCFHeaders, 1),
+		cfCheckpt: make(chan *wire.MsgCFCheckpt, 1),
+	}
+	peerCfg := &peer.Config{
+		UserAgentName:    "cftest",
+		UserAgentVersion: "1.0.0",
+		ChainParams:      r.ActiveNet,
+		Services:         0,
+		Listeners: peer.MessageListeners{
+			OnVerAck: func(*peer.Peer, *wire.MsgVerAck) {
+				close(verack)
+			},
+			OnCFHeaders: func(_ *peer.Peer, msg *wire.MsgCFHeaders) {
+				p.cfHeaders <- msg
+			},
+			OnCFCheckpt: func(_ *peer.Peer, msg *wire.MsgCFCheckpt) {
+				p.cfCheckpt <- msg
+			},
+		},
+	}
+
+	var err error
+	p.Peer, err = peer.NewOutboundPeer(peerCfg, r.P2PAddress())
+	if err != nil {
+		t.Fatalf("unable to create outbound peer: %v", err)
+	}
+	conn, err := net.Dial("tcp", r.P2PAddress())
+	if err != nil {
+		t.Fatalf("unable to connect to harness: %v", err)
+	}
+	p.AssociateConnection(conn)
+
+	select {
+	case <-verack:
+	case <-time.After(time.Second * 10):
+		t.Fatal("timeout waiting for verack")
+	}
+	return p
+}
+
+// expectedCFHeaders returns the basic committed filter headers of the blocks
+// in the main chain of the passed harness from the genesis block up to and
+// including the provided height calculated from the blocks themselves.
+func expectedCFHeaders(r *rpctest.Harness, t *testing.T, height int32) []chainhash.Hash {
+	headers := make([]chainhash.Hash, 0, height+1)
+	var prevHeader chainhash.Hash
+	for i := int32(0); i <= height; i++ {
+		hash, err := r.Node.GetBlockHash(int64(i))
+		if err != nil {
+			t.Fatalf("unable to get hash of block %d: %v", i, err)
+		}
+		block, err := r.Node.GetBlock(hash)
+		if err != nil {
+			t.Fatalf("unable to get block %v: %v", hash, err)
+		}
+		filter, err := builder.BuildBasicFilter(block)
+		if err != nil && err != gcs.ErrNoData {
+			t.Fatalf("unable to build filter for block %v: %v",
+				hash, err)
+		}
+
+		prevHeader = builder.MakeHeaderForFilter(filter, prevHeader)
+		headers = append(headers, prevHeader)
+	}
+	return headers
+}
+
+// TestCFHeaders ensures the committed filter headers served via the
+// getcfheaders and getcfcheckpt messages form a chain which links back to the
+// genesis block and matches the filters of the blocks.
+func TestCFHeaders(t *testing.T) {
+	t.Parallel()
+
+	r, err := rpctest.New(&chaincfg.SimNetParams, nil, nil)
+	if err != nil {
+		t.Fatal("unable to create primary harness: ", err)
+	}
+	if err := r.SetUp(true, 25); err != nil {
+		t.Fatalf("unable to setup test chain: %v", err)
+	}
+	defer r.TearDown()
+
+	tipHash, tipHeight, err := r.Node.GetBestBlock()
+	if err != nil {
+		t.Fatalf("unable to get best block: %v", err)
+	}
+	want := expectedCFHeaders(r, t, tipHeight)
+
+	p := connectCFTestPeer(r, t)
+	defer func() {
+		p.Disconnect()
+		p.WaitForDisconnect()
+	}()
+
+	// Request the headers following the genesis block up to the tip.
+	genesisHash := r.ActiveNet.GenesisHash
+	getHeaders := wire.NewMsgGetCFHeaders()
+	getHeaders.AddBlockLocatorHash(genesisHash)
+	getHeaders.HashStop = *tipHash
+	p.QueueMessage(getHeaders, nil)
+
+	var headersMsg *wire.MsgCFHeaders
+	select {
+	case headersMsg = <-p.cfHeaders:
+	case <-time.After(time.Second * 10):
+		t.Fatal("timeout waiting for cfheaders")
+	}
+	if headersMsg.StopHash != *tipHash || headersMsg.Extended {
+		t.Fatalf("unexpected cfheaders for stop hash %v (extended "+
+			"%v), want %v (basic)", headersMsg.StopHash,
+			headersMsg.Extended, tipHash)
+	}
+	if int32(len(headersMsg.HeaderHashes)) != tipHeight {
+		t.Fatalf("unexpected number of cfheaders -- got %d, want %d",
+			len(headersMsg.HeaderHashes), tipHeight)
+	}
+
+	// Ensure each header commits to the filter of its block and links to
+	// the header before it, starting with the genesis block header.
+	for i, header := range headersMsg.HeaderHashes {
+		if *header != want[i+1] {
+			t.Fatalf("cfheader for block %d does not link to the "+
+				"previous header -- got %v, want %v", i+1,
+				header, want[i+1])
+		}
+	}
+
+	// Ensure a header requested by stop hash alone matches the chain.
+	midHash, err := r.Node.GetBlockHash(int64(tipHeight / 2))
+	if err != nil {
+		t.Fatalf("unable to get block hash: %v", err)
+	}
+	getHeaders = wire.NewMsgGetCFHeaders()
+	getHeaders.HashStop = *midHash
+	p.QueueMessage(getHeaders, nil)
+	select {
+	case headersMsg = <-p.cfHeaders:
+	case <-time.After(time.Second * 10):
+		t.Fatal("timeout waiting for cfheaders")
+	}
+	if len(headersMsg.HeaderHashes) != 1 ||
+		*headersMsg.HeaderHashes[0] != want[tipHeight/2] {
+
+		t.Fatalf("unexpected cfheaders for block %d: %v", tipHeight/2,
+			headersMsg.HeaderHashes)
+	}
+
+	// Ensure the checkpoints cover every block at a checkpoint interval up
+	// to the tip.
+	p.QueueMessage(wire.NewMsgGetCFCheckpt(tipHash, false), nil)
+	var checkptMsg *wire.MsgCFCheckpt
+	select {
+	case checkptMsg = <-p.cfCheckpt:
+	case <-time.After(time.Second * 10):
+		t.Fatal("timeout waiting for cfcheckpt")
+	}
+	if checkptMsg.StopHash != *tipHash {
+		t.Fatalf("unexpected cfcheckpt stop hash -- got %v, want %v",
+			checkptMsg.StopHash, tipHash)
+	}
+	numCheckpts := int(tipHeight / wire.CFCheckptInterval)
+	if len(checkptMsg.FilterHeaders) != numCheckpts {
+		t.Fatalf("unexpected number of cfcheckpt headers -- got %d, "+
+			"want %d", len(checkptMsg.FilterHeaders), numCheckpts)
+	}
+	for i, header := range checkptMsg.FilterHeaders {
+		height := (i + 1) * wire.CFCheckptInterval
+		if *header != want[height] {
+			t.Fatalf("unexpected cfcheckpt header for block %d -- "+
+				"got %v, want %v", height, header, want[height])
+		}
+	}
+}
@@ -6,10 +6,13 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/btcsuite/btclog"
 	"github.com/jrick/logrotate/rotator"
@@ -21,8 +24,10 @@ import (
 	"github.com/ltcsuite/ltcd/mempool"
 	"github.com/ltcsuite/ltcd/mining"
 	"github.com/ltcsuite/ltcd/mining/cpuminer"
+	"github.com/ltcsuite/ltcd/mining/stratum"
 	"github.com/ltcsuite/ltcd/peer"
 	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/zmq"
 )
 
 // logWriter implements an io.Writer that outputs to both standard output and
@@ -30,11 +35,84 @@ import (
 type logWriter struct{}
 
 func (logWriter) Write(p []byte) (n int, err error) {
-	os.Stdout.Write(p)
-	logRotatorPipe.Write(p)
+	entry := p
+	if logFormatJSON {
+		entry = jsonLogEntry(p)
+	}
+	os.Stdout.Write(entry)
+	logRotatorPipe.Write(entry)
 	return len(p), nil
 }
 
+// logFormatJSON specifies whether log entries are written as JSON objects
+// instead of the default human-readable lines.  It is set while loading the
+// config, before any of the subsystem loggers are used.
+var logFormatJSON bool
+
+// logTimeLayout is the layout of the timestamp the logging backend writes at
+// the start of each log entry.
+const logTimeLayout = "2006-01-02 15:04:05.000"
+
+// logLevelNames maps the abbreviated level names written by the logging
+// backend to the level names accepted by the debuglevel option.
+var logLevelNames = map[string]string{
+	"TRC": "trace",
+	"DBG": "debug",
+	"INF": "info",
+	"WRN": "warn",
+	"ERR": "error",
+	"CRT": "critical",
+	"OFF": "off",
+}
+
+// jsonLogRecord describes a log entry written when the json log format is
+// selected.
+type jsonLogRecord struct {
+	Time      string `json:"time,omitempty"`
+	Level     string `json:"level,omitempty"`
+	Subsystem string `json:"subsystem,omitempty"`
+	Source    string `json:"source,omitempty"`
+	Message   string `json:"message"`
+}
+
+// jsonLogEntry converts a log entry written by the logging backend, which has
+// the form 'YYYY-MM-DD hh:mm:ss.sss [LVL] TAG: message', to a single line JSON
+// object with the time, level, subsystem and message as separate fields.  When
+// the LOGFLAGS environment variable adds the callsite to the entry, it is
+// reported in the source field.  Entries that do not have the expected form
+// are written with only the message field.
+func jsonLogEntry(p []byte) []byte {
+	entry := strings.TrimRight(string(p), "\n")
+	record := jsonLogRecord{Message: entry}
+
+	// Parse the header written by the backend.  The message is left intact
+	// when any part of it is malformed.
+	header := len(logTimeLayout) + len(" [LVL] ")
+	if len(entry) >= header && entry[len(logTimeLayout):][:2] == " [" &&
+		entry[header-2:header] == "] " {
+
+		t, err := time.ParseInLocation(logTimeLayout,
+			entry[:len(logTimeLayout)], time.Local)
+		level, ok := logLevelNames[entry[header-5:header-2]]
+		tag := entry[header:]
+		sep := strings.Index(tag, ": ")
+		if err == nil && ok && sep > 0 {
+			record.Time = t.Format(time.RFC3339Nano)
+			record.Level = level
+			record.Subsystem = tag[:sep]
+			record.Message = tag[sep+2:]
+			if i := strings.IndexByte(record.Subsystem, ' '); i > 0 {
+				record.Source = record.Subsystem[i+1:]
+				record.Subsystem = record.Subsystem[:i]
+			}
+		}
+	}
+
+	// Marshalling a struct of strings can not fail.
+	b, _ := json.Marshal(&record)
+	return append(b, '\n')
+}
+
 // Loggers per subsystem.  A single backend logger is created and all subsytem
 // loggers created from it will write to the backend.  When adding new
 // subsystems, add the subsystem logger variable here and to the
@@ -83,6 +161,8 @@ func init() {
 	indexers.UseLogger(indxLog)
 	mining.UseLogger(minrLog)
 	cpuminer.UseLogger(minrLog)
+	stratum.UseLogger(minrLog)
+	zmq.UseLogger(srvrLog)
 	peer.UseLogger(peerLog)
 	txscript.UseLogger(scrpLog)
 	mempool.UseLogger(txmpLog)
@@ -107,19 +187,31 @@ var subsystemLoggers = map[string]btclog.Logger{
 	"TXMP": txmpLog,
 }
 
-// initLogRotator initializes the logging rotater to write logs to logFile and
-// create roll files in the same directory.  It must be called before the
-// package-global log rotater variables are used.
-func initLogRotator(logFile string) {
+// newLogRotator returns a log rotator which writes logs to logFile and, once it
+// grows past maxSize MiB, moves it to a compressed roll file in the same
+// directory.  At most maxBackups roll files are kept, or all of them when it is
+// 0.  Lines written to the rotator from its Run method are never split across
+// files, so it is safe to use with concurrent writers of an io.Pipe.
+func newLogRotator(logFile string, maxSize, maxBackups int) (*rotator.Rotator, error) {
 	logDir, _ := filepath.Split(logFile)
 	err := os.MkdirAll(logDir, 0700)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to create log directory: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("failed to create log directory: %v", err)
 	}
-	r, err := rotator.New(logFile, 10*1024, false, 3)
+	r, err := rotator.New(logFile, int64(maxSize)*1024, false, maxBackups)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to create file rotator: %v\n", err)
+		return nil, fmt.Errorf("failed to create file rotator: %v", err)
+	}
+	return r, nil
+}
+
+// initLogRotator initializes the logging rotater to write logs to logFile and
+// create roll files in the same directory.  It must be called before the
+// package-global log rotater variables are used.
+func initLogRotator(logFile string, maxSize, maxBackups int) {
+	r, err := newLogRotator(logFile, maxSize, maxBackups)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
@@ -130,6 +222,19 @@ func initLogRotator(logFile string) {
 	logRotatorPipe = pw
 }
 
+// logLevelSpec returns the current logging level of every subsystem in the
+// <subsystem>=<level>,<subsystem2>=<level2>,... form accepted by the
+// debuglevel option.  The subsystems are sorted for stable display.
+func logLevelSpec() string {
+	subsystems := supportedSubsystems()
+	pairs := make([]string, 0, len(subsystems))
+	for _, subsysID := range subsystems {
+		level := subsystemLoggers[subsysID].Level().String()
+		pairs = append(pairs, subsysID+"="+logLevelNames[level])
+	}
+	return strings.Join(pairs, ",")
+}
+
 // setLogLevel sets the logging level for provided subsystem.  Invalid
 // subsystems are ignored.  Uninitialized subsystems are dynamically created as
 // needed.
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btclog"
+)
+
+// jsonTestWriter is an io.Writer that converts the entries written by a
+// logging backend to JSON the same way the logWriter does when the json log
+// format is selected.
+type jsonTestWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *jsonTestWriter) Write(p []byte) (int, error) {
+	w.buf.Write(jsonLogEntry(p))
+	return len(p), nil
+}
+
+// TestJSONLogEntry ensures log entries written by subsystem loggers are
+// converted to JSON objects with the expected fields.
+func TestJSONLogEntry(t *testing.T) {
+	t.Parallel()
+
+	w := new(jsonTestWriter)
+	backend := btclog.NewBackend(w)
+	logger := backend.Logger("TEST")
+	logger.SetLevel(btclog.LevelTrace)
+
+	start := time.Now().Add(-time.Second)
+	logger.Infof("Processed %d blocks", 3)
+	logger.Warn("Peer", "disconnected:", "timeout")
+	logger.Tracef("Multiple\nlines")
+	end := time.Now().Add(time.Second)
+
+	tests := []struct {
+		level   string
+		message string
+	}{
+		{"info", "Processed 3 blocks"},
+		{"warn", "Peer disconnected: timeout"},
+		{"trace", "Multiple\nlines"},
+	}
+
+	lines := bytes.Split(bytes.TrimSuffix(w.buf.Bytes(), []byte("\n")),
+		[]byte("\n"))
+	if len(lines) != len(tests) {
+		t.Fatalf("got %d log lines, want %d: %q", len(lines),
+			len(tests), w.buf.String())
+	}
+	for i, test := range tests {
+		var record map[string]interface{}
+		if err := json.Unmarshal(lines[i], &record); err != nil {
+			t.Errorf("#%d: invalid JSON %q: %v", i, lines[i], err)
+			continue
+		}
+
+		want := map[string]string{
+			"level":     test.level,
+			"subsystem": "TEST",
+			"message":   test.message,
+		}
+		for field, value := range want {
+			if record[field] != value {
+				t.Errorf("#%d: %s is %v, want %q", i, field,
+					record[field], value)
+			}
+		}
+
+		timestamp, ok := record["time"].(string)
+		if !ok {
+			t.Errorf("#%d: missing time field", i)
+			continue
+		}
+		ts, err := time.Parse(time.RFC3339Nano, timestamp)
+		if err != nil {
+			t.Errorf("#%d: invalid time %q: %v", i, timestamp, err)
+			continue
+		}
+		if ts.Before(start) || ts.After(end) {
+			t.Errorf("#%d: time %v is not between %v and %v", i,
+				ts, start, end)
+		}
+		if _, ok := record["source"]; ok {
+			t.Errorf("#%d: unexpected source field", i)
+		}
+	}
+}
+
+// TestJSONLogEntryUnparsed ensures entries which do not have the form written
+// by the logging backend, and entries which include the callsite, are still
+// converted to valid JSON.
+func TestJSONLogEntryUnparsed(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		entry string
+		want  jsonLogRecord
+	}{
+		{
+			entry: "unexpected output\n",
+			want:  jsonLogRecord{Message: "unexpected output"},
+		},
+		{
+			entry: "2017-06-01 12:00:00.000 [XYZ] TEST: bad level\n",
+			want: jsonLogRecord{
+				Message: "2017-06-01 12:00:00.000 [XYZ] TEST: " +
+					"bad level",
+			},
+		},
+		{
+			entry: "2017-06-01 12:00:00.000 [ERR] TEST log.go:12: " +
+				"with \"callsite\"\n",
+			want: jsonLogRecord{
+				Time: time.Date(2017, 6, 1, 12, 0, 0, 0,
+					time.Local).Format(time.RFC3339Nano),
+				Level:     "error",
+				Subsystem: "TEST",
+				Source:    "log.go:12",
+				Message:   "with \"callsite\"",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		got := jsonLogEntry([]byte(test.entry))
+		if !bytes.HasSuffix(got, []byte("\n")) ||
+			bytes.Count(got, []byte("\n")) != 1 {
+
+			t.Errorf("#%d: entry %q is not a single line", i, got)
+			continue
+		}
+		var record jsonLogRecord
+		if err := json.Unmarshal(got, &record); err != nil {
+			t.Errorf("#%d: invalid JSON %q: %v", i, got, err)
+			continue
+		}
+		if record != test.want {
+			t.Errorf("#%d: got %+v, want %+v", i, record, test.want)
+		}
+	}
+}
+
+// TestLogRotation ensures the log file is rotated to a compressed backup once
+// it grows past the maximum size while being written to concurrently, and that
+// the oldest backups are removed once there are more than the maximum number.
+func TestLogRotation(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "logrotation")
+	if err != nil {
+		t.Fatalf("TempDir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	const maxBackups = 2
+	logFile := filepath.Join(dir, "logs", defaultLogFilename)
+	r, err := newLogRotator(logFile, 1, maxBackups)
+	if err != nil {
+		t.Fatalf("newLogRotator: %v", err)
+	}
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		r.Run(pr)
+		close(done)
+	}()
+
+	// Write a little more than the maximum size of the log file from
+	// several goroutines for each rotation, and wait for the rotated file
+	// to be compressed before the next one.
+	const writers = 4
+	const linesPerWriter = 275
+	line := strings.Repeat("x", 990)
+	rotations := maxBackups + 2
+	for rotation := 1; rotation <= rotations; rotation++ {
+		var wg sync.WaitGroup
+		for w := 0; w < writers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < linesPerWriter; i++ {
+					fmt.Fprintf(pw, "%d %04d %s\n", w, i, line)
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		backup := fmt.Sprintf("%s.%d", logFile, rotation)
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			_, errGz := os.Stat(backup + ".gz")
+			_, errRaw := os.Stat(backup)
+			if errGz == nil && os.IsNotExist(errRaw) {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("rotation %d: %s was not created", rotation,
+					backup+".gz")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	pw.Close()
+	<-done
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Only the newest backups must remain.
+	backups, err := filepath.Glob(logFile + ".*")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	var want []string
+	for n := rotations - maxBackups + 1; n <= rotations; n++ {
+		want = append(want, fmt.Sprintf("%s.%d.gz", logFile, n))
+	}
+	if strings.Join(backups, ",") != strings.Join(want, ",") {
+		t.Fatalf("got backups %v, want %v", backups, want)
+	}
+
+	// Every line of the active log file must be complete.
+	f, err := os.Open(logFile)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	lines := 0
+	for scanner.Scan() {
+		var w, i int
+		var rest string
+		_, err := fmt.Sscanf(scanner.Text(), "%d %04d %s", &w, &i, &rest)
+		if err != nil || rest != line {
+			t.Fatalf("malformed log line %q", scanner.Text())
+		}
+		lines++
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if lines == 0 {
+		t.Fatal("active log file is empty")
+	}
+}
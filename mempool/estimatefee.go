@@ -0,0 +1,967 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/mining"
+	"github.com/ltcsuite/ltcutil"
+)
+
+const (
+	// estimateFeeDepth is the maximum number of blocks before a transaction
+	// is confirmed that we want to track.
+	estimateFeeDepth = 25
+
+	// estimateFeeBinSize is the number of txs stored in each bin.
+	estimateFeeBinSize = 100
+
+	// estimateFeeMaxReplacements is the max number of replacements that
+	// can be made by the txs found in a given block.
+	estimateFeeMaxReplacements = 10
+
+	// estimateFeeMaxSnapshotAge is the maximum number of blocks a saved
+	// fee estimator may be behind the best chain and still be restored.
+	// Older snapshots no longer describe the current fee market and are
+	// discarded.
+	estimateFeeMaxSnapshotAge = estimateFeeDepth
+
+	// estimateFeeSaveVersion is the version of the serialized format
+	// produced by Save.  It must be increased whenever the format changes
+	// so that older snapshots are discarded rather than misinterpreted.
+	estimateFeeSaveVersion = 1
+
+	// estimateFeeEconomicalHorizon and estimateFeeConservativeHorizon are
+	// the number of most recent blocks whose transactions are taken into
+	// account by economical and conservative smart fee estimates
+	// respectively.
+	estimateFeeEconomicalHorizon   = 48
+	estimateFeeConservativeHorizon = 576
+
+	// estimateFeeEconomicalThreshold and estimateFeeConservativeThreshold
+	// are the fractions of the transactions paying at least an estimated
+	// fee rate which must have been confirmed within the target number of
+	// blocks for economical and conservative smart fee estimates
+	// respectively.
+	estimateFeeEconomicalThreshold   = 0.85
+	estimateFeeConservativeThreshold = 0.95
+
+	// DefaultEstimateFeeMaxRollback is the default number of rollbacks
+	// allowed by the fee estimator for orphaned blocks.
+	DefaultEstimateFeeMaxRollback = 2
+
+	// DefaultEstimateFeeMinRegisteredBlocks is the default minimum
+	// number of blocks which must be observed by the fee estimator before
+	// it will provide fee estimations.
+	DefaultEstimateFeeMinRegisteredBlocks = 3
+
+	bytePerKb = 1000
+
+	btcPerSatoshi = 1e-8
+)
+
+// EstimateFeeDatabaseKey is the key used to store the serialized fee
+// estimator in the metadata bucket of the block database.
+var EstimateFeeDatabaseKey = []byte("estimatefee")
+
+// SatoshiPerByte is number with units of satoshis per byte.
+type SatoshiPerByte float64
+
+// BtcPerKilobyte is number with units of bitcoins per kilobyte.
+type BtcPerKilobyte float64
+
+// ToBtcPerKb returns a float value that represents the given
+// SatoshiPerByte converted to bitcoins per kb.
+func (rate SatoshiPerByte) ToBtcPerKb() BtcPerKilobyte {
+	// If our rate is the error value, return that.
+	if rate == SatoshiPerByte(-1.0) {
+		return -1.0
+	}
+
+	return BtcPerKilobyte(float64(rate) * bytePerKb * btcPerSatoshi)
+}
+
+// NewSatoshiPerByte creates a SatoshiPerByte from an Amount and a
+// size in bytes.
+func NewSatoshiPerByte(fee ltcutil.Amount, size uint32) SatoshiPerByte {
+	return SatoshiPerByte(float64(fee) / float64(size))
+}
+
+// observedTransaction represents an observed transaction and some
+// additional data required for the fee estimation algorithm.
+type observedTransaction struct {
+	// A transaction hash.
+	hash chainhash.Hash
+
+	// The fee per byte of the transaction in satoshis.
+	feeRate SatoshiPerByte
+
+	// The block height when it was observed.
+	observed int32
+
+	// The height of the block in which it was mined.
+	// If the transaction has not yet been mined, it is
+	// mining.UnminedHeight.
+	mined int32
+}
+
+// serialize writes the observed transaction to the passed writer.
+func (o *observedTransaction) serialize(w io.Writer) error {
+	if _, err := w.Write(o.hash[:]); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, o.feeRate); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, o.observed); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.BigEndian, o.mined)
+}
+
+// deserializeObservedTransaction reads an observed transaction which was
+// written by serialize from the passed reader.
+func deserializeObservedTransaction(r io.Reader) (*observedTransaction, error) {
+	var o observedTransaction
+	if _, err := io.ReadFull(r, o.hash[:]); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &o.feeRate); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &o.observed); err != nil {
+		return nil, err
+	}
+	if err := binary.Read(r, binary.BigEndian, &o.mined); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// serializeObservedTransactions writes the count of the passed transactions
+// followed by each transaction to the passed writer.
+func serializeObservedTransactions(w io.Writer, txns []*observedTransaction) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(txns))); err != nil {
+		return err
+	}
+	for _, o := range txns {
+		if err := o.serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deserializeObservedTransactions reads a list of transactions which was
+// written by serializeObservedTransactions from the passed reader.
+func deserializeObservedTransactions(r io.Reader) ([]*observedTransaction, error) {
+	var count uint32
+	if err := binary.Read(r, binary.BigEndian, &count); err != nil {
+		return nil, err
+	}
+
+	// Don't trust the count for the allocation since the data may be
+	// corrupt.
+	var txns []*observedTransaction
+	for i := uint32(0); i < count; i++ {
+		o, err := deserializeObservedTransaction(r)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, o)
+	}
+	return txns, nil
+}
+
+// registeredBlock has the hash of a block and the list of transactions
+// it mined which had been previously observed by the FeeEstimator. It
+// is used if Rollback is called to reverse the effect of registering
+// a block.
+type registeredBlock struct {
+	hash         chainhash.Hash
+	transactions []*observedTransaction
+}
+
+// FeeEstimator manages the data necessary to create
+// fee estimations. It is safe for concurrent access.
+type FeeEstimator struct {
+	maxRollback uint32
+	binSize     int32
+
+	// The maximum number of replacements that can be made in a single
+	// bin per block. Default is estimateFeeMaxReplacements
+	maxReplacements int32
+
+	// The minimum number of blocks that can be registered with the fee
+	// estimator before it will provide answers.
+	minRegisteredBlocks uint32
+
+	// The last known height.
+	lastKnownHeight int32
+
+	// The number of blocks that have been registered.
+	numBlocksRegistered uint32
+
+	mtx sync.RWMutex
+
+	// observed holds the transactions which have been seen in the memory
+	// pool but not yet mined.
+	observed map[chainhash.Hash]*observedTransaction
+	bin      [estimateFeeDepth][]*observedTransaction
+
+	// The cached estimates.
+	cached []SatoshiPerByte
+
+	// Transactions that have been removed from the bins. This allows us to
+	// revert in case of an orphaned block.
+	dropped []*registeredBlock
+}
+
+// NewFeeEstimator creates a FeeEstimator for which at most maxRollback blocks
+// can be unregistered and which returns an error unless minRegisteredBlocks
+// have been registered with it.
+func NewFeeEstimator(maxRollback, minRegisteredBlocks uint32) *FeeEstimator {
+	return &FeeEstimator{
+		maxRollback:         maxRollback,
+		minRegisteredBlocks: minRegisteredBlocks,
+		lastKnownHeight:     mining.UnminedHeight,
+		binSize:             estimateFeeBinSize,
+		maxReplacements:     estimateFeeMaxReplacements,
+		observed:            make(map[chainhash.Hash]*observedTransaction),
+		dropped:             make([]*registeredBlock, 0, maxRollback),
+	}
+}
+
+// ObserveTransaction is called when a new transaction is observed in the mempool.
+func (ef *FeeEstimator) ObserveTransaction(t *TxDesc) {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	// If we haven't seen a block yet we don't know when this one arrived,
+	// so we ignore it.
+	if ef.lastKnownHeight == mining.UnminedHeight {
+		return
+	}
+
+	hash := *t.Tx.Hash()
+	if _, ok := ef.observed[hash]; !ok {
+		size := uint32(GetTxVirtualSize(t.Tx))
+
+		ef.observed[hash] = &observedTransaction{
+			hash:     hash,
+			feeRate:  NewSatoshiPerByte(ltcutil.Amount(t.Fee), size),
+			observed: t.Height,
+			mined:    mining.UnminedHeight,
+		}
+	}
+}
+
+// reset discards all collected data so that fee estimation starts over.
+//
+// This function MUST be called with the fee estimator lock held (for writes).
+func (ef *FeeEstimator) reset() {
+	ef.lastKnownHeight = mining.UnminedHeight
+	ef.numBlocksRegistered = 0
+	ef.observed = make(map[chainhash.Hash]*observedTransaction)
+	ef.bin = [estimateFeeDepth][]*observedTransaction{}
+	ef.cached = nil
+	ef.dropped = make([]*registeredBlock, 0, ef.maxRollback)
+}
+
+// RegisterBlock informs the fee estimator of a new block to take into account.
+//
+// Blocks must be registered in order.  Should a block which does not
+// directly extend the last registered one be passed, the collected data can
+// no longer be trusted, so it is discarded and estimation starts over from
+// the passed block.
+func (ef *FeeEstimator) RegisterBlock(block *ltcutil.Block) {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	// The previous sorted list is invalid, so delete it.
+	ef.cached = nil
+
+	height := block.Height()
+	if ef.lastKnownHeight != mining.UnminedHeight &&
+		height != ef.lastKnownHeight+1 {
+
+		log.Warnf("Intermediate block not recorded by the fee "+
+			"estimator (last known height %d, new height %d) -- "+
+			"restarting fee estimation", ef.lastKnownHeight, height)
+		ef.reset()
+	}
+
+	// Update the last known height.
+	ef.lastKnownHeight = height
+	ef.numBlocksRegistered++
+
+	// Randomly order txs in block.
+	transactions := make(map[*ltcutil.Tx]struct{})
+	for _, t := range block.Transactions() {
+		transactions[t] = struct{}{}
+	}
+
+	// Count the number of replacements we make per bin so that we don't
+	// replace too many.
+	var replacementCounts [estimateFeeDepth]int
+
+	// Keep track of which txs were dropped in case of an orphan block.
+	dropped := &registeredBlock{
+		hash:         *block.Hash(),
+		transactions: make([]*observedTransaction, 0, 100),
+	}
+
+	// Go through the txs in the block.
+	for t := range transactions {
+		hash := *t.Hash()
+
+		// Have we observed this tx in the mempool?
+		o, ok := ef.observed[hash]
+		if !ok {
+			continue
+		}
+
+		// The transaction is no longer pending regardless of whether or
+		// not it ends up in one of the bins.
+		delete(ef.observed, hash)
+
+		// Put the observed tx in the appropriate bin.
+		blocksToConfirm := height - o.observed - 1
+
+		// This shouldn't happen but check just in case to avoid
+		// an out-of-bounds array index later.
+		if blocksToConfirm < 0 || blocksToConfirm >= estimateFeeDepth {
+			continue
+		}
+
+		// Make sure we do not replace too many transactions per min.
+		if replacementCounts[blocksToConfirm] == int(ef.maxReplacements) {
+			continue
+		}
+
+		o.mined = height
+
+		replacementCounts[blocksToConfirm]++
+
+		bin := ef.bin[blocksToConfirm]
+
+		// Remove a random element and replace it with this new tx.
+		if len(bin) == int(ef.binSize) {
+			// Don't drop transactions we have just added from this same block.
+			l := int(ef.binSize) - replacementCounts[blocksToConfirm]
+			drop := rand.Intn(l)
+			dropped.transactions = append(dropped.transactions, bin[drop])
+
+			bin[drop] = bin[l-1]
+			bin[l-1] = o
+		} else {
+			bin = append(bin, o)
+		}
+		ef.bin[blocksToConfirm] = bin
+	}
+
+	// Go through the mempool for txs that have been in too long.
+	for hash, o := range ef.observed {
+		if height-o.observed >= estimateFeeDepth {
+			delete(ef.observed, hash)
+		}
+	}
+
+	// Add dropped list to history.
+	if ef.maxRollback == 0 {
+		return
+	}
+
+	if uint32(len(ef.dropped)) == ef.maxRollback {
+		ef.dropped = append(ef.dropped[1:], dropped)
+	} else {
+		ef.dropped = append(ef.dropped, dropped)
+	}
+}
+
+// LastKnownHeight returns the height of the last block which was registered.
+func (ef *FeeEstimator) LastKnownHeight() int32 {
+	ef.mtx.RLock()
+	defer ef.mtx.RUnlock()
+
+	return ef.lastKnownHeight
+}
+
+// Rollback unregisters a recently registered block from the FeeEstimator.
+// This can be used to reverse the effect of an orphaned block on the fee
+// estimator. The maximum number of rollbacks allowed is given by
+// maxRollbacks.
+//
+// Note: not everything can be rolled back because some transactions are
+// deleted if they have been observed too long ago. That means the result
+// of Rollback won't always be exactly the same as if the last block had not
+// happened, but it should be close enough.
+func (ef *FeeEstimator) Rollback(hash *chainhash.Hash) error {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	// Find this block in the stack of recent registered blocks.
+	var n int
+	for n = 1; n <= len(ef.dropped); n++ {
+		if ef.dropped[len(ef.dropped)-n].hash.IsEqual(hash) {
+			break
+		}
+	}
+
+	if n > len(ef.dropped) {
+		return errors.New("no such block was recently registered")
+	}
+
+	for i := 0; i < n; i++ {
+		ef.rollback()
+	}
+
+	return nil
+}
+
+// rollback rolls back the effect of the last block in the stack
+// of registered blocks.
+//
+// This function MUST be called with the fee estimator lock held (for writes).
+func (ef *FeeEstimator) rollback() {
+	// The previous sorted list is invalid, so delete it.
+	ef.cached = nil
+
+	// pop the last list of dropped txs from the stack.
+	last := len(ef.dropped) - 1
+	if last == -1 {
+		// Cannot really happen because the exported calling function
+		// only rolls back a block already known to be in the list
+		// of dropped transactions.
+		return
+	}
+
+	dropped := ef.dropped[last]
+
+	// Keep track of where we are in each bin as we replace txs.
+	var replacementCounters [estimateFeeDepth]int
+
+	// Go through the txs in the dropped block.
+	for _, o := range dropped.transactions {
+		// Which bin was this tx in?
+		blocksToConfirm := o.mined - o.observed - 1
+
+		bin := ef.bin[blocksToConfirm]
+
+		var counter = replacementCounters[blocksToConfirm]
+
+		// Continue to go through that bin where we left off.
+		for {
+			if counter >= len(bin) {
+				// Panic, as we have entered an unrecoverable invalid state.
+				panic(errors.New("illegal state: cannot rollback dropped transaction"))
+			}
+
+			prev := bin[counter]
+
+			if prev.mined == ef.lastKnownHeight {
+				prev.mined = mining.UnminedHeight
+				ef.observed[prev.hash] = prev
+
+				bin[counter] = o
+
+				counter++
+				break
+			}
+
+			counter++
+		}
+
+		replacementCounters[blocksToConfirm] = counter
+	}
+
+	// Continue going through bins to find other txs to remove
+	// which did not replace any other when they were entered.
+	for i, j := range replacementCounters {
+		for {
+			l := len(ef.bin[i])
+			if j >= l {
+				break
+			}
+
+			prev := ef.bin[i][j]
+
+			if prev.mined == ef.lastKnownHeight {
+				prev.mined = mining.UnminedHeight
+				ef.observed[prev.hash] = prev
+
+				ef.bin[i] = append(ef.bin[i][0:j], ef.bin[i][j+1:l]...)
+
+				continue
+			}
+
+			j++
+		}
+	}
+
+	ef.dropped = ef.dropped[0:last]
+
+	// The number of blocks the fee estimator has seen is decremented.
+	ef.numBlocksRegistered--
+	ef.lastKnownHeight--
+}
+
+// estimateFeeSet is a set of txs that can that is sorted
+// by the fee per kb rate.
+type estimateFeeSet struct {
+	feeRate []SatoshiPerByte
+	bin     [estimateFeeDepth]uint32
+}
+
+func (b *estimateFeeSet) Len() int { return len(b.feeRate) }
+
+func (b *estimateFeeSet) Less(i, j int) bool {
+	return b.feeRate[i] > b.feeRate[j]
+}
+
+func (b *estimateFeeSet) Swap(i, j int) {
+	b.feeRate[i], b.feeRate[j] = b.feeRate[j], b.feeRate[i]
+}
+
+// estimateFee returns the estimated fee for a transaction
+// to confirm in confirmations blocks from now, given
+// the data set we have collected.
+func (b *estimateFeeSet) estimateFee(confirmations int) SatoshiPerByte {
+	if confirmations <= 0 {
+		return SatoshiPerByte(math.Inf(1))
+	}
+
+	if confirmations > estimateFeeDepth {
+		return 0
+	}
+
+	// We don't have any transactions!
+	if len(b.feeRate) == 0 {
+		return 0
+	}
+
+	var min, max int = 0, 0
+	for i := 0; i < confirmations-1; i++ {
+		min += int(b.bin[i])
+	}
+
+	max = min + int(b.bin[confirmations-1]) - 1
+	if max < min {
+		max = min
+	}
+	feeIndex := (min + max) / 2
+	if feeIndex >= len(b.feeRate) {
+		feeIndex = len(b.feeRate) - 1
+	}
+
+	return b.feeRate[feeIndex]
+}
+
+// newEstimateFeeSet creates a temporary data structure that
+// can be used to find all fee estimates.
+func (ef *FeeEstimator) newEstimateFeeSet() *estimateFeeSet {
+	set := &estimateFeeSet{}
+
+	capacity := 0
+	for i, b := range ef.bin {
+		l := len(b)
+		set.bin[i] = uint32(l)
+		capacity += l
+	}
+
+	set.feeRate = make([]SatoshiPerByte, capacity)
+
+	i := 0
+	for _, b := range ef.bin {
+		for _, o := range b {
+			set.feeRate[i] = o.feeRate
+			i++
+		}
+	}
+
+	sort.Sort(set)
+
+	return set
+}
+
+// estimates returns the set of all fee estimates from 1 to estimateFeeDepth
+// confirmations from now.
+func (ef *FeeEstimator) estimates() []SatoshiPerByte {
+	set := ef.newEstimateFeeSet()
+
+	estimates := make([]SatoshiPerByte, estimateFeeDepth)
+	for i := 0; i < estimateFeeDepth; i++ {
+		estimates[i] = set.estimateFee(i + 1)
+	}
+
+	return estimates
+}
+
+// EstimateFee estimates the fee per byte to have a tx confirmed a given
+// number of blocks from now.
+func (ef *FeeEstimator) EstimateFee(numBlocks uint32) (BtcPerKilobyte, error) {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	// If the number of registered blocks is below the minimum, return
+	// an error.
+	if ef.numBlocksRegistered < ef.minRegisteredBlocks {
+		return -1, errors.New("not enough blocks have been observed")
+	}
+
+	if numBlocks == 0 {
+		return -1, errors.New("cannot confirm transaction in zero blocks")
+	}
+
+	if numBlocks > estimateFeeDepth {
+		return -1, fmt.Errorf(
+			"can only estimate fees for up to %d blocks from now",
+			estimateFeeDepth)
+	}
+
+	// If there are no cached results, generate them.
+	if ef.cached == nil {
+		ef.cached = ef.estimates()
+	}
+
+	return ef.cached[int(numBlocks)-1].ToBtcPerKb(), nil
+}
+
+// EstimateMode specifies the tradeoff between the fee paid and the likelihood
+// of being confirmed within the target number of blocks made by
+// EstimateSmartFee.
+type EstimateMode int
+
+const (
+	// EstimateModeEconomical only considers recent blocks and therefore
+	// reacts quickly to changes in the fee market, potentially returning
+	// lower estimates.
+	EstimateModeEconomical EstimateMode = iota
+
+	// EstimateModeConservative considers a longer history of blocks and
+	// requires a larger share of transactions to have been confirmed in
+	// time, which makes the estimates less likely to be too low.
+	EstimateModeConservative
+)
+
+// feeRateSet is a set of txs that is sorted by descending fee rate.
+type feeRateSet []*observedTransaction
+
+func (q feeRateSet) Len() int { return len(q) }
+
+func (q feeRateSet) Less(i, j int) bool {
+	if q[i].feeRate == q[j].feeRate {
+		return bytes.Compare(q[i].hash[:], q[j].hash[:]) < 0
+	}
+	return q[i].feeRate > q[j].feeRate
+}
+
+func (q feeRateSet) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+// smartFeeRate returns the lowest fee rate for which at least the passed
+// threshold of the transactions paying that rate or more were confirmed
+// within target blocks of being observed.  The transactions must be sorted by
+// descending fee rate.  The returned flag is false when there is no such fee
+// rate.
+func smartFeeRate(txns feeRateSet, target uint32, threshold float64) (SatoshiPerByte, bool) {
+	var feeRate SatoshiPerByte
+	var found bool
+	var confirmed int
+	for i, o := range txns {
+		if uint32(o.mined-o.observed) <= target {
+			confirmed++
+		}
+
+		// Only consider a fee rate once all of the transactions paying
+		// it have been counted.
+		if i+1 < len(txns) && txns[i+1].feeRate == o.feeRate {
+			continue
+		}
+		if float64(confirmed) >= threshold*float64(i+1) {
+			feeRate = o.feeRate
+			found = true
+		}
+	}
+	return feeRate, found
+}
+
+// EstimateSmartFee estimates the fee per kilobyte needed for a tx to be
+// confirmed within the given number of blocks according to the passed mode.
+//
+// Since transactions are only tracked for a limited number of blocks, larger
+// targets are answered for that maximum instead.  Likewise, when no fee rate
+// satisfies the target, the smallest larger target which can be satisfied is
+// used.  The target the estimate is for is returned along with it.
+func (ef *FeeEstimator) EstimateSmartFee(confTarget uint32, mode EstimateMode) (BtcPerKilobyte, uint32, error) {
+	ef.mtx.RLock()
+	defer ef.mtx.RUnlock()
+
+	// If the number of registered blocks is below the minimum, return
+	// an error.
+	if ef.numBlocksRegistered < ef.minRegisteredBlocks {
+		return -1, 0, errors.New("not enough blocks have been observed")
+	}
+
+	if confTarget == 0 {
+		return -1, 0, errors.New("cannot confirm transaction in zero blocks")
+	}
+	if confTarget > estimateFeeDepth {
+		confTarget = estimateFeeDepth
+	}
+
+	horizon := int32(estimateFeeEconomicalHorizon)
+	threshold := estimateFeeEconomicalThreshold
+	if mode == EstimateModeConservative {
+		horizon = estimateFeeConservativeHorizon
+		threshold = estimateFeeConservativeThreshold
+	}
+
+	// Gather the transactions mined within the horizon.
+	var txns feeRateSet
+	for _, bin := range ef.bin {
+		for _, o := range bin {
+			if ef.lastKnownHeight-o.mined < horizon {
+				txns = append(txns, o)
+			}
+		}
+	}
+	if len(txns) == 0 {
+		return -1, 0, fmt.Errorf("no transactions have been confirmed "+
+			"in the last %d blocks", horizon)
+	}
+	sort.Sort(txns)
+
+	for target := confTarget; target <= estimateFeeDepth; target++ {
+		feeRate, ok := smartFeeRate(txns, target, threshold)
+		if ok {
+			return feeRate.ToBtcPerKb(), target, nil
+		}
+	}
+
+	return -1, 0, fmt.Errorf("insufficient data to estimate the fee "+
+		"for %d blocks", confTarget)
+}
+
+// FeeEstimatorState represents a saved FeeEstimator that can be
+// restored with data from an earlier session of the program.
+type FeeEstimatorState []byte
+
+// observedTxSet is a set of txs that is sorted by hash. It exists for
+// serialization purposes so that a serialized state always comes out the
+// same.
+type observedTxSet []*observedTransaction
+
+func (q observedTxSet) Len() int { return len(q) }
+
+func (q observedTxSet) Less(i, j int) bool {
+	return bytes.Compare(q[i].hash[:], q[j].hash[:]) < 0
+}
+
+func (q observedTxSet) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+// Save records the current state of the FeeEstimator to a []byte that
+// can be restored later.
+//
+// The serialized format is:
+//
+//   <version><parameters><observed txns><bins><dropped blocks>
+//
+//   Field                 Type       Size
+//   version               uint32     4 bytes
+//   max rollback          uint32     4 bytes
+//   bin size              int32      4 bytes
+//   max replacements      int32      4 bytes
+//   min registered blocks uint32     4 bytes
+//   last known height     int32      4 bytes
+//   registered blocks     uint32     4 bytes
+//   observed txns         tx list    variable
+//   bins                  tx list    variable (estimateFeeDepth lists)
+//   num dropped blocks    uint32     4 bytes
+//   dropped blocks        []block    variable
+//
+// Each tx list is a uint32 count followed by that many 48 byte records made
+// up of the tx hash, fee rate, observed height and mined height, while each
+// dropped block is its 32 byte hash followed by a tx list.  All integers are
+// big endian.
+func (ef *FeeEstimator) Save() FeeEstimatorState {
+	ef.mtx.Lock()
+	defer ef.mtx.Unlock()
+
+	// Writes to a bytes.Buffer never fail, so the errors are ignored.
+	var w bytes.Buffer
+	binary.Write(&w, binary.BigEndian, uint32(estimateFeeSaveVersion))
+
+	// Insert basic parameters.
+	binary.Write(&w, binary.BigEndian, ef.maxRollback)
+	binary.Write(&w, binary.BigEndian, ef.binSize)
+	binary.Write(&w, binary.BigEndian, ef.maxReplacements)
+	binary.Write(&w, binary.BigEndian, ef.minRegisteredBlocks)
+	binary.Write(&w, binary.BigEndian, ef.lastKnownHeight)
+	binary.Write(&w, binary.BigEndian, ef.numBlocksRegistered)
+
+	// Put all the observed transactions in a sorted list.
+	ots := make([]*observedTransaction, 0, len(ef.observed))
+	for _, o := range ef.observed {
+		ots = append(ots, o)
+	}
+	sort.Sort(observedTxSet(ots))
+	serializeObservedTransactions(&w, ots)
+
+	// Save all the bins.
+	for _, list := range ef.bin {
+		serializeObservedTransactions(&w, list)
+	}
+
+	// Dropped transactions.
+	binary.Write(&w, binary.BigEndian, uint32(len(ef.dropped)))
+	for _, registered := range ef.dropped {
+		w.Write(registered.hash[:])
+		serializeObservedTransactions(&w, registered.transactions)
+	}
+
+	return FeeEstimatorState(w.Bytes())
+}
+
+// RestoreFeeEstimator takes a FeeEstimatorState that was previously returned
+// by Save and restores it to a FeeEstimator which is ready to continue
+// tracking the chain from the passed best height.
+//
+// An error is returned when the state was saved with an unsupported version
+// of the format, when it is malformed, or when it is stale.  A state is
+// considered stale when it was saved at a height greater than the passed
+// best height, which happens when the chain was reorganized or rebuilt in
+// the meantime, or more than estimateFeeMaxSnapshotAge blocks below it.
+// States which are only slightly behind are fast-forwarded to the best
+// height.
+func RestoreFeeEstimator(data FeeEstimatorState, bestHeight int32) (*FeeEstimator, error) {
+	r := bytes.NewReader(data)
+
+	// Check version
+	var version uint32
+	err := binary.Read(r, binary.BigEndian, &version)
+	if err != nil {
+		return nil, err
+	}
+	if version != estimateFeeSaveVersion {
+		return nil, fmt.Errorf("unsupported fee estimator state version "+
+			"%d (expected %d)", version, estimateFeeSaveVersion)
+	}
+
+	ef := &FeeEstimator{
+		observed: make(map[chainhash.Hash]*observedTransaction),
+	}
+
+	// Read basic parameters.
+	fields := []interface{}{
+		&ef.maxRollback,
+		&ef.binSize,
+		&ef.maxReplacements,
+		&ef.minRegisteredBlocks,
+		&ef.lastKnownHeight,
+		&ef.numBlocksRegistered,
+	}
+	for _, field := range fields {
+		if err := binary.Read(r, binary.BigEndian, field); err != nil {
+			return nil, err
+		}
+	}
+	if ef.binSize <= 0 || ef.maxReplacements <= 0 {
+		return nil, errors.New("invalid fee estimator parameters")
+	}
+
+	// Read the observed transactions.
+	ots, err := deserializeObservedTransactions(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range ots {
+		ef.observed[o.hash] = o
+	}
+
+	// Read bins.
+	for i := range ef.bin {
+		list, err := deserializeObservedTransactions(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(list) > int(ef.binSize) {
+			return nil, fmt.Errorf("fee estimator bin %d has %d "+
+				"transactions which exceeds the bin size of %d",
+				i, len(list), ef.binSize)
+		}
+		for _, o := range list {
+			if o.mined-o.observed-1 != int32(i) {
+				return nil, fmt.Errorf("transaction %v is in "+
+					"the wrong fee estimator bin", o.hash)
+			}
+		}
+		ef.bin[i] = list
+	}
+
+	// Read dropped transactions.
+	var numDropped uint32
+	err = binary.Read(r, binary.BigEndian, &numDropped)
+	if err != nil {
+		return nil, err
+	}
+	if numDropped > ef.maxRollback {
+		return nil, errors.New("max rollback exceeded")
+	}
+	ef.dropped = make([]*registeredBlock, 0, ef.maxRollback)
+	for i := uint32(0); i < numDropped; i++ {
+		var registered registeredBlock
+		if _, err := io.ReadFull(r, registered.hash[:]); err != nil {
+			return nil, err
+		}
+		registered.transactions, err = deserializeObservedTransactions(r)
+		if err != nil {
+			return nil, err
+		}
+		ef.dropped = append(ef.dropped, &registered)
+	}
+
+	// Nothing more can be done with a state that never saw a block.
+	if ef.lastKnownHeight == mining.UnminedHeight {
+		return ef, nil
+	}
+
+	// Discard the state if it no longer describes the best chain.
+	if ef.lastKnownHeight > bestHeight {
+		return nil, fmt.Errorf("fee estimator state at height %d is "+
+			"ahead of the best chain height %d", ef.lastKnownHeight,
+			bestHeight)
+	}
+	if bestHeight-ef.lastKnownHeight > estimateFeeMaxSnapshotAge {
+		return nil, fmt.Errorf("fee estimator state at height %d is "+
+			"too far behind the best chain height %d",
+			ef.lastKnownHeight, bestHeight)
+	}
+
+	// Fast-forward a state which is slightly behind.  The blocks in
+	// between were never registered, so the rollback history no longer
+	// lines up with the chain and transactions which have been pending for
+	// too long are pruned as RegisterBlock would have done.
+	if ef.lastKnownHeight < bestHeight {
+		ef.lastKnownHeight = bestHeight
+		ef.dropped = ef.dropped[:0]
+		for hash, o := range ef.observed {
+			if bestHeight-o.observed >= estimateFeeDepth {
+				delete(ef.observed, hash)
+			}
+		}
+	}
+
+	return ef, nil
+}
@@ -0,0 +1,406 @@
+// Copyright (c) 2016 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mempool
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/mining"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// estimateFeeTester is used to create transactions and blocks which are fed
+// to a fee estimator.
+type estimateFeeTester struct {
+	ef      *FeeEstimator
+	version int32
+	height  int32
+}
+
+// newTestTx returns a transaction description for a new unique transaction
+// paying the given fee which was observed at the current height.
+func (eft *estimateFeeTester) newTestTx(fee ltcutil.Amount) *TxDesc {
+	eft.version++
+	tx := ltcutil.NewTx(&wire.MsgTx{
+		Version: eft.version,
+		TxIn:    []*wire.TxIn{{Sequence: wire.MaxTxInSequenceNum}},
+		TxOut:   []*wire.TxOut{{Value: 1}},
+	})
+
+	return &TxDesc{
+		TxDesc: mining.TxDesc{
+			Tx:     tx,
+			Height: eft.height,
+			Fee:    int64(fee),
+		},
+	}
+}
+
+// newBlock registers a new block containing the passed transactions with the
+// fee estimator.
+func (eft *estimateFeeTester) newBlock(txs []*TxDesc) *ltcutil.Block {
+	eft.height++
+	msgBlock := &wire.MsgBlock{
+		Header: wire.BlockHeader{Nonce: uint32(eft.height)},
+	}
+	for _, tx := range txs {
+		msgBlock.AddTransaction(tx.Tx.MsgTx())
+	}
+
+	block := ltcutil.NewBlock(msgBlock)
+	block.SetHeight(eft.height)
+	eft.ef.RegisterBlock(block)
+	return block
+}
+
+// populate feeds a fee estimator a number of blocks with transactions of
+// varying fees and confirmation times, leaving some transactions unmined.
+func (eft *estimateFeeTester) populate() {
+	// Register an initial block so the estimator starts observing.
+	eft.newBlock(nil)
+
+	var pending []*TxDesc
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 4; j++ {
+			txD := eft.newTestTx(ltcutil.Amount(1000 * (i + j + 1)))
+			eft.ef.ObserveTransaction(txD)
+			pending = append(pending, txD)
+		}
+
+		// Mine every other pending transaction so that there is a mix
+		// of confirmation times.
+		var mined, remaining []*TxDesc
+		for k, txD := range pending {
+			if k%2 == 0 {
+				mined = append(mined, txD)
+			} else {
+				remaining = append(remaining, txD)
+			}
+		}
+		pending = remaining
+		eft.newBlock(mined)
+	}
+}
+
+// estimates returns all estimates the fee estimator can provide.
+func (eft *estimateFeeTester) estimates(t *testing.T, ef *FeeEstimator) []BtcPerKilobyte {
+	estimates := make([]BtcPerKilobyte, estimateFeeDepth)
+	for i := range estimates {
+		var err error
+		estimates[i], err = ef.EstimateFee(uint32(i + 1))
+		if err != nil {
+			t.Fatalf("EstimateFee(%d): unexpected error: %v", i+1,
+				err)
+		}
+	}
+	return estimates
+}
+
+// TestEstimateFeeSaveRestore ensures a populated fee estimator can be saved
+// and restored and that the restored estimator provides the same estimates
+// and continues to track the chain.
+func TestEstimateFeeSaveRestore(t *testing.T) {
+	t.Parallel()
+
+	eft := &estimateFeeTester{ef: NewFeeEstimator(
+		DefaultEstimateFeeMaxRollback,
+		DefaultEstimateFeeMinRegisteredBlocks)}
+	eft.populate()
+
+	expected := eft.estimates(t, eft.ef)
+	if expected[0] <= 0 {
+		t.Fatalf("populated estimator provided no estimate: %v",
+			expected[0])
+	}
+
+	saved := eft.ef.Save()
+	restored, err := RestoreFeeEstimator(saved, eft.height)
+	if err != nil {
+		t.Fatalf("RestoreFeeEstimator: unexpected error: %v", err)
+	}
+
+	// The restored estimator must provide the same estimates and
+	// serialize identically.
+	got := eft.estimates(t, restored)
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("estimate for %d blocks mismatch -- got %v, "+
+				"want %v", i+1, got[i], expected[i])
+		}
+	}
+	if !bytes.Equal(restored.Save(), saved) {
+		t.Fatal("restored estimator does not serialize to the " +
+			"same state")
+	}
+
+	// Both estimators must agree after the same block is registered and
+	// rolled back.
+	block := eft.newBlock(nil)
+	restored.RegisterBlock(block)
+	if !bytes.Equal(restored.Save(), eft.ef.Save()) {
+		t.Fatal("estimators differ after registering a block")
+	}
+	if err := eft.ef.Rollback(block.Hash()); err != nil {
+		t.Fatalf("Rollback: unexpected error: %v", err)
+	}
+	if err := restored.Rollback(block.Hash()); err != nil {
+		t.Fatalf("Rollback: unexpected error: %v", err)
+	}
+	if !bytes.Equal(restored.Save(), eft.ef.Save()) {
+		t.Fatal("estimators differ after rolling back a block")
+	}
+}
+
+// TestEstimateFeeRestoreStale ensures saved fee estimator states which do not
+// match the current best chain are handled properly.
+func TestEstimateFeeRestoreStale(t *testing.T) {
+	t.Parallel()
+
+	eft := &estimateFeeTester{ef: NewFeeEstimator(
+		DefaultEstimateFeeMaxRollback,
+		DefaultEstimateFeeMinRegisteredBlocks)}
+	eft.populate()
+	expected := eft.estimates(t, eft.ef)
+	saved := eft.ef.Save()
+
+	// A state which is ahead of or too far behind the best chain must be
+	// discarded.
+	tests := []int32{
+		eft.height - 1,
+		eft.height + estimateFeeMaxSnapshotAge + 1,
+	}
+	for _, bestHeight := range tests {
+		_, err := RestoreFeeEstimator(saved, bestHeight)
+		if err == nil {
+			t.Fatalf("RestoreFeeEstimator: restored state at "+
+				"height %d with best height %d", eft.height,
+				bestHeight)
+		}
+	}
+
+	// A state which is only slightly behind must be restored and
+	// fast-forwarded to the best height.
+	bestHeight := eft.height + estimateFeeMaxSnapshotAge
+	restored, err := RestoreFeeEstimator(saved, bestHeight)
+	if err != nil {
+		t.Fatalf("RestoreFeeEstimator: unexpected error: %v", err)
+	}
+	if restored.LastKnownHeight() != bestHeight {
+		t.Fatalf("unexpected last known height -- got %d, want %d",
+			restored.LastKnownHeight(), bestHeight)
+	}
+	got := eft.estimates(t, restored)
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("estimate for %d blocks mismatch -- got %v, "+
+				"want %v", i+1, got[i], expected[i])
+		}
+	}
+	if len(restored.observed) != 0 {
+		t.Fatalf("fast-forwarded estimator still tracks %d expired "+
+			"transactions", len(restored.observed))
+	}
+
+	// A state saved with a different version of the format must be
+	// discarded.
+	badVersion := make([]byte, len(saved))
+	copy(badVersion, saved)
+	binary.BigEndian.PutUint32(badVersion, estimateFeeSaveVersion+1)
+	if _, err := RestoreFeeEstimator(badVersion, eft.height); err == nil {
+		t.Fatal("RestoreFeeEstimator: restored state with " +
+			"unsupported version")
+	}
+
+	// A truncated state must be discarded.
+	_, err = RestoreFeeEstimator(saved[:len(saved)-1], eft.height)
+	if err == nil {
+		t.Fatal("RestoreFeeEstimator: restored truncated state")
+	}
+}
+
+// addConfirmedTxns adds count transactions paying the given fee rate which
+// were mined at the given height after the given number of blocks directly to
+// the bins of the passed fee estimator.
+func addConfirmedTxns(ef *FeeEstimator, count int, feeRate SatoshiPerByte, mined, blocks int32) {
+	for i := 0; i < count; i++ {
+		o := &observedTransaction{
+			feeRate:  feeRate,
+			observed: mined - blocks,
+			mined:    mined,
+		}
+		binary.BigEndian.PutUint32(o.hash[:], uint32(len(ef.bin[blocks-1])))
+		binary.BigEndian.PutUint32(o.hash[4:], uint32(blocks))
+		binary.BigEndian.PutUint32(o.hash[8:], uint32(mined))
+		ef.bin[blocks-1] = append(ef.bin[blocks-1], o)
+	}
+}
+
+// TestEstimateSmartFee ensures smart fee estimates are calculated properly
+// for both estimate modes.
+func TestEstimateSmartFee(t *testing.T) {
+	t.Parallel()
+
+	const bestHeight = 1000
+	newEstimator := func() *FeeEstimator {
+		ef := NewFeeEstimator(DefaultEstimateFeeMaxRollback,
+			DefaultEstimateFeeMinRegisteredBlocks)
+		ef.lastKnownHeight = bestHeight
+		ef.numBlocksRegistered = DefaultEstimateFeeMinRegisteredBlocks
+		return ef
+	}
+
+	// Recent transactions paying higher fees are more likely to have been
+	// confirmed in the next block.
+	recent := newEstimator()
+	addConfirmedTxns(recent, 10, 100, bestHeight, 1)
+	addConfirmedTxns(recent, 8, 50, bestHeight, 1)
+	addConfirmedTxns(recent, 2, 50, bestHeight, 3)
+	addConfirmedTxns(recent, 5, 10, bestHeight, 1)
+	addConfirmedTxns(recent, 5, 10, bestHeight, 5)
+
+	// Old transactions are only taken into account by conservative
+	// estimates.
+	old := newEstimator()
+	addConfirmedTxns(old, 20, 5, bestHeight-100, 1)
+
+	// Transactions which all took a few blocks to be confirmed.
+	slow := newEstimator()
+	addConfirmedTxns(slow, 10, 20, bestHeight, 3)
+
+	tests := []struct {
+		name       string
+		ef         *FeeEstimator
+		confTarget uint32
+		mode       EstimateMode
+		feeRate    SatoshiPerByte
+		blocks     uint32
+		err        bool
+	}{
+		{
+			name:       "economical next block",
+			ef:         recent,
+			confTarget: 1,
+			mode:       EstimateModeEconomical,
+			feeRate:    50,
+			blocks:     1,
+		},
+		{
+			name:       "conservative next block",
+			ef:         recent,
+			confTarget: 1,
+			mode:       EstimateModeConservative,
+			feeRate:    100,
+			blocks:     1,
+		},
+		{
+			name:       "economical 3 blocks",
+			ef:         recent,
+			confTarget: 3,
+			mode:       EstimateModeEconomical,
+			feeRate:    50,
+			blocks:     3,
+		},
+		{
+			name:       "conservative 3 blocks",
+			ef:         recent,
+			confTarget: 3,
+			mode:       EstimateModeConservative,
+			feeRate:    50,
+			blocks:     3,
+		},
+		{
+			name:       "conservative 5 blocks",
+			ef:         recent,
+			confTarget: 5,
+			mode:       EstimateModeConservative,
+			feeRate:    10,
+			blocks:     5,
+		},
+		{
+			name:       "target beyond tracked depth",
+			ef:         recent,
+			confTarget: 1008,
+			mode:       EstimateModeConservative,
+			feeRate:    10,
+			blocks:     estimateFeeDepth,
+		},
+		{
+			name:       "unsatisfiable target",
+			ef:         slow,
+			confTarget: 1,
+			mode:       EstimateModeEconomical,
+			feeRate:    20,
+			blocks:     3,
+		},
+		{
+			name:       "economical ignores old blocks",
+			ef:         old,
+			confTarget: 1,
+			mode:       EstimateModeEconomical,
+			err:        true,
+		},
+		{
+			name:       "conservative uses old blocks",
+			ef:         old,
+			confTarget: 1,
+			mode:       EstimateModeConservative,
+			feeRate:    5,
+			blocks:     1,
+		},
+		{
+			name: "not enough blocks",
+			ef: NewFeeEstimator(DefaultEstimateFeeMaxRollback,
+				DefaultEstimateFeeMinRegisteredBlocks),
+			confTarget: 1,
+			mode:       EstimateModeConservative,
+			err:        true,
+		},
+		{
+			name:       "no transactions",
+			ef:         newEstimator(),
+			confTarget: 1,
+			mode:       EstimateModeConservative,
+			err:        true,
+		},
+		{
+			name:       "zero target",
+			ef:         recent,
+			confTarget: 0,
+			mode:       EstimateModeConservative,
+			err:        true,
+		},
+	}
+
+	for _, test := range tests {
+		feeRate, blocks, err := test.ef.EstimateSmartFee(
+			test.confTarget, test.mode)
+		if test.err {
+			if err == nil {
+				t.Errorf("%s: did not receive expected error",
+					test.name)
+			}
+			if feeRate != -1 {
+				t.Errorf("%s: unexpected fee rate for error -- "+
+					"got %v, want -1", test.name, feeRate)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		if want := test.feeRate.ToBtcPerKb(); feeRate != want {
+			t.Errorf("%s: unexpected fee rate -- got %v, want %v",
+				test.name, feeRate, want)
+		}
+		if blocks != test.blocks {
+			t.Errorf("%s: unexpected blocks -- got %d, want %d",
+				test.name, blocks, test.blocks)
+		}
+	}
+}
@@ -30,14 +30,74 @@ const (
 	// inclusion when generating block templates.
 	DefaultBlockPrioritySize = 50000
 
-	// orphanTTL is the maximum amount of time an orphan is allowed to
-	// stay in the orphan pool before it expires and is evicted during the
-	// next scan.
-	orphanTTL = time.Minute * 15
-
-	// orphanExpireScanInterval is the minimum amount of time in between
-	// scans of the orphan pool to evict expired transactions.
+	// DefaultOrphanTTL is the default maximum amount of time an orphan is
+	// allowed to stay in the orphan pool before it expires and is evicted
+	// during the next scan.
+	DefaultOrphanTTL = time.Minute * 15
+
+	// orphanExpireScanInterval is the maximum amount of time in between
+	// scans of the orphan pool to evict expired transactions.  The orphan
+	// pool is scanned more often when the orphan TTL is shorter.
 	orphanExpireScanInterval = time.Minute * 5
+
+	// DefaultExpiry is the default maximum amount of time a transaction is
+	// allowed to stay in the main pool before it expires and is evicted
+	// along with its descendants.
+	DefaultExpiry = time.Hour * 24 * 14
+
+	// poolExpireScanInterval is the minimum amount of time in between
+	// scans of the main pool to evict expired transactions.
+	poolExpireScanInterval = time.Minute * 5
+
+	// MaxReplacementEvictions is the maximum number of transactions that
+	// may be evicted from the memory pool when accepting a BIP 125
+	// replacement transaction.  This includes both the transactions which
+	// directly conflict with the replacement and all of their descendants.
+	MaxReplacementEvictions = 100
+
+	// DefaultMaxAncestorCount is the default maximum number of ancestors in
+	// the pool a transaction may have, including itself.
+	DefaultMaxAncestorCount = 25
+
+	// DefaultMaxAncestorSize is the default maximum total virtual size of
+	// the ancestors in the pool a transaction may have, including itself.
+	DefaultMaxAncestorSize = 101000
+
+	// DefaultMaxDescendantCount is the default maximum number of
+	// descendants in the pool a transaction may have, including itself.
+	DefaultMaxDescendantCount = 25
+
+	// DefaultMaxDescendantSize is the default maximum total virtual size
+	// of the descendants in the pool a transaction may have, including
+	// itself.
+	DefaultMaxDescendantSize = 101000
+
+	// MaxPackageCount is the maximum number of transactions in a package
+	// of transactions which is processed as a unit.
+	MaxPackageCount = 25
+
+	// MaxPackageSize is the maximum total virtual size of the transactions
+	// in a package of transactions which is processed as a unit.
+	MaxPackageSize = 101000
+
+	// DefaultMaxPoolSize is the default maximum total size in bytes of the
+	// transactions in the memory pool.
+	DefaultMaxPoolSize = 300 * 1000 * 1000
+
+	// incrementalRelayFee is the fee rate in satoshi/kB which is added to
+	// the fee rate of the transactions evicted when limiting the size of
+	// the pool in order to determine the minimum fee rate required for
+	// transactions to replace them.
+	incrementalRelayFee = 1000
+
+	// rollingFeeHalfLife is the half-life of the minimum fee rate required
+	// for transactions to be accepted once it was raised due to limiting
+	// the size of the pool.  It decays faster when the pool is less full.
+	rollingFeeHalfLife = time.Hour * 12
+
+	// rollingFeeUpdateInterval is the minimum amount of time in between
+	// updates of the decaying minimum fee rate.
+	rollingFeeUpdateInterval = time.Second * 10
 )
 
 // Tag represents an identifier to use for tagging orphan transactions.  The
@@ -45,6 +105,65 @@ const (
 // so that orphans can be identified by which peer first relayed them.
 type Tag uint64
 
+// RemovalReason identifies why a transaction was removed from the memory pool.
+type RemovalReason int
+
+// These constants define the reasons a transaction may be removed from the
+// memory pool.
+const (
+	// RemovalReasonConfirmed indicates the transaction was included in a
+	// block connected to the main chain.
+	RemovalReasonConfirmed RemovalReason = iota
+
+	// RemovalReasonReplaced indicates the transaction was replaced by a
+	// BIP 125 replacement transaction or spent an output of a replaced
+	// transaction.
+	RemovalReasonReplaced
+
+	// RemovalReasonConflict indicates the transaction double spent an
+	// output spent by a transaction in a block connected to the main chain
+	// or spent an output of such a conflicting transaction.
+	RemovalReasonConflict
+
+	// RemovalReasonInvalid indicates the transaction is no longer valid,
+	// such as when it could not be reinserted into the pool after the block
+	// containing it was disconnected from the main chain.
+	RemovalReasonInvalid
+
+	// RemovalReasonOrphanTimeout indicates the orphan transaction expired
+	// before its missing parents were received.
+	RemovalReasonOrphanTimeout
+
+	// RemovalReasonSizeLimit indicates the transaction was evicted along
+	// with its descendants because they paid the lowest fee rate when the
+	// pool exceeded its maximum size.
+	RemovalReasonSizeLimit
+
+	// RemovalReasonExpired indicates the transaction was evicted along
+	// with its descendants because it stayed in the pool for longer than
+	// the expiry allowed by the policy.
+	RemovalReasonExpired
+)
+
+// Map of removal reasons back to their constant names for pretty printing.
+var removalReasonStrings = map[RemovalReason]string{
+	RemovalReasonConfirmed:     "confirmed",
+	RemovalReasonReplaced:      "replaced",
+	RemovalReasonConflict:      "conflict",
+	RemovalReasonInvalid:       "invalid",
+	RemovalReasonOrphanTimeout: "orphan_timeout",
+	RemovalReasonSizeLimit:     "size_limit",
+	RemovalReasonExpired:       "expired",
+}
+
+// String returns the RemovalReason in human-readable form.
+func (r RemovalReason) String() string {
+	if s, ok := removalReasonStrings[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown RemovalReason (%d)", int(r))
+}
+
 // Config is a descriptor containing the memory pool configuration.
 type Config struct {
 	// Policy defines the various mempool configuration options related
@@ -85,10 +204,37 @@ type Config struct {
 	// HashCache defines the transaction hash mid-state cache to use.
 	HashCache *txscript.HashCache
 
+	// ScriptCache defines the script execution cache to use.  The inputs
+	// of accepted transactions are added to it so their scripts are not
+	// executed again when they are included in a block.
+	ScriptCache *txscript.ScriptCache
+
 	// AddrIndex defines the optional address index instance to use for
 	// indexing the unconfirmed transactions in the memory pool.
 	// This can be nil if the address index is not enabled.
 	AddrIndex *indexers.AddrIndex
+
+	// FeeEstimator provides a feeEstimator. If it is not nil, the mempool
+	// records all new transactions it observes into the feeEstimator.
+	FeeEstimator *FeeEstimator
+
+	// OnTxAdded defines an optional function to invoke with every
+	// transaction added to the pool along with the sequence number of the
+	// update, which is the number of times the pool was updated before.
+	//
+	// NOTE: The function is invoked with the mempool lock held, so it must
+	// not call back into the mempool.
+	OnTxAdded func(tx *ltcutil.Tx, sequence uint64)
+
+	// OnTxRemoved defines an optional function to invoke with every
+	// transaction removed from the pool along with the reason it was
+	// removed and the sequence number of the update.  It is also invoked
+	// with orphan transactions that expire, which do not update the pool
+	// and are reported with the sequence number of the next update.
+	//
+	// NOTE: The function is invoked with the mempool lock held, so it must
+	// not call back into the mempool.
+	OnTxRemoved func(tx *ltcutil.Tx, reason RemovalReason, sequence uint64)
 }
 
 // Policy houses the policy (configuration parameters) which is used to
@@ -121,6 +267,26 @@ type Policy struct {
 	// of big orphans.
 	MaxOrphanTxSize int
 
+	// OrphanTTL is the maximum amount of time an orphan transaction is
+	// kept in the orphan pool before it expires.  DefaultOrphanTTL is used
+	// when it is zero.
+	OrphanTTL time.Duration
+
+	// MaxAncestorCount and MaxAncestorSize are the maximum number and
+	// total virtual size of the ancestors in the main pool a transaction
+	// may have in order to be accepted, including the transaction itself.
+	// Neither is limited when it is zero.
+	MaxAncestorCount int
+	MaxAncestorSize  int64
+
+	// MaxDescendantCount and MaxDescendantSize are the maximum number and
+	// total virtual size of the descendants in the main pool any
+	// transaction in the pool may have once a new transaction is accepted,
+	// including the transaction itself.  Neither is limited when it is
+	// zero.
+	MaxDescendantCount int
+	MaxDescendantSize  int64
+
 	// MaxSigOpCostPerTx is the cumulative maximum cost of all the signature
 	// operations in a single transaction we will relay or mine.  It is a
 	// fraction of the max signature operations for a block.
@@ -129,6 +295,25 @@ type Policy struct {
 	// MinRelayTxFee defines the minimum transaction fee in BTC/kB to be
 	// considered a non-zero fee.
 	MinRelayTxFee ltcutil.Amount
+
+	// MaxPoolSize is the maximum total size in bytes of the transactions
+	// in the main pool.  Once it is exceeded, the transactions paying the
+	// lowest fee rates when evaluated along with their descendants are
+	// evicted and the minimum fee rate required for new transactions
+	// rises accordingly.  The size of the pool is not limited when it is
+	// zero.
+	MaxPoolSize int64
+
+	// Expiry is the maximum amount of time a transaction is kept in the
+	// main pool before it expires and is evicted along with its
+	// descendants.  Transactions do not expire when it is zero.
+	Expiry time.Duration
+
+	// AcceptRBF defines whether to accept transactions which replace
+	// transactions already in the pool that signal replaceability as
+	// defined by BIP 125.  When false, any transaction which spends an
+	// output already spent by a transaction in the pool is rejected.
+	AcceptRBF bool
 }
 
 // TxDesc is a descriptor containing a transaction in the mempool along with
@@ -139,6 +324,46 @@ type TxDesc struct {
 	// StartingPriority is the priority of the transaction when it was added
 	// to the pool.
 	StartingPriority float64
+
+	// ReplacedTxns houses the hashes of the transactions that were evicted
+	// from the pool when the transaction was accepted as a BIP 125
+	// replacement.  It includes both the transactions which directly
+	// conflicted with it and all of their descendants.  It is nil when the
+	// transaction did not replace anything.
+	ReplacedTxns []*chainhash.Hash
+}
+
+// PackageResult houses the result of processing a package of transactions as a
+// unit.
+type PackageResult struct {
+	// TxDescs houses the descriptors of the transactions in the package in
+	// the same order, including those which were already in the pool.
+	TxDescs []*TxDesc
+
+	// Accepted houses the descriptors of the transactions in the package
+	// which were added to the pool followed by those of any orphans which
+	// were accepted as a result.
+	Accepted []*TxDesc
+
+	// FeeRate is the fee rate per kB of the transactions in the package
+	// which were added to the pool.  It is zero when all of them were
+	// already in the pool.
+	FeeRate ltcutil.Amount
+}
+
+// TestAcceptResult houses the result of checking whether or not a transaction
+// would be accepted to the memory pool.
+type TestAcceptResult struct {
+	// Tx is the transaction which was checked.
+	Tx *ltcutil.Tx
+
+	// Fee is the total fee the transaction pays.  It is only set when the
+	// transaction would be accepted.
+	Fee int64
+
+	// Err is the reason the transaction would be rejected or nil when it
+	// would be accepted.
+	Err error
 }
 
 // orphanTx is normal transaction that references an ancestor transaction
@@ -155,7 +380,8 @@ type orphanTx struct {
 // peers.
 type TxPool struct {
 	// The following variables must only be used atomically.
-	lastUpdated int64 // last time pool was updated
+	lastUpdated int64  // last time pool was updated
+	txUpdates   uint64 // number of times the pool was updated
 
 	mtx           sync.RWMutex
 	cfg           Config
@@ -166,11 +392,35 @@ type TxPool struct {
 	pennyTotal    float64 // exponentially decaying total for penny spends.
 	lastPennyUnix int64   // unix time of last ``penny spend''
 
+	// poolSize is the total serialized size of the transactions in the
+	// main pool.
+	poolSize int64
+
+	// rollingMinFee is the minimum fee rate in satoshi/kB required for
+	// transactions to be accepted due to limiting the size of the pool.
+	// It only starts decaying once a block has been connected after it
+	// was last raised.
+	rollingMinFee         float64
+	lastRollingFeeUpdate  time.Time
+	blockSinceLastFeeBump bool
+
 	// nextExpireScan is the time after which the orphan pool will be
-	// scanned in order to evict orphans.  This is NOT a hard deadline as
-	// the scan will only run when an orphan is added to the pool as opposed
-	// to on an unconditional timer.
+	// scanned in order to evict orphans when an orphan is added to the
+	// pool.  The pool is also scanned on a timer once it is started.
 	nextExpireScan time.Time
+
+	// nextPoolExpireScan is the time after which the main pool will be
+	// scanned in order to evict expired transactions when a transaction is
+	// added to the pool.
+	nextPoolExpireScan time.Time
+
+	// unbroadcast houses the hashes of the transactions in the main pool
+	// which were submitted locally and are not yet known to have been
+	// received by any peer.
+	unbroadcast map[chainhash.Hash]struct{}
+
+	wg   sync.WaitGroup
+	quit chan struct{}
 }
 
 // Ensure the TxPool type implements the mining.TxSource interface.
@@ -244,54 +494,100 @@ func (mp *TxPool) RemoveOrphansByTag(tag Tag) uint64 {
 	return numEvicted
 }
 
-// limitNumOrphans limits the number of orphan transactions by evicting a random
-// orphan if adding a new one would cause it to overflow the max allowed.
+// orphanTTL returns the maximum amount of time an orphan is kept in the orphan
+// pool before it expires.
+func (mp *TxPool) orphanTTL() time.Duration {
+	if mp.cfg.Policy.OrphanTTL <= 0 {
+		return DefaultOrphanTTL
+	}
+	return mp.cfg.Policy.OrphanTTL
+}
+
+// expireScanInterval returns the maximum amount of time in between scans of
+// the orphan pool to evict expired orphans.
+func (mp *TxPool) expireScanInterval() time.Duration {
+	if ttl := mp.orphanTTL(); ttl < orphanExpireScanInterval {
+		return ttl
+	}
+	return orphanExpireScanInterval
+}
+
+// expireOrphans removes all orphans which expired as of the passed time from
+// the orphan pool along with the orphans which redeem them.
 //
 // This function MUST be called with the mempool lock held (for writes).
-func (mp *TxPool) limitNumOrphans() error {
-	// Scan through the orphan pool and remove any expired orphans when it's
-	// time.  This is done for efficiency so the scan only happens
-	// periodically instead of on every orphan added to the pool.
-	if now := time.Now(); now.After(mp.nextExpireScan) {
-		origNumOrphans := len(mp.orphans)
-		for _, otx := range mp.orphans {
-			if now.After(otx.expiration) {
-				// Remove redeemers too because the missing
-				// parents are very unlikely to ever materialize
-				// since the orphan has already been around more
-				// than long enough for them to be delivered.
-				mp.removeOrphan(otx.tx, true)
-			}
+func (mp *TxPool) expireOrphans(now time.Time) {
+	origNumOrphans := len(mp.orphans)
+
+	// Keep track of the orphans prior to the scan when the removal of
+	// expired orphans needs to be reported.
+	var origOrphans map[chainhash.Hash]*orphanTx
+	if mp.cfg.OnTxRemoved != nil {
+		origOrphans = make(map[chainhash.Hash]*orphanTx, origNumOrphans)
+		for hash, otx := range mp.orphans {
+			origOrphans[hash] = otx
 		}
+	}
 
-		// Set next expiration scan to occur after the scan interval.
-		mp.nextExpireScan = now.Add(orphanExpireScanInterval)
+	for _, otx := range mp.orphans {
+		if now.After(otx.expiration) {
+			// Remove redeemers too because the missing parents are
+			// very unlikely to ever materialize since the orphan has
+			// already been around more than long enough for them to
+			// be delivered.
+			mp.removeOrphan(otx.tx, true)
+		}
+	}
 
-		numOrphans := len(mp.orphans)
-		if numExpired := origNumOrphans - numOrphans; numExpired > 0 {
-			log.Debugf("Expired %d %s (remaining: %d)", numExpired,
-				pickNoun(numExpired, "orphan", "orphans"),
-				numOrphans)
+	// Report the expired orphans along with any orphans which were removed
+	// because they redeem them.
+	sequence := atomic.LoadUint64(&mp.txUpdates)
+	for hash, otx := range origOrphans {
+		if _, exists := mp.orphans[hash]; !exists {
+			mp.cfg.OnTxRemoved(otx.tx, RemovalReasonOrphanTimeout,
+				sequence)
 		}
 	}
 
-	// Nothing to do if adding another orphan will not cause the pool to
-	// exceed the limit.
-	if len(mp.orphans)+1 <= mp.cfg.Policy.MaxOrphanTxs {
-		return nil
+	// Set next expiration scan to occur after the scan interval.
+	mp.nextExpireScan = now.Add(mp.expireScanInterval())
+
+	numOrphans := len(mp.orphans)
+	if numExpired := origNumOrphans - numOrphans; numExpired > 0 {
+		log.Debugf("Expired %d %s (remaining: %d)", numExpired,
+			pickNoun(numExpired, "orphan", "orphans"), numOrphans)
 	}
+}
 
-	// Remove a random entry from the map.  For most compilers, Go's
-	// range statement iterates starting at a random item although
-	// that is not 100% guaranteed by the spec.  The iteration order
-	// is not important here because an adversary would have to be
-	// able to pull off preimage attacks on the hashing function in
-	// order to target eviction of specific entries anyways.
-	for _, otx := range mp.orphans {
-		// Don't remove redeemers in the case of a random eviction since
-		// it is quite possible it might be needed again shortly.
-		mp.removeOrphan(otx.tx, false)
-		break
+// limitNumOrphans limits the number of orphan transactions by evicting random
+// orphans until adding a new one would not cause it to overflow the max
+// allowed.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) limitNumOrphans() error {
+	// Scan through the orphan pool and remove any expired orphans when it's
+	// time.  This is done for efficiency so the scan only happens
+	// periodically instead of on every orphan added to the pool.
+	if now := time.Now(); now.After(mp.nextExpireScan) {
+		mp.expireOrphans(now)
+	}
+
+	// Remove random entries from the map until there is room for another
+	// orphan.  More than one entry is only removed when the max allowed
+	// was lowered.  For most compilers, Go's range statement iterates
+	// starting at a random item although that is not 100% guaranteed by
+	// the spec.  The iteration order is not important here because an
+	// adversary would have to be able to pull off preimage attacks on the
+	// hashing function in order to target eviction of specific entries
+	// anyways.
+	for len(mp.orphans)+1 > mp.cfg.Policy.MaxOrphanTxs {
+		for _, otx := range mp.orphans {
+			// Don't remove redeemers in the case of a random
+			// eviction since it is quite possible it might be
+			// needed again shortly.
+			mp.removeOrphan(otx.tx, false)
+			break
+		}
 	}
 
 	return nil
@@ -314,7 +610,7 @@ func (mp *TxPool) addOrphan(tx *ltcutil.Tx, tag Tag) {
 	mp.orphans[*tx.Hash()] = &orphanTx{
 		tx:         tx,
 		tag:        tag,
-		expiration: time.Now().Add(orphanTTL),
+		expiration: time.Now().Add(mp.orphanTTL()),
 	}
 	for _, txIn := range tx.MsgTx().TxIn {
 		if _, exists := mp.orphansByPrev[txIn.PreviousOutPoint]; !exists {
@@ -350,7 +646,9 @@ func (mp *TxPool) maybeAddOrphan(tx *ltcutil.Tx, tag Tag) error {
 		return txRuleError(wire.RejectNonstandard, str)
 	}
 
-	// Add the orphan if the none of the above disqualified it.
+	// Add the orphan if the none of the above disqualified it.  This is
+	// done after the size check so that orphans which are too large never
+	// cause the eviction of other orphans.
 	mp.addOrphan(tx, tag)
 
 	return nil
@@ -447,14 +745,14 @@ func (mp *TxPool) HaveTransaction(hash *chainhash.Hash) bool {
 // RemoveTransaction.  See the comment for RemoveTransaction for more details.
 //
 // This function MUST be called with the mempool lock held (for writes).
-func (mp *TxPool) removeTransaction(tx *ltcutil.Tx, removeRedeemers bool) {
+func (mp *TxPool) removeTransaction(tx *ltcutil.Tx, removeRedeemers bool, reason RemovalReason) {
 	txHash := tx.Hash()
 	if removeRedeemers {
 		// Remove any transactions which rely on this one.
 		for i := uint32(0); i < uint32(len(tx.MsgTx().TxOut)); i++ {
 			prevOut := wire.OutPoint{Hash: *txHash, Index: i}
 			if txRedeemer, exists := mp.outpoints[prevOut]; exists {
-				mp.removeTransaction(txRedeemer, true)
+				mp.removeTransaction(txRedeemer, true, reason)
 			}
 		}
 	}
@@ -472,20 +770,32 @@ func (mp *TxPool) removeTransaction(tx *ltcutil.Tx, removeRedeemers bool) {
 			delete(mp.outpoints, txIn.PreviousOutPoint)
 		}
 		delete(mp.pool, *txHash)
+		delete(mp.unbroadcast, *txHash)
+		mp.poolSize -= int64(tx.MsgTx().SerializeSize())
 		atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
+		sequence := atomic.AddUint64(&mp.txUpdates, 1) - 1
+
+		if reason == RemovalReasonConfirmed {
+			mp.blockSinceLastFeeBump = true
+		}
+
+		if mp.cfg.OnTxRemoved != nil {
+			mp.cfg.OnTxRemoved(tx, reason, sequence)
+		}
 	}
 }
 
 // RemoveTransaction removes the passed transaction from the mempool. When the
 // removeRedeemers flag is set, any transactions that redeem outputs from the
 // removed transaction will also be removed recursively from the mempool, as
-// they would otherwise become orphans.
+// they would otherwise become orphans.  The passed reason is reported for all
+// removed transactions.
 //
 // This function is safe for concurrent access.
-func (mp *TxPool) RemoveTransaction(tx *ltcutil.Tx, removeRedeemers bool) {
+func (mp *TxPool) RemoveTransaction(tx *ltcutil.Tx, removeRedeemers bool, reason RemovalReason) {
 	// Protect concurrent access.
 	mp.mtx.Lock()
-	mp.removeTransaction(tx, removeRedeemers)
+	mp.removeTransaction(tx, removeRedeemers, reason)
 	mp.mtx.Unlock()
 }
 
@@ -502,7 +812,8 @@ func (mp *TxPool) RemoveDoubleSpends(tx *ltcutil.Tx) {
 	for _, txIn := range tx.MsgTx().TxIn {
 		if txRedeemer, ok := mp.outpoints[txIn.PreviousOutPoint]; ok {
 			if !txRedeemer.Hash().IsEqual(tx.Hash()) {
-				mp.removeTransaction(txRedeemer, true)
+				mp.removeTransaction(txRedeemer, true,
+					RemovalReasonConflict)
 			}
 		}
 	}
@@ -528,11 +839,13 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *ltcutil
 		StartingPriority: mining.CalcPriority(tx.MsgTx(), utxoView, height),
 	}
 	mp.pool[*tx.Hash()] = txD
+	mp.poolSize += int64(tx.MsgTx().SerializeSize())
 
 	for _, txIn := range tx.MsgTx().TxIn {
 		mp.outpoints[txIn.PreviousOutPoint] = tx
 	}
 	atomic.StoreInt64(&mp.lastUpdated, time.Now().Unix())
+	sequence := atomic.AddUint64(&mp.txUpdates, 1) - 1
 
 	// Add unconfirmed address index entries associated with the transaction
 	// if enabled.
@@ -540,6 +853,10 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *ltcutil
 		mp.cfg.AddrIndex.AddUnconfirmedTx(tx, utxoView)
 	}
 
+	if mp.cfg.OnTxAdded != nil {
+		mp.cfg.OnTxAdded(tx, sequence)
+	}
+
 	return txD
 }
 
@@ -548,14 +865,217 @@ func (mp *TxPool) addTransaction(utxoView *blockchain.UtxoViewpoint, tx *ltcutil
 // Note it does not check for double spends against transactions already in the
 // main chain.
 //
+// When the policy allows BIP 125 replacements, double spends of transactions
+// which signal replaceability are not treated as an error and instead the set
+// of conflicting transactions is returned so the caller can determine whether
+// or not the passed transaction is a valid replacement for them.
+//
 // This function MUST be called with the mempool lock held (for reads).
-func (mp *TxPool) checkPoolDoubleSpend(tx *ltcutil.Tx) error {
+func (mp *TxPool) checkPoolDoubleSpend(tx *ltcutil.Tx) (map[chainhash.Hash]*ltcutil.Tx, error) {
+	var conflicts map[chainhash.Hash]*ltcutil.Tx
 	for _, txIn := range tx.MsgTx().TxIn {
-		if txR, exists := mp.outpoints[txIn.PreviousOutPoint]; exists {
+		txR, exists := mp.outpoints[txIn.PreviousOutPoint]
+		if !exists {
+			continue
+		}
+
+		if !mp.cfg.Policy.AcceptRBF || !signalsReplacement(txR) {
 			str := fmt.Sprintf("output %v already spent by "+
 				"transaction %v in the memory pool",
 				txIn.PreviousOutPoint, txR.Hash())
-			return txRuleError(wire.RejectDuplicate, str)
+			return nil, txRuleError(wire.RejectDuplicate, str)
+		}
+
+		if conflicts == nil {
+			conflicts = make(map[chainhash.Hash]*ltcutil.Tx)
+		}
+		conflicts[*txR.Hash()] = txR
+	}
+
+	return conflicts, nil
+}
+
+// signalsReplacement returns whether or not the passed transaction signals
+// that it is replaceable as defined by BIP 125.  A transaction signals
+// replaceability when at least one of its inputs has a sequence number less
+// than 0xfffffffe.
+func signalsReplacement(tx *ltcutil.Tx) bool {
+	for _, txIn := range tx.MsgTx().TxIn {
+		if txIn.Sequence < wire.MaxTxInSequenceNum-1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateReplacement determines whether or not the passed transaction, which
+// pays the provided fee, is a valid BIP 125 replacement for the passed set of
+// conflicting transactions.  It returns the full set of transactions that must
+// be evicted from the pool in order to accept the replacement, which consists
+// of the conflicting transactions along with all of their descendants.
+//
+// The replacement is rejected when:
+//   - it would cause more than MaxReplacementEvictions transactions to be
+//     evicted
+//   - it spends an output of any of the transactions it would evict
+//   - it spends an unconfirmed output that none of the conflicting
+//     transactions also spent
+//   - it does not pay a higher fee and a higher fee rate than the evicted
+//     transactions in aggregate
+//   - the additional fee does not cover the minimum relay fee for its own
+//     size
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) validateReplacement(tx *ltcutil.Tx, txFee int64,
+	conflicts map[chainhash.Hash]*ltcutil.Tx) (map[chainhash.Hash]*TxDesc, error) {
+
+	txHash := tx.Hash()
+
+	// Gather the conflicting transactions along with all of their
+	// descendants while ensuring the number of evictions is bounded.
+	evictions := make(map[chainhash.Hash]*TxDesc)
+	conflictParents := make(map[chainhash.Hash]struct{})
+	for hash, conflict := range conflicts {
+		desc, exists := mp.pool[hash]
+		if !exists {
+			continue
+		}
+		evictions[hash] = desc
+		for _, descendant := range mp.txDescendants(conflict) {
+			evictions[*descendant.Tx.Hash()] = descendant
+		}
+		if len(evictions) > MaxReplacementEvictions {
+			str := fmt.Sprintf("replacement transaction %v would "+
+				"evict more than %d transactions", txHash,
+				MaxReplacementEvictions)
+			return nil, txRuleError(wire.RejectNonstandard, str)
+		}
+
+		for _, txIn := range conflict.MsgTx().TxIn {
+			conflictParents[txIn.PreviousOutPoint.Hash] = struct{}{}
+		}
+	}
+
+	// The replacement may not spend any outputs of the transactions it
+	// evicts since it would otherwise be an orphan once they are removed,
+	// and it may only spend unconfirmed outputs of transactions that were
+	// already being spent by the transactions it replaces.
+	for _, txIn := range tx.MsgTx().TxIn {
+		parentHash := txIn.PreviousOutPoint.Hash
+		if _, ok := evictions[parentHash]; ok {
+			str := fmt.Sprintf("replacement transaction %v spends "+
+				"output %v of a transaction it replaces",
+				txHash, txIn.PreviousOutPoint)
+			return nil, txRuleError(wire.RejectInvalid, str)
+		}
+		if !mp.isTransactionInPool(&parentHash) {
+			continue
+		}
+		if _, ok := conflictParents[parentHash]; !ok {
+			str := fmt.Sprintf("replacement transaction %v spends "+
+				"new unconfirmed output %v", txHash,
+				txIn.PreviousOutPoint)
+			return nil, txRuleError(wire.RejectNonstandard, str)
+		}
+	}
+
+	// The replacement must pay a higher absolute fee and a higher fee rate
+	// than all of the transactions it evicts in aggregate.  The fee rates
+	// are compared by cross multiplying to avoid floating point rounding.
+	var evictedFees, evictedSize int64
+	for _, desc := range evictions {
+		evictedFees += desc.Fee
+		evictedSize += GetTxVirtualSize(desc.Tx)
+	}
+	txSize := GetTxVirtualSize(tx)
+	if txFee <= evictedFees {
+		str := fmt.Sprintf("replacement transaction %v has %d fees "+
+			"which is not more than the %d fees of the transactions "+
+			"it replaces", txHash, txFee, evictedFees)
+		return nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
+	if txFee*evictedSize <= evictedFees*txSize {
+		str := fmt.Sprintf("replacement transaction %v has a fee rate "+
+			"that is not more than the %d fees over %d bytes of the "+
+			"transactions it replaces", txHash, evictedFees,
+			evictedSize)
+		return nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
+
+	// The additional fee paid by the replacement must cover the relay of
+	// the replacement itself.
+	minFee := calcMinRequiredTxRelayFee(txSize, mp.cfg.Policy.MinRelayTxFee)
+	if txFee-evictedFees < minFee {
+		str := fmt.Sprintf("replacement transaction %v pays %d "+
+			"additional fees which is under the required amount of "+
+			"%d", txHash, txFee-evictedFees, minFee)
+		return nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
+
+	return evictions, nil
+}
+
+// checkPackageLimits ensures adding the passed transaction to the main pool
+// would neither exceed the limits imposed by the policy on the number and total
+// virtual size of its own ancestors in the pool nor those on the descendants of
+// any of them.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) checkPackageLimits(tx *ltcutil.Tx) error {
+	policy := &mp.cfg.Policy
+	txHash := tx.Hash()
+	txSize := GetTxVirtualSize(tx)
+	ancestors := mp.txAncestors(tx)
+
+	// The ancestor limits include the transaction itself.
+	numAncestors := len(ancestors) + 1
+	if policy.MaxAncestorCount > 0 && numAncestors > policy.MaxAncestorCount {
+		str := fmt.Sprintf("transaction %v has too many ancestors in "+
+			"the pool: %d > %d", txHash, numAncestors,
+			policy.MaxAncestorCount)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+	ancestorSize := txSize
+	for _, ancestor := range ancestors {
+		ancestorSize += GetTxVirtualSize(ancestor.Tx)
+	}
+	if policy.MaxAncestorSize > 0 && ancestorSize > policy.MaxAncestorSize {
+		str := fmt.Sprintf("transaction %v has too large ancestors in "+
+			"the pool: %d > %d virtual bytes", txHash, ancestorSize,
+			policy.MaxAncestorSize)
+		return txRuleError(wire.RejectNonstandard, str)
+	}
+
+	if policy.MaxDescendantCount <= 0 && policy.MaxDescendantSize <= 0 {
+		return nil
+	}
+	for _, ancestor := range ancestors {
+		// The descendant limits include the ancestor itself along with
+		// the transaction.
+		descendants := mp.txDescendants(ancestor.Tx)
+		numDescendants := len(descendants) + 2
+		if policy.MaxDescendantCount > 0 &&
+			numDescendants > policy.MaxDescendantCount {
+
+			str := fmt.Sprintf("transaction %v would give ancestor "+
+				"%v too many descendants in the pool: %d > %d",
+				txHash, ancestor.Tx.Hash(), numDescendants,
+				policy.MaxDescendantCount)
+			return txRuleError(wire.RejectNonstandard, str)
+		}
+		descendantSize := GetTxVirtualSize(ancestor.Tx) + txSize
+		for _, descendant := range descendants {
+			descendantSize += GetTxVirtualSize(descendant.Tx)
+		}
+		if policy.MaxDescendantSize > 0 &&
+			descendantSize > policy.MaxDescendantSize {
+
+			str := fmt.Sprintf("transaction %v would give ancestor "+
+				"%v too large descendants in the pool: %d > %d "+
+				"virtual bytes", txHash, ancestor.Tx.Hash(),
+				descendantSize, policy.MaxDescendantSize)
+			return txRuleError(wire.RejectNonstandard, str)
 		}
 	}
 
@@ -605,12 +1125,36 @@ func (mp *TxPool) FetchTransaction(txHash *chainhash.Hash) (*ltcutil.Tx, error)
 	return nil, fmt.Errorf("transaction is not in the pool")
 }
 
-// maybeAcceptTransaction is the internal function which implements the public
-// MaybeAcceptTransaction.  See the comment for MaybeAcceptTransaction for
-// more details.
+// txAcceptance houses the details of a transaction which has been checked for
+// acceptance to the pool by checkTransactionAcceptance.
+type txAcceptance struct {
+	// missingParents houses the hashes of the unknown transactions whose
+	// outputs the transaction spends.  The other fields are not set when
+	// there are any since the transaction is an orphan.
+	missingParents []*chainhash.Hash
+
+	// utxoView houses the outputs spent by the transaction.
+	utxoView *blockchain.UtxoViewpoint
+
+	// bestHeight is the height of the main chain the transaction was
+	// checked against.
+	bestHeight int32
+
+	// fee is the fee paid by the transaction.
+	fee int64
+
+	// evictions houses the transactions in the pool, along with their
+	// descendants, which the transaction replaces.
+	evictions map[chainhash.Hash]*TxDesc
+}
+
+// checkTransactionAcceptance performs all of the checks which determine
+// whether or not the passed transaction may be accepted to the pool without
+// modifying the pool.  See the comment for maybeAcceptTransaction for details
+// on the flags.
 //
 // This function MUST be called with the mempool lock held (for writes).
-func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejectDupOrphans bool) ([]*chainhash.Hash, *TxDesc, error) {
+func (mp *TxPool) checkTransactionAcceptance(tx *ltcutil.Tx, isNew, rateLimit, rejectDupOrphans, inPackage bool) (*txAcceptance, error) {
 	txHash := tx.Hash()
 
 	// If a transaction has iwtness data, and segwit isn't active yet, If
@@ -619,13 +1163,13 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 	if tx.MsgTx().HasWitness() {
 		segwitActive, err := mp.cfg.IsDeploymentActive(chaincfg.DeploymentSegwit)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		if !segwitActive {
 			str := fmt.Sprintf("transaction %v has witness data, "+
 				"but segwit isn't active yet", txHash)
-			return nil, nil, txRuleError(wire.RejectNonstandard, str)
+			return nil, txRuleError(wire.RejectNonstandard, str)
 		}
 	}
 
@@ -637,7 +1181,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 		mp.isOrphanInPool(txHash)) {
 
 		str := fmt.Sprintf("already have transaction %v", txHash)
-		return nil, nil, txRuleError(wire.RejectDuplicate, str)
+		return nil, txRuleError(wire.RejectDuplicate, str)
 	}
 
 	// Perform preliminary sanity checks on the transaction.  This makes
@@ -646,16 +1190,16 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 	err := blockchain.CheckTransactionSanity(tx)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, nil, chainRuleError(cerr)
+			return nil, chainRuleError(cerr)
 		}
-		return nil, nil, err
+		return nil, err
 	}
 
 	// A standalone transaction must not be a coinbase transaction.
 	if blockchain.IsCoinBase(tx) {
 		str := fmt.Sprintf("transaction %v is an individual coinbase",
 			txHash)
-		return nil, nil, txRuleError(wire.RejectInvalid, str)
+		return nil, txRuleError(wire.RejectInvalid, str)
 	}
 
 	// Get the current height of the main chain.  A standalone transaction
@@ -682,21 +1226,29 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 			}
 			str := fmt.Sprintf("transaction %v is not standard: %v",
 				txHash, err)
-			return nil, nil, txRuleError(rejectCode, str)
+			return nil, txRuleError(rejectCode, str)
 		}
 	}
 
 	// The transaction may not use any of the same outputs as other
 	// transactions already in the pool as that would ultimately result in a
-	// double spend.  This check is intended to be quick and therefore only
-	// detects double spends within the transaction pool itself.  The
-	// transaction could still be double spending coins from the main chain
-	// at this point.  There is a more in-depth check that happens later
-	// after fetching the referenced transaction inputs from the main chain
-	// which examines the actual spend data and prevents double spends.
-	err = mp.checkPoolDoubleSpend(tx)
+	// double spend unless it is a BIP 125 replacement for them, which is
+	// checked later once the fee is known.  This check is intended to be
+	// quick and therefore only detects double spends within the transaction
+	// pool itself.  The transaction could still be double spending coins
+	// from the main chain at this point.  There is a more in-depth check
+	// that happens later after fetching the referenced transaction inputs
+	// from the main chain which examines the actual spend data and prevents
+	// double spends.
+	conflicts, err := mp.checkPoolDoubleSpend(tx)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+	if inPackage && len(conflicts) > 0 {
+		str := fmt.Sprintf("transaction %v double spends transactions "+
+			"in the memory pool, which is not allowed in a package",
+			txHash)
+		return nil, txRuleError(wire.RejectDuplicate, str)
 	}
 
 	// Fetch all of the unspent transaction outputs referenced by the inputs
@@ -706,16 +1258,16 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 	utxoView, err := mp.fetchInputUtxos(tx)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, nil, chainRuleError(cerr)
+			return nil, chainRuleError(cerr)
 		}
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Don't allow the transaction if it exists in the main chain and is not
 	// not already fully spent.
 	txEntry := utxoView.LookupEntry(txHash)
 	if txEntry != nil && !txEntry.IsFullySpent() {
-		return nil, nil, txRuleError(wire.RejectDuplicate,
+		return nil, txRuleError(wire.RejectDuplicate,
 			"transaction already exists")
 	}
 	delete(utxoView.Entries(), *txHash)
@@ -736,7 +1288,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 		}
 	}
 	if len(missingParents) > 0 {
-		return missingParents, nil, nil
+		return &txAcceptance{missingParents: missingParents}, nil
 	}
 
 	// Don't allow the transaction into the mempool unless its sequence
@@ -745,13 +1297,13 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 	sequenceLock, err := mp.cfg.CalcSequenceLock(tx, utxoView)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, nil, chainRuleError(cerr)
+			return nil, chainRuleError(cerr)
 		}
-		return nil, nil, err
+		return nil, err
 	}
 	if !blockchain.SequenceLockActive(sequenceLock, nextBlockHeight,
 		medianTimePast) {
-		return nil, nil, txRuleError(wire.RejectNonstandard,
+		return nil, txRuleError(wire.RejectNonstandard,
 			"transaction's sequence locks on inputs not met")
 	}
 
@@ -763,9 +1315,9 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 		utxoView, mp.cfg.ChainParams)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, nil, chainRuleError(cerr)
+			return nil, chainRuleError(cerr)
 		}
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Don't allow transactions with non-standard inputs if the network
@@ -782,7 +1334,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 			}
 			str := fmt.Sprintf("transaction %v has a non-standard "+
 				"input: %v", txHash, err)
-			return nil, nil, txRuleError(rejectCode, str)
+			return nil, txRuleError(rejectCode, str)
 		}
 	}
 
@@ -799,14 +1351,14 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 	sigOpCost, err := blockchain.GetSigOpCost(tx, false, utxoView, true, true)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, nil, chainRuleError(cerr)
+			return nil, chainRuleError(cerr)
 		}
-		return nil, nil, err
+		return nil, err
 	}
 	if sigOpCost > mp.cfg.Policy.MaxSigOpCostPerTx {
 		str := fmt.Sprintf("transaction %v sigop cost is too high: %d > %d",
 			txHash, sigOpCost, mp.cfg.Policy.MaxSigOpCostPerTx)
-		return nil, nil, txRuleError(wire.RejectNonstandard, str)
+		return nil, txRuleError(wire.RejectNonstandard, str)
 	}
 
 	// Don't allow transactions with fees too low to get into a mined block.
@@ -823,25 +1375,46 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 	serializedSize := GetTxVirtualSize(tx)
 	minFee := calcMinRequiredTxRelayFee(serializedSize,
 		mp.cfg.Policy.MinRelayTxFee)
-	if serializedSize >= (DefaultBlockPrioritySize-1000) && txFee < minFee {
+	if !inPackage && serializedSize >= (DefaultBlockPrioritySize-1000) &&
+		txFee < minFee {
+
 		str := fmt.Sprintf("transaction %v has %d fees which is under "+
 			"the required amount of %d", txHash, txFee,
 			minFee)
-		return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
+		return nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
+
+	// Require new transactions to pay the minimum fee rate which applies
+	// once the size of the pool has been limited.  Transactions which are
+	// being added back to the memory pool from blocks that have been
+	// disconnected during a reorg are exempted.
+	poolMinFee := mp.minFee(time.Now())
+	if isNew && !inPackage && poolMinFee > 0 {
+		requiredFee := calcMinRequiredTxRelayFee(serializedSize,
+			poolMinFee)
+		if txFee < requiredFee {
+			str := fmt.Sprintf("transaction %v has %d fees which is "+
+				"under the mempool min fee of %d", txHash,
+				txFee, requiredFee)
+			return nil, txRuleError(wire.RejectInsufficientFee,
+				str)
+		}
 	}
 
 	// Require that free transactions have sufficient priority to be mined
 	// in the next block.  Transactions which are being added back to the
 	// memory pool from blocks that have been disconnected during a reorg
 	// are exempted.
-	if isNew && !mp.cfg.Policy.DisableRelayPriority && txFee < minFee {
+	if isNew && !inPackage && !mp.cfg.Policy.DisableRelayPriority &&
+		txFee < minFee {
+
 		currentPriority := mining.CalcPriority(tx.MsgTx(), utxoView,
 			nextBlockHeight)
 		if currentPriority <= mining.MinHighPriority {
 			str := fmt.Sprintf("transaction %v has insufficient "+
 				"priority (%g <= %g)", txHash,
 				currentPriority, mining.MinHighPriority)
-			return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
+			return nil, txRuleError(wire.RejectInsufficientFee, str)
 		}
 	}
 
@@ -859,7 +1432,7 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 		if mp.pennyTotal >= mp.cfg.Policy.FreeTxRelayLimit*10*1000 {
 			str := fmt.Sprintf("transaction %v has been rejected "+
 				"by the rate limiter due to low fees", txHash)
-			return nil, nil, txRuleError(wire.RejectInsufficientFee, str)
+			return nil, txRuleError(wire.RejectInsufficientFee, str)
 		}
 		oldTotal := mp.pennyTotal
 
@@ -869,21 +1442,104 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 			mp.cfg.Policy.FreeTxRelayLimit*10*1000)
 	}
 
+	// Don't allow the transaction to create chains of unconfirmed
+	// transactions which exceed the ancestor and descendant limits.
+	if err := mp.checkPackageLimits(tx); err != nil {
+		return nil, err
+	}
+
+	// Ensure the transaction is a valid replacement for any transactions
+	// in the pool it double spends and determine the full set of
+	// transactions that must be evicted to accept it.
+	var evictions map[chainhash.Hash]*TxDesc
+	if len(conflicts) > 0 {
+		evictions, err = mp.validateReplacement(tx, txFee, conflicts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Verify crypto signatures for each input and reject the transaction if
 	// any don't verify.
 	err = blockchain.ValidateTransactionScripts(tx, utxoView,
-		txscript.StandardVerifyFlags, mp.cfg.SigCache,
-		mp.cfg.HashCache)
+		mining.StandardVerifyFlags(mp.cfg.ChainParams, nextBlockHeight),
+		mp.cfg.SigCache, mp.cfg.HashCache, mp.cfg.ScriptCache)
 	if err != nil {
 		if cerr, ok := err.(blockchain.RuleError); ok {
-			return nil, nil, chainRuleError(cerr)
+			return nil, chainRuleError(cerr)
 		}
+		return nil, err
+	}
+
+	return &txAcceptance{
+		utxoView:   utxoView,
+		bestHeight: bestHeight,
+		fee:        txFee,
+		evictions:  evictions,
+	}, nil
+}
+
+// maybeAcceptTransaction is the internal function which implements the public
+// MaybeAcceptTransaction.  See the comment for MaybeAcceptTransaction for
+// more details.
+//
+// When the in package flag is set, the transaction is a member of a package
+// whose fees are checked as a whole by the caller.  The fee related checks,
+// limiting the size of the pool, and recording the transaction with the fee
+// estimator are left to the caller in that case and replacements are not
+// allowed.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejectDupOrphans, inPackage bool) ([]*chainhash.Hash, *TxDesc, error) {
+	acceptance, err := mp.checkTransactionAcceptance(tx, isNew, rateLimit,
+		rejectDupOrphans, inPackage)
+	if err != nil {
 		return nil, nil, err
 	}
+	if len(acceptance.missingParents) > 0 {
+		return acceptance.missingParents, nil, nil
+	}
+	txHash := tx.Hash()
+
+	// Evict the transactions being replaced along with all of their
+	// descendants now that the replacement is known to be valid.
+	var replacedTxns []*chainhash.Hash
+	for hash, desc := range acceptance.evictions {
+		hashCopy := hash
+		replacedTxns = append(replacedTxns, &hashCopy)
+		mp.removeTransaction(desc.Tx, true, RemovalReasonReplaced)
+	}
 
 	// Add to transaction pool.
-	txD := mp.addTransaction(utxoView, tx, bestHeight, txFee)
+	txD := mp.addTransaction(acceptance.utxoView, tx,
+		acceptance.bestHeight, acceptance.fee)
+	txD.ReplacedTxns = replacedTxns
+
+	// Evict the expired transactions and limit the pool to its maximum
+	// size and reject the transaction when it was evicted in the process
+	// due to paying too low a fee rate.
+	if isNew && !inPackage {
+		now := time.Now()
+		mp.expireTransactions(now)
+		mp.trimToSize(now)
+		if _, exists := mp.pool[*txHash]; !exists {
+			str := fmt.Sprintf("transaction %v was not accepted "+
+				"since the mempool is full", txHash)
+			return nil, nil, txRuleError(wire.RejectInsufficientFee,
+				str)
+		}
+	}
+
+	// Record the transaction with the fee estimator, if there is one.
+	if mp.cfg.FeeEstimator != nil && !inPackage {
+		mp.cfg.FeeEstimator.ObserveTransaction(txD)
+	}
 
+	if len(replacedTxns) > 0 {
+		log.Debugf("Transaction %v replaced %d %s", txHash,
+			len(replacedTxns), pickNoun(len(replacedTxns),
+				"transaction", "transactions"))
+	}
 	log.Debugf("Accepted transaction %v (pool size: %v)", txHash,
 		len(mp.pool))
 
@@ -904,7 +1560,8 @@ func (mp *TxPool) maybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit, rejec
 func (mp *TxPool) MaybeAcceptTransaction(tx *ltcutil.Tx, isNew, rateLimit bool) ([]*chainhash.Hash, *TxDesc, error) {
 	// Protect concurrent access.
 	mp.mtx.Lock()
-	hashes, txD, err := mp.maybeAcceptTransaction(tx, isNew, rateLimit, true)
+	hashes, txD, err := mp.maybeAcceptTransaction(tx, isNew, rateLimit, true,
+		false)
 	mp.mtx.Unlock()
 
 	return hashes, txD, err
@@ -947,7 +1604,7 @@ func (mp *TxPool) processOrphans(acceptedTx *ltcutil.Tx) []*TxDesc {
 			// Potentially accept an orphan into the tx pool.
 			for _, tx := range orphans {
 				missing, txD, err := mp.maybeAcceptTransaction(
-					tx, true, true, false)
+					tx, true, true, false, false)
 				if err != nil {
 					// The orphan is now invalid, so there
 					// is no way any other orphans which
@@ -1031,7 +1688,7 @@ func (mp *TxPool) ProcessTransaction(tx *ltcutil.Tx, allowOrphan, rateLimit bool
 
 	// Potentially accept the transaction to the memory pool.
 	missingParents, txD, err := mp.maybeAcceptTransaction(tx, true, rateLimit,
-		true)
+		true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -1075,69 +1732,473 @@ func (mp *TxPool) ProcessTransaction(tx *ltcutil.Tx, allowOrphan, rateLimit bool
 	return nil, err
 }
 
-// Count returns the number of transactions in the main pool.  It does not
-// include the orphan pool.
-//
-// This function is safe for concurrent access.
-func (mp *TxPool) Count() int {
-	mp.mtx.RLock()
-	count := len(mp.pool)
-	mp.mtx.RUnlock()
+// checkPackageConsistency ensures the passed transactions do not exceed the
+// limits on the number and total size of the transactions in a package, do not
+// contain duplicate or conflicting transactions, and are sorted such that every
+// transaction comes after the transactions whose outputs it spends.
+func checkPackageConsistency(txns []*ltcutil.Tx) error {
+	if len(txns) == 0 || len(txns) > MaxPackageCount {
+		str := fmt.Sprintf("package must contain between 1 and %d "+
+			"transactions", MaxPackageCount)
+		return txRuleError(wire.RejectInvalid, str)
+	}
 
-	return count
-}
+	var packageSize int64
+	indexes := make(map[chainhash.Hash]int, len(txns))
+	spent := make(map[wire.OutPoint]struct{})
+	for i, tx := range txns {
+		if _, exists := indexes[*tx.Hash()]; exists {
+			str := fmt.Sprintf("package contains duplicate "+
+				"transaction %v", tx.Hash())
+			return txRuleError(wire.RejectInvalid, str)
+		}
+		indexes[*tx.Hash()] = i
 
-// TxHashes returns a slice of hashes for all of the transactions in the memory
-// pool.
-//
-// This function is safe for concurrent access.
-func (mp *TxPool) TxHashes() []*chainhash.Hash {
-	mp.mtx.RLock()
-	hashes := make([]*chainhash.Hash, len(mp.pool))
-	i := 0
-	for hash := range mp.pool {
-		hashCopy := hash
-		hashes[i] = &hashCopy
-		i++
+		for _, txIn := range tx.MsgTx().TxIn {
+			if _, exists := spent[txIn.PreviousOutPoint]; exists {
+				str := fmt.Sprintf("package contains conflicting "+
+					"transactions which spend output %v",
+					txIn.PreviousOutPoint)
+				return txRuleError(wire.RejectInvalid, str)
+			}
+			spent[txIn.PreviousOutPoint] = struct{}{}
+		}
+		packageSize += GetTxVirtualSize(tx)
+	}
+	if packageSize > MaxPackageSize {
+		str := fmt.Sprintf("package virtual size of %d is larger than "+
+			"max allowed size of %d", packageSize, MaxPackageSize)
+		return txRuleError(wire.RejectInvalid, str)
 	}
-	mp.mtx.RUnlock()
-
-	return hashes
-}
 
-// TxDescs returns a slice of descriptors for all the transactions in the pool.
-// The descriptors are to be treated as read only.
-//
-// This function is safe for concurrent access.
-func (mp *TxPool) TxDescs() []*TxDesc {
-	mp.mtx.RLock()
-	descs := make([]*TxDesc, len(mp.pool))
-	i := 0
-	for _, desc := range mp.pool {
-		descs[i] = desc
-		i++
+	// Ensure no transaction spends the outputs of a transaction which comes
+	// after it in the package.
+	for i, tx := range txns {
+		for _, txIn := range tx.MsgTx().TxIn {
+			parentIndex, exists := indexes[txIn.PreviousOutPoint.Hash]
+			if exists && parentIndex > i {
+				str := fmt.Sprintf("package is not sorted: "+
+					"transaction %v spends transaction %v "+
+					"which comes after it", tx.Hash(),
+					txIn.PreviousOutPoint.Hash)
+				return txRuleError(wire.RejectInvalid, str)
+			}
+		}
 	}
-	mp.mtx.RUnlock()
 
-	return descs
+	return nil
 }
 
-// MiningDescs returns a slice of mining descriptors for all the transactions
-// in the pool.
-//
-// This is part of the mining.TxSource interface implementation and is safe for
-// concurrent access as required by the interface contract.
-func (mp *TxPool) MiningDescs() []*mining.TxDesc {
-	mp.mtx.RLock()
-	descs := make([]*mining.TxDesc, len(mp.pool))
-	i := 0
-	for _, desc := range mp.pool {
-		descs[i] = &desc.TxDesc
-		i++
+// checkPackageTopology ensures the passed transactions form a package which
+// can be processed as a unit.  In addition to the checks performed by
+// checkPackageConsistency, the package must consist of a child transaction and
+// its parents.
+func checkPackageTopology(txns []*ltcutil.Tx) error {
+	if err := checkPackageConsistency(txns); err != nil {
+		return err
 	}
-	mp.mtx.RUnlock()
 
-	return descs
+	// Ensure every transaction other than the last one is a parent of the
+	// last one.
+	child := txns[len(txns)-1]
+	parents := make(map[chainhash.Hash]struct{})
+	for _, txIn := range child.MsgTx().TxIn {
+		parents[txIn.PreviousOutPoint.Hash] = struct{}{}
+	}
+	for _, tx := range txns[:len(txns)-1] {
+		if _, ok := parents[*tx.Hash()]; !ok {
+			str := fmt.Sprintf("package must consist of a child and "+
+				"its parents: transaction %v is not a parent "+
+				"of %v", tx.Hash(), child.Hash())
+			return txRuleError(wire.RejectInvalid, str)
+		}
+	}
+
+	return nil
+}
+
+// ProcessPackage is the main workhorse for handling insertion of a package of
+// related transactions into the memory pool as a unit.  The package must
+// consist of a child transaction and its parents sorted such that every
+// transaction comes after the transactions whose outputs it spends.
+//
+// The transactions are subject to the same rules as individual transactions
+// except that their fees are checked together, so a parent which pays too low
+// a fee on its own is accepted when its child pays enough for both.  Unlike
+// individual transactions, a package is never relayed for free, so it must pay
+// at least the minimum relay fee rate.  Transactions in the package which are
+// already in the pool are skipped and do not count towards its fee rate.
+//
+// Either all of the transactions in the package which are not already in the
+// pool are added to it, or none of them are, in which case the returned error
+// identifies the transaction which was rejected.  Any orphans which depend on
+// the added transactions are processed as well.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) ProcessPackage(txns []*ltcutil.Tx) (*PackageResult, error) {
+	log.Tracef("Processing package of %d %s", len(txns),
+		pickNoun(len(txns), "transaction", "transactions"))
+
+	// Protect concurrent access.
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	if err := checkPackageTopology(txns); err != nil {
+		return nil, err
+	}
+
+	// removeAdded removes the transactions in the package which were added
+	// to the pool so far, children first.
+	var added []*TxDesc
+	removeAdded := func() {
+		for i := len(added) - 1; i >= 0; i-- {
+			mp.removeTransaction(added[i].Tx, true,
+				RemovalReasonInvalid)
+		}
+	}
+
+	// Attempt to add each transaction in the package to the pool in order
+	// so its parents in the package are available to it.
+	result := &PackageResult{TxDescs: make([]*TxDesc, 0, len(txns))}
+	for i, tx := range txns {
+		if txD, exists := mp.pool[*tx.Hash()]; exists {
+			result.TxDescs = append(result.TxDescs, txD)
+			continue
+		}
+
+		missingParents, txD, err := mp.maybeAcceptTransaction(tx, true,
+			false, false, true)
+		if err == nil && len(missingParents) > 0 {
+			str := fmt.Sprintf("references outputs of unknown or "+
+				"fully-spent transaction %v", missingParents[0])
+			err = txRuleError(wire.RejectDuplicate, str)
+		}
+		if err != nil {
+			removeAdded()
+
+			rejectCode, found := extractRejectCode(err)
+			if !found {
+				return nil, err
+			}
+			str := fmt.Sprintf("package transaction %d (%v) was "+
+				"rejected: %v", i, tx.Hash(), err)
+			return nil, txRuleError(rejectCode, str)
+		}
+
+		added = append(added, txD)
+		result.TxDescs = append(result.TxDescs, txD)
+	}
+	if len(added) == 0 {
+		return result, nil
+	}
+
+	// Ensure the transactions added to the pool pay at least the minimum
+	// fee rate required for transactions to be accepted as a whole.
+	var packageFee, packageSize int64
+	for _, txD := range added {
+		packageFee += txD.Fee
+		packageSize += GetTxVirtualSize(txD.Tx)
+	}
+	minFee := mp.minFee(time.Now())
+	if minFee < mp.cfg.Policy.MinRelayTxFee {
+		minFee = mp.cfg.Policy.MinRelayTxFee
+	}
+	requiredFee := calcMinRequiredTxRelayFee(packageSize, minFee)
+	if packageFee < requiredFee {
+		removeAdded()
+
+		str := fmt.Sprintf("package has %d fees which is under the "+
+			"required amount of %d", packageFee, requiredFee)
+		return nil, txRuleError(wire.RejectInsufficientFee, str)
+	}
+	result.FeeRate = ltcutil.Amount(packageFee * 1000 / packageSize)
+
+	// Evict the expired transactions and limit the pool to its maximum
+	// size and reject the package when any of its transactions were
+	// evicted in the process.
+	now := time.Now()
+	mp.expireTransactions(now)
+	mp.trimToSize(now)
+	for _, txD := range added {
+		if _, exists := mp.pool[*txD.Tx.Hash()]; !exists {
+			removeAdded()
+
+			return nil, txRuleError(wire.RejectInsufficientFee,
+				"package was not accepted since the mempool "+
+					"is full")
+		}
+	}
+
+	// The package was accepted, so record its transactions with the fee
+	// estimator, remove any of them from the orphan pool, and accept any
+	// orphans which depend on them.
+	result.Accepted = append([]*TxDesc(nil), added...)
+	for _, txD := range added {
+		if mp.cfg.FeeEstimator != nil {
+			mp.cfg.FeeEstimator.ObserveTransaction(txD)
+		}
+		mp.removeOrphan(txD.Tx, false)
+	}
+	for _, txD := range added {
+		result.Accepted = append(result.Accepted,
+			mp.processOrphans(txD.Tx)...)
+	}
+
+	log.Debugf("Accepted package of %d %s (pool size: %v)", len(added),
+		pickNoun(len(added), "transaction", "transactions"),
+		len(mp.pool))
+
+	return result, nil
+}
+
+// TestAcceptTransactions checks whether or not each of the passed transactions
+// would be accepted to the memory pool without adding any of them to it.  The
+// returned results are in the same order as the transactions and the error of
+// each result is nil when the transaction would be accepted.
+//
+// The transactions are subject to the same rules as individual transactions,
+// so each one must pay its own fees.  When more than one transaction is passed,
+// they are treated as a package which may not contain duplicate or conflicting
+// transactions and must be sorted such that every transaction comes after the
+// transactions whose outputs it spends, in which case an error is returned and
+// none of them are checked.  Each transaction in a package is checked as if the
+// transactions before it which would be accepted had been added to the pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TestAcceptTransactions(txns []*ltcutil.Tx) ([]*TestAcceptResult, error) {
+	// Protect concurrent access.
+	mp.mtx.Lock()
+	defer mp.mtx.Unlock()
+
+	isPackage := len(txns) > 1
+	if isPackage {
+		if err := checkPackageConsistency(txns); err != nil {
+			return nil, err
+		}
+	}
+
+	// The transactions in a package which would be accepted are staged in
+	// the pool so the transactions which spend them can be checked.  They
+	// are removed directly rather than through removeTransaction once all
+	// of them have been checked since they were never announced.
+	var staged []*TxDesc
+	defer func() {
+		for _, txD := range staged {
+			for _, txIn := range txD.Tx.MsgTx().TxIn {
+				delete(mp.outpoints, txIn.PreviousOutPoint)
+			}
+			delete(mp.pool, *txD.Tx.Hash())
+		}
+	}()
+
+	results := make([]*TestAcceptResult, 0, len(txns))
+	for _, tx := range txns {
+		result := &TestAcceptResult{Tx: tx}
+		results = append(results, result)
+
+		acceptance, err := mp.checkTransactionAcceptance(tx, true,
+			false, true, false)
+		switch {
+		case err != nil:
+			result.Err = err
+			continue
+
+		case len(acceptance.missingParents) > 0:
+			str := fmt.Sprintf("transaction %v references outputs "+
+				"of unknown or fully-spent transaction %v",
+				tx.Hash(), acceptance.missingParents[0])
+			result.Err = txRuleError(wire.RejectDuplicate, str)
+			continue
+
+		case isPackage && len(acceptance.evictions) > 0:
+			str := fmt.Sprintf("transaction %v double spends "+
+				"transactions in the memory pool which is not "+
+				"allowed in a package", tx.Hash())
+			result.Err = txRuleError(wire.RejectDuplicate, str)
+			continue
+		}
+		result.Fee = acceptance.fee
+
+		if !isPackage {
+			continue
+		}
+		txD := &TxDesc{
+			TxDesc: mining.TxDesc{
+				Tx:     tx,
+				Added:  time.Now(),
+				Height: acceptance.bestHeight,
+				Fee:    acceptance.fee,
+				FeePerKB: acceptance.fee * 1000 /
+					int64(tx.MsgTx().SerializeSize()),
+			},
+		}
+		mp.pool[*tx.Hash()] = txD
+		for _, txIn := range tx.MsgTx().TxIn {
+			mp.outpoints[txIn.PreviousOutPoint] = tx
+		}
+		staged = append(staged, txD)
+	}
+
+	return results, nil
+}
+
+// OrphanCount returns the number of transactions in the orphan pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) OrphanCount() int {
+	mp.mtx.RLock()
+	count := len(mp.orphans)
+	mp.mtx.RUnlock()
+
+	return count
+}
+
+// Count returns the number of transactions in the main pool.  It does not
+// include the orphan pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) Count() int {
+	mp.mtx.RLock()
+	count := len(mp.pool)
+	mp.mtx.RUnlock()
+
+	return count
+}
+
+// AddUnbroadcastTx marks the transaction with the passed hash, which was
+// submitted locally, as not yet known to have been received by any peer so it
+// is announced until it is requested by a peer, included in a block, or
+// otherwise removed from the pool.  Transactions which are not in the main
+// pool are ignored.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) AddUnbroadcastTx(hash *chainhash.Hash) {
+	mp.mtx.Lock()
+	if _, exists := mp.pool[*hash]; exists {
+		mp.unbroadcast[*hash] = struct{}{}
+	}
+	mp.mtx.Unlock()
+}
+
+// RemoveUnbroadcastTx marks the transaction with the passed hash as broadcast,
+// which happens once a peer requests it or it is included in a block.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) RemoveUnbroadcastTx(hash *chainhash.Hash) {
+	mp.mtx.Lock()
+	delete(mp.unbroadcast, *hash)
+	mp.mtx.Unlock()
+}
+
+// IsUnbroadcastTx returns whether or not the transaction with the passed hash
+// was submitted locally and is not yet known to have been received by any
+// peer.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) IsUnbroadcastTx(hash *chainhash.Hash) bool {
+	mp.mtx.RLock()
+	_, exists := mp.unbroadcast[*hash]
+	mp.mtx.RUnlock()
+
+	return exists
+}
+
+// UnbroadcastTxDescs returns the descriptors of the transactions which were
+// submitted locally and are not yet known to have been received by any peer.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) UnbroadcastTxDescs() []*TxDesc {
+	mp.mtx.RLock()
+	descs := make([]*TxDesc, 0, len(mp.unbroadcast))
+	for hash := range mp.unbroadcast {
+		descs = append(descs, mp.pool[hash])
+	}
+	mp.mtx.RUnlock()
+
+	return descs
+}
+
+// UnbroadcastCount returns the number of transactions which were submitted
+// locally and are not yet known to have been received by any peer.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) UnbroadcastCount() int {
+	mp.mtx.RLock()
+	count := len(mp.unbroadcast)
+	mp.mtx.RUnlock()
+
+	return count
+}
+
+// TxHashes returns a slice of hashes for all of the transactions in the memory
+// pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TxHashes() []*chainhash.Hash {
+	mp.mtx.RLock()
+	hashes := make([]*chainhash.Hash, len(mp.pool))
+	i := 0
+	for hash := range mp.pool {
+		hashCopy := hash
+		hashes[i] = &hashCopy
+		i++
+	}
+	mp.mtx.RUnlock()
+
+	return hashes
+}
+
+// TxHashesWithSequence returns a slice of hashes for all of the transactions in
+// the memory pool along with the mempool sequence they are current as of, which
+// is the number of times a transaction was added to or removed from the pool as
+// returned by TransactionsUpdated.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TxHashesWithSequence() ([]*chainhash.Hash, uint64) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	hashes := make([]*chainhash.Hash, 0, len(mp.pool))
+	for hash := range mp.pool {
+		hashCopy := hash
+		hashes = append(hashes, &hashCopy)
+	}
+	return hashes, atomic.LoadUint64(&mp.txUpdates)
+}
+
+// TxDescs returns a slice of descriptors for all the transactions in the pool.
+// The descriptors are to be treated as read only.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TxDescs() []*TxDesc {
+	mp.mtx.RLock()
+	descs := make([]*TxDesc, len(mp.pool))
+	i := 0
+	for _, desc := range mp.pool {
+		descs[i] = desc
+		i++
+	}
+	mp.mtx.RUnlock()
+
+	return descs
+}
+
+// MiningDescs returns a slice of mining descriptors for all the transactions
+// in the pool.
+//
+// This is part of the mining.TxSource interface implementation and is safe for
+// concurrent access as required by the interface contract.
+func (mp *TxPool) MiningDescs() []*mining.TxDesc {
+	mp.mtx.RLock()
+	descs := make([]*mining.TxDesc, len(mp.pool))
+	i := 0
+	for _, desc := range mp.pool {
+		descs[i] = &desc.TxDesc
+		i++
+	}
+	mp.mtx.RUnlock()
+
+	return descs
 }
 
 // RawMempoolVerbose returns all of the entries in the mempool as a fully
@@ -1153,39 +2214,446 @@ func (mp *TxPool) RawMempoolVerbose() map[string]*btcjson.GetRawMempoolVerboseRe
 	bestHeight := mp.cfg.BestHeight()
 
 	for _, desc := range mp.pool {
-		// Calculate the current priority based on the inputs to
-		// the transaction.  Use zero if one or more of the
-		// input transactions can't be found for some reason.
 		tx := desc.Tx
-		var currentPriority float64
-		utxos, err := mp.fetchInputUtxos(tx)
-		if err == nil {
-			currentPriority = mining.CalcPriority(tx.MsgTx(), utxos,
-				bestHeight+1)
+		entry := mp.mempoolEntry(desc, bestHeight)
+		result[tx.Hash().String()] = &btcjson.GetRawMempoolVerboseResult{
+			Size:             entry.Size,
+			Vsize:            int32(GetTxVirtualSize(tx)),
+			Fee:              entry.Fee,
+			Time:             entry.Time,
+			Height:           entry.Height,
+			StartingPriority: entry.StartingPriority,
+			CurrentPriority:  entry.CurrentPriority,
+			Depends:          entry.Depends,
+			Wtxid:            tx.WitnessHash().String(),
+			Fees: btcjson.MempoolFees{
+				Base:       entry.Fee,
+				Modified:   entry.ModifiedFee,
+				Ancestor:   entry.AncestorFees,
+				Descendant: entry.DescendantFees,
+			},
+			BIP125Replaceable: entry.BIP125Replaceable,
+			Unbroadcast:       entry.Unbroadcast,
 		}
+	}
 
-		mpd := &btcjson.GetRawMempoolVerboseResult{
-			Size:             int32(tx.MsgTx().SerializeSize()),
-			Vsize:            int32(GetTxVirtualSize(tx)),
-			Fee:              ltcutil.Amount(desc.Fee).ToBTC(),
-			Time:             desc.Added.Unix(),
-			Height:           int64(desc.Height),
-			StartingPriority: desc.StartingPriority,
-			CurrentPriority:  currentPriority,
-			Depends:          make([]string, 0),
+	return result
+}
+
+// txAncestors returns the descriptors for all transactions in the main pool
+// that the passed transaction depends on either directly or transitively.  The
+// passed transaction itself is not included.
+//
+// The dependency graph is walked iteratively with an explicit stack and a set
+// of visited transactions, so a long chain of unconfirmed transactions can't
+// exhaust the goroutine stack and each transaction in the pool is visited at
+// most once even if the graph were to somehow contain a cycle.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) txAncestors(tx *ltcutil.Tx) []*TxDesc {
+	var ancestors []*TxDesc
+	visited := map[chainhash.Hash]struct{}{*tx.Hash(): {}}
+	stack := []*ltcutil.Tx{tx}
+	for len(stack) > 0 {
+		// Pop the next transaction to examine off the stack.
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, txIn := range item.MsgTx().TxIn {
+			parentHash := txIn.PreviousOutPoint.Hash
+			if _, ok := visited[parentHash]; ok {
+				continue
+			}
+			parent, exists := mp.pool[parentHash]
+			if !exists {
+				continue
+			}
+
+			visited[parentHash] = struct{}{}
+			ancestors = append(ancestors, parent)
+			stack = append(stack, parent.Tx)
 		}
-		for _, txIn := range tx.MsgTx().TxIn {
-			hash := &txIn.PreviousOutPoint.Hash
-			if mp.haveTransaction(hash) {
-				mpd.Depends = append(mpd.Depends,
-					hash.String())
+	}
+
+	return ancestors
+}
+
+// TxAncestors returns the descriptors for all transactions in the main pool
+// that the transaction with the passed hash depends on either directly or
+// transitively.  An error is returned if the transaction is not in the main
+// pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TxAncestors(hash *chainhash.Hash) ([]*TxDesc, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	txDesc, exists := mp.pool[*hash]
+	if !exists {
+		return nil, fmt.Errorf("transaction is not in the pool")
+	}
+
+	return mp.txAncestors(txDesc.Tx), nil
+}
+
+// txDescendants returns the descriptors for all transactions in the main pool
+// that spend outputs of the passed transaction either directly or
+// transitively.  The passed transaction itself is not included.
+//
+// The spenders are found through the pool's outpoint to spending transaction
+// index rather than by scanning the entire pool, and, as with txAncestors, the
+// graph is walked iteratively with a set of visited transactions.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) txDescendants(tx *ltcutil.Tx) []*TxDesc {
+	var descendants []*TxDesc
+	visited := map[chainhash.Hash]struct{}{*tx.Hash(): {}}
+	stack := []*ltcutil.Tx{tx}
+	for len(stack) > 0 {
+		// Pop the next transaction to examine off the stack.
+		item := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		prevOut := wire.OutPoint{Hash: *item.Hash()}
+		for txOutIdx := range item.MsgTx().TxOut {
+			prevOut.Index = uint32(txOutIdx)
+			spender, exists := mp.outpoints[prevOut]
+			if !exists {
+				continue
 			}
+			if _, ok := visited[*spender.Hash()]; ok {
+				continue
+			}
+			child, exists := mp.pool[*spender.Hash()]
+			if !exists {
+				continue
+			}
+
+			visited[*spender.Hash()] = struct{}{}
+			descendants = append(descendants, child)
+			stack = append(stack, child.Tx)
 		}
+	}
+
+	return descendants
+}
+
+// TxDescendants returns the descriptors for all transactions in the main pool
+// that spend outputs of the transaction with the passed hash either directly
+// or transitively.  An error is returned if the transaction is not in the main
+// pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TxDescendants(hash *chainhash.Hash) ([]*TxDesc, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
 
-		result[tx.Hash().String()] = mpd
+	txDesc, exists := mp.pool[*hash]
+	if !exists {
+		return nil, fmt.Errorf("transaction is not in the pool")
 	}
 
-	return result
+	return mp.txDescendants(txDesc.Tx), nil
+}
+
+// mempoolEntry returns a populated getmempoolentry result for the passed
+// transaction descriptor.  The ancestor and descendant statistics include the
+// transaction itself.  The transaction is reported as replaceable as defined by
+// BIP 125 when either it or any of its ancestors in the pool signal
+// replaceability.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) mempoolEntry(desc *TxDesc, bestHeight int32) *btcjson.GetMempoolEntryResult {
+	// Calculate the current priority based on the inputs to the
+	// transaction.  Use zero if one or more of the input transactions
+	// can't be found for some reason.
+	tx := desc.Tx
+	var currentPriority float64
+	utxos, err := mp.fetchInputUtxos(tx)
+	if err == nil {
+		currentPriority = mining.CalcPriority(tx.MsgTx(), utxos,
+			bestHeight+1)
+	}
+
+	size := int64(tx.MsgTx().SerializeSize())
+	entry := &btcjson.GetMempoolEntryResult{
+		Size:             int32(size),
+		Fee:              ltcutil.Amount(desc.Fee).ToBTC(),
+		ModifiedFee:      ltcutil.Amount(desc.Fee).ToBTC(),
+		Time:             desc.Added.Unix(),
+		Height:           int64(desc.Height),
+		StartingPriority: desc.StartingPriority,
+		CurrentPriority:  currentPriority,
+		Depends:          make([]string, 0),
+	}
+	_, entry.Unbroadcast = mp.unbroadcast[*tx.Hash()]
+	for _, txIn := range tx.MsgTx().TxIn {
+		hash := &txIn.PreviousOutPoint.Hash
+		if mp.isTransactionInPool(hash) {
+			entry.Depends = append(entry.Depends, hash.String())
+		}
+	}
+
+	ancestorFees := desc.Fee
+	replaceable := signalsReplacement(tx)
+	entry.AncestorCount = 1
+	entry.AncestorSize = size
+	for _, ancestor := range mp.txAncestors(tx) {
+		entry.AncestorCount++
+		entry.AncestorSize += int64(ancestor.Tx.MsgTx().SerializeSize())
+		ancestorFees += ancestor.Fee
+		replaceable = replaceable || signalsReplacement(ancestor.Tx)
+	}
+	entry.AncestorFees = ltcutil.Amount(ancestorFees).ToBTC()
+	entry.BIP125Replaceable = "no"
+	if replaceable {
+		entry.BIP125Replaceable = "yes"
+	}
+
+	descendantFees := desc.Fee
+	entry.DescendantCount = 1
+	entry.DescendantSize = size
+	for _, descendant := range mp.txDescendants(tx) {
+		entry.DescendantCount++
+		entry.DescendantSize += int64(descendant.Tx.MsgTx().SerializeSize())
+		descendantFees += descendant.Fee
+	}
+	entry.DescendantFees = ltcutil.Amount(descendantFees).ToBTC()
+
+	return entry
+}
+
+// MempoolEntry returns the getmempoolentry result for the transaction with the
+// passed hash.  An error is returned if the transaction is not in the main
+// pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MempoolEntry(hash *chainhash.Hash) (*btcjson.GetMempoolEntryResult, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	txDesc, exists := mp.pool[*hash]
+	if !exists {
+		return nil, fmt.Errorf("transaction is not in the pool")
+	}
+
+	return mp.mempoolEntry(txDesc, mp.cfg.BestHeight()), nil
+}
+
+// TxAncestorsVerbose returns getmempoolentry results for all transactions in
+// the main pool that the transaction with the passed hash depends on either
+// directly or transitively keyed by their transaction hash.  An error is
+// returned if the transaction is not in the main pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TxAncestorsVerbose(hash *chainhash.Hash) (map[string]*btcjson.GetMempoolEntryResult, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	txDesc, exists := mp.pool[*hash]
+	if !exists {
+		return nil, fmt.Errorf("transaction is not in the pool")
+	}
+
+	ancestors := mp.txAncestors(txDesc.Tx)
+	result := make(map[string]*btcjson.GetMempoolEntryResult,
+		len(ancestors))
+	bestHeight := mp.cfg.BestHeight()
+	for _, desc := range ancestors {
+		result[desc.Tx.Hash().String()] = mp.mempoolEntry(desc,
+			bestHeight)
+	}
+
+	return result, nil
+}
+
+// TxDescendantsVerbose returns getmempoolentry results for all transactions in
+// the main pool that spend outputs of the transaction with the passed hash
+// either directly or transitively keyed by their transaction hash.  An error is
+// returned if the transaction is not in the main pool.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TxDescendantsVerbose(hash *chainhash.Hash) (map[string]*btcjson.GetMempoolEntryResult, error) {
+	mp.mtx.RLock()
+	defer mp.mtx.RUnlock()
+
+	txDesc, exists := mp.pool[*hash]
+	if !exists {
+		return nil, fmt.Errorf("transaction is not in the pool")
+	}
+
+	descendants := mp.txDescendants(txDesc.Tx)
+	result := make(map[string]*btcjson.GetMempoolEntryResult,
+		len(descendants))
+	bestHeight := mp.cfg.BestHeight()
+	for _, desc := range descendants {
+		result[desc.Tx.Hash().String()] = mp.mempoolEntry(desc,
+			bestHeight)
+	}
+
+	return result, nil
+}
+
+// evictionFeeRate returns the fee rate in satoshi/kB which determines the order
+// in which the passed transaction is evicted when limiting the size of the
+// pool.  It is the larger of the fee rate of the transaction itself and that of
+// the package it forms with all of its descendants, so a transaction whose
+// descendants pay for it is not evicted before transactions paying a lower fee
+// rate than the package, and a transaction is never evicted before its
+// descendants which pay a lower fee rate than it does.
+//
+// This function MUST be called with the mempool lock held (for reads).
+func (mp *TxPool) evictionFeeRate(txDesc *TxDesc) float64 {
+	fees := txDesc.Fee
+	size := int64(GetTxVirtualSize(txDesc.Tx))
+	feeRate := float64(fees) * 1000 / float64(size)
+	for _, desc := range mp.txDescendants(txDesc.Tx) {
+		fees += desc.Fee
+		size += int64(GetTxVirtualSize(desc.Tx))
+	}
+	if packageRate := float64(fees) * 1000 / float64(size); packageRate > feeRate {
+		return packageRate
+	}
+	return feeRate
+}
+
+// expireTransactions evicts the transactions which have been in the main pool
+// for longer than the expiry allowed by the policy along with all of their
+// descendants.  The scan only happens periodically instead of every time a
+// transaction is added to the pool for efficiency.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) expireTransactions(now time.Time) {
+	expiry := mp.cfg.Policy.Expiry
+	if expiry <= 0 || now.Before(mp.nextPoolExpireScan) {
+		return
+	}
+	mp.nextPoolExpireScan = now.Add(poolExpireScanInterval)
+
+	// Transactions removed as descendants of expired transactions are
+	// deleted from the pool during the iteration and thus not visited.
+	var numExpired int
+	for _, txDesc := range mp.pool {
+		if now.Sub(txDesc.Added) <= expiry {
+			continue
+		}
+
+		numBefore := len(mp.pool)
+		mp.removeTransaction(txDesc.Tx, true, RemovalReasonExpired)
+		numExpired += numBefore - len(mp.pool)
+	}
+
+	if numExpired > 0 {
+		log.Debugf("Expired %d %s (remaining: %d)", numExpired,
+			pickNoun(numExpired, "transaction", "transactions"),
+			len(mp.pool))
+	}
+}
+
+// trimToSize evicts the transactions with the lowest eviction fee rates along
+// with all of their descendants from the main pool until it no longer exceeds
+// the maximum size allowed by the policy.  The minimum fee rate required for
+// transactions to be accepted is raised above the eviction fee rate of every
+// evicted package.
+//
+// Since evicting a package changes the fee rates of the packages formed by its
+// ancestors, the fee rates are recalculated after every eviction.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) trimToSize(now time.Time) {
+	maxSize := mp.cfg.Policy.MaxPoolSize
+	if maxSize <= 0 {
+		return
+	}
+
+	var numEvicted int
+	for mp.poolSize > maxSize && len(mp.pool) > 0 {
+		var evictTx *ltcutil.Tx
+		var evictRate float64
+		for _, txDesc := range mp.pool {
+			feeRate := mp.evictionFeeRate(txDesc)
+			if evictTx == nil || feeRate < evictRate {
+				evictTx = txDesc.Tx
+				evictRate = feeRate
+			}
+		}
+
+		feeRate := evictRate + incrementalRelayFee
+		if feeRate > mp.rollingMinFee {
+			mp.rollingMinFee = feeRate
+			mp.lastRollingFeeUpdate = now
+			mp.blockSinceLastFeeBump = false
+		}
+
+		numBefore := len(mp.pool)
+		mp.removeTransaction(evictTx, true, RemovalReasonSizeLimit)
+		numEvicted += numBefore - len(mp.pool)
+	}
+
+	if numEvicted > 0 {
+		log.Debugf("Evicted %d %s to limit the mempool size (min fee "+
+			"rate %v per kB)", numEvicted, pickNoun(numEvicted,
+			"transaction", "transactions"),
+			ltcutil.Amount(mp.rollingMinFee))
+	}
+}
+
+// minFee returns the minimum fee rate in satoshi/kB required for transactions
+// to be accepted due to limiting the size of the pool.  It is zero when the
+// size of the pool has not been limited recently.
+//
+// Once a block has been connected after the fee rate was last raised, it
+// decays exponentially with a half-life which is shorter the less full the
+// pool is and it is reset to zero once it drops below half of the incremental
+// relay fee.
+//
+// This function MUST be called with the mempool lock held (for writes).
+func (mp *TxPool) minFee(now time.Time) ltcutil.Amount {
+	if !mp.blockSinceLastFeeBump || mp.rollingMinFee == 0 {
+		return ltcutil.Amount(mp.rollingMinFee)
+	}
+
+	if now.Sub(mp.lastRollingFeeUpdate) > rollingFeeUpdateInterval {
+		halfLife := rollingFeeHalfLife
+		maxSize := mp.cfg.Policy.MaxPoolSize
+		if mp.poolSize < maxSize/4 {
+			halfLife /= 4
+		} else if mp.poolSize < maxSize/2 {
+			halfLife /= 2
+		}
+
+		elapsed := now.Sub(mp.lastRollingFeeUpdate)
+		mp.rollingMinFee /= math.Pow(2, elapsed.Seconds()/
+			halfLife.Seconds())
+		mp.lastRollingFeeUpdate = now
+
+		if mp.rollingMinFee < incrementalRelayFee/2 {
+			mp.rollingMinFee = 0
+			return 0
+		}
+	}
+
+	if mp.rollingMinFee < incrementalRelayFee {
+		return incrementalRelayFee
+	}
+	return ltcutil.Amount(mp.rollingMinFee)
+}
+
+// MinFee returns the minimum fee rate per kB required for transactions to be
+// accepted into the pool.  It is the minimum relay fee of the policy unless
+// the size of the pool has been limited recently, in which case it is raised
+// above the fee rate of the evicted transactions.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) MinFee() ltcutil.Amount {
+	mp.mtx.Lock()
+	minFee := mp.minFee(time.Now())
+	mp.mtx.Unlock()
+
+	if minFee < mp.cfg.Policy.MinRelayTxFee {
+		return mp.cfg.Policy.MinRelayTxFee
+	}
+	return minFee
 }
 
 // LastUpdated returns the last time a transaction was added to or removed from
@@ -1196,15 +2664,64 @@ func (mp *TxPool) LastUpdated() time.Time {
 	return time.Unix(atomic.LoadInt64(&mp.lastUpdated), 0)
 }
 
+// TransactionsUpdated returns the number of times a transaction was added to or
+// removed from the main pool.  Unlike LastUpdated, it changes with every update
+// no matter how close together they are.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) TransactionsUpdated() uint64 {
+	return atomic.LoadUint64(&mp.txUpdates)
+}
+
+// orphanExpireHandler periodically evicts expired orphans from the orphan pool
+// so they do not linger when no new orphans are added.
+//
+// This function MUST be run as a goroutine.
+func (mp *TxPool) orphanExpireHandler() {
+	ticker := time.NewTicker(mp.expireScanInterval())
+	defer ticker.Stop()
+
+out:
+	for {
+		select {
+		case now := <-ticker.C:
+			mp.mtx.Lock()
+			mp.expireOrphans(now)
+			mp.mtx.Unlock()
+
+		case <-mp.quit:
+			break out
+		}
+	}
+
+	mp.wg.Done()
+}
+
+// Start begins periodically evicting expired orphans from the orphan pool.
+func (mp *TxPool) Start() {
+	mp.wg.Add(1)
+	go mp.orphanExpireHandler()
+}
+
+// Stop stops the periodic eviction of expired orphans and waits for it to
+// finish.
+func (mp *TxPool) Stop() {
+	close(mp.quit)
+	mp.wg.Wait()
+}
+
 // New returns a new memory pool for validating and storing standalone
 // transactions until they are mined into a block.
 func New(cfg *Config) *TxPool {
-	return &TxPool{
-		cfg:            *cfg,
-		pool:           make(map[chainhash.Hash]*TxDesc),
-		orphans:        make(map[chainhash.Hash]*orphanTx),
-		orphansByPrev:  make(map[wire.OutPoint]map[chainhash.Hash]*ltcutil.Tx),
-		nextExpireScan: time.Now().Add(orphanExpireScanInterval),
-		outpoints:      make(map[wire.OutPoint]*ltcutil.Tx),
+	mp := &TxPool{
+		cfg:           *cfg,
+		pool:          make(map[chainhash.Hash]*TxDesc),
+		orphans:       make(map[chainhash.Hash]*orphanTx),
+		orphansByPrev: make(map[wire.OutPoint]map[chainhash.Hash]*ltcutil.Tx),
+		outpoints:     make(map[wire.OutPoint]*ltcutil.Tx),
+		unbroadcast:   make(map[chainhash.Hash]struct{}),
+		quit:          make(chan struct{}),
 	}
+	mp.nextExpireScan = time.Now().Add(mp.expireScanInterval())
+	return mp
 }
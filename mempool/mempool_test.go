@@ -8,6 +8,7 @@ import (
 	"encoding/hex"
 	"reflect"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -185,12 +186,23 @@ func (p *poolHarness) CreateCoinbaseTx(blockHeight int32, numOutputs uint32) (*l
 // total input amount.  All outputs will be to the payment script associated
 // with the harness and all inputs are assumed to do the same.
 func (p *poolHarness) CreateSignedTx(inputs []spendableOutput, numOutputs uint32) (*ltcutil.Tx, error) {
-	// Calculate the total input amount and split it amongst the requested
-	// number of outputs.
+	return p.CreateSignedTxWithFee(inputs, numOutputs, 0,
+		wire.MaxTxInSequenceNum)
+}
+
+// CreateSignedTxWithFee creates a new signed transaction that consumes the
+// provided inputs with the provided sequence number and generates the provided
+// number of outputs by evenly splitting the total input amount less the
+// provided fee.  All outputs will be to the payment script associated with the
+// harness and all inputs are assumed to do the same.
+func (p *poolHarness) CreateSignedTxWithFee(inputs []spendableOutput, numOutputs uint32, fee ltcutil.Amount, sequence uint32) (*ltcutil.Tx, error) {
+	// Calculate the total input amount less the fee and split it amongst
+	// the requested number of outputs.
 	var totalInput ltcutil.Amount
 	for _, input := range inputs {
 		totalInput += input.amount
 	}
+	totalInput -= fee
 	amountPerOutput := int64(totalInput) / int64(numOutputs)
 	remainder := int64(totalInput) - amountPerOutput*int64(numOutputs)
 
@@ -199,7 +211,7 @@ func (p *poolHarness) CreateSignedTx(inputs []spendableOutput, numOutputs uint32
 		tx.AddTxIn(&wire.TxIn{
 			PreviousOutPoint: input.outPoint,
 			SignatureScript:  nil,
-			Sequence:         wire.MaxTxInSequenceNum,
+			Sequence:         sequence,
 		})
 	}
 	for i := uint32(0); i < numOutputs; i++ {
@@ -320,6 +332,7 @@ func newPoolHarness(chainParams *chaincfg.Params) (*poolHarness, []spendableOutp
 			MedianTimePast:   chain.MedianTimePast,
 			CalcSequenceLock: chain.CalcSequenceLock,
 			SigCache:         nil,
+			ScriptCache:      txscript.NewScriptCache(1000),
 			AddrIndex:        nil,
 		}),
 	}
@@ -567,6 +580,120 @@ func TestOrphanEviction(t *testing.T) {
 	}
 }
 
+// TestOrphanLimitsAndExpiry ensures the orphan pool is limited to the
+// configured number of orphans without evicting any for orphans which exceed
+// the configured size, and that orphans expire after the configured TTL both
+// when the pool is scanned on demand and periodically once it is started.
+func TestOrphanLimitsAndExpiry(t *testing.T) {
+	t.Parallel()
+
+	const (
+		maxOrphans = 4
+		orphanTTL  = time.Minute
+	)
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+	txPool.cfg.Policy.MaxOrphanTxs = maxOrphans
+	txPool.cfg.Policy.OrphanTTL = orphanTTL
+	expired := make(map[chainhash.Hash]struct{})
+	txPool.cfg.OnTxRemoved = func(tx *ltcutil.Tx, reason RemovalReason, sequence uint64) {
+		if reason == RemovalReasonOrphanTimeout {
+			expired[*tx.Hash()] = struct{}{}
+		}
+	}
+
+	// Fill the orphan pool past its limit and ensure it stays at the limit.
+	chainedTxns, err := harness.CreateTxChain(outputs[0], maxOrphans+4)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	for _, tx := range chainedTxns[1:] {
+		_, err := txPool.ProcessTransaction(tx, true, false, 0)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept valid "+
+				"orphan %v", err)
+		}
+	}
+	if got := txPool.OrphanCount(); got != maxOrphans {
+		t.Fatalf("OrphanCount: got %d, want %d", got, maxOrphans)
+	}
+
+	// Ensure an orphan which exceeds the max size is rejected without
+	// evicting any of the orphans in the pool.
+	var orphans []*ltcutil.Tx
+	for _, tx := range chainedTxns[1:] {
+		if txPool.IsOrphanInPool(tx.Hash()) {
+			orphans = append(orphans, tx)
+		}
+	}
+	bigMsgTx := chainedTxns[len(chainedTxns)-1].MsgTx().Copy()
+	bigMsgTx.AddTxOut(wire.NewTxOut(0,
+		make([]byte, txPool.cfg.Policy.MaxOrphanTxSize)))
+	_, err = txPool.ProcessTransaction(ltcutil.NewTx(bigMsgTx), true,
+		false, 0)
+	if _, ok := err.(RuleError); !ok {
+		t.Fatalf("ProcessTransaction: unexpected result for too large "+
+			"orphan: %v", err)
+	}
+	for _, tx := range orphans {
+		if !txPool.IsOrphanInPool(tx.Hash()) {
+			t.Fatalf("orphan %v was evicted for too large orphan",
+				tx.Hash())
+		}
+	}
+
+	// Ensure lowering the limit evicts enough orphans to make room for a
+	// new one.
+	txPool.mtx.Lock()
+	txPool.cfg.Policy.MaxOrphanTxs = 2
+	txPool.limitNumOrphans()
+	txPool.mtx.Unlock()
+	if got := txPool.OrphanCount(); got != 1 {
+		t.Fatalf("OrphanCount: got %d after lowering limit, want 1", got)
+	}
+
+	// Ensure the remaining orphans do not expire before the TTL and are
+	// reported as expired after it.
+	txPool.mtx.Lock()
+	txPool.expireOrphans(time.Now().Add(orphanTTL / 2))
+	txPool.mtx.Unlock()
+	if got := txPool.OrphanCount(); got != 1 || len(expired) != 0 {
+		t.Fatalf("OrphanCount: got %d with %d expired before TTL, "+
+			"want 1 with none expired", got, len(expired))
+	}
+	txPool.mtx.Lock()
+	txPool.expireOrphans(time.Now().Add(orphanTTL + time.Second))
+	txPool.mtx.Unlock()
+	if got := txPool.OrphanCount(); got != 0 || len(expired) != 1 {
+		t.Fatalf("OrphanCount: got %d with %d expired after TTL, "+
+			"want 0 with 1 expired", got, len(expired))
+	}
+
+	// Ensure orphans are expired periodically once the pool is started.
+	txPool.cfg.Policy.OrphanTTL = 10 * time.Millisecond
+	txPool.cfg.Policy.MaxOrphanTxs = maxOrphans
+	for _, tx := range chainedTxns[1:3] {
+		_, err := txPool.ProcessTransaction(tx, true, false, 0)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept valid "+
+				"orphan %v", err)
+		}
+	}
+	txPool.Start()
+	defer txPool.Stop()
+	deadline := time.Now().Add(5 * time.Second)
+	for txPool.OrphanCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("OrphanCount: %d orphans did not expire",
+				txPool.OrphanCount())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
 // TestBasicOrphanRemoval ensure that orphan removal works as expected when an
 // orphan that doesn't exist is removed  both when there is another orphan that
 // redeems it and when there is not.
@@ -794,3 +921,1406 @@ func TestMultiInputOrphanDoubleSpend(t *testing.T) {
 	// was not moved to the transaction pool.
 	testPoolMembership(tc, doubleSpendTx, false, false)
 }
+
+// TestAncestorTracking ensures that the in-pool ancestors of a transaction are
+// reported as expected for both a linear chain and a diamond dependency, and
+// that requesting the ancestors of a transaction which is not in the pool
+// fails.
+func TestAncestorTracking(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+
+	// Create a transaction with three outputs that the remaining
+	// transactions are built from and add it to the pool.
+	rootTx, err := harness.CreateSignedTx(outputs, 3)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	_, err = harness.txPool.ProcessTransaction(rootTx, false, false, 0)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept root tx: %v", err)
+	}
+
+	// Create a diamond where two transactions each spend a separate output
+	// of the root transaction and a final transaction spends an output from
+	// each of them.
+	leftTx, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(rootTx, 0),
+	}, 1)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	rightTx, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(rootTx, 1),
+	}, 1)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	joinTx, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(leftTx, 0),
+		txOutToSpendableOut(rightTx, 0),
+	}, 1)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+
+	// Create a linear chain of transactions that spends the final output
+	// of the root transaction.
+	chainedTxns, err := harness.CreateTxChain(txOutToSpendableOut(rootTx, 2), 3)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+
+	txns := append([]*ltcutil.Tx{leftTx, rightTx, joinTx}, chainedTxns...)
+	for _, tx := range txns {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept valid "+
+				"tx %v: %v", tx.Hash(), err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		tx        *ltcutil.Tx
+		ancestors []*ltcutil.Tx
+	}{
+		{
+			name:      "root has no ancestors",
+			tx:        rootTx,
+			ancestors: nil,
+		},
+		{
+			name:      "linear chain",
+			tx:        chainedTxns[2],
+			ancestors: []*ltcutil.Tx{rootTx, chainedTxns[0], chainedTxns[1]},
+		},
+		{
+			name:      "diamond dependency",
+			tx:        joinTx,
+			ancestors: []*ltcutil.Tx{rootTx, leftTx, rightTx},
+		},
+	}
+
+	for _, test := range tests {
+		ancestors, err := harness.txPool.TxAncestors(test.tx.Hash())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+
+		gotHashes := make(map[chainhash.Hash]struct{})
+		for _, desc := range ancestors {
+			gotHashes[*desc.Tx.Hash()] = struct{}{}
+		}
+		if len(ancestors) != len(test.ancestors) ||
+			len(gotHashes) != len(test.ancestors) {
+
+			t.Fatalf("%s: unexpected number of ancestors -- got %d, "+
+				"want %d", test.name, len(ancestors),
+				len(test.ancestors))
+		}
+		for _, tx := range test.ancestors {
+			if _, ok := gotHashes[*tx.Hash()]; !ok {
+				t.Fatalf("%s: missing ancestor %v", test.name,
+					tx.Hash())
+			}
+		}
+
+		// Ensure the verbose results describe the same set and that
+		// the ancestor statistics of each entry include itself.
+		verbose, err := harness.txPool.TxAncestorsVerbose(test.tx.Hash())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if len(verbose) != len(test.ancestors) {
+			t.Fatalf("%s: unexpected number of verbose ancestors -- "+
+				"got %d, want %d", test.name, len(verbose),
+				len(test.ancestors))
+		}
+		for _, tx := range test.ancestors {
+			entry, ok := verbose[tx.Hash().String()]
+			if !ok {
+				t.Fatalf("%s: missing verbose ancestor %v",
+					test.name, tx.Hash())
+			}
+			if entry.AncestorCount < 1 {
+				t.Fatalf("%s: ancestor count of %v does not "+
+					"include itself", test.name, tx.Hash())
+			}
+		}
+	}
+
+	// Ensure the ancestors of a transaction that is not in the pool are
+	// not reported.
+	missingTx, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(chainedTxns[2], 0),
+	}, 1)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	if _, err := harness.txPool.TxAncestors(missingTx.Hash()); err == nil {
+		t.Fatal("TxAncestors: did not fail for transaction not in pool")
+	}
+	if _, err := harness.txPool.TxAncestorsVerbose(missingTx.Hash()); err == nil {
+		t.Fatal("TxAncestorsVerbose: did not fail for transaction not " +
+			"in pool")
+	}
+}
+
+// TestDescendantTracking ensures that all in-pool descendants of a transaction
+// are reported as expected, along with their verbose details.
+func TestDescendantTracking(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+
+	// Create a parent transaction with two outputs, a child spending each
+	// of them, and a grandchild spending the output of the first child.
+	parentTx, err := harness.CreateSignedTx(outputs, 2)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	childTx1, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(parentTx, 0),
+	}, 1)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	childTx2, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(parentTx, 1),
+	}, 1)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	grandchildTx, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(childTx1, 0),
+	}, 1)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+
+	txns := []*ltcutil.Tx{parentTx, childTx1, childTx2, grandchildTx}
+	for _, tx := range txns {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+		if err != nil {
+			t.Fatalf("ProcessTransaction: failed to accept valid "+
+				"tx %v: %v", tx.Hash(), err)
+		}
+	}
+
+	// Ensure all three descendants of the parent are reported.
+	descendants, err := harness.txPool.TxDescendants(parentTx.Hash())
+	if err != nil {
+		t.Fatalf("TxDescendants: unexpected error: %v", err)
+	}
+	if len(descendants) != 3 {
+		t.Fatalf("TxDescendants: unexpected number of descendants -- "+
+			"got %d, want %d", len(descendants), 3)
+	}
+	verbose, err := harness.txPool.TxDescendantsVerbose(parentTx.Hash())
+	if err != nil {
+		t.Fatalf("TxDescendantsVerbose: unexpected error: %v", err)
+	}
+	for _, tx := range txns[1:] {
+		entry, ok := verbose[tx.Hash().String()]
+		if !ok {
+			t.Fatalf("TxDescendantsVerbose: missing descendant %v",
+				tx.Hash())
+		}
+		wantSize := int32(tx.MsgTx().SerializeSize())
+		if entry.Size != wantSize {
+			t.Fatalf("TxDescendantsVerbose: unexpected size for %v "+
+				"-- got %d, want %d", tx.Hash(), entry.Size,
+				wantSize)
+		}
+		if entry.Time == 0 {
+			t.Fatalf("TxDescendantsVerbose: time added not set for "+
+				"%v", tx.Hash())
+		}
+	}
+
+	// Ensure only the grandchild is reported for the first child and
+	// nothing is reported for the leaves.
+	descendants, err = harness.txPool.TxDescendants(childTx1.Hash())
+	if err != nil {
+		t.Fatalf("TxDescendants: unexpected error: %v", err)
+	}
+	if len(descendants) != 1 || *descendants[0].Tx.Hash() != *grandchildTx.Hash() {
+		t.Fatalf("TxDescendants: unexpected descendants of first child")
+	}
+	for _, tx := range []*ltcutil.Tx{childTx2, grandchildTx} {
+		descendants, err := harness.txPool.TxDescendants(tx.Hash())
+		if err != nil {
+			t.Fatalf("TxDescendants: unexpected error: %v", err)
+		}
+		if len(descendants) != 0 {
+			t.Fatalf("TxDescendants: unexpected descendants of %v",
+				tx.Hash())
+		}
+	}
+}
+
+// TestMempoolEntry ensures the getmempoolentry results of a chain of
+// transactions report the expected ancestor and descendant aggregates along
+// with whether or not they are replaceable as defined by BIP 125.
+func TestMempoolEntry(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+
+	// createTx returns a new signed transaction that spends the provided
+	// output with the provided fee and sequence number.
+	createTx := func(input spendableOutput, fee ltcutil.Amount, sequence uint32) *ltcutil.Tx {
+		tx, err := harness.CreateSignedTxWithFee([]spendableOutput{input},
+			1, fee, sequence)
+		if err != nil {
+			t.Fatalf("unable to create signed tx: %v", err)
+		}
+		return tx
+	}
+
+	// Create a root transaction with two outputs, a transaction spending
+	// the first one which signals replaceability along with a child of it
+	// which does not, and a transaction spending the second one which does
+	// not signal replaceability either.
+	rootTx, err := harness.CreateSignedTxWithFee(outputs, 2, 1000,
+		wire.MaxTxInSequenceNum)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	signalTx := createTx(txOutToSpendableOut(rootTx, 0), 2000,
+		replaceableSequence)
+	childTx := createTx(txOutToSpendableOut(signalTx, 0), 3000,
+		wire.MaxTxInSequenceNum)
+	plainTx := createTx(txOutToSpendableOut(rootTx, 1), 4000,
+		wire.MaxTxInSequenceNum)
+	for _, tx := range []*ltcutil.Tx{rootTx, signalTx, childTx, plainTx} {
+		mustAccept(t, harness, tx)
+	}
+
+	// sizes returns the total serialized size of the passed transactions.
+	sizes := func(txns ...*ltcutil.Tx) int64 {
+		var size int64
+		for _, tx := range txns {
+			size += int64(tx.MsgTx().SerializeSize())
+		}
+		return size
+	}
+
+	tests := []struct {
+		name            string
+		tx              *ltcutil.Tx
+		ancestorCount   int64
+		ancestorSize    int64
+		ancestorFees    ltcutil.Amount
+		descendantCount int64
+		descendantSize  int64
+		descendantFees  ltcutil.Amount
+		replaceable     string
+	}{{
+		name:            "root",
+		tx:              rootTx,
+		ancestorCount:   1,
+		ancestorSize:    sizes(rootTx),
+		ancestorFees:    1000,
+		descendantCount: 4,
+		descendantSize:  sizes(rootTx, signalTx, childTx, plainTx),
+		descendantFees:  10000,
+		replaceable:     "no",
+	}, {
+		name:            "signaling",
+		tx:              signalTx,
+		ancestorCount:   2,
+		ancestorSize:    sizes(rootTx, signalTx),
+		ancestorFees:    3000,
+		descendantCount: 2,
+		descendantSize:  sizes(signalTx, childTx),
+		descendantFees:  5000,
+		replaceable:     "yes",
+	}, {
+		name:            "child of signaling",
+		tx:              childTx,
+		ancestorCount:   3,
+		ancestorSize:    sizes(rootTx, signalTx, childTx),
+		ancestorFees:    6000,
+		descendantCount: 1,
+		descendantSize:  sizes(childTx),
+		descendantFees:  3000,
+		replaceable:     "yes",
+	}, {
+		name:            "not signaling",
+		tx:              plainTx,
+		ancestorCount:   2,
+		ancestorSize:    sizes(rootTx, plainTx),
+		ancestorFees:    5000,
+		descendantCount: 1,
+		descendantSize:  sizes(plainTx),
+		descendantFees:  4000,
+		replaceable:     "no",
+	}}
+	for _, test := range tests {
+		entry, err := harness.txPool.MempoolEntry(test.tx.Hash())
+		if err != nil {
+			t.Fatalf("%s: MempoolEntry: unexpected error: %v",
+				test.name, err)
+		}
+		if entry.AncestorCount != test.ancestorCount ||
+			entry.AncestorSize != test.ancestorSize ||
+			entry.AncestorFees != test.ancestorFees.ToBTC() {
+
+			t.Fatalf("%s: got ancestor count %d, size %d, fees %v, "+
+				"want %d, %d, %v", test.name, entry.AncestorCount,
+				entry.AncestorSize, entry.AncestorFees,
+				test.ancestorCount, test.ancestorSize,
+				test.ancestorFees.ToBTC())
+		}
+		if entry.DescendantCount != test.descendantCount ||
+			entry.DescendantSize != test.descendantSize ||
+			entry.DescendantFees != test.descendantFees.ToBTC() {
+
+			t.Fatalf("%s: got descendant count %d, size %d, fees "+
+				"%v, want %d, %d, %v", test.name,
+				entry.DescendantCount, entry.DescendantSize,
+				entry.DescendantFees, test.descendantCount,
+				test.descendantSize, test.descendantFees.ToBTC())
+		}
+		if entry.BIP125Replaceable != test.replaceable {
+			t.Fatalf("%s: got bip125-replaceable %q, want %q",
+				test.name, entry.BIP125Replaceable,
+				test.replaceable)
+		}
+	}
+
+	// Ensure an error is returned for a transaction not in the pool.
+	missingTx := createTx(txOutToSpendableOut(childTx, 0), 1000,
+		wire.MaxTxInSequenceNum)
+	if _, err := harness.txPool.MempoolEntry(missingTx.Hash()); err == nil {
+		t.Fatal("MempoolEntry: did not fail for transaction not in " +
+			"the pool")
+	}
+}
+
+// replaceableSequence is a sequence number which signals replaceability as
+// defined by BIP 125.
+const replaceableSequence = wire.MaxTxInSequenceNum - 2
+
+// TestReplaceByFee ensures that transactions which double spend transactions in
+// the pool are only accepted when they are valid BIP 125 replacements and that
+// the replaced transactions along with all of their descendants are evicted.
+func TestReplaceByFee(t *testing.T) {
+	t.Parallel()
+
+	// newHarness returns a new pool harness with replacements enabled and
+	// a root transaction in the pool that does not signal replaceability
+	// and has several outputs the tests can spend.
+	newHarness := func() (*poolHarness, *ltcutil.Tx) {
+		harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+		if err != nil {
+			t.Fatalf("unable to create test pool: %v", err)
+		}
+		harness.txPool.cfg.Policy.AcceptRBF = true
+
+		rootTx, err := harness.CreateSignedTx(outputs, 3)
+		if err != nil {
+			t.Fatalf("unable to create signed tx: %v", err)
+		}
+		mustAccept(t, harness, rootTx)
+		return harness, rootTx
+	}
+
+	// createTx returns a new signed transaction that spends the provided
+	// outputs with the provided fee and sequence number.
+	createTx := func(harness *poolHarness, inputs []spendableOutput, numOutputs uint32, fee ltcutil.Amount, sequence uint32) *ltcutil.Tx {
+		tx, err := harness.CreateSignedTxWithFee(inputs, numOutputs, fee,
+			sequence)
+		if err != nil {
+			t.Fatalf("unable to create signed tx: %v", err)
+		}
+		return tx
+	}
+
+	// assertRejected ensures the passed transaction is rejected with the
+	// provided reject code and is not added to the pool.
+	assertRejected := func(name string, harness *poolHarness, tx *ltcutil.Tx, wantCode wire.RejectCode) {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+		if err == nil {
+			t.Fatalf("%s: ProcessTransaction: accepted invalid "+
+				"replacement", name)
+		}
+		code, extracted := extractRejectCode(err)
+		if !extracted {
+			t.Fatalf("%s: ProcessTransaction: failed to extract "+
+				"reject code from error %q", name, err)
+		}
+		if code != wantCode {
+			t.Fatalf("%s: ProcessTransaction: unexpected reject "+
+				"code -- got %v, want %v (%v)", name, code,
+				wantCode, err)
+		}
+		testPoolMembership(&testContext{t, harness}, tx, false, false)
+	}
+
+	// Ensure a replacement is rejected when replacements are disabled.
+	harness, rootTx := newHarness()
+	rootOut := txOutToSpendableOut(rootTx, 0)
+	origTx := createTx(harness, []spendableOutput{rootOut}, 1, 1000,
+		replaceableSequence)
+	mustAccept(t, harness, origTx)
+	harness.txPool.cfg.Policy.AcceptRBF = false
+	replacement := createTx(harness, []spendableOutput{rootOut}, 1, 100000,
+		wire.MaxTxInSequenceNum)
+	assertRejected("disabled", harness, replacement, wire.RejectDuplicate)
+
+	// Ensure a replacement of a transaction which does not signal
+	// replaceability is rejected.
+	harness, rootTx = newHarness()
+	rootOut = txOutToSpendableOut(rootTx, 0)
+	origTx = createTx(harness, []spendableOutput{rootOut}, 1, 1000,
+		wire.MaxTxInSequenceNum)
+	mustAccept(t, harness, origTx)
+	replacement = createTx(harness, []spendableOutput{rootOut}, 1, 100000,
+		wire.MaxTxInSequenceNum)
+	assertRejected("no signal", harness, replacement, wire.RejectDuplicate)
+
+	// Ensure a replacement that does not pay more fees than the original
+	// transaction is rejected.
+	harness, rootTx = newHarness()
+	rootOut = txOutToSpendableOut(rootTx, 0)
+	origTx = createTx(harness, []spendableOutput{rootOut}, 1, 100000,
+		replaceableSequence)
+	mustAccept(t, harness, origTx)
+	replacement = createTx(harness, []spendableOutput{rootOut}, 1, 100000,
+		wire.MaxTxInSequenceNum)
+	assertRejected("insufficient fee", harness, replacement,
+		wire.RejectInsufficientFee)
+
+	// Ensure a replacement that spends an unconfirmed output which was not
+	// already spent by the transaction it replaces is rejected.
+	harness, rootTx = newHarness()
+	rootOut = txOutToSpendableOut(rootTx, 0)
+	origTx = createTx(harness, []spendableOutput{rootOut}, 1, 1000,
+		replaceableSequence)
+	mustAccept(t, harness, origTx)
+	unrelatedTx := createTx(harness, []spendableOutput{
+		txOutToSpendableOut(rootTx, 1),
+	}, 1, 0, wire.MaxTxInSequenceNum)
+	mustAccept(t, harness, unrelatedTx)
+	replacement = createTx(harness, []spendableOutput{
+		rootOut, txOutToSpendableOut(unrelatedTx, 0),
+	}, 1, 100000, wire.MaxTxInSequenceNum)
+	assertRejected("new unconfirmed input", harness, replacement,
+		wire.RejectNonstandard)
+
+	// Ensure a replacement that would evict more than the maximum allowed
+	// number of transactions is rejected.
+	harness, rootTx = newHarness()
+	rootOut = txOutToSpendableOut(rootTx, 0)
+	origTx = createTx(harness, []spendableOutput{rootOut},
+		MaxReplacementEvictions, 1000, replaceableSequence)
+	mustAccept(t, harness, origTx)
+	for i := uint32(0); i < MaxReplacementEvictions; i++ {
+		childTx := createTx(harness, []spendableOutput{
+			txOutToSpendableOut(origTx, i),
+		}, 1, 0, wire.MaxTxInSequenceNum)
+		mustAccept(t, harness, childTx)
+	}
+	replacement = createTx(harness, []spendableOutput{rootOut}, 1,
+		1000000, wire.MaxTxInSequenceNum)
+	assertRejected("too many evictions", harness, replacement,
+		wire.RejectNonstandard)
+
+	// Ensure a valid replacement is accepted and evicts the transaction it
+	// replaces along with all of its descendants.
+	harness, rootTx = newHarness()
+	rootOut = txOutToSpendableOut(rootTx, 0)
+	origTx = createTx(harness, []spendableOutput{rootOut}, 1, 1000,
+		replaceableSequence)
+	mustAccept(t, harness, origTx)
+	childTx := createTx(harness, []spendableOutput{
+		txOutToSpendableOut(origTx, 0),
+	}, 1, 0, wire.MaxTxInSequenceNum)
+	mustAccept(t, harness, childTx)
+	grandchildTx := createTx(harness, []spendableOutput{
+		txOutToSpendableOut(childTx, 0),
+	}, 1, 0, wire.MaxTxInSequenceNum)
+	mustAccept(t, harness, grandchildTx)
+	replacement = createTx(harness, []spendableOutput{rootOut}, 1, 100000,
+		wire.MaxTxInSequenceNum)
+	acceptedTxns, err := harness.txPool.ProcessTransaction(replacement,
+		false, false, 0)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: failed to accept valid "+
+			"replacement: %v", err)
+	}
+	if len(acceptedTxns) != 1 {
+		t.Fatalf("ProcessTransaction: reported %d accepted transactions, "+
+			"want 1", len(acceptedTxns))
+	}
+	wantReplaced := map[chainhash.Hash]struct{}{
+		*origTx.Hash():       {},
+		*childTx.Hash():      {},
+		*grandchildTx.Hash(): {},
+	}
+	replaced := acceptedTxns[0].ReplacedTxns
+	if len(replaced) != len(wantReplaced) {
+		t.Fatalf("ProcessTransaction: reported %d replaced "+
+			"transactions, want %d", len(replaced), len(wantReplaced))
+	}
+	for _, hash := range replaced {
+		if _, ok := wantReplaced[*hash]; !ok {
+			t.Fatalf("ProcessTransaction: unexpected replaced "+
+				"transaction %v", hash)
+		}
+	}
+	tc := &testContext{t, harness}
+	testPoolMembership(tc, replacement, false, true)
+	for _, tx := range []*ltcutil.Tx{origTx, childTx, grandchildTx} {
+		testPoolMembership(tc, tx, false, false)
+	}
+}
+
+// TestRemovalNotifications ensures the pool invokes the configured removal
+// callback with the appropriate reason when transactions are replaced or
+// removed because they were included in a block.
+func TestRemovalNotifications(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	harness.txPool.cfg.Policy.AcceptRBF = true
+	removed := make(map[chainhash.Hash]RemovalReason)
+	harness.txPool.cfg.OnTxRemoved = func(tx *ltcutil.Tx, reason RemovalReason, sequence uint64) {
+		removed[*tx.Hash()] = reason
+	}
+
+	// Create a transaction that signals replaceability along with a child
+	// that spends it and add them to the pool.
+	origTx, err := harness.CreateSignedTxWithFee(outputs[:1], 1, 1000,
+		replaceableSequence)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	mustAccept(t, harness, origTx)
+	childTx, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(origTx, 0),
+	}, 1)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	mustAccept(t, harness, childTx)
+	if len(removed) != 0 {
+		t.Fatalf("OnTxRemoved: unexpected removals %v", removed)
+	}
+
+	// Replace the transaction and ensure both it and its child are
+	// reported as replaced.
+	replacement, err := harness.CreateSignedTxWithFee(outputs[:1], 1,
+		100000, wire.MaxTxInSequenceNum)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	mustAccept(t, harness, replacement)
+	for _, tx := range []*ltcutil.Tx{origTx, childTx} {
+		reason, ok := removed[*tx.Hash()]
+		if !ok {
+			t.Fatalf("OnTxRemoved: replaced transaction %v was not "+
+				"reported", tx.Hash())
+		}
+		if reason != RemovalReasonReplaced {
+			t.Fatalf("OnTxRemoved: unexpected reason for %v -- "+
+				"got %v, want %v", tx.Hash(), reason,
+				RemovalReasonReplaced)
+		}
+	}
+	if len(removed) != 2 {
+		t.Fatalf("OnTxRemoved: reported %d removals, want 2",
+			len(removed))
+	}
+
+	// Ensure removing the replacement as though it were included in a
+	// block reports it as confirmed.
+	harness.txPool.RemoveTransaction(replacement, false,
+		RemovalReasonConfirmed)
+	if reason := removed[*replacement.Hash()]; reason != RemovalReasonConfirmed {
+		t.Fatalf("OnTxRemoved: unexpected reason for %v -- got %v, "+
+			"want %v", replacement.Hash(), reason,
+			RemovalReasonConfirmed)
+	}
+}
+
+// TestTransactionsUpdated ensures the number of updates to the pool is
+// incremented whenever a transaction is added to or removed from the pool.
+func TestTransactionsUpdated(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+	checkUpdates := func(desc string, want uint64) {
+		if got := txPool.TransactionsUpdated(); got != want {
+			t.Fatalf("%s: got %d updates, want %d", desc, got, want)
+		}
+	}
+	checkUpdates("empty pool", 0)
+
+	parentTx, err := harness.CreateSignedTx(outputs[:1], 1)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	mustAccept(t, harness, parentTx)
+	checkUpdates("parent added", 1)
+
+	childTx, err := harness.CreateSignedTx([]spendableOutput{
+		txOutToSpendableOut(parentTx, 0),
+	}, 1)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	mustAccept(t, harness, childTx)
+	checkUpdates("child added", 2)
+
+	// Removing the parent along with its redeemers updates the pool once
+	// for each transaction.
+	txPool.RemoveTransaction(parentTx, true, RemovalReasonConfirmed)
+	checkUpdates("transactions removed", 4)
+}
+
+// TestScriptCache ensures the inputs of the transactions accepted to the pool
+// are added to the script cache under the standard verification flags so their
+// scripts are not executed again when they are included in a block.
+func TestScriptCache(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	scriptCache := harness.txPool.cfg.ScriptCache
+
+	tx, err := harness.CreateSignedTx(outputs[:1], 2)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	wtxid := *tx.WitnessHash()
+	if scriptCache.Exists(wtxid, 0, txscript.ScriptBip16) {
+		t.Fatal("input of transaction in script cache before acceptance")
+	}
+	mustAccept(t, harness, tx)
+	if !scriptCache.Exists(wtxid, 0, txscript.StandardVerifyFlags) {
+		t.Fatal("input of accepted transaction not in script cache")
+	}
+	stats := scriptCache.Stats()
+	if stats.Entries != 1 || stats.Hits != 1 {
+		t.Fatalf("got %d entries and %d hits, want 1 of each",
+			stats.Entries, stats.Hits)
+	}
+}
+
+// TestPoolSizeLimit ensures the transactions paying the lowest fee rates are
+// evicted when the pool exceeds its maximum size, that the minimum fee rate
+// required to enter the pool rises above the minimum relay fee as a result,
+// and that it decays once a block is connected.
+func TestPoolSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+	evicted := make(map[chainhash.Hash]struct{})
+	txPool.cfg.OnTxRemoved = func(tx *ltcutil.Tx, reason RemovalReason, sequence uint64) {
+		if reason == RemovalReasonSizeLimit {
+			evicted[*tx.Hash()] = struct{}{}
+		}
+	}
+
+	// Create a root transaction along with children which spend its
+	// outputs and pay increasing fees.
+	rootTx, err := harness.CreateSignedTx(outputs, 6)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	mustAccept(t, harness, rootTx)
+	children := make([]*ltcutil.Tx, 0, 6)
+	for i := uint32(0); i < 6; i++ {
+		fee := ltcutil.Amount(1000 * (i + 1))
+		if i == 5 {
+			fee = 1000
+		}
+		tx, err := harness.CreateSignedTxWithFee([]spendableOutput{
+			txOutToSpendableOut(rootTx, i)}, 1, fee,
+			wire.MaxTxInSequenceNum)
+		if err != nil {
+			t.Fatalf("unable to create signed tx: %v", err)
+		}
+		children = append(children, tx)
+	}
+	for _, tx := range children[:4] {
+		mustAccept(t, harness, tx)
+	}
+
+	// The minimum fee rate is the minimum relay fee while the pool is not
+	// full.
+	if got := txPool.MinFee(); got != txPool.cfg.Policy.MinRelayTxFee {
+		t.Fatalf("MinFee: got %v before limiting the pool, want %v",
+			got, txPool.cfg.Policy.MinRelayTxFee)
+	}
+
+	// Limit the pool such that it is exceeded by adding another transaction
+	// and ensure adding one which pays a higher fee rate evicts the one
+	// paying the lowest.
+	txPool.mtx.Lock()
+	txPool.cfg.Policy.MaxPoolSize = txPool.poolSize +
+		int64(children[4].MsgTx().SerializeSize()/2)
+	txPool.mtx.Unlock()
+	mustAccept(t, harness, children[4])
+	if len(evicted) != 1 {
+		t.Fatalf("got %d evicted transactions, want 1", len(evicted))
+	}
+	if _, ok := evicted[*children[0].Hash()]; !ok {
+		t.Fatalf("transaction paying the lowest fee rate was not evicted")
+	}
+	testPoolMembership(&testContext{t, harness}, children[0], false, false)
+	for _, tx := range children[1:5] {
+		testPoolMembership(&testContext{t, harness}, tx, false, true)
+	}
+
+	// Ensure the minimum fee rate rose above both the minimum relay fee
+	// and the fee rate of the evicted transaction.
+	evictedRate := ltcutil.Amount(1000 * 1000 /
+		int64(GetTxVirtualSize(children[0])))
+	minFee := txPool.MinFee()
+	if minFee <= txPool.cfg.Policy.MinRelayTxFee || minFee <= evictedRate {
+		t.Fatalf("MinFee: got %v after limiting the pool, want more "+
+			"than %v and %v", minFee, txPool.cfg.Policy.MinRelayTxFee,
+			evictedRate)
+	}
+
+	// Ensure a transaction which pays the minimum relay fee, but not the
+	// raised minimum fee rate, is rejected.
+	_, err = txPool.ProcessTransaction(children[5], false, false, 0)
+	if code, _ := extractRejectCode(err); code != wire.RejectInsufficientFee {
+		t.Fatalf("ProcessTransaction: unexpected result for tx under "+
+			"the mempool min fee: %v", err)
+	}
+	testPoolMembership(&testContext{t, harness}, children[5], false, false)
+
+	// Ensure the minimum fee rate does not decay until a block has been
+	// connected and decays back to the minimum relay fee afterwards.
+	later := time.Now().Add(rollingFeeHalfLife * 10)
+	txPool.mtx.Lock()
+	got := txPool.minFee(later)
+	txPool.mtx.Unlock()
+	if got != minFee {
+		t.Fatalf("minFee: got %v before a block was connected, want %v",
+			got, minFee)
+	}
+	txPool.RemoveTransaction(children[4], false, RemovalReasonConfirmed)
+	txPool.mtx.Lock()
+	got = txPool.minFee(later)
+	txPool.mtx.Unlock()
+	if got != 0 {
+		t.Fatalf("minFee: got %v after decaying, want 0", got)
+	}
+	if got := txPool.MinFee(); got != txPool.cfg.Policy.MinRelayTxFee {
+		t.Fatalf("MinFee: got %v after decaying, want %v", got,
+			txPool.cfg.Policy.MinRelayTxFee)
+	}
+}
+
+// TestPoolSizeLimitPackages ensures the fee rates of transactions are
+// evaluated along with their descendants when limiting the size of the pool so
+// a transaction paying a low fee rate is not evicted before others paying
+// higher ones when its descendants pay for it.
+func TestPoolSizeLimitPackages(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+	var evicted []*chainhash.Hash
+	txPool.cfg.OnTxRemoved = func(tx *ltcutil.Tx, reason RemovalReason, sequence uint64) {
+		if reason == RemovalReasonSizeLimit {
+			evicted = append(evicted, tx.Hash())
+		}
+	}
+
+	// createTx returns a new signed transaction that spends the provided
+	// output of the passed transaction with the provided fee.
+	createTx := func(parent *ltcutil.Tx, index uint32, fee ltcutil.Amount) *ltcutil.Tx {
+		tx, err := harness.CreateSignedTxWithFee([]spendableOutput{
+			txOutToSpendableOut(parent, index)}, 1, fee,
+			wire.MaxTxInSequenceNum)
+		if err != nil {
+			t.Fatalf("unable to create signed tx: %v", err)
+		}
+		return tx
+	}
+
+	// Create a parent paying a low fee rate with a child paying for it and
+	// a standalone transaction paying a higher fee rate than the parent,
+	// but a lower one than the package formed by the parent and its child.
+	rootTx, err := harness.CreateSignedTx(outputs, 4)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	parentTx := createTx(rootTx, 0, 1000)
+	childTx := createTx(parentTx, 0, 20000)
+	standaloneTx := createTx(rootTx, 1, 4000)
+	highFeeTx := createTx(rootTx, 2, 8000)
+	for _, tx := range []*ltcutil.Tx{rootTx, parentTx, childTx, standaloneTx,
+		highFeeTx} {
+
+		mustAccept(t, harness, tx)
+	}
+
+	// Limit the pool such that it is exceeded by adding another transaction
+	// and ensure only the standalone transaction is evicted.
+	newTx := createTx(rootTx, 3, 12000)
+	txPool.mtx.Lock()
+	txPool.cfg.Policy.MaxPoolSize = txPool.poolSize +
+		int64(newTx.MsgTx().SerializeSize()/2)
+	txPool.mtx.Unlock()
+	mustAccept(t, harness, newTx)
+	if len(evicted) != 1 || *evicted[0] != *standaloneTx.Hash() {
+		t.Fatalf("got evicted transactions %v, want only %v", evicted,
+			standaloneTx.Hash())
+	}
+	tc := &testContext{t, harness}
+	for _, tx := range []*ltcutil.Tx{rootTx, parentTx, childTx, highFeeTx,
+		newTx} {
+
+		testPoolMembership(tc, tx, false, true)
+	}
+
+	// Ensure lowering the limit evicts the package with the lowest fee rate
+	// next, which is the root transaction along with all of its
+	// descendants since it does not pay a fee.
+	txPool.mtx.Lock()
+	txPool.cfg.Policy.MaxPoolSize = txPool.poolSize - 1
+	txPool.trimToSize(time.Now())
+	txPool.mtx.Unlock()
+	if txPool.Count() != 0 {
+		t.Fatalf("got %d transactions in the pool, want none",
+			txPool.Count())
+	}
+	if len(evicted) != 6 {
+		t.Fatalf("got %d evicted transactions, want 6", len(evicted))
+	}
+}
+
+// TestPoolExpiry ensures transactions which stay in the pool for longer than
+// the expiry allowed by the policy are evicted along with their descendants
+// while the other transactions are kept.
+func TestPoolExpiry(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+	txPool.cfg.Policy.Expiry = time.Hour
+	var expired []*chainhash.Hash
+	txPool.cfg.OnTxRemoved = func(tx *ltcutil.Tx, reason RemovalReason, sequence uint64) {
+		if reason == RemovalReasonExpired {
+			expired = append(expired, tx.Hash())
+		}
+	}
+
+	// createTx returns a new signed transaction that spends the provided
+	// output of the passed transaction.
+	createTx := func(parent *ltcutil.Tx, index uint32) *ltcutil.Tx {
+		tx, err := harness.CreateSignedTx([]spendableOutput{
+			txOutToSpendableOut(parent, index)}, 1)
+		if err != nil {
+			t.Fatalf("unable to create signed tx: %v", err)
+		}
+		return tx
+	}
+
+	// Create a root transaction with a parent and child spending one of
+	// its outputs and standalone transactions spending the others.
+	rootTx, err := harness.CreateSignedTx(outputs, 3)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	parentTx := createTx(rootTx, 0)
+	childTx := createTx(parentTx, 0)
+	standaloneTx := createTx(rootTx, 1)
+	for _, tx := range []*ltcutil.Tx{rootTx, parentTx, childTx,
+		standaloneTx} {
+
+		mustAccept(t, harness, tx)
+	}
+
+	// Make the parent transaction older than the expiry and ensure it is
+	// only evicted along with its child once the next scan is due.  The
+	// child is removed first.
+	now := time.Now()
+	txPool.mtx.Lock()
+	txPool.pool[*parentTx.Hash()].Added = now.Add(-2 * time.Hour)
+	txPool.expireTransactions(now)
+	txPool.mtx.Unlock()
+	if len(expired) != 0 {
+		t.Fatalf("got expired transactions %v before the next scan",
+			expired)
+	}
+	txPool.mtx.Lock()
+	txPool.expireTransactions(now.Add(poolExpireScanInterval))
+	txPool.mtx.Unlock()
+	if len(expired) != 2 || *expired[0] != *childTx.Hash() ||
+		*expired[1] != *parentTx.Hash() {
+
+		t.Fatalf("got expired transactions %v, want %v and %v",
+			expired, childTx.Hash(), parentTx.Hash())
+	}
+	tc := &testContext{t, harness}
+	testPoolMembership(tc, rootTx, false, true)
+	testPoolMembership(tc, parentTx, false, false)
+	testPoolMembership(tc, childTx, false, false)
+	testPoolMembership(tc, standaloneTx, false, true)
+
+	// Ensure accepting a transaction evicts the expired transactions once
+	// the next scan is due.
+	txPool.mtx.Lock()
+	txPool.pool[*standaloneTx.Hash()].Added = now.Add(-2 * time.Hour)
+	txPool.nextPoolExpireScan = now
+	txPool.mtx.Unlock()
+	newTx := createTx(rootTx, 2)
+	mustAccept(t, harness, newTx)
+	if len(expired) != 3 || *expired[2] != *standaloneTx.Hash() {
+		t.Fatalf("got expired transactions %v, want %v to be expired "+
+			"last", expired, standaloneTx.Hash())
+	}
+	testPoolMembership(tc, standaloneTx, false, false)
+	testPoolMembership(tc, newTx, false, true)
+
+	// Ensure transactions do not expire without an expiry.
+	txPool.mtx.Lock()
+	txPool.cfg.Policy.Expiry = 0
+	txPool.pool[*rootTx.Hash()].Added = now.Add(-2 * time.Hour)
+	txPool.nextPoolExpireScan = time.Time{}
+	txPool.expireTransactions(now)
+	txPool.mtx.Unlock()
+	if txPool.Count() != 2 {
+		t.Fatalf("got %d transactions in the pool, want 2",
+			txPool.Count())
+	}
+}
+
+// TestPackageLimits ensures transactions which would exceed the limits on the
+// number and total size of their ancestors, or of the descendants of any of
+// their ancestors, in the pool are rejected.
+func TestPackageLimits(t *testing.T) {
+	t.Parallel()
+
+	// assertRejected ensures the passed transaction is rejected as
+	// non-standard and is not added to the pool.
+	assertRejected := func(name string, harness *poolHarness, tx *ltcutil.Tx) {
+		_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+		code, _ := extractRejectCode(err)
+		if code != wire.RejectNonstandard {
+			t.Fatalf("%s: ProcessTransaction: unexpected result -- "+
+				"got %v, want reject code %v", name, err,
+				wire.RejectNonstandard)
+		}
+		testPoolMembership(&testContext{t, harness}, tx, false, false)
+	}
+
+	// Ensure a chain of transactions is accepted up to the ancestor count
+	// limit.
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	harness.txPool.cfg.Policy.MaxAncestorCount = DefaultMaxAncestorCount
+	chainedTxns, err := harness.CreateTxChain(outputs[0],
+		DefaultMaxAncestorCount+1)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	for _, tx := range chainedTxns[:DefaultMaxAncestorCount] {
+		mustAccept(t, harness, tx)
+	}
+	assertRejected("ancestor count", harness,
+		chainedTxns[DefaultMaxAncestorCount])
+
+	// Ensure a chain of transactions is accepted up to the ancestor size
+	// limit.
+	harness, outputs, err = newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	chainedTxns, err = harness.CreateTxChain(outputs[0], 3)
+	if err != nil {
+		t.Fatalf("unable to create transaction chain: %v", err)
+	}
+	harness.txPool.cfg.Policy.MaxAncestorSize =
+		GetTxVirtualSize(chainedTxns[0]) + GetTxVirtualSize(chainedTxns[1])
+	mustAccept(t, harness, chainedTxns[0])
+	mustAccept(t, harness, chainedTxns[1])
+	assertRejected("ancestor size", harness, chainedTxns[2])
+
+	// Ensure children of a transaction are accepted up to the descendant
+	// count limit.
+	harness, outputs, err = newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	harness.txPool.cfg.Policy.MaxDescendantCount = DefaultMaxDescendantCount
+	rootTx, err := harness.CreateSignedTx(outputs, DefaultMaxDescendantCount)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	mustAccept(t, harness, rootTx)
+	var children []*ltcutil.Tx
+	for i := uint32(0); i < DefaultMaxDescendantCount; i++ {
+		tx, err := harness.CreateSignedTx([]spendableOutput{
+			txOutToSpendableOut(rootTx, i)}, 1)
+		if err != nil {
+			t.Fatalf("unable to create signed tx: %v", err)
+		}
+		children = append(children, tx)
+	}
+	for _, tx := range children[:DefaultMaxDescendantCount-1] {
+		mustAccept(t, harness, tx)
+	}
+	assertRejected("descendant count", harness,
+		children[DefaultMaxDescendantCount-1])
+
+	// Ensure children of a transaction are accepted up to the descendant
+	// size limit.
+	descendantSize := GetTxVirtualSize(rootTx)
+	for _, tx := range children[:DefaultMaxDescendantCount-1] {
+		descendantSize += GetTxVirtualSize(tx)
+	}
+	harness.txPool.cfg.Policy.MaxDescendantCount = 0
+	harness.txPool.cfg.Policy.MaxDescendantSize = descendantSize
+	assertRejected("descendant size", harness,
+		children[DefaultMaxDescendantCount-1])
+	harness.txPool.cfg.Policy.MaxDescendantSize = 0
+	mustAccept(t, harness, children[DefaultMaxDescendantCount-1])
+}
+
+// TestProcessPackage ensures a parent which pays too low a fee to be accepted
+// on its own is accepted along with a child which pays for it when they are
+// processed as a package, and that packages which are malformed or do not pay
+// enough fees are rejected as a whole.
+func TestProcessPackage(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+	txPool.cfg.Policy.DisableRelayPriority = false
+	tc := &testContext{t, harness}
+
+	// createTx returns a new signed transaction that spends the provided
+	// outputs with the provided fee.
+	createTx := func(inputs []spendableOutput, numOutputs uint32, fee ltcutil.Amount) *ltcutil.Tx {
+		tx, err := harness.CreateSignedTxWithFee(inputs, numOutputs, fee,
+			wire.MaxTxInSequenceNum)
+		if err != nil {
+			t.Fatalf("unable to create signed tx: %v", err)
+		}
+		return tx
+	}
+
+	// Create a root transaction in the pool along with parents spending
+	// its unconfirmed outputs which have no priority and do not pay a fee,
+	// so they are rejected on their own.
+	rootTx := createTx(outputs, 4, 1000)
+	mustAccept(t, harness, rootTx)
+	parentTx := createTx([]spendableOutput{txOutToSpendableOut(rootTx, 0)},
+		1, 0)
+	otherParentTx := createTx([]spendableOutput{
+		txOutToSpendableOut(rootTx, 1)}, 1, 0)
+	_, err = txPool.ProcessTransaction(parentTx, false, false, 0)
+	if code, _ := extractRejectCode(err); code != wire.RejectInsufficientFee {
+		t.Fatalf("ProcessTransaction: unexpected result for parent "+
+			"without fee: %v", err)
+	}
+
+	// Ensure malformed packages are rejected without adding any of their
+	// transactions to the pool.
+	childInputs := []spendableOutput{txOutToSpendableOut(parentTx, 0),
+		txOutToSpendableOut(otherParentTx, 0)}
+	childTx := createTx(childInputs, 1, 2000)
+	unrelatedTx := createTx([]spendableOutput{
+		txOutToSpendableOut(rootTx, 2)}, 1, 1000)
+	conflictTx := createTx([]spendableOutput{
+		txOutToSpendableOut(rootTx, 0)}, 1, 1000)
+	malformed := []struct {
+		name string
+		txns []*ltcutil.Tx
+	}{
+		{"empty", nil},
+		{"unsorted", []*ltcutil.Tx{childTx, parentTx, otherParentTx}},
+		{"duplicate", []*ltcutil.Tx{parentTx, parentTx, childTx}},
+		{"conflict", []*ltcutil.Tx{parentTx, conflictTx, childTx}},
+		{"not a parent", []*ltcutil.Tx{parentTx, unrelatedTx, childTx}},
+	}
+	for _, test := range malformed {
+		_, err := txPool.ProcessPackage(test.txns)
+		if code, _ := extractRejectCode(err); code != wire.RejectInvalid {
+			t.Fatalf("%s: ProcessPackage: unexpected result: %v",
+				test.name, err)
+		}
+		if txPool.Count() != 1 {
+			t.Fatalf("%s: ProcessPackage: rejected package added "+
+				"transactions to the pool", test.name)
+		}
+	}
+
+	// Ensure a package whose child does not pay enough for its parents is
+	// rejected as a whole.
+	pkg := []*ltcutil.Tx{parentTx, otherParentTx, createTx(childInputs, 1,
+		100)}
+	_, err = txPool.ProcessPackage(pkg)
+	if code, _ := extractRejectCode(err); code != wire.RejectInsufficientFee {
+		t.Fatalf("ProcessPackage: unexpected result for package "+
+			"under the min fee: %v", err)
+	}
+	for _, tx := range pkg {
+		testPoolMembership(tc, tx, false, false)
+	}
+
+	// Ensure a package which contains an invalid transaction is rejected
+	// as a whole and identifies the rejected transaction.
+	invalidChild := childTx.MsgTx().Copy()
+	invalidChild.TxIn[0].SignatureScript = nil
+	pkg = []*ltcutil.Tx{parentTx, otherParentTx, ltcutil.NewTx(invalidChild)}
+	_, err = txPool.ProcessPackage(pkg)
+	if _, ok := err.(RuleError); !ok || !strings.Contains(err.Error(),
+		pkg[2].Hash().String()) {
+
+		t.Fatalf("ProcessPackage: unexpected result for package with "+
+			"invalid transaction: %v", err)
+	}
+	for _, tx := range pkg {
+		testPoolMembership(tc, tx, false, false)
+	}
+
+	// Ensure the parents are accepted along with a child which pays for
+	// them and that the results report the fee rate of the package.
+	pkg = []*ltcutil.Tx{parentTx, otherParentTx, childTx}
+	result, err := txPool.ProcessPackage(pkg)
+	if err != nil {
+		t.Fatalf("ProcessPackage: failed to accept valid package: %v",
+			err)
+	}
+	if len(result.TxDescs) != len(pkg) || len(result.Accepted) != len(pkg) {
+		t.Fatalf("ProcessPackage: got %d descriptors with %d accepted, "+
+			"want %d", len(result.TxDescs), len(result.Accepted),
+			len(pkg))
+	}
+	var packageSize int64
+	for i, tx := range pkg {
+		if *result.TxDescs[i].Tx.Hash() != *tx.Hash() {
+			t.Fatalf("ProcessPackage: descriptor %d is for %v, want "+
+				"%v", i, result.TxDescs[i].Tx.Hash(), tx.Hash())
+		}
+		packageSize += GetTxVirtualSize(tx)
+		testPoolMembership(tc, tx, false, true)
+	}
+	wantFeeRate := ltcutil.Amount(2000 * 1000 / packageSize)
+	if result.FeeRate != wantFeeRate {
+		t.Fatalf("ProcessPackage: got fee rate %v, want %v",
+			result.FeeRate, wantFeeRate)
+	}
+
+	// Ensure submitting the package again skips the transactions which are
+	// already in the pool.
+	result, err = txPool.ProcessPackage(pkg)
+	if err != nil {
+		t.Fatalf("ProcessPackage: failed to process package already "+
+			"in the pool: %v", err)
+	}
+	if len(result.TxDescs) != len(pkg) || len(result.Accepted) != 0 {
+		t.Fatalf("ProcessPackage: got %d descriptors with %d accepted "+
+			"for package already in the pool, want %d with none",
+			len(result.TxDescs), len(result.Accepted), len(pkg))
+	}
+}
+
+// mustAccept ensures the passed transaction is accepted to the pool of the
+// passed harness.
+func mustAccept(t *testing.T, harness *poolHarness, tx *ltcutil.Tx) {
+	_, err := harness.txPool.ProcessTransaction(tx, false, false, 0)
+	if err != nil {
+		_, file, line, _ := runtime.Caller(1)
+		t.Fatalf("%s:%d -- ProcessTransaction: failed to accept valid "+
+			"tx %v: %v", file, line, tx.Hash(), err)
+	}
+}
+
+// TestTestAcceptTransactions ensures checking whether or not transactions
+// would be accepted to the pool reports the expected results without modifying
+// the pool.
+func TestTestAcceptTransactions(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+	tc := &testContext{t, harness}
+
+	// createTx returns a new signed transaction that spends the provided
+	// outputs with the provided fee.
+	createTx := func(inputs []spendableOutput, numOutputs uint32, fee ltcutil.Amount) *ltcutil.Tx {
+		tx, err := harness.CreateSignedTxWithFee(inputs, numOutputs, fee,
+			wire.MaxTxInSequenceNum)
+		if err != nil {
+			t.Fatalf("unable to create signed tx: %v", err)
+		}
+		return tx
+	}
+
+	// Create a root transaction in the pool which the other transactions
+	// spend.
+	rootTx := createTx(outputs, 3, 1000)
+	mustAccept(t, harness, rootTx)
+
+	// Ensure a valid transaction is allowed and reports its fee.
+	validTx := createTx([]spendableOutput{txOutToSpendableOut(rootTx, 0)},
+		1, 1000)
+	results, err := txPool.TestAcceptTransactions([]*ltcutil.Tx{validTx})
+	if err != nil {
+		t.Fatalf("TestAcceptTransactions: unexpected error: %v", err)
+	}
+	if len(results) != 1 || results[0].Err != nil || results[0].Fee != 1000 {
+		t.Fatalf("TestAcceptTransactions: unexpected result for valid "+
+			"transaction: %+v", results[0])
+	}
+	testPoolMembership(tc, validTx, false, false)
+
+	// Ensure a transaction which has no priority and does not pay a fee is
+	// rejected.
+	txPool.cfg.Policy.DisableRelayPriority = false
+	lowFeeTx := createTx([]spendableOutput{txOutToSpendableOut(rootTx, 1)},
+		1, 0)
+	results, err = txPool.TestAcceptTransactions([]*ltcutil.Tx{lowFeeTx})
+	if err != nil {
+		t.Fatalf("TestAcceptTransactions: unexpected error: %v", err)
+	}
+	code, _ := extractRejectCode(results[0].Err)
+	if code != wire.RejectInsufficientFee {
+		t.Fatalf("TestAcceptTransactions: unexpected result for low "+
+			"fee transaction: %v", results[0].Err)
+	}
+
+	// Ensure a child is allowed along with the parent it spends when they
+	// are checked together, but not on its own, and that neither of them
+	// is added to the pool.
+	parentTx := createTx([]spendableOutput{txOutToSpendableOut(rootTx, 2)},
+		1, 1000)
+	childTx := createTx([]spendableOutput{txOutToSpendableOut(parentTx, 0)},
+		1, 1000)
+	results, err = txPool.TestAcceptTransactions([]*ltcutil.Tx{parentTx,
+		childTx})
+	if err != nil {
+		t.Fatalf("TestAcceptTransactions: unexpected error: %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil || result.Fee != 1000 {
+			t.Fatalf("TestAcceptTransactions: unexpected result for "+
+				"package transaction %d: %+v", i, result)
+		}
+	}
+	testPoolMembership(tc, parentTx, false, false)
+	testPoolMembership(tc, childTx, false, false)
+	if txPool.Count() != 1 {
+		t.Fatalf("TestAcceptTransactions: pool has %d transactions, "+
+			"want 1", txPool.Count())
+	}
+	results, err = txPool.TestAcceptTransactions([]*ltcutil.Tx{childTx})
+	if err != nil {
+		t.Fatalf("TestAcceptTransactions: unexpected error: %v", err)
+	}
+	if code, _ := extractRejectCode(results[0].Err); code != wire.RejectDuplicate {
+		t.Fatalf("TestAcceptTransactions: unexpected result for child "+
+			"without its parent: %v", results[0].Err)
+	}
+
+	// Ensure an unsorted package is rejected.
+	_, err = txPool.TestAcceptTransactions([]*ltcutil.Tx{childTx, parentTx})
+	if code, _ := extractRejectCode(err); code != wire.RejectInvalid {
+		t.Fatalf("TestAcceptTransactions: unexpected result for "+
+			"unsorted package: %v", err)
+	}
+}
+
+// TestUnbroadcastTxs ensures transactions are only tracked as unbroadcast while
+// they are in the pool and that their mempool entries report them as such.
+func TestUnbroadcastTxs(t *testing.T) {
+	t.Parallel()
+
+	harness, outputs, err := newPoolHarness(&chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create test pool: %v", err)
+	}
+	txPool := harness.txPool
+
+	// Transactions which are not in the pool are ignored.
+	tx, err := harness.CreateSignedTx(outputs, 1)
+	if err != nil {
+		t.Fatalf("unable to create signed tx: %v", err)
+	}
+	txPool.AddUnbroadcastTx(tx.Hash())
+	if txPool.UnbroadcastCount() != 0 || txPool.IsUnbroadcastTx(tx.Hash()) {
+		t.Fatal("transaction not in the pool is tracked as unbroadcast")
+	}
+
+	// Ensure a transaction in the pool is tracked until it is marked as
+	// broadcast.
+	mustAccept(t, harness, tx)
+	txPool.AddUnbroadcastTx(tx.Hash())
+	if txPool.UnbroadcastCount() != 1 || !txPool.IsUnbroadcastTx(tx.Hash()) {
+		t.Fatal("transaction is not tracked as unbroadcast")
+	}
+	descs := txPool.UnbroadcastTxDescs()
+	if len(descs) != 1 || *descs[0].Tx.Hash() != *tx.Hash() {
+		t.Fatalf("got %d unbroadcast descriptors, want the transaction",
+			len(descs))
+	}
+	entry, err := txPool.MempoolEntry(tx.Hash())
+	if err != nil {
+		t.Fatalf("MempoolEntry: unexpected error: %v", err)
+	}
+	if !entry.Unbroadcast {
+		t.Fatal("mempool entry is not reported as unbroadcast")
+	}
+	txPool.RemoveUnbroadcastTx(tx.Hash())
+	if txPool.UnbroadcastCount() != 0 {
+		t.Fatal("transaction marked as broadcast is still tracked")
+	}
+
+	// Ensure removing the transaction from the pool stops tracking it.
+	txPool.AddUnbroadcastTx(tx.Hash())
+	txPool.RemoveTransaction(tx, false, RemovalReasonConfirmed)
+	if txPool.UnbroadcastCount() != 0 {
+		t.Fatal("transaction removed from the pool is still tracked")
+	}
+}
@@ -204,8 +204,12 @@ func (m *CPUMiner) submitBlock(block *ltcutil.Block) bool {
 // This function will return early with false when conditions that trigger a
 // stale block such as a new block showing up or periodically when there are
 // new transactions and enough time has elapsed without finding a solution.
+//
+// When tries is not nil, it is the number of hashes which may still be tried
+// and is decremented for each one.  The function returns false once it
+// reaches zero without finding a solution.
 func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
-	ticker *time.Ticker, quit chan struct{}) bool {
+	ticker *time.Ticker, quit chan struct{}, tries *uint64) bool {
 
 	// Choose a random extra nonce offset for this block template and
 	// worker.
@@ -286,6 +290,14 @@ func (m *CPUMiner) solveBlock(msgBlock *wire.MsgBlock, blockHeight int32,
 				m.updateHashes <- hashesCompleted
 				return true
 			}
+
+			if tries != nil {
+				*tries--
+				if *tries == 0 {
+					m.updateHashes <- hashesCompleted
+					return false
+				}
+			}
 		}
 	}
 
@@ -357,7 +369,7 @@ out:
 		// with false when conditions that trigger a stale block, so
 		// a new block template can be generated.  When the return is
 		// true a solution was found, so submit the solved block.
-		if m.solveBlock(template.Block, curHeight+1, ticker, quit) {
+		if m.solveBlock(template.Block, curHeight+1, ticker, quit, nil) {
 			block := ltcutil.NewBlock(template.Block)
 			m.submitBlock(block)
 		}
@@ -548,6 +560,21 @@ func (m *CPUMiner) NumWorkers() int32 {
 // generating a new block template.  When a block is solved, it is submitted.
 // The function returns a list of the hashes of generated blocks.
 func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
+	return m.generateNBlocks(n, nil, 0)
+}
+
+// GenerateNBlocksToAddress generates the requested number of blocks paying to
+// the passed address in the same way as GenerateNBlocks.  When maxTries is not
+// zero, it bounds the number of hashes tried for each block and an error is
+// returned once it is exhausted.
+func (m *CPUMiner) GenerateNBlocksToAddress(n uint32, payToAddr ltcutil.Address, maxTries uint64) ([]*chainhash.Hash, error) {
+	return m.generateNBlocks(n, payToAddr, maxTries)
+}
+
+// generateNBlocks generates the requested number of blocks paying to the
+// passed address, or to a random mining address when it is nil.  When maxTries
+// is not zero, it bounds the number of hashes tried for each block.
+func (m *CPUMiner) generateNBlocks(n uint32, payToAddr ltcutil.Address, maxTries uint64) ([]*chainhash.Hash, error) {
 	m.Lock()
 
 	// Respond with an error if server is already mining.
@@ -566,10 +593,24 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 
 	m.Unlock()
 
+	stop := func() {
+		m.Lock()
+		close(m.speedMonitorQuit)
+		m.wg.Wait()
+		m.started = false
+		m.discreteMining = false
+		m.Unlock()
+	}
+
 	log.Tracef("Generating %d blocks", n)
 
 	i := uint32(0)
 	blockHashes := make([]*chainhash.Hash, n)
+	var tries *uint64
+	if maxTries != 0 {
+		remainingTries := maxTries
+		tries = &remainingTries
+	}
 
 	// Start a ticker which is used to signal checks for stale work and
 	// updates to the speed monitor.
@@ -591,14 +632,17 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 		m.submitBlockLock.Lock()
 		curHeight := m.g.BestSnapshot().Height
 
-		// Choose a payment address at random.
-		rand.Seed(time.Now().UnixNano())
-		payToAddr := m.cfg.MiningAddrs[rand.Intn(len(m.cfg.MiningAddrs))]
+		// Choose a payment address at random unless one was provided.
+		blockPayToAddr := payToAddr
+		if blockPayToAddr == nil {
+			rand.Seed(time.Now().UnixNano())
+			blockPayToAddr = m.cfg.MiningAddrs[rand.Intn(len(m.cfg.MiningAddrs))]
+		}
 
 		// Create a new block template using the available transactions
 		// in the memory pool as a source of transactions to potentially
 		// include in the block.
-		template, err := m.g.NewBlockTemplate(payToAddr)
+		template, err := m.g.NewBlockTemplate(blockPayToAddr)
 		m.submitBlockLock.Unlock()
 		if err != nil {
 			errStr := fmt.Sprintf("Failed to create new block "+
@@ -611,21 +655,27 @@ func (m *CPUMiner) GenerateNBlocks(n uint32) ([]*chainhash.Hash, error) {
 		// with false when conditions that trigger a stale block, so
 		// a new block template can be generated.  When the return is
 		// true a solution was found, so submit the solved block.
-		if m.solveBlock(template.Block, curHeight+1, ticker, nil) {
+		if m.solveBlock(template.Block, curHeight+1, ticker, nil, tries) {
 			block := ltcutil.NewBlock(template.Block)
 			m.submitBlock(block)
 			blockHashes[i] = block.Hash()
 			i++
 			if i == n {
 				log.Tracef("Generated %d blocks", i)
-				m.Lock()
-				close(m.speedMonitorQuit)
-				m.wg.Wait()
-				m.started = false
-				m.discreteMining = false
-				m.Unlock()
+				stop()
 				return blockHashes, nil
 			}
+			if tries != nil {
+				*tries = maxTries
+			}
+			continue
+		}
+
+		// Give up once the tries for the block are exhausted.
+		if tries != nil && *tries == 0 {
+			stop()
+			return nil, fmt.Errorf("unable to solve block %d within "+
+				"%d tries", curHeight+1, maxTries)
 		}
 	}
 }
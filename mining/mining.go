@@ -7,7 +7,9 @@ package mining
 import (
 	"bytes"
 	"container/heap"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ltcsuite/ltcd/blockchain"
@@ -31,6 +33,19 @@ const (
 	// and is used to monitor BIP16 support as well as blocks that are
 	// generated via ltcd.
 	CoinbaseFlags = "/P2SH/ltcd/"
+
+	// MaxExtraCoinbaseDataLen is the maximum length of the extra data which
+	// may be appended to the coinbase flags.  It leaves room in the coinbase
+	// script for the push of the block height, which takes at most 5 bytes,
+	// the push of the extra nonce, which takes at most 9 bytes, and the
+	// opcodes of the push of the coinbase flags, which take at most 2
+	// bytes.
+	MaxExtraCoinbaseDataLen = blockchain.MaxCoinbaseScriptLen - 5 - 9 - 2 -
+		len(CoinbaseFlags)
+
+	// MaxVersionBit is the highest bit of the block version which may be
+	// used to signal for a rule change deployment per BIP0009.
+	MaxVersionBit = 28
 )
 
 // TxDesc is a descriptor about a transaction in a transaction source along with
@@ -217,6 +232,14 @@ type BlockTemplate struct {
 	// witness has been activated, and the block contains a transaction
 	// which has witness data.
 	WitnessCommitment []byte
+
+	// CoinbaseFlags are the flags, including any extra coinbase data, that
+	// are added to the coinbase script of the block.
+	CoinbaseFlags []byte
+
+	// SignalBits are the version bits the block was forced to signal
+	// regardless of the state of their deployments.
+	SignalBits uint32
 }
 
 // mergeUtxoView adds all of the entries in view to viewA.  The result is that
@@ -237,11 +260,10 @@ func mergeUtxoView(viewA *blockchain.UtxoViewpoint, viewB *blockchain.UtxoViewpo
 // standardCoinbaseScript returns a standard script suitable for use as the
 // signature script of the coinbase transaction of a new block.  In particular,
 // it starts with the block height that is required by version 2 blocks and adds
-// the extra nonce as well as additional coinbase flags.
-func standardCoinbaseScript(nextBlockHeight int32, extraNonce uint64) ([]byte, error) {
+// the extra nonce as well as the passed coinbase flags.
+func standardCoinbaseScript(nextBlockHeight int32, extraNonce uint64, coinbaseFlags []byte) ([]byte, error) {
 	return txscript.NewScriptBuilder().AddInt64(int64(nextBlockHeight)).
-		AddInt64(int64(extraNonce)).AddData([]byte(CoinbaseFlags)).
-		Script()
+		AddInt64(int64(extraNonce)).AddData(coinbaseFlags).Script()
 }
 
 // createCoinbaseTx returns a coinbase transaction paying an appropriate subsidy
@@ -355,6 +377,18 @@ type BlkTmplGenerator struct {
 	timeSource  blockchain.MedianTimeSource
 	sigCache    *txscript.SigCache
 	hashCache   *txscript.HashCache
+
+	// coinbaseFlags are the coinbase flags followed by any extra coinbase
+	// data.  They are protected by coinbaseFlagsMtx.
+	coinbaseFlagsMtx sync.RWMutex
+	coinbaseFlags    []byte
+
+	// signalBits and noSignalBits are the version bits generated blocks
+	// are forced to signal and not to signal regardless of the state of
+	// their deployments.  They are protected by versionBitsMtx.
+	versionBitsMtx sync.RWMutex
+	signalBits     uint32
+	noSignalBits   uint32
 }
 
 // NewBlkTmplGenerator returns a new block template generator for the given
@@ -370,14 +404,104 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 	hashCache *txscript.HashCache) *BlkTmplGenerator {
 
 	return &BlkTmplGenerator{
-		policy:      policy,
-		chainParams: params,
-		txSource:    txSource,
-		chain:       chain,
-		timeSource:  timeSource,
-		sigCache:    sigCache,
-		hashCache:   hashCache,
+		policy:        policy,
+		chainParams:   params,
+		txSource:      txSource,
+		chain:         chain,
+		timeSource:    timeSource,
+		sigCache:      sigCache,
+		hashCache:     hashCache,
+		coinbaseFlags: []byte(CoinbaseFlags),
+	}
+}
+
+// SetExtraCoinbaseData sets the extra data appended to the coinbase flags in
+// the coinbase script of generated blocks.  An error is returned when the data
+// is longer than MaxExtraCoinbaseDataLen.
+//
+// This function is safe for concurrent access.
+func (g *BlkTmplGenerator) SetExtraCoinbaseData(data []byte) error {
+	if len(data) > MaxExtraCoinbaseDataLen {
+		return fmt.Errorf("extra coinbase data of %d bytes is longer "+
+			"than the maximum of %d bytes", len(data),
+			MaxExtraCoinbaseDataLen)
+	}
+
+	coinbaseFlags := make([]byte, 0, len(CoinbaseFlags)+len(data))
+	coinbaseFlags = append(coinbaseFlags, CoinbaseFlags...)
+	coinbaseFlags = append(coinbaseFlags, data...)
+
+	g.coinbaseFlagsMtx.Lock()
+	g.coinbaseFlags = coinbaseFlags
+	g.coinbaseFlagsMtx.Unlock()
+	return nil
+}
+
+// CoinbaseFlags returns the flags, including any extra coinbase data, that are
+// added to the coinbase script of generated blocks.  The returned slice must be
+// treated as immutable.
+//
+// This function is safe for concurrent access.
+func (g *BlkTmplGenerator) CoinbaseFlags() []byte {
+	g.coinbaseFlagsMtx.RLock()
+	coinbaseFlags := g.coinbaseFlags
+	g.coinbaseFlagsMtx.RUnlock()
+	return coinbaseFlags
+}
+
+// isDeploymentBit returns whether the passed version bit is used by any of the
+// rule change deployments of the network.
+func (g *BlkTmplGenerator) isDeploymentBit(bit uint8) bool {
+	for _, deployment := range g.chainParams.Deployments {
+		if deployment.BitNumber == bit {
+			return true
+		}
+	}
+	return false
+}
+
+// SetVersionBit overrides whether generated blocks signal the passed version
+// bit.  Passing true or false forces the bit to be set or cleared, while
+// passing nil restores the default of signalling for the deployments which
+// are started or locked in per BIP0009.  Forcing a bit which is not used by
+// any known deployment is allowed for testing purposes, but logs a warning.
+// An error is returned when the bit is higher than MaxVersionBit.
+//
+// This function is safe for concurrent access.
+func (g *BlkTmplGenerator) SetVersionBit(bit uint8, signal *bool) error {
+	if bit > MaxVersionBit {
+		return fmt.Errorf("version bit %d is higher than the maximum "+
+			"of %d", bit, MaxVersionBit)
+	}
+	if signal != nil && *signal && !g.isDeploymentBit(bit) {
+		log.Warnf("Signalling version bit %d which is not used by any "+
+			"known deployment", bit)
+	}
+
+	mask := uint32(1) << bit
+	g.versionBitsMtx.Lock()
+	g.signalBits &^= mask
+	g.noSignalBits &^= mask
+	if signal != nil {
+		if *signal {
+			g.signalBits |= mask
+		} else {
+			g.noSignalBits |= mask
+		}
 	}
+	g.versionBitsMtx.Unlock()
+	return nil
+}
+
+// VersionBits returns the version bits generated blocks are forced to signal
+// and not to signal regardless of the state of their deployments.
+//
+// This function is safe for concurrent access.
+func (g *BlkTmplGenerator) VersionBits() (signal, noSignal uint32) {
+	g.versionBitsMtx.RLock()
+	signal, noSignal = g.signalBits, g.noSignalBits
+	g.versionBitsMtx.RUnlock()
+	return signal, noSignal
 }
 
 // NewBlockTemplate returns a new block template that is ready to be solved
@@ -392,57 +516,151 @@ func NewBlkTmplGenerator(policy *Policy, params *chaincfg.Params,
 // The transactions selected and included are prioritized according to several
 // factors.  First, each transaction has a priority calculated based on its
 // value, age of inputs, and size.  Transactions which consist of larger
-// amounts, older inputs, and small sizes have the highest priority.  Second, a
-// fee per kilobyte is calculated for each transaction.  Transactions with a
-// higher fee per kilobyte are preferred.  Finally, the block generation related
-// policy settings are all taken into account.
+// amounts, older inputs, and small sizes have the highest priority.  Second, an
+// ancestor fee per kilobyte is calculated for each transaction from the fees
+// and sizes of its package, which consists of the transaction and its
+// ancestors in the source pool which are not in the block yet.  Packages with
+// a higher fee per kilobyte are preferred, so a child paying a high fee pulls
+// its parents into the block.  Finally, the block generation related policy
+// settings are all taken into account.
 //
-// Transactions which only spend outputs from other transactions already in the
-// block chain are immediately added to a priority queue which either
-// prioritizes based on the priority (then fee per kilobyte) or the fee per
-// kilobyte (then priority) depending on whether or not the BlockPrioritySize
-// policy setting allots space for high-priority transactions.  Transactions
+// When the BlockPrioritySize policy setting allots space for high-priority
+// transactions, the transactions which only spend outputs from other
+// transactions already in the block chain are added to a priority queue which
+// prioritizes based on the priority (then fee per kilobyte).  Transactions
 // which spend outputs from other transactions in the source pool are added to a
 // dependency map so they can be added to the priority queue once the
 // transactions they depend on have been included.
 //
 // Once the high-priority area (if configured) has been filled with
 // transactions, or the priority falls below what is considered high-priority,
-// the priority queue is updated to prioritize by fees per kilobyte (then
-// priority).
+// the rest of the block is filled with whole packages by their ancestor fee
+// per kilobyte.  The fees and sizes of the packages are updated as their
+// ancestors are included.
 //
-// When the fees per kilobyte drop below the TxMinFreeFee policy setting, the
-// transaction will be skipped unless the BlockMinSize policy setting is
-// nonzero, in which case the block will be filled with the low-fee/free
-// transactions until the block size reaches that minimum size.
+// When the ancestor fees per kilobyte drop below the TxMinFreeFee policy
+// setting, the package will be skipped unless the BlockMinWeight or
+// BlockMinSize policy settings are nonzero, in which case the block will be
+// filled with the low-fee/free packages until the block reaches both of those
+// minimums.
 //
-// Any transactions which would cause the block to exceed the BlockMaxSize
-// policy setting, exceed the maximum allowed signature operations per block, or
-// otherwise cause the block to be invalid are skipped.
+// Any transactions which would cause the block to exceed either the
+// BlockMaxWeight or the BlockMaxSize policy setting, whichever is reached
+// first, exceed the maximum allowed signature operations per block, or
+// otherwise cause the block to be invalid are skipped.  The weight counts each
+// byte of witness data once and every other byte four times, while the size
+// counts all of the serialized bytes, witness data included.  So a size limit
+// below a quarter of the weight limit caps blocks of legacy transactions before
+// the weight limit does, while witness data takes up more of the size limit
+// than of the weight limit.  The BlockPrioritySize policy setting is a size as
+// well.
 //
 // Given the above, a block generated by this function is of the following form:
 //
-//   -----------------------------------  --  --
-//  |      Coinbase Transaction         |   |   |
-//  |-----------------------------------|   |   |
-//  |                                   |   |   | ----- policy.BlockPrioritySize
-//  |   High-priority Transactions      |   |   |
-//  |                                   |   |   |
-//  |-----------------------------------|   | --
-//  |                                   |   |
-//  |                                   |   |
-//  |                                   |   |--- policy.BlockMaxSize
-//  |  Transactions prioritized by fee  |   |
-//  |  until <= policy.TxMinFreeFee     |   |
-//  |                                   |   |
-//  |                                   |   |
-//  |                                   |   |
-//  |-----------------------------------|   |
-//  |  Low-fee/Non high-priority (free) |   |
-//  |  transactions (while block size   |   |
-//  |  <= policy.BlockMinSize)          |   |
-//   -----------------------------------  --
+//	 -----------------------------------  --  --
+//	|      Coinbase Transaction         |   |   |
+//	|-----------------------------------|   |   |
+//	|                                   |   |   | ----- policy.BlockPrioritySize
+//	|   High-priority Transactions      |   |   |
+//	|                                   |   |   |
+//	|-----------------------------------|   | --
+//	|                                   |   |
+//	|                                   |   |
+//	|                                   |   |--- policy.BlockMaxSize
+//	|  Transactions prioritized by fee  |   |
+//	|  until <= policy.TxMinFreeFee     |   |
+//	|                                   |   |
+//	|                                   |   |
+//	|                                   |   |
+//	|-----------------------------------|   |
+//	|  Low-fee/Non high-priority (free) |   |
+//	|  transactions (while block size   |   |
+//	|  <= policy.BlockMinSize)          |   |
+//	 -----------------------------------  --
 func (g *BlkTmplGenerator) NewBlockTemplate(payToAddress ltcutil.Address) (*BlockTemplate, error) {
+	return g.newBlockTemplate(payToAddress, nil, nil)
+}
+
+// NewBlockTemplateWithSelection returns a new block template like
+// NewBlockTemplate, except the passed transactions of the transaction source
+// which must be included are added ahead of the others regardless of the
+// priority and fee policy, along with the transactions of the source they
+// depend on.  The excluded transactions, and the transactions which depend on
+// them, are left out of the block.
+//
+// An error is returned when a transaction which must be included is not in the
+// transaction source, is excluded or depends on an excluded transaction, or
+// can't be added without exceeding the block limits or otherwise making the
+// block invalid.
+func (g *BlkTmplGenerator) NewBlockTemplateWithSelection(payToAddress ltcutil.Address, mustInclude, exclude []chainhash.Hash) (*BlockTemplate, error) {
+	excluded := make(map[chainhash.Hash]struct{}, len(exclude))
+	for _, hash := range exclude {
+		excluded[hash] = struct{}{}
+	}
+	return g.newBlockTemplate(payToAddress, mustInclude, excluded)
+}
+
+// orderWithAncestors returns the items of the transactions with the passed
+// hashes, along with the items of the transactions in the source pool they
+// depend on which are not in the block yet, ordered such that each transaction
+// comes after the ones it depends on.  The passed items are those of the source
+// pool transactions which are available for inclusion in the block, except for
+// the passed unavailable ones.
+func orderWithAncestors(items map[chainhash.Hash]*txPrioItem, hashes []chainhash.Hash, unavailable map[chainhash.Hash]struct{}) ([]*txPrioItem, error) {
+	ordered := make([]*txPrioItem, 0, len(hashes))
+	visited := make(map[chainhash.Hash]struct{})
+	var visit func(hash chainhash.Hash) error
+	visit = func(hash chainhash.Hash) error {
+		if _, ok := visited[hash]; ok {
+			return nil
+		}
+		visited[hash] = struct{}{}
+
+		item, ok := items[hash]
+		if _, skip := unavailable[hash]; !ok || skip {
+			return fmt.Errorf("transaction %v is not available for "+
+				"inclusion", hash)
+		}
+		for depHash := range item.dependsOn {
+			if err := visit(depHash); err != nil {
+				return err
+			}
+		}
+		ordered = append(ordered, item)
+		return nil
+	}
+	for _, hash := range hashes {
+		if err := visit(hash); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// witnessCommitmentCost returns the weight and the serialized size the witness
+// commitment adds to the passed coinbase transaction, which is modeled by a
+// copy of it with the commitment.
+func witnessCommitmentCost(coinbaseTx *ltcutil.Tx) (uint32, uint32) {
+	coinbaseCopy := ltcutil.NewTx(coinbaseTx.MsgTx().Copy())
+	coinbaseCopy.MsgTx().TxIn[0].Witness = [][]byte{
+		bytes.Repeat([]byte("a"), blockchain.CoinbaseWitnessDataLen),
+	}
+	coinbaseCopy.MsgTx().AddTxOut(&wire.TxOut{
+		PkScript: bytes.Repeat([]byte("a"),
+			blockchain.CoinbaseWitnessPkScriptLength),
+	})
+	weight := blockchain.GetTransactionWeight(coinbaseCopy) -
+		blockchain.GetTransactionWeight(coinbaseTx)
+	size := coinbaseCopy.MsgTx().SerializeSize() -
+		coinbaseTx.MsgTx().SerializeSize()
+	return uint32(weight), uint32(size)
+}
+
+// newBlockTemplate returns a new block template as described by
+// NewBlockTemplate which includes the transactions with the passed hashes and
+// leaves out the excluded transactions as described by
+// NewBlockTemplateWithSelection.
+func (g *BlkTmplGenerator) newBlockTemplate(payToAddress ltcutil.Address, mustInclude []chainhash.Hash, excluded map[chainhash.Hash]struct{}) (*BlockTemplate, error) {
 	// Extend the most recently known best block.
 	best := g.chain.BestSnapshot()
 	nextBlockHeight := best.Height + 1
@@ -456,7 +674,9 @@ func (g *BlkTmplGenerator) NewBlockTemplate(payToAddress ltcutil.Address) (*Bloc
 	// same value to the same public key address would otherwise be an
 	// identical transaction for block version 1).
 	extraNonce := uint64(0)
-	coinbaseScript, err := standardCoinbaseScript(nextBlockHeight, extraNonce)
+	coinbaseFlags := g.CoinbaseFlags()
+	coinbaseScript, err := standardCoinbaseScript(nextBlockHeight, extraNonce,
+		coinbaseFlags)
 	if err != nil {
 		return nil, err
 	}
@@ -492,6 +712,10 @@ func (g *BlkTmplGenerator) NewBlockTemplate(payToAddress ltcutil.Address) (*Bloc
 	// in the block once each transaction has been included.
 	dependers := make(map[chainhash.Hash]map[chainhash.Hash]*txPrioItem)
 
+	// prioItems tracks the transactions which are available for inclusion
+	// in order to look up those which must be included.
+	prioItems := make(map[chainhash.Hash]*txPrioItem, len(sourceTxns))
+
 	// Create slices to hold the fees and number of signature operations
 	// for each of the selected transactions and add an entry for the
 	// coinbase.  This allows the code below to simply append details about
@@ -521,6 +745,10 @@ mempoolLoop:
 			log.Tracef("Skipping non-finalized tx %s", tx.Hash())
 			continue
 		}
+		if _, ok := excluded[*tx.Hash()]; ok {
+			log.Tracef("Skipping excluded tx %s", tx.Hash())
+			continue
+		}
 
 		// Fetch all of the utxos referenced by the this transaction.
 		// NOTE: This intentionally does not fetch inputs from the
@@ -581,6 +809,7 @@ mempoolLoop:
 		// Calculate the fee in Satoshi/kB.
 		prioItem.feePerKB = txDesc.FeePerKB
 		prioItem.fee = txDesc.Fee
+		prioItems[*tx.Hash()] = prioItem
 
 		// Add the transaction to the priority queue to mark it ready
 		// for inclusion in the block unless it has dependencies.
@@ -599,9 +828,13 @@ mempoolLoop:
 
 	// The starting block size is the size of the block header plus the max
 	// possible transaction count size, plus the size of the coinbase
-	// transaction.
+	// transaction.  The weight is tracked along with the serialized size,
+	// which includes the witness data, since the block must stay within
+	// both the weight and the size limits of the policy.
 	blockWeight := uint32((blockHeaderOverhead * blockchain.WitnessScaleFactor) +
 		blockchain.GetTransactionWeight(coinbaseTx))
+	blockSize := uint32(blockHeaderOverhead +
+		coinbaseTx.MsgTx().SerializeSize())
 	blockSigOpCost := coinbaseSigOpCost
 	totalFees := int64(0)
 
@@ -617,65 +850,54 @@ mempoolLoop:
 
 	witnessIncluded := false
 
-	// Choose which transactions make it into the block.
-	for priorityQueue.Len() > 0 {
-		// Grab the highest priority (or highest fee per kilobyte
-		// depending on the sort order) transaction.
-		prioItem := heap.Pop(priorityQueue).(*txPrioItem)
+	// included and failed track the transactions which have been added to
+	// the block and those which can't be added to it respectively.
+	included := make(map[chainhash.Hash]struct{})
+	failed := make(map[chainhash.Hash]struct{})
+
+	// addTx adds the passed transaction to the block after ensuring it
+	// neither exceeds the block limits nor fails any of the necessary
+	// preconditions, and returns the reason it can't be added otherwise.
+	// The transactions which depend on it are added to the priority queue
+	// when it was their last dependency and the block is still being
+	// filled by priority.
+	addTx := func(prioItem *txPrioItem) error {
 		tx := prioItem.tx
-
-		switch {
-		// If segregated witness has not been activated yet, then we
-		// shouldn't include any witness transactions in the block.
-		case !segwitActive && tx.HasWitness():
-			continue
-
-		// Otherwise, Keep track of if we've included a transaction
-		// with witness data or not. If so, then we'll need to include
-		// the witness commitment as the last output in the coinbase
-		// transaction.
-		case segwitActive && !witnessIncluded && tx.HasWitness():
-			// If we're about to include a transaction bearing
-			// witness data, then we'll also need to include a
-			// witness commitment in the coinbase transaction.
-			// Therefore, we account for the additional weight
-			// within the block with a model coinbase tx with a
-			// witness commitment.
-			coinbaseCopy := ltcutil.NewTx(coinbaseTx.MsgTx().Copy())
-			coinbaseCopy.MsgTx().TxIn[0].Witness = [][]byte{
-				bytes.Repeat([]byte("a"),
-					blockchain.CoinbaseWitnessDataLen),
+		weight, size := blockWeight, blockSize
+		addsCommitment := false
+		if tx.HasWitness() {
+			// If segregated witness has not been activated yet,
+			// then we shouldn't include any witness transactions
+			// in the block.
+			if !segwitActive {
+				return errors.New("it has witness data before " +
+					"segwit is active")
 			}
-			coinbaseCopy.MsgTx().AddTxOut(&wire.TxOut{
-				PkScript: bytes.Repeat([]byte("a"),
-					blockchain.CoinbaseWitnessPkScriptLength),
-			})
 
-			// In order to accurately account for the weight
-			// addition due to this coinbase transaction, we'll add
-			// the difference of the transaction before and after
-			// the addition of the commitment to the block weight.
-			weightDiff := blockchain.GetTransactionWeight(coinbaseCopy) -
-				blockchain.GetTransactionWeight(coinbaseTx)
+			// The first transaction bearing witness data requires
+			// a witness commitment in the coinbase transaction, so
+			// account for its weight and size as well.
+			if !witnessIncluded {
+				commitmentWeight, commitmentSize :=
+					witnessCommitmentCost(coinbaseTx)
+				weight += commitmentWeight
+				size += commitmentSize
+				addsCommitment = true
+			}
+		}
 
-			blockWeight += uint32(weightDiff)
+		// Enforce maximum block weight.  Also check for overflow.
+		txWeight := uint32(blockchain.GetTransactionWeight(tx))
+		if weight+txWeight < weight ||
+			weight+txWeight >= g.policy.BlockMaxWeight {
 
-			witnessIncluded = true
+			return errors.New("it would exceed the max block weight")
 		}
 
-		// Grab any transactions which depend on this one.
-		deps := dependers[*tx.Hash()]
-
 		// Enforce maximum block size.  Also check for overflow.
-		txWeight := uint32(blockchain.GetTransactionWeight(tx))
-		blockPlusTxWeight := blockWeight + txWeight
-		if blockPlusTxWeight < blockWeight ||
-			blockPlusTxWeight >= g.policy.BlockMaxWeight {
-
-			log.Tracef("Skipping tx %s because it would exceed "+
-				"the max block weight", tx.Hash())
-			logSkippedDeps(tx, deps)
-			continue
+		txSize := uint32(tx.MsgTx().SerializeSize())
+		if size+txSize < size || size+txSize >= g.policy.BlockMaxSize {
+			return errors.New("it would exceed the max block size")
 		}
 
 		// Enforce maximum signature operation cost per block.  Also
@@ -683,61 +905,13 @@ mempoolLoop:
 		sigOpCost, err := blockchain.GetSigOpCost(tx, false,
 			blockUtxos, true, segwitActive)
 		if err != nil {
-			log.Tracef("Skipping tx %s due to error in "+
-				"GetSigOpCost: %v", tx.Hash(), err)
-			logSkippedDeps(tx, deps)
-			continue
+			return fmt.Errorf("error in GetSigOpCost: %v", err)
 		}
 		if blockSigOpCost+int64(sigOpCost) < blockSigOpCost ||
 			blockSigOpCost+int64(sigOpCost) > blockchain.MaxBlockSigOpsCost {
-			log.Tracef("Skipping tx %s because it would "+
-				"exceed the maximum sigops per block", tx.Hash())
-			logSkippedDeps(tx, deps)
-			continue
-		}
-
-		// Skip free transactions once the block is larger than the
-		// minimum block size.
-		if sortedByFee &&
-			prioItem.feePerKB < int64(g.policy.TxMinFreeFee) &&
-			blockPlusTxWeight >= g.policy.BlockMinWeight {
-
-			log.Tracef("Skipping tx %s with feePerKB %d "+
-				"< TxMinFreeFee %d and block weight %d >= "+
-				"minBlockWeight %d", tx.Hash(), prioItem.feePerKB,
-				g.policy.TxMinFreeFee, blockPlusTxWeight,
-				g.policy.BlockMinWeight)
-			logSkippedDeps(tx, deps)
-			continue
-		}
-
-		// Prioritize by fee per kilobyte once the block is larger than
-		// the priority size or there are no more high-priority
-		// transactions.
-		if !sortedByFee && (blockPlusTxWeight >= g.policy.BlockPrioritySize ||
-			prioItem.priority <= MinHighPriority) {
-
-			log.Tracef("Switching to sort by fees per "+
-				"kilobyte blockSize %d >= BlockPrioritySize "+
-				"%d || priority %.2f <= minHighPriority %.2f",
-				blockPlusTxWeight, g.policy.BlockPrioritySize,
-				prioItem.priority, MinHighPriority)
 
-			sortedByFee = true
-			priorityQueue.SetLessFunc(txPQByFee)
-
-			// Put the transaction back into the priority queue and
-			// skip it so it is re-priortized by fees if it won't
-			// fit into the high-priority section or the priority
-			// is too low.  Otherwise this transaction will be the
-			// final one in the high-priority section, so just fall
-			// though to the code below so it is added now.
-			if blockPlusTxWeight > g.policy.BlockPrioritySize ||
-				prioItem.priority < MinHighPriority {
-
-				heap.Push(priorityQueue, prioItem)
-				continue
-			}
+			return errors.New("it would exceed the maximum sigops " +
+				"per block")
 		}
 
 		// Ensure the transaction inputs pass all of the necessary
@@ -745,19 +919,15 @@ mempoolLoop:
 		_, err = blockchain.CheckTransactionInputs(tx, nextBlockHeight,
 			blockUtxos, g.chainParams)
 		if err != nil {
-			log.Tracef("Skipping tx %s due to error in "+
-				"CheckTransactionInputs: %v", tx.Hash(), err)
-			logSkippedDeps(tx, deps)
-			continue
+			return fmt.Errorf("error in CheckTransactionInputs: %v",
+				err)
 		}
 		err = blockchain.ValidateTransactionScripts(tx, blockUtxos,
-			txscript.StandardVerifyFlags, g.sigCache,
-			g.hashCache)
+			StandardVerifyFlags(g.chainParams, nextBlockHeight),
+			g.sigCache, g.hashCache, nil)
 		if err != nil {
-			log.Tracef("Skipping tx %s due to error in "+
-				"ValidateTransactionScripts: %v", tx.Hash(), err)
-			logSkippedDeps(tx, deps)
-			continue
+			return fmt.Errorf("error in ValidateTransactionScripts: %v",
+				err)
 		}
 
 		// Spend the transaction inputs in the block utxo view and add
@@ -770,34 +940,186 @@ mempoolLoop:
 		// save the fees and signature operation counts to the block
 		// template.
 		blockTxns = append(blockTxns, tx)
-		blockWeight += txWeight
+		blockWeight = weight + txWeight
+		blockSize = size + txSize
 		blockSigOpCost += int64(sigOpCost)
 		totalFees += prioItem.fee
 		txFees = append(txFees, prioItem.fee)
 		txSigOpCosts = append(txSigOpCosts, int64(sigOpCost))
+		included[*tx.Hash()] = struct{}{}
+		if addsCommitment {
+			witnessIncluded = true
+		}
 
 		log.Tracef("Adding tx %s (priority %.2f, feePerKB %.2f)",
 			prioItem.tx.Hash(), prioItem.priority, prioItem.feePerKB)
 
-		// Add transactions which depend on this one (and also do not
-		// have any other unsatisified dependencies) to the priority
-		// queue.
-		for _, item := range deps {
-			// Add the transaction to the priority queue if there
-			// are no more dependencies after this one.
+		// Remove the dependency of the transactions which depend on
+		// this one and add those which have no other unsatisfied
+		// dependencies to the priority queue.
+		for _, item := range dependers[*tx.Hash()] {
 			delete(item.dependsOn, *tx.Hash())
-			if len(item.dependsOn) == 0 {
+			if len(item.dependsOn) == 0 && !sortedByFee {
 				heap.Push(priorityQueue, item)
 			}
 		}
+		return nil
+	}
+
+	// Add the transactions which must be included ahead of the others
+	// regardless of their priority and fees, after the transactions in the
+	// source pool they depend on.  They must not exceed the block limits.
+	forced, err := orderWithAncestors(prioItems, mustInclude, excluded)
+	if err != nil {
+		return nil, err
+	}
+	for _, prioItem := range forced {
+		if err := addTx(prioItem); err != nil {
+			return nil, fmt.Errorf("transaction %v can't be "+
+				"included: %v", prioItem.tx.Hash(), err)
+		}
+	}
+
+	// Fill the high-priority area of the block when the policy allots
+	// space for one.
+	for !sortedByFee && priorityQueue.Len() > 0 {
+		// Grab the highest priority transaction.
+		prioItem := heap.Pop(priorityQueue).(*txPrioItem)
+		tx := prioItem.tx
+		if _, ok := included[*tx.Hash()]; ok {
+			continue
+		}
+
+		// Switch to filling the block by fee rate once the block is
+		// larger than the priority size or there are no more
+		// high-priority transactions.  This transaction is left for
+		// the fee rate selection unless it is the final one in the
+		// high-priority area.
+		blockPlusTxSize := blockSize + uint32(tx.MsgTx().SerializeSize())
+		if blockPlusTxSize >= g.policy.BlockPrioritySize ||
+			prioItem.priority <= MinHighPriority {
+
+			log.Tracef("Switching to sort by fees per "+
+				"kilobyte blockSize %d >= BlockPrioritySize "+
+				"%d || priority %.2f <= minHighPriority %.2f",
+				blockPlusTxSize, g.policy.BlockPrioritySize,
+				prioItem.priority, MinHighPriority)
+
+			sortedByFee = true
+			if blockPlusTxSize > g.policy.BlockPrioritySize ||
+				prioItem.priority < MinHighPriority {
+
+				break
+			}
+		}
+
+		if err := addTx(prioItem); err != nil {
+			log.Tracef("Skipping tx %s because %v", tx.Hash(), err)
+			logSkippedDeps(tx, dependers[*tx.Hash()])
+			failed[*tx.Hash()] = struct{}{}
+		}
+	}
+
+	// Fill the rest of the block with the packages of the highest ancestor
+	// fee rate, where the package of a transaction consists of it and its
+	// ancestors in the source pool which are not in the block yet.  This
+	// allows a child paying a high fee to pull in parents paying less.
+	// The fee and weight of the packages are kept up to date as their
+	// ancestors are added to the block.
+	packages := make(map[chainhash.Hash]*txPackage, len(prioItems))
+	var packageQueue txPackageQueue
+	for hash, prioItem := range prioItems {
+		if _, ok := included[hash]; ok {
+			continue
+		}
+		pkg, ok := newTxPackage(prioItems, prioItem)
+		if !ok {
+			continue
+		}
+		packages[hash] = pkg
+		heap.Push(&packageQueue, pkg.queueEntry())
+	}
+	for packageQueue.Len() > 0 {
+		// Grab the package with the highest ancestor fee rate and skip
+		// it when it is outdated.
+		entry := heap.Pop(&packageQueue).(txPackageEntry)
+		pkg := entry.pkg
+		hash := *pkg.item.tx.Hash()
+		if _, ok := included[hash]; ok || entry.feePerKB != pkg.feePerKB() {
+			continue
+		}
+
+		// Skip packages which include a transaction that can't be
+		// added to the block.
+		order, err := orderWithAncestors(prioItems,
+			[]chainhash.Hash{hash}, failed)
+		if err != nil {
+			log.Tracef("Skipping tx %s because %v", hash, err)
+			failed[hash] = struct{}{}
+			continue
+		}
+
+		// Skip free packages once the block has reached both the
+		// minimum block weight and the minimum block size.
+		if pkg.feePerKB() < float64(g.policy.TxMinFreeFee) &&
+			blockWeight >= g.policy.BlockMinWeight &&
+			blockSize >= g.policy.BlockMinSize {
+
+			log.Tracef("Skipping tx %s with ancestor feePerKB "+
+				"%.2f < TxMinFreeFee %d, block weight %d >= "+
+				"minBlockWeight %d and block size %d >= "+
+				"minBlockSize %d", hash, pkg.feePerKB(),
+				g.policy.TxMinFreeFee, blockWeight,
+				g.policy.BlockMinWeight, blockSize,
+				g.policy.BlockMinSize)
+			continue
+		}
+
+		// Enforce maximum block weight and size for the whole package.
+		blockPlusPkgWeight := int64(blockWeight) + pkg.weight
+		blockPlusPkgSize := int64(blockSize) + pkg.size
+		if blockPlusPkgWeight >= int64(g.policy.BlockMaxWeight) ||
+			blockPlusPkgSize >= int64(g.policy.BlockMaxSize) {
+
+			log.Tracef("Skipping tx %s because its package would "+
+				"exceed the max block weight or size", hash)
+			continue
+		}
+
+		// Add the package, ancestors first, and deduct the fee and
+		// weight of each added transaction from the packages of its
+		// descendants.
+		for _, prioItem := range order {
+			tx := prioItem.tx
+			if err := addTx(prioItem); err != nil {
+				log.Tracef("Skipping tx %s because %v",
+					tx.Hash(), err)
+				logSkippedDeps(tx, dependers[*tx.Hash()])
+				failed[*tx.Hash()] = struct{}{}
+				break
+			}
+			txWeight := int64(blockchain.GetTransactionWeight(tx))
+			txSize := int64(tx.MsgTx().SerializeSize())
+			for _, descHash := range descendants(dependers, tx) {
+				descPkg, ok := packages[descHash]
+				if !ok {
+					continue
+				}
+				descPkg.fee -= prioItem.fee
+				descPkg.weight -= txWeight
+				descPkg.size -= txSize
+				heap.Push(&packageQueue, descPkg.queueEntry())
+			}
+		}
 	}
 
 	// Now that the actual transactions have been selected, update the
-	// block weight for the real transaction count and coinbase value with
-	// the total fees accordingly.
+	// block weight and size for the real transaction count and coinbase
+	// value with the total fees accordingly.
+	txCountSize := uint32(wire.VarIntSerializeSize(uint64(len(blockTxns))))
 	blockWeight -= wire.MaxVarIntPayload -
-		(uint32(wire.VarIntSerializeSize(uint64(len(blockTxns)))) *
-			blockchain.WitnessScaleFactor)
+		(txCountSize * blockchain.WitnessScaleFactor)
+	blockSize -= wire.MaxVarIntPayload - txCountSize
 	coinbaseTx.MsgTx().TxOut[0].Value += totalFees
 	txFees[0] = -totalFees
 
@@ -806,42 +1128,224 @@ mempoolLoop:
 	// OP_RETURN output within the coinbase transaction.
 	var witnessCommitment []byte
 	if witnessIncluded {
-		// The witness of the coinbase transaction MUST be exactly 32-bytes
-		// of all zeroes.
-		var witnessNonce [blockchain.CoinbaseWitnessDataLen]byte
-		coinbaseTx.MsgTx().TxIn[0].Witness = wire.TxWitness{witnessNonce[:]}
-
-		// Next, obtain the merkle root of a tree which consists of the
-		// wtxid of all transactions in the block. The coinbase
-		// transaction will have a special wtxid of all zeroes.
-		witnessMerkleTree := blockchain.BuildMerkleTreeStore(blockTxns,
-			true)
-		witnessMerkleRoot := witnessMerkleTree[len(witnessMerkleTree)-1]
-
-		// The preimage to the witness commitment is:
-		// witnessRoot || coinbaseWitness
-		var witnessPreimage [64]byte
-		copy(witnessPreimage[:32], witnessMerkleRoot[:])
-		copy(witnessPreimage[32:], witnessNonce[:])
-
-		// The witness commitment itself is the double-sha256 of the
-		// witness preimage generated above. With the commitment
-		// generated, the witness script for the output is: OP_RETURN
-		// OP_DATA_36 {0xaa21a9ed || witnessCommitment}. The leading
-		// prefix is refered to as the "witness magic bytes".
-		witnessCommitment = chainhash.DoubleHashB(witnessPreimage[:])
-		witnessScript := append(blockchain.WitnessMagicBytes, witnessCommitment...)
-
-		// Finally, create the OP_RETURN carrying witness commitment
-		// output as an additional output within the coinbase.
-		commitmentOutput := &wire.TxOut{
-			Value:    0,
-			PkScript: witnessScript,
-		}
-		coinbaseTx.MsgTx().TxOut = append(coinbaseTx.MsgTx().TxOut,
-			commitmentOutput)
+		witnessCommitment = addWitnessCommitment(coinbaseTx, blockTxns)
+	}
+
+	// Create a new block ready to be solved and perform a full check on it
+	// against the chain consensus rules.
+	signalBits, noSignalBits := g.VersionBits()
+	msgBlock, err := g.newBlock(best, blockTxns, signalBits, noSignalBits)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("Created new block template (%d transactions, %d in "+
+		"fees, %d signature operations cost, %d weight, %d bytes, target "+
+		"difficulty %064x)", len(msgBlock.Transactions), totalFees,
+		blockSigOpCost, blockWeight, blockSize,
+		blockchain.CompactToBig(msgBlock.Header.Bits))
+
+	return &BlockTemplate{
+		Block:             msgBlock,
+		Fees:              txFees,
+		SigOpCosts:        txSigOpCosts,
+		Height:            nextBlockHeight,
+		ValidPayAddress:   payToAddress != nil,
+		WitnessCommitment: witnessCommitment,
+		CoinbaseFlags:     coinbaseFlags,
+		SignalBits:        signalBits,
+	}, nil
+}
+
+// NewBlockTemplateWithTxns returns a new block template that is ready to be
+// solved and contains exactly the passed transactions, in order, after a
+// coinbase paying to the passed address.  See NewBlockTemplate for details
+// about the nil address.
+//
+// Unlike NewBlockTemplate, the transaction source and the mining policy are
+// not consulted.  Instead, every passed transaction is required to be valid
+// for inclusion in the block, and an error identifying the first transaction
+// which is not is returned.  Transactions may spend outputs of the
+// transactions before them.
+func (g *BlkTmplGenerator) NewBlockTemplateWithTxns(payToAddress ltcutil.Address, txns []*ltcutil.Tx) (*BlockTemplate, error) {
+	// Extend the most recently known best block.
+	best := g.chain.BestSnapshot()
+	nextBlockHeight := best.Height + 1
+
+	// Create a standard coinbase transaction paying to the provided
+	// address.  The coinbase value is updated to include the fees of the
+	// transactions below once they are known.
+	coinbaseFlags := g.CoinbaseFlags()
+	coinbaseScript, err := standardCoinbaseScript(nextBlockHeight, 0,
+		coinbaseFlags)
+	if err != nil {
+		return nil, err
+	}
+	coinbaseTx, err := createCoinbaseTx(g.chainParams, coinbaseScript,
+		nextBlockHeight, payToAddress)
+	if err != nil {
+		return nil, err
+	}
+	coinbaseSigOpCost := int64(blockchain.CountSigOps(coinbaseTx)) * blockchain.WitnessScaleFactor
+
+	segwitState, err := g.chain.ThresholdState(chaincfg.DeploymentSegwit)
+	if err != nil {
+		return nil, err
+	}
+	segwitActive := segwitState == blockchain.ThresholdActive
+
+	// Fetch the outputs referenced by all of the transactions into a
+	// single view before any of them are spent so that transactions which
+	// spend outputs of earlier transactions in the block, as well as
+	// double spends within the block, are handled by the view.
+	blockUtxos := blockchain.NewUtxoViewpoint()
+	for _, tx := range txns {
+		utxos, err := g.chain.FetchUtxoView(tx)
+		if err != nil {
+			return nil, err
+		}
+		mergeUtxoView(blockUtxos, utxos)
+	}
+
+	blockTxns := make([]*ltcutil.Tx, 0, len(txns)+1)
+	blockTxns = append(blockTxns, coinbaseTx)
+	txFees := make([]int64, 0, len(txns)+1)
+	txSigOpCosts := make([]int64, 0, len(txns)+1)
+	txFees = append(txFees, -1) // Updated once known
+	txSigOpCosts = append(txSigOpCosts, coinbaseSigOpCost)
+	totalFees := int64(0)
+	witnessIncluded := false
+	for i, tx := range txns {
+		if blockchain.IsCoinBase(tx) {
+			return nil, fmt.Errorf("transaction %d (%s) is a "+
+				"coinbase", i, tx.Hash())
+		}
+		if err := blockchain.CheckTransactionSanity(tx); err != nil {
+			return nil, fmt.Errorf("transaction %d (%s) is "+
+				"invalid: %v", i, tx.Hash(), err)
+		}
+		if !blockchain.IsFinalizedTransaction(tx, nextBlockHeight,
+			g.timeSource.AdjustedTime()) {
+
+			return nil, fmt.Errorf("transaction %d (%s) is not "+
+				"finalized", i, tx.Hash())
+		}
+		if tx.HasWitness() {
+			if !segwitActive {
+				return nil, fmt.Errorf("transaction %d (%s) has "+
+					"witness data before segwit is active",
+					i, tx.Hash())
+			}
+			witnessIncluded = true
+		}
+
+		fee, err := blockchain.CheckTransactionInputs(tx,
+			nextBlockHeight, blockUtxos, g.chainParams)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d (%s) has invalid "+
+				"inputs: %v", i, tx.Hash(), err)
+		}
+		err = blockchain.ValidateTransactionScripts(tx, blockUtxos,
+			StandardVerifyFlags(g.chainParams, nextBlockHeight),
+			g.sigCache, g.hashCache, nil)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d (%s) has invalid "+
+				"scripts: %v", i, tx.Hash(), err)
+		}
+		sigOpCost, err := blockchain.GetSigOpCost(tx, false,
+			blockUtxos, true, segwitActive)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d (%s) has invalid "+
+				"signature operations: %v", i, tx.Hash(), err)
+		}
+
+		spendTransaction(blockUtxos, tx, nextBlockHeight)
+		blockTxns = append(blockTxns, tx)
+		totalFees += fee
+		txFees = append(txFees, fee)
+		txSigOpCosts = append(txSigOpCosts, int64(sigOpCost))
+	}
+	coinbaseTx.MsgTx().TxOut[0].Value += totalFees
+	txFees[0] = -totalFees
+
+	var witnessCommitment []byte
+	if witnessIncluded {
+		witnessCommitment = addWitnessCommitment(coinbaseTx, blockTxns)
+	}
+
+	// Create a new block ready to be solved and perform a full check on it
+	// against the chain consensus rules, which also enforces the block
+	// size and signature operation limits.
+	signalBits, noSignalBits := g.VersionBits()
+	msgBlock, err := g.newBlock(best, blockTxns, signalBits, noSignalBits)
+	if err != nil {
+		return nil, err
 	}
 
+	log.Debugf("Created new block template with %d given transactions "+
+		"(%d in fees)", len(txns), totalFees)
+
+	return &BlockTemplate{
+		Block:             msgBlock,
+		Fees:              txFees,
+		SigOpCosts:        txSigOpCosts,
+		Height:            nextBlockHeight,
+		ValidPayAddress:   payToAddress != nil,
+		WitnessCommitment: witnessCommitment,
+		CoinbaseFlags:     coinbaseFlags,
+		SignalBits:        signalBits,
+	}, nil
+}
+
+// addWitnessCommitment adds the witness commitment for the passed block
+// transactions as an OP_RETURN output within the passed coinbase transaction,
+// which must be the first of the block transactions, and sets the coinbase
+// witness accordingly.  The commitment is returned.
+func addWitnessCommitment(coinbaseTx *ltcutil.Tx, blockTxns []*ltcutil.Tx) []byte {
+	// The witness of the coinbase transaction MUST be exactly 32-bytes
+	// of all zeroes.
+	var witnessNonce [blockchain.CoinbaseWitnessDataLen]byte
+	coinbaseTx.MsgTx().TxIn[0].Witness = wire.TxWitness{witnessNonce[:]}
+
+	// Next, obtain the merkle root of a tree which consists of the
+	// wtxid of all transactions in the block. The coinbase
+	// transaction will have a special wtxid of all zeroes.
+	witnessMerkleTree := blockchain.BuildMerkleTreeStore(blockTxns,
+		true)
+	witnessMerkleRoot := witnessMerkleTree[len(witnessMerkleTree)-1]
+
+	// The preimage to the witness commitment is:
+	// witnessRoot || coinbaseWitness
+	var witnessPreimage [64]byte
+	copy(witnessPreimage[:32], witnessMerkleRoot[:])
+	copy(witnessPreimage[32:], witnessNonce[:])
+
+	// The witness commitment itself is the double-sha256 of the
+	// witness preimage generated above. With the commitment
+	// generated, the witness script for the output is: OP_RETURN
+	// OP_DATA_36 {0xaa21a9ed || witnessCommitment}. The leading
+	// prefix is refered to as the "witness magic bytes".
+	witnessCommitment := chainhash.DoubleHashB(witnessPreimage[:])
+	witnessScript := append(blockchain.WitnessMagicBytes, witnessCommitment...)
+
+	// Finally, create the OP_RETURN carrying witness commitment
+	// output as an additional output within the coinbase.
+	commitmentOutput := &wire.TxOut{
+		Value:    0,
+		PkScript: witnessScript,
+	}
+	coinbaseTx.MsgTx().TxOut = append(coinbaseTx.MsgTx().TxOut,
+		commitmentOutput)
+
+	return witnessCommitment
+}
+
+// newBlock returns a new block ready to be solved which extends the passed
+// best chain state and contains the passed transactions.  The passed version
+// bits are forced to be set and cleared in the block version.  The block is
+// checked against the chain consensus rules to ensure it properly connects to
+// the current best chain.
+func (g *BlkTmplGenerator) newBlock(best *blockchain.BestState, blockTxns []*ltcutil.Tx, signalBits, noSignalBits uint32) (*wire.MsgBlock, error) {
 	// Calculate the required difficulty for the block.  The timestamp
 	// is potentially adjusted to ensure it comes after the median time of
 	// the last several blocks per the chain consensus rules.
@@ -852,11 +1356,13 @@ mempoolLoop:
 	}
 
 	// Calculate the next expected block version based on the state of the
-	// rule change deployments.
+	// rule change deployments and then apply the forced version bits.
 	nextBlockVersion, err := g.chain.CalcNextBlockVersion()
 	if err != nil {
 		return nil, err
 	}
+	nextBlockVersion = int32((uint32(nextBlockVersion) | signalBits) &^
+		noSignalBits)
 
 	// Create a new block ready to be solved.
 	merkles := blockchain.BuildMerkleTreeStore(blockTxns, false)
@@ -878,24 +1384,12 @@ mempoolLoop:
 	// consensus rules to ensure it properly connects to the current best
 	// chain with no issues.
 	block := ltcutil.NewBlock(&msgBlock)
-	block.SetHeight(nextBlockHeight)
+	block.SetHeight(best.Height + 1)
 	if err := g.chain.CheckConnectBlock(block); err != nil {
 		return nil, err
 	}
 
-	log.Debugf("Created new block template (%d transactions, %d in "+
-		"fees, %d signature operations cost, %d weight, target difficulty "+
-		"%064x)", len(msgBlock.Transactions), totalFees, blockSigOpCost,
-		blockWeight, blockchain.CompactToBig(msgBlock.Header.Bits))
-
-	return &BlockTemplate{
-		Block:             &msgBlock,
-		Fees:              txFees,
-		SigOpCosts:        txSigOpCosts,
-		Height:            nextBlockHeight,
-		ValidPayAddress:   payToAddress != nil,
-		WitnessCommitment: witnessCommitment,
-	}, nil
+	return &msgBlock, nil
 }
 
 // UpdateBlockTime updates the timestamp in the header of the passed block to
@@ -928,7 +1422,8 @@ func (g *BlkTmplGenerator) UpdateBlockTime(msgBlock *wire.MsgBlock) error {
 // height.  It also recalculates and updates the new merkle root that results
 // from changing the coinbase script.
 func (g *BlkTmplGenerator) UpdateExtraNonce(msgBlock *wire.MsgBlock, blockHeight int32, extraNonce uint64) error {
-	coinbaseScript, err := standardCoinbaseScript(blockHeight, extraNonce)
+	coinbaseScript, err := standardCoinbaseScript(blockHeight, extraNonce,
+		g.CoinbaseFlags())
 	if err != nil {
 		return err
 	}
@@ -5,10 +5,14 @@
 package mining
 
 import (
+	"bytes"
 	"container/heap"
+	"math"
 	"math/rand"
 	"testing"
 
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg"
 	"github.com/ltcsuite/ltcutil"
 )
 
@@ -108,3 +112,91 @@ func TestTxFeePrioHeap(t *testing.T) {
 		highest = prioItem
 	}
 }
+
+// TestExtraCoinbaseData ensures extra coinbase data is appended to the coinbase
+// flags and that the longest allowed data still results in a valid coinbase
+// script for the largest heights and extra nonces.
+func TestExtraCoinbaseData(t *testing.T) {
+	g := NewBlkTmplGenerator(&Policy{}, nil, nil, nil, nil, nil, nil)
+	if flags := g.CoinbaseFlags(); string(flags) != CoinbaseFlags {
+		t.Fatalf("got default coinbase flags %q, want %q", flags,
+			CoinbaseFlags)
+	}
+
+	data := bytes.Repeat([]byte{'a'}, MaxExtraCoinbaseDataLen)
+	if err := g.SetExtraCoinbaseData(data); err != nil {
+		t.Fatalf("SetExtraCoinbaseData: unexpected error: %v", err)
+	}
+	flags := g.CoinbaseFlags()
+	if want := CoinbaseFlags + string(data); string(flags) != want {
+		t.Fatalf("got coinbase flags %q, want %q", flags, want)
+	}
+	for _, extraNonce := range []uint64{0, math.MaxInt64, 1<<63 + 1} {
+		script, err := standardCoinbaseScript(math.MaxInt32, extraNonce,
+			flags)
+		if err != nil {
+			t.Fatalf("standardCoinbaseScript: unexpected error: %v",
+				err)
+		}
+		if len(script) > blockchain.MaxCoinbaseScriptLen {
+			t.Fatalf("coinbase script with extra nonce %d is %d "+
+				"bytes, want at most %d", extraNonce, len(script),
+				blockchain.MaxCoinbaseScriptLen)
+		}
+	}
+
+	// Overlong data must be rejected without changing the flags.
+	if err := g.SetExtraCoinbaseData(append(data, 'a')); err == nil {
+		t.Fatal("SetExtraCoinbaseData: unexpected success for " +
+			"overlong data")
+	}
+	if !bytes.Equal(g.CoinbaseFlags(), flags) {
+		t.Fatalf("got coinbase flags %q after rejected data, want %q",
+			g.CoinbaseFlags(), flags)
+	}
+}
+
+// TestSetVersionBit ensures version bits can be forced to be signalled or not
+// signalled, that the default can be restored and that invalid bits are
+// rejected.
+func TestSetVersionBit(t *testing.T) {
+	g := NewBlkTmplGenerator(&Policy{}, &chaincfg.RegressionNetParams, nil,
+		nil, nil, nil, nil)
+	wantBits := func(wantSignal, wantNoSignal uint32) {
+		signal, noSignal := g.VersionBits()
+		if signal != wantSignal || noSignal != wantNoSignal {
+			t.Fatalf("got version bits %#x/%#x, want %#x/%#x",
+				signal, noSignal, wantSignal, wantNoSignal)
+		}
+	}
+	setBit := func(bit uint8, signal *bool) {
+		if err := g.SetVersionBit(bit, signal); err != nil {
+			t.Fatalf("SetVersionBit(%d): unexpected error: %v", bit,
+				err)
+		}
+	}
+	signal, noSignal := true, false
+
+	wantBits(0, 0)
+	setBit(1, &signal)
+	setBit(28, &noSignal)
+	wantBits(1<<1, 1<<28)
+
+	// Forcing a bit the other way replaces the previous setting, and bits
+	// which are not used by any deployment may be forced.
+	setBit(1, &noSignal)
+	setBit(20, &signal)
+	wantBits(1<<20, 1<<1|1<<28)
+
+	// Passing nil restores the default.
+	setBit(1, nil)
+	setBit(28, nil)
+	wantBits(1<<20, 0)
+
+	// Bits above the maximum must be rejected without changing the bits.
+	if err := g.SetVersionBit(MaxVersionBit+1, &signal); err == nil {
+		t.Fatal("SetVersionBit: unexpected success for bit above " +
+			"the maximum")
+	}
+	wantBits(1<<20, 0)
+}
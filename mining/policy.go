@@ -6,6 +6,8 @@ package mining
 
 import (
 	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/txscript"
 	"github.com/ltcsuite/ltcd/wire"
 	"github.com/ltcsuite/ltcutil"
 )
@@ -131,3 +133,17 @@ func CalcPriority(tx *wire.MsgTx, utxoView *blockchain.UtxoViewpoint, nextBlockH
 	inputValueAge := calcInputValueAge(tx, utxoView, nextBlockHeight)
 	return inputValueAge / float64(serializedTxSize-overhead)
 }
+
+// StandardVerifyFlags returns the script flags which are used to verify the
+// scripts of transactions for the block at the passed height.  These are the
+// standard flags along with the flags of the rule changes which the passed
+// network opts in to, such as OP_CHECKTEMPLATEVERIFY.
+func StandardVerifyFlags(params *chaincfg.Params, nextBlockHeight int32) txscript.ScriptFlags {
+	flags := txscript.StandardVerifyFlags
+	if params.CheckTemplateVerifyHeight != 0 &&
+		nextBlockHeight >= params.CheckTemplateVerifyHeight {
+
+		flags |= txscript.ScriptVerifyCheckTemplateVerify
+	}
+	return flags
+}
@@ -0,0 +1,438 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcutil"
+)
+
+const (
+	// maxMessageSize is the maximum size of a message received from a
+	// miner.
+	maxMessageSize = 16384
+
+	// writeTimeout is the maximum amount of time sending a message to a
+	// miner may take before the connection is closed.
+	writeTimeout = 10 * time.Second
+)
+
+// Error codes used by stratum mining servers.
+const (
+	errCodeOther         = 20
+	errCodeJobNotFound   = 21
+	errCodeDuplicate     = 22
+	errCodeLowDifficulty = 23
+	errCodeUnauthorized  = 24
+	errCodeNotSubscribed = 25
+)
+
+// stratumError is an error sent to a miner in response to a request.  It is
+// encoded as an array of the error code, the message and an unused traceback.
+type stratumError struct {
+	code    int
+	message string
+}
+
+// Error satisfies the error interface and prints human-readable errors.
+func (e *stratumError) Error() string {
+	return fmt.Sprintf("%d: %s", e.code, e.message)
+}
+
+// MarshalJSON encodes the error in the form expected by stratum miners.
+func (e *stratumError) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.code, e.message, nil})
+}
+
+// newError returns a stratum error with the passed code and message.
+func newError(code int, message string) *stratumError {
+	return &stratumError{code: code, message: message}
+}
+
+// request is a request received from a miner.
+type request struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// response is a response to a request sent to a miner.
+type response struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  *stratumError   `json:"error"`
+}
+
+// notification is a notification sent to a miner.
+type notification struct {
+	ID     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// client houses the state of a connection from a miner.
+type client struct {
+	mtx             sync.Mutex
+	writeMtx        sync.Mutex
+	server          *Server
+	conn            net.Conn
+	extraNonce1     [extraNonce1Size]byte
+	subscribed      bool
+	authorized      bool
+	difficulty      float64
+	jobDifficulties map[string]float64
+	shares          int
+	retargetTime    time.Time
+}
+
+// newClient returns a new client for the passed connection which is assigned
+// the provided extra nonce.
+func newClient(s *Server, conn net.Conn, extraNonce1 [extraNonce1Size]byte) *client {
+	return &client{
+		server:          s,
+		conn:            conn,
+		extraNonce1:     extraNonce1,
+		difficulty:      s.cfg.DefaultDifficulty,
+		jobDifficulties: make(map[string]float64),
+		retargetTime:    time.Now(),
+	}
+}
+
+// clampDifficulty returns the passed difficulty limited to the provided
+// bounds.
+func clampDifficulty(difficulty, min, max float64) float64 {
+	if difficulty < min {
+		return min
+	}
+	if difficulty > max {
+		return max
+	}
+	return difficulty
+}
+
+// send encodes the passed message as a line of JSON and sends it to the
+// miner.  The connection is closed when sending fails.
+func (c *client) send(msg interface{}) {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		log.Errorf("Failed to marshal stratum message: %v", err)
+		return
+	}
+	b = append(b, '\n')
+
+	c.writeMtx.Lock()
+	defer c.writeMtx.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := c.conn.Write(b); err != nil {
+		log.Debugf("Failed to send stratum message to %s: %v",
+			c.conn.RemoteAddr(), err)
+		c.conn.Close()
+	}
+}
+
+// sendDifficulty notifies the miner of the passed share difficulty.
+func (c *client) sendDifficulty(difficulty float64) {
+	c.send(&notification{
+		Method: "mining.set_difficulty",
+		Params: []interface{}{difficulty},
+	})
+}
+
+// notifyJob notifies the miner of the passed job when it is subscribed.
+func (c *client) notifyJob(j *job, cleanJobs bool) {
+	c.mtx.Lock()
+	if !c.subscribed {
+		c.mtx.Unlock()
+		return
+	}
+	if cleanJobs || len(c.jobDifficulties) >= maxJobs {
+		c.jobDifficulties = make(map[string]float64)
+	}
+	c.jobDifficulties[j.id] = c.difficulty
+	c.mtx.Unlock()
+
+	c.send(&notification{
+		Method: "mining.notify",
+		Params: j.notifyParams(cleanJobs),
+	})
+}
+
+// shareDifficulty returns the difficulty a share for the job with the passed
+// id must meet.  This is the lower of the difficulty in effect when the job
+// was handed out and the current difficulty, since miners may apply changes
+// of the difficulty to the work in progress.
+func (c *client) shareDifficulty(jobID string) float64 {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	difficulty := c.difficulty
+	if jobDifficulty, ok := c.jobDifficulties[jobID]; ok &&
+		jobDifficulty < difficulty {
+
+		difficulty = jobDifficulty
+	}
+	return difficulty
+}
+
+// retarget adjusts the share difficulty towards the target share interval
+// once enough time has elapsed since the last adjustment.
+func (c *client) retarget(now time.Time) {
+	cfg := &c.server.cfg
+
+	c.mtx.Lock()
+	elapsed := now.Sub(c.retargetTime)
+	if !c.subscribed || elapsed < cfg.RetargetInterval {
+		c.mtx.Unlock()
+		return
+	}
+
+	// Scale the difficulty by the ratio of the number of shares received
+	// to the number expected, limited to a maximum factor in either
+	// direction.
+	expected := elapsed.Seconds() / cfg.ShareInterval.Seconds()
+	factor := float64(c.shares) / expected
+	factor = clampDifficulty(factor, 1.0/maxRetargetFactor,
+		maxRetargetFactor)
+	difficulty := clampDifficulty(c.difficulty*factor, cfg.MinDifficulty,
+		cfg.MaxDifficulty)
+	changed := difficulty != c.difficulty
+	c.difficulty = difficulty
+	c.shares = 0
+	c.retargetTime = now
+	c.mtx.Unlock()
+
+	if changed {
+		log.Debugf("Adjusted share difficulty of %s to %v",
+			c.conn.RemoteAddr(), difficulty)
+		c.sendDifficulty(difficulty)
+	}
+}
+
+// handleSubscribe handles mining.subscribe requests.
+func (c *client) handleSubscribe(id json.RawMessage) {
+	c.mtx.Lock()
+	c.subscribed = true
+	difficulty := c.difficulty
+	c.retargetTime = time.Now()
+	c.mtx.Unlock()
+
+	subscriptionID := hex.EncodeToString(c.extraNonce1[:])
+	c.send(&response{
+		ID: id,
+		Result: []interface{}{
+			[][]string{
+				{"mining.set_difficulty", subscriptionID},
+				{"mining.notify", subscriptionID},
+			},
+			hex.EncodeToString(c.extraNonce1[:]),
+			extraNonce2Size,
+		},
+	})
+	c.sendDifficulty(difficulty)
+	if j := c.server.current(); j != nil {
+		c.notifyJob(j, true)
+	}
+}
+
+// handleAuthorize handles mining.authorize requests.  All credentials are
+// accepted since every block pays to the configured mining addresses.
+func (c *client) handleAuthorize(params []json.RawMessage) (interface{}, *stratumError) {
+	var worker string
+	if len(params) < 1 || json.Unmarshal(params[0], &worker) != nil {
+		return nil, newError(errCodeOther, "Invalid parameters")
+	}
+
+	c.mtx.Lock()
+	c.authorized = true
+	c.mtx.Unlock()
+
+	log.Debugf("Authorized stratum worker %q from %s", worker,
+		c.conn.RemoteAddr())
+	return true, nil
+}
+
+// handleSuggestDifficulty handles mining.suggest_difficulty requests.
+func (c *client) handleSuggestDifficulty(params []json.RawMessage) (interface{}, *stratumError) {
+	var difficulty float64
+	if len(params) < 1 || json.Unmarshal(params[0], &difficulty) != nil ||
+		difficulty <= 0 {
+
+		return nil, newError(errCodeOther, "Invalid parameters")
+	}
+
+	cfg := &c.server.cfg
+	difficulty = clampDifficulty(difficulty, cfg.MinDifficulty,
+		cfg.MaxDifficulty)
+
+	c.mtx.Lock()
+	c.difficulty = difficulty
+	subscribed := c.subscribed
+	c.mtx.Unlock()
+
+	if subscribed {
+		c.sendDifficulty(difficulty)
+	}
+	return true, nil
+}
+
+// parseUint32 parses the passed big-endian hex-encoded 32-bit value.
+func parseUint32(s string) (uint32, bool) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != 4 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(b), true
+}
+
+// handleSubmit handles mining.submit requests.  Shares which meet the network
+// difficulty are submitted as blocks.
+func (c *client) handleSubmit(params []json.RawMessage) (interface{}, *stratumError) {
+	c.mtx.Lock()
+	authorized := c.authorized
+	c.mtx.Unlock()
+	if !authorized {
+		return nil, newError(errCodeUnauthorized, "Unauthorized worker")
+	}
+
+	// The parameters are the worker name, the job id, the extra nonce
+	// iterated by the miner, the timestamp and the nonce.
+	var fields [5]string
+	if len(params) < len(fields) {
+		return nil, newError(errCodeOther, "Invalid parameters")
+	}
+	for i := range fields {
+		if err := json.Unmarshal(params[i], &fields[i]); err != nil {
+			return nil, newError(errCodeOther, "Invalid parameters")
+		}
+	}
+	jobID := fields[1]
+	extraNonce2, err := hex.DecodeString(fields[2])
+	if err != nil || len(extraNonce2) != extraNonce2Size {
+		return nil, newError(errCodeOther, "Invalid extranonce2")
+	}
+	timestamp, ok := parseUint32(fields[3])
+	if !ok {
+		return nil, newError(errCodeOther, "Invalid ntime")
+	}
+	nonce, ok := parseUint32(fields[4])
+	if !ok {
+		return nil, newError(errCodeOther, "Invalid nonce")
+	}
+
+	j := c.server.job(jobID)
+	if j == nil {
+		return nil, newError(errCodeJobNotFound, "Job not found")
+	}
+	jobTime := j.block.Header.Timestamp.Unix()
+	if int64(timestamp) < jobTime ||
+		int64(timestamp) > jobTime+maxTimeOffset {
+
+		return nil, newError(errCodeOther, "ntime out of range")
+	}
+
+	msgBlock, err := j.solvedBlock(c.extraNonce1[:], extraNonce2,
+		timestamp, nonce)
+	if err != nil {
+		return nil, newError(errCodeOther, "Invalid coinbase")
+	}
+	powHash, err := msgBlock.Header.PowHash()
+	if err != nil {
+		log.Errorf("Failed to calculate proof of work hash: %v", err)
+		return nil, newError(errCodeOther, "Internal error")
+	}
+
+	hashNum := blockchain.HashToBig(powHash)
+	powLimit := c.server.cfg.ChainParams.PowLimit
+	shareTarget := difficultyToTarget(c.shareDifficulty(jobID), powLimit)
+	if hashNum.Cmp(shareTarget) > 0 {
+		return nil, newError(errCodeLowDifficulty, "Low difficulty share")
+	}
+	share := hex.EncodeToString(extraNonce2) + fields[3] + fields[4]
+	if !c.server.addShare(j, hex.EncodeToString(c.extraNonce1[:])+share) {
+		return nil, newError(errCodeDuplicate, "Duplicate share")
+	}
+
+	if hashNum.Cmp(blockchain.CompactToBig(msgBlock.Header.Bits)) <= 0 {
+		log.Infof("Stratum share from %s meets the network difficulty",
+			c.conn.RemoteAddr())
+		c.server.submitBlock(ltcutil.NewBlock(msgBlock))
+	}
+
+	c.mtx.Lock()
+	c.shares++
+	c.mtx.Unlock()
+	c.retarget(time.Now())
+	return true, nil
+}
+
+// handleRequest handles the passed request from the miner.
+func (c *client) handleRequest(req *request) {
+	var result interface{}
+	var jsonErr *stratumError
+	switch req.Method {
+	case "mining.subscribe":
+		c.handleSubscribe(req.ID)
+		return
+
+	case "mining.authorize":
+		result, jsonErr = c.handleAuthorize(req.Params)
+
+	case "mining.suggest_difficulty":
+		result, jsonErr = c.handleSuggestDifficulty(req.Params)
+
+	case "mining.submit":
+		c.mtx.Lock()
+		subscribed := c.subscribed
+		c.mtx.Unlock()
+		if !subscribed {
+			jsonErr = newError(errCodeNotSubscribed, "Not subscribed")
+			break
+		}
+		result, jsonErr = c.handleSubmit(req.Params)
+
+	default:
+		jsonErr = newError(errCodeOther, "Unsupported method")
+	}
+
+	c.send(&response{ID: req.ID, Result: result, Error: jsonErr})
+}
+
+// inHandler reads and handles requests from the miner until the connection
+// is closed.  It must be run as a goroutine.
+func (c *client) inHandler() {
+	r := bufio.NewReaderSize(c.conn, maxMessageSize)
+	for {
+		line, err := r.ReadSlice('\n')
+		if err != nil {
+			if err == bufio.ErrBufferFull {
+				log.Debugf("Message from %s exceeds the maximum "+
+					"size", c.conn.RemoteAddr())
+			}
+			break
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			log.Debugf("Malformed message from %s: %v",
+				c.conn.RemoteAddr(), err)
+			break
+		}
+		c.handleRequest(&req)
+	}
+
+	c.conn.Close()
+	c.server.removeClient(c)
+	c.server.wg.Done()
+	log.Debugf("Stratum connection from %s closed", c.conn.RemoteAddr())
+}
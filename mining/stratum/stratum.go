@@ -0,0 +1,626 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/mining"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+const (
+	// extraNonce1Size is the number of bytes of the extra nonce the server
+	// assigns to each connection.
+	extraNonce1Size = 4
+
+	// extraNonce2Size is the number of bytes of the extra nonce miners
+	// iterate through in the coinbase transaction.
+	extraNonce2Size = 4
+
+	// jobUpdateInterval is how often the server checks whether the current
+	// job is stale and needs to be replaced.
+	jobUpdateInterval = time.Second
+
+	// jobTxRefreshInterval is the minimum amount of time in between jobs
+	// built on the same block when the memory pool has been updated.
+	jobTxRefreshInterval = time.Minute
+
+	// maxJobs is the maximum number of jobs built on the current best block
+	// which are retained in order to accept late shares.
+	maxJobs = 16
+
+	// maxTimeOffset is the maximum number of seconds miners are allowed to
+	// roll the timestamp of a job forward.
+	maxTimeOffset = 2 * 60 * 60
+)
+
+const (
+	// DefaultMinDifficulty is the default minimum share difficulty of a
+	// connection.
+	DefaultMinDifficulty = 1.0 / 1024
+
+	// DefaultMaxDifficulty is the default maximum share difficulty of a
+	// connection.
+	DefaultMaxDifficulty = 1 << 32
+
+	// DefaultDifficulty is the default share difficulty connections start
+	// with.
+	DefaultDifficulty = 1
+
+	// DefaultShareInterval is the default target amount of time in between
+	// shares from each connection.
+	DefaultShareInterval = 15 * time.Second
+
+	// DefaultRetargetInterval is the default minimum amount of time in
+	// between share difficulty adjustments of each connection.
+	DefaultRetargetInterval = 90 * time.Second
+
+	// maxRetargetFactor is the maximum factor the share difficulty of a
+	// connection is changed by in a single adjustment.
+	maxRetargetFactor = 4
+)
+
+var (
+	// diff1Target is the target of a share with difficulty 1.  Following
+	// the convention of scrypt mining software, it is 2^16 times easier
+	// than the target of difficulty 1 for sha256d.
+	diff1Target = new(big.Int).Lsh(big.NewInt(0xffff), 224)
+)
+
+// Config is a descriptor containing the stratum server configuration.
+type Config struct {
+	// ChainParams identifies which chain parameters the stratum server is
+	// associated with.
+	ChainParams *chaincfg.Params
+
+	// BlockTemplateGenerator identifies the instance to use in order to
+	// generate the block templates jobs are built from.
+	BlockTemplateGenerator *mining.BlkTmplGenerator
+
+	// MiningAddrs is a list of payment addresses to use for the generated
+	// blocks.  Each job will randomly choose one of them.
+	MiningAddrs []ltcutil.Address
+
+	// ProcessBlock defines the function to call with any solved blocks.
+	// It typically must run the provided block through the same set of
+	// rules and handling as any other block coming from the network.
+	ProcessBlock func(*ltcutil.Block, blockchain.BehaviorFlags) (bool, error)
+
+	// IsCurrent defines the function to use to obtain whether or not the
+	// block chain is current.  No jobs are handed out while the chain is
+	// not current since any solved blocks would be on a side chain and end
+	// up orphaned anyways.
+	IsCurrent func() bool
+
+	// Listeners defines a slice of listeners for which the server will
+	// take ownership of and accept connections from miners.
+	Listeners []net.Listener
+
+	// MinDifficulty and MaxDifficulty bound the share difficulty of each
+	// connection.  DefaultDifficulty is the share difficulty connections
+	// start with.
+	MinDifficulty     float64
+	MaxDifficulty     float64
+	DefaultDifficulty float64
+
+	// ShareInterval is the target amount of time in between shares from
+	// each connection the share difficulty is adjusted towards.
+	ShareInterval time.Duration
+
+	// RetargetInterval is the minimum amount of time in between share
+	// difficulty adjustments of each connection.
+	RetargetInterval time.Duration
+}
+
+// job houses a block template along with the data handed out to miners in
+// order to work on it.
+type job struct {
+	id           string
+	block        *wire.MsgBlock
+	coinbase1    []byte
+	coinbase2    []byte
+	merkleBranch []chainhash.Hash
+	shares       map[string]struct{}
+}
+
+// notifyParams returns the parameters of a mining.notify notification for the
+// job.
+func (j *job) notifyParams(cleanJobs bool) []interface{} {
+	header := &j.block.Header
+	branch := make([]string, 0, len(j.merkleBranch))
+	for i := range j.merkleBranch {
+		branch = append(branch, hex.EncodeToString(j.merkleBranch[i][:]))
+	}
+	return []interface{}{
+		j.id,
+		hex.EncodeToString(swapWords(header.PrevBlock[:])),
+		hex.EncodeToString(j.coinbase1),
+		hex.EncodeToString(j.coinbase2),
+		branch,
+		fmt.Sprintf("%08x", uint32(header.Version)),
+		fmt.Sprintf("%08x", header.Bits),
+		fmt.Sprintf("%08x", uint32(header.Timestamp.Unix())),
+		cleanJobs,
+	}
+}
+
+// solvedBlock returns the block for the job with the coinbase transaction
+// containing the passed extra nonces and the header fields chosen by a miner.
+func (j *job) solvedBlock(extraNonce1, extraNonce2 []byte, timestamp,
+	nonce uint32) (*wire.MsgBlock, error) {
+
+	var serialized bytes.Buffer
+	serialized.Write(j.coinbase1)
+	serialized.Write(extraNonce1)
+	serialized.Write(extraNonce2)
+	serialized.Write(j.coinbase2)
+	var coinbaseTx wire.MsgTx
+	if err := coinbaseTx.DeserializeNoWitness(&serialized); err != nil {
+		return nil, err
+	}
+
+	// The witness commitment of the template does not depend on the
+	// coinbase transaction, so its witness nonce is retained as is.
+	templateCoinbase := j.block.Transactions[0]
+	coinbaseTx.TxIn[0].Witness = templateCoinbase.TxIn[0].Witness
+
+	merkleRoot := coinbaseTx.TxHash()
+	for i := range j.merkleBranch {
+		merkleRoot = *blockchain.HashMerkleBranches(&merkleRoot,
+			&j.merkleBranch[i])
+	}
+
+	msgBlock := &wire.MsgBlock{
+		Header:       j.block.Header,
+		Transactions: make([]*wire.MsgTx, len(j.block.Transactions)),
+	}
+	copy(msgBlock.Transactions, j.block.Transactions)
+	msgBlock.Transactions[0] = &coinbaseTx
+	msgBlock.Header.MerkleRoot = merkleRoot
+	msgBlock.Header.Timestamp = time.Unix(int64(timestamp), 0)
+	msgBlock.Header.Nonce = nonce
+	return msgBlock, nil
+}
+
+// Server provides a Stratum v1 server which hands out jobs built from block
+// templates to external miners, accepts their shares, and submits the shares
+// which meet the network difficulty as blocks.
+type Server struct {
+	sync.Mutex
+	g               *mining.BlkTmplGenerator
+	cfg             Config
+	started         bool
+	jobs            map[string]*job
+	jobIDs          []string
+	currentJob      *job
+	jobCreated      time.Time
+	lastTxUpdate    time.Time
+	nextJobID       uint64
+	nextExtraNonce1 uint32
+	clients         map[*client]struct{}
+	submitBlockLock sync.Mutex
+	wg              sync.WaitGroup
+	quit            chan struct{}
+}
+
+// swapWords returns a copy of the passed bytes with the order of the bytes
+// within each 4-byte word reversed.  This is the form in which stratum
+// encodes the previous block hash.
+func swapWords(b []byte) []byte {
+	swapped := make([]byte, len(b))
+	for i := 0; i+4 <= len(b); i += 4 {
+		binary.BigEndian.PutUint32(swapped[i:],
+			binary.LittleEndian.Uint32(b[i:]))
+	}
+	return swapped
+}
+
+// merkleBranch returns the hashes needed to calculate the merkle root of the
+// passed transactions from the hash of the first one, the coinbase, in order
+// from the bottom of the merkle tree.
+func merkleBranch(txns []*wire.MsgTx) []chainhash.Hash {
+	level := make([]*chainhash.Hash, len(txns))
+	for i, tx := range txns[1:] {
+		hash := tx.TxHash()
+		level[i+1] = &hash
+	}
+
+	var branch []chainhash.Hash
+	for len(level) > 1 {
+		branch = append(branch, *level[1])
+		if len(level)%2 != 0 {
+			level = append(level, level[len(level)-1])
+		}
+		next := make([]*chainhash.Hash, 1, len(level)/2)
+		for i := 2; i < len(level); i += 2 {
+			next = append(next, blockchain.HashMerkleBranches(level[i],
+				level[i+1]))
+		}
+		level = next
+	}
+	return branch
+}
+
+// difficultyToTarget returns the target a share with the passed difficulty
+// must not exceed.  The target is limited to the proof-of-work limit.
+func difficultyToTarget(difficulty float64, powLimit *big.Int) *big.Int {
+	target, _ := new(big.Float).Quo(new(big.Float).SetInt(diff1Target),
+		big.NewFloat(difficulty)).Int(nil)
+	if target.Cmp(powLimit) > 0 {
+		return new(big.Int).Set(powLimit)
+	}
+	return target
+}
+
+// newJob returns a new job built from a block template paying to one of the
+// mining addresses.
+func (s *Server) newJob(id string) (*job, error) {
+	// Choose a payment address at random.
+	rand.Seed(time.Now().UnixNano())
+	payToAddr := s.cfg.MiningAddrs[rand.Intn(len(s.cfg.MiningAddrs))]
+
+	template, err := s.g.NewBlockTemplate(payToAddr)
+	if err != nil {
+		return nil, err
+	}
+	msgBlock := template.Block
+
+	// Replace the coinbase script with one reserving space for the extra
+	// nonces assigned by the server and iterated by the miners.
+	prefix, err := txscript.NewScriptBuilder().
+		AddInt64(int64(template.Height)).Script()
+	if err != nil {
+		return nil, err
+	}
+	suffix, err := txscript.NewScriptBuilder().
+		AddData(template.CoinbaseFlags).Script()
+	if err != nil {
+		return nil, err
+	}
+	extraNonce := make([]byte, extraNonce1Size+extraNonce2Size)
+	script := make([]byte, 0, len(prefix)+1+len(extraNonce)+len(suffix))
+	script = append(script, prefix...)
+	script = append(script, byte(len(extraNonce)))
+	script = append(script, extraNonce...)
+	script = append(script, suffix...)
+	if len(script) > blockchain.MaxCoinbaseScriptLen {
+		return nil, fmt.Errorf("coinbase transaction script length "+
+			"of %d is out of range (min: %d, max: %d)", len(script),
+			blockchain.MinCoinbaseScriptLen,
+			blockchain.MaxCoinbaseScriptLen)
+	}
+	coinbaseTx := msgBlock.Transactions[0]
+	coinbaseTx.TxIn[0].SignatureScript = script
+
+	// Split the serialized coinbase transaction around the extra nonces.
+	var buf bytes.Buffer
+	if err := coinbaseTx.SerializeNoWitness(&buf); err != nil {
+		return nil, err
+	}
+	serialized := buf.Bytes()
+	offset := bytes.Index(serialized, script) + len(prefix) + 1
+
+	return &job{
+		id:           id,
+		block:        msgBlock,
+		coinbase1:    serialized[:offset],
+		coinbase2:    serialized[offset+len(extraNonce):],
+		merkleBranch: merkleBranch(msgBlock.Transactions),
+		shares:       make(map[string]struct{}),
+	}, nil
+}
+
+// updateJob replaces the current job when it is stale, either because the
+// best block has changed or because the memory pool has been updated and
+// enough time has elapsed since the job was created, and notifies all
+// connected miners of the new job.
+func (s *Server) updateJob() {
+	best := s.g.BestSnapshot()
+	lastTxUpdate := s.g.TxSource().LastUpdated()
+
+	s.Lock()
+	cleanJobs := s.currentJob == nil ||
+		!s.currentJob.block.Header.PrevBlock.IsEqual(&best.Hash)
+	txsUpdated := lastTxUpdate != s.lastTxUpdate &&
+		time.Since(s.jobCreated) >= jobTxRefreshInterval
+	id := strconv.FormatUint(s.nextJobID, 16)
+	s.Unlock()
+	if !cleanJobs && !txsUpdated {
+		return
+	}
+
+	// No jobs are handed out while the chain is not current since any
+	// solved blocks would end up orphaned.
+	if best.Height != 0 && !s.cfg.IsCurrent() {
+		return
+	}
+
+	j, err := s.newJob(id)
+	if err != nil {
+		log.Errorf("Failed to create new stratum job: %v", err)
+		return
+	}
+
+	s.Lock()
+	if cleanJobs {
+		s.jobs = make(map[string]*job)
+		s.jobIDs = s.jobIDs[:0]
+	}
+	if len(s.jobIDs) == maxJobs {
+		delete(s.jobs, s.jobIDs[0])
+		s.jobIDs = s.jobIDs[1:]
+	}
+	s.jobs[j.id] = j
+	s.jobIDs = append(s.jobIDs, j.id)
+	s.currentJob = j
+	s.jobCreated = time.Now()
+	s.lastTxUpdate = lastTxUpdate
+	s.nextJobID++
+	clients := make([]*client, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.Unlock()
+
+	log.Debugf("New stratum job %s at height %d", j.id, best.Height+1)
+	for _, c := range clients {
+		c.notifyJob(j, cleanJobs)
+	}
+}
+
+// job returns the job with the passed id, or nil when it does not exist or
+// is stale.
+func (s *Server) job(id string) *job {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.jobs[id]
+}
+
+// addShare records the passed share of the job and returns whether it was
+// not previously submitted.
+func (s *Server) addShare(j *job, share string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	if _, ok := j.shares[share]; ok {
+		return false
+	}
+	j.shares[share] = struct{}{}
+	return true
+}
+
+// current returns the current job, or nil when there is none.
+func (s *Server) current() *job {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.currentJob
+}
+
+// submitBlock submits the passed block to network after ensuring it passes all
+// of the consensus validation rules.
+func (s *Server) submitBlock(block *ltcutil.Block) bool {
+	s.submitBlockLock.Lock()
+	defer s.submitBlockLock.Unlock()
+
+	// Ensure the block is not stale since a new block could have shown up
+	// while the solution was being found.
+	msgBlock := block.MsgBlock()
+	if !msgBlock.Header.PrevBlock.IsEqual(&s.g.BestSnapshot().Hash) {
+		log.Debugf("Block submitted via stratum with previous block "+
+			"%s is stale", msgBlock.Header.PrevBlock)
+		return false
+	}
+
+	// Process this block using the same rules as blocks coming from other
+	// nodes.  This will in turn relay it to the network like normal.
+	isOrphan, err := s.cfg.ProcessBlock(block, blockchain.BFNone)
+	if err != nil {
+		// Anything other than a rule violation is an unexpected error,
+		// so log that error as an internal error.
+		if _, ok := err.(blockchain.RuleError); !ok {
+			log.Errorf("Unexpected error while processing "+
+				"block submitted via stratum: %v", err)
+			return false
+		}
+
+		log.Debugf("Block submitted via stratum rejected: %v", err)
+		return false
+	}
+	if isOrphan {
+		log.Debugf("Block submitted via stratum is an orphan")
+		return false
+	}
+
+	// The block was accepted.
+	coinbaseTx := msgBlock.Transactions[0].TxOut[0]
+	log.Infof("Block submitted via stratum accepted (hash %s, "+
+		"amount %v)", block.Hash(), ltcutil.Amount(coinbaseTx.Value))
+
+	// Hand out a job building on the new block right away.
+	go s.updateJob()
+	return true
+}
+
+// jobHandler periodically replaces stale jobs and adjusts the share
+// difficulty of idle connections.  It must be run as a goroutine.
+func (s *Server) jobHandler() {
+	ticker := time.NewTicker(jobUpdateInterval)
+	defer ticker.Stop()
+
+	s.updateJob()
+out:
+	for {
+		select {
+		case <-ticker.C:
+			s.updateJob()
+
+			s.Lock()
+			clients := make([]*client, 0, len(s.clients))
+			for c := range s.clients {
+				clients = append(clients, c)
+			}
+			s.Unlock()
+			now := time.Now()
+			for _, c := range clients {
+				c.retarget(now)
+			}
+
+		case <-s.quit:
+			break out
+		}
+	}
+
+	s.wg.Done()
+	log.Tracef("Stratum job handler done")
+}
+
+// listenHandler accepts connections from miners on the passed listener.  It
+// must be run as a goroutine.
+func (s *Server) listenHandler(listener net.Listener) {
+	log.Infof("Stratum server listening on %s", listener.Addr())
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			// Only log the error if not forcibly shutting down.
+			select {
+			case <-s.quit:
+			default:
+				log.Errorf("Can't accept connection: %v", err)
+			}
+			break
+		}
+
+		s.Lock()
+		if !s.started {
+			s.Unlock()
+			conn.Close()
+			break
+		}
+		var extraNonce1 [extraNonce1Size]byte
+		binary.BigEndian.PutUint32(extraNonce1[:], s.nextExtraNonce1)
+		s.nextExtraNonce1++
+		c := newClient(s, conn, extraNonce1)
+		s.clients[c] = struct{}{}
+		s.Unlock()
+
+		log.Debugf("New stratum connection from %s", conn.RemoteAddr())
+		s.wg.Add(1)
+		go c.inHandler()
+	}
+	s.wg.Done()
+	log.Tracef("Stratum listener done for %s", listener.Addr())
+}
+
+// removeClient removes the passed client from the connected clients.
+func (s *Server) removeClient(c *client) {
+	s.Lock()
+	delete(s.clients, c)
+	s.Unlock()
+}
+
+// Start begins accepting connections from miners and handing out jobs.
+// Calling this function when the server has already been started will have
+// no effect.
+//
+// This function is safe for concurrent access.
+func (s *Server) Start() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.started {
+		return
+	}
+
+	s.quit = make(chan struct{})
+	s.wg.Add(1 + len(s.cfg.Listeners))
+	go s.jobHandler()
+	for _, listener := range s.cfg.Listeners {
+		go s.listenHandler(listener)
+	}
+
+	s.started = true
+	log.Infof("Stratum server started")
+}
+
+// Stop closes the listeners and all connections from miners.  Calling this
+// function when the server has not been started will have no effect.
+//
+// This function is safe for concurrent access.
+func (s *Server) Stop() {
+	s.Lock()
+	if !s.started {
+		s.Unlock()
+		return
+	}
+	close(s.quit)
+	for _, listener := range s.cfg.Listeners {
+		listener.Close()
+	}
+	for c := range s.clients {
+		c.conn.Close()
+	}
+	s.started = false
+	s.Unlock()
+
+	s.wg.Wait()
+	log.Infof("Stratum server stopped")
+}
+
+// New returns a new instance of a stratum server for the provided
+// configuration.  Use Start to begin accepting connections from miners.
+// Unset difficulty settings are replaced with their defaults.
+func New(cfg *Config) (*Server, error) {
+	if len(cfg.MiningAddrs) == 0 {
+		return nil, errors.New("no mining addresses specified")
+	}
+
+	c := *cfg
+	if c.MinDifficulty == 0 {
+		c.MinDifficulty = DefaultMinDifficulty
+	}
+	if c.MaxDifficulty == 0 {
+		c.MaxDifficulty = DefaultMaxDifficulty
+	}
+	if c.DefaultDifficulty == 0 {
+		c.DefaultDifficulty = DefaultDifficulty
+	}
+	if c.ShareInterval == 0 {
+		c.ShareInterval = DefaultShareInterval
+	}
+	if c.RetargetInterval == 0 {
+		c.RetargetInterval = DefaultRetargetInterval
+	}
+	if c.MinDifficulty > c.MaxDifficulty {
+		return nil, fmt.Errorf("minimum difficulty %v exceeds maximum "+
+			"difficulty %v", c.MinDifficulty, c.MaxDifficulty)
+	}
+	c.DefaultDifficulty = clampDifficulty(c.DefaultDifficulty,
+		c.MinDifficulty, c.MaxDifficulty)
+
+	return &Server{
+		g:       cfg.BlockTemplateGenerator,
+		cfg:     c,
+		jobs:    make(map[string]*job),
+		clients: make(map[*client]struct{}),
+	}, nil
+}
@@ -0,0 +1,426 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package stratum
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	_ "github.com/ltcsuite/ltcd/database/ffldb"
+	"github.com/ltcsuite/ltcd/mining"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// fakeTxSource provides an empty source of transactions for block templates.
+type fakeTxSource struct{}
+
+// LastUpdated returns the last time a transaction was added to or removed
+// from the source pool.  It is part of the mining.TxSource interface.
+func (fakeTxSource) LastUpdated() time.Time { return time.Time{} }
+
+// MiningDescs returns a slice of mining descriptors for all the transactions
+// in the source pool.  It is part of the mining.TxSource interface.
+func (fakeTxSource) MiningDescs() []*mining.TxDesc { return nil }
+
+// HaveTransaction returns whether or not the passed transaction hash exists in
+// the source pool.  It is part of the mining.TxSource interface.
+func (fakeTxSource) HaveTransaction(*chainhash.Hash) bool { return false }
+
+// newRegtestGenerator returns a block template generator for a new chain on
+// the regression test network along with a function to tear it down.
+func newRegtestGenerator(t *testing.T) (*blockchain.BlockChain, *mining.BlkTmplGenerator, func()) {
+	dbPath, err := ioutil.TempDir("", "ltcdstratumtest")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	db, err := database.Create("ffldb", filepath.Join(dbPath, "db"),
+		wire.TestNet)
+	if err != nil {
+		os.RemoveAll(dbPath)
+		t.Fatalf("unable to create db: %v", err)
+	}
+	teardown := func() {
+		db.Close()
+		os.RemoveAll(dbPath)
+	}
+
+	params := &chaincfg.RegressionNetParams
+	timeSource := blockchain.NewMedianTime()
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: params,
+		TimeSource:  timeSource,
+	})
+	if err != nil {
+		teardown()
+		t.Fatalf("unable to create chain: %v", err)
+	}
+
+	policy := mining.Policy{BlockMaxWeight: 4000000, BlockMaxSize: 1000000}
+	g := mining.NewBlkTmplGenerator(&policy, params, fakeTxSource{}, chain,
+		timeSource, txscript.NewSigCache(100), txscript.NewHashCache(100))
+	return chain, g, teardown
+}
+
+// TestMerkleBranch ensures the merkle root calculated from the hash of the
+// coinbase transaction and the merkle branch matches the merkle root of all
+// transactions.
+func TestMerkleBranch(t *testing.T) {
+	for numTxns := 1; numTxns <= 9; numTxns++ {
+		txns := make([]*wire.MsgTx, 0, numTxns)
+		for i := 0; i < numTxns; i++ {
+			tx := wire.NewMsgTx(wire.TxVersion)
+			tx.LockTime = uint32(i)
+			txns = append(txns, tx)
+		}
+		block := ltcutil.NewBlock(&wire.MsgBlock{Transactions: txns})
+		merkles := blockchain.BuildMerkleTreeStore(block.Transactions(),
+			false)
+		want := merkles[len(merkles)-1]
+
+		got := txns[0].TxHash()
+		for _, hash := range merkleBranch(txns) {
+			got = *blockchain.HashMerkleBranches(&got, &hash)
+		}
+		if !got.IsEqual(want) {
+			t.Errorf("merkle root of %d transactions: got %v, want %v",
+				numTxns, got, want)
+		}
+	}
+}
+
+// testMiner is a miner connected to a stratum server in the tests.
+type testMiner struct {
+	t           *testing.T
+	conn        net.Conn
+	r           *bufio.Reader
+	nextID      int
+	extraNonce1 []byte
+	difficulty  float64
+	job         []interface{}
+}
+
+// message is a response or notification received by a test miner.
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params []interface{}   `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  []interface{}   `json:"error"`
+}
+
+// call sends a request to the server and returns the response, handling any
+// notifications received in the meantime.
+func (m *testMiner) call(method string, params ...interface{}) *message {
+	m.nextID++
+	b, err := json.Marshal(map[string]interface{}{
+		"id":     m.nextID,
+		"method": method,
+		"params": params,
+	})
+	if err != nil {
+		m.t.Fatalf("unable to marshal request: %v", err)
+	}
+	if _, err := m.conn.Write(append(b, '\n')); err != nil {
+		m.t.Fatalf("unable to send request: %v", err)
+	}
+	for {
+		msg := m.read()
+		if msg.ID != nil && *msg.ID == m.nextID {
+			return msg
+		}
+	}
+}
+
+// read reads the next message from the server and records the difficulty
+// and job of notifications.
+func (m *testMiner) read() *message {
+	m.conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	line, err := m.r.ReadBytes('\n')
+	if err != nil {
+		m.t.Fatalf("unable to read message: %v", err)
+	}
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		m.t.Fatalf("unable to unmarshal message %s: %v", line, err)
+	}
+	switch msg.Method {
+	case "mining.set_difficulty":
+		m.difficulty = msg.Params[0].(float64)
+	case "mining.notify":
+		m.job = msg.Params
+	}
+	return &msg
+}
+
+// solve searches for a nonce of the current job with the passed extra nonce
+// which makes the header hash satisfy the passed function and returns the
+// parameters to submit it along with the resulting block header.
+func (m *testMiner) solve(extraNonce2 []byte, ok func(*chainhash.Hash) bool) ([]interface{}, *wire.BlockHeader) {
+	for m.job == nil {
+		m.read()
+	}
+	decode := func(i int) []byte {
+		b, err := hex.DecodeString(m.job[i].(string))
+		if err != nil {
+			m.t.Fatalf("invalid job field %d: %v", i, err)
+		}
+		return b
+	}
+
+	var coinbase []byte
+	coinbase = append(coinbase, decode(2)...)
+	coinbase = append(coinbase, m.extraNonce1...)
+	coinbase = append(coinbase, extraNonce2...)
+	coinbase = append(coinbase, decode(3)...)
+	merkleRoot := chainhash.DoubleHashH(coinbase)
+	for _, branch := range m.job[4].([]interface{}) {
+		b, err := hex.DecodeString(branch.(string))
+		if err != nil {
+			m.t.Fatalf("invalid merkle branch: %v", err)
+		}
+		var hash chainhash.Hash
+		copy(hash[:], b)
+		merkleRoot = *blockchain.HashMerkleBranches(&merkleRoot, &hash)
+	}
+
+	var prevBlock chainhash.Hash
+	copy(prevBlock[:], swapWords(decode(1)))
+	timestamp := binary.BigEndian.Uint32(decode(7))
+	header := wire.BlockHeader{
+		Version:    int32(binary.BigEndian.Uint32(decode(5))),
+		PrevBlock:  prevBlock,
+		MerkleRoot: merkleRoot,
+		Timestamp:  time.Unix(int64(timestamp), 0),
+		Bits:       binary.BigEndian.Uint32(decode(6)),
+	}
+	for nonce := uint32(0); ; nonce++ {
+		header.Nonce = nonce
+		hash, err := header.PowHash()
+		if err != nil {
+			m.t.Fatalf("unable to hash header: %v", err)
+		}
+		if ok(hash) {
+			break
+		}
+	}
+
+	params := []interface{}{
+		"worker",
+		m.job[0],
+		hex.EncodeToString(extraNonce2),
+		m.job[7],
+		fmt.Sprintf("%08x", header.Nonce),
+	}
+	return params, &header
+}
+
+// checkError ensures the passed response failed with the provided error code.
+func checkError(t *testing.T, desc string, msg *message, code int) {
+	if len(msg.Error) == 0 || msg.Error[0].(float64) != float64(code) {
+		t.Fatalf("%s: got error %v, want code %d", desc, msg.Error, code)
+	}
+}
+
+// checkResult ensures the passed response succeeded with a true result.
+func checkResult(t *testing.T, desc string, msg *message) {
+	if msg.Error != nil || string(msg.Result) != "true" {
+		t.Fatalf("%s: got result %s, error %v, want true", desc,
+			msg.Result, msg.Error)
+	}
+}
+
+// TestSubmitShare ensures shares submitted by a miner are validated against
+// its share difficulty and that shares meeting the network difficulty are
+// submitted as valid blocks.
+func TestSubmitShare(t *testing.T) {
+	chain, g, teardown := newRegtestGenerator(t)
+	defer teardown()
+
+	params := &chaincfg.RegressionNetParams
+	addr, err := ltcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+
+	blocks := make(chan *ltcutil.Block, 1)
+	s, err := New(&Config{
+		ChainParams:            params,
+		BlockTemplateGenerator: g,
+		MiningAddrs:            []ltcutil.Address{addr},
+		ProcessBlock: func(block *ltcutil.Block, flags blockchain.BehaviorFlags) (bool, error) {
+			blocks <- block
+			return false, nil
+		},
+		IsCurrent:     func() bool { return true },
+		Listeners:     []net.Listener{listener},
+		MinDifficulty: 1.0 / (1 << 20),
+	})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	s.Start()
+	defer s.Stop()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to connect: %v", err)
+	}
+	defer conn.Close()
+	m := &testMiner{t: t, conn: conn, r: bufio.NewReader(conn)}
+
+	// Shares must not be accepted before subscribing and authorizing.
+	extraNonce2 := []byte{0x01, 0x02, 0x03, 0x04}
+	submit := []interface{}{"worker", "0", "01020304", "00000000",
+		"00000000"}
+	checkError(t, "submit before subscribe",
+		m.call("mining.submit", submit...), errCodeNotSubscribed)
+
+	msg := m.call("mining.subscribe")
+	var subscribe []interface{}
+	if err := json.Unmarshal(msg.Result, &subscribe); err != nil ||
+		len(subscribe) != 3 {
+
+		t.Fatalf("mining.subscribe: unexpected result %s", msg.Result)
+	}
+	m.extraNonce1, err = hex.DecodeString(subscribe[1].(string))
+	if err != nil || len(m.extraNonce1) != extraNonce1Size {
+		t.Fatalf("mining.subscribe: invalid extranonce1 %v",
+			subscribe[1])
+	}
+	if subscribe[2].(float64) != extraNonce2Size {
+		t.Fatalf("mining.subscribe: got extranonce2 size %v, want %d",
+			subscribe[2], extraNonce2Size)
+	}
+	checkError(t, "submit before authorize",
+		m.call("mining.submit", submit...), errCodeUnauthorized)
+	checkResult(t, "mining.authorize",
+		m.call("mining.authorize", "worker", "password"))
+
+	// A share not meeting the default difficulty must be rejected.
+	if m.difficulty != DefaultDifficulty {
+		t.Fatalf("got difficulty %v, want %v", m.difficulty,
+			DefaultDifficulty)
+	}
+	shareTarget := difficultyToTarget(m.difficulty, params.PowLimit)
+	submit, _ = m.solve(extraNonce2, func(hash *chainhash.Hash) bool {
+		return blockchain.HashToBig(hash).Cmp(shareTarget) > 0
+	})
+	checkError(t, "low difficulty share",
+		m.call("mining.submit", submit...), errCodeLowDifficulty)
+	select {
+	case <-blocks:
+		t.Fatal("low difficulty share submitted as block")
+	default:
+	}
+
+	// Lower the difficulty so the share target is easier than the network
+	// target and submit a share meeting the network target.
+	checkResult(t, "mining.suggest_difficulty",
+		m.call("mining.suggest_difficulty", 1.0/(1<<16)))
+	netTarget := blockchain.CompactToBig(params.PowLimitBits)
+	submit, header := m.solve(extraNonce2, func(hash *chainhash.Hash) bool {
+		return blockchain.HashToBig(hash).Cmp(netTarget) <= 0
+	})
+	checkResult(t, "block share", m.call("mining.submit", submit...))
+
+	var block *ltcutil.Block
+	select {
+	case block = <-blocks:
+	case <-time.After(10 * time.Second):
+		t.Fatal("block was not submitted")
+	}
+	if block.MsgBlock().Header.BlockHash() != header.BlockHash() {
+		t.Fatalf("submitted block header %v, want %v",
+			block.MsgBlock().Header.BlockHash(), header.BlockHash())
+	}
+
+	// Submitting the same share again must be rejected.
+	checkError(t, "duplicate share", m.call("mining.submit", submit...),
+		errCodeDuplicate)
+
+	// The submitted block must be valid.
+	_, isOrphan, err := chain.ProcessBlock(block, blockchain.BFNone)
+	if err != nil || isOrphan {
+		t.Fatalf("ProcessBlock: got orphan %v, error %v", isOrphan, err)
+	}
+	if height := chain.BestSnapshot().Height; height != 1 {
+		t.Fatalf("got best height %d, want 1", height)
+	}
+
+	// Shares of unknown jobs must be rejected.
+	submit[1] = "unknown"
+	checkError(t, "unknown job", m.call("mining.submit", submit...),
+		errCodeJobNotFound)
+}
+
+// TestRetarget ensures the share difficulty of a connection is adjusted
+// towards the target share interval within the configured bounds.
+func TestRetarget(t *testing.T) {
+	s, err := New(&Config{
+		ChainParams:   &chaincfg.RegressionNetParams,
+		MiningAddrs:   []ltcutil.Address{nil},
+		MinDifficulty: 0.25,
+		MaxDifficulty: 64,
+	})
+	if err != nil {
+		t.Fatalf("New: unexpected error: %v", err)
+	}
+	conn, remote := net.Pipe()
+	defer conn.Close()
+	go io.Copy(ioutil.Discard, remote)
+
+	c := newClient(s, conn, [extraNonce1Size]byte{})
+	c.subscribed = true
+	interval := s.cfg.RetargetInterval
+	expected := int(interval / s.cfg.ShareInterval)
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		shares  int
+		want    float64
+	}{
+		{"too early", interval / 2, 100 * expected, 1},
+		{"on target", interval, expected, 1},
+		{"twice as fast", interval, 2 * expected, 2},
+		{"limited increase", interval, 100 * expected, 8},
+		{"maximum", interval, 100 * expected, 32},
+		{"maximum bound", interval, 100 * expected, 64},
+		{"half as fast", 2 * interval, expected, 32},
+		{"limited decrease", interval, 0, 8},
+		{"limited slow decrease", 10 * interval, 0, 2},
+		{"minimum", interval, 0, 0.5},
+		{"minimum bound", interval, 0, 0.25},
+	}
+	for _, test := range tests {
+		c.shares = test.shares
+		c.retarget(c.retargetTime.Add(test.elapsed))
+		if c.difficulty != test.want {
+			t.Fatalf("%s: got difficulty %v, want %v", test.name,
+				c.difficulty, test.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package mining
+
+import (
+	"bytes"
+
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// txPackage houses a transaction in the source pool along with the total fee,
+// weight and serialized size of its package, which consists of the transaction and its
+// ancestors in the source pool which are not in the block being generated yet.
+// Packages are selected by their ancestor fee rate, which accounts for the fees
+// children pay for their parents.
+type txPackage struct {
+	item   *txPrioItem
+	fee    int64
+	weight int64
+	size   int64
+}
+
+// newTxPackage returns the package of the passed transaction, which must be one
+// of the passed items of the source pool transactions available for inclusion
+// in the block.  False is returned when any of its ancestors is not available.
+func newTxPackage(items map[chainhash.Hash]*txPrioItem, item *txPrioItem) (*txPackage, bool) {
+	pkg := &txPackage{item: item}
+	visited := make(map[chainhash.Hash]struct{})
+	toVisit := []*txPrioItem{item}
+	for len(toVisit) > 0 {
+		next := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+		pkg.fee += next.fee
+		pkg.weight += blockchain.GetTransactionWeight(next.tx)
+		pkg.size += int64(next.tx.MsgTx().SerializeSize())
+
+		for depHash := range next.dependsOn {
+			if _, ok := visited[depHash]; ok {
+				continue
+			}
+			visited[depHash] = struct{}{}
+			dep, ok := items[depHash]
+			if !ok {
+				return nil, false
+			}
+			toVisit = append(toVisit, dep)
+		}
+	}
+	return pkg, true
+}
+
+// feePerKB returns the fee rate of the package in Satoshi per 1000 virtual
+// bytes.
+func (pkg *txPackage) feePerKB() float64 {
+	return float64(pkg.fee) * 1000 * blockchain.WitnessScaleFactor /
+		float64(pkg.weight)
+}
+
+// queueEntry returns an entry for the package with its current fee rate to add
+// to a package queue.
+func (pkg *txPackage) queueEntry() txPackageEntry {
+	return txPackageEntry{pkg: pkg, feePerKB: pkg.feePerKB()}
+}
+
+// txPackageEntry is an entry of a package queue.  Since the fee rate of a
+// package changes as its ancestors are added to the block, the package is
+// queued again each time and the entries whose fee rate no longer matches the
+// package are outdated.
+type txPackageEntry struct {
+	pkg      *txPackage
+	feePerKB float64
+}
+
+// txPackageQueue implements a priority queue of packages ordered by descending
+// fee rate.  Packages with the same fee rate are ordered by transaction hash so
+// the selection is deterministic.  It implements the heap.Interface.
+type txPackageQueue []txPackageEntry
+
+// Len returns the number of entries in the queue.  It is part of the
+// heap.Interface implementation.
+func (pq txPackageQueue) Len() int {
+	return len(pq)
+}
+
+// Less returns whether the entry with index i should sort before the entry with
+// index j.  It is part of the heap.Interface implementation.
+func (pq txPackageQueue) Less(i, j int) bool {
+	if pq[i].feePerKB != pq[j].feePerKB {
+		return pq[i].feePerKB > pq[j].feePerKB
+	}
+	return bytes.Compare(pq[i].pkg.item.tx.Hash()[:],
+		pq[j].pkg.item.tx.Hash()[:]) < 0
+}
+
+// Swap swaps the entries at the passed indices in the queue.  It is part of
+// the heap.Interface implementation.
+func (pq txPackageQueue) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+}
+
+// Push pushes the passed entry onto the queue.  It is part of the
+// heap.Interface implementation.
+func (pq *txPackageQueue) Push(x interface{}) {
+	*pq = append(*pq, x.(txPackageEntry))
+}
+
+// Pop removes the highest fee rate entry from the queue and returns it.  It is
+// part of the heap.Interface implementation.
+func (pq *txPackageQueue) Pop() interface{} {
+	n := len(*pq)
+	entry := (*pq)[n-1]
+	(*pq)[n-1] = txPackageEntry{}
+	*pq = (*pq)[0 : n-1]
+	return entry
+}
+
+// descendants returns the hashes of the transactions in the source pool which
+// depend on the passed transaction, directly or through other transactions,
+// given the transactions which directly depend on each transaction.
+func descendants(dependers map[chainhash.Hash]map[chainhash.Hash]*txPrioItem, tx *ltcutil.Tx) []chainhash.Hash {
+	var hashes []chainhash.Hash
+	visited := make(map[chainhash.Hash]struct{})
+	toVisit := []chainhash.Hash{*tx.Hash()}
+	for len(toVisit) > 0 {
+		hash := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+		for depHash := range dependers[hash] {
+			if _, ok := visited[depHash]; ok {
+				continue
+			}
+			visited[depHash] = struct{}{}
+			hashes = append(hashes, depHash)
+			toVisit = append(toVisit, depHash)
+		}
+	}
+	return hashes
+}
@@ -27,7 +27,7 @@ import (
 
 const (
 	// MaxProtocolVersion is the max protocol version the peer supports.
-	MaxProtocolVersion = wire.FeeFilterVersion
+	MaxProtocolVersion = wire.BIP0152Version
 
 	// minAcceptableProtocolVersion is the lowest protocol version that a
 	// connected peer may support.
@@ -105,6 +105,9 @@ type MessageListeners struct {
 	// OnAddr is invoked when a peer receives an addr bitcoin message.
 	OnAddr func(p *Peer, msg *wire.MsgAddr)
 
+	// OnAddrV2 is invoked when a peer receives an addrv2 bitcoin message.
+	OnAddrV2 func(p *Peer, msg *wire.MsgAddrV2)
+
 	// OnPing is invoked when a peer receives a ping bitcoin message.
 	OnPing func(p *Peer, msg *wire.MsgPing)
 
@@ -130,6 +133,10 @@ type MessageListeners struct {
 	// message.
 	OnCFHeaders func(p *Peer, msg *wire.MsgCFHeaders)
 
+	// OnCFCheckpt is invoked when a peer receives a cfcheckpt bitcoin
+	// message.
+	OnCFCheckpt func(p *Peer, msg *wire.MsgCFCheckpt)
+
 	// OnInv is invoked when a peer receives an inv bitcoin message.
 	OnInv func(p *Peer, msg *wire.MsgInv)
 
@@ -159,6 +166,10 @@ type MessageListeners struct {
 	// bitcoin message.
 	OnGetCFHeaders func(p *Peer, msg *wire.MsgGetCFHeaders)
 
+	// OnGetCFCheckpt is invoked when a peer receives a getcfcheckpt
+	// bitcoin message.
+	OnGetCFCheckpt func(p *Peer, msg *wire.MsgGetCFCheckpt)
+
 	// OnFeeFilter is invoked when a peer receives a feefilter bitcoin message.
 	OnFeeFilter func(p *Peer, msg *wire.MsgFeeFilter)
 
@@ -190,6 +201,26 @@ type MessageListeners struct {
 	// message.
 	OnSendHeaders func(p *Peer, msg *wire.MsgSendHeaders)
 
+	// OnSendAddrV2 is invoked when a peer receives a sendaddrv2 bitcoin
+	// message.
+	OnSendAddrV2 func(p *Peer, msg *wire.MsgSendAddrV2)
+
+	// OnSendCmpct is invoked when a peer receives a sendcmpct bitcoin
+	// message.
+	OnSendCmpct func(p *Peer, msg *wire.MsgSendCmpct)
+
+	// OnCmpctBlock is invoked when a peer receives a cmpctblock bitcoin
+	// message.
+	OnCmpctBlock func(p *Peer, msg *wire.MsgCmpctBlock)
+
+	// OnGetBlockTxn is invoked when a peer receives a getblocktxn bitcoin
+	// message.
+	OnGetBlockTxn func(p *Peer, msg *wire.MsgGetBlockTxn)
+
+	// OnBlockTxn is invoked when a peer receives a blocktxn bitcoin
+	// message.
+	OnBlockTxn func(p *Peer, msg *wire.MsgBlockTxn)
+
 	// OnRead is invoked when a peer receives a bitcoin message.  It
 	// consists of the number of bytes read, the message, and whether or not
 	// an error in the read occurred.  Typically, callers will opt to use
@@ -364,6 +395,7 @@ type StatsSnap struct {
 	LastPingNonce  uint64
 	LastPingTime   time.Time
 	LastPingMicros int64
+	MinPingMicros  int64
 }
 
 // HashFunc is a function which returns a block hash, height and error
@@ -430,6 +462,9 @@ type Peer struct {
 	advertisedProtoVer   uint32 // protocol version advertised by remote
 	protocolVersion      uint32 // negotiated protocol version
 	sendHeadersPreferred bool   // peer sent a sendheaders message
+	addrV2Preferred      bool   // peer sent a sendaddrv2 message
+	cmpctBlockVersion    uint64 // compact block version sent by peer
+	cmpctBlocksPreferred bool   // peer requested high-bandwidth mode
 	verAckReceived       bool
 	witnessEnabled       bool
 
@@ -454,6 +489,7 @@ type Peer struct {
 	lastPingNonce      uint64    // Set to nonce if we have a pending ping.
 	lastPingTime       time.Time // Time we sent last ping.
 	lastPingMicros     int64     // Time for last ping to return.
+	minPingMicros      int64     // Shortest time for a ping to return.
 
 	stallControl  chan stallControlMsg
 	outputQueue   chan outMsg
@@ -537,6 +573,7 @@ func (p *Peer) StatsSnapshot() *StatsSnap {
 		LastBlock:      p.lastBlock,
 		LastPingNonce:  p.lastPingNonce,
 		LastPingMicros: p.lastPingMicros,
+		MinPingMicros:  p.minPingMicros,
 		LastPingTime:   p.lastPingTime,
 	}
 
@@ -648,6 +685,18 @@ func (p *Peer) LastPingMicros() int64 {
 	return lastPingMicros
 }
 
+// MinPingMicros returns the shortest time in microseconds it took the remote
+// peer to respond to a ping.  It is zero when no ping has been answered yet.
+//
+// This function is safe for concurrent access.
+func (p *Peer) MinPingMicros() int64 {
+	p.statsMtx.RLock()
+	minPingMicros := p.minPingMicros
+	p.statsMtx.RUnlock()
+
+	return minPingMicros
+}
+
 // VersionKnown returns the whether or not the version of a peer is known
 // locally.
 //
@@ -781,6 +830,56 @@ func (p *Peer) WantsHeaders() bool {
 	return sendHeadersPreferred
 }
 
+// WantsAddrV2 returns if the peer signalled it wants addrv2 messages instead
+// of addr messages as defined by BIP0155.
+//
+// This function is safe for concurrent access.
+func (p *Peer) WantsAddrV2() bool {
+	p.flagsMtx.Lock()
+	addrV2Preferred := p.addrV2Preferred
+	p.flagsMtx.Unlock()
+
+	return addrV2Preferred
+}
+
+// CmpctBlockVersion returns the compact block version the peer signalled
+// support for via a sendcmpct message.  Zero is returned when the peer has not
+// signalled support for the version preferred for the peer.
+//
+// This function is safe for concurrent access.
+func (p *Peer) CmpctBlockVersion() uint64 {
+	p.flagsMtx.Lock()
+	cmpctBlockVersion := p.cmpctBlockVersion
+	p.flagsMtx.Unlock()
+
+	return cmpctBlockVersion
+}
+
+// WantsCmpctBlocks returns if the peer wants new blocks to be announced with
+// cmpctblock messages instead of inventory vectors or headers (BIP0152
+// high-bandwidth mode).
+//
+// This function is safe for concurrent access.
+func (p *Peer) WantsCmpctBlocks() bool {
+	p.flagsMtx.Lock()
+	cmpctBlocksPreferred := p.cmpctBlocksPreferred
+	p.flagsMtx.Unlock()
+
+	return cmpctBlocksPreferred
+}
+
+// PreferredCmpctBlockVersion returns the compact block version that is used
+// with the peer.  Peers which support segregated witness use the version that
+// identifies transactions by their witness hash.
+//
+// This function is safe for concurrent access.
+func (p *Peer) PreferredCmpctBlockVersion() uint64 {
+	if p.IsWitnessEnabled() {
+		return wire.CmpctBlockWitnessVersion
+	}
+	return wire.CmpctBlockVersion
+}
+
 // IsWitnessEnabled returns true if the peer has signalled that it supports
 // segregated witness.
 //
@@ -888,24 +987,39 @@ func (p *Peer) PushAddrMsg(addresses []*wire.NetAddress) ([]*wire.NetAddress, er
 		return nil, nil
 	}
 
-	msg := wire.NewMsgAddr()
-	msg.AddrList = make([]*wire.NetAddress, addressCount)
-	copy(msg.AddrList, addresses)
+	// Addresses which are not IP addresses can only be sent to peers which
+	// signalled support for addrv2 messages.
+	wantsAddrV2 := p.WantsAddrV2()
+	addrList := make([]*wire.NetAddress, 0, addressCount)
+	for _, na := range addresses {
+		if !wantsAddrV2 && na.NeedsAddrV2() {
+			continue
+		}
+		addrList = append(addrList, na)
+	}
+	addressCount = len(addrList)
+	if addressCount == 0 {
+		return nil, nil
+	}
 
 	// Randomize the addresses sent if there are more than the maximum allowed.
 	if addressCount > wire.MaxAddrPerMsg {
 		// Shuffle the address list.
 		for i := 0; i < wire.MaxAddrPerMsg; i++ {
 			j := i + rand.Intn(addressCount-i)
-			msg.AddrList[i], msg.AddrList[j] = msg.AddrList[j], msg.AddrList[i]
+			addrList[i], addrList[j] = addrList[j], addrList[i]
 		}
 
 		// Truncate it to the maximum size.
-		msg.AddrList = msg.AddrList[:wire.MaxAddrPerMsg]
+		addrList = addrList[:wire.MaxAddrPerMsg]
 	}
 
-	p.QueueMessage(msg, nil)
-	return msg.AddrList, nil
+	if wantsAddrV2 {
+		p.QueueMessage(&wire.MsgAddrV2{AddrList: addrList}, nil)
+	} else {
+		p.QueueMessage(&wire.MsgAddr{AddrList: addrList}, nil)
+	}
+	return addrList, nil
 }
 
 // PushGetBlocksMsg sends a getblocks message for the provided block locator
@@ -1129,6 +1243,11 @@ func (p *Peer) handlePongMsg(msg *wire.MsgPong) {
 			p.lastPingMicros = time.Since(p.lastPingTime).Nanoseconds()
 			p.lastPingMicros /= 1000 // convert to usec.
 			p.lastPingNonce = 0
+			if p.minPingMicros == 0 ||
+				p.lastPingMicros < p.minPingMicros {
+
+				p.minPingMicros = p.lastPingMicros
+			}
 		}
 		p.statsMtx.Unlock()
 	}
@@ -1291,6 +1410,10 @@ func (p *Peer) maybeAddDeadline(pendingResponses map[string]time.Time, msgCmd st
 		// headers.
 		deadline = time.Now().Add(stallResponseTimeout * 3)
 		pendingResponses[wire.CmdHeaders] = deadline
+
+	case wire.CmdGetBlockTxn:
+		// Expects a blocktxn message.
+		pendingResponses[wire.CmdBlockTxn] = deadline
 	}
 }
 
@@ -1528,6 +1651,11 @@ out:
 				p.cfg.Listeners.OnAddr(p, msg)
 			}
 
+		case *wire.MsgAddrV2:
+			if p.cfg.Listeners.OnAddrV2 != nil {
+				p.cfg.Listeners.OnAddrV2(p, msg)
+			}
+
 		case *wire.MsgPing:
 			p.handlePingMsg(msg)
 			if p.cfg.Listeners.OnPing != nil {
@@ -1600,6 +1728,11 @@ out:
 				p.cfg.Listeners.OnGetCFHeaders(p, msg)
 			}
 
+		case *wire.MsgGetCFCheckpt:
+			if p.cfg.Listeners.OnGetCFCheckpt != nil {
+				p.cfg.Listeners.OnGetCFCheckpt(p, msg)
+			}
+
 		case *wire.MsgCFilter:
 			if p.cfg.Listeners.OnCFilter != nil {
 				p.cfg.Listeners.OnCFilter(p, msg)
@@ -1610,6 +1743,11 @@ out:
 				p.cfg.Listeners.OnCFHeaders(p, msg)
 			}
 
+		case *wire.MsgCFCheckpt:
+			if p.cfg.Listeners.OnCFCheckpt != nil {
+				p.cfg.Listeners.OnCFCheckpt(p, msg)
+			}
+
 		case *wire.MsgFeeFilter:
 			if p.cfg.Listeners.OnFeeFilter != nil {
 				p.cfg.Listeners.OnFeeFilter(p, msg)
@@ -1649,6 +1787,50 @@ out:
 				p.cfg.Listeners.OnSendHeaders(p, msg)
 			}
 
+		case *wire.MsgSendAddrV2:
+			// BIP0155 only allows signalling support for addrv2
+			// messages before the verack message, so ignore it
+			// afterwards.
+			if !p.VerAckReceived() {
+				p.flagsMtx.Lock()
+				p.addrV2Preferred = true
+				p.flagsMtx.Unlock()
+			}
+
+			if p.cfg.Listeners.OnSendAddrV2 != nil {
+				p.cfg.Listeners.OnSendAddrV2(p, msg)
+			}
+
+		case *wire.MsgSendCmpct:
+			// Peers may signal support for several compact block
+			// versions, so only record the one preferred for this
+			// peer and ignore the others.
+			if msg.Version == p.PreferredCmpctBlockVersion() {
+				p.flagsMtx.Lock()
+				p.cmpctBlockVersion = msg.Version
+				p.cmpctBlocksPreferred = msg.AnnounceBlocks
+				p.flagsMtx.Unlock()
+			}
+
+			if p.cfg.Listeners.OnSendCmpct != nil {
+				p.cfg.Listeners.OnSendCmpct(p, msg)
+			}
+
+		case *wire.MsgCmpctBlock:
+			if p.cfg.Listeners.OnCmpctBlock != nil {
+				p.cfg.Listeners.OnCmpctBlock(p, msg)
+			}
+
+		case *wire.MsgGetBlockTxn:
+			if p.cfg.Listeners.OnGetBlockTxn != nil {
+				p.cfg.Listeners.OnGetBlockTxn(p, msg)
+			}
+
+		case *wire.MsgBlockTxn:
+			if p.cfg.Listeners.OnBlockTxn != nil {
+				p.cfg.Listeners.OnBlockTxn(p, msg)
+			}
+
 		default:
 			log.Debugf("Received unhandled message of type %v "+
 				"from %v", rmsg.Command(), p)
@@ -2064,6 +2246,15 @@ func (p *Peer) start() error {
 	go p.outHandler()
 	go p.pingHandler()
 
+	// Signal support for addrv2 messages to peers which know about them,
+	// which must happen before the verack message per BIP0155.
+	p.flagsMtx.Lock()
+	advertisedProtoVer := p.advertisedProtoVer
+	p.flagsMtx.Unlock()
+	if advertisedProtoVer >= wire.AddrV2Version {
+		p.QueueMessage(wire.NewMsgSendAddrV2(), nil)
+	}
+
 	// Send our verack message now that the IO processing machinery has started.
 	p.QueueMessage(wire.NewMsgVerAck(), nil)
 	return nil
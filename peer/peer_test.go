@@ -363,6 +363,9 @@ func TestPeerListeners(t *testing.T) {
 			OnAddr: func(p *peer.Peer, msg *wire.MsgAddr) {
 				ok <- msg
 			},
+			OnAddrV2: func(p *peer.Peer, msg *wire.MsgAddrV2) {
+				ok <- msg
+			},
 			OnPing: func(p *peer.Peer, msg *wire.MsgPing) {
 				ok <- msg
 			},
@@ -411,6 +414,12 @@ func TestPeerListeners(t *testing.T) {
 			OnCFHeaders: func(p *peer.Peer, msg *wire.MsgCFHeaders) {
 				ok <- msg
 			},
+			OnGetCFCheckpt: func(p *peer.Peer, msg *wire.MsgGetCFCheckpt) {
+				ok <- msg
+			},
+			OnCFCheckpt: func(p *peer.Peer, msg *wire.MsgCFCheckpt) {
+				ok <- msg
+			},
 			OnFeeFilter: func(p *peer.Peer, msg *wire.MsgFeeFilter) {
 				ok <- msg
 			},
@@ -438,6 +447,21 @@ func TestPeerListeners(t *testing.T) {
 			OnSendHeaders: func(p *peer.Peer, msg *wire.MsgSendHeaders) {
 				ok <- msg
 			},
+			OnSendAddrV2: func(p *peer.Peer, msg *wire.MsgSendAddrV2) {
+				ok <- msg
+			},
+			OnSendCmpct: func(p *peer.Peer, msg *wire.MsgSendCmpct) {
+				ok <- msg
+			},
+			OnCmpctBlock: func(p *peer.Peer, msg *wire.MsgCmpctBlock) {
+				ok <- msg
+			},
+			OnGetBlockTxn: func(p *peer.Peer, msg *wire.MsgGetBlockTxn) {
+				ok <- msg
+			},
+			OnBlockTxn: func(p *peer.Peer, msg *wire.MsgBlockTxn) {
+				ok <- msg
+			},
 		},
 		UserAgentName:     "peer",
 		UserAgentVersion:  "1.0",
@@ -485,6 +509,10 @@ func TestPeerListeners(t *testing.T) {
 			"OnAddr",
 			wire.NewMsgAddr(),
 		},
+		{
+			"OnAddrV2",
+			wire.NewMsgAddrV2(),
+		},
 		{
 			"OnPing",
 			wire.NewMsgPing(42),
@@ -551,6 +579,14 @@ func TestPeerListeners(t *testing.T) {
 			"OnCFHeaders",
 			wire.NewMsgCFHeaders(),
 		},
+		{
+			"OnGetCFCheckpt",
+			wire.NewMsgGetCFCheckpt(&chainhash.Hash{}, false),
+		},
+		{
+			"OnCFCheckpt",
+			wire.NewMsgCFCheckpt(&chainhash.Hash{}, false, 0),
+		},
 		{
 			"OnFeeFilter",
 			wire.NewMsgFeeFilter(15000),
@@ -582,6 +618,27 @@ func TestPeerListeners(t *testing.T) {
 			"OnSendHeaders",
 			wire.NewMsgSendHeaders(),
 		},
+		{
+			"OnSendAddrV2",
+			wire.NewMsgSendAddrV2(),
+		},
+		{
+			"OnSendCmpct",
+			wire.NewMsgSendCmpct(true, wire.CmpctBlockVersion),
+		},
+		{
+			"OnCmpctBlock",
+			wire.NewMsgCmpctBlock(wire.NewBlockHeader(1,
+				&chainhash.Hash{}, &chainhash.Hash{}, 1, 1), 1),
+		},
+		{
+			"OnGetBlockTxn",
+			wire.NewMsgGetBlockTxn(&chainhash.Hash{}),
+		},
+		{
+			"OnBlockTxn",
+			wire.NewMsgBlockTxn(&chainhash.Hash{}),
+		},
 	}
 	t.Logf("Running %d tests", len(tests))
 	for _, test := range tests {
@@ -594,10 +651,105 @@ func TestPeerListeners(t *testing.T) {
 			return
 		}
 	}
+
+	// Ensure the sendcmpct message sent above was recorded.
+	if !inPeer.WantsCmpctBlocks() {
+		t.Errorf("WantsCmpctBlocks: peer does not want compact blocks")
+	}
+	if v := inPeer.CmpctBlockVersion(); v != wire.CmpctBlockVersion {
+		t.Errorf("CmpctBlockVersion: wrong version - got %v, want %v",
+			v, wire.CmpctBlockVersion)
+	}
+
+	// Ensure the sendaddrv2 message sent above was ignored since it was
+	// sent after the verack message.
+	if inPeer.WantsAddrV2() {
+		t.Errorf("WantsAddrV2: peer wants addrv2 after late sendaddrv2")
+	}
+
 	inPeer.Disconnect()
 	outPeer.Disconnect()
 }
 
+// TestAddrV2Negotiation ensures sendaddrv2 is only sent to peers which
+// advertise a protocol version of at least AddrV2Version and that addresses
+// are pushed as addrv2 messages to peers which signalled support for them,
+// while addresses which need addrv2 are never sent to other peers.
+func TestAddrV2Negotiation(t *testing.T) {
+	verack := make(chan struct{}, 2)
+	addrs := make(chan *wire.MsgAddrV2, 1)
+	peerCfg := &peer.Config{
+		Listeners: peer.MessageListeners{
+			OnVerAck: func(p *peer.Peer, msg *wire.MsgVerAck) {
+				verack <- struct{}{}
+			},
+			OnAddrV2: func(p *peer.Peer, msg *wire.MsgAddrV2) {
+				addrs <- msg
+			},
+		},
+		UserAgentName:    "peer",
+		UserAgentVersion: "1.0",
+		ChainParams:      &chaincfg.MainNetParams,
+	}
+	inConn, outConn := pipe(
+		&conn{raddr: "10.0.0.1:9333"},
+		&conn{raddr: "10.0.0.2:9333"},
+	)
+	inPeer := peer.NewInboundPeer(peerCfg)
+	inPeer.AssociateConnection(inConn)
+
+	// Only the outbound peer advertises support for addrv2, so only the
+	// inbound peer sends it sendaddrv2 and wants addrv2 messages.
+	peerCfg.ProtocolVersion = wire.AddrV2Version
+	outPeer, err := peer.NewOutboundPeer(peerCfg, "10.0.0.1:9333")
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: unexpected err %v", err)
+	}
+	outPeer.AssociateConnection(outConn)
+	defer inPeer.Disconnect()
+	defer outPeer.Disconnect()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-verack:
+		case <-time.After(time.Second):
+			t.Fatal("verack timeout")
+		}
+	}
+	if !outPeer.WantsAddrV2() {
+		t.Fatal("WantsAddrV2: outbound peer did not receive sendaddrv2")
+	}
+	if inPeer.WantsAddrV2() {
+		t.Fatal("WantsAddrV2: inbound peer received sendaddrv2")
+	}
+
+	torV3 := wire.NewNetAddressNetID(wire.NetIDTorV3,
+		make([]byte, 32), 9333, wire.SFNodeNetwork)
+	ipv4 := wire.NewNetAddressIPPort(net.ParseIP("1.2.3.4"), 9333,
+		wire.SFNodeNetwork)
+
+	// The Tor v3 address can't be sent to the peer without addrv2 support.
+	sent, err := inPeer.PushAddrMsg([]*wire.NetAddress{torV3})
+	if err != nil || len(sent) != 0 {
+		t.Fatalf("PushAddrMsg: sent %v (err %v), want none", sent, err)
+	}
+
+	sent, err = outPeer.PushAddrMsg([]*wire.NetAddress{torV3, ipv4})
+	if err != nil || len(sent) != 2 {
+		t.Fatalf("PushAddrMsg: sent %v (err %v), want both", sent, err)
+	}
+	select {
+	case msg := <-addrs:
+		if len(msg.AddrList) != 2 ||
+			msg.AddrList[0].NetID != wire.NetIDTorV3 {
+
+			t.Fatalf("OnAddrV2: unexpected addresses %v", msg.AddrList)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnAddrV2 timeout")
+	}
+}
+
 // TestOutboundPeer tests that the outbound peer works as expected.
 func TestOutboundPeer(t *testing.T) {
 
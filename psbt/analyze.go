@@ -0,0 +1,303 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// Role identifies one of the roles defined by BIP0174 which process a packet.
+// The roles are ordered by when they are involved in the life of a packet.
+type Role int
+
+const (
+	// RoleCreator creates a new packet.
+	RoleCreator Role = iota
+
+	// RoleUpdater adds the outputs spent by the inputs along with the
+	// scripts and public keys needed to sign them.
+	RoleUpdater
+
+	// RoleSigner adds signatures for the inputs.
+	RoleSigner
+
+	// RoleFinalizer builds the final signature scripts and witnesses of
+	// the inputs.
+	RoleFinalizer
+
+	// RoleExtractor extracts the signed transaction.
+	RoleExtractor
+)
+
+// roleStrings is a map of roles back to their names for pretty printing.
+var roleStrings = map[Role]string{
+	RoleCreator:   "creator",
+	RoleUpdater:   "updater",
+	RoleSigner:    "signer",
+	RoleFinalizer: "finalizer",
+	RoleExtractor: "extractor",
+}
+
+// String returns the Role in human-readable form.
+func (r Role) String() string {
+	if s, ok := roleStrings[r]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown Role (%d)", int(r))
+}
+
+// InputAnalysis houses the state of an input of a packet along with the data
+// which is still missing to complete it.  The missing public keys and
+// signatures are identified by the hash160 of the public key, the missing
+// redeem script by its hash160 and the missing witness script by its sha256.
+type InputAnalysis struct {
+	HasUtxo              bool
+	IsFinal              bool
+	MissingPubKeys       [][]byte
+	MissingSigs          [][]byte
+	MissingRedeemScript  []byte
+	MissingWitnessScript []byte
+	Next                 Role
+}
+
+// Analysis houses the state of a packet.  The fee is only known when the
+// outputs spent by all of the inputs are known.
+type Analysis struct {
+	Inputs []*InputAnalysis
+	Fee    ltcutil.Amount
+	HasFee bool
+	Next   Role
+}
+
+// Utxo returns the output spent by the input at the passed index of the
+// packet, or nil when the packet does not contain it.  An error is returned
+// when the full transaction of a spent output in the packet is not the
+// transaction referenced by the input.
+func (p *Packet) Utxo(index int) (*wire.TxOut, error) {
+	in := p.Inputs[index]
+	if in.WitnessUtxo != nil {
+		return in.WitnessUtxo, nil
+	}
+	if in.NonWitnessUtxo == nil {
+		return nil, nil
+	}
+
+	prevOut := &p.UnsignedTx.TxIn[index].PreviousOutPoint
+	if in.NonWitnessUtxo.TxHash() != prevOut.Hash ||
+		prevOut.Index >= uint32(len(in.NonWitnessUtxo.TxOut)) {
+
+		return nil, fmt.Errorf("input %d specifies invalid prevout",
+			index)
+	}
+	return in.NonWitnessUtxo.TxOut[prevOut.Index], nil
+}
+
+// hasSig returns whether the input has a signature for the passed public key.
+func (in *Input) hasSig(pubKey []byte) bool {
+	for _, sig := range in.PartialSigs {
+		if bytes.Equal(sig.PubKey, pubKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSigs returns whether the input has at least the required number of
+// signatures for the passed public keys.  Otherwise, the public keys without
+// a signature are added to the missing signatures of the analysis.
+func (in *Input) checkSigs(pubKeys [][]byte, required int, a *InputAnalysis) bool {
+	var missing [][]byte
+	for _, pubKey := range pubKeys {
+		if !in.hasSig(pubKey) {
+			missing = append(missing, ltcutil.Hash160(pubKey))
+		}
+	}
+	if len(pubKeys)-len(missing) >= required {
+		return true
+	}
+	a.MissingSigs = append(a.MissingSigs, missing...)
+	return false
+}
+
+// checkKeyHashSig returns whether the input has a signature for the public key
+// with the passed hash160.  Otherwise, the key hash is added to the missing
+// signatures of the analysis when the public key is known from a BIP0032
+// derivation, or to the missing public keys when it is not.
+func (in *Input) checkKeyHashSig(keyHash []byte, a *InputAnalysis) bool {
+	for _, sig := range in.PartialSigs {
+		if bytes.Equal(ltcutil.Hash160(sig.PubKey), keyHash) {
+			return true
+		}
+	}
+	for _, d := range in.Bip32Derivation {
+		if bytes.Equal(ltcutil.Hash160(d.PubKey), keyHash) {
+			a.MissingSigs = append(a.MissingSigs, keyHash)
+			return false
+		}
+	}
+	a.MissingPubKeys = append(a.MissingPubKeys, keyHash)
+	return false
+}
+
+// checkComplete returns whether the input has all of the scripts and
+// signatures needed to finalize it when spending an output with the passed
+// public key script.  The data which is missing is added to the analysis.
+// Scripts which are not understood can never be completed.
+func (in *Input) checkComplete(pkScript []byte, a *InputAnalysis) bool {
+	script := pkScript
+	if txscript.IsPayToScriptHash(script) {
+		scriptHash := script[2:22]
+		if !bytes.Equal(ltcutil.Hash160(in.RedeemScript), scriptHash) {
+			a.MissingRedeemScript = scriptHash
+			return false
+		}
+		script = in.RedeemScript
+	}
+	if txscript.IsPayToWitnessScriptHash(script) {
+		scriptHash := script[2:34]
+		witnessScriptHash := sha256.Sum256(in.WitnessScript)
+		if !bytes.Equal(witnessScriptHash[:], scriptHash) {
+			a.MissingWitnessScript = scriptHash
+			return false
+		}
+		script = in.WitnessScript
+	}
+
+	switch txscript.GetScriptClass(script) {
+	case txscript.PubKeyTy:
+		pushes, err := txscript.PushedData(script)
+		if err != nil {
+			return false
+		}
+		return in.checkSigs(pushes[:1], 1, a)
+
+	case txscript.PubKeyHashTy:
+		return in.checkKeyHashSig(script[3:23], a)
+
+	case txscript.WitnessV0PubKeyHashTy:
+		return in.checkKeyHashSig(script[2:22], a)
+
+	case txscript.MultiSigTy:
+		_, numSigs, err := txscript.CalcMultiSigStats(script)
+		if err != nil {
+			return false
+		}
+		pubKeys, err := txscript.PushedData(script)
+		if err != nil {
+			return false
+		}
+		return in.checkSigs(pubKeys, numSigs, a)
+	}
+
+	return false
+}
+
+// Analyze returns the state of each input of the packet along with the next
+// role which needs to process the packet.  Since no keys are available, the
+// inputs are only checked for the presence of the scripts and signatures
+// needed to finalize them and the signatures themselves are not verified.
+//
+// An error is returned when the packet is not valid, such as when the spent
+// outputs it contains do not match the inputs or when the outputs spend more
+// than the inputs.
+func (p *Packet) Analyze() (*Analysis, error) {
+	analysis := &Analysis{
+		Inputs: make([]*InputAnalysis, len(p.Inputs)),
+		Next:   RoleExtractor,
+	}
+
+	hasAllUtxos := true
+	var totalIn int64
+	for i, in := range p.Inputs {
+		a := &InputAnalysis{}
+		analysis.Inputs[i] = a
+
+		utxo, err := p.Utxo(i)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case utxo == nil:
+			hasAllUtxos = false
+			a.Next = RoleUpdater
+
+		case in.IsFinal():
+			a.HasUtxo = true
+			a.IsFinal = true
+			a.Next = RoleExtractor
+
+		default:
+			a.HasUtxo = true
+			switch {
+			case in.checkComplete(utxo.PkScript, a):
+				a.Next = RoleFinalizer
+
+			// The signer is only next when signatures are the only
+			// missing data.
+			case len(a.MissingPubKeys) == 0 &&
+				a.MissingRedeemScript == nil &&
+				a.MissingWitnessScript == nil &&
+				len(a.MissingSigs) != 0:
+
+				a.Next = RoleSigner
+
+			default:
+				a.Next = RoleUpdater
+			}
+		}
+		if a.Next < analysis.Next {
+			analysis.Next = a.Next
+		}
+
+		if utxo != nil {
+			if utxo.Value < 0 || utxo.Value > ltcutil.MaxSatoshi {
+				return nil, fmt.Errorf("input %d has an "+
+					"invalid amount", i)
+			}
+			totalIn += utxo.Value
+		}
+	}
+
+	var totalOut int64
+	for _, txOut := range p.UnsignedTx.TxOut {
+		if txOut.Value < 0 || txOut.Value > ltcutil.MaxSatoshi {
+			return nil, errors.New("output amount invalid")
+		}
+		totalOut += txOut.Value
+	}
+	if totalOut > ltcutil.MaxSatoshi {
+		return nil, errors.New("output amount invalid")
+	}
+	if hasAllUtxos {
+		if totalIn > ltcutil.MaxSatoshi || totalIn < totalOut {
+			return nil, errors.New("fee amount invalid")
+		}
+		analysis.Fee = ltcutil.Amount(totalIn - totalOut)
+		analysis.HasFee = true
+	}
+
+	return analysis, nil
+}
+
+// Extract returns the signed transaction of the packet, which requires all of
+// its inputs to be finalized.
+func (p *Packet) Extract() (*wire.MsgTx, error) {
+	tx := p.UnsignedTx.Copy()
+	for i, in := range p.Inputs {
+		if !in.IsFinal() {
+			return nil, fmt.Errorf("input %d is not finalized", i)
+		}
+		tx.TxIn[i].SignatureScript = in.FinalScriptSig
+		tx.TxIn[i].Witness = in.FinalScriptWitness
+	}
+	return tx, nil
+}
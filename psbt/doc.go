@@ -0,0 +1,31 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package psbt implements parsing, serializing and analyzing partially signed
+transactions as defined by BIP0174.
+
+PSBT Overview
+
+A partially signed transaction, or packet, houses an unsigned transaction
+along with a map of key-value pairs for the packet as a whole, called the
+global map, and one map for each input and output of the transaction.  The
+maps carry the data the various participants need to sign and finalize the
+transaction, such as the outputs spent by the inputs, redeem and witness
+scripts, BIP0032 derivation paths and partial signatures.
+
+Entries which are unknown to this package, including those reserved for
+proprietary use, are preserved as is so a packet can be parsed and serialized
+again without losing them.  The Proprietary method of an unknown entry splits
+the key of a proprietary entry into its identifier, subtype and key data.
+
+Analysis
+
+BIP0174 defines the roles of the participants which process a packet: the
+creator, updater, signer, finalizer and extractor.  Analyze reports the data
+which is still missing from each input and the next role which needs to
+process the packet.  Since the analysis is done without any keys, signatures
+are only checked to be present and are not verified.
+*/
+package psbt
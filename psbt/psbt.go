@@ -0,0 +1,742 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// magic is the sequence of bytes every serialized packet begins with.  It is
+// the ASCII string "psbt" followed by a 0xff separator.
+var magic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// maxPacketSize is the maximum size of a serialized packet which will be
+// parsed.  It guards against allocating large amounts of memory for bogus
+// lengths and matches the maximum size of a message.
+const maxPacketSize = wire.MaxMessagePayload
+
+// The following are the key types of the global map.
+const (
+	globalUnsignedTx = 0x00
+	globalXPub       = 0x01
+	globalVersion    = 0xfb
+)
+
+// The following are the key types of the per-input maps.
+const (
+	inputNonWitnessUtxo     = 0x00
+	inputWitnessUtxo        = 0x01
+	inputPartialSig         = 0x02
+	inputSighashType        = 0x03
+	inputRedeemScript       = 0x04
+	inputWitnessScript      = 0x05
+	inputBip32Derivation    = 0x06
+	inputFinalScriptSig     = 0x07
+	inputFinalScriptWitness = 0x08
+)
+
+// The following are the key types of the per-output maps.
+const (
+	outputRedeemScript    = 0x00
+	outputWitnessScript   = 0x01
+	outputBip32Derivation = 0x02
+)
+
+// proprietaryType is the key type reserved for proprietary use in every map.
+const proprietaryType = 0xfc
+
+// xpubSize is the size of a serialized extended public key.
+const xpubSize = 78
+
+var (
+	// ErrInvalidMagic describes an error in which the passed data does not
+	// begin with the magic bytes of a packet.
+	ErrInvalidMagic = errors.New("invalid psbt magic bytes")
+
+	// ErrDuplicateKey describes an error in which a map of a packet has
+	// more than one entry for the same key.
+	ErrDuplicateKey = errors.New("duplicate key in psbt map")
+
+	// ErrMissingUnsignedTx describes an error in which the global map of a
+	// packet does not contain the unsigned transaction.
+	ErrMissingUnsignedTx = errors.New("psbt is missing the unsigned " +
+		"transaction")
+
+	// ErrSignedUnsignedTx describes an error in which the unsigned
+	// transaction of a packet has a signature script or witness.
+	ErrSignedUnsignedTx = errors.New("unsigned transaction of psbt has " +
+		"signature scripts or witnesses")
+)
+
+// Bip32Derivation houses the master key fingerprint and derivation path of a
+// public key.
+type Bip32Derivation struct {
+	PubKey               []byte
+	MasterKeyFingerprint uint32
+	Path                 []uint32
+}
+
+// XPub houses an extended public key of the global map along with the master
+// key fingerprint and derivation path it was derived with.  The key is kept in
+// its serialized form without the base58 checksum.
+type XPub struct {
+	ExtendedKey          []byte
+	MasterKeyFingerprint uint32
+	Path                 []uint32
+}
+
+// PartialSig houses a signature of an input along with the public key it was
+// made for.
+type PartialSig struct {
+	PubKey    []byte
+	Signature []byte
+}
+
+// Unknown houses an entry of a map which is either unknown to this package or
+// reserved for proprietary use.  Such entries are preserved as is so they
+// survive parsing and serializing the packet.  The key includes the key type.
+type Unknown struct {
+	Key   []byte
+	Value []byte
+}
+
+// Proprietary houses the parts of the key of a proprietary entry.
+type Proprietary struct {
+	Identifier []byte
+	Subtype    uint64
+	KeyData    []byte
+}
+
+// Proprietary returns the parts of the key of the entry when it is reserved
+// for proprietary use.  The returned flag is false for other entries as well
+// as proprietary entries with a malformed key.
+func (u *Unknown) Proprietary() (*Proprietary, bool) {
+	if len(u.Key) == 0 || u.Key[0] != proprietaryType {
+		return nil, false
+	}
+
+	r := bytes.NewReader(u.Key[1:])
+	identifier, err := wire.ReadVarBytes(r, 0, maxPacketSize,
+		"proprietary identifier")
+	if err != nil {
+		return nil, false
+	}
+	subtype, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, false
+	}
+	keyData := make([]byte, r.Len())
+	r.Read(keyData)
+	return &Proprietary{
+		Identifier: identifier,
+		Subtype:    subtype,
+		KeyData:    keyData,
+	}, true
+}
+
+// Input houses the per-input map of a packet.  Fields without a value in the
+// map are nil.
+type Input struct {
+	NonWitnessUtxo     *wire.MsgTx
+	WitnessUtxo        *wire.TxOut
+	PartialSigs        []*PartialSig
+	SighashType        *uint32
+	RedeemScript       []byte
+	WitnessScript      []byte
+	Bip32Derivation    []*Bip32Derivation
+	FinalScriptSig     []byte
+	FinalScriptWitness wire.TxWitness
+	Unknowns           []*Unknown
+}
+
+// IsFinal returns whether the input has a finalized signature script or
+// witness.
+func (in *Input) IsFinal() bool {
+	return in.FinalScriptSig != nil || in.FinalScriptWitness != nil
+}
+
+// Output houses the per-output map of a packet.  Fields without a value in
+// the map are nil.
+type Output struct {
+	RedeemScript    []byte
+	WitnessScript   []byte
+	Bip32Derivation []*Bip32Derivation
+	Unknowns        []*Unknown
+}
+
+// Packet houses a partially signed transaction along with the maps for each
+// of its inputs and outputs.
+type Packet struct {
+	UnsignedTx *wire.MsgTx
+	XPubs      []*XPub
+	Version    uint32
+	Unknowns   []*Unknown
+	Inputs     []*Input
+	Outputs    []*Output
+}
+
+// New returns a packet for the passed unsigned transaction with empty maps for
+// all of its inputs and outputs.  The transaction must not have any signature
+// scripts or witnesses.
+func New(tx *wire.MsgTx) (*Packet, error) {
+	if err := checkUnsignedTx(tx); err != nil {
+		return nil, err
+	}
+
+	p := &Packet{
+		UnsignedTx: tx,
+		Inputs:     make([]*Input, len(tx.TxIn)),
+		Outputs:    make([]*Output, len(tx.TxOut)),
+	}
+	for i := range p.Inputs {
+		p.Inputs[i] = &Input{}
+	}
+	for i := range p.Outputs {
+		p.Outputs[i] = &Output{}
+	}
+	return p, nil
+}
+
+// checkUnsignedTx ensures the passed transaction does not have any signature
+// scripts or witnesses.
+func checkUnsignedTx(tx *wire.MsgTx) error {
+	for _, txIn := range tx.TxIn {
+		if len(txIn.SignatureScript) != 0 || len(txIn.Witness) != 0 {
+			return ErrSignedUnsignedTx
+		}
+	}
+	return nil
+}
+
+// keyValue houses a single entry of a map.
+type keyValue struct {
+	key   []byte
+	value []byte
+}
+
+// readMap reads a map up to and including its separator.  An error is
+// returned when the map has more than one entry for a key.
+func readMap(r io.Reader) ([]keyValue, error) {
+	var entries []keyValue
+	seen := make(map[string]struct{})
+	for {
+		key, err := wire.ReadVarBytes(r, 0, maxPacketSize, "psbt key")
+		if err != nil {
+			return nil, err
+		}
+		if len(key) == 0 {
+			return entries, nil
+		}
+		if _, ok := seen[string(key)]; ok {
+			return nil, ErrDuplicateKey
+		}
+		seen[string(key)] = struct{}{}
+
+		value, err := wire.ReadVarBytes(r, 0, maxPacketSize,
+			"psbt value")
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, keyValue{key: key, value: value})
+	}
+}
+
+// keyError returns an error for an entry of the named map with a malformed
+// key or value.
+func keyError(mapName string, key []byte) error {
+	return fmt.Errorf("invalid %s entry with key %x", mapName, key)
+}
+
+// parseDerivation parses the master key fingerprint and derivation path from
+// the value of a BIP0032 derivation entry.
+func parseDerivation(value []byte) (uint32, []uint32, bool) {
+	if len(value) < 4 || len(value)%4 != 0 {
+		return 0, nil, false
+	}
+	fingerprint := binary.LittleEndian.Uint32(value)
+	path := make([]uint32, 0, len(value)/4-1)
+	for i := 4; i < len(value); i += 4 {
+		path = append(path, binary.LittleEndian.Uint32(value[i:]))
+	}
+	return fingerprint, path, true
+}
+
+// serializeDerivation serializes the passed master key fingerprint and
+// derivation path as the value of a BIP0032 derivation entry.
+func serializeDerivation(fingerprint uint32, path []uint32) []byte {
+	value := make([]byte, 4+4*len(path))
+	binary.LittleEndian.PutUint32(value, fingerprint)
+	for i, index := range path {
+		binary.LittleEndian.PutUint32(value[4+4*i:], index)
+	}
+	return value
+}
+
+// isPubKey returns whether the passed bytes have the size of a serialized
+// compressed or uncompressed public key.
+func isPubKey(pubKey []byte) bool {
+	return len(pubKey) == 33 || len(pubKey) == 65
+}
+
+// parseInput parses the entries of a per-input map.
+func parseInput(entries []keyValue) (*Input, error) {
+	in := &Input{}
+	for _, entry := range entries {
+		key, value := entry.key, entry.value
+		keyData := key[1:]
+		switch {
+		case key[0] == inputNonWitnessUtxo && len(keyData) == 0:
+			var tx wire.MsgTx
+			err := tx.Deserialize(bytes.NewReader(value))
+			if err != nil {
+				return nil, keyError("input", key)
+			}
+			in.NonWitnessUtxo = &tx
+
+		case key[0] == inputWitnessUtxo && len(keyData) == 0:
+			r := bytes.NewReader(value)
+			var amount [8]byte
+			if _, err := io.ReadFull(r, amount[:]); err != nil {
+				return nil, keyError("input", key)
+			}
+			pkScript, err := wire.ReadVarBytes(r, 0, maxPacketSize,
+				"witness utxo script")
+			if err != nil || r.Len() != 0 {
+				return nil, keyError("input", key)
+			}
+			in.WitnessUtxo = wire.NewTxOut(
+				int64(binary.LittleEndian.Uint64(amount[:])),
+				pkScript)
+
+		case key[0] == inputPartialSig && isPubKey(keyData):
+			in.PartialSigs = append(in.PartialSigs, &PartialSig{
+				PubKey:    keyData,
+				Signature: value,
+			})
+
+		case key[0] == inputSighashType && len(keyData) == 0:
+			if len(value) != 4 {
+				return nil, keyError("input", key)
+			}
+			sighashType := binary.LittleEndian.Uint32(value)
+			in.SighashType = &sighashType
+
+		case key[0] == inputRedeemScript && len(keyData) == 0:
+			in.RedeemScript = value
+
+		case key[0] == inputWitnessScript && len(keyData) == 0:
+			in.WitnessScript = value
+
+		case key[0] == inputBip32Derivation && isPubKey(keyData):
+			fingerprint, path, ok := parseDerivation(value)
+			if !ok {
+				return nil, keyError("input", key)
+			}
+			in.Bip32Derivation = append(in.Bip32Derivation,
+				&Bip32Derivation{
+					PubKey:               keyData,
+					MasterKeyFingerprint: fingerprint,
+					Path:                 path,
+				})
+
+		case key[0] == inputFinalScriptSig && len(keyData) == 0:
+			in.FinalScriptSig = value
+
+		case key[0] == inputFinalScriptWitness && len(keyData) == 0:
+			witness, err := parseWitness(value)
+			if err != nil {
+				return nil, keyError("input", key)
+			}
+			in.FinalScriptWitness = witness
+
+		default:
+			in.Unknowns = append(in.Unknowns, &Unknown{
+				Key:   key,
+				Value: value,
+			})
+		}
+	}
+
+	return in, nil
+}
+
+// parseWitness parses a serialized witness stack.
+func parseWitness(value []byte) (wire.TxWitness, error) {
+	r := bytes.NewReader(value)
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > uint64(len(value)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	witness := make(wire.TxWitness, count)
+	for i := range witness {
+		witness[i], err = wire.ReadVarBytes(r, 0, maxPacketSize,
+			"witness item")
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("trailing bytes after witness")
+	}
+	return witness, nil
+}
+
+// parseOutput parses the entries of a per-output map.
+func parseOutput(entries []keyValue) (*Output, error) {
+	out := &Output{}
+	for _, entry := range entries {
+		key, value := entry.key, entry.value
+		keyData := key[1:]
+		switch {
+		case key[0] == outputRedeemScript && len(keyData) == 0:
+			out.RedeemScript = value
+
+		case key[0] == outputWitnessScript && len(keyData) == 0:
+			out.WitnessScript = value
+
+		case key[0] == outputBip32Derivation && isPubKey(keyData):
+			fingerprint, path, ok := parseDerivation(value)
+			if !ok {
+				return nil, keyError("output", key)
+			}
+			out.Bip32Derivation = append(out.Bip32Derivation,
+				&Bip32Derivation{
+					PubKey:               keyData,
+					MasterKeyFingerprint: fingerprint,
+					Path:                 path,
+				})
+
+		default:
+			out.Unknowns = append(out.Unknowns, &Unknown{
+				Key:   key,
+				Value: value,
+			})
+		}
+	}
+
+	return out, nil
+}
+
+// Parse parses a serialized packet.
+func Parse(serialized []byte) (*Packet, error) {
+	if len(serialized) > maxPacketSize {
+		return nil, fmt.Errorf("psbt of %d bytes exceeds the maximum "+
+			"of %d bytes", len(serialized), maxPacketSize)
+	}
+	if !bytes.HasPrefix(serialized, magic) {
+		return nil, ErrInvalidMagic
+	}
+	r := bytes.NewReader(serialized[len(magic):])
+
+	// Parse the global map which must contain the unsigned transaction.
+	entries, err := readMap(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &Packet{}
+	for _, entry := range entries {
+		key, value := entry.key, entry.value
+		keyData := key[1:]
+		switch {
+		case key[0] == globalUnsignedTx && len(keyData) == 0:
+			// The unsigned transaction is always serialized without
+			// witnesses.
+			var tx wire.MsgTx
+			vr := bytes.NewReader(value)
+			err := tx.DeserializeNoWitness(vr)
+			if err != nil || vr.Len() != 0 {
+				return nil, keyError("global", key)
+			}
+			if err := checkUnsignedTx(&tx); err != nil {
+				return nil, err
+			}
+			p.UnsignedTx = &tx
+
+		case key[0] == globalXPub && len(keyData) == xpubSize:
+			fingerprint, path, ok := parseDerivation(value)
+			if !ok {
+				return nil, keyError("global", key)
+			}
+			p.XPubs = append(p.XPubs, &XPub{
+				ExtendedKey:          keyData,
+				MasterKeyFingerprint: fingerprint,
+				Path:                 path,
+			})
+
+		case key[0] == globalVersion && len(keyData) == 0:
+			if len(value) != 4 {
+				return nil, keyError("global", key)
+			}
+			p.Version = binary.LittleEndian.Uint32(value)
+			if p.Version != 0 {
+				return nil, fmt.Errorf("unsupported psbt version %d",
+					p.Version)
+			}
+
+		default:
+			p.Unknowns = append(p.Unknowns, &Unknown{
+				Key:   key,
+				Value: value,
+			})
+		}
+	}
+	if p.UnsignedTx == nil {
+		return nil, ErrMissingUnsignedTx
+	}
+
+	// Parse a map for every input and output of the unsigned transaction.
+	p.Inputs = make([]*Input, len(p.UnsignedTx.TxIn))
+	for i := range p.Inputs {
+		entries, err := readMap(r)
+		if err != nil {
+			return nil, err
+		}
+		if p.Inputs[i], err = parseInput(entries); err != nil {
+			return nil, err
+		}
+
+		// The full transaction of the spent output must be the one
+		// referenced by the input.
+		utxo := p.Inputs[i].NonWitnessUtxo
+		prevOut := &p.UnsignedTx.TxIn[i].PreviousOutPoint
+		if utxo != nil && utxo.TxHash() != prevOut.Hash {
+			return nil, fmt.Errorf("non-witness utxo of input %d "+
+				"does not match its outpoint", i)
+		}
+	}
+	p.Outputs = make([]*Output, len(p.UnsignedTx.TxOut))
+	for i := range p.Outputs {
+		entries, err := readMap(r)
+		if err != nil {
+			return nil, err
+		}
+		if p.Outputs[i], err = parseOutput(entries); err != nil {
+			return nil, err
+		}
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("trailing bytes after psbt")
+	}
+
+	return p, nil
+}
+
+// ParseBase64 parses a base64-encoded packet.
+func ParseBase64(encoded string) (*Packet, error) {
+	serialized, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(serialized)
+}
+
+// writeEntry writes a single entry of a map.
+func writeEntry(w io.Writer, keyType byte, keyData, value []byte) error {
+	key := append([]byte{keyType}, keyData...)
+	if err := wire.WriteVarBytes(w, 0, key); err != nil {
+		return err
+	}
+	return wire.WriteVarBytes(w, 0, value)
+}
+
+// writeUnknowns writes the passed unknown entries of a map followed by the map
+// separator.
+func writeUnknowns(w io.Writer, unknowns []*Unknown) error {
+	for _, u := range unknowns {
+		if err := wire.WriteVarBytes(w, 0, u.Key); err != nil {
+			return err
+		}
+		if err := wire.WriteVarBytes(w, 0, u.Value); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write([]byte{0x00})
+	return err
+}
+
+// serializeInput writes the per-input map for the passed input.
+func serializeInput(w io.Writer, in *Input) error {
+	if in.NonWitnessUtxo != nil {
+		var buf bytes.Buffer
+		if err := in.NonWitnessUtxo.Serialize(&buf); err != nil {
+			return err
+		}
+		err := writeEntry(w, inputNonWitnessUtxo, nil, buf.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	if in.WitnessUtxo != nil {
+		var buf bytes.Buffer
+		err := binary.Write(&buf, binary.LittleEndian,
+			uint64(in.WitnessUtxo.Value))
+		if err != nil {
+			return err
+		}
+		err = wire.WriteVarBytes(&buf, 0, in.WitnessUtxo.PkScript)
+		if err != nil {
+			return err
+		}
+		err = writeEntry(w, inputWitnessUtxo, nil, buf.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	for _, sig := range in.PartialSigs {
+		err := writeEntry(w, inputPartialSig, sig.PubKey, sig.Signature)
+		if err != nil {
+			return err
+		}
+	}
+	if in.SighashType != nil {
+		var value [4]byte
+		binary.LittleEndian.PutUint32(value[:], *in.SighashType)
+		err := writeEntry(w, inputSighashType, nil, value[:])
+		if err != nil {
+			return err
+		}
+	}
+	if in.RedeemScript != nil {
+		err := writeEntry(w, inputRedeemScript, nil, in.RedeemScript)
+		if err != nil {
+			return err
+		}
+	}
+	if in.WitnessScript != nil {
+		err := writeEntry(w, inputWitnessScript, nil, in.WitnessScript)
+		if err != nil {
+			return err
+		}
+	}
+	for _, d := range in.Bip32Derivation {
+		err := writeEntry(w, inputBip32Derivation, d.PubKey,
+			serializeDerivation(d.MasterKeyFingerprint, d.Path))
+		if err != nil {
+			return err
+		}
+	}
+	if in.FinalScriptSig != nil {
+		err := writeEntry(w, inputFinalScriptSig, nil, in.FinalScriptSig)
+		if err != nil {
+			return err
+		}
+	}
+	if in.FinalScriptWitness != nil {
+		var buf bytes.Buffer
+		err := wire.WriteVarInt(&buf, 0,
+			uint64(len(in.FinalScriptWitness)))
+		if err != nil {
+			return err
+		}
+		for _, item := range in.FinalScriptWitness {
+			if err := wire.WriteVarBytes(&buf, 0, item); err != nil {
+				return err
+			}
+		}
+		err = writeEntry(w, inputFinalScriptWitness, nil, buf.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeUnknowns(w, in.Unknowns)
+}
+
+// serializeOutput writes the per-output map for the passed output.
+func serializeOutput(w io.Writer, out *Output) error {
+	if out.RedeemScript != nil {
+		err := writeEntry(w, outputRedeemScript, nil, out.RedeemScript)
+		if err != nil {
+			return err
+		}
+	}
+	if out.WitnessScript != nil {
+		err := writeEntry(w, outputWitnessScript, nil, out.WitnessScript)
+		if err != nil {
+			return err
+		}
+	}
+	for _, d := range out.Bip32Derivation {
+		err := writeEntry(w, outputBip32Derivation, d.PubKey,
+			serializeDerivation(d.MasterKeyFingerprint, d.Path))
+		if err != nil {
+			return err
+		}
+	}
+
+	return writeUnknowns(w, out.Unknowns)
+}
+
+// Serialize writes the serialized packet to the passed writer.  Entries which
+// are unknown to this package are written after the known entries of their
+// map.
+func (p *Packet) Serialize(w io.Writer) error {
+	if len(p.Inputs) != len(p.UnsignedTx.TxIn) ||
+		len(p.Outputs) != len(p.UnsignedTx.TxOut) {
+
+		return errors.New("psbt maps do not match the inputs and " +
+			"outputs of the unsigned transaction")
+	}
+
+	if _, err := w.Write(magic); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := p.UnsignedTx.SerializeNoWitness(&buf); err != nil {
+		return err
+	}
+	if err := writeEntry(w, globalUnsignedTx, nil, buf.Bytes()); err != nil {
+		return err
+	}
+	for _, xpub := range p.XPubs {
+		err := writeEntry(w, globalXPub, xpub.ExtendedKey,
+			serializeDerivation(xpub.MasterKeyFingerprint, xpub.Path))
+		if err != nil {
+			return err
+		}
+	}
+	if p.Version != 0 {
+		var value [4]byte
+		binary.LittleEndian.PutUint32(value[:], p.Version)
+		if err := writeEntry(w, globalVersion, nil, value[:]); err != nil {
+			return err
+		}
+	}
+	if err := writeUnknowns(w, p.Unknowns); err != nil {
+		return err
+	}
+
+	for _, in := range p.Inputs {
+		if err := serializeInput(w, in); err != nil {
+			return err
+		}
+	}
+	for _, out := range p.Outputs {
+		if err := serializeOutput(w, out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// B64Encode returns the base64-encoded serialized packet.
+func (p *Packet) B64Encode() (string, error) {
+	var buf bytes.Buffer
+	if err := p.Serialize(&buf); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
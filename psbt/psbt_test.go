@@ -0,0 +1,367 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package psbt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/wire"
+)
+
+// multisigPSBT is the packet from the signer example of BIP0174.  It spends a
+// 2-of-2 multisig pay-to-script-hash output and a 2-of-2 multisig
+// pay-to-witness-script-hash output nested in a pay-to-script-hash output, and
+// both inputs have one of their two signatures.
+const multisigPSBT = "cHNidP8BAJoCAAAAAljoeiG1ba8MI76OcHBFbDNvfLqlyHV5JPVFiHuyq911AAAA" +
+	"AAD/////g40EJ9DsZQpoqka7CwmK6kQiwHGyyng1Kgd5WdB86h0BAAAAAP////8C" +
+	"cKrwCAAAAAAWABTYXCtx0AYLCcmIauuBXlCZHdoSTQDh9QUAAAAAFgAUAK6pouXw" +
+	"+HaliN9VRuh0LR2HAI8AAAAAAAEAuwIAAAABqtc5MQGL0l+ErkALaISL4J23BurC" +
+	"rBgpi6vucatlb4sAAAAASEcwRAIgWPb8fGoz4bMVSNSByCbAFb0wE1qtQs1neQ2r" +
+	"ZtKtJDsCIEoc7SYExnNbY5PltBaR3XiwDwxZQvufdRhW+qk4FX26Af7///8CgPD6" +
+	"AgAAAAAXqRQPuUY0IWlrgsgzryQceMF9295JNIfQ8gonAQAAABepFCnKdPigj4GZ" +
+	"lCgYXJe12FLkBj9hh2UAAAAiAgKVg785rgpgl0etGZrd1jT6YQhVnWxc05tMIYPx" +
+	"q5bgf0cwRAIgdAGK1BgAl7hzMjwAFXILNoTMgSOJEEjn282bVa1nnJkCIHPTabdA" +
+	"4+tT3O+jOCPIBwUUylWn3ZVE8VfBZ5EyYRGMAQEDBAEAAAABBEdSIQKVg785rgpg" +
+	"l0etGZrd1jT6YQhVnWxc05tMIYPxq5bgfyEC2rYf9JoU22p9ArDNH7t4/EsYMStb" +
+	"TlTa5Nui+/71NtdSriIGApWDvzmuCmCXR60Zmt3WNPphCFWdbFzTm0whg/GrluB/" +
+	"ENkMak8AAACAAAAAgAAAAIAiBgLath/0mhTban0CsM0fu3j8SxgxK1tOVNrk26L7" +
+	"/vU21xDZDGpPAAAAgAAAAIABAACAAAEBIADC6wsAAAAAF6kUt/X69A49QKWkWbHb" +
+	"NTXyty+pIeiHIgIDCJ3BDHrG21T5EymvYXMz2ziM6tDCMfcjN50bmQMLAtxHMEQC" +
+	"IGLrelVhB6fHP0WsSrWh3d9vcHX7EnWWmn84Pv/3hLyyAiAMBdu3Rw2/LwhVfdNW" +
+	"xzJcHtMJE+mWzThAlF2xIijaXwEBAwQBAAAAAQQiACCMI1MXN0O1ld+0oHtyuo5C" +
+	"43l9p06H/n2ddJfjsgKJAwEFR1IhAwidwQx6xttU+RMpr2FzM9s4jOrQwjH3Ized" +
+	"G5kDCwLcIQI63ZBPPW3PWd25BrDe4jUpt/+57VDl6GFRkmhgIh8Oc1KuIgYCOt2Q" +
+	"Tz1tz1nduQaw3uI1Kbf/ue1Q5ehhUZJoYCIfDnMQ2QxqTwAAAIAAAACAAwAAgCIG" +
+	"AwidwQx6xttU+RMpr2FzM9s4jOrQwjH3IzedG5kDCwLcENkMak8AAACAAAAAgAIA" +
+	"AIAAIgIDqaTDf1mW06ol26xrVwrwZQOUSSlCRgs1R1Ptnuylh3EQ2QxqTwAAAIAA" +
+	"AACABAAAgAAiAgJ/Y5l1fS7/VaE2rQLGhLGDi2VW5fG2s0KCqUtrUAUQlhDZDGpP" +
+	"AAAAgAAAAIAFAACAAA=="
+
+// hexToBytes converts the passed hex string into bytes and will panic if there
+// is an error.  This is only provided for the hard-coded constants so errors in
+// the source code can be detected.  It will only (and must only) be called with
+// hard-coded values.
+func hexToBytes(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic("invalid hex in source file: " + s)
+	}
+	return b
+}
+
+// TestParse ensures the multisig packet is parsed as expected and serializes
+// back to the same bytes.
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParseBase64(multisigPSBT)
+	if err != nil {
+		t.Fatalf("ParseBase64: unexpected error: %v", err)
+	}
+
+	wantTxID := "82efd652d7ab1197f01a5f4d9a30cb4c68bb79ab6fec58dfa1bf112291d1617b"
+	if txID := p.UnsignedTx.TxHash().String(); txID != wantTxID {
+		t.Fatalf("unsigned tx: got txid %s, want %s", txID, wantTxID)
+	}
+	if len(p.Inputs) != 2 || len(p.Outputs) != 2 {
+		t.Fatalf("got %d inputs and %d outputs, want 2 and 2",
+			len(p.Inputs), len(p.Outputs))
+	}
+
+	// The first input spends a legacy output and includes the full
+	// transaction which created it.
+	in := p.Inputs[0]
+	prevOut := p.UnsignedTx.TxIn[0].PreviousOutPoint
+	if in.NonWitnessUtxo == nil || in.NonWitnessUtxo.TxHash() != prevOut.Hash {
+		t.Fatalf("input 0: missing or mismatched non-witness utxo")
+	}
+	if in.WitnessUtxo != nil {
+		t.Fatalf("input 0: unexpected witness utxo")
+	}
+	wantSig := &PartialSig{
+		PubKey:    hexToBytes("029583bf39ae0a609747ad199addd634fa6108559d6c5cd39b4c2183f1ab96e07f"),
+		Signature: hexToBytes("3044022074018ad4180097b873323c0015720b3684cc8123891048e7dbcd9b55ad679c99022073d369b740e3eb53dcefa33823c8070514ca55a7dd9544f157c167913261118c01"),
+	}
+	if len(in.PartialSigs) != 1 || !reflect.DeepEqual(in.PartialSigs[0], wantSig) {
+		t.Fatalf("input 0: got partial sigs %v, want %v", in.PartialSigs,
+			wantSig)
+	}
+	if in.SighashType == nil || *in.SighashType != 1 {
+		t.Fatalf("input 0: got sighash type %v, want 1", in.SighashType)
+	}
+	wantRedeemScript := hexToBytes("5221029583bf39ae0a609747ad199addd634fa6108559d6c5cd39b4c2183f1ab96e07f2102dab61ff49a14db6a7d02b0cd1fbb78fc4b18312b5b4e54dae4dba2fbfef536d752ae")
+	if !bytes.Equal(in.RedeemScript, wantRedeemScript) {
+		t.Fatalf("input 0: got redeem script %x, want %x",
+			in.RedeemScript, wantRedeemScript)
+	}
+	wantDerivation := &Bip32Derivation{
+		PubKey:               hexToBytes("029583bf39ae0a609747ad199addd634fa6108559d6c5cd39b4c2183f1ab96e07f"),
+		MasterKeyFingerprint: 0x4f6a0cd9,
+		Path:                 []uint32{0x80000000, 0x80000000, 0x80000000},
+	}
+	if len(in.Bip32Derivation) != 2 ||
+		!reflect.DeepEqual(in.Bip32Derivation[0], wantDerivation) {
+
+		t.Fatalf("input 0: got derivations %v, want %v first",
+			in.Bip32Derivation, wantDerivation)
+	}
+
+	// The second input spends a nested segwit output and only includes
+	// the spent output.
+	in = p.Inputs[1]
+	if in.NonWitnessUtxo != nil {
+		t.Fatalf("input 1: unexpected non-witness utxo")
+	}
+	wantUtxo := wire.NewTxOut(200000000,
+		hexToBytes("a914b7f5faf40e3d40a5a459b1db3535f2b72fa921e887"))
+	if !reflect.DeepEqual(in.WitnessUtxo, wantUtxo) {
+		t.Fatalf("input 1: got witness utxo %v, want %v",
+			in.WitnessUtxo, wantUtxo)
+	}
+	if in.RedeemScript == nil || in.WitnessScript == nil {
+		t.Fatalf("input 1: missing redeem or witness script")
+	}
+	if in.IsFinal() {
+		t.Fatalf("input 1: unexpectedly final")
+	}
+
+	// Each output has a single derivation.
+	for i, out := range p.Outputs {
+		if len(out.Bip32Derivation) != 1 {
+			t.Fatalf("output %d: got %d derivations, want 1", i,
+				len(out.Bip32Derivation))
+		}
+	}
+
+	encoded, err := p.B64Encode()
+	if err != nil {
+		t.Fatalf("B64Encode: unexpected error: %v", err)
+	}
+	if encoded != multisigPSBT {
+		t.Fatalf("B64Encode: got %s, want %s", encoded, multisigPSBT)
+	}
+}
+
+// TestUnknowns ensures unknown and proprietary entries survive parsing and
+// serializing a packet.
+func TestUnknowns(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParseBase64(multisigPSBT)
+	if err != nil {
+		t.Fatalf("ParseBase64: unexpected error: %v", err)
+	}
+
+	// Add a proprietary entry with the identifier "ltcd", subtype 1 and key
+	// data 0xabcd to the global map as well as an unknown entry to the
+	// first input.
+	proprietary := &Unknown{
+		Key:   hexToBytes("fc046c74636401abcd"),
+		Value: hexToBytes("0102"),
+	}
+	unknown := &Unknown{Key: hexToBytes("f0aa"), Value: hexToBytes("03")}
+	p.Unknowns = append(p.Unknowns, proprietary)
+	p.Inputs[0].Unknowns = append(p.Inputs[0].Unknowns, unknown)
+
+	var buf bytes.Buffer
+	if err := p.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: unexpected error: %v", err)
+	}
+	p, err = Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	if len(p.Unknowns) != 1 || !reflect.DeepEqual(p.Unknowns[0], proprietary) {
+		t.Fatalf("got global unknowns %v, want %v", p.Unknowns,
+			proprietary)
+	}
+	if len(p.Inputs[0].Unknowns) != 1 ||
+		!reflect.DeepEqual(p.Inputs[0].Unknowns[0], unknown) {
+
+		t.Fatalf("got input unknowns %v, want %v", p.Inputs[0].Unknowns,
+			unknown)
+	}
+
+	wantProprietary := &Proprietary{
+		Identifier: []byte("ltcd"),
+		Subtype:    1,
+		KeyData:    hexToBytes("abcd"),
+	}
+	got, ok := p.Unknowns[0].Proprietary()
+	if !ok || !reflect.DeepEqual(got, wantProprietary) {
+		t.Fatalf("Proprietary: got %v, want %v", got, wantProprietary)
+	}
+	if _, ok := p.Inputs[0].Unknowns[0].Proprietary(); ok {
+		t.Fatalf("Proprietary: unexpected proprietary unknown entry")
+	}
+}
+
+// TestParseErrors ensures malformed packets are rejected.
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	valid, err := base64.StdEncoding.DecodeString(multisigPSBT)
+	if err != nil {
+		t.Fatalf("unable to decode packet: %v", err)
+	}
+
+	// The unsigned transaction entry directly follows the magic bytes and
+	// is the only entry of the global map.
+	unsignedTxEntry := valid[5:162]
+	withDuplicate := append([]byte{}, valid[:162]...)
+	withDuplicate = append(withDuplicate, unsignedTxEntry...)
+	withDuplicate = append(withDuplicate, valid[162:]...)
+	if _, err := Parse(withDuplicate); err != ErrDuplicateKey {
+		t.Fatalf("Parse: got error %v, want %v", err, ErrDuplicateKey)
+	}
+
+	tests := []struct {
+		name       string
+		serialized []byte
+	}{
+		{"empty", nil},
+		{"bad magic", append([]byte("psbu\xff"), valid[5:]...)},
+		{"missing unsigned tx", append(append([]byte{}, valid[:5]...), 0x00)},
+		{"truncated", valid[:len(valid)-1]},
+		{"trailing bytes", append(append([]byte{}, valid...), 0x00)},
+	}
+	for _, test := range tests {
+		if _, err := Parse(test.serialized); err == nil {
+			t.Errorf("%s: unexpected success", test.name)
+		}
+	}
+
+	// Ensure a full spent transaction which does not match the input is
+	// rejected.
+	p, err := Parse(valid)
+	if err != nil {
+		t.Fatalf("Parse: unexpected error: %v", err)
+	}
+	p.Inputs[0].NonWitnessUtxo.LockTime++
+	var buf bytes.Buffer
+	if err := p.Serialize(&buf); err != nil {
+		t.Fatalf("Serialize: unexpected error: %v", err)
+	}
+	if _, err := Parse(buf.Bytes()); err == nil {
+		t.Fatalf("Parse: unexpected success with mismatched utxo")
+	}
+
+	// Ensure a transaction with a signature script can't be used to create
+	// a packet.
+	signedTx := p.UnsignedTx.Copy()
+	signedTx.TxIn[0].SignatureScript = []byte{0x00}
+	if _, err := New(signedTx); err != ErrSignedUnsignedTx {
+		t.Fatalf("New: got error %v, want %v", err, ErrSignedUnsignedTx)
+	}
+}
+
+// TestAnalyze ensures the analysis of a packet reports the missing data and
+// next role as expected as the packet progresses.
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	p, err := ParseBase64(multisigPSBT)
+	if err != nil {
+		t.Fatalf("ParseBase64: unexpected error: %v", err)
+	}
+
+	// Both inputs are missing the signature of their second key.
+	analysis, err := p.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: unexpected error: %v", err)
+	}
+	if analysis.Next != RoleSigner || !analysis.HasFee || analysis.Fee != 10000 {
+		t.Fatalf("Analyze: got next %v and fee %v, want signer and "+
+			"10000", analysis.Next, analysis.Fee)
+	}
+	wantMissing := [][][]byte{
+		{hexToBytes("b9147fd38b198ab90491adec86ad6b69f5a3ec44")},
+		{hexToBytes("d48ed3110b94014cb114bd32d6f4d066dc74256b")},
+	}
+	for i, a := range analysis.Inputs {
+		if !a.HasUtxo || a.IsFinal || a.Next != RoleSigner ||
+			!reflect.DeepEqual(a.MissingSigs, wantMissing[i]) {
+
+			t.Fatalf("input %d: got %+v, want missing sigs %x", i, a,
+				wantMissing[i])
+		}
+	}
+
+	// Removing the witness script of the second input makes the updater
+	// next while adding the missing signature to the first input makes it
+	// ready for the finalizer.
+	witnessScript := p.Inputs[1].WitnessScript
+	p.Inputs[1].WitnessScript = nil
+	p.Inputs[0].PartialSigs = append(p.Inputs[0].PartialSigs, &PartialSig{
+		PubKey:    p.Inputs[0].Bip32Derivation[1].PubKey,
+		Signature: []byte{0x01},
+	})
+	analysis, err = p.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: unexpected error: %v", err)
+	}
+	if analysis.Next != RoleUpdater {
+		t.Fatalf("Analyze: got next %v, want updater", analysis.Next)
+	}
+	if a := analysis.Inputs[0]; a.Next != RoleFinalizer || a.MissingSigs != nil {
+		t.Fatalf("input 0: got %+v, want finalizer next", a)
+	}
+	a := analysis.Inputs[1]
+	wantWitnessScript := hexToBytes("8c2353173743b595dfb4a07b72ba8e42e3797da74e87fe7d9d7497e3b2028903")
+	if a.Next != RoleUpdater || !bytes.Equal(a.MissingWitnessScript, wantWitnessScript) {
+		t.Fatalf("input 1: got %+v, want missing witness script %x", a,
+			wantWitnessScript)
+	}
+
+	// An input without its spent output also requires the updater and
+	// leaves the fee unknown.
+	p.Inputs[1].WitnessScript = witnessScript
+	witnessUtxo := p.Inputs[1].WitnessUtxo
+	p.Inputs[1].WitnessUtxo = nil
+	analysis, err = p.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: unexpected error: %v", err)
+	}
+	if a := analysis.Inputs[1]; a.HasUtxo || a.Next != RoleUpdater ||
+		analysis.HasFee {
+
+		t.Fatalf("input 1: got %+v and fee known %v, want updater "+
+			"next and unknown fee", a, analysis.HasFee)
+	}
+
+	// Once both inputs are finalized the extractor is next and the signed
+	// transaction can be extracted.
+	p.Inputs[1].WitnessUtxo = witnessUtxo
+	p.Inputs[0].FinalScriptSig = []byte{0x00}
+	p.Inputs[1].FinalScriptSig = []byte{0x01}
+	p.Inputs[1].FinalScriptWitness = wire.TxWitness{{0x02}}
+	analysis, err = p.Analyze()
+	if err != nil {
+		t.Fatalf("Analyze: unexpected error: %v", err)
+	}
+	if analysis.Next != RoleExtractor {
+		t.Fatalf("Analyze: got next %v, want extractor", analysis.Next)
+	}
+	tx, err := p.Extract()
+	if err != nil {
+		t.Fatalf("Extract: unexpected error: %v", err)
+	}
+	if !bytes.Equal(tx.TxIn[1].SignatureScript, []byte{0x01}) ||
+		!tx.HasWitness() || p.UnsignedTx.HasWitness() {
+
+		t.Fatalf("Extract: unexpected transaction %v", tx)
+	}
+
+	// A full spent transaction which does not match the input makes the
+	// packet invalid.
+	p.Inputs[0].NonWitnessUtxo = wire.NewMsgTx(1)
+	if _, err := p.Analyze(); err == nil {
+		t.Fatalf("Analyze: unexpected success with mismatched utxo")
+	}
+}
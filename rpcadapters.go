@@ -5,8 +5,11 @@
 package main
 
 import (
+	"net"
 	"sync/atomic"
+	"time"
 
+	"github.com/ltcsuite/ltcd/addrmgr"
 	"github.com/ltcsuite/ltcd/blockchain"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 	"github.com/ltcsuite/ltcd/mempool"
@@ -60,6 +63,24 @@ func (p *rpcPeer) FeeFilter() int64 {
 	return atomic.LoadInt64(&(*serverPeer)(p).feeFilter)
 }
 
+// BytesSentPerMsg returns the number of bytes sent to the peer for each message
+// command.
+//
+// This function is safe for concurrent access and is part of the rpcserverPeer
+// interface implementation.
+func (p *rpcPeer) BytesSentPerMsg() map[string]uint64 {
+	return (*serverPeer)(p).BytesSentPerMsg()
+}
+
+// BytesRecvPerMsg returns the number of bytes received from the peer for each
+// message command.
+//
+// This function is safe for concurrent access and is part of the rpcserverPeer
+// interface implementation.
+func (p *rpcPeer) BytesRecvPerMsg() map[string]uint64 {
+	return (*serverPeer)(p).BytesRecvPerMsg()
+}
+
 // rpcConnManager provides a connection manager for use with the RPC server and
 // implements the rpcserverConnManager interface.
 type rpcConnManager struct {
@@ -110,6 +131,7 @@ func (cm *rpcConnManager) RemoveByID(id int32) error {
 func (cm *rpcConnManager) RemoveByAddr(addr string) error {
 	replyChan := make(chan error)
 	cm.server.query <- removeNodeMsg{
+		addr:  addr,
 		cmp:   func(sp *serverPeer) bool { return sp.Addr() == addr },
 		reply: replyChan,
 	}
@@ -206,20 +228,100 @@ func (cm *rpcConnManager) BroadcastMessage(msg wire.Message) {
 	cm.server.BroadcastMessage(msg)
 }
 
-// AddRebroadcastInventory adds the provided inventory to the list of
-// inventories to be rebroadcast at random intervals until they show up in a
-// block.
+// NodeAddresses returns up to the requested number of randomly selected
+// addresses known to be good from the address manager.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) NodeAddresses(count int) []*wire.NetAddress {
+	return cm.server.addrManager.GoodAddresses(count)
+}
+
+// AddPeerAddress adds the provided address to the address manager as if it
+// had been learned from a peer, and additionally marks it good when tried is
+// set.  It returns whether the address is known to the address manager
+// afterwards.
 //
 // This function is safe for concurrent access and is part of the
 // rpcserverConnManager interface implementation.
-func (cm *rpcConnManager) AddRebroadcastInventory(iv *wire.InvVect, data interface{}) {
-	cm.server.AddRebroadcastInventory(iv, data)
+func (cm *rpcConnManager) AddPeerAddress(na *wire.NetAddress, tried bool) bool {
+	amgr := cm.server.addrManager
+	amgr.AddAddress(na, na)
+	if tried {
+		amgr.Good(na)
+	}
+	return amgr.HaveAddress(na)
+}
+
+// AddressCounts returns the number of addresses in the new and tried tables of
+// the address manager for each network.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) AddressCounts() map[addrmgr.NetworkType]addrmgr.AddressCounts {
+	return cm.server.addrManager.NetworkCounts()
+}
+
+// BanSubnet bans the provided subnet until the passed time and disconnects all
+// peers with an address within it.  Attempting to ban a subnet which is
+// already banned will return an error.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) BanSubnet(subnet *net.IPNet, until time.Time) error {
+	if err := cm.server.banList.Ban(subnet, until); err != nil {
+		return err
+	}
+
+	// Disconnect the matching peers until none are left.  An error is
+	// returned once no more peers are found.
+	cmp := func(sp *serverPeer) bool {
+		host, _, err := net.SplitHostPort(sp.Addr())
+		return err == nil && subnet.Contains(net.ParseIP(host))
+	}
+	for {
+		replyChan := make(chan error)
+		cm.server.query <- disconnectNodeMsg{cmp: cmp, reply: replyChan}
+		if err := <-replyChan; err != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// UnbanSubnet removes the ban of the provided subnet.  Attempting to unban a
+// subnet which is not banned will return an error.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) UnbanSubnet(subnet *net.IPNet) error {
+	return cm.server.banList.Unban(subnet)
+}
+
+// BannedSubnets returns all of the subnets which are currently banned.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) BannedSubnets() []bannedSubnet {
+	return cm.server.banList.Bans()
+}
+
+// ClearBanned removes all bans.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverConnManager interface implementation.
+func (cm *rpcConnManager) ClearBanned() error {
+	return cm.server.banList.Clear()
 }
 
 // RelayTransactions generates and relays inventory vectors for all of the
-// passed transactions to all connected peers.
+// passed transactions to all connected peers.  The transactions, which were
+// just accepted to the memory pool, are published to ZMQ subscribers as well.
 func (cm *rpcConnManager) RelayTransactions(txns []*mempool.TxDesc) {
 	cm.server.relayTransactions(txns)
+	if cm.server.zmqNotifier != nil {
+		cm.server.zmqNotifier.NotifyNewTransactions(txns)
+	}
 }
 
 // rpcSyncMgr provides a block manager for use with the RPC server and
@@ -267,6 +369,14 @@ func (b *rpcSyncMgr) SyncPeerID() int32 {
 	return b.blockMgr.SyncPeerID()
 }
 
+// SyncStatus returns the progress of the chain synchronization.
+//
+// This function is safe for concurrent access and is part of the
+// rpcserverSyncManager interface implementation.
+func (b *rpcSyncMgr) SyncStatus() *syncStatus {
+	return b.blockMgr.SyncStatus()
+}
+
 // LocateBlocks returns the hashes of the blocks after the first known block in
 // the provided locators until the provided stop hash or the current tip is
 // reached, up to a max of wire.MaxBlockHeadersPerMsg hashes.
@@ -97,7 +97,7 @@ func (c *Client) GetBlockAsync(blockHash *chainhash.Hash) FutureGetBlockResult {
 		hash = blockHash.String()
 	}
 
-	cmd := btcjson.NewGetBlockCmd(hash, btcjson.Bool(false), nil)
+	cmd := btcjson.NewGetBlockCmd(hash, btcjson.Bool(false), nil, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -141,7 +141,7 @@ func (c *Client) GetBlockVerboseAsync(blockHash *chainhash.Hash) FutureGetBlockV
 		hash = blockHash.String()
 	}
 
-	cmd := btcjson.NewGetBlockCmd(hash, btcjson.Bool(true), nil)
+	cmd := btcjson.NewGetBlockCmd(hash, btcjson.Bool(true), nil, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -165,7 +165,7 @@ func (c *Client) GetBlockVerboseTxAsync(blockHash *chainhash.Hash) FutureGetBloc
 		hash = blockHash.String()
 	}
 
-	cmd := btcjson.NewGetBlockCmd(hash, btcjson.Bool(true), btcjson.Bool(true))
+	cmd := btcjson.NewGetBlockCmd(hash, btcjson.Bool(true), btcjson.Bool(true), nil)
 	return c.sendCmd(cmd)
 }
 
@@ -503,7 +503,7 @@ func (r FutureGetRawMempoolResult) Receive() ([]*chainhash.Hash, error) {
 //
 // See GetRawMempool for the blocking version and more details.
 func (c *Client) GetRawMempoolAsync() FutureGetRawMempoolResult {
-	cmd := btcjson.NewGetRawMempoolCmd(btcjson.Bool(false))
+	cmd := btcjson.NewGetRawMempoolCmd(btcjson.Bool(false), nil)
 	return c.sendCmd(cmd)
 }
 
@@ -544,7 +544,7 @@ func (r FutureGetRawMempoolVerboseResult) Receive() (map[string]btcjson.GetRawMe
 //
 // See GetRawMempoolVerbose for the blocking version and more details.
 func (c *Client) GetRawMempoolVerboseAsync() FutureGetRawMempoolVerboseResult {
-	cmd := btcjson.NewGetRawMempoolCmd(btcjson.Bool(true))
+	cmd := btcjson.NewGetRawMempoolCmd(btcjson.Bool(true), nil)
 	return c.sendCmd(cmd)
 }
 
@@ -820,19 +820,20 @@ func (r FutureGetCFilterResult) Receive() (*wire.MsgCFilter, error) {
 // returned instance.
 //
 // See GetCFilter for the blocking version and more details.
-func (c *Client) GetCFilterAsync(blockHash *chainhash.Hash, extended bool) FutureGetCFilterResult {
+func (c *Client) GetCFilterAsync(blockHash *chainhash.Hash, filterType string) FutureGetCFilterResult {
 	hash := ""
 	if blockHash != nil {
 		hash = blockHash.String()
 	}
 
-	cmd := btcjson.NewGetCFilterCmd(hash, extended)
+	cmd := btcjson.NewGetCFilterCmd(hash, &filterType)
 	return c.sendCmd(cmd)
 }
 
-// GetCFilter returns a raw filter from the server given its block hash.
-func (c *Client) GetCFilter(blockHash *chainhash.Hash, extended bool) (*wire.MsgCFilter, error) {
-	return c.GetCFilterAsync(blockHash, extended).Receive()
+// GetCFilter returns a raw filter of the provided type, such as
+// btcjson.CFilterTypeBasic, from the server given its block hash.
+func (c *Client) GetCFilter(blockHash *chainhash.Hash, filterType string) (*wire.MsgCFilter, error) {
+	return c.GetCFilterAsync(blockHash, filterType).Receive()
 }
 
 // FutureGetCFilterHeaderResult is a future promise to deliver the result of a
@@ -875,18 +876,18 @@ func (r FutureGetCFilterHeaderResult) Receive() (*wire.MsgCFHeaders, error) {
 // on the returned instance.
 //
 // See GetCFilterHeader for the blocking version and more details.
-func (c *Client) GetCFilterHeaderAsync(blockHash *chainhash.Hash, extended bool) FutureGetCFilterHeaderResult {
+func (c *Client) GetCFilterHeaderAsync(blockHash *chainhash.Hash, filterType string) FutureGetCFilterHeaderResult {
 	hash := ""
 	if blockHash != nil {
 		hash = blockHash.String()
 	}
 
-	cmd := btcjson.NewGetCFilterHeaderCmd(hash, extended)
+	cmd := btcjson.NewGetCFilterHeaderCmd(hash, &filterType)
 	return c.sendCmd(cmd)
 }
 
-// GetCFilterHeader returns a raw filter header from the server given its block
-// hash.
-func (c *Client) GetCFilterHeader(blockHash *chainhash.Hash, extended bool) (*wire.MsgCFHeaders, error) {
-	return c.GetCFilterHeaderAsync(blockHash, extended).Receive()
+// GetCFilterHeader returns a raw filter header of the provided type, such as
+// btcjson.CFilterTypeBasic, from the server given its block hash.
+func (c *Client) GetCFilterHeader(blockHash *chainhash.Hash, filterType string) (*wire.MsgCFHeaders, error) {
+	return c.GetCFilterHeaderAsync(blockHash, filterType).Receive()
 }
@@ -22,9 +22,10 @@ import (
 // DebugLevelAsync RPC invocation (or an applicable error).
 type FutureDebugLevelResult chan *response
 
-// Receive waits for the response promised by the future and returns the result
-// of setting the debug logging level to the passed level specification or the
-// list of of the available subsystems for the special keyword 'show'.
+// Receive waits for the response promised by the future and returns the
+// resulting levels of all subsystems after setting the debug logging level to
+// the passed level specification or the list of of the available subsystems for
+// the special keyword 'show'.
 func (r FutureDebugLevelResult) Receive() (string, error) {
 	res, err := receiveFuture(r)
 	if err != nil {
@@ -53,7 +54,8 @@ func (c *Client) DebugLevelAsync(levelSpec string) FutureDebugLevelResult {
 }
 
 // DebugLevel dynamically sets the debug logging level to the passed level
-// specification.
+// specification and returns the resulting levels of all subsystems in the same
+// form.
 //
 // The levelspec can be either a debug level or of the form:
 // 	<subsystem>=<level>,<subsystem2>=<level2>,...
@@ -249,6 +249,9 @@ func (c *Client) trackRegisteredNtfns(cmd interface{}) {
 
 		}
 
+	case *btcjson.NotifyMempoolEvictedCmd:
+		c.ntfnState.notifyEvicted = true
+
 	case *btcjson.NotifySpentCmd:
 		for _, op := range bcmd.OutPoints {
 			c.ntfnState.notifySpent[op] = struct{}{}
@@ -521,6 +524,14 @@ func (c *Client) reregisterNtfns() error {
 		}
 	}
 
+	// Reregister notifymempoolevicted if needed.
+	if stateCopy.notifyEvicted {
+		log.Debugf("Reregistering [notifymempoolevicted]")
+		if err := c.NotifyMempoolEvicted(); err != nil {
+			return err
+		}
+	}
+
 	// Reregister the combination of all previously registered notifyspent
 	// outpoints in one command if needed.
 	nslen := len(stateCopy.notifySpent)
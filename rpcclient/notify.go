@@ -35,6 +35,7 @@ type notificationState struct {
 	notifyBlocks       bool
 	notifyNewTx        bool
 	notifyNewTxVerbose bool
+	notifyEvicted      bool
 	notifyReceived     map[string]struct{}
 	notifySpent        map[btcjson.OutPoint]struct{}
 }
@@ -45,6 +46,7 @@ func (s *notificationState) Copy() *notificationState {
 	stateCopy.notifyBlocks = s.notifyBlocks
 	stateCopy.notifyNewTx = s.notifyNewTx
 	stateCopy.notifyNewTxVerbose = s.notifyNewTxVerbose
+	stateCopy.notifyEvicted = s.notifyEvicted
 	stateCopy.notifyReceived = make(map[string]struct{})
 	for addr := range s.notifyReceived {
 		stateCopy.notifyReceived[addr] = struct{}{}
@@ -179,6 +181,12 @@ type NotificationHandlers struct {
 	// made to register for the notification and the function is non-nil.
 	OnTxAcceptedVerbose func(txDetails *btcjson.TxRawResult)
 
+	// OnMempoolEvicted is invoked when a transaction is removed from the
+	// memory pool along with the reason it was removed.  It will only be
+	// invoked if a preceding call to NotifyMempoolEvicted has been made to
+	// register for the notification and the function is non-nil.
+	OnMempoolEvicted func(hash *chainhash.Hash, reason string)
+
 	// OnBtcdConnected is invoked when a wallet connects or disconnects from
 	// ltcd.
 	//
@@ -409,6 +417,23 @@ func (c *Client) handleNotification(ntfn *rawNotification) {
 
 		c.ntfnHandlers.OnTxAcceptedVerbose(rawTx)
 
+	// OnMempoolEvicted
+	case btcjson.MempoolEvictedNtfnMethod:
+		// Ignore the notification if the client is not interested in
+		// it.
+		if c.ntfnHandlers.OnMempoolEvicted == nil {
+			return
+		}
+
+		hash, reason, err := parseMempoolEvictedNtfnParams(ntfn.Params)
+		if err != nil {
+			log.Warnf("Received invalid mempool evicted "+
+				"notification: %v", err)
+			return
+		}
+
+		c.ntfnHandlers.OnMempoolEvicted(hash, reason)
+
 	// OnBtcdConnected
 	case btcjson.BtcdConnectedNtfnMethod:
 		// Ignore the notification if the client is not interested in
@@ -775,6 +800,38 @@ func parseTxAcceptedVerboseNtfnParams(params []json.RawMessage) (*btcjson.TxRawR
 	return &rawTx, nil
 }
 
+// parseMempoolEvictedNtfnParams parses out the transaction hash and the reason
+// it was removed from the parameters of a mempoolevicted notification.
+func parseMempoolEvictedNtfnParams(params []json.RawMessage) (*chainhash.Hash,
+	string, error) {
+
+	if len(params) != 2 {
+		return nil, "", wrongNumParams(len(params))
+	}
+
+	// Unmarshal first parameter as a string.
+	var txHashStr string
+	err := json.Unmarshal(params[0], &txHashStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Unmarshal second parameter as a string.
+	var reason string
+	err = json.Unmarshal(params[1], &reason)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Decode string encoding of transaction sha.
+	txHash, err := chainhash.NewHashFromStr(txHashStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return txHash, reason, nil
+}
+
 // parseBtcdConnectedNtfnParams parses out the connection status of ltcd
 // and btcwallet from the parameters of a ltcdconnected notification.
 func parseBtcdConnectedNtfnParams(params []json.RawMessage) (bool, error) {
@@ -1037,6 +1094,54 @@ func (c *Client) NotifyNewTransactions(verbose bool) error {
 	return c.NotifyNewTransactionsAsync(verbose).Receive()
 }
 
+// FutureNotifyMempoolEvictedResult is a future promise to deliver the result
+// of a NotifyMempoolEvictedAsync RPC invocation (or an applicable error).
+type FutureNotifyMempoolEvictedResult chan *response
+
+// Receive waits for the response promised by the future and returns an error
+// if the registration was not successful.
+func (r FutureNotifyMempoolEvictedResult) Receive() error {
+	_, err := receiveFuture(r)
+	return err
+}
+
+// NotifyMempoolEvictedAsync returns an instance of a type that can be used to
+// get the result of the RPC at some future time by invoking the Receive
+// function on the returned instance.
+//
+// See NotifyMempoolEvicted for the blocking version and more details.
+//
+// NOTE: This is a ltcd extension and requires a websocket connection.
+func (c *Client) NotifyMempoolEvictedAsync() FutureNotifyMempoolEvictedResult {
+	// Not supported in HTTP POST mode.
+	if c.config.HTTPPostMode {
+		return newFutureError(ErrWebsocketsRequired)
+	}
+
+	// Ignore the notification if the client is not interested in
+	// notifications.
+	if c.ntfnHandlers == nil {
+		return newNilFutureResult()
+	}
+
+	cmd := btcjson.NewNotifyMempoolEvictedCmd()
+	return c.sendCmd(cmd)
+}
+
+// NotifyMempoolEvicted registers the client to receive notifications every time
+// a transaction is removed from the memory pool.  The notifications are
+// delivered to the notification handlers associated with the client.  Calling
+// this function has no effect if there are no notification handlers and will
+// result in an error if the client is configured to run in HTTP POST mode.
+//
+// The notifications delivered as a result of this call will be via
+// OnMempoolEvicted.
+//
+// NOTE: This is a ltcd extension and requires a websocket connection.
+func (c *Client) NotifyMempoolEvicted() error {
+	return c.NotifyMempoolEvictedAsync().Receive()
+}
+
 // FutureNotifyReceivedResult is a future promise to deliver the result of a
 // NotifyReceivedAsync RPC invocation (or an applicable error).
 //
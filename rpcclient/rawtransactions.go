@@ -102,7 +102,7 @@ func (c *Client) GetRawTransactionAsync(txHash *chainhash.Hash) FutureGetRawTran
 		hash = txHash.String()
 	}
 
-	cmd := btcjson.NewGetRawTransactionCmd(hash, btcjson.Int(0))
+	cmd := btcjson.NewGetRawTransactionCmd(hash, btcjson.VerbosityLevel(0))
 	return c.sendCmd(cmd)
 }
 
@@ -148,7 +148,7 @@ func (c *Client) GetRawTransactionVerboseAsync(txHash *chainhash.Hash) FutureGet
 		hash = txHash.String()
 	}
 
-	cmd := btcjson.NewGetRawTransactionCmd(hash, btcjson.Int(1))
+	cmd := btcjson.NewGetRawTransactionCmd(hash, btcjson.VerbosityLevel(1))
 	return c.sendCmd(cmd)
 }
 
@@ -241,11 +241,12 @@ func (r FutureCreateRawTransactionResult) Receive() (*wire.MsgTx, error) {
 func (c *Client) CreateRawTransactionAsync(inputs []btcjson.TransactionInput,
 	amounts map[ltcutil.Address]ltcutil.Amount, lockTime *int64) FutureCreateRawTransactionResult {
 
-	convertedAmts := make(map[string]float64, len(amounts))
+	convertedAmts := make(map[string]interface{}, len(amounts))
 	for addr, amount := range amounts {
 		convertedAmts[addr.String()] = amount.ToBTC()
 	}
-	cmd := btcjson.NewCreateRawTransactionCmd(inputs, convertedAmts, lockTime)
+	cmd := btcjson.NewCreateRawTransactionCmd(inputs, convertedAmts,
+		lockTime, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -296,7 +297,12 @@ func (c *Client) SendRawTransactionAsync(tx *wire.MsgTx, allowHighFees bool) Fut
 		txHex = hex.EncodeToString(buf.Bytes())
 	}
 
-	cmd := btcjson.NewSendRawTransactionCmd(txHex, &allowHighFees)
+	// Allowing high fees disables the max fee rate.
+	var maxFeeRate *btcjson.MaxFeeRate
+	if allowHighFees {
+		maxFeeRate = btcjson.FeeRateLimit(0)
+	}
+	cmd := btcjson.NewSendRawTransactionCmd(txHex, maxFeeRate, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -554,7 +560,7 @@ func (c *Client) SearchRawTransactionsAsync(address ltcutil.Address, skip, count
 	addr := address.EncodeAddress()
 	verbose := btcjson.Int(0)
 	cmd := btcjson.NewSearchRawTransactionsCmd(addr, verbose, &skip, &count,
-		nil, &reverse, &filterAddrs)
+		nil, &reverse, &filterAddrs, nil)
 	return c.sendCmd(cmd)
 }
 
@@ -607,7 +613,7 @@ func (c *Client) SearchRawTransactionsVerboseAsync(address ltcutil.Address, skip
 		prevOut = btcjson.Int(1)
 	}
 	cmd := btcjson.NewSearchRawTransactionsCmd(addr, verbose, &skip, &count,
-		prevOut, &reverse, filterAddrs)
+		prevOut, &reverse, filterAddrs, nil)
 	return c.sendCmd(cmd)
 }
 
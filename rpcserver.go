@@ -6,21 +6,28 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	crand "crypto/rand"
 	"crypto/sha256"
 	"crypto/subtle"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/big"
 	"math/rand"
 	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -28,6 +35,7 @@ import (
 	"time"
 
 	"github.com/btcsuite/websocket"
+	"github.com/ltcsuite/ltcd/addrmgr"
 	"github.com/ltcsuite/ltcd/blockchain"
 	"github.com/ltcsuite/ltcd/blockchain/indexers"
 	"github.com/ltcsuite/ltcd/btcec"
@@ -35,13 +43,17 @@ import (
 	"github.com/ltcsuite/ltcd/chaincfg"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcd/descriptor"
 	"github.com/ltcsuite/ltcd/mempool"
 	"github.com/ltcsuite/ltcd/mining"
 	"github.com/ltcsuite/ltcd/mining/cpuminer"
 	"github.com/ltcsuite/ltcd/peer"
+	"github.com/ltcsuite/ltcd/psbt"
 	"github.com/ltcsuite/ltcd/txscript"
 	"github.com/ltcsuite/ltcd/wire"
 	"github.com/ltcsuite/ltcutil"
+	"github.com/ltcsuite/ltcutil/base58"
+	"github.com/ltcsuite/ltcutil/hdkeychain"
 )
 
 // API version constants
@@ -58,6 +70,10 @@ const (
 	// is closed.
 	rpcAuthTimeoutSeconds = 10
 
+	// rpcCookieUser is the username of the credentials written to the
+	// authentication cookie, which matches Bitcoin Core.
+	rpcCookieUser = "__cookie__"
+
 	// uint256Size is the number of bytes needed to represent an unsigned
 	// 256-bit integer.
 	uint256Size = 32
@@ -73,8 +89,26 @@ const (
 	// in the memory pool.
 	gbtRegenerateSeconds = 60
 
+	// gbtLongPollTimeout is the maximum amount of time a long poll request
+	// for a block template waits for the template to become stale before
+	// the current template is returned.
+	gbtLongPollTimeout = time.Minute * 10
+
 	// maxProtocolVersion is the max protocol version the server supports.
 	maxProtocolVersion = 70002
+
+	// maxEstimateSmartFeeTarget is the maximum confirmation target accepted
+	// by the estimatesmartfee RPC.
+	maxEstimateSmartFeeTarget = 1008
+
+	// maxDeriveAddressesRange is the maximum number of addresses the
+	// deriveaddresses RPC derives from a ranged descriptor in one request.
+	maxDeriveAddressesRange = 1000000
+
+	// maxBlockHeadersPerRPC is the maximum number of block headers the
+	// getblockheaders RPC returns in one request.  Callers needing more
+	// headers iterate starting from the last header returned.
+	maxBlockHeadersPerRPC = 2000
 )
 
 var (
@@ -86,16 +120,6 @@ var (
 		"time", "transactions/add", "prevblock", "coinbase/append",
 	}
 
-	// gbtCoinbaseAux describes additional data that miners should include
-	// in the coinbase signature script.  It is declared here to avoid the
-	// overhead of creating a new object on every invocation for constant
-	// data.
-	gbtCoinbaseAux = &btcjson.GetBlockTemplateResultAux{
-		Flags: hex.EncodeToString(builderScript(txscript.
-			NewScriptBuilder().
-			AddData([]byte(mining.CoinbaseFlags)))),
-	}
-
 	// gbtCapabilities describes additional capabilities returned with a
 	// block template generated by the getblocktemplate RPC.    It is
 	// declared here to avoid the overhead of creating the slice on every
@@ -127,51 +151,97 @@ type commandHandler func(*rpcServer, interface{}, <-chan struct{}) (interface{},
 // a dependency loop.
 var rpcHandlers map[string]commandHandler
 var rpcHandlersBeforeInit = map[string]commandHandler{
-	"addnode":               handleAddNode,
-	"createrawtransaction":  handleCreateRawTransaction,
-	"debuglevel":            handleDebugLevel,
-	"decoderawtransaction":  handleDecodeRawTransaction,
-	"decodescript":          handleDecodeScript,
-	"generate":              handleGenerate,
-	"getaddednodeinfo":      handleGetAddedNodeInfo,
-	"getbestblock":          handleGetBestBlock,
-	"getbestblockhash":      handleGetBestBlockHash,
-	"getblock":              handleGetBlock,
-	"getblockchaininfo":     handleGetBlockChainInfo,
-	"getblockcount":         handleGetBlockCount,
-	"getblockhash":          handleGetBlockHash,
-	"getblockheader":        handleGetBlockHeader,
-	"getblocktemplate":      handleGetBlockTemplate,
-	"getcfilter":            handleGetCFilter,
-	"getcfilterheader":      handleGetCFilterHeader,
-	"getconnectioncount":    handleGetConnectionCount,
-	"getcurrentnet":         handleGetCurrentNet,
-	"getdifficulty":         handleGetDifficulty,
-	"getgenerate":           handleGetGenerate,
-	"gethashespersec":       handleGetHashesPerSec,
-	"getheaders":            handleGetHeaders,
-	"getinfo":               handleGetInfo,
-	"getmempoolinfo":        handleGetMempoolInfo,
-	"getmininginfo":         handleGetMiningInfo,
-	"getnettotals":          handleGetNetTotals,
-	"getnetworkhashps":      handleGetNetworkHashPS,
-	"getpeerinfo":           handleGetPeerInfo,
-	"getrawmempool":         handleGetRawMempool,
-	"getrawtransaction":     handleGetRawTransaction,
-	"gettxout":              handleGetTxOut,
-	"help":                  handleHelp,
-	"node":                  handleNode,
-	"ping":                  handlePing,
-	"searchrawtransactions": handleSearchRawTransactions,
-	"sendrawtransaction":    handleSendRawTransaction,
-	"setgenerate":           handleSetGenerate,
-	"stop":                  handleStop,
-	"submitblock":           handleSubmitBlock,
-	"uptime":                handleUptime,
-	"validateaddress":       handleValidateAddress,
-	"verifychain":           handleVerifyChain,
-	"verifymessage":         handleVerifyMessage,
-	"version":               handleVersion,
+	"addnode":                handleAddNode,
+	"addpeeraddress":         handleAddPeerAddress,
+	"analyzepsbt":            handleAnalyzePSBT,
+	"clearbanned":            handleClearBanned,
+	"converttopsbt":          handleConvertToPSBT,
+	"createrawtransaction":   handleCreateRawTransaction,
+	"debuglevel":             handleDebugLevel,
+	"decodepsbt":             handleDecodePSBT,
+	"decoderawtransaction":   handleDecodeRawTransaction,
+	"decodescript":           handleDecodeScript,
+	"deriveaddresses":        handleDeriveAddresses,
+	"disconnectnode":         handleDisconnectNode,
+	"dumptxoutset":           handleDumpTxOutSet,
+	"estimatefee":            handleEstimateFee,
+	"estimatesmartfee":       handleEstimateSmartFee,
+	"generate":               handleGenerate,
+	"generateblock":          handleGenerateBlock,
+	"generatetoaddress":      handleGenerateToAddress,
+	"generatetodescriptor":   handleGenerateToDescriptor,
+	"getaddednodeinfo":       handleGetAddedNodeInfo,
+	"getaddrmaninfo":         handleGetAddrManInfo,
+	"getbestblock":           handleGetBestBlock,
+	"getbestblockhash":       handleGetBestBlockHash,
+	"getblock":               handleGetBlock,
+	"getblockchaininfo":      handleGetBlockChainInfo,
+	"getblockcount":          handleGetBlockCount,
+	"getblockfilter":         handleGetBlockFilter,
+	"getblockhash":           handleGetBlockHash,
+	"getblockheader":         handleGetBlockHeader,
+	"getblockheaders":        handleGetBlockHeaders,
+	"getblockstats":          handleGetBlockStats,
+	"getblocktemplate":       handleGetBlockTemplate,
+	"getcacheinfo":           handleGetCacheInfo,
+	"getcfilter":             handleGetCFilter,
+	"getcfilterheader":       handleGetCFilterHeader,
+	"getchaintxstats":        handleGetChainTxStats,
+	"getconnectioncount":     handleGetConnectionCount,
+	"getcurrentnet":          handleGetCurrentNet,
+	"getdeploymentinfo":      handleGetDeploymentInfo,
+	"getdescriptorinfo":      handleGetDescriptorInfo,
+	"getdifficulty":          handleGetDifficulty,
+	"getgenerate":            handleGetGenerate,
+	"gethashespersec":        handleGetHashesPerSec,
+	"getheaders":             handleGetHeaders,
+	"getindexinfo":           handleGetIndexInfo,
+	"getinfo":                handleGetInfo,
+	"getmempoolancestors":    handleGetMempoolAncestors,
+	"getmempoolentry":        handleGetMempoolEntry,
+	"getmempooldescendants":  handleGetMempoolDescendants,
+	"getmempoolinfo":         handleGetMempoolInfo,
+	"getmininginfo":          handleGetMiningInfo,
+	"getnettotals":           handleGetNetTotals,
+	"getnetworkhashps":       handleGetNetworkHashPS,
+	"getnodeaddresses":       handleGetNodeAddresses,
+	"getpeerinfo":            handleGetPeerInfo,
+	"getrawmempool":          handleGetRawMempool,
+	"getrawtransaction":      handleGetRawTransaction,
+	"getsyncstatus":          handleGetSyncStatus,
+	"gettxout":               handleGetTxOut,
+	"gettxoutproof":          handleGetTxOutProof,
+	"gettxoutsetinfo":        handleGetTxOutSetInfo,
+	"help":                   handleHelp,
+	"invalidateblock":        handleInvalidateBlock,
+	"listbanned":             handleListBanned,
+	"loadtxoutset":           handleLoadTxOutSet,
+	"node":                   handleNode,
+	"ping":                   handlePing,
+	"pruneblockchain":        handlePruneBlockChain,
+	"reconsiderblock":        handleReconsiderBlock,
+	"scantxoutset":           handleScanTxOutSet,
+	"searchrawtransactions":  handleSearchRawTransactions,
+	"sendrawtransaction":     handleSendRawTransaction,
+	"setban":                 handleSetBan,
+	"setgenerate":            handleSetGenerate,
+	"setminingflags":         handleSetMiningFlags,
+	"setversionbit":          handleSetVersionBit,
+	"signmessagewithprivkey": handleSignMessageWithPrivKey,
+	"stop":                   handleStop,
+	"submitblock":            handleSubmitBlock,
+	"submitpackage":          handleSubmitPackage,
+	"testmempoolaccept":      handleTestMempoolAccept,
+	"uptime":                 handleUptime,
+	"utxoupdatepsbt":         handleUtxoUpdatePSBT,
+	"validateaddress":        handleValidateAddress,
+	"verifychain":            handleVerifyChain,
+	"verifymessage":          handleVerifyMessage,
+	"verifytxoutproof":       handleVerifyTxOutProof,
+	"version":                handleVersion,
+	"waitforblock":           handleWaitForBlock,
+	"waitforblockheight":     handleWaitForBlockHeight,
+	"waitfornewblock":        handleWaitForNewBlock,
 }
 
 // list of commands that we recognize, but for which ltcd has no support because
@@ -194,7 +264,6 @@ var rpcAskWallet = map[string]struct{}{
 	"getreceivedbyaccount":   {},
 	"getreceivedbyaddress":   {},
 	"gettransaction":         {},
-	"gettxoutsetinfo":        {},
 	"getunconfirmedbalance":  {},
 	"getwalletinfo":          {},
 	"importprivkey":          {},
@@ -224,15 +293,62 @@ var rpcAskWallet = map[string]struct{}{
 
 // Commands that are currently unimplemented, but should ultimately be.
 var rpcUnimplemented = map[string]struct{}{
-	"estimatefee":      {},
 	"estimatepriority": {},
 	"getchaintips":     {},
-	"getmempoolentry":  {},
 	"getnetworkinfo":   {},
 	"getwork":          {},
-	"invalidateblock":  {},
 	"preciousblock":    {},
-	"reconsiderblock":  {},
+}
+
+// rpcScope is the set of methods an RPC user is permitted to call.  A nil scope
+// permits all methods.
+type rpcScope map[string]struct{}
+
+// permits returns whether or not the scope permits calling the passed method.
+func (scope rpcScope) permits(method string) bool {
+	if scope == nil {
+		return true
+	}
+	_, ok := scope[method]
+	return ok
+}
+
+// Roles which may be granted to the RPC users defined with scopes.  The full
+// and limited roles match the access of the rpcuser and rpclimituser users.
+const (
+	rpcRoleFull     = "full"
+	rpcRoleLimited  = "limited"
+	rpcRoleReadOnly = "readonly"
+)
+
+// rpcLimitedStateChanging houses the commands available to a limited user which
+// change the state of the server, which the readonly role does not permit.
+var rpcLimitedStateChanging = map[string]struct{}{
+	"sendrawtransaction": {},
+	"submitblock":        {},
+	"submitpackage":      {},
+}
+
+// rpcRoleScope returns the scope of the passed role and whether or not the
+// role exists.
+func rpcRoleScope(role string) (rpcScope, bool) {
+	switch role {
+	case rpcRoleFull:
+		return nil, true
+
+	case rpcRoleLimited:
+		return rpcLimited, true
+
+	case rpcRoleReadOnly:
+		scope := make(rpcScope, len(rpcLimited))
+		for method := range rpcLimited {
+			if _, ok := rpcLimitedStateChanging[method]; !ok {
+				scope[method] = struct{}{}
+			}
+		}
+		return scope, true
+	}
+	return nil, false
 }
 
 // Commands that are available to a limited user
@@ -251,33 +367,59 @@ var rpcLimited = map[string]struct{}{
 	"help": {},
 
 	// HTTP/S-only commands
-	"createrawtransaction":  {},
-	"decoderawtransaction":  {},
-	"decodescript":          {},
-	"getbestblock":          {},
-	"getbestblockhash":      {},
-	"getblock":              {},
-	"getblockcount":         {},
-	"getblockhash":          {},
-	"getblockheader":        {},
-	"getcfilter":            {},
-	"getcfilterheader":      {},
-	"getcurrentnet":         {},
-	"getdifficulty":         {},
-	"getheaders":            {},
-	"getinfo":               {},
-	"getnettotals":          {},
-	"getnetworkhashps":      {},
-	"getrawmempool":         {},
-	"getrawtransaction":     {},
-	"gettxout":              {},
-	"searchrawtransactions": {},
-	"sendrawtransaction":    {},
-	"submitblock":           {},
-	"uptime":                {},
-	"validateaddress":       {},
-	"verifymessage":         {},
-	"version":               {},
+	"analyzepsbt":            {},
+	"converttopsbt":          {},
+	"createrawtransaction":   {},
+	"decodepsbt":             {},
+	"decoderawtransaction":   {},
+	"decodescript":           {},
+	"deriveaddresses":        {},
+	"estimatefee":            {},
+	"estimatesmartfee":       {},
+	"getaddrmaninfo":         {},
+	"getbestblock":           {},
+	"getbestblockhash":       {},
+	"getblock":               {},
+	"getblockcount":          {},
+	"getblockfilter":         {},
+	"getblockhash":           {},
+	"getblockheader":         {},
+	"getblockheaders":        {},
+	"getblockstats":          {},
+	"getcacheinfo":           {},
+	"getcfilter":             {},
+	"getcfilterheader":       {},
+	"getchaintxstats":        {},
+	"getcurrentnet":          {},
+	"getdeploymentinfo":      {},
+	"getdescriptorinfo":      {},
+	"getdifficulty":          {},
+	"getheaders":             {},
+	"getindexinfo":           {},
+	"getinfo":                {},
+	"getmempoolancestors":    {},
+	"getmempoolentry":        {},
+	"getmempooldescendants":  {},
+	"getnettotals":           {},
+	"getnetworkhashps":       {},
+	"getrawmempool":          {},
+	"getrawtransaction":      {},
+	"getsyncstatus":          {},
+	"gettxout":               {},
+	"searchrawtransactions":  {},
+	"sendrawtransaction":     {},
+	"signmessagewithprivkey": {},
+	"submitblock":            {},
+	"submitpackage":          {},
+	"testmempoolaccept":      {},
+	"uptime":                 {},
+	"utxoupdatepsbt":         {},
+	"validateaddress":        {},
+	"verifymessage":          {},
+	"version":                {},
+	"waitforblock":           {},
+	"waitforblockheight":     {},
+	"waitfornewblock":        {},
 }
 
 // builderScript is a convenience function which is used for hard-coded scripts
@@ -323,25 +465,50 @@ func rpcNoTxInfoError(txHash *chainhash.Hash) *btcjson.RPCError {
 			txHash))
 }
 
+// rpcNotInMempoolError is a convenience function for returning a nicely
+// formatted RPC error which indicates the provided transaction hash is not in
+// the memory pool.
+func rpcNotInMempoolError(txHash *chainhash.Hash) *btcjson.RPCError {
+	return btcjson.NewRPCError(btcjson.ErrRPCInvalidAddressOrKey,
+		fmt.Sprintf("Transaction %v not in mempool", txHash))
+}
+
+// rpcBlockUnavailableError is a convenience function for returning a nicely
+// formatted RPC error which indicates the block with the provided hash is not
+// available.  Blocks in the main chain which have been pruned are reported as
+// such.
+func rpcBlockUnavailableError(s *rpcServer, hash *chainhash.Hash) *btcjson.RPCError {
+	height, err := s.cfg.Chain.BlockHeightByHash(hash)
+	if err == nil && height <= s.cfg.Chain.PruneHeight() {
+		return btcjson.NewRPCError(btcjson.ErrRPCBlockPruned,
+			"Block not available (pruned data)")
+	}
+	return btcjson.NewRPCError(btcjson.ErrRPCBlockNotFound,
+		"Block not found")
+}
+
 // gbtWorkState houses state that is used in between multiple RPC invocations to
 // getblocktemplate.
 type gbtWorkState struct {
 	sync.Mutex
-	lastTxUpdate  time.Time
-	lastGenerated time.Time
-	prevHash      *chainhash.Hash
-	minTimestamp  time.Time
-	template      *mining.BlockTemplate
-	notifyMap     map[chainhash.Hash]map[int64]chan struct{}
-	timeSource    blockchain.MedianTimeSource
+	txUpdates       uint64
+	lastGenerated   time.Time
+	prevHash        *chainhash.Hash
+	minTimestamp    time.Time
+	template        *mining.BlockTemplate
+	vbAvailable     map[string]int
+	notifyMap       map[chainhash.Hash]map[uint64]chan struct{}
+	timeSource      blockchain.MedianTimeSource
+	longPollTimeout time.Duration
 }
 
 // newGbtWorkState returns a new instance of a gbtWorkState with all internal
 // fields initialized and ready to use.
 func newGbtWorkState(timeSource blockchain.MedianTimeSource) *gbtWorkState {
 	return &gbtWorkState{
-		notifyMap:  make(map[chainhash.Hash]map[int64]chan struct{}),
-		timeSource: timeSource,
+		notifyMap:       make(map[chainhash.Hash]map[uint64]chan struct{}),
+		timeSource:      timeSource,
+		longPollTimeout: gbtLongPollTimeout,
 	}
 }
 
@@ -378,7 +545,19 @@ func handleAddNode(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 		}
 	}
 
-	if err != nil {
+	switch err {
+	case nil:
+	case errNodeAlreadyAdded:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientNodeAlreadyAdded,
+			Message: "Node already added",
+		}
+	case errNodeNotAdded:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientNodeNotAdded,
+			Message: "Node has not been added",
+		}
+	default:
 		return nil, &btcjson.RPCError{
 			Code:    btcjson.ErrRPCInvalidParameter,
 			Message: err.Error(),
@@ -389,6 +568,50 @@ func handleAddNode(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 	return nil, nil
 }
 
+// handleDisconnectNode handles disconnectnode commands.
+func handleDisconnectNode(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.DisconnectNodeCmd)
+
+	// Exactly one of the address and the peer id must be provided.
+	var addr string
+	if c.Address != nil {
+		addr = *c.Address
+	}
+	if (addr == "") == (c.NodeID == nil) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Only one of address and nodeid should be provided",
+		}
+	}
+
+	var err error
+	nodeID := int32(-1)
+	if c.NodeID != nil {
+		nodeID = int32(*c.NodeID)
+		err = s.cfg.ConnMgr.DisconnectByID(nodeID)
+	} else {
+		addr = normalizeAddress(addr, s.cfg.ChainParams.DefaultPort)
+		err = s.cfg.ConnMgr.DisconnectByAddr(addr)
+	}
+	if err != nil {
+		// Persistent peers are not disconnected since the connection
+		// would be retried anyways.
+		if peerExists(s.cfg.ConnMgr, addr, nodeID) {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCMisc,
+				Message: "can't disconnect a permanent peer, use addnode remove",
+			}
+		}
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientNodeNotConnected,
+			Message: "Node not found in connected nodes",
+		}
+	}
+
+	// no data returned unless an error.
+	return nil, nil
+}
+
 // handleNode handles node commands.
 func handleNode(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.NodeCmd)
@@ -483,6 +706,96 @@ func handleNode(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return nil, nil
 }
 
+// handleSetBan handles setban commands.
+func handleSetBan(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SetBanCmd)
+
+	subnet, err := parseSubnet(c.SubNet)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCClientInvalidIPOrSubnet,
+			Message: "Invalid IP/Subnet",
+		}
+	}
+
+	switch c.SubCmd {
+	case btcjson.SBAdd:
+		// Use the default ban duration when no ban time is provided.
+		// Otherwise, the ban time is either an absolute unix time or
+		// the number of seconds from now.
+		until := time.Now().Add(cfg.BanDuration)
+		if c.BanTime != nil && *c.BanTime > 0 {
+			if c.Absolute != nil && *c.Absolute {
+				until = time.Unix(*c.BanTime, 0)
+			} else {
+				until = time.Now().Add(time.Duration(*c.BanTime) *
+					time.Second)
+			}
+		}
+		if !until.After(time.Now()) {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Ban time is in the past",
+			}
+		}
+
+		err = s.cfg.ConnMgr.BanSubnet(subnet, until)
+		if err == errAlreadyBanned {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCClientNodeAlreadyAdded,
+				Message: err.Error(),
+			}
+		}
+
+	case btcjson.SBRemove:
+		err = s.cfg.ConnMgr.UnbanSubnet(subnet)
+		if err == errNotBanned {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCClientInvalidIPOrSubnet,
+				Message: err.Error(),
+			}
+		}
+
+	default:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "invalid subcommand for setban",
+		}
+	}
+	if err != nil {
+		context := "Failed to update ban list"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	// no data returned unless an error.
+	return nil, nil
+}
+
+// handleListBanned handles listbanned commands.
+func handleListBanned(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	bans := s.cfg.ConnMgr.BannedSubnets()
+	results := make([]btcjson.ListBannedResult, 0, len(bans))
+	for _, ban := range bans {
+		results = append(results, btcjson.ListBannedResult{
+			Address:     ban.Subnet.String(),
+			BanCreated:  ban.Created.Unix(),
+			BannedUntil: ban.Until.Unix(),
+		})
+	}
+	return results, nil
+}
+
+// handleClearBanned handles clearbanned commands.
+func handleClearBanned(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	if err := s.cfg.ConnMgr.ClearBanned(); err != nil {
+		context := "Failed to clear ban list"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	// no data returned unless an error.
+	return nil, nil
+}
+
 // peerExists determines if a certain peer is currently connected given
 // information about all currently connected peers. Peer existence is
 // determined using either a target address or node id.
@@ -507,6 +820,10 @@ func messageToHex(msg wire.Message) (string, error) {
 	return hex.EncodeToString(buf.Bytes()), nil
 }
 
+// maxReplaceableSequence is the maximum sequence number of an input which
+// signals replaceability as defined by BIP 125.
+const maxReplaceableSequence = wire.MaxTxInSequenceNum - 2
+
 // handleCreateRawTransaction handles createrawtransaction commands.
 func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.CreateRawTransactionCmd)
@@ -521,7 +838,10 @@ func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 	}
 
 	// Add all transaction inputs to a new transaction after performing
-	// some validity checks.
+	// some validity checks.  The inputs signal replaceability when
+	// requested and otherwise locktime-activate the inputs when a non-zero
+	// locktime is given unless they have an explicit sequence number.
+	replaceable := c.Replaceable != nil && *c.Replaceable
 	mtx := wire.NewMsgTx(wire.TxVersion)
 	for _, input := range c.Inputs {
 		txHash, err := chainhash.NewHashFromStr(input.Txid)
@@ -531,18 +851,67 @@ func handleCreateRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 
 		prevOut := wire.NewOutPoint(txHash, input.Vout)
 		txIn := wire.NewTxIn(prevOut, []byte{}, nil)
-		if c.LockTime != nil && *c.LockTime != 0 {
+		switch {
+		case replaceable:
+			txIn.Sequence = maxReplaceableSequence
+		case c.LockTime != nil && *c.LockTime != 0:
 			txIn.Sequence = wire.MaxTxInSequenceNum - 1
 		}
+		if input.Sequence != nil {
+			sequence := *input.Sequence
+			if sequence < 0 ||
+				sequence > int64(wire.MaxTxInSequenceNum) {
+
+				return nil, &btcjson.RPCError{
+					Code:    btcjson.ErrRPCInvalidParameter,
+					Message: "Sequence number out of range",
+				}
+			}
+			if replaceable && sequence > int64(maxReplaceableSequence) {
+				return nil, &btcjson.RPCError{
+					Code: btcjson.ErrRPCInvalidParameter,
+					Message: "Invalid parameter combination: " +
+						"sequence number contradicts " +
+						"replaceable option",
+				}
+			}
+			txIn.Sequence = uint32(sequence)
+		}
 		mtx.AddTxIn(txIn)
 	}
 
 	// Add all transaction outputs to the transaction after performing
 	// some validity checks.
 	params := s.cfg.ChainParams
-	for encodedAddr, amount := range c.Amounts {
+	for encodedAddr, value := range c.Amounts {
+		// The data key creates a provably-prunable output carrying the
+		// provided hex-encoded data.
+		if encodedAddr == "data" {
+			hexData, ok := value.(string)
+			if !ok {
+				return nil, &btcjson.RPCError{
+					Code:    btcjson.ErrRPCType,
+					Message: "Data must be a hex-encoded string",
+				}
+			}
+			data, err := hex.DecodeString(hexData)
+			if err != nil {
+				return nil, rpcDecodeHexError(hexData)
+			}
+			pkScript, err := txscript.NullDataScript(data)
+			if err != nil {
+				return nil, &btcjson.RPCError{
+					Code:    btcjson.ErrRPCInvalidParameter,
+					Message: "Invalid data: " + err.Error(),
+				}
+			}
+			mtx.AddTxOut(wire.NewTxOut(0, pkScript))
+			continue
+		}
+
 		// Ensure amount is in the valid range for monetary amounts.
-		if amount <= 0 || amount > ltcutil.MaxSatoshi {
+		amount, ok := value.(float64)
+		if !ok || amount <= 0 || amount > ltcutil.MaxSatoshi {
 			return nil, &btcjson.RPCError{
 				Code:    btcjson.ErrRPCType,
 				Message: "Invalid amount",
@@ -630,7 +999,7 @@ func handleDebugLevel(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 		}
 	}
 
-	return "Done.", nil
+	return logLevelSpec(), nil
 }
 
 // createVinList returns a slice of JSON objects for the inputs of the passed
@@ -793,14 +1162,19 @@ func handleDecodeRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan
 	}
 
 	// Create and return the result.
-	txReply := btcjson.TxRawDecodeResult{
+	return createTxRawDecodeResult(&mtx, s.cfg.ChainParams), nil
+}
+
+// createTxRawDecodeResult converts the passed transaction to the JSON object
+// returned by the decoderawtransaction command.
+func createTxRawDecodeResult(mtx *wire.MsgTx, chainParams *chaincfg.Params) btcjson.TxRawDecodeResult {
+	return btcjson.TxRawDecodeResult{
 		Txid:     mtx.TxHash().String(),
 		Version:  mtx.Version,
 		Locktime: mtx.LockTime,
-		Vin:      createVinList(&mtx),
-		Vout:     createVoutList(&mtx, s.cfg.ChainParams, nil),
+		Vin:      createVinList(mtx),
+		Vout:     createVoutList(mtx, chainParams, nil),
 	}
-	return txReply, nil
 }
 
 // handleDecodeScript handles decodescript commands.
@@ -842,110 +1216,1063 @@ func handleDecodeScript(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	reply := btcjson.DecodeScriptResult{
 		Asm:       disbuf,
 		ReqSigs:   int32(reqSigs),
-		Type:      scriptClass.String(),
+		Type:      decodeScriptType(script, scriptClass),
 		Addresses: addresses,
 	}
 	if scriptClass != txscript.ScriptHashTy {
 		reply.P2sh = p2sh.EncodeAddress()
 	}
+
+	// Scripts which are not already segwit programs or data carriers can
+	// also be paid to by a segwit program.
+	switch {
+	case scriptClass == txscript.PubKeyTy,
+		scriptClass == txscript.PubKeyHashTy,
+		scriptClass == txscript.MultiSigTy,
+		scriptClass == txscript.NonStandardTy &&
+			!txscript.IsWitnessProgram(script):
+
+		segwit, err := decodeScriptSegwit(script, scriptClass,
+			s.cfg.ChainParams)
+		if err != nil {
+			context := "Failed to convert script to segwit program"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		reply.Segwit = segwit
+	}
 	return reply, nil
 }
 
-// handleGenerate handles generate commands.
-func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	// Respond with an error if there are no addresses to pay the
-	// created blocks to.
-	if len(cfg.miningAddrs) == 0 {
-		return nil, &btcjson.RPCError{
-			Code: btcjson.ErrRPCInternal.Code,
-			Message: "No payment addresses specified " +
-				"via --miningaddr",
-		}
+// Witness versions and program sizes of the outputs which are witness
+// programs but not one of the standard script classes.
+const (
+	taprootWitnessVersion = 1
+	taprootProgramSize    = 32
+
+	// mwebHogAddrWitnessVersion is the witness version of the outputs of
+	// the integrating transactions which hold the coins of the MimbleWimble
+	// Extension Block (MWEB), while mwebPegInWitnessVersion is the version
+	// of the outputs which peg coins into it.
+	mwebHogAddrWitnessVersion = 8
+	mwebPegInWitnessVersion   = 9
+	mwebProgramSize           = 32
+)
+
+// decodeScriptType returns the type of the passed script, which is the name of
+// its script class except for witness programs of a version or size that the
+// script class does not recognize.
+func decodeScriptType(script []byte, class txscript.ScriptClass) string {
+	if class != txscript.NonStandardTy || !txscript.IsWitnessProgram(script) {
+		return class.String()
+	}
+	version, program, err := txscript.ExtractWitnessProgramInfo(script)
+	if err != nil {
+		return class.String()
 	}
 
-	// Respond with an error if there's virtually 0 chance of mining a block
-	// with the CPU.
-	if !s.cfg.ChainParams.GenerateSupported {
-		return nil, &btcjson.RPCError{
-			Code: btcjson.ErrRPCDifficulty,
-			Message: fmt.Sprintf("No support for `generate` on "+
-				"the current network, %s, as it's unlikely to "+
-				"be possible to main a block with the CPU.",
-				s.cfg.ChainParams.Net),
-		}
+	switch {
+	case version == taprootWitnessVersion &&
+		len(program) == taprootProgramSize:
+		return "witness_v1_taproot"
+
+	case version == mwebHogAddrWitnessVersion &&
+		len(program) == mwebProgramSize:
+		return "witness_mweb_hogaddr"
+
+	case version == mwebPegInWitnessVersion &&
+		len(program) == mwebProgramSize:
+		return "witness_mweb_pegin"
 	}
+	return "witness_unknown"
+}
 
-	c := cmd.(*btcjson.GenerateCmd)
+// decodeScriptSegwit returns the segwit program which pays to the passed
+// script along with the pay-to-script-hash address wrapping it.  Public keys
+// are paid to by their key hash while all other scripts are paid to by their
+// script hash.  Nil is returned when the script contains uncompressed public
+// keys since they can't be spent from segwit programs.
+func decodeScriptSegwit(script []byte, class txscript.ScriptClass,
+	params *chaincfg.Params) (*btcjson.DecodeScriptSegwit, error) {
 
-	// Respond with an error if the client is requesting 0 blocks to be generated.
-	if c.NumBlocks == 0 {
-		return nil, &btcjson.RPCError{
-			Code:    btcjson.ErrRPCInternal.Code,
-			Message: "Please request a nonzero number of blocks to generate.",
+	var pubKeys [][]byte
+	if class == txscript.PubKeyTy || class == txscript.MultiSigTy {
+		var err error
+		pubKeys, err = txscript.PushedData(script)
+		if err != nil {
+			return nil, err
+		}
+		for _, pubKey := range pubKeys {
+			if len(pubKey) != btcec.PubKeyBytesLenCompressed {
+				return nil, nil
+			}
 		}
 	}
 
-	// Create a reply
-	reply := make([]string, c.NumBlocks)
+	var addr ltcutil.Address
+	var err error
+	switch class {
+	case txscript.PubKeyTy:
+		addr, err = ltcutil.NewAddressWitnessPubKeyHash(
+			ltcutil.Hash160(pubKeys[0]), params)
+	case txscript.PubKeyHashTy:
+		addr, err = ltcutil.NewAddressWitnessPubKeyHash(script[3:23],
+			params)
+	default:
+		scriptHash := sha256.Sum256(script)
+		addr, err = ltcutil.NewAddressWitnessScriptHash(scriptHash[:],
+			params)
+	}
+	if err != nil {
+		return nil, err
+	}
+	segwitScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return nil, err
+	}
+	p2shSegwit, err := ltcutil.NewAddressScriptHash(segwitScript, params)
+	if err != nil {
+		return nil, err
+	}
 
-	blockHashes, err := s.cfg.CPUMiner.GenerateNBlocks(c.NumBlocks)
+	disbuf, _ := txscript.DisasmString(segwitScript)
+	segwitClass, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(
+		segwitScript, params)
+	addresses := make([]string, len(addrs))
+	for i, addr := range addrs {
+		addresses[i] = addr.EncodeAddress()
+	}
+	return &btcjson.DecodeScriptSegwit{
+		Asm:        disbuf,
+		Hex:        hex.EncodeToString(segwitScript),
+		ReqSigs:    int32(reqSigs),
+		Type:       segwitClass.String(),
+		Addresses:  addresses,
+		P2shSegwit: p2shSegwit.EncodeAddress(),
+	}, nil
+}
+
+// decodePSBT parses the passed base64-encoded partially signed transaction.
+func decodePSBT(encoded string) (*psbt.Packet, error) {
+	p, err := psbt.ParseBase64(encoded)
 	if err != nil {
 		return nil, &btcjson.RPCError{
-			Code:    btcjson.ErrRPCInternal.Code,
-			Message: err.Error(),
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "TX decode failed: " + err.Error(),
 		}
 	}
+	return p, nil
+}
 
-	// Mine the correct number of blocks, assigning the hex representation of the
-	// hash of each one to its place in the reply.
-	for i, hash := range blockHashes {
-		reply[i] = hash.String()
-	}
+// createPSBTScript returns a JSON object for the passed script of a partially
+// signed transaction.
+func createPSBTScript(script []byte) *btcjson.PSBTScript {
+	// The disassembled string will contain [error] inline if the script
+	// doesn't fully parse, so ignore the error here.
+	disbuf, _ := txscript.DisasmString(script)
 
-	return reply, nil
+	return &btcjson.PSBTScript{
+		Asm:  disbuf,
+		Hex:  hex.EncodeToString(script),
+		Type: txscript.GetScriptClass(script).String(),
+	}
 }
 
-// handleGetAddedNodeInfo handles getaddednodeinfo commands.
-func handleGetAddedNodeInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	c := cmd.(*btcjson.GetAddedNodeInfoCmd)
+// formatFingerprint returns the hex encoding of the passed master key
+// fingerprint in the byte order it is serialized with.
+func formatFingerprint(fingerprint uint32) string {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], fingerprint)
+	return hex.EncodeToString(b[:])
+}
 
-	// Retrieve a list of persistent (added) peers from the server and
-	// filter the list of peers per the specified address (if any).
-	peers := s.cfg.ConnMgr.PersistentPeers()
-	if c.Node != nil {
-		node := *c.Node
-		found := false
-		for i, peer := range peers {
-			if peer.ToPeer().Addr() == node {
-				peers = peers[i : i+1]
-				found = true
-			}
-		}
-		if !found {
-			return nil, &btcjson.RPCError{
-				Code:    btcjson.ErrRPCClientNodeNotAdded,
-				Message: "Node has not been added",
-			}
+// formatBip32Path returns the passed BIP0032 derivation path in the form
+// m/0'/1/2 where hardened child indexes are marked with an apostrophe.
+func formatBip32Path(path []uint32) string {
+	var buf bytes.Buffer
+	buf.WriteString("m")
+	for _, index := range path {
+		if index >= hdkeychain.HardenedKeyStart {
+			fmt.Fprintf(&buf, "/%d'", index-hdkeychain.HardenedKeyStart)
+			continue
 		}
+		fmt.Fprintf(&buf, "/%d", index)
 	}
+	return buf.String()
+}
 
-	// Without the dns flag, the result is just a slice of the addresses as
-	// strings.
-	if !c.DNS {
-		results := make([]string, 0, len(peers))
-		for _, peer := range peers {
-			results = append(results, peer.ToPeer().Addr())
-		}
-		return results, nil
+// createPSBTBip32Derivs returns a slice of JSON objects for the passed BIP0032
+// derivations of a partially signed transaction.
+func createPSBTBip32Derivs(derivations []*psbt.Bip32Derivation) []btcjson.PSBTBip32Deriv {
+	if len(derivations) == 0 {
+		return nil
 	}
 
-	// With the dns flag, the result is an array of JSON objects which
-	// include the result of DNS lookups for each peer.
-	results := make([]*btcjson.GetAddedNodeInfoResult, 0, len(peers))
-	for _, rpcPeer := range peers {
-		// Set the "address" of the peer which could be an ip address
-		// or a domain name.
-		peer := rpcPeer.ToPeer()
+	results := make([]btcjson.PSBTBip32Deriv, 0, len(derivations))
+	for _, d := range derivations {
+		results = append(results, btcjson.PSBTBip32Deriv{
+			PubKey:            hex.EncodeToString(d.PubKey),
+			MasterFingerprint: formatFingerprint(d.MasterKeyFingerprint),
+			Path:              formatBip32Path(d.Path),
+		})
+	}
+	return results
+}
+
+// createPSBTUnknowns splits the passed unknown entries of a map of a partially
+// signed transaction into the JSON objects for the proprietary entries and a
+// map of the hex-encoded keys to the hex-encoded values of all others.
+func createPSBTUnknowns(unknowns []*psbt.Unknown) ([]btcjson.PSBTProprietary, map[string]string) {
+	var proprietary []btcjson.PSBTProprietary
+	var others map[string]string
+	for _, u := range unknowns {
+		if prop, ok := u.Proprietary(); ok {
+			proprietary = append(proprietary, btcjson.PSBTProprietary{
+				Identifier: hex.EncodeToString(prop.Identifier),
+				Subtype:    prop.Subtype,
+				Key:        hex.EncodeToString(u.Key),
+				Value:      hex.EncodeToString(u.Value),
+			})
+			continue
+		}
+
+		if others == nil {
+			others = make(map[string]string)
+		}
+		others[hex.EncodeToString(u.Key)] = hex.EncodeToString(u.Value)
+	}
+	return proprietary, others
+}
+
+// sigHashTypeNames maps the signature hash types to their names as used by the
+// decodepsbt command.
+var sigHashTypeNames = map[txscript.SigHashType]string{
+	txscript.SigHashAll:                                   "ALL",
+	txscript.SigHashNone:                                  "NONE",
+	txscript.SigHashSingle:                                "SINGLE",
+	txscript.SigHashAll | txscript.SigHashAnyOneCanPay:    "ALL|ANYONECANPAY",
+	txscript.SigHashNone | txscript.SigHashAnyOneCanPay:   "NONE|ANYONECANPAY",
+	txscript.SigHashSingle | txscript.SigHashAnyOneCanPay: "SINGLE|ANYONECANPAY",
+}
+
+// createDecodePSBTInput returns the JSON object for the passed input of a
+// partially signed transaction.
+func createDecodePSBTInput(in *psbt.Input, chainParams *chaincfg.Params) btcjson.DecodePSBTInput {
+	var result btcjson.DecodePSBTInput
+	if in.NonWitnessUtxo != nil {
+		txReply := createTxRawDecodeResult(in.NonWitnessUtxo, chainParams)
+		result.NonWitnessUtxo = &txReply
+	}
+	if in.WitnessUtxo != nil {
+		pkScript := in.WitnessUtxo.PkScript
+
+		// The disassembled string will contain [error] inline if the
+		// script doesn't fully parse, so ignore the error here.
+		disbuf, _ := txscript.DisasmString(pkScript)
+
+		// Ignore the error here since an error means the script
+		// couldn't parse and there is no additional information about
+		// it anyways.
+		scriptClass, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(
+			pkScript, chainParams)
+		addresses := make([]string, len(addrs))
+		for i, addr := range addrs {
+			addresses[i] = addr.EncodeAddress()
+		}
+
+		result.WitnessUtxo = &btcjson.PSBTWitnessUtxo{
+			Amount: ltcutil.Amount(in.WitnessUtxo.Value).ToBTC(),
+			ScriptPubKey: btcjson.ScriptPubKeyResult{
+				Asm:       disbuf,
+				Hex:       hex.EncodeToString(pkScript),
+				ReqSigs:   int32(reqSigs),
+				Type:      scriptClass.String(),
+				Addresses: addresses,
+			},
+		}
+	}
+	if len(in.PartialSigs) != 0 {
+		result.PartialSignatures = make(map[string]string)
+		for _, sig := range in.PartialSigs {
+			pubKey := hex.EncodeToString(sig.PubKey)
+			result.PartialSignatures[pubKey] = hex.EncodeToString(
+				sig.Signature)
+		}
+	}
+	if in.SighashType != nil {
+		hashType := txscript.SigHashType(*in.SighashType)
+		name, ok := sigHashTypeNames[hashType]
+		if !ok {
+			name = strconv.FormatUint(uint64(*in.SighashType), 10)
+		}
+		result.Sighash = name
+	}
+	if in.RedeemScript != nil {
+		result.RedeemScript = createPSBTScript(in.RedeemScript)
+	}
+	if in.WitnessScript != nil {
+		result.WitnessScript = createPSBTScript(in.WitnessScript)
+	}
+	result.Bip32Derivs = createPSBTBip32Derivs(in.Bip32Derivation)
+	if in.FinalScriptSig != nil {
+		result.FinalScriptSig = createPSBTScript(in.FinalScriptSig)
+		result.FinalScriptSig.Type = ""
+	}
+	result.FinalScriptWitness = witnessToHex(in.FinalScriptWitness)
+	result.Proprietary, result.Unknown = createPSBTUnknowns(in.Unknowns)
+
+	return result
+}
+
+// handleDecodePSBT handles decodepsbt commands.
+func handleDecodePSBT(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.DecodePSBTCmd)
+
+	p, err := decodePSBT(c.PSBT)
+	if err != nil {
+		return nil, err
+	}
+
+	params := s.cfg.ChainParams
+	result := btcjson.DecodePSBTResult{
+		Tx:          createTxRawDecodeResult(p.UnsignedTx, params),
+		GlobalXPubs: make([]btcjson.PSBTXPub, 0, len(p.XPubs)),
+		PSBTVersion: p.Version,
+		Inputs:      make([]btcjson.DecodePSBTInput, 0, len(p.Inputs)),
+		Outputs:     make([]btcjson.DecodePSBTOutput, 0, len(p.Outputs)),
+	}
+	for _, xpub := range p.XPubs {
+		checksum := chainhash.DoubleHashB(xpub.ExtendedKey)[:4]
+		encoded := base58.Encode(append(append([]byte{},
+			xpub.ExtendedKey...), checksum...))
+		result.GlobalXPubs = append(result.GlobalXPubs, btcjson.PSBTXPub{
+			XPub:              encoded,
+			MasterFingerprint: formatFingerprint(xpub.MasterKeyFingerprint),
+			Path:              formatBip32Path(xpub.Path),
+		})
+	}
+	result.Proprietary, result.Unknown = createPSBTUnknowns(p.Unknowns)
+	if result.Proprietary == nil {
+		result.Proprietary = []btcjson.PSBTProprietary{}
+	}
+	if result.Unknown == nil {
+		result.Unknown = map[string]string{}
+	}
+
+	for _, in := range p.Inputs {
+		result.Inputs = append(result.Inputs,
+			createDecodePSBTInput(in, params))
+	}
+	for _, out := range p.Outputs {
+		var outResult btcjson.DecodePSBTOutput
+		if out.RedeemScript != nil {
+			outResult.RedeemScript = createPSBTScript(out.RedeemScript)
+		}
+		if out.WitnessScript != nil {
+			outResult.WitnessScript = createPSBTScript(out.WitnessScript)
+		}
+		outResult.Bip32Derivs = createPSBTBip32Derivs(out.Bip32Derivation)
+		outResult.Proprietary, outResult.Unknown = createPSBTUnknowns(
+			out.Unknowns)
+		result.Outputs = append(result.Outputs, outResult)
+	}
+
+	// The fee is only known when the outputs spent by all of the inputs
+	// are.
+	if analysis, err := p.Analyze(); err == nil && analysis.HasFee {
+		fee := analysis.Fee.ToBTC()
+		result.Fee = &fee
+	}
+
+	return result, nil
+}
+
+// handleAnalyzePSBT handles analyzepsbt commands.
+func handleAnalyzePSBT(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.AnalyzePSBTCmd)
+
+	p, err := decodePSBT(c.PSBT)
+	if err != nil {
+		return nil, err
+	}
+
+	// Packets which are not valid have to be recreated.
+	analysis, err := p.Analyze()
+	if err != nil {
+		return &btcjson.AnalyzePSBTResult{
+			Next:  psbt.RoleCreator.String(),
+			Error: "PSBT is not valid. " + err.Error(),
+		}, nil
+	}
+
+	encodeHashes := func(hashes [][]byte) []string {
+		if len(hashes) == 0 {
+			return nil
+		}
+		encoded := make([]string, 0, len(hashes))
+		for _, hash := range hashes {
+			encoded = append(encoded, hex.EncodeToString(hash))
+		}
+		return encoded
+	}
+
+	result := &btcjson.AnalyzePSBTResult{
+		Inputs: make([]btcjson.AnalyzePSBTInput, 0, len(analysis.Inputs)),
+		Next:   analysis.Next.String(),
+	}
+	for _, a := range analysis.Inputs {
+		inResult := btcjson.AnalyzePSBTInput{
+			HasUtxo: a.HasUtxo,
+			IsFinal: a.IsFinal,
+			Next:    a.Next.String(),
+		}
+		missing := btcjson.AnalyzePSBTMissing{
+			PubKeys:       encodeHashes(a.MissingPubKeys),
+			Signatures:    encodeHashes(a.MissingSigs),
+			RedeemScript:  hex.EncodeToString(a.MissingRedeemScript),
+			WitnessScript: hex.EncodeToString(a.MissingWitnessScript),
+		}
+		if missing.PubKeys != nil || missing.Signatures != nil ||
+			missing.RedeemScript != "" || missing.WitnessScript != "" {
+
+			inResult.Missing = &missing
+		}
+		result.Inputs = append(result.Inputs, inResult)
+	}
+	if analysis.HasFee {
+		fee := analysis.Fee.ToBTC()
+		result.Fee = &fee
+	}
+
+	// The size of the signed transaction, and therefore its fee rate, is
+	// only known once all of the inputs are finalized.
+	if analysis.Next == psbt.RoleExtractor && analysis.HasFee {
+		tx, err := p.Extract()
+		if err != nil {
+			context := "Failed to extract transaction"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		vsize := mempool.GetTxVirtualSize(ltcutil.NewTx(tx))
+		feeRate := ltcutil.Amount(int64(analysis.Fee) * 1000 / vsize).ToBTC()
+		result.EstimatedVSize = vsize
+		result.EstimatedFeeRate = &feeRate
+	}
+
+	return result, nil
+}
+
+// handleConvertToPSBT handles converttopsbt commands.
+func handleConvertToPSBT(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.ConvertToPSBTCmd)
+
+	// Deserialize the transaction, only allowing or disallowing witnesses
+	// when requested.
+	hexStr := c.HexTx
+	if len(hexStr)%2 != 0 {
+		hexStr = "0" + hexStr
+	}
+	serializedTx, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return nil, rpcDecodeHexError(hexStr)
+	}
+	var mtx wire.MsgTx
+	r := bytes.NewReader(serializedTx)
+	if c.IsWitness != nil && !*c.IsWitness {
+		err = mtx.DeserializeNoWitness(r)
+	} else {
+		err = mtx.Deserialize(r)
+	}
+	if err == nil && c.IsWitness != nil && *c.IsWitness && !mtx.HasWitness() {
+		err = errors.New("transaction does not have witnesses")
+	}
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "TX decode failed: " + err.Error(),
+		}
+	}
+
+	// Discard any signatures when permitted.
+	for _, txIn := range mtx.TxIn {
+		if len(txIn.SignatureScript) == 0 && len(txIn.Witness) == 0 {
+			continue
+		}
+		if c.PermitSigData == nil || !*c.PermitSigData {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCDeserialization,
+				Message: "Inputs must not have signature " +
+					"scripts or witnesses",
+			}
+		}
+		txIn.SignatureScript = nil
+		txIn.Witness = nil
+	}
+
+	p, err := psbt.New(&mtx)
+	if err != nil {
+		context := "Failed to create PSBT"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	encoded, err := p.B64Encode()
+	if err != nil {
+		context := "Failed to encode PSBT"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return encoded, nil
+}
+
+// isSegWitOutput returns whether the passed public key script pays to a
+// witness program, either directly or nested in a pay-to-script-hash output
+// with the passed redeem script.
+func isSegWitOutput(pkScript, redeemScript []byte) bool {
+	if txscript.IsWitnessProgram(pkScript) {
+		return true
+	}
+	return txscript.IsPayToScriptHash(pkScript) &&
+		bytes.Equal(ltcutil.Hash160(redeemScript), pkScript[2:22]) &&
+		txscript.IsWitnessProgram(redeemScript)
+}
+
+// handleUtxoUpdatePSBT handles utxoupdatepsbt commands.
+func handleUtxoUpdatePSBT(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.UtxoUpdatePSBTCmd)
+
+	p, err := decodePSBT(c.PSBT)
+	if err != nil {
+		return nil, err
+	}
+
+	prevOuts, err := fetchTxPrevOuts(s, p.UnsignedTx)
+	if err != nil {
+		return nil, err
+	}
+	for i, in := range p.Inputs {
+		// Leave the inputs which already have their spent output as
+		// well as those whose spent output is unknown untouched.
+		if in.WitnessUtxo != nil || in.NonWitnessUtxo != nil {
+			continue
+		}
+		origin := &p.UnsignedTx.TxIn[i].PreviousOutPoint
+		prevOut, ok := prevOuts[*origin]
+		if !ok {
+			continue
+		}
+
+		// Only the spent output itself is needed to sign segwit
+		// inputs.
+		if isSegWitOutput(prevOut.txOut.PkScript, in.RedeemScript) {
+			txOut := prevOut.txOut
+			in.WitnessUtxo = &txOut
+			continue
+		}
+
+		// All other inputs need the full transaction which created the
+		// spent output, which is only available from the memory pool
+		// or the transaction index.
+		originTx, err := s.cfg.TxMemPool.FetchTransaction(&origin.Hash)
+		if err == nil {
+			in.NonWitnessUtxo = originTx.MsgTx()
+			continue
+		}
+		if s.cfg.TxIndex == nil {
+			continue
+		}
+		indexedTx, err := fetchIndexedTx(s, &origin.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if indexedTx != nil {
+			in.NonWitnessUtxo = indexedTx
+		}
+	}
+
+	encoded, err := p.B64Encode()
+	if err != nil {
+		context := "Failed to encode PSBT"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return encoded, nil
+}
+
+// handleDeriveAddresses handles deriveaddresses commands.
+func handleDeriveAddresses(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.DeriveAddressesCmd)
+
+	desc, err := descriptor.Parse(c.Descriptor, s.cfg.ChainParams, true)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid descriptor: " + err.Error(),
+		}
+	}
+
+	// Ranged descriptors require a range of child indexes to derive the
+	// addresses for while others describe a single address.
+	var begin, end int64
+	switch {
+	case desc.IsRange() && c.Range == nil:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Range must be specified for a ranged descriptor",
+		}
+
+	case !desc.IsRange() && c.Range != nil:
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: "Range should not be specified for an " +
+				"un-ranged descriptor",
+		}
+
+	case c.Range != nil:
+		begin, end = c.Range.Begin, c.Range.End
+		if begin < 0 || begin > end || end >= hdkeychain.HardenedKeyStart {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("Invalid range [%d, %d]",
+					begin, end),
+			}
+		}
+		if end-begin >= maxDeriveAddressesRange {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Range is too large",
+			}
+		}
+	}
+
+	addresses := make([]string, 0, end-begin+1)
+	for index := begin; index <= end; index++ {
+		addr, err := desc.Address(uint32(index))
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidAddressOrKey,
+				Message: fmt.Sprintf("Unable to derive address "+
+					"at index %d: %v", index, err),
+			}
+		}
+		addresses = append(addresses, addr.EncodeAddress())
+	}
+	return addresses, nil
+}
+
+// handleEstimateFee handles estimatefee commands.
+func handleEstimateFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.EstimateFeeCmd)
+
+	if c.NumBlocks <= 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Parameter numblocks must be positive",
+		}
+	}
+
+	// Like Bitcoin Core, respond with -1 when there is not enough data to
+	// provide an estimate.
+	feeRate, err := s.cfg.FeeEstimator.EstimateFee(uint32(c.NumBlocks))
+	if err != nil {
+		rpcsLog.Debugf("Unable to estimate fee: %v", err)
+		return -1.0, nil
+	}
+
+	return float64(feeRate), nil
+}
+
+// handleEstimateSmartFee handles estimatesmartfee commands.
+func handleEstimateSmartFee(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.EstimateSmartFeeCmd)
+
+	if c.ConfTarget < 1 || c.ConfTarget > maxEstimateSmartFeeTarget {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Invalid conf_target, must be "+
+				"between 1 - %d", maxEstimateSmartFeeTarget),
+		}
+	}
+
+	// Estimates are conservative unless explicitly requested otherwise.
+	mode := mempool.EstimateModeConservative
+	if c.EstimateMode != nil {
+		switch strings.ToUpper(*c.EstimateMode) {
+		case "UNSET", "CONSERVATIVE":
+		case "ECONOMICAL":
+			mode = mempool.EstimateModeEconomical
+		default:
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Invalid estimate_mode parameter",
+			}
+		}
+	}
+
+	// Like Bitcoin Core, report a lack of data in the result rather than
+	// as an error.
+	feeRate, blocks, err := s.cfg.FeeEstimator.EstimateSmartFee(
+		uint32(c.ConfTarget), mode)
+	if err != nil {
+		return &btcjson.EstimateSmartFeeResult{
+			FeeRate: -1,
+			Errors:  []string{err.Error()},
+			Blocks:  c.ConfTarget,
+		}, nil
+	}
+
+	return &btcjson.EstimateSmartFeeResult{
+		FeeRate: float64(feeRate),
+		Blocks:  int64(blocks),
+	}, nil
+}
+
+// handleGenerate handles generate commands.
+func handleGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// Respond with an error if there are no addresses to pay the
+	// created blocks to.
+	if len(cfg.miningAddrs) == 0 {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInternal.Code,
+			Message: "No payment addresses specified " +
+				"via --miningaddr",
+		}
+	}
+
+	// Respond with an error if there's virtually 0 chance of mining a block
+	// with the CPU.
+	if !s.cfg.ChainParams.GenerateSupported {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCDifficulty,
+			Message: fmt.Sprintf("No support for `generate` on "+
+				"the current network, %s, as it's unlikely to "+
+				"be possible to main a block with the CPU.",
+				s.cfg.ChainParams.Net),
+		}
+	}
+
+	c := cmd.(*btcjson.GenerateCmd)
+
+	// Respond with an error if the client is requesting 0 blocks to be generated.
+	if c.NumBlocks == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInternal.Code,
+			Message: "Please request a nonzero number of blocks to generate.",
+		}
+	}
+
+	// Create a reply
+	reply := make([]string, c.NumBlocks)
+
+	blockHashes, err := s.cfg.CPUMiner.GenerateNBlocks(c.NumBlocks)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInternal.Code,
+			Message: err.Error(),
+		}
+	}
+
+	// Mine the correct number of blocks, assigning the hex representation of the
+	// hash of each one to its place in the reply.
+	for i, hash := range blockHashes {
+		reply[i] = hash.String()
+	}
+
+	return reply, nil
+}
+
+// solveBlock searches the nonce space of the passed block header for a nonce
+// which makes its scrypt proof of work hash meet its target difficulty.  It
+// returns false when no such nonce exists or the passed channel is closed
+// before one is found.  This is only feasible at very low difficulties such
+// as those of the regression test network.
+func solveBlock(header *wire.BlockHeader, closeChan <-chan struct{}) bool {
+	targetDifficulty := blockchain.CompactToBig(header.Bits)
+	for i := uint32(0); ; i++ {
+		select {
+		case <-closeChan:
+			return false
+		default:
+		}
+
+		header.Nonce = i
+		hash, err := header.PowHash()
+		if err != nil {
+			return false
+		}
+		if blockchain.HashToBig(hash).Cmp(targetDifficulty) <= 0 {
+			return true
+		}
+		if i == math.MaxUint32 {
+			return false
+		}
+	}
+}
+
+// handleGenerateBlock implements the generateblock command.
+func handleGenerateBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// Respond with an error if there's virtually 0 chance of mining a block
+	// with the CPU.
+	if !s.cfg.ChainParams.GenerateSupported {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCDifficulty,
+			Message: fmt.Sprintf("No support for `generateblock` on "+
+				"the current network, %s, as it's unlikely to "+
+				"be possible to mine a block with the CPU.",
+				s.cfg.ChainParams.Net),
+		}
+	}
+
+	c := cmd.(*btcjson.GenerateBlockCmd)
+
+	// Decode the address the coinbase pays to.
+	params := s.cfg.ChainParams
+	addr, err := ltcutil.DecodeAddress(c.Output, params)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address or key: " + err.Error(),
+		}
+	}
+	if !addr.IsForNet(params) {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address: " + c.Output +
+				" is for the wrong network",
+		}
+	}
+
+	// Each transaction is either given by the hash of a transaction in the
+	// memory pool or as a serialized transaction.  Since a serialized
+	// transaction is always longer than a hash, the length distinguishes
+	// them.
+	var txns []*ltcutil.Tx
+	if c.Transactions != nil {
+		for _, txStr := range *c.Transactions {
+			if len(txStr) == chainhash.MaxHashStringSize {
+				txHash, err := chainhash.NewHashFromStr(txStr)
+				if err != nil {
+					return nil, rpcDecodeHexError(txStr)
+				}
+				tx, err := s.cfg.TxMemPool.FetchTransaction(txHash)
+				if err != nil {
+					return nil, rpcNotInMempoolError(txHash)
+				}
+				txns = append(txns, tx)
+				continue
+			}
+
+			hexStr := txStr
+			if len(hexStr)%2 != 0 {
+				hexStr = "0" + hexStr
+			}
+			serializedTx, err := hex.DecodeString(hexStr)
+			if err != nil {
+				return nil, rpcDecodeHexError(hexStr)
+			}
+			var msgTx wire.MsgTx
+			err = msgTx.Deserialize(bytes.NewReader(serializedTx))
+			if err != nil {
+				return nil, &btcjson.RPCError{
+					Code:    btcjson.ErrRPCDeserialization,
+					Message: "TX decode failed: " + err.Error(),
+				}
+			}
+			txns = append(txns, ltcutil.NewTx(&msgTx))
+		}
+	}
+
+	// Create a block containing exactly the requested transactions.  The
+	// error identifies the first transaction which can't be included.
+	template, err := s.cfg.Generator.NewBlockTemplateWithTxns(addr, txns)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCVerify,
+			Message: "Unable to create block: " + err.Error(),
+		}
+	}
+
+	msgBlock := template.Block
+	if !solveBlock(&msgBlock.Header, closeChan) {
+		select {
+		case <-closeChan:
+			return nil, ErrClientQuit
+		default:
+		}
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInternal.Code,
+			Message: "Unable to solve block",
+		}
+	}
+
+	// Process the block using the same rules as blocks coming from other
+	// nodes.  This will in turn relay it to the network like normal.
+	block := ltcutil.NewBlock(msgBlock)
+	isOrphan, err := s.cfg.SyncMgr.SubmitBlock(block, blockchain.BFNone)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCVerify,
+			Message: "Block rejected: " + err.Error(),
+		}
+	}
+	if isOrphan {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCVerify,
+			Message: "Block rejected: the best chain changed",
+		}
+	}
+
+	rpcsLog.Infof("Accepted block %s via generateblock", block.Hash())
+
+	result := &btcjson.GenerateBlockResult{
+		Hash: block.Hash().String(),
+	}
+	if c.IncludeHex != nil && *c.IncludeHex {
+		blockBytes, err := block.Bytes()
+		if err != nil {
+			context := "Failed to serialize block"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		result.Hex = hex.EncodeToString(blockBytes)
+	}
+	return result, nil
+}
+
+// generateToAddress generates the requested number of blocks paying to the
+// passed address using the CPU miner and returns their hashes.  The passed
+// method is the name of the command used in errors.
+func generateToAddress(s *rpcServer, method string, numBlocks int64, addr ltcutil.Address, maxTries int64) (interface{}, error) {
+	// Respond with an error if there's virtually 0 chance of mining a block
+	// with the CPU.
+	if !s.cfg.ChainParams.GenerateSupported {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCDifficulty,
+			Message: fmt.Sprintf("No support for `%s` on the "+
+				"current network, %s, as it's unlikely to be "+
+				"possible to mine a block with the CPU.", method,
+				s.cfg.ChainParams.Net),
+		}
+	}
+
+	if numBlocks <= 0 || numBlocks > math.MaxUint32 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Please request a nonzero number of blocks to generate.",
+		}
+	}
+	if maxTries <= 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "The maximum number of tries must be positive",
+		}
+	}
+
+	blockHashes, err := s.cfg.CPUMiner.GenerateNBlocksToAddress(
+		uint32(numBlocks), addr, uint64(maxTries))
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInternal.Code,
+			Message: err.Error(),
+		}
+	}
+
+	reply := make([]string, 0, len(blockHashes))
+	for _, hash := range blockHashes {
+		reply = append(reply, hash.String())
+	}
+	return reply, nil
+}
+
+// handleGenerateToAddress implements the generatetoaddress command.
+func handleGenerateToAddress(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GenerateToAddressCmd)
+
+	params := s.cfg.ChainParams
+	addr, err := ltcutil.DecodeAddress(c.Address, params)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address or key: " + err.Error(),
+		}
+	}
+	if !addr.IsForNet(params) {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid address: " + c.Address +
+				" is for the wrong network",
+		}
+	}
+
+	return generateToAddress(s, "generatetoaddress", c.NumBlocks, addr,
+		*c.MaxTries)
+}
+
+// handleGenerateToDescriptor implements the generatetodescriptor command.
+func handleGenerateToDescriptor(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GenerateToDescriptorCmd)
+
+	desc, err := descriptor.Parse(c.Descriptor, s.cfg.ChainParams, false)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid descriptor: " + err.Error(),
+		}
+	}
+
+	// A ranged descriptor does not describe a single output to pay to.
+	if desc.IsRange() {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Ranged descriptors are not supported",
+		}
+	}
+	addr, err := desc.Address(0)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid descriptor: " + err.Error(),
+		}
+	}
+
+	return generateToAddress(s, "generatetodescriptor", c.NumBlocks, addr,
+		*c.MaxTries)
+}
+
+// handleGetAddedNodeInfo handles getaddednodeinfo commands.
+func handleGetAddedNodeInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetAddedNodeInfoCmd)
+
+	// Retrieve a list of persistent (added) peers from the server and
+	// filter the list of peers per the specified address (if any).
+	peers := s.cfg.ConnMgr.PersistentPeers()
+	if c.Node != nil {
+		node := *c.Node
+		found := false
+		for i, peer := range peers {
+			if peer.ToPeer().Addr() == node {
+				peers = peers[i : i+1]
+				found = true
+			}
+		}
+		if !found {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCClientNodeNotAdded,
+				Message: "Node has not been added",
+			}
+		}
+	}
+
+	// Without the dns flag, the result is just a slice of the addresses as
+	// strings.
+	if !c.DNS {
+		results := make([]string, 0, len(peers))
+		for _, peer := range peers {
+			results = append(results, peer.ToPeer().Addr())
+		}
+		return results, nil
+	}
+
+	// With the dns flag, the result is an array of JSON objects which
+	// include the result of DNS lookups for each peer.
+	results := make([]*btcjson.GetAddedNodeInfoResult, 0, len(peers))
+	for _, rpcPeer := range peers {
+		// Set the "address" of the peer which could be an ip address
+		// or a domain name.
+		peer := rpcPeer.ToPeer()
 		var result btcjson.GetAddedNodeInfoResult
 		result.AddedNode = peer.Addr()
 		result.Connected = btcjson.Bool(peer.Connected())
@@ -1008,6 +2335,31 @@ func handleGetBestBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 	return result, nil
 }
 
+// handleGetAddrManInfo implements the getaddrmaninfo command.
+func handleGetAddrManInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// Every network is reported, even those without any known addresses,
+	// along with the totals across all networks.
+	counts := s.cfg.ConnMgr.AddressCounts()
+	networks := []addrmgr.NetworkType{addrmgr.NetworkIPv4,
+		addrmgr.NetworkIPv6, addrmgr.NetworkOnion, addrmgr.NetworkCJDNS,
+		addrmgr.NetworkI2P}
+	result := make(map[string]btcjson.GetAddrManInfoResult, len(networks)+1)
+	var all btcjson.GetAddrManInfoResult
+	for _, network := range networks {
+		c := counts[network]
+		result[network.String()] = btcjson.GetAddrManInfoResult{
+			New:   c.New,
+			Tried: c.Tried,
+			Total: c.New + c.Tried,
+		}
+		all.New += c.New
+		all.Tried += c.Tried
+	}
+	all.Total = all.New + all.Tried
+	result["all_networks"] = all
+	return result, nil
+}
+
 // handleGetBestBlockHash implements the getbestblockhash command.
 func handleGetBestBlockHash(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	best := s.cfg.Chain.BestSnapshot()
@@ -1034,6 +2386,28 @@ func getDifficultyRatio(bits uint32, params *chaincfg.Params) float64 {
 	return diff
 }
 
+// estimateVerificationProgress returns an estimate of the fraction of the
+// transactions in the chain which have been verified, ranging from 0 to 1.
+// Like Bitcoin Core, the transactions added to the chain since the best block
+// are estimated from the time elapsed since it was mined and the transaction
+// rate of the network, so the estimate approaches 1 as the best block nears
+// the tip of the chain.
+func estimateVerificationProgress(totalTxns uint64, bestTime, now time.Time, txRate float64) float64 {
+	if totalTxns == 0 {
+		return 0
+	}
+
+	elapsed := now.Sub(bestTime).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	progress := float64(totalTxns) / (float64(totalTxns) + elapsed*txRate)
+	if progress > 1 {
+		progress = 1
+	}
+	return progress
+}
+
 // handleGetBlock implements the getblock command.
 func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetBlockCmd)
@@ -1050,10 +2424,7 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		return err
 	})
 	if err != nil {
-		return nil, &btcjson.RPCError{
-			Code:    btcjson.ErrRPCBlockNotFound,
-			Message: "Block not found",
-		}
+		return nil, rpcBlockUnavailableError(s, hash)
 	}
 
 	// When the verbose flag isn't set, simply return the serialized block
@@ -1111,7 +2482,11 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 		NextHash:      nextHashString,
 	}
 
-	if c.VerboseTx == nil || !*c.VerboseTx {
+	// Requesting the previous outputs implies the transactions are
+	// returned as JSON objects since the outputs are added to their inputs.
+	verbosePrevOut := c.VerbosePrevOut != nil && *c.VerbosePrevOut
+	verboseTx := verbosePrevOut || (c.VerboseTx != nil && *c.VerboseTx)
+	if !verboseTx {
 		transactions := blk.Transactions()
 		txNames := make([]string, len(transactions))
 		for i, tx := range transactions {
@@ -1120,6 +2495,14 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 
 		blockReply.Tx = txNames
 	} else {
+		var prevOuts map[wire.OutPoint]prevOutput
+		if verbosePrevOut {
+			prevOuts, err = fetchBlockPrevOuts(s, blk)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		txns := blk.Transactions()
 		rawTxns := make([]btcjson.TxRawResult, len(txns))
 		for i, tx := range txns {
@@ -1129,12 +2512,221 @@ func handleGetBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 			if err != nil {
 				return nil, err
 			}
+			if verbosePrevOut && i != 0 {
+				addTxRawResultPrevOuts(rawTxn, tx.MsgTx(),
+					prevOuts, params)
+			}
 			rawTxns[i] = *rawTxn
 		}
-		blockReply.RawTx = rawTxns
+		blockReply.RawTx = rawTxns
+	}
+
+	return blockReply, nil
+}
+
+// prevOutput houses an output spent by a transaction input along with whether
+// or not it was created by a coinbase transaction.
+type prevOutput struct {
+	txOut    wire.TxOut
+	coinbase bool
+}
+
+// newPrevOutput returns the output of the passed transaction at the passed
+// index.  False is returned when the transaction does not have the output.
+func newPrevOutput(originTx *wire.MsgTx, index uint32) (prevOutput, bool) {
+	if originTx == nil || index >= uint32(len(originTx.TxOut)) {
+		return prevOutput{}, false
+	}
+	return prevOutput{
+		txOut:    *originTx.TxOut[index],
+		coinbase: blockchain.IsCoinBaseTx(originTx),
+	}, true
+}
+
+// fetchBlockPrevOuts returns the outputs spent by the transactions in the passed
+// block which can be resolved.  Outputs created earlier in the same block are
+// taken from the block itself while all others are loaded via the transaction
+// index.  Since the outputs spent by a block are no longer in the utxo set,
+// outputs are left out of the returned map when the transaction index is
+// disabled or the block containing them has been pruned.
+func fetchBlockPrevOuts(s *rpcServer, block *ltcutil.Block) (map[wire.OutPoint]prevOutput, error) {
+	// Index the transactions of the block by their hashes.
+	blockTxns := make(map[chainhash.Hash]*wire.MsgTx)
+	for _, tx := range block.Transactions() {
+		blockTxns[*tx.Hash()] = tx.MsgTx()
+	}
+
+	prevOuts := make(map[wire.OutPoint]prevOutput)
+	originTxns := make(map[chainhash.Hash]*wire.MsgTx)
+	for _, tx := range block.Transactions()[1:] {
+		for _, txIn := range tx.MsgTx().TxIn {
+			origin := &txIn.PreviousOutPoint
+			originTx, ok := blockTxns[origin.Hash]
+			if !ok {
+				originTx, ok = originTxns[origin.Hash]
+			}
+			if !ok && s.cfg.TxIndex != nil {
+				var err error
+				originTx, err = fetchIndexedTx(s, &origin.Hash)
+				if err != nil {
+					return nil, err
+				}
+				originTxns[origin.Hash] = originTx
+			}
+			if prevOut, ok := newPrevOutput(originTx, origin.Index); ok {
+				prevOuts[*origin] = prevOut
+			}
+		}
+	}
+
+	return prevOuts, nil
+}
+
+// fetchTxPrevOuts returns the outputs spent by the passed non-coinbase
+// transaction which can be resolved.  The outputs are looked up in the memory pool first, then the
+// utxo set, and finally via the transaction index for the outputs which are
+// already spent in the main chain.  Outputs are left out of the returned map
+// when none of them has the output, such as when the transaction index is
+// disabled or the block containing the output has been pruned.
+func fetchTxPrevOuts(s *rpcServer, mtx *wire.MsgTx) (map[wire.OutPoint]prevOutput, error) {
+	prevOuts := make(map[wire.OutPoint]prevOutput)
+	for _, txIn := range mtx.TxIn {
+		// Attempt to fetch and use the referenced transaction from the
+		// memory pool.
+		origin := &txIn.PreviousOutPoint
+		originTx, err := s.cfg.TxMemPool.FetchTransaction(&origin.Hash)
+		if err == nil {
+			prevOut, ok := newPrevOutput(originTx.MsgTx(), origin.Index)
+			if ok {
+				prevOuts[*origin] = prevOut
+			}
+			continue
+		}
+
+		// Use the output from the utxo set when it is still unspent.
+		entry, err := s.cfg.Chain.FetchUtxoEntry(&origin.Hash)
+		if err != nil {
+			context := "Failed to fetch utxo entry"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		if entry != nil && !entry.IsOutputSpent(origin.Index) {
+			prevOuts[*origin] = prevOutput{
+				txOut: wire.TxOut{
+					Value:    entry.AmountByIndex(origin.Index),
+					PkScript: entry.PkScriptByIndex(origin.Index),
+				},
+				coinbase: entry.IsCoinBase(),
+			}
+			continue
+		}
+
+		// Fall back to loading the transaction which created the
+		// output via the transaction index.
+		if s.cfg.TxIndex == nil {
+			continue
+		}
+		indexedTx, err := fetchIndexedTx(s, &origin.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if prevOut, ok := newPrevOutput(indexedTx, origin.Index); ok {
+			prevOuts[*origin] = prevOut
+		}
+	}
+
+	return prevOuts, nil
+}
+
+// fetchIndexedTx loads the transaction with the passed hash via the transaction
+// index.  A nil transaction is returned when the transaction is not indexed or
+// the block containing it is no longer available.
+func fetchIndexedTx(s *rpcServer, hash *chainhash.Hash) (*wire.MsgTx, error) {
+	// Look up the location of the transaction.
+	blockRegion, err := s.cfg.TxIndex.TxBlockRegion(hash)
+	if err != nil {
+		context := "Failed to retrieve transaction location"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	if blockRegion == nil {
+		return nil, nil
+	}
+
+	// Load the raw transaction bytes from the database.
+	var txBytes []byte
+	err = s.cfg.DB.View(func(dbTx database.Tx) error {
+		var err error
+		txBytes, err = dbTx.FetchBlockRegion(blockRegion)
+		return err
+	})
+	if err != nil {
+		return nil, nil
+	}
+
+	// Deserialize the transaction.
+	var msgTx wire.MsgTx
+	err = msgTx.Deserialize(bytes.NewReader(txBytes))
+	if err != nil {
+		context := "Failed to deserialize transaction"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return &msgTx, nil
+}
+
+// addTxRawResultPrevOuts adds the outputs spent by the inputs of the passed
+// non-coinbase transaction to its raw transaction JSON object.  Inputs whose
+// previous output is not in the passed map are flagged as unavailable, and
+// the fee of the transaction is only set when all of them are available.
+func addTxRawResultPrevOuts(rawTxn *btcjson.TxRawResult, mtx *wire.MsgTx,
+	prevOuts map[wire.OutPoint]prevOutput, chainParams *chaincfg.Params) {
+
+	var totalIn int64
+	allAvailable := true
+	for i, txIn := range mtx.TxIn {
+		spent, ok := prevOuts[txIn.PreviousOutPoint]
+		if !ok {
+			rawTxn.Vin[i].PrevOutUnavailable = true
+			allAvailable = false
+			continue
+		}
+		prevOut := &spent.txOut
+		totalIn += prevOut.Value
+
+		// The disassembled string will contain [error] inline if the
+		// script doesn't fully parse, so ignore the error here.
+		disbuf, _ := txscript.DisasmString(prevOut.PkScript)
+
+		// Ignore the error here since an error means the script
+		// couldn't parse and there is no additional information about
+		// it anyways.
+		scriptClass, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(
+			prevOut.PkScript, chainParams)
+		encodedAddrs := make([]string, len(addrs))
+		for j, addr := range addrs {
+			encodedAddrs[j] = addr.EncodeAddress()
+		}
+
+		rawTxn.Vin[i].PrevOut = &btcjson.PrevOutResult{
+			Generated: spent.coinbase,
+			Value:     ltcutil.Amount(prevOut.Value).ToBTC(),
+			ScriptPubKey: btcjson.ScriptPubKeyResult{
+				Asm:       disbuf,
+				Hex:       hex.EncodeToString(prevOut.PkScript),
+				ReqSigs:   int32(reqSigs),
+				Type:      scriptClass.String(),
+				Addresses: encodedAddrs,
+			},
+		}
+	}
+	if !allAvailable {
+		return
 	}
 
-	return blockReply, nil
+	var totalOut int64
+	for _, txOut := range mtx.TxOut {
+		totalOut += txOut.Value
+	}
+	fee := ltcutil.Amount(totalIn - totalOut).ToBTC()
+	rawTxn.Fee = &fee
 }
 
 // softForkStatus converts a ThresholdState state into a human readable string
@@ -1146,7 +2738,7 @@ func softForkStatus(state blockchain.ThresholdState) (string, error) {
 	case blockchain.ThresholdStarted:
 		return "started", nil
 	case blockchain.ThresholdLockedIn:
-		return "lockedin", nil
+		return "locked_in", nil
 	case blockchain.ThresholdActive:
 		return "active", nil
 	case blockchain.ThresholdFailed:
@@ -1156,86 +2748,71 @@ func softForkStatus(state blockchain.ThresholdState) (string, error) {
 	}
 }
 
-// handleGetBlockChainInfo implements the getblockchaininfo command.
-func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	// Obtain a snapshot of the current best known blockchain state. We'll
-	// populate the response to this call primarily from this snapshot.
+// deploymentName converts the passed BIP0009 deployment ID into a human
+// readable fork name.  An error is returned for unknown deployments.
+func deploymentName(deployment int) (string, error) {
+	switch deployment {
+	case chaincfg.DeploymentTestDummy:
+		return "dummy", nil
+
+	case chaincfg.DeploymentCSV:
+		return "csv", nil
+
+	case chaincfg.DeploymentSegwit:
+		return "segwit", nil
+
+	default:
+		return "", &btcjson.RPCError{
+			Code: btcjson.ErrRPCInternal.Code,
+			Message: fmt.Sprintf("Unknown deployment %v "+
+				"detected", deployment),
+		}
+	}
+}
+
+// softForkDescriptions returns the status of the soft-forks for the block after
+// the block with the passed hash and height in the main chain, or after the
+// best block when the hash is nil.
+func softForkDescriptions(s *rpcServer, hash *chainhash.Hash, height int32) (map[string]*btcjson.SoftForkDescription, error) {
 	params := s.cfg.ChainParams
 	chain := s.cfg.Chain
-	chainSnapshot := chain.BestSnapshot()
 
-	chainInfo := &btcjson.GetBlockChainInfoResult{
-		Chain:         params.Name,
-		Blocks:        chainSnapshot.Height,
-		Headers:       chainSnapshot.Height,
-		BestBlockHash: chainSnapshot.Hash.String(),
-		Difficulty:    getDifficultyRatio(chainSnapshot.Bits, params),
-		MedianTime:    chainSnapshot.MedianTime.Unix(),
-		Pruned:        false,
-		Bip9SoftForks: make(map[string]*btcjson.Bip9SoftForkDescription),
-	}
-
-	// Next, populate the response with information describing the current
-	// status of soft-forks deployed via the super-majority block
-	// signalling mechanism.
-	height := chainSnapshot.Height
-	chainInfo.SoftForks = []*btcjson.SoftForkDescription{
-		{
-			ID:      "bip34",
-			Version: 2,
-			Reject: struct {
-				Status bool `json:"status"`
-			}{
-				Status: height >= params.BIP0034Height,
-			},
-		},
-		{
-			ID:      "bip66",
-			Version: 3,
-			Reject: struct {
-				Status bool `json:"status"`
-			}{
-				Status: height >= params.BIP0066Height,
-			},
-		},
-		{
-			ID:      "bip65",
-			Version: 4,
-			Reject: struct {
-				Status bool `json:"status"`
-			}{
-				Status: height >= params.BIP0065Height,
-			},
-		},
+	// Populate the status of the soft-forks which are enforced from a
+	// fixed height.
+	softForks := make(map[string]*btcjson.SoftForkDescription)
+	buriedForks := map[string]int32{
+		"bip34": params.BIP0034Height,
+		"bip66": params.BIP0066Height,
+		"bip65": params.BIP0065Height,
+	}
+	for forkName, forkHeight := range buriedForks {
+		softForks[forkName] = &btcjson.SoftForkDescription{
+			Type:   "buried",
+			Height: forkHeight,
+			Active: height+1 >= forkHeight,
+		}
 	}
 
-	// Finally, query the BIP0009 version bits state for all currently
-	// defined BIP0009 soft-fork deployments.
+	// Query the BIP0009 version bits state for all currently defined
+	// BIP0009 soft-fork deployments.
 	for deployment, deploymentDetails := range params.Deployments {
 		// Map the integer deployment ID into a human readable
 		// fork-name.
-		var forkName string
-		switch deployment {
-		case chaincfg.DeploymentTestDummy:
-			forkName = "dummy"
-
-		case chaincfg.DeploymentCSV:
-			forkName = "csv"
-
-		case chaincfg.DeploymentSegwit:
-			forkName = "segwit"
-
-		default:
-			return nil, &btcjson.RPCError{
-				Code: btcjson.ErrRPCInternal.Code,
-				Message: fmt.Sprintf("Unknown deployment %v "+
-					"detected", deployment),
-			}
+		forkName, err := deploymentName(deployment)
+		if err != nil {
+			return nil, err
 		}
 
 		// Query the chain for the current status of the deployment as
 		// identified by its deployment ID.
-		deploymentStatus, err := chain.ThresholdState(uint32(deployment))
+		var deploymentStatus *blockchain.DeploymentStatus
+		if hash == nil {
+			deploymentStatus, err = chain.DeploymentStatus(
+				uint32(deployment))
+		} else {
+			deploymentStatus, err = chain.DeploymentStatusByHash(
+				hash, uint32(deployment))
+		}
 		if err != nil {
 			context := "Failed to obtain deployment status"
 			return nil, internalRPCError(err.Error(), context)
@@ -1244,24 +2821,96 @@ func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan str
 		// Attempt to convert the current deployment status into a
 		// human readable string. If the status is unrecognized, then a
 		// non-nil error is returned.
-		statusString, err := softForkStatus(deploymentStatus)
+		statusString, err := softForkStatus(deploymentStatus.State)
 		if err != nil {
 			return nil, &btcjson.RPCError{
 				Code: btcjson.ErrRPCInternal.Code,
 				Message: fmt.Sprintf("unknown deployment status: %v",
-					deploymentStatus),
+					deploymentStatus.State),
 			}
 		}
 
 		// Finally, populate the soft-fork description with all the
 		// information gathered above.
-		chainInfo.Bip9SoftForks[forkName] = &btcjson.Bip9SoftForkDescription{
-			Status:    strings.ToLower(statusString),
+		bip9 := &btcjson.Bip9SoftForkDescription{
+			Status:    statusString,
 			Bit:       deploymentDetails.BitNumber,
 			StartTime: int64(deploymentDetails.StartTime),
 			Timeout:   int64(deploymentDetails.ExpireTime),
+			Since:     deploymentStatus.Since,
+		}
+		if stats := deploymentStatus.Stats; stats != nil {
+			bip9.Statistics = &btcjson.Bip9Statistics{
+				Period:    stats.Period,
+				Threshold: stats.Threshold,
+				Elapsed:   stats.Elapsed,
+				Count:     stats.Count,
+				Possible:  stats.Possible,
+			}
 		}
+		desc := &btcjson.SoftForkDescription{
+			Type:   "bip9",
+			Bip9:   bip9,
+			Active: deploymentStatus.State == blockchain.ThresholdActive,
+		}
+		if desc.Active {
+			desc.Height = deploymentStatus.Since
+		}
+		softForks[forkName] = desc
+	}
+
+	return softForks, nil
+}
+
+// handleGetBlockChainInfo implements the getblockchaininfo command.
+func handleGetBlockChainInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	// Obtain a snapshot of the current best known blockchain state. We'll
+	// populate the response to this call primarily from this snapshot.
+	params := s.cfg.ChainParams
+	chain := s.cfg.Chain
+	chainSnapshot := chain.BestSnapshot()
+
+	chainInfo := &btcjson.GetBlockChainInfoResult{
+		Chain:         params.Name,
+		Blocks:        chainSnapshot.Height,
+		Headers:       chainSnapshot.Height,
+		BestBlockHash: chainSnapshot.Hash.String(),
+		Difficulty:    getDifficultyRatio(chainSnapshot.Bits, params),
+		MedianTime:    chainSnapshot.MedianTime.Unix(),
+		OrphanBlocks:  chain.NumOrphans(),
+	}
+
+	// Report the best known header and whether the initial block download
+	// is in progress as tracked by the sync manager.
+	status := s.cfg.SyncMgr.SyncStatus()
+	chainInfo.Headers = status.headerHeight
+	chainInfo.InitialBlockDownload = status.phase != syncPhaseSynced
+
+	// Estimate the progress of the verification of the chain from the time
+	// of the best block.
+	bestHeader, err := chain.FetchHeader(&chainSnapshot.Hash)
+	if err != nil {
+		context := "Failed to fetch best block header"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	chainInfo.VerificationProgress = estimateVerificationProgress(
+		chainSnapshot.TotalTxns, bestHeader.Timestamp,
+		time.Now(), params.TxRate)
+
+	// Report the lowest block which is still available when blocks have
+	// been pruned.
+	pruneHeight := chain.PruneHeight()
+	if chain.PruneEnabled() || pruneHeight >= 0 {
+		chainInfo.Pruned = true
+		chainInfo.PruneHeight = pruneHeight + 1
+	}
+
+	// Finally, populate the response with the status of the soft-forks.
+	softForks, err := softForkDescriptions(s, nil, chainSnapshot.Height)
+	if err != nil {
+		return nil, err
 	}
+	chainInfo.SoftForks = softForks
 
 	return chainInfo, nil
 }
@@ -1295,77 +2944,516 @@ func handleGetBlockHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct
 	if err != nil {
 		return nil, rpcDecodeHexError(c.Hash)
 	}
-	blockHeader, err := s.cfg.Chain.FetchHeader(hash)
+	blockHeader, err := s.cfg.Chain.FetchHeader(hash)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+
+	// When the verbose flag isn't set, simply return the serialized block
+	// header as a hex-encoded string.
+	if c.Verbose != nil && !*c.Verbose {
+		var headerBuf bytes.Buffer
+		err := blockHeader.Serialize(&headerBuf)
+		if err != nil {
+			context := "Failed to serialize block header"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		return hex.EncodeToString(headerBuf.Bytes()), nil
+	}
+
+	// The verbose flag is set, so generate the JSON object and return it.
+
+	// Get the block height from chain.
+	blockHeight, err := s.cfg.Chain.BlockHeightByHash(hash)
+	if err != nil {
+		context := "Failed to obtain block height"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	best := s.cfg.Chain.BestSnapshot()
+
+	// Get next block hash unless there are none.
+	var nextHashString string
+	if blockHeight < best.Height {
+		nextHash, err := s.cfg.Chain.BlockHashByHeight(blockHeight + 1)
+		if err != nil {
+			context := "No next block"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		nextHashString = nextHash.String()
+	}
+
+	params := s.cfg.ChainParams
+	blockHeaderReply := btcjson.GetBlockHeaderVerboseResult{
+		Hash:          c.Hash,
+		Confirmations: uint64(1 + best.Height - blockHeight),
+		Height:        blockHeight,
+		Version:       blockHeader.Version,
+		VersionHex:    fmt.Sprintf("%08x", blockHeader.Version),
+		MerkleRoot:    blockHeader.MerkleRoot.String(),
+		NextHash:      nextHashString,
+		PreviousHash:  blockHeader.PrevBlock.String(),
+		Nonce:         uint64(blockHeader.Nonce),
+		Time:          blockHeader.Timestamp.Unix(),
+		Bits:          strconv.FormatInt(int64(blockHeader.Bits), 16),
+		Difficulty:    getDifficultyRatio(blockHeader.Bits, params),
+	}
+	return blockHeaderReply, nil
+}
+
+// handleGetBlockHeaders implements the getblockheaders command.
+func handleGetBlockHeaders(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetBlockHeadersCmd)
+
+	hash, err := chainhash.NewHashFromStr(c.Hash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Hash)
+	}
+
+	// Limit the number of headers to the maximum allowed per request.
+	count := int32(maxBlockHeadersPerRPC)
+	if c.Count != nil {
+		if *c.Count < 1 {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Count must be positive",
+			}
+		}
+		if *c.Count < count {
+			count = *c.Count
+		}
+	}
+
+	// Headers are only returned for blocks in the main chain since the
+	// headers which follow the starting block are found by height.
+	startHeight, err := s.cfg.Chain.BlockHeightByHash(hash)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found in main chain",
+		}
+	}
+
+	// Fetch the hash of one more block than requested so the next block
+	// hash of the final header is known.  The range is limited to the
+	// current main chain height.
+	best := s.cfg.Chain.BestSnapshot()
+	hashes, err := s.cfg.Chain.HeightRange(startHeight,
+		startHeight+count+1)
+	if err != nil {
+		context := "Failed to fetch block hashes"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	numHeaders := len(hashes)
+	if numHeaders > int(count) {
+		numHeaders = int(count)
+	}
+
+	verbose := c.Verbose == nil || *c.Verbose
+	hexHeaders := make([]string, 0, numHeaders)
+	verboseHeaders := make([]btcjson.GetBlockHeaderVerboseResult, 0,
+		numHeaders)
+	params := s.cfg.ChainParams
+	for i := 0; i < numHeaders; i++ {
+		blockHeader, err := s.cfg.Chain.FetchHeader(&hashes[i])
+		if err != nil {
+			context := "Failed to fetch block header"
+			return nil, internalRPCError(err.Error(), context)
+		}
+
+		// When the verbose flag isn't set, simply return the serialized
+		// block headers as hex-encoded strings.
+		if !verbose {
+			var headerBuf bytes.Buffer
+			err := blockHeader.Serialize(&headerBuf)
+			if err != nil {
+				context := "Failed to serialize block header"
+				return nil, internalRPCError(err.Error(), context)
+			}
+			hexHeaders = append(hexHeaders,
+				hex.EncodeToString(headerBuf.Bytes()))
+			continue
+		}
+
+		// Get next block hash unless there are none.
+		var nextHashString string
+		if i+1 < len(hashes) {
+			nextHashString = hashes[i+1].String()
+		}
+
+		blockHeight := startHeight + int32(i)
+		verboseHeaders = append(verboseHeaders,
+			btcjson.GetBlockHeaderVerboseResult{
+				Hash:          hashes[i].String(),
+				Confirmations: uint64(1 + best.Height - blockHeight),
+				Height:        blockHeight,
+				Version:       blockHeader.Version,
+				VersionHex:    fmt.Sprintf("%08x", blockHeader.Version),
+				MerkleRoot:    blockHeader.MerkleRoot.String(),
+				NextHash:      nextHashString,
+				PreviousHash:  blockHeader.PrevBlock.String(),
+				Nonce:         uint64(blockHeader.Nonce),
+				Time:          blockHeader.Timestamp.Unix(),
+				Bits:          strconv.FormatInt(int64(blockHeader.Bits), 16),
+				Difficulty:    getDifficultyRatio(blockHeader.Bits, params),
+			})
+	}
+	if !verbose {
+		return hexHeaders, nil
+	}
+	return verboseHeaders, nil
+}
+
+// blockStatNames maps the names of the statistics supported by the
+// getblockstats RPC to whether or not calculating them requires the previous
+// outputs spent by the block.
+var blockStatNames = map[string]bool{
+	"avgfee":              true,
+	"avgfeerate":          true,
+	"avgtxsize":           false,
+	"blockhash":           false,
+	"feerate_percentiles": true,
+	"height":              false,
+	"ins":                 false,
+	"maxfee":              true,
+	"maxfeerate":          true,
+	"maxtxsize":           false,
+	"medianfee":           true,
+	"mediantime":          false,
+	"mediantxsize":        false,
+	"minfee":              true,
+	"minfeerate":          true,
+	"mintxsize":           false,
+	"outs":                false,
+	"subsidy":             false,
+	"swtotal_size":        false,
+	"swtotal_weight":      false,
+	"swtxs":               false,
+	"time":                false,
+	"total_out":           false,
+	"total_size":          false,
+	"total_weight":        false,
+	"totalfee":            true,
+	"txs":                 false,
+	"utxo_increase":       false,
+	"utxo_size_inc":       true,
+}
+
+// perUtxoOverhead is the number of bytes, in addition to the serialized
+// output, the utxo_size_inc statistic of the getblockstats RPC attributes to
+// each unspent transaction output.  It accounts for the outpoint, the height
+// and the coinbase flag and matches the value used by Bitcoin Core.
+const perUtxoOverhead = 41
+
+// int64Sorter implements sort.Interface to allow a slice of 64-bit integers to
+// be sorted.
+type int64Sorter []int64
+
+func (s int64Sorter) Len() int           { return len(s) }
+func (s int64Sorter) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s int64Sorter) Less(i, j int) bool { return s[i] < s[j] }
+
+// truncatedMedian returns the median of the passed values.  The average of the
+// two middle values is truncated to an integer for an even number of values.
+// The passed slice is sorted in place.
+func truncatedMedian(values []int64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sort.Sort(int64Sorter(values))
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+// weightedFeeRate houses the fee rate of a transaction along with its weight.
+type weightedFeeRate struct {
+	feeRate int64
+	weight  int64
+}
+
+// weightedFeeRateSorter implements sort.Interface to allow a slice of weighted
+// fee rates to be sorted by fee rate.
+type weightedFeeRateSorter []weightedFeeRate
+
+func (s weightedFeeRateSorter) Len() int      { return len(s) }
+func (s weightedFeeRateSorter) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s weightedFeeRateSorter) Less(i, j int) bool {
+	return s[i].feeRate < s[j].feeRate
+}
+
+// feeRatePercentiles returns the fee rates at the 10th, 25th, 50th, 75th and
+// 90th percentiles of the passed transaction weight.  The passed slice is
+// sorted in place.
+func feeRatePercentiles(feeRates []weightedFeeRate, totalWeight int64) []int64 {
+	percentiles := make([]int64, 5)
+	if len(feeRates) == 0 {
+		return percentiles
+	}
+
+	sort.Sort(weightedFeeRateSorter(feeRates))
+	weights := []float64{
+		float64(totalWeight) / 10,
+		float64(totalWeight) / 4,
+		float64(totalWeight) / 2,
+		float64(totalWeight) * 3 / 4,
+		float64(totalWeight) * 9 / 10,
+	}
+	var next int
+	var cumulativeWeight int64
+	for _, feeRate := range feeRates {
+		cumulativeWeight += feeRate.weight
+		for next < len(weights) && float64(cumulativeWeight) >= weights[next] {
+			percentiles[next] = feeRate.feeRate
+			next++
+		}
+	}
+
+	// Fill any remaining percentiles with the highest fee rate.
+	for ; next < len(percentiles); next++ {
+		percentiles[next] = feeRates[len(feeRates)-1].feeRate
+	}
+
+	return percentiles
+}
+
+// calcBlockStats returns the statistics reported by the getblockstats RPC for
+// the passed block.  The prevOuts map must contain all outputs spent by the
+// block in order to calculate the fee related statistics.  It may be nil when
+// none of those statistics are needed in which case they are left unset.
+func calcBlockStats(block *ltcutil.Block, medianTime time.Time, prevOuts map[wire.OutPoint]wire.TxOut, chainParams *chaincfg.Params) *btcjson.GetBlockStatsResult {
+	header := &block.MsgBlock().Header
+	stats := &btcjson.GetBlockStatsResult{
+		Hash:       block.Hash().String(),
+		Height:     int64(block.Height()),
+		MedianTime: medianTime.Unix(),
+		Subsidy:    blockchain.CalcBlockSubsidy(block.Height(), chainParams),
+		Time:       header.Timestamp.Unix(),
+		Txs:        int64(len(block.Transactions())),
+	}
+
+	var fees, txSizes []int64
+	var feeRates []weightedFeeRate
+	minFee, minFeeRate, minTxSize := int64(math.MaxInt64),
+		int64(math.MaxInt64), int64(math.MaxInt64)
+	for i, tx := range block.Transactions() {
+		msgTx := tx.MsgTx()
+		stats.Outs += int64(len(msgTx.TxOut))
+
+		var totalOut int64
+		for _, txOut := range msgTx.TxOut {
+			totalOut += txOut.Value
+			stats.UTXOSizeIncrease += int64(txOut.SerializeSize()) +
+				perUtxoOverhead
+		}
+
+		// The coinbase does not have any real inputs and its outputs
+		// consist of the subsidy and fees, so it is not included in
+		// the remaining statistics.
+		if i == 0 {
+			continue
+		}
+
+		stats.Ins += int64(len(msgTx.TxIn))
+		stats.TotalOut += totalOut
+
+		size := int64(msgTx.SerializeSize())
+		weight := blockchain.GetTransactionWeight(tx)
+		txSizes = append(txSizes, size)
+		stats.TotalSize += size
+		stats.TotalWeight += weight
+		if size > stats.MaxTxSize {
+			stats.MaxTxSize = size
+		}
+		if size < minTxSize {
+			minTxSize = size
+		}
+		if msgTx.HasWitness() {
+			stats.SegWitTxs++
+			stats.SegWitTotalSize += size
+			stats.SegWitTotalWeight += weight
+		}
+
+		if prevOuts == nil {
+			continue
+		}
+
+		var totalIn int64
+		for _, txIn := range msgTx.TxIn {
+			prevOut := prevOuts[txIn.PreviousOutPoint]
+			totalIn += prevOut.Value
+			stats.UTXOSizeIncrease -= int64(prevOut.SerializeSize()) +
+				perUtxoOverhead
+		}
+
+		// Fee rates are in litoshi per virtual byte.
+		fee := totalIn - totalOut
+		feeRate := fee * blockchain.WitnessScaleFactor / weight
+		fees = append(fees, fee)
+		feeRates = append(feeRates, weightedFeeRate{feeRate, weight})
+		stats.TotalFee += fee
+		if fee > stats.MaxFee {
+			stats.MaxFee = fee
+		}
+		if fee < minFee {
+			minFee = fee
+		}
+		if feeRate > stats.MaxFeeRate {
+			stats.MaxFeeRate = feeRate
+		}
+		if feeRate < minFeeRate {
+			minFeeRate = feeRate
+		}
+	}
+
+	stats.UTXOIncrease = stats.Outs - stats.Ins
+	if numTxns := stats.Txs - 1; numTxns > 0 {
+		stats.AverageFee = stats.TotalFee / numTxns
+		stats.AverageTxSize = stats.TotalSize / numTxns
+	}
+	if stats.TotalWeight > 0 {
+		stats.AverageFeeRate = stats.TotalFee *
+			blockchain.WitnessScaleFactor / stats.TotalWeight
+	}
+	stats.MedianFee = truncatedMedian(fees)
+	stats.MedianTxSize = truncatedMedian(txSizes)
+	stats.FeeRatePercentiles = feeRatePercentiles(feeRates,
+		stats.TotalWeight)
+	if len(fees) > 0 {
+		stats.MinFee = minFee
+		stats.MinFeeRate = minFeeRate
+	}
+	if len(txSizes) > 0 {
+		stats.MinTxSize = minTxSize
+	}
+
+	return stats
+}
+
+// handleGetBlockStats implements the getblockstats command.
+func handleGetBlockStats(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetBlockStatsCmd)
+
+	// Determine whether or not any of the requested statistics require the
+	// outputs spent by the block.  All statistics are requested when none
+	// are specified.
+	needPrevOuts := c.Stats == nil
+	if c.Stats != nil {
+		for _, name := range *c.Stats {
+			needsInputs, ok := blockStatNames[name]
+			if !ok {
+				return nil, &btcjson.RPCError{
+					Code: btcjson.ErrRPCInvalidParameter,
+					Message: fmt.Sprintf("Invalid selected "+
+						"statistic %s", name),
+				}
+			}
+			needPrevOuts = needPrevOuts || needsInputs
+		}
+	}
+	if needPrevOuts && s.cfg.TxIndex == nil {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCNoTxInfo,
+			Message: "The transaction index must be enabled to " +
+				"calculate fee statistics (specify --txindex)",
+		}
+	}
+
+	// Look up the hash of the requested block.
+	var hash *chainhash.Hash
+	switch val := c.HashOrHeight.Value.(type) {
+	case int32:
+		var err error
+		hash, err = s.cfg.Chain.BlockHashByHeight(val)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCOutOfRange,
+				Message: "Block number out of range",
+			}
+		}
+	case string:
+		var err error
+		hash, err = chainhash.NewHashFromStr(val)
+		if err != nil {
+			return nil, rpcDecodeHexError(val)
+		}
+	default:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Block hash or height must be provided",
+		}
+	}
+
+	block, err := s.cfg.Chain.BlockByHash(hash)
 	if err != nil {
 		return nil, &btcjson.RPCError{
 			Code:    btcjson.ErrRPCBlockNotFound,
 			Message: "Block not found",
 		}
 	}
+	medianTime, err := s.cfg.Chain.BlockMedianTimeByHash(hash)
+	if err != nil {
+		context := "Failed to obtain block median time"
+		return nil, internalRPCError(err.Error(), context)
+	}
 
-	// When the verbose flag isn't set, simply return the serialized block
-	// header as a hex-encoded string.
-	if c.Verbose != nil && !*c.Verbose {
-		var headerBuf bytes.Buffer
-		err := blockHeader.Serialize(&headerBuf)
-		if err != nil {
-			context := "Failed to serialize block header"
-			return nil, internalRPCError(err.Error(), context)
+	// Resolve the outputs spent by the block when needed.
+	var prevOuts map[wire.OutPoint]wire.TxOut
+	if needPrevOuts {
+		prevOuts = make(map[wire.OutPoint]wire.TxOut)
+		for _, tx := range block.Transactions()[1:] {
+			txPrevOuts, err := fetchInputTxos(s, tx.MsgTx())
+			if err != nil {
+				return nil, err
+			}
+			for outPoint, txOut := range txPrevOuts {
+				prevOuts[outPoint] = txOut
+			}
 		}
-		return hex.EncodeToString(headerBuf.Bytes()), nil
 	}
 
-	// The verbose flag is set, so generate the JSON object and return it.
+	stats := calcBlockStats(block, medianTime, prevOuts, s.cfg.ChainParams)
+	if c.Stats == nil {
+		return stats, nil
+	}
 
-	// Get the block height from chain.
-	blockHeight, err := s.cfg.Chain.BlockHeightByHash(hash)
+	// Limit the result to the requested statistics.  The statistics are
+	// keyed by the JSON field names of the full result.
+	statsJSON, err := json.Marshal(stats)
 	if err != nil {
-		context := "Failed to obtain block height"
+		context := "Failed to marshal block statistics"
 		return nil, internalRPCError(err.Error(), context)
 	}
-	best := s.cfg.Chain.BestSnapshot()
-
-	// Get next block hash unless there are none.
-	var nextHashString string
-	if blockHeight < best.Height {
-		nextHash, err := s.cfg.Chain.BlockHashByHeight(blockHeight + 1)
-		if err != nil {
-			context := "No next block"
-			return nil, internalRPCError(err.Error(), context)
-		}
-		nextHashString = nextHash.String()
+	var allStats map[string]json.RawMessage
+	if err := json.Unmarshal(statsJSON, &allStats); err != nil {
+		context := "Failed to unmarshal block statistics"
+		return nil, internalRPCError(err.Error(), context)
 	}
-
-	params := s.cfg.ChainParams
-	blockHeaderReply := btcjson.GetBlockHeaderVerboseResult{
-		Hash:          c.Hash,
-		Confirmations: uint64(1 + best.Height - blockHeight),
-		Height:        blockHeight,
-		Version:       blockHeader.Version,
-		VersionHex:    fmt.Sprintf("%08x", blockHeader.Version),
-		MerkleRoot:    blockHeader.MerkleRoot.String(),
-		NextHash:      nextHashString,
-		PreviousHash:  blockHeader.PrevBlock.String(),
-		Nonce:         uint64(blockHeader.Nonce),
-		Time:          blockHeader.Timestamp.Unix(),
-		Bits:          strconv.FormatInt(int64(blockHeader.Bits), 16),
-		Difficulty:    getDifficultyRatio(blockHeader.Bits, params),
+	result := make(map[string]json.RawMessage, len(*c.Stats))
+	for _, name := range *c.Stats {
+		result[name] = allStats[name]
 	}
-	return blockHeaderReply, nil
+	return result, nil
 }
 
 // encodeTemplateID encodes the passed details into an ID that can be used to
 // uniquely identify a block template.
-func encodeTemplateID(prevHash *chainhash.Hash, lastGenerated time.Time) string {
-	return fmt.Sprintf("%s-%d", prevHash.String(), lastGenerated.Unix())
+func encodeTemplateID(prevHash *chainhash.Hash, txUpdates uint64) string {
+	return fmt.Sprintf("%s-%d", prevHash.String(), txUpdates)
 }
 
 // decodeTemplateID decodes an ID that is used to uniquely identify a block
 // template.  This is mainly used as a mechanism to track when to update clients
 // that are using long polling for block templates.  The ID consists of the
-// previous block hash for the associated template and the time the associated
+// previous block hash for the associated template and the number of times the
+// transactions in the memory pool had been updated when the associated
 // template was generated.
-func decodeTemplateID(templateID string) (*chainhash.Hash, int64, error) {
+func decodeTemplateID(templateID string) (*chainhash.Hash, uint64, error) {
 	fields := strings.Split(templateID, "-")
 	if len(fields) != 2 {
 		return nil, 0, errors.New("invalid longpollid format")
@@ -1375,19 +3463,19 @@ func decodeTemplateID(templateID string) (*chainhash.Hash, int64, error) {
 	if err != nil {
 		return nil, 0, errors.New("invalid longpollid format")
 	}
-	lastGenerated, err := strconv.ParseInt(fields[1], 10, 64)
+	txUpdates, err := strconv.ParseUint(fields[1], 10, 64)
 	if err != nil {
 		return nil, 0, errors.New("invalid longpollid format")
 	}
 
-	return prevHash, lastGenerated, nil
+	return prevHash, txUpdates, nil
 }
 
 // notifyLongPollers notifies any channels that have been registered to be
 // notified when block templates are stale.
 //
 // This function MUST be called with the state locked.
-func (state *gbtWorkState) notifyLongPollers(latestHash *chainhash.Hash, lastGenerated time.Time) {
+func (state *gbtWorkState) notifyLongPollers(latestHash *chainhash.Hash, txUpdates uint64) {
 	// Notify anything that is waiting for a block template update from a
 	// hash which is not the hash of the tip of the best chain since their
 	// work is now invalid.
@@ -1400,9 +3488,8 @@ func (state *gbtWorkState) notifyLongPollers(latestHash *chainhash.Hash, lastGen
 		}
 	}
 
-	// Return now if the provided last generated timestamp has not been
-	// initialized.
-	if lastGenerated.IsZero() {
+	// Return now if no block template has been generated yet.
+	if state.lastGenerated.IsZero() {
 		return
 	}
 
@@ -1414,13 +3501,12 @@ func (state *gbtWorkState) notifyLongPollers(latestHash *chainhash.Hash, lastGen
 	}
 
 	// Notify anything that is waiting for a block template update from a
-	// block template generated before the most recently generated block
-	// template.
-	lastGeneratedUnix := lastGenerated.Unix()
-	for lastGen, c := range channels {
-		if lastGen < lastGeneratedUnix {
+	// block template generated before the provided number of updates to the
+	// transactions in the memory pool.
+	for updates, c := range channels {
+		if updates < txUpdates {
 			close(c)
-			delete(channels, lastGen)
+			delete(channels, updates)
 		}
 	}
 
@@ -1439,15 +3525,15 @@ func (state *gbtWorkState) NotifyBlockConnected(blockHash *chainhash.Hash) {
 		state.Lock()
 		defer state.Unlock()
 
-		state.notifyLongPollers(blockHash, state.lastTxUpdate)
+		state.notifyLongPollers(blockHash, state.txUpdates)
 	}()
 }
 
-// NotifyMempoolTx uses the new last updated time for the transaction memory
-// pool to notify any long poll clients with a new block template when their
-// existing block template is stale due to enough time passing and the contents
-// of the memory pool changing.
-func (state *gbtWorkState) NotifyMempoolTx(lastUpdated time.Time) {
+// NotifyMempoolTx uses the new number of updates to the transaction memory pool
+// to notify any long poll clients with a new block template when their existing
+// block template is stale due to enough time passing and the contents of the
+// memory pool changing.
+func (state *gbtWorkState) NotifyMempoolTx(txUpdates uint64) {
 	go func() {
 		state.Lock()
 		defer state.Unlock()
@@ -1461,39 +3547,70 @@ func (state *gbtWorkState) NotifyMempoolTx(lastUpdated time.Time) {
 		if time.Now().After(state.lastGenerated.Add(time.Second *
 			gbtRegenerateSeconds)) {
 
-			state.notifyLongPollers(state.prevHash, lastUpdated)
+			state.notifyLongPollers(state.prevHash, txUpdates)
 		}
 	}()
 }
 
 // templateUpdateChan returns a channel that will be closed once the block
-// template associated with the passed previous hash and last generated time
-// is stale.  The function will return existing channels for duplicate
+// template associated with the passed previous hash and number of updates to
+// the transactions in the memory pool is stale.  The function will return existing channels for duplicate
 // parameters which allows multiple clients to wait for the same block template
 // without requiring a different channel for each client.
 //
 // This function MUST be called with the state locked.
-func (state *gbtWorkState) templateUpdateChan(prevHash *chainhash.Hash, lastGenerated int64) chan struct{} {
+func (state *gbtWorkState) templateUpdateChan(prevHash *chainhash.Hash, txUpdates uint64) chan struct{} {
 	// Either get the current list of channels waiting for updates about
 	// changes to block template for the previous hash or create a new one.
 	channels, ok := state.notifyMap[*prevHash]
 	if !ok {
-		m := make(map[int64]chan struct{})
+		m := make(map[uint64]chan struct{})
 		state.notifyMap[*prevHash] = m
 		channels = m
 	}
 
-	// Get the current channel associated with the time the block template
-	// was last generated or create a new one.
-	c, ok := channels[lastGenerated]
+	// Get the current channel associated with the number of updates to the
+	// memory pool the block template was generated at or create a new one.
+	c, ok := channels[txUpdates]
 	if !ok {
 		c = make(chan struct{})
-		channels[lastGenerated] = c
+		channels[txUpdates] = c
 	}
 
 	return c
 }
 
+// gbtVbAvailable returns the bits of the BIP0009 deployments which are started
+// or locked in, and are signalled by the passed block version, keyed by the
+// names of the deployments.
+func gbtVbAvailable(s *rpcServer, version int32) (map[string]int, error) {
+	vbAvailable := make(map[string]int)
+	for deployment, deploymentDetails := range s.cfg.ChainParams.Deployments {
+		deploymentStatus, err := s.cfg.Chain.DeploymentStatus(
+			uint32(deployment))
+		if err != nil {
+			context := "Failed to obtain deployment status"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		if deploymentStatus.State != blockchain.ThresholdStarted &&
+			deploymentStatus.State != blockchain.ThresholdLockedIn {
+
+			continue
+		}
+
+		bit := deploymentDetails.BitNumber
+		if uint32(version)&(uint32(1)<<bit) == 0 {
+			continue
+		}
+		forkName, err := deploymentName(deployment)
+		if err != nil {
+			return nil, err
+		}
+		vbAvailable[forkName] = int(bit)
+	}
+	return vbAvailable, nil
+}
+
 // updateBlockTemplate creates or updates a block template for the work state.
 // A new block template will be generated when the current best block has
 // changed or the transactions in the memory pool have been updated and it has
@@ -1508,10 +3625,7 @@ func (state *gbtWorkState) templateUpdateChan(prevHash *chainhash.Hash, lastGene
 // This function MUST be called with the state locked.
 func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bool) error {
 	generator := s.cfg.Generator
-	lastTxUpdate := generator.TxSource().LastUpdated()
-	if lastTxUpdate.IsZero() {
-		lastTxUpdate = time.Now()
-	}
+	txUpdates := s.cfg.TxMemPool.TransactionsUpdated()
 
 	// Generate a new block template when the current best block has
 	// changed or the transactions in the memory pool have been updated and
@@ -1523,7 +3637,7 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 	template := state.template
 	if template == nil || state.prevHash == nil ||
 		!state.prevHash.IsEqual(latestHash) ||
-		(state.lastTxUpdate != lastTxUpdate &&
+		(state.txUpdates != txUpdates &&
 			time.Now().After(state.lastGenerated.Add(time.Second*
 				gbtRegenerateSeconds))) {
 
@@ -1561,13 +3675,21 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 		best := s.cfg.Chain.BestSnapshot()
 		minTimestamp := mining.MinimumMedianTime(best)
 
+		// Determine which of the pending deployments the block
+		// template signals.
+		vbAvailable, err := gbtVbAvailable(s, msgBlock.Header.Version)
+		if err != nil {
+			return err
+		}
+
 		// Update work state to ensure another block template isn't
 		// generated until needed.
 		state.template = template
 		state.lastGenerated = time.Now()
-		state.lastTxUpdate = lastTxUpdate
+		state.txUpdates = txUpdates
 		state.prevHash = latestHash
 		state.minTimestamp = minTimestamp
+		state.vbAvailable = vbAvailable
 
 		rpcsLog.Debugf("Generated block template (timestamp %v, "+
 			"target %s, merkle root %s)",
@@ -1576,7 +3698,7 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 
 		// Notify any clients that are long polling about the new
 		// template.
-		state.notifyLongPollers(latestHash, lastTxUpdate)
+		state.notifyLongPollers(latestHash, txUpdates)
 	} else {
 		// At this point, there is a saved block template and another
 		// request for a template was made, but either the available
@@ -1629,6 +3751,16 @@ func (state *gbtWorkState) updateBlockTemplate(s *rpcServer, useCoinbaseValue bo
 	return nil
 }
 
+// gbtCoinbaseAux returns the additional data that miners should include in the
+// coinbase signature script for the passed coinbase flags.  The flags are
+// bounded in length, so the script which pushes them is always valid.
+func gbtCoinbaseAux(coinbaseFlags []byte) *btcjson.GetBlockTemplateResultAux {
+	return &btcjson.GetBlockTemplateResultAux{
+		Flags: hex.EncodeToString(builderScript(txscript.
+			NewScriptBuilder().AddData(coinbaseFlags))),
+	}
+}
+
 // blockTemplateResult returns the current block template associated with the
 // state as a btcjson.GetBlockTemplateResult that is ready to be encoded to JSON
 // and returned to the caller.
@@ -1710,7 +3842,7 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 	//  Including MinTime -> time/decrement
 	//  Omitting CoinbaseTxn -> coinbase, generation
 	targetDifficulty := fmt.Sprintf("%064x", blockchain.CompactToBig(header.Bits))
-	templateID := encodeTemplateID(state.prevHash, state.lastGenerated)
+	templateID := encodeTemplateID(state.prevHash, state.txUpdates)
 	reply := btcjson.GetBlockTemplateResult{
 		Bits:         strconv.FormatInt(int64(header.Bits), 16),
 		CurTime:      header.Timestamp.Unix(),
@@ -1729,6 +3861,8 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 		Mutable:      gbtMutableFields,
 		NonceRange:   gbtNonceRange,
 		Capabilities: gbtCapabilities,
+		VbAvailable:  state.vbAvailable,
+		VbRequired:   int(template.SignalBits),
 	}
 	// If the generated block template includes transactions with witness
 	// data, then include the witness commitment in the GBT result.
@@ -1737,7 +3871,7 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 	}
 
 	if useCoinbaseValue {
-		reply.CoinbaseAux = gbtCoinbaseAux
+		reply.CoinbaseAux = gbtCoinbaseAux(template.CoinbaseFlags)
 		reply.CoinbaseValue = &msgBlock.Transactions[0].TxOut[0].Value
 	} else {
 		// Ensure the template has a valid payment address associated
@@ -1781,7 +3915,8 @@ func (state *gbtWorkState) blockTemplateResult(useCoinbaseValue bool, submitOld
 // template in favor of the new one.  In particular, this is the case when the
 // old block template is no longer valid due to a solution already being found
 // and added to the block chain, or new transactions have shown up and some time
-// has passed without finding a solution.
+// has passed without finding a solution.  The current block template is also
+// returned once the request has been waiting for the long poll timeout.
 //
 // See https://en.bitcoin.it/wiki/BIP_0022 for more details.
 func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbaseValue bool, closeChan <-chan struct{}) (interface{}, error) {
@@ -1798,7 +3933,7 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 
 	// Just return the current block template if the long poll ID provided by
 	// the caller is invalid.
-	prevHash, lastGenerated, err := decodeTemplateID(longPollID)
+	prevHash, txUpdates, err := decodeTemplateID(longPollID)
 	if err != nil {
 		result, err := state.blockTemplateResult(useCoinbaseValue, nil)
 		if err != nil {
@@ -1815,7 +3950,7 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 	// template as this means the provided template is stale.
 	prevTemplateHash := &state.template.Block.Header.PrevBlock
 	if !prevHash.IsEqual(prevTemplateHash) ||
-		lastGenerated != state.lastGenerated.Unix() {
+		txUpdates != state.txUpdates {
 
 		// Include whether or not it is valid to submit work against the
 		// old block template depending on whether or not a solution has
@@ -1832,11 +3967,13 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 		return result, nil
 	}
 
-	// Register the previous hash and last generated time for notifications
-	// Get a channel that will be notified when the template associated with
-	// the provided ID is stale and a new block template should be returned to
-	// the caller.
-	longPollChan := state.templateUpdateChan(prevHash, lastGenerated)
+	// Register the previous hash and number of memory pool updates for
+	// notifications.  Get a channel that will be notified when the template
+	// associated with the provided ID is stale and a new block template
+	// should be returned to the caller.
+	longPollChan := state.templateUpdateChan(prevHash, txUpdates)
+	timeout := time.NewTimer(state.longPollTimeout)
+	defer timeout.Stop()
 	state.Unlock()
 
 	select {
@@ -1848,6 +3985,11 @@ func handleGetBlockTemplateLongPoll(s *rpcServer, longPollID string, useCoinbase
 	// Wait until signal received to send the reply.
 	case <-longPollChan:
 		// Fallthrough
+
+	// Reply with the current block template once the request has been
+	// waiting for too long.
+	case <-timeout.C:
+		// Fallthrough
 	}
 
 	// Get the lastest block template
@@ -1939,6 +4081,15 @@ func handleGetBlockTemplateRequest(s *rpcServer, request *btcjson.TemplateReques
 			useCoinbaseValue, closeChan)
 	}
 
+	// The transactions selected by the caller only apply to the template
+	// returned to the caller, so it is generated separately.
+	if request != nil &&
+		(len(request.MustInclude) > 0 || len(request.Exclude) > 0) {
+
+		return handleGetBlockTemplateSelection(s, request,
+			useCoinbaseValue)
+	}
+
 	// Protect concurrent access when updating block templates.
 	state := s.gbtWorkState
 	state.Lock()
@@ -1956,6 +4107,76 @@ func handleGetBlockTemplateRequest(s *rpcServer, request *btcjson.TemplateReques
 	return state.blockTemplateResult(useCoinbaseValue, nil)
 }
 
+// handleGetBlockTemplateSelection is a helper for
+// handleGetBlockTemplateRequest which generates a block template that includes
+// and leaves out the memory pool transactions selected by the caller.  Since
+// the template is specific to the request, a new one is generated each time
+// and the shared block template is left untouched.
+func handleGetBlockTemplateSelection(s *rpcServer, request *btcjson.TemplateRequest, useCoinbaseValue bool) (interface{}, error) {
+	parseTxIDs := func(txIDs []string) ([]chainhash.Hash, error) {
+		hashes := make([]chainhash.Hash, 0, len(txIDs))
+		for _, txID := range txIDs {
+			hash, err := chainhash.NewHashFromStr(txID)
+			if err != nil {
+				return nil, rpcDecodeHexError(txID)
+			}
+			hashes = append(hashes, *hash)
+		}
+		return hashes, nil
+	}
+	mustInclude, err := parseTxIDs(request.MustInclude)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := parseTxIDs(request.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	for i := range mustInclude {
+		if !s.cfg.TxMemPool.IsTransactionInPool(&mustInclude[i]) {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCNoTxInfo,
+				Message: fmt.Sprintf("Transaction %v is not in "+
+					"the memory pool", mustInclude[i]),
+			}
+		}
+	}
+
+	// Choose a payment address at random if the caller requests a full
+	// coinbase as opposed to only the pertinent details needed to create
+	// their own coinbase.
+	var payAddr ltcutil.Address
+	if !useCoinbaseValue {
+		payAddr = cfg.miningAddrs[rand.Intn(len(cfg.miningAddrs))]
+	}
+
+	txUpdates := s.cfg.TxMemPool.TransactionsUpdated()
+	template, err := s.cfg.Generator.NewBlockTemplateWithSelection(payAddr,
+		mustInclude, exclude)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: "Unable to create block template with the " +
+				"selected transactions: " + err.Error(),
+		}
+	}
+	vbAvailable, err := gbtVbAvailable(s, template.Block.Header.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build the result from a work state which only holds the template.
+	state := &gbtWorkState{
+		txUpdates:    txUpdates,
+		prevHash:     &template.Block.Header.PrevBlock,
+		minTimestamp: mining.MinimumMedianTime(s.cfg.Chain.BestSnapshot()),
+		template:     template,
+		vbAvailable:  vbAvailable,
+		timeSource:   s.cfg.TimeSource,
+	}
+	return state.blockTemplateResult(useCoinbaseValue, nil)
+}
+
 // chainErrToGBTErrString converts an error returned from btcchain to a string
 // which matches the reasons and format described in BIP0022 for rejection
 // reasons.
@@ -2090,7 +4311,12 @@ func handleGetBlockTemplateProposal(s *rpcServer, request *btcjson.TemplateReque
 		return "bad-prevblk", nil
 	}
 
-	flags := blockchain.BFDryRun | blockchain.BFNoPoWCheck
+	// The proposed block is typically not solved yet, so only check its
+	// proof of work when requested.
+	flags := blockchain.BFDryRun
+	if !request.CheckPoW {
+		flags |= blockchain.BFNoPoWCheck
+	}
 	isOrphan, err := s.cfg.SyncMgr.SubmitBlock(block, flags)
 	if err != nil {
 		if _, ok := err.(blockchain.RuleError); !ok {
@@ -2139,15 +4365,43 @@ func handleGetBlockTemplate(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	}
 }
 
+// checkCFilterRequest returns an appropriate RPC error when the committed
+// filter index is not enabled or the passed filter type is not one of the
+// known committed filter types.  The basic filter type may also be referred to
+// as the regular filter type.
+func checkCFilterRequest(s *rpcServer, filterType *string) error {
+	if s.cfg.CfIndex == nil {
+		return &btcjson.RPCError{
+			Code: btcjson.ErrRPCMisc,
+			Message: "The committed filter index must be enabled " +
+				"(do not specify --nocfilters)",
+		}
+	}
+
+	switch *filterType {
+	case btcjson.CFilterTypeBasic, "regular":
+		return nil
+	}
+
+	return &btcjson.RPCError{
+		Code:    btcjson.ErrRPCInvalidParameter,
+		Message: "Unknown filter type " + *filterType,
+	}
+}
+
 // handleGetCFilter implements the getcfilter command.
 func handleGetCFilter(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetCFilterCmd)
+	if err := checkCFilterRequest(s, c.FilterType); err != nil {
+		return nil, err
+	}
+
 	hash, err := chainhash.NewHashFromStr(c.Hash)
 	if err != nil {
 		return nil, rpcDecodeHexError(c.Hash)
 	}
 
-	filterBytes, err := s.cfg.CfIndex.FilterByBlockHash(hash, c.Extended)
+	filterBytes, err := s.cfg.CfIndex.FilterByBlockHash(hash, false)
 	if err != nil {
 		rpcsLog.Debugf("Could not find committed filter for %v: %v",
 			hash, err)
@@ -2164,12 +4418,16 @@ func handleGetCFilter(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 // handleGetCFilterHeader implements the getcfilterheader command.
 func handleGetCFilterHeader(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetCFilterHeaderCmd)
+	if err := checkCFilterRequest(s, c.FilterType); err != nil {
+		return nil, err
+	}
+
 	hash, err := chainhash.NewHashFromStr(c.Hash)
 	if err != nil {
 		return nil, rpcDecodeHexError(c.Hash)
 	}
 
-	headerBytes, err := s.cfg.CfIndex.FilterHeaderByBlockHash(hash, c.Extended)
+	headerBytes, err := s.cfg.CfIndex.FilterHeaderByBlockHash(hash, false)
 	if len(headerBytes) > 0 {
 		rpcsLog.Debugf("Found header of committed filter for %v", hash)
 	} else {
@@ -2185,16 +4443,273 @@ func handleGetCFilterHeader(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	return hash.String(), nil
 }
 
+// handleGetBlockFilter implements the getblockfilter command.
+func handleGetBlockFilter(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetBlockFilterCmd)
+	if err := checkCFilterRequest(s, c.FilterType); err != nil {
+		return nil, err
+	}
+
+	hash, err := chainhash.NewHashFromStr(c.BlockHash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.BlockHash)
+	}
+
+	// The header is looked up first since, unlike the filter, it is never
+	// empty for an indexed block.
+	headerBytes, err := s.cfg.CfIndex.FilterHeaderByBlockHash(hash, false)
+	if len(headerBytes) == 0 {
+		rpcsLog.Debugf("Could not find committed filter for %v: %v",
+			hash, err)
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+	filterBytes, err := s.cfg.CfIndex.FilterByBlockHash(hash, false)
+	if err != nil {
+		context := "Failed to fetch committed filter"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	var header chainhash.Hash
+	header.SetBytes(headerBytes)
+	return &btcjson.GetBlockFilterResult{
+		Filter: hex.EncodeToString(filterBytes),
+		Header: header.String(),
+	}, nil
+}
+
+// chainTxStatsBlock houses the details of a block in the main chain which the
+// statistics reported by the getchaintxstats RPC are derived from.
+type chainTxStatsBlock struct {
+	hash       chainhash.Hash
+	height     int32
+	timestamp  time.Time
+	medianTime time.Time
+	totalTxns  uint64
+}
+
+// fetchChainTxStatsBlock returns the details of the block with the given hash
+// in the main chain which the statistics reported by the getchaintxstats RPC
+// are derived from.
+func fetchChainTxStatsBlock(s *rpcServer, hash *chainhash.Hash) (*chainTxStatsBlock, error) {
+	chain := s.cfg.Chain
+	height, err := chain.BlockHeightByHash(hash)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found in main chain",
+		}
+	}
+	header, err := chain.FetchHeader(hash)
+	if err != nil {
+		context := "Failed to fetch block header"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	medianTime, err := chain.BlockMedianTimeByHash(hash)
+	if err != nil {
+		context := "Failed to obtain block median time"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	totalTxns, err := chain.TotalTxnsByHash(hash)
+	if err != nil {
+		context := "Failed to obtain total number of transactions"
+		return nil, internalRPCError(err.Error(), context)
+	}
+
+	return &chainTxStatsBlock{
+		hash:       *hash,
+		height:     height,
+		timestamp:  header.Timestamp,
+		medianTime: medianTime,
+		totalTxns:  totalTxns,
+	}, nil
+}
+
+// calcChainTxStats returns the statistics reported by the getchaintxstats RPC
+// for the window of blocks after the passed window start block up to and
+// including the passed end block.  The window start block is nil when the
+// window is empty.
+//
+// The window interval is measured between the median times of the blocks
+// since, unlike block timestamps, they are guaranteed to increase.
+func calcChainTxStats(end, windowStart *chainTxStatsBlock) *btcjson.GetChainTxStatsResult {
+	result := &btcjson.GetChainTxStatsResult{
+		Time:                   end.timestamp.Unix(),
+		TxCount:                int64(end.totalTxns),
+		WindowFinalBlockHash:   end.hash.String(),
+		WindowFinalBlockHeight: end.height,
+	}
+	if windowStart == nil {
+		return result
+	}
+
+	windowTxns := int64(end.totalTxns - windowStart.totalTxns)
+	interval := end.medianTime.Unix() - windowStart.medianTime.Unix()
+	result.WindowBlockCount = end.height - windowStart.height
+	result.WindowTxCount = &windowTxns
+	result.WindowInterval = &interval
+	if interval > 0 {
+		txRate := float64(windowTxns) / float64(interval)
+		result.TxRate = &txRate
+	}
+	return result
+}
+
+// handleGetChainTxStats implements the getchaintxstats command.
+func handleGetChainTxStats(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetChainTxStatsCmd)
+
+	// Default to the end of the main chain when no block is specified.
+	var hash *chainhash.Hash
+	if c.BlockHash != nil {
+		var err error
+		hash, err = chainhash.NewHashFromStr(*c.BlockHash)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.BlockHash)
+		}
+	} else {
+		hash = &s.cfg.Chain.BestSnapshot().Hash
+	}
+	end, err := fetchChainTxStatsBlock(s, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// Default the window to the number of blocks expected in a month, but
+	// don't extend it beyond the genesis block.
+	var numBlocks int32
+	if c.NumBlocks != nil {
+		numBlocks = *c.NumBlocks
+		if numBlocks < 0 || (numBlocks > 0 && numBlocks >= end.height) {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidParameter,
+				Message: "Invalid block count: should be " +
+					"between 0 and the block's height - 1",
+			}
+		}
+	} else {
+		const month = time.Hour * 24 * 30
+		numBlocks = int32(month / s.cfg.ChainParams.TargetTimePerBlock)
+		if numBlocks >= end.height {
+			numBlocks = end.height - 1
+		}
+		if numBlocks < 0 {
+			numBlocks = 0
+		}
+	}
+	if numBlocks == 0 {
+		return calcChainTxStats(end, nil), nil
+	}
+
+	startHash, err := s.cfg.Chain.BlockHashByHeight(end.height - numBlocks)
+	if err != nil {
+		context := "Failed to obtain window start block hash"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	windowStart, err := fetchChainTxStatsBlock(s, startHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return calcChainTxStats(end, windowStart), nil
+}
+
 // handleGetConnectionCount implements the getconnectioncount command.
 func handleGetConnectionCount(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return s.cfg.ConnMgr.ConnectedCount(), nil
 }
 
+// cacheInfoResult returns the statistics of a verification cache as reported by
+// the getcacheinfo command.
+func cacheInfoResult(stats txscript.CacheStats) btcjson.CacheInfoResult {
+	return btcjson.CacheInfoResult{
+		Entries:    uint64(stats.Entries),
+		MaxEntries: uint64(stats.MaxEntries),
+		Hits:       stats.Hits,
+		Misses:     stats.Misses,
+	}
+}
+
+// handleGetCacheInfo implements the getcacheinfo command.
+func handleGetCacheInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	var result btcjson.GetCacheInfoResult
+	if s.cfg.SigCache != nil {
+		result.SigCache = cacheInfoResult(s.cfg.SigCache.Stats())
+	}
+	if s.cfg.ScriptCache != nil {
+		result.ScriptCache = cacheInfoResult(s.cfg.ScriptCache.Stats())
+	}
+	return &result, nil
+}
+
 // handleGetCurrentNet implements the getcurrentnet command.
 func handleGetCurrentNet(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return s.cfg.ChainParams.Net, nil
 }
 
+// handleGetDeploymentInfo implements the getdeploymentinfo command.
+func handleGetDeploymentInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetDeploymentInfoCmd)
+
+	// Report the deployments after the best block unless a block is
+	// provided.
+	best := s.cfg.Chain.BestSnapshot()
+	var hash *chainhash.Hash
+	result := &btcjson.GetDeploymentInfoResult{
+		Hash:   best.Hash.String(),
+		Height: best.Height,
+	}
+	if c.BlockHash != nil {
+		var err error
+		hash, err = chainhash.NewHashFromStr(*c.BlockHash)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.BlockHash)
+		}
+		height, err := s.cfg.Chain.BlockHeightByHash(hash)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCBlockNotFound,
+				Message: "Block not found",
+			}
+		}
+		result.Hash = hash.String()
+		result.Height = height
+	}
+
+	deployments, err := softForkDescriptions(s, hash, result.Height)
+	if err != nil {
+		return nil, err
+	}
+	result.Deployments = deployments
+	return result, nil
+}
+
+// handleGetDescriptorInfo implements the getdescriptorinfo command.
+func handleGetDescriptorInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetDescriptorInfoCmd)
+
+	// The checksum is optional since one of the purposes of the command is
+	// to calculate it.
+	desc, err := descriptor.Parse(c.Descriptor, s.cfg.ChainParams, false)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid descriptor: " + err.Error(),
+		}
+	}
+
+	// Private keys are not supported by descriptors, so they never have
+	// any.
+	return &btcjson.GetDescriptorInfoResult{
+		Descriptor: desc.String(),
+		Checksum:   desc.Checksum(),
+		IsRange:    desc.IsRange(),
+		IsSolvable: desc.IsSolvable(),
+	}, nil
+}
+
 // handleGetDifficulty implements the getdifficulty command.
 func handleGetDifficulty(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	best := s.cfg.Chain.BestSnapshot()
@@ -2246,10 +4761,47 @@ func handleGetHeaders(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 			return nil, internalRPCError(err.Error(),
 				"Failed to serialize block header")
 		}
-		hexBlockHeaders[i] = hex.EncodeToString(buf.Bytes())
-		buf.Reset()
+		hexBlockHeaders[i] = hex.EncodeToString(buf.Bytes())
+		buf.Reset()
+	}
+	return hexBlockHeaders, nil
+}
+
+// handleGetIndexInfo implements the getindexinfo command.
+func handleGetIndexInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetIndexInfoCmd)
+
+	// Only the enabled indexes are reported.  They are keyed by the names of
+	// the options which enable them.
+	indexes := make(map[string]indexers.Indexer)
+	if s.cfg.TxIndex != nil {
+		indexes["txindex"] = s.cfg.TxIndex
+	}
+	if s.cfg.AddrIndex != nil {
+		indexes["addrindex"] = s.cfg.AddrIndex
+	}
+	if s.cfg.CfIndex != nil {
+		indexes["cfindex"] = s.cfg.CfIndex
+	}
+
+	bestHeight := s.cfg.Chain.BestSnapshot().Height
+	result := make(map[string]btcjson.GetIndexInfoResult)
+	for name, indexer := range indexes {
+		if c.IndexName != nil && *c.IndexName != name {
+			continue
+		}
+
+		_, height, err := indexer.Tip()
+		if err != nil {
+			context := "Failed to fetch index tip"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		result[name] = btcjson.GetIndexInfoResult{
+			Synced:          height >= bestHeight,
+			BestBlockHeight: height,
+		}
 	}
-	return hexBlockHeaders, nil
+	return result, nil
 }
 
 // handleGetInfo implements the getinfo command. We only return the fields
@@ -2271,6 +4823,90 @@ func handleGetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 	return ret, nil
 }
 
+// handleGetMempoolAncestors implements the getmempoolancestors command.
+func handleGetMempoolAncestors(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetMempoolAncestorsCmd)
+
+	// Convert the provided transaction hash hex to a Hash.
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+
+	mp := s.cfg.TxMemPool
+	if c.Verbose != nil && *c.Verbose {
+		result, err := mp.TxAncestorsVerbose(txHash)
+		if err != nil {
+			return nil, rpcNotInMempoolError(txHash)
+		}
+		return result, nil
+	}
+
+	// The response is simply an array of the transaction hashes if the
+	// verbose flag is not set.
+	ancestors, err := mp.TxAncestors(txHash)
+	if err != nil {
+		return nil, rpcNotInMempoolError(txHash)
+	}
+	hashStrings := make([]string, len(ancestors))
+	for i := range hashStrings {
+		hashStrings[i] = ancestors[i].Tx.Hash().String()
+	}
+
+	return hashStrings, nil
+}
+
+// handleGetMempoolDescendants implements the getmempooldescendants command.
+func handleGetMempoolDescendants(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetMempoolDescendantsCmd)
+
+	// Convert the provided transaction hash hex to a Hash.
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+
+	mp := s.cfg.TxMemPool
+	if c.Verbose != nil && *c.Verbose {
+		result, err := mp.TxDescendantsVerbose(txHash)
+		if err != nil {
+			return nil, rpcNotInMempoolError(txHash)
+		}
+		return result, nil
+	}
+
+	// The response is simply an array of the transaction hashes if the
+	// verbose flag is not set.
+	descendants, err := mp.TxDescendants(txHash)
+	if err != nil {
+		return nil, rpcNotInMempoolError(txHash)
+	}
+	hashStrings := make([]string, len(descendants))
+	for i := range hashStrings {
+		hashStrings[i] = descendants[i].Tx.Hash().String()
+	}
+
+	return hashStrings, nil
+}
+
+// handleGetMempoolEntry implements the getmempoolentry command.
+func handleGetMempoolEntry(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetMempoolEntryCmd)
+
+	// Convert the provided transaction hash hex to a Hash.
+	txHash, err := chainhash.NewHashFromStr(c.TxID)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.TxID)
+	}
+
+	entry, err := s.cfg.TxMemPool.MempoolEntry(txHash)
+	if err != nil {
+		return nil, rpcNotInMempoolError(txHash)
+	}
+
+	return entry, nil
+}
+
 // handleGetMempoolInfo implements the getmempoolinfo command.
 func handleGetMempoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	mempoolTxns := s.cfg.TxMemPool.TxDescs()
@@ -2281,8 +4917,13 @@ func handleGetMempoolInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct
 	}
 
 	ret := &btcjson.GetMempoolInfoResult{
-		Size:  int64(len(mempoolTxns)),
-		Bytes: numBytes,
+		Size:             int64(len(mempoolTxns)),
+		Bytes:            numBytes,
+		Orphans:          int64(s.cfg.TxMemPool.OrphanCount()),
+		MaxMempool:       int64(cfg.MaxMempool) * 1000000,
+		MempoolMinFee:    s.cfg.TxMemPool.MinFee().ToBTC(),
+		MinRelayTxFee:    cfg.minRelayTxFee.ToBTC(),
+		UnbroadcastCount: int64(s.cfg.TxMemPool.UnbroadcastCount()),
 	}
 
 	return ret, nil
@@ -2299,27 +4940,31 @@ func handleGetMiningInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	if err != nil {
 		return nil, err
 	}
-	networkHashesPerSec, ok := networkHashesPerSecIface.(int64)
+	networkHashesPerSec, ok := networkHashesPerSecIface.(float64)
 	if !ok {
 		return nil, &btcjson.RPCError{
 			Code:    btcjson.ErrRPCInternal.Code,
-			Message: "networkHashesPerSec is not an int64",
+			Message: "networkHashesPerSec is not a float64",
 		}
 	}
 
 	best := s.cfg.Chain.BestSnapshot()
+	warnings := s.cfg.Chain.Warnings()
 	result := btcjson.GetMiningInfoResult{
 		Blocks:             int64(best.Height),
+		Chain:              s.cfg.ChainParams.Name,
 		CurrentBlockSize:   best.BlockSize,
 		CurrentBlockWeight: best.BlockWeight,
 		CurrentBlockTx:     best.NumTxns,
 		Difficulty:         getDifficultyRatio(best.Bits, s.cfg.ChainParams),
+		Errors:             warnings,
 		Generate:           s.cfg.CPUMiner.IsMining(),
 		GenProcLimit:       s.cfg.CPUMiner.NumWorkers(),
 		HashesPerSec:       int64(s.cfg.CPUMiner.HashesPerSecond()),
 		NetworkHashPS:      networkHashesPerSec,
 		PooledTx:           uint64(s.cfg.TxMemPool.Count()),
-		TestNet:            cfg.TestNet4,
+		TestNet:            s.cfg.ChainParams.Net == wire.TestNet4,
+		Warnings:           warnings,
 	}
 	return &result, nil
 }
@@ -2337,8 +4982,8 @@ func handleGetNetTotals(s *rpcServer, cmd interface{}, closeChan <-chan struct{}
 
 // handleGetNetworkHashPS implements the getnetworkhashps command.
 func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
-	// Note: All valid error return paths should return an int64.
-	// Literal zeros are inferred as int, and won't coerce to int64
+	// Note: All valid error return paths should return a float64.
+	// Literal zeros are inferred as int, and won't coerce to float64
 	// because the return value is an interface{}.
 
 	c := cmd.(*btcjson.GetNetworkHashPSCmd)
@@ -2353,7 +4998,7 @@ func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan stru
 		endHeight = int32(*c.Height)
 	}
 	if endHeight > best.Height || endHeight == 0 {
-		return int64(0), nil
+		return float64(0), nil
 	}
 	if endHeight < 0 {
 		endHeight = best.Height
@@ -2385,10 +5030,20 @@ func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan stru
 		startHeight, endHeight)
 
 	// Find the min and max block timestamps as well as calculate the total
-	// amount of work that happened between the start and end blocks.
+	// amount of work that happened between the start and end blocks.  The
+	// work of a block is the expected number of scrypt hashes required to
+	// find it given its target difficulty.
 	var minTimestamp, maxTimestamp time.Time
 	totalWork := big.NewInt(0)
 	for curHeight := startHeight; curHeight <= endHeight; curHeight++ {
+		// Stop when the client disconnects or the call times out since
+		// this may span the entire chain.
+		select {
+		case <-closeChan:
+			return nil, ErrClientQuit
+		default:
+		}
+
 		hash, err := s.cfg.Chain.BlockHashByHeight(curHeight)
 		if err != nil {
 			context := "Failed to fetch block hash"
@@ -2421,12 +5076,83 @@ func handleGetNetworkHashPS(s *rpcServer, cmd interface{}, closeChan <-chan stru
 	// timestamps and avoid division by zero in the case where there is no
 	// time difference.
 	timeDiff := int64(maxTimestamp.Sub(minTimestamp) / time.Second)
-	if timeDiff == 0 {
-		return int64(0), nil
+	if timeDiff <= 0 {
+		return float64(0), nil
 	}
 
-	hashesPerSec := new(big.Int).Div(totalWork, big.NewInt(timeDiff))
-	return hashesPerSec.Int64(), nil
+	// The rate is calculated with floating point since networks with low
+	// difficulty such as the test networks can have rates below one hash
+	// per second.
+	hashesPerSec, _ := new(big.Float).Quo(new(big.Float).SetInt(totalWork),
+		new(big.Float).SetInt64(timeDiff)).Float64()
+	return hashesPerSec, nil
+}
+
+// handleAddPeerAddress implements the addpeeraddress command.
+func handleAddPeerAddress(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.AddPeerAddressCmd)
+
+	// The address must be a literal IP address.  Addresses which are not
+	// routable are rejected since the address manager never stores them.
+	ip := net.ParseIP(c.Address)
+	if ip == nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Invalid IP address %q", c.Address),
+		}
+	}
+	if c.Port == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Port must not be zero",
+		}
+	}
+	na := wire.NewNetAddressIPPort(ip, c.Port, wire.SFNodeNetwork)
+	if !addrmgr.IsRoutable(na) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Address %s is not routable", ip),
+		}
+	}
+
+	tried := c.Tried != nil && *c.Tried
+	success := s.cfg.ConnMgr.AddPeerAddress(na, tried)
+	return &btcjson.AddPeerAddressResult{Success: success}, nil
+}
+
+// handleGetNodeAddresses implements the getnodeaddresses command.
+func handleGetNodeAddresses(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetNodeAddressesCmd)
+
+	count := 1
+	if c.Count != nil {
+		count = *c.Count
+	}
+	if count < 1 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Address count out of range",
+		}
+	}
+
+	// Only addresses which have been connected to successfully are
+	// returned and the address manager limits their number so the full
+	// table of known addresses is not leaked.
+	addrs := s.cfg.ConnMgr.NodeAddresses(count)
+	results := make([]btcjson.GetNodeAddressesResult, 0, len(addrs))
+	for _, na := range addrs {
+		host, _, err := net.SplitHostPort(addrmgr.NetAddressKey(na))
+		if err != nil {
+			return nil, internalRPCError(err.Error(), "")
+		}
+		results = append(results, btcjson.GetNodeAddressesResult{
+			Time:     na.Timestamp.Unix(),
+			Services: uint64(na.Services),
+			Address:  host,
+			Port:     na.Port,
+		})
+	}
+	return results, nil
 }
 
 // handleGetPeerInfo implements the getpeerinfo command.
@@ -2437,26 +5163,29 @@ func handleGetPeerInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	for _, p := range peers {
 		statsSnap := p.ToPeer().StatsSnapshot()
 		info := &btcjson.GetPeerInfoResult{
-			ID:             statsSnap.ID,
-			Addr:           statsSnap.Addr,
-			AddrLocal:      p.ToPeer().LocalAddr().String(),
-			Services:       fmt.Sprintf("%08d", uint64(statsSnap.Services)),
-			RelayTxes:      !p.IsTxRelayDisabled(),
-			LastSend:       statsSnap.LastSend.Unix(),
-			LastRecv:       statsSnap.LastRecv.Unix(),
-			BytesSent:      statsSnap.BytesSent,
-			BytesRecv:      statsSnap.BytesRecv,
-			ConnTime:       statsSnap.ConnTime.Unix(),
-			PingTime:       float64(statsSnap.LastPingMicros),
-			TimeOffset:     statsSnap.TimeOffset,
-			Version:        statsSnap.Version,
-			SubVer:         statsSnap.UserAgent,
-			Inbound:        statsSnap.Inbound,
-			StartingHeight: statsSnap.StartingHeight,
-			CurrentHeight:  statsSnap.LastBlock,
-			BanScore:       int32(p.BanScore()),
-			FeeFilter:      p.FeeFilter(),
-			SyncNode:       statsSnap.ID == syncPeerID,
+			ID:              statsSnap.ID,
+			Addr:            statsSnap.Addr,
+			AddrLocal:       p.ToPeer().LocalAddr().String(),
+			Services:        fmt.Sprintf("%08d", uint64(statsSnap.Services)),
+			RelayTxes:       !p.IsTxRelayDisabled(),
+			LastSend:        statsSnap.LastSend.Unix(),
+			LastRecv:        statsSnap.LastRecv.Unix(),
+			BytesSent:       statsSnap.BytesSent,
+			BytesRecv:       statsSnap.BytesRecv,
+			BytesSentPerMsg: p.BytesSentPerMsg(),
+			BytesRecvPerMsg: p.BytesRecvPerMsg(),
+			ConnTime:        statsSnap.ConnTime.Unix(),
+			PingTime:        float64(statsSnap.LastPingMicros),
+			MinPing:         float64(statsSnap.MinPingMicros),
+			TimeOffset:      statsSnap.TimeOffset,
+			Version:         statsSnap.Version,
+			SubVer:          statsSnap.UserAgent,
+			Inbound:         statsSnap.Inbound,
+			StartingHeight:  statsSnap.StartingHeight,
+			CurrentHeight:   statsSnap.LastBlock,
+			BanScore:        int32(p.BanScore()),
+			FeeFilter:       p.FeeFilter(),
+			SyncNode:        statsSnap.ID == syncPeerID,
 		}
 		if p.ToPeer().LastPingNonce() != 0 {
 			wait := float64(time.Since(statsSnap.LastPingTime).Nanoseconds())
@@ -2473,10 +5202,34 @@ func handleGetRawMempool(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	c := cmd.(*btcjson.GetRawMempoolCmd)
 	mp := s.cfg.TxMemPool
 
-	if c.Verbose != nil && *c.Verbose {
+	verbose := c.Verbose != nil && *c.Verbose
+	mempoolSequence := c.MempoolSequence != nil && *c.MempoolSequence
+	if verbose && mempoolSequence {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: "Verbose results cannot contain mempool " +
+				"sequence values",
+		}
+	}
+	if verbose {
 		return mp.RawMempoolVerbose(), nil
 	}
 
+	// The transaction hashes are returned along with the mempool sequence
+	// they are current as of when it is requested so clients can detect
+	// the changes they missed.
+	if mempoolSequence {
+		hashes, sequence := mp.TxHashesWithSequence()
+		txIDs := make([]string, len(hashes))
+		for i, hash := range hashes {
+			txIDs[i] = hash.String()
+		}
+		return &btcjson.GetRawMempoolSequenceResult{
+			TxIDs:           txIDs,
+			MempoolSequence: sequence,
+		}, nil
+	}
+
 	// The response is simply an array of the transaction hashes if the
 	// verbose flag is not set.
 	descs := mp.TxDescs()
@@ -2498,9 +5251,13 @@ func handleGetRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan str
 		return nil, rpcDecodeHexError(c.Txid)
 	}
 
+	// Verbosity level 2 adds the outputs spent by the transaction along
+	// with its fee to the verbose result.
 	verbose := false
+	verbosePrevOut := false
 	if c.Verbose != nil {
 		verbose = *c.Verbose != 0
+		verbosePrevOut = *c.Verbose >= 2
 	}
 
 	// Try to fetch the transaction from the memory pool and if that fails,
@@ -2604,9 +5361,31 @@ func handleGetRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan str
 	if err != nil {
 		return nil, err
 	}
+	if verbosePrevOut && !blockchain.IsCoinBaseTx(mtx) {
+		prevOuts, err := fetchTxPrevOuts(s, mtx)
+		if err != nil {
+			return nil, err
+		}
+		addTxRawResultPrevOuts(rawTxn, mtx, prevOuts, s.cfg.ChainParams)
+	}
 	return *rawTxn, nil
 }
 
+// handleGetSyncStatus implements the getsyncstatus command.
+func handleGetSyncStatus(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	status := s.cfg.SyncMgr.SyncStatus()
+	return &btcjson.GetSyncStatusResult{
+		Phase:                status.phase.String(),
+		Headers:              status.headerHeight,
+		Blocks:               status.blockHeight,
+		SyncHeight:           status.syncHeight,
+		DownloadWindowStart:  status.windowStart,
+		DownloadWindowEnd:    status.windowEnd,
+		BlocksInFlight:       status.blocksInFlight,
+		VerificationProgress: status.progress,
+	}, nil
+}
+
 // handleGetTxOut handles gettxout commands.
 func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.GetTxOutCmd)
@@ -2637,83 +5416,418 @@ func handleGetTxOut(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (i
 			return nil, rpcNoTxInfoError(txHash)
 		}
 
-		mtx := tx.MsgTx()
-		if c.Vout > uint32(len(mtx.TxOut)-1) {
-			return nil, &btcjson.RPCError{
-				Code: btcjson.ErrRPCInvalidTxVout,
-				Message: "Output index number (vout) does not " +
-					"exist for transaction.",
+		mtx := tx.MsgTx()
+		if c.Vout > uint32(len(mtx.TxOut)-1) {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidTxVout,
+				Message: "Output index number (vout) does not " +
+					"exist for transaction.",
+			}
+		}
+
+		txOut := mtx.TxOut[c.Vout]
+		if txOut == nil {
+			errStr := fmt.Sprintf("Output index: %d for txid: %s "+
+				"does not exist", c.Vout, txHash)
+			return nil, internalRPCError(errStr, "")
+		}
+
+		best := s.cfg.Chain.BestSnapshot()
+		bestBlockHash = best.Hash.String()
+		confirmations = 0
+		txVersion = mtx.Version
+		value = txOut.Value
+		pkScript = txOut.PkScript
+		isCoinbase = blockchain.IsCoinBaseTx(mtx)
+	} else {
+		entry, err := s.cfg.Chain.FetchUtxoEntry(txHash)
+		if err != nil {
+			return nil, rpcNoTxInfoError(txHash)
+		}
+
+		// To match the behavior of the reference client, return nil
+		// (JSON null) if the transaction output is spent by another
+		// transaction already in the main chain.  Mined transactions
+		// that are spent by a mempool transaction are not affected by
+		// this.
+		if entry == nil || entry.IsOutputSpent(c.Vout) {
+			return nil, nil
+		}
+
+		best := s.cfg.Chain.BestSnapshot()
+		bestBlockHash = best.Hash.String()
+		confirmations = 1 + best.Height - entry.BlockHeight()
+		txVersion = entry.Version()
+		value = entry.AmountByIndex(c.Vout)
+		pkScript = entry.PkScriptByIndex(c.Vout)
+		isCoinbase = entry.IsCoinBase()
+	}
+
+	// Disassemble script into single line printable format.
+	// The disassembled string will contain [error] inline if the script
+	// doesn't fully parse, so ignore the error here.
+	disbuf, _ := txscript.DisasmString(pkScript)
+
+	// Get further info about the script.
+	// Ignore the error here since an error means the script couldn't parse
+	// and there is no additional information about it anyways.
+	scriptClass, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(pkScript,
+		s.cfg.ChainParams)
+	addresses := make([]string, len(addrs))
+	for i, addr := range addrs {
+		addresses[i] = addr.EncodeAddress()
+	}
+
+	txOutReply := &btcjson.GetTxOutResult{
+		BestBlock:     bestBlockHash,
+		Confirmations: int64(confirmations),
+		Value:         ltcutil.Amount(value).ToBTC(),
+		Version:       txVersion,
+		ScriptPubKey: btcjson.ScriptPubKeyResult{
+			Asm:       disbuf,
+			Hex:       hex.EncodeToString(pkScript),
+			ReqSigs:   int32(reqSigs),
+			Type:      scriptClass.String(),
+			Addresses: addresses,
+		},
+		Coinbase: isCoinbase,
+	}
+	return txOutReply, nil
+}
+
+// handleGetTxOutProof implements the gettxoutproof command.
+func handleGetTxOutProof(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetTxOutProofCmd)
+
+	// Parse the transaction hashes while rejecting duplicates.
+	if len(c.TxIDs) == 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Parameter 'txids' cannot be empty",
+		}
+	}
+	txHashes := make(map[chainhash.Hash]struct{}, len(c.TxIDs))
+	orderedHashes := make([]*chainhash.Hash, 0, len(c.TxIDs))
+	for _, txID := range c.TxIDs {
+		txHash, err := chainhash.NewHashFromStr(txID)
+		if err != nil {
+			return nil, rpcDecodeHexError(txID)
+		}
+		if _, ok := txHashes[*txHash]; ok {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidParameter,
+				Message: fmt.Sprintf("Invalid parameter, "+
+					"duplicated txid: %v", txHash),
+			}
+		}
+		txHashes[*txHash] = struct{}{}
+		orderedHashes = append(orderedHashes, txHash)
+	}
+
+	// Use the provided block or locate the block which contains the
+	// transactions via the transaction index otherwise.
+	var blockHash *chainhash.Hash
+	if c.BlockHash != nil {
+		var err error
+		blockHash, err = chainhash.NewHashFromStr(*c.BlockHash)
+		if err != nil {
+			return nil, rpcDecodeHexError(*c.BlockHash)
+		}
+	} else {
+		if s.cfg.TxIndex == nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCNoTxInfo,
+				Message: "The transaction index must be " +
+					"enabled to locate the block containing " +
+					"the transactions (specify --txindex) " +
+					"unless a block hash is provided",
+			}
+		}
+		for _, txHash := range orderedHashes {
+			blockRegion, err := s.cfg.TxIndex.TxBlockRegion(txHash)
+			if err != nil {
+				context := "Failed to retrieve transaction location"
+				return nil, internalRPCError(err.Error(), context)
+			}
+			if blockRegion != nil {
+				blockHash = blockRegion.Hash
+				break
+			}
+		}
+		if blockHash == nil {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidAddressOrKey,
+				Message: "Transaction not yet in block",
+			}
+		}
+	}
+
+	// Load the block and ensure it contains all of the transactions.
+	var blkBytes []byte
+	err := s.cfg.DB.View(func(dbTx database.Tx) error {
+		var err error
+		blkBytes, err = dbTx.FetchBlock(blockHash)
+		return err
+	})
+	if err != nil {
+		return nil, rpcBlockUnavailableError(s, blockHash)
+	}
+	block, err := ltcutil.NewBlockFromBytes(blkBytes)
+	if err != nil {
+		context := "Failed to deserialize block"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	var numFound int
+	for _, tx := range block.Transactions() {
+		if _, ok := txHashes[*tx.Hash()]; ok {
+			numFound++
+		}
+	}
+	if numFound != len(txHashes) {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Not all transactions found in specified or " +
+				"retrieved block",
+		}
+	}
+
+	// Serialize the proof and return it as a hex-encoded string.
+	var buf bytes.Buffer
+	proof := newTxOutProof(block, txHashes)
+	err = proof.BtcEncode(&buf, wire.ProtocolVersion, wire.BaseEncoding)
+	if err != nil {
+		context := "Failed to serialize proof"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+// writeUtxoSetHashEntry writes the serialization of the passed unspent
+// transaction output which is committed to by the hash of the utxo set
+// returned by the gettxoutsetinfo command to the passed writer.
+//
+// The serialized format is:
+//
+//   <outpoint hash><outpoint index><height code><amount><pk script>
+//
+//   Field            Type     Size
+//   outpoint hash    [32]byte 32
+//   outpoint index   uint32   4
+//   height code      VarInt   variable
+//   amount           uint64   8
+//   pk script        VarBytes variable
+//
+// The height code is the height of the block containing the output shifted
+// left one bit with the lowest bit set when the output belongs to a coinbase.
+// All integers are little endian.
+func writeUtxoSetHashEntry(w io.Writer, outPoint *wire.OutPoint, entry *blockchain.UtxoEntry) error {
+	var buf [8]byte
+	if _, err := w.Write(outPoint.Hash[:]); err != nil {
+		return err
+	}
+	binary.LittleEndian.PutUint32(buf[:4], outPoint.Index)
+	if _, err := w.Write(buf[:4]); err != nil {
+		return err
+	}
+
+	heightCode := uint64(entry.BlockHeight()) << 1
+	if entry.IsCoinBase() {
+		heightCode |= 0x01
+	}
+	if err := wire.WriteVarInt(w, 0, heightCode); err != nil {
+		return err
+	}
+
+	amount := entry.AmountByIndex(outPoint.Index)
+	binary.LittleEndian.PutUint64(buf[:], uint64(amount))
+	if _, err := w.Write(buf[:]); err != nil {
+		return err
+	}
+	return wire.WriteVarBytes(w, 0, entry.PkScriptByIndex(outPoint.Index))
+}
+
+// handleGetTxOutSetInfo implements the gettxoutsetinfo command.
+func handleGetTxOutSetInfo(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.GetTxOutSetInfoCmd)
+
+	hashType := btcjson.TxOutSetHashTypeSerialized
+	if c.HashType != nil {
+		hashType = *c.HashType
+	}
+	var hasher hash.Hash
+	switch hashType {
+	case btcjson.TxOutSetHashTypeSerialized:
+		hasher = sha256.New()
+	case btcjson.TxOutSetHashTypeNone:
+	default:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Unknown hash type " + hashType,
+		}
+	}
+
+	// Iterate the entire utxo set, which is streamed from the database,
+	// while tallying the outputs and hashing them as requested.  The
+	// outputs of a transaction are visited consecutively, so the number of
+	// transactions is the number of times the transaction hash changes.
+	var result btcjson.GetTxOutSetInfoResult
+	var prevHash chainhash.Hash
+	bestHash, bestHeight, err := s.cfg.Chain.ForEachUtxo(func(outPoint *wire.OutPoint, entry *blockchain.UtxoEntry) error {
+		// Stop iterating when the client disconnects since this can
+		// take quite a while and there is nobody left to receive the
+		// result.
+		if result.TxOuts%1000 == 0 {
+			select {
+			case <-closeChan:
+				return ErrClientQuit
+			default:
 			}
 		}
 
-		txOut := mtx.TxOut[c.Vout]
-		if txOut == nil {
-			errStr := fmt.Sprintf("Output index: %d for txid: %s "+
-				"does not exist", c.Vout, txHash)
-			return nil, internalRPCError(errStr, "")
+		if result.TxOuts == 0 || outPoint.Hash != prevHash {
+			result.Transactions++
+			prevHash = outPoint.Hash
 		}
+		result.TxOuts++
+		result.TotalAmountLitoshi += entry.AmountByIndex(outPoint.Index)
 
-		best := s.cfg.Chain.BestSnapshot()
-		bestBlockHash = best.Hash.String()
-		confirmations = 0
-		txVersion = mtx.Version
-		value = txOut.Value
-		pkScript = txOut.PkScript
-		isCoinbase = blockchain.IsCoinBaseTx(mtx)
-	} else {
-		entry, err := s.cfg.Chain.FetchUtxoEntry(txHash)
-		if err != nil {
-			return nil, rpcNoTxInfoError(txHash)
+		if hasher == nil {
+			return nil
 		}
+		return writeUtxoSetHashEntry(hasher, outPoint, entry)
+	})
+	if err == ErrClientQuit {
+		return nil, err
+	}
+	if err != nil {
+		context := "Failed to iterate the utxo set"
+		return nil, internalRPCError(err.Error(), context)
+	}
 
-		// To match the behavior of the reference client, return nil
-		// (JSON null) if the transaction output is spent by another
-		// transaction already in the main chain.  Mined transactions
-		// that are spent by a mempool transaction are not affected by
-		// this.
-		if entry == nil || entry.IsOutputSpent(c.Vout) {
-			return nil, nil
+	result.Height = int64(bestHeight)
+	result.BestBlock = bestHash.String()
+	result.TotalAmount = ltcutil.Amount(result.TotalAmountLitoshi).ToBTC()
+	if hasher != nil {
+		utxoSetHash := chainhash.Hash(sha256.Sum256(hasher.Sum(nil)))
+		result.HashSerialized = utxoSetHash.String()
+	}
+	return &result, nil
+}
+
+// utxoSnapshotPath returns the path of the utxo snapshot file with the passed
+// name, which is relative to the data directory unless it is absolute.
+func utxoSnapshotPath(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(cfg.DataDir, name)
+}
+
+// closeChanWriter is a writer which fails with ErrClientQuit once its close
+// channel is closed, which allows aborting long-running writes.
+type closeChanWriter struct {
+	w         io.Writer
+	closeChan <-chan struct{}
+}
+
+// Write writes the passed bytes to the underlying writer unless the close
+// channel is closed.
+//
+// This is part of the io.Writer interface.
+func (w *closeChanWriter) Write(p []byte) (int, error) {
+	select {
+	case <-w.closeChan:
+		return 0, ErrClientQuit
+	default:
+	}
+	return w.w.Write(p)
+}
+
+// handleDumpTxOutSet implements the dumptxoutset command.
+func handleDumpTxOutSet(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.DumpTxOutSetCmd)
+
+	// Refuse to overwrite an existing file.  The snapshot is written to a
+	// temporary file which is only renamed once it is complete so an
+	// interrupted dump never leaves a truncated snapshot behind.
+	path := utxoSnapshotPath(c.Path)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: path + " already exists",
 		}
+	}
+	tmpPath := path + ".incomplete"
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL,
+		0600)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
 
-		best := s.cfg.Chain.BestSnapshot()
-		bestBlockHash = best.Hash.String()
-		confirmations = 1 + best.Height - entry.BlockHeight()
-		txVersion = entry.Version()
-		value = entry.AmountByIndex(c.Vout)
-		pkScript = entry.PkScriptByIndex(c.Vout)
-		isCoinbase = entry.IsCoinBase()
+	// Stop writing the snapshot when the client disconnects or the call
+	// times out, which leaves nothing behind.
+	w := bufio.NewWriter(&closeChanWriter{w: file, closeChan: closeChan})
+	snapshot, err := s.cfg.Chain.DumpUtxoSnapshot(w)
+	if err == ErrClientQuit {
+		file.Close()
+		os.Remove(tmpPath)
+		return nil, err
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		context := "Failed to dump the utxo set"
+		return nil, internalRPCError(err.Error(), context)
 	}
 
-	// Disassemble script into single line printable format.
-	// The disassembled string will contain [error] inline if the script
-	// doesn't fully parse, so ignore the error here.
-	disbuf, _ := txscript.DisasmString(pkScript)
+	return &btcjson.DumpTxOutSetResult{
+		CoinsWritten: snapshot.NumCoins,
+		BaseHash:     snapshot.BaseHash.String(),
+		BaseHeight:   snapshot.BaseHeight,
+		Path:         path,
+		TxOutSetHash: snapshot.Commitment.String(),
+	}, nil
+}
 
-	// Get further info about the script.
-	// Ignore the error here since an error means the script couldn't parse
-	// and there is no additional information about it anyways.
-	scriptClass, addrs, reqSigs, _ := txscript.ExtractPkScriptAddrs(pkScript,
-		s.cfg.ChainParams)
-	addresses := make([]string, len(addrs))
-	for i, addr := range addrs {
-		addresses[i] = addr.EncodeAddress()
+// handleLoadTxOutSet implements the loadtxoutset command.
+func handleLoadTxOutSet(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.LoadTxOutSetCmd)
+
+	path := utxoSnapshotPath(c.Path)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
 	}
+	defer file.Close()
 
-	txOutReply := &btcjson.GetTxOutResult{
-		BestBlock:     bestBlockHash,
-		Confirmations: int64(confirmations),
-		Value:         ltcutil.Amount(value).ToBTC(),
-		Version:       txVersion,
-		ScriptPubKey: btcjson.ScriptPubKeyResult{
-			Asm:       disbuf,
-			Hex:       hex.EncodeToString(pkScript),
-			ReqSigs:   int32(reqSigs),
-			Type:      scriptClass.String(),
-			Addresses: addresses,
-		},
-		Coinbase: isCoinbase,
+	snapshot, err := s.cfg.Chain.LoadUtxoSnapshot(bufio.NewReader(file))
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCMisc,
+			Message: "Unable to load utxo snapshot: " + err.Error(),
+		}
 	}
-	return txOutReply, nil
+
+	return &btcjson.LoadTxOutSetResult{
+		CoinsLoaded: snapshot.NumCoins,
+		TipHash:     snapshot.BaseHash.String(),
+		BaseHeight:  snapshot.BaseHeight,
+		Path:        path,
+	}, nil
 }
 
 // handleHelp implements the help command.
@@ -2755,6 +5869,28 @@ func handleHelp(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return help, nil
 }
 
+// handleInvalidateBlock implements the invalidateblock command.
+func handleInvalidateBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.InvalidateBlockCmd)
+
+	hash, err := chainhash.NewHashFromStr(c.BlockHash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.BlockHash)
+	}
+	if _, err := s.cfg.Chain.FetchHeader(hash); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+
+	if err := s.cfg.Chain.InvalidateBlock(hash); err != nil {
+		context := "Failed to invalidate block"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return nil, nil
+}
+
 // handlePing implements the ping command.
 func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	// Ask server to ping \o_
@@ -2768,6 +5904,87 @@ func handlePing(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (inter
 	return nil, nil
 }
 
+// handlePruneBlockChain implements the pruneblockchain command.
+func handlePruneBlockChain(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.PruneBlockChainCmd)
+
+	chain := s.cfg.Chain
+	if !chain.PruneEnabled() {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCMisc,
+			Message: "Cannot prune blocks because pruning is not " +
+				"enabled (specify --prune)",
+		}
+	}
+	if s.cfg.TxIndex != nil || s.cfg.AddrIndex != nil {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCMisc,
+			Message: "Cannot prune blocks because the transaction " +
+				"and address indexes require them",
+		}
+	}
+	if c.Height < 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Negative block height",
+		}
+	}
+
+	// Values above a billion are far too high to be a block height and
+	// too low to be a recent block time, so they are treated as a unix
+	// timestamp which is resolved to the first block created no more than
+	// two hours before it to allow for blocks with old timestamps.
+	height := c.Height
+	if height > 1000000000 {
+		t := time.Unix(height, 0).Add(-2 * time.Hour)
+		blockHeight, err := chain.HeightByTime(t)
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCInvalidParameter,
+				Message: "Could not find block with at least " +
+					"the specified timestamp",
+			}
+		}
+		height = int64(blockHeight)
+	}
+	if height > int64(chain.BestSnapshot().Height) {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: "Blockchain is shorter than the attempted " +
+				"prune height",
+		}
+	}
+
+	pruneHeight, err := chain.PruneToHeight(int32(height))
+	if err != nil {
+		context := "Failed to prune blocks"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return pruneHeight, nil
+}
+
+// handleReconsiderBlock implements the reconsiderblock command.
+func handleReconsiderBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.ReconsiderBlockCmd)
+
+	hash, err := chainhash.NewHashFromStr(c.BlockHash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.BlockHash)
+	}
+	if _, err := s.cfg.Chain.FetchHeader(hash); err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCBlockNotFound,
+			Message: "Block not found",
+		}
+	}
+
+	if err := s.cfg.Chain.ReconsiderBlock(hash); err != nil {
+		context := "Failed to reconsider block"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return nil, nil
+}
+
 // retrievedTx represents a transaction that was either loaded from the
 // transaction memory pool or from the database.  When a transaction is loaded
 // from the database, it is loaded with the raw serialized bytes while the
@@ -2944,49 +6161,312 @@ func createVinListPrevOut(s *rpcServer, mtx *wire.MsgTx, chainParams *chaincfg.P
 			}
 		}
 
-		// Ignore the entry if it doesn't pass the filter.
-		if !passesFilter {
-			continue
+		// Ignore the entry if it doesn't pass the filter.
+		if !passesFilter {
+			continue
+		}
+
+		// Add entry to the list if it wasn't already done above.
+		if len(filterAddrMap) != 0 {
+			vinList = append(vinList, vinEntry)
+		}
+
+		// Update the entry with previous output information if
+		// requested.
+		if vinExtra {
+			vinListEntry := &vinList[len(vinList)-1]
+			vinListEntry.PrevOut = &btcjson.PrevOut{
+				Addresses: encodedAddrs,
+				Value:     ltcutil.Amount(originTxOut.Value).ToBTC(),
+			}
+		}
+	}
+
+	return vinList, nil
+}
+
+// fetchMempoolTxnsForAddress queries the address index for all unconfirmed
+// transactions of the given types that involve the provided address.  The
+// results are ordered by when the transactions were added to the mempool,
+// oldest first unless the reverse flag is set, and will be limited by the
+// number to skip and the number requested.
+func fetchMempoolTxnsForAddress(s *rpcServer, addr ltcutil.Address, txType indexers.AddrTxType, numToSkip, numRequested uint32, reverse bool) ([]*ltcutil.Tx, uint32) {
+	// There are no entries to return when there are less available than the
+	// number being skipped.
+	mpTxns := s.cfg.AddrIndex.UnconfirmedTxnsForAddress(addr, txType)
+	numAvailable := uint32(len(mpTxns))
+	if numToSkip > numAvailable {
+		return nil, numAvailable
+	}
+
+	// Filter the available entries based on the number to skip and number
+	// requested.
+	rangeEnd := numToSkip + numRequested
+	if rangeEnd > numAvailable {
+		rangeEnd = numAvailable
+	}
+	if !reverse {
+		return mpTxns[numToSkip:rangeEnd], numToSkip
+	}
+
+	// Take the entries from the end of the available entries in reverse
+	// order when the reverse flag is set.
+	results := make([]*ltcutil.Tx, 0, rangeEnd-numToSkip)
+	for i := numToSkip; i < rangeEnd; i++ {
+		results = append(results, mpTxns[numAvailable-i-1])
+	}
+	return results, numToSkip
+}
+
+// errUtxoScanAborted is returned from the function passed to ForEachUtxo to
+// stop a utxo set scan that was requested to be aborted.
+var errUtxoScanAborted = errors.New("utxo set scan aborted")
+
+// utxoScanState houses the state of the utxo set scan performed by the
+// scantxoutset command.  Only a single scan may be in progress at a time.
+type utxoScanState struct {
+	sync.Mutex
+	inProgress bool
+	progress   float64
+	abort      chan struct{}
+}
+
+// begin marks a new scan as in progress and returns the channel that will be
+// closed when the scan is requested to be aborted.  False is returned when a
+// scan is already in progress.
+func (state *utxoScanState) begin() (<-chan struct{}, bool) {
+	state.Lock()
+	defer state.Unlock()
+
+	if state.inProgress {
+		return nil, false
+	}
+	state.inProgress = true
+	state.progress = 0
+	state.abort = make(chan struct{})
+	return state.abort, true
+}
+
+// end marks the scan in progress as finished.
+func (state *utxoScanState) end() {
+	state.Lock()
+	state.inProgress = false
+	state.abort = nil
+	state.Unlock()
+}
+
+// setProgress updates the percentage of the utxo set the scan in progress has
+// searched.
+func (state *utxoScanState) setProgress(progress float64) {
+	state.Lock()
+	state.progress = progress
+	state.Unlock()
+}
+
+// status returns the percentage of the utxo set the scan in progress has
+// searched and whether or not there is a scan in progress.
+func (state *utxoScanState) status() (float64, bool) {
+	state.Lock()
+	defer state.Unlock()
+	return state.progress, state.inProgress
+}
+
+// requestAbort requests the scan in progress to be aborted.  It returns whether
+// or not there was a scan in progress which had not already been requested to
+// be aborted.
+func (state *utxoScanState) requestAbort() bool {
+	state.Lock()
+	defer state.Unlock()
+
+	if !state.inProgress {
+		return false
+	}
+	select {
+	case <-state.abort:
+		return false
+	default:
+		close(state.abort)
+		return true
+	}
+}
+
+// parseScanDescriptor returns the public key script described by the passed
+// output descriptor.  Only addr(<address>) and raw(<hex script>) descriptors
+// are supported.  A trailing descriptor checksum is ignored.
+func parseScanDescriptor(desc string, params *chaincfg.Params) ([]byte, error) {
+	if idx := strings.LastIndex(desc, "#"); idx != -1 {
+		desc = desc[:idx]
+	}
+
+	invalidDescErr := func(detail string) error {
+		return &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Invalid descriptor %q: %s", desc,
+				detail),
+		}
+	}
+	open := strings.Index(desc, "(")
+	if open == -1 || !strings.HasSuffix(desc, ")") {
+		return nil, invalidDescErr("not of the form function(argument)")
+	}
+	arg := desc[open+1 : len(desc)-1]
+
+	switch desc[:open] {
+	case "addr":
+		addr, err := ltcutil.DecodeAddress(arg, params)
+		if err != nil || !addr.IsForNet(params) {
+			return nil, invalidDescErr("invalid address")
+		}
+		pkScript, err := txscript.PayToAddrScript(addr)
+		if err != nil {
+			return nil, invalidDescErr(err.Error())
 		}
+		return pkScript, nil
 
-		// Add entry to the list if it wasn't already done above.
-		if len(filterAddrMap) != 0 {
-			vinList = append(vinList, vinEntry)
+	case "raw":
+		pkScript, err := hex.DecodeString(arg)
+		if err != nil || len(pkScript) == 0 {
+			return nil, invalidDescErr("invalid hex script")
 		}
+		return pkScript, nil
+	}
 
-		// Update the entry with previous output information if
-		// requested.
-		if vinExtra {
-			vinListEntry := &vinList[len(vinList)-1]
-			vinListEntry.PrevOut = &btcjson.PrevOut{
-				Addresses: encodedAddrs,
-				Value:     ltcutil.Amount(originTxOut.Value).ToBTC(),
+	return nil, invalidDescErr("only addr and raw descriptors are " +
+		"supported")
+}
+
+// scanUtxoSet searches the utxo set of the passed chain for the unspent outputs
+// paying to any of the passed public key scripts, which are keyed by their
+// serialized bytes and map to the descriptor they were derived from.  The
+// progress of the scan is reported to the passed scan state and the scan is
+// stopped when the passed abort channel is closed, in which case the result
+// only contains the outputs found until then and is not marked successful.
+func scanUtxoSet(chain *blockchain.BlockChain, scripts map[string]string, state *utxoScanState, abort <-chan struct{}) (*btcjson.ScanTxOutSetResult, error) {
+	result := &btcjson.ScanTxOutSetResult{
+		Unspents: []btcjson.ScanTxOutSetUnspent{},
+	}
+	var totalAmount int64
+	bestHash, bestHeight, err := chain.ForEachUtxo(func(outPoint *wire.OutPoint, entry *blockchain.UtxoEntry) error {
+		// Periodically check for an abort request and update the
+		// progress.  The utxo set is visited in order of transaction
+		// hash, so the leading bytes of the hash closely approximate
+		// how much of it has been searched.
+		if result.TxOuts%1000 == 0 {
+			select {
+			case <-abort:
+				return errUtxoScanAborted
+			default:
 			}
+			prefix := uint32(outPoint.Hash[0])<<8 | uint32(outPoint.Hash[1])
+			state.setProgress(float64(prefix) * 100 / 65536)
+		}
+		result.TxOuts++
+
+		pkScript := entry.PkScriptByIndex(outPoint.Index)
+		desc, ok := scripts[string(pkScript)]
+		if !ok {
+			return nil
 		}
+		amount := entry.AmountByIndex(outPoint.Index)
+		totalAmount += amount
+		result.Unspents = append(result.Unspents, btcjson.ScanTxOutSetUnspent{
+			TxID:         outPoint.Hash.String(),
+			Vout:         outPoint.Index,
+			ScriptPubKey: hex.EncodeToString(pkScript),
+			Desc:         desc,
+			Amount:       ltcutil.Amount(amount).ToBTC(),
+			Height:       int64(entry.BlockHeight()),
+		})
+		return nil
+	})
+	result.TotalAmount = ltcutil.Amount(totalAmount).ToBTC()
+	if err == errUtxoScanAborted {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return vinList, nil
+	result.Success = true
+	result.Height = int64(bestHeight)
+	result.BestBlock = bestHash.String()
+	return result, nil
 }
 
-// fetchMempoolTxnsForAddress queries the address index for all unconfirmed
-// transactions that involve the provided address.  The results will be limited
-// by the number to skip and the number requested.
-func fetchMempoolTxnsForAddress(s *rpcServer, addr ltcutil.Address, numToSkip, numRequested uint32) ([]*ltcutil.Tx, uint32) {
-	// There are no entries to return when there are less available than the
-	// number being skipped.
-	mpTxns := s.cfg.AddrIndex.UnconfirmedTxnsForAddress(addr)
-	numAvailable := uint32(len(mpTxns))
-	if numToSkip > numAvailable {
-		return nil, numAvailable
+// handleScanTxOutSet implements the scantxoutset command.
+func handleScanTxOutSet(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.ScanTxOutSetCmd)
+
+	state := &s.utxoScanState
+	switch c.Action {
+	case "start":
+	case "status":
+		progress, inProgress := state.status()
+		if !inProgress {
+			return nil, nil
+		}
+		return &btcjson.ScanTxOutSetStatusResult{Progress: progress}, nil
+	case "abort":
+		return state.requestAbort(), nil
+	default:
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Invalid command",
+		}
 	}
 
-	// Filter the available entries based on the number to skip and number
-	// requested.
-	rangeEnd := numToSkip + numRequested
-	if rangeEnd > numAvailable {
-		rangeEnd = numAvailable
+	if c.ScanObjects == nil {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: "scanobjects argument is required for the " +
+				"start action",
+		}
+	}
+	scripts := make(map[string]string, len(*c.ScanObjects))
+	for _, obj := range *c.ScanObjects {
+		pkScript, err := parseScanDescriptor(obj.Desc, s.cfg.ChainParams)
+		if err != nil {
+			return nil, err
+		}
+		scripts[string(pkScript)] = obj.Desc
+	}
+
+	abort, ok := state.begin()
+	if !ok {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCMisc,
+			Message: "Scan already in progress, use action " +
+				"\"abort\" or \"status\"",
+		}
+	}
+
+	// Perform the scan in the background so the status of it can be
+	// queried and it can be aborted by other requests in the mean time.
+	type scanResult struct {
+		result *btcjson.ScanTxOutSetResult
+		err    error
+	}
+	resultChan := make(chan scanResult, 1)
+	go func() {
+		result, err := scanUtxoSet(s.cfg.Chain, scripts, state, abort)
+		state.end()
+		resultChan <- scanResult{result, err}
+	}()
+
+	select {
+	// Abort the scan when the client closes before it completes since there
+	// is nobody left to receive the results.
+	case <-closeChan:
+		state.requestAbort()
+		return nil, ErrClientQuit
+
+	case r := <-resultChan:
+		if r.err != nil {
+			context := "Failed to scan the utxo set"
+			return nil, internalRPCError(r.err.Error(), context)
+		}
+		return r.result, nil
 	}
-	return mpTxns[numToSkip:rangeEnd], numToSkip
 }
 
 // handleSearchRawTransactions implements the searchrawtransactions command.
@@ -3029,6 +6509,24 @@ func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan
 		}
 	}
 
+	// Determine the types of transactions to return.  All transactions
+	// involving the address are returned by default.
+	txType := indexers.AddrTxBoth
+	if c.Type != nil {
+		switch *c.Type {
+		case btcjson.SearchRawTransactionsTypeSend:
+			txType = indexers.AddrTxSend
+		case btcjson.SearchRawTransactionsTypeReceive:
+			txType = indexers.AddrTxReceive
+		case btcjson.SearchRawTransactionsTypeBoth:
+		default:
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Unknown transaction type " + *c.Type,
+			}
+		}
+	}
+
 	// Override the default number of requested entries if needed.  Also,
 	// just return now if the number of requested entries is zero to avoid
 	// extra work.
@@ -3062,9 +6560,9 @@ func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan
 	// order.  Otherwise, they will be added last (as needed depending on
 	// the requested counts).
 	//
-	// NOTE: This code doesn't sort by dependency.  This might be something
-	// to do in the future for the client's convenience, or leave it to the
-	// client.
+	// The mempool transactions are ordered by when they were added to the
+	// mempool, which also orders them after any transactions they depend
+	// on, and that order is reversed along with the rest of the results.
 	numSkipped := uint32(0)
 	addressTxns := make([]retrievedTx, 0, numRequested)
 	if reverse {
@@ -3072,7 +6570,7 @@ func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan
 		// so the block header field in the retieved transaction struct
 		// is left nil.
 		mpTxns, mpSkipped := fetchMempoolTxnsForAddress(s, addr,
-			uint32(numToSkip), uint32(numRequested))
+			txType, uint32(numToSkip), uint32(numRequested), true)
 		numSkipped += mpSkipped
 		for _, tx := range mpTxns {
 			addressTxns = append(addressTxns, retrievedTx{tx: tx})
@@ -3084,7 +6582,7 @@ func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan
 	if len(addressTxns) < numRequested {
 		err = s.cfg.DB.View(func(dbTx database.Tx) error {
 			regions, dbSkipped, err := addrIndex.TxRegionsForAddress(
-				dbTx, addr, uint32(numToSkip)-numSkipped,
+				dbTx, addr, txType, uint32(numToSkip)-numSkipped,
 				uint32(numRequested-len(addressTxns)), reverse)
 			if err != nil {
 				return err
@@ -3126,8 +6624,8 @@ func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan
 		// so the block header field in the retieved transaction struct
 		// is left nil.
 		mpTxns, mpSkipped := fetchMempoolTxnsForAddress(s, addr,
-			uint32(numToSkip)-numSkipped, uint32(numRequested-
-				len(addressTxns)))
+			txType, uint32(numToSkip)-numSkipped,
+			uint32(numRequested-len(addressTxns)), false)
 		numSkipped += mpSkipped
 		for _, tx := range mpTxns {
 			addressTxns = append(addressTxns, retrievedTx{tx: tx})
@@ -3253,6 +6751,85 @@ func handleSearchRawTransactions(s *rpcServer, cmd interface{}, closeChan <-chan
 	return srtList, nil
 }
 
+// checkRawTxLimits ensures the passed transaction submitted through the
+// sendrawtransaction command does not send more than the max burn amount to
+// any provably unspendable output and does not pay a fee rate higher than the
+// max fee rate, both in LTC, where a max fee rate of zero disables the check.
+// The fee rate is only checked when the outputs spent by the transaction can be
+// resolved since the transaction might be an orphan.
+func checkRawTxLimits(s *rpcServer, tx *ltcutil.Tx, maxFeeRate *btcjson.MaxFeeRate, maxBurnAmount *float64) error {
+	maxBurn := ltcutil.Amount(0)
+	if maxBurnAmount != nil {
+		var err error
+		maxBurn, err = ltcutil.NewAmount(*maxBurnAmount)
+		if err != nil || maxBurn < 0 {
+			return &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Invalid max burn amount",
+			}
+		}
+	}
+	rate := btcjson.MaxFeeRate(btcjson.DefaultMaxFeeRate)
+	if maxFeeRate != nil {
+		rate = *maxFeeRate
+	}
+	maxRate, err := ltcutil.NewAmount(float64(rate))
+	if err != nil || maxRate < 0 {
+		return &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Invalid max fee rate",
+		}
+	}
+
+	mtx := tx.MsgTx()
+	var totalOut int64
+	for i, txOut := range mtx.TxOut {
+		totalOut += txOut.Value
+		if txscript.IsUnspendable(txOut.PkScript) &&
+			txOut.Value > int64(maxBurn) {
+
+			return &btcjson.RPCError{
+				Code: btcjson.ErrRPCDeserialization,
+				Message: fmt.Sprintf("TX rejected: unspendable "+
+					"output %d burns %v which exceeds the max "+
+					"burn amount of %v", i,
+					ltcutil.Amount(txOut.Value), maxBurn),
+			}
+		}
+	}
+	if maxRate == 0 || blockchain.IsCoinBaseTx(mtx) {
+		return nil
+	}
+
+	prevOuts, err := fetchTxPrevOuts(s, mtx)
+	if err != nil {
+		return err
+	}
+	var totalIn int64
+	for _, txIn := range mtx.TxIn {
+		prevOut, ok := prevOuts[txIn.PreviousOutPoint]
+		if !ok {
+			rpcsLog.Debugf("Skipping max fee rate check for "+
+				"transaction %v since output %v it spends is "+
+				"unknown", tx.Hash(), txIn.PreviousOutPoint)
+			return nil
+		}
+		totalIn += prevOut.txOut.Value
+	}
+	fee := totalIn - totalOut
+	feeRate := ltcutil.Amount(fee * 1000 / mempool.GetTxVirtualSize(tx))
+	if feeRate > maxRate {
+		return &btcjson.RPCError{
+			Code: btcjson.ErrRPCDeserialization,
+			Message: fmt.Sprintf("TX rejected: fee rate of %v/kvB "+
+				"exceeds the max fee rate of %v/kvB", feeRate,
+				maxRate),
+		}
+	}
+
+	return nil
+}
+
 // handleSendRawTransaction implements the sendrawtransaction command.
 func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.SendRawTransactionCmd)
@@ -3274,8 +6851,14 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 		}
 	}
 
-	// Use 0 for the tag to represent local node.
+	// Reject transactions which would likely be a mistake before they are
+	// accepted to the memory pool.
 	tx := ltcutil.NewTx(&msgTx)
+	if err := checkRawTxLimits(s, tx, c.MaxFeeRate, c.MaxBurnAmount); err != nil {
+		return nil, err
+	}
+
+	// Use 0 for the tag to represent local node.
 	acceptedTxs, err := s.cfg.TxMemPool.ProcessTransaction(tx, false, false, 0)
 	if err != nil {
 		// When the error is a rule error, it means the transaction was
@@ -3305,7 +6888,8 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	// Also, since an error is being returned to the caller, ensure the
 	// transaction is removed from the memory pool.
 	if len(acceptedTxs) == 0 || !acceptedTxs[0].Tx.Hash().IsEqual(tx.Hash()) {
-		s.cfg.TxMemPool.RemoveTransaction(tx, true)
+		s.cfg.TxMemPool.RemoveTransaction(tx, true,
+			mempool.RemovalReasonInvalid)
 
 		errStr := fmt.Sprintf("transaction %v is not in accepted list",
 			tx.Hash())
@@ -3322,14 +6906,102 @@ func handleSendRawTransaction(s *rpcServer, cmd interface{}, closeChan <-chan st
 	s.NotifyNewTransactions(acceptedTxs)
 
 	// Keep track of all the sendrawtransaction request txns so that they
-	// can be rebroadcast if they don't make their way into a block.
-	txD := acceptedTxs[0]
-	iv := wire.NewInvVect(wire.InvTypeTx, txD.Tx.Hash())
-	s.cfg.ConnMgr.AddRebroadcastInventory(iv, txD)
+	// can be rebroadcast until a peer requests them or they make their way
+	// into a block.
+	s.cfg.TxMemPool.AddUnbroadcastTx(tx.Hash())
 
 	return tx.Hash().String(), nil
 }
 
+// decodePackageTxns deserializes the passed serialized, hex-encoded
+// transactions of a package.
+func decodePackageTxns(hexTxns []string) ([]*ltcutil.Tx, error) {
+	if len(hexTxns) == 0 || len(hexTxns) > mempool.MaxPackageCount {
+		return nil, &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidParameter,
+			Message: fmt.Sprintf("Array must contain between 1 and "+
+				"%d transactions", mempool.MaxPackageCount),
+		}
+	}
+
+	txns := make([]*ltcutil.Tx, 0, len(hexTxns))
+	for i, hexStr := range hexTxns {
+		if len(hexStr)%2 != 0 {
+			hexStr = "0" + hexStr
+		}
+		serializedTx, err := hex.DecodeString(hexStr)
+		if err != nil {
+			return nil, rpcDecodeHexError(hexStr)
+		}
+		var msgTx wire.MsgTx
+		err = msgTx.Deserialize(bytes.NewReader(serializedTx))
+		if err != nil {
+			return nil, &btcjson.RPCError{
+				Code: btcjson.ErrRPCDeserialization,
+				Message: fmt.Sprintf("TX decode failed for "+
+					"transaction %d: %v", i, err),
+			}
+		}
+		txns = append(txns, ltcutil.NewTx(&msgTx))
+	}
+
+	return txns, nil
+}
+
+// handleSubmitPackage implements the submitpackage command.
+func handleSubmitPackage(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SubmitPackageCmd)
+	txns, err := decodePackageTxns(c.Package)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.cfg.TxMemPool.ProcessPackage(txns)
+	if err != nil {
+		// When the error is a rule error, it means the package was
+		// simply rejected as opposed to something actually going wrong,
+		// so log it as such.  Otherwise, something really did go wrong,
+		// so log it as an actual error.
+		if _, ok := err.(mempool.RuleError); ok {
+			rpcsLog.Debugf("Rejected package: %v", err)
+		} else {
+			rpcsLog.Errorf("Failed to process package: %v", err)
+		}
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "Package rejected: " + err.Error(),
+		}
+	}
+
+	// Generate and relay inventory vectors for all newly accepted
+	// transactions and notify both websocket and getblocktemplate long
+	// poll clients of them.
+	if len(result.Accepted) > 0 {
+		s.cfg.ConnMgr.RelayTransactions(result.Accepted)
+		s.NotifyNewTransactions(result.Accepted)
+	}
+
+	// Keep track of the transactions of the package so that they can be
+	// rebroadcast until a peer requests them or they make their way into a
+	// block.
+	reply := &btcjson.SubmitPackageResult{
+		TxResults:      make(map[string]btcjson.SubmitPackageTxResult),
+		PackageFeeRate: result.FeeRate.ToBTC(),
+	}
+	for _, txD := range result.TxDescs {
+		s.cfg.TxMemPool.AddUnbroadcastTx(txD.Tx.Hash())
+
+		txHash := txD.Tx.Hash().String()
+		reply.TxResults[txHash] = btcjson.SubmitPackageTxResult{
+			TxID:  txHash,
+			VSize: mempool.GetTxVirtualSize(txD.Tx),
+			Fee:   ltcutil.Amount(txD.Fee).ToBTC(),
+		}
+	}
+
+	return reply, nil
+}
+
 // handleSetGenerate implements the setgenerate command.
 func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.SetGenerateCmd)
@@ -3366,6 +7038,83 @@ func handleSetGenerate(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return nil, nil
 }
 
+// handleSetMiningFlags implements the setminingflags command.
+func handleSetMiningFlags(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SetMiningFlagsCmd)
+
+	err := s.cfg.Generator.SetExtraCoinbaseData([]byte(c.Flags))
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+	return nil, nil
+}
+
+// handleSetVersionBit implements the setversionbit command.
+func handleSetVersionBit(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SetVersionBitCmd)
+
+	err := s.cfg.Generator.SetVersionBit(c.Bit, c.Signal)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: err.Error(),
+		}
+	}
+
+	// Discard the current block template so the next getblocktemplate
+	// request creates one with the new block version.
+	state := s.gbtWorkState
+	state.Lock()
+	state.template = nil
+	state.Unlock()
+	return nil, nil
+}
+
+// signedMessageMagic is the prefix of the messages signed by the
+// signmessagewithprivkey command and verified by the verifymessage command.  It
+// differs from the one used by bitcoin so the signed messages of the two chains
+// can't be mistaken for each other.
+const signedMessageMagic = "Litecoin Signed Message:\n"
+
+// signedMessageHash returns the hash which is signed to sign the passed
+// message.
+func signedMessageHash(message string) []byte {
+	var buf bytes.Buffer
+	wire.WriteVarString(&buf, 0, signedMessageMagic)
+	wire.WriteVarString(&buf, 0, message)
+	return chainhash.DoubleHashB(buf.Bytes())
+}
+
+// handleSignMessageWithPrivKey implements the signmessagewithprivkey command.
+func handleSignMessageWithPrivKey(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.SignMessageWithPrivKeyCmd)
+
+	wif, err := ltcutil.DecodeWIF(c.PrivKey)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid private key: " + err.Error(),
+		}
+	}
+	if !wif.IsForNet(s.cfg.ChainParams) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Invalid private key: key is for the wrong network",
+		}
+	}
+
+	sig, err := btcec.SignCompact(btcec.S256(), wif.PrivKey,
+		signedMessageHash(c.Message), wif.CompressPubKey)
+	if err != nil {
+		context := "Failed to sign message"
+		return nil, internalRPCError(err.Error(), context)
+	}
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
 // handleStop implements the stop command.
 func handleStop(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	select {
@@ -3408,6 +7157,74 @@ func handleSubmitBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 	return nil, nil
 }
 
+// handleTestMempoolAccept implements the testmempoolaccept command.
+func handleTestMempoolAccept(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.TestMempoolAcceptCmd)
+
+	// A max fee rate of zero disables the check.
+	var maxFeeRate ltcutil.Amount
+	if c.MaxFeeRate != nil {
+		var err error
+		maxFeeRate, err = ltcutil.NewAmount(*c.MaxFeeRate)
+		if err != nil || maxFeeRate < 0 {
+			return nil, &btcjson.RPCError{
+				Code:    btcjson.ErrRPCInvalidParameter,
+				Message: "Invalid max fee rate",
+			}
+		}
+	}
+
+	txns, err := decodePackageTxns(c.RawTxns)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.cfg.TxMemPool.TestAcceptTransactions(txns)
+	if err != nil {
+		if _, ok := err.(mempool.RuleError); !ok {
+			context := "Failed to test transactions"
+			return nil, internalRPCError(err.Error(), context)
+		}
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Package rejected: " + err.Error(),
+		}
+	}
+
+	reply := make([]btcjson.TestMempoolAcceptResult, 0, len(results))
+	for _, result := range results {
+		vsize := mempool.GetTxVirtualSize(result.Tx)
+		txResult := btcjson.TestMempoolAcceptResult{
+			TxID:  result.Tx.Hash().String(),
+			VSize: vsize,
+		}
+		switch {
+		case result.Err != nil:
+			// Errors other than rule errors mean something
+			// actually went wrong as opposed to the transaction
+			// simply being rejected.
+			if _, ok := result.Err.(mempool.RuleError); !ok {
+				context := "Failed to test transaction"
+				return nil, internalRPCError(result.Err.Error(),
+					context)
+			}
+			txResult.RejectReason = result.Err.Error()
+
+		case maxFeeRate > 0 && result.Fee*1000/vsize > int64(maxFeeRate):
+			txResult.RejectReason = "max-fee-exceeded"
+
+		default:
+			txResult.Allowed = true
+			txResult.Fees = &btcjson.TestMempoolAcceptFees{
+				Base: ltcutil.Amount(result.Fee).ToBTC(),
+			}
+		}
+		reply = append(reply, txResult)
+	}
+
+	return reply, nil
+}
+
 // handleUptime implements the uptime command.
 func handleUptime(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	return time.Now().Unix() - s.cfg.StartupTime, nil
@@ -3417,52 +7234,63 @@ func handleUptime(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (int
 func handleValidateAddress(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
 	c := cmd.(*btcjson.ValidateAddressCmd)
 
+	// Addresses which fail to decode, are for another network, or can't be
+	// paid to are not valid.  Note that decoding an address only ensures it
+	// is for one of the known networks.
 	result := btcjson.ValidateAddressChainResult{}
-	addr, err := ltcutil.DecodeAddress(c.Address, s.cfg.ChainParams)
-	if err != nil {
+	params := s.cfg.ChainParams
+	addr, err := ltcutil.DecodeAddress(c.Address, params)
+	if err != nil || !addr.IsForNet(params) {
 		// Return the default value (false) for IsValid.
 		return result, nil
 	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		return result, nil
+	}
 
-	result.Address = addr.EncodeAddress()
 	result.IsValid = true
+	result.Address = addr.EncodeAddress()
+	result.ScriptPubKey = hex.EncodeToString(pkScript)
+	result.Type = txscript.GetScriptClass(pkScript).String()
 
-	return result, nil
-}
+	var isWitness bool
+	switch addr := addr.(type) {
+	case *ltcutil.AddressWitnessPubKeyHash:
+		isWitness = true
+		result.WitnessVersion = btcjson.Int32(int32(addr.WitnessVersion()))
+		result.WitnessProgram = hex.EncodeToString(addr.WitnessProgram())
 
-func verifyChain(s *rpcServer, level, depth int32) error {
-	best := s.cfg.Chain.BestSnapshot()
-	finishHeight := best.Height - depth
-	if finishHeight < 0 {
-		finishHeight = 0
+	case *ltcutil.AddressWitnessScriptHash:
+		isWitness = true
+		result.WitnessVersion = btcjson.Int32(int32(addr.WitnessVersion()))
+		result.WitnessProgram = hex.EncodeToString(addr.WitnessProgram())
 	}
-	rpcsLog.Infof("Verifying chain for %d blocks at level %d",
-		best.Height-finishHeight, level)
+	result.IsWitness = &isWitness
 
-	for height := best.Height; height > finishHeight; height-- {
-		// Level 0 just looks up the block.
-		block, err := s.cfg.Chain.BlockByHeight(height)
-		if err != nil {
-			rpcsLog.Errorf("Verify is unable to fetch block at "+
-				"height %d: %v", height, err)
-			return err
-		}
+	return result, nil
+}
+
+// verifyChain verifies the blocks of the main chain at the passed level going
+// back the passed number of blocks from the tip, or all blocks when the depth
+// is zero.  The depth is limited at level 2 and above as described by
+// blockchain.VerifyChain.  The block which failed verification, if any, is
+// logged.
+func verifyChain(s *rpcServer, level, depth int32, closeChan <-chan struct{}) error {
+	err := s.cfg.Chain.VerifyChain(level, depth, closeChan)
+	if err == nil {
+		return nil
+	}
 
-		// Level 1 does basic chain sanity checks.
-		if level > 0 {
-			err := blockchain.CheckBlockSanity(block,
-				s.cfg.ChainParams.PowLimit, s.cfg.TimeSource)
-			if err != nil {
-				rpcsLog.Errorf("Verify is unable to validate "+
-					"block at hash %v height %d: %v",
-					block.Hash(), height, err)
-				return err
-			}
-		}
+	// Stop when the client disconnects or the call times out.
+	select {
+	case <-closeChan:
+		return ErrClientQuit
+	default:
 	}
-	rpcsLog.Infof("Chain verify completed successfully")
 
-	return nil
+	rpcsLog.Errorf("Chain verification failed: %v", err)
+	return err
 }
 
 // handleVerifyChain implements the verifychain command.
@@ -3477,7 +7305,10 @@ func handleVerifyChain(s *rpcServer, cmd interface{}, closeChan <-chan struct{})
 		checkDepth = *c.CheckDepth
 	}
 
-	err := verifyChain(s, checkLevel, checkDepth)
+	err := verifyChain(s, checkLevel, checkDepth, closeChan)
+	if err == ErrClientQuit {
+		return nil, err
+	}
 	return err == nil, nil
 }
 
@@ -3514,10 +7345,7 @@ func handleVerifyMessage(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 
 	// Validate the signature - this just shows that it was valid at all.
 	// we will compare it with the key next.
-	var buf bytes.Buffer
-	wire.WriteVarString(&buf, 0, "Bitcoin Signed Message:\n")
-	wire.WriteVarString(&buf, 0, c.Message)
-	expectedMessageHash := chainhash.DoubleHashB(buf.Bytes())
+	expectedMessageHash := signedMessageHash(c.Message)
 	pk, wasCompressed, err := btcec.RecoverCompact(btcec.S256(), sig,
 		expectedMessageHash)
 	if err != nil {
@@ -3544,6 +7372,48 @@ func handleVerifyMessage(s *rpcServer, cmd interface{}, closeChan <-chan struct{
 	return address.EncodeAddress() == c.Address, nil
 }
 
+// handleVerifyTxOutProof implements the verifytxoutproof command.
+func handleVerifyTxOutProof(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.VerifyTxOutProofCmd)
+
+	// Deserialize the proof.
+	proofBytes, err := hex.DecodeString(c.Proof)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.Proof)
+	}
+	var proof wire.MsgMerkleBlock
+	err = proof.BtcDecode(bytes.NewReader(proofBytes), wire.ProtocolVersion,
+		wire.BaseEncoding)
+	if err != nil {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCDeserialization,
+			Message: "Proof decode failed: " + err.Error(),
+		}
+	}
+
+	// No transactions are proven when the partial merkle tree is malformed
+	// or does not commit to the merkle root of the block header.
+	root, matches, err := extractTxOutProofMatches(&proof)
+	if err != nil || *root != proof.Header.MerkleRoot {
+		return []string{}, nil
+	}
+
+	// The proof is only meaningful for blocks in the main chain.
+	blockHash := proof.Header.BlockHash()
+	if !s.cfg.Chain.MainChainHasBlock(&blockHash) {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidAddressOrKey,
+			Message: "Block not found in chain",
+		}
+	}
+
+	txIDs := make([]string, 0, len(matches))
+	for i := range matches {
+		txIDs = append(txIDs, matches[i].String())
+	}
+	return txIDs, nil
+}
+
 // handleVersion implements the version command.
 //
 // NOTE: This is a btcsuite extension ported from github.com/decred/dcrd.
@@ -3559,6 +7429,128 @@ func handleVersion(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (in
 	return result, nil
 }
 
+// tipWaitState houses the state used to wake up the invocations of the
+// waitforblock, waitforblockheight, and waitfornewblock commands which are
+// waiting for the tip of the main chain to change.
+type tipWaitState struct {
+	sync.Mutex
+	tipChanged chan struct{}
+}
+
+// tipChangedChan returns a channel which is closed the next time the tip of the
+// main chain changes.
+func (state *tipWaitState) tipChangedChan() <-chan struct{} {
+	state.Lock()
+	defer state.Unlock()
+
+	if state.tipChanged == nil {
+		state.tipChanged = make(chan struct{})
+	}
+	return state.tipChanged
+}
+
+// NotifyTipChanged wakes up all of the invocations which are waiting for the
+// tip of the main chain to change.
+func (state *tipWaitState) NotifyTipChanged() {
+	state.Lock()
+	if state.tipChanged != nil {
+		close(state.tipChanged)
+		state.tipChanged = nil
+	}
+	state.Unlock()
+}
+
+// waitForTip blocks until the passed function reports the tip of the main chain
+// is the one being waited for, the timeout in milliseconds expires, or the
+// client disconnects.  A timeout of zero waits indefinitely.  The tip of the
+// main chain at the time the wait ends is returned.
+func waitForTip(s *rpcServer, timeout int64, closeChan <-chan struct{}, done func(*blockchain.BestState) bool) (interface{}, error) {
+	if timeout < 0 {
+		return nil, &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidParameter,
+			Message: "Negative timeout",
+		}
+	}
+	var timeoutChan <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(time.Duration(timeout) * time.Millisecond)
+		defer timer.Stop()
+		timeoutChan = timer.C
+	}
+
+	for {
+		// Register for the next tip change before checking the current
+		// tip so a change in between is not missed.
+		tipChanged := s.tipWaitState.tipChangedChan()
+		best := s.cfg.Chain.BestSnapshot()
+		if done(best) {
+			return &btcjson.WaitForBlockResult{
+				Hash:   best.Hash.String(),
+				Height: best.Height,
+			}, nil
+		}
+
+		select {
+		case <-tipChanged:
+
+		case <-timeoutChan:
+			best = s.cfg.Chain.BestSnapshot()
+			return &btcjson.WaitForBlockResult{
+				Hash:   best.Hash.String(),
+				Height: best.Height,
+			}, nil
+
+		case <-closeChan:
+			return nil, ErrClientQuit
+
+		case <-s.quit:
+			return nil, ErrClientQuit
+		}
+	}
+}
+
+// handleWaitForBlock implements the waitforblock command.
+func handleWaitForBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.WaitForBlockCmd)
+
+	hash, err := chainhash.NewHashFromStr(c.BlockHash)
+	if err != nil {
+		return nil, rpcDecodeHexError(c.BlockHash)
+	}
+	done := func(best *blockchain.BestState) bool {
+		return best.Hash == *hash
+	}
+	return waitForTip(s, *c.Timeout, closeChan, done)
+}
+
+// handleWaitForBlockHeight implements the waitforblockheight command.
+func handleWaitForBlockHeight(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.WaitForBlockHeightCmd)
+
+	done := func(best *blockchain.BestState) bool {
+		return int64(best.Height) >= c.Height
+	}
+	return waitForTip(s, *c.Timeout, closeChan, done)
+}
+
+// handleWaitForNewBlock implements the waitfornewblock command.
+func handleWaitForNewBlock(s *rpcServer, cmd interface{}, closeChan <-chan struct{}) (interface{}, error) {
+	c := cmd.(*btcjson.WaitForNewBlockCmd)
+
+	initialHash := s.cfg.Chain.BestSnapshot().Hash
+	done := func(best *blockchain.BestState) bool {
+		return best.Hash != initialHash
+	}
+	return waitForTip(s, *c.Timeout, closeChan, done)
+}
+
+// rpcScopedAuth houses the hash of the authorization header of an RPC user
+// defined with a scope along with the scope.
+type rpcScopedAuth struct {
+	authsha [sha256.Size]byte
+	scope   rpcScope
+}
+
 // rpcServer provides a concurrent safe RPC server to a chain server.
 type rpcServer struct {
 	started                int32
@@ -3566,12 +7558,16 @@ type rpcServer struct {
 	cfg                    rpcserverConfig
 	authsha                [sha256.Size]byte
 	limitauthsha           [sha256.Size]byte
+	cookieauthsha          [sha256.Size]byte
+	scopedAuths            []rpcScopedAuth
 	ntfnMgr                *wsNotificationManager
 	numClients             int32
 	statusLines            map[int]string
 	statusLock             sync.RWMutex
 	wg                     sync.WaitGroup
 	gbtWorkState           *gbtWorkState
+	utxoScanState          utxoScanState
+	tipWaitState           tipWaitState
 	helpCacher             *helpCacher
 	requestProcessShutdown chan struct{}
 	quit                   chan int
@@ -3650,6 +7646,13 @@ func (s *rpcServer) Stop() error {
 	s.ntfnMgr.WaitForShutdown()
 	close(s.quit)
 	s.wg.Wait()
+	if s.cfg.CookiePath != "" {
+		err := os.Remove(s.cfg.CookiePath)
+		if err != nil && !os.IsNotExist(err) {
+			rpcsLog.Errorf("Unable to remove the RPC authentication "+
+				"cookie: %v", err)
+		}
+	}
 	rpcsLog.Infof("RPC server shutdown complete")
 	return nil
 }
@@ -3671,10 +7674,17 @@ func (s *rpcServer) NotifyNewTransactions(txns []*mempool.TxDesc) {
 
 		// Potentially notify any getblocktemplate long poll clients
 		// about stale block templates due to the new transaction.
-		s.gbtWorkState.NotifyMempoolTx(s.cfg.TxMemPool.LastUpdated())
+		s.gbtWorkState.NotifyMempoolTx(s.cfg.TxMemPool.TransactionsUpdated())
 	}
 }
 
+// NotifyTxRemoved notifies websocket clients that have registered for mempool
+// eviction updates that the passed transaction was removed from the memory
+// pool for the passed reason.
+func (s *rpcServer) NotifyTxRemoved(tx *ltcutil.Tx, reason mempool.RemovalReason) {
+	s.ntfnMgr.NotifyMempoolEvicted(tx, reason.String())
+}
+
 // limitConnections responds with a 503 service unavailable and returns true if
 // adding another client would exceed the maximum allow RPC clients.
 //
@@ -3716,40 +7726,63 @@ func (s *rpcServer) decrementClients() {
 //
 // This check is time-constant.
 //
-// The first bool return value signifies auth success (true if successful) and
-// the second bool return value specifies whether the user can change the state
-// of the server (true) or whether the user is limited (false). The second is
-// always false if the first is.
-func (s *rpcServer) checkAuth(r *http.Request, require bool) (bool, bool, error) {
+// The bool return value signifies auth success (true if successful) and the
+// scope return value specifies the methods the user is permitted to call, which
+// is nil when the user may call all of them.
+func (s *rpcServer) checkAuth(r *http.Request, require bool) (bool, rpcScope, error) {
 	authhdr := r.Header["Authorization"]
 	if len(authhdr) <= 0 {
 		if require {
 			rpcsLog.Warnf("RPC authentication failure from %s",
 				r.RemoteAddr)
-			return false, false, errors.New("auth failure")
+			return false, nil, errors.New("auth failure")
 		}
 
-		return false, false, nil
+		return false, nil, nil
 	}
 
 	authsha := sha256.Sum256([]byte(authhdr[0]))
+	scope, ok := s.authScope(&authsha)
+	if !ok {
+		// Request's auth doesn't match any user
+		rpcsLog.Warnf("RPC authentication failure from %s", r.RemoteAddr)
+		return false, nil, errors.New("auth failure")
+	}
+	return true, scope, nil
+}
 
+// authScope returns the scope of the user whose authorization header hashes to
+// the passed hash and whether or not such a user exists.  Limited users are
+// only permitted to call the limited set of RPC calls, while the cookie grants
+// access to all of them like the admin user.
+//
+// This check is time-constant.
+func (s *rpcServer) authScope(authsha *[sha256.Size]byte) (rpcScope, bool) {
 	// Check for limited auth first as in environments with limited users, those
 	// are probably expected to have a higher volume of calls
 	limitcmp := subtle.ConstantTimeCompare(authsha[:], s.limitauthsha[:])
 	if limitcmp == 1 {
-		return true, false, nil
+		return rpcLimited, true
 	}
 
-	// Check for admin-level auth
+	// Check for admin-level auth, which the cookie grants as well
 	cmp := subtle.ConstantTimeCompare(authsha[:], s.authsha[:])
-	if cmp == 1 {
-		return true, true, nil
+	cookiecmp := subtle.ConstantTimeCompare(authsha[:], s.cookieauthsha[:])
+	if cmp == 1 || cookiecmp == 1 {
+		return nil, true
 	}
 
-	// Request's auth doesn't match either user
-	rpcsLog.Warnf("RPC authentication failure from %s", r.RemoteAddr)
-	return false, false, errors.New("auth failure")
+	// Check for the users defined with scopes.  All of them are compared
+	// so the time taken does not depend on which user matches.
+	var scope rpcScope
+	var found bool
+	for i := range s.scopedAuths {
+		auth := &s.scopedAuths[i]
+		if subtle.ConstantTimeCompare(authsha[:], auth.authsha[:]) == 1 {
+			scope, found = auth.scope, true
+		}
+	}
+	return scope, found
 }
 
 // parsedRPCCmd represents a JSON-RPC request object that has been parsed into
@@ -3784,7 +7817,62 @@ func (s *rpcServer) standardCmdResult(cmd *parsedRPCCmd, closeChan <-chan struct
 	return nil, btcjson.ErrRPCMethodNotFound
 handled:
 
-	return handler(s, cmd.cmd, closeChan)
+	// Cancel the call once its timeout elapses the same way as when the
+	// client disconnects, which the handlers of long-running calls observe,
+	// and log the calls which are slow.
+	start := time.Now()
+	timeout := rpcCallTimeout(cmd.method)
+	var expired func() bool
+	if timeout > 0 {
+		closeChan, expired = closeAfter(closeChan, timeout)
+	}
+	result, err := handler(s, cmd.cmd, closeChan)
+	elapsed := time.Since(start)
+	if expired != nil && expired() && err != nil {
+		err = &btcjson.RPCError{
+			Code: btcjson.ErrRPCMisc,
+			Message: fmt.Sprintf("%s timed out after %v", cmd.method,
+				timeout),
+		}
+	}
+	if cfg.RPCSlowThreshold > 0 && elapsed >= cfg.RPCSlowThreshold {
+		rpcsLog.Warnf("Slow RPC %s took %v", cmd.method, elapsed)
+	}
+	return result, err
+}
+
+// rpcCallTimeout returns the duration after which calls of the passed method
+// are cancelled, which is zero when they are not.
+func rpcCallTimeout(method string) time.Duration {
+	if timeout, ok := cfg.rpcMethodTimeouts[method]; ok {
+		return timeout
+	}
+	return cfg.RPCTimeout
+}
+
+// closeAfter returns a channel which is closed once the passed channel is
+// closed or the passed timeout elapses, whichever happens first, along with a
+// function which stops the timeout and returns whether or not it elapsed.
+func closeAfter(closeChan <-chan struct{}, timeout time.Duration) (<-chan struct{}, func() bool) {
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	var elapsed int32
+	go func() {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-closeChan:
+		case <-timer.C:
+			atomic.StoreInt32(&elapsed, 1)
+		case <-stop:
+			return
+		}
+		close(done)
+	}()
+	return done, func() bool {
+		close(stop)
+		return atomic.LoadInt32(&elapsed) == 1
+	}
 }
 
 // parseCmd parses a JSON-RPC request object into known concrete command.  The
@@ -3834,47 +7922,10 @@ func createMarshalledReply(id, result interface{}, replyErr error) ([]byte, erro
 	return btcjson.MarshalResponse(id, result, jsonErr)
 }
 
-// jsonRPCRead handles reading and responding to RPC messages.
-func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin bool) {
-	if atomic.LoadInt32(&s.shutdown) != 0 {
-		return
-	}
-
-	// Read and close the JSON-RPC request body from the caller.
-	body, err := ioutil.ReadAll(r.Body)
-	r.Body.Close()
-	if err != nil {
-		errCode := http.StatusBadRequest
-		http.Error(w, fmt.Sprintf("%d error reading JSON message: %v",
-			errCode, err), errCode)
-		return
-	}
-
-	// Unfortunately, the http server doesn't provide the ability to
-	// change the read deadline for the new connection and having one breaks
-	// long polling.  However, not having a read deadline on the initial
-	// connection would mean clients can connect and idle forever.  Thus,
-	// hijack the connecton from the HTTP server, clear the read deadline,
-	// and handle writing the response manually.
-	hj, ok := w.(http.Hijacker)
-	if !ok {
-		errMsg := "webserver doesn't support hijacking"
-		rpcsLog.Warnf(errMsg)
-		errCode := http.StatusInternalServerError
-		http.Error(w, strconv.Itoa(errCode)+" "+errMsg, errCode)
-		return
-	}
-	conn, buf, err := hj.Hijack()
-	if err != nil {
-		rpcsLog.Warnf("Failed to hijack HTTP connection: %v", err)
-		errCode := http.StatusInternalServerError
-		http.Error(w, strconv.Itoa(errCode)+" "+err.Error(), errCode)
-		return
-	}
-	defer conn.Close()
-	defer buf.Flush()
-	conn.SetReadDeadline(timeZeroVal)
-
+// processRequest parses and responds to the passed raw JSON-RPC request and
+// returns the marshalled reply, or nil when the request must not be replied
+// to.
+func (s *rpcServer) processRequest(body []byte, scope rpcScope, closeChan <-chan struct{}) []byte {
 	// Attempt to parse the raw body into a JSON-RPC request.
 	var responseID interface{}
 	var jsonErr error
@@ -3906,30 +7957,19 @@ func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin
 		// RPC quirks can be enabled by the user to avoid compatibility issues
 		// with software relying on Core's behavior.
 		if request.ID == nil && !(cfg.RPCQuirks && request.Jsonrpc == "") {
-			return
+			return nil
 		}
 
 		// The parse was at least successful enough to have an ID so
 		// set it for the response.
 		responseID = request.ID
 
-		// Setup a close notifier.  Since the connection is hijacked,
-		// the CloseNotifer on the ResponseWriter is not available.
-		closeChan := make(chan struct{}, 1)
-		go func() {
-			_, err := conn.Read(make([]byte, 1))
-			if err != nil {
-				close(closeChan)
-			}
-		}()
-
-		// Check if the user is limited and set error if method unauthorized
-		if !isAdmin {
-			if _, ok := rpcLimited[request.Method]; !ok {
-				jsonErr = &btcjson.RPCError{
-					Code:    btcjson.ErrRPCInvalidParams.Code,
-					Message: "limited user not authorized for this method",
-				}
+		// Check if the user is permitted to call the method and set
+		// error if not
+		if !scope.permits(request.Method) {
+			jsonErr = &btcjson.RPCError{
+				Code:    btcjson.ErrRPCMethodNotPermitted,
+				Message: "method not permitted",
 			}
 		}
 
@@ -3949,6 +7989,148 @@ func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, isAdmin
 	msg, err := createMarshalledReply(responseID, result, jsonErr)
 	if err != nil {
 		rpcsLog.Errorf("Failed to marshal reply: %v", err)
+		return nil
+	}
+	return msg
+}
+
+// processBatch parses and responds to each of the requests of the passed raw
+// JSON-RPC batch, and returns the marshalled array of their replies, or nil
+// when none of them must be replied to.  A single error is returned instead
+// when the batch is empty, malformed or exceeds the maximum number of requests
+// allowed.
+func (s *rpcServer) processBatch(body []byte, scope rpcScope, closeChan <-chan struct{}) []byte {
+	// Only split the batch into its requests so they are not parsed when
+	// the batch is rejected.
+	var requests []json.RawMessage
+	var jsonErr *btcjson.RPCError
+	if err := json.Unmarshal(body, &requests); err != nil {
+		jsonErr = &btcjson.RPCError{
+			Code:    btcjson.ErrRPCParse.Code,
+			Message: "Failed to parse request: " + err.Error(),
+		}
+	} else if len(requests) == 0 {
+		jsonErr = &btcjson.RPCError{
+			Code:    btcjson.ErrRPCInvalidRequest.Code,
+			Message: "Invalid request: empty batch",
+		}
+	} else if len(requests) > cfg.RPCMaxBatchSize {
+		jsonErr = &btcjson.RPCError{
+			Code: btcjson.ErrRPCInvalidRequest.Code,
+			Message: fmt.Sprintf("Invalid request: batch of %d "+
+				"requests exceeds the maximum of %d",
+				len(requests), cfg.RPCMaxBatchSize),
+		}
+	}
+	if jsonErr != nil {
+		msg, err := createMarshalledReply(nil, nil, jsonErr)
+		if err != nil {
+			rpcsLog.Errorf("Failed to marshal reply: %v", err)
+			return nil
+		}
+		return msg
+	}
+
+	replies := make([]json.RawMessage, 0, len(requests))
+	for _, request := range requests {
+		if reply := s.processRequest(request, scope, closeChan); reply != nil {
+			replies = append(replies, reply)
+		}
+	}
+	if len(replies) == 0 {
+		return nil
+	}
+	msg, err := json.Marshal(replies)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal reply: %v", err)
+		return nil
+	}
+	return msg
+}
+
+// jsonRPCRead handles reading and responding to RPC messages.
+func (s *rpcServer) jsonRPCRead(w http.ResponseWriter, r *http.Request, scope rpcScope) {
+	if atomic.LoadInt32(&s.shutdown) != 0 {
+		return
+	}
+
+	// Reject request bodies which exceed the maximum allowed size before
+	// reading them entirely when the size is known up front.
+	maxSize := cfg.RPCMaxRequestSize
+	if r.ContentLength > maxSize {
+		r.Body.Close()
+		errCode := http.StatusRequestEntityTooLarge
+		http.Error(w, fmt.Sprintf("%d request of %d bytes exceeds the "+
+			"maximum of %d bytes", errCode, r.ContentLength, maxSize),
+			errCode)
+		return
+	}
+
+	// Read and close the JSON-RPC request body from the caller.  Reading
+	// at most one more byte than allowed is enough to detect when the
+	// size was not known up front and exceeds the maximum.
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	r.Body.Close()
+	if err != nil {
+		errCode := http.StatusBadRequest
+		http.Error(w, fmt.Sprintf("%d error reading JSON message: %v",
+			errCode, err), errCode)
+		return
+	}
+	if int64(len(body)) > maxSize {
+		errCode := http.StatusRequestEntityTooLarge
+		http.Error(w, fmt.Sprintf("%d request exceeds the maximum of "+
+			"%d bytes", errCode, maxSize), errCode)
+		return
+	}
+
+	// Unfortunately, the http server doesn't provide the ability to
+	// change the read deadline for the new connection and having one breaks
+	// long polling.  However, not having a read deadline on the initial
+	// connection would mean clients can connect and idle forever.  Thus,
+	// hijack the connecton from the HTTP server, clear the read deadline,
+	// and handle writing the response manually.
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		errMsg := "webserver doesn't support hijacking"
+		rpcsLog.Warnf(errMsg)
+		errCode := http.StatusInternalServerError
+		http.Error(w, strconv.Itoa(errCode)+" "+errMsg, errCode)
+		return
+	}
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		rpcsLog.Warnf("Failed to hijack HTTP connection: %v", err)
+		errCode := http.StatusInternalServerError
+		http.Error(w, strconv.Itoa(errCode)+" "+err.Error(), errCode)
+		return
+	}
+	defer conn.Close()
+	defer buf.Flush()
+	conn.SetReadDeadline(timeZeroVal)
+
+	// Setup a close notifier.  Since the connection is hijacked, the
+	// CloseNotifer on the ResponseWriter is not available.
+	closeChan := make(chan struct{}, 1)
+	go func() {
+		_, err := conn.Read(make([]byte, 1))
+		if err != nil {
+			close(closeChan)
+		}
+	}()
+
+	// Process the request, or each of the requests of a batch, which is a
+	// JSON array of requests.  The replies of a batch are sent as a JSON
+	// array as well, which does not include the replies to notifications.
+	var msg []byte
+	if trimmed := bytes.TrimLeft(body, " \t\r\n"); len(trimmed) > 0 &&
+		trimmed[0] == '[' {
+
+		msg = s.processBatch(body, scope, closeChan)
+	} else {
+		msg = s.processRequest(body, scope, closeChan)
+	}
+	if msg == nil {
 		return
 	}
 
@@ -4002,19 +8184,19 @@ func (s *rpcServer) Start() {
 		// Keep track of the number of connected clients.
 		s.incrementClients()
 		defer s.decrementClients()
-		_, isAdmin, err := s.checkAuth(r, true)
+		_, scope, err := s.checkAuth(r, true)
 		if err != nil {
 			jsonAuthFail(w)
 			return
 		}
 
 		// Read and respond to the request.
-		s.jsonRPCRead(w, r, isAdmin)
+		s.jsonRPCRead(w, r, scope)
 	})
 
 	// Websocket endpoint.
 	rpcServeMux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		authenticated, isAdmin, err := s.checkAuth(r, false)
+		authenticated, scope, err := s.checkAuth(r, false)
 		if err != nil {
 			jsonAuthFail(w)
 			return
@@ -4031,7 +8213,7 @@ func (s *rpcServer) Start() {
 			http.Error(w, "400 Bad Request.", http.StatusBadRequest)
 			return
 		}
-		s.WebsocketHandler(ws, r.RemoteAddr, authenticated, isAdmin)
+		s.WebsocketHandler(ws, r.RemoteAddr, authenticated, scope)
 	})
 
 	for _, listener := range s.cfg.Listeners {
@@ -4071,6 +8253,31 @@ func genCertPair(certFile, keyFile string) error {
 	return nil
 }
 
+// writeRPCCookie generates new random credentials and writes them to the
+// passed path in the username:password form of the authentication cookie of
+// Bitcoin Core, which is only readable by the current user.  The cookie is
+// first written to a temporary file so readers never see a partial cookie.
+// The written credentials are returned.
+func writeRPCCookie(path string) (string, error) {
+	var password [32]byte
+	if _, err := io.ReadFull(crand.Reader, password[:]); err != nil {
+		return "", err
+	}
+	login := rpcCookieUser + ":" + hex.EncodeToString(password[:])
+
+	tmpPath := path + ".tmp"
+	err := ioutil.WriteFile(tmpPath, []byte(login), 0600)
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("unable to write the RPC authentication "+
+			"cookie: %v", err)
+	}
+	return login, nil
+}
+
 // rpcserverPeer represents a peer for use with the RPC server.
 //
 // The interface contract requires that all of these methods are safe for
@@ -4090,6 +8297,14 @@ type rpcserverPeer interface {
 	// FeeFilter returns the requested current minimum fee rate for which
 	// transactions should be announced.
 	FeeFilter() int64
+
+	// BytesSentPerMsg returns the number of bytes sent to the peer for
+	// each message command.
+	BytesSentPerMsg() map[string]uint64
+
+	// BytesRecvPerMsg returns the number of bytes received from the peer
+	// for each message command.
+	BytesRecvPerMsg() map[string]uint64
 }
 
 // rpcserverConnManager represents a connection manager for use with the RPC
@@ -4143,10 +8358,34 @@ type rpcserverConnManager interface {
 	// connected peers.
 	BroadcastMessage(msg wire.Message)
 
-	// AddRebroadcastInventory adds the provided inventory to the list of
-	// inventories to be rebroadcast at random intervals until they show up
-	// in a block.
-	AddRebroadcastInventory(iv *wire.InvVect, data interface{})
+	// NodeAddresses returns up to the requested number of randomly
+	// selected addresses known to be good from the address manager.
+	NodeAddresses(count int) []*wire.NetAddress
+
+	// AddPeerAddress adds the provided address to the address manager as
+	// if it had been learned from a peer, and additionally marks it good
+	// when tried is set.  It returns whether the address is known to the
+	// address manager afterwards.
+	AddPeerAddress(na *wire.NetAddress, tried bool) bool
+
+	// AddressCounts returns the number of addresses in the new and tried
+	// tables of the address manager for each network.
+	AddressCounts() map[addrmgr.NetworkType]addrmgr.AddressCounts
+
+	// BanSubnet bans the provided subnet until the passed time and
+	// disconnects all peers with an address within it.  Attempting to ban
+	// a subnet which is already banned will return an error.
+	BanSubnet(subnet *net.IPNet, until time.Time) error
+
+	// UnbanSubnet removes the ban of the provided subnet.  Attempting to
+	// unban a subnet which is not banned will return an error.
+	UnbanSubnet(subnet *net.IPNet) error
+
+	// BannedSubnets returns all of the subnets which are currently banned.
+	BannedSubnets() []bannedSubnet
+
+	// ClearBanned removes all bans.
+	ClearBanned() error
 
 	// RelayTransactions generates and relays inventory vectors for all of
 	// the passed transactions to all connected peers.
@@ -4173,6 +8412,9 @@ type rpcserverSyncManager interface {
 	// used to sync from or 0 if there is none.
 	SyncPeerID() int32
 
+	// SyncStatus returns the progress of the chain synchronization.
+	SyncStatus() *syncStatus
+
 	// LocateHeaders returns the headers of the blocks after the first known
 	// block in the provided locators until the provided stop hash or the
 	// current tip is reached, up to a max of wire.MaxBlockHeadersPerMsg
@@ -4192,6 +8434,12 @@ type rpcserverConfig struct {
 	// the RPC server started.
 	StartupTime int64
 
+	// CookiePath is the path of the authentication cookie, which is written
+	// with new random credentials granting full access when the RPC server
+	// is created and removed when it is stopped.  It is empty when clients
+	// are not authenticated with a cookie.
+	CookiePath string
+
 	// ConnMgr defines the connection manager for the RPC server to use.  It
 	// provides the RPC server with a means to do things such as add,
 	// remove, connect, disconnect, and query peers as well as other
@@ -4211,6 +8459,10 @@ type rpcserverConfig struct {
 	// TxMemPool defines the transaction memory pool to interact with.
 	TxMemPool *mempool.TxPool
 
+	// FeeEstimator provides an estimate of the fee rate required for a
+	// transaction to be confirmed within a given number of blocks.
+	FeeEstimator *mempool.FeeEstimator
+
 	// These fields allow the RPC server to interface with mining.
 	//
 	// Generator produces block templates and the CPUMiner solves them using
@@ -4219,6 +8471,11 @@ type rpcserverConfig struct {
 	Generator *mining.BlkTmplGenerator
 	CPUMiner  *cpuminer.CPUMiner
 
+	// These fields define the verification caches the RPC server reports
+	// the statistics of.
+	SigCache    *txscript.SigCache
+	ScriptCache *txscript.ScriptCache
+
 	// These fields define any optional indexes the RPC server can make use
 	// of to provide additional data when queried.
 	TxIndex   *indexers.TxIndex
@@ -4246,6 +8503,24 @@ func newRPCServer(config *rpcserverConfig) (*rpcServer, error) {
 		auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
 		rpc.limitauthsha = sha256.Sum256([]byte(auth))
 	}
+	for _, user := range cfg.rpcScopedUsers {
+		login := user.name + ":" + user.password
+		auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
+		rpc.scopedAuths = append(rpc.scopedAuths, rpcScopedAuth{
+			authsha: sha256.Sum256([]byte(auth)),
+			scope:   user.scope,
+		})
+	}
+	if config.CookiePath != "" {
+		login, err := writeRPCCookie(config.CookiePath)
+		if err != nil {
+			return nil, err
+		}
+		auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
+		rpc.cookieauthsha = sha256.Sum256([]byte(auth))
+		rpcsLog.Infof("Wrote RPC authentication cookie to %s",
+			config.CookiePath)
+	}
 	rpc.ntfnMgr = newWsNotificationManager(&rpc)
 	rpc.cfg.Chain.Subscribe(rpc.handleBlockchainNotification)
 
@@ -4275,6 +8550,9 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockchain.Notifi
 			break
 		}
 
+		// Wake up the clients waiting for the tip to change.
+		s.tipWaitState.NotifyTipChanged()
+
 		// Notify registered websocket clients of incoming block.
 		s.ntfnMgr.NotifyBlockConnected(block)
 
@@ -4285,6 +8563,9 @@ func (s *rpcServer) handleBlockchainNotification(notification *blockchain.Notifi
 			break
 		}
 
+		// Wake up the clients waiting for the tip to change.
+		s.tipWaitState.NotifyTipChanged()
+
 		// Notify registered websocket clients.
 		s.ntfnMgr.NotifyBlockDisconnected(block)
 	}
@@ -0,0 +1,6077 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btclog"
+	"github.com/ltcsuite/ltcd/addrmgr"
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/blockchain/indexers"
+	"github.com/ltcsuite/ltcd/btcec"
+	"github.com/ltcsuite/ltcd/btcjson"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/database"
+	"github.com/ltcsuite/ltcd/descriptor"
+	"github.com/ltcsuite/ltcd/mempool"
+	"github.com/ltcsuite/ltcd/mining"
+	"github.com/ltcsuite/ltcd/mining/cpuminer"
+	"github.com/ltcsuite/ltcd/psbt"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+	"github.com/ltcsuite/ltcutil/gcs"
+	"github.com/ltcsuite/ltcutil/gcs/builder"
+)
+
+// TestCalcBlockStats ensures the statistics reported by getblockstats are
+// calculated properly for a block with a known set of transactions.
+func TestCalcBlockStats(t *testing.T) {
+	t.Parallel()
+
+	// Every output pays to a 1 byte script and every spent output paid to
+	// a 25 byte script.
+	pkScript := []byte{txscript.OP_TRUE}
+	prevPkScript := bytes.Repeat([]byte{txscript.OP_NOP}, 25)
+	prevOuts := make(map[wire.OutPoint]wire.TxOut)
+	prevOut := func(index uint32, value int64) wire.OutPoint {
+		outPoint := wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: index}
+		prevOuts[outPoint] = wire.TxOut{Value: value, PkScript: prevPkScript}
+		return outPoint
+	}
+
+	// The coinbase claims the subsidy and the fees of the other
+	// transactions.
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		SignatureScript:  []byte{txscript.OP_0, txscript.OP_0},
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(wire.NewTxOut(5000000000+1500, pkScript))
+
+	// A 62 byte transaction without witness data paying a fee of 1000.
+	// Its weight is 62*4 = 248 and its fee rate 1000*4/248 = 16.
+	tx1 := wire.NewMsgTx(1)
+	tx1.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: prevOut(0, 10000),
+		SignatureScript:  []byte{txscript.OP_TRUE},
+		Sequence:         wire.MaxTxInSequenceNum,
+	})
+	tx1.AddTxOut(wire.NewTxOut(9000, pkScript))
+
+	// A 110 byte transaction with witness data paying a fee of 500.  Its
+	// stripped size is 102 bytes, so its weight is 102*3+110 = 416 and its
+	// fee rate 500*4/416 = 4.
+	tx2 := wire.NewMsgTx(1)
+	for i := uint32(1); i <= 2; i++ {
+		tx2.AddTxIn(&wire.TxIn{
+			PreviousOutPoint: prevOut(i, 5000),
+			Witness:          wire.TxWitness{{0x01}},
+			Sequence:         wire.MaxTxInSequenceNum,
+		})
+	}
+	tx2.AddTxOut(wire.NewTxOut(9500, pkScript))
+
+	block := ltcutil.NewBlock(&wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:   4,
+			Timestamp: time.Unix(1500000000, 0),
+		},
+		Transactions: []*wire.MsgTx{coinbase, tx1, tx2},
+	})
+	block.SetHeight(100)
+	medianTime := time.Unix(1499999000, 0)
+
+	// Ensure the transactions have the sizes the expected statistics
+	// are based on.
+	if size := tx1.SerializeSize(); size != 62 {
+		t.Fatalf("unexpected size of tx1 -- got %d, want 62", size)
+	}
+	if size := tx2.SerializeSize(); size != 110 {
+		t.Fatalf("unexpected size of tx2 -- got %d, want 110", size)
+	}
+
+	want := &btcjson.GetBlockStatsResult{
+		AverageFee:         750,
+		AverageFeeRate:     1500 * 4 / 664,
+		AverageTxSize:      86,
+		Hash:               block.Hash().String(),
+		FeeRatePercentiles: []int64{4, 4, 4, 16, 16},
+		Height:             100,
+		Ins:                3,
+		MaxFee:             1000,
+		MaxFeeRate:         16,
+		MaxTxSize:          110,
+		MedianFee:          750,
+		MedianTime:         1499999000,
+		MedianTxSize:       86,
+		MinFee:             500,
+		MinFeeRate:         4,
+		MinTxSize:          62,
+		Outs:               3,
+		Subsidy:            5000000000,
+		SegWitTotalSize:    110,
+		SegWitTotalWeight:  416,
+		SegWitTxs:          1,
+		Time:               1500000000,
+		TotalOut:           18500,
+		TotalSize:          172,
+		TotalWeight:        664,
+		TotalFee:           1500,
+		Txs:                3,
+		UTXOIncrease:       0,
+
+		// Each created output accounts for 8+1+1+41 = 51 bytes while
+		// each spent output accounts for 8+1+25+41 = 75 bytes.
+		UTXOSizeIncrease: 3*51 - 3*75,
+	}
+	got := calcBlockStats(block, medianTime, prevOuts,
+		&chaincfg.MainNetParams)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected block stats -- got %+v, want %+v", got,
+			want)
+	}
+
+	// Without the spent outputs, only the statistics which do not involve
+	// fees are calculated.
+	want.AverageFee = 0
+	want.AverageFeeRate = 0
+	want.FeeRatePercentiles = []int64{0, 0, 0, 0, 0}
+	want.MaxFee = 0
+	want.MaxFeeRate = 0
+	want.MedianFee = 0
+	want.MinFee = 0
+	want.MinFeeRate = 0
+	want.TotalFee = 0
+	want.UTXOSizeIncrease = 3 * 51
+	got = calcBlockStats(block, medianTime, nil, &chaincfg.MainNetParams)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected block stats without spent outputs -- "+
+			"got %+v, want %+v", got, want)
+	}
+}
+
+// createRegtestDB creates a database in a temporary directory and returns it
+// along with a function which closes and removes it.  The database uses small
+// block files when pruning is going to be enabled with the provided non-zero
+// target so blocks are pruned once the chain is only a few hundred blocks long.
+func createRegtestDB(t *testing.T, pruneTarget uint64) (database.DB, func()) {
+	// The log rotator is not initialized by the tests, so disable the
+	// logging of the chain and indexes.
+	setLogLevel("CHAN", "off")
+	setLogLevel("INDX", "off")
+
+	dbPath, err := ioutil.TempDir("", "ltcdrpctest")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	args := []interface{}{filepath.Join(dbPath, "db"), wire.TestNet}
+	if pruneTarget != 0 {
+		args = append(args, uint32(4096))
+	}
+	db, err := database.Create("ffldb", args...)
+	if err != nil {
+		os.RemoveAll(dbPath)
+		t.Fatalf("unable to create db: %v", err)
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dbPath)
+	}
+}
+
+// newRegtestChainWithoutIndexes returns a new chain instance for the regression
+// test network which does not maintain any optional indexes along with a
+// teardown function the caller should invoke when done testing to clean up.
+func newRegtestChainWithoutIndexes(t *testing.T) (*blockchain.BlockChain, func()) {
+	db, teardown := createRegtestDB(t, 0)
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &chaincfg.RegressionNetParams,
+		TimeSource:  blockchain.NewMedianTime(),
+	})
+	if err != nil {
+		teardown()
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	return chain, teardown
+}
+
+// newRegtestChain returns a new chain instance for the regression test network
+// backed by a database in a temporary directory along with the database, the
+// committed filter index it maintains, and a teardown function the caller
+// should invoke when done testing to clean up.
+//
+// Pruning is enabled with the provided target when it is non-zero, in which
+// case the database uses small block files so blocks are pruned once the chain
+// is only a few hundred blocks long.
+//
+// The chain also maintains the indexes returned by the provided functions,
+// which are passed the database and network parameters.
+func newRegtestChain(t *testing.T, pruneTarget uint64, newIndexes ...func(database.DB, *chaincfg.Params) indexers.Indexer) (*blockchain.BlockChain, database.DB, *indexers.CfIndex, func()) {
+	db, teardown := createRegtestDB(t, pruneTarget)
+
+	params := chaincfg.RegressionNetParams
+	cfIndex := indexers.NewCfIndex(db, &params, true)
+	indexes := []indexers.Indexer{cfIndex}
+	for _, newIndex := range newIndexes {
+		indexes = append(indexes, newIndex(db, &params))
+	}
+	indexManager := indexers.NewManager(db, indexes)
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:           db,
+		ChainParams:  &params,
+		TimeSource:   blockchain.NewMedianTime(),
+		IndexManager: indexManager,
+		PruneTarget:  pruneTarget,
+	})
+	if err != nil {
+		teardown()
+		t.Fatalf("unable to create chain: %v", err)
+	}
+
+	// The new indexes still need to index the genesis block, so wait for
+	// them to catch up in order for the tests to start with indexes which
+	// are kept current.
+	closeDB := teardown
+	teardown = func() {
+		indexManager.Stop()
+		closeDB()
+	}
+	select {
+	case <-indexManager.CaughtUp():
+	case <-time.After(10 * time.Second):
+		teardown()
+		t.Fatal("timeout waiting for indexes to catch up")
+	}
+	return chain, db, cfIndex, teardown
+}
+
+// addRegtestBlock extends the main chain of the passed regression test network
+// chain instance with a block containing a coinbase paying to the passed script
+// followed by the provided transactions and returns the coinbase.  The proof of
+// work of the block is not solved.
+func addRegtestBlock(t *testing.T, chain *blockchain.BlockChain, pkScript []byte, txns ...*wire.MsgTx) *wire.MsgTx {
+	return addRegtestBlockVersion(t, chain, 1, pkScript, txns...)
+}
+
+// addRegtestBlockVersion is like addRegtestBlock except the version of the
+// block is provided, which allows blocks to signal for deployments.
+func addRegtestBlockVersion(t *testing.T, chain *blockchain.BlockChain, version int32, pkScript []byte, txns ...*wire.MsgTx) *wire.MsgTx {
+	best := chain.BestSnapshot()
+	height := best.Height + 1
+	params := &chaincfg.RegressionNetParams
+
+	coinbaseScript, err := txscript.NewScriptBuilder().
+		AddInt64(int64(height)).AddInt64(0).Script()
+	if err != nil {
+		t.Fatalf("unable to create coinbase script: %v", err)
+	}
+	coinbase := wire.NewMsgTx(1)
+	coinbase.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: *wire.NewOutPoint(&chainhash.Hash{},
+			wire.MaxPrevOutIndex),
+		SignatureScript: coinbaseScript,
+		Sequence:        wire.MaxTxInSequenceNum,
+	})
+	coinbase.AddTxOut(wire.NewTxOut(blockchain.CalcBlockSubsidy(height,
+		params), pkScript))
+
+	msgTxns := append([]*wire.MsgTx{coinbase}, txns...)
+	utilTxns := make([]*ltcutil.Tx, 0, len(msgTxns))
+	for _, tx := range msgTxns {
+		utilTxns = append(utilTxns, ltcutil.NewTx(tx))
+	}
+	merkles := blockchain.BuildMerkleTreeStore(utilTxns, false)
+	block := ltcutil.NewBlock(&wire.MsgBlock{
+		Header: wire.BlockHeader{
+			Version:    version,
+			PrevBlock:  best.Hash,
+			MerkleRoot: *merkles[len(merkles)-1],
+			Timestamp: params.GenesisBlock.Header.Timestamp.Add(
+				time.Duration(height) * time.Minute),
+			Bits: params.PowLimitBits,
+		},
+		Transactions: msgTxns,
+	})
+
+	// Solve the block since verifying the chain checks the proof of work
+	// of the stored blocks.
+	for blockchain.CheckProofOfWork(block, params.PowLimit) != nil {
+		block.MsgBlock().Header.Nonce++
+	}
+	_, isOrphan, err := chain.ProcessBlock(block, blockchain.BFNone)
+	if err != nil {
+		t.Fatalf("unable to process block %d: %v", height, err)
+	}
+	if isOrphan {
+		t.Fatalf("block %d is unexpectedly an orphan", height)
+	}
+	return coinbase
+}
+
+// newRegtestMempool returns a memory pool which validates transactions against
+// the passed regression test network chain instance and accepts non-standard
+// transactions so they may spend outputs paying to OP_TRUE.
+func newRegtestMempool(chain *blockchain.BlockChain) *mempool.TxPool {
+	return mempool.New(newRegtestMempoolConfig(chain))
+}
+
+// newRegtestMempoolConfig returns the configuration of the memory pools created
+// by newRegtestMempool, which allows the callers to set additional options.
+func newRegtestMempoolConfig(chain *blockchain.BlockChain) *mempool.Config {
+	return &mempool.Config{
+		Policy: mempool.Policy{
+			DisableRelayPriority: true,
+			AcceptNonStd:         true,
+			MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
+			MinRelayTxFee:        1000,
+			MaxTxVersion:         2,
+		},
+		ChainParams:    &chaincfg.RegressionNetParams,
+		FetchUtxoView:  chain.FetchUtxoView,
+		BestHeight:     func() int32 { return chain.BestSnapshot().Height },
+		MedianTimePast: func() time.Time { return chain.BestSnapshot().MedianTime },
+		CalcSequenceLock: func(tx *ltcutil.Tx, view *blockchain.UtxoViewpoint) (*blockchain.SequenceLock, error) {
+			return chain.CalcSequenceLock(tx, view, true)
+		},
+		IsDeploymentActive: chain.IsDeploymentActive,
+		SigCache:           txscript.NewSigCache(100),
+		HashCache:          txscript.NewHashCache(100),
+	}
+}
+
+// TestScanUtxoSet ensures scanning the utxo set for descriptors finds the
+// unspent outputs paying to them.
+func TestScanUtxoSet(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Mine a few blocks paying to a known address and one paying to a raw
+	// script.
+	params := &chaincfg.RegressionNetParams
+	addr, err := ltcutil.NewAddressPubKeyHash(bytes.Repeat([]byte{0x01},
+		20), params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	addrScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to create address script: %v", err)
+	}
+	rawScript := []byte{txscript.OP_TRUE}
+	coinbase1 := addRegtestBlock(t, chain, addrScript)
+	coinbase2 := addRegtestBlock(t, chain, rawScript)
+	coinbase3 := addRegtestBlock(t, chain, addrScript)
+	best := chain.BestSnapshot()
+
+	addrDesc := "addr(" + addr.EncodeAddress() + ")"
+	rawDesc := "raw(51)"
+	scripts := make(map[string]string)
+	for _, desc := range []string{addrDesc, rawDesc} {
+		pkScript, err := parseScanDescriptor(desc, params)
+		if err != nil {
+			t.Fatalf("parseScanDescriptor(%q): unexpected error: %v",
+				desc, err)
+		}
+		scripts[string(pkScript)] = desc
+	}
+
+	var state utxoScanState
+	abort, _ := state.begin()
+	result, err := scanUtxoSet(chain, map[string]string{
+		string(addrScript): addrDesc,
+	}, &state, abort)
+	if err != nil {
+		t.Fatalf("scanUtxoSet: unexpected error: %v", err)
+	}
+
+	// The outputs are visited in order of transaction hash.
+	unspent := func(coinbase *wire.MsgTx, height int64) btcjson.ScanTxOutSetUnspent {
+		return btcjson.ScanTxOutSetUnspent{
+			TxID:         coinbase.TxHash().String(),
+			Vout:         0,
+			ScriptPubKey: hex.EncodeToString(addrScript),
+			Desc:         addrDesc,
+			Amount:       50,
+			Height:       height,
+		}
+	}
+	unspents := []btcjson.ScanTxOutSetUnspent{
+		unspent(coinbase1, 1), unspent(coinbase3, 3),
+	}
+	hash1, hash3 := coinbase1.TxHash(), coinbase3.TxHash()
+	if bytes.Compare(hash1[:], hash3[:]) > 0 {
+		unspents[0], unspents[1] = unspents[1], unspents[0]
+	}
+	want := &btcjson.ScanTxOutSetResult{
+		Success:     true,
+		TxOuts:      3,
+		Height:      3,
+		BestBlock:   best.Hash.String(),
+		Unspents:    unspents,
+		TotalAmount: 100,
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("scanUtxoSet: unexpected result -- got %+v, want %+v",
+			result, want)
+	}
+
+	// Ensure all descriptors are matched when scanning for several.
+	result, err = scanUtxoSet(chain, scripts, &state, abort)
+	if err != nil {
+		t.Fatalf("scanUtxoSet: unexpected error: %v", err)
+	}
+	if len(result.Unspents) != 3 || result.TotalAmount != 150 {
+		t.Fatalf("scanUtxoSet: unexpected result for multiple "+
+			"descriptors -- got %d outputs totalling %v, want 3 "+
+			"totalling 150", len(result.Unspents),
+			result.TotalAmount)
+	}
+	for _, u := range result.Unspents {
+		if u.TxID == coinbase2.TxHash().String() && u.Desc != rawDesc {
+			t.Fatalf("scanUtxoSet: unexpected descriptor for raw "+
+				"script output -- got %q, want %q", u.Desc,
+				rawDesc)
+		}
+	}
+
+	// Ensure an aborted scan is reported as unsuccessful and the state no
+	// longer reports a scan in progress once it ends.
+	if !state.requestAbort() {
+		t.Fatal("requestAbort: unexpectedly no scan in progress")
+	}
+	if state.requestAbort() {
+		t.Fatal("requestAbort: unexpectedly aborted the scan twice")
+	}
+	result, err = scanUtxoSet(chain, scripts, &state, abort)
+	if err != nil {
+		t.Fatalf("scanUtxoSet: unexpected error: %v", err)
+	}
+	if result.Success {
+		t.Fatal("scanUtxoSet: aborted scan unexpectedly successful")
+	}
+	state.end()
+	if _, inProgress := state.status(); inProgress {
+		t.Fatal("status: scan unexpectedly still in progress")
+	}
+}
+
+// TestParseScanDescriptor ensures the descriptors supported by scantxoutset are
+// parsed properly and unsupported or malformed descriptors are rejected.
+func TestParseScanDescriptor(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.RegressionNetParams
+	addr, err := ltcutil.NewAddressPubKeyHash(bytes.Repeat([]byte{0x01},
+		20), params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	addrScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to create address script: %v", err)
+	}
+	mainNetAddr, err := ltcutil.NewAddressPubKeyHash(
+		bytes.Repeat([]byte{0x01}, 20), &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+
+	tests := []struct {
+		desc     string
+		pkScript []byte
+		valid    bool
+	}{
+		{"addr(" + addr.EncodeAddress() + ")", addrScript, true},
+		{"addr(" + addr.EncodeAddress() + ")#checksum", addrScript, true},
+		{"raw(76a9)", []byte{0x76, 0xa9}, true},
+		{"addr(" + mainNetAddr.EncodeAddress() + ")", nil, false},
+		{"addr(bogus)", nil, false},
+		{"raw()", nil, false},
+		{"raw(zz)", nil, false},
+		{"pkh(" + addr.EncodeAddress() + ")", nil, false},
+		{"raw(51", nil, false},
+		{"51", nil, false},
+	}
+	for _, test := range tests {
+		pkScript, err := parseScanDescriptor(test.desc, params)
+		if (err == nil) != test.valid {
+			t.Errorf("parseScanDescriptor(%q): unexpected error "+
+				"result -- got %v, want valid %v", test.desc, err,
+				test.valid)
+			continue
+		}
+		if !bytes.Equal(pkScript, test.pkScript) {
+			t.Errorf("parseScanDescriptor(%q): unexpected script "+
+				"-- got %x, want %x", test.desc, pkScript,
+				test.pkScript)
+		}
+	}
+}
+
+// TestCalcChainTxStats ensures the statistics reported by getchaintxstats are
+// calculated properly for a synthetic chain with known transaction counts.
+func TestCalcChainTxStats(t *testing.T) {
+	t.Parallel()
+
+	// Create a chain where each block contains one more transaction than
+	// its height and is mined 150 seconds after its parent.  The median
+	// time of each block trails its timestamp by 5 blocks.
+	const numBlocks = 20
+	chain := make([]*chainTxStatsBlock, 0, numBlocks)
+	var totalTxns uint64
+	for height := int32(0); height < numBlocks; height++ {
+		totalTxns += uint64(height + 1)
+		timestamp := time.Unix(1500000000+int64(height)*150, 0)
+		chain = append(chain, &chainTxStatsBlock{
+			hash:       chainhash.Hash{byte(height)},
+			height:     height,
+			timestamp:  timestamp,
+			medianTime: timestamp.Add(-5 * 150 * time.Second),
+			totalTxns:  totalTxns,
+		})
+	}
+
+	// The window after block 9 up to block 19 contains the transactions of
+	// blocks 10 through 19, which is 11+12+...+20 = 155 transactions over
+	// 10*150 = 1500 seconds.
+	end := chain[19]
+	windowTxns, interval, txRate := int64(155), int64(1500), 155.0/1500
+	want := &btcjson.GetChainTxStatsResult{
+		Time:                   end.timestamp.Unix(),
+		TxCount:                210,
+		WindowFinalBlockHash:   end.hash.String(),
+		WindowFinalBlockHeight: 19,
+		WindowBlockCount:       10,
+		WindowTxCount:          &windowTxns,
+		WindowInterval:         &interval,
+		TxRate:                 &txRate,
+	}
+	got := calcChainTxStats(end, chain[9])
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected chain tx stats -- got %+v, want %+v", got,
+			want)
+	}
+
+	// Without a window, only the totals are reported.
+	want.WindowBlockCount = 0
+	want.WindowTxCount = nil
+	want.WindowInterval = nil
+	want.TxRate = nil
+	got = calcChainTxStats(end, nil)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected chain tx stats without window -- got %+v, "+
+			"want %+v", got, want)
+	}
+
+	// A window which spans no time does not report a rate.
+	windowStart := *chain[18]
+	windowStart.medianTime = end.medianTime
+	got = calcChainTxStats(end, &windowStart)
+	if got.TxRate != nil || got.WindowInterval == nil ||
+		*got.WindowInterval != 0 || *got.WindowTxCount != 20 {
+
+		t.Fatalf("unexpected chain tx stats for zero interval -- got "+
+			"%+v", got)
+	}
+}
+
+// TestGetCFilter ensures the committed filters and filter headers served via
+// the getcfilter and getcfilterheader RPCs match the filters built from the
+// blocks once they round-trip through the RPC encoding.
+func TestGetCFilter(t *testing.T) {
+	t.Parallel()
+
+	chain, _, cfIndex, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Mine a block paying to a script with a data push so its filter is
+	// not empty.
+	pkScript := append([]byte{txscript.OP_DATA_20},
+		bytes.Repeat([]byte{0x01}, 20)...)
+	pkScript = append(pkScript, txscript.OP_DROP, txscript.OP_TRUE)
+	addRegtestBlock(t, chain, pkScript)
+	best := chain.BestSnapshot()
+	block, err := chain.BlockByHash(&best.Hash)
+	if err != nil {
+		t.Fatalf("unable to fetch block: %v", err)
+	}
+	wantFilter, err := builder.BuildBasicFilter(block.MsgBlock())
+	if err != nil {
+		t.Fatalf("unable to build filter: %v", err)
+	}
+	genesisFilter, err := builder.BuildBasicFilter(
+		chaincfg.RegressionNetParams.GenesisBlock)
+	if err != nil && err != gcs.ErrNoData {
+		t.Fatalf("unable to build genesis filter: %v", err)
+	}
+	wantHeader := builder.MakeHeaderForFilter(wantFilter,
+		builder.MakeHeaderForFilter(genesisFilter, chainhash.Hash{}))
+
+	// roundTrip marshals the passed RPC result as it would be sent to a
+	// client and unmarshals it back into a string.
+	roundTrip := func(result interface{}) string {
+		marshalled, err := btcjson.MarshalResponse(1, result, nil)
+		if err != nil {
+			t.Fatalf("unable to marshal response: %v", err)
+		}
+		var reply btcjson.Response
+		if err := json.Unmarshal(marshalled, &reply); err != nil {
+			t.Fatalf("unable to unmarshal response: %v", err)
+		}
+		var str string
+		if err := json.Unmarshal(reply.Result, &str); err != nil {
+			t.Fatalf("unable to unmarshal result: %v", err)
+		}
+		return str
+	}
+
+	s := &rpcServer{cfg: rpcserverConfig{CfIndex: cfIndex}}
+	for _, filterType := range []string{"basic", "regular"} {
+		result, err := handleGetCFilter(s, btcjson.NewGetCFilterCmd(
+			best.Hash.String(), btcjson.String(filterType)), nil)
+		if err != nil {
+			t.Fatalf("getcfilter %s: unexpected error: %v",
+				filterType, err)
+		}
+		filterBytes, err := hex.DecodeString(roundTrip(result))
+		if err != nil {
+			t.Fatalf("getcfilter %s: unable to decode filter: %v",
+				filterType, err)
+		}
+		filter, err := gcs.FromNBytes(builder.DefaultP, filterBytes)
+		if err != nil {
+			t.Fatalf("getcfilter %s: unable to deserialize filter: "+
+				"%v", filterType, err)
+		}
+		if !bytes.Equal(filter.NBytes(), wantFilter.NBytes()) {
+			t.Fatalf("getcfilter %s: got filter %x, want %x",
+				filterType, filter.NBytes(), wantFilter.NBytes())
+		}
+
+		result, err = handleGetCFilterHeader(s,
+			btcjson.NewGetCFilterHeaderCmd(best.Hash.String(),
+				btcjson.String(filterType)), nil)
+		if err != nil {
+			t.Fatalf("getcfilterheader %s: unexpected error: %v",
+				filterType, err)
+		}
+		header, err := chainhash.NewHashFromStr(roundTrip(result))
+		if err != nil {
+			t.Fatalf("getcfilterheader %s: unable to decode header: "+
+				"%v", filterType, err)
+		}
+		if *header != wantHeader {
+			t.Fatalf("getcfilterheader %s: got header %v, want %v",
+				filterType, header, wantHeader)
+		}
+	}
+
+	// Ensure unknown filter types and a disabled index are rejected.
+	wantCode := func(name string, err error, code btcjson.RPCErrorCode) {
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != code {
+			t.Fatalf("%s: got error %v, want code %d", name, err, code)
+		}
+	}
+	_, err = handleGetCFilter(s, btcjson.NewGetCFilterCmd(
+		best.Hash.String(), btcjson.String("extended")), nil)
+	wantCode("getcfilter unknown type", err, btcjson.ErrRPCInvalidParameter)
+	_, err = handleGetCFilterHeader(s, btcjson.NewGetCFilterHeaderCmd(
+		best.Hash.String(), btcjson.String("extended")), nil)
+	wantCode("getcfilterheader unknown type", err,
+		btcjson.ErrRPCInvalidParameter)
+
+	s = &rpcServer{}
+	basic := btcjson.String(btcjson.CFilterTypeBasic)
+	_, err = handleGetCFilter(s, btcjson.NewGetCFilterCmd(
+		best.Hash.String(), basic), nil)
+	wantCode("getcfilter disabled", err, btcjson.ErrRPCMisc)
+	_, err = handleGetCFilterHeader(s, btcjson.NewGetCFilterHeaderCmd(
+		best.Hash.String(), basic), nil)
+	wantCode("getcfilterheader disabled", err, btcjson.ErrRPCMisc)
+}
+
+// TestGetBlockFilter ensures the filters returned by the getblockfilter RPC
+// decode to the filters built from the blocks and that their headers chain to
+// the filter header of the previous block.
+func TestGetBlockFilter(t *testing.T) {
+	t.Parallel()
+
+	chain, _, cfIndex, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	pkScript := append([]byte{txscript.OP_DATA_20},
+		bytes.Repeat([]byte{0x01}, 20)...)
+	pkScript = append(pkScript, txscript.OP_DROP, txscript.OP_TRUE)
+	addRegtestBlock(t, chain, pkScript)
+	addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+
+	s := &rpcServer{cfg: rpcserverConfig{CfIndex: cfIndex}}
+	basic := btcjson.String(btcjson.CFilterTypeBasic)
+	getBlockFilter := func(hash *chainhash.Hash) *btcjson.GetBlockFilterResult {
+		result, err := handleGetBlockFilter(s,
+			btcjson.NewGetBlockFilterCmd(hash.String(), basic), nil)
+		if err != nil {
+			t.Fatalf("getblockfilter %v: unexpected error: %v", hash,
+				err)
+		}
+		return result.(*btcjson.GetBlockFilterResult)
+	}
+
+	// The header of the genesis block filter chains to the zero hash.
+	genesisHash := chaincfg.RegressionNetParams.GenesisHash
+	prevHeader, err := chainhash.NewHashFromStr(
+		getBlockFilter(genesisHash).Header)
+	if err != nil {
+		t.Fatalf("unable to decode genesis filter header: %v", err)
+	}
+	for height := int32(1); height <= chain.BestSnapshot().Height; height++ {
+		hash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			t.Fatalf("unable to fetch block hash: %v", err)
+		}
+		block, err := chain.BlockByHash(hash)
+		if err != nil {
+			t.Fatalf("unable to fetch block: %v", err)
+		}
+		wantFilter, err := builder.BuildBasicFilter(block.MsgBlock())
+		if err != nil && err != gcs.ErrNoData {
+			t.Fatalf("unable to build filter: %v", err)
+		}
+
+		// Blocks without any data to filter have an empty filter.
+		result := getBlockFilter(hash)
+		filterBytes, err := hex.DecodeString(result.Filter)
+		if err != nil {
+			t.Fatalf("height %d: unable to decode filter: %v",
+				height, err)
+		}
+		var filter *gcs.Filter
+		if wantFilter == nil {
+			if len(filterBytes) != 0 {
+				t.Fatalf("height %d: got filter %x, want empty "+
+					"filter", height, filterBytes)
+			}
+		} else {
+			filter, err = gcs.FromNBytes(builder.DefaultP, filterBytes)
+			if err != nil {
+				t.Fatalf("height %d: unable to deserialize "+
+					"filter: %v", height, err)
+			}
+			if !bytes.Equal(filter.NBytes(), wantFilter.NBytes()) {
+				t.Fatalf("height %d: got filter %x, want %x",
+					height, filter.NBytes(),
+					wantFilter.NBytes())
+			}
+		}
+
+		header, err := chainhash.NewHashFromStr(result.Header)
+		if err != nil {
+			t.Fatalf("height %d: unable to decode header: %v",
+				height, err)
+		}
+		wantHeader := builder.MakeHeaderForFilter(filter, *prevHeader)
+		if *header != wantHeader {
+			t.Fatalf("height %d: got header %v, want %v", height,
+				header, wantHeader)
+		}
+		prevHeader = header
+	}
+
+	// Ensure unknown blocks, unknown filter types and a disabled index are
+	// rejected.
+	wantCode := func(name string, err error, code btcjson.RPCErrorCode) {
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != code {
+			t.Fatalf("%s: got error %v, want code %d", name, err, code)
+		}
+	}
+	_, err = handleGetBlockFilter(s, btcjson.NewGetBlockFilterCmd(
+		chainhash.HashH([]byte("unknown")).String(), basic), nil)
+	wantCode("unknown block", err, btcjson.ErrRPCBlockNotFound)
+	_, err = handleGetBlockFilter(s, btcjson.NewGetBlockFilterCmd(
+		genesisHash.String(), btcjson.String("extended")), nil)
+	wantCode("unknown type", err, btcjson.ErrRPCInvalidParameter)
+	s = &rpcServer{}
+	_, err = handleGetBlockFilter(s, btcjson.NewGetBlockFilterCmd(
+		genesisHash.String(), basic), nil)
+	wantCode("disabled", err, btcjson.ErrRPCMisc)
+}
+
+// TestGetBlockHeaders ensures getblockheaders returns contiguous, properly
+// linked ranges of main chain headers in both the verbose and serialized forms.
+func TestGetBlockHeaders(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	const numBlocks = 10
+	for i := 0; i < numBlocks; i++ {
+		addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	}
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: &chaincfg.RegressionNetParams,
+	}}
+	getHeaders := func(hash string, count int32, verbose bool) interface{} {
+		result, err := handleGetBlockHeaders(s,
+			btcjson.NewGetBlockHeadersCmd(hash, &count, &verbose), nil)
+		if err != nil {
+			t.Fatalf("getblockheaders %s: unexpected error: %v", hash,
+				err)
+		}
+		return result
+	}
+
+	// Walk the entire chain several verbose headers at a time, starting
+	// each request from the next block hash of the final header returned
+	// by the previous one.
+	const count = 3
+	var headers []btcjson.GetBlockHeaderVerboseResult
+	next := chaincfg.RegressionNetParams.GenesisHash.String()
+	for next != "" {
+		result := getHeaders(next, count, true)
+		batch := result.([]btcjson.GetBlockHeaderVerboseResult)
+		if len(batch) == 0 || len(batch) > count {
+			t.Fatalf("getblockheaders %s: got %d headers, want "+
+				"between 1 and %d", next, len(batch), count)
+		}
+		headers = append(headers, batch...)
+		next = batch[len(batch)-1].NextHash
+	}
+	if len(headers) != numBlocks+1 {
+		t.Fatalf("got %d headers, want %d", len(headers), numBlocks+1)
+	}
+	for i, header := range headers {
+		height := int32(i)
+		hash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			t.Fatalf("unable to fetch block hash: %v", err)
+		}
+		if header.Hash != hash.String() || header.Height != height {
+			t.Fatalf("header %d: got block %s at height %d, want "+
+				"%v at height %d", i, header.Hash, header.Height,
+				hash, height)
+		}
+		if header.Confirmations != uint64(numBlocks-height+1) {
+			t.Fatalf("header %d: got %d confirmations, want %d", i,
+				header.Confirmations, numBlocks-height+1)
+		}
+		if i > 0 && header.PreviousHash != headers[i-1].Hash {
+			t.Fatalf("header %d: got previous hash %s, want %s", i,
+				header.PreviousHash, headers[i-1].Hash)
+		}
+		wantNext := ""
+		if i < numBlocks {
+			wantNext = headers[i+1].Hash
+		}
+		if header.NextHash != wantNext {
+			t.Fatalf("header %d: got next hash %q, want %q", i,
+				header.NextHash, wantNext)
+		}
+	}
+
+	// The serialized headers must describe the same linked range and the
+	// range must be limited to the tip of the main chain.
+	result := getHeaders(headers[4].Hash, 100, false)
+	hexHeaders := result.([]string)
+	if len(hexHeaders) != numBlocks-3 {
+		t.Fatalf("got %d serialized headers, want %d", len(hexHeaders),
+			numBlocks-3)
+	}
+	for i, hexHeader := range hexHeaders {
+		serialized, err := hex.DecodeString(hexHeader)
+		if err != nil {
+			t.Fatalf("serialized header %d: unable to decode: %v",
+				i, err)
+		}
+		var header wire.BlockHeader
+		err = header.Deserialize(bytes.NewReader(serialized))
+		if err != nil {
+			t.Fatalf("serialized header %d: unable to "+
+				"deserialize: %v", i, err)
+		}
+		want := headers[i+4]
+		if header.BlockHash().String() != want.Hash ||
+			header.PrevBlock.String() != want.PreviousHash {
+			t.Fatalf("serialized header %d: got block %v with "+
+				"previous block %v, want %s with previous "+
+				"block %s", i, header.BlockHash(),
+				header.PrevBlock, want.Hash, want.PreviousHash)
+		}
+	}
+
+	// Ensure invalid counts and unknown blocks are rejected.
+	wantCode := func(name string, err error, code btcjson.RPCErrorCode) {
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != code {
+			t.Fatalf("%s: got error %v, want code %d", name, err, code)
+		}
+	}
+	_, err := handleGetBlockHeaders(s, btcjson.NewGetBlockHeadersCmd(
+		headers[0].Hash, btcjson.Int32(0), nil), nil)
+	wantCode("zero count", err, btcjson.ErrRPCInvalidParameter)
+	_, err = handleGetBlockHeaders(s, btcjson.NewGetBlockHeadersCmd(
+		chainhash.HashH([]byte("unknown")).String(), nil, nil), nil)
+	wantCode("unknown block", err, btcjson.ErrRPCBlockNotFound)
+}
+
+// TestGetIndexInfo ensures getindexinfo reports the enabled indexes along with
+// the height they have processed and whether or not they are synced.
+func TestGetIndexInfo(t *testing.T) {
+	t.Parallel()
+
+	var txIndex *indexers.TxIndex
+	chain, db, cfIndex, teardown := newRegtestChain(t, 0,
+		func(db database.DB, params *chaincfg.Params) indexers.Indexer {
+			txIndex = indexers.NewTxIndex(db)
+			return txIndex
+		})
+	defer teardown()
+	for i := 0; i < 5; i++ {
+		addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	}
+
+	// Simulate a transaction index which is still catching up, as is the
+	// case when the node is stopped while the index is being built, by
+	// rewinding its tip to height 2.
+	const txIndexHeight = 2
+	tipHash, err := chain.BlockHashByHeight(txIndexHeight)
+	if err != nil {
+		t.Fatalf("unable to fetch block hash: %v", err)
+	}
+	err = db.Update(func(dbTx database.Tx) error {
+		serialized := make([]byte, chainhash.HashSize+4)
+		copy(serialized, tipHash[:])
+		binary.LittleEndian.PutUint32(serialized[chainhash.HashSize:],
+			txIndexHeight)
+		return dbTx.Metadata().Bucket([]byte("idxtips")).Put(
+			txIndex.Key(), serialized)
+	})
+	if err != nil {
+		t.Fatalf("unable to rewind transaction index tip: %v", err)
+	}
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:   chain,
+		TxIndex: txIndex,
+		CfIndex: cfIndex,
+	}}
+	result, err := handleGetIndexInfo(s, btcjson.NewGetIndexInfoCmd(nil), nil)
+	if err != nil {
+		t.Fatalf("getindexinfo: unexpected error: %v", err)
+	}
+	want := map[string]btcjson.GetIndexInfoResult{
+		"txindex": {Synced: false, BestBlockHeight: txIndexHeight},
+		"cfindex": {Synced: true, BestBlockHeight: 5},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("getindexinfo: got %v, want %v", result, want)
+	}
+
+	// Only the named index is reported when a name is provided, which
+	// results in nothing being reported for disabled indexes.
+	for _, name := range []string{"txindex", "addrindex"} {
+		result, err = handleGetIndexInfo(s,
+			btcjson.NewGetIndexInfoCmd(btcjson.String(name)), nil)
+		if err != nil {
+			t.Fatalf("getindexinfo %s: unexpected error: %v", name,
+				err)
+		}
+		wantNamed := make(map[string]btcjson.GetIndexInfoResult)
+		if info, ok := want[name]; ok {
+			wantNamed[name] = info
+		}
+		if !reflect.DeepEqual(result, wantNamed) {
+			t.Fatalf("getindexinfo %s: got %v, want %v", name,
+				result, wantNamed)
+		}
+	}
+}
+
+// TestWaitForBlock ensures the waitforblock, waitforblockheight, and
+// waitfornewblock commands block until the tip of the main chain changes as
+// requested or the timeout expires and return the resulting tip.
+func TestWaitForBlock(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	pkScript := []byte{txscript.OP_TRUE}
+	addRegtestBlock(t, chain, pkScript)
+
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain}}
+	chain.Subscribe(func(n *blockchain.Notification) {
+		switch n.Type {
+		case blockchain.NTBlockConnected, blockchain.NTBlockDisconnected:
+			s.tipWaitState.NotifyTipChanged()
+		}
+	})
+
+	// tipResult returns the expected result for the current tip.
+	tipResult := func() *btcjson.WaitForBlockResult {
+		best := chain.BestSnapshot()
+		return &btcjson.WaitForBlockResult{
+			Hash:   best.Hash.String(),
+			Height: best.Height,
+		}
+	}
+
+	// waitAsync invokes the passed handler in a separate goroutine and
+	// returns a channel which receives its result.
+	type handlerResult struct {
+		result interface{}
+		err    error
+	}
+	waitAsync := func(handler commandHandler, cmd interface{}) <-chan handlerResult {
+		c := make(chan handlerResult, 1)
+		go func() {
+			result, err := handler(s, cmd, nil)
+			c <- handlerResult{result, err}
+		}()
+		return c
+	}
+
+	// checkBlocked ensures the passed call is still waiting, then makes the
+	// change the call is waiting for and ensures it returns the new tip.
+	checkBlocked := func(name string, c <-chan handlerResult, change func()) {
+		select {
+		case r := <-c:
+			t.Fatalf("%s: returned early with %v (err %v)", name,
+				r.result, r.err)
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		change()
+		select {
+		case r := <-c:
+			if r.err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, r.err)
+			}
+			if want := tipResult(); !reflect.DeepEqual(r.result, want) {
+				t.Fatalf("%s: got %+v, want %+v", name, r.result,
+					want)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("%s: did not return after the tip changed", name)
+		}
+	}
+
+	mineBlock := func() { addRegtestBlock(t, chain, pkScript) }
+	c := waitAsync(handleWaitForNewBlock,
+		btcjson.NewWaitForNewBlockCmd(btcjson.Int64(0)))
+	checkBlocked("waitfornewblock", c, mineBlock)
+
+	height := int64(chain.BestSnapshot().Height) + 2
+	c = waitAsync(handleWaitForBlockHeight,
+		btcjson.NewWaitForBlockHeightCmd(height, btcjson.Int64(0)))
+	mineBlock()
+	checkBlocked("waitforblockheight", c, mineBlock)
+
+	// Wait for a block which is not the tip by invalidating the tip and
+	// then reconsidering it.
+	tipHash := chain.BestSnapshot().Hash
+	if err := chain.InvalidateBlock(&tipHash); err != nil {
+		t.Fatalf("InvalidateBlock: %v", err)
+	}
+	c = waitAsync(handleWaitForBlock, btcjson.NewWaitForBlockCmd(
+		tipHash.String(), btcjson.Int64(0)))
+	checkBlocked("waitforblock", c, func() {
+		if err := chain.ReconsiderBlock(&tipHash); err != nil {
+			t.Fatalf("ReconsiderBlock: %v", err)
+		}
+	})
+
+	// Calls which are already satisfied and calls which time out return the
+	// current tip right away.
+	tests := []struct {
+		name    string
+		handler commandHandler
+		cmd     interface{}
+	}{{
+		name:    "waitforblockheight",
+		handler: handleWaitForBlockHeight,
+		cmd:     btcjson.NewWaitForBlockHeightCmd(1, btcjson.Int64(0)),
+	}, {
+		name:    "waitforblock",
+		handler: handleWaitForBlock,
+		cmd: btcjson.NewWaitForBlockCmd(tipHash.String(),
+			btcjson.Int64(0)),
+	}, {
+		name:    "waitfornewblock",
+		handler: handleWaitForNewBlock,
+		cmd:     btcjson.NewWaitForNewBlockCmd(btcjson.Int64(10)),
+	}}
+	for _, test := range tests {
+		result, err := test.handler(s, test.cmd, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if want := tipResult(); !reflect.DeepEqual(result, want) {
+			t.Fatalf("%s: got %+v, want %+v", test.name, result, want)
+		}
+	}
+}
+
+// TestGetBlockTemplateLongPoll ensures long poll requests for block templates
+// return a new template once a block is connected and return the current
+// template once the long poll timeout elapses.
+func TestGetBlockTemplateLongPoll(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	pkScript := []byte{txscript.OP_TRUE}
+	addRegtestBlock(t, chain, pkScript)
+
+	params := &chaincfg.RegressionNetParams
+	timeSource := blockchain.NewMedianTime()
+	txPool := mempool.New(&mempool.Config{})
+	policy := mining.Policy{BlockMaxWeight: 4000000, BlockMaxSize: 1000000}
+	s := &rpcServer{
+		cfg: rpcserverConfig{
+			Chain:       chain,
+			ChainParams: params,
+			TimeSource:  timeSource,
+			TxMemPool:   txPool,
+			Generator: mining.NewBlkTmplGenerator(&policy, params,
+				txPool, chain, timeSource, txscript.NewSigCache(100),
+				txscript.NewHashCache(100)),
+		},
+		gbtWorkState: newGbtWorkState(timeSource),
+	}
+	chain.Subscribe(func(n *blockchain.Notification) {
+		if n.Type == blockchain.NTBlockConnected {
+			block := n.Data.(*ltcutil.Block)
+			s.gbtWorkState.NotifyBlockConnected(block.Hash())
+		}
+	})
+
+	// longPollAsync starts a long poll request with the passed ID in a
+	// separate goroutine and returns a channel which receives its result.
+	type handlerResult struct {
+		result *btcjson.GetBlockTemplateResult
+		err    error
+	}
+	longPollAsync := func(longPollID string) <-chan handlerResult {
+		c := make(chan handlerResult, 1)
+		go func() {
+			result, err := handleGetBlockTemplateLongPoll(s,
+				longPollID, true, nil)
+			tmpl, _ := result.(*btcjson.GetBlockTemplateResult)
+			c <- handlerResult{tmpl, err}
+		}()
+		return c
+	}
+	waitResult := func(name string, c <-chan handlerResult) *btcjson.GetBlockTemplateResult {
+		select {
+		case r := <-c:
+			if r.err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, r.err)
+			}
+			return r.result
+		case <-time.After(10 * time.Second):
+			t.Fatalf("%s: long poll did not return", name)
+		}
+		return nil
+	}
+
+	// An invalid long poll ID returns the current template right away and
+	// its long poll ID identifies the tip and memory pool state.
+	tmpl := waitResult("invalid id", longPollAsync("invalid"))
+	best := chain.BestSnapshot()
+	wantID := encodeTemplateID(&best.Hash, txPool.TransactionsUpdated())
+	if tmpl.LongPollID != wantID {
+		t.Fatalf("got long poll id %q, want %q", tmpl.LongPollID, wantID)
+	}
+
+	// A long poll for the current template must block until a new block
+	// is connected and then return a template building on it.
+	c := longPollAsync(tmpl.LongPollID)
+	select {
+	case r := <-c:
+		t.Fatalf("long poll returned early with %+v (err %v)", r.result,
+			r.err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	addRegtestBlock(t, chain, pkScript)
+	tmpl = waitResult("new block", c)
+	best = chain.BestSnapshot()
+	if tmpl.PreviousHash != best.Hash.String() ||
+		tmpl.Height != int64(best.Height)+1 {
+
+		t.Fatalf("new block: got template at height %d building on %s, "+
+			"want height %d building on %s", tmpl.Height,
+			tmpl.PreviousHash, best.Height+1, best.Hash)
+	}
+	if tmpl.SubmitOld == nil || *tmpl.SubmitOld {
+		t.Fatalf("new block: got submitold %v, want false",
+			tmpl.SubmitOld)
+	}
+	wantID = encodeTemplateID(&best.Hash, txPool.TransactionsUpdated())
+	if tmpl.LongPollID != wantID {
+		t.Fatalf("new block: got long poll id %q, want %q",
+			tmpl.LongPollID, wantID)
+	}
+
+	// A long poll for a template which is not stale returns the current
+	// template once the timeout elapses.
+	s.gbtWorkState.longPollTimeout = 50 * time.Millisecond
+	timedOut := waitResult("timeout", longPollAsync(tmpl.LongPollID))
+	if timedOut.LongPollID != tmpl.LongPollID {
+		t.Fatalf("timeout: got long poll id %q, want %q",
+			timedOut.LongPollID, tmpl.LongPollID)
+	}
+	if timedOut.SubmitOld == nil || !*timedOut.SubmitOld {
+		t.Fatalf("timeout: got submitold %v, want true",
+			timedOut.SubmitOld)
+	}
+}
+
+// TestGetNetworkHashPS ensures the network hashes per second are calculated
+// from the work and timestamps of the blocks in the requested window.
+func TestGetNetworkHashPS(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	for i := 0; i < 10; i++ {
+		addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	}
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: &chaincfg.RegressionNetParams,
+	}}
+
+	// Every block is at the minimum difficulty of the regression test
+	// network, which is expected to take 2 hashes to solve, and is one
+	// minute after the previous one, so the network solves 1 hash every
+	// 30 seconds.
+	if work := blockchain.CalcWork(chaincfg.RegressionNetParams.PowLimitBits); work.Int64() != 2 {
+		t.Fatalf("got block work %v, want 2", work)
+	}
+	tests := []struct {
+		name   string
+		blocks int
+		height int
+		want   float64
+	}{
+		{"recent blocks", 5, -1, 1.0 / 30},
+		{"blocks since difficulty change", -1, -1, 1.0 / 30},
+		{"more blocks than the chain", 120, -1, 1.0 / 30},
+		{"ending at height", 3, 4, 1.0 / 30},
+		{"height after tip", 5, 11, 0},
+		{"genesis height", 5, 0, 0},
+	}
+	for _, test := range tests {
+		cmd := btcjson.NewGetNetworkHashPSCmd(&test.blocks, &test.height)
+		result, err := handleGetNetworkHashPS(s, cmd, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if result != test.want {
+			t.Fatalf("%s: got %v, want %v", test.name, result,
+				test.want)
+		}
+	}
+}
+
+// TestGetMiningInfo ensures the mining information reflects the tip of the
+// chain and the memory pool.
+func TestGetMiningInfo(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	for i := 0; i < 3; i++ {
+		addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	}
+	params := &chaincfg.RegressionNetParams
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		TxMemPool:   mempool.New(&mempool.Config{}),
+		CPUMiner:    cpuminer.New(&cpuminer.Config{}),
+	}}
+
+	result, err := handleGetMiningInfo(s, &btcjson.GetMiningInfoCmd{}, nil)
+	if err != nil {
+		t.Fatalf("handleGetMiningInfo: unexpected error: %v", err)
+	}
+	info := result.(*btcjson.GetMiningInfoResult)
+
+	// Every block is at the minimum difficulty of the regression test
+	// network, which is a difficulty of 1.
+	best := chain.BestSnapshot()
+	if info.Blocks != 3 || info.Blocks != int64(best.Height) {
+		t.Fatalf("got blocks %d, want %d", info.Blocks, best.Height)
+	}
+	if want := getDifficultyRatio(best.Bits, params); info.Difficulty != want ||
+		info.Difficulty != 1 {
+
+		t.Fatalf("got difficulty %v, want 1", info.Difficulty)
+	}
+	if info.Chain != params.Name {
+		t.Fatalf("got chain %q, want %q", info.Chain, params.Name)
+	}
+	if info.CurrentBlockTx != 1 {
+		t.Fatalf("got current block transactions %d, want 1",
+			info.CurrentBlockTx)
+	}
+	if info.NetworkHashPS != 1.0/30 {
+		t.Fatalf("got network hashes per second %v, want %v",
+			info.NetworkHashPS, 1.0/30)
+	}
+	if info.PooledTx != 0 || info.TestNet || info.Generate {
+		t.Fatalf("got pooled transactions %d, testnet %v, generate %v, "+
+			"want 0, false, false", info.PooledTx, info.TestNet,
+			info.Generate)
+	}
+	if info.Warnings != "" || info.Errors != "" {
+		t.Fatalf("got warnings %q, errors %q, want none", info.Warnings,
+			info.Errors)
+	}
+}
+
+// chainSyncManager is an rpcserverSyncManager which submits blocks directly to
+// a chain.  Only the methods needed by the tests are implemented.
+type chainSyncManager struct {
+	rpcserverSyncManager
+	chain *blockchain.BlockChain
+}
+
+// SubmitBlock processes the passed block with the chain.
+func (m *chainSyncManager) SubmitBlock(block *ltcutil.Block, flags blockchain.BehaviorFlags) (bool, error) {
+	_, isOrphan, err := m.chain.ProcessBlock(block, flags)
+	return isOrphan, err
+}
+
+// IsCurrent reports the chain as current.
+func (m *chainSyncManager) IsCurrent() bool {
+	return true
+}
+
+// SyncStatus reports the chain as synced up to its best block.
+func (m *chainSyncManager) SyncStatus() *syncStatus {
+	best := m.chain.BestSnapshot()
+	return &syncStatus{
+		phase:        syncPhaseSynced,
+		headerHeight: best.Height,
+		blockHeight:  best.Height,
+		syncHeight:   best.Height,
+		windowStart:  best.Height + 1,
+		windowEnd:    best.Height,
+		progress:     1,
+	}
+}
+
+// TestGetSyncStatus ensures the phase and progress of the chain
+// synchronization tracked by the block manager are reported by the
+// getsyncstatus and getblockchaininfo commands during a headers-first initial
+// block download.
+func TestGetSyncStatus(t *testing.T) {
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	for i := 0; i < 3; i++ {
+		addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	}
+	best := chain.BestSnapshot()
+
+	setLogLevel("BMGR", "off")
+	bm, err := newBlockManager(&blockManagerConfig{
+		Chain:              chain,
+		ChainParams:        &chaincfg.RegressionNetParams,
+		DisableCheckpoints: true,
+		MaxPeers:           1,
+	})
+	if err != nil {
+		t.Fatalf("newBlockManager: unexpected error: %v", err)
+	}
+
+	// Simulate the headers of blocks 4 to 10 having been downloaded.  The
+	// first entry of the header list is the best block.
+	bm.headersFirstMode = true
+	bm.headerList.PushBack(&headerNode{height: best.Height, hash: &best.Hash})
+	for height := best.Height + 1; height <= 10; height++ {
+		hash := chainhash.Hash{byte(height)}
+		bm.headerList.PushBack(&headerNode{height: height, hash: &hash})
+	}
+	bm.setSyncPhase(syncPhaseHeaders)
+
+	bm.wg.Add(1)
+	go bm.blockHandler()
+	defer func() {
+		close(bm.quit)
+		bm.wg.Wait()
+	}()
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: &chaincfg.RegressionNetParams,
+		SyncMgr:     &rpcSyncMgr{blockMgr: bm},
+	}}
+	getSyncStatus := func() *btcjson.GetSyncStatusResult {
+		result, err := handleGetSyncStatus(s, nil, nil)
+		if err != nil {
+			t.Fatalf("getsyncstatus: unexpected error: %v", err)
+		}
+		return result.(*btcjson.GetSyncStatusResult)
+	}
+
+	// No blocks are requested while the headers are downloaded.
+	want := &btcjson.GetSyncStatusResult{
+		Phase:                "headers",
+		Headers:              10,
+		Blocks:               3,
+		SyncHeight:           10,
+		DownloadWindowStart:  4,
+		DownloadWindowEnd:    3,
+		VerificationProgress: 0.3,
+	}
+	if status := getSyncStatus(); !reflect.DeepEqual(status, want) {
+		t.Fatalf("getsyncstatus: got %+v, want %+v", status, want)
+	}
+
+	// Simulate the blocks of the headers before block 7 having been
+	// requested by pausing the block handler while modifying its state.
+	unpause := bm.Pause()
+	bm.headerList.Remove(bm.headerList.Front())
+	for e := bm.headerList.Front(); e != nil; e = e.Next() {
+		node := e.Value.(*headerNode)
+		if node.height == 7 {
+			bm.startHeader = e
+			break
+		}
+		bm.requestedBlocks[*node.hash] = struct{}{}
+	}
+	bm.setSyncPhase(syncPhaseBlocks)
+	close(unpause)
+
+	want.Phase = "blocks"
+	want.DownloadWindowEnd = 6
+	want.BlocksInFlight = 3
+	if status := getSyncStatus(); !reflect.DeepEqual(status, want) {
+		t.Fatalf("getsyncstatus: got %+v, want %+v", status, want)
+	}
+
+	// The header tip is reported by getblockchaininfo as well.
+	result, err := handleGetBlockChainInfo(s, nil, nil)
+	if err != nil {
+		t.Fatalf("getblockchaininfo: unexpected error: %v", err)
+	}
+	info := result.(*btcjson.GetBlockChainInfoResult)
+	if info.Blocks != 3 || info.Headers != 10 || !info.InitialBlockDownload {
+		t.Fatalf("getblockchaininfo: got blocks %d, headers %d and "+
+			"initial block download %v, want 3, 10 and true",
+			info.Blocks, info.Headers, info.InitialBlockDownload)
+	}
+}
+
+// TestEstimateVerificationProgress ensures the estimated verification progress
+// is near 1 when the best block is recent, small when the best block is far
+// behind the tip and always within [0, 1].
+func TestEstimateVerificationProgress(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1500000000, 0)
+	txRate := chaincfg.MainNetParams.TxRate
+	tests := []struct {
+		name      string
+		totalTxns uint64
+		bestTime  time.Time
+		txRate    float64
+		min, max  float64
+	}{
+		{
+			name:      "recent best block",
+			totalTxns: 20000000,
+			bestTime:  now.Add(-10 * time.Minute),
+			txRate:    txRate,
+			min:       0.9999,
+			max:       1,
+		},
+		{
+			name:      "best block years behind",
+			totalTxns: 100000,
+			bestTime:  now.Add(-4 * 365 * 24 * time.Hour),
+			txRate:    txRate,
+			min:       0,
+			max:       0.02,
+		},
+		{
+			name:      "best block in the future",
+			totalTxns: 100000,
+			bestTime:  now.Add(time.Hour),
+			txRate:    txRate,
+			min:       1,
+			max:       1,
+		},
+		{
+			name:      "no transaction rate",
+			totalTxns: 100000,
+			bestTime:  now.Add(-4 * 365 * 24 * time.Hour),
+			min:       1,
+			max:       1,
+		},
+		{
+			name:     "no transactions",
+			bestTime: now,
+			txRate:   txRate,
+			min:      0,
+			max:      0,
+		},
+	}
+
+	for _, test := range tests {
+		progress := estimateVerificationProgress(test.totalTxns,
+			test.bestTime, now, test.txRate)
+		if progress < test.min || progress > test.max {
+			t.Errorf("%s: got progress %v, want [%v, %v]", test.name,
+				progress, test.min, test.max)
+		}
+	}
+}
+
+// TestGenerateBlock ensures blocks are generated with exactly the requested
+// transactions and that invalid transactions are reported.
+func TestGenerateBlock(t *testing.T) {
+	t.Parallel()
+
+	// Accepted blocks are logged, so disable the logging of the RPC server
+	// since the log rotator is not initialized by the tests.
+	setLogLevel("RPCS", "off")
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Create enough blocks for the first coinbase to mature along with a
+	// transaction spending it.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity); i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+	coinbaseHash := coinbases[0].TxHash()
+	const fee = 1000
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(coinbases[0].TxOut[0].Value-fee, pkScript))
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatalf("unable to serialize transaction: %v", err)
+	}
+	txHex := hex.EncodeToString(buf.Bytes())
+
+	addr, err := ltcutil.NewAddressPubKeyHash(bytes.Repeat([]byte{0x01},
+		20), params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	addrScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to create address script: %v", err)
+	}
+
+	txMemPool := mempool.New(&mempool.Config{})
+	policy := mining.Policy{BlockMaxWeight: 4000000, BlockMaxSize: 1000000}
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		SyncMgr:     &chainSyncManager{chain: chain},
+		TxMemPool:   txMemPool,
+		Generator: mining.NewBlkTmplGenerator(&policy, params,
+			txMemPool, chain, blockchain.NewMedianTime(),
+			txscript.NewSigCache(100), txscript.NewHashCache(100)),
+	}}
+
+	// Generate a block with the transaction and ensure it extends the
+	// chain with the transaction and a coinbase claiming its fee.
+	result, err := handleGenerateBlock(s, btcjson.NewGenerateBlockCmd(
+		addr.EncodeAddress(), &[]string{txHex}, btcjson.Bool(true)), nil)
+	if err != nil {
+		t.Fatalf("generateblock: unexpected error: %v", err)
+	}
+	genResult := result.(*btcjson.GenerateBlockResult)
+	best := chain.BestSnapshot()
+	if genResult.Hash != best.Hash.String() {
+		t.Fatalf("got block hash %s, want best block %s",
+			genResult.Hash, best.Hash)
+	}
+	serializedBlock, err := hex.DecodeString(genResult.Hex)
+	if err != nil {
+		t.Fatalf("unable to decode block hex: %v", err)
+	}
+	block, err := ltcutil.NewBlockFromBytes(serializedBlock)
+	if err != nil {
+		t.Fatalf("unable to deserialize block: %v", err)
+	}
+	if *block.Hash() != best.Hash {
+		t.Fatalf("got block %s from hex, want %s", block.Hash(),
+			best.Hash)
+	}
+	msgBlock := block.MsgBlock()
+	if len(msgBlock.Transactions) != 2 ||
+		msgBlock.Transactions[1].TxHash() != tx.TxHash() {
+
+		t.Fatalf("got %d transactions, want the coinbase and %s",
+			len(msgBlock.Transactions), tx.TxHash())
+	}
+	coinbaseOut := msgBlock.Transactions[0].TxOut[0]
+	subsidy := blockchain.CalcBlockSubsidy(best.Height, params)
+	if coinbaseOut.Value != subsidy+fee ||
+		!bytes.Equal(coinbaseOut.PkScript, addrScript) {
+
+		t.Fatalf("got coinbase output %d to %x, want %d to %x",
+			coinbaseOut.Value, coinbaseOut.PkScript, subsidy+fee,
+			addrScript)
+	}
+
+	// The hex is only included when requested.
+	result, err = handleGenerateBlock(s, btcjson.NewGenerateBlockCmd(
+		addr.EncodeAddress(), nil, nil), nil)
+	if err != nil {
+		t.Fatalf("generateblock: unexpected error: %v", err)
+	}
+	genResult = result.(*btcjson.GenerateBlockResult)
+	best = chain.BestSnapshot()
+	if genResult.Hash != best.Hash.String() || genResult.Hex != "" {
+		t.Fatalf("got block hash %s with hex %q, want best block %s "+
+			"without hex", genResult.Hash, genResult.Hex, best.Hash)
+	}
+
+	// Invalid requests must not extend the chain.
+	tests := []struct {
+		name    string
+		output  string
+		txns    []string
+		code    btcjson.RPCErrorCode
+		message string
+	}{{
+		name:    "spent transaction",
+		output:  addr.EncodeAddress(),
+		txns:    []string{txHex},
+		code:    btcjson.ErrRPCVerify,
+		message: tx.TxHash().String(),
+	}, {
+		name:    "transaction not in mempool",
+		output:  addr.EncodeAddress(),
+		txns:    []string{tx.TxHash().String()},
+		code:    btcjson.ErrRPCInvalidAddressOrKey,
+		message: "not in mempool",
+	}, {
+		name:    "undecodable transaction",
+		output:  addr.EncodeAddress(),
+		txns:    []string{txHex[:len(txHex)-2]},
+		code:    btcjson.ErrRPCDeserialization,
+		message: "TX decode failed",
+	}, {
+		name:    "invalid address",
+		output:  "invalid",
+		code:    btcjson.ErrRPCInvalidAddressOrKey,
+		message: "Invalid address",
+	}}
+	for _, test := range tests {
+		txns := test.txns
+		_, err := handleGenerateBlock(s, btcjson.NewGenerateBlockCmd(
+			test.output, &txns, nil), nil)
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != test.code ||
+			!strings.Contains(rpcErr.Message, test.message) {
+
+			t.Errorf("%s: got error %v, want code %d containing %q",
+				test.name, err, test.code, test.message)
+		}
+	}
+	if newBest := chain.BestSnapshot(); newBest.Hash != best.Hash {
+		t.Fatalf("invalid requests extended the chain to %s",
+			newBest.Hash)
+	}
+}
+
+// TestTestMempoolAccept ensures testmempoolaccept reports whether or not
+// transactions would be accepted to the memory pool, including when they
+// exceed the max fee rate, without adding them to it.
+func TestTestMempoolAccept(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Create enough blocks for the first coinbase to mature.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity); i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+
+	txMemPool := newRegtestMempool(chain)
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		TxMemPool:   txMemPool,
+	}}
+
+	// spend returns a transaction spending the first output of the passed
+	// transaction with the passed fee along with its serialized hex.
+	spend := func(prevTx *wire.MsgTx, fee int64) (*wire.MsgTx, string) {
+		prevHash := prevTx.TxHash()
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0), nil,
+			nil))
+		tx.AddTxOut(wire.NewTxOut(prevTx.TxOut[0].Value-fee, pkScript))
+		var buf bytes.Buffer
+		if err := tx.Serialize(&buf); err != nil {
+			t.Fatalf("unable to serialize transaction: %v", err)
+		}
+		return tx, hex.EncodeToString(buf.Bytes())
+	}
+	parentTx, parentHex := spend(coinbases[0], 10000)
+	childTx, childHex := spend(parentTx, 10000)
+
+	tests := []struct {
+		name       string
+		rawTxns    []string
+		maxFeeRate *float64
+		want       []btcjson.TestMempoolAcceptResult
+	}{{
+		name:    "dependent pair",
+		rawTxns: []string{parentHex, childHex},
+		want: []btcjson.TestMempoolAcceptResult{{
+			TxID:    parentTx.TxHash().String(),
+			Allowed: true,
+			VSize:   int64(parentTx.SerializeSize()),
+			Fees:    &btcjson.TestMempoolAcceptFees{Base: 0.0001},
+		}, {
+			TxID:    childTx.TxHash().String(),
+			Allowed: true,
+			VSize:   int64(childTx.SerializeSize()),
+			Fees:    &btcjson.TestMempoolAcceptFees{Base: 0.0001},
+		}},
+	}, {
+		name:       "max fee rate exceeded",
+		rawTxns:    []string{parentHex},
+		maxFeeRate: btcjson.Float64(0.0001),
+		want: []btcjson.TestMempoolAcceptResult{{
+			TxID:         parentTx.TxHash().String(),
+			RejectReason: "max-fee-exceeded",
+			VSize:        int64(parentTx.SerializeSize()),
+		}},
+	}, {
+		name:       "max fee rate disabled",
+		rawTxns:    []string{parentHex},
+		maxFeeRate: btcjson.Float64(0),
+		want: []btcjson.TestMempoolAcceptResult{{
+			TxID:    parentTx.TxHash().String(),
+			Allowed: true,
+			VSize:   int64(parentTx.SerializeSize()),
+			Fees:    &btcjson.TestMempoolAcceptFees{Base: 0.0001},
+		}},
+	}}
+	for _, test := range tests {
+		result, err := handleTestMempoolAccept(s,
+			btcjson.NewTestMempoolAcceptCmd(test.rawTxns,
+				test.maxFeeRate), nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		got := result.([]btcjson.TestMempoolAcceptResult)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Fatalf("%s: got %+v, want %+v", test.name, got,
+				test.want)
+		}
+	}
+
+	// The child is rejected on its own since its parent was not added to
+	// the memory pool.
+	result, err := handleTestMempoolAccept(s,
+		btcjson.NewTestMempoolAcceptCmd([]string{childHex}, nil), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := result.([]btcjson.TestMempoolAcceptResult)
+	if len(got) != 1 || got[0].Allowed || got[0].RejectReason == "" {
+		t.Fatalf("got %+v, want the child to be rejected", got)
+	}
+	if txMemPool.Count() != 0 {
+		t.Fatalf("memory pool has %d transactions, want 0",
+			txMemPool.Count())
+	}
+
+	// Malformed packages are rejected as a whole.
+	_, err = handleTestMempoolAccept(s, btcjson.NewTestMempoolAcceptCmd(
+		[]string{childHex, parentHex}, nil), nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+
+		t.Fatalf("got error %v for unsorted package, want code %d", err,
+			btcjson.ErrRPCInvalidParameter)
+	}
+}
+
+// TestGetRawMempool ensures getrawmempool reports the fees of the transactions
+// and of their ancestors and descendants in verbose mode, and that the mempool
+// sequence it reports advances as transactions are added and removed.
+func TestGetRawMempool(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Create enough blocks for the first coinbase to mature.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity); i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+
+	txMemPool := newRegtestMempool(chain)
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		TxMemPool:   txMemPool,
+	}}
+	getSequence := func() *btcjson.GetRawMempoolSequenceResult {
+		result, err := handleGetRawMempool(s, btcjson.NewGetRawMempoolCmd(
+			nil, btcjson.Bool(true)), nil)
+		if err != nil {
+			t.Fatalf("getrawmempool: unexpected error: %v", err)
+		}
+		return result.(*btcjson.GetRawMempoolSequenceResult)
+	}
+	start := getSequence()
+	if len(start.TxIDs) != 0 {
+		t.Fatalf("got transactions %v in an empty pool", start.TxIDs)
+	}
+
+	// Add a parent and a child of it, each of which advances the mempool
+	// sequence.
+	spend := func(prevTx *wire.MsgTx, fee int64) *wire.MsgTx {
+		prevHash := prevTx.TxHash()
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0), nil,
+			nil))
+		tx.AddTxOut(wire.NewTxOut(prevTx.TxOut[0].Value-fee, pkScript))
+		_, err := txMemPool.ProcessTransaction(ltcutil.NewTx(tx), false,
+			false, 0)
+		if err != nil {
+			t.Fatalf("unable to add transaction to the pool: %v", err)
+		}
+		return tx
+	}
+	parent := spend(coinbases[0], 10000)
+	child := spend(parent, 20000)
+	added := getSequence()
+	if added.MempoolSequence != start.MempoolSequence+2 {
+		t.Fatalf("mempool sequence: got %d, want %d",
+			added.MempoolSequence, start.MempoolSequence+2)
+	}
+	if len(added.TxIDs) != 2 {
+		t.Fatalf("got transactions %v, want the parent and child",
+			added.TxIDs)
+	}
+
+	// The fees of the parent include those of its child as a descendant,
+	// and the fees of the child include those of its parent as an
+	// ancestor.
+	result, err := handleGetRawMempool(s, btcjson.NewGetRawMempoolCmd(
+		btcjson.Bool(true), nil), nil)
+	if err != nil {
+		t.Fatalf("getrawmempool: unexpected error: %v", err)
+	}
+	entries := result.(map[string]*btcjson.GetRawMempoolVerboseResult)
+	tests := []struct {
+		tx   *wire.MsgTx
+		fees btcjson.MempoolFees
+	}{
+		{parent, btcjson.MempoolFees{
+			Base:       0.0001,
+			Modified:   0.0001,
+			Ancestor:   0.0001,
+			Descendant: 0.0003,
+		}},
+		{child, btcjson.MempoolFees{
+			Base:       0.0002,
+			Modified:   0.0002,
+			Ancestor:   0.0003,
+			Descendant: 0.0002,
+		}},
+	}
+	for _, test := range tests {
+		entry, ok := entries[test.tx.TxHash().String()]
+		if !ok {
+			t.Fatalf("transaction %v is missing", test.tx.TxHash())
+		}
+		if entry.Fees != test.fees {
+			t.Fatalf("transaction %v: got fees %+v, want %+v",
+				test.tx.TxHash(), entry.Fees, test.fees)
+		}
+		if entry.Wtxid != test.tx.WitnessHash().String() {
+			t.Fatalf("transaction %v: got wtxid %s, want %s",
+				test.tx.TxHash(), entry.Wtxid,
+				test.tx.WitnessHash())
+		}
+		if entry.BIP125Replaceable != "no" || entry.Unbroadcast {
+			t.Fatalf("transaction %v: got replaceable %s and "+
+				"unbroadcast %v, want no and false",
+				test.tx.TxHash(), entry.BIP125Replaceable,
+				entry.Unbroadcast)
+		}
+	}
+
+	// Removing the child advances the mempool sequence again.
+	txMemPool.RemoveTransaction(ltcutil.NewTx(child), false,
+		mempool.RemovalReasonConflict)
+	removed := getSequence()
+	if removed.MempoolSequence != added.MempoolSequence+1 {
+		t.Fatalf("mempool sequence: got %d, want %d",
+			removed.MempoolSequence, added.MempoolSequence+1)
+	}
+	want := []string{parent.TxHash().String()}
+	if !reflect.DeepEqual(removed.TxIDs, want) {
+		t.Fatalf("got transactions %v, want %v", removed.TxIDs, want)
+	}
+
+	// Verbose results can't include the mempool sequence.
+	_, err = handleGetRawMempool(s, btcjson.NewGetRawMempoolCmd(
+		btcjson.Bool(true), btcjson.Bool(true)), nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+
+		t.Fatalf("verbose mempool sequence: got error %v, want code %v",
+			err, btcjson.ErrRPCInvalidParameter)
+	}
+}
+
+// TestGenerateToAddress ensures the CPU miner generates blocks paying to the
+// requested address or descriptor.
+func TestGenerateToAddress(t *testing.T) {
+	t.Parallel()
+
+	// Accepted blocks are logged, so disable the logging of the CPU miner
+	// since the log rotator is not initialized by the tests.
+	setLogLevel("MINR", "off")
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	params := &chaincfg.RegressionNetParams
+	policy := mining.Policy{BlockMaxWeight: 4000000, BlockMaxSize: 1000000}
+	generator := mining.NewBlkTmplGenerator(&policy, params,
+		mempool.New(&mempool.Config{}), chain, blockchain.NewMedianTime(),
+		txscript.NewSigCache(100), txscript.NewHashCache(100))
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		CPUMiner: cpuminer.New(&cpuminer.Config{
+			ChainParams:            params,
+			BlockTemplateGenerator: generator,
+			ProcessBlock: func(block *ltcutil.Block, flags blockchain.BehaviorFlags) (bool, error) {
+				_, isOrphan, err := chain.ProcessBlock(block, flags)
+				return isOrphan, err
+			},
+		}),
+	}}
+
+	addr, err := ltcutil.NewAddressPubKeyHash(bytes.Repeat([]byte{0x01},
+		20), params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	addrScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to create address script: %v", err)
+	}
+
+	// checkBlocks ensures the passed result contains the hashes of the
+	// passed number of blocks at the tip of the chain, each of which pays
+	// to the address.
+	checkBlocks := func(name string, result interface{}, numBlocks int) {
+		hashes := result.([]string)
+		if len(hashes) != numBlocks {
+			t.Fatalf("%s: got %d blocks, want %d", name, len(hashes),
+				numBlocks)
+		}
+		best := chain.BestSnapshot()
+		for i, hashStr := range hashes {
+			height := best.Height - int32(numBlocks-1-i)
+			block, err := chain.BlockByHeight(height)
+			if err != nil {
+				t.Fatalf("%s: unable to fetch block %d: %v", name,
+					height, err)
+			}
+			if block.Hash().String() != hashStr {
+				t.Fatalf("%s: got block %s at height %d, want %s",
+					name, block.Hash(), height, hashStr)
+			}
+			coinbaseOut := block.MsgBlock().Transactions[0].TxOut[0]
+			if !bytes.Equal(coinbaseOut.PkScript, addrScript) {
+				t.Fatalf("%s: block %d coinbase pays to %x, "+
+					"want %x", name, height,
+					coinbaseOut.PkScript, addrScript)
+			}
+		}
+	}
+
+	result, err := handleGenerateToAddress(s,
+		btcjson.NewGenerateToAddressCmd(3, addr.EncodeAddress(),
+			btcjson.Int64(1000000)), nil)
+	if err != nil {
+		t.Fatalf("generatetoaddress: unexpected error: %v", err)
+	}
+	checkBlocks("generatetoaddress", result, 3)
+	if height := chain.BestSnapshot().Height; height != 3 {
+		t.Fatalf("got height %d, want 3", height)
+	}
+
+	result, err = handleGenerateToDescriptor(s,
+		btcjson.NewGenerateToDescriptorCmd(2,
+			"addr("+addr.EncodeAddress()+")", btcjson.Int64(1000000)),
+		nil)
+	if err != nil {
+		t.Fatalf("generatetodescriptor: unexpected error: %v", err)
+	}
+	checkBlocks("generatetodescriptor", result, 2)
+
+	// Invalid requests must not generate any blocks.
+	tests := []struct {
+		name string
+		cmd  interface{}
+		code btcjson.RPCErrorCode
+	}{{
+		name: "invalid address",
+		cmd: btcjson.NewGenerateToAddressCmd(1, "invalid",
+			btcjson.Int64(1000000)),
+		code: btcjson.ErrRPCInvalidAddressOrKey,
+	}, {
+		name: "no blocks",
+		cmd: btcjson.NewGenerateToAddressCmd(0, addr.EncodeAddress(),
+			btcjson.Int64(1000000)),
+		code: btcjson.ErrRPCInvalidParameter,
+	}, {
+		name: "no tries",
+		cmd: btcjson.NewGenerateToAddressCmd(1, addr.EncodeAddress(),
+			btcjson.Int64(0)),
+		code: btcjson.ErrRPCInvalidParameter,
+	}, {
+		name: "invalid descriptor",
+		cmd: btcjson.NewGenerateToDescriptorCmd(1, "invalid",
+			btcjson.Int64(1000000)),
+		code: btcjson.ErrRPCInvalidAddressOrKey,
+	}}
+	for _, test := range tests {
+		var err error
+		switch cmd := test.cmd.(type) {
+		case *btcjson.GenerateToAddressCmd:
+			_, err = handleGenerateToAddress(s, cmd, nil)
+		case *btcjson.GenerateToDescriptorCmd:
+			_, err = handleGenerateToDescriptor(s, cmd, nil)
+		}
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != test.code {
+			t.Errorf("%s: got error %v, want code %d", test.name,
+				err, test.code)
+		}
+	}
+	if height := chain.BestSnapshot().Height; height != 5 {
+		t.Fatalf("invalid requests changed the height to %d", height)
+	}
+}
+
+// TestSetMiningFlags ensures the extra coinbase data set via setminingflags is
+// included in the coinbase of generated blocks after the block height and in
+// the coinbase data of block templates.
+func TestSetMiningFlags(t *testing.T) {
+	t.Parallel()
+
+	// Accepted blocks are logged, so disable the logging of the RPC server
+	// since the log rotator is not initialized by the tests.
+	setLogLevel("RPCS", "off")
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+
+	params := &chaincfg.RegressionNetParams
+	timeSource := blockchain.NewMedianTime()
+	txPool := mempool.New(&mempool.Config{})
+	policy := mining.Policy{BlockMaxWeight: 4000000, BlockMaxSize: 1000000}
+	s := &rpcServer{
+		cfg: rpcserverConfig{
+			Chain:       chain,
+			ChainParams: params,
+			SyncMgr:     &chainSyncManager{chain: chain},
+			TimeSource:  timeSource,
+			TxMemPool:   txPool,
+			Generator: mining.NewBlkTmplGenerator(&policy, params,
+				txPool, chain, timeSource, txscript.NewSigCache(100),
+				txscript.NewHashCache(100)),
+		},
+		gbtWorkState: newGbtWorkState(timeSource),
+	}
+
+	// Overlong data must be rejected.
+	overlong := strings.Repeat("a", mining.MaxExtraCoinbaseDataLen+1)
+	_, err := handleSetMiningFlags(s, btcjson.NewSetMiningFlagsCmd(overlong),
+		nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+
+		t.Fatalf("got error %v for overlong data, want code %d", err,
+			btcjson.ErrRPCInvalidParameter)
+	}
+
+	const data = "/test pool/"
+	_, err = handleSetMiningFlags(s, btcjson.NewSetMiningFlagsCmd(data), nil)
+	if err != nil {
+		t.Fatalf("setminingflags: unexpected error: %v", err)
+	}
+	wantFlags := mining.CoinbaseFlags + data
+
+	// The coinbase script of a generated block must start with the block
+	// height and end with the coinbase flags followed by the data.
+	addr, err := ltcutil.NewAddressPubKeyHash(bytes.Repeat([]byte{0x01},
+		20), params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	result, err := handleGenerateBlock(s, btcjson.NewGenerateBlockCmd(
+		addr.EncodeAddress(), nil, btcjson.Bool(true)), nil)
+	if err != nil {
+		t.Fatalf("generateblock: unexpected error: %v", err)
+	}
+	serializedBlock, err := hex.DecodeString(
+		result.(*btcjson.GenerateBlockResult).Hex)
+	if err != nil {
+		t.Fatalf("unable to decode block hex: %v", err)
+	}
+	block, err := ltcutil.NewBlockFromBytes(serializedBlock)
+	if err != nil {
+		t.Fatalf("unable to deserialize block: %v", err)
+	}
+	coinbase := block.Transactions()[0]
+	height, err := blockchain.ExtractCoinbaseHeight(coinbase)
+	if err != nil {
+		t.Fatalf("unable to extract coinbase height: %v", err)
+	}
+	if best := chain.BestSnapshot(); height != best.Height || height != 2 {
+		t.Fatalf("got coinbase height %d, want 2", height)
+	}
+	pushes, err := txscript.PushedData(
+		coinbase.MsgTx().TxIn[0].SignatureScript)
+	if err != nil {
+		t.Fatalf("unable to parse coinbase script: %v", err)
+	}
+	if flags := pushes[len(pushes)-1]; string(flags) != wantFlags {
+		t.Fatalf("got coinbase flags %q, want %q", flags, wantFlags)
+	}
+
+	// Block templates must report the flags as coinbase data.
+	tmplResult, err := handleGetBlockTemplateLongPoll(s, "", true, nil)
+	if err != nil {
+		t.Fatalf("getblocktemplate: unexpected error: %v", err)
+	}
+	tmpl := tmplResult.(*btcjson.GetBlockTemplateResult)
+	wantAux := hex.EncodeToString(append([]byte{byte(len(wantFlags))},
+		wantFlags...))
+	if tmpl.CoinbaseAux == nil || tmpl.CoinbaseAux.Flags != wantAux {
+		t.Fatalf("got coinbase aux %+v, want flags %s", tmpl.CoinbaseAux,
+			wantAux)
+	}
+}
+
+// TestSetVersionBit ensures the version bits set via setversionbit change the
+// version, vbavailable and vbrequired fields of block templates.
+func TestSetVersionBit(t *testing.T) {
+	t.Parallel()
+
+	// Forcing unknown version bits is logged, so disable the logging of
+	// the miner since the log rotator is not initialized by the tests.
+	setLogLevel("MINR", "off")
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Complete the first window so the deployments are started.
+	params := &chaincfg.RegressionNetParams
+	for i := uint32(1); i < params.MinerConfirmationWindow; i++ {
+		addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	}
+
+	timeSource := blockchain.NewMedianTime()
+	txPool := mempool.New(&mempool.Config{})
+	policy := mining.Policy{BlockMaxWeight: 4000000, BlockMaxSize: 1000000}
+	s := &rpcServer{
+		cfg: rpcserverConfig{
+			Chain:       chain,
+			ChainParams: params,
+			SyncMgr:     &chainSyncManager{chain: chain},
+			TimeSource:  timeSource,
+			TxMemPool:   txPool,
+			Generator: mining.NewBlkTmplGenerator(&policy, params,
+				txPool, chain, timeSource, txscript.NewSigCache(100),
+				txscript.NewHashCache(100)),
+		},
+		gbtWorkState: newGbtWorkState(timeSource),
+	}
+	setBit := func(bit uint8, signal *bool) {
+		_, err := handleSetVersionBit(s,
+			btcjson.NewSetVersionBitCmd(bit, signal), nil)
+		if err != nil {
+			t.Fatalf("setversionbit %d: unexpected error: %v", bit,
+				err)
+		}
+	}
+	getTemplate := func() *btcjson.GetBlockTemplateResult {
+		result, err := handleGetBlockTemplateLongPoll(s, "", true, nil)
+		if err != nil {
+			t.Fatalf("getblocktemplate: unexpected error: %v", err)
+		}
+		return result.(*btcjson.GetBlockTemplateResult)
+	}
+	dummyBit := params.Deployments[chaincfg.DeploymentTestDummy].BitNumber
+	dummyMask := int32(1) << dummyBit
+	const testBit = 20
+	testMask := int32(1) << testBit
+
+	// By default, the started deployments are signalled.
+	tmpl := getTemplate()
+	if tmpl.Version&dummyMask == 0 || tmpl.Version&testMask != 0 {
+		t.Fatalf("got default version %#x, want dummy bit only",
+			tmpl.Version)
+	}
+	if bit, ok := tmpl.VbAvailable["dummy"]; !ok || bit != int(dummyBit) {
+		t.Fatalf("got default vbavailable %v, want dummy bit %d",
+			tmpl.VbAvailable, dummyBit)
+	}
+	if tmpl.VbRequired != 0 {
+		t.Fatalf("got default vbrequired %#x, want 0", tmpl.VbRequired)
+	}
+
+	// Clearing the dummy bit and forcing a bit which is not used by any
+	// deployment must be reflected by the next template.
+	setBit(uint8(dummyBit), btcjson.Bool(false))
+	setBit(testBit, btcjson.Bool(true))
+	tmpl = getTemplate()
+	if tmpl.Version&dummyMask != 0 || tmpl.Version&testMask == 0 {
+		t.Fatalf("got version %#x, want test bit only", tmpl.Version)
+	}
+	if _, ok := tmpl.VbAvailable["dummy"]; ok {
+		t.Fatalf("got vbavailable %v, want no dummy deployment",
+			tmpl.VbAvailable)
+	}
+	if tmpl.VbRequired != int(testMask) {
+		t.Fatalf("got vbrequired %#x, want %#x", tmpl.VbRequired,
+			testMask)
+	}
+
+	// Restoring the defaults must signal the started deployments again.
+	setBit(uint8(dummyBit), nil)
+	setBit(testBit, nil)
+	tmpl = getTemplate()
+	if tmpl.Version&dummyMask == 0 || tmpl.Version&testMask != 0 ||
+		tmpl.VbRequired != 0 {
+
+		t.Fatalf("got restored version %#x and vbrequired %#x, want "+
+			"dummy bit only", tmpl.Version, tmpl.VbRequired)
+	}
+
+	// Bits above the maximum must be rejected.
+	_, err := handleSetVersionBit(s, btcjson.NewSetVersionBitCmd(
+		mining.MaxVersionBit+1, btcjson.Bool(true)), nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+
+		t.Fatalf("got error %v for invalid bit, want code %d", err,
+			btcjson.ErrRPCInvalidParameter)
+	}
+}
+
+// TestGetBlockTemplateProposal ensures block proposals are validated against
+// the consensus rules without extending the chain.
+func TestGetBlockTemplateProposal(t *testing.T) {
+	t.Parallel()
+
+	// Rejected proposals are logged, so disable the logging of the RPC
+	// server since the log rotator is not initialized by the tests.
+	setLogLevel("RPCS", "off")
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+
+	params := &chaincfg.RegressionNetParams
+	policy := mining.Policy{BlockMaxWeight: 4000000, BlockMaxSize: 1000000}
+	generator := mining.NewBlkTmplGenerator(&policy, params,
+		mempool.New(&mempool.Config{}), chain, blockchain.NewMedianTime(),
+		txscript.NewSigCache(100), txscript.NewHashCache(100))
+	template, err := generator.NewBlockTemplate(nil)
+	if err != nil {
+		t.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+	}
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		SyncMgr:     &chainSyncManager{chain: chain},
+	}}
+
+	// proposal returns a copy of the template block modified by the passed
+	// function.
+	proposal := func(modify func(*wire.MsgBlock)) *wire.MsgBlock {
+		var buf bytes.Buffer
+		if err := template.Block.Serialize(&buf); err != nil {
+			t.Fatalf("unable to serialize block: %v", err)
+		}
+		var msgBlock wire.MsgBlock
+		if err := msgBlock.Deserialize(&buf); err != nil {
+			t.Fatalf("unable to deserialize block: %v", err)
+		}
+		modify(&msgBlock)
+		return &msgBlock
+	}
+	updateMerkleRoot := func(msgBlock *wire.MsgBlock) {
+		merkles := blockchain.BuildMerkleTreeStore(
+			ltcutil.NewBlock(msgBlock).Transactions(), false)
+		msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
+	}
+
+	// solve sets the nonce of the passed block to the first one which makes
+	// its proof of work hash satisfy the target or not as requested.
+	target := blockchain.CompactToBig(template.Block.Header.Bits)
+	solve := func(msgBlock *wire.MsgBlock, valid bool) {
+		for nonce := uint32(0); ; nonce++ {
+			msgBlock.Header.Nonce = nonce
+			hash, err := msgBlock.Header.PowHash()
+			if err != nil {
+				t.Fatalf("unable to hash header: %v", err)
+			}
+			if (blockchain.HashToBig(hash).Cmp(target) <= 0) == valid {
+				return
+			}
+		}
+	}
+
+	// A transaction spending an output which does not exist.
+	missingInputTx := wire.NewMsgTx(wire.TxVersion)
+	missingInputTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: 1}, nil, nil))
+	missingInputTx.AddTxOut(wire.NewTxOut(1000, []byte{txscript.OP_TRUE}))
+
+	tests := []struct {
+		name     string
+		block    *wire.MsgBlock
+		checkPoW bool
+		want     interface{}
+	}{{
+		name:  "valid proposal",
+		block: proposal(func(*wire.MsgBlock) {}),
+		want:  nil,
+	}, {
+		name: "tampered coinbase",
+		block: proposal(func(msgBlock *wire.MsgBlock) {
+			msgBlock.Transactions[0].TxOut[0].Value++
+		}),
+		want: "bad-txnmrklroot",
+	}, {
+		name: "tampered coinbase with updated merkle root",
+		block: proposal(func(msgBlock *wire.MsgBlock) {
+			msgBlock.Transactions[0].TxOut[0].Value++
+			updateMerkleRoot(msgBlock)
+		}),
+		want: "bad-cb-value",
+	}, {
+		name: "invalid transaction",
+		block: proposal(func(msgBlock *wire.MsgBlock) {
+			msgBlock.AddTransaction(missingInputTx)
+			updateMerkleRoot(msgBlock)
+		}),
+		want: "bad-txns-missinginput",
+	}, {
+		name: "wrong previous block",
+		block: proposal(func(msgBlock *wire.MsgBlock) {
+			msgBlock.Header.PrevBlock = *params.GenesisHash
+		}),
+		want: "bad-prevblk",
+	}, {
+		name: "insufficient proof of work not checked",
+		block: proposal(func(msgBlock *wire.MsgBlock) {
+			solve(msgBlock, false)
+		}),
+		want: nil,
+	}, {
+		name: "insufficient proof of work",
+		block: proposal(func(msgBlock *wire.MsgBlock) {
+			solve(msgBlock, false)
+		}),
+		checkPoW: true,
+		want:     "high-hash",
+	}, {
+		name: "sufficient proof of work",
+		block: proposal(func(msgBlock *wire.MsgBlock) {
+			solve(msgBlock, true)
+		}),
+		checkPoW: true,
+		want:     nil,
+	}}
+
+	tip := chain.BestSnapshot().Hash
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := test.block.Serialize(&buf); err != nil {
+			t.Fatalf("%s: unable to serialize block: %v", test.name,
+				err)
+		}
+		cmd := btcjson.NewGetBlockTemplateCmd(&btcjson.TemplateRequest{
+			Mode:     "proposal",
+			Data:     hex.EncodeToString(buf.Bytes()),
+			CheckPoW: test.checkPoW,
+		})
+		result, err := handleGetBlockTemplate(s, cmd, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if result != test.want {
+			t.Fatalf("%s: got result %v, want %v", test.name, result,
+				test.want)
+		}
+		if best := chain.BestSnapshot().Hash; best != tip {
+			t.Fatalf("%s: proposal extended the chain to %v",
+				test.name, best)
+		}
+	}
+
+	// The valid proposal must still be accepted as a new block since the
+	// proposal did not add it to the chain.
+	block := ltcutil.NewBlock(tests[0].block)
+	_, isOrphan, err := chain.ProcessBlock(block, blockchain.BFNoPoWCheck)
+	if err != nil || isOrphan {
+		t.Fatalf("ProcessBlock: got orphan %v, error %v", isOrphan, err)
+	}
+	if best := chain.BestSnapshot().Hash; best != *block.Hash() {
+		t.Fatalf("got best block %v, want %v", best, block.Hash())
+	}
+}
+
+// TestGetBlockTemplateSelection ensures getblocktemplate requests which select
+// memory pool transactions include the transactions which must be included,
+// along with their parents, regardless of their fees and leave out the
+// excluded transactions.
+func TestGetBlockTemplateSelection(t *testing.T) {
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Create enough blocks for the first three coinbases to mature.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity)+2; i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+
+	// Add a low-fee parent and child along with two high-fee transactions
+	// to the memory pool.
+	txMemPool := newRegtestMempool(chain)
+	spend := func(prevTx *wire.MsgTx, fee int64) *wire.MsgTx {
+		prevHash := prevTx.TxHash()
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0), nil,
+			nil))
+		tx.AddTxOut(wire.NewTxOut(prevTx.TxOut[0].Value-fee, pkScript))
+		_, err := txMemPool.ProcessTransaction(ltcutil.NewTx(tx), false,
+			false, 0)
+		if err != nil {
+			t.Fatalf("unable to add transaction to the pool: %v", err)
+		}
+		return tx
+	}
+	lowParent := spend(coinbases[0], 200)
+	lowChild := spend(lowParent, 200)
+	highFee := spend(coinbases[1], 100000)
+	excluded := spend(coinbases[2], 100000)
+
+	oldCfg := cfg
+	cfg = &config{RegressionTest: true}
+	defer func() {
+		cfg = oldCfg
+	}()
+
+	// Transactions paying less than 5000 satoshi per kilobyte are left out
+	// of templates by the policy.
+	timeSource := blockchain.NewMedianTime()
+	policy := mining.Policy{
+		BlockMaxWeight: 4000000,
+		BlockMaxSize:   1000000,
+		TxMinFreeFee:   5000,
+	}
+	s := &rpcServer{
+		cfg: rpcserverConfig{
+			Chain:       chain,
+			ChainParams: params,
+			TimeSource:  timeSource,
+			TxMemPool:   txMemPool,
+			SyncMgr:     &chainSyncManager{chain: chain},
+			Generator: mining.NewBlkTmplGenerator(&policy, params,
+				txMemPool, chain, timeSource,
+				txscript.NewSigCache(100), txscript.NewHashCache(100)),
+		},
+		gbtWorkState: newGbtWorkState(timeSource),
+	}
+	getBlockTemplate := func(mustInclude, exclude []*wire.MsgTx) ([]string, error) {
+		request := &btcjson.TemplateRequest{Mode: "template"}
+		for _, tx := range mustInclude {
+			request.MustInclude = append(request.MustInclude,
+				tx.TxHash().String())
+		}
+		for _, tx := range exclude {
+			request.Exclude = append(request.Exclude,
+				tx.TxHash().String())
+		}
+		result, err := handleGetBlockTemplate(s,
+			btcjson.NewGetBlockTemplateCmd(request), nil)
+		if err != nil {
+			return nil, err
+		}
+		var txIDs []string
+		for _, tx := range result.(*btcjson.GetBlockTemplateResult).Transactions {
+			txIDs = append(txIDs, tx.Hash)
+		}
+		return txIDs, nil
+	}
+
+	// Without a selection, only the high-fee transactions are included.
+	txIDs, err := getBlockTemplate(nil, nil)
+	if err != nil {
+		t.Fatalf("getblocktemplate: unexpected error: %v", err)
+	}
+	if len(txIDs) != 2 {
+		t.Fatalf("got transactions %v, want the two high-fee ones", txIDs)
+	}
+
+	// Forcing the low-fee child includes its parent before it, ahead of
+	// the high-fee transaction, while the excluded one is left out.
+	txIDs, err = getBlockTemplate([]*wire.MsgTx{lowChild},
+		[]*wire.MsgTx{excluded})
+	if err != nil {
+		t.Fatalf("getblocktemplate: unexpected error: %v", err)
+	}
+	want := []string{
+		lowParent.TxHash().String(),
+		lowChild.TxHash().String(),
+		highFee.TxHash().String(),
+	}
+	if !reflect.DeepEqual(txIDs, want) {
+		t.Fatalf("got transactions %v, want %v", txIDs, want)
+	}
+
+	// Transactions which are not in the memory pool, or which depend on
+	// an excluded transaction, can't be included.
+	_, err = getBlockTemplate([]*wire.MsgTx{coinbases[3]}, nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCNoTxInfo {
+
+		t.Fatalf("unknown transaction: got error %v, want code %v", err,
+			btcjson.ErrRPCNoTxInfo)
+	}
+	_, err = getBlockTemplate([]*wire.MsgTx{lowChild},
+		[]*wire.MsgTx{lowParent})
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+
+		t.Fatalf("excluded parent: got error %v, want code %v", err,
+			btcjson.ErrRPCInvalidParameter)
+	}
+}
+
+// TestBlockTemplateAncestorFeeRate ensures block templates select transactions
+// by the fee rate of their packages, so a child paying a high fee pulls its
+// low-fee parent into the block ahead of an independent transaction paying a
+// fee rate between the two, and that the block weight limit applies to the
+// whole package.
+func TestBlockTemplateAncestorFeeRate(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Create enough blocks for the first two coinbases to mature.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity)+1; i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+
+	// Add a parent paying a low fee, a child of it paying a high fee and
+	// an independent transaction paying a fee rate between the two, which
+	// is lower than the fee rate of the parent and child together.
+	txMemPool := newRegtestMempool(chain)
+	spend := func(prevTx *wire.MsgTx, fee int64) *wire.MsgTx {
+		prevHash := prevTx.TxHash()
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0), nil,
+			nil))
+		tx.AddTxOut(wire.NewTxOut(prevTx.TxOut[0].Value-fee, pkScript))
+		_, err := txMemPool.ProcessTransaction(ltcutil.NewTx(tx), false,
+			false, 0)
+		if err != nil {
+			t.Fatalf("unable to add transaction to the pool: %v", err)
+		}
+		return tx
+	}
+	parent := spend(coinbases[0], 200)
+	child := spend(parent, 50000)
+	midFee := spend(coinbases[1], 20000)
+
+	newTemplate := func(maxWeight uint32) *wire.MsgBlock {
+		policy := mining.Policy{
+			BlockMaxWeight: maxWeight,
+			BlockMaxSize:   1000000,
+		}
+		generator := mining.NewBlkTmplGenerator(&policy, params,
+			txMemPool, chain, blockchain.NewMedianTime(),
+			txscript.NewSigCache(100), txscript.NewHashCache(100))
+		template, err := generator.NewBlockTemplate(nil)
+		if err != nil {
+			t.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+		}
+		return template.Block
+	}
+	wantTxns := func(name string, block *wire.MsgBlock, want ...*wire.MsgTx) {
+		var got, wantIDs []string
+		for _, tx := range block.Transactions[1:] {
+			got = append(got, tx.TxHash().String())
+		}
+		for _, tx := range want {
+			wantIDs = append(wantIDs, tx.TxHash().String())
+		}
+		if !reflect.DeepEqual(got, wantIDs) {
+			t.Fatalf("%s: got transactions %v, want %v", name, got,
+				wantIDs)
+		}
+	}
+
+	// The parent is pulled in by its child ahead of the mid-fee
+	// transaction.
+	block := newTemplate(4000000)
+	wantTxns("no limit", block, parent, child, midFee)
+
+	// Limit the weight of the block such that either the package of the
+	// parent and child or the mid-fee transaction fits in it, along with
+	// half of the weight of a transaction as a margin.
+	txWeight := func(tx *wire.MsgTx) int64 {
+		return blockchain.GetTransactionWeight(ltcutil.NewTx(tx))
+	}
+	baseWeight := blockchain.GetBlockWeight(ltcutil.NewBlock(block)) -
+		txWeight(parent) - txWeight(child) - txWeight(midFee)
+	margin := txWeight(midFee) / 2
+	block = newTemplate(uint32(baseWeight + txWeight(parent) +
+		txWeight(child) + margin))
+	wantTxns("package fits", block, parent, child)
+
+	// The whole package must fit in the block, so only the mid-fee
+	// transaction is included when the package does not.
+	block = newTemplate(uint32(baseWeight + txWeight(midFee) + margin))
+	wantTxns("package does not fit", block, midFee)
+}
+
+// TestBlockTemplateSizeLimits ensures block templates stop adding transactions
+// once either the block weight or the block size limit is reached, and that
+// low-fee transactions are only added to reach the minimum block weight.
+func TestBlockTemplateSizeLimits(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Create enough blocks for the first three coinbases to mature.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity)+2; i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+
+	// Add two transactions paying high fees and one paying a fee rate
+	// below the minimum fee rate of the policy below.
+	txMemPool := newRegtestMempool(chain)
+	spend := func(prevTx *wire.MsgTx, fee int64) *wire.MsgTx {
+		prevHash := prevTx.TxHash()
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0), nil,
+			nil))
+		tx.AddTxOut(wire.NewTxOut(prevTx.TxOut[0].Value-fee, pkScript))
+		_, err := txMemPool.ProcessTransaction(ltcutil.NewTx(tx), false,
+			false, 0)
+		if err != nil {
+			t.Fatalf("unable to add transaction to the pool: %v", err)
+		}
+		return tx
+	}
+	highFee := spend(coinbases[0], 50000)
+	midFee := spend(coinbases[1], 40000)
+	lowFee := spend(coinbases[2], 200)
+
+	newTemplate := func(minWeight, maxWeight, maxSize uint32) *wire.MsgBlock {
+		policy := mining.Policy{
+			BlockMinWeight: minWeight,
+			BlockMaxWeight: maxWeight,
+			BlockMaxSize:   maxSize,
+			TxMinFreeFee:   10000,
+		}
+		generator := mining.NewBlkTmplGenerator(&policy, params,
+			txMemPool, chain, blockchain.NewMedianTime(),
+			txscript.NewSigCache(100), txscript.NewHashCache(100))
+		template, err := generator.NewBlockTemplate(nil)
+		if err != nil {
+			t.Fatalf("NewBlockTemplate: unexpected error: %v", err)
+		}
+		return template.Block
+	}
+	wantTxns := func(name string, block *wire.MsgBlock, want ...*wire.MsgTx) {
+		var got, wantIDs []string
+		for _, tx := range block.Transactions[1:] {
+			got = append(got, tx.TxHash().String())
+		}
+		for _, tx := range want {
+			wantIDs = append(wantIDs, tx.TxHash().String())
+		}
+		if !reflect.DeepEqual(got, wantIDs) {
+			t.Fatalf("%s: got transactions %v, want %v", name, got,
+				wantIDs)
+		}
+	}
+
+	// Without limits, only the high-fee transactions are included.
+	block := newTemplate(0, 4000000, 1000000)
+	wantTxns("no limit", block, highFee, midFee)
+
+	// Limit the weight, and then the size, of the block such that only
+	// one of the transactions fits in it, along with half of the weight or
+	// size of a transaction as a margin.
+	txWeight := func(tx *wire.MsgTx) int64 {
+		return blockchain.GetTransactionWeight(ltcutil.NewTx(tx))
+	}
+	baseWeight := blockchain.GetBlockWeight(ltcutil.NewBlock(block)) -
+		txWeight(highFee) - txWeight(midFee)
+	baseSize := int64(block.SerializeSize() - highFee.SerializeSize() -
+		midFee.SerializeSize())
+	block = newTemplate(0, uint32(baseWeight+txWeight(highFee)+
+		txWeight(highFee)/2), 1000000)
+	wantTxns("low max weight", block, highFee)
+	block = newTemplate(0, 4000000, uint32(baseSize+
+		int64(highFee.SerializeSize()+highFee.SerializeSize()/2)))
+	wantTxns("low max size", block, highFee)
+
+	// The low-fee transaction is added while the block is below the
+	// minimum weight, but not once the block has reached it.
+	block = newTemplate(uint32(baseWeight+txWeight(highFee)+
+		txWeight(midFee)+txWeight(lowFee)), 4000000, 1000000)
+	wantTxns("below min weight", block, highFee, midFee, lowFee)
+	block = newTemplate(uint32(baseWeight+txWeight(highFee)), 4000000,
+		1000000)
+	wantTxns("min weight reached", block, highFee, midFee)
+}
+
+// TestIndexCatchUp ensures enabling a new index on an existing chain builds the
+// index in the background while blocks continue to be connected and keeps it
+// current once it has caught up.
+func TestIndexCatchUp(t *testing.T) {
+	t.Parallel()
+
+	// Build a chain without the transaction index.
+	chain, db, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	var coinbases []*wire.MsgTx
+	for i := 0; i < 100; i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain,
+			[]byte{txscript.OP_TRUE}))
+	}
+
+	// Load the chain again with the transaction index enabled and connect
+	// more blocks while the index is being caught up.
+	params := chaincfg.RegressionNetParams
+	txIndex := indexers.NewTxIndex(db)
+	indexManager := indexers.NewManager(db, []indexers.Indexer{
+		indexers.NewCfIndex(db, &params, true), txIndex,
+	})
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:           db,
+		ChainParams:  &params,
+		TimeSource:   blockchain.NewMedianTime(),
+		IndexManager: indexManager,
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	defer indexManager.Stop()
+	for i := 0; i < 10; i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain,
+			[]byte{txscript.OP_TRUE}))
+	}
+
+	select {
+	case <-indexManager.CaughtUp():
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the transaction index to catch up")
+	}
+
+	// Blocks connected once the index is caught up must be indexed too.
+	coinbases = append(coinbases, addRegtestBlock(t, chain,
+		[]byte{txscript.OP_TRUE}))
+
+	best := chain.BestSnapshot()
+	tipHash, tipHeight, err := txIndex.Tip()
+	if err != nil {
+		t.Fatalf("unable to fetch transaction index tip: %v", err)
+	}
+	if !tipHash.IsEqual(&best.Hash) || tipHeight != best.Height {
+		t.Fatalf("unexpected transaction index tip: got %v (height %d), "+
+			"want %v (height %d)", tipHash, tipHeight, best.Hash,
+			best.Height)
+	}
+	for i, coinbase := range coinbases {
+		txHash := coinbase.TxHash()
+		region, err := txIndex.TxBlockRegion(&txHash)
+		if err != nil {
+			t.Fatalf("unable to fetch block region of coinbase %d: "+
+				"%v", i, err)
+		}
+		if region == nil {
+			t.Fatalf("coinbase %d is not indexed", i)
+		}
+	}
+}
+
+// TestPruneIndexCatchUp ensures the blocks an index which is being caught up
+// still needs are not pruned so the index is able to finish catching up.
+func TestPruneIndexCatchUp(t *testing.T) {
+	t.Parallel()
+
+	// Build a chain without the committed filter index and with a prune
+	// target which is never reached so blocks are only pruned on demand.
+	const pruneTarget = 1 << 40
+	db, teardown := createRegtestDB(t, pruneTarget)
+	defer teardown()
+	params := chaincfg.RegressionNetParams
+	chain, err := blockchain.New(&blockchain.Config{
+		DB:          db,
+		ChainParams: &params,
+		TimeSource:  blockchain.NewMedianTime(),
+		PruneTarget: pruneTarget,
+	})
+	if err != nil {
+		t.Fatalf("unable to create chain: %v", err)
+	}
+	const numBlocks = 400
+	for i := 0; i < numBlocks; i++ {
+		addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	}
+
+	// loadChain loads the chain again with the passed index manager.
+	loadChain := func(indexManager *indexers.Manager) *blockchain.BlockChain {
+		chain, err := blockchain.New(&blockchain.Config{
+			DB:           db,
+			ChainParams:  &params,
+			TimeSource:   blockchain.NewMedianTime(),
+			IndexManager: indexManager,
+			PruneTarget:  pruneTarget,
+		})
+		if err != nil {
+			t.Fatalf("unable to create chain: %v", err)
+		}
+		return chain
+	}
+
+	// Enable the committed filter index, but stop catching it up before
+	// the chain is loaded so it is left catching up, and then prune as
+	// many blocks as possible.
+	cfIndex := indexers.NewCfIndex(db, &params, true)
+	indexManager := indexers.NewManager(db, []indexers.Indexer{cfIndex})
+	indexManager.Stop()
+	chain = loadChain(indexManager)
+	addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	pruneHeight, err := chain.PruneToHeight(numBlocks)
+	if err != nil {
+		t.Fatalf("unable to prune blocks: %v", err)
+	}
+	_, tipHeight, err := cfIndex.Tip()
+	if err != nil {
+		t.Fatalf("unable to fetch committed filter index tip: %v", err)
+	}
+	if tipHeight >= numBlocks-288 {
+		t.Fatalf("committed filter index caught up to height %d",
+			tipHeight)
+	}
+	if pruneHeight > tipHeight {
+		t.Fatalf("pruned to height %d beyond the committed filter index "+
+			"tip at height %d", pruneHeight, tipHeight)
+	}
+
+	// The index finishes catching up once the chain is loaded again.
+	cfIndex = indexers.NewCfIndex(db, &params, true)
+	indexManager = indexers.NewManager(db, []indexers.Indexer{cfIndex})
+	chain = loadChain(indexManager)
+	defer indexManager.Stop()
+	select {
+	case <-indexManager.CaughtUp():
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for the committed filter index to " +
+			"catch up")
+	}
+	best := chain.BestSnapshot()
+	tipHash, tipHeight, err := cfIndex.Tip()
+	if err != nil {
+		t.Fatalf("unable to fetch committed filter index tip: %v", err)
+	}
+	if !tipHash.IsEqual(&best.Hash) || tipHeight != best.Height {
+		t.Fatalf("unexpected committed filter index tip: got %v "+
+			"(height %d), want %v (height %d)", tipHash, tipHeight,
+			best.Hash, best.Height)
+	}
+}
+
+// TestGetBlockPrevOuts ensures getblock includes the outputs spent by the
+// inputs of each transaction along with the transaction fees when requested and
+// flags the spent outputs which are unavailable without the transaction index.
+func TestGetBlockPrevOuts(t *testing.T) {
+	t.Parallel()
+
+	var txIndex *indexers.TxIndex
+	chain, db, _, teardown := newRegtestChain(t, 0,
+		func(db database.DB, params *chaincfg.Params) indexers.Indexer {
+			txIndex = indexers.NewTxIndex(db)
+			return txIndex
+		})
+	defer teardown()
+
+	// Create enough blocks for the first coinbase to mature and then a
+	// block with a transaction spending it along with a transaction which
+	// spends an output created earlier in the same block.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity); i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+	coinbaseHash := coinbases[0].TxHash()
+	value := coinbases[0].TxOut[0].Value
+	const fee1, fee2 = 1000, 500
+	tx1 := wire.NewMsgTx(1)
+	tx1.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0), nil, nil))
+	tx1.AddTxOut(wire.NewTxOut(value/2, pkScript))
+	tx1.AddTxOut(wire.NewTxOut(value-value/2-fee1, pkScript))
+	tx1Hash := tx1.TxHash()
+	tx2 := wire.NewMsgTx(1)
+	tx2.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&tx1Hash, 1), nil, nil))
+	tx2.AddTxOut(wire.NewTxOut(tx1.TxOut[1].Value-fee2, pkScript))
+	addRegtestBlock(t, chain, pkScript, tx1, tx2)
+	best := chain.BestSnapshot()
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		DB:          db,
+		TxIndex:     txIndex,
+	}}
+	getBlock := func(verboseTx, verbosePrevOut bool) []btcjson.TxRawResult {
+		result, err := handleGetBlock(s, btcjson.NewGetBlockCmd(
+			best.Hash.String(), btcjson.Bool(true), &verboseTx,
+			&verbosePrevOut), nil)
+		if err != nil {
+			t.Fatalf("getblock: unexpected error: %v", err)
+		}
+		rawTxns := result.(btcjson.GetBlockVerboseResult).RawTx
+		if len(rawTxns) != 3 {
+			t.Fatalf("getblock: got %d transactions, want 3",
+				len(rawTxns))
+		}
+		return rawTxns
+	}
+	checkPrevOut := func(name string, vin *btcjson.Vin, want *wire.TxOut) {
+		if want == nil {
+			if vin.PrevOut != nil || !vin.PrevOutUnavailable {
+				t.Fatalf("%s: got prevout %v (unavailable %v), "+
+					"want unavailable", name, vin.PrevOut,
+					vin.PrevOutUnavailable)
+			}
+			return
+		}
+		if vin.PrevOut == nil || vin.PrevOutUnavailable {
+			t.Fatalf("%s: prevout unavailable", name)
+		}
+		wantValue := ltcutil.Amount(want.Value).ToBTC()
+		wantScript := hex.EncodeToString(want.PkScript)
+		if vin.PrevOut.Value != wantValue ||
+			vin.PrevOut.ScriptPubKey.Hex != wantScript {
+			t.Fatalf("%s: got prevout value %v and script %s, want "+
+				"%v and %s", name, vin.PrevOut.Value,
+				vin.PrevOut.ScriptPubKey.Hex, wantValue,
+				wantScript)
+		}
+	}
+	checkFee := func(name string, rawTxn *btcjson.TxRawResult, want int64) {
+		if want < 0 {
+			if rawTxn.Fee != nil {
+				t.Fatalf("%s: got fee %v, want none", name,
+					*rawTxn.Fee)
+			}
+			return
+		}
+		wantFee := ltcutil.Amount(want).ToBTC()
+		if rawTxn.Fee == nil || *rawTxn.Fee != wantFee {
+			t.Fatalf("%s: got fee %v, want %v", name, rawTxn.Fee,
+				wantFee)
+		}
+	}
+
+	// The spent outputs and fees are included when requested, which also
+	// implies verbose transactions.  The output spent from an earlier
+	// block is resolved via the transaction index.
+	rawTxns := getBlock(false, true)
+	if rawTxns[0].Vin[0].PrevOut != nil || rawTxns[0].Fee != nil {
+		t.Fatalf("coinbase: unexpected prevout or fee")
+	}
+	checkPrevOut("tx1", &rawTxns[1].Vin[0], coinbases[0].TxOut[0])
+	checkFee("tx1", &rawTxns[1], fee1)
+	checkPrevOut("tx2", &rawTxns[2].Vin[0], tx1.TxOut[1])
+	checkFee("tx2", &rawTxns[2], fee2)
+
+	// Neither spent outputs nor fees are included unless requested.
+	rawTxns = getBlock(true, false)
+	for i := range rawTxns {
+		vin := &rawTxns[i].Vin[0]
+		if vin.PrevOut != nil || vin.PrevOutUnavailable {
+			t.Fatalf("tx %d: unexpected prevout", i)
+		}
+		checkFee("unrequested", &rawTxns[i], -1)
+	}
+
+	// Without the transaction index, only the output created in the same
+	// block is available and the fee is only known for the transaction
+	// spending it.
+	s.cfg.TxIndex = nil
+	rawTxns = getBlock(true, true)
+	checkPrevOut("no txindex tx1", &rawTxns[1].Vin[0], nil)
+	checkFee("no txindex tx1", &rawTxns[1], -1)
+	checkPrevOut("no txindex tx2", &rawTxns[2].Vin[0], tx1.TxOut[1])
+	checkFee("no txindex tx2", &rawTxns[2], fee2)
+}
+
+// TestGetRawTransactionPrevOuts ensures getrawtransaction includes the outputs
+// spent by the inputs of a transaction along with its fee at verbosity level 2
+// while remaining compatible with the lower levels.
+func TestGetRawTransactionPrevOuts(t *testing.T) {
+	t.Parallel()
+
+	var txIndex *indexers.TxIndex
+	chain, db, _, teardown := newRegtestChain(t, 0,
+		func(db database.DB, params *chaincfg.Params) indexers.Indexer {
+			txIndex = indexers.NewTxIndex(db)
+			return txIndex
+		})
+	defer teardown()
+
+	// Create enough blocks for the first two coinbases to mature and then
+	// a block with a transaction spending both of them.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity)+1; i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+	const fee = 2500
+	tx := wire.NewMsgTx(1)
+	var totalIn int64
+	for _, coinbase := range coinbases[:2] {
+		coinbaseHash := coinbase.TxHash()
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0), nil,
+			nil))
+		totalIn += coinbase.TxOut[0].Value
+	}
+	tx.AddTxOut(wire.NewTxOut(totalIn/2, pkScript))
+	tx.AddTxOut(wire.NewTxOut(totalIn-totalIn/2-fee, pkScript))
+	coinbase := addRegtestBlock(t, chain, pkScript, tx)
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		DB:          db,
+		TxIndex:     txIndex,
+		TxMemPool:   mempool.New(&mempool.Config{}),
+	}}
+	getRawTx := func(tx *wire.MsgTx, verbosity int) interface{} {
+		result, err := handleGetRawTransaction(s,
+			btcjson.NewGetRawTransactionCmd(tx.TxHash().String(),
+				btcjson.VerbosityLevel(verbosity)), nil)
+		if err != nil {
+			t.Fatalf("getrawtransaction %d: unexpected error: %v",
+				verbosity, err)
+		}
+		return result
+	}
+
+	// Both spent outputs and the fee are included at verbosity level 2.
+	rawTxn := getRawTx(tx, 2).(btcjson.TxRawResult)
+	for i, vin := range rawTxn.Vin {
+		want := coinbases[i].TxOut[0]
+		prevOut := vin.PrevOut
+		if prevOut == nil || vin.PrevOutUnavailable {
+			t.Fatalf("input %d: prevout unavailable", i)
+		}
+		wantValue := ltcutil.Amount(want.Value).ToBTC()
+		wantScript := hex.EncodeToString(want.PkScript)
+		if !prevOut.Generated || prevOut.Value != wantValue ||
+			prevOut.ScriptPubKey.Hex != wantScript {
+			t.Fatalf("input %d: got prevout %+v, want generated "+
+				"output with value %v and script %s", i,
+				prevOut, wantValue, wantScript)
+		}
+	}
+	wantFee := ltcutil.Amount(fee).ToBTC()
+	if rawTxn.Fee == nil || *rawTxn.Fee != wantFee {
+		t.Fatalf("got fee %v, want %v", rawTxn.Fee, wantFee)
+	}
+
+	// Coinbase transactions have neither spent outputs nor a fee.
+	rawTxn = getRawTx(coinbase, 2).(btcjson.TxRawResult)
+	if rawTxn.Vin[0].PrevOut != nil || rawTxn.Vin[0].PrevOutUnavailable ||
+		rawTxn.Fee != nil {
+
+		t.Fatalf("coinbase: unexpected prevout or fee")
+	}
+
+	// The lower verbosity levels are unchanged.
+	rawTxn = getRawTx(tx, 1).(btcjson.TxRawResult)
+	if rawTxn.Vin[0].PrevOut != nil || rawTxn.Fee != nil {
+		t.Fatalf("verbosity 1: unexpected prevout or fee")
+	}
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		t.Fatalf("unable to serialize transaction: %v", err)
+	}
+	wantHex := hex.EncodeToString(buf.Bytes())
+	if got := getRawTx(tx, 0).(string); got != wantHex {
+		t.Fatalf("verbosity 0: got %s, want %s", got, wantHex)
+	}
+}
+
+// TestGetBlockPruned ensures requesting a block which has been pruned returns
+// a distinct error while the remaining blocks are still returned and the
+// pruned state is reported by getblockchaininfo.
+func TestGetBlockPruned(t *testing.T) {
+	t.Parallel()
+
+	chain, db, _, teardown := newRegtestChain(t, 1)
+	defer teardown()
+
+	for i := 0; i < 320; i++ {
+		addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	}
+	pruneHeight := chain.PruneHeight()
+	if pruneHeight < 1 {
+		t.Fatalf("no blocks were pruned (prune height %d)", pruneHeight)
+	}
+
+	wantCode := func(name string, err error, code btcjson.RPCErrorCode) {
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != code {
+			t.Fatalf("%s: got error %v, want code %d", name, err, code)
+		}
+	}
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: &chaincfg.RegressionNetParams,
+		DB:          db,
+		SyncMgr:     &chainSyncManager{chain: chain},
+	}}
+	getBlock := func(height int32) (interface{}, error) {
+		hash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			t.Fatalf("unable to get hash of block %d: %v", height, err)
+		}
+		return handleGetBlock(s, btcjson.NewGetBlockCmd(hash.String(),
+			btcjson.Bool(false), nil, nil), nil)
+	}
+
+	_, err := getBlock(pruneHeight)
+	wantCode("getblock pruned", err, btcjson.ErrRPCBlockPruned)
+	if _, err := getBlock(pruneHeight + 1); err != nil {
+		t.Fatalf("getblock: unexpected error: %v", err)
+	}
+	_, err = handleGetBlock(s, btcjson.NewGetBlockCmd(
+		chainhash.Hash{0x01}.String(), btcjson.Bool(false), nil, nil), nil)
+	wantCode("getblock unknown", err, btcjson.ErrRPCBlockNotFound)
+
+	result, err := handleGetBlockChainInfo(s, nil, nil)
+	if err != nil {
+		t.Fatalf("getblockchaininfo: unexpected error: %v", err)
+	}
+	info := result.(*btcjson.GetBlockChainInfoResult)
+	if !info.Pruned || info.PruneHeight != pruneHeight+1 {
+		t.Fatalf("getblockchaininfo: got pruned %v and prune height %d, "+
+			"want true and %d", info.Pruned, info.PruneHeight,
+			pruneHeight+1)
+	}
+}
+
+// TestPruneBlockChain ensures the pruneblockchain command prunes the blocks up
+// to a height or timestamp while keeping the most recent blocks and that the
+// pruned blocks are no longer returned by getblock.
+func TestPruneBlockChain(t *testing.T) {
+	t.Parallel()
+
+	// Enable pruning with a target which is never reached so blocks are
+	// only pruned on demand.
+	chain, db, _, teardown := newRegtestChain(t, 1<<40)
+	defer teardown()
+
+	const numBlocks = 400
+	for i := 0; i < numBlocks; i++ {
+		addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	}
+	if pruneHeight := chain.PruneHeight(); pruneHeight != -1 {
+		t.Fatalf("blocks were pruned automatically (prune height %d)",
+			pruneHeight)
+	}
+
+	wantCode := func(name string, err error, code btcjson.RPCErrorCode) {
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != code {
+			t.Fatalf("%s: got error %v, want code %d", name, err, code)
+		}
+	}
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: &chaincfg.RegressionNetParams,
+		DB:          db,
+	}}
+	prune := func(height int64) (int32, error) {
+		result, err := handlePruneBlockChain(s,
+			btcjson.NewPruneBlockChainCmd(height), nil)
+		if err != nil {
+			return 0, err
+		}
+		return result.(int32), nil
+	}
+
+	_, err := prune(-1)
+	wantCode("pruneblockchain negative", err, btcjson.ErrRPCInvalidParameter)
+	_, err = prune(numBlocks + 1)
+	wantCode("pruneblockchain beyond tip", err,
+		btcjson.ErrRPCInvalidParameter)
+	_, err = prune(time.Now().Add(time.Hour).Unix())
+	wantCode("pruneblockchain future timestamp", err,
+		btcjson.ErrRPCInvalidParameter)
+
+	// Ensure a timestamp is resolved to the first block created no more
+	// than two hours before it.  The test blocks are a minute apart, so
+	// only the blocks up to height 50 may be pruned.
+	genesisTime := chaincfg.RegressionNetParams.GenesisBlock.Header.Timestamp
+	timestamp := genesisTime.Add(50*time.Minute + 2*time.Hour).Unix()
+	pruneHeight, err := prune(timestamp)
+	if err != nil {
+		t.Fatalf("pruneblockchain: unexpected error: %v", err)
+	}
+	if pruneHeight < 1 || pruneHeight > 50 {
+		t.Fatalf("pruneblockchain: pruned to height %d, want between 1 "+
+			"and 50", pruneHeight)
+	}
+
+	// Ensure pruning to a lower height reports the existing prune height.
+	result, err := prune(int64(pruneHeight) / 2)
+	if err != nil || result != pruneHeight {
+		t.Fatalf("pruneblockchain: got height %d (err %v), want %d",
+			result, err, pruneHeight)
+	}
+
+	// Ensure the most recent blocks are kept when pruning to the tip.
+	prevPruneHeight := pruneHeight
+	pruneHeight, err = prune(numBlocks)
+	if err != nil {
+		t.Fatalf("pruneblockchain: unexpected error: %v", err)
+	}
+	if pruneHeight <= prevPruneHeight || pruneHeight > numBlocks-288 {
+		t.Fatalf("pruneblockchain: pruned to height %d, want between %d "+
+			"and %d", pruneHeight, prevPruneHeight+1, numBlocks-288)
+	}
+	if got := chain.PruneHeight(); got != pruneHeight {
+		t.Fatalf("pruneblockchain: returned height %d, chain reports %d",
+			pruneHeight, got)
+	}
+
+	// Ensure the pruned blocks are reported as such by getblock while the
+	// remaining blocks are still available.
+	for _, height := range []int32{0, pruneHeight / 2, pruneHeight} {
+		hash, err := chain.BlockHashByHeight(height)
+		if err != nil {
+			t.Fatalf("unable to get hash of block %d: %v", height, err)
+		}
+		_, err = handleGetBlock(s, btcjson.NewGetBlockCmd(hash.String(),
+			btcjson.Bool(false), nil, nil), nil)
+		wantCode("getblock pruned", err, btcjson.ErrRPCBlockPruned)
+	}
+	hash, err := chain.BlockHashByHeight(pruneHeight + 1)
+	if err != nil {
+		t.Fatalf("unable to get hash of block %d: %v", pruneHeight+1, err)
+	}
+	_, err = handleGetBlock(s, btcjson.NewGetBlockCmd(hash.String(),
+		btcjson.Bool(false), nil, nil), nil)
+	if err != nil {
+		t.Fatalf("getblock: unexpected error: %v", err)
+	}
+
+	// Ensure pruning is refused when it is not enabled.
+	unprunedChain, _, _, unprunedTeardown := newRegtestChain(t, 0)
+	defer unprunedTeardown()
+	s.cfg.Chain = unprunedChain
+	_, err = prune(0)
+	wantCode("pruneblockchain disabled", err, btcjson.ErrRPCMisc)
+}
+
+// TestTxOutProofRPC ensures a proof created by gettxoutproof for multiple
+// transactions in a block round-trips through verifytxoutproof.
+func TestTxOutProofRPC(t *testing.T) {
+	t.Parallel()
+
+	chain, db, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Create enough blocks for the first coinbase outputs to mature and
+	// then a block which spends them.
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	maturity := int(chaincfg.RegressionNetParams.CoinbaseMaturity)
+	for i := 0; i < maturity+3; i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+	var spends []*wire.MsgTx
+	for _, coinbase := range coinbases[:3] {
+		tx := wire.NewMsgTx(1)
+		coinbaseHash := coinbase.TxHash()
+		prevOut := wire.NewOutPoint(&coinbaseHash, 0)
+		tx.AddTxIn(wire.NewTxIn(prevOut, nil, nil))
+		tx.AddTxOut(wire.NewTxOut(coinbase.TxOut[0].Value, pkScript))
+		spends = append(spends, tx)
+	}
+	addRegtestBlock(t, chain, pkScript, spends...)
+	best := chain.BestSnapshot()
+
+	wantCode := func(name string, err error, code btcjson.RPCErrorCode) {
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != code {
+			t.Fatalf("%s: got error %v, want code %d", name, err, code)
+		}
+	}
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: &chaincfg.RegressionNetParams,
+		DB:          db,
+	}}
+	blockHash := btcjson.String(best.Hash.String())
+	txIDs := []string{spends[2].TxHash().String(),
+		spends[0].TxHash().String()}
+	result, err := handleGetTxOutProof(s,
+		btcjson.NewGetTxOutProofCmd(txIDs, blockHash), nil)
+	if err != nil {
+		t.Fatalf("gettxoutproof: unexpected error: %v", err)
+	}
+	proof := result.(string)
+
+	// The proven transactions are returned in block order.
+	result, err = handleVerifyTxOutProof(s,
+		btcjson.NewVerifyTxOutProofCmd(proof), nil)
+	if err != nil {
+		t.Fatalf("verifytxoutproof: unexpected error: %v", err)
+	}
+	want := []string{txIDs[1], txIDs[0]}
+	if got := result.([]string); !reflect.DeepEqual(got, want) {
+		t.Fatalf("verifytxoutproof: got %v, want %v", got, want)
+	}
+
+	// Ensure a proof for a block which is not in the main chain is
+	// rejected.
+	proofBytes, err := hex.DecodeString(proof)
+	if err != nil {
+		t.Fatalf("unable to decode proof: %v", err)
+	}
+	proofBytes[4] ^= 0xff
+	_, err = handleVerifyTxOutProof(s, btcjson.NewVerifyTxOutProofCmd(
+		hex.EncodeToString(proofBytes)), nil)
+	wantCode("verifytxoutproof unknown block", err,
+		btcjson.ErrRPCInvalidAddressOrKey)
+
+	// Ensure invalid requests are rejected.
+	_, err = handleGetTxOutProof(s, btcjson.NewGetTxOutProofCmd(
+		[]string{txIDs[0], txIDs[0]}, blockHash), nil)
+	wantCode("gettxoutproof duplicate", err, btcjson.ErrRPCInvalidParameter)
+	_, err = handleGetTxOutProof(s, btcjson.NewGetTxOutProofCmd(
+		[]string{coinbases[0].TxHash().String()}, blockHash), nil)
+	wantCode("gettxoutproof wrong block", err,
+		btcjson.ErrRPCInvalidAddressOrKey)
+	_, err = handleGetTxOutProof(s, btcjson.NewGetTxOutProofCmd(txIDs,
+		nil), nil)
+	wantCode("gettxoutproof no txindex", err, btcjson.ErrRPCNoTxInfo)
+}
+
+// TestGetTxOutSetInfo ensures the statistics about the utxo set returned by the
+// gettxoutsetinfo command match a known utxo set.
+func TestGetTxOutSetInfo(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Create enough blocks for the first coinbase to mature and then a
+	// block which splits it into two outputs.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	var totalAmount int64
+	for i := 0; i <= int(params.CoinbaseMaturity); i++ {
+		coinbase := addRegtestBlock(t, chain, pkScript)
+		coinbases = append(coinbases, coinbase)
+		totalAmount += coinbase.TxOut[0].Value
+	}
+	coinbaseHash := coinbases[0].TxHash()
+	value := coinbases[0].TxOut[0].Value
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(value/2, pkScript))
+	tx.AddTxOut(wire.NewTxOut(value-value/2, pkScript))
+	coinbase := addRegtestBlock(t, chain, pkScript, tx)
+	totalAmount += coinbase.TxOut[0].Value
+	best := chain.BestSnapshot()
+
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain}}
+	getTxOutSetInfo := func(hashType *string) *btcjson.GetTxOutSetInfoResult {
+		result, err := handleGetTxOutSetInfo(s,
+			btcjson.NewGetTxOutSetInfoCmd(hashType), nil)
+		if err != nil {
+			t.Fatalf("gettxoutsetinfo: unexpected error: %v", err)
+		}
+		return result.(*btcjson.GetTxOutSetInfoResult)
+	}
+
+	// The first coinbase was spent and all of the other coinbases along
+	// with both outputs of the transaction which spent it are unspent.
+	result := getTxOutSetInfo(btcjson.String(btcjson.TxOutSetHashTypeNone))
+	want := btcjson.GetTxOutSetInfoResult{
+		Height:             int64(best.Height),
+		BestBlock:          best.Hash.String(),
+		Transactions:       int64(len(coinbases)) + 1,
+		TxOuts:             int64(len(coinbases)) + 2,
+		TotalAmount:        ltcutil.Amount(totalAmount).ToBTC(),
+		TotalAmountLitoshi: totalAmount,
+	}
+	if *result != want {
+		t.Fatalf("gettxoutsetinfo: got %+v, want %+v", *result, want)
+	}
+
+	// Ensure the hash of the set is only calculated when requested, does
+	// not otherwise affect the result, and commits to the set.
+	result = getTxOutSetInfo(nil)
+	if len(result.HashSerialized) != chainhash.MaxHashStringSize {
+		t.Fatalf("gettxoutsetinfo: unexpected hash %q",
+			result.HashSerialized)
+	}
+	utxoSetHash := result.HashSerialized
+	result.HashSerialized = ""
+	if *result != want {
+		t.Fatalf("gettxoutsetinfo: got %+v, want %+v", *result, want)
+	}
+	if result = getTxOutSetInfo(nil); result.HashSerialized != utxoSetHash {
+		t.Fatalf("gettxoutsetinfo: got hash %v, want %v",
+			result.HashSerialized, utxoSetHash)
+	}
+	addRegtestBlock(t, chain, pkScript)
+	if result = getTxOutSetInfo(nil); result.HashSerialized == utxoSetHash {
+		t.Fatal("gettxoutsetinfo: hash did not change with the set")
+	}
+
+	_, err := handleGetTxOutSetInfo(s, btcjson.NewGetTxOutSetInfoCmd(
+		btcjson.String("muhash")), nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+
+		t.Fatalf("gettxoutsetinfo: unexpected error for unknown hash "+
+			"type: %v", err)
+	}
+}
+
+// TestDumpLoadTxOutSet ensures the utxo set dumped via dumptxoutset is loaded
+// into a fresh chain via loadtxoutset with the same contents, and that corrupt
+// snapshots are rejected without changing the utxo set.
+func TestDumpLoadTxOutSet(t *testing.T) {
+	t.Parallel()
+
+	// Failed dumps and loads are logged, so disable the logging of the RPC
+	// server since the log rotator is not initialized by the tests.
+	setLogLevel("RPCS", "off")
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Create enough blocks for the first coinbase to mature and then a
+	// block which splits it into two outputs to a different script.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var firstCoinbase *wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity); i++ {
+		coinbase := addRegtestBlock(t, chain, pkScript)
+		if firstCoinbase == nil {
+			firstCoinbase = coinbase
+		}
+	}
+	coinbaseHash := firstCoinbase.TxHash()
+	value := firstCoinbase.TxOut[0].Value
+	splitScript := []byte{txscript.OP_TRUE, txscript.OP_TRUE}
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0), nil, nil))
+	tx.AddTxOut(wire.NewTxOut(value/2, splitScript))
+	tx.AddTxOut(wire.NewTxOut(value-value/2, splitScript))
+	addRegtestBlock(t, chain, pkScript, tx)
+	splitHash := tx.TxHash()
+	best := chain.BestSnapshot()
+
+	dir, err := ioutil.TempDir("", "dumptxoutset")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "utxo.dat")
+
+	// Dump the utxo set and ensure the snapshot is as of the best block.
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain}}
+	result, err := handleDumpTxOutSet(s, btcjson.NewDumpTxOutSetCmd(path),
+		nil)
+	if err != nil {
+		t.Fatalf("dumptxoutset: unexpected error: %v", err)
+	}
+	dumpResult := result.(*btcjson.DumpTxOutSetResult)
+	// Every coinbase but the spent one remains along with both outputs of
+	// the split.
+	wantCoins := uint64(best.Height) - 1 + 2
+	if dumpResult.CoinsWritten != wantCoins ||
+		dumpResult.BaseHash != best.Hash.String() ||
+		dumpResult.BaseHeight != best.Height || dumpResult.Path != path {
+
+		t.Fatalf("dumptxoutset: got %+v, want %d coins as of block %v "+
+			"(height %d)", dumpResult, wantCoins, best.Hash,
+			best.Height)
+	}
+
+	// An existing file must not be overwritten.
+	_, err = handleDumpTxOutSet(s, btcjson.NewDumpTxOutSetCmd(path), nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+
+		t.Fatalf("got error %v for existing file, want code %d", err,
+			btcjson.ErrRPCInvalidParameter)
+	}
+
+	getTxOutSetInfo := func(s *rpcServer) *btcjson.GetTxOutSetInfoResult {
+		result, err := handleGetTxOutSetInfo(s,
+			btcjson.NewGetTxOutSetInfoCmd(nil), nil)
+		if err != nil {
+			t.Fatalf("gettxoutsetinfo: unexpected error: %v", err)
+		}
+		return result.(*btcjson.GetTxOutSetInfoResult)
+	}
+	wantInfo := getTxOutSetInfo(s)
+
+	// A snapshot whose contents do not match its commitment must be
+	// rejected without loading any of it.
+	snapshot, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read snapshot: %v", err)
+	}
+	corrupt := append([]byte(nil), snapshot...)
+	corrupt[len(corrupt)/2] ^= 0x01
+	corruptPath := filepath.Join(dir, "corrupt.dat")
+	if err := ioutil.WriteFile(corruptPath, corrupt, 0600); err != nil {
+		t.Fatalf("unable to write corrupt snapshot: %v", err)
+	}
+	// Snapshots can't be loaded into a chain with optional indexes.
+	indexedChain, _, _, indexedTeardown := newRegtestChain(t, 0)
+	defer indexedTeardown()
+	indexed := &rpcServer{cfg: rpcserverConfig{Chain: indexedChain}}
+	_, err = handleLoadTxOutSet(indexed, btcjson.NewLoadTxOutSetCmd(path),
+		nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCMisc {
+
+		t.Fatalf("got error %v for chain with indexes, want code %d",
+			err, btcjson.ErrRPCMisc)
+	}
+
+	loadedChain, loadedTeardown := newRegtestChainWithoutIndexes(t)
+	defer loadedTeardown()
+	loaded := &rpcServer{cfg: rpcserverConfig{Chain: loadedChain}}
+	_, err = handleLoadTxOutSet(loaded,
+		btcjson.NewLoadTxOutSetCmd(corruptPath), nil)
+	if rpcErr, ok := err.(*btcjson.RPCError); !ok ||
+		rpcErr.Code != btcjson.ErrRPCMisc {
+
+		t.Fatalf("got error %v for corrupt snapshot, want code %d", err,
+			btcjson.ErrRPCMisc)
+	}
+	if info := getTxOutSetInfo(loaded); info.TxOuts != 0 {
+		t.Fatalf("got %d txouts after rejected snapshot, want 0",
+			info.TxOuts)
+	}
+
+	// Load the snapshot into the fresh chain and ensure the loaded utxo
+	// set has the same balances and commitment as the dumped one.
+	result, err = handleLoadTxOutSet(loaded,
+		btcjson.NewLoadTxOutSetCmd(path), nil)
+	if err != nil {
+		t.Fatalf("loadtxoutset: unexpected error: %v", err)
+	}
+	wantLoad := btcjson.LoadTxOutSetResult{
+		CoinsLoaded: wantCoins,
+		TipHash:     best.Hash.String(),
+		BaseHeight:  best.Height,
+		Path:        path,
+	}
+	if loadResult := result.(*btcjson.LoadTxOutSetResult); *loadResult != wantLoad {
+		t.Fatalf("loadtxoutset: got %+v, want %+v", *loadResult,
+			wantLoad)
+	}
+	info := getTxOutSetInfo(loaded)
+	if info.Transactions != wantInfo.Transactions ||
+		info.TxOuts != wantInfo.TxOuts ||
+		info.TotalAmountLitoshi != wantInfo.TotalAmountLitoshi ||
+		info.HashSerialized != wantInfo.HashSerialized {
+
+		t.Fatalf("gettxoutsetinfo after load: got %+v, want %+v", info,
+			wantInfo)
+	}
+	for i, txOut := range tx.TxOut {
+		entry, err := loadedChain.FetchUtxoEntry(&splitHash)
+		if err != nil || entry == nil {
+			t.Fatalf("FetchUtxoEntry: got entry %v (err %v)", entry,
+				err)
+		}
+		index := uint32(i)
+		if entry.AmountByIndex(index) != txOut.Value ||
+			!bytes.Equal(entry.PkScriptByIndex(index), splitScript) ||
+			entry.BlockHeight() != best.Height || entry.IsCoinBase() {
+
+			t.Fatalf("loaded output %d does not match %+v", i, txOut)
+		}
+	}
+
+	// The loaded snapshot is pending validation, so neither a second
+	// snapshot may be loaded nor the utxo set dumped.
+	if loadedChain.PendingUtxoSnapshot() == nil {
+		t.Fatal("no pending utxo snapshot after load")
+	}
+	_, err = handleLoadTxOutSet(loaded, btcjson.NewLoadTxOutSetCmd(path),
+		nil)
+	if err == nil {
+		t.Fatal("loadtxoutset: unexpected success for second snapshot")
+	}
+	_, err = handleDumpTxOutSet(loaded, btcjson.NewDumpTxOutSetCmd(
+		filepath.Join(dir, "pending.dat")), nil)
+	if err == nil {
+		t.Fatal("dumptxoutset: unexpected success for pending snapshot")
+	}
+
+	// Process the blocks of the dumped chain with the loaded snapshot.
+	// The base block validates the snapshot and becomes the best block,
+	// after which a block spending outputs from the snapshot connects.
+	for height := int32(1); height <= best.Height; height++ {
+		block, err := chain.BlockByHeight(height)
+		if err != nil {
+			t.Fatalf("BlockByHeight: %v", err)
+		}
+		isMainChain, _, err := loadedChain.ProcessBlock(block,
+			blockchain.BFNone)
+		if err != nil {
+			t.Fatalf("unable to process block %d with pending "+
+				"snapshot: %v", height, err)
+		}
+		if isMainChain != (height == best.Height) {
+			t.Fatalf("block %d: got main chain %v", height,
+				isMainChain)
+		}
+	}
+	if loadedChain.PendingUtxoSnapshot() != nil {
+		t.Fatal("utxo snapshot still pending after its base block")
+	}
+	if got := loadedChain.BestSnapshot(); got.Hash != best.Hash ||
+		got.TotalTxns != best.TotalTxns {
+
+		t.Fatalf("best block %v with %d txns, want %v with %d txns",
+			got.Hash, got.TotalTxns, best.Hash, best.TotalTxns)
+	}
+	spend := wire.NewMsgTx(1)
+	spend.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&splitHash, 0), nil, nil))
+	spend.AddTxOut(wire.NewTxOut(tx.TxOut[0].Value, pkScript))
+	addRegtestBlock(t, loadedChain, pkScript, spend)
+	entry, err := loadedChain.FetchUtxoEntry(&splitHash)
+	if err != nil || entry == nil || !entry.IsOutputSpent(0) ||
+		entry.IsOutputSpent(1) {
+
+		t.Fatalf("split outputs not updated by connected block: entry "+
+			"%v (err %v)", entry, err)
+	}
+}
+
+// TestSearchRawTransactionsOrder ensures searchrawtransactions returns the
+// transactions involving an address in a stable order, with the unconfirmed
+// transactions at the newest end, and that the number to skip and count select
+// the correct window of them in both chronological and reverse order.
+func TestSearchRawTransactionsOrder(t *testing.T) {
+	t.Parallel()
+
+	// The address index relies on the transaction index.
+	var addrIndex *indexers.AddrIndex
+	chain, db, _, teardown := newRegtestChain(t, 0,
+		func(db database.DB, params *chaincfg.Params) indexers.Indexer {
+			return indexers.NewTxIndex(db)
+		},
+		func(db database.DB, params *chaincfg.Params) indexers.Indexer {
+			addrIndex = indexers.NewAddrIndex(db, params)
+			return addrIndex
+		})
+	defer teardown()
+
+	// Mine blocks paying to the address and then add unconfirmed
+	// transactions spending from it to the unconfirmed address index.
+	params := &chaincfg.RegressionNetParams
+	addr, err := ltcutil.NewAddressPubKeyHash(bytes.Repeat([]byte{0x01},
+		20), params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	pkScript, err := txscript.PayToAddrScript(addr)
+	if err != nil {
+		t.Fatalf("unable to create script: %v", err)
+	}
+	const numConfirmed = 12
+	const numUnconfirmed = 3
+	var allHex []string
+	var coinbases []*wire.MsgTx
+	for i := 0; i < numConfirmed; i++ {
+		coinbase := addRegtestBlock(t, chain, pkScript)
+		coinbases = append(coinbases, coinbase)
+		txHex, err := messageToHex(coinbase)
+		if err != nil {
+			t.Fatalf("unable to serialize tx: %v", err)
+		}
+		allHex = append(allHex, txHex)
+	}
+	view := blockchain.NewUtxoViewpoint()
+	for _, coinbase := range coinbases[:numUnconfirmed] {
+		view.AddTxOuts(ltcutil.NewTx(coinbase), 1)
+	}
+	for _, coinbase := range coinbases[:numUnconfirmed] {
+		coinbaseHash := coinbase.TxHash()
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0),
+			nil, nil))
+		tx.AddTxOut(wire.NewTxOut(coinbase.TxOut[0].Value,
+			[]byte{txscript.OP_TRUE}))
+		addrIndex.AddUnconfirmedTx(ltcutil.NewTx(tx), view)
+		txHex, err := messageToHex(tx)
+		if err != nil {
+			t.Fatalf("unable to serialize tx: %v", err)
+		}
+		allHex = append(allHex, txHex)
+	}
+	reverseHex := make([]string, 0, len(allHex))
+	for i := len(allHex) - 1; i >= 0; i-- {
+		reverseHex = append(reverseHex, allHex[i])
+	}
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		DB:          db,
+		AddrIndex:   addrIndex,
+	}}
+	tests := []struct {
+		skip  int
+		count int
+	}{
+		{skip: 0, count: 100},
+		{skip: 0, count: 2},
+		{skip: 2, count: 3},
+		{skip: 5, count: 4},
+		{skip: numConfirmed - 1, count: 2},
+		{skip: numConfirmed + 1, count: 5},
+		{skip: numConfirmed + numUnconfirmed - 1, count: 1},
+	}
+	for _, reverse := range []bool{false, true} {
+		want := allHex
+		if reverse {
+			want = reverseHex
+		}
+		for _, test := range tests {
+			end := test.skip + test.count
+			if end > len(want) {
+				end = len(want)
+			}
+
+			// Query each window twice to ensure the order is
+			// stable.
+			for i := 0; i < 2; i++ {
+				cmd := btcjson.NewSearchRawTransactionsCmd(
+					addr.EncodeAddress(), btcjson.Int(0),
+					btcjson.Int(test.skip),
+					btcjson.Int(test.count), nil,
+					btcjson.Bool(reverse), nil, nil)
+				result, err := handleSearchRawTransactions(s,
+					cmd, nil)
+				if err != nil {
+					t.Fatalf("skip %d count %d reverse %v: "+
+						"unexpected error: %v", test.skip,
+						test.count, reverse, err)
+				}
+				got := result.([]string)
+				if !reflect.DeepEqual(got, want[test.skip:end]) {
+					t.Fatalf("skip %d count %d reverse %v: "+
+						"got %v, want %v", test.skip,
+						test.count, reverse, got,
+						want[test.skip:end])
+				}
+			}
+		}
+	}
+}
+
+// TestValidateAddress ensures validateaddress reports the details of each type
+// of address for the active network and rejects addresses for other networks.
+func TestValidateAddress(t *testing.T) {
+	t.Parallel()
+
+	mainNet := &chaincfg.MainNetParams
+	testNet := &chaincfg.TestNet4Params
+	hash20 := bytes.Repeat([]byte{0x01}, 20)
+	hash32 := bytes.Repeat([]byte{0x02}, 32)
+	hex20 := hex.EncodeToString(hash20)
+	hex32 := hex.EncodeToString(hash32)
+
+	// encode returns the encoding of the passed address after ensuring it
+	// was created without error.
+	encode := func(addr ltcutil.Address, err error) string {
+		if err != nil {
+			t.Fatalf("unable to create address: %v", err)
+		}
+		return addr.EncodeAddress()
+	}
+	tests := []struct {
+		name    string
+		address string
+		want    btcjson.ValidateAddressChainResult
+	}{{
+		name: "pubkeyhash",
+		address: encode(ltcutil.NewAddressPubKeyHash(hash20,
+			mainNet)),
+		want: btcjson.ValidateAddressChainResult{
+			ScriptPubKey: "76a914" + hex20 + "88ac",
+			Type:         "pubkeyhash",
+			IsWitness:    btcjson.Bool(false),
+		},
+	}, {
+		name: "scripthash",
+		address: encode(ltcutil.NewAddressScriptHashFromHash(hash20,
+			mainNet)),
+		want: btcjson.ValidateAddressChainResult{
+			ScriptPubKey: "a914" + hex20 + "87",
+			Type:         "scripthash",
+			IsWitness:    btcjson.Bool(false),
+		},
+	}, {
+		name: "witness_v0_keyhash",
+		address: encode(ltcutil.NewAddressWitnessPubKeyHash(hash20,
+			mainNet)),
+		want: btcjson.ValidateAddressChainResult{
+			ScriptPubKey:   "0014" + hex20,
+			Type:           "witness_v0_keyhash",
+			IsWitness:      btcjson.Bool(true),
+			WitnessVersion: btcjson.Int32(0),
+			WitnessProgram: hex20,
+		},
+	}, {
+		name: "witness_v0_scripthash",
+		address: encode(ltcutil.NewAddressWitnessScriptHash(hash32,
+			mainNet)),
+		want: btcjson.ValidateAddressChainResult{
+			ScriptPubKey:   "0020" + hex32,
+			Type:           "witness_v0_scripthash",
+			IsWitness:      btcjson.Bool(true),
+			WitnessVersion: btcjson.Int32(0),
+			WitnessProgram: hex32,
+		},
+	}, {
+		name: "testnet pubkeyhash",
+		address: encode(ltcutil.NewAddressPubKeyHash(hash20,
+			testNet)),
+	}, {
+		name: "testnet scripthash",
+		address: encode(ltcutil.NewAddressScriptHashFromHash(hash20,
+			testNet)),
+	}, {
+		name: "testnet witness_v0_keyhash",
+		address: encode(ltcutil.NewAddressWitnessPubKeyHash(hash20,
+			testNet)),
+	}, {
+		name: "testnet witness_v0_scripthash",
+		address: encode(ltcutil.NewAddressWitnessScriptHash(hash32,
+			testNet)),
+	}, {
+		name:    "garbage",
+		address: "notanaddress",
+	}}
+
+	s := &rpcServer{cfg: rpcserverConfig{ChainParams: mainNet}}
+	for _, test := range tests {
+		// The address is only reported for valid addresses.
+		want := test.want
+		if want.Type != "" {
+			want.IsValid = true
+			want.Address = test.address
+		}
+
+		result, err := handleValidateAddress(s,
+			btcjson.NewValidateAddressCmd(test.address), nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		got := result.(btcjson.ValidateAddressChainResult)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("%s: got %+v, want %+v", test.name, got, want)
+		}
+	}
+}
+
+// TestDeriveAddresses ensures the deriveaddresses RPC derives the expected
+// addresses for the requested ranges and rejects invalid descriptors and
+// ranges.
+func TestDeriveAddresses(t *testing.T) {
+	t.Parallel()
+
+	const (
+		tpub = "tpubD6NzVbkrYhZ4XgiXtGrdW5XDAPFCL9h7we1vwNCpn8tGbBcgfVYjXyhWo4E1xkh56hjod1RhGjxbaTLV3X4FyWuejifB9jusQ46QzG87VKp"
+		desc = "wpkh(" + tpub + "/0/*)#qvnrzae8"
+	)
+	addrs := []string{
+		"tltc1qp5wfcq48h6d63wyy9qz0awtpfqwwv4smwfrkuc",
+		"tltc1qrfxr69jqnhwufxgkqgcdep9prq4j4vuweqa9yk",
+		"tltc1qhvd6suvqzjcu9pxjhrwhtrlj85ny3n2mnwxe90",
+		"tltc1qjzgwzugce3mqfvn2cdq8wt8drz50mf6j2t9xn9",
+		"tltc1q4lrflkd0sddm4ktujw8e5syxmlwcdprdcv4ssm",
+	}
+	checksum, err := descriptor.Checksum("addr(" + addrs[0] + ")")
+	if err != nil {
+		t.Fatalf("unable to calculate checksum: %v", err)
+	}
+	addrDesc := "addr(" + addrs[0] + ")#" + checksum
+
+	tests := []struct {
+		name      string
+		desc      string
+		descRange *btcjson.DescriptorRange
+		want      []string
+		wantCode  btcjson.RPCErrorCode
+	}{{
+		name:      "first five",
+		desc:      desc,
+		descRange: &btcjson.DescriptorRange{End: 4},
+		want:      addrs,
+	}, {
+		name:      "begin and end",
+		desc:      desc,
+		descRange: &btcjson.DescriptorRange{Begin: 3, End: 4},
+		want:      addrs[3:],
+	}, {
+		name: "un-ranged",
+		desc: addrDesc,
+		want: addrs[:1],
+	}, {
+		name:     "missing range",
+		desc:     desc,
+		wantCode: btcjson.ErrRPCInvalidParameter,
+	}, {
+		name:      "range for un-ranged",
+		desc:      addrDesc,
+		descRange: &btcjson.DescriptorRange{End: 4},
+		wantCode:  btcjson.ErrRPCInvalidParameter,
+	}, {
+		name:      "begin after end",
+		desc:      desc,
+		descRange: &btcjson.DescriptorRange{Begin: 5, End: 4},
+		wantCode:  btcjson.ErrRPCInvalidParameter,
+	}, {
+		name:      "hardened end",
+		desc:      desc,
+		descRange: &btcjson.DescriptorRange{End: 1 << 31},
+		wantCode:  btcjson.ErrRPCInvalidParameter,
+	}, {
+		name:      "missing checksum",
+		desc:      desc[:len(desc)-9],
+		descRange: &btcjson.DescriptorRange{End: 4},
+		wantCode:  btcjson.ErrRPCInvalidAddressOrKey,
+	}, {
+		name:      "invalid checksum",
+		desc:      desc[:len(desc)-1] + "9",
+		descRange: &btcjson.DescriptorRange{End: 4},
+		wantCode:  btcjson.ErrRPCInvalidAddressOrKey,
+	}}
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		ChainParams: &chaincfg.TestNet4Params,
+	}}
+	for _, test := range tests {
+		result, err := handleDeriveAddresses(s,
+			btcjson.NewDeriveAddressesCmd(test.desc, test.descRange),
+			nil)
+		if test.wantCode != 0 {
+			rpcErr, ok := err.(*btcjson.RPCError)
+			if !ok || rpcErr.Code != test.wantCode {
+				t.Fatalf("%s: got error %v, want code %d",
+					test.name, err, test.wantCode)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(result, test.want) {
+			t.Fatalf("%s: got %v, want %v", test.name, result,
+				test.want)
+		}
+	}
+}
+
+// TestGetDescriptorInfo ensures the getdescriptorinfo RPC returns the canonical
+// form of descriptors with their checksums and rejects invalid descriptors.
+func TestGetDescriptorInfo(t *testing.T) {
+	t.Parallel()
+
+	const xpub = "xpub6DJ2dNUysrn5Vt36jH2KLBT2i1auw1tTSSomg8PhqNiUtx8QX2SvC9nrHu81fT41fvDUnhMjEzQgXnQjKEu3oaqMSzhSrHMxyyoEAmUHQbY"
+	tests := []struct {
+		name    string
+		desc    string
+		want    *btcjson.GetDescriptorInfoResult
+		wantErr string
+	}{{
+		name: "without checksum",
+		desc: "wpkh([d34db33f/84h/0h/0h]" + xpub + "/0/*)",
+		want: &btcjson.GetDescriptorInfoResult{
+			Descriptor: "wpkh([d34db33f/84'/0'/0']" + xpub +
+				"/0/*)#trd0mf0l",
+			Checksum:   "trd0mf0l",
+			IsRange:    true,
+			IsSolvable: true,
+		},
+	}, {
+		name: "with checksum",
+		desc: "wpkh([d34db33f/84h/0h/0h]" + xpub + "/0/*)#cjjspncu",
+		want: &btcjson.GetDescriptorInfoResult{
+			Descriptor: "wpkh([d34db33f/84'/0'/0']" + xpub +
+				"/0/*)#trd0mf0l",
+			Checksum:   "trd0mf0l",
+			IsRange:    true,
+			IsSolvable: true,
+		},
+	}, {
+		name: "address",
+		desc: "addr(LKDxGDJq5fF4FohAB8zJH24mDDNHDNtqsE)",
+		want: &btcjson.GetDescriptorInfoResult{
+			Descriptor: "addr(LKDxGDJq5fF4FohAB8zJH24mDDNHDNtqsE)#255uu22h",
+			Checksum:   "255uu22h",
+		},
+	}, {
+		name:    "invalid checksum",
+		desc:    "wpkh([d34db33f/84h/0h/0h]" + xpub + "/0/*)#cjjspncv",
+		wantErr: "checksum is invalid",
+	}, {
+		name:    "unbalanced parentheses",
+		desc:    "sh(wpkh(" + xpub + ")",
+		wantErr: "unbalanced parentheses",
+	}, {
+		name:    "unknown function",
+		desc:    "pkhash(" + xpub + ")",
+		wantErr: `unknown descriptor function "pkhash"`,
+	}}
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		ChainParams: &chaincfg.MainNetParams,
+	}}
+	for _, test := range tests {
+		result, err := handleGetDescriptorInfo(s,
+			btcjson.NewGetDescriptorInfoCmd(test.desc), nil)
+		if test.wantErr != "" {
+			rpcErr, ok := err.(*btcjson.RPCError)
+			if !ok || rpcErr.Code != btcjson.ErrRPCInvalidAddressOrKey ||
+				!strings.Contains(rpcErr.Message, test.wantErr) {
+
+				t.Fatalf("%s: got error %v, want %q", test.name,
+					err, test.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if !reflect.DeepEqual(result, test.want) {
+			t.Fatalf("%s: got %+v, want %+v", test.name, result,
+				test.want)
+		}
+	}
+}
+
+// TestGetNodeAddresses ensures the getnodeaddresses command returns a subset
+// of the addresses known to be good by the address manager with their details.
+func TestGetNodeAddresses(t *testing.T) {
+	t.Parallel()
+
+	dataDir, err := ioutil.TempDir("", "getnodeaddresses")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	// Seed the address manager with addresses which are all marked good.
+	amgr := addrmgr.New(dataDir, nil)
+	srcAddr := wire.NewNetAddressIPPort(net.IPv4(173, 144, 173, 111), 9333, 0)
+	want := make(map[string]btcjson.GetNodeAddressesResult)
+	for i := 0; i < 100; i++ {
+		ip := net.IPv4(byte(i/64+60), 173, 147, byte(i%64+60))
+		na := wire.NewNetAddressIPPort(ip, uint16(9333+i),
+			wire.SFNodeNetwork|wire.SFNodeWitness)
+		amgr.AddAddress(na, srcAddr)
+		amgr.Good(na)
+		want[ip.String()] = btcjson.GetNodeAddressesResult{
+			Time:     na.Timestamp.Unix(),
+			Services: uint64(na.Services),
+			Address:  ip.String(),
+			Port:     na.Port,
+		}
+	}
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		ConnMgr: &rpcConnManager{server: &server{addrManager: amgr}},
+	}}
+	tests := []struct {
+		count   *int
+		wantLen int
+	}{
+		{count: nil, wantLen: 1},
+		{count: btcjson.Int(5), wantLen: 5},
+
+		// Only a fraction of the known addresses are ever returned.
+		{count: btcjson.Int(2500), wantLen: 23},
+	}
+	for _, test := range tests {
+		result, err := handleGetNodeAddresses(s,
+			btcjson.NewGetNodeAddressesCmd(test.count), nil)
+		if err != nil {
+			t.Fatalf("getnodeaddresses: unexpected error: %v", err)
+		}
+		addrs := result.([]btcjson.GetNodeAddressesResult)
+		if len(addrs) != test.wantLen {
+			t.Fatalf("getnodeaddresses: got %d addresses, want %d",
+				len(addrs), test.wantLen)
+		}
+		for _, addr := range addrs {
+			if addr != want[addr.Address] {
+				t.Fatalf("getnodeaddresses: got %+v, want %+v",
+					addr, want[addr.Address])
+			}
+		}
+	}
+
+	// Ensure an invalid count is rejected.
+	_, err = handleGetNodeAddresses(s,
+		btcjson.NewGetNodeAddressesCmd(btcjson.Int(0)), nil)
+	rpcErr, ok := err.(*btcjson.RPCError)
+	if !ok || rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+		t.Fatalf("getnodeaddresses: got error %v, want code %d", err,
+			btcjson.ErrRPCInvalidParameter)
+	}
+}
+
+// TestGetAddrManInfo ensures the getaddrmaninfo command reports the number of
+// addresses in the new and tried tables for each network.
+func TestGetAddrManInfo(t *testing.T) {
+	t.Parallel()
+
+	dataDir, err := ioutil.TempDir("", "getaddrmaninfo")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	amgr := addrmgr.New(dataDir, nil)
+	s := &rpcServer{cfg: rpcserverConfig{
+		ConnMgr: &rpcConnManager{server: &server{addrManager: amgr}},
+	}}
+
+	// Seed the address manager with IPv4 and IPv6 addresses, some of which
+	// are marked good.  No onion addresses are known.
+	srcAddr := wire.NewNetAddressIPPort(net.IPv4(173, 144, 173, 111), 9333, 0)
+	for i := 0; i < 5; i++ {
+		na := wire.NewNetAddressIPPort(net.IPv4(60, 173, 147, byte(i+1)),
+			9333, wire.SFNodeNetwork)
+		amgr.AddAddress(na, srcAddr)
+		if i < 2 {
+			amgr.Good(na)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		ip := net.ParseIP("2001:470::")
+		ip[15] = byte(i + 1)
+		na := wire.NewNetAddressIPPort(ip, 9333, wire.SFNodeNetwork)
+		amgr.AddAddress(na, srcAddr)
+		if i < 1 {
+			amgr.Good(na)
+		}
+	}
+
+	result, err := handleGetAddrManInfo(s, btcjson.NewGetAddrManInfoCmd(), nil)
+	if err != nil {
+		t.Fatalf("getaddrmaninfo: unexpected error: %v", err)
+	}
+	want := map[string]btcjson.GetAddrManInfoResult{
+		"ipv4":         {New: 3, Tried: 2, Total: 5},
+		"ipv6":         {New: 2, Tried: 1, Total: 3},
+		"onion":        {New: 0, Tried: 0, Total: 0},
+		"cjdns":        {New: 0, Tried: 0, Total: 0},
+		"i2p":          {New: 0, Tried: 0, Total: 0},
+		"all_networks": {New: 5, Tried: 3, Total: 8},
+	}
+	got := result.(map[string]btcjson.GetAddrManInfoResult)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("getaddrmaninfo: got %v, want %v", got, want)
+	}
+}
+
+// TestAddPeerAddress ensures the addpeeraddress command adds addresses to the
+// address manager, that tried addresses are returned by getnodeaddresses, and
+// that invalid addresses are rejected.
+func TestAddPeerAddress(t *testing.T) {
+	t.Parallel()
+
+	dataDir, err := ioutil.TempDir("", "addpeeraddress")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	amgr := addrmgr.New(dataDir, nil)
+	s := &rpcServer{cfg: rpcserverConfig{
+		ConnMgr: &rpcConnManager{server: &server{addrManager: amgr}},
+	}}
+
+	// Add enough tried addresses for getnodeaddresses to return some of
+	// them along with an untried address which must never be returned.
+	tried := make(map[string]uint16)
+	for i := 0; i < 10; i++ {
+		ip := net.IPv4(byte(i/4+60), 173, 147, byte(i%4+60)).String()
+		port := uint16(9333 + i)
+		result, err := handleAddPeerAddress(s,
+			btcjson.NewAddPeerAddressCmd(ip, port, btcjson.Bool(true)),
+			nil)
+		if err != nil {
+			t.Fatalf("addpeeraddress: unexpected error: %v", err)
+		}
+		if !result.(*btcjson.AddPeerAddressResult).Success {
+			t.Fatalf("addpeeraddress: %s was not added", ip)
+		}
+		tried[ip] = port
+	}
+	result, err := handleAddPeerAddress(s,
+		btcjson.NewAddPeerAddressCmd("70.173.147.60", 9333, nil), nil)
+	if err != nil {
+		t.Fatalf("addpeeraddress: unexpected error: %v", err)
+	}
+	if !result.(*btcjson.AddPeerAddressResult).Success {
+		t.Fatal("addpeeraddress: untried address was not added")
+	}
+	if amgr.NumAddresses() != 11 {
+		t.Fatalf("got %d known addresses, want 11", amgr.NumAddresses())
+	}
+
+	result, err = handleGetNodeAddresses(s,
+		btcjson.NewGetNodeAddressesCmd(btcjson.Int(2500)), nil)
+	if err != nil {
+		t.Fatalf("getnodeaddresses: unexpected error: %v", err)
+	}
+	addrs := result.([]btcjson.GetNodeAddressesResult)
+	if len(addrs) == 0 {
+		t.Fatal("getnodeaddresses: no addresses returned")
+	}
+	for _, addr := range addrs {
+		if port, ok := tried[addr.Address]; !ok || addr.Port != port {
+			t.Fatalf("getnodeaddresses: unexpected address %+v",
+				addr)
+		}
+	}
+
+	// Ensure malformed and unroutable addresses are rejected.
+	tests := []struct {
+		address string
+		port    uint16
+	}{
+		{address: "not an address", port: 9333},
+		{address: "1.2.3.4:9333", port: 9333},
+		{address: "1.2.3.4", port: 0},
+		{address: "127.0.0.1", port: 9333},
+		{address: "10.0.0.1", port: 9333},
+		{address: "0.0.0.0", port: 9333},
+	}
+	for _, test := range tests {
+		_, err := handleAddPeerAddress(s, btcjson.NewAddPeerAddressCmd(
+			test.address, test.port, nil), nil)
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != btcjson.ErrRPCInvalidParameter {
+			t.Fatalf("addpeeraddress %s:%d: got error %v, want "+
+				"code %d", test.address, test.port, err,
+				btcjson.ErrRPCInvalidParameter)
+		}
+	}
+	if amgr.NumAddresses() != 11 {
+		t.Fatalf("got %d known addresses after rejected additions, "+
+			"want 11", amgr.NumAddresses())
+	}
+}
+
+// TestSendRawTransactionLimits ensures sendrawtransaction rejects transactions
+// which pay a fee rate over the max fee rate or burn more than the max burn
+// amount before they reach the memory pool.
+func TestSendRawTransactionLimits(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Create enough blocks for the first coinbase to mature.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity); i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+
+	txMemPool := newRegtestMempool(chain)
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		TxMemPool:   txMemPool,
+	}}
+
+	burnScript, err := txscript.NewScriptBuilder().
+		AddOp(txscript.OP_RETURN).Script()
+	if err != nil {
+		t.Fatalf("unable to create burn script: %v", err)
+	}
+
+	// spend returns a transaction spending the first output of the passed
+	// transaction with the passed fee which burns the passed amount along
+	// with its serialized hex.
+	spend := func(prevTx *wire.MsgTx, fee, burn int64) (*wire.MsgTx, string) {
+		prevHash := prevTx.TxHash()
+		tx := wire.NewMsgTx(1)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0), nil,
+			nil))
+		tx.AddTxOut(wire.NewTxOut(prevTx.TxOut[0].Value-fee-burn,
+			pkScript))
+		if burn != 0 {
+			tx.AddTxOut(wire.NewTxOut(burn, burnScript))
+		}
+		var buf bytes.Buffer
+		if err := tx.Serialize(&buf); err != nil {
+			t.Fatalf("unable to serialize transaction: %v", err)
+		}
+		return tx, hex.EncodeToString(buf.Bytes())
+	}
+	highFeeTx, highFeeHex := spend(coinbases[0], ltcutil.SatoshiPerBitcoin, 0)
+	burnTx, burnHex := spend(coinbases[0], 10000, ltcutil.SatoshiPerBitcoin)
+
+	tests := []struct {
+		name          string
+		hexTx         string
+		maxFeeRate    *btcjson.MaxFeeRate
+		maxBurnAmount *float64
+		message       string
+	}{{
+		name:    "default max fee rate exceeded",
+		hexTx:   highFeeHex,
+		message: "exceeds the max fee rate",
+	}, {
+		name:       "max fee rate exceeded",
+		hexTx:      highFeeHex,
+		maxFeeRate: btcjson.FeeRateLimit(0.5),
+		message:    "exceeds the max fee rate",
+	}, {
+		name:    "default max burn amount exceeded",
+		hexTx:   burnHex,
+		message: "exceeds the max burn amount",
+	}, {
+		name:          "max burn amount exceeded",
+		hexTx:         burnHex,
+		maxBurnAmount: btcjson.Float64(0.5),
+		message:       "exceeds the max burn amount",
+	}}
+	for _, test := range tests {
+		cmd := btcjson.NewSendRawTransactionCmd(test.hexTx,
+			test.maxFeeRate, test.maxBurnAmount)
+		_, err := handleSendRawTransaction(s, cmd, nil)
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != btcjson.ErrRPCDeserialization ||
+			!strings.Contains(rpcErr.Message, test.message) {
+
+			t.Fatalf("%s: got error %v, want %q", test.name, err,
+				test.message)
+		}
+	}
+	if count := txMemPool.Count(); count != 0 {
+		t.Fatalf("got %d transactions in the memory pool, want 0", count)
+	}
+
+	// Ensure the limits allow the transactions when raised or disabled.
+	err = checkRawTxLimits(s, ltcutil.NewTx(highFeeTx),
+		btcjson.FeeRateLimit(0), nil)
+	if err != nil {
+		t.Fatalf("disabled max fee rate: unexpected error: %v", err)
+	}
+	err = checkRawTxLimits(s, ltcutil.NewTx(burnTx), nil,
+		btcjson.Float64(1))
+	if err != nil {
+		t.Fatalf("raised max burn amount: unexpected error: %v", err)
+	}
+
+	// Ensure the fee rate check is skipped when the spent output is
+	// unknown.
+	orphanTx, _ := spend(highFeeTx, ltcutil.SatoshiPerBitcoin, 0)
+	if err := checkRawTxLimits(s, ltcutil.NewTx(orphanTx), nil, nil); err != nil {
+		t.Fatalf("orphan: unexpected error: %v", err)
+	}
+}
+
+// multisigPSBT is the packet from the signer example of BIP0174 which spends a
+// 2-of-2 multisig pay-to-script-hash output and a 2-of-2 multisig
+// pay-to-witness-script-hash output nested in a pay-to-script-hash output.
+// Both inputs have one of their two signatures.
+const multisigPSBT = "cHNidP8BAJoCAAAAAljoeiG1ba8MI76OcHBFbDNvfLqlyHV5JPVFiHuyq911AAAA" +
+	"AAD/////g40EJ9DsZQpoqka7CwmK6kQiwHGyyng1Kgd5WdB86h0BAAAAAP////8C" +
+	"cKrwCAAAAAAWABTYXCtx0AYLCcmIauuBXlCZHdoSTQDh9QUAAAAAFgAUAK6pouXw" +
+	"+HaliN9VRuh0LR2HAI8AAAAAAAEAuwIAAAABqtc5MQGL0l+ErkALaISL4J23BurC" +
+	"rBgpi6vucatlb4sAAAAASEcwRAIgWPb8fGoz4bMVSNSByCbAFb0wE1qtQs1neQ2r" +
+	"ZtKtJDsCIEoc7SYExnNbY5PltBaR3XiwDwxZQvufdRhW+qk4FX26Af7///8CgPD6" +
+	"AgAAAAAXqRQPuUY0IWlrgsgzryQceMF9295JNIfQ8gonAQAAABepFCnKdPigj4GZ" +
+	"lCgYXJe12FLkBj9hh2UAAAAiAgKVg785rgpgl0etGZrd1jT6YQhVnWxc05tMIYPx" +
+	"q5bgf0cwRAIgdAGK1BgAl7hzMjwAFXILNoTMgSOJEEjn282bVa1nnJkCIHPTabdA" +
+	"4+tT3O+jOCPIBwUUylWn3ZVE8VfBZ5EyYRGMAQEDBAEAAAABBEdSIQKVg785rgpg" +
+	"l0etGZrd1jT6YQhVnWxc05tMIYPxq5bgfyEC2rYf9JoU22p9ArDNH7t4/EsYMStb" +
+	"TlTa5Nui+/71NtdSriIGApWDvzmuCmCXR60Zmt3WNPphCFWdbFzTm0whg/GrluB/" +
+	"ENkMak8AAACAAAAAgAAAAIAiBgLath/0mhTban0CsM0fu3j8SxgxK1tOVNrk26L7" +
+	"/vU21xDZDGpPAAAAgAAAAIABAACAAAEBIADC6wsAAAAAF6kUt/X69A49QKWkWbHb" +
+	"NTXyty+pIeiHIgIDCJ3BDHrG21T5EymvYXMz2ziM6tDCMfcjN50bmQMLAtxHMEQC" +
+	"IGLrelVhB6fHP0WsSrWh3d9vcHX7EnWWmn84Pv/3hLyyAiAMBdu3Rw2/LwhVfdNW" +
+	"xzJcHtMJE+mWzThAlF2xIijaXwEBAwQBAAAAAQQiACCMI1MXN0O1ld+0oHtyuo5C" +
+	"43l9p06H/n2ddJfjsgKJAwEFR1IhAwidwQx6xttU+RMpr2FzM9s4jOrQwjH3Ized" +
+	"G5kDCwLcIQI63ZBPPW3PWd25BrDe4jUpt/+57VDl6GFRkmhgIh8Oc1KuIgYCOt2Q" +
+	"Tz1tz1nduQaw3uI1Kbf/ue1Q5ehhUZJoYCIfDnMQ2QxqTwAAAIAAAACAAwAAgCIG" +
+	"AwidwQx6xttU+RMpr2FzM9s4jOrQwjH3IzedG5kDCwLcENkMak8AAACAAAAAgAIA" +
+	"AIAAIgIDqaTDf1mW06ol26xrVwrwZQOUSSlCRgs1R1Ptnuylh3EQ2QxqTwAAAIAA" +
+	"AACABAAAgAAiAgJ/Y5l1fS7/VaE2rQLGhLGDi2VW5fG2s0KCqUtrUAUQlhDZDGpP" +
+	"AAAAgAAAAIAFAACAAA=="
+
+// TestDecodePSBT ensures decodepsbt returns the expected data for a known
+// multisig packet.
+func TestDecodePSBT(t *testing.T) {
+	t.Parallel()
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		ChainParams: &chaincfg.RegressionNetParams,
+	}}
+	result, err := handleDecodePSBT(s, btcjson.NewDecodePSBTCmd(multisigPSBT),
+		nil)
+	if err != nil {
+		t.Fatalf("decodepsbt: unexpected error: %v", err)
+	}
+	decoded := result.(btcjson.DecodePSBTResult)
+
+	wantTxID := "82efd652d7ab1197f01a5f4d9a30cb4c68bb79ab6fec58dfa1bf112291d1617b"
+	if decoded.Tx.Txid != wantTxID || len(decoded.Tx.Vin) != 2 ||
+		len(decoded.Tx.Vout) != 2 {
+
+		t.Fatalf("decodepsbt: unexpected unsigned tx %+v", decoded.Tx)
+	}
+	if decoded.Fee == nil || *decoded.Fee != 0.0001 {
+		t.Fatalf("decodepsbt: got fee %v, want 0.0001", decoded.Fee)
+	}
+	if len(decoded.Inputs) != 2 || len(decoded.Outputs) != 2 {
+		t.Fatalf("decodepsbt: got %d inputs and %d outputs, want 2 and 2",
+			len(decoded.Inputs), len(decoded.Outputs))
+	}
+
+	// The first input spends a legacy multisig output.
+	in := decoded.Inputs[0]
+	wantPrevTxID := "75ddabb27b8845f5247975c8a5ba7c6f336c4570708ebe230caf6db5217ae858"
+	if in.NonWitnessUtxo == nil || in.NonWitnessUtxo.Txid != wantPrevTxID {
+		t.Fatalf("input 0: unexpected non-witness utxo %+v",
+			in.NonWitnessUtxo)
+	}
+	wantSigs := map[string]string{
+		"029583bf39ae0a609747ad199addd634fa6108559d6c5cd39b4c2183f1ab96e07f": "3044022074018ad4180097b873323c0015720b3684cc8123891048e7dbcd9b55ad679c99022073d369b740e3eb53dcefa33823c8070514ca55a7dd9544f157c167913261118c01",
+	}
+	if !reflect.DeepEqual(in.PartialSignatures, wantSigs) {
+		t.Fatalf("input 0: got partial signatures %v, want %v",
+			in.PartialSignatures, wantSigs)
+	}
+	if in.Sighash != "ALL" {
+		t.Fatalf("input 0: got sighash %q, want ALL", in.Sighash)
+	}
+	if in.RedeemScript == nil || in.RedeemScript.Type != "multisig" {
+		t.Fatalf("input 0: unexpected redeem script %+v", in.RedeemScript)
+	}
+	wantDeriv := btcjson.PSBTBip32Deriv{
+		PubKey:            "029583bf39ae0a609747ad199addd634fa6108559d6c5cd39b4c2183f1ab96e07f",
+		MasterFingerprint: "d90c6a4f",
+		Path:              "m/0'/0'/0'",
+	}
+	if len(in.Bip32Derivs) != 2 || in.Bip32Derivs[0] != wantDeriv {
+		t.Fatalf("input 0: got derivations %+v, want %+v first",
+			in.Bip32Derivs, wantDeriv)
+	}
+
+	// The second input spends a nested segwit multisig output.
+	in = decoded.Inputs[1]
+	if in.NonWitnessUtxo != nil || in.WitnessUtxo == nil ||
+		in.WitnessUtxo.Amount != 2 ||
+		in.WitnessUtxo.ScriptPubKey.Type != "scripthash" {
+
+		t.Fatalf("input 1: unexpected utxos %+v, %+v", in.NonWitnessUtxo,
+			in.WitnessUtxo)
+	}
+	if in.RedeemScript == nil || in.RedeemScript.Type != "witness_v0_scripthash" ||
+		in.WitnessScript == nil || in.WitnessScript.Type != "multisig" {
+
+		t.Fatalf("input 1: unexpected scripts %+v, %+v", in.RedeemScript,
+			in.WitnessScript)
+	}
+
+	wantOutDeriv := btcjson.PSBTBip32Deriv{
+		PubKey:            "03a9a4c37f5996d3aa25dbac6b570af0650394492942460b354753ed9eeca58771",
+		MasterFingerprint: "d90c6a4f",
+		Path:              "m/0'/0'/4'",
+	}
+	out := decoded.Outputs[0]
+	if len(out.Bip32Derivs) != 1 || out.Bip32Derivs[0] != wantOutDeriv {
+		t.Fatalf("output 0: got derivations %+v, want %+v",
+			out.Bip32Derivs, wantOutDeriv)
+	}
+
+	// Ensure the global fields are always present in the JSON result.
+	marshalled, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("unable to marshal result: %v", err)
+	}
+	for _, field := range []string{`"global_xpubs":[]`,
+		`"proprietary":[]`, `"unknown":{}`} {
+
+		if !strings.Contains(string(marshalled), field) {
+			t.Fatalf("decodepsbt: result %s does not contain %s",
+				marshalled, field)
+		}
+	}
+
+	// Ensure a packet which is not valid base64 is rejected.
+	_, err = handleDecodePSBT(s, btcjson.NewDecodePSBTCmd("not a psbt"), nil)
+	rpcErr, ok := err.(*btcjson.RPCError)
+	if !ok || rpcErr.Code != btcjson.ErrRPCDeserialization {
+		t.Fatalf("decodepsbt: got error %v, want code %d", err,
+			btcjson.ErrRPCDeserialization)
+	}
+}
+
+// TestAnalyzePSBT ensures analyzepsbt reports the missing signatures of a
+// known multisig packet along with the signer as the next role.
+func TestAnalyzePSBT(t *testing.T) {
+	t.Parallel()
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		ChainParams: &chaincfg.RegressionNetParams,
+	}}
+	result, err := handleAnalyzePSBT(s,
+		btcjson.NewAnalyzePSBTCmd(multisigPSBT), nil)
+	if err != nil {
+		t.Fatalf("analyzepsbt: unexpected error: %v", err)
+	}
+	fee := 0.0001
+	want := &btcjson.AnalyzePSBTResult{
+		Inputs: []btcjson.AnalyzePSBTInput{{
+			HasUtxo: true,
+			Missing: &btcjson.AnalyzePSBTMissing{
+				Signatures: []string{"b9147fd38b198ab90491adec86ad6b69f5a3ec44"},
+			},
+			Next: "signer",
+		}, {
+			HasUtxo: true,
+			Missing: &btcjson.AnalyzePSBTMissing{
+				Signatures: []string{"d48ed3110b94014cb114bd32d6f4d066dc74256b"},
+			},
+			Next: "signer",
+		}},
+		Fee:  &fee,
+		Next: "signer",
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Fatalf("analyzepsbt: got %+v, want %+v", result, want)
+	}
+
+	// Ensure a packet whose outputs spend more than its inputs is reported
+	// as not valid.
+	p, err := psbt.ParseBase64(multisigPSBT)
+	if err != nil {
+		t.Fatalf("unable to parse packet: %v", err)
+	}
+	p.Inputs[1].WitnessUtxo.Value = 1
+	encoded, err := p.B64Encode()
+	if err != nil {
+		t.Fatalf("unable to encode packet: %v", err)
+	}
+	result, err = handleAnalyzePSBT(s, btcjson.NewAnalyzePSBTCmd(encoded),
+		nil)
+	if err != nil {
+		t.Fatalf("analyzepsbt: unexpected error: %v", err)
+	}
+	analysis := result.(*btcjson.AnalyzePSBTResult)
+	if analysis.Next != "creator" || analysis.Error == "" ||
+		analysis.Inputs != nil {
+
+		t.Fatalf("analyzepsbt: got %+v, want invalid packet", analysis)
+	}
+}
+
+// TestUtxoUpdatePSBT ensures utxoupdatepsbt adds the outputs spent by the
+// inputs of a packet from the chain while leaving inputs which already have
+// them or whose spent output is unknown untouched.
+func TestUtxoUpdatePSBT(t *testing.T) {
+	t.Parallel()
+
+	var txIndex *indexers.TxIndex
+	chain, db, _, teardown := newRegtestChain(t, 0,
+		func(db database.DB, params *chaincfg.Params) indexers.Indexer {
+			txIndex = indexers.NewTxIndex(db)
+			return txIndex
+		})
+	defer teardown()
+
+	// Create enough blocks for the first three coinbases to mature and
+	// then a block with a transaction spending the first one to a segwit
+	// and a legacy output.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity)+2; i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+	witnessScriptHash := sha256.Sum256(pkScript)
+	segwitScript, err := txscript.NewScriptBuilder().AddOp(txscript.OP_0).
+		AddData(witnessScriptHash[:]).Script()
+	if err != nil {
+		t.Fatalf("unable to create segwit script: %v", err)
+	}
+	coinbaseHash := coinbases[0].TxHash()
+	fundingTx := wire.NewMsgTx(1)
+	fundingTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0), nil,
+		nil))
+	value := coinbases[0].TxOut[0].Value / 2
+	fundingTx.AddTxOut(wire.NewTxOut(value, segwitScript))
+	fundingTx.AddTxOut(wire.NewTxOut(value-1000, pkScript))
+	addRegtestBlock(t, chain, pkScript, fundingTx)
+
+	// Create a packet spending both outputs of the funding transaction,
+	// the second coinbase, an unknown output, and the third coinbase
+	// which already has a spent output.
+	fundingHash := fundingTx.TxHash()
+	coinbaseHash = coinbases[1].TxHash()
+	otherCoinbaseHash := coinbases[2].TxHash()
+	tx := wire.NewMsgTx(1)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&fundingHash, 0), nil, nil))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&fundingHash, 1), nil, nil))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0), nil, nil))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{0x01}, 0), nil,
+		nil))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&otherCoinbaseHash, 0), nil,
+		nil))
+	tx.AddTxOut(wire.NewTxOut(value, pkScript))
+	p, err := psbt.New(tx)
+	if err != nil {
+		t.Fatalf("unable to create packet: %v", err)
+	}
+	presetUtxo := wire.NewTxOut(1, pkScript)
+	p.Inputs[4].WitnessUtxo = presetUtxo
+	encoded, err := p.B64Encode()
+	if err != nil {
+		t.Fatalf("unable to encode packet: %v", err)
+	}
+
+	// updatePSBT returns the packet updated by the passed server.
+	updatePSBT := func(s *rpcServer) *psbt.Packet {
+		result, err := handleUtxoUpdatePSBT(s,
+			btcjson.NewUtxoUpdatePSBTCmd(encoded), nil)
+		if err != nil {
+			t.Fatalf("utxoupdatepsbt: unexpected error: %v", err)
+		}
+		updated, err := psbt.ParseBase64(result.(string))
+		if err != nil {
+			t.Fatalf("unable to parse updated packet: %v", err)
+		}
+		return updated
+	}
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		DB:          db,
+		TxIndex:     txIndex,
+		TxMemPool:   mempool.New(&mempool.Config{}),
+	}}
+	updated := updatePSBT(s)
+
+	// The segwit input only gets the spent output while the legacy inputs
+	// get the full transactions which created them.
+	in := updated.Inputs[0]
+	if !reflect.DeepEqual(in.WitnessUtxo, fundingTx.TxOut[0]) ||
+		in.NonWitnessUtxo != nil {
+
+		t.Fatalf("input 0: got utxos %v, %v, want witness utxo %v",
+			in.WitnessUtxo, in.NonWitnessUtxo, fundingTx.TxOut[0])
+	}
+	wantTxns := []*wire.MsgTx{fundingTx, coinbases[1]}
+	for i, wantTx := range wantTxns {
+		in := updated.Inputs[i+1]
+		if in.WitnessUtxo != nil || in.NonWitnessUtxo == nil ||
+			in.NonWitnessUtxo.TxHash() != wantTx.TxHash() {
+
+			t.Fatalf("input %d: got utxos %v, %v, want non-witness "+
+				"utxo %v", i+1, in.WitnessUtxo, in.NonWitnessUtxo,
+				wantTx.TxHash())
+		}
+	}
+	if in := updated.Inputs[3]; in.WitnessUtxo != nil ||
+		in.NonWitnessUtxo != nil {
+
+		t.Fatalf("input 3: unexpected utxo for unknown output")
+	}
+	if in := updated.Inputs[4]; !reflect.DeepEqual(in.WitnessUtxo,
+		presetUtxo) || in.NonWitnessUtxo != nil {
+
+		t.Fatalf("input 4: got utxos %v, %v, want untouched witness "+
+			"utxo %v", in.WitnessUtxo, in.NonWitnessUtxo, presetUtxo)
+	}
+
+	// Without the transaction index only the segwit input can be updated.
+	s.cfg.TxIndex = nil
+	updated = updatePSBT(s)
+	if updated.Inputs[0].WitnessUtxo == nil {
+		t.Fatalf("no tx index: missing witness utxo of input 0")
+	}
+	for i := 1; i < 3; i++ {
+		if in := updated.Inputs[i]; in.WitnessUtxo != nil ||
+			in.NonWitnessUtxo != nil {
+
+			t.Fatalf("no tx index: unexpected utxo for input %d", i)
+		}
+	}
+}
+
+// TestConvertToPSBT ensures converttopsbt converts a raw transaction to a
+// packet which decodes back to the same unsigned transaction and only
+// discards signatures when permitted.
+func TestConvertToPSBT(t *testing.T) {
+	t.Parallel()
+
+	s := &rpcServer{cfg: rpcserverConfig{
+		ChainParams: &chaincfg.RegressionNetParams,
+	}}
+
+	// Create an unsigned transaction with two inputs along with a signed
+	// version of it.
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{0x01}, 0), nil,
+		nil))
+	tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&chainhash.Hash{0x02}, 1), nil,
+		nil))
+	tx.AddTxOut(wire.NewTxOut(100000, []byte{txscript.OP_TRUE}))
+	tx.LockTime = 500
+	signedTx := tx.Copy()
+	signedTx.TxIn[0].SignatureScript = []byte{txscript.OP_TRUE}
+	signedTx.TxIn[1].Witness = wire.TxWitness{{0x01, 0x02}}
+	toHex := func(tx *wire.MsgTx) string {
+		var buf bytes.Buffer
+		if err := tx.Serialize(&buf); err != nil {
+			t.Fatalf("unable to serialize transaction: %v", err)
+		}
+		return hex.EncodeToString(buf.Bytes())
+	}
+
+	// convert returns the packet the passed transaction is converted to.
+	convert := func(hexTx string, permitSigData *bool) *psbt.Packet {
+		result, err := handleConvertToPSBT(s,
+			btcjson.NewConvertToPSBTCmd(hexTx, permitSigData, nil), nil)
+		if err != nil {
+			t.Fatalf("converttopsbt: unexpected error: %v", err)
+		}
+		decoded, err := handleDecodePSBT(s,
+			btcjson.NewDecodePSBTCmd(result.(string)), nil)
+		if err != nil {
+			t.Fatalf("decodepsbt: unexpected error: %v", err)
+		}
+		if txID := decoded.(btcjson.DecodePSBTResult).Tx.Txid; txID !=
+			tx.TxHash().String() {
+
+			t.Fatalf("decodepsbt: got txid %s, want %s", txID,
+				tx.TxHash())
+		}
+		p, err := psbt.ParseBase64(result.(string))
+		if err != nil {
+			t.Fatalf("unable to parse packet: %v", err)
+		}
+		return p
+	}
+
+	p := convert(toHex(tx), nil)
+	if got, want := toHex(p.UnsignedTx), toHex(tx); got != want {
+		t.Fatalf("got unsigned tx %s, want %s", got, want)
+	}
+	if len(p.Inputs) != 2 || len(p.Outputs) != 1 {
+		t.Fatalf("got %d inputs and %d outputs, want 2 and 1",
+			len(p.Inputs), len(p.Outputs))
+	}
+	for i, in := range p.Inputs {
+		if !reflect.DeepEqual(in, &psbt.Input{}) {
+			t.Fatalf("input %d: got %+v, want empty map", i, in)
+		}
+	}
+
+	// Signatures are rejected by default and discarded when permitted.
+	_, err := handleConvertToPSBT(s,
+		btcjson.NewConvertToPSBTCmd(toHex(signedTx), nil, nil), nil)
+	rpcErr, ok := err.(*btcjson.RPCError)
+	if !ok || rpcErr.Code != btcjson.ErrRPCDeserialization {
+		t.Fatalf("converttopsbt: got error %v, want code %d", err,
+			btcjson.ErrRPCDeserialization)
+	}
+	p = convert(toHex(signedTx), btcjson.Bool(true))
+	if got, want := toHex(p.UnsignedTx), toHex(tx); got != want {
+		t.Fatalf("got unsigned tx %s, want %s", got, want)
+	}
+}
+
+// TestCreateRawTransaction ensures the createrawtransaction handler sets the
+// sequence numbers and locktime of the created transaction as requested and
+// creates data carrier outputs.
+func TestCreateRawTransaction(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.RegressionNetParams
+	s := &rpcServer{cfg: rpcserverConfig{ChainParams: params}}
+	addr, err := ltcutil.NewAddressPubKeyHash(make([]byte, 20), params)
+	if err != nil {
+		t.Fatalf("unable to create address: %v", err)
+	}
+	amounts := map[string]interface{}{addr.EncodeAddress(): 1.5}
+	newInputs := func(sequences ...*int64) []btcjson.TransactionInput {
+		inputs := make([]btcjson.TransactionInput, len(sequences))
+		for i, sequence := range sequences {
+			inputs[i] = btcjson.TransactionInput{
+				Txid:     chainhash.Hash{byte(i + 1)}.String(),
+				Vout:     uint32(i),
+				Sequence: sequence,
+			}
+		}
+		return inputs
+	}
+
+	// create returns the transaction created by the passed command.
+	create := func(name string, cmd *btcjson.CreateRawTransactionCmd) *wire.MsgTx {
+		result, err := handleCreateRawTransaction(s, cmd, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		serializedTx, err := hex.DecodeString(result.(string))
+		if err != nil {
+			t.Fatalf("%s: unable to decode result: %v", name, err)
+		}
+		var mtx wire.MsgTx
+		if err := mtx.Deserialize(bytes.NewReader(serializedTx)); err != nil {
+			t.Fatalf("%s: unable to deserialize result: %v", name, err)
+		}
+		return &mtx
+	}
+
+	tests := []struct {
+		name      string
+		cmd       *btcjson.CreateRawTransactionCmd
+		sequences []uint32
+		lockTime  uint32
+	}{
+		{
+			name: "defaults",
+			cmd: btcjson.NewCreateRawTransactionCmd(newInputs(nil, nil),
+				amounts, nil, nil),
+			sequences: []uint32{wire.MaxTxInSequenceNum,
+				wire.MaxTxInSequenceNum},
+		},
+		{
+			name: "locktime",
+			cmd: btcjson.NewCreateRawTransactionCmd(newInputs(nil, nil),
+				amounts, btcjson.Int64(500), nil),
+			sequences: []uint32{wire.MaxTxInSequenceNum - 1,
+				wire.MaxTxInSequenceNum - 1},
+			lockTime: 500,
+		},
+		{
+			name: "replaceable",
+			cmd: btcjson.NewCreateRawTransactionCmd(newInputs(nil, nil),
+				amounts, btcjson.Int64(500), btcjson.Bool(true)),
+			sequences: []uint32{wire.MaxTxInSequenceNum - 2,
+				wire.MaxTxInSequenceNum - 2},
+			lockTime: 500,
+		},
+		{
+			name: "explicit sequences",
+			cmd: btcjson.NewCreateRawTransactionCmd(newInputs(
+				btcjson.Int64(10), nil), amounts, btcjson.Int64(500),
+				nil),
+			sequences: []uint32{10, wire.MaxTxInSequenceNum - 1},
+			lockTime:  500,
+		},
+		{
+			name: "replaceable with explicit sequence",
+			cmd: btcjson.NewCreateRawTransactionCmd(newInputs(
+				btcjson.Int64(0), nil), amounts, nil,
+				btcjson.Bool(true)),
+			sequences: []uint32{0, wire.MaxTxInSequenceNum - 2},
+		},
+	}
+	for _, test := range tests {
+		mtx := create(test.name, test.cmd)
+		if len(mtx.TxIn) != len(test.sequences) {
+			t.Fatalf("%s: got %d inputs, want %d", test.name,
+				len(mtx.TxIn), len(test.sequences))
+		}
+		for i, txIn := range mtx.TxIn {
+			if txIn.Sequence != test.sequences[i] {
+				t.Fatalf("%s: got sequence %d for input %d, "+
+					"want %d", test.name, txIn.Sequence, i,
+					test.sequences[i])
+			}
+		}
+		if mtx.LockTime != test.lockTime {
+			t.Fatalf("%s: got locktime %d, want %d", test.name,
+				mtx.LockTime, test.lockTime)
+		}
+	}
+
+	// Ensure the data key creates a zero-value data carrier output.
+	mtx := create("data", btcjson.NewCreateRawTransactionCmd(newInputs(nil),
+		map[string]interface{}{"data": "deadbeef"}, nil, nil))
+	wantScript, err := txscript.NullDataScript([]byte{0xde, 0xad, 0xbe, 0xef})
+	if err != nil {
+		t.Fatalf("unable to create data script: %v", err)
+	}
+	if len(mtx.TxOut) != 1 || mtx.TxOut[0].Value != 0 ||
+		!bytes.Equal(mtx.TxOut[0].PkScript, wantScript) {
+
+		t.Fatalf("data: got outputs %v, want a single data output",
+			mtx.TxOut)
+	}
+
+	// Ensure invalid sequence numbers and data are rejected.
+	wantCode := func(name string, err error, code btcjson.RPCErrorCode) {
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != code {
+			t.Fatalf("%s: got error %v, want code %d", name, err, code)
+		}
+	}
+	_, err = handleCreateRawTransaction(s, btcjson.NewCreateRawTransactionCmd(
+		newInputs(btcjson.Int64(int64(wire.MaxTxInSequenceNum))),
+		amounts, nil, btcjson.Bool(true)), nil)
+	wantCode("replaceable with final sequence", err,
+		btcjson.ErrRPCInvalidParameter)
+	_, err = handleCreateRawTransaction(s, btcjson.NewCreateRawTransactionCmd(
+		newInputs(btcjson.Int64(-1)), amounts, nil, nil), nil)
+	wantCode("negative sequence", err, btcjson.ErrRPCInvalidParameter)
+	_, err = handleCreateRawTransaction(s, btcjson.NewCreateRawTransactionCmd(
+		newInputs(btcjson.Int64(1<<32)), amounts, nil, nil), nil)
+	wantCode("sequence too large", err, btcjson.ErrRPCInvalidParameter)
+	_, err = handleCreateRawTransaction(s, btcjson.NewCreateRawTransactionCmd(
+		newInputs(nil), map[string]interface{}{"data": "xyz"}, nil, nil),
+		nil)
+	wantCode("non-hex data", err, btcjson.ErrRPCDecodeHexString)
+}
+
+// TestDecodeScript ensures the decodescript handler classifies witness and
+// MWEB scripts and derives the addresses which pay to the decoded script.
+func TestDecodeScript(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.RegressionNetParams
+	s := &rpcServer{cfg: rpcserverConfig{ChainParams: params}}
+
+	// Create a 1-of-2 multisig script along with the segwit program which
+	// pays to it.
+	var pubKeys []*ltcutil.AddressPubKey
+	var pubKeyAddrs []string
+	for i := byte(1); i <= 2; i++ {
+		_, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), []byte{i})
+		addr, err := ltcutil.NewAddressPubKey(
+			pubKey.SerializeCompressed(), params)
+		if err != nil {
+			t.Fatalf("unable to create pubkey address: %v", err)
+		}
+		pubKeys = append(pubKeys, addr)
+		pubKeyAddrs = append(pubKeyAddrs, addr.EncodeAddress())
+	}
+	multiSigScript, err := txscript.MultiSigScript(pubKeys, 1)
+	if err != nil {
+		t.Fatalf("unable to create multisig script: %v", err)
+	}
+	scriptHash := sha256.Sum256(multiSigScript)
+	p2wshAddr, err := ltcutil.NewAddressWitnessScriptHash(scriptHash[:],
+		params)
+	if err != nil {
+		t.Fatalf("unable to create witness script hash address: %v", err)
+	}
+	p2wshScript, err := txscript.PayToAddrScript(p2wshAddr)
+	if err != nil {
+		t.Fatalf("unable to create witness script hash script: %v", err)
+	}
+	p2shAddr := func(script []byte) string {
+		addr, err := ltcutil.NewAddressScriptHash(script, params)
+		if err != nil {
+			t.Fatalf("unable to create script hash address: %v", err)
+		}
+		return addr.EncodeAddress()
+	}
+
+	// decode returns the result of decoding the passed script.
+	decode := func(script []byte) btcjson.DecodeScriptResult {
+		result, err := handleDecodeScript(s, btcjson.NewDecodeScriptCmd(
+			hex.EncodeToString(script)), nil)
+		if err != nil {
+			t.Fatalf("decodescript %x: unexpected error: %v", script,
+				err)
+		}
+		return result.(btcjson.DecodeScriptResult)
+	}
+
+	// Ensure the multisig script is decoded with its public keys and
+	// wrapped in both a script hash and a segwit program.
+	result := decode(multiSigScript)
+	if result.Type != "multisig" || result.ReqSigs != 1 ||
+		!reflect.DeepEqual(result.Addresses, pubKeyAddrs) {
+
+		t.Fatalf("multisig: got type %s, reqSigs %d and addresses %v",
+			result.Type, result.ReqSigs, result.Addresses)
+	}
+	if want := p2shAddr(multiSigScript); result.P2sh != want {
+		t.Fatalf("multisig: got p2sh %s, want %s", result.P2sh, want)
+	}
+	want := &btcjson.DecodeScriptSegwit{
+		Asm:        "0 " + hex.EncodeToString(scriptHash[:]),
+		Hex:        hex.EncodeToString(p2wshScript),
+		ReqSigs:    1,
+		Type:       "witness_v0_scripthash",
+		Addresses:  []string{p2wshAddr.EncodeAddress()},
+		P2shSegwit: p2shAddr(p2wshScript),
+	}
+	if !reflect.DeepEqual(result.Segwit, want) {
+		t.Fatalf("multisig: got segwit %+v, want %+v", result.Segwit,
+			want)
+	}
+
+	// Ensure the segwit program itself is decoded with its address and is
+	// not wrapped in another segwit program.
+	result = decode(p2wshScript)
+	if result.Type != "witness_v0_scripthash" ||
+		!reflect.DeepEqual(result.Addresses,
+			[]string{p2wshAddr.EncodeAddress()}) {
+
+		t.Fatalf("p2wsh: got type %s and addresses %v", result.Type,
+			result.Addresses)
+	}
+	if want := p2shAddr(p2wshScript); result.P2sh != want {
+		t.Fatalf("p2wsh: got p2sh %s, want %s", result.P2sh, want)
+	}
+	if result.Segwit != nil {
+		t.Fatalf("p2wsh: got unexpected segwit %+v", result.Segwit)
+	}
+
+	// Ensure public key hash scripts are wrapped in a witness key hash
+	// while scripts with uncompressed public keys are not wrapped at all.
+	p2pkhScript, err := txscript.PayToAddrScript(pubKeys[0].AddressPubKeyHash())
+	if err != nil {
+		t.Fatalf("unable to create pubkey hash script: %v", err)
+	}
+	if result := decode(p2pkhScript); result.Segwit == nil ||
+		result.Segwit.Type != "witness_v0_keyhash" {
+
+		t.Fatalf("p2pkh: got segwit %+v, want witness_v0_keyhash",
+			result.Segwit)
+	}
+	_, pubKey := btcec.PrivKeyFromBytes(btcec.S256(), []byte{1})
+	p2pkScript, err := txscript.NewScriptBuilder().
+		AddData(pubKey.SerializeUncompressed()).
+		AddOp(txscript.OP_CHECKSIG).Script()
+	if err != nil {
+		t.Fatalf("unable to create pubkey script: %v", err)
+	}
+	if result := decode(p2pkScript); result.Type != "pubkey" ||
+		result.Segwit != nil {
+
+		t.Fatalf("uncompressed pubkey: got type %s and segwit %+v",
+			result.Type, result.Segwit)
+	}
+
+	// Ensure the witness programs without a script class and unknown
+	// scripts are identified.
+	program := bytes.Repeat([]byte{0x01}, 32)
+	tests := []struct {
+		name     string
+		version  byte
+		program  []byte
+		wantType string
+	}{
+		{"taproot", txscript.OP_1, program, "witness_v1_taproot"},
+		{"mweb hogaddr", txscript.OP_8, program, "witness_mweb_hogaddr"},
+		{"mweb pegin", txscript.OP_9, program, "witness_mweb_pegin"},
+		{"unknown witness", txscript.OP_2, program[:20], "witness_unknown"},
+	}
+	for _, test := range tests {
+		script, err := txscript.NewScriptBuilder().AddOp(test.version).
+			AddData(test.program).Script()
+		if err != nil {
+			t.Fatalf("%s: unable to create script: %v", test.name, err)
+		}
+		result := decode(script)
+		if result.Type != test.wantType || result.Segwit != nil {
+			t.Fatalf("%s: got type %s and segwit %+v, want type %s",
+				test.name, result.Type, result.Segwit, test.wantType)
+		}
+	}
+	result = decode([]byte{txscript.OP_1, txscript.OP_ADD})
+	if result.Type != "nonstandard" || result.Asm != "1 OP_ADD" ||
+		result.Segwit == nil {
+
+		t.Fatalf("nonstandard: got type %s, asm %q and segwit %+v",
+			result.Type, result.Asm, result.Segwit)
+	}
+}
+
+// TestSignVerifyMessage ensures messages signed by the signmessagewithprivkey
+// handler are verified by the verifymessage handler with the litecoin signed
+// message magic and that invalid keys and signatures are rejected.
+func TestSignVerifyMessage(t *testing.T) {
+	t.Parallel()
+
+	params := &chaincfg.RegressionNetParams
+	s := &rpcServer{cfg: rpcserverConfig{ChainParams: params}}
+	wantCode := func(name string, err error, code btcjson.RPCErrorCode) {
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != code {
+			t.Fatalf("%s: got error %v, want code %d", name, err, code)
+		}
+	}
+	verify := func(name, addr, sig, message string) bool {
+		result, err := handleVerifyMessage(s,
+			btcjson.NewVerifyMessageCmd(addr, sig, message), nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected verify error: %v", name, err)
+		}
+		return result.(bool)
+	}
+
+	const message = "litecoin message"
+	var addrs []string
+	for i, compress := range []bool{true, false} {
+		privKey, pubKey := btcec.PrivKeyFromBytes(btcec.S256(),
+			[]byte{byte(i + 1)})
+		wif, err := ltcutil.NewWIF(privKey, params, compress)
+		if err != nil {
+			t.Fatalf("unable to create WIF: %v", err)
+		}
+		serializedPubKey := pubKey.SerializeUncompressed()
+		if compress {
+			serializedPubKey = pubKey.SerializeCompressed()
+		}
+		addr, err := ltcutil.NewAddressPubKeyHash(
+			ltcutil.Hash160(serializedPubKey), params)
+		if err != nil {
+			t.Fatalf("unable to create address: %v", err)
+		}
+		addrs = append(addrs, addr.EncodeAddress())
+
+		result, err := handleSignMessageWithPrivKey(s,
+			btcjson.NewSignMessageWithPrivKeyCmd(wif.String(),
+				message), nil)
+		if err != nil {
+			t.Fatalf("compressed %v: unexpected sign error: %v",
+				compress, err)
+		}
+		sig := result.(string)
+		if !verify("valid", addr.EncodeAddress(), sig, message) {
+			t.Fatalf("compressed %v: signature did not verify",
+				compress)
+		}
+		if verify("other message", addr.EncodeAddress(), sig, "other") {
+			t.Fatalf("compressed %v: signature verified for other "+
+				"message", compress)
+		}
+
+		// Ensure signatures over the bitcoin signed message magic do
+		// not verify.
+		var buf bytes.Buffer
+		wire.WriteVarString(&buf, 0, "Bitcoin Signed Message:\n")
+		wire.WriteVarString(&buf, 0, message)
+		btcSig, err := btcec.SignCompact(btcec.S256(), privKey,
+			chainhash.DoubleHashB(buf.Bytes()), compress)
+		if err != nil {
+			t.Fatalf("unable to sign message: %v", err)
+		}
+		if verify("bitcoin magic", addr.EncodeAddress(),
+			base64.StdEncoding.EncodeToString(btcSig), message) {
+
+			t.Fatalf("compressed %v: signature over bitcoin magic "+
+				"verified", compress)
+		}
+	}
+
+	// Ensure a signature does not verify for the address of another key.
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), []byte{3})
+	wif, err := ltcutil.NewWIF(privKey, params, false)
+	if err != nil {
+		t.Fatalf("unable to create WIF: %v", err)
+	}
+	result, err := handleSignMessageWithPrivKey(s,
+		btcjson.NewSignMessageWithPrivKeyCmd(wif.String(), message), nil)
+	if err != nil {
+		t.Fatalf("unexpected sign error: %v", err)
+	}
+	if verify("other address", addrs[1], result.(string), message) {
+		t.Fatal("signature verified for the address of another key")
+	}
+	if verify("truncated signature", addrs[0], "AAAA", message) {
+		t.Fatal("truncated signature verified")
+	}
+
+	// Ensure invalid keys and malformed signatures are rejected.
+	_, err = handleSignMessageWithPrivKey(s,
+		btcjson.NewSignMessageWithPrivKeyCmd("notakey", message), nil)
+	wantCode("invalid key", err, btcjson.ErrRPCInvalidAddressOrKey)
+	wif, err = ltcutil.NewWIF(privKey, &chaincfg.MainNetParams, true)
+	if err != nil {
+		t.Fatalf("unable to create WIF: %v", err)
+	}
+	_, err = handleSignMessageWithPrivKey(s,
+		btcjson.NewSignMessageWithPrivKeyCmd(wif.String(), message), nil)
+	wantCode("wrong network key", err, btcjson.ErrRPCInvalidAddressOrKey)
+	_, err = handleVerifyMessage(s, btcjson.NewVerifyMessageCmd(addrs[0],
+		"!!!", message), nil)
+	wantCode("malformed signature", err, btcjson.ErrRPCParse.Code)
+}
+
+// TestGetBlockChainInfoSoftForks ensures getblockchaininfo reports the buried
+// soft-forks along with the state and signalling statistics of the BIP0009
+// deployments.
+func TestGetBlockChainInfoSoftForks(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	params := &chaincfg.RegressionNetParams
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+		SyncMgr:     &chainSyncManager{chain: chain},
+	}}
+	softForks := func() map[string]*btcjson.SoftForkDescription {
+		result, err := handleGetBlockChainInfo(s, nil, nil)
+		if err != nil {
+			t.Fatalf("getblockchaininfo: unexpected error: %v", err)
+		}
+		return result.(*btcjson.GetBlockChainInfoResult).SoftForks
+	}
+
+	// Ensure the buried soft-forks are reported with their heights and
+	// the deployments are defined in the first window.
+	forks := softForks()
+	wantBuried := map[string]int32{
+		"bip34": params.BIP0034Height,
+		"bip66": params.BIP0066Height,
+		"bip65": params.BIP0065Height,
+	}
+	for name, height := range wantBuried {
+		want := &btcjson.SoftForkDescription{
+			Type:   "buried",
+			Height: height,
+		}
+		if !reflect.DeepEqual(forks[name], want) {
+			t.Fatalf("%s: got %+v, want %+v", name, forks[name], want)
+		}
+	}
+	dummy := params.Deployments[chaincfg.DeploymentTestDummy]
+	want := &btcjson.SoftForkDescription{
+		Type: "bip9",
+		Bip9: &btcjson.Bip9SoftForkDescription{
+			Status:    "defined",
+			Bit:       dummy.BitNumber,
+			StartTime: int64(dummy.StartTime),
+			Timeout:   int64(dummy.ExpireTime),
+		},
+	}
+	if !reflect.DeepEqual(forks["dummy"], want) {
+		t.Fatalf("dummy: got %+v, want %+v", forks["dummy"], want)
+	}
+
+	// Ensure the deployments are started with the signalling statistics
+	// of the next window once the first window is complete.
+	for i := uint32(1); i < params.MinerConfirmationWindow; i++ {
+		addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	}
+	forks = softForks()
+	for _, name := range []string{"dummy", "csv", "segwit"} {
+		fork := forks[name]
+		if fork == nil || fork.Type != "bip9" || fork.Active ||
+			fork.Bip9.Status != "started" ||
+			fork.Bip9.Since != int32(params.MinerConfirmationWindow) {
+
+			t.Fatalf("%s: got %+v, want started bip9 soft-fork",
+				name, fork)
+		}
+		wantStats := &btcjson.Bip9Statistics{
+			Period:    params.MinerConfirmationWindow,
+			Threshold: params.RuleChangeActivationThreshold,
+			Possible:  true,
+		}
+		if !reflect.DeepEqual(fork.Bip9.Statistics, wantStats) {
+			t.Fatalf("%s: got statistics %+v, want %+v", name,
+				fork.Bip9.Statistics, wantStats)
+		}
+	}
+}
+
+// TestGetDeploymentInfo ensures getdeploymentinfo reports the signalling
+// statistics and state of the deployments after the best block or a provided
+// block as blocks signalling for a deployment are mined.
+func TestGetDeploymentInfo(t *testing.T) {
+	t.Parallel()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	params := &chaincfg.RegressionNetParams
+	s := &rpcServer{cfg: rpcserverConfig{
+		Chain:       chain,
+		ChainParams: params,
+	}}
+	getInfo := func(blockHash *string) *btcjson.GetDeploymentInfoResult {
+		result, err := handleGetDeploymentInfo(s,
+			btcjson.NewGetDeploymentInfoCmd(blockHash), nil)
+		if err != nil {
+			t.Fatalf("getdeploymentinfo: unexpected error: %v", err)
+		}
+		return result.(*btcjson.GetDeploymentInfoResult)
+	}
+	wantStats := func(name string, info *btcjson.GetDeploymentInfoResult,
+		elapsed, count uint32, possible bool) {
+
+		want := &btcjson.Bip9Statistics{
+			Period:    params.MinerConfirmationWindow,
+			Threshold: params.RuleChangeActivationThreshold,
+			Elapsed:   elapsed,
+			Count:     count,
+			Possible:  possible,
+		}
+		fork := info.Deployments[name]
+		if fork == nil || fork.Bip9 == nil ||
+			fork.Bip9.Status != "started" ||
+			!reflect.DeepEqual(fork.Bip9.Statistics, want) {
+
+			t.Fatalf("%s at height %d: got %+v, want started with "+
+				"statistics %+v", name, info.Height, fork, want)
+		}
+	}
+
+	// Complete the first window, which starts the deployments, and then
+	// signal for the dummy deployment in some of the blocks of the next
+	// window.
+	dummyBit := params.Deployments[chaincfg.DeploymentTestDummy].BitNumber
+	signalVersion := int32(0x20000000 | uint32(1)<<dummyBit)
+	pkScript := []byte{txscript.OP_TRUE}
+	for i := uint32(1); i < params.MinerConfirmationWindow; i++ {
+		addRegtestBlock(t, chain, pkScript)
+	}
+	for i := 0; i < 100; i++ {
+		addRegtestBlockVersion(t, chain, signalVersion, pkScript)
+	}
+	signalHash := chain.BestSnapshot().Hash.String()
+	for i := 0; i < 10; i++ {
+		addRegtestBlock(t, chain, pkScript)
+	}
+
+	// Ensure the statistics of the window are reported after the best
+	// block and the last signalling block.  The CSV deployment can no
+	// longer lock in since none of the blocks signal for it.
+	info := getInfo(nil)
+	best := chain.BestSnapshot()
+	if info.Hash != best.Hash.String() || info.Height != best.Height {
+		t.Fatalf("got block %s (%d), want %s (%d)", info.Hash,
+			info.Height, best.Hash, best.Height)
+	}
+	wantStats("dummy", info, 110, 100, true)
+	wantStats("csv", info, 110, 0, false)
+	info = getInfo(&signalHash)
+	if info.Hash != signalHash || info.Height != best.Height-10 {
+		t.Fatalf("got block %s (%d), want %s (%d)", info.Hash,
+			info.Height, signalHash, best.Height-10)
+	}
+	wantStats("dummy", info, 100, 100, true)
+	wantStats("csv", info, 100, 0, false)
+
+	// Ensure the dummy deployment locks in once the window is complete.
+	window := int32(params.MinerConfirmationWindow)
+	for best.Height < 2*window-1 {
+		addRegtestBlockVersion(t, chain, signalVersion, pkScript)
+		best = chain.BestSnapshot()
+	}
+	fork := getInfo(nil).Deployments["dummy"]
+	if fork.Bip9.Status != "locked_in" || fork.Bip9.Since != 2*window ||
+		fork.Bip9.Statistics != nil || fork.Active {
+
+		t.Fatalf("dummy: got %+v, want locked in since %d", fork.Bip9,
+			2*window)
+	}
+
+	// Ensure unknown and malformed block hashes are rejected.
+	wantCode := func(name string, err error, code btcjson.RPCErrorCode) {
+		rpcErr, ok := err.(*btcjson.RPCError)
+		if !ok || rpcErr.Code != code {
+			t.Fatalf("%s: got error %v, want code %d", name, err, code)
+		}
+	}
+	_, err := handleGetDeploymentInfo(s, btcjson.NewGetDeploymentInfoCmd(
+		btcjson.String(chainhash.Hash{0x01}.String())), nil)
+	wantCode("unknown block", err, btcjson.ErrRPCBlockNotFound)
+	_, err = handleGetDeploymentInfo(s, btcjson.NewGetDeploymentInfoCmd(
+		btcjson.String("xyz")), nil)
+	wantCode("malformed hash", err, btcjson.ErrRPCDecodeHexString)
+}
+
+// TestGetCacheInfo ensures getcacheinfo reports the statistics of the
+// signature verification and script execution caches.
+func TestGetCacheInfo(t *testing.T) {
+	t.Parallel()
+
+	scriptCache := txscript.NewScriptCache(10)
+	scriptCache.Add(chainhash.Hash{}, 0, txscript.ScriptBip16)
+	scriptCache.Exists(chainhash.Hash{}, 0, txscript.ScriptBip16)
+	scriptCache.Exists(chainhash.Hash{}, 1, txscript.ScriptBip16)
+	s := &rpcServer{cfg: rpcserverConfig{
+		SigCache:    txscript.NewSigCache(20),
+		ScriptCache: scriptCache,
+	}}
+	result, err := handleGetCacheInfo(s, btcjson.NewGetCacheInfoCmd(), nil)
+	if err != nil {
+		t.Fatalf("getcacheinfo: unexpected error: %v", err)
+	}
+	want := btcjson.GetCacheInfoResult{
+		SigCache: btcjson.CacheInfoResult{MaxEntries: 20},
+		ScriptCache: btcjson.CacheInfoResult{
+			Entries:    1,
+			MaxEntries: 10,
+			Hits:       1,
+			Misses:     1,
+		},
+	}
+	if got := *result.(*btcjson.GetCacheInfoResult); got != want {
+		t.Fatalf("getcacheinfo: got %+v, want %+v", got, want)
+	}
+}
+
+// TestRPCRequestLimits ensures HTTP POST requests exceeding the maximum size
+// and batches exceeding the maximum number of requests are rejected, while
+// batches within the limits are replied to.
+func TestRPCRequestLimits(t *testing.T) {
+	oldCfg := cfg
+	cfg = &config{RPCMaxRequestSize: 1024, RPCMaxBatchSize: 2}
+	defer func() {
+		cfg = oldCfg
+	}()
+
+	s := &rpcServer{
+		cfg:         rpcserverConfig{StartupTime: time.Now().Unix()},
+		statusLines: make(map[int]string),
+	}
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			s.jsonRPCRead(w, r, nil)
+		}))
+	defer server.Close()
+
+	uptime := func(id int) string {
+		return fmt.Sprintf(`{"jsonrpc":"1.0","id":%d,"method":"uptime",`+
+			`"params":[]}`, id)
+	}
+	post := func(body io.Reader) (int, []byte) {
+		resp, err := http.Post(server.URL, "application/json", body)
+		if err != nil {
+			t.Fatalf("unable to post request: %v", err)
+		}
+		defer resp.Body.Close()
+		reply, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("unable to read reply: %v", err)
+		}
+		return resp.StatusCode, reply
+	}
+
+	// A batch within the limits is replied to with the replies to each of
+	// its requests.
+	code, reply := post(strings.NewReader("[" + uptime(1) + "," +
+		uptime(2) + "]"))
+	if code != http.StatusOK {
+		t.Fatalf("batch: got status %d, want %d", code, http.StatusOK)
+	}
+	var replies []btcjson.Response
+	if err := json.Unmarshal(reply, &replies); err != nil {
+		t.Fatalf("batch: unable to parse reply %s: %v", reply, err)
+	}
+	if len(replies) != 2 {
+		t.Fatalf("batch: got %d replies, want 2", len(replies))
+	}
+	for i, r := range replies {
+		if r.Error != nil || r.ID == nil || fmt.Sprint(*r.ID) !=
+			fmt.Sprint(float64(i+1)) {
+
+			t.Fatalf("batch: unexpected reply %d: %s", i, reply)
+		}
+	}
+
+	// Empty batches and batches with too many requests are rejected with a
+	// single invalid request error.
+	tests := []string{
+		"[]",
+		"[" + uptime(1) + "," + uptime(2) + "," + uptime(3) + "]",
+	}
+	for _, body := range tests {
+		code, reply := post(strings.NewReader(body))
+		if code != http.StatusOK {
+			t.Fatalf("%s: got status %d, want %d", body, code,
+				http.StatusOK)
+		}
+		var r btcjson.Response
+		if err := json.Unmarshal(reply, &r); err != nil {
+			t.Fatalf("%s: unable to parse reply %s: %v", body, reply,
+				err)
+		}
+		if r.Error == nil ||
+			r.Error.Code != btcjson.ErrRPCInvalidRequest.Code {
+
+			t.Fatalf("%s: got reply %s, want error code %d", body,
+				reply, btcjson.ErrRPCInvalidRequest.Code)
+		}
+	}
+
+	// Oversized requests are rejected whether or not their size is known
+	// up front.
+	oversized := "[" + strings.Repeat(uptime(1)+",", 50) + uptime(1) + "]"
+	code, _ = post(strings.NewReader(oversized))
+	if code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized: got status %d, want %d", code,
+			http.StatusRequestEntityTooLarge)
+	}
+	code, _ = post(ioutil.NopCloser(strings.NewReader(oversized)))
+	if code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized with unknown size: got status %d, want %d",
+			code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+// TestRPCCookie ensures the RPC server writes an authentication cookie which
+// only the current user may read and which authenticates clients with full
+// access, and that the cookie is removed when the RPC server is stopped.  The
+// node has no configured RPC users, so the RPC server is only enabled since
+// the interfaces to listen on are specified and clients authenticate with the
+// cookie alone.
+func TestRPCCookie(t *testing.T) {
+	setLogLevel("CHAN", "off")
+	setLogLevel("RPCS", "off")
+
+	oldCfg := cfg
+	cfg = &config{RPCListeners: []string{"127.0.0.1:19334"}}
+	defer func() {
+		cfg = oldCfg
+	}()
+	if !rpcServerEnabled(cfg) {
+		t.Fatal("RPC server not enabled with only the cookie")
+	}
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	dir, err := ioutil.TempDir("", "ltcdrpccookie")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	cookiePath := filepath.Join(dir, defaultRPCCookieFilename)
+	s, err := newRPCServer(&rpcserverConfig{
+		Chain:       chain,
+		ChainParams: &chaincfg.RegressionNetParams,
+		CookiePath:  cookiePath,
+	})
+	if err != nil {
+		t.Fatalf("newRPCServer: unexpected error: %v", err)
+	}
+
+	info, err := os.Stat(cookiePath)
+	if err != nil {
+		t.Fatalf("unable to stat cookie: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+		t.Fatalf("cookie permissions: got %v, want %v",
+			info.Mode().Perm(), os.FileMode(0600))
+	}
+	cookie, err := ioutil.ReadFile(cookiePath)
+	if err != nil {
+		t.Fatalf("unable to read cookie: %v", err)
+	}
+	if !strings.HasPrefix(string(cookie), rpcCookieUser+":") ||
+		len(cookie) != len(rpcCookieUser)+1+64 {
+
+		t.Fatalf("unexpected cookie %q", cookie)
+	}
+
+	// The credentials of the cookie grant full access, unlike others.
+	checkAuth := func(login string) (bool, rpcScope, error) {
+		r, err := http.NewRequest("POST", "/", nil)
+		if err != nil {
+			t.Fatalf("unable to create request: %v", err)
+		}
+		r.Header.Set("Authorization", "Basic "+
+			base64.StdEncoding.EncodeToString([]byte(login)))
+		return s.checkAuth(r, true)
+	}
+	authenticated, scope, err := checkAuth(string(cookie))
+	if err != nil || !authenticated || scope != nil {
+		t.Fatalf("cookie: got authenticated %v, scope %v, error %v",
+			authenticated, scope, err)
+	}
+	authenticated, _, err = checkAuth(rpcCookieUser + ":wrong")
+	if err == nil || authenticated {
+		t.Fatal("wrong cookie password was authenticated")
+	}
+	authenticated, _, err = checkAuth(":")
+	if err == nil || authenticated {
+		t.Fatal("empty credentials were authenticated")
+	}
+
+	// Creating the RPC server again regenerates the cookie.
+	s2, err := newRPCServer(&rpcserverConfig{
+		Chain:       chain,
+		ChainParams: &chaincfg.RegressionNetParams,
+		CookiePath:  cookiePath,
+	})
+	if err != nil {
+		t.Fatalf("newRPCServer: unexpected error: %v", err)
+	}
+	cookie2, err := ioutil.ReadFile(cookiePath)
+	if err != nil {
+		t.Fatalf("unable to read cookie: %v", err)
+	}
+	if bytes.Equal(cookie, cookie2) {
+		t.Fatal("cookie was not regenerated")
+	}
+	s.Stop()
+	if _, err := os.Stat(cookiePath); !os.IsNotExist(err) {
+		t.Fatalf("cookie was not removed: %v", err)
+	}
+	s2.Stop()
+}
+
+// TestRPCScopedUsers ensures the RPC users defined with scopes are only
+// permitted to call the methods of their scope.
+func TestRPCScopedUsers(t *testing.T) {
+	setLogLevel("CHAN", "off")
+	setLogLevel("RPCS", "off")
+
+	// Ensure invalid users are rejected.
+	invalid := []string{
+		"user",
+		"user:readonly",
+		":password:readonly",
+		"user::readonly",
+		"user:password:",
+		"user:password:uptime,unknown",
+	}
+	for _, value := range invalid {
+		if _, err := parseRPCScopedUser(value); err == nil {
+			t.Fatalf("parseRPCScopedUser(%q): unexpected success", value)
+		}
+	}
+
+	// Define a user permitted to call a list of methods and a user with
+	// the readonly role, whose password contains colons.
+	var users []rpcScopedUser
+	for _, value := range []string{
+		"lister:pass:uptime,getblockcount",
+		"reader:pa:ss:readonly",
+	} {
+		user, err := parseRPCScopedUser(value)
+		if err != nil {
+			t.Fatalf("parseRPCScopedUser(%q): unexpected error: %v",
+				value, err)
+		}
+		users = append(users, *user)
+	}
+	if users[1].name != "reader" || users[1].password != "pa:ss" {
+		t.Fatalf("got user %q with password %q, want reader with "+
+			"password pa:ss", users[1].name, users[1].password)
+	}
+
+	oldCfg := cfg
+	cfg = &config{
+		RPCLimitUser:   "limited",
+		RPCLimitPass:   "pass",
+		rpcScopedUsers: users,
+	}
+	defer func() {
+		cfg = oldCfg
+	}()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	s, err := newRPCServer(&rpcserverConfig{
+		Chain:       chain,
+		ChainParams: &chaincfg.RegressionNetParams,
+		StartupTime: time.Now().Unix(),
+	})
+	if err != nil {
+		t.Fatalf("newRPCServer: unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		login     string
+		method    string
+		permitted bool
+	}{
+		{"lister:pass", "uptime", true},
+		{"lister:pass", "getblockcount", true},
+		{"lister:pass", "getbestblockhash", false},
+		{"reader:pa:ss", "getblockcount", true},
+		{"reader:pa:ss", "sendrawtransaction", false},
+		{"reader:pa:ss", "stop", false},
+		{"limited:pass", "sendrawtransaction", true},
+		{"limited:pass", "stop", false},
+	}
+	for _, test := range tests {
+		r, err := http.NewRequest("POST", "/", nil)
+		if err != nil {
+			t.Fatalf("unable to create request: %v", err)
+		}
+		r.Header.Set("Authorization", "Basic "+
+			base64.StdEncoding.EncodeToString([]byte(test.login)))
+		authenticated, scope, err := s.checkAuth(r, true)
+		if err != nil || !authenticated {
+			t.Fatalf("%s: unable to authenticate: %v", test.login, err)
+		}
+
+		request := fmt.Sprintf(`{"jsonrpc":"1.0","id":1,"method":"%s",`+
+			`"params":[]}`, test.method)
+		reply := s.processRequest([]byte(request), scope, nil)
+		var response btcjson.Response
+		if err := json.Unmarshal(reply, &response); err != nil {
+			t.Fatalf("%s %s: unable to parse reply %s: %v",
+				test.login, test.method, reply, err)
+		}
+		// The error code must differ from the invalid parameters
+		// code so clients are able to tell the errors apart.
+		forbidden := response.Error != nil &&
+			response.Error.Code == -37 &&
+			response.Error.Message == "method not permitted"
+		if forbidden == test.permitted {
+			t.Fatalf("%s %s: got reply %s, want permitted %v",
+				test.login, test.method, reply, test.permitted)
+		}
+	}
+}
+
+// TestRPCCallTimeout ensures RPC calls whose handlers observe the close channel
+// are cancelled once their timeout elapses, while calls completing in time and
+// calls of disconnected clients are unaffected.
+func TestRPCCallTimeout(t *testing.T) {
+	setLogLevel("CHAN", "off")
+	setLogLevel("RPCS", "off")
+
+	const timeout = 50 * time.Millisecond
+	oldCfg := cfg
+	cfg = &config{
+		RPCTimeout: time.Hour,
+		rpcMethodTimeouts: map[string]time.Duration{
+			"uptime":          0,
+			"waitfornewblock": timeout,
+		},
+	}
+	defer func() {
+		cfg = oldCfg
+	}()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	s, err := newRPCServer(&rpcserverConfig{
+		Chain:       chain,
+		ChainParams: &chaincfg.RegressionNetParams,
+		StartupTime: time.Now().Unix(),
+	})
+	if err != nil {
+		t.Fatalf("newRPCServer: unexpected error: %v", err)
+	}
+
+	// Waiting for a new block without a timeout of its own never completes
+	// unless the call is cancelled at its deadline.
+	waitCmd := &parsedRPCCmd{
+		method: "waitfornewblock",
+		cmd:    &btcjson.WaitForNewBlockCmd{Timeout: btcjson.Int64(0)},
+	}
+	start := time.Now()
+	_, err = s.standardCmdResult(waitCmd, nil)
+	elapsed := time.Since(start)
+	jsonErr, ok := err.(*btcjson.RPCError)
+	if !ok || jsonErr.Code != btcjson.ErrRPCMisc ||
+		!strings.Contains(jsonErr.Message, "timed out") {
+
+		t.Fatalf("waitfornewblock: got error %v, want timeout", err)
+	}
+	if elapsed < timeout || elapsed > 5*time.Second {
+		t.Fatalf("waitfornewblock was cancelled after %v, want %v",
+			elapsed, timeout)
+	}
+
+	// A client disconnecting before the deadline is not reported as a
+	// timeout.
+	closeChan := make(chan struct{})
+	close(closeChan)
+	_, err = s.standardCmdResult(waitCmd, closeChan)
+	if err != ErrClientQuit {
+		t.Fatalf("waitfornewblock: got error %v, want %v", err,
+			ErrClientQuit)
+	}
+
+	// Calls completing in time return their result.
+	cfg.RPCTimeout = timeout
+	for _, method := range []string{"uptime", "getblockcount"} {
+		result, err := s.standardCmdResult(&parsedRPCCmd{
+			method: method,
+			cmd:    struct{}{},
+		}, nil)
+		if err != nil || result == nil {
+			t.Fatalf("%s: got result %v, error %v", method, result,
+				err)
+		}
+	}
+}
+
+// TestHandleDebugLevel ensures the debuglevel RPC changes the levels of the
+// requested subsystems, that subsequent log calls honor them, and that it
+// returns the resulting levels of all subsystems.
+func TestHandleDebugLevel(t *testing.T) {
+	// Log to a buffer from a test subsystem and restore the levels of all
+	// subsystems once done since they are package globals.
+	var buf bytes.Buffer
+	testLog := btclog.NewBackend(&buf).Logger("TEST")
+	testLog.SetLevel(btclog.LevelInfo)
+	levels := make(map[string]btclog.Level)
+	for subsysID, logger := range subsystemLoggers {
+		levels[subsysID] = logger.Level()
+	}
+	subsystemLoggers["TEST"] = testLog
+	defer func() {
+		delete(subsystemLoggers, "TEST")
+		for subsysID, level := range levels {
+			subsystemLoggers[subsysID].SetLevel(level)
+		}
+	}()
+
+	debugLevel := func(levelSpec string) (string, error) {
+		cmd := btcjson.NewDebugLevelCmd(levelSpec)
+		result, err := handleDebugLevel(nil, cmd, nil)
+		if err != nil {
+			return "", err
+		}
+		return result.(string), nil
+	}
+
+	testLog.Debug("hidden")
+	result, err := debugLevel("TEST=debug,RPCS=critical")
+	if err != nil {
+		t.Fatalf("debuglevel: %v", err)
+	}
+	testLog.Debug("shown")
+	if got := buf.String(); strings.Contains(got, "hidden") ||
+		!strings.Contains(got, "[DBG] TEST: shown") {
+
+		t.Fatalf("unexpected log output after raising the level: %q", got)
+	}
+	if !strings.Contains(result, ",RPCS=critical,") ||
+		!strings.Contains(result, ",TEST=debug,") {
+
+		t.Fatalf("unexpected levels %q", result)
+	}
+	if pairs := strings.Split(result, ","); len(pairs) !=
+		len(subsystemLoggers) {
+
+		t.Fatalf("got %d levels, want %d", len(pairs),
+			len(subsystemLoggers))
+	}
+
+	buf.Reset()
+	result, err = debugLevel("TEST=error")
+	if err != nil {
+		t.Fatalf("debuglevel: %v", err)
+	}
+	testLog.Warn("hidden")
+	testLog.Error("shown")
+	if got := buf.String(); strings.Contains(got, "hidden") ||
+		!strings.Contains(got, "[ERR] TEST: shown") {
+
+		t.Fatalf("unexpected log output after lowering the level: %q", got)
+	}
+	if !strings.Contains(result, ",TEST=error,") {
+		t.Fatalf("unexpected levels %q", result)
+	}
+
+	// Invalid subsystems must be rejected with the list of valid ones
+	// without changing the levels of the other pairs.
+	_, err = debugLevel("TEST=trace,NOPE=debug")
+	rpcErr, ok := err.(*btcjson.RPCError)
+	if !ok || rpcErr.Code != btcjson.ErrRPCInvalidParams.Code {
+		t.Fatalf("unexpected error for an invalid subsystem: %v", err)
+	}
+	if !strings.Contains(rpcErr.Message, "[NOPE]") ||
+		!strings.Contains(rpcErr.Message, "CHAN") {
+
+		t.Fatalf("error does not list the valid subsystems: %v", err)
+	}
+	if testLog.Level() != btclog.LevelError {
+		t.Fatalf("level changed to %v by an invalid levelspec",
+			testLog.Level())
+	}
+	if _, err := debugLevel("TEST=loud"); err == nil {
+		t.Fatal("expected an error for an invalid level")
+	}
+}
+
+// TestHandleVerifyChain ensures the verifychain command reports a healthy chain
+// as verified at every level and a chain with a corrupt block as not verified
+// once the level checks the block against the utxo set.
+func TestHandleVerifyChain(t *testing.T) {
+	t.Parallel()
+
+	chain, db, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+	setLogLevel("RPCS", "off")
+	pkScript := []byte{txscript.OP_TRUE}
+	for i := 0; i < 10; i++ {
+		addRegtestBlock(t, chain, pkScript)
+	}
+	s := &rpcServer{cfg: rpcserverConfig{Chain: chain}}
+
+	// verify returns the result of the verifychain command for the passed
+	// level and depth.
+	verify := func(level, depth int32) bool {
+		cmd := btcjson.NewVerifyChainCmd(&level, &depth)
+		result, err := handleVerifyChain(s, cmd, nil)
+		if err != nil {
+			t.Fatalf("verifychain %d %d: %v", level, depth, err)
+		}
+		return result.(bool)
+	}
+
+	for level := int32(0); level <= blockchain.MaxVerifyLevel; level++ {
+		if !verify(level, 0) {
+			t.Fatalf("healthy chain failed verification at level %d",
+				level)
+		}
+	}
+
+	// Remove the coinbase output of the block before the tip from the utxo
+	// set, which is only detected from level 3 onwards and only when the
+	// block is within the requested depth.
+	best := chain.BestSnapshot()
+	block, err := chain.BlockByHeight(best.Height - 1)
+	if err != nil {
+		t.Fatalf("BlockByHeight: %v", err)
+	}
+	err = db.Update(func(dbTx database.Tx) error {
+		utxoBucket := dbTx.Metadata().Bucket([]byte("utxoset"))
+		return utxoBucket.Delete(block.Transactions()[0].Hash()[:])
+	})
+	if err != nil {
+		t.Fatalf("unable to corrupt the utxo set: %v", err)
+	}
+	tests := []struct {
+		level, depth int32
+		want         bool
+	}{
+		{level: 1, depth: 0, want: true},
+		{level: 2, depth: 0, want: true},
+		{level: 3, depth: 1, want: true},
+		{level: 3, depth: 2, want: false},
+		{level: 3, depth: 0, want: false},
+		{level: 4, depth: 0, want: false},
+	}
+	for _, test := range tests {
+		if got := verify(test.level, test.depth); got != test.want {
+			t.Errorf("verifychain %d %d: got %v, want %v",
+				test.level, test.depth, got, test.want)
+		}
+	}
+
+	// A call whose client disconnected is reported as such.
+	closeChan := make(chan struct{})
+	close(closeChan)
+	cmd := btcjson.NewVerifyChainCmd(btcjson.Int32(0), btcjson.Int32(0))
+	if _, err := handleVerifyChain(s, cmd, closeChan); err != ErrClientQuit {
+		t.Fatalf("unexpected error for a disconnected client: %v", err)
+	}
+}
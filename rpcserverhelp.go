@@ -21,12 +21,13 @@ var helpDescsEnUS = map[string]string{
 		"The levelspec can either a debug level or of the form:\n" +
 		"<subsystem>=<level>,<subsystem2>=<level2>,...\n" +
 		"The valid debug levels are trace, debug, info, warn, error, and critical.\n" +
-		"The valid subsystems are AMGR, ADXR, BCDB, BMGR, BTCD, CHAN, DISC, PEER, RPCS, SCRP, SRVR, and TXMP.\n" +
+		"The valid subsystems are ADXR, AMGR, BCDB, BMGR, CHAN, CMGR, DISC, INDX, LTCD, MINR, PEER, RPCS, SCRP, SRVR, and TXMP.\n" +
+		"The levels are only changed when the whole levelspec is valid.\n" +
 		"Finally the keyword 'show' will return a list of the available subsystems.",
 	"debuglevel-levelspec":   "The debug level(s) to use or the keyword 'show'",
 	"debuglevel--condition0": "levelspec!=show",
 	"debuglevel--condition1": "levelspec=show",
-	"debuglevel--result0":    "The string 'Done.'",
+	"debuglevel--result0":    "The resulting levels of all subsystems in the form <subsystem>=<level>,<subsystem2>=<level2>,...",
 	"debuglevel--result1":    "The list of subsystems",
 
 	// AddNodeCmd help.
@@ -34,26 +35,60 @@ var helpDescsEnUS = map[string]string{
 	"addnode-addr":      "IP address and port of the peer to operate on",
 	"addnode-subcmd":    "'add' to add a persistent peer, 'remove' to remove a persistent peer, or 'onetry' to try a single connection to a peer",
 
+	// AddPeerAddressCmd help.
+	"addpeeraddress--synopsis": "Adds an address to the address manager as if it had been learned from a peer.",
+	"addpeeraddress-address":   "The IP address of the peer",
+	"addpeeraddress-port":      "The port of the peer",
+	"addpeeraddress-tried":     "Whether to also mark the address as one which has been connected to successfully",
+
+	// AddPeerAddressResult help.
+	"addpeeraddressresult-success": "Whether the address is known to the address manager",
+
+	// DisconnectNodeCmd help.
+	"disconnectnode--synopsis": "Disconnects a non-persistent peer identified by either its address or its peer id.",
+	"disconnectnode-address":   "IP address and port of the peer to disconnect, or an empty string when disconnecting by peer id",
+	"disconnectnode-nodeid":    "The peer id as reported by getpeerinfo of the peer to disconnect",
+
 	// NodeCmd help.
 	"node--synopsis":     "Attempts to add or remove a peer.",
 	"node-subcmd":        "'disconnect' to remove all matching non-persistent peers, 'remove' to remove a persistent peer, or 'connect' to connect to a peer",
 	"node-target":        "Either the IP address and port of the peer to operate on, or a valid peer ID.",
 	"node-connectsubcmd": "'perm' to make the connected peer a permanent one, 'temp' to try a single connect to a peer",
 
+	// SetBanCmd help.
+	"setban--synopsis": "Attempts to add or remove an IP address or subnet from the ban list.",
+	"setban-subnet":    "The IP address or subnet in CIDR notation to operate on",
+	"setban-subcmd":    "'add' to ban the IP address or subnet, 'remove' to remove the ban",
+	"setban-bantime":   "The number of seconds to ban for, or the unix time to ban until when absolute is set (0 uses the default ban duration)",
+	"setban-absolute":  "Whether or not the ban time is an absolute unix time",
+
+	// ListBannedCmd help.
+	"listbanned--synopsis": "Returns the banned IP addresses and subnets.",
+
+	// ListBannedResult help.
+	"listbannedresult-address":      "The banned IP address or subnet in CIDR notation",
+	"listbannedresult-ban_created":  "The time the ban was created in seconds since 1 Jan 1970 GMT",
+	"listbannedresult-banned_until": "The time the ban expires in seconds since 1 Jan 1970 GMT",
+
+	// ClearBannedCmd help.
+	"clearbanned--synopsis": "Removes all bans.",
+
 	// TransactionInput help.
-	"transactioninput-txid": "The hash of the input transaction",
-	"transactioninput-vout": "The specific output of the input transaction to redeem",
+	"transactioninput-txid":     "The hash of the input transaction",
+	"transactioninput-vout":     "The specific output of the input transaction to redeem",
+	"transactioninput-sequence": "The sequence number of the input, which defaults to the maximum unless replaceable is set or a non-zero locktime is given",
 
 	// CreateRawTransactionCmd help.
 	"createrawtransaction--synopsis": "Returns a new transaction spending the provided inputs and sending to the provided addresses.\n" +
 		"The transaction inputs are not signed in the created transaction.\n" +
 		"The signrawtransaction RPC command provided by wallet must be used to sign the resulting transaction.",
 	"createrawtransaction-inputs":         "The inputs to the transaction",
-	"createrawtransaction-amounts":        "JSON object with the destination addresses as keys and amounts as values",
+	"createrawtransaction-amounts":        "JSON object with the destination addresses as keys and amounts as values, along with an optional data key with hex-encoded data for an OP_RETURN output",
 	"createrawtransaction-amounts--key":   "address",
 	"createrawtransaction-amounts--value": "n.nnn",
-	"createrawtransaction-amounts--desc":  "The destination address as the key and the amount in BTC as the value",
-	"createrawtransaction-locktime":       "Locktime value; a non-zero value will also locktime-activate the inputs",
+	"createrawtransaction-amounts--desc":  "The destination address as the key and the amount in LTC as the value",
+	"createrawtransaction-locktime":       "Locktime value; a non-zero value will also locktime-activate the inputs without an explicit sequence number",
+	"createrawtransaction-replaceable":    "Signal BIP 125 replaceability on all inputs, in which case explicit sequence numbers must signal it as well",
 	"createrawtransaction--result0":       "Hex-encoded bytes of the serialized transaction",
 
 	// ScriptSig help.
@@ -74,12 +109,19 @@ var helpDescsEnUS = map[string]string{
 	"vinprevout-sequence":    "The script sequence number",
 
 	// Vin help.
-	"vin-coinbase":    "The hex-encoded bytes of the signature script (coinbase txns only)",
-	"vin-txid":        "The hash of the origin transaction (non-coinbase txns only)",
-	"vin-vout":        "The index of the output being redeemed from the origin transaction (non-coinbase txns only)",
-	"vin-scriptSig":   "The signature script used to redeem the origin transaction as a JSON object (non-coinbase txns only)",
-	"vin-txinwitness": "The witness used to redeem the input encoded as a string array of its items",
-	"vin-sequence":    "The script sequence number",
+	"vin-coinbase":           "The hex-encoded bytes of the signature script (coinbase txns only)",
+	"vin-txid":               "The hash of the origin transaction (non-coinbase txns only)",
+	"vin-vout":               "The index of the output being redeemed from the origin transaction (non-coinbase txns only)",
+	"vin-scriptSig":          "The signature script used to redeem the origin transaction as a JSON object (non-coinbase txns only)",
+	"vin-txinwitness":        "The witness used to redeem the input encoded as a string array of its items",
+	"vin-sequence":           "The script sequence number",
+	"vin-prevout":            "The output spent by the input as a JSON object (only with getblock verboseprevout=true or getrawtransaction verbose=2)",
+	"vin-prevoutunavailable": "Whether the output spent by the input could not be found (only with getblock verboseprevout=true or getrawtransaction verbose=2)",
+
+	// PrevOutResult help.
+	"prevoutresult-generated":    "Whether the spent output was created by a coinbase transaction",
+	"prevoutresult-value":        "The amount in BTC",
+	"prevoutresult-scriptPubKey": "The public key script of the spent output as a JSON object",
 
 	// ScriptPubKeyResult help.
 	"scriptpubkeyresult-asm":       "Disassembly of the script",
@@ -107,20 +149,180 @@ var helpDescsEnUS = map[string]string{
 	// DecodeScriptResult help.
 	"decodescriptresult-asm":       "Disassembly of the script",
 	"decodescriptresult-reqSigs":   "The number of required signatures",
-	"decodescriptresult-type":      "The type of the script (e.g. 'pubkeyhash')",
-	"decodescriptresult-addresses": "The bitcoin addresses associated with this script",
+	"decodescriptresult-type":      "The type of the script (e.g. 'pubkeyhash', 'witness_v0_scripthash' or 'witness_mweb_pegin')",
+	"decodescriptresult-addresses": "The litecoin addresses associated with this script",
 	"decodescriptresult-p2sh":      "The script hash for use in pay-to-script-hash transactions (only present if the provided redeem script is not already a pay-to-script-hash script)",
+	"decodescriptresult-segwit":    "The segwit program which pays to the script (only present for pubkey, pubkeyhash, multisig and nonstandard scripts without uncompressed public keys)",
+
+	// DecodeScriptSegwit help.
+	"decodescriptsegwit-asm":         "Disassembly of the segwit program",
+	"decodescriptsegwit-hex":         "Hex-encoded segwit program",
+	"decodescriptsegwit-reqSigs":     "The number of required signatures",
+	"decodescriptsegwit-type":        "The type of the segwit program (e.g. 'witness_v0_keyhash')",
+	"decodescriptsegwit-addresses":   "The litecoin addresses associated with the segwit program",
+	"decodescriptsegwit-p2sh-segwit": "The pay-to-script-hash address which wraps the segwit program",
 
 	// DecodeScriptCmd help.
 	"decodescript--synopsis": "Returns a JSON object with information about the provided hex-encoded script.",
 	"decodescript-hexscript": "Hex-encoded script",
 
+	// PSBTScript help.
+	"psbtscript-asm":  "Disassembly of the script",
+	"psbtscript-hex":  "Hex-encoded bytes of the script",
+	"psbtscript-type": "The type of the script (e.g. 'pubkeyhash'), not set for final signature scripts",
+
+	// PSBTBip32Deriv help.
+	"psbtbip32deriv-pubkey":             "The hex-encoded public key",
+	"psbtbip32deriv-master_fingerprint": "The hex-encoded fingerprint of the master key",
+	"psbtbip32deriv-path":               "The derivation path of the public key",
+
+	// PSBTXPub help.
+	"psbtxpub-xpub":               "The extended public key",
+	"psbtxpub-master_fingerprint": "The hex-encoded fingerprint of the master key",
+	"psbtxpub-path":               "The derivation path of the extended public key",
+
+	// PSBTProprietary help.
+	"psbtproprietary-identifier": "The hex-encoded identifier of the proprietary entry",
+	"psbtproprietary-subtype":    "The subtype of the proprietary entry",
+	"psbtproprietary-key":        "The hex-encoded key of the entry including its key type",
+	"psbtproprietary-value":      "The hex-encoded value of the entry",
+
+	// PSBTWitnessUtxo help.
+	"psbtwitnessutxo-amount":       "The amount in LTC",
+	"psbtwitnessutxo-scriptPubKey": "The public key script of the spent output as a JSON object",
+
+	// DecodePSBTInput help.
+	"decodepsbtinput-non_witness_utxo":          "The decoded transaction containing the spent output",
+	"decodepsbtinput-witness_utxo":              "The spent output of a segwit input",
+	"decodepsbtinput-partial_signatures":        "The partial signatures of the input",
+	"decodepsbtinput-partial_signatures--key":   "pubkey",
+	"decodepsbtinput-partial_signatures--value": "signature",
+	"decodepsbtinput-partial_signatures--desc":  "The hex-encoded public key as the key and the hex-encoded signature as the value",
+	"decodepsbtinput-sighash":                   "The signature hash type to sign the input with",
+	"decodepsbtinput-redeem_script":             "The redeem script of the input",
+	"decodepsbtinput-witness_script":            "The witness script of the input",
+	"decodepsbtinput-bip32_derivs":              "The BIP0032 derivations of the public keys of the input",
+	"decodepsbtinput-final_scriptSig":           "The final signature script of the input",
+	"decodepsbtinput-final_scriptwitness":       "The final witness of the input as a string array of its hex-encoded items",
+	"decodepsbtinput-proprietary":               "The entries of the input reserved for proprietary use",
+	"decodepsbtinput-unknown":                   "The entries of the input which are unknown",
+	"decodepsbtinput-unknown--key":              "key",
+	"decodepsbtinput-unknown--value":            "value",
+	"decodepsbtinput-unknown--desc":             "The hex-encoded key as the key and the hex-encoded value as the value",
+
+	// DecodePSBTOutput help.
+	"decodepsbtoutput-redeem_script":  "The redeem script of the output",
+	"decodepsbtoutput-witness_script": "The witness script of the output",
+	"decodepsbtoutput-bip32_derivs":   "The BIP0032 derivations of the public keys of the output",
+	"decodepsbtoutput-proprietary":    "The entries of the output reserved for proprietary use",
+	"decodepsbtoutput-unknown":        "The entries of the output which are unknown",
+	"decodepsbtoutput-unknown--key":   "key",
+	"decodepsbtoutput-unknown--value": "value",
+	"decodepsbtoutput-unknown--desc":  "The hex-encoded key as the key and the hex-encoded value as the value",
+
+	// DecodePSBTResult help.
+	"decodepsbtresult-tx":             "The decoded unsigned transaction",
+	"decodepsbtresult-global_xpubs":   "The extended public keys of the global map",
+	"decodepsbtresult-psbt_version":   "The version of the partially signed transaction",
+	"decodepsbtresult-proprietary":    "The entries of the global map reserved for proprietary use",
+	"decodepsbtresult-unknown":        "The entries of the global map which are unknown",
+	"decodepsbtresult-unknown--key":   "key",
+	"decodepsbtresult-unknown--value": "value",
+	"decodepsbtresult-unknown--desc":  "The hex-encoded key as the key and the hex-encoded value as the value",
+	"decodepsbtresult-inputs":         "The maps of the inputs",
+	"decodepsbtresult-outputs":        "The maps of the outputs",
+	"decodepsbtresult-fee":            "The fee paid by the transaction in LTC (only present if the outputs spent by all inputs are known)",
+
+	// DecodePSBTCmd help.
+	"decodepsbt--synopsis": "Returns a JSON object representing the provided base64-encoded partially signed transaction (BIP0174).",
+	"decodepsbt-psbt":      "Base64-encoded partially signed transaction",
+
+	// AnalyzePSBTMissing help.
+	"analyzepsbtmissing-pubkeys":       "The hash160 of the public keys which are missing",
+	"analyzepsbtmissing-signatures":    "The hash160 of the public keys which signatures are missing for",
+	"analyzepsbtmissing-redeemscript":  "The hash160 of the redeem script which is missing",
+	"analyzepsbtmissing-witnessscript": "The sha256 of the witness script which is missing",
+
+	// AnalyzePSBTInput help.
+	"analyzepsbtinput-has_utxo": "Whether the output spent by the input is known",
+	"analyzepsbtinput-is_final": "Whether the input is finalized",
+	"analyzepsbtinput-missing":  "The data which is missing to finalize the input",
+	"analyzepsbtinput-next":     "The role which needs to process the input next",
+
+	// AnalyzePSBTResult help.
+	"analyzepsbtresult-inputs":            "The analysis of each input",
+	"analyzepsbtresult-estimated_vsize":   "The virtual size of the signed transaction (only present once all inputs are finalized)",
+	"analyzepsbtresult-estimated_feerate": "The fee rate of the signed transaction in LTC/kvB (only present once all inputs are finalized)",
+	"analyzepsbtresult-fee":               "The fee paid by the transaction in LTC (only present if the outputs spent by all inputs are known)",
+	"analyzepsbtresult-next":              "The role which needs to process the partially signed transaction next",
+	"analyzepsbtresult-error":             "The reason the partially signed transaction is not valid",
+
+	// AnalyzePSBTCmd help.
+	"analyzepsbt--synopsis": "Analyzes the provided base64-encoded partially signed transaction (BIP0174) and reports the data which is missing from each input along with the next role (creator, updater, signer, finalizer, or extractor) which needs to process it.\n" +
+		"Since no keys are available, signatures are only checked to be present and are not verified.",
+	"analyzepsbt-psbt": "Base64-encoded partially signed transaction",
+
+	// DeriveAddressesCmd help.
+	"deriveaddresses--synopsis": "Derives the addresses described by an output descriptor.\n" +
+		"The pkh(KEY), wpkh(KEY), sh(wpkh(KEY)), and addr(ADDRESS) descriptors are supported, where KEY is a hex-encoded public key or an extended public key with an optional unhardened derivation path, which may end in '*' to derive a range of addresses.\n" +
+		"The descriptor must include its checksum.",
+	"deriveaddresses-descriptor": "The output descriptor, including its checksum",
+	"deriveaddresses-range":      "The end index or the [begin, end] pair of child indexes to derive addresses for, which is required for ranged descriptors and not allowed otherwise",
+	"deriveaddresses--result0":   "The derived addresses",
+
+	// DescriptorRange help.
+	"descriptorrange-begin": "The first child index to derive an address for",
+	"descriptorrange-end":   "The last child index to derive an address for",
+
+	// EstimateFeeCmd help.
+	"estimatefee--synopsis": "Estimates the fee per kilobyte, in bitcoins, needed for a transaction to be confirmed within a given number of blocks.\n" +
+		"Returns -1 if not enough transactions and blocks have been observed to make an estimate.",
+	"estimatefee-numblocks": "The number of blocks within which the transaction should be confirmed (maximum 25)",
+	"estimatefee--result0":  "The estimated fee per kilobyte in bitcoins, or -1",
+
+	// EstimateSmartFeeCmd help.
+	"estimatesmartfee--synopsis":    "Estimates the fee per kilobyte, in bitcoins, needed for a transaction to be confirmed within a given number of blocks.",
+	"estimatesmartfee-conftarget":   "The number of blocks within which the transaction should be confirmed (1 - 1008)",
+	"estimatesmartfee-estimatemode": "The estimate mode (UNSET, ECONOMICAL or CONSERVATIVE); economical estimates only consider recent blocks and may be lower than conservative ones",
+
+	// EstimateSmartFeeResult help.
+	"estimatesmartfeeresult-feerate": "The estimated fee per kilobyte in bitcoins, or -1 if there is not enough data to make an estimate",
+	"estimatesmartfeeresult-errors":  "Errors encountered while making the estimate",
+	"estimatesmartfeeresult-blocks":  "The number of blocks the estimate is for, which may be more than the requested target",
+
 	// GenerateCmd help
 	"generate--synopsis": "Generates a set number of blocks (simnet or regtest only) and returns a JSON\n" +
 		" array of their hashes.",
 	"generate-numblocks": "Number of blocks to generate",
 	"generate--result0":  "The hashes, in order, of blocks generated by the call",
 
+	// GenerateBlockCmd help
+	"generateblock--synopsis": "Mines a block containing exactly the given transactions after a coinbase paying to the given address (simnet or regtest only)\n" +
+		"and submits it to the network.",
+	"generateblock-output":       "The address the coinbase of the block pays to",
+	"generateblock-transactions": "Transactions to include in the block, in order, each given as the hash of a transaction in the memory pool or as a hex-encoded serialized transaction",
+	"generateblock-includehex":   "Whether or not to include the hex-encoded serialized block in the result",
+
+	// GenerateBlockResult help
+	"generateblockresult-hash": "The hash of the generated block",
+	"generateblockresult-hex":  "The hex-encoded serialized block (only when includehex is true)",
+
+	// GenerateToAddressCmd help
+	"generatetoaddress--synopsis": "Generates a set number of blocks paying to the given address (simnet or regtest only) and returns a JSON\n" +
+		" array of their hashes.",
+	"generatetoaddress-numblocks": "Number of blocks to generate",
+	"generatetoaddress-address":   "The address the generated blocks pay to",
+	"generatetoaddress-maxtries":  "The maximum number of hashes to try for each block",
+	"generatetoaddress--result0":  "The hashes, in order, of blocks generated by the call",
+
+	// GenerateToDescriptorCmd help
+	"generatetodescriptor--synopsis": "Generates a set number of blocks paying to the output of the given descriptor (simnet or regtest only) and returns a JSON\n" +
+		" array of their hashes.",
+	"generatetodescriptor-numblocks":  "Number of blocks to generate",
+	"generatetodescriptor-descriptor": "The descriptor of the output the generated blocks pay to, which must not be ranged",
+	"generatetodescriptor-maxtries":   "The maximum number of hashes to try for each block",
+	"generatetodescriptor--result0":   "The hashes, in order, of blocks generated by the call",
+
 	// GetAddedNodeInfoResultAddr help.
 	"getaddednodeinforesultaddr-address":   "The ip address for this DNS entry",
 	"getaddednodeinforesultaddr-connected": "The connection 'direction' (inbound/outbound/false)",
@@ -138,6 +340,17 @@ var helpDescsEnUS = map[string]string{
 	"getaddednodeinfo--condition1": "dns=true",
 	"getaddednodeinfo--result0":    "List of added peers",
 
+	// GetAddrManInfoCmd help.
+	"getaddrmaninfo--synopsis":       "Returns the number of addresses in the new and tried tables of the address manager for each network (ipv4, ipv6, onion, cjdns and i2p) and across all networks (all_networks).",
+	"getaddrmaninfo--result0--desc":  "Address counts keyed by the network",
+	"getaddrmaninfo--result0--key":   "Network",
+	"getaddrmaninfo--result0--value": "Object containing the address counts of the network",
+
+	// GetAddrManInfoResult help.
+	"getaddrmaninforesult-new":   "The number of addresses in the new table, which have not been connected to successfully",
+	"getaddrmaninforesult-tried": "The number of addresses in the tried table, which have been connected to successfully",
+	"getaddrmaninforesult-total": "The total number of addresses in both tables",
+
 	// GetBestBlockResult help.
 	"getbestblockresult-hash":   "Hex-encoded bytes of the best block hash",
 	"getbestblockresult-height": "Height of the best block",
@@ -151,10 +364,12 @@ var helpDescsEnUS = map[string]string{
 	"getbestblockhash--result0":  "The hex-encoded block hash",
 
 	// GetBlockCmd help.
-	"getblock--synopsis":   "Returns information about a block given its hash.",
-	"getblock-hash":        "The hash of the block",
-	"getblock-verbose":     "Specifies the block is returned as a JSON object instead of hex-encoded string",
-	"getblock-verbosetx":   "Specifies that each transaction is returned as a JSON object and only applies if the verbose flag is true (ltcd extension)",
+	"getblock--synopsis": "Returns information about a block given its hash.",
+	"getblock-hash":      "The hash of the block",
+	"getblock-verbose":   "Specifies the block is returned as a JSON object instead of hex-encoded string",
+	"getblock-verbosetx": "Specifies that each transaction is returned as a JSON object and only applies if the verbose flag is true (ltcd extension)",
+	"getblock-verboseprevout": "Specifies that each transaction is returned as a JSON object which includes its fee and the outputs spent by its inputs and only applies if the verbose flag is true (ltcd extension).\n" +
+		"Spent outputs which were not created in the same block are loaded via the transaction index, so they are unavailable when it is disabled or the blocks containing them have been pruned",
 	"getblock--condition0": "verbose=false",
 	"getblock--condition1": "verbose=true",
 	"getblock--result0":    "Hex-encoded bytes of the serialized block",
@@ -163,27 +378,24 @@ var helpDescsEnUS = map[string]string{
 	"getblockchaininfo--synopsis": "Returns information about the current blockchain state and the status of any active soft-fork deployments.",
 
 	// GetBlockChainInfoResult help.
-	"getblockchaininforesult-chain":                 "The name of the chain the daemon is on (testnet, mainnet, etc)",
-	"getblockchaininforesult-blocks":                "The number of blocks in the best known chain",
-	"getblockchaininforesult-headers":               "The number of headers that we've gathered for in the best known chain",
-	"getblockchaininforesult-bestblockhash":         "The block hash for the latest block in the main chain",
-	"getblockchaininforesult-difficulty":            "The current chain difficulty",
-	"getblockchaininforesult-mediantime":            "The median time from the PoV of the best block in the chain",
-	"getblockchaininforesult-verificationprogress":  "An estimate for how much of the best chain we've verified",
-	"getblockchaininforesult-pruned":                "A bool that indicates if the node is pruned or not",
-	"getblockchaininforesult-pruneheight":           "The lowest block retained in the current pruned chain",
-	"getblockchaininforesult-chainwork":             "The total cumulative work in the best chain",
-	"getblockchaininforesult-softforks":             "The status of the super-majority soft-forks",
-	"getblockchaininforesult-bip9_softforks":        "JSON object describing active BIP0009 deployments",
-	"getblockchaininforesult-bip9_softforks--key":   "bip9_softforks",
-	"getblockchaininforesult-bip9_softforks--value": "An object describing a particular BIP009 deployment",
-	"getblockchaininforesult-bip9_softforks--desc":  "The status of any defined BIP0009 soft-fork deployments",
-
-	// SoftForkDescription help.
-	"softforkdescription-reject":  "The current activation status of the softfork",
-	"softforkdescription-version": "The block version that signals enforcement of this softfork",
-	"softforkdescription-id":      "The string identifier for the soft fork",
-	"-status":                     "A bool which indicates if the soft fork is active",
+	"getblockchaininforesult-chain":                "The name of the chain the daemon is on (testnet, mainnet, etc)",
+	"getblockchaininforesult-blocks":               "The number of blocks in the best known chain",
+	"getblockchaininforesult-headers":              "The number of headers that we've gathered for in the best known chain",
+	"getblockchaininforesult-bestblockhash":        "The block hash for the latest block in the main chain",
+	"getblockchaininforesult-difficulty":           "The current chain difficulty",
+	"getblockchaininforesult-mediantime":           "The median time from the PoV of the best block in the chain",
+	"getblockchaininforesult-verificationprogress": "An estimate for how much of the best chain we've verified",
+	"getblockchaininforesult-initialblockdownload": "Whether or not the initial block download is in progress",
+	"getblockchaininforesult-pruned":               "A bool that indicates if the node is pruned or not",
+	"getblockchaininforesult-pruneheight":          "The lowest block retained in the current pruned chain",
+	"getblockchaininforesult-chainwork":            "The total cumulative work in the best chain",
+	"getblockchaininforesult-orphanblocks":         "The number of orphan blocks held while waiting for their parents",
+	"getblockchaininforesult-softforks":            "JSON object describing the status of the soft-forks",
+	"getblockchaininforesult-softforks--key":       "name",
+	"getblockchaininforesult-softforks--value":     "object",
+	"getblockchaininforesult-softforks--desc": "The name of the soft-fork along with its type ('buried' or 'bip9'), whether it is active and the height it is enforced from (buried or active soft-forks only). " +
+		"BIP0009 soft-forks also report a bip9 object with the status ('defined', 'started', 'locked_in', 'active' or 'failed'), bit, start_time, timeout and the height the status was reached since, " +
+		"along with the statistics of the blocks signalling in the current retarget window (period, threshold, elapsed, count and possible) while the status is 'started'",
 
 	// TxRawResult help.
 	"txrawresult-hex":           "Hex-encoded transaction",
@@ -199,6 +411,7 @@ var helpDescsEnUS = map[string]string{
 	"txrawresult-size":          "The size of the transation in bytes",
 	"txrawresult-vsize":         "The virtual size of the transaction in bytes",
 	"txrawresult-hash":          "The wtxid of the transaction",
+	"txrawresult-fee":           "The fee of the transaction in BTC (only with getblock verboseprevout=true or getrawtransaction verbose=2 when all spent outputs are available)",
 
 	// SearchRawTransactionsResult help.
 	"searchrawtransactionsresult-hex":           "Hex-encoded transaction",
@@ -238,6 +451,15 @@ var helpDescsEnUS = map[string]string{
 	"getblockcount--synopsis": "Returns the number of blocks in the longest block chain.",
 	"getblockcount--result0":  "The current block count",
 
+	// GetBlockFilterCmd help.
+	"getblockfilter--synopsis":  "Returns a block's committed filter and filter header given its hash.",
+	"getblockfilter-blockhash":  "The hash of the block",
+	"getblockfilter-filtertype": "The type of committed filter to return (basic)",
+
+	// GetBlockFilterResult help.
+	"getblockfilterresult-filter": "The block's committed filter as a hex-encoded string",
+	"getblockfilterresult-header": "The block's committed filter header",
+
 	// GetBlockHashCmd help.
 	"getblockhash--synopsis": "Returns hash of the block in best block chain at the given height.",
 	"getblockhash-index":     "The block height",
@@ -251,6 +473,74 @@ var helpDescsEnUS = map[string]string{
 	"getblockheader--condition1": "verbose=true",
 	"getblockheader--result0":    "The block header hash",
 
+	// GetBlockHeadersCmd help.
+	"getblockheaders--synopsis": "Returns consecutive block headers of the main chain starting with the header of the given block.\n" +
+		"At most 2000 headers are returned per request, so callers needing more request them again starting from the last header returned.",
+	"getblockheaders-hash":        "The hash of the first block",
+	"getblockheaders-count":       "The number of headers to return (capped at 2000)",
+	"getblockheaders-verbose":     "Specifies the block headers are returned as JSON objects instead of hex-encoded strings",
+	"getblockheaders--condition0": "verbose=false",
+	"getblockheaders--condition1": "verbose=true",
+	"getblockheaders--result0":    "The serialized block headers",
+
+	// GetBlockStatsCmd help.
+	"getblockstats--synopsis": "Returns statistics about the transactions in a block of the main chain.\n" +
+		"Statistics involving fees require the transaction index to be enabled (--txindex).",
+	"getblockstats-hashorheight":    "The hash or the height of the block",
+	"getblockstats-stats":           "The names of the statistics to return (default: all statistics)",
+	"hashorheight-value":            "The block hash string or the block height",
+	"getblockstats--condition0":     "stats not specified",
+	"getblockstats--condition1":     "stats specified",
+	"getblockstats--result1--desc":  "The requested statistics keyed by their names",
+	"getblockstats--result1--key":   "The name of the statistic",
+	"getblockstats--result1--value": "The value of the statistic",
+
+	// GetBlockStatsResult help.
+	"getblockstatsresult-avgfee":              "The average fee of the transactions in litoshi",
+	"getblockstatsresult-avgfeerate":          "The average fee rate of the transactions in litoshi per virtual byte",
+	"getblockstatsresult-avgtxsize":           "The average size of the transactions in bytes",
+	"getblockstatsresult-blockhash":           "The hash of the block",
+	"getblockstatsresult-feerate_percentiles": "The fee rates at the 10th, 25th, 50th, 75th and 90th percentiles of the transaction weight in litoshi per virtual byte",
+	"getblockstatsresult-height":              "The height of the block",
+	"getblockstatsresult-ins":                 "The number of inputs, excluding the coinbase",
+	"getblockstatsresult-maxfee":              "The maximum fee of the transactions in litoshi",
+	"getblockstatsresult-maxfeerate":          "The maximum fee rate of the transactions in litoshi per virtual byte",
+	"getblockstatsresult-maxtxsize":           "The maximum size of the transactions in bytes",
+	"getblockstatsresult-medianfee":           "The median fee of the transactions in litoshi",
+	"getblockstatsresult-mediantime":          "The median time of the block and the blocks before it",
+	"getblockstatsresult-mediantxsize":        "The median size of the transactions in bytes",
+	"getblockstatsresult-minfee":              "The minimum fee of the transactions in litoshi",
+	"getblockstatsresult-minfeerate":          "The minimum fee rate of the transactions in litoshi per virtual byte",
+	"getblockstatsresult-mintxsize":           "The minimum size of the transactions in bytes",
+	"getblockstatsresult-outs":                "The number of outputs, including the coinbase",
+	"getblockstatsresult-subsidy":             "The block subsidy in litoshi",
+	"getblockstatsresult-swtotal_size":        "The total size of the segwit transactions in bytes",
+	"getblockstatsresult-swtotal_weight":      "The total weight of the segwit transactions",
+	"getblockstatsresult-swtxs":               "The number of segwit transactions",
+	"getblockstatsresult-time":                "The block timestamp",
+	"getblockstatsresult-total_out":           "The total amount of the outputs, excluding the coinbase, in litoshi",
+	"getblockstatsresult-total_size":          "The total size of the transactions, excluding the coinbase, in bytes",
+	"getblockstatsresult-total_weight":        "The total weight of the transactions, excluding the coinbase",
+	"getblockstatsresult-totalfee":            "The total fee of the transactions in litoshi",
+	"getblockstatsresult-txs":                 "The number of transactions, including the coinbase",
+	"getblockstatsresult-utxo_increase":       "The change in the number of unspent transaction outputs",
+	"getblockstatsresult-utxo_size_inc":       "The change in the size of the unspent transaction output set in bytes",
+
+	// GetChainTxStatsCmd help.
+	"getchaintxstats--synopsis": "Returns statistics about the total number and rate of transactions in the main chain.",
+	"getchaintxstats-numblocks": "The size of the window in number of blocks (default: the number of blocks in one month)",
+	"getchaintxstats-blockhash": "The hash of the block that ends the window (default: the best block)",
+
+	// GetChainTxStatsResult help.
+	"getchaintxstatsresult-time":                      "The timestamp of the final block in the window",
+	"getchaintxstatsresult-txcount":                   "The total number of transactions in the main chain up to the final block in the window",
+	"getchaintxstatsresult-window_final_block_hash":   "The hash of the final block in the window",
+	"getchaintxstatsresult-window_final_block_height": "The height of the final block in the window",
+	"getchaintxstatsresult-window_block_count":        "The size of the window in number of blocks",
+	"getchaintxstatsresult-window_tx_count":           "The number of transactions in the window (only present when the window is not empty)",
+	"getchaintxstatsresult-window_interval":           "The elapsed time in the window in seconds (only present when the window is not empty)",
+	"getchaintxstatsresult-txrate":                    "The average number of transactions per second in the window (only present when the window interval is positive)",
+
 	// GetBlockHeaderVerboseResult help.
 	"getblockheaderverboseresult-hash":              "The hash of the block (same as provided)",
 	"getblockheaderverboseresult-confirmations":     "The number of confirmations",
@@ -275,6 +565,9 @@ var helpDescsEnUS = map[string]string{
 	"templaterequest-target":       "The desired target for the block template (this parameter is ignored)",
 	"templaterequest-data":         "Hex-encoded block data (only for mode=proposal)",
 	"templaterequest-workid":       "The server provided workid if provided in block template (not applicable)",
+	"templaterequest-checkpow":     "Also check the proof of work of the proposed block (only for mode=proposal)",
+	"templaterequest-mustinclude":  "The ids of memory pool transactions the template must include along with the transactions they depend on, regardless of their fees",
+	"templaterequest-exclude":      "The ids of memory pool transactions the template must leave out along with the transactions which depend on them",
 
 	// GetBlockTemplateResultTx help.
 	"getblocktemplateresulttx-data":    "Hex-encoded transaction data (byte-for-byte)",
@@ -313,6 +606,11 @@ var helpDescsEnUS = map[string]string{
 	"getblocktemplateresult-reject-reason":              "Reason the proposal was invalid as-is (only applies to proposal responses)",
 	"getblocktemplateresult-default_witness_commitment": "The witness commitment itself. Will be populated if the block has witness data",
 	"getblocktemplateresult-weightlimit":                "The current limit on the max allowed weight of a block",
+	"getblocktemplateresult-vbavailable":                "The pending BIP0009 deployments which are signalled by the version",
+	"getblocktemplateresult-vbavailable--key":           "name",
+	"getblocktemplateresult-vbavailable--value":         "n",
+	"getblocktemplateresult-vbavailable--desc":          "The names of the started or locked in deployments as the keys and their version bits as the values",
+	"getblocktemplateresult-vbrequired":                 "Bit mask of the version bits which are forced to be signalled regardless of the state of their deployments",
 
 	// GetBlockTemplateCmd help.
 	"getblocktemplate--synopsis": "Returns a JSON object with information necessary to construct a block to mine or accepts a proposal to validate.\n" +
@@ -324,18 +622,62 @@ var helpDescsEnUS = map[string]string{
 	"getblocktemplate--result1":    "An error string which represents why the proposal was rejected or nothing if accepted",
 
 	// GetCFilterCmd help.
-	"getcfilter--synopsis":   "Returns a block's committed filter given its hash.",
-	"getcfilter-hash":        "The hash of the block",
-	"getcfilter--result0":    "The block's committed filter",
+	"getcfilter--synopsis":  "Returns a block's committed filter given its hash.",
+	"getcfilter-hash":       "The hash of the block",
+	"getcfilter-filtertype": "The type of committed filter to return (basic)",
+	"getcfilter--result0":   "The block's committed filter as a hex-encoded string",
+
+	// GetCFilterHeaderCmd help.
+	"getcfilterheader--synopsis":  "Returns a block's committed filter header given its hash.",
+	"getcfilterheader-hash":       "The hash of the block",
+	"getcfilterheader-filtertype": "The type of committed filter header to return (basic)",
+	"getcfilterheader--result0":   "The block's committed filter header",
 
 	// GetConnectionCountCmd help.
 	"getconnectioncount--synopsis": "Returns the number of active connections to other peers.",
 	"getconnectioncount--result0":  "The number of connections",
 
+	// GetCacheInfoCmd help.
+	"getcacheinfo--synopsis": "Returns the statistics of the signature verification and script execution caches.",
+
+	// CacheInfoResult help.
+	"cacheinforesult-entries":    "The number of entries in the cache",
+	"cacheinforesult-maxentries": "The maximum number of entries in the cache",
+	"cacheinforesult-hits":       "The number of lookups which found an entry since the server started",
+	"cacheinforesult-misses":     "The number of lookups which did not find an entry since the server started",
+
+	// GetCacheInfoResult help.
+	"getcacheinforesult-sigcache":    "The statistics of the signature verification cache",
+	"getcacheinforesult-scriptcache": "The statistics of the script execution cache, which holds the transaction inputs whose scripts were found valid by the mempool",
+
 	// GetCurrentNetCmd help.
 	"getcurrentnet--synopsis": "Get bitcoin network the server is running on.",
 	"getcurrentnet--result0":  "The network identifer",
 
+	// GetDeploymentInfoCmd help.
+	"getdeploymentinfo--synopsis": "Returns the status of the soft-fork deployments for the block after the provided block in the main chain, " +
+		"including the signalling statistics of the BIP0009 deployments in the retarget window of that block.",
+	"getdeploymentinfo-blockhash": "The hash of the block to report the deployments after (default: the best block)",
+
+	// GetDeploymentInfoResult help.
+	"getdeploymentinforesult-hash":               "The hash of the block the deployments are reported after",
+	"getdeploymentinforesult-height":             "The height of the block the deployments are reported after",
+	"getdeploymentinforesult-deployments":        "JSON object describing the status of the soft-fork deployments",
+	"getdeploymentinforesult-deployments--key":   "name",
+	"getdeploymentinforesult-deployments--value": "object",
+	"getdeploymentinforesult-deployments--desc":  "The name of the soft-fork along with its status in the same form as the softforks of getblockchaininfo",
+
+	// GetDescriptorInfoCmd help.
+	"getdescriptorinfo--synopsis":  "Analyzes an output descriptor and returns its canonical form along with its checksum.",
+	"getdescriptorinfo-descriptor": "The output descriptor, with or without a checksum",
+
+	// GetDescriptorInfoResult help.
+	"getdescriptorinforesult-descriptor":     "The canonical form of the descriptor including its checksum",
+	"getdescriptorinforesult-checksum":       "The checksum of the descriptor",
+	"getdescriptorinforesult-isrange":        "Whether or not the descriptor is ranged",
+	"getdescriptorinforesult-issolvable":     "Whether or not the descriptor describes everything needed to spend its outputs other than the private keys",
+	"getdescriptorinforesult-hasprivatekeys": "Whether or not the descriptor contains private keys, which is always false since they are not supported",
+
 	// GetDifficultyCmd help.
 	"getdifficulty--synopsis": "Returns the proof-of-work difficulty as a multiple of the minimum difficulty.",
 	"getdifficulty--result0":  "The difficulty",
@@ -384,29 +726,85 @@ var helpDescsEnUS = map[string]string{
 	"getheaders-hashstop":      "Block hash to stop including block headers for; if not found, all headers to the latest known block are returned.",
 	"getheaders--result0":      "Serialized block headers of all located blocks, limited to some arbitrary maximum number of hashes (currently 2000, which matches the wire protocol headers message, but this is not guaranteed)",
 
+	// GetIndexInfoCmd help.
+	"getindexinfo--synopsis":       "Returns the sync status of the enabled indexes.",
+	"getindexinfo-indexname":       "Only return the status of the index with this name (txindex, addrindex or cfindex)",
+	"getindexinfo--result0--desc":  "Index status objects keyed by the index name",
+	"getindexinfo--result0--key":   "Index name",
+	"getindexinfo--result0--value": "Object containing the sync status of the index",
+
+	// GetIndexInfoResult help.
+	"getindexinforesult-synced":            "Whether or not the index has processed the best block of the chain",
+	"getindexinforesult-best_block_height": "The height of the most recent block processed by the index",
+
 	// GetInfoCmd help.
 	"getinfo--synopsis": "Returns a JSON object containing various state info.",
 
+	// GetMempoolAncestorsCmd help.
+	"getmempoolancestors--synopsis":   "Returns all in-mempool ancestors of a transaction currently in the memory pool.",
+	"getmempoolancestors-txid":        "The hash of the transaction",
+	"getmempoolancestors-verbose":     "Returns JSON objects keyed by transaction hash when true or an array of transaction hashes when false",
+	"getmempoolancestors--condition0": "verbose=false",
+	"getmempoolancestors--condition1": "verbose=true",
+	"getmempoolancestors--result0":    "Array of transaction hashes",
+
+	// GetMempoolDescendantsCmd help.
+	"getmempooldescendants--synopsis":   "Returns all in-mempool descendants of a transaction currently in the memory pool.",
+	"getmempooldescendants-txid":        "The hash of the transaction",
+	"getmempooldescendants-verbose":     "Returns JSON objects keyed by transaction hash when true or an array of transaction hashes when false",
+	"getmempooldescendants--condition0": "verbose=false",
+	"getmempooldescendants--condition1": "verbose=true",
+	"getmempooldescendants--result0":    "Array of transaction hashes",
+
+	// GetMempoolEntryCmd help.
+	"getmempoolentry--synopsis": "Returns information about a transaction currently in the memory pool.",
+	"getmempoolentry-txid":      "The hash of the transaction",
+
+	// GetMempoolEntryResult help.
+	"getmempoolentryresult-size":               "Transaction size in bytes",
+	"getmempoolentryresult-fee":                "Transaction fee in bitcoins",
+	"getmempoolentryresult-modifiedfee":        "Transaction fee with fee deltas used for mining priority in bitcoins",
+	"getmempoolentryresult-time":               "Local time transaction entered pool in seconds since 1 Jan 1970 GMT",
+	"getmempoolentryresult-height":             "Block height when transaction entered the pool",
+	"getmempoolentryresult-startingpriority":   "Priority when transaction entered the pool",
+	"getmempoolentryresult-currentpriority":    "Current priority",
+	"getmempoolentryresult-descendantcount":    "Number of in-mempool descendant transactions (including this one)",
+	"getmempoolentryresult-descendantsize":     "Size in bytes of in-mempool descendants (including this one)",
+	"getmempoolentryresult-descendantfees":     "Fees in bitcoins of in-mempool descendants (including this one)",
+	"getmempoolentryresult-ancestorcount":      "Number of in-mempool ancestor transactions (including this one)",
+	"getmempoolentryresult-ancestorsize":       "Size in bytes of in-mempool ancestors (including this one)",
+	"getmempoolentryresult-ancestorfees":       "Fees in bitcoins of in-mempool ancestors (including this one)",
+	"getmempoolentryresult-depends":            "Unconfirmed transactions used as inputs for this transaction",
+	"getmempoolentryresult-bip125-replaceable": "Whether or not this transaction or any of its in-mempool ancestors signal BIP 125 replaceability (yes or no)",
+	"getmempoolentryresult-unbroadcast":        "Whether or not this transaction was submitted through the RPC server and is not yet known to have been received by any peer",
+
 	// GetMempoolInfoCmd help.
 	"getmempoolinfo--synopsis": "Returns memory pool information",
 
 	// GetMempoolInfoResult help.
-	"getmempoolinforesult-bytes": "Size in bytes of the mempool",
-	"getmempoolinforesult-size":  "Number of transactions in the mempool",
+	"getmempoolinforesult-bytes":            "Size in bytes of the mempool",
+	"getmempoolinforesult-size":             "Number of transactions in the mempool",
+	"getmempoolinforesult-orphans":          "Number of transactions in the orphan pool",
+	"getmempoolinforesult-maxmempool":       "Maximum size in bytes of the mempool (0 when it is not limited)",
+	"getmempoolinforesult-mempoolminfee":    "Minimum fee rate in LTC/kB for transactions to be accepted into the mempool, which is raised above minrelaytxfee while the mempool is full",
+	"getmempoolinforesult-minrelaytxfee":    "Minimum fee rate in LTC/kB for transactions to be relayed",
+	"getmempoolinforesult-unbroadcastcount": "Number of transactions submitted through the RPC server which are not yet known to have been received by any peer and are periodically rebroadcast",
 
 	// GetMiningInfoResult help.
 	"getmininginforesult-blocks":             "Height of the latest best block",
+	"getmininginforesult-chain":              "The name of the chain the server is using",
 	"getmininginforesult-currentblocksize":   "Size of the latest best block",
 	"getmininginforesult-currentblockweight": "Weight of the latest best block",
 	"getmininginforesult-currentblocktx":     "Number of transactions in the latest best block",
 	"getmininginforesult-difficulty":         "Current target difficulty",
-	"getmininginforesult-errors":             "Any current errors",
+	"getmininginforesult-errors":             "Any current warnings (deprecated, use warnings)",
 	"getmininginforesult-generate":           "Whether or not server is set to generate coins",
 	"getmininginforesult-genproclimit":       "Number of processors to use for coin generation (-1 when disabled)",
 	"getmininginforesult-hashespersec":       "Recent hashes per second performance measurement while generating coins",
 	"getmininginforesult-networkhashps":      "Estimated network hashes per second for the most recent blocks",
 	"getmininginforesult-pooledtx":           "Number of transactions in the memory pool",
 	"getmininginforesult-testnet":            "Whether or not server is using testnet",
+	"getmininginforesult-warnings":           "Any current warnings such as unknown new rules being activated",
 
 	// GetMiningInfoCmd help.
 	"getmininginfo--synopsis": "Returns a JSON object containing mining-related information.",
@@ -417,6 +815,16 @@ var helpDescsEnUS = map[string]string{
 	"getnetworkhashps-height":    "Perform estimate ending with this height or -1 for current best chain block height",
 	"getnetworkhashps--result0":  "Estimated hashes per second",
 
+	// GetNodeAddressesCmd help.
+	"getnodeaddresses--synopsis": "Returns randomly selected addresses of nodes which have previously been connected to successfully.",
+	"getnodeaddresses-count":     "The maximum number of addresses to return, where at most 2500 and never more than a fraction of all known addresses are returned",
+
+	// GetNodeAddressesResult help.
+	"getnodeaddressesresult-time":     "The last time the node was seen in seconds since 1 Jan 1970 GMT",
+	"getnodeaddressesresult-services": "The services supported by the node",
+	"getnodeaddressesresult-address":  "The address of the node",
+	"getnodeaddressesresult-port":     "The port of the node",
+
 	// GetNetTotalsCmd help.
 	"getnettotals--synopsis": "Returns a JSON object containing network traffic statistics.",
 
@@ -426,56 +834,94 @@ var helpDescsEnUS = map[string]string{
 	"getnettotalsresult-timemillis":     "Number of milliseconds since 1 Jan 1970 GMT",
 
 	// GetPeerInfoResult help.
-	"getpeerinforesult-id":             "A unique node ID",
-	"getpeerinforesult-addr":           "The ip address and port of the peer",
-	"getpeerinforesult-addrlocal":      "Local address",
-	"getpeerinforesult-services":       "Services bitmask which represents the services supported by the peer",
-	"getpeerinforesult-relaytxes":      "Peer has requested transactions be relayed to it",
-	"getpeerinforesult-lastsend":       "Time the last message was received in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-lastrecv":       "Time the last message was sent in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-bytessent":      "Total bytes sent",
-	"getpeerinforesult-bytesrecv":      "Total bytes received",
-	"getpeerinforesult-conntime":       "Time the connection was made in seconds since 1 Jan 1970 GMT",
-	"getpeerinforesult-timeoffset":     "The time offset of the peer",
-	"getpeerinforesult-pingtime":       "Number of microseconds the last ping took",
-	"getpeerinforesult-pingwait":       "Number of microseconds a queued ping has been waiting for a response",
-	"getpeerinforesult-version":        "The protocol version of the peer",
-	"getpeerinforesult-subver":         "The user agent of the peer",
-	"getpeerinforesult-inbound":        "Whether or not the peer is an inbound connection",
-	"getpeerinforesult-startingheight": "The latest block height the peer knew about when the connection was established",
-	"getpeerinforesult-currentheight":  "The current height of the peer",
-	"getpeerinforesult-banscore":       "The ban score",
-	"getpeerinforesult-feefilter":      "The requested minimum fee a transaction must have to be announced to the peer",
-	"getpeerinforesult-syncnode":       "Whether or not the peer is the sync peer",
+	"getpeerinforesult-id":                       "A unique node ID",
+	"getpeerinforesult-addr":                     "The ip address and port of the peer",
+	"getpeerinforesult-addrlocal":                "Local address",
+	"getpeerinforesult-services":                 "Services bitmask which represents the services supported by the peer",
+	"getpeerinforesult-relaytxes":                "Peer has requested transactions be relayed to it",
+	"getpeerinforesult-lastsend":                 "Time the last message was received in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-lastrecv":                 "Time the last message was sent in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-bytessent":                "Total bytes sent",
+	"getpeerinforesult-bytesrecv":                "Total bytes received",
+	"getpeerinforesult-bytessent_per_msg":        "Total bytes sent for each message command",
+	"getpeerinforesult-bytessent_per_msg--key":   "command",
+	"getpeerinforesult-bytessent_per_msg--value": "n",
+	"getpeerinforesult-bytessent_per_msg--desc":  "The total bytes sent in messages with the command, where bytes of messages which could not be read are counted as *other*",
+	"getpeerinforesult-bytesrecv_per_msg":        "Total bytes received for each message command",
+	"getpeerinforesult-bytesrecv_per_msg--key":   "command",
+	"getpeerinforesult-bytesrecv_per_msg--value": "n",
+	"getpeerinforesult-bytesrecv_per_msg--desc":  "The total bytes received in messages with the command, where bytes of messages which could not be read are counted as *other*",
+	"getpeerinforesult-conntime":                 "Time the connection was made in seconds since 1 Jan 1970 GMT",
+	"getpeerinforesult-timeoffset":               "The time offset of the peer",
+	"getpeerinforesult-pingtime":                 "Number of microseconds the last ping took",
+	"getpeerinforesult-minping":                  "Number of microseconds the fastest ping took",
+	"getpeerinforesult-pingwait":                 "Number of microseconds a queued ping has been waiting for a response",
+	"getpeerinforesult-version":                  "The protocol version of the peer",
+	"getpeerinforesult-subver":                   "The user agent of the peer",
+	"getpeerinforesult-inbound":                  "Whether or not the peer is an inbound connection",
+	"getpeerinforesult-startingheight":           "The latest block height the peer knew about when the connection was established",
+	"getpeerinforesult-currentheight":            "The current height of the peer",
+	"getpeerinforesult-banscore":                 "The ban score",
+	"getpeerinforesult-feefilter":                "The requested minimum fee a transaction must have to be announced to the peer",
+	"getpeerinforesult-syncnode":                 "Whether or not the peer is the sync peer",
 
 	// GetPeerInfoCmd help.
 	"getpeerinfo--synopsis": "Returns data about each connected network peer as an array of json objects.",
 
 	// GetRawMempoolVerboseResult help.
-	"getrawmempoolverboseresult-size":             "Transaction size in bytes",
-	"getrawmempoolverboseresult-fee":              "Transaction fee in bitcoins",
-	"getrawmempoolverboseresult-time":             "Local time transaction entered pool in seconds since 1 Jan 1970 GMT",
-	"getrawmempoolverboseresult-height":           "Block height when transaction entered the pool",
-	"getrawmempoolverboseresult-startingpriority": "Priority when transaction entered the pool",
-	"getrawmempoolverboseresult-currentpriority":  "Current priority",
-	"getrawmempoolverboseresult-depends":          "Unconfirmed transactions used as inputs for this transaction",
-	"getrawmempoolverboseresult-vsize":            "The virtual size of a transaction",
+	"getrawmempoolverboseresult-size":               "Transaction size in bytes",
+	"getrawmempoolverboseresult-fee":                "Transaction fee in bitcoins",
+	"getrawmempoolverboseresult-time":               "Local time transaction entered pool in seconds since 1 Jan 1970 GMT",
+	"getrawmempoolverboseresult-height":             "Block height when transaction entered the pool",
+	"getrawmempoolverboseresult-startingpriority":   "Priority when transaction entered the pool",
+	"getrawmempoolverboseresult-currentpriority":    "Current priority",
+	"getrawmempoolverboseresult-depends":            "Unconfirmed transactions used as inputs for this transaction",
+	"getrawmempoolverboseresult-vsize":              "The virtual size of a transaction",
+	"getrawmempoolverboseresult-wtxid":              "The hash of the transaction including its witness data",
+	"getrawmempoolverboseresult-fees":               "The fees of the transaction and of its in-mempool ancestors and descendants",
+	"getrawmempoolverboseresult-bip125-replaceable": "Whether or not this transaction or any of its in-mempool ancestors signal BIP 125 replaceability (yes or no)",
+	"getrawmempoolverboseresult-unbroadcast":        "Whether or not this transaction was submitted through the RPC server and is not yet known to have been received by any peer",
+
+	// MempoolFees help.
+	"mempoolfees-base":       "Transaction fee in LTC",
+	"mempoolfees-modified":   "Transaction fee with fee deltas used for mining priority in LTC",
+	"mempoolfees-ancestor":   "Fees in LTC of in-mempool ancestors (including this one)",
+	"mempoolfees-descendant": "Fees in LTC of in-mempool descendants (including this one)",
+
+	// GetRawMempoolSequenceResult help.
+	"getrawmempoolsequenceresult-txids":            "The hashes of the transactions in the memory pool",
+	"getrawmempoolsequenceresult-mempool_sequence": "The number of times a transaction was added to or removed from the memory pool, which the transaction hashes are current as of",
 
 	// GetRawMempoolCmd help.
-	"getrawmempool--synopsis":   "Returns information about all of the transactions currently in the memory pool.",
-	"getrawmempool-verbose":     "Returns JSON object when true or an array of transaction hashes when false",
-	"getrawmempool--condition0": "verbose=false",
-	"getrawmempool--condition1": "verbose=true",
-	"getrawmempool--result0":    "Array of transaction hashes",
+	"getrawmempool--synopsis":       "Returns information about all of the transactions currently in the memory pool.",
+	"getrawmempool-verbose":         "Returns JSON object when true or an array of transaction hashes when false",
+	"getrawmempool-mempoolsequence": "Returns the transaction hashes along with the mempool sequence when true, which can't be combined with verbose",
+	"getrawmempool--condition0":     "verbose=false, mempoolsequence=false",
+	"getrawmempool--condition1":     "verbose=true",
+	"getrawmempool--condition2":     "mempoolsequence=true",
+	"getrawmempool--result0":        "Array of transaction hashes",
 
 	// GetRawTransactionCmd help.
 	"getrawtransaction--synopsis":   "Returns information about a transaction given its hash.",
 	"getrawtransaction-txid":        "The hash of the transaction",
-	"getrawtransaction-verbose":     "Specifies the transaction is returned as a JSON object instead of a hex-encoded string",
-	"getrawtransaction--condition0": "verbose=false",
-	"getrawtransaction--condition1": "verbose=true",
+	"getrawtransaction-verbose":     "The verbosity level: 0 (or false) returns a hex-encoded string, 1 (or true) returns a JSON object, and 2 returns a JSON object including the fee and the outputs spent by the inputs",
+	"getrawtransaction--condition0": "verbose=0",
+	"getrawtransaction--condition1": "verbose=1 or verbose=2",
 	"getrawtransaction--result0":    "Hex-encoded bytes of the serialized transaction",
 
+	// GetSyncStatusCmd help.
+	"getsyncstatus--synopsis": "Returns the progress of the chain synchronization, including whether headers or blocks are being downloaded.",
+
+	// GetSyncStatusResult help.
+	"getsyncstatusresult-phase":                "The phase of the synchronization: idle (no peer to sync from), headers (downloading the headers up to the next checkpoint), blocks (downloading and validating blocks) or synced",
+	"getsyncstatusresult-headers":              "The height of the best known header",
+	"getsyncstatusresult-blocks":               "The height of the best validated block",
+	"getsyncstatusresult-syncheight":           "The height being synced to, which is the best of the header height and the height advertised by the sync peer",
+	"getsyncstatusresult-downloadwindowstart":  "The height of the first block of the download window",
+	"getsyncstatusresult-downloadwindowend":    "The height of the last requested block of the download window, which is less than the start when no blocks are requested",
+	"getsyncstatusresult-blocksinflight":       "The number of requested blocks which have not been received yet",
+	"getsyncstatusresult-verificationprogress": "An estimate of the fraction of the blocks up to the sync height which have been validated, from 0 to 1",
+
 	// GetTxOutResult help.
 	"gettxoutresult-bestblock":     "The block hash that contains the transaction output",
 	"gettxoutresult-confirmations": "The number of confirmations",
@@ -490,6 +936,51 @@ var helpDescsEnUS = map[string]string{
 	"gettxout-vout":           "The index of the output",
 	"gettxout-includemempool": "Include the mempool when true",
 
+	// GetTxOutProofCmd help.
+	"gettxoutproof--synopsis": "Returns a hex-encoded proof that the given transactions were included in a block.\n" +
+		"The proof is a serialized merkle block as defined by BIP0037.  The block is located via the transaction index unless it is specified.",
+	"gettxoutproof-txids":     "The hashes of the transactions to prove which must all be in the same block",
+	"gettxoutproof-blockhash": "The hash of the block which contains the transactions",
+	"gettxoutproof--result0":  "The hex-encoded proof",
+
+	// GetTxOutSetInfoCmd help.
+	"gettxoutsetinfo--synopsis": "Returns statistics about the unspent transaction output set.\n" +
+		"This iterates the entire set, so it can take quite a while.",
+	"gettxoutsetinfo-hashtype": "The type of hash to calculate for the set (hash_serialized or none to skip calculating it)",
+
+	// GetTxOutSetInfoResult help.
+	"gettxoutsetinforesult-height":               "The height of the best block the set is as of",
+	"gettxoutsetinforesult-bestblock":            "The hash of the best block the set is as of",
+	"gettxoutsetinforesult-transactions":         "The number of transactions with unspent outputs",
+	"gettxoutsetinforesult-txouts":               "The number of unspent transaction outputs",
+	"gettxoutsetinforesult-hash_serialized":      "The double sha256 hash of the serialized set (only when the hash_serialized hash type is requested)",
+	"gettxoutsetinforesult-total_amount":         "The total amount of all unspent outputs in bitcoins",
+	"gettxoutsetinforesult-total_amount_litoshi": "The total amount of all unspent outputs in litoshi",
+
+	// DumpTxOutSetCmd help.
+	"dumptxoutset--synopsis": "Writes a snapshot of the unspent transaction output set as of the best block to a file.\n" +
+		"This iterates the entire set, so it can take quite a while.",
+	"dumptxoutset-path": "The path of the file to write, which must not exist yet (relative to the data directory unless absolute)",
+
+	// DumpTxOutSetResult help.
+	"dumptxoutsetresult-coins_written": "The number of unspent transaction outputs written",
+	"dumptxoutsetresult-base_hash":     "The hash of the block the snapshot is as of",
+	"dumptxoutsetresult-base_height":   "The height of the block the snapshot is as of",
+	"dumptxoutsetresult-path":          "The path of the written file",
+	"dumptxoutsetresult-txoutset_hash": "The double sha256 commitment to the contents of the snapshot",
+
+	// LoadTxOutSetCmd help.
+	"loadtxoutset--synopsis": "Loads a snapshot of the unspent transaction output set written by dumptxoutset into a chain without any blocks after the genesis block.\n" +
+		"Until the block the snapshot is as of is validated, the blocks leading up to it are checked and stored without being connected.\n" +
+		"The snapshot may not be loaded while optional indexes are enabled.",
+	"loadtxoutset-path": "The path of the snapshot file (relative to the data directory unless absolute)",
+
+	// LoadTxOutSetResult help.
+	"loadtxoutsetresult-coins_loaded": "The number of unspent transaction outputs loaded",
+	"loadtxoutsetresult-tip_hash":     "The hash of the block the snapshot is as of",
+	"loadtxoutsetresult-base_height":  "The height of the block the snapshot is as of",
+	"loadtxoutsetresult-path":         "The path of the loaded file",
+
 	// HelpCmd help.
 	"help--synopsis":   "Returns a list of all commands or help for a specified command.",
 	"help-command":     "The command to retrieve help for",
@@ -498,10 +989,57 @@ var helpDescsEnUS = map[string]string{
 	"help--result0":    "List of commands",
 	"help--result1":    "Help for specified command",
 
+	// InvalidateBlockCmd help.
+	"invalidateblock--synopsis": "Marks a block and all of its descendants as invalid, reorganizing to the valid chain with the most work when the block is part of the main chain.\n" +
+		"The block remains invalid across restarts until it is reconsidered with reconsiderblock.",
+	"invalidateblock-blockhash": "The hash of the block to invalidate",
+
 	// PingCmd help.
 	"ping--synopsis": "Queues a ping to be sent to each connected peer.\n" +
 		"Ping times are provided by getpeerinfo via the pingtime and pingwait fields.",
 
+	// PruneBlockChainCmd help.
+	"pruneblockchain--synopsis": "Deletes the blocks in the main chain up to the given height from the database when pruning is enabled.\n" +
+		"The most recent 288 blocks are never pruned and blocks are deleted a block file at a time, so fewer blocks than requested might be pruned.",
+	"pruneblockchain-height":   "The height of the most recent block to prune or, when above 1000000000, a unix timestamp which selects the first block with a timestamp no more than two hours before it",
+	"pruneblockchain--result0": "The height of the most recent pruned block or -1 when no blocks have been pruned",
+
+	// ReconsiderBlockCmd help.
+	"reconsiderblock--synopsis": "Removes the invalid status of a block along with its ancestors and descendants, reorganizing to the valid chain with the most work which might include them.",
+	"reconsiderblock-blockhash": "The hash of the block to reconsider",
+
+	// ScanTxOutSetCmd help.
+	"scantxoutset--synopsis": "Scans the unspent transaction output set for outputs matching the passed descriptors.\n" +
+		"Only a single scan can be in progress at a time.  The status action reports the progress of a scan in progress, or null when there is none, and the abort action requests it to be stopped.",
+	"scantxoutset-action":      "The action to perform (start, status, or abort)",
+	"scantxoutset-scanobjects": "The descriptors to scan for, required for the start action; either descriptor strings or objects with a desc field, where addr(<address>) and raw(<hex script>) descriptors are supported",
+	"scantxoutset--condition0": "action=start",
+	"scantxoutset--condition1": "action=status",
+	"scantxoutset--condition2": "action=abort",
+	"scantxoutset--result2":    "Whether or not a scan in progress was requested to be aborted",
+
+	// ScanObject help.
+	"scanobject-desc": "The output descriptor to scan for",
+
+	// ScanTxOutSetResult help.
+	"scantxoutsetresult-success":      "Whether or not the scan completed without being aborted",
+	"scantxoutsetresult-txouts":       "The number of unspent transaction outputs searched",
+	"scantxoutsetresult-height":       "The height of the best block the scanned outputs are unspent as of",
+	"scantxoutsetresult-bestblock":    "The hash of the best block the scanned outputs are unspent as of",
+	"scantxoutsetresult-unspents":     "The unspent transaction outputs matching the descriptors",
+	"scantxoutsetresult-total_amount": "The total amount of all matching outputs in bitcoins",
+
+	// ScanTxOutSetUnspent help.
+	"scantxoutsetunspent-txid":         "The hash of the transaction containing the output",
+	"scantxoutsetunspent-vout":         "The index of the output",
+	"scantxoutsetunspent-scriptPubKey": "The hex-encoded public key script of the output",
+	"scantxoutsetunspent-desc":         "The descriptor the output matched",
+	"scantxoutsetunspent-amount":       "The amount of the output in bitcoins",
+	"scantxoutsetunspent-height":       "The height of the block containing the output",
+
+	// ScanTxOutSetStatusResult help.
+	"scantxoutsetstatusresult-progress": "The approximate percentage of the unspent transaction output set searched so far",
+
 	// SearchRawTransactionsCmd help.
 	"searchrawtransactions--synopsis": "Returns raw data for transactions involving the passed address.\n" +
 		"Returned transactions are pulled from both the database, and transactions currently in the mempool.\n" +
@@ -517,12 +1055,14 @@ var helpDescsEnUS = map[string]string{
 	"searchrawtransactions-vinextra":    "Specify that extra data from previous output will be returned in vin",
 	"searchrawtransactions-reverse":     "Specifies that the transactions should be returned in reverse chronological order",
 	"searchrawtransactions-filteraddrs": "Address list.  Only inputs or outputs with matching address will be returned",
+	"searchrawtransactions-type":        "The type of transactions to return: send for those spending from the address, receive for those paying to it, or both",
 	"searchrawtransactions--result0":    "Hex-encoded serialized transaction",
 
 	// SendRawTransactionCmd help.
 	"sendrawtransaction--synopsis":     "Submits the serialized, hex-encoded transaction to the local peer and relays it to the network.",
 	"sendrawtransaction-hextx":         "Serialized, hex-encoded signed transaction",
-	"sendrawtransaction-allowhighfees": "Whether or not to allow insanely high fees (ltcd does not yet implement this parameter, so it has no effect)",
+	"sendrawtransaction-maxfeerate":    "Reject the transaction if its fee rate in LTC/kvB is higher than this value (0 to disable), which may also be given as a boolean for compatibility where true disables the check; skipped when the spent outputs are unknown",
+	"sendrawtransaction-maxburnamount": "Reject the transaction if it sends more than this value in LTC to any provably unspendable output such as an OP_RETURN output",
 	"sendrawtransaction--result0":      "The hash of the transaction",
 
 	// SetGenerateCmd help.
@@ -530,6 +1070,23 @@ var helpDescsEnUS = map[string]string{
 	"setgenerate-generate":     "Use true to enable generation, false to disable it",
 	"setgenerate-genproclimit": "The number of processors (cores) to limit generation to or -1 for default",
 
+	// SetMiningFlagsCmd help.
+	"setminingflags--synopsis": "Set the extra data appended to the coinbase flags of generated blocks.\n" +
+		"The data applies to block templates created after the call.",
+	"setminingflags-flags": "The extra data, which may be at most 73 bytes, or an empty string for none",
+
+	// SetVersionBitCmd help.
+	"setversionbit--synopsis": "Force the block version of generated blocks to signal or not signal a BIP0009 version bit regardless of the state of its deployment.\n" +
+		"Forcing a bit which is not used by any known deployment is allowed for testing purposes.",
+	"setversionbit-bit":    "The version bit (0-28)",
+	"setversionbit-signal": "True to signal the bit, false to not signal it, or omitted to signal it only when its deployment is started or locked in",
+
+	// SignMessageWithPrivKeyCmd help.
+	"signmessagewithprivkey--synopsis": "Sign a message with the provided private key.",
+	"signmessagewithprivkey-privkey":   "The WIF-encoded private key to sign the message with",
+	"signmessagewithprivkey-message":   "The message to sign",
+	"signmessagewithprivkey--result0":  "The base-64 encoded compact signature of the message",
+
 	// StopCmd help.
 	"stop--synopsis": "Shutdown ltcd.",
 	"stop--result0":  "The string 'ltcd stopping.'",
@@ -545,12 +1102,70 @@ var helpDescsEnUS = map[string]string{
 	"submitblock--condition1": "Block rejected",
 	"submitblock--result1":    "The reason the block was rejected",
 
+	// SubmitPackageCmd help.
+	"submitpackage--synopsis": "Submits a package of serialized, hex-encoded transactions to the memory pool as a unit and relays them to the network.\n" +
+		"The package must consist of a child transaction and its parents sorted such that every transaction comes after the transactions it spends.\n" +
+		"The fees of the transactions are checked together, so a parent which pays too low a fee on its own is accepted when its child pays enough for both.\n" +
+		"Either all of the transactions are accepted or the package is rejected along with the reason the first failing transaction was rejected.",
+	"submitpackage-package": "An array of serialized, hex-encoded signed transactions",
+
+	// SubmitPackageResult help.
+	"submitpackageresult-tx-results":        "The results for the transactions in the package, including those which were already in the memory pool",
+	"submitpackageresult-tx-results--key":   "txid",
+	"submitpackageresult-tx-results--value": "An object with the txid, virtual size (vsize), and fee in LTC of the transaction",
+	"submitpackageresult-tx-results--desc":  "The result for the transaction with the given hash",
+	"submitpackageresult-package-feerate":   "The fee rate in LTC/kB of the transactions in the package which were added to the memory pool",
+
+	// SubmitPackageTxResult help.
+	"submitpackagetxresult-txid":  "The hash of the transaction",
+	"submitpackagetxresult-vsize": "The virtual size of the transaction",
+	"submitpackagetxresult-fee":   "The fee paid by the transaction in LTC",
+
+	// TestMempoolAcceptCmd help.
+	"testmempoolaccept--synopsis": "Returns whether or not serialized, hex-encoded transactions would be accepted to the memory pool without adding them to it.\n" +
+		"Each transaction must pay its own fees.\n" +
+		"When more than one transaction is given they are treated as a package which must be sorted such that every transaction comes after the transactions it spends,\n" +
+		"in which case each transaction is checked as if the transactions before it which would be accepted had been added to the memory pool.",
+	"testmempoolaccept-rawtxns":    "An array of serialized, hex-encoded signed transactions",
+	"testmempoolaccept-maxfeerate": "Reject transactions whose fee rate is higher than this value in LTC/kB (0 to disable)",
+	"testmempoolaccept--result0":   "The results for the transactions in the same order",
+
+	// TestMempoolAcceptResult help.
+	"testmempoolacceptresult-txid":          "The hash of the transaction",
+	"testmempoolacceptresult-allowed":       "Whether or not the transaction would be accepted to the memory pool",
+	"testmempoolacceptresult-reject-reason": "The reason the transaction would be rejected (only when allowed is false)",
+	"testmempoolacceptresult-vsize":         "The virtual size of the transaction",
+	"testmempoolacceptresult-fees":          "The fees of the transaction (only when allowed is true)",
+
+	// TestMempoolAcceptFees help.
+	"testmempoolacceptfees-base": "The fee paid by the transaction in LTC",
+
+	// ConvertToPSBTCmd help.
+	"converttopsbt--synopsis": "Converts the provided serialized, hex-encoded transaction to a base64-encoded partially signed transaction (BIP0174) with empty maps for all of its inputs and outputs.\n" +
+		"The utxoupdatepsbt command can be used to add the outputs spent by the inputs afterwards.",
+	"converttopsbt-hextx":         "Serialized, hex-encoded unsigned transaction",
+	"converttopsbt-permitsigdata": "Discard the signature scripts and witnesses of the inputs instead of failing when any are present",
+	"converttopsbt-iswitness":     "Whether the transaction is serialized with witnesses, which is detected automatically when not specified",
+	"converttopsbt--result0":      "The base64-encoded partially signed transaction",
+
+	// UtxoUpdatePSBTCmd help.
+	"utxoupdatepsbt--synopsis": "Adds the outputs spent by the inputs of the provided base64-encoded partially signed transaction (BIP0174) which are missing them.\n" +
+		"Segwit inputs are given their spent output from the memory pool, the utxo set, or the transaction index, while all other inputs are given the full transaction which created the spent output, which requires it to be in the memory pool or the transaction index.\n" +
+		"Inputs which already have their spent output and those whose spent output can't be found are left untouched.",
+	"utxoupdatepsbt-psbt":     "Base64-encoded partially signed transaction",
+	"utxoupdatepsbt--result0": "The base64-encoded updated partially signed transaction",
+
 	// ValidateAddressResult help.
-	"validateaddresschainresult-isvalid": "Whether or not the address is valid",
-	"validateaddresschainresult-address": "The bitcoin address (only when isvalid is true)",
+	"validateaddresschainresult-isvalid":         "Whether or not the address is valid",
+	"validateaddresschainresult-address":         "The bitcoin address (only when isvalid is true)",
+	"validateaddresschainresult-scriptPubKey":    "The hex-encoded public key script paying to the address (only when isvalid is true)",
+	"validateaddresschainresult-type":            "The type of the address: pubkeyhash, scripthash, witness_v0_keyhash, witness_v0_scripthash, or pubkey for a serialized public key (only when isvalid is true)",
+	"validateaddresschainresult-iswitness":       "Whether or not the address is a segwit address (only when isvalid is true)",
+	"validateaddresschainresult-witness_version": "The witness version of the address (only for segwit addresses)",
+	"validateaddresschainresult-witness_program": "The hex-encoded witness program of the address (only for segwit addresses)",
 
 	// ValidateAddressCmd help.
-	"validateaddress--synopsis": "Verify an address is valid.",
+	"validateaddress--synopsis": "Verify an address is valid for the active network.",
 	"validateaddress-address":   "Bitcoin address to validate",
 
 	// VerifyChainCmd help.
@@ -558,18 +1173,47 @@ var helpDescsEnUS = map[string]string{
 		"The actual checks performed by the checklevel parameter are implementation specific.\n" +
 		"For ltcd this is:\n" +
 		"checklevel=0 - Look up each block and ensure it can be loaded from the database.\n" +
-		"checklevel=1 - Perform basic context-free sanity checks on each block.",
-	"verifychain-checklevel": "How thorough the block verification is",
-	"verifychain-checkdepth": "The number of blocks to check",
+		"checklevel=1 - Perform basic context-free sanity checks on each block.\n" +
+		"checklevel=2 - Load the spend journal entry of each block and disconnect it from a view of the utxo set.\n" +
+		"checklevel=3 - Ensure the outputs created by each block match the utxo set before disconnecting it.\n" +
+		"checklevel=4 - Reconnect the disconnected blocks while fully validating them.\n" +
+		"Each level includes the checks of the lower levels and higher levels are clamped to 4.\n" +
+		"Levels 2 and above verify at most 288 blocks.\n" +
+		"The block which failed verification, if any, is logged.",
+	"verifychain-checklevel": "How thorough the block verification is (0-4)",
+	"verifychain-checkdepth": "The number of blocks to check going back from the end of the chain (0 = all)",
 	"verifychain--result0":   "Whether or not the chain verified",
 
 	// VerifyMessageCmd help.
 	"verifymessage--synopsis": "Verify a signed message.",
-	"verifymessage-address":   "The bitcoin address to use for the signature",
+	"verifymessage-address":   "The litecoin address to use for the signature",
 	"verifymessage-signature": "The base-64 encoded signature provided by the signer",
 	"verifymessage-message":   "The signed message",
 	"verifymessage--result0":  "Whether or not the signature verified",
 
+	// VerifyTxOutProofCmd help.
+	"verifytxoutproof--synopsis": "Verifies a proof created by gettxoutproof commits to a block in the main chain and returns the transactions it proves were included in the block.",
+	"verifytxoutproof-proof":     "The hex-encoded proof",
+	"verifytxoutproof--result0":  "The hashes of the proven transactions or an empty array when the proof is invalid",
+
+	// WaitForBlockResult help.
+	"waitforblockresult-hash":   "The hash of the tip of the main chain when the wait ended",
+	"waitforblockresult-height": "The height of the tip of the main chain when the wait ended",
+
+	// WaitForBlockCmd help.
+	"waitforblock--synopsis": "Waits until the block with the given hash is the tip of the main chain or the timeout expires and returns the tip at that time.",
+	"waitforblock-blockhash": "The hash of the block to wait for",
+	"waitforblock-timeout":   "The time to wait in milliseconds, or 0 to wait indefinitely",
+
+	// WaitForBlockHeightCmd help.
+	"waitforblockheight--synopsis": "Waits until the main chain reaches at least the given height or the timeout expires and returns the tip at that time.",
+	"waitforblockheight-height":    "The height to wait for",
+	"waitforblockheight-timeout":   "The time to wait in milliseconds, or 0 to wait indefinitely",
+
+	// WaitForNewBlockCmd help.
+	"waitfornewblock--synopsis": "Waits until the tip of the main chain changes or the timeout expires and returns the tip at that time.",
+	"waitfornewblock-timeout":   "The time to wait in milliseconds, or 0 to wait indefinitely",
+
 	// -------- Websocket-specific help --------
 
 	// Session help.
@@ -589,6 +1233,13 @@ var helpDescsEnUS = map[string]string{
 	// StopNotifyNewTransactionsCmd help.
 	"stopnotifynewtransactions--synopsis": "Stop sending either a txaccepted or a txacceptedverbose notification when a new transaction is accepted into the mempool.",
 
+	// NotifyMempoolEvictedCmd help.
+	"notifymempoolevicted--synopsis": "Send a mempoolevicted notification when a transaction is removed from the mempool.\n" +
+		"The notification includes the reason the transaction was removed: confirmed, replaced, conflict, invalid, orphan_timeout, size_limit, or expired.",
+
+	// StopNotifyMempoolEvictedCmd help.
+	"stopnotifymempoolevicted--synopsis": "Stop sending mempoolevicted notifications when a transaction is removed from the mempool.",
+
 	// NotifyReceivedCmd help.
 	"notifyreceived--synopsis": "Send a recvtx notification when a transaction added to mempool or appears in a newly-attached block contains a txout pkScript sending to any of the passed addresses.\n" +
 		"Matching outpoints are automatically registered for redeemingtx notifications.",
@@ -658,50 +1309,97 @@ var helpDescsEnUS = map[string]string{
 // This information is used to generate the help.  Each result type must be a
 // pointer to the type (or nil to indicate no return value).
 var rpcResultTypes = map[string][]interface{}{
-	"addnode":               nil,
-	"createrawtransaction":  {(*string)(nil)},
-	"debuglevel":            {(*string)(nil), (*string)(nil)},
-	"decoderawtransaction":  {(*btcjson.TxRawDecodeResult)(nil)},
-	"decodescript":          {(*btcjson.DecodeScriptResult)(nil)},
-	"generate":              {(*[]string)(nil)},
-	"getaddednodeinfo":      {(*[]string)(nil), (*[]btcjson.GetAddedNodeInfoResult)(nil)},
-	"getbestblock":          {(*btcjson.GetBestBlockResult)(nil)},
-	"getbestblockhash":      {(*string)(nil)},
-	"getblock":              {(*string)(nil), (*btcjson.GetBlockVerboseResult)(nil)},
-	"getblockcount":         {(*int64)(nil)},
-	"getblockhash":          {(*string)(nil)},
-	"getblockheader":        {(*string)(nil), (*btcjson.GetBlockHeaderVerboseResult)(nil)},
-	"getblocktemplate":      {(*btcjson.GetBlockTemplateResult)(nil), (*string)(nil), nil},
-	"getblockchaininfo":     {(*btcjson.GetBlockChainInfoResult)(nil)},
-	"getcfilter":            {(*string)(nil)},
-	"getconnectioncount":    {(*int32)(nil)},
-	"getcurrentnet":         {(*uint32)(nil)},
-	"getdifficulty":         {(*float64)(nil)},
-	"getgenerate":           {(*bool)(nil)},
-	"gethashespersec":       {(*float64)(nil)},
-	"getheaders":            {(*[]string)(nil)},
-	"getinfo":               {(*btcjson.InfoChainResult)(nil)},
-	"getmempoolinfo":        {(*btcjson.GetMempoolInfoResult)(nil)},
-	"getmininginfo":         {(*btcjson.GetMiningInfoResult)(nil)},
-	"getnettotals":          {(*btcjson.GetNetTotalsResult)(nil)},
-	"getnetworkhashps":      {(*int64)(nil)},
-	"getpeerinfo":           {(*[]btcjson.GetPeerInfoResult)(nil)},
-	"getrawmempool":         {(*[]string)(nil), (*btcjson.GetRawMempoolVerboseResult)(nil)},
-	"getrawtransaction":     {(*string)(nil), (*btcjson.TxRawResult)(nil)},
-	"gettxout":              {(*btcjson.GetTxOutResult)(nil)},
-	"node":                  nil,
-	"help":                  {(*string)(nil), (*string)(nil)},
-	"ping":                  nil,
-	"searchrawtransactions": {(*string)(nil), (*[]btcjson.SearchRawTransactionsResult)(nil)},
-	"sendrawtransaction":    {(*string)(nil)},
-	"setgenerate":           nil,
-	"stop":                  {(*string)(nil)},
-	"submitblock":           {nil, (*string)(nil)},
-	"uptime":                {(*int64)(nil)},
-	"validateaddress":       {(*btcjson.ValidateAddressChainResult)(nil)},
-	"verifychain":           {(*bool)(nil)},
-	"verifymessage":         {(*bool)(nil)},
-	"version":               {(*map[string]btcjson.VersionResult)(nil)},
+	"addnode":                nil,
+	"addpeeraddress":         {(*btcjson.AddPeerAddressResult)(nil)},
+	"analyzepsbt":            {(*btcjson.AnalyzePSBTResult)(nil)},
+	"clearbanned":            nil,
+	"converttopsbt":          {(*string)(nil)},
+	"createrawtransaction":   {(*string)(nil)},
+	"debuglevel":             {(*string)(nil), (*string)(nil)},
+	"decodepsbt":             {(*btcjson.DecodePSBTResult)(nil)},
+	"decoderawtransaction":   {(*btcjson.TxRawDecodeResult)(nil)},
+	"decodescript":           {(*btcjson.DecodeScriptResult)(nil)},
+	"deriveaddresses":        {(*[]string)(nil)},
+	"disconnectnode":         nil,
+	"dumptxoutset":           {(*btcjson.DumpTxOutSetResult)(nil)},
+	"estimatefee":            {(*float64)(nil)},
+	"estimatesmartfee":       {(*btcjson.EstimateSmartFeeResult)(nil)},
+	"generate":               {(*[]string)(nil)},
+	"generateblock":          {(*btcjson.GenerateBlockResult)(nil)},
+	"generatetoaddress":      {(*[]string)(nil)},
+	"generatetodescriptor":   {(*[]string)(nil)},
+	"getaddednodeinfo":       {(*[]string)(nil), (*[]btcjson.GetAddedNodeInfoResult)(nil)},
+	"getaddrmaninfo":         {(*map[string]btcjson.GetAddrManInfoResult)(nil)},
+	"getbestblock":           {(*btcjson.GetBestBlockResult)(nil)},
+	"getbestblockhash":       {(*string)(nil)},
+	"getblock":               {(*string)(nil), (*btcjson.GetBlockVerboseResult)(nil)},
+	"getblockcount":          {(*int64)(nil)},
+	"getblockfilter":         {(*btcjson.GetBlockFilterResult)(nil)},
+	"getblockhash":           {(*string)(nil)},
+	"getblockheader":         {(*string)(nil), (*btcjson.GetBlockHeaderVerboseResult)(nil)},
+	"getblockheaders":        {(*[]string)(nil), (*[]btcjson.GetBlockHeaderVerboseResult)(nil)},
+	"getblockstats":          {(*btcjson.GetBlockStatsResult)(nil), (*map[string]interface{})(nil)},
+	"getblocktemplate":       {(*btcjson.GetBlockTemplateResult)(nil), (*string)(nil), nil},
+	"getblockchaininfo":      {(*btcjson.GetBlockChainInfoResult)(nil)},
+	"getcacheinfo":           {(*btcjson.GetCacheInfoResult)(nil)},
+	"getcfilter":             {(*string)(nil)},
+	"getcfilterheader":       {(*string)(nil)},
+	"getchaintxstats":        {(*btcjson.GetChainTxStatsResult)(nil)},
+	"getconnectioncount":     {(*int32)(nil)},
+	"getcurrentnet":          {(*uint32)(nil)},
+	"getdeploymentinfo":      {(*btcjson.GetDeploymentInfoResult)(nil)},
+	"getdescriptorinfo":      {(*btcjson.GetDescriptorInfoResult)(nil)},
+	"getdifficulty":          {(*float64)(nil)},
+	"getgenerate":            {(*bool)(nil)},
+	"gethashespersec":        {(*float64)(nil)},
+	"getheaders":             {(*[]string)(nil)},
+	"getindexinfo":           {(*map[string]btcjson.GetIndexInfoResult)(nil)},
+	"getinfo":                {(*btcjson.InfoChainResult)(nil)},
+	"getmempoolancestors":    {(*[]string)(nil), (*btcjson.GetMempoolEntryResult)(nil)},
+	"getmempooldescendants":  {(*[]string)(nil), (*btcjson.GetMempoolEntryResult)(nil)},
+	"getmempoolentry":        {(*btcjson.GetMempoolEntryResult)(nil)},
+	"getmempoolinfo":         {(*btcjson.GetMempoolInfoResult)(nil)},
+	"getmininginfo":          {(*btcjson.GetMiningInfoResult)(nil)},
+	"getnettotals":           {(*btcjson.GetNetTotalsResult)(nil)},
+	"getnetworkhashps":       {(*float64)(nil)},
+	"getnodeaddresses":       {(*[]btcjson.GetNodeAddressesResult)(nil)},
+	"getpeerinfo":            {(*[]btcjson.GetPeerInfoResult)(nil)},
+	"getrawmempool":          {(*[]string)(nil), (*btcjson.GetRawMempoolVerboseResult)(nil), (*btcjson.GetRawMempoolSequenceResult)(nil)},
+	"getrawtransaction":      {(*string)(nil), (*btcjson.TxRawResult)(nil)},
+	"getsyncstatus":          {(*btcjson.GetSyncStatusResult)(nil)},
+	"gettxout":               {(*btcjson.GetTxOutResult)(nil)},
+	"gettxoutproof":          {(*string)(nil)},
+	"gettxoutsetinfo":        {(*btcjson.GetTxOutSetInfoResult)(nil)},
+	"node":                   nil,
+	"help":                   {(*string)(nil), (*string)(nil)},
+	"invalidateblock":        nil,
+	"listbanned":             {(*[]btcjson.ListBannedResult)(nil)},
+	"loadtxoutset":           {(*btcjson.LoadTxOutSetResult)(nil)},
+	"ping":                   nil,
+	"pruneblockchain":        {(*int32)(nil)},
+	"reconsiderblock":        nil,
+	"scantxoutset":           {(*btcjson.ScanTxOutSetResult)(nil), (*btcjson.ScanTxOutSetStatusResult)(nil), (*bool)(nil)},
+	"searchrawtransactions":  {(*string)(nil), (*[]btcjson.SearchRawTransactionsResult)(nil)},
+	"sendrawtransaction":     {(*string)(nil)},
+	"setban":                 nil,
+	"setgenerate":            nil,
+	"setminingflags":         nil,
+	"setversionbit":          nil,
+	"signmessagewithprivkey": {(*string)(nil)},
+	"stop":                   {(*string)(nil)},
+	"submitblock":            {nil, (*string)(nil)},
+	"submitpackage":          {(*btcjson.SubmitPackageResult)(nil)},
+	"testmempoolaccept":      {(*[]btcjson.TestMempoolAcceptResult)(nil)},
+	"uptime":                 {(*int64)(nil)},
+	"utxoupdatepsbt":         {(*string)(nil)},
+	"validateaddress":        {(*btcjson.ValidateAddressChainResult)(nil)},
+	"verifychain":            {(*bool)(nil)},
+	"verifymessage":          {(*bool)(nil)},
+	"verifytxoutproof":       {(*[]string)(nil)},
+	"version":                {(*map[string]btcjson.VersionResult)(nil)},
+	"waitforblock":           {(*btcjson.WaitForBlockResult)(nil)},
+	"waitforblockheight":     {(*btcjson.WaitForBlockResult)(nil)},
+	"waitfornewblock":        {(*btcjson.WaitForBlockResult)(nil)},
 
 	// Websocket commands.
 	"loadtxfilter":              nil,
@@ -710,6 +1408,8 @@ var rpcResultTypes = map[string][]interface{}{
 	"stopnotifyblocks":          nil,
 	"notifynewtransactions":     nil,
 	"stopnotifynewtransactions": nil,
+	"notifymempoolevicted":      nil,
+	"stopnotifymempoolevicted":  nil,
 	"notifyreceived":            nil,
 	"stopnotifyreceived":        nil,
 	"notifyspent":               nil,
@@ -9,7 +9,6 @@ import (
 	"bytes"
 	"container/list"
 	"crypto/sha256"
-	"crypto/subtle"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -67,11 +66,13 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 	"loadtxfilter":              handleLoadTxFilter,
 	"help":                      handleWebsocketHelp,
 	"notifyblocks":              handleNotifyBlocks,
+	"notifymempoolevicted":      handleNotifyMempoolEvicted,
 	"notifynewtransactions":     handleNotifyNewTransactions,
 	"notifyreceived":            handleNotifyReceived,
 	"notifyspent":               handleNotifySpent,
 	"session":                   handleSession,
 	"stopnotifyblocks":          handleStopNotifyBlocks,
+	"stopnotifymempoolevicted":  handleStopNotifyMempoolEvicted,
 	"stopnotifynewtransactions": handleStopNotifyNewTransactions,
 	"stopnotifyspent":           handleStopNotifySpent,
 	"stopnotifyreceived":        handleStopNotifyReceived,
@@ -85,12 +86,16 @@ var wsHandlersBeforeInit = map[string]wsCommandHandler{
 // server handler which runs each new connection in a new goroutine thereby
 // satisfying the requirement.
 func (s *rpcServer) WebsocketHandler(conn *websocket.Conn, remoteAddr string,
-	authenticated bool, isAdmin bool) {
+	authenticated bool, scope rpcScope) {
 
 	// Clear the read deadline that was set before the websocket hijacked
 	// the connection.
 	conn.SetReadDeadline(timeZeroVal)
 
+	// Limit the size of the messages read from the client.  The connection
+	// is closed when a larger message is received.
+	conn.SetReadLimit(cfg.RPCMaxRequestSize)
+
 	// Limit max number of websocket clients.
 	rpcsLog.Infof("New websocket client %s", remoteAddr)
 	if s.ntfnMgr.NumClients()+1 > cfg.RPCMaxWebsockets {
@@ -104,7 +109,7 @@ func (s *rpcServer) WebsocketHandler(conn *websocket.Conn, remoteAddr string,
 	// Create a new websocket client to handle the new websocket connection
 	// and wait for it to shutdown.  Once it has shutdown (and hence
 	// disconnected), remove it and any notifications it registered for.
-	client, err := newWebsocketClient(s, conn, remoteAddr, authenticated, isAdmin)
+	client, err := newWebsocketClient(s, conn, remoteAddr, authenticated, scope)
 	if err != nil {
 		rpcsLog.Errorf("Failed to serve client %s: %v", remoteAddr, err)
 		conn.Close()
@@ -247,6 +252,25 @@ func (m *wsNotificationManager) NotifyMempoolTx(tx *ltcutil.Tx, isNew bool) {
 	}
 }
 
+// NotifyMempoolEvicted passes a transaction removed from the mempool along with
+// the reason it was removed to the notification manager for transaction
+// notification processing.
+func (m *wsNotificationManager) NotifyMempoolEvicted(tx *ltcutil.Tx, reason string) {
+	n := &notificationTxRemovedFromMempool{
+		tx:     tx,
+		reason: reason,
+	}
+
+	// As NotifyMempoolEvicted will be called by mempool and the RPC server
+	// may no longer be running, use a select statement to unblock
+	// enqueuing the notification once the RPC server has begun shutting
+	// down.
+	select {
+	case m.queueNotification <- n:
+	case <-m.quit:
+	}
+}
+
 // wsClientFilter tracks relevant addresses for each websocket client for
 // the `rescanblocks` extension. It is modified by the `loadtxfilter` command.
 //
@@ -452,6 +476,10 @@ type notificationTxAcceptedByMempool struct {
 	isNew bool
 	tx    *ltcutil.Tx
 }
+type notificationTxRemovedFromMempool struct {
+	tx     *ltcutil.Tx
+	reason string
+}
 
 // Notification control requests
 type notificationRegisterClient wsClient
@@ -460,6 +488,8 @@ type notificationRegisterBlocks wsClient
 type notificationUnregisterBlocks wsClient
 type notificationRegisterNewMempoolTxs wsClient
 type notificationUnregisterNewMempoolTxs wsClient
+type notificationRegisterMempoolEvicted wsClient
+type notificationUnregisterMempoolEvicted wsClient
 type notificationRegisterSpent struct {
 	wsc *wsClient
 	ops []*wire.OutPoint
@@ -492,6 +522,7 @@ func (m *wsNotificationManager) notificationHandler() {
 	// since it is quite a bit more efficient than using the entire struct.
 	blockNotifications := make(map[chan struct{}]*wsClient)
 	txNotifications := make(map[chan struct{}]*wsClient)
+	evictedNotifications := make(map[chan struct{}]*wsClient)
 	watchedOutPoints := make(map[wire.OutPoint]map[chan struct{}]*wsClient)
 	watchedAddrs := make(map[string]map[chan struct{}]*wsClient)
 
@@ -540,6 +571,12 @@ out:
 				m.notifyForTx(watchedOutPoints, watchedAddrs, n.tx, nil)
 				m.notifyRelevantTxAccepted(n.tx, clients)
 
+			case *notificationTxRemovedFromMempool:
+				if len(evictedNotifications) != 0 {
+					m.notifyMempoolEvicted(evictedNotifications,
+						n.tx, n.reason)
+				}
+
 			case *notificationRegisterBlocks:
 				wsc := (*wsClient)(n)
 				blockNotifications[wsc.quit] = wsc
@@ -558,6 +595,7 @@ out:
 				// the client itself.
 				delete(blockNotifications, wsc.quit)
 				delete(txNotifications, wsc.quit)
+				delete(evictedNotifications, wsc.quit)
 				for k := range wsc.spentRequests {
 					op := k
 					m.removeSpentRequest(watchedOutPoints, wsc, &op)
@@ -587,6 +625,14 @@ out:
 				wsc := (*wsClient)(n)
 				delete(txNotifications, wsc.quit)
 
+			case *notificationRegisterMempoolEvicted:
+				wsc := (*wsClient)(n)
+				evictedNotifications[wsc.quit] = wsc
+
+			case *notificationUnregisterMempoolEvicted:
+				wsc := (*wsClient)(n)
+				delete(evictedNotifications, wsc.quit)
+
 			default:
 				rpcsLog.Warn("Unhandled notification type")
 			}
@@ -820,6 +866,33 @@ func (m *wsNotificationManager) UnregisterNewMempoolTxsUpdates(wsc *wsClient) {
 	m.queueNotification <- (*notificationUnregisterNewMempoolTxs)(wsc)
 }
 
+// RegisterMempoolEvictedUpdates requests notifications to the passed websocket
+// client when transactions are removed from the memory pool.
+func (m *wsNotificationManager) RegisterMempoolEvictedUpdates(wsc *wsClient) {
+	m.queueNotification <- (*notificationRegisterMempoolEvicted)(wsc)
+}
+
+// UnregisterMempoolEvictedUpdates removes notifications to the passed websocket
+// client when transactions are removed from the memory pool.
+func (m *wsNotificationManager) UnregisterMempoolEvictedUpdates(wsc *wsClient) {
+	m.queueNotification <- (*notificationUnregisterMempoolEvicted)(wsc)
+}
+
+// notifyMempoolEvicted notifies websocket clients that have registered for
+// updates when a transaction is removed from the memory pool.
+func (m *wsNotificationManager) notifyMempoolEvicted(clients map[chan struct{}]*wsClient, tx *ltcutil.Tx, reason string) {
+	ntfn := btcjson.NewMempoolEvictedNtfn(tx.Hash().String(), reason)
+	marshalledJSON, err := btcjson.MarshalCmd(nil, ntfn)
+	if err != nil {
+		rpcsLog.Errorf("Failed to marshal mempool evicted "+
+			"notification: %v", err)
+		return
+	}
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
 // notifyForNewTx notifies websocket clients that have registered for updates
 // when a new transaction is added to the memory pool.
 func (m *wsNotificationManager) notifyForNewTx(clients map[chan struct{}]*wsClient, tx *ltcutil.Tx) {
@@ -1249,9 +1322,9 @@ type wsClient struct {
 	// and therefore is allowed to communicated over the websocket.
 	authenticated bool
 
-	// isAdmin specifies whether a client may change the state of the server;
-	// false means its access is only to the limited set of RPC calls.
-	isAdmin bool
+	// scope specifies the RPC calls a client is permitted to make, which is
+	// nil when it may make all of them.
+	scope rpcScope
 
 	// sessionID is a random ID generated for each client when connected.
 	// These IDs may be queried by a client using the session RPC.  A change
@@ -1390,14 +1463,13 @@ out:
 			login := authCmd.Username + ":" + authCmd.Passphrase
 			auth := "Basic " + base64.StdEncoding.EncodeToString([]byte(login))
 			authSha := sha256.Sum256([]byte(auth))
-			cmp := subtle.ConstantTimeCompare(authSha[:], c.server.authsha[:])
-			limitcmp := subtle.ConstantTimeCompare(authSha[:], c.server.limitauthsha[:])
-			if cmp != 1 && limitcmp != 1 {
+			scope, ok := c.server.authScope(&authSha)
+			if !ok {
 				rpcsLog.Warnf("Auth failure.")
 				break out
 			}
 			c.authenticated = true
-			c.isAdmin = cmp == 1
+			c.scope = scope
 
 			// Marshal and send response.
 			reply, err := createMarshalledReply(cmd.id, nil, nil)
@@ -1410,24 +1482,22 @@ out:
 			continue
 		}
 
-		// Check if the client is using limited RPC credentials and
-		// error when not authorized to call this RPC.
-		if !c.isAdmin {
-			if _, ok := rpcLimited[request.Method]; !ok {
-				jsonErr := &btcjson.RPCError{
-					Code:    btcjson.ErrRPCInvalidParams.Code,
-					Message: "limited user not authorized for this method",
-				}
-				// Marshal and send response.
-				reply, err := createMarshalledReply(request.ID, nil, jsonErr)
-				if err != nil {
-					rpcsLog.Errorf("Failed to marshal parse failure "+
-						"reply: %v", err)
-					continue
-				}
-				c.SendMessage(reply, nil)
+		// Check if the client is permitted to make this RPC and error
+		// when not.
+		if !c.scope.permits(request.Method) {
+			jsonErr := &btcjson.RPCError{
+				Code:    btcjson.ErrRPCMethodNotPermitted,
+				Message: "method not permitted",
+			}
+			// Marshal and send response.
+			reply, err := createMarshalledReply(request.ID, nil, jsonErr)
+			if err != nil {
+				rpcsLog.Errorf("Failed to marshal parse failure "+
+					"reply: %v", err)
 				continue
 			}
+			c.SendMessage(reply, nil)
+			continue
 		}
 
 		// Asynchronously handle the request.  A semaphore is used to
@@ -1478,7 +1548,7 @@ func (c *wsClient) serviceRequest(r *parsedRPCCmd) {
 	if ok {
 		result, err = wsHandler(c, r.cmd)
 	} else {
-		result, err = c.server.standardCmdResult(r, nil)
+		result, err = c.server.standardCmdResult(r, c.quit)
 	}
 	reply, err := createMarshalledReply(r.id, result, err)
 	if err != nil {
@@ -1694,7 +1764,7 @@ func (c *wsClient) WaitForShutdown() {
 // incoming and outgoing messages in separate goroutines complete with queuing
 // and asynchrous handling for long-running operations.
 func newWebsocketClient(server *rpcServer, conn *websocket.Conn,
-	remoteAddr string, authenticated bool, isAdmin bool) (*wsClient, error) {
+	remoteAddr string, authenticated bool, scope rpcScope) (*wsClient, error) {
 
 	sessionID, err := wire.RandomUint64()
 	if err != nil {
@@ -1705,7 +1775,7 @@ func newWebsocketClient(server *rpcServer, conn *websocket.Conn,
 		conn:              conn,
 		addr:              remoteAddr,
 		authenticated:     authenticated,
-		isAdmin:           isAdmin,
+		scope:             scope,
 		sessionID:         sessionID,
 		server:            server,
 		addrRequests:      make(map[string]struct{}),
@@ -1867,6 +1937,20 @@ func handleStopNotifyNewTransactions(wsc *wsClient, icmd interface{}) (interface
 	return nil, nil
 }
 
+// handleNotifyMempoolEvicted implements the notifymempoolevicted command
+// extension for websocket connections.
+func handleNotifyMempoolEvicted(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.RegisterMempoolEvictedUpdates(wsc)
+	return nil, nil
+}
+
+// handleStopNotifyMempoolEvicted implements the stopnotifymempoolevicted
+// command extension for websocket connections.
+func handleStopNotifyMempoolEvicted(wsc *wsClient, icmd interface{}) (interface{}, error) {
+	wsc.server.ntfnMgr.UnregisterMempoolEvictedUpdates(wsc)
+	return nil, nil
+}
+
 // handleNotifyReceived implements the notifyreceived command extension for
 // websocket connections.
 func handleNotifyReceived(wsc *wsClient, icmd interface{}) (interface{}, error) {
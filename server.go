@@ -14,6 +14,7 @@ import (
 	"fmt"
 	"math"
 	"net"
+	"path/filepath"
 	"runtime"
 	"sort"
 	"strconv"
@@ -32,6 +33,7 @@ import (
 	"github.com/ltcsuite/ltcd/mempool"
 	"github.com/ltcsuite/ltcd/mining"
 	"github.com/ltcsuite/ltcd/mining/cpuminer"
+	"github.com/ltcsuite/ltcd/mining/stratum"
 	"github.com/ltcsuite/ltcd/peer"
 	"github.com/ltcsuite/ltcd/txscript"
 	"github.com/ltcsuite/ltcd/wire"
@@ -56,6 +58,20 @@ const (
 	// retries when connecting to persistent peers.  It is adjusted by the
 	// number of retries such that there is a retry backoff.
 	connectionRetryInterval = time.Second * 5
+
+	// otherMsgCommand is the command the bytes of messages which could not
+	// be read are accounted to in the per message byte counts of a peer.
+	otherMsgCommand = "*other*"
+)
+
+var (
+	// errNodeAlreadyAdded indicates an attempt to add a node as a persistent
+	// peer which has already been added.
+	errNodeAlreadyAdded = errors.New("node already added")
+
+	// errNodeNotAdded indicates an attempt to remove a node which has not
+	// been added as a persistent peer.
+	errNodeNotAdded = errors.New("node has not been added")
 )
 
 var (
@@ -97,14 +113,6 @@ type broadcastMsg struct {
 	excludePeers []*serverPeer
 }
 
-// broadcastInventoryAdd is a type used to declare that the InvVect it contains
-// needs to be added to the rebroadcast map
-type broadcastInventoryAdd relayMsg
-
-// broadcastInventoryDel is a type used to declare that the InvVect it contains
-// needs to be removed from the rebroadcast map
-type broadcastInventoryDel *wire.InvVect
-
 // relayMsg packages an inventory vector along with the newly discovered
 // inventory so the relay has access to that information.
 type relayMsg struct {
@@ -130,8 +138,13 @@ type peerState struct {
 	inboundPeers    map[int32]*serverPeer
 	outboundPeers   map[int32]*serverPeer
 	persistentPeers map[int32]*serverPeer
-	banned          map[string]time.Time
 	outboundGroups  map[string]int
+
+	// addedNodes houses the connection requests of all nodes which were
+	// added as persistent peers keyed by their address.  The connection
+	// manager retries the connections until the nodes are removed, even
+	// while they are not connected.
+	addedNodes map[string]*connmgr.ConnReq
 }
 
 // Count returns the count of all known peers.
@@ -151,6 +164,17 @@ func (ps *peerState) forAllOutboundPeers(closure func(sp *serverPeer)) {
 	}
 }
 
+// isAddedNode returns whether or not the passed connection request belongs to
+// a node which is currently added as a persistent peer.
+func (ps *peerState) isAddedNode(c *connmgr.ConnReq) bool {
+	for _, connReq := range ps.addedNodes {
+		if connReq == c {
+			return true
+		}
+	}
+	return false
+}
+
 // forAllPeers is a helper function that runs closure on all peers known to
 // peerState.
 func (ps *peerState) forAllPeers(closure func(sp *serverPeer)) {
@@ -172,38 +196,46 @@ type server struct {
 	shutdownSched int32
 	startupTime   int64
 
-	chainParams          *chaincfg.Params
-	addrManager          *addrmgr.AddrManager
-	connManager          *connmgr.ConnManager
-	sigCache             *txscript.SigCache
-	hashCache            *txscript.HashCache
-	rpcServer            *rpcServer
-	blockManager         *blockManager
-	chain                *blockchain.BlockChain
-	txMemPool            *mempool.TxPool
-	cpuMiner             *cpuminer.CPUMiner
-	modifyRebroadcastInv chan interface{}
-	newPeers             chan *serverPeer
-	donePeers            chan *serverPeer
-	banPeers             chan *serverPeer
-	query                chan interface{}
-	relayInv             chan relayMsg
-	broadcast            chan broadcastMsg
-	peerHeightsUpdate    chan updatePeerHeightsMsg
-	wg                   sync.WaitGroup
-	quit                 chan struct{}
-	nat                  NAT
-	db                   database.DB
-	timeSource           blockchain.MedianTimeSource
-	services             wire.ServiceFlag
+	chainParams       *chaincfg.Params
+	addrManager       *addrmgr.AddrManager
+	connManager       *connmgr.ConnManager
+	i2pSession        *connmgr.I2PSession
+	banList           *banList
+	sigCache          *txscript.SigCache
+	hashCache         *txscript.HashCache
+	scriptCache       *txscript.ScriptCache
+	rpcServer         *rpcServer
+	blockManager      *blockManager
+	chain             *blockchain.BlockChain
+	txMemPool         *mempool.TxPool
+	feeEstimator      *mempool.FeeEstimator
+	cpuMiner          *cpuminer.CPUMiner
+	stratumServer     *stratum.Server
+	zmqNotifier       *zmqNotifier
+	newPeers          chan *serverPeer
+	donePeers         chan *serverPeer
+	banPeers          chan *serverPeer
+	query             chan interface{}
+	relayInv          chan relayMsg
+	broadcast         chan broadcastMsg
+	peerHeightsUpdate chan updatePeerHeightsMsg
+	wg                sync.WaitGroup
+	quit              chan struct{}
+	nat               NAT
+	db                database.DB
+	addedNodes        map[string]*connmgr.ConnReq
+	timeSource        blockchain.MedianTimeSource
+	services          wire.ServiceFlag
+	banHalflife       time.Duration
 
 	// The following fields are used for optional indexes.  They will be nil
 	// if the associated index is not enabled.  These fields are set during
 	// initial creation of the server and never changed afterwards, so they
 	// do not need to be protected for concurrent access.
-	txIndex   *indexers.TxIndex
-	addrIndex *indexers.AddrIndex
-	cfIndex   *indexers.CfIndex
+	txIndex      *indexers.TxIndex
+	addrIndex    *indexers.AddrIndex
+	cfIndex      *indexers.CfIndex
+	indexManager *indexers.Manager
 }
 
 // serverPeer extends the peer to maintain state shared by the server and
@@ -217,30 +249,41 @@ type serverPeer struct {
 	connReq        *connmgr.ConnReq
 	server         *server
 	persistent     bool
+	blockRelayOnly bool
 	continueHash   *chainhash.Hash
 	relayMtx       sync.Mutex
 	disableRelayTx bool
 	sentAddrs      bool
 	filter         *bloom.Filter
 	knownAddresses map[string]struct{}
-	banScore       connmgr.DynamicBanScore
+	banScore       *connmgr.DynamicBanScore
 	quit           chan struct{}
 	// The following chans are used to sync blockmanager and server.
 	txProcessed    chan struct{}
 	blockProcessed chan struct{}
+
+	// The following maps track the number of bytes sent to and received
+	// from the peer for each message command.  They are protected by the
+	// msgStatsMtx mutex.
+	msgStatsMtx     sync.Mutex
+	bytesSentPerMsg map[string]uint64
+	bytesRecvPerMsg map[string]uint64
 }
 
 // newServerPeer returns a new serverPeer instance. The peer needs to be set by
 // the caller.
 func newServerPeer(s *server, isPersistent bool) *serverPeer {
 	return &serverPeer{
-		server:         s,
-		persistent:     isPersistent,
-		filter:         bloom.LoadFilter(nil),
-		knownAddresses: make(map[string]struct{}),
-		quit:           make(chan struct{}),
-		txProcessed:    make(chan struct{}, 1),
-		blockProcessed: make(chan struct{}, 1),
+		server:          s,
+		persistent:      isPersistent,
+		filter:          bloom.LoadFilter(nil),
+		knownAddresses:  make(map[string]struct{}),
+		banScore:        connmgr.NewDynamicBanScore(s.banHalflife),
+		quit:            make(chan struct{}),
+		txProcessed:     make(chan struct{}, 1),
+		blockProcessed:  make(chan struct{}, 1),
+		bytesSentPerMsg: make(map[string]uint64),
+		bytesRecvPerMsg: make(map[string]uint64),
 	}
 }
 
@@ -284,9 +327,42 @@ func (sp *serverPeer) relayTxDisabled() bool {
 	return isDisabled
 }
 
+// blocksOnly returns whether transactions are neither accepted from nor
+// relayed to the peer, which is the case when the server runs in blocks only
+// mode or the peer is a block-relay-only peer.
+func (sp *serverPeer) blocksOnly() bool {
+	return cfg.BlocksOnly || sp.blockRelayOnly
+}
+
+// wantsTx returns whether or not the passed transaction should be announced to
+// the peer.  It is not announced when the peer is a block-relay-only peer or
+// has transaction relaying disabled, its fee rate is less than the fee filter
+// of the peer, or the peer has a bloom filter loaded which the transaction
+// doesn't match.
+func (sp *serverPeer) wantsTx(txD *mempool.TxDesc) bool {
+	if sp.blockRelayOnly || sp.relayTxDisabled() {
+		return false
+	}
+
+	feeFilter := atomic.LoadInt64(&sp.feeFilter)
+	if feeFilter > 0 && txD.FeePerKB < feeFilter {
+		return false
+	}
+
+	if sp.filter.IsLoaded() && !sp.filter.MatchTxAndUpdate(txD.Tx) {
+		return false
+	}
+
+	return true
+}
+
 // pushAddrMsg sends an addr message to the connected peer using the provided
-// addresses.
+// addresses.  No addresses are sent to block-relay-only peers.
 func (sp *serverPeer) pushAddrMsg(addresses []*wire.NetAddress) {
+	if sp.blockRelayOnly {
+		return
+	}
+
 	// Filter addresses already known to the peer.
 	addrs := make([]*wire.NetAddress, 0, len(addresses))
 	for _, addr := range addresses {
@@ -306,7 +382,7 @@ func (sp *serverPeer) pushAddrMsg(addresses []*wire.NetAddress) {
 // addBanScore increases the persistent and decaying ban score fields by the
 // values passed as parameters. If the resulting score exceeds half of the ban
 // threshold, a warning is logged including the reason provided. Further, if
-// the score is above the ban threshold, the peer will be banned and
+// the score reaches the ban threshold, the peer will be banned and
 // disconnected.
 func (sp *serverPeer) addBanScore(persistent, transient uint32, reason string) {
 	// No warning is logged and no score is calculated if banning is disabled.
@@ -328,7 +404,7 @@ func (sp *serverPeer) addBanScore(persistent, transient uint32, reason string) {
 	if score > warnThreshold {
 		peerLog.Warnf("Misbehaving peer %s: %s -- ban score increased to %d",
 			sp, reason, score)
-		if score > cfg.BanThreshold {
+		if score >= cfg.BanThreshold {
 			peerLog.Warnf("Misbehaving peer %s -- banning and disconnecting",
 				sp)
 			sp.server.BanPeer(sp)
@@ -395,10 +471,13 @@ func (sp *serverPeer) OnVersion(_ *peer.Peer, msg *wire.MsgVersion) {
 
 			// Request known addresses if the server address manager needs
 			// more and the peer has a protocol version new enough to
-			// include a timestamp with addresses.
+			// include a timestamp with addresses.  Block-relay-only
+			// peers do not participate in address relay.
 			hasTimestamp := sp.ProtocolVersion() >=
 				wire.NetAddressTimeVersion
-			if addrManager.NeedMoreAddresses() && hasTimestamp {
+			if addrManager.NeedMoreAddresses() && hasTimestamp &&
+				!sp.blockRelayOnly {
+
 				sp.QueueMessage(wire.NewMsgGetAddr(), nil)
 			}
 
@@ -411,11 +490,29 @@ func (sp *serverPeer) OnVersion(_ *peer.Peer, msg *wire.MsgVersion) {
 	sp.server.AddPeer(sp)
 }
 
+// OnVerAck is invoked when a peer receives a verack bitcoin message.  It is
+// used to signal support for compact block relay (BIP0152) to peers which
+// support it as well.  New blocks are not announced with compact blocks until
+// the peer is selected as a high-bandwidth peer by the block manager.
+func (sp *serverPeer) OnVerAck(_ *peer.Peer, msg *wire.MsgVerAck) {
+	if sp.ProtocolVersion() >= wire.BIP0152Version {
+		sp.QueueMessage(wire.NewMsgSendCmpct(false,
+			sp.PreferredCmpctBlockVersion()), nil)
+	}
+}
+
 // OnMemPool is invoked when a peer receives a mempool bitcoin message.
 // It creates and sends an inventory message with the contents of the memory
 // pool up to the maximum inventory allowed per message.  When the peer has a
 // bloom filter loaded, the contents are filtered accordingly.
 func (sp *serverPeer) OnMemPool(_ *peer.Peer, msg *wire.MsgMemPool) {
+	// Block-relay-only peers do not participate in transaction relay.
+	if sp.blockRelayOnly {
+		peerLog.Debugf("Ignoring mempool request from block-relay-only "+
+			"peer %v", sp)
+		return
+	}
+
 	// Only allow mempool requests if the server has bloom filtering
 	// enabled.
 	if sp.server.services&wire.SFNodeBloom != wire.SFNodeBloom {
@@ -464,9 +561,9 @@ func (sp *serverPeer) OnMemPool(_ *peer.Peer, msg *wire.MsgMemPool) {
 // handler this does not serialize all transactions through a single thread
 // transactions don't rely on the previous one in a linear fashion like blocks.
 func (sp *serverPeer) OnTx(_ *peer.Peer, msg *wire.MsgTx) {
-	if cfg.BlocksOnly {
-		peerLog.Tracef("Ignoring tx %v from %v - blocksonly enabled",
-			msg.TxHash(), sp)
+	if sp.blocksOnly() {
+		peerLog.Tracef("Ignoring tx %v from %v - transaction relay "+
+			"disabled", msg.TxHash(), sp)
 		return
 	}
 
@@ -512,12 +609,66 @@ func (sp *serverPeer) OnBlock(_ *peer.Peer, msg *wire.MsgBlock, buf []byte) {
 	<-sp.blockProcessed
 }
 
+// OnCmpctBlock is invoked when a peer receives a cmpctblock bitcoin message.
+// It blocks until the compact block has been processed which includes the
+// block itself when it could be reconstructed from the memory pool.
+func (sp *serverPeer) OnCmpctBlock(_ *peer.Peer, msg *wire.MsgCmpctBlock) {
+	// Add the block to the known inventory for the peer.
+	blockHash := msg.BlockHash()
+	iv := wire.NewInvVect(wire.InvTypeBlock, &blockHash)
+	sp.AddKnownInventory(iv)
+
+	sp.server.blockManager.QueueCmpctBlock(msg, sp.Peer, sp.blockProcessed)
+	<-sp.blockProcessed
+}
+
+// OnBlockTxn is invoked when a peer receives a blocktxn bitcoin message.  It
+// blocks until the block the transactions complete has been fully processed.
+func (sp *serverPeer) OnBlockTxn(_ *peer.Peer, msg *wire.MsgBlockTxn) {
+	sp.server.blockManager.QueueBlockTxn(msg, sp.Peer, sp.blockProcessed)
+	<-sp.blockProcessed
+}
+
+// OnGetBlockTxn is invoked when a peer receives a getblocktxn bitcoin message.
+// It responds with the requested transactions of a recent block in the main
+// chain, or with the full block when it is too old.
+func (sp *serverPeer) OnGetBlockTxn(_ *peer.Peer, msg *wire.MsgGetBlockTxn) {
+	chain := sp.server.blockManager.chain
+	block, err := chain.BlockByHash(&msg.BlockHash)
+	if err != nil {
+		peerLog.Debugf("Unable to fetch requested block %v for "+
+			"getblocktxn from %s: %v", msg.BlockHash, sp, err)
+		return
+	}
+
+	// Peers are not expected to request transactions for blocks which
+	// are deep in the chain, so serve the full block in that case as
+	// described by BIP0152.
+	if chain.BestSnapshot().Height-block.Height() >= maxBlockTxnDepth {
+		sp.QueueMessage(block.MsgBlock(), nil)
+		return
+	}
+
+	txns := block.MsgBlock().Transactions
+	blockTxn := wire.NewMsgBlockTxn(&msg.BlockHash)
+	for _, index := range msg.Indexes {
+		if int(index) >= len(txns) {
+			sp.addBanScore(100, 0, fmt.Sprintf("getblocktxn index "+
+				"%d out of range for block %v", index,
+				msg.BlockHash))
+			return
+		}
+		blockTxn.AddTransaction(txns[index])
+	}
+	sp.QueueMessage(blockTxn, nil)
+}
+
 // OnInv is invoked when a peer receives an inv bitcoin message and is
 // used to examine the inventory being advertised by the remote peer and react
 // accordingly.  We pass the message down to blockmanager which will call
 // QueueMessage with any appropriate responses.
 func (sp *serverPeer) OnInv(_ *peer.Peer, msg *wire.MsgInv) {
-	if !cfg.BlocksOnly {
+	if !sp.blocksOnly() {
 		if len(msg.InvList) > 0 {
 			sp.server.blockManager.QueueInv(msg, sp.Peer)
 		}
@@ -528,7 +679,7 @@ func (sp *serverPeer) OnInv(_ *peer.Peer, msg *wire.MsgInv) {
 	for _, invVect := range msg.InvList {
 		if invVect.Type == wire.InvTypeTx {
 			peerLog.Tracef("Ignoring tx %v in inv from %v -- "+
-				"blocksonly enabled", invVect.Hash, sp)
+				"transaction relay disabled", invVect.Hash, sp)
 			if sp.ProtocolVersion() >= wire.BIP0037Version {
 				peerLog.Infof("Peer %v is announcing "+
 					"transactions -- disconnecting", sp)
@@ -601,6 +752,8 @@ func (sp *serverPeer) OnGetData(_ *peer.Peer, msg *wire.MsgGetData) {
 			err = sp.server.pushMerkleBlockMsg(sp, &iv.Hash, c, waitChan, wire.WitnessEncoding)
 		case wire.InvTypeFilteredBlock:
 			err = sp.server.pushMerkleBlockMsg(sp, &iv.Hash, c, waitChan, wire.BaseEncoding)
+		case wire.InvTypeCmpctBlock:
+			err = sp.server.pushCmpctBlockMsg(sp, &iv.Hash, c, waitChan)
 		default:
 			peerLog.Warnf("Unknown type in inventory request %d",
 				iv.Type)
@@ -709,6 +862,11 @@ func (sp *serverPeer) OnGetHeaders(_ *peer.Peer, msg *wire.MsgGetHeaders) {
 
 // OnGetCFilter is invoked when a peer receives a getcfilter bitcoin message.
 func (sp *serverPeer) OnGetCFilter(_ *peer.Peer, msg *wire.MsgGetCFilter) {
+	// Refuse getcfilter requests if committed filters are not enabled.
+	if !sp.enforceNodeCFFlag(msg.Command()) {
+		return
+	}
+
 	// Ignore getcfilter requests if not in sync.
 	if !sp.server.blockManager.IsCurrent() {
 		return
@@ -731,7 +889,12 @@ func (sp *serverPeer) OnGetCFilter(_ *peer.Peer, msg *wire.MsgGetCFilter) {
 
 // OnGetCFHeaders is invoked when a peer receives a getcfheader bitcoin message.
 func (sp *serverPeer) OnGetCFHeaders(_ *peer.Peer, msg *wire.MsgGetCFHeaders) {
-	// Ignore getcfilterheader requests if not in sync.
+	// Refuse getcfheaders requests if committed filters are not enabled.
+	if !sp.enforceNodeCFFlag(msg.Command()) {
+		return
+	}
+
+	// Ignore getcfheaders requests if not in sync.
 	if !sp.server.blockManager.IsCurrent() {
 		return
 	}
@@ -797,8 +960,8 @@ func (sp *serverPeer) OnGetCFHeaders(_ *peer.Peer, msg *wire.MsgGetCFHeaders) {
 	}
 
 	// Don't attempt to fetch more than we can put into a single message.
-	if endIdx-startIdx > wire.MaxBlockHeadersPerMsg {
-		endIdx = startIdx + wire.MaxBlockHeadersPerMsg
+	if endIdx-startIdx > wire.MaxCFHeadersPerMsg {
+		endIdx = startIdx + wire.MaxCFHeadersPerMsg
 	}
 
 	// Fetch the inventory from the block database.
@@ -811,29 +974,32 @@ func (sp *serverPeer) OnGetCFHeaders(_ *peer.Peer, msg *wire.MsgGetCFHeaders) {
 		return
 	}
 
-	// Generate cfheaders message and send it.
-	headersMsg := wire.NewMsgCFHeaders()
+	// Fetch the raw committed filter header bytes from the database.  They
+	// are all loaded at once so they form a consistent chain.
+	hashPtrs := make([]*chainhash.Hash, len(hashList))
 	for i := range hashList {
-		// Fetch the raw committed filter header bytes from the
-		// database.
-		headerBytes, err := sp.server.cfIndex.FilterHeaderByBlockHash(
-			&hashList[i], msg.Extended)
-		if (err != nil) || (len(headerBytes) == 0) {
-			peerLog.Warnf("Could not obtain CF header for %v: %v",
-				hashList[i], err)
-			return
-		}
+		hashPtrs[i] = &hashList[i]
+	}
+	headersBytes, err := sp.server.cfIndex.FilterHeadersByBlockHashes(
+		hashPtrs, msg.Extended)
+	if err != nil {
+		peerLog.Warnf("Could not obtain CF headers for %v through %v: %v",
+			hashList[0], hashList[len(hashList)-1], err)
+		return
+	}
 
+	// Generate cfheaders message and send it.
+	headersMsg := wire.NewMsgCFHeaders()
+	for i, headerBytes := range headersBytes {
 		// Deserialize the hash.
-		var header chainhash.Hash
-		err = header.SetBytes(headerBytes)
+		header, err := chainhash.NewHash(headerBytes)
 		if err != nil {
-			peerLog.Warnf("Committed filter header deserialize "+
-				"failed: %v", err)
+			peerLog.Warnf("Committed filter header for %v "+
+				"deserialize failed: %v", hashList[i], err)
 			return
 		}
 
-		headersMsg.AddCFHeader(&header)
+		headersMsg.AddCFHeader(header)
 	}
 
 	headersMsg.Extended = msg.Extended
@@ -841,6 +1007,81 @@ func (sp *serverPeer) OnGetCFHeaders(_ *peer.Peer, msg *wire.MsgGetCFHeaders) {
 	sp.QueueMessage(headersMsg, nil)
 }
 
+// OnGetCFCheckpt is invoked when a peer receives a getcfcheckpt bitcoin
+// message.  It responds with the committed filter headers of the blocks at
+// every wire.CFCheckptInterval height in the main chain up to the requested
+// stop hash.
+func (sp *serverPeer) OnGetCFCheckpt(_ *peer.Peer, msg *wire.MsgGetCFCheckpt) {
+	// Refuse getcfcheckpt requests if committed filters are not enabled.
+	if !sp.enforceNodeCFFlag(msg.Command()) {
+		return
+	}
+
+	// Ignore getcfcheckpt requests if not in sync.
+	if !sp.server.blockManager.IsCurrent() {
+		return
+	}
+
+	// Only blocks in the main chain have committed filter headers, so
+	// there is nothing to do when the stop hash is not one of them.  This
+	// mirrors the handling of an unknown stop hash by getcfheaders.
+	chain := sp.server.blockManager.chain
+	if !chain.MainChainHasBlock(&msg.StopHash) {
+		peerLog.Debugf("Ignoring getcfcheckpt from %v for block %v "+
+			"which is not in the main chain", sp, msg.StopHash)
+		return
+	}
+
+	blockHashes, err := chain.IntervalBlockHashes(&msg.StopHash,
+		wire.CFCheckptInterval)
+	if err != nil {
+		peerLog.Debugf("Invalid getcfcheckpt request: %v", err)
+		return
+	}
+
+	hashPtrs := make([]*chainhash.Hash, len(blockHashes))
+	for i := range blockHashes {
+		hashPtrs[i] = &blockHashes[i]
+	}
+	headersBytes, err := sp.server.cfIndex.FilterHeadersByBlockHashes(
+		hashPtrs, msg.Extended)
+	if err != nil {
+		peerLog.Warnf("Could not obtain CF checkpoints up to %v: %v",
+			msg.StopHash, err)
+		return
+	}
+
+	checkptMsg := wire.NewMsgCFCheckpt(&msg.StopHash, msg.Extended,
+		len(headersBytes))
+	for i, headerBytes := range headersBytes {
+		header, err := chainhash.NewHash(headerBytes)
+		if err != nil {
+			peerLog.Warnf("Committed filter header for %v "+
+				"deserialize failed: %v", blockHashes[i], err)
+			return
+		}
+
+		checkptMsg.AddCFHeader(header)
+	}
+
+	sp.QueueMessage(checkptMsg, nil)
+}
+
+// enforceNodeCFFlag refuses committed filter requests from the peer with a
+// reject message if the server is not configured to serve committed filters
+// and returns whether or not the request may be served.
+func (sp *serverPeer) enforceNodeCFFlag(cmd string) bool {
+	if sp.server.services&wire.SFNodeCF != wire.SFNodeCF {
+		peerLog.Debugf("%s sent an unsupported %s request -- "+
+			"rejecting", sp, cmd)
+		sp.PushRejectMsg(cmd, wire.RejectNonstandard,
+			"committed filters are not enabled", nil, false)
+		return false
+	}
+
+	return true
+}
+
 // enforceNodeBloomFlag disconnects the peer if the server is not configured to
 // allow bloom filters.  Additionally, if the peer has negotiated to a protocol
 // version  that is high enough to observe the bloom filter service support bit,
@@ -990,6 +1231,19 @@ func (sp *serverPeer) OnGetAddr(_ *peer.Peer, msg *wire.MsgGetAddr) {
 // OnAddr is invoked when a peer receives an addr bitcoin message and is
 // used to notify the server about advertised addresses.
 func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
+	sp.handleAddrs(msg.Command(), msg.AddrList)
+}
+
+// OnAddrV2 is invoked when a peer receives an addrv2 bitcoin message and is
+// used to notify the server about advertised addresses, which unlike those of
+// addr messages may include Tor v3 and I2P addresses.
+func (sp *serverPeer) OnAddrV2(_ *peer.Peer, msg *wire.MsgAddrV2) {
+	sp.handleAddrs(msg.Command(), msg.AddrList)
+}
+
+// handleAddrs adds the addresses advertised by the peer in an addr or addrv2
+// message to the known addresses of the peer and the address manager.
+func (sp *serverPeer) handleAddrs(command string, addrList []*wire.NetAddress) {
 	// Ignore addresses when running on the simulation test network.  This
 	// helps prevent the network from becoming another public test network
 	// since it will not be able to learn about other peers that have not
@@ -998,20 +1252,24 @@ func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
 		return
 	}
 
-	// Ignore old style addresses which don't include a timestamp.
-	if sp.ProtocolVersion() < wire.NetAddressTimeVersion {
+	// Ignore old style addresses which don't include a timestamp as well
+	// as addresses from block-relay-only peers, which do not participate in
+	// address relay.
+	if sp.ProtocolVersion() < wire.NetAddressTimeVersion ||
+		sp.blockRelayOnly {
+
 		return
 	}
 
 	// A message that has no addresses is invalid.
-	if len(msg.AddrList) == 0 {
+	if len(addrList) == 0 {
 		peerLog.Errorf("Command [%s] from %s does not contain any addresses",
-			msg.Command(), sp)
+			command, sp)
 		sp.Disconnect()
 		return
 	}
 
-	for _, na := range msg.AddrList {
+	for _, na := range addrList {
 		// Don't add more address if we're disconnecting.
 		if !sp.Connected() {
 			return
@@ -1034,19 +1292,75 @@ func (sp *serverPeer) OnAddr(_ *peer.Peer, msg *wire.MsgAddr) {
 	// addresses, and last seen updates.
 	// XXX bitcoind gives a 2 hour time penalty here, do we want to do the
 	// same?
-	sp.server.addrManager.AddAddresses(msg.AddrList, sp.NA())
+	sp.server.addrManager.AddAddresses(addrList, sp.NA())
 }
 
 // OnRead is invoked when a peer receives a message and it is used to update
 // the bytes received by the server.
 func (sp *serverPeer) OnRead(_ *peer.Peer, bytesRead int, msg wire.Message, err error) {
 	sp.server.AddBytesReceived(uint64(bytesRead))
+	sp.addMsgBytes(sp.bytesRecvPerMsg, msg, bytesRead)
+
+	// Messages which don't match their checksum are only sent by peers that
+	// are broken or misbehaving.  The regression test intentionally sends
+	// such messages, so don't ban the peer in regression test mode.
+	if wire.IsChecksumError(err) &&
+		sp.server.chainParams != &chaincfg.RegressionNetParams {
+
+		sp.addBanScore(100, 0, "bad checksum")
+	}
 }
 
 // OnWrite is invoked when a peer sends a message and it is used to update
 // the bytes sent by the server.
 func (sp *serverPeer) OnWrite(_ *peer.Peer, bytesWritten int, msg wire.Message, err error) {
 	sp.server.AddBytesSent(uint64(bytesWritten))
+	sp.addMsgBytes(sp.bytesSentPerMsg, msg, bytesWritten)
+}
+
+// addMsgBytes adds the passed number of bytes to the entry for the command of
+// the passed message in the provided per message byte counts of the peer.  The
+// bytes of messages which could not be read are counted as otherMsgCommand.
+func (sp *serverPeer) addMsgBytes(perMsg map[string]uint64, msg wire.Message, bytes int) {
+	if bytes <= 0 {
+		return
+	}
+
+	command := otherMsgCommand
+	if msg != nil {
+		command = msg.Command()
+	}
+	sp.msgStatsMtx.Lock()
+	perMsg[command] += uint64(bytes)
+	sp.msgStatsMtx.Unlock()
+}
+
+// copyMsgBytes returns a copy of the provided per message byte counts of the
+// peer.
+func (sp *serverPeer) copyMsgBytes(perMsg map[string]uint64) map[string]uint64 {
+	sp.msgStatsMtx.Lock()
+	perMsgCopy := make(map[string]uint64, len(perMsg))
+	for command, bytes := range perMsg {
+		perMsgCopy[command] = bytes
+	}
+	sp.msgStatsMtx.Unlock()
+	return perMsgCopy
+}
+
+// BytesSentPerMsg returns the number of bytes sent to the peer for each message
+// command.
+//
+// This function is safe for concurrent access.
+func (sp *serverPeer) BytesSentPerMsg() map[string]uint64 {
+	return sp.copyMsgBytes(sp.bytesSentPerMsg)
+}
+
+// BytesRecvPerMsg returns the number of bytes received from the peer for each
+// message command.
+//
+// This function is safe for concurrent access.
+func (sp *serverPeer) BytesRecvPerMsg() map[string]uint64 {
+	return sp.copyMsgBytes(sp.bytesRecvPerMsg)
 }
 
 // randomUint16Number returns a random uint16 in a specified input range.  Note
@@ -1067,28 +1381,6 @@ func randomUint16Number(max uint16) uint16 {
 	}
 }
 
-// AddRebroadcastInventory adds 'iv' to the list of inventories to be
-// rebroadcasted at random intervals until they show up in a block.
-func (s *server) AddRebroadcastInventory(iv *wire.InvVect, data interface{}) {
-	// Ignore if shutting down.
-	if atomic.LoadInt32(&s.shutdown) != 0 {
-		return
-	}
-
-	s.modifyRebroadcastInv <- broadcastInventoryAdd{invVect: iv, data: data}
-}
-
-// RemoveRebroadcastInventory removes 'iv' from the list of items to be
-// rebroadcasted if present.
-func (s *server) RemoveRebroadcastInventory(iv *wire.InvVect) {
-	// Ignore if shutting down.
-	if atomic.LoadInt32(&s.shutdown) != 0 {
-		return
-	}
-
-	s.modifyRebroadcastInv <- broadcastInventoryDel(iv)
-}
-
 // relayTransactions generates and relays inventory vectors for all of the
 // passed transactions to all connected peers.
 func (s *server) relayTransactions(txns []*mempool.TxDesc) {
@@ -1112,18 +1404,17 @@ func (s *server) AnnounceNewTransactions(txns []*mempool.TxDesc) {
 	if s.rpcServer != nil {
 		s.rpcServer.NotifyNewTransactions(txns)
 	}
+
+	// Publish the newly accepted transactions to ZMQ subscribers.
+	if s.zmqNotifier != nil {
+		s.zmqNotifier.NotifyNewTransactions(txns)
+	}
 }
 
 // Transaction has one confirmation on the main chain. Now we can mark it as no
 // longer needing rebroadcasting.
 func (s *server) TransactionConfirmed(tx *ltcutil.Tx) {
-	// Rebroadcasting is only necessary when the RPC server is active.
-	if s.rpcServer == nil {
-		return
-	}
-
-	iv := wire.NewInvVect(wire.InvTypeTx, tx.Hash())
-	s.RemoveRebroadcastInventory(iv)
+	s.txMemPool.RemoveUnbroadcastTx(tx.Hash())
 }
 
 // pushTxMsg sends a tx message for the provided transaction hash to the
@@ -1150,6 +1441,10 @@ func (s *server) pushTxMsg(sp *serverPeer, hash *chainhash.Hash, doneChan chan<-
 		<-waitChan
 	}
 
+	// The transaction no longer needs to be rebroadcast once a peer has
+	// requested it.
+	s.txMemPool.RemoveUnbroadcastTx(hash)
+
 	sp.QueueMessageWithEncoding(tx.MsgTx(), doneChan, encoding)
 
 	return nil
@@ -1221,6 +1516,64 @@ func (s *server) pushBlockMsg(sp *serverPeer, hash *chainhash.Hash, doneChan cha
 	return nil
 }
 
+// pushCmpctBlockMsg sends a cmpctblock message for the provided block hash to
+// the connected peer.  The full block is sent instead when the block is too old
+// to be served as a compact block.  An error is returned if the block hash is
+// not known.
+func (s *server) pushCmpctBlockMsg(sp *serverPeer, hash *chainhash.Hash,
+	doneChan chan<- struct{}, waitChan <-chan struct{}) error {
+
+	// Fetch the block from the main chain.
+	chain := s.blockManager.chain
+	block, err := chain.BlockByHash(hash)
+	if err != nil {
+		peerLog.Tracef("Unable to fetch requested block hash %v: %v",
+			hash, err)
+
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+
+	// Serve the full block when it is too deep in the chain for the peer
+	// to be able to reconstruct it from its memory pool.
+	if chain.BestSnapshot().Height-block.Height() >= maxCmpctBlockDepth {
+		encoding := wire.BaseEncoding
+		if sp.IsWitnessEnabled() {
+			encoding = wire.WitnessEncoding
+		}
+		return s.pushBlockMsg(sp, hash, doneChan, waitChan, encoding)
+	}
+
+	nonce, err := wire.RandomUint64()
+	if err != nil {
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+	msgCmpctBlock, err := newCmpctBlock(block,
+		sp.PreferredCmpctBlockVersion(), nonce)
+	if err != nil {
+		peerLog.Tracef("Unable to create compact block for block "+
+			"hash %v: %v", hash, err)
+
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return err
+	}
+
+	// Once we have fetched data wait for any previous operation to finish.
+	if waitChan != nil {
+		<-waitChan
+	}
+
+	sp.QueueMessage(msgCmpctBlock, doneChan)
+	return nil
+}
+
 // pushMerkleBlockMsg sends a merkleblock message for the provided block hash to
 // the connected peer.  Since a merkle block requires the peer to have a filter
 // loaded, this call will simply be ignored if there is no filter loaded.  An
@@ -1331,20 +1684,23 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 		sp.Disconnect()
 		return false
 	}
-	if banEnd, ok := state.banned[host]; ok {
-		if time.Now().Before(banEnd) {
-			srvrLog.Debugf("Peer %s is banned for another %v - disconnecting",
-				host, banEnd.Sub(time.Now()))
-			sp.Disconnect()
-			return false
-		}
-
-		srvrLog.Infof("Peer %s is no longer banned", host)
-		delete(state.banned, host)
+	if banEnd, banned := s.banList.BannedUntil(net.ParseIP(host)); banned {
+		srvrLog.Debugf("Peer %s is banned for another %v - disconnecting",
+			host, banEnd.Sub(time.Now()))
+		sp.Disconnect()
+		return false
 	}
 
 	// TODO: Check for max peers from a single IP.
 
+	// Disconnect persistent peers which were removed while the connection
+	// was being established.
+	if sp.persistent && !state.isAddedNode(sp.connReq) {
+		srvrLog.Debugf("Peer %s is no longer added - disconnecting", sp)
+		sp.Disconnect()
+		return false
+	}
+
 	// Limit max number of total peers.
 	if state.Count() >= cfg.MaxPeers {
 		srvrLog.Infof("Max peers reached [%d] - disconnecting peer %s",
@@ -1368,9 +1724,34 @@ func (s *server) handleAddPeerMsg(state *peerState, sp *serverPeer) bool {
 		}
 	}
 
+	// Announce the transactions submitted locally which are not yet known
+	// to have been received by any peer right away since they might have
+	// been submitted while no peers were connected.
+	s.announceUnbroadcastTxs(sp)
+
 	return true
 }
 
+// announceUnbroadcastTxs sends an inventory message to the passed peer for the
+// transactions submitted locally which are not yet known to have been received
+// by any peer and which the peer wants.
+func (s *server) announceUnbroadcastTxs(sp *serverPeer) {
+	invMsg := wire.NewMsgInv()
+	for _, txD := range s.txMemPool.UnbroadcastTxDescs() {
+		if !sp.wantsTx(txD) {
+			continue
+		}
+		iv := wire.NewInvVect(wire.InvTypeTx, txD.Tx.Hash())
+		if err := invMsg.AddInvVect(iv); err != nil {
+			break
+		}
+		sp.AddKnownInventory(iv)
+	}
+	if len(invMsg.InvList) > 0 {
+		sp.QueueMessage(invMsg, nil)
+	}
+}
+
 // handleDonePeerMsg deals with peers that have signalled they are done.  It is
 // invoked from the peerHandler goroutine.
 func (s *server) handleDonePeerMsg(state *peerState, sp *serverPeer) {
@@ -1394,8 +1775,14 @@ func (s *server) handleDonePeerMsg(state *peerState, sp *serverPeer) {
 		return
 	}
 
+	// Connections to persistent peers which were removed must no longer
+	// be retried.
 	if sp.connReq != nil {
-		s.connManager.Disconnect(sp.connReq.ID())
+		if sp.persistent && !state.isAddedNode(sp.connReq) {
+			s.connManager.Remove(sp.connReq.ID())
+		} else {
+			s.connManager.Disconnect(sp.connReq.ID())
+		}
 	}
 
 	// Update the address' last seen time if the peer has acknowledged
@@ -1416,30 +1803,80 @@ func (s *server) handleBanPeerMsg(state *peerState, sp *serverPeer) {
 		srvrLog.Debugf("can't split ban peer %s %v", sp.Addr(), err)
 		return
 	}
+	subnet, err := parseSubnet(host)
+	if err != nil {
+		srvrLog.Debugf("can't parse ban peer address %s %v", host, err)
+		return
+	}
+	err = s.banList.Ban(subnet, time.Now().Add(cfg.BanDuration))
+	if err != nil && err != errAlreadyBanned {
+		srvrLog.Errorf("Unable to ban peer %s: %v", host, err)
+		return
+	}
 	direction := directionString(sp.Inbound())
 	srvrLog.Infof("Banned peer %s (%s) for %v", host, direction,
 		cfg.BanDuration)
-	state.banned[host] = time.Now().Add(cfg.BanDuration)
 }
 
 // handleRelayInvMsg deals with relaying inventory to peers that are not already
 // known to have it.  It is invoked from the peerHandler goroutine.
 func (s *server) handleRelayInvMsg(state *peerState, msg relayMsg) {
+	// Compact blocks are only created once for each version as needed.
+	var cmpctBlocks map[uint64]*wire.MsgCmpctBlock
+
 	state.forAllPeers(func(sp *serverPeer) {
 		if !sp.Connected() {
 			return
 		}
 
+		// If the inventory is a block and the peer asked for new
+		// blocks to be announced with compact blocks, generate and
+		// send a cmpctblock message instead of an inventory message.
+		if msg.invVect.Type == wire.InvTypeBlock && sp.WantsCmpctBlocks() {
+			block, ok := msg.data.(*ltcutil.Block)
+			if !ok {
+				peerLog.Warnf("Underlying data for compact " +
+					"block is not a block")
+				return
+			}
+
+			version := sp.CmpctBlockVersion()
+			msgCmpctBlock, ok := cmpctBlocks[version]
+			if !ok {
+				nonce, err := wire.RandomUint64()
+				if err != nil {
+					peerLog.Errorf("Failed to generate "+
+						"compact block nonce: %v", err)
+					return
+				}
+				msgCmpctBlock, err = newCmpctBlock(block,
+					version, nonce)
+				if err != nil {
+					peerLog.Errorf("Failed to create "+
+						"compact block: %v", err)
+					return
+				}
+				if cmpctBlocks == nil {
+					cmpctBlocks = make(map[uint64]*wire.MsgCmpctBlock)
+				}
+				cmpctBlocks[version] = msgCmpctBlock
+			}
+			sp.AddKnownInventory(msg.invVect)
+			sp.QueueMessage(msgCmpctBlock, nil)
+			return
+		}
+
 		// If the inventory is a block and the peer prefers headers,
 		// generate and send a headers message instead of an inventory
 		// message.
 		if msg.invVect.Type == wire.InvTypeBlock && sp.WantsHeaders() {
-			blockHeader, ok := msg.data.(wire.BlockHeader)
+			block, ok := msg.data.(*ltcutil.Block)
 			if !ok {
 				peerLog.Warnf("Underlying data for headers" +
-					" is not a block header")
+					" is not a block")
 				return
 			}
+			blockHeader := block.MsgBlock().Header
 			msgHeaders := wire.NewMsgHeaders()
 			if err := msgHeaders.AddBlockHeader(&blockHeader); err != nil {
 				peerLog.Errorf("Failed to add block"+
@@ -1451,12 +1888,6 @@ func (s *server) handleRelayInvMsg(state *peerState, msg relayMsg) {
 		}
 
 		if msg.invVect.Type == wire.InvTypeTx {
-			// Don't relay the transaction to the peer when it has
-			// transaction relaying disabled.
-			if sp.relayTxDisabled() {
-				return
-			}
-
 			txD, ok := msg.data.(*mempool.TxDesc)
 			if !ok {
 				peerLog.Warnf("Underlying data for tx inv "+
@@ -1465,20 +1896,11 @@ func (s *server) handleRelayInvMsg(state *peerState, msg relayMsg) {
 				return
 			}
 
-			// Don't relay the transaction if the transaction fee-per-kb
-			// is less than the peer's feefilter.
-			feeFilter := atomic.LoadInt64(&sp.feeFilter)
-			if feeFilter > 0 && txD.FeePerKB < feeFilter {
+			// Don't relay the transaction when the peer does not
+			// want it.
+			if !sp.wantsTx(txD) {
 				return
 			}
-
-			// Don't relay the transaction if there is a bloom
-			// filter loaded and the transaction doesn't match it.
-			if sp.filter.IsLoaded() {
-				if !sp.filter.MatchTxAndUpdate(txD.Tx) {
-					return
-				}
-			}
 		}
 
 		// Queue the inventory to be relayed with the next batch.
@@ -1523,6 +1945,11 @@ type getAddedNodesMsg struct {
 	reply chan []*serverPeer
 }
 
+type getServerPeerMsg struct {
+	peer  *peer.Peer
+	reply chan *serverPeer
+}
+
 type disconnectNodeMsg struct {
 	cmp   func(*serverPeer) bool
 	reply chan error
@@ -1535,6 +1962,7 @@ type connectNodeMsg struct {
 }
 
 type removeNodeMsg struct {
+	addr  string
 	cmp   func(*serverPeer) bool
 	reply chan error
 }
@@ -1569,6 +1997,14 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 			msg.reply <- errors.New("max peers reached")
 			return
 		}
+		if _, ok := state.addedNodes[msg.addr]; ok {
+			if msg.permanent {
+				msg.reply <- errNodeAlreadyAdded
+			} else {
+				msg.reply <- errors.New("peer exists as a permanent peer")
+			}
+			return
+		}
 		for _, peer := range state.persistentPeers {
 			if peer.Addr() == msg.addr {
 				if msg.permanent {
@@ -1587,22 +2023,37 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 		}
 
 		// TODO: if too many, nuke a non-perm peer.
-		go s.connManager.Connect(&connmgr.ConnReq{
+		connReq := &connmgr.ConnReq{
 			Addr:      netAddr,
 			Permanent: msg.permanent,
-		})
+		}
+		if msg.permanent {
+			state.addedNodes[msg.addr] = connReq
+		}
+		go s.connManager.Connect(connReq)
 		msg.reply <- nil
 	case removeNodeMsg:
-		found := disconnectPeer(state.persistentPeers, msg.cmp, func(sp *serverPeer) {
+		// Stop tracking the node as added so its connection is no
+		// longer retried, regardless of whether or not it is currently
+		// connected.
+		_, found := state.addedNodes[msg.addr]
+		delete(state.addedNodes, msg.addr)
+
+		found = disconnectPeer(state.persistentPeers, msg.cmp, func(sp *serverPeer) {
 			// Keep group counts ok since we remove from
 			// the list now.
 			state.outboundGroups[addrmgr.GroupKey(sp.NA())]--
-		})
+			for addr, connReq := range state.addedNodes {
+				if connReq == sp.connReq {
+					delete(state.addedNodes, addr)
+				}
+			}
+		}) || found
 
 		if found {
 			msg.reply <- nil
 		} else {
-			msg.reply <- errors.New("peer not found")
+			msg.reply <- errNodeNotAdded
 		}
 	case getOutboundGroup:
 		count, ok := state.outboundGroups[msg.key]
@@ -1619,11 +2070,25 @@ func (s *server) handleQuery(state *peerState, querymsg interface{}) {
 			peers = append(peers, sp)
 		}
 		msg.reply <- peers
+	case getServerPeerMsg:
+		// Respond with the server peer associated with the peer, if
+		// any.
+		var found *serverPeer
+		state.forAllPeers(func(sp *serverPeer) {
+			if sp.Peer == msg.peer {
+				found = sp
+			}
+		})
+		msg.reply <- found
 	case disconnectNodeMsg:
 		// Check inbound peers. We pass a nil callback since we don't
 		// require any additional actions on disconnect for inbound peers.
 		found := disconnectPeer(state.inboundPeers, msg.cmp, nil)
 		if found {
+			// Disconnect all matching inbound peers.
+			for found {
+				found = disconnectPeer(state.inboundPeers, msg.cmp, nil)
+			}
 			msg.reply <- nil
 			return
 		}
@@ -1680,22 +2145,28 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 	return &peer.Config{
 		Listeners: peer.MessageListeners{
 			OnVersion:      sp.OnVersion,
+			OnVerAck:       sp.OnVerAck,
 			OnMemPool:      sp.OnMemPool,
 			OnTx:           sp.OnTx,
 			OnBlock:        sp.OnBlock,
+			OnCmpctBlock:   sp.OnCmpctBlock,
+			OnBlockTxn:     sp.OnBlockTxn,
 			OnInv:          sp.OnInv,
 			OnHeaders:      sp.OnHeaders,
 			OnGetData:      sp.OnGetData,
 			OnGetBlocks:    sp.OnGetBlocks,
 			OnGetHeaders:   sp.OnGetHeaders,
+			OnGetBlockTxn:  sp.OnGetBlockTxn,
 			OnGetCFilter:   sp.OnGetCFilter,
 			OnGetCFHeaders: sp.OnGetCFHeaders,
+			OnGetCFCheckpt: sp.OnGetCFCheckpt,
 			OnFeeFilter:    sp.OnFeeFilter,
 			OnFilterAdd:    sp.OnFilterAdd,
 			OnFilterClear:  sp.OnFilterClear,
 			OnFilterLoad:   sp.OnFilterLoad,
 			OnGetAddr:      sp.OnGetAddr,
 			OnAddr:         sp.OnAddr,
+			OnAddrV2:       sp.OnAddrV2,
 			OnRead:         sp.OnRead,
 			OnWrite:        sp.OnWrite,
 
@@ -1713,7 +2184,7 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 		UserAgentComments: cfg.UserAgentComments,
 		ChainParams:       sp.server.chainParams,
 		Services:          sp.server.services,
-		DisableRelayTx:    cfg.BlocksOnly,
+		DisableRelayTx:    sp.blocksOnly(),
 		ProtocolVersion:   peer.MaxProtocolVersion,
 	}
 }
@@ -1721,8 +2192,24 @@ func newPeerConfig(sp *serverPeer) *peer.Config {
 // inboundPeerConnected is invoked by the connection manager when a new inbound
 // connection is established.  It initializes a new inbound server peer
 // instance, associates it with the connection, and starts a goroutine to wait
-// for disconnection.
+// for disconnection.  Connections from banned addresses and from networks not
+// allowed by --onlynet are closed instead.
 func (s *server) inboundPeerConnected(conn net.Conn) {
+	// Drop connections from banned addresses before the handshake.
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err == nil && s.banList.IsBanned(net.ParseIP(host)) {
+		srvrLog.Debugf("Rejected inbound connection from banned "+
+			"address %s", host)
+		conn.Close()
+		return
+	}
+	if netType, ok := connNetType(conn); ok && !onlyNetAllowed(netType) {
+		srvrLog.Debugf("Rejected inbound connection from %s on the %s "+
+			"network", conn.RemoteAddr(), netType)
+		conn.Close()
+		return
+	}
+
 	sp := newServerPeer(s, false)
 	sp.Peer = peer.NewInboundPeer(newPeerConfig(sp))
 	sp.AssociateConnection(conn)
@@ -1736,6 +2223,7 @@ func (s *server) inboundPeerConnected(conn net.Conn) {
 // manager of the attempt.
 func (s *server) outboundPeerConnected(c *connmgr.ConnReq, conn net.Conn) {
 	sp := newServerPeer(s, c.Permanent)
+	sp.blockRelayOnly = c.BlockRelayOnly
 	p, err := peer.NewOutboundPeer(newPeerConfig(sp), c.Addr.String())
 	if err != nil {
 		srvrLog.Debugf("Cannot create outbound peer %s: %v", c.Addr, err)
@@ -1769,6 +2257,30 @@ func (s *server) peerDoneHandler(sp *serverPeer) {
 	close(sp.quit)
 }
 
+// seedAddrManager adds peers discovered through the DNS seeds of the network
+// to the address manager.  When none of the DNS seeds return any addresses and
+// the use of fixed seeds is enabled, the fixed seeds of the network are added
+// instead.  It blocks until seeding is complete.
+func (s *server) seedAddrManager(params *chaincfg.Params,
+	lookupFn connmgr.LookupFunc, useFixedSeeds bool) {
+
+	seedFn := func(addrs []*wire.NetAddress) {
+		// Bitcoind uses a lookup of the dns seeder here. This
+		// is rather strange since the values looked up by the
+		// DNS seed lookups will vary quite a lot.
+		// to replicate this behaviour we put all addresses as
+		// having come from the first one.
+		s.addrManager.AddAddresses(addrs, addrs[0])
+	}
+	if !useFixedSeeds {
+		connmgr.SeedFromDNS(params, defaultRequiredServices, lookupFn,
+			seedFn)
+		return
+	}
+	connmgr.SeedFromDNSWithFallback(params, defaultRequiredServices,
+		lookupFn, seedFn)
+}
+
 // peerHandler is used to handle peer operations such as adding and removing
 // peers to and from the server, banning peers, and broadcasting messages to
 // peers.  It must be run in a goroutine.
@@ -1787,24 +2299,25 @@ func (s *server) peerHandler() {
 		inboundPeers:    make(map[int32]*serverPeer),
 		persistentPeers: make(map[int32]*serverPeer),
 		outboundPeers:   make(map[int32]*serverPeer),
-		banned:          make(map[string]time.Time),
 		outboundGroups:  make(map[string]int),
+		addedNodes:      s.addedNodes,
 	}
 
-	if !cfg.DisableDNSSeed {
-		// Add peers discovered through DNS to the address manager.
-		connmgr.SeedFromDNS(activeNetParams.Params, defaultRequiredServices,
-			ltcdLookup, func(addrs []*wire.NetAddress) {
-				// Bitcoind uses a lookup of the dns seeder here. This
-				// is rather strange since the values looked up by the
-				// DNS seed lookups will vary quite a lot.
-				// to replicate this behaviour we put all addresses as
-				// having come from the first one.
-				s.addrManager.AddAddresses(addrs, addrs[0])
-			})
+	// The seeds only provide clearnet addresses, so there is no point in
+	// seeding when --onlynet excludes them.
+	if !cfg.DisableDNSSeed && (onlyNetAllowed(addrmgr.NetworkIPv4) ||
+		onlyNetAllowed(addrmgr.NetworkIPv6)) {
+
+		go s.seedAddrManager(activeNetParams.Params, cfg.seedLookup,
+			!cfg.NoFixedSeeds)
 	}
 	go s.connManager.Start()
 
+	// Start up persistent peers.
+	for _, connReq := range state.addedNodes {
+		go s.connManager.Connect(connReq)
+	}
+
 out:
 	for {
 		select {
@@ -1847,6 +2360,9 @@ out:
 	}
 
 	s.connManager.Stop()
+	if s.i2pSession != nil {
+		s.i2pSession.Close()
+	}
 	s.blockManager.Stop()
 	s.addrManager.Stop()
 
@@ -1879,6 +2395,22 @@ func (s *server) BanPeer(sp *serverPeer) {
 	s.banPeers <- sp
 }
 
+// AddBanScore increases the ban score of the server peer associated with the
+// passed peer by the provided persistent and decaying values.  The peer is
+// banned and disconnected when the resulting score reaches the ban threshold.
+// It is part of the PeerNotifier interface implementation.
+func (s *server) AddBanScore(p *peer.Peer, persistent, transient uint32, reason string) {
+	replyChan := make(chan *serverPeer)
+	select {
+	case s.query <- getServerPeerMsg{peer: p, reply: replyChan}:
+	case <-s.quit:
+		return
+	}
+	if sp := <-replyChan; sp != nil {
+		sp.addBanScore(persistent, transient, reason)
+	}
+}
+
 // RelayInventory relays the passed inventory vector to all connected peers
 // that are not already known to have it.
 func (s *server) RelayInventory(invVect *wire.InvVect, data interface{}) {
@@ -1942,38 +2474,23 @@ func (s *server) UpdatePeerHeights(latestBlkHash *chainhash.Hash, latestHeight i
 	}
 }
 
-// rebroadcastHandler keeps track of user submitted inventories that we have
-// sent out but have not yet made it into a block. We periodically rebroadcast
-// them in case our peers restarted or otherwise lost track of them.
+// rebroadcastHandler periodically announces the transactions submitted
+// through the RPC server which are not yet known to have been received by any
+// peer in case they were submitted while no peers were connected or our peers
+// restarted or otherwise lost track of them.  The interval is randomized to
+// make it harder to identify the transactions which were submitted locally.
 func (s *server) rebroadcastHandler() {
 	// Wait 5 min before first tx rebroadcast.
 	timer := time.NewTimer(5 * time.Minute)
-	pendingInvs := make(map[wire.InvVect]interface{})
 
 out:
 	for {
 		select {
-		case riv := <-s.modifyRebroadcastInv:
-			switch msg := riv.(type) {
-			// Incoming InvVects are added to our map of RPC txs.
-			case broadcastInventoryAdd:
-				pendingInvs[*msg.invVect] = msg.data
-
-			// When an InvVect has been added to a block, we can
-			// now remove it, if it was present.
-			case broadcastInventoryDel:
-				if _, ok := pendingInvs[*msg]; ok {
-					delete(pendingInvs, *msg)
-				}
-			}
-
 		case <-timer.C:
-			// Any inventory we have has not made it into a block
-			// yet. We periodically resubmit them until they have.
-			for iv, data := range pendingInvs {
-				ivCopy := iv
-				s.RelayInventory(&ivCopy, data)
-			}
+			// Any transaction we have has not been requested by a
+			// peer or made it into a block yet.  We periodically
+			// resubmit them until they have.
+			s.relayTransactions(s.txMemPool.UnbroadcastTxDescs())
 
 			// Process at a random time up to 30mins (in seconds)
 			// in the future.
@@ -1986,17 +2503,6 @@ out:
 	}
 
 	timer.Stop()
-
-	// Drain channels before exiting so nothing is left waiting around
-	// to send.
-cleanup:
-	for {
-		select {
-		case <-s.modifyRebroadcastInv:
-		default:
-			break cleanup
-		}
-	}
 	s.wg.Done()
 }
 
@@ -2017,6 +2523,9 @@ func (s *server) Start() {
 	s.wg.Add(1)
 	go s.peerHandler()
 
+	// Start evicting expired orphan transactions from the memory pool.
+	s.txMemPool.Start()
+
 	if s.nat != nil {
 		s.wg.Add(1)
 		go s.upnpUpdateThread()
@@ -2036,6 +2545,11 @@ func (s *server) Start() {
 	if cfg.Generate {
 		s.cpuMiner.Start()
 	}
+
+	// Start the stratum server if it is enabled.
+	if s.stratumServer != nil {
+		s.stratumServer.Start()
+	}
 }
 
 // Stop gracefully shuts down the server by stopping and disconnecting all
@@ -2052,11 +2566,37 @@ func (s *server) Stop() error {
 	// Stop the CPU miner if needed
 	s.cpuMiner.Stop()
 
+	// Stop the stratum server if needed.
+	if s.stratumServer != nil {
+		s.stratumServer.Stop()
+	}
+
+	// Stop evicting expired orphan transactions from the memory pool.
+	s.txMemPool.Stop()
+
 	// Shutdown the RPC server if it's not disabled.
 	if !cfg.DisableRPC {
 		s.rpcServer.Stop()
 	}
 
+	// Stop publishing ZMQ notifications if needed.
+	if s.zmqNotifier != nil {
+		s.zmqNotifier.Stop()
+	}
+
+	// Stop catching up the indexes in the background if needed.
+	if s.indexManager != nil {
+		s.indexManager.Stop()
+	}
+
+	// Save fee estimator state in the database so that it can be restored
+	// the next time the server starts.
+	s.db.Update(func(tx database.Tx) error {
+		metadata := tx.Metadata()
+		metadata.Put(mempool.EstimateFeeDatabaseKey, s.feeEstimator.Save())
+		return nil
+	})
+
 	// Signal the remaining goroutines to quit.
 	close(s.quit)
 	return nil
@@ -2270,10 +2810,41 @@ func setupRPCListeners() ([]net.Listener, error) {
 	return listeners, nil
 }
 
+// setupStratumListeners returns a slice of listeners that are configured for
+// use with the stratum server depending on the configuration settings.
+func setupStratumListeners() ([]net.Listener, error) {
+	ipv4Addrs, ipv6Addrs, _, err := parseListeners(cfg.StratumListeners)
+	if err != nil {
+		return nil, err
+	}
+	listeners := make([]net.Listener, 0, len(ipv4Addrs)+len(ipv6Addrs))
+	for _, addr := range ipv4Addrs {
+		listener, err := net.Listen("tcp4", addr)
+		if err != nil {
+			minrLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	for _, addr := range ipv6Addrs {
+		listener, err := net.Listen("tcp6", addr)
+		if err != nil {
+			minrLog.Warnf("Can't listen on %s: %v", addr, err)
+			continue
+		}
+		listeners = append(listeners, listener)
+	}
+
+	return listeners, nil
+}
+
 // newServer returns a new ltcd server configured to listen on addr for the
 // bitcoin network type specified by chainParams.  Use start to begin accepting
-// connections from peers.
-func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Params) (*server, error) {
+// connections from peers.  Closing the interrupt channel stops a reindex of the
+// chain performed while creating the server, in which case an error is
+// returned.
+func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Params, interrupt <-chan struct{}) (*server, error) {
 	services := defaultServices
 	if cfg.NoPeerBloomFilters {
 		services &^= wire.SFNodeBloom
@@ -2281,9 +2852,33 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 	if cfg.NoCFilters {
 		services &^= wire.SFNodeCF
 	}
+	if cfg.Prune != 0 {
+		services = services&^wire.SFNodeNetwork | wire.SFNodeNetworkLimited
+	}
 
 	amgr := addrmgr.New(cfg.DataDir, ltcdLookup)
 
+	// An I2P session is used to connect to I2P peers when a SAM bridge is
+	// configured.  The address of each new session is advertised to peers
+	// when listening.
+	var i2pSession *connmgr.I2PSession
+	if cfg.I2PSAM != "" {
+		i2pSession = connmgr.NewI2PSession(cfg.I2PSAM,
+			func(addr *connmgr.I2PAddr) {
+				if cfg.DisableListen {
+					return
+				}
+				na, err := amgr.HostToNetAddress(addr.Host, 0, services)
+				if err == nil {
+					err = amgr.AddLocalAddress(na, addrmgr.BoundPrio)
+				}
+				if err != nil {
+					amgrLog.Warnf("Skipping I2P address %s: %v",
+						addr.Host, err)
+				}
+			})
+	}
+
 	var listeners []net.Listener
 	var nat NAT
 	if !cfg.DisableListen {
@@ -2403,29 +2998,51 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 			}
 		}
 
+		// Connections forwarded by a tor hidden service are accepted
+		// on the onion listeners.  Their addresses are local, so they
+		// are not advertised.
+		for _, addr := range cfg.OnionListeners {
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				srvrLog.Warnf("Can't listen on %s: %v", addr,
+					err)
+				continue
+			}
+			listeners = append(listeners, &onionListener{listener})
+		}
+
+		// Connections from I2P peers are accepted through the I2P
+		// session.
+		if i2pSession != nil {
+			listeners = append(listeners, i2pSession)
+		}
+
 		if len(listeners) == 0 {
 			return nil, errors.New("no valid listen address")
 		}
 	}
 
 	s := server{
-		chainParams:          chainParams,
-		addrManager:          amgr,
-		newPeers:             make(chan *serverPeer, cfg.MaxPeers),
-		donePeers:            make(chan *serverPeer, cfg.MaxPeers),
-		banPeers:             make(chan *serverPeer, cfg.MaxPeers),
-		query:                make(chan interface{}),
-		relayInv:             make(chan relayMsg, cfg.MaxPeers),
-		broadcast:            make(chan broadcastMsg, cfg.MaxPeers),
-		quit:                 make(chan struct{}),
-		modifyRebroadcastInv: make(chan interface{}),
-		peerHeightsUpdate:    make(chan updatePeerHeightsMsg),
-		nat:                  nat,
-		db:                   db,
-		timeSource:           blockchain.NewMedianTime(),
-		services:             services,
-		sigCache:             txscript.NewSigCache(cfg.SigCacheMaxSize),
-		hashCache:            txscript.NewHashCache(cfg.SigCacheMaxSize),
+		chainParams:       chainParams,
+		addrManager:       amgr,
+		banList:           newBanList(filepath.Join(cfg.DataDir, banListFilename)),
+		newPeers:          make(chan *serverPeer, cfg.MaxPeers),
+		donePeers:         make(chan *serverPeer, cfg.MaxPeers),
+		banPeers:          make(chan *serverPeer, cfg.MaxPeers),
+		query:             make(chan interface{}),
+		relayInv:          make(chan relayMsg, cfg.MaxPeers),
+		broadcast:         make(chan broadcastMsg, cfg.MaxPeers),
+		quit:              make(chan struct{}),
+		peerHeightsUpdate: make(chan updatePeerHeightsMsg),
+		nat:               nat,
+		i2pSession:        i2pSession,
+		db:                db,
+		timeSource:        blockchain.NewMedianTime(),
+		services:          services,
+		banHalflife:       cfg.BanHalflife,
+		sigCache:          txscript.NewSigCache(cfg.SigCacheMaxSize),
+		hashCache:         txscript.NewHashCache(cfg.SigCacheMaxSize),
+		scriptCache:       txscript.NewScriptCache(cfg.ScriptCacheMaxSize),
 	}
 
 	// Create the transaction and address indexes if needed.
@@ -2456,47 +3073,112 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 	}
 	if !cfg.NoCFilters {
 		indxLog.Info("cf index is enabled")
-		s.cfIndex = indexers.NewCfIndex(db, chainParams)
+		s.cfIndex = indexers.NewCfIndex(db, chainParams,
+			cfg.CfIndexVerify)
 		indexes = append(indexes, s.cfIndex)
 	}
 
 	// Create an index manager if any of the optional indexes are enabled.
 	var indexManager blockchain.IndexManager
 	if len(indexes) > 0 {
-		indexManager = indexers.NewManager(db, indexes)
+		s.indexManager = indexers.NewManager(db, indexes)
+		indexManager = s.indexManager
 	}
 
 	// Merge given checkpoints with the default ones unless they are disabled.
+	// The assumed valid block is ignored along with them since nothing else
+	// vouches for the chain it is on.
 	var checkpoints []chaincfg.Checkpoint
+	var assumeValid *chaincfg.Checkpoint
 	if !cfg.DisableCheckpoints {
 		checkpoints = mergeCheckpoints(s.chainParams.Checkpoints, cfg.addCheckpoints)
+		assumeValid = cfg.assumeValid
 	}
 
 	// Create a new block chain instance with the appropriate configuration.
+	reindex := blockchain.ReindexNone
+	switch {
+	case cfg.Reindex:
+		reindex = blockchain.ReindexFull
+	case cfg.ReindexChainState:
+		reindex = blockchain.ReindexChainState
+	}
 	var err error
 	s.chain, err = blockchain.New(&blockchain.Config{
-		DB:           s.db,
-		ChainParams:  s.chainParams,
-		Checkpoints:  checkpoints,
-		TimeSource:   s.timeSource,
-		SigCache:     s.sigCache,
-		IndexManager: indexManager,
-		HashCache:    s.hashCache,
+		DB:               s.db,
+		ChainParams:      s.chainParams,
+		Checkpoints:      checkpoints,
+		TimeSource:       s.timeSource,
+		SigCache:         s.sigCache,
+		IndexManager:     indexManager,
+		HashCache:        s.hashCache,
+		ScriptCache:      s.scriptCache,
+		PruneTarget:      cfg.Prune * 1024 * 1024,
+		UtxoCacheMaxSize: cfg.DbCache * 1024 * 1024,
+		AssumeValid:      assumeValid,
+		ScriptWorkers:    int(cfg.ScriptWorkers),
+		MaxOrphanBlocks:  cfg.MaxOrphanBlocks,
+		Reindex:          reindex,
+		Interrupt:        interrupt,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	// Only advertise serving recent blocks when blocks were previously
+	// pruned from the database even though pruning is no longer enabled.
+	if s.chain.PruneHeight() >= 0 {
+		s.services = s.services&^wire.SFNodeNetwork |
+			wire.SFNodeNetworkLimited
+	}
+
+	// Search for a FeeEstimator state in the database.  If none can be
+	// found, it is stale, or it cannot be loaded, create a new one.
+	bestHeight := s.chain.BestSnapshot().Height
+	db.Update(func(tx database.Tx) error {
+		metadata := tx.Metadata()
+		feeEstimationData := metadata.Get(mempool.EstimateFeeDatabaseKey)
+		if feeEstimationData == nil {
+			return nil
+		}
+
+		// Delete it from the database so that the same state is never
+		// restored twice should the server not shut down cleanly.
+		metadata.Delete(mempool.EstimateFeeDatabaseKey)
+
+		var err error
+		s.feeEstimator, err = mempool.RestoreFeeEstimator(
+			feeEstimationData, bestHeight)
+		if err != nil {
+			srvrLog.Infof("Discarding saved fee estimator state: %v",
+				err)
+		}
+		return nil
+	})
+	if s.feeEstimator == nil {
+		s.feeEstimator = mempool.NewFeeEstimator(
+			mempool.DefaultEstimateFeeMaxRollback,
+			mempool.DefaultEstimateFeeMinRegisteredBlocks)
+	}
+
 	txC := mempool.Config{
 		Policy: mempool.Policy{
 			DisableRelayPriority: cfg.NoRelayPriority,
 			AcceptNonStd:         cfg.RelayNonStd,
 			FreeTxRelayLimit:     cfg.FreeTxRelayLimit,
 			MaxOrphanTxs:         cfg.MaxOrphanTxs,
-			MaxOrphanTxSize:      defaultMaxOrphanTxSize,
+			MaxOrphanTxSize:      cfg.MaxOrphanTxSize,
+			OrphanTTL:            cfg.OrphanTTL,
 			MaxSigOpCostPerTx:    blockchain.MaxBlockSigOpsCost / 4,
 			MinRelayTxFee:        cfg.minRelayTxFee,
+			MaxPoolSize:          int64(cfg.MaxMempool) * 1000000,
+			Expiry:               cfg.MempoolExpiry,
+			MaxAncestorCount:     cfg.LimitAncestorCount,
+			MaxAncestorSize:      int64(cfg.LimitAncestorSize) * 1000,
+			MaxDescendantCount:   cfg.LimitDescendantCount,
+			MaxDescendantSize:    int64(cfg.LimitDescendantSize) * 1000,
 			MaxTxVersion:         2,
+			AcceptRBF:            cfg.AcceptRBF,
 		},
 		ChainParams:    chainParams,
 		FetchUtxoView:  s.chain.FetchUtxoView,
@@ -2508,7 +3190,23 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		IsDeploymentActive: s.chain.IsDeploymentActive,
 		SigCache:           s.sigCache,
 		HashCache:          s.hashCache,
+		ScriptCache:        s.scriptCache,
 		AddrIndex:          s.addrIndex,
+		FeeEstimator:       s.feeEstimator,
+		OnTxAdded: func(tx *ltcutil.Tx, sequence uint64) {
+			if s.zmqNotifier != nil {
+				s.zmqNotifier.NotifyTxAdded(tx, sequence)
+			}
+		},
+		OnTxRemoved: func(tx *ltcutil.Tx, reason mempool.RemovalReason, sequence uint64) {
+			if s.rpcServer != nil {
+				s.rpcServer.NotifyTxRemoved(tx, reason)
+			}
+			if s.zmqNotifier != nil {
+				s.zmqNotifier.NotifyTxRemoved(tx, reason,
+					sequence)
+			}
+		},
 	}
 	s.txMemPool = mempool.New(&txC)
 
@@ -2516,6 +3214,7 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		PeerNotifier:       &s,
 		Chain:              s.chain,
 		TxMemPool:          s.txMemPool,
+		FeeEstimator:       s.feeEstimator,
 		ChainParams:        s.chainParams,
 		DisableCheckpoints: cfg.DisableCheckpoints,
 		MaxPeers:           cfg.MaxPeers,
@@ -2540,6 +3239,25 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 	blockTemplateGenerator := mining.NewBlkTmplGenerator(&policy,
 		s.chainParams, s.txMemPool, s.chain, s.timeSource,
 		s.sigCache, s.hashCache)
+	if err := blockTemplateGenerator.SetExtraCoinbaseData(
+		[]byte(cfg.MiningFlags)); err != nil {
+
+		return nil, err
+	}
+	for _, bit := range cfg.SignalBits {
+		signal := true
+		err := blockTemplateGenerator.SetVersionBit(bit, &signal)
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, bit := range cfg.NoSignalBits {
+		signal := false
+		err := blockTemplateGenerator.SetVersionBit(bit, &signal)
+		if err != nil {
+			return nil, err
+		}
+	}
 	s.cpuMiner = cpuminer.New(&cpuminer.Config{
 		ChainParams:            chainParams,
 		BlockTemplateGenerator: blockTemplateGenerator,
@@ -2549,6 +3267,29 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		IsCurrent:              s.blockManager.IsCurrent,
 	})
 
+	// Setup the stratum server when it is enabled.
+	if len(cfg.StratumListeners) > 0 {
+		stratumListeners, err := setupStratumListeners()
+		if err != nil {
+			return nil, err
+		}
+		if len(stratumListeners) == 0 {
+			return nil, errors.New("Stratum: No valid listen address")
+		}
+
+		s.stratumServer, err = stratum.New(&stratum.Config{
+			ChainParams:            chainParams,
+			BlockTemplateGenerator: blockTemplateGenerator,
+			MiningAddrs:            cfg.miningAddrs,
+			ProcessBlock:           s.blockManager.ProcessBlock,
+			IsCurrent:              s.blockManager.IsCurrent,
+			Listeners:              stratumListeners,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Only setup a function to return new addresses to connect to when
 	// not running in connect-only mode.  The simulation network is always
 	// in connect-only mode since it is only intended to connect to
@@ -2557,78 +3298,51 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 	// network.
 	var newAddressFunc func() (net.Addr, error)
 	if !cfg.SimNet && len(cfg.ConnectPeers) == 0 {
-		newAddressFunc = func() (net.Addr, error) {
-			for tries := 0; tries < 100; tries++ {
-				addr := s.addrManager.GetAddress()
-				if addr == nil {
-					break
-				}
-
-				// Address will not be invalid, local or unroutable
-				// because addrmanager rejects those on addition.
-				// Just check that we don't already have an address
-				// in the same group so that we are not connecting
-				// to the same network segment at the expense of
-				// others.
-				key := addrmgr.GroupKey(addr.NetAddress())
-				if s.OutboundGroupCount(key) != 0 {
-					continue
-				}
-
-				// only allow recent nodes (10mins) after we failed 30
-				// times
-				if tries < 30 && time.Since(addr.LastAttempt()) < 10*time.Minute {
-					continue
-				}
-
-				// allow nondefault ports after 50 failed tries.
-				if tries < 50 && fmt.Sprintf("%d", addr.NetAddress().Port) !=
-					activeNetParams.DefaultPort {
-					continue
-				}
-
-				addrString := addrmgr.NetAddressKey(addr.NetAddress())
-				return addrStringToNetAddr(addrString)
-			}
-
-			return nil, errors.New("no valid connect address")
-		}
+		newAddressFunc = s.newAddress
 	}
 
-	// Create a connection manager.
+	// Create a connection manager.  The block-relay-only peers are limited
+	// to the peers left by the regular outbound peers.
 	targetOutbound := defaultTargetOutbound
 	if cfg.MaxPeers < targetOutbound {
 		targetOutbound = cfg.MaxPeers
 	}
+	targetBlockRelayOnly := int(cfg.BlockRelayPeers)
+	if cfg.MaxPeers-targetOutbound < targetBlockRelayOnly {
+		targetBlockRelayOnly = cfg.MaxPeers - targetOutbound
+	}
 	cmgr, err := connmgr.New(&connmgr.Config{
-		Listeners:      listeners,
-		OnAccept:       s.inboundPeerConnected,
-		RetryDuration:  connectionRetryInterval,
-		TargetOutbound: uint32(targetOutbound),
-		Dial:           ltcdDial,
-		OnConnection:   s.outboundPeerConnected,
-		GetNewAddress:  newAddressFunc,
+		Listeners:            listeners,
+		OnAccept:             s.inboundPeerConnected,
+		RetryDuration:        connectionRetryInterval,
+		TargetOutbound:       uint32(targetOutbound),
+		TargetBlockRelayOnly: uint32(targetBlockRelayOnly),
+		Dial:                 s.dial,
+		OnConnection:         s.outboundPeerConnected,
+		GetNewAddress:        newAddressFunc,
 	})
 	if err != nil {
 		return nil, err
 	}
 	s.connManager = cmgr
 
-	// Start up persistent peers.
+	// Create the connection requests for the persistent peers.  They are
+	// tracked as added nodes and connected once the peer handler starts.
 	permanentPeers := cfg.ConnectPeers
 	if len(permanentPeers) == 0 {
 		permanentPeers = cfg.AddPeers
 	}
+	s.addedNodes = make(map[string]*connmgr.ConnReq, len(permanentPeers))
 	for _, addr := range permanentPeers {
 		netAddr, err := addrStringToNetAddr(addr)
 		if err != nil {
 			return nil, err
 		}
 
-		go s.connManager.Connect(&connmgr.ConnReq{
+		s.addedNodes[addr] = &connmgr.ConnReq{
 			Addr:      netAddr,
 			Permanent: true,
-		})
+		}
 	}
 
 	if !cfg.DisableRPC {
@@ -2642,20 +3356,32 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 			return nil, errors.New("RPCS: No valid listen address")
 		}
 
+		// Clients are allowed to authenticate with a cookie written to
+		// the data directory unless it is disabled.
+		var cookiePath string
+		if !cfg.DisableRPCCookie {
+			cookiePath = filepath.Join(cfg.DataDir,
+				defaultRPCCookieFilename)
+		}
+
 		s.rpcServer, err = newRPCServer(&rpcserverConfig{
-			Listeners:   rpcListeners,
-			StartupTime: s.startupTime,
-			ConnMgr:     &rpcConnManager{&s},
-			SyncMgr:     &rpcSyncMgr{&s, s.blockManager},
-			TimeSource:  s.timeSource,
-			Chain:       s.blockManager.chain,
-			ChainParams: chainParams,
-			DB:          db,
-			TxMemPool:   s.txMemPool,
-			Generator:   blockTemplateGenerator,
-			CPUMiner:    s.cpuMiner,
-			TxIndex:     s.txIndex,
-			AddrIndex:   s.addrIndex,
+			Listeners:    rpcListeners,
+			StartupTime:  s.startupTime,
+			CookiePath:   cookiePath,
+			ConnMgr:      &rpcConnManager{&s},
+			SyncMgr:      &rpcSyncMgr{&s, s.blockManager},
+			TimeSource:   s.timeSource,
+			Chain:        s.blockManager.chain,
+			ChainParams:  chainParams,
+			DB:           db,
+			TxMemPool:    s.txMemPool,
+			FeeEstimator: s.feeEstimator,
+			Generator:    blockTemplateGenerator,
+			CPUMiner:     s.cpuMiner,
+			SigCache:     s.sigCache,
+			ScriptCache:  s.scriptCache,
+			TxIndex:      s.txIndex,
+			AddrIndex:    s.addrIndex,
 		})
 		if err != nil {
 			return nil, err
@@ -2668,9 +3394,134 @@ func newServer(listenAddrs []string, db database.DB, chainParams *chaincfg.Param
 		}()
 	}
 
+	// Publish ZMQ notifications of connected blocks and accepted
+	// transactions when any of the topics are configured.
+	s.zmqNotifier, err = newZMQNotifier(zmqTopics(cfg))
+	if err != nil {
+		return nil, err
+	}
+	if s.zmqNotifier != nil {
+		s.chain.Subscribe(s.zmqNotifier.handleBlockchainNotification)
+	}
+
 	return &s, nil
 }
 
+// newAddress returns the address of the next peer the connection manager
+// should automatically connect to, which is chosen from the address manager
+// while avoiding network segments that are already connected to as well as
+// networks that can't be reached or are not allowed by --onlynet.
+func (s *server) newAddress() (net.Addr, error) {
+	for tries := 0; tries < 100; tries++ {
+		addr := s.addrManager.GetAddress()
+		if addr == nil {
+			break
+		}
+
+		// Never connect to networks excluded by --onlynet, such as
+		// clearnet addresses when only onion peers are allowed.
+		if !onlyNetAllowed(addrmgr.NetType(addr.NetAddress())) {
+			continue
+		}
+
+		// Address will not be invalid, local or unroutable
+		// because addrmanager rejects those on addition.
+		// Just check that we don't already have an address
+		// in the same group so that we are not connecting
+		// to the same network segment at the expense of
+		// others.
+		key := addrmgr.GroupKey(addr.NetAddress())
+		if s.OutboundGroupCount(key) != 0 {
+			continue
+		}
+
+		// CJDNS addresses are only reachable when this
+		// host is joined to the overlay network.
+		if !cfg.CJDNSReachable &&
+			addrmgr.IsCJDNS(addr.NetAddress()) {
+
+			continue
+		}
+
+		// Tor addresses can only be dialed through the
+		// onion proxy and I2P addresses through the I2P
+		// session.
+		if (cfg.NoOnion && addrmgr.IsTor(addr.NetAddress())) ||
+			(cfg.I2PSAM == "" && addrmgr.IsI2P(addr.NetAddress())) {
+
+			continue
+		}
+
+		// only allow recent nodes (10mins) after we failed 30
+		// times
+		if tries < 30 && time.Since(addr.LastAttempt()) < 10*time.Minute {
+			continue
+		}
+
+		// allow nondefault ports after 50 failed tries.
+		// I2P addresses have no ports.
+		if tries < 50 && fmt.Sprintf("%d", addr.NetAddress().Port) !=
+			activeNetParams.DefaultPort &&
+			!addrmgr.IsI2P(addr.NetAddress()) {
+			continue
+		}
+
+		addrString := addrmgr.NetAddressKey(addr.NetAddress())
+		return addrStringToNetAddr(addrString)
+	}
+
+	return nil, errors.New("no valid connect address")
+}
+
+// onionListener wraps a listener for connections forwarded by a tor hidden
+// service so the accepted connections are known to come from onion peers.
+type onionListener struct {
+	net.Listener
+}
+
+// Accept waits for and returns the next connection forwarded by the hidden
+// service.  This is part of the net.Listener interface.
+func (l *onionListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &onionConn{conn}, nil
+}
+
+// onionConn is a connection accepted by an onion listener.
+type onionConn struct {
+	net.Conn
+}
+
+// connNetType returns the network the remote peer of the passed connection
+// is on, along with whether or not it could be determined.
+func connNetType(conn net.Conn) (addrmgr.NetworkType, bool) {
+	if _, ok := conn.(*onionConn); ok {
+		return addrmgr.NetworkOnion, true
+	}
+	switch addr := conn.RemoteAddr().(type) {
+	case *connmgr.I2PAddr:
+		return addrmgr.NetworkI2P, true
+	case *net.TCPAddr:
+		return addrmgr.NetType(&wire.NetAddress{IP: addr.IP}), true
+	}
+	return 0, false
+}
+
+// dial connects to the passed address for the connection manager.  I2P
+// addresses are dialed through the I2P session while all other addresses are
+// dialed with ltcdDial.
+func (s *server) dial(addr net.Addr) (net.Conn, error) {
+	if i2pAddr, ok := addr.(*connmgr.I2PAddr); ok {
+		if s.i2pSession == nil {
+			return nil, errors.New("i2p has not been enabled")
+		}
+		return s.i2pSession.Dial(i2pAddr.Host)
+	}
+	return ltcdDial(addr)
+}
+
 // addrStringToNetAddr takes an address in the form of 'host:port' and returns
 // a net.Addr which maps to the original address with any host names resolved
 // to IP addresses.  It also handles tor addresses properly by returning a
@@ -2694,6 +3545,15 @@ func addrStringToNetAddr(addr string) (net.Addr, error) {
 		}, nil
 	}
 
+	// I2P addresses are dialed through the I2P session.
+	if strings.HasSuffix(host, ".b32.i2p") {
+		if cfg.I2PSAM == "" {
+			return nil, errors.New("i2p has not been enabled")
+		}
+
+		return &connmgr.I2PAddr{Host: host}, nil
+	}
+
 	// Tor addresses cannot be resolved to an IP, so just return an onion
 	// address instead.
 	if strings.HasSuffix(host, ".onion") {
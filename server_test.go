@@ -0,0 +1,906 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btclog"
+	"github.com/ltcsuite/ltcd/addrmgr"
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/connmgr"
+	"github.com/ltcsuite/ltcd/mempool"
+	"github.com/ltcsuite/ltcd/peer"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// TestPeerByteAccounting ensures the bytes sent to and received from a peer are
+// accounted to the commands of the exchanged messages and that the totals
+// advance as messages are exchanged.
+func TestPeerByteAccounting(t *testing.T) {
+	verack := make(chan struct{}, 1)
+	pong := make(chan struct{}, 1)
+	sp := newServerPeer(&server{}, false)
+	peerCfg := &peer.Config{
+		Listeners: peer.MessageListeners{
+			OnVerAck: func(p *peer.Peer, msg *wire.MsgVerAck) {
+				verack <- struct{}{}
+			},
+			OnPong: func(p *peer.Peer, msg *wire.MsgPong) {
+				pong <- struct{}{}
+			},
+			OnRead:  sp.OnRead,
+			OnWrite: sp.OnWrite,
+		},
+		UserAgentName:    "peer",
+		UserAgentVersion: "1.0",
+		ChainParams:      &chaincfg.MainNetParams,
+		Services:         0,
+	}
+	var err error
+	sp.Peer, err = peer.NewOutboundPeer(peerCfg, "127.0.0.1:9333")
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: unexpected error: %v", err)
+	}
+
+	// The test acts as the remote peer and tracks the bytes it exchanges
+	// with the peer for each command.  Since the remote peer is in the same
+	// process, it must be driven manually to avoid the detection of self
+	// connections by the peer.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer listener.Close()
+	localConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial: %v", err)
+	}
+	remoteConn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("unable to accept: %v", err)
+	}
+	defer remoteConn.Close()
+	sp.AssociateConnection(localConn)
+	defer sp.Disconnect()
+
+	pver := wire.ProtocolVersion
+	btcnet := chaincfg.MainNetParams.Net
+	remoteSent := make(map[string]uint64)
+	remoteRecv := make(map[string]uint64)
+	writeMsg := func(msg wire.Message) {
+		n, err := wire.WriteMessageN(remoteConn, msg, pver, btcnet)
+		if err != nil {
+			t.Fatalf("unable to write %s: %v", msg.Command(), err)
+		}
+		remoteSent[msg.Command()] += uint64(n)
+	}
+	readMsg := func(command string) wire.Message {
+		remoteConn.SetReadDeadline(time.Now().Add(time.Second * 5))
+		n, msg, _, err := wire.ReadMessageN(remoteConn, pver, btcnet)
+		if err != nil {
+			t.Fatalf("unable to read %s: %v", command, err)
+		}
+		if msg.Command() != command {
+			t.Fatalf("got %s message, want %s", msg.Command(),
+				command)
+		}
+		remoteRecv[command] += uint64(n)
+		return msg
+	}
+
+	// checkTotals ensures the per message byte counts of the peer match the
+	// bytes exchanged by the remote peer and sum to the totals of the peer.
+	// Since the write of a message is only accounted once it completes,
+	// which might be after the remote peer already read it, the counts are
+	// polled for a short time.
+	checkTotals := func(name string) {
+		sentPerMsg := sp.BytesSentPerMsg()
+		for i := 0; i < 50 && !reflect.DeepEqual(sentPerMsg, remoteRecv); i++ {
+			time.Sleep(time.Millisecond * 10)
+			sentPerMsg = sp.BytesSentPerMsg()
+		}
+		if !reflect.DeepEqual(sentPerMsg, remoteRecv) {
+			t.Fatalf("%s: got bytes sent %v, want %v", name,
+				sentPerMsg, remoteRecv)
+		}
+		recvPerMsg := sp.BytesRecvPerMsg()
+		if !reflect.DeepEqual(recvPerMsg, remoteSent) {
+			t.Fatalf("%s: got bytes received %v, want %v", name,
+				recvPerMsg, remoteSent)
+		}
+
+		var totalSent, totalRecv uint64
+		for _, bytes := range sentPerMsg {
+			totalSent += bytes
+		}
+		for _, bytes := range recvPerMsg {
+			totalRecv += bytes
+		}
+		if sp.BytesSent() != totalSent {
+			t.Fatalf("%s: got %d total bytes sent, want %d", name,
+				sp.BytesSent(), totalSent)
+		}
+		if sp.BytesReceived() != totalRecv {
+			t.Fatalf("%s: got %d total bytes received, want %d",
+				name, sp.BytesReceived(), totalRecv)
+		}
+	}
+
+	// Negotiate the protocol with the peer.
+	readMsg(wire.CmdVersion)
+	me := wire.NewNetAddressIPPort(net.ParseIP("127.0.0.1"), 9333, 0)
+	you := wire.NewNetAddressIPPort(net.ParseIP("127.0.0.1"), 18555, 0)
+	writeMsg(wire.NewMsgVersion(me, you, 0x0123456789abcdef, 0))
+	readMsg(wire.CmdVerAck)
+	writeMsg(wire.NewMsgVerAck())
+	select {
+	case <-verack:
+	case <-time.After(time.Second * 5):
+		t.Fatal("verack timeout")
+	}
+	checkTotals("negotiation")
+
+	// Ensure the ping and pong are accounted and the totals advance.
+	sp.QueueMessage(wire.NewMsgPing(1), nil)
+	ping := readMsg(wire.CmdPing).(*wire.MsgPing)
+	writeMsg(wire.NewMsgPong(ping.Nonce))
+	select {
+	case <-pong:
+	case <-time.After(time.Second * 5):
+		t.Fatal("pong timeout")
+	}
+	checkTotals("ping")
+
+	// The answered ping must also be reflected in the ping times.
+	stats := sp.StatsSnapshot()
+	if stats.MinPingMicros != stats.LastPingMicros ||
+		sp.MinPingMicros() != stats.MinPingMicros {
+
+		t.Fatalf("got min ping %d, want %d", stats.MinPingMicros,
+			stats.LastPingMicros)
+	}
+}
+
+// TestAddedNodes ensures nodes added as persistent peers are tracked until
+// they are removed, regardless of whether or not they are connected, and that
+// duplicate additions are rejected.
+func TestAddedNodes(t *testing.T) {
+	// The connection manager never connects to the added nodes and does
+	// not retry in the time frame of the test.
+	cmgr, err := connmgr.New(&connmgr.Config{
+		RetryDuration: time.Hour,
+		Dial: func(addr net.Addr) (net.Conn, error) {
+			return nil, errors.New("unreachable")
+		},
+	})
+	if err != nil {
+		t.Fatalf("unable to create connection manager: %v", err)
+	}
+	cmgr.Start()
+	defer cmgr.Stop()
+
+	oldCfg := cfg
+	cfg = &config{MaxPeers: defaultMaxPeers}
+	defer func() {
+		cfg = oldCfg
+	}()
+
+	s := &server{
+		connManager: cmgr,
+		banList:     &banList{bans: make(map[string]bannedSubnet)},
+		txMemPool:   mempool.New(&mempool.Config{}),
+	}
+	state := &peerState{
+		inboundPeers:    make(map[int32]*serverPeer),
+		persistentPeers: make(map[int32]*serverPeer),
+		outboundPeers:   make(map[int32]*serverPeer),
+		outboundGroups:  make(map[string]int),
+		addedNodes:      make(map[string]*connmgr.ConnReq),
+	}
+	connect := func(addr string, permanent bool) error {
+		reply := make(chan error, 1)
+		s.handleQuery(state, connectNodeMsg{addr: addr,
+			permanent: permanent, reply: reply})
+		return <-reply
+	}
+	remove := func(addr string, cmp func(*serverPeer) bool) error {
+		reply := make(chan error, 1)
+		s.handleQuery(state, removeNodeMsg{addr: addr, cmp: cmp,
+			reply: reply})
+		return <-reply
+	}
+
+	// Add a node and ensure it is tracked as persistent and that adding
+	// it again, even as a one-off connection, is rejected.
+	const addr = "127.0.0.1:9333"
+	if err := connect(addr, true); err != nil {
+		t.Fatalf("add: unexpected error: %v", err)
+	}
+	connReq, ok := state.addedNodes[addr]
+	if !ok || !connReq.Permanent {
+		t.Fatalf("add: node %s is not tracked as persistent", addr)
+	}
+	if err := connect(addr, true); err != errNodeAlreadyAdded {
+		t.Fatalf("duplicate add: got error %v, want %v", err,
+			errNodeAlreadyAdded)
+	}
+	if err := connect(addr, false); err == nil {
+		t.Fatal("onetry of added node: unexpected success")
+	}
+
+	// Simulate the connection to the node being established and ensure
+	// removing it by the id of the peer stops tracking it.
+	newPersistentPeer := func() *serverPeer {
+		sp := newServerPeer(s, true)
+		sp.Peer, err = peer.NewOutboundPeer(&peer.Config{
+			ChainParams: &chaincfg.MainNetParams,
+		}, addr)
+		if err != nil {
+			t.Fatalf("NewOutboundPeer: unexpected error: %v", err)
+		}
+		sp.connReq = connReq
+		return sp
+	}
+	sp := newPersistentPeer()
+	if !s.handleAddPeerMsg(state, sp) {
+		t.Fatal("persistent peer was not added")
+	}
+	if _, ok := state.persistentPeers[sp.ID()]; !ok {
+		t.Fatal("peer is not tracked as persistent")
+	}
+	err = remove("", func(p *serverPeer) bool { return p.ID() == sp.ID() })
+	if err != nil {
+		t.Fatalf("remove: unexpected error: %v", err)
+	}
+	if len(state.addedNodes) != 0 || len(state.persistentPeers) != 0 {
+		t.Fatalf("remove: node is still tracked: %v, %v",
+			state.addedNodes, state.persistentPeers)
+	}
+	if err := remove(addr, func(*serverPeer) bool { return false }); err != errNodeNotAdded {
+		t.Fatalf("duplicate remove: got error %v, want %v", err,
+			errNodeNotAdded)
+	}
+
+	// A retried connection to a removed node must not be accepted.
+	if s.handleAddPeerMsg(state, newPersistentPeer()) {
+		t.Fatal("peer for removed node was added")
+	}
+
+	// Nodes which are not connected must be removable by address.
+	if err := connect(addr, true); err != nil {
+		t.Fatalf("re-add: unexpected error: %v", err)
+	}
+	if err := remove(addr, func(*serverPeer) bool { return false }); err != nil {
+		t.Fatalf("remove unconnected: unexpected error: %v", err)
+	}
+	if len(state.addedNodes) != 0 {
+		t.Fatalf("remove unconnected: node is still tracked: %v",
+			state.addedNodes)
+	}
+}
+
+// TestInboundBannedSubnet ensures inbound connections from addresses within a
+// banned subnet are closed before a peer is created for them.
+func TestInboundBannedSubnet(t *testing.T) {
+	subnet, err := parseSubnet("127.0.0.0/8")
+	if err != nil {
+		t.Fatalf("parseSubnet: unexpected error: %v", err)
+	}
+	s := &server{banList: &banList{
+		filePath: filepath.Join(os.TempDir(), "ltcd-banlist-test.json"),
+		bans:     make(map[string]bannedSubnet),
+	}}
+	defer os.Remove(s.banList.filePath)
+	if err := s.banList.Ban(subnet, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Ban: unexpected error: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer listener.Close()
+	remoteConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial: %v", err)
+	}
+	defer remoteConn.Close()
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("unable to accept: %v", err)
+	}
+
+	// The connection must be closed without the version message of an
+	// inbound peer being awaited or sent.
+	s.inboundPeerConnected(conn)
+	remoteConn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	if n, err := remoteConn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("got %d bytes and error %v, want %v", n, err, io.EOF)
+	}
+}
+
+// TestMisbehavingPeerBan ensures the ban score of a peer accumulates across
+// violations and that the peer is banned once the score reaches the ban
+// threshold.
+func TestMisbehavingPeerBan(t *testing.T) {
+	oldCfg := cfg
+	cfg = &config{BanThreshold: defaultBanThreshold}
+	defer func() {
+		cfg = oldCfg
+	}()
+
+	// Don't log the misbehavior warnings since the log rotator is not
+	// initialized.
+	peerLog.SetLevel(btclog.LevelOff)
+	defer peerLog.SetLevel(btclog.LevelInfo)
+
+	s := &server{
+		chainParams: &chaincfg.MainNetParams,
+		banPeers:    make(chan *serverPeer, 1),
+	}
+	newMisbehavingPeer := func() *serverPeer {
+		sp := newServerPeer(s, false)
+		var err error
+		sp.Peer, err = peer.NewOutboundPeer(&peer.Config{
+			ChainParams: &chaincfg.MainNetParams,
+		}, "127.0.0.1:9333")
+		if err != nil {
+			t.Fatalf("NewOutboundPeer: unexpected error: %v", err)
+		}
+		return sp
+	}
+	assertBanned := func(sp *serverPeer, banned bool) {
+		select {
+		case bannedPeer := <-s.banPeers:
+			if !banned || bannedPeer != sp {
+				t.Fatalf("unexpected ban with score %d",
+					sp.banScore.Int())
+			}
+		default:
+			if banned {
+				t.Fatalf("peer was not banned with score %d",
+					sp.banScore.Int())
+			}
+		}
+	}
+
+	// Violations which don't reach the threshold must not ban the peer
+	// until their cumulative score reaches it.
+	sp := newMisbehavingPeer()
+	violations := []struct {
+		persistent uint32
+		transient  uint32
+		reason     string
+	}{
+		{20, 0, "unrequested block"},
+		{20, 0, "unrequested headers"},
+		{0, 33, "mempool"},
+		{0, 26, "getdata"},
+	}
+	for _, v := range violations {
+		sp.addBanScore(v.persistent, v.transient, v.reason)
+		assertBanned(sp, false)
+	}
+	if score := sp.banScore.Int(); score != 99 {
+		t.Fatalf("got ban score %d, want 99", score)
+	}
+	sp.addBanScore(1, 0, "unrequested block")
+	assertBanned(sp, true)
+
+	// Invalid blocks ban the peer immediately unless well-behaved peers
+	// might send them as well.
+	invalidBlockErr := blockchain.RuleError{ErrorCode: blockchain.ErrBadMerkleRoot}
+	duplicateBlockErr := blockchain.RuleError{ErrorCode: blockchain.ErrDuplicateBlock}
+	if score := blockBanScore(invalidBlockErr); score != 100 {
+		t.Fatalf("got ban score %d for invalid block, want 100", score)
+	}
+	if score := blockBanScore(duplicateBlockErr); score != 0 {
+		t.Fatalf("got ban score %d for duplicate block, want 0", score)
+	}
+
+	// Messages with a bad checksum ban the peer immediately.
+	sp = newMisbehavingPeer()
+	sp.OnRead(sp.Peer, 24, nil, &wire.MessageError{
+		Func:        "ReadMessage",
+		Description: "payload checksum failed",
+	})
+	assertBanned(sp, true)
+
+	// Other read errors don't increase the ban score.
+	sp = newMisbehavingPeer()
+	sp.OnRead(sp.Peer, 24, nil, &wire.MessageError{
+		Func:        "ReadMessage",
+		Description: "unhandled command [bogus]",
+	})
+	if score := sp.banScore.Int(); score != 0 {
+		t.Fatalf("got ban score %d for unknown command, want 0", score)
+	}
+
+	// No peers are banned when banning is disabled, which is also the case
+	// for a ban threshold of 0.
+	cfg.DisableBanning = true
+	sp = newMisbehavingPeer()
+	sp.addBanScore(100, 0, "invalid block")
+	assertBanned(sp, false)
+}
+
+// TestAnnounceUnbroadcastTxs ensures transactions submitted while no peers are
+// connected are announced to peers once they connect and are no longer
+// rebroadcast once a peer requests them.
+func TestAnnounceUnbroadcastTxs(t *testing.T) {
+	oldCfg := cfg
+	cfg = &config{MaxPeers: defaultMaxPeers}
+	defer func() {
+		cfg = oldCfg
+	}()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Submit a transaction spending a mature coinbase while no peers are
+	// connected.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity); i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+	coinbaseHash := coinbases[0].TxHash()
+	msgTx := wire.NewMsgTx(1)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(coinbases[0].TxOut[0].Value-10000,
+		pkScript))
+	tx := ltcutil.NewTx(msgTx)
+	txMemPool := newRegtestMempool(chain)
+	if _, err := txMemPool.ProcessTransaction(tx, false, false, 0); err != nil {
+		t.Fatalf("ProcessTransaction: unexpected error: %v", err)
+	}
+	txMemPool.AddUnbroadcastTx(tx.Hash())
+	if txMemPool.UnbroadcastCount() != 1 {
+		t.Fatalf("got %d unbroadcast transactions, want 1",
+			txMemPool.UnbroadcastCount())
+	}
+
+	s := &server{
+		chainParams: params,
+		banList:     &banList{bans: make(map[string]bannedSubnet)},
+		txMemPool:   txMemPool,
+	}
+	state := &peerState{
+		inboundPeers:    make(map[int32]*serverPeer),
+		persistentPeers: make(map[int32]*serverPeer),
+		outboundPeers:   make(map[int32]*serverPeer),
+		outboundGroups:  make(map[string]int),
+	}
+
+	// Connect a peer which the test drives as the remote peer.
+	verack := make(chan struct{}, 1)
+	sp := newServerPeer(s, false)
+	var err error
+	sp.Peer, err = peer.NewOutboundPeer(&peer.Config{
+		Listeners: peer.MessageListeners{
+			OnVerAck: func(p *peer.Peer, msg *wire.MsgVerAck) {
+				verack <- struct{}{}
+			},
+			OnGetData: sp.OnGetData,
+		},
+		UserAgentName:    "peer",
+		UserAgentVersion: "1.0",
+		ChainParams:      params,
+	}, "127.0.0.1:18444")
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: unexpected error: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer listener.Close()
+	localConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial: %v", err)
+	}
+	remoteConn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("unable to accept: %v", err)
+	}
+	defer remoteConn.Close()
+	sp.AssociateConnection(localConn)
+	defer sp.Disconnect()
+
+	pver := wire.ProtocolVersion
+	writeMsg := func(msg wire.Message) {
+		err := wire.WriteMessage(remoteConn, msg, pver, params.Net)
+		if err != nil {
+			t.Fatalf("unable to write %s: %v", msg.Command(), err)
+		}
+	}
+	readMsg := func(command string) wire.Message {
+		remoteConn.SetReadDeadline(time.Now().Add(time.Second * 5))
+		msg, _, err := wire.ReadMessage(remoteConn, pver, params.Net)
+		if err != nil {
+			t.Fatalf("unable to read %s: %v", command, err)
+		}
+		if msg.Command() != command {
+			t.Fatalf("got %s message, want %s", msg.Command(),
+				command)
+		}
+		return msg
+	}
+	readMsg(wire.CmdVersion)
+	me := wire.NewNetAddressIPPort(net.ParseIP("127.0.0.1"), 18444, 0)
+	you := wire.NewNetAddressIPPort(net.ParseIP("127.0.0.1"), 18555, 0)
+	writeMsg(wire.NewMsgVersion(me, you, 0x0123456789abcdef, 0))
+	readMsg(wire.CmdVerAck)
+	writeMsg(wire.NewMsgVerAck())
+	select {
+	case <-verack:
+	case <-time.After(time.Second * 5):
+		t.Fatal("verack timeout")
+	}
+
+	// Ensure the transaction is announced to the peer once it is added.
+	if !s.handleAddPeerMsg(state, sp) {
+		t.Fatal("peer was not added")
+	}
+	inv := readMsg(wire.CmdInv).(*wire.MsgInv)
+	wantInv := wire.NewInvVect(wire.InvTypeTx, tx.Hash())
+	if len(inv.InvList) != 1 || *inv.InvList[0] != *wantInv {
+		t.Fatalf("got inventory %v, want %v", inv.InvList, wantInv)
+	}
+
+	// Ensure the transaction is no longer rebroadcast once the peer
+	// requests it.
+	getData := wire.NewMsgGetData()
+	getData.AddInvVect(wantInv)
+	writeMsg(getData)
+	gotTx := readMsg(wire.CmdTx).(*wire.MsgTx)
+	if gotTx.TxHash() != *tx.Hash() {
+		t.Fatalf("got transaction %v, want %v", gotTx.TxHash(),
+			tx.Hash())
+	}
+	if txMemPool.IsUnbroadcastTx(tx.Hash()) {
+		t.Fatal("requested transaction is still unbroadcast")
+	}
+}
+
+// TestSeedAddrManager ensures the address manager is populated with the
+// addresses returned by the DNS seeds and falls back to the fixed seeds of the
+// network when DNS seeding returns no addresses.
+func TestSeedAddrManager(t *testing.T) {
+	// Don't log the seeding progress since the log rotator is not
+	// initialized.
+	cmgrLog.SetLevel(btclog.LevelOff)
+	defer cmgrLog.SetLevel(btclog.LevelInfo)
+
+	params := chaincfg.MainNetParams
+	params.FixedSeeds = []string{
+		"60.173.147.60:9333",
+		"61.173.147.61:9334",
+		"[2001:470::1]:9333",
+		"not a seed",
+	}
+
+	dnsIP := net.IPv4(62, 173, 147, 62)
+	workingLookup := func(host string) ([]net.IP, error) {
+		return []net.IP{dnsIP}, nil
+	}
+	failingLookup := func(host string) ([]net.IP, error) {
+		return nil, errors.New("lookup failed")
+	}
+	emptyLookup := func(host string) ([]net.IP, error) {
+		return nil, nil
+	}
+
+	tests := []struct {
+		name          string
+		lookup        connmgr.LookupFunc
+		useFixedSeeds bool
+		want          []string
+	}{
+		{
+			name:          "dns seeds",
+			lookup:        workingLookup,
+			useFixedSeeds: true,
+			want:          []string{"62.173.147.62:9333"},
+		},
+		{
+			name:          "failing resolver",
+			lookup:        failingLookup,
+			useFixedSeeds: true,
+			want: []string{"60.173.147.60:9333",
+				"61.173.147.61:9334", "[2001:470::1]:9333"},
+		},
+		{
+			name:          "no addresses",
+			lookup:        emptyLookup,
+			useFixedSeeds: true,
+			want: []string{"60.173.147.60:9333",
+				"61.173.147.61:9334", "[2001:470::1]:9333"},
+		},
+		{
+			name:          "fixed seeds disabled",
+			lookup:        failingLookup,
+			useFixedSeeds: false,
+		},
+	}
+
+	for _, test := range tests {
+		dataDir, err := ioutil.TempDir("", "seedaddrmanager")
+		if err != nil {
+			t.Fatalf("unable to create temp dir: %v", err)
+		}
+		defer os.RemoveAll(dataDir)
+
+		s := &server{addrManager: addrmgr.New(dataDir, nil)}
+		s.seedAddrManager(&params, test.lookup, test.useFixedSeeds)
+
+		// Seeding without the fallback does not wait for the DNS
+		// seeds, so give the lookups a moment to complete.
+		if !test.useFixedSeeds {
+			time.Sleep(time.Millisecond * 100)
+		}
+
+		if n := s.addrManager.NumAddresses(); n != len(test.want) {
+			t.Errorf("%s: got %d addresses, want %d", test.name, n,
+				len(test.want))
+			continue
+		}
+		for _, addr := range test.want {
+			na, err := s.addrManager.DeserializeNetAddress(addr)
+			if err != nil {
+				t.Fatalf("%s: unable to parse %s: %v", test.name,
+					addr, err)
+			}
+			if !s.addrManager.HaveAddress(na) {
+				t.Errorf("%s: address %s was not added",
+					test.name, addr)
+			}
+		}
+	}
+}
+
+// TestNewAddressOnlyNet ensures addresses on networks not allowed by --onlynet
+// are skipped when choosing peers to connect to and that connections from such
+// networks are rejected.
+func TestNewAddressOnlyNet(t *testing.T) {
+	oldCfg := cfg
+	cfg = &config{
+		onlyNets: map[addrmgr.NetworkType]struct{}{
+			addrmgr.NetworkIPv4: {},
+		},
+	}
+	defer func() {
+		cfg = oldCfg
+	}()
+
+	dataDir, err := ioutil.TempDir("", "newaddressonlynet")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dataDir)
+
+	// Serve the outbound group queries of the address selection as if no
+	// peers are connected.
+	s := &server{
+		addrManager: addrmgr.New(dataDir, nil),
+		query:       make(chan interface{}),
+		quit:        make(chan struct{}),
+	}
+	defer close(s.quit)
+	go func() {
+		for {
+			select {
+			case msg := <-s.query:
+				msg.(getOutboundGroup).reply <- 0
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+
+	port := activeNetParams.DefaultPort
+	var addrs []*wire.NetAddress
+	for _, host := range []string{"2001:470::1", "aaaaaaaaaaaaaaaa.onion"} {
+		na, err := s.addrManager.DeserializeNetAddress(
+			net.JoinHostPort(host, port))
+		if err != nil {
+			t.Fatalf("unable to parse %s: %v", host, err)
+		}
+		addrs = append(addrs, na)
+	}
+	srcAddr := wire.NewNetAddressIPPort(net.IPv4(173, 194, 115, 66), 9333, 0)
+	s.addrManager.AddAddresses(addrs, srcAddr)
+
+	// Only ipv6 and onion addresses are known, so none can be chosen.
+	if addr, err := s.newAddress(); err == nil {
+		t.Fatalf("newAddress: chose %s, want error", addr)
+	}
+
+	// The ipv4 address is the only one which is ever chosen.
+	na, err := s.addrManager.DeserializeNetAddress(
+		net.JoinHostPort("173.194.115.67", port))
+	if err != nil {
+		t.Fatalf("unable to parse address: %v", err)
+	}
+	s.addrManager.AddAddress(na, srcAddr)
+	want := net.JoinHostPort("173.194.115.67", port)
+	for i := 0; i < 20; i++ {
+		addr, err := s.newAddress()
+		if err != nil {
+			t.Fatalf("newAddress: unexpected error: %v", err)
+		}
+		if addr.String() != want {
+			t.Fatalf("newAddress: chose %s, want %s", addr, want)
+		}
+	}
+
+	// Connections forwarded by a tor hidden service are rejected.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer listener.Close()
+	remoteConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial: %v", err)
+	}
+	defer remoteConn.Close()
+	conn, err := (&onionListener{listener}).Accept()
+	if err != nil {
+		t.Fatalf("unable to accept: %v", err)
+	}
+	s.banList = &banList{bans: make(map[string]bannedSubnet)}
+	s.inboundPeerConnected(conn)
+	remoteConn.SetReadDeadline(time.Now().Add(time.Second * 5))
+	if n, err := remoteConn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("got %d bytes and error %v, want %v", n, err, io.EOF)
+	}
+}
+
+// TestBlockRelayOnlyPeer ensures block-relay-only peers are asked not to relay
+// transactions and are only announced blocks.
+func TestBlockRelayOnlyPeer(t *testing.T) {
+	oldCfg := cfg
+	cfg = &config{MaxPeers: defaultMaxPeers}
+	defer func() {
+		cfg = oldCfg
+	}()
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Add a transaction spending a mature coinbase to the mempool.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity); i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+	coinbaseHash := coinbases[0].TxHash()
+	msgTx := wire.NewMsgTx(1)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&coinbaseHash, 0), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(coinbases[0].TxOut[0].Value-10000,
+		pkScript))
+	tx := ltcutil.NewTx(msgTx)
+	txMemPool := newRegtestMempool(chain)
+	txDescs, err := txMemPool.ProcessTransaction(tx, false, false, 0)
+	if err != nil {
+		t.Fatalf("ProcessTransaction: unexpected error: %v", err)
+	}
+	txMemPool.AddUnbroadcastTx(tx.Hash())
+
+	s := &server{
+		chainParams: params,
+		banList:     &banList{bans: make(map[string]bannedSubnet)},
+		txMemPool:   txMemPool,
+	}
+	state := &peerState{
+		inboundPeers:    make(map[int32]*serverPeer),
+		persistentPeers: make(map[int32]*serverPeer),
+		outboundPeers:   make(map[int32]*serverPeer),
+		outboundGroups:  make(map[string]int),
+	}
+
+	// Connect a block-relay-only peer which the test drives as the remote
+	// peer.
+	verack := make(chan struct{}, 1)
+	sp := newServerPeer(s, false)
+	sp.blockRelayOnly = true
+	sp.Peer, err = peer.NewOutboundPeer(&peer.Config{
+		Listeners: peer.MessageListeners{
+			OnVerAck: func(p *peer.Peer, msg *wire.MsgVerAck) {
+				verack <- struct{}{}
+			},
+		},
+		UserAgentName:    "peer",
+		UserAgentVersion: "1.0",
+		ChainParams:      params,
+		DisableRelayTx:   sp.blocksOnly(),
+	}, "127.0.0.1:18444")
+	if err != nil {
+		t.Fatalf("NewOutboundPeer: unexpected error: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+	defer listener.Close()
+	localConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unable to dial: %v", err)
+	}
+	remoteConn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("unable to accept: %v", err)
+	}
+	defer remoteConn.Close()
+	sp.AssociateConnection(localConn)
+	defer sp.Disconnect()
+
+	pver := wire.ProtocolVersion
+	writeMsg := func(msg wire.Message) {
+		err := wire.WriteMessage(remoteConn, msg, pver, params.Net)
+		if err != nil {
+			t.Fatalf("unable to write %s: %v", msg.Command(), err)
+		}
+	}
+	readMsg := func(command string, timeout time.Duration) wire.Message {
+		remoteConn.SetReadDeadline(time.Now().Add(timeout))
+		msg, _, err := wire.ReadMessage(remoteConn, pver, params.Net)
+		if err != nil {
+			t.Fatalf("unable to read %s: %v", command, err)
+		}
+		if msg.Command() != command {
+			t.Fatalf("got %s message, want %s", msg.Command(),
+				command)
+		}
+		return msg
+	}
+	version := readMsg(wire.CmdVersion, time.Second*5).(*wire.MsgVersion)
+	if !version.DisableRelayTx {
+		t.Fatal("version message does not disable transaction relay")
+	}
+	me := wire.NewNetAddressIPPort(net.ParseIP("127.0.0.1"), 18444, 0)
+	you := wire.NewNetAddressIPPort(net.ParseIP("127.0.0.1"), 18555, 0)
+	writeMsg(wire.NewMsgVersion(me, you, 0x0123456789abcdef, 0))
+	readMsg(wire.CmdVerAck, time.Second*5)
+	writeMsg(wire.NewMsgVerAck())
+	select {
+	case <-verack:
+	case <-time.After(time.Second * 5):
+		t.Fatal("verack timeout")
+	}
+
+	// Relay the transaction followed by a block and ensure only the block
+	// is announced.  The unbroadcast transaction must not be announced when
+	// the peer is added either.
+	if !s.handleAddPeerMsg(state, sp) {
+		t.Fatal("peer was not added")
+	}
+	s.handleRelayInvMsg(state, relayMsg{
+		invVect: wire.NewInvVect(wire.InvTypeTx, tx.Hash()),
+		data:    txDescs[0],
+	})
+	blockHash := chain.BestSnapshot().Hash
+	wantInv := wire.NewInvVect(wire.InvTypeBlock, &blockHash)
+	s.handleRelayInvMsg(state, relayMsg{invVect: wantInv})
+	inv := readMsg(wire.CmdInv, time.Second*15).(*wire.MsgInv)
+	if len(inv.InvList) != 1 || *inv.InvList[0] != *wantInv {
+		t.Fatalf("got inventory %v, want %v", inv.InvList, wantInv)
+	}
+}
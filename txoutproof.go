@@ -0,0 +1,221 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// maxProofTxns is the maximum number of transactions a block which is proven
+// by a transaction output proof can possibly have.  It is based on the size of
+// the smallest possible transaction.
+const maxProofTxns = blockchain.MaxBlockBaseSize / 60
+
+// errInvalidTxOutProof indicates a transaction output proof does not describe
+// a well-formed partial merkle tree.
+var errInvalidTxOutProof = errors.New("invalid transaction output proof")
+
+// partialMerkleTree houses the state needed to build or traverse the partial
+// merkle tree of a transaction output proof as defined by BIP0037.
+type partialMerkleTree struct {
+	numTx uint32
+
+	// The following fields are used when building a tree.
+	allHashes   []*chainhash.Hash
+	matchedBits []byte
+
+	// The following fields hold the hashes and flag bits which describe the
+	// tree in depth-first order.  They are consumed in order when
+	// traversing a tree that was received.
+	hashes   []*chainhash.Hash
+	bits     []byte
+	hashUsed int
+	bitsUsed int
+}
+
+// calcTreeWidth returns the number of nodes at the given depth-first height of
+// the merkle tree.
+func (t *partialMerkleTree) calcTreeWidth(height uint32) uint32 {
+	return (t.numTx + (1 << height) - 1) >> height
+}
+
+// treeHeight returns the number of merkle branches (height) of the tree.
+func (t *partialMerkleTree) treeHeight() uint32 {
+	height := uint32(0)
+	for t.calcTreeWidth(height) > 1 {
+		height++
+	}
+	return height
+}
+
+// calcHash returns the hash of the sub-tree at the given depth-first height
+// and position.
+func (t *partialMerkleTree) calcHash(height, pos uint32) *chainhash.Hash {
+	if height == 0 {
+		return t.allHashes[pos]
+	}
+
+	left := t.calcHash(height-1, pos*2)
+	right := left
+	if pos*2+1 < t.calcTreeWidth(height-1) {
+		right = t.calcHash(height-1, pos*2+1)
+	}
+	return blockchain.HashMerkleBranches(left, right)
+}
+
+// traverseAndBuild builds the partial merkle tree depth-first by recording
+// whether each visited node is the parent of a matched transaction and the
+// hashes of the nodes whose sub-trees are not descended into.
+func (t *partialMerkleTree) traverseAndBuild(height, pos uint32) {
+	var isParent byte
+	for i := pos << height; i < (pos+1)<<height && i < t.numTx; i++ {
+		isParent |= t.matchedBits[i]
+	}
+	t.bits = append(t.bits, isParent)
+
+	if height == 0 || isParent == 0 {
+		t.hashes = append(t.hashes, t.calcHash(height, pos))
+		return
+	}
+
+	t.traverseAndBuild(height-1, pos*2)
+	if pos*2+1 < t.calcTreeWidth(height-1) {
+		t.traverseAndBuild(height-1, pos*2+1)
+	}
+}
+
+// traverseAndExtract traverses the partial merkle tree depth-first in the
+// same order it was built in order to calculate the hash of the sub-tree at
+// the given height and position while appending the hashes of the matched
+// transactions it contains to the provided slice.
+func (t *partialMerkleTree) traverseAndExtract(height, pos uint32, matches *[]chainhash.Hash) (*chainhash.Hash, error) {
+	if t.bitsUsed >= len(t.bits) {
+		return nil, errInvalidTxOutProof
+	}
+	isParent := t.bits[t.bitsUsed]
+	t.bitsUsed++
+
+	if height == 0 || isParent == 0 {
+		if t.hashUsed >= len(t.hashes) {
+			return nil, errInvalidTxOutProof
+		}
+		hash := t.hashes[t.hashUsed]
+		t.hashUsed++
+		if height == 0 && isParent != 0 {
+			*matches = append(*matches, *hash)
+		}
+		return hash, nil
+	}
+
+	left, err := t.traverseAndExtract(height-1, pos*2, matches)
+	if err != nil {
+		return nil, err
+	}
+	right := left
+	if pos*2+1 < t.calcTreeWidth(height-1) {
+		right, err = t.traverseAndExtract(height-1, pos*2+1, matches)
+		if err != nil {
+			return nil, err
+		}
+
+		// Identical left and right branches would allow a different
+		// list of transactions to produce the same merkle root
+		// (CVE-2012-2459).
+		if *right == *left {
+			return nil, errInvalidTxOutProof
+		}
+	}
+	return blockchain.HashMerkleBranches(left, right), nil
+}
+
+// newTxOutProof returns a merkle block which proves the transactions in the
+// passed block with the provided hashes are included in it.
+func newTxOutProof(block *ltcutil.Block, txHashes map[chainhash.Hash]struct{}) *wire.MsgMerkleBlock {
+	numTx := uint32(len(block.Transactions()))
+	tree := partialMerkleTree{
+		numTx:       numTx,
+		allHashes:   make([]*chainhash.Hash, 0, numTx),
+		matchedBits: make([]byte, 0, numTx),
+	}
+	for _, tx := range block.Transactions() {
+		var matched byte
+		if _, ok := txHashes[*tx.Hash()]; ok {
+			matched = 0x01
+		}
+		tree.matchedBits = append(tree.matchedBits, matched)
+		tree.allHashes = append(tree.allHashes, tx.Hash())
+	}
+	tree.traverseAndBuild(tree.treeHeight(), 0)
+
+	msg := wire.MsgMerkleBlock{
+		Header:       block.MsgBlock().Header,
+		Transactions: numTx,
+		Hashes:       make([]*chainhash.Hash, 0, len(tree.hashes)),
+		Flags:        make([]byte, (len(tree.bits)+7)/8),
+	}
+	for _, hash := range tree.hashes {
+		msg.AddTxHash(hash)
+	}
+	for i := uint32(0); i < uint32(len(tree.bits)); i++ {
+		msg.Flags[i/8] |= tree.bits[i] << (i % 8)
+	}
+	return &msg
+}
+
+// extractTxOutProofMatches traverses the partial merkle tree of the passed
+// merkle block and returns the merkle root it commits to along with the hashes
+// of the transactions it proves are included in the block.  The caller must
+// compare the returned merkle root with the one in the block header.
+func extractTxOutProofMatches(msg *wire.MsgMerkleBlock) (*chainhash.Hash, []chainhash.Hash, error) {
+	if msg.Transactions == 0 {
+		return nil, nil, fmt.Errorf("%v: no transactions",
+			errInvalidTxOutProof)
+	}
+	if msg.Transactions > maxProofTxns {
+		return nil, nil, fmt.Errorf("%v: too many transactions",
+			errInvalidTxOutProof)
+	}
+	if uint32(len(msg.Hashes)) > msg.Transactions {
+		return nil, nil, fmt.Errorf("%v: more hashes than "+
+			"transactions", errInvalidTxOutProof)
+	}
+	if len(msg.Flags)*8 < len(msg.Hashes) {
+		return nil, nil, fmt.Errorf("%v: fewer flag bits than hashes",
+			errInvalidTxOutProof)
+	}
+
+	tree := partialMerkleTree{
+		numTx:  msg.Transactions,
+		hashes: msg.Hashes,
+		bits:   make([]byte, len(msg.Flags)*8),
+	}
+	for i := range tree.bits {
+		tree.bits[i] = (msg.Flags[i/8] >> (uint(i) % 8)) & 0x01
+	}
+
+	var matches []chainhash.Hash
+	root, err := tree.traverseAndExtract(tree.treeHeight(), 0, &matches)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// All of the hashes and all but the padding of the flag bits must have
+	// been consumed.
+	if (tree.bitsUsed+7)/8 != len(msg.Flags) {
+		return nil, nil, fmt.Errorf("%v: unused flag bits",
+			errInvalidTxOutProof)
+	}
+	if tree.hashUsed != len(msg.Hashes) {
+		return nil, nil, fmt.Errorf("%v: unused hashes",
+			errInvalidTxOutProof)
+	}
+	return root, matches, nil
+}
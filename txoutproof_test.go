@@ -0,0 +1,121 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// TestTxOutProof ensures transaction output proofs created for various subsets
+// of the transactions in blocks of various sizes commit to the merkle root of
+// the block and prove exactly the selected transactions.
+func TestTxOutProof(t *testing.T) {
+	t.Parallel()
+
+	// newBlock returns a block with the provided number of unique
+	// transactions.
+	newBlock := func(numTxns int) *ltcutil.Block {
+		genesis := chaincfg.RegressionNetParams.GenesisBlock
+		msgBlock := wire.NewMsgBlock(&genesis.Header)
+		for i := 0; i < numTxns; i++ {
+			tx := wire.NewMsgTx(1)
+			prevOut := wire.NewOutPoint(&chainhash.Hash{0x01}, uint32(i))
+			tx.AddTxIn(wire.NewTxIn(prevOut, nil, nil))
+			tx.AddTxOut(wire.NewTxOut(int64(i), nil))
+			msgBlock.AddTransaction(tx)
+		}
+		merkles := blockchain.BuildMerkleTreeStore(
+			ltcutil.NewBlock(msgBlock).Transactions(), false)
+		msgBlock.Header.MerkleRoot = *merkles[len(merkles)-1]
+		return ltcutil.NewBlock(msgBlock)
+	}
+
+	tests := []struct {
+		numTxns int
+		indexes []int
+	}{
+		{numTxns: 1, indexes: []int{0}},
+		{numTxns: 2, indexes: []int{1}},
+		{numTxns: 5, indexes: []int{1, 3}},
+		{numTxns: 7, indexes: []int{6}},
+		{numTxns: 9, indexes: []int{0, 4, 8}},
+		{numTxns: 16, indexes: nil},
+		{numTxns: 16, indexes: []int{2, 3, 4, 5, 6, 7, 8, 9, 10}},
+	}
+
+	for i, test := range tests {
+		block := newBlock(test.numTxns)
+		txHashes := make(map[chainhash.Hash]struct{})
+		wantMatches := make([]chainhash.Hash, 0, len(test.indexes))
+		for _, index := range test.indexes {
+			txHash := block.Transactions()[index].Hash()
+			txHashes[*txHash] = struct{}{}
+			wantMatches = append(wantMatches, *txHash)
+		}
+
+		proof := newTxOutProof(block, txHashes)
+		root, matches, err := extractTxOutProofMatches(proof)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		if *root != block.MsgBlock().Header.MerkleRoot {
+			t.Fatalf("#%d: got merkle root %v, want %v", i, root,
+				block.MsgBlock().Header.MerkleRoot)
+		}
+		if len(matches) != len(wantMatches) ||
+			(len(matches) > 0 && !reflect.DeepEqual(matches, wantMatches)) {
+
+			t.Fatalf("#%d: got matches %v, want %v", i, matches,
+				wantMatches)
+		}
+	}
+
+	// Ensure malformed proofs are rejected.
+	block := newBlock(5)
+	txHash := block.Transactions()[3].Hash()
+	proof := newTxOutProof(block, map[chainhash.Hash]struct{}{*txHash: {}})
+
+	unusedHash := *proof
+	unusedHash.Hashes = append(unusedHash.Hashes, txHash)
+	extraFlags := *proof
+	extraFlags.Flags = append(extraFlags.Flags, 0x00)
+	missingHash := *proof
+	missingHash.Hashes = missingHash.Hashes[:len(missingHash.Hashes)-1]
+	noTxns := *proof
+	noTxns.Transactions = 0
+	tampered := *proof
+	tampered.Hashes = append([]*chainhash.Hash(nil), proof.Hashes...)
+	tampered.Hashes[0] = &chainhash.Hash{0x02}
+
+	malformed := []struct {
+		name  string
+		proof *wire.MsgMerkleBlock
+	}{
+		{"unused hash", &unusedHash},
+		{"extra flags", &extraFlags},
+		{"missing hash", &missingHash},
+		{"no transactions", &noTxns},
+	}
+	for _, test := range malformed {
+		_, _, err := extractTxOutProofMatches(test.proof)
+		if err == nil {
+			t.Fatalf("%s: proof was not rejected", test.name)
+		}
+	}
+
+	// A proof with a tampered hash is well-formed, but must not commit to
+	// the merkle root of the block.
+	root, _, err := extractTxOutProofMatches(&tampered)
+	if err != nil || *root == block.MsgBlock().Header.MerkleRoot {
+		t.Fatal("tampered proof commits to the merkle root")
+	}
+}
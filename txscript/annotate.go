@@ -0,0 +1,352 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/ltcsuite/ltcd/btcec"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// SpendType identifies the type of output an input spends.
+type SpendType byte
+
+const (
+	// BareSpend is a spend of an output whose public key script is
+	// executed as is, such as a pay-to-pubkey-hash or multisig script.
+	BareSpend SpendType = iota
+
+	// P2SHSpend is a spend of a pay-to-script-hash output.
+	P2SHSpend
+
+	// P2WPKHSpend is a spend of a version 0 pay-to-witness-pubkey-hash
+	// output.
+	P2WPKHSpend
+
+	// P2WSHSpend is a spend of a version 0 pay-to-witness-script-hash
+	// output.
+	P2WSHSpend
+
+	// NestedP2WPKHSpend is a spend of a pay-to-script-hash output whose
+	// redeem script is a version 0 pay-to-witness-pubkey-hash program.
+	NestedP2WPKHSpend
+
+	// NestedP2WSHSpend is a spend of a pay-to-script-hash output whose
+	// redeem script is a version 0 pay-to-witness-script-hash program.
+	NestedP2WSHSpend
+
+	// WitnessUnknownSpend is a spend of a witness program of an unknown
+	// version or length, either directly or nested in a
+	// pay-to-script-hash output.
+	WitnessUnknownSpend
+)
+
+// spendTypeToName houses the human-readable strings which describe each
+// spend type.
+var spendTypeToName = []string{
+	BareSpend:           "bare",
+	P2SHSpend:           "p2sh",
+	P2WPKHSpend:         "p2wpkh",
+	P2WSHSpend:          "p2wsh",
+	NestedP2WPKHSpend:   "p2sh-p2wpkh",
+	NestedP2WSHSpend:    "p2sh-p2wsh",
+	WitnessUnknownSpend: "witness_unknown",
+}
+
+// String returns the SpendType in human-readable form.
+func (t SpendType) String() string {
+	if int(t) >= len(spendTypeToName) {
+		return fmt.Sprintf("Unknown SpendType (%d)", byte(t))
+	}
+	return spendTypeToName[t]
+}
+
+// ElementType identifies what an item pushed by a signature script or
+// included in a witness is used for.
+type ElementType byte
+
+const (
+	// DataElement is an item which isn't recognized as any of the other
+	// element types.
+	DataElement ElementType = iota
+
+	// EmptyElement is an empty item, such as the dummy item consumed by
+	// OP_CHECKMULTISIG.
+	EmptyElement
+
+	// SignatureElement is a signature followed by its hash type.
+	SignatureElement
+
+	// PubKeyElement is a serialized public key.
+	PubKeyElement
+
+	// RedeemScriptElement is the redeem script of a pay-to-script-hash
+	// spend.
+	RedeemScriptElement
+
+	// WitnessScriptElement is the witness script of a
+	// pay-to-witness-script-hash spend.
+	WitnessScriptElement
+)
+
+// elementTypeToName houses the human-readable strings which describe each
+// element type.
+var elementTypeToName = []string{
+	DataElement:          "data",
+	EmptyElement:         "empty",
+	SignatureElement:     "signature",
+	PubKeyElement:        "pubkey",
+	RedeemScriptElement:  "redeemscript",
+	WitnessScriptElement: "witnessscript",
+}
+
+// String returns the ElementType in human-readable form.
+func (t ElementType) String() string {
+	if int(t) >= len(elementTypeToName) {
+		return fmt.Sprintf("Unknown ElementType (%d)", byte(t))
+	}
+	return elementTypeToName[t]
+}
+
+// AnnotatedElement houses an item pushed by a signature script or included
+// in a witness along with what it is used for.
+type AnnotatedElement struct {
+	Type ElementType
+	Data []byte
+
+	// HashType is the signature hash type of a signature element.
+	HashType SigHashType
+
+	// Script is the disassembly of a redeem script or witness script
+	// element.
+	Script string
+}
+
+// SpendAnnotation houses the annotated breakdown of the signature script and
+// witness of an input.  Err is set when the input is malformed, in which case
+// the breakdown only covers the parts which could be annotated.
+type SpendAnnotation struct {
+	Type      SpendType
+	SigScript []AnnotatedElement
+	Witness   []AnnotatedElement
+	Err       error
+}
+
+// setErr sets the error of the annotation unless an earlier error is already
+// set, so the first problem encountered is the one reported.
+func (a *SpendAnnotation) setErr(err error) {
+	if a.Err == nil {
+		a.Err = err
+	}
+}
+
+// pushedItems returns the items the passed opcodes push to the stack.  The
+// items are returned up to the first opcode which is not a push along with
+// whether all of the opcodes are pushes.
+func pushedItems(pops []parsedOpcode) ([][]byte, bool) {
+	items := make([][]byte, 0, len(pops))
+	for _, pop := range pops {
+		switch {
+		case pop.opcode.value == OP_0:
+			items = append(items, nil)
+		case pop.opcode.value == OP_1NEGATE:
+			items = append(items, []byte{0x81})
+		case isSmallInt(pop.opcode):
+			items = append(items, []byte{byte(asSmallInt(pop.opcode))})
+		case pop.opcode.value <= OP_PUSHDATA4:
+			items = append(items, pop.data)
+		default:
+			return items, false
+		}
+	}
+	return items, true
+}
+
+// annotateElement returns the passed item labeled with what it appears to be
+// based on its contents alone.
+func annotateElement(item []byte) AnnotatedElement {
+	element := AnnotatedElement{Type: DataElement, Data: item}
+	switch {
+	case len(item) == 0:
+		element.Type = EmptyElement
+
+	case len(item) == 33 || len(item) == 65:
+		if _, err := btcec.ParsePubKey(item, btcec.S256()); err == nil {
+			element.Type = PubKeyElement
+			return element
+		}
+		fallthrough
+
+	default:
+		if len(item) < 2 {
+			break
+		}
+		sig := item[:len(item)-1]
+		if _, err := btcec.ParseSignature(sig, btcec.S256()); err == nil {
+			element.Type = SignatureElement
+			element.HashType = SigHashType(item[len(item)-1])
+		}
+	}
+	return element
+}
+
+// annotateScript returns the passed item labeled as a script of the passed
+// element type along with its disassembly.  The disassembly of a script which
+// fails to parse is complete up to the point of failure.
+func annotateScript(item []byte, elementType ElementType) AnnotatedElement {
+	disasm, _ := DisasmString(item)
+	return AnnotatedElement{Type: elementType, Data: item, Script: disasm}
+}
+
+// annotateWitness annotates the witness which spends the passed witness
+// program.  The nested flag indicates whether the program is the redeem
+// script of a pay-to-script-hash spend.
+func (a *SpendAnnotation) annotateWitness(program []byte, witness wire.TxWitness,
+	nested bool) {
+
+	a.Witness = make([]AnnotatedElement, len(witness))
+	for i, item := range witness {
+		a.Witness[i] = annotateElement(item)
+	}
+
+	version, prog, err := ExtractWitnessProgramInfo(program)
+	if err != nil || version != 0 ||
+		(len(prog) != payToWitnessPubKeyHashDataSize &&
+			len(prog) != payToWitnessScriptHashDataSize) {
+
+		a.Type = WitnessUnknownSpend
+		return
+	}
+
+	if len(prog) == payToWitnessPubKeyHashDataSize {
+		a.Type = P2WPKHSpend
+		if nested {
+			a.Type = NestedP2WPKHSpend
+		}
+
+		// The witness of a pay-to-witness-pubkey-hash spend is
+		// exactly a signature followed by the public key, so they are
+		// labeled based on their position even when malformed.
+		if len(witness) != 2 {
+			str := fmt.Sprintf("p2wpkh witness has %d items "+
+				"instead of 2", len(witness))
+			a.setErr(scriptError(ErrWitnessProgramMismatch, str))
+			return
+		}
+		sig, pubKey := witness[0], witness[1]
+		a.Witness[0].Type = SignatureElement
+		if len(sig) != 0 {
+			a.Witness[0].HashType = SigHashType(sig[len(sig)-1])
+		}
+		a.Witness[1].Type = PubKeyElement
+		if !bytes.Equal(ltcutil.Hash160(pubKey), prog) {
+			str := "public key does not match the witness program"
+			a.setErr(scriptError(ErrWitnessProgramMismatch, str))
+		}
+		return
+	}
+
+	a.Type = P2WSHSpend
+	if nested {
+		a.Type = NestedP2WSHSpend
+	}
+	if len(witness) == 0 {
+		str := "p2wsh witness is missing the witness script"
+		a.setErr(scriptError(ErrWitnessProgramEmpty, str))
+		return
+	}
+	witnessScript := witness[len(witness)-1]
+	a.Witness[len(witness)-1] = annotateScript(witnessScript,
+		WitnessScriptElement)
+	witnessScriptHash := sha256.Sum256(witnessScript)
+	if !bytes.Equal(witnessScriptHash[:], prog) {
+		str := "witness script does not match the witness program"
+		a.setErr(scriptError(ErrWitnessProgramMismatch, str))
+	}
+}
+
+// AnnotateSpend returns an annotated breakdown of the passed signature script
+// and witness which spend an output with the passed public key script.  The
+// breakdown identifies the type of the spend and labels each item pushed by
+// the signature script and each witness item with what it is used for, such
+// as a signature along with its hash type, a public key, or a redeem script.
+//
+// Items are labeled based on their position for spend types with a fixed
+// layout and based on their contents otherwise.  No signatures are verified.
+//
+// Malformed inputs do not cause the annotation to fail.  Instead, the Err
+// field of the returned annotation describes the first problem encountered
+// and the breakdown covers the parts which could be annotated.
+func AnnotateSpend(sigScript []byte, witness wire.TxWitness, pkScript []byte) *SpendAnnotation {
+	a := &SpendAnnotation{Type: BareSpend}
+
+	// Don't return on errors since parseScript returns the
+	// parsed-up-to-error list of pops, which are still annotated.
+	sigPops, err := parseScript(sigScript)
+	if err != nil {
+		a.setErr(err)
+	}
+	items, pushOnly := pushedItems(sigPops)
+	if !pushOnly {
+		str := "signature script is not push only"
+		a.setErr(scriptError(ErrNotPushOnly, str))
+	}
+	a.SigScript = make([]AnnotatedElement, len(items))
+	for i, item := range items {
+		a.SigScript[i] = annotateElement(item)
+	}
+
+	pkPops, err := parseScript(pkScript)
+	if err != nil {
+		a.setErr(err)
+	}
+	switch {
+	case isWitnessProgram(pkPops):
+		if len(sigScript) != 0 {
+			str := "signature script for witness program is not empty"
+			a.setErr(scriptError(ErrWitnessMalleated, str))
+		}
+		a.annotateWitness(pkScript, witness, false)
+		return a
+
+	case isScriptHash(pkPops):
+		a.Type = P2SHSpend
+		if len(items) == 0 {
+			str := "signature script is missing the redeem script"
+			a.setErr(scriptError(ErrInvalidStackOperation, str))
+			break
+		}
+
+		// The redeem script is the last item the signature script
+		// pushes to the stack.
+		redeemScript := items[len(items)-1]
+		a.SigScript[len(items)-1] = annotateScript(redeemScript,
+			RedeemScriptElement)
+		if !bytes.Equal(ltcutil.Hash160(redeemScript), pkScript[2:22]) {
+			str := "redeem script does not match the script hash"
+			a.setErr(scriptError(ErrEvalFalse, str))
+		}
+		if IsWitnessProgram(redeemScript) {
+			a.annotateWitness(redeemScript, witness, true)
+			return a
+		}
+	}
+
+	// Witnesses are only allowed for spends of witness programs, but are
+	// annotated anyway to help debug the spend.
+	if len(witness) != 0 {
+		str := "witness provided for a spend of a non-witness program"
+		a.setErr(scriptError(ErrWitnessUnexpected, str))
+		a.Witness = make([]AnnotatedElement, len(witness))
+		for i, item := range witness {
+			a.Witness[i] = annotateElement(item)
+		}
+	}
+	return a
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/ltcsuite/ltcd/btcec"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// TestAnnotateSpend ensures spends of each type are identified and their
+// signature script and witness items are labeled, and that malformed spends
+// are partially annotated along with the problem encountered.
+func TestAnnotateSpend(t *testing.T) {
+	t.Parallel()
+
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), bytes.Repeat(
+		[]byte{0x01}, 32))
+	pubKey := privKey.PubKey().SerializeCompressed()
+	signature, err := privKey.Sign(bytes.Repeat([]byte{0x02}, 32))
+	if err != nil {
+		t.Fatalf("unable to sign: %v", err)
+	}
+	sig := append(signature.Serialize(), byte(SigHashAll))
+	sigSingle := append(signature.Serialize(), byte(SigHashSingle))
+
+	mustScript := func(script []byte, err error) []byte {
+		if err != nil {
+			t.Fatalf("unable to create script: %v", err)
+		}
+		return script
+	}
+	pushes := func(items ...[]byte) []byte {
+		builder := NewScriptBuilder()
+		for _, item := range items {
+			builder.AddData(item)
+		}
+		return mustScript(builder.Script())
+	}
+	keyHash := ltcutil.Hash160(pubKey)
+	p2pkh := mustScript(payToPubKeyHashScript(keyHash))
+	p2wpkh := mustScript(payToWitnessPubKeyHashScript(keyHash))
+	multiSig := mustScript(NewScriptBuilder().AddOp(OP_1).AddData(pubKey).
+		AddOp(OP_1).AddOp(OP_CHECKMULTISIG).Script())
+	p2sh := mustScript(payToScriptHashScript(ltcutil.Hash160(multiSig)))
+	multiSigHash := sha256.Sum256(multiSig)
+	p2wsh := mustScript(payToWitnessScriptHashScript(multiSigHash[:]))
+	nestedP2WPKH := mustScript(payToScriptHashScript(ltcutil.Hash160(p2wpkh)))
+
+	const (
+		data    = DataElement
+		empty   = EmptyElement
+		sigElem = SignatureElement
+		pubElem = PubKeyElement
+		redeem  = RedeemScriptElement
+		witElem = WitnessScriptElement
+	)
+	tests := []struct {
+		name      string
+		sigScript []byte
+		witness   wire.TxWitness
+		pkScript  []byte
+		spendType SpendType
+		sigTypes  []ElementType
+		witTypes  []ElementType
+		hashType  SigHashType
+		err       error
+	}{
+		{
+			name:      "p2pkh",
+			sigScript: pushes(sig, pubKey),
+			pkScript:  p2pkh,
+			spendType: BareSpend,
+			sigTypes:  []ElementType{sigElem, pubElem},
+			hashType:  SigHashAll,
+		},
+		{
+			name: "p2sh multisig",
+			sigScript: mustScript(NewScriptBuilder().AddOp(OP_0).
+				AddData(sigSingle).AddData(multiSig).Script()),
+			pkScript:  p2sh,
+			spendType: P2SHSpend,
+			sigTypes:  []ElementType{empty, sigElem, redeem},
+			hashType:  SigHashSingle,
+		},
+		{
+			name:      "p2wpkh",
+			witness:   wire.TxWitness{sig, pubKey},
+			pkScript:  p2wpkh,
+			spendType: P2WPKHSpend,
+			witTypes:  []ElementType{sigElem, pubElem},
+			hashType:  SigHashAll,
+		},
+		{
+			name:      "p2wsh multisig",
+			witness:   wire.TxWitness{nil, sig, multiSig},
+			pkScript:  p2wsh,
+			spendType: P2WSHSpend,
+			witTypes:  []ElementType{empty, sigElem, witElem},
+			hashType:  SigHashAll,
+		},
+		{
+			name:      "nested p2wpkh",
+			sigScript: pushes(p2wpkh),
+			witness:   wire.TxWitness{sig, pubKey},
+			pkScript:  nestedP2WPKH,
+			spendType: NestedP2WPKHSpend,
+			sigTypes:  []ElementType{redeem},
+			witTypes:  []ElementType{sigElem, pubElem},
+			hashType:  SigHashAll,
+		},
+		{
+			name:      "p2wpkh missing public key",
+			witness:   wire.TxWitness{sig},
+			pkScript:  p2wpkh,
+			spendType: P2WPKHSpend,
+			witTypes:  []ElementType{sigElem},
+			hashType:  SigHashAll,
+			err:       scriptError(ErrWitnessProgramMismatch, ""),
+		},
+		{
+			name:      "p2wsh wrong witness script",
+			witness:   wire.TxWitness{nil, sig, p2pkh},
+			pkScript:  p2wsh,
+			spendType: P2WSHSpend,
+			witTypes:  []ElementType{empty, sigElem, witElem},
+			hashType:  SigHashAll,
+			err:       scriptError(ErrWitnessProgramMismatch, ""),
+		},
+		{
+			name:      "p2sh wrong redeem script",
+			sigScript: pushes(sig, p2pkh),
+			pkScript:  p2sh,
+			spendType: P2SHSpend,
+			sigTypes:  []ElementType{sigElem, redeem},
+			hashType:  SigHashAll,
+			err:       scriptError(ErrEvalFalse, ""),
+		},
+		{
+			name: "non-push signature script",
+			sigScript: mustScript(NewScriptBuilder().AddData(sig).
+				AddOp(OP_DUP).AddData(pubKey).Script()),
+			pkScript:  p2pkh,
+			spendType: BareSpend,
+			sigTypes:  []ElementType{sigElem},
+			hashType:  SigHashAll,
+			err:       scriptError(ErrNotPushOnly, ""),
+		},
+		{
+			name: "truncated signature script",
+			sigScript: append(pushes(sig, []byte{0x01, 0x02}),
+				OP_DATA_33, 0x02),
+			pkScript:  p2pkh,
+			spendType: BareSpend,
+			sigTypes:  []ElementType{sigElem, data},
+			hashType:  SigHashAll,
+			err:       scriptError(ErrMalformedPush, ""),
+		},
+		{
+			name:      "unexpected witness",
+			sigScript: pushes(sig, pubKey),
+			witness:   wire.TxWitness{pubKey},
+			pkScript:  p2pkh,
+			spendType: BareSpend,
+			sigTypes:  []ElementType{sigElem, pubElem},
+			witTypes:  []ElementType{pubElem},
+			hashType:  SigHashAll,
+			err:       scriptError(ErrWitnessUnexpected, ""),
+		},
+	}
+
+	checkElements := func(name, field string, got []AnnotatedElement,
+		want []ElementType, hashType SigHashType) {
+
+		if len(got) != len(want) {
+			t.Errorf("%s: got %d %s elements, want %d", name,
+				len(got), field, len(want))
+			return
+		}
+		for i, element := range got {
+			if element.Type != want[i] {
+				t.Errorf("%s: %s element %d is %v, want %v", name,
+					field, i, element.Type, want[i])
+			}
+			switch element.Type {
+			case SignatureElement:
+				if element.HashType != hashType {
+					t.Errorf("%s: %s element %d has hash "+
+						"type %v, want %v", name, field, i,
+						element.HashType, hashType)
+				}
+			case RedeemScriptElement, WitnessScriptElement:
+				want, _ := DisasmString(element.Data)
+				if element.Script != want {
+					t.Errorf("%s: %s element %d has script "+
+						"%q, want %q", name, field, i,
+						element.Script, want)
+				}
+			}
+		}
+	}
+
+	for _, test := range tests {
+		a := AnnotateSpend(test.sigScript, test.witness, test.pkScript)
+		if a.Type != test.spendType {
+			t.Errorf("%s: got spend type %v, want %v", test.name,
+				a.Type, test.spendType)
+		}
+		checkElements(test.name, "sigScript", a.SigScript,
+			test.sigTypes, test.hashType)
+		checkElements(test.name, "witness", a.Witness, test.witTypes,
+			test.hashType)
+		if err := tstCheckScriptError(a.Err, test.err); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
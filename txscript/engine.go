@@ -93,6 +93,11 @@ const (
 	// operation whose public key isn't serialized in a compressed format
 	// non-standard.
 	ScriptVerifyWitnessPubKeyType
+
+	// ScriptVerifyCheckTemplateVerify defines whether to allow execution
+	// pathways of a script to be restricted to spending transactions which
+	// match a template committed to by the script.  This is BIP0119.
+	ScriptVerifyCheckTemplateVerify
 )
 
 const (
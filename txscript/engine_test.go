@@ -425,3 +425,92 @@ func TestCheckSignatureEncoding(t *testing.T) {
 		}
 	}
 }
+
+// TestCheckTemplateVerify ensures OP_CHECKTEMPLATEVERIFY only restricts the
+// spending transaction to the template committed to by the script when the
+// flag is set, and otherwise behaves as OP_NOP4.
+func TestCheckTemplateVerify(t *testing.T) {
+	t.Parallel()
+
+	tx := &wire.MsgTx{
+		Version: 2,
+		TxIn: []*wire.TxIn{{
+			PreviousOutPoint: wire.OutPoint{Hash: chainhash.Hash{0x01}},
+			Sequence:         wire.MaxTxInSequenceNum,
+		}},
+		TxOut: []*wire.TxOut{{
+			Value:    1000000000,
+			PkScript: []byte{OP_TRUE},
+		}},
+	}
+	hash := CalcTemplateHash(tx, 0)
+	mismatched := hash
+	mismatched[0] ^= 0x01
+
+	const (
+		ctv        = ScriptVerifyCheckTemplateVerify
+		discourage = ScriptDiscourageUpgradableNops
+	)
+	tests := []struct {
+		name  string
+		arg   []byte
+		flags ScriptFlags
+		err   error
+	}{
+		{
+			name:  "matching hash",
+			arg:   hash[:],
+			flags: ctv | discourage,
+			err:   nil,
+		},
+		{
+			name:  "mismatched hash",
+			arg:   mismatched[:],
+			flags: ctv,
+			err:   scriptError(ErrTemplateHashMismatch, ""),
+		},
+		{
+			name:  "mismatched hash without flag",
+			arg:   mismatched[:],
+			flags: 0,
+			err:   nil,
+		},
+		{
+			name:  "discouraged without flag",
+			arg:   hash[:],
+			flags: discourage,
+			err:   scriptError(ErrDiscourageUpgradableNOPs, ""),
+		},
+		{
+			name:  "short argument",
+			arg:   hash[:31],
+			flags: ctv,
+			err:   nil,
+		},
+		{
+			name:  "discouraged short argument",
+			arg:   hash[:31],
+			flags: ctv | discourage,
+			err:   scriptError(ErrDiscourageUpgradableNOPs, ""),
+		},
+	}
+
+	for _, test := range tests {
+		pkScript, err := NewScriptBuilder().AddData(test.arg).
+			AddOp(OP_CHECKTEMPLATEVERIFY).Script()
+		if err != nil {
+			t.Fatalf("%s: unable to create script: %v", test.name,
+				err)
+		}
+		vm, err := NewEngine(pkScript, tx, 0, test.flags, nil, nil, -1)
+		if err != nil {
+			t.Errorf("%s: failed to create engine: %v", test.name,
+				err)
+			continue
+		}
+		err = vm.Execute()
+		if e := tstCheckScriptError(err, test.err); e != nil {
+			t.Errorf("%s: %v", test.name, e)
+		}
+	}
+}
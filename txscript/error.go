@@ -236,6 +236,11 @@ const (
 	// reached.
 	ErrUnsatisfiedLockTime
 
+	// ErrTemplateHashMismatch is returned when a script contains an
+	// OP_CHECKTEMPLATEVERIFY whose template hash does not match the one of
+	// the transaction.
+	ErrTemplateHashMismatch
+
 	// ErrMinimalIf is returned if ScriptVerifyWitness is set and the
 	// operand of an OP_IF/OP_NOF_IF are not either an empty vector or
 	// [0x01].
@@ -324,6 +329,7 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrDiscourageUpgradableNOPs:           "ErrDiscourageUpgradableNOPs",
 	ErrNegativeLockTime:                   "ErrNegativeLockTime",
 	ErrUnsatisfiedLockTime:                "ErrUnsatisfiedLockTime",
+	ErrTemplateHashMismatch:               "ErrTemplateHashMismatch",
 	ErrWitnessProgramEmpty:                "ErrWitnessProgramEmpty",
 	ErrWitnessProgramMismatch:             "ErrWitnessProgramMismatch",
 	ErrWitnessProgramWrongLength:          "ErrWitnessProgramWrongLength",
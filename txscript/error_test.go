@@ -57,6 +57,7 @@ func TestErrorCodeStringer(t *testing.T) {
 		{ErrDiscourageUpgradableNOPs, "ErrDiscourageUpgradableNOPs"},
 		{ErrNegativeLockTime, "ErrNegativeLockTime"},
 		{ErrUnsatisfiedLockTime, "ErrUnsatisfiedLockTime"},
+		{ErrTemplateHashMismatch, "ErrTemplateHashMismatch"},
 		{ErrWitnessProgramEmpty, "ErrWitnessProgramEmpty"},
 		{ErrWitnessProgramMismatch, "ErrWitnessProgramMismatch"},
 		{ErrWitnessProgramWrongLength, "ErrWitnessProgramWrongLength"},
@@ -218,6 +218,7 @@ const (
 	OP_NOP3                = 0xb2 // 178
 	OP_CHECKSEQUENCEVERIFY = 0xb2 // 178 - AKA OP_NOP3
 	OP_NOP4                = 0xb3 // 179
+	OP_CHECKTEMPLATEVERIFY = 0xb3 // 179 - AKA OP_NOP4
 	OP_NOP5                = 0xb4 // 180
 	OP_NOP6                = 0xb5 // 181
 	OP_NOP7                = 0xb6 // 182
@@ -419,6 +420,7 @@ var opcodeArray = [256]opcode{
 	OP_RETURN:              {OP_RETURN, "OP_RETURN", 1, opcodeReturn},
 	OP_CHECKLOCKTIMEVERIFY: {OP_CHECKLOCKTIMEVERIFY, "OP_CHECKLOCKTIMEVERIFY", 1, opcodeCheckLockTimeVerify},
 	OP_CHECKSEQUENCEVERIFY: {OP_CHECKSEQUENCEVERIFY, "OP_CHECKSEQUENCEVERIFY", 1, opcodeCheckSequenceVerify},
+	OP_CHECKTEMPLATEVERIFY: {OP_CHECKTEMPLATEVERIFY, "OP_CHECKTEMPLATEVERIFY", 1, opcodeCheckTemplateVerify},
 
 	// Stack opcodes.
 	OP_TOALTSTACK:   {OP_TOALTSTACK, "OP_TOALTSTACK", 1, opcodeToAltStack},
@@ -501,7 +503,6 @@ var opcodeArray = [256]opcode{
 
 	// Reserved opcodes.
 	OP_NOP1:  {OP_NOP1, "OP_NOP1", 1, opcodeNop},
-	OP_NOP4:  {OP_NOP4, "OP_NOP4", 1, opcodeNop},
 	OP_NOP5:  {OP_NOP5, "OP_NOP5", 1, opcodeNop},
 	OP_NOP6:  {OP_NOP6, "OP_NOP6", 1, opcodeNop},
 	OP_NOP7:  {OP_NOP7, "OP_NOP7", 1, opcodeNop},
@@ -907,8 +908,7 @@ func opcodeN(op *parsedOpcode, vm *Engine) error {
 // the flag to discourage use of NOPs is set for select opcodes.
 func opcodeNop(op *parsedOpcode, vm *Engine) error {
 	switch op.opcode.value {
-	case OP_NOP1, OP_NOP4, OP_NOP5,
-		OP_NOP6, OP_NOP7, OP_NOP8, OP_NOP9, OP_NOP10:
+	case OP_NOP1, OP_NOP5, OP_NOP6, OP_NOP7, OP_NOP8, OP_NOP9, OP_NOP10:
 		if vm.hasFlag(ScriptDiscourageUpgradableNops) {
 			str := fmt.Sprintf("OP_NOP%d reserved for soft-fork "+
 				"upgrades", op.opcode.value-(OP_NOP1-1))
@@ -1270,6 +1270,49 @@ func opcodeCheckSequenceVerify(op *parsedOpcode, vm *Engine) error {
 		wire.SequenceLockTimeIsSeconds, sequence&lockTimeMask)
 }
 
+// opcodeCheckTemplateVerify compares the top item on the data stack to the
+// BIP0119 template hash of the transaction containing the script signature
+// and input being verified, which commits to the fields of the transaction
+// such as its outputs.  If flag ScriptVerifyCheckTemplateVerify is not set,
+// the code continues as if OP_NOP4 were executed.
+//
+// Items of any size other than 32 bytes are left to future soft-forks and
+// treated as a NOP.  As with OP_CHECKLOCKTIMEVERIFY, the item is not removed
+// from the stack.
+func opcodeCheckTemplateVerify(op *parsedOpcode, vm *Engine) error {
+	// If the ScriptVerifyCheckTemplateVerify script flag is not set, treat
+	// opcode as OP_NOP4 instead.
+	if !vm.hasFlag(ScriptVerifyCheckTemplateVerify) {
+		if vm.hasFlag(ScriptDiscourageUpgradableNops) {
+			return scriptError(ErrDiscourageUpgradableNOPs,
+				"OP_NOP4 reserved for soft-fork upgrades")
+		}
+		return nil
+	}
+
+	so, err := vm.dstack.PeekByteArray(0)
+	if err != nil {
+		return err
+	}
+	if len(so) != chainhash.HashSize {
+		if vm.hasFlag(ScriptDiscourageUpgradableNops) {
+			str := fmt.Sprintf("OP_CHECKTEMPLATEVERIFY with a %d "+
+				"byte argument is reserved for soft-fork upgrades",
+				len(so))
+			return scriptError(ErrDiscourageUpgradableNOPs, str)
+		}
+		return nil
+	}
+
+	hash := CalcTemplateHash(&vm.tx, vm.txIdx)
+	if !bytes.Equal(so, hash[:]) {
+		str := fmt.Sprintf("template hash mismatch: script specifies "+
+			"%x, transaction has %x", so, hash[:])
+		return scriptError(ErrTemplateHashMismatch, str)
+	}
+	return nil
+}
+
 // opcodeToAltStack removes the top item from the main data stack and pushes it
 // onto the alternate data stack.
 //
@@ -2434,9 +2477,10 @@ var OpcodeByName = make(map[string]byte)
 
 func init() {
 	// Initialize the opcode name to value map using the contents of the
-	// opcode array.  Also add entries for "OP_FALSE", "OP_TRUE", and
-	// "OP_NOP2" since they are aliases for "OP_0", "OP_1",
-	// and "OP_CHECKLOCKTIMEVERIFY" respectively.
+	// opcode array.  Also add entries for "OP_FALSE", "OP_TRUE", "OP_NOP2",
+	// "OP_NOP3", and "OP_NOP4" since they are aliases for "OP_0", "OP_1",
+	// "OP_CHECKLOCKTIMEVERIFY", "OP_CHECKSEQUENCEVERIFY", and
+	// "OP_CHECKTEMPLATEVERIFY" respectively.
 	for _, op := range opcodeArray {
 		OpcodeByName[op.name] = op.value
 	}
@@ -2444,4 +2488,5 @@ func init() {
 	OpcodeByName["OP_TRUE"] = OP_TRUE
 	OpcodeByName["OP_NOP2"] = OP_CHECKLOCKTIMEVERIFY
 	OpcodeByName["OP_NOP3"] = OP_CHECKSEQUENCEVERIFY
+	OpcodeByName["OP_NOP4"] = OP_CHECKTEMPLATEVERIFY
 }
@@ -15,6 +15,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/ltcsuite/ltcd/btcec"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
 	"github.com/ltcsuite/ltcd/wire"
 	"github.com/ltcsuite/ltcutil"
@@ -661,6 +662,64 @@ testloop:
 	}
 }
 
+// verifyInputSigHash verifies the signature of an input spending a
+// pay-to-pubkey-hash output or a version 0 pay-to-witness-pubkey-hash program,
+// either natively or nested, against the signature hash calculated by
+// CalcInputSigHash.  It returns whether the input spends one of those outputs
+// with a signature and public key which can be parsed.
+func verifyInputSigHash(tx *wire.MsgTx, idx int, prevOut scriptWithInputVal) (bool, error) {
+	txIn := tx.TxIn[idx]
+	var items [][]byte
+	switch {
+	case IsPayToWitnessPubKeyHash(prevOut.pkScript):
+		items = txIn.Witness
+
+	case IsPayToScriptHash(prevOut.pkScript):
+		pushes, err := PushedData(txIn.SignatureScript)
+		if err != nil || len(pushes) != 1 ||
+			!IsPayToWitnessPubKeyHash(pushes[0]) {
+
+			return false, nil
+		}
+		items = txIn.Witness
+
+	default:
+		pops, err := parseScript(prevOut.pkScript)
+		if err != nil || !isPubkeyHash(pops) {
+			return false, nil
+		}
+		items, err = PushedData(txIn.SignatureScript)
+		if err != nil {
+			return false, nil
+		}
+	}
+	if len(items) != 2 || len(items[0]) == 0 {
+		return false, nil
+	}
+	sigBytes, pubKeyBytes := items[0], items[1]
+	signature, err := btcec.ParseSignature(sigBytes[:len(sigBytes)-1],
+		btcec.S256())
+	if err != nil {
+		return false, nil
+	}
+	pubKey, err := btcec.ParsePubKey(pubKeyBytes, btcec.S256())
+	if err != nil {
+		return false, nil
+	}
+
+	hashType := SigHashType(sigBytes[len(sigBytes)-1])
+	hash, err := CalcInputSigHash(tx, idx, prevOut.pkScript,
+		prevOut.inputVal, hashType, nil)
+	if err != nil {
+		return true, err
+	}
+	if !signature.Verify(hash, pubKey) {
+		return true, errors.New("signature does not commit to the " +
+			"signature hash")
+	}
+	return true, nil
+}
+
 // TestTxValidTests ensures all of the tests in tx_valid.json pass as expected.
 func TestTxValidTests(t *testing.T) {
 	file, err := ioutil.ReadFile("data/tx_valid.json")
@@ -679,6 +738,10 @@ func TestTxValidTests(t *testing.T) {
 	// or:
 	//   [[[previous hash, previous index, previous scriptPubKey, input value]...,]
 	//	serializedTransaction, verifyFlags]
+	//
+	// The signatures of inputs with a known layout are also verified against
+	// the signature hash calculated by CalcInputSigHash.
+	var numSigHashes int
 testloop:
 	for i, test := range tests {
 		inputs, ok := test[0].([]interface{})
@@ -810,6 +873,93 @@ testloop:
 					"%v", i, test, k, err)
 				continue
 			}
+
+			verified, err := verifyInputSigHash(tx.MsgTx(), k,
+				prevOut)
+			if err != nil {
+				t.Errorf("test (%d:%v:%d) signature hash "+
+					"mismatch: %v", i, test, k, err)
+				continue
+			}
+			if verified {
+				numSigHashes++
+			}
+		}
+	}
+	if numSigHashes == 0 {
+		t.Error("no signatures verified against CalcInputSigHash")
+	}
+}
+
+// TestCalcTemplateHashReference runs the BIP0119 template hash tests in
+// ctvhash.json through CalcTemplateHash and OP_CHECKTEMPLATEVERIFY.
+func TestCalcTemplateHashReference(t *testing.T) {
+	file, err := ioutil.ReadFile("data/ctvhash.json")
+	if err != nil {
+		t.Fatalf("TestCalcTemplateHashReference: %v\n", err)
+	}
+
+	var tests [][]interface{}
+	err = json.Unmarshal(file, &tests)
+	if err != nil {
+		t.Fatalf("TestCalcTemplateHashReference couldn't Unmarshal: %v\n",
+			err)
+	}
+
+	for i, test := range tests {
+		// Skip comments.
+		if len(test) == 1 {
+			continue
+		}
+		if len(test) != 4 {
+			t.Fatalf("TestCalcTemplateHashReference: Test #%d has "+
+				"wrong length.", i)
+		}
+		var tx wire.MsgTx
+		rawTx, _ := hex.DecodeString(test[0].(string))
+		err := tx.Deserialize(bytes.NewReader(rawTx))
+		if err != nil {
+			t.Errorf("TestCalcTemplateHashReference failed test #%d: "+
+				"Failed to parse transaction: %v", i, err)
+			continue
+		}
+		idx := int(test[1].(float64))
+		templateHash, _ := hex.DecodeString(test[2].(string))
+
+		var wantErr error
+		switch result := test[3].(string); result {
+		case "OK":
+		case "TEMPLATE_HASH_MISMATCH":
+			wantErr = scriptError(ErrTemplateHashMismatch, "")
+		default:
+			t.Fatalf("TestCalcTemplateHashReference: Test #%d has "+
+				"unknown result %q.", i, result)
+		}
+
+		hash := CalcTemplateHash(&tx, idx)
+		if bytes.Equal(hash[:], templateHash) != (wantErr == nil) {
+			t.Errorf("TestCalcTemplateHashReference failed test #%d: "+
+				"got template hash %x, test hash %x, result %v", i,
+				hash[:], templateHash, test[3])
+		}
+
+		pkScript, err := NewScriptBuilder().AddData(templateHash).
+			AddOp(OP_CHECKTEMPLATEVERIFY).Script()
+		if err != nil {
+			t.Fatalf("TestCalcTemplateHashReference: Test #%d: unable "+
+				"to create script: %v", i, err)
+		}
+		vm, err := NewEngine(pkScript, &tx, idx,
+			ScriptVerifyCheckTemplateVerify, nil, nil, -1)
+		if err != nil {
+			t.Errorf("TestCalcTemplateHashReference failed test #%d: "+
+				"failed to create engine: %v", i, err)
+			continue
+		}
+		err = vm.Execute()
+		if e := tstCheckScriptError(err, wantErr); e != nil {
+			t.Errorf("TestCalcTemplateHashReference failed test #%d: "+
+				"%v", i, e)
 		}
 	}
 }
@@ -424,6 +424,63 @@ func calcHashOutputs(tx *wire.MsgTx) chainhash.Hash {
 	return chainhash.DoubleHashH(b.Bytes())
 }
 
+// CalcTemplateHash computes the default template hash of the passed
+// transaction for the input at the passed index as defined by BIP0119.  This
+// is the hash OP_CHECKTEMPLATEVERIFY compares its argument against.
+//
+// Unlike the signature hashes, the template hash and each of the hashes of
+// the transaction fields it commits to are a single SHA256.  The hash of the
+// signature scripts is only committed to when any of them is non-empty.
+func CalcTemplateHash(tx *wire.MsgTx, idx int) chainhash.Hash {
+	var b bytes.Buffer
+	var bUint32 [4]byte
+	writeUint32 := func(v uint32) {
+		binary.LittleEndian.PutUint32(bUint32[:], v)
+		b.Write(bUint32[:])
+	}
+
+	writeUint32(uint32(tx.Version))
+	writeUint32(tx.LockTime)
+
+	var hasScriptSigs bool
+	for _, in := range tx.TxIn {
+		if len(in.SignatureScript) != 0 {
+			hasScriptSigs = true
+			break
+		}
+	}
+	if hasScriptSigs {
+		var scripts bytes.Buffer
+		for _, in := range tx.TxIn {
+			wire.WriteVarBytes(&scripts, 0, in.SignatureScript)
+		}
+		scriptsHash := chainhash.HashH(scripts.Bytes())
+		b.Write(scriptsHash[:])
+	}
+
+	var sequences bytes.Buffer
+	for _, in := range tx.TxIn {
+		var bSequence [4]byte
+		binary.LittleEndian.PutUint32(bSequence[:], in.Sequence)
+		sequences.Write(bSequence[:])
+	}
+	sequencesHash := chainhash.HashH(sequences.Bytes())
+	writeUint32(uint32(len(tx.TxIn)))
+	b.Write(sequencesHash[:])
+
+	var outputs bytes.Buffer
+	for _, out := range tx.TxOut {
+		wire.WriteTxOut(&outputs, 0, 0, out)
+	}
+	outputsHash := chainhash.HashH(outputs.Bytes())
+	writeUint32(uint32(len(tx.TxOut)))
+	b.Write(outputsHash[:])
+
+	writeUint32(uint32(idx))
+
+	return chainhash.HashH(b.Bytes())
+}
+
 // calcWitnessSignatureHash computes the sighash digest of a transaction's
 // segwit input using the new, optimized digest calculation algorithm defined
 // in BIP0143: https://github.com/bitcoin/bips/blob/master/bip-0143.mediawiki.
@@ -554,6 +611,85 @@ func CalcWitnessSigHash(script []byte, sigHashes *TxSigHashes, hType SigHashType
 		amt)
 }
 
+// CalcInputSigHash computes the signature hash a signature must commit to in
+// order to spend the output with the passed public key script and amount by
+// the specified input of the transaction, observing the passed hash type.
+// Legacy inputs use the original algorithm, while inputs spending version 0
+// witness programs, either natively or nested in a pay-to-script-hash
+// output, use the algorithm defined in BIP0143.  The passed sighash cache is
+// only used for the latter and is calculated when nil.
+//
+// The script a signature commits to for pay-to-script-hash and
+// pay-to-witness-script-hash outputs is not part of the public key script, so
+// the redeem script must be the last item pushed by the signature script of
+// the input and the witness script must be the last item of its witness,
+// which is where they are found when verifying the input.  The entire script
+// is committed to, so signatures which follow an OP_CODESEPARATOR are not
+// supported.
+//
+// NOTE: Legacy inputs signing with SigHashSingle which do not have an output
+// with the same index result in the special hash of 1 (as a uint256 little
+// endian) due to a bug in the original Satoshi client which is now part of
+// the consensus rules.  For inputs using BIP0143, the hash of the outputs is
+// zero instead.
+func CalcInputSigHash(tx *wire.MsgTx, idx int, pkScript []byte, amt int64,
+	hashType SigHashType, sigHashes *TxSigHashes) ([]byte, error) {
+
+	if idx < 0 || idx >= len(tx.TxIn) {
+		return nil, fmt.Errorf("idx %d but %d txins", idx, len(tx.TxIn))
+	}
+	txIn := tx.TxIn[idx]
+
+	// The redeem script of a pay-to-script-hash output is the last item
+	// pushed by the signature script.
+	script := pkScript
+	if IsPayToScriptHash(script) {
+		pushes, err := PushedData(txIn.SignatureScript)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse signature script: "+
+				"%v", err)
+		}
+		if len(pushes) == 0 {
+			return nil, fmt.Errorf("signature script of input %d "+
+				"does not contain the redeem script", idx)
+		}
+		script = pushes[len(pushes)-1]
+	}
+
+	if !IsWitnessProgram(script) {
+		parsedScript, err := parseScript(script)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse script: %v", err)
+		}
+		return calcSignatureHash(parsedScript, hashType, tx, idx), nil
+	}
+
+	version, program, err := ExtractWitnessProgramInfo(script)
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 {
+		return nil, fmt.Errorf("unsupported witness program version %d",
+			version)
+	}
+
+	// The script code of a pay-to-witness-pubkey-hash program is derived
+	// from the program itself, while the one of a
+	// pay-to-witness-script-hash program is the last witness item.
+	if len(program) == payToWitnessScriptHashDataSize {
+		if len(txIn.Witness) == 0 {
+			return nil, fmt.Errorf("witness of input %d does not "+
+				"contain the witness script", idx)
+		}
+		script = txIn.Witness[len(txIn.Witness)-1]
+	}
+
+	if sigHashes == nil {
+		sigHashes = NewTxSigHashes(tx)
+	}
+	return CalcWitnessSigHash(script, sigHashes, hashType, tx, idx, amt)
+}
+
 // shallowCopyTx creates a shallow copy of the transaction for use when
 // calculating the signature hash.  It is used over the Copy method on the
 // transaction itself since that is a deep copy and therefore does more work and
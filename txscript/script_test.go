@@ -6,6 +6,8 @@ package txscript
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"reflect"
 	"testing"
 
@@ -4312,3 +4314,126 @@ func TestIsUnspendable(t *testing.T) {
 		}
 	}
 }
+
+// TestCalcTemplateHash ensures the BIP0119 template hash commits to the fields
+// of the transaction in the expected order, including the signature scripts
+// only when any of them is non-empty.
+func TestCalcTemplateHash(t *testing.T) {
+	t.Parallel()
+
+	sha := func(s string) string {
+		hash := sha256.Sum256(hexToBytes(s))
+		return hex.EncodeToString(hash[:])
+	}
+	output := &wire.TxOut{Value: 1000, PkScript: []byte{OP_TRUE}}
+	outputHex := "e803000000000000" + "0151"
+
+	tests := []struct {
+		name     string
+		tx       *wire.MsgTx
+		idx      int
+		preimage string
+	}{
+		{
+			name: "no signature scripts",
+			tx: &wire.MsgTx{
+				Version: 2,
+				TxIn: []*wire.TxIn{{
+					Sequence: wire.MaxTxInSequenceNum,
+				}},
+				TxOut: []*wire.TxOut{output},
+			},
+			idx: 0,
+			preimage: "02000000" + "00000000" + "01000000" +
+				sha("ffffffff") + "01000000" + sha(outputHex) +
+				"00000000",
+		},
+		{
+			name: "signature scripts",
+			tx: &wire.MsgTx{
+				Version: 1,
+				TxIn: []*wire.TxIn{{
+					SignatureScript: []byte{OP_TRUE},
+					Sequence:        wire.MaxTxInSequenceNum - 1,
+				}, {
+					Sequence: wire.MaxTxInSequenceNum,
+				}},
+				TxOut:    []*wire.TxOut{output, output},
+				LockTime: 100,
+			},
+			idx: 1,
+			preimage: "01000000" + "64000000" + sha("0151"+"00") +
+				"02000000" + sha("feffffff"+"ffffffff") +
+				"02000000" + sha(outputHex+outputHex) + "01000000",
+		},
+	}
+
+	for _, test := range tests {
+		want := sha256.Sum256(hexToBytes(test.preimage))
+		got := CalcTemplateHash(test.tx, test.idx)
+		if !bytes.Equal(got[:], want[:]) {
+			t.Errorf("%s: mismatched template hash - got %v, want %x",
+				test.name, got, want)
+		}
+	}
+}
+
+// TestCalcInputSigHash ensures the special cases of CalcInputSigHash are
+// handled as expected, including the signature hash of 1 for legacy inputs
+// signing with SigHashSingle without a matching output.
+func TestCalcInputSigHash(t *testing.T) {
+	t.Parallel()
+
+	p2pkh := mustParseShortForm("DUP HASH160 DATA_20 0x" +
+		"0000000000000000000000000000000000000000 EQUALVERIFY CHECKSIG")
+	p2sh := mustParseShortForm("HASH160 DATA_20 0x" +
+		"0000000000000000000000000000000000000000 EQUAL")
+	p2wsh := mustParseShortForm("0 DATA_32 0x" +
+		"0000000000000000000000000000000000000000000000000000000000000000")
+	tx := &wire.MsgTx{
+		Version: 1,
+		TxIn:    []*wire.TxIn{{}, {}},
+		TxOut:   []*wire.TxOut{{Value: 1000, PkScript: p2pkh}},
+	}
+
+	// Legacy inputs without a matching output sign the hash of 1.
+	hash, err := CalcInputSigHash(tx, 1, p2pkh, 0, SigHashSingle, nil)
+	if err != nil {
+		t.Fatalf("CalcInputSigHash: unexpected error: %v", err)
+	}
+	oneHash := make([]byte, 32)
+	oneHash[0] = 0x01
+	if !bytes.Equal(hash, oneHash) {
+		t.Fatalf("got hash %x for SigHashSingle without output, want %x",
+			hash, oneHash)
+	}
+
+	// The hash of the outputs is zero instead for inputs using BIP0143.
+	tx.TxIn[1].Witness = wire.TxWitness{p2pkh}
+	hash, err = CalcInputSigHash(tx, 1, p2wsh, 0, SigHashSingle, nil)
+	if err != nil {
+		t.Fatalf("CalcInputSigHash: unexpected error: %v", err)
+	}
+	want, err := CalcWitnessSigHash(p2pkh, NewTxSigHashes(tx),
+		SigHashSingle, tx, 1, 0)
+	if err != nil {
+		t.Fatalf("CalcWitnessSigHash: unexpected error: %v", err)
+	}
+	if !bytes.Equal(hash, want) || bytes.Equal(hash, oneHash) {
+		t.Fatalf("got witness hash %x, want %x", hash, want)
+	}
+
+	// The scripts committed to by script hash outputs must be provided by
+	// the input and the input must exist.
+	if _, err := CalcInputSigHash(tx, 0, p2sh, 0, SigHashAll, nil); err == nil {
+		t.Fatal("CalcInputSigHash: unexpected success without redeem " +
+			"script")
+	}
+	if _, err := CalcInputSigHash(tx, 0, p2wsh, 0, SigHashAll, nil); err == nil {
+		t.Fatal("CalcInputSigHash: unexpected success without witness " +
+			"script")
+	}
+	if _, err := CalcInputSigHash(tx, 2, p2pkh, 0, SigHashAll, nil); err == nil {
+		t.Fatal("CalcInputSigHash: unexpected success for invalid index")
+	}
+}
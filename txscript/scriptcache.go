@@ -0,0 +1,135 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+)
+
+// CacheStats houses the number of entries in a verification cache along with
+// the number of lookups which found an entry (hits) and which did not
+// (misses).
+type CacheStats struct {
+	Entries    uint
+	MaxEntries uint
+	Hits       uint64
+	Misses     uint64
+}
+
+// scriptCacheKey identifies a transaction input within the ScriptCache.  The
+// witness hash of the transaction commits to all of its scripts and witnesses
+// while the outputs it spends are fixed by the previous outpoints it commits
+// to, so the result of executing the scripts of the input only depends on the
+// script flags.
+type scriptCacheKey struct {
+	wtxid chainhash.Hash
+	index uint32
+}
+
+// ScriptCache implements a script execution cache which keeps track of the
+// transaction inputs whose scripts were found valid along with the flags they
+// were executed with.  It uses a randomized entry eviction policy the same way
+// as the SigCache.
+//
+// Its main benefit is to avoid executing the scripts of the transactions in a
+// block again, including the verification of their signatures, when they were
+// already validated by the mempool.  Since the script flags only ever add
+// restrictions, an input found valid under a set of flags is also valid under
+// any subset of them, which allows inputs validated using the stricter
+// standard flags of the mempool to be found under the consensus flags of a
+// block.  Conversely, an input cached before a soft fork activated new rules
+// is not found under the flags which enforce them.
+type ScriptCache struct {
+	// The following fields are atomically accessed and must be placed
+	// first for the 64-bit alignment required on 32-bit platforms.
+	hits   uint64
+	misses uint64
+
+	sync.RWMutex
+	validInputs map[scriptCacheKey]ScriptFlags
+	maxEntries  uint
+}
+
+// NewScriptCache creates and initializes a new instance of ScriptCache.  Its
+// sole parameter 'maxEntries' represents the maximum number of entries allowed
+// to exist in the ScriptCache at any particular moment.  Random entries are
+// evicted to make room for new entries that would cause the number of entries
+// in the cache to exceed the max.
+func NewScriptCache(maxEntries uint) *ScriptCache {
+	return &ScriptCache{
+		validInputs: make(map[scriptCacheKey]ScriptFlags, maxEntries),
+		maxEntries:  maxEntries,
+	}
+}
+
+// Exists returns true if the input at index 'index' of the transaction with
+// the witness hash 'wtxid' was previously found valid under at least all of
+// the passed flags.  Otherwise, false is returned.
+//
+// NOTE: This function is safe for concurrent access. Readers won't be blocked
+// unless there exists a writer, adding an entry to the ScriptCache.
+func (c *ScriptCache) Exists(wtxid chainhash.Hash, index uint32, flags ScriptFlags) bool {
+	c.RLock()
+	validFlags, ok := c.validInputs[scriptCacheKey{wtxid, index}]
+	c.RUnlock()
+
+	if ok && validFlags&flags == flags {
+		atomic.AddUint64(&c.hits, 1)
+		return true
+	}
+	atomic.AddUint64(&c.misses, 1)
+	return false
+}
+
+// Add adds an entry for the input at index 'index' of the transaction with the
+// witness hash 'wtxid', whose scripts were found valid under the passed flags,
+// to the script cache.  An existing entry for the input is replaced.  In the
+// event that the ScriptCache is 'full', an existing entry is randomly chosen to
+// be evicted in order to make space for the new entry.
+//
+// NOTE: This function is safe for concurrent access. Writers will block
+// simultaneous readers until function execution has concluded.
+func (c *ScriptCache) Add(wtxid chainhash.Hash, index uint32, flags ScriptFlags) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.maxEntries <= 0 {
+		return
+	}
+
+	// If adding this new entry will put us over the max number of allowed
+	// entries, then evict an entry.  See the SigCache for why relying on
+	// the random starting point of Go's map iteration is acceptable.
+	key := scriptCacheKey{wtxid, index}
+	if _, ok := c.validInputs[key]; !ok &&
+		uint(len(c.validInputs)+1) > c.maxEntries {
+
+		for entry := range c.validInputs {
+			delete(c.validInputs, entry)
+			break
+		}
+	}
+	c.validInputs[key] = flags
+}
+
+// Stats returns the number of entries in the script cache along with its hit
+// and miss counters.
+//
+// NOTE: This function is safe for concurrent access.
+func (c *ScriptCache) Stats() CacheStats {
+	c.RLock()
+	entries := uint(len(c.validInputs))
+	c.RUnlock()
+
+	return CacheStats{
+		Entries:    entries,
+		MaxEntries: c.maxEntries,
+		Hits:       atomic.LoadUint64(&c.hits),
+		Misses:     atomic.LoadUint64(&c.misses),
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package txscript
+
+import (
+	"testing"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+)
+
+// TestScriptCacheAddExists tests the ability to add, and later check the
+// existence of an input in the script cache under the flags it was added with
+// and under their subsets, along with the hit and miss counters.
+func TestScriptCacheAddExists(t *testing.T) {
+	scriptCache := NewScriptCache(10)
+
+	wtxid := chainhash.Hash{0x01}
+	flags := ScriptBip16 | ScriptVerifyWitness
+	scriptCache.Add(wtxid, 1, flags)
+
+	tests := []struct {
+		name  string
+		wtxid chainhash.Hash
+		index uint32
+		flags ScriptFlags
+		want  bool
+	}{
+		{"same flags", wtxid, 1, flags, true},
+		{"subset of flags", wtxid, 1, ScriptBip16, true},
+		{"no flags", wtxid, 1, 0, true},
+		{"additional flag", wtxid, 1, flags | ScriptVerifyCleanStack, false},
+		{"other input", wtxid, 0, flags, false},
+		{"other transaction", chainhash.Hash{0x02}, 1, flags, false},
+	}
+	for _, test := range tests {
+		got := scriptCache.Exists(test.wtxid, test.index, test.flags)
+		if got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	want := CacheStats{Entries: 1, MaxEntries: 10, Hits: 3, Misses: 3}
+	if stats := scriptCache.Stats(); stats != want {
+		t.Errorf("got stats %+v, want %+v", stats, want)
+	}
+
+	// Adding the input again replaces its flags.
+	scriptCache.Add(wtxid, 1, ScriptBip16)
+	if scriptCache.Exists(wtxid, 1, flags) {
+		t.Error("input found under flags it is no longer cached with")
+	}
+	if entries := scriptCache.Stats().Entries; entries != 1 {
+		t.Errorf("got %d entries, want 1", entries)
+	}
+}
+
+// TestScriptCacheAddEvictEntry tests the eviction case where a new input is
+// added to a full script cache which should trigger randomized eviction,
+// followed by adding the new input to the cache.
+func TestScriptCacheAddEvictEntry(t *testing.T) {
+	const maxEntries = 10
+	scriptCache := NewScriptCache(maxEntries)
+
+	// Fill the cache, then add one more input.
+	for i := uint32(0); i < maxEntries+1; i++ {
+		scriptCache.Add(chainhash.Hash{}, i, ScriptBip16)
+	}
+	if entries := scriptCache.Stats().Entries; entries != maxEntries {
+		t.Fatalf("got %d entries, want %d", entries, maxEntries)
+	}
+	if !scriptCache.Exists(chainhash.Hash{}, maxEntries, ScriptBip16) {
+		t.Fatal("most recently added input not found in script cache")
+	}
+}
+
+// TestScriptCacheAddMaxEntriesZero tests that no entries are added to a
+// script cache with a maximum of zero entries.
+func TestScriptCacheAddMaxEntriesZero(t *testing.T) {
+	scriptCache := NewScriptCache(0)
+	scriptCache.Add(chainhash.Hash{}, 0, ScriptBip16)
+	if scriptCache.Exists(chainhash.Hash{}, 0, ScriptBip16) {
+		t.Fatal("input found in script cache with zero max entries")
+	}
+}
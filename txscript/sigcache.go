@@ -6,6 +6,7 @@ package txscript
 
 import (
 	"sync"
+	"sync/atomic"
 
 	"github.com/ltcsuite/ltcd/btcec"
 	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
@@ -33,6 +34,11 @@ type sigCacheEntry struct {
 // optimization which speeds up the validation of transactions within a block,
 // if they've already been seen and verified within the mempool.
 type SigCache struct {
+	// The following fields are atomically accessed and must be placed
+	// first for the 64-bit alignment required on 32-bit platforms.
+	hits   uint64
+	misses uint64
+
 	sync.RWMutex
 	validSigs  map[chainhash.Hash]sigCacheEntry
 	maxEntries uint
@@ -60,7 +66,12 @@ func (s *SigCache) Exists(sigHash chainhash.Hash, sig *btcec.Signature, pubKey *
 	entry, ok := s.validSigs[sigHash]
 	s.RUnlock()
 
-	return ok && entry.pubKey.IsEqual(pubKey) && entry.sig.IsEqual(sig)
+	if ok && entry.pubKey.IsEqual(pubKey) && entry.sig.IsEqual(sig) {
+		atomic.AddUint64(&s.hits, 1)
+		return true
+	}
+	atomic.AddUint64(&s.misses, 1)
+	return false
 }
 
 // Add adds an entry for a signature over 'sigHash' under public key 'pubKey'
@@ -97,3 +108,20 @@ func (s *SigCache) Add(sigHash chainhash.Hash, sig *btcec.Signature, pubKey *btc
 	}
 	s.validSigs[sigHash] = sigCacheEntry{sig, pubKey}
 }
+
+// Stats returns the number of entries in the signature cache along with its
+// hit and miss counters.
+//
+// NOTE: This function is safe for concurrent access.
+func (s *SigCache) Stats() CacheStats {
+	s.RLock()
+	entries := uint(len(s.validSigs))
+	s.RUnlock()
+
+	return CacheStats{
+		Entries:    entries,
+		MaxEntries: s.maxEntries,
+		Hits:       atomic.LoadUint64(&s.hits),
+		Misses:     atomic.LoadUint64(&s.misses),
+	}
+}
@@ -6,8 +6,14 @@ package wire
 
 import (
 	"fmt"
+	"strings"
 )
 
+// checksumErrorDesc is the beginning of the description of the message error
+// returned when the payload of a message does not match the checksum in its
+// header.
+const checksumErrorDesc = "payload checksum failed"
+
 // MessageError describes an issue with a message.
 // An example of some potential issues are messages from the wrong bitcoin
 // network, invalid commands, mismatched checksums, and exceeding max payloads.
@@ -32,3 +38,10 @@ func (e *MessageError) Error() string {
 func messageError(f string, desc string) *MessageError {
 	return &MessageError{Func: f, Description: desc}
 }
+
+// IsChecksumError returns whether or not the passed error is a MessageError
+// caused by a message whose payload does not match the checksum in its header.
+func IsChecksumError(err error) bool {
+	merr, ok := err.(*MessageError)
+	return ok && strings.HasPrefix(merr.Description, checksumErrorDesc)
+}
@@ -55,6 +55,14 @@ const (
 	CmdGetCFHeaders = "getcfheaders"
 	CmdCFilter      = "cfilter"
 	CmdCFHeaders    = "cfheaders"
+	CmdGetCFCheckpt = "getcfcheckpt"
+	CmdCFCheckpt    = "cfcheckpt"
+	CmdSendCmpct    = "sendcmpct"
+	CmdCmpctBlock   = "cmpctblock"
+	CmdGetBlockTxn  = "getblocktxn"
+	CmdBlockTxn     = "blocktxn"
+	CmdAddrV2       = "addrv2"
+	CmdSendAddrV2   = "sendaddrv2"
 )
 
 // MessageEncoding represents the wire message encoding format to be used.
@@ -154,6 +162,12 @@ func makeEmptyMessage(command string) (Message, error) {
 	case CmdReject:
 		msg = &MsgReject{}
 
+	case CmdAddrV2:
+		msg = &MsgAddrV2{}
+
+	case CmdSendAddrV2:
+		msg = &MsgSendAddrV2{}
+
 	case CmdSendHeaders:
 		msg = &MsgSendHeaders{}
 
@@ -172,6 +186,24 @@ func makeEmptyMessage(command string) (Message, error) {
 	case CmdCFHeaders:
 		msg = &MsgCFHeaders{}
 
+	case CmdGetCFCheckpt:
+		msg = &MsgGetCFCheckpt{}
+
+	case CmdCFCheckpt:
+		msg = &MsgCFCheckpt{}
+
+	case CmdSendCmpct:
+		msg = &MsgSendCmpct{}
+
+	case CmdCmpctBlock:
+		msg = &MsgCmpctBlock{}
+
+	case CmdGetBlockTxn:
+		msg = &MsgGetBlockTxn{}
+
+	case CmdBlockTxn:
+		msg = &MsgBlockTxn{}
+
 	default:
 		return nil, fmt.Errorf("unhandled command [%s]", command)
 	}
@@ -390,7 +422,7 @@ func ReadMessageWithEncodingN(r io.Reader, pver uint32, btcnet BitcoinNet,
 	// Test checksum.
 	checksum := chainhash.DoubleHashB(payload)[0:4]
 	if !bytes.Equal(checksum[:], hdr.checksum[:]) {
-		str := fmt.Sprintf("payload checksum failed - header "+
+		str := fmt.Sprintf(checksumErrorDesc+" - header "+
 			"indicates %v, but actual checksum is %v.",
 			hdr.checksum, checksum)
 		return totalBytes, nil, nil, messageError("ReadMessage", str)
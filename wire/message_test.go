@@ -73,6 +73,14 @@ func TestMessage(t *testing.T) {
 	msgGetCFHeaders := NewMsgGetCFHeaders()
 	msgCFilter := NewMsgCFilter(&chainhash.Hash{}, true, []byte("payload"))
 	msgCFHeaders := NewMsgCFHeaders()
+	msgGetCFCheckpt := NewMsgGetCFCheckpt(&chainhash.Hash{}, false)
+	msgCFCheckpt := NewMsgCFCheckpt(&chainhash.Hash{}, true, 0)
+	msgSendCmpct := NewMsgSendCmpct(true, CmpctBlockVersion)
+	msgCmpctBlock := NewMsgCmpctBlock(bh, 123123)
+	msgGetBlockTxn := NewMsgGetBlockTxn(&chainhash.Hash{})
+	msgBlockTxn := NewMsgBlockTxn(&chainhash.Hash{})
+	msgAddrV2 := NewMsgAddrV2()
+	msgSendAddrV2 := NewMsgSendAddrV2()
 
 	tests := []struct {
 		in     Message    // Value to encode
@@ -106,6 +114,14 @@ func TestMessage(t *testing.T) {
 		{msgGetCFHeaders, msgGetCFHeaders, pver, MainNet, 62},
 		{msgCFilter, msgCFilter, pver, MainNet, 65},
 		{msgCFHeaders, msgCFHeaders, pver, MainNet, 58},
+		{msgGetCFCheckpt, msgGetCFCheckpt, pver, MainNet, 57},
+		{msgCFCheckpt, msgCFCheckpt, pver, MainNet, 58},
+		{msgSendCmpct, msgSendCmpct, pver, MainNet, 33},
+		{msgCmpctBlock, msgCmpctBlock, pver, MainNet, 114},
+		{msgGetBlockTxn, msgGetBlockTxn, pver, MainNet, 57},
+		{msgBlockTxn, msgBlockTxn, pver, MainNet, 57},
+		{msgAddrV2, msgAddrV2, pver, MainNet, 25},
+		{msgSendAddrV2, msgSendAddrV2, pver, MainNet, 24},
 	}
 
 	t.Logf("Running %d tests", len(tests))
@@ -375,6 +391,31 @@ func TestReadMessageWireErrors(t *testing.T) {
 	}
 }
 
+// TestIsChecksumError ensures only the errors caused by messages with a bad
+// checksum are identified as checksum errors.
+func TestIsChecksumError(t *testing.T) {
+	pver := ProtocolVersion
+	btcnet := MainNet
+
+	badChecksumBytes := makeHeader(btcnet, "version", 2, 0xbeef)
+	badChecksumBytes = append(badChecksumBytes, []byte{0x0, 0x0}...)
+	_, _, _, err := ReadMessageN(bytes.NewReader(badChecksumBytes), pver,
+		btcnet)
+	if !IsChecksumError(err) {
+		t.Errorf("IsChecksumError: got false for %v, want true", err)
+	}
+
+	unsupportedCommandBytes := makeHeader(btcnet, "bogus", 0, 0)
+	_, _, _, err = ReadMessageN(bytes.NewReader(unsupportedCommandBytes),
+		pver, btcnet)
+	if err == nil || IsChecksumError(err) {
+		t.Errorf("IsChecksumError: got true for %v, want false", err)
+	}
+	if IsChecksumError(io.EOF) {
+		t.Errorf("IsChecksumError: got true for %v, want false", io.EOF)
+	}
+}
+
 // TestWriteMessageWireErrors performs negative tests against wire encoding from
 // concrete messages to confirm error paths work correctly.
 func TestWriteMessageWireErrors(t *testing.T) {
@@ -0,0 +1,165 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestAddrV2Wire tests the MsgAddrV2 wire encode and decode for addresses of
+// each of the known networks.
+func TestAddrV2Wire(t *testing.T) {
+	pver := ProtocolVersion
+	ts := time.Unix(0x495fab29, 0)
+
+	torV3 := bytes.Repeat([]byte{0x53}, 32)
+	i2p := bytes.Repeat([]byte{0x12}, 32)
+	msg := NewMsgAddrV2()
+	msg.AddAddresses(
+		&NetAddress{Timestamp: ts, Services: SFNodeNetwork,
+			IP: net.ParseIP("127.0.0.1"), Port: 9333},
+		&NetAddress{Timestamp: ts, Services: SFNodeWitness,
+			IP: net.ParseIP("2001:db8::1"), Port: 9333},
+		&NetAddress{Timestamp: ts, Services: SFNodeNetwork,
+			IP:   net.ParseIP("fd87:d87e:eb43:102:304:506:708:90a"),
+			Port: 9333},
+		&NetAddress{Timestamp: ts, Services: SFNodeNetwork,
+			IP: net.ParseIP("fc00::1"), Port: 9333},
+		&NetAddress{Timestamp: ts, Services: SFNodeNetwork,
+			Port: 9333, NetID: NetIDTorV3, Addr: torV3},
+		&NetAddress{Timestamp: ts, Services: SFNodeNetwork,
+			Port: 0, NetID: NetIDI2P, Addr: i2p},
+	)
+
+	want := []byte{
+		0x06, // Varint for number of addresses
+		// IPv4
+		0x29, 0xab, 0x5f, 0x49, 0x01, 0x01, 0x04,
+		0x7f, 0x00, 0x00, 0x01, 0x24, 0x75,
+		// IPv6
+		0x29, 0xab, 0x5f, 0x49, 0x08, 0x02, 0x10,
+		0x20, 0x01, 0x0d, 0xb8, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		0x24, 0x75,
+		// Tor v2
+		0x29, 0xab, 0x5f, 0x49, 0x01, 0x03, 0x0a,
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+		0x09, 0x0a, 0x24, 0x75,
+		// CJDNS
+		0x29, 0xab, 0x5f, 0x49, 0x01, 0x06, 0x10,
+		0xfc, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+		0x24, 0x75,
+	}
+	// Tor v3
+	want = append(want, 0x29, 0xab, 0x5f, 0x49, 0x01, 0x04, 0x20)
+	want = append(want, torV3...)
+	want = append(want, 0x24, 0x75)
+	// I2P
+	want = append(want, 0x29, 0xab, 0x5f, 0x49, 0x01, 0x05, 0x20)
+	want = append(want, i2p...)
+	want = append(want, 0x00, 0x00)
+
+	var buf bytes.Buffer
+	if err := msg.BtcEncode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("BtcEncode: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Fatalf("BtcEncode\n got: %s want: %s", spew.Sdump(buf.Bytes()),
+			spew.Sdump(want))
+	}
+
+	var readmsg MsgAddrV2
+	if err := readmsg.BtcDecode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("BtcDecode: %v", err)
+	}
+	// The IPv4 address is decoded to its 16 byte form.
+	msg.AddrList[0].IP = msg.AddrList[0].IP.To16()
+	if !reflect.DeepEqual(&readmsg, msg) {
+		t.Fatalf("BtcDecode\n got: %s want: %s", spew.Sdump(&readmsg),
+			spew.Sdump(msg))
+	}
+	if readmsg.AddrList[0].NeedsAddrV2() ||
+		!readmsg.AddrList[4].NeedsAddrV2() {
+
+		t.Fatal("NeedsAddrV2: only Tor v3 and I2P addresses need addrv2")
+	}
+}
+
+// TestAddrV2WireIgnored ensures addresses of unknown networks and addresses
+// encoded in the range of another network are dropped from decoded addrv2
+// messages, while known networks with an invalid size are rejected.
+func TestAddrV2WireIgnored(t *testing.T) {
+	pver := ProtocolVersion
+
+	addr := func(netID byte, addr []byte) []byte {
+		b := []byte{0x29, 0xab, 0x5f, 0x49, 0x01, netID, byte(len(addr))}
+		b = append(b, addr...)
+		return append(b, 0x24, 0x75)
+	}
+	ipv4Mapped := net.ParseIP("127.0.0.1").To16()
+	onionCat := net.ParseIP("fd87:d87e:eb43::1")
+
+	tests := []struct {
+		name string
+		buf  []byte
+		err  bool
+	}{
+		{"unknown network", addr(0x07, []byte{1, 2, 3}), false},
+		{"IPv4 mapped IPv6", addr(0x02, ipv4Mapped), false},
+		{"OnionCat IPv6", addr(0x02, onionCat), false},
+		{"CJDNS outside fc00::/8", addr(0x06, ipv4Mapped), false},
+		{"short Tor v3", addr(0x04, make([]byte, 31)), true},
+		{"long IPv4", addr(0x01, make([]byte, 5)), true},
+	}
+
+	for _, test := range tests {
+		buf := append([]byte{0x01}, test.buf...)
+		var msg MsgAddrV2
+		err := msg.BtcDecode(bytes.NewReader(buf), pver, BaseEncoding)
+		if test.err {
+			if _, ok := err.(*MessageError); !ok {
+				t.Errorf("%s: got error %v, want MessageError",
+					test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.name, err)
+			continue
+		}
+		if len(msg.AddrList) != 0 {
+			t.Errorf("%s: address was not dropped: %s", test.name,
+				spew.Sdump(msg.AddrList))
+		}
+	}
+}
+
+// TestSendAddrV2 tests the MsgSendAddrV2 API.
+func TestSendAddrV2(t *testing.T) {
+	msg := NewMsgSendAddrV2()
+	if cmd := msg.Command(); cmd != "sendaddrv2" {
+		t.Errorf("NewMsgSendAddrV2: wrong command - got %v want %v",
+			cmd, "sendaddrv2")
+	}
+
+	// The message is valid for all protocol versions.
+	var buf bytes.Buffer
+	err := msg.BtcEncode(&buf, MultipleAddressVersion, BaseEncoding)
+	if err != nil || buf.Len() != 0 {
+		t.Errorf("encode of MsgSendAddrV2 failed: %v", err)
+	}
+	readmsg := NewMsgSendAddrV2()
+	err = readmsg.BtcDecode(&buf, MultipleAddressVersion, BaseEncoding)
+	if err != nil {
+		t.Errorf("decode of MsgSendAddrV2 failed: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+)
+
+// MsgBlockTxn implements the Message interface and represents a bitcoin
+// blocktxn message.  It is used to deliver the transactions of a block in
+// response to a getblocktxn message (MsgGetBlockTxn).  The transactions must
+// be in the same order as the indexes in the request they answer.
+//
+// This message was not added until protocol versions starting with
+// BIP0152Version.
+type MsgBlockTxn struct {
+	BlockHash    chainhash.Hash
+	Transactions []*MsgTx
+}
+
+// AddTransaction adds a transaction to the message.
+func (msg *MsgBlockTxn) AddTransaction(tx *MsgTx) error {
+	msg.Transactions = append(msg.Transactions, tx)
+	return nil
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgBlockTxn) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < BIP0152Version {
+		str := fmt.Sprintf("blocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgBlockTxn.BtcDecode", str)
+	}
+
+	err := readElement(r, &msg.BlockHash)
+	if err != nil {
+		return err
+	}
+
+	// Prevent more transactions than could possibly fit into a block.
+	// It would be possible to cause memory exhaustion and panics without
+	// a sane upper bound on this count.
+	txCount, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if txCount > maxTxPerBlock {
+		str := fmt.Sprintf("too many transactions to fit into a block "+
+			"[count %d, max %d]", txCount, maxTxPerBlock)
+		return messageError("MsgBlockTxn.BtcDecode", str)
+	}
+
+	msg.Transactions = make([]*MsgTx, 0, txCount)
+	for i := uint64(0); i < txCount; i++ {
+		tx := MsgTx{}
+		err := tx.BtcDecode(r, pver, enc)
+		if err != nil {
+			return err
+		}
+		msg.Transactions = append(msg.Transactions, &tx)
+	}
+
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgBlockTxn) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < BIP0152Version {
+		str := fmt.Sprintf("blocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgBlockTxn.BtcEncode", str)
+	}
+
+	err := writeElement(w, &msg.BlockHash)
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.Transactions)))
+	if err != nil {
+		return err
+	}
+
+	for _, tx := range msg.Transactions {
+		err = tx.BtcEncode(w, pver, enc)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgBlockTxn) Command() string {
+	return CmdBlockTxn
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgBlockTxn) MaxPayloadLength(pver uint32) uint32 {
+	// The transactions can never be larger than the block they are part
+	// of.
+	return MaxBlockPayload
+}
+
+// NewMsgBlockTxn returns a new bitcoin blocktxn message that conforms to the
+// Message interface.  See MsgBlockTxn for details.
+func NewMsgBlockTxn(blockHash *chainhash.Hash) *MsgBlockTxn {
+	return &MsgBlockTxn{
+		BlockHash:    *blockHash,
+		Transactions: make([]*MsgTx, 0),
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestBlockTxnWire tests the MsgBlockTxn wire encode and decode.
+func TestBlockTxnWire(t *testing.T) {
+	pver := ProtocolVersion
+	hash := blockOne.Header.BlockHash()
+
+	// Ensure the command is expected value.
+	wantCmd := "blocktxn"
+	msg := NewMsgBlockTxn(&hash)
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgBlockTxn: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+	msg.AddTransaction(blockOne.Transactions[0])
+
+	// The transactions are encoded exactly as they are in a block.
+	wantBuf := append(append([]byte{}, hash[:]...), blockOneBytes[80:]...)
+
+	// Encode the message to wire format.
+	var buf bytes.Buffer
+	if err := msg.BtcEncode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("BtcEncode: unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), wantBuf) {
+		t.Fatalf("BtcEncode\n got: %s want: %s",
+			spew.Sdump(buf.Bytes()), spew.Sdump(wantBuf))
+	}
+
+	// Decode the message from wire format.
+	var readMsg MsgBlockTxn
+	err := readMsg.BtcDecode(bytes.NewReader(wantBuf), pver, BaseEncoding)
+	if err != nil {
+		t.Fatalf("BtcDecode: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(&readMsg, msg) {
+		t.Fatalf("BtcDecode\n got: %s want: %s", spew.Sdump(readMsg),
+			spew.Sdump(msg))
+	}
+
+	// Older protocol versions should fail since message didn't exist yet.
+	buf.Reset()
+	if err := msg.BtcEncode(&buf, BIP0152Version-1, BaseEncoding); err == nil {
+		t.Fatal("BtcEncode: encode passed for old protocol version")
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+)
+
+const (
+	// CFCheckptInterval is the gap (in number of blocks) between each
+	// committed filter header checkpoint.
+	CFCheckptInterval = 1000
+
+	// maxCFCheckptsPerMsg is the maximum number of committed filter header
+	// checkpoints that fit into a single bitcoin cfcheckpt message.
+	maxCFCheckptsPerMsg = (MaxMessagePayload - chainhash.HashSize - 1 -
+		MaxVarIntPayload) / MaxCFHeaderPayload
+)
+
+// MsgCFCheckpt implements the Message interface and represents a bitcoin
+// cfcheckpt message.  It is used to deliver the committed filter headers of
+// the blocks at every CFCheckptInterval height in response to a getcfcheckpt
+// message (MsgGetCFCheckpt).  The headers are in order of increasing height
+// and the stop hash identifies the block which ends the chain they are part
+// of.
+type MsgCFCheckpt struct {
+	StopHash      chainhash.Hash
+	Extended      bool
+	FilterHeaders []*chainhash.Hash
+}
+
+// AddCFHeader adds a new committed filter header checkpoint to the message.
+func (msg *MsgCFCheckpt) AddCFHeader(header *chainhash.Hash) error {
+	if len(msg.FilterHeaders)+1 > maxCFCheckptsPerMsg {
+		str := fmt.Sprintf("too many committed filter header "+
+			"checkpoints in message [max %v]", maxCFCheckptsPerMsg)
+		return messageError("MsgCFCheckpt.AddCFHeader", str)
+	}
+
+	msg.FilterHeaders = append(msg.FilterHeaders, header)
+	return nil
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgCFCheckpt) BtcDecode(r io.Reader, pver uint32, _ MessageEncoding) error {
+	err := readElement(r, &msg.StopHash)
+	if err != nil {
+		return err
+	}
+
+	err = readElement(r, &msg.Extended)
+	if err != nil {
+		return err
+	}
+
+	// Limit to max committed filter header checkpoints per message.
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > maxCFCheckptsPerMsg {
+		str := fmt.Sprintf("too many committed filter header "+
+			"checkpoints for message [count %v, max %v]", count,
+			maxCFCheckptsPerMsg)
+		return messageError("MsgCFCheckpt.BtcDecode", str)
+	}
+
+	// Create a contiguous slice of headers to deserialize into in order to
+	// reduce the number of allocations.
+	headers := make([]chainhash.Hash, count)
+	msg.FilterHeaders = make([]*chainhash.Hash, 0, count)
+	for i := uint64(0); i < count; i++ {
+		header := &headers[i]
+		err := readElement(r, header)
+		if err != nil {
+			return err
+		}
+		msg.AddCFHeader(header)
+	}
+
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgCFCheckpt) BtcEncode(w io.Writer, pver uint32, _ MessageEncoding) error {
+	err := writeElement(w, &msg.StopHash)
+	if err != nil {
+		return err
+	}
+
+	err = writeElement(w, msg.Extended)
+	if err != nil {
+		return err
+	}
+
+	// Limit to max committed filter header checkpoints per message.
+	count := len(msg.FilterHeaders)
+	if count > maxCFCheckptsPerMsg {
+		str := fmt.Sprintf("too many committed filter header "+
+			"checkpoints for message [count %v, max %v]", count,
+			maxCFCheckptsPerMsg)
+		return messageError("MsgCFCheckpt.BtcEncode", str)
+	}
+
+	err = WriteVarInt(w, pver, uint64(count))
+	if err != nil {
+		return err
+	}
+
+	for _, header := range msg.FilterHeaders {
+		err := writeElement(w, header)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgCFCheckpt) Command() string {
+	return CmdCFCheckpt
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgCFCheckpt) MaxPayloadLength(pver uint32) uint32 {
+	return MaxMessagePayload
+}
+
+// NewMsgCFCheckpt returns a new bitcoin cfcheckpt message that conforms to the
+// Message interface.  See MsgCFCheckpt for details.
+func NewMsgCFCheckpt(stopHash *chainhash.Hash, extended bool, headersCount int) *MsgCFCheckpt {
+	return &MsgCFCheckpt{
+		StopHash:      *stopHash,
+		Extended:      extended,
+		FilterHeaders: make([]*chainhash.Hash, 0, headersCount),
+	}
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+)
+
+const (
+	// ShortIDSize is the number of bytes used to encode a compact block
+	// transaction short id.
+	ShortIDSize = 6
+
+	// maxShortID is the maximum value a transaction short id can take.
+	maxShortID = 1<<(ShortIDSize*8) - 1
+)
+
+// PrefilledTx houses a transaction which is sent in full along with a compact
+// block and its absolute index within the block.
+type PrefilledTx struct {
+	Index uint32
+	Tx    *MsgTx
+}
+
+// MsgCmpctBlock implements the Message interface and represents a bitcoin
+// cmpctblock message.  It is used to relay a block as defined by BIP0152 by
+// sending its header along with short transaction ids which the receiver is
+// expected to match against transactions it already knows about.  Any
+// transactions the sender expects the receiver will not have, such as the
+// coinbase, are sent in full as prefilled transactions.
+//
+// This message was not added until protocol versions starting with
+// BIP0152Version.
+type MsgCmpctBlock struct {
+	Header        BlockHeader
+	Nonce         uint64
+	ShortIDs      []uint64
+	PrefilledTxns []PrefilledTx
+}
+
+// AddShortID adds a new transaction short id to the message.
+func (msg *MsgCmpctBlock) AddShortID(shortID uint64) error {
+	if shortID > maxShortID {
+		str := fmt.Sprintf("short id %x exceeds %d bytes", shortID,
+			ShortIDSize)
+		return messageError("MsgCmpctBlock.AddShortID", str)
+	}
+
+	msg.ShortIDs = append(msg.ShortIDs, shortID)
+	return nil
+}
+
+// AddPrefilledTx adds a new prefilled transaction at the provided absolute
+// index within the block to the message.  Prefilled transactions must be added
+// in order of increasing index.
+func (msg *MsgCmpctBlock) AddPrefilledTx(index uint32, tx *MsgTx) error {
+	if n := len(msg.PrefilledTxns); n > 0 &&
+		index <= msg.PrefilledTxns[n-1].Index {
+
+		str := fmt.Sprintf("prefilled transaction index %d is not "+
+			"greater than previous index %d", index,
+			msg.PrefilledTxns[n-1].Index)
+		return messageError("MsgCmpctBlock.AddPrefilledTx", str)
+	}
+
+	msg.PrefilledTxns = append(msg.PrefilledTxns, PrefilledTx{
+		Index: index,
+		Tx:    tx,
+	})
+	return nil
+}
+
+// BlockTxCount returns the total number of transactions in the block the
+// message describes.
+func (msg *MsgCmpctBlock) BlockTxCount() int {
+	return len(msg.ShortIDs) + len(msg.PrefilledTxns)
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < BIP0152Version {
+		str := fmt.Sprintf("cmpctblock message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+
+	err := readBlockHeader(r, pver, &msg.Header)
+	if err != nil {
+		return err
+	}
+
+	err = readElement(r, &msg.Nonce)
+	if err != nil {
+		return err
+	}
+
+	// Prevent more short ids than could possibly fit into a block.
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > maxTxPerBlock {
+		str := fmt.Sprintf("too many short ids to fit into a block "+
+			"[count %d, max %d]", count, maxTxPerBlock)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+
+	var buf [8]byte
+	msg.ShortIDs = make([]uint64, 0, count)
+	for i := uint64(0); i < count; i++ {
+		_, err := io.ReadFull(r, buf[:ShortIDSize])
+		if err != nil {
+			return err
+		}
+		msg.ShortIDs = append(msg.ShortIDs, littleEndian.Uint64(buf[:]))
+	}
+
+	// Prevent more transactions than could possibly fit into a block.
+	prefilledCount, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count+prefilledCount > maxTxPerBlock {
+		str := fmt.Sprintf("too many transactions to fit into a block "+
+			"[count %d, max %d]", count+prefilledCount,
+			maxTxPerBlock)
+		return messageError("MsgCmpctBlock.BtcDecode", str)
+	}
+
+	// The indexes of prefilled transactions are differentially encoded
+	// relative to the previous index.
+	msg.PrefilledTxns = make([]PrefilledTx, 0, prefilledCount)
+	var nextIndex uint64
+	for i := uint64(0); i < prefilledCount; i++ {
+		diff, err := ReadVarInt(r, pver)
+		if err != nil {
+			return err
+		}
+		index := nextIndex + diff
+		if diff > math.MaxUint16 || index > math.MaxUint16 {
+			str := fmt.Sprintf("prefilled transaction index "+
+				"overflows [diff %d, previous %d]", diff,
+				nextIndex)
+			return messageError("MsgCmpctBlock.BtcDecode", str)
+		}
+
+		tx := MsgTx{}
+		err = tx.BtcDecode(r, pver, enc)
+		if err != nil {
+			return err
+		}
+		msg.PrefilledTxns = append(msg.PrefilledTxns, PrefilledTx{
+			Index: uint32(index),
+			Tx:    &tx,
+		})
+		nextIndex = index + 1
+	}
+
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < BIP0152Version {
+		str := fmt.Sprintf("cmpctblock message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgCmpctBlock.BtcEncode", str)
+	}
+
+	err := writeBlockHeader(w, pver, &msg.Header)
+	if err != nil {
+		return err
+	}
+
+	err = writeElement(w, msg.Nonce)
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.ShortIDs)))
+	if err != nil {
+		return err
+	}
+
+	var buf [8]byte
+	for _, shortID := range msg.ShortIDs {
+		littleEndian.PutUint64(buf[:], shortID)
+		_, err := w.Write(buf[:ShortIDSize])
+		if err != nil {
+			return err
+		}
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.PrefilledTxns)))
+	if err != nil {
+		return err
+	}
+
+	var nextIndex uint32
+	for _, ptx := range msg.PrefilledTxns {
+		if ptx.Index < nextIndex {
+			str := fmt.Sprintf("prefilled transaction index %d is "+
+				"out of order", ptx.Index)
+			return messageError("MsgCmpctBlock.BtcEncode", str)
+		}
+		err = WriteVarInt(w, pver, uint64(ptx.Index-nextIndex))
+		if err != nil {
+			return err
+		}
+
+		err = ptx.Tx.BtcEncode(w, pver, enc)
+		if err != nil {
+			return err
+		}
+		nextIndex = ptx.Index + 1
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgCmpctBlock) Command() string {
+	return CmdCmpctBlock
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgCmpctBlock) MaxPayloadLength(pver uint32) uint32 {
+	// A compact block can never be larger than the block it describes.
+	return MaxBlockPayload
+}
+
+// BlockHash computes the block identifier hash for the block the message
+// describes.
+func (msg *MsgCmpctBlock) BlockHash() chainhash.Hash {
+	return msg.Header.BlockHash()
+}
+
+// NewMsgCmpctBlock returns a new bitcoin cmpctblock message that conforms to
+// the Message interface.  See MsgCmpctBlock for details.
+func NewMsgCmpctBlock(blockHeader *BlockHeader, nonce uint64) *MsgCmpctBlock {
+	return &MsgCmpctBlock{
+		Header:        *blockHeader,
+		Nonce:         nonce,
+		ShortIDs:      make([]uint64, 0),
+		PrefilledTxns: make([]PrefilledTx, 0),
+	}
+}
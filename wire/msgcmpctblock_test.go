@@ -0,0 +1,99 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestCmpctBlockWire tests the MsgCmpctBlock wire encode and decode including
+// the encoding of the short ids and differential prefilled indexes.
+func TestCmpctBlockWire(t *testing.T) {
+	pver := ProtocolVersion
+
+	// Ensure the command is expected value.
+	wantCmd := "cmpctblock"
+	msg := NewMsgCmpctBlock(&blockOne.Header, 0x0102030405060708)
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgCmpctBlock: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure short ids larger than 6 bytes are rejected.
+	if err := msg.AddShortID(1 << 48); err == nil {
+		t.Fatal("AddShortID: accepted oversized short id")
+	}
+	for _, shortID := range []uint64{0x0000aabbccddeeff, 0xffffffffffff} {
+		if err := msg.AddShortID(shortID); err != nil {
+			t.Fatalf("AddShortID: unexpected error: %v", err)
+		}
+	}
+
+	// Ensure prefilled transactions must be added in increasing order.
+	coinbase := blockOne.Transactions[0]
+	if err := msg.AddPrefilledTx(0, coinbase); err != nil {
+		t.Fatalf("AddPrefilledTx: unexpected error: %v", err)
+	}
+	if err := msg.AddPrefilledTx(3, coinbase); err != nil {
+		t.Fatalf("AddPrefilledTx: unexpected error: %v", err)
+	}
+	if err := msg.AddPrefilledTx(3, coinbase); err == nil {
+		t.Fatal("AddPrefilledTx: accepted out of order index")
+	}
+	if msg.BlockTxCount() != 4 {
+		t.Fatalf("BlockTxCount: got %d, want 4", msg.BlockTxCount())
+	}
+
+	coinbaseBytes := blockOneBytes[81:]
+	var wantBuf []byte
+	wantBuf = append(wantBuf, blockOneBytes[:80]...) // Header
+	wantBuf = append(wantBuf,
+		0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01, // Nonce
+		0x02,                               // Varint for number of short ids
+		0xff, 0xee, 0xdd, 0xcc, 0xbb, 0xaa, // Short id
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, // Short id
+		0x02, // Varint for number of prefilled txns
+		0x00, // Index 0
+	)
+	wantBuf = append(wantBuf, coinbaseBytes...)
+	wantBuf = append(wantBuf, 0x02) // Index 3
+	wantBuf = append(wantBuf, coinbaseBytes...)
+
+	// Encode the message to wire format.
+	var buf bytes.Buffer
+	if err := msg.BtcEncode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("BtcEncode: unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), wantBuf) {
+		t.Fatalf("BtcEncode\n got: %s want: %s",
+			spew.Sdump(buf.Bytes()), spew.Sdump(wantBuf))
+	}
+
+	// Decode the message from wire format.
+	var readMsg MsgCmpctBlock
+	err := readMsg.BtcDecode(bytes.NewReader(wantBuf), pver, BaseEncoding)
+	if err != nil {
+		t.Fatalf("BtcDecode: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(&readMsg, msg) {
+		t.Fatalf("BtcDecode\n got: %s want: %s", spew.Sdump(readMsg),
+			spew.Sdump(msg))
+	}
+
+	// Older protocol versions should fail since message didn't exist yet.
+	buf.Reset()
+	if err := msg.BtcEncode(&buf, BIP0152Version-1, BaseEncoding); err == nil {
+		t.Fatal("BtcEncode: encode passed for old protocol version")
+	}
+	err = readMsg.BtcDecode(bytes.NewReader(wantBuf), BIP0152Version-1,
+		BaseEncoding)
+	if err == nil {
+		t.Fatal("BtcDecode: decode passed for old protocol version")
+	}
+}
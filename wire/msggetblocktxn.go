@@ -0,0 +1,147 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+)
+
+// MsgGetBlockTxn implements the Message interface and represents a bitcoin
+// getblocktxn message.  It is used to request the transactions of a block
+// previously announced via a cmpctblock message which could not be
+// reconstructed from the prefilled transactions and the transactions already
+// known to the requester.  The indexes are the absolute indexes of the
+// requested transactions within the block in increasing order.
+//
+// This message was not added until protocol versions starting with
+// BIP0152Version.
+type MsgGetBlockTxn struct {
+	BlockHash chainhash.Hash
+	Indexes   []uint32
+}
+
+// AddIndex adds a new transaction index to the message.  Indexes must be added
+// in increasing order.
+func (msg *MsgGetBlockTxn) AddIndex(index uint32) error {
+	if n := len(msg.Indexes); n > 0 && index <= msg.Indexes[n-1] {
+		str := fmt.Sprintf("transaction index %d is not greater than "+
+			"previous index %d", index, msg.Indexes[n-1])
+		return messageError("MsgGetBlockTxn.AddIndex", str)
+	}
+
+	msg.Indexes = append(msg.Indexes, index)
+	return nil
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxn) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < BIP0152Version {
+		str := fmt.Sprintf("getblocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgGetBlockTxn.BtcDecode", str)
+	}
+
+	err := readElement(r, &msg.BlockHash)
+	if err != nil {
+		return err
+	}
+
+	// Prevent more indexes than there could possibly be transactions in a
+	// block.
+	count, err := ReadVarInt(r, pver)
+	if err != nil {
+		return err
+	}
+	if count > maxTxPerBlock {
+		str := fmt.Sprintf("too many transaction indexes for message "+
+			"[count %d, max %d]", count, maxTxPerBlock)
+		return messageError("MsgGetBlockTxn.BtcDecode", str)
+	}
+
+	// The indexes are differentially encoded relative to the previous
+	// index.
+	msg.Indexes = make([]uint32, 0, count)
+	var nextIndex uint64
+	for i := uint64(0); i < count; i++ {
+		diff, err := ReadVarInt(r, pver)
+		if err != nil {
+			return err
+		}
+		index := nextIndex + diff
+		if diff > math.MaxUint16 || index > math.MaxUint16 {
+			str := fmt.Sprintf("transaction index overflows "+
+				"[diff %d, previous %d]", diff, nextIndex)
+			return messageError("MsgGetBlockTxn.BtcDecode", str)
+		}
+		msg.Indexes = append(msg.Indexes, uint32(index))
+		nextIndex = index + 1
+	}
+
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxn) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < BIP0152Version {
+		str := fmt.Sprintf("getblocktxn message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgGetBlockTxn.BtcEncode", str)
+	}
+
+	err := writeElement(w, &msg.BlockHash)
+	if err != nil {
+		return err
+	}
+
+	err = WriteVarInt(w, pver, uint64(len(msg.Indexes)))
+	if err != nil {
+		return err
+	}
+
+	var nextIndex uint32
+	for _, index := range msg.Indexes {
+		if index < nextIndex {
+			str := fmt.Sprintf("transaction index %d is out of "+
+				"order", index)
+			return messageError("MsgGetBlockTxn.BtcEncode", str)
+		}
+		err = WriteVarInt(w, pver, uint64(index-nextIndex))
+		if err != nil {
+			return err
+		}
+		nextIndex = index + 1
+	}
+
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgGetBlockTxn) Command() string {
+	return CmdGetBlockTxn
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgGetBlockTxn) MaxPayloadLength(pver uint32) uint32 {
+	// Block hash + num indexes (varInt) + max indexes which are each
+	// encoded with at most 3 bytes since they can't exceed 16 bits.
+	return chainhash.HashSize + MaxVarIntPayload + (maxTxPerBlock * 3)
+}
+
+// NewMsgGetBlockTxn returns a new bitcoin getblocktxn message that conforms to
+// the Message interface.  See MsgGetBlockTxn for details.
+func NewMsgGetBlockTxn(blockHash *chainhash.Hash) *MsgGetBlockTxn {
+	return &MsgGetBlockTxn{
+		BlockHash: *blockHash,
+		Indexes:   make([]uint32, 0),
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestGetBlockTxnWire tests the MsgGetBlockTxn wire encode and decode
+// including the differential encoding of the transaction indexes.
+func TestGetBlockTxnWire(t *testing.T) {
+	pver := ProtocolVersion
+	hash := blockOne.Header.BlockHash()
+
+	// Ensure the command is expected value.
+	wantCmd := "getblocktxn"
+	msg := NewMsgGetBlockTxn(&hash)
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgGetBlockTxn: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure indexes must be added in increasing order.
+	for _, index := range []uint32{1, 2, 5, 300} {
+		if err := msg.AddIndex(index); err != nil {
+			t.Fatalf("AddIndex: unexpected error: %v", err)
+		}
+	}
+	if err := msg.AddIndex(300); err == nil {
+		t.Fatal("AddIndex: accepted out of order index")
+	}
+
+	wantBuf := append(hash[:],
+		0x04,             // Varint for number of indexes
+		0x01,             // Index 1
+		0x00,             // Index 2
+		0x02,             // Index 5
+		0xfd, 0x26, 0x01, // Index 300
+	)
+
+	// Encode the message to wire format.
+	var buf bytes.Buffer
+	if err := msg.BtcEncode(&buf, pver, BaseEncoding); err != nil {
+		t.Fatalf("BtcEncode: unexpected error: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), wantBuf) {
+		t.Fatalf("BtcEncode\n got: %s want: %s",
+			spew.Sdump(buf.Bytes()), spew.Sdump(wantBuf))
+	}
+
+	// Decode the message from wire format.
+	var readMsg MsgGetBlockTxn
+	err := readMsg.BtcDecode(bytes.NewReader(wantBuf), pver, BaseEncoding)
+	if err != nil {
+		t.Fatalf("BtcDecode: unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(&readMsg, msg) {
+		t.Fatalf("BtcDecode\n got: %s want: %s", spew.Sdump(readMsg),
+			spew.Sdump(msg))
+	}
+
+	// Ensure indexes which overflow 16 bits are rejected.
+	overflowBuf := append(hash[:],
+		0x02,             // Varint for number of indexes
+		0xfd, 0xff, 0xff, // Index 65535
+		0xfe, 0x00, 0x00, 0x00, // Index 65536
+		0x00,
+	)
+	err = readMsg.BtcDecode(bytes.NewReader(overflowBuf), pver,
+		BaseEncoding)
+	if _, ok := err.(*MessageError); !ok {
+		t.Fatalf("BtcDecode: did not reject overflowing index -- got "+
+			"%v", err)
+	}
+
+	// Older protocol versions should fail since message didn't exist yet.
+	buf.Reset()
+	if err := msg.BtcEncode(&buf, BIP0152Version-1, BaseEncoding); err == nil {
+		t.Fatal("BtcEncode: encode passed for old protocol version")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"io"
+
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+)
+
+// MsgGetCFCheckpt implements the Message interface and represents a bitcoin
+// getcfcheckpt message.  It is used to request the committed filter headers
+// of the blocks at every CFCheckptInterval height in the chain ending with the
+// block identified by the stop hash.  The Extended field selects the chain of
+// basic (false) or extended (true) filter headers.
+type MsgGetCFCheckpt struct {
+	StopHash chainhash.Hash
+	Extended bool
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgGetCFCheckpt) BtcDecode(r io.Reader, pver uint32, _ MessageEncoding) error {
+	err := readElement(r, &msg.StopHash)
+	if err != nil {
+		return err
+	}
+	return readElement(r, &msg.Extended)
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgGetCFCheckpt) BtcEncode(w io.Writer, pver uint32, _ MessageEncoding) error {
+	err := writeElement(w, &msg.StopHash)
+	if err != nil {
+		return err
+	}
+	return writeElement(w, msg.Extended)
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgGetCFCheckpt) Command() string {
+	return CmdGetCFCheckpt
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgGetCFCheckpt) MaxPayloadLength(pver uint32) uint32 {
+	// Stop hash + Extended flag.
+	return chainhash.HashSize + 1
+}
+
+// NewMsgGetCFCheckpt returns a new bitcoin getcfcheckpt message that conforms
+// to the Message interface using the passed parameters and defaults for the
+// remaining fields.
+func NewMsgGetCFCheckpt(stopHash *chainhash.Hash, extended bool) *MsgGetCFCheckpt {
+	return &MsgGetCFCheckpt{
+		StopHash: *stopHash,
+		Extended: extended,
+	}
+}
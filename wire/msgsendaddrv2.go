@@ -0,0 +1,45 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import "io"
+
+// MsgSendAddrV2 implements the Message interface and represents a bitcoin
+// sendaddrv2 message as defined by BIP0155.  It is used to signal support for
+// receiving addrv2 messages (MsgAddrV2) and is only valid before the verack
+// message.
+//
+// This message has no payload.
+type MsgSendAddrV2 struct{}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgSendAddrV2) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	return nil
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgSendAddrV2) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	return nil
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgSendAddrV2) Command() string {
+	return CmdSendAddrV2
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgSendAddrV2) MaxPayloadLength(pver uint32) uint32 {
+	return 0
+}
+
+// NewMsgSendAddrV2 returns a new bitcoin sendaddrv2 message that conforms to
+// the Message interface.  See MsgSendAddrV2 for details.
+func NewMsgSendAddrV2() *MsgSendAddrV2 {
+	return &MsgSendAddrV2{}
+}
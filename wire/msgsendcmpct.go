@@ -0,0 +1,80 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	// CmpctBlockVersion is the compact block version which identifies
+	// transactions by their hash without witness data.
+	CmpctBlockVersion uint64 = 1
+
+	// CmpctBlockWitnessVersion is the compact block version which
+	// identifies transactions by their witness hash and includes witness
+	// data in all transactions sent along with compact blocks.
+	CmpctBlockWitnessVersion uint64 = 2
+)
+
+// MsgSendCmpct implements the Message interface and represents a bitcoin
+// sendcmpct message.  It is used to signal that the sender supports compact
+// block relay as defined by BIP0152 and, when AnnounceBlocks is set, that the
+// receiver should announce new blocks by sending a cmpctblock message directly
+// (high-bandwidth mode) rather than an inv or headers message.
+//
+// This message was not added until protocol versions starting with
+// BIP0152Version.
+type MsgSendCmpct struct {
+	AnnounceBlocks bool
+	Version        uint64
+}
+
+// BtcDecode decodes r using the bitcoin protocol encoding into the receiver.
+// This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) BtcDecode(r io.Reader, pver uint32, enc MessageEncoding) error {
+	if pver < BIP0152Version {
+		str := fmt.Sprintf("sendcmpct message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgSendCmpct.BtcDecode", str)
+	}
+
+	return readElements(r, &msg.AnnounceBlocks, &msg.Version)
+}
+
+// BtcEncode encodes the receiver to w using the bitcoin protocol encoding.
+// This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) BtcEncode(w io.Writer, pver uint32, enc MessageEncoding) error {
+	if pver < BIP0152Version {
+		str := fmt.Sprintf("sendcmpct message invalid for protocol "+
+			"version %d", pver)
+		return messageError("MsgSendCmpct.BtcEncode", str)
+	}
+
+	return writeElements(w, msg.AnnounceBlocks, msg.Version)
+}
+
+// Command returns the protocol command string for the message.  This is part
+// of the Message interface implementation.
+func (msg *MsgSendCmpct) Command() string {
+	return CmdSendCmpct
+}
+
+// MaxPayloadLength returns the maximum length the payload can be for the
+// receiver.  This is part of the Message interface implementation.
+func (msg *MsgSendCmpct) MaxPayloadLength(pver uint32) uint32 {
+	// Announce flag 1 byte + version 8 bytes.
+	return 9
+}
+
+// NewMsgSendCmpct returns a new bitcoin sendcmpct message that conforms to the
+// Message interface.  See MsgSendCmpct for details.
+func NewMsgSendCmpct(announceBlocks bool, version uint64) *MsgSendCmpct {
+	return &MsgSendCmpct{
+		AnnounceBlocks: announceBlocks,
+		Version:        version,
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/davecgh/go-spew/spew"
+)
+
+// TestSendCmpct tests the MsgSendCmpct API against the latest protocol
+// version.
+func TestSendCmpct(t *testing.T) {
+	pver := ProtocolVersion
+	enc := BaseEncoding
+
+	// Ensure the command is expected value.
+	wantCmd := "sendcmpct"
+	msg := NewMsgSendCmpct(true, CmpctBlockWitnessVersion)
+	if cmd := msg.Command(); cmd != wantCmd {
+		t.Errorf("NewMsgSendCmpct: wrong command - got %v want %v",
+			cmd, wantCmd)
+	}
+
+	// Ensure max payload is expected value.
+	wantPayload := uint32(9)
+	maxPayload := msg.MaxPayloadLength(pver)
+	if maxPayload != wantPayload {
+		t.Errorf("MaxPayloadLength: wrong max payload length for "+
+			"protocol version %d - got %v, want %v", pver,
+			maxPayload, wantPayload)
+	}
+
+	// Older protocol versions should fail encode since message didn't
+	// exist yet.
+	var buf bytes.Buffer
+	oldPver := BIP0152Version - 1
+	err := msg.BtcEncode(&buf, oldPver, enc)
+	if err == nil {
+		t.Errorf("encode of MsgSendCmpct passed for old protocol "+
+			"version %v", oldPver)
+	}
+
+	// Older protocol versions should fail decode since message didn't
+	// exist yet.
+	readmsg := MsgSendCmpct{}
+	err = readmsg.BtcDecode(&buf, oldPver, enc)
+	if err == nil {
+		t.Errorf("decode of MsgSendCmpct passed for old protocol "+
+			"version %v", oldPver)
+	}
+}
+
+// TestSendCmpctWire tests the MsgSendCmpct wire encode and decode for various
+// protocol versions.
+func TestSendCmpctWire(t *testing.T) {
+	tests := []struct {
+		in   *MsgSendCmpct // Message to encode
+		out  *MsgSendCmpct // Expected decoded message
+		buf  []byte        // Wire encoding
+		pver uint32        // Protocol version for wire encoding
+	}{
+		// Latest protocol version with high-bandwidth mode.
+		{
+			NewMsgSendCmpct(true, CmpctBlockWitnessVersion),
+			NewMsgSendCmpct(true, CmpctBlockWitnessVersion),
+			[]byte{
+				0x01,                                           // Announce
+				0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Version
+			},
+			ProtocolVersion,
+		},
+
+		// Protocol version BIP0152Version with low-bandwidth mode.
+		{
+			NewMsgSendCmpct(false, CmpctBlockVersion),
+			NewMsgSendCmpct(false, CmpctBlockVersion),
+			[]byte{
+				0x00,                                           // Announce
+				0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // Version
+			},
+			BIP0152Version,
+		},
+	}
+
+	t.Logf("Running %d tests", len(tests))
+	for i, test := range tests {
+		// Encode the message to wire format.
+		var buf bytes.Buffer
+		err := test.in.BtcEncode(&buf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcEncode #%d error %v", i, err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), test.buf) {
+			t.Errorf("BtcEncode #%d\n got: %s want: %s", i,
+				spew.Sdump(buf.Bytes()), spew.Sdump(test.buf))
+			continue
+		}
+
+		// Decode the message from wire format.
+		var msg MsgSendCmpct
+		rbuf := bytes.NewReader(test.buf)
+		err = msg.BtcDecode(rbuf, test.pver, BaseEncoding)
+		if err != nil {
+			t.Errorf("BtcDecode #%d error %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(&msg, test.out) {
+			t.Errorf("BtcDecode #%d\n got: %s want: %s", i,
+				spew.Sdump(msg), spew.Sdump(test.out))
+			continue
+		}
+	}
+}
@@ -38,12 +38,29 @@ type NetAddress struct {
 	// Bitfield which identifies the services supported by the address.
 	Services ServiceFlag
 
-	// IP address of the peer.
+	// IP address of the peer.  It is nil for addresses which are not IP
+	// addresses, in which case NetID and Addr are set instead.
 	IP net.IP
 
 	// Port the peer is using.  This is encoded in big endian on the wire
 	// which differs from most everything else.
 	Port uint16
+
+	// NetID identifies the network of the address of the peer when it is
+	// not an IP address, which is the case for Tor v3 and I2P addresses.
+	// Such addresses can only be relayed in addrv2 messages (BIP0155).  It
+	// is zero for IP addresses.
+	NetID NetworkID
+
+	// Addr is the address of the peer when NetID is set.
+	Addr []byte
+}
+
+// NeedsAddrV2 returns whether the address is not an IP address and can
+// therefore only be relayed in addrv2 messages.  Such addresses are encoded as
+// the unspecified IP address in all other messages.
+func (na *NetAddress) NeedsAddrV2() bool {
+	return na.NetID != 0
 }
 
 // HasService returns whether the specified service is supported by the address.
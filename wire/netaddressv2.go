@@ -0,0 +1,224 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package wire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// MaxAddrV2Size is the maximum size of an address in an addrv2 message as
+// defined by BIP0155.
+const MaxAddrV2Size = 512
+
+// NetworkID identifies the network of an address in an addrv2 message as
+// defined by BIP0155.
+type NetworkID uint8
+
+const (
+	// NetIDIPv4 identifies IPv4 addresses.
+	NetIDIPv4 NetworkID = 1
+
+	// NetIDIPv6 identifies IPv6 addresses.
+	NetIDIPv6 NetworkID = 2
+
+	// NetIDTorV2 identifies Tor v2 addresses, which are the 10 bytes of
+	// the onion address.
+	NetIDTorV2 NetworkID = 3
+
+	// NetIDTorV3 identifies Tor v3 addresses, which are the 32 byte public
+	// key of the onion service.
+	NetIDTorV3 NetworkID = 4
+
+	// NetIDI2P identifies I2P addresses, which are the 32 byte hash of the
+	// destination.
+	NetIDI2P NetworkID = 5
+
+	// NetIDCJDNS identifies CJDNS addresses, which are IPv6 addresses in
+	// the fc00::/8 range.
+	NetIDCJDNS NetworkID = 6
+)
+
+// netIDStrings is a map of network IDs back to their names for pretty
+// printing.
+var netIDStrings = map[NetworkID]string{
+	NetIDIPv4:  "IPv4",
+	NetIDIPv6:  "IPv6",
+	NetIDTorV2: "TorV2",
+	NetIDTorV3: "TorV3",
+	NetIDI2P:   "I2P",
+	NetIDCJDNS: "CJDNS",
+}
+
+// String returns the NetworkID in human-readable form.
+func (id NetworkID) String() string {
+	if s, ok := netIDStrings[id]; ok {
+		return s
+	}
+	return fmt.Sprintf("Unknown NetworkID (%d)", uint8(id))
+}
+
+// addrV2Sizes maps the networks known to this package to the size of their
+// addresses.  Addresses of known networks with any other size are invalid.
+var addrV2Sizes = map[NetworkID]int{
+	NetIDIPv4:  4,
+	NetIDIPv6:  16,
+	NetIDTorV2: 10,
+	NetIDTorV3: 32,
+	NetIDI2P:   32,
+	NetIDCJDNS: 16,
+}
+
+// onionCatPrefix is the IPv6 prefix Tor v2 addresses are encoded with when
+// they are held as IP addresses.
+var onionCatPrefix = []byte{0xfd, 0x87, 0xd8, 0x7e, 0xeb, 0x43}
+
+// cjdnsPrefix is the first byte of all CJDNS addresses.
+const cjdnsPrefix = 0xfc
+
+// NewNetAddressNetID returns a new NetAddress for an address which is not an
+// IP address using the provided network, address, port, and supported services
+// with defaults for the remaining fields.
+func NewNetAddressNetID(netID NetworkID, addr []byte, port uint16,
+	services ServiceFlag) *NetAddress {
+
+	na := NewNetAddressTimestamp(time.Now(), services, nil, port)
+	na.NetID = netID
+	na.Addr = addr
+	return na
+}
+
+// maxNetAddressV2Payload returns the max payload size for an address in an
+// addrv2 message.
+func maxNetAddressV2Payload() uint32 {
+	// Timestamp 4 bytes + services up to 9 bytes + network id 1 byte +
+	// address length up to 3 bytes + address + port 2 bytes.
+	return 4 + MaxVarIntPayload + 1 + 3 + MaxAddrV2Size + 2
+}
+
+// readNetAddressV2 reads an address encoded as defined by BIP0155 from r into
+// na.  It returns false without an error for addresses of networks which are
+// not known to this package, which must be ignored, along with IPv6 and CJDNS
+// addresses which are encoded in the range reserved for another network.
+func readNetAddressV2(r io.Reader, pver uint32, na *NetAddress) (bool, error) {
+	err := readElement(r, (*uint32Time)(&na.Timestamp))
+	if err != nil {
+		return false, err
+	}
+	services, err := ReadVarInt(r, pver)
+	if err != nil {
+		return false, err
+	}
+	id, err := binarySerializer.Uint8(r)
+	if err != nil {
+		return false, err
+	}
+	netID := NetworkID(id)
+	addr, err := ReadVarBytes(r, pver, MaxAddrV2Size, "address")
+	if err != nil {
+		return false, err
+	}
+	port, err := binarySerializer.Uint16(r, bigEndian)
+	if err != nil {
+		return false, err
+	}
+
+	size, ok := addrV2Sizes[netID]
+	if !ok {
+		return false, nil
+	}
+	if len(addr) != size {
+		str := fmt.Sprintf("%v address has size %d, want %d", netID,
+			len(addr), size)
+		return false, messageError("readNetAddressV2", str)
+	}
+
+	*na = NetAddress{
+		Timestamp: na.Timestamp,
+		Services:  ServiceFlag(services),
+		Port:      port,
+	}
+	switch netID {
+	case NetIDIPv4:
+		na.IP = net.IPv4(addr[0], addr[1], addr[2], addr[3])
+
+	case NetIDIPv6:
+		ip := net.IP(addr)
+		if ip.To4() != nil || bytes.HasPrefix(ip, onionCatPrefix) {
+			return false, nil
+		}
+		na.IP = ip
+
+	case NetIDTorV2:
+		na.IP = net.IP(append(append([]byte{}, onionCatPrefix...),
+			addr...))
+
+	case NetIDCJDNS:
+		if addr[0] != cjdnsPrefix {
+			return false, nil
+		}
+		na.IP = net.IP(addr)
+
+	default:
+		na.NetID = netID
+		na.Addr = addr
+	}
+	return true, nil
+}
+
+// writeNetAddressV2 serializes na to w as defined by BIP0155.  IP addresses are
+// encoded as the network they belong to, so Tor v2 addresses held as OnionCat
+// IPv6 addresses are encoded as Tor v2 addresses and IPv6 addresses in the
+// fc00::/8 range as CJDNS addresses.
+func writeNetAddressV2(w io.Writer, pver uint32, na *NetAddress) error {
+	netID, addr := na.NetID, na.Addr
+	if netID != 0 {
+		if size, ok := addrV2Sizes[netID]; ok && len(addr) != size {
+			str := fmt.Sprintf("%v address has size %d, want %d",
+				netID, len(addr), size)
+			return messageError("writeNetAddressV2", str)
+		}
+		if len(addr) > MaxAddrV2Size {
+			str := fmt.Sprintf("address has size %d, max %d",
+				len(addr), MaxAddrV2Size)
+			return messageError("writeNetAddressV2", str)
+		}
+	} else {
+		var ip [16]byte
+		if na.IP != nil {
+			copy(ip[:], na.IP.To16())
+		}
+		switch {
+		case na.IP.To4() != nil:
+			netID, addr = NetIDIPv4, ip[12:]
+		case bytes.HasPrefix(ip[:], onionCatPrefix):
+			netID, addr = NetIDTorV2, ip[len(onionCatPrefix):]
+		case ip[0] == cjdnsPrefix:
+			netID, addr = NetIDCJDNS, ip[:]
+		default:
+			netID, addr = NetIDIPv6, ip[:]
+		}
+	}
+
+	err := writeElement(w, uint32(na.Timestamp.Unix()))
+	if err != nil {
+		return err
+	}
+	if err := WriteVarInt(w, pver, uint64(na.Services)); err != nil {
+		return err
+	}
+	if err := binarySerializer.PutUint8(w, uint8(netID)); err != nil {
+		return err
+	}
+	if err := WriteVarBytes(w, pver, addr); err != nil {
+		return err
+	}
+
+	return binary.Write(w, bigEndian, na.Port)
+}
@@ -51,6 +51,17 @@ const (
 	// FeeFilterVersion is the protocol version which added a new
 	// feefilter message.
 	FeeFilterVersion uint32 = 70013
+
+	// BIP0152Version is the protocol version which added compact block
+	// relay along with the sendcmpct, cmpctblock, getblocktxn, and blocktxn
+	// messages.
+	BIP0152Version uint32 = 70014
+
+	// AddrV2Version is the protocol version from which peers are sent a
+	// sendaddrv2 message to signal support for addrv2 messages as defined
+	// by BIP0155.  Both messages are valid for all protocol versions, so
+	// this only avoids sending them to peers which predate them.
+	AddrV2Version uint32 = 70016
 )
 
 // ServiceFlag identifies services supported by a bitcoin peer.
@@ -75,15 +86,21 @@ const (
 	// SFNodeCF is a flag used to indicate a peer supports committed
 	// filters (CFs).
 	SFNodeCF
+
+	// SFNodeNetworkLimited is a flag used to indicate a peer only serves
+	// the most recent blocks of the main chain, such as is the case for
+	// nodes which prune old blocks (BIP0159).
+	SFNodeNetworkLimited ServiceFlag = 1 << 10
 )
 
 // Map of service flags back to their constant names for pretty printing.
 var sfStrings = map[ServiceFlag]string{
-	SFNodeNetwork: "SFNodeNetwork",
-	SFNodeGetUTXO: "SFNodeGetUTXO",
-	SFNodeBloom:   "SFNodeBloom",
-	SFNodeWitness: "SFNodeWitness",
-	SFNodeCF:      "SFNodeCF",
+	SFNodeNetwork:        "SFNodeNetwork",
+	SFNodeGetUTXO:        "SFNodeGetUTXO",
+	SFNodeBloom:          "SFNodeBloom",
+	SFNodeWitness:        "SFNodeWitness",
+	SFNodeCF:             "SFNodeCF",
+	SFNodeNetworkLimited: "SFNodeNetworkLimited",
 }
 
 // orderedSFStrings is an ordered list of service flags from highest to
@@ -94,6 +111,7 @@ var orderedSFStrings = []ServiceFlag{
 	SFNodeBloom,
 	SFNodeWitness,
 	SFNodeCF,
+	SFNodeNetworkLimited,
 }
 
 // String returns the ServiceFlag in human-readable form.
@@ -0,0 +1,20 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+/*
+Package zmq implements the ZMQ publish/subscribe sockets used for the block and
+transaction notifications of a node.
+
+ZMQ Overview
+
+A Publisher listens on a tcp endpoint and speaks the ZMTP 3 protocol with the
+NULL security mechanism, so it can be consumed by any ZMQ SUB socket, such as
+those of the tools which consume the notifications of Bitcoin Core.  Each
+message consists of a topic, a body and the sequence number of the message
+within its topic, which lets subscribers detect the messages they missed.
+
+A Subscriber connects to a publisher and receives the messages of the topics it
+subscribed to.
+*/
+package zmq
@@ -0,0 +1,363 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zmq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// handshakeTimeout is the time allowed for subscribers to complete the
+	// ZMTP handshake after connecting.
+	handshakeTimeout = 10 * time.Second
+
+	// DefaultHighWaterMark is the default maximum number of messages queued
+	// for each subscriber.
+	DefaultHighWaterMark = 1000
+)
+
+var (
+	// ErrPublisherClosed is returned when publishing with a publisher which
+	// has been closed.
+	ErrPublisherClosed = errors.New("zmq publisher closed")
+)
+
+// ParseEndpoint returns the network address of the passed ZMQ endpoint, which
+// must use the tcp transport such as tcp://127.0.0.1:28332.  The wildcard
+// host * listens on all interfaces.
+func ParseEndpoint(endpoint string) (string, error) {
+	const prefix = "tcp://"
+	if !strings.HasPrefix(endpoint, prefix) {
+		return "", fmt.Errorf("zmq endpoint %q does not use the tcp "+
+			"transport", endpoint)
+	}
+	host, port, err := net.SplitHostPort(endpoint[len(prefix):])
+	if err != nil {
+		return "", fmt.Errorf("invalid zmq endpoint %q: %v", endpoint,
+			err)
+	}
+	if host == "*" {
+		host = ""
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// subscriber is a connection of a subscriber to a publisher along with the
+// topic prefixes it subscribed to and the messages queued for it.
+type subscriber struct {
+	conn   net.Conn
+	topics [][]byte
+
+	mtx    sync.Mutex
+	queue  [][]byte
+	signal chan struct{}
+}
+
+// subscribed returns whether or not the subscriber subscribed to a prefix of
+// the passed topic.  It must be called with the publisher lock held.
+func (s *subscriber) subscribed(topic []byte) bool {
+	for _, prefix := range s.topics {
+		if bytes.HasPrefix(topic, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Publisher is a ZMQ PUB socket which subscribers connect to over the ZMTP 3
+// protocol with the NULL security mechanism.  Each message is published as
+// three frames, the topic, the body and the little-endian sequence number of
+// the message within its topic, which matches the notifications of Bitcoin
+// Core.
+//
+// Like a ZMQ PUB socket, the messages queued for a subscriber are limited by
+// the high-water mark and further messages are dropped for that subscriber
+// until it catches up.
+type Publisher struct {
+	listener      net.Listener
+	highWaterMark int
+	wg            sync.WaitGroup
+
+	mtx         sync.Mutex
+	subscribers map[*subscriber]struct{}
+	sequences   map[string]uint32
+	closed      bool
+}
+
+// NewPublisher returns a publisher listening on the passed ZMQ endpoint.  A
+// high-water mark of zero does not limit the messages queued for subscribers.
+func NewPublisher(endpoint string, highWaterMark int) (*Publisher, error) {
+	addr, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	p := &Publisher{
+		listener:      listener,
+		highWaterMark: highWaterMark,
+		subscribers:   make(map[*subscriber]struct{}),
+		sequences:     make(map[string]uint32),
+	}
+	p.wg.Add(1)
+	go p.acceptHandler()
+	return p, nil
+}
+
+// Addr returns the address the publisher is listening on.
+func (p *Publisher) Addr() net.Addr {
+	return p.listener.Addr()
+}
+
+// acceptHandler accepts the connections of subscribers until the publisher is
+// closed.
+//
+// This function MUST be run as a goroutine.
+func (p *Publisher) acceptHandler() {
+	defer p.wg.Done()
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			p.mtx.Lock()
+			closed := p.closed
+			p.mtx.Unlock()
+			if !closed {
+				log.Errorf("Can't accept zmq subscriber: %v", err)
+			}
+			return
+		}
+		p.wg.Add(1)
+		go p.serve(conn)
+	}
+}
+
+// serve performs the handshake with a newly connected subscriber, then sends
+// it the messages queued for it while its subscriptions are processed.
+//
+// This function MUST be run as a goroutine.
+func (p *Publisher) serve(conn net.Conn) {
+	defer p.wg.Done()
+	defer conn.Close()
+
+	// Track the subscriber right away so closing the publisher interrupts
+	// the handshake.  It does not receive any messages before subscribing.
+	s := &subscriber{conn: conn, signal: make(chan struct{}, 1)}
+	p.mtx.Lock()
+	if p.closed {
+		p.mtx.Unlock()
+		return
+	}
+	p.subscribers[s] = struct{}{}
+	p.mtx.Unlock()
+	defer func() {
+		p.mtx.Lock()
+		delete(p.subscribers, s)
+		p.mtx.Unlock()
+	}()
+
+	r := bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	socketType, err := handshake(conn, r, socketTypePub)
+	if err != nil {
+		log.Debugf("zmq handshake with %s failed: %v", conn.RemoteAddr(),
+			err)
+		return
+	}
+	if socketType != socketTypeSub && socketType != "XSUB" {
+		log.Debugf("Rejecting zmq %s socket %s", socketType,
+			conn.RemoteAddr())
+		return
+	}
+	conn.SetDeadline(time.Time{})
+	log.Debugf("New zmq subscriber %s", conn.RemoteAddr())
+
+	// Process the subscriptions while sending the queued messages, and
+	// stop sending once the subscriber disconnects.
+	done := make(chan struct{})
+	go func() {
+		p.readSubscriptions(s, r)
+		close(done)
+	}()
+	p.writeMessages(s, done)
+	conn.Close()
+	<-done
+	log.Debugf("zmq subscriber %s disconnected", conn.RemoteAddr())
+}
+
+// readSubscriptions processes the subscriptions of the passed subscriber until
+// it disconnects.  Subscriptions are accepted both as the messages of ZMTP 3.0
+// and the SUBSCRIBE and CANCEL commands of ZMTP 3.1.
+func (p *Publisher) readSubscriptions(s *subscriber, r *bufio.Reader) {
+	for {
+		f, err := readFrame(r, maxIncomingFrameSize)
+		if err != nil {
+			return
+		}
+
+		var subscribe bool
+		var topic []byte
+		switch {
+		case f.flags&flagCommand != 0:
+			name, data, err := f.command()
+			if err != nil {
+				return
+			}
+			switch name {
+			case "SUBSCRIBE":
+				subscribe, topic = true, data
+			case "CANCEL":
+				topic = data
+			case "PING":
+				// Reply with the context of the ping, which
+				// follows its time to live.
+				if len(data) < 2 {
+					return
+				}
+				var pong bytes.Buffer
+				writeFrame(&pong, flagCommand, append(
+					[]byte("\x04PONG"), data[2:]...))
+				s.enqueue(pong.Bytes(), 0)
+				continue
+			default:
+				continue
+			}
+
+		case len(f.body) > 0:
+			subscribe, topic = f.body[0] == 1, f.body[1:]
+
+		default:
+			continue
+		}
+
+		p.mtx.Lock()
+		if subscribe {
+			s.topics = append(s.topics, topic)
+		} else {
+			for i, prefix := range s.topics {
+				if bytes.Equal(prefix, topic) {
+					s.topics = append(s.topics[:i],
+						s.topics[i+1:]...)
+					break
+				}
+			}
+		}
+		p.mtx.Unlock()
+	}
+}
+
+// enqueue queues the passed encoded message to be sent to the subscriber
+// unless the passed high-water mark has been reached, which does not limit the
+// queue when it is zero.
+func (s *subscriber) enqueue(msg []byte, highWaterMark int) {
+	s.mtx.Lock()
+	if highWaterMark > 0 && len(s.queue) >= highWaterMark {
+		s.mtx.Unlock()
+		return
+	}
+	s.queue = append(s.queue, msg)
+	s.mtx.Unlock()
+
+	select {
+	case s.signal <- struct{}{}:
+	default:
+	}
+}
+
+// writeMessages sends the messages queued for the passed subscriber until
+// sending fails or the passed channel is closed.
+func (p *Publisher) writeMessages(s *subscriber, done <-chan struct{}) {
+	w := bufio.NewWriter(s.conn)
+	for {
+		select {
+		case <-s.signal:
+		case <-done:
+			return
+		}
+
+		s.mtx.Lock()
+		queue := s.queue
+		s.queue = nil
+		s.mtx.Unlock()
+		for _, msg := range queue {
+			if _, err := w.Write(msg); err != nil {
+				return
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// Publish publishes the passed body to the subscribers of the passed topic
+// along with the next sequence number of the topic.  Sequence numbers start at
+// zero and wrap around.
+func (p *Publisher) Publish(topic string, body []byte) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.closed {
+		return ErrPublisherClosed
+	}
+	sequence := p.sequences[topic]
+	p.sequences[topic] = sequence + 1
+
+	var seq [4]byte
+	binary.LittleEndian.PutUint32(seq[:], sequence)
+	var msg bytes.Buffer
+	writeFrame(&msg, flagMore, []byte(topic))
+	writeFrame(&msg, flagMore, body)
+	writeFrame(&msg, 0, seq[:])
+	for s := range p.subscribers {
+		if s.subscribed([]byte(topic)) {
+			s.enqueue(msg.Bytes(), p.highWaterMark)
+		}
+	}
+	return nil
+}
+
+// NumSubscribers returns the number of connected subscribers which have
+// subscribed to at least one topic.
+func (p *Publisher) NumSubscribers() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	var n int
+	for s := range p.subscribers {
+		if len(s.topics) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// Close stops listening, disconnects all subscribers and waits for their
+// connections to be closed.
+func (p *Publisher) Close() error {
+	p.mtx.Lock()
+	if p.closed {
+		p.mtx.Unlock()
+		return nil
+	}
+	p.closed = true
+	err := p.listener.Close()
+	for s := range p.subscribers {
+		s.conn.Close()
+	}
+	p.mtx.Unlock()
+
+	p.wg.Wait()
+	return err
+}
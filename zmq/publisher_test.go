@@ -0,0 +1,149 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zmq
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// waitForSubscribers waits until the passed publisher has the passed number of
+// subscribers.
+func waitForSubscribers(t *testing.T, p *Publisher, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for p.NumSubscribers() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d subscribers, want %d", p.NumSubscribers(),
+				n)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestPublisher ensures subscribers receive the messages of the topics they
+// subscribed to along with the sequence numbers of the topics.
+func TestPublisher(t *testing.T) {
+	p, err := NewPublisher("tcp://127.0.0.1:0", DefaultHighWaterMark)
+	if err != nil {
+		t.Fatalf("NewPublisher: unexpected error: %v", err)
+	}
+	defer p.Close()
+
+	s, err := Subscribe("tcp://"+p.Addr().String(), "raw")
+	if err != nil {
+		t.Fatalf("Subscribe: unexpected error: %v", err)
+	}
+	defer s.Close()
+	waitForSubscribers(t, p, 1)
+
+	// Publish a body large enough to require the long frame encoding
+	// along with messages of a topic which is not subscribed to.
+	large := bytes.Repeat([]byte{0x42}, 1000)
+	published := []struct {
+		topic string
+		body  []byte
+	}{
+		{"rawtx", []byte("tx1")},
+		{"hashtx", []byte("hash1")},
+		{"rawblock", large},
+		{"rawtx", []byte("tx2")},
+	}
+	for _, msg := range published {
+		if err := p.Publish(msg.topic, msg.body); err != nil {
+			t.Fatalf("Publish: unexpected error: %v", err)
+		}
+	}
+
+	want := []struct {
+		topic    string
+		body     []byte
+		sequence uint32
+	}{
+		{"rawtx", []byte("tx1"), 0},
+		{"rawblock", large, 0},
+		{"rawtx", []byte("tx2"), 1},
+	}
+	s.SetDeadline(time.Now().Add(5 * time.Second))
+	for i, msg := range want {
+		topic, body, sequence, err := s.Receive()
+		if err != nil {
+			t.Fatalf("Receive #%d: unexpected error: %v", i, err)
+		}
+		if topic != msg.topic || !bytes.Equal(body, msg.body) ||
+			sequence != msg.sequence {
+
+			t.Fatalf("Receive #%d: got %s message %x with sequence "+
+				"%d, want %s message %x with sequence %d", i,
+				topic, body, sequence, msg.topic, msg.body,
+				msg.sequence)
+		}
+	}
+
+	// Disconnected subscribers are forgotten and nothing can be published
+	// once the publisher is closed.
+	s.Close()
+	waitForSubscribers(t, p, 0)
+	p.Close()
+	if err := p.Publish("rawtx", nil); err != ErrPublisherClosed {
+		t.Fatalf("Publish: got error %v, want %v", err,
+			ErrPublisherClosed)
+	}
+}
+
+// TestHighWaterMark ensures the messages queued for a subscriber are limited by
+// the high-water mark unless it is zero.
+func TestHighWaterMark(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		highWaterMark int
+		want          int
+	}{
+		{highWaterMark: 2, want: 2},
+		{highWaterMark: 0, want: 5},
+	}
+	for _, test := range tests {
+		s := &subscriber{signal: make(chan struct{}, 1)}
+		for i := 0; i < 5; i++ {
+			s.enqueue([]byte{byte(i)}, test.highWaterMark)
+		}
+		if len(s.queue) != test.want {
+			t.Errorf("high-water mark %d: got %d queued messages, "+
+				"want %d", test.highWaterMark, len(s.queue),
+				test.want)
+		}
+	}
+}
+
+// TestParseEndpoint ensures ZMQ endpoints are converted to network addresses
+// and only the tcp transport is accepted.
+func TestParseEndpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		endpoint string
+		want     string
+		valid    bool
+	}{
+		{"tcp://127.0.0.1:28332", "127.0.0.1:28332", true},
+		{"tcp://*:28332", ":28332", true},
+		{"tcp://[::1]:28332", "[::1]:28332", true},
+		{"ipc:///tmp/ltcd", "", false},
+		{"127.0.0.1:28332", "", false},
+		{"tcp://127.0.0.1", "", false},
+	}
+	for _, test := range tests {
+		got, err := ParseEndpoint(test.endpoint)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: unexpected error: %v", test.endpoint, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%s: got %s, want %s", test.endpoint, got,
+				test.want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zmq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// maxMessageFrameSize is the maximum size of the frames accepted from a
+// publisher, which is large enough for any block.
+const maxMessageFrameSize = 32 * 1024 * 1024
+
+// Subscriber is a ZMQ SUB socket connected to a single publisher, such as the
+// notifications of a node.
+type Subscriber struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Subscribe connects to the publisher at the passed ZMQ endpoint and subscribes
+// to the topics starting with any of the passed prefixes.
+func Subscribe(endpoint string, topics ...string) (*Subscriber, error) {
+	addr, err := ParseEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.DialTimeout("tcp", addr, handshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	socketType, err := handshake(conn, r, socketTypeSub)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if socketType != socketTypePub && socketType != "XPUB" {
+		conn.Close()
+		return nil, fmt.Errorf("unexpected zmq %s socket", socketType)
+	}
+
+	// Subscribe with the messages of ZMTP 3.0, which consist of a one
+	// followed by the topic prefix.
+	var buf bytes.Buffer
+	for _, topic := range topics {
+		writeFrame(&buf, 0, append([]byte{1}, topic...))
+	}
+	if _, err := conn.Write(buf.Bytes()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	conn.SetDeadline(time.Time{})
+
+	return &Subscriber{conn: conn, r: r}, nil
+}
+
+// Receive waits for and returns the topic, body and sequence number of the
+// next message published to the subscriber.
+func (s *Subscriber) Receive() (string, []byte, uint32, error) {
+	var parts [][]byte
+	for {
+		f, err := readFrame(s.r, maxMessageFrameSize)
+		if err != nil {
+			return "", nil, 0, err
+		}
+		if f.flags&flagCommand != 0 {
+			continue
+		}
+		parts = append(parts, f.body)
+		if f.flags&flagMore == 0 {
+			break
+		}
+	}
+	if len(parts) != 3 || len(parts[2]) != 4 {
+		return "", nil, 0, errors.New("malformed zmq notification")
+	}
+	return string(parts[0]), parts[1], binary.LittleEndian.Uint32(parts[2]),
+		nil
+}
+
+// SetDeadline sets the deadline for receiving messages.  A zero value disables
+// the deadline.
+func (s *Subscriber) SetDeadline(t time.Time) error {
+	return s.conn.SetDeadline(t)
+}
+
+// Close disconnects from the publisher.
+func (s *Subscriber) Close() error {
+	return s.conn.Close()
+}
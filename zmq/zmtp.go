@@ -0,0 +1,199 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package zmq
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// greetingSize is the size of the greeting each side of a ZMTP
+	// connection sends first.
+	greetingSize = 64
+
+	// maxIncomingFrameSize is the maximum size of the frames accepted from
+	// the other side of a connection, which only sends the handshake and
+	// subscriptions to a publisher.
+	maxIncomingFrameSize = 1 << 16
+
+	// Flags of the frames.
+	flagMore    = 0x01
+	flagLong    = 0x02
+	flagCommand = 0x04
+)
+
+// Socket types exchanged in the handshake.
+const (
+	socketTypePub = "PUB"
+	socketTypeSub = "SUB"
+)
+
+var (
+	// errBadGreeting is returned when the other side of a connection does
+	// not send a ZMTP 3 greeting with the NULL security mechanism.
+	errBadGreeting = errors.New("invalid ZMTP greeting")
+)
+
+// greeting returns the ZMTP 3.0 greeting using the NULL security mechanism.
+func greeting() []byte {
+	g := make([]byte, greetingSize)
+	g[0] = 0xff
+	g[9] = 0x7f
+	g[10] = 3
+	g[11] = 0
+	copy(g[12:32], "NULL")
+	return g
+}
+
+// frame is a single frame of a ZMTP connection.
+type frame struct {
+	flags byte
+	body  []byte
+}
+
+// writeFrame writes a frame with the passed flags and body, using the long
+// size encoding when needed.
+func writeFrame(w io.Writer, flags byte, body []byte) error {
+	var header [9]byte
+	n := 2
+	if len(body) > 255 {
+		header[0] = flags | flagLong
+		binary.BigEndian.PutUint64(header[1:], uint64(len(body)))
+		n = 9
+	} else {
+		header[0] = flags
+		header[1] = byte(len(body))
+	}
+	if _, err := w.Write(header[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(body)
+	return err
+}
+
+// readFrame reads the next frame, which must not be larger than the passed
+// maximum size.
+func readFrame(r *bufio.Reader, maxSize uint64) (*frame, error) {
+	flags, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	var size uint64
+	if flags&flagLong != 0 {
+		var b [8]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		size = binary.BigEndian.Uint64(b[:])
+	} else {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		size = uint64(b)
+	}
+	if size > maxSize {
+		return nil, fmt.Errorf("ZMTP frame of %d bytes exceeds the "+
+			"maximum of %d bytes", size, maxSize)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return &frame{flags: flags &^ flagLong, body: body}, nil
+}
+
+// command returns the name and the data of a command frame.
+func (f *frame) command() (string, []byte, error) {
+	if len(f.body) == 0 || int(f.body[0]) > len(f.body)-1 {
+		return "", nil, errors.New("malformed ZMTP command")
+	}
+	nameLen := int(f.body[0])
+	return string(f.body[1 : 1+nameLen]), f.body[1+nameLen:], nil
+}
+
+// readyCommand returns the body of a READY command announcing the passed
+// socket type.
+func readyCommand(socketType string) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(5)
+	buf.WriteString("READY")
+	buf.WriteByte(byte(len("Socket-Type")))
+	buf.WriteString("Socket-Type")
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(socketType)))
+	buf.Write(size[:])
+	buf.WriteString(socketType)
+	return buf.Bytes()
+}
+
+// parseReady returns the socket type announced by the passed READY command
+// data.
+func parseReady(data []byte) (string, error) {
+	for len(data) > 0 {
+		nameLen := int(data[0])
+		if len(data) < 1+nameLen+4 {
+			return "", errors.New("malformed ZMTP READY command")
+		}
+		name := string(data[1 : 1+nameLen])
+		data = data[1+nameLen:]
+		valueLen := binary.BigEndian.Uint32(data)
+		data = data[4:]
+		if uint64(len(data)) < uint64(valueLen) {
+			return "", errors.New("malformed ZMTP READY command")
+		}
+		value := string(data[:valueLen])
+		data = data[valueLen:]
+		if strings.EqualFold(name, "Socket-Type") {
+			return value, nil
+		}
+	}
+	return "", errors.New("ZMTP READY command without socket type")
+}
+
+// handshake performs the ZMTP greeting and handshake over the passed
+// connection announcing the passed socket type, and returns the socket type
+// of the other side.
+func handshake(w io.Writer, r *bufio.Reader, socketType string) (string, error) {
+	if _, err := w.Write(greeting()); err != nil {
+		return "", err
+	}
+	var g [greetingSize]byte
+	if _, err := io.ReadFull(r, g[:]); err != nil {
+		return "", err
+	}
+	mechanism := string(bytes.TrimRight(g[12:32], "\x00"))
+	if g[0] != 0xff || g[9]&0x01 != 0x01 || g[10] < 3 ||
+		mechanism != "NULL" {
+
+		return "", errBadGreeting
+	}
+
+	err := writeFrame(w, flagCommand, readyCommand(socketType))
+	if err != nil {
+		return "", err
+	}
+	f, err := readFrame(r, maxIncomingFrameSize)
+	if err != nil {
+		return "", err
+	}
+	if f.flags&flagCommand == 0 {
+		return "", errors.New("expected ZMTP READY command")
+	}
+	name, data, err := f.command()
+	if err != nil {
+		return "", err
+	}
+	if name != "READY" {
+		return "", fmt.Errorf("unexpected ZMTP command %q", name)
+	}
+	return parseReady(data)
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/ltcsuite/ltcd/blockchain"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/mempool"
+	"github.com/ltcsuite/ltcd/zmq"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// ZMQ notification topics, which match those of Bitcoin Core.
+const (
+	zmqTopicHashBlock = "hashblock"
+	zmqTopicHashTx    = "hashtx"
+	zmqTopicRawBlock  = "rawblock"
+	zmqTopicRawTx     = "rawtx"
+	zmqTopicSequence  = "sequence"
+)
+
+// Labels of the events published to the sequence topic.
+const (
+	zmqSequenceBlockConnected    = 'C'
+	zmqSequenceBlockDisconnected = 'D'
+	zmqSequenceTxAdded           = 'A'
+	zmqSequenceTxRemoved         = 'R'
+)
+
+// zmqTopicConfig houses the endpoint a ZMQ notification topic is published on
+// along with the high-water mark of its subscribers.
+type zmqTopicConfig struct {
+	topic         string
+	endpoint      string
+	highWaterMark int
+}
+
+// zmqTopics returns the ZMQ notification topics as configured by the passed
+// configuration.  The endpoint of the topics which are not published is empty.
+func zmqTopics(cfg *config) []zmqTopicConfig {
+	return []zmqTopicConfig{
+		{zmqTopicHashBlock, cfg.ZMQPubHashBlock, cfg.ZMQPubHashBlockHWM},
+		{zmqTopicHashTx, cfg.ZMQPubHashTx, cfg.ZMQPubHashTxHWM},
+		{zmqTopicRawBlock, cfg.ZMQPubRawBlock, cfg.ZMQPubRawBlockHWM},
+		{zmqTopicRawTx, cfg.ZMQPubRawTx, cfg.ZMQPubRawTxHWM},
+		{zmqTopicSequence, cfg.ZMQPubSequence, cfg.ZMQPubSequenceHWM},
+	}
+}
+
+// zmqNotifier publishes the blocks connected to the main chain and the
+// transactions accepted to the memory pool to ZMQ subscribers.  The
+// transactions of connected blocks are published as well, ahead of the block.
+// The sequence topic additionally publishes the disconnected blocks and the
+// transactions removed from the memory pool, so subscribers are able to keep
+// track of both without polling.
+type zmqNotifier struct {
+	publishers map[string]*zmq.Publisher
+	sockets    []*zmq.Publisher
+}
+
+// newZMQNotifier returns a notifier publishing the passed topics, or nil when
+// none of them are published.  Topics with the same endpoint share a
+// publisher, which uses the high-water mark of the first of them.
+func newZMQNotifier(topics []zmqTopicConfig) (*zmqNotifier, error) {
+	n := &zmqNotifier{publishers: make(map[string]*zmq.Publisher)}
+	byEndpoint := make(map[string]*zmq.Publisher)
+	for _, topic := range topics {
+		if topic.endpoint == "" {
+			continue
+		}
+		publisher, ok := byEndpoint[topic.endpoint]
+		if !ok {
+			var err error
+			publisher, err = zmq.NewPublisher(topic.endpoint,
+				topic.highWaterMark)
+			if err != nil {
+				n.Stop()
+				return nil, err
+			}
+			byEndpoint[topic.endpoint] = publisher
+			n.sockets = append(n.sockets, publisher)
+		}
+		n.publishers[topic.topic] = publisher
+		srvrLog.Infof("Publishing ZMQ %s notifications on %s",
+			topic.topic, topic.endpoint)
+	}
+	if len(n.publishers) == 0 {
+		return nil, nil
+	}
+	return n, nil
+}
+
+// publish publishes the passed body to the subscribers of the passed topic
+// when it is published.  The body is only created when needed.
+func (n *zmqNotifier) publish(topic string, body func() ([]byte, error)) {
+	publisher, ok := n.publishers[topic]
+	if !ok {
+		return
+	}
+	data, err := body()
+	if err != nil {
+		srvrLog.Errorf("Unable to create ZMQ %s notification: %v", topic,
+			err)
+		return
+	}
+	if err := publisher.Publish(topic, data); err != nil {
+		srvrLog.Debugf("Unable to publish ZMQ %s notification: %v",
+			topic, err)
+	}
+}
+
+// zmqHash returns the passed hash in the byte order it is displayed in, which
+// is how hashes are published.
+func zmqHash(hash *chainhash.Hash) []byte {
+	b := make([]byte, chainhash.HashSize)
+	for i := range hash {
+		b[chainhash.HashSize-1-i] = hash[i]
+	}
+	return b
+}
+
+// notifyTx publishes the hash and the serialization of the passed transaction.
+func (n *zmqNotifier) notifyTx(tx *ltcutil.Tx) {
+	n.publish(zmqTopicHashTx, func() ([]byte, error) {
+		return zmqHash(tx.Hash()), nil
+	})
+	n.publish(zmqTopicRawTx, func() ([]byte, error) {
+		var buf bytes.Buffer
+		buf.Grow(tx.MsgTx().SerializeSize())
+		err := tx.MsgTx().Serialize(&buf)
+		return buf.Bytes(), err
+	})
+}
+
+// notifySequence publishes an event with the passed label concerning the block
+// or transaction with the passed hash to the sequence topic.  The memory pool
+// sequence number is included for the events of the memory pool, which pass a
+// non-nil sequence.
+func (n *zmqNotifier) notifySequence(hash *chainhash.Hash, label byte, sequence *uint64) {
+	n.publish(zmqTopicSequence, func() ([]byte, error) {
+		body := make([]byte, chainhash.HashSize+1, chainhash.HashSize+9)
+		copy(body, zmqHash(hash))
+		body[chainhash.HashSize] = label
+		if sequence != nil {
+			var b [8]byte
+			binary.LittleEndian.PutUint64(b[:], *sequence)
+			body = append(body, b[:]...)
+		}
+		return body, nil
+	})
+}
+
+// NotifyTxAdded publishes the sequence event of the passed transaction, which
+// was added to the memory pool with the passed memory pool sequence number.
+func (n *zmqNotifier) NotifyTxAdded(tx *ltcutil.Tx, sequence uint64) {
+	n.notifySequence(tx.Hash(), zmqSequenceTxAdded, &sequence)
+}
+
+// NotifyTxRemoved publishes the sequence event of the passed transaction, which
+// was removed from the memory pool with the passed memory pool sequence number.
+// Like Bitcoin Core, the transactions included in a connected block are not
+// reported since the block connected event implies their removal, and expired
+// orphans are not reported since they were never in the memory pool.
+func (n *zmqNotifier) NotifyTxRemoved(tx *ltcutil.Tx, reason mempool.RemovalReason, sequence uint64) {
+	switch reason {
+	case mempool.RemovalReasonConfirmed, mempool.RemovalReasonOrphanTimeout:
+		return
+	}
+	n.notifySequence(tx.Hash(), zmqSequenceTxRemoved, &sequence)
+}
+
+// NotifyNewTransactions publishes the passed transactions, which were accepted
+// to the memory pool.
+func (n *zmqNotifier) NotifyNewTransactions(txns []*mempool.TxDesc) {
+	for _, txD := range txns {
+		n.notifyTx(txD.Tx)
+	}
+}
+
+// handleBlockchainNotification publishes the blocks connected to the main
+// chain along with their transactions, and the sequence events of the blocks
+// connected to and disconnected from the main chain.
+func (n *zmqNotifier) handleBlockchainNotification(notification *blockchain.Notification) {
+	switch notification.Type {
+	case blockchain.NTBlockConnected:
+		block, ok := notification.Data.(*ltcutil.Block)
+		if !ok {
+			srvrLog.Warnf("Chain connected notification is not a block.")
+			break
+		}
+
+		for _, tx := range block.Transactions() {
+			n.notifyTx(tx)
+		}
+		n.publish(zmqTopicHashBlock, func() ([]byte, error) {
+			return zmqHash(block.Hash()), nil
+		})
+		n.publish(zmqTopicRawBlock, block.Bytes)
+		n.notifySequence(block.Hash(), zmqSequenceBlockConnected, nil)
+
+	case blockchain.NTBlockDisconnected:
+		block, ok := notification.Data.(*ltcutil.Block)
+		if !ok {
+			srvrLog.Warnf("Chain disconnected notification is not a " +
+				"block.")
+			break
+		}
+
+		n.notifySequence(block.Hash(), zmqSequenceBlockDisconnected,
+			nil)
+	}
+}
+
+// Stop closes the publishers and disconnects their subscribers.
+func (n *zmqNotifier) Stop() {
+	for _, publisher := range n.sockets {
+		if err := publisher.Close(); err != nil {
+			srvrLog.Debugf("Unable to close ZMQ publisher: %v", err)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2017 The btcsuite developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/ltcsuite/ltcd/chaincfg"
+	"github.com/ltcsuite/ltcd/chaincfg/chainhash"
+	"github.com/ltcsuite/ltcd/mempool"
+	"github.com/ltcsuite/ltcd/txscript"
+	"github.com/ltcsuite/ltcd/wire"
+	"github.com/ltcsuite/ltcd/zmq"
+	"github.com/ltcsuite/ltcutil"
+)
+
+// TestZMQNotifier ensures the blocks connected to the main chain are published
+// to ZMQ subscribers along with their hashes, and that topics with the same
+// endpoint share a publisher.
+func TestZMQNotifier(t *testing.T) {
+	setLogLevel("CHAN", "off")
+	setLogLevel("SRVR", "off")
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	n, err := newZMQNotifier([]zmqTopicConfig{
+		{zmqTopicRawBlock, "tcp://127.0.0.1:0", zmq.DefaultHighWaterMark},
+		{zmqTopicHashBlock, "tcp://127.0.0.1:0", zmq.DefaultHighWaterMark},
+		{zmqTopicRawTx, "", zmq.DefaultHighWaterMark},
+	})
+	if err != nil {
+		t.Fatalf("newZMQNotifier: unexpected error: %v", err)
+	}
+	defer n.Stop()
+	if len(n.sockets) != 1 || n.publishers[zmqTopicHashBlock] !=
+		n.publishers[zmqTopicRawBlock] {
+
+		t.Fatalf("got %d publishers, want the topics to share one",
+			len(n.sockets))
+	}
+	if _, ok := n.publishers[zmqTopicRawTx]; ok {
+		t.Fatal("rawtx is published without an endpoint")
+	}
+	chain.Subscribe(n.handleBlockchainNotification)
+
+	publisher := n.publishers[zmqTopicRawBlock]
+	endpoint := "tcp://" + publisher.Addr().String()
+	s, err := zmq.Subscribe(endpoint, zmqTopicRawBlock, zmqTopicHashBlock)
+	if err != nil {
+		t.Fatalf("Subscribe: unexpected error: %v", err)
+	}
+	defer s.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for publisher.NumSubscribers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for the subscriber")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Connect a block, which is published by hash and then as a whole.
+	addRegtestBlock(t, chain, []byte{txscript.OP_TRUE})
+	best := chain.BestSnapshot()
+	block, err := chain.BlockByHash(&best.Hash)
+	if err != nil {
+		t.Fatalf("BlockByHash: unexpected error: %v", err)
+	}
+	var want bytes.Buffer
+	if err := block.MsgBlock().Serialize(&want); err != nil {
+		t.Fatalf("unable to serialize block: %v", err)
+	}
+
+	s.SetDeadline(time.Now().Add(5 * time.Second))
+	topic, body, sequence, err := s.Receive()
+	if err != nil {
+		t.Fatalf("Receive: unexpected error: %v", err)
+	}
+	if topic != zmqTopicHashBlock || sequence != 0 ||
+		hex.EncodeToString(body) != best.Hash.String() {
+
+		t.Fatalf("got %s message %x with sequence %d, want hashblock "+
+			"message %s with sequence 0", topic, body, sequence,
+			best.Hash)
+	}
+	topic, body, sequence, err = s.Receive()
+	if err != nil {
+		t.Fatalf("Receive: unexpected error: %v", err)
+	}
+	if topic != zmqTopicRawBlock || sequence != 0 ||
+		!bytes.Equal(body, want.Bytes()) {
+
+		t.Fatalf("got %s message %x with sequence %d, want rawblock "+
+			"message %x with sequence 0", topic, body, sequence,
+			want.Bytes())
+	}
+}
+
+// TestZMQSequence ensures the sequence topic publishes the events of the memory
+// pool along with the memory pool sequence and the events of the main chain.
+func TestZMQSequence(t *testing.T) {
+	setLogLevel("CHAN", "off")
+	setLogLevel("SRVR", "off")
+
+	chain, _, _, teardown := newRegtestChain(t, 0)
+	defer teardown()
+
+	// Create enough blocks for the first coinbase to mature.
+	params := &chaincfg.RegressionNetParams
+	pkScript := []byte{txscript.OP_TRUE}
+	var coinbases []*wire.MsgTx
+	for i := 0; i <= int(params.CoinbaseMaturity); i++ {
+		coinbases = append(coinbases, addRegtestBlock(t, chain, pkScript))
+	}
+
+	n, err := newZMQNotifier([]zmqTopicConfig{
+		{zmqTopicSequence, "tcp://127.0.0.1:0", zmq.DefaultHighWaterMark},
+	})
+	if err != nil {
+		t.Fatalf("newZMQNotifier: unexpected error: %v", err)
+	}
+	defer n.Stop()
+	chain.Subscribe(n.handleBlockchainNotification)
+	mempoolCfg := newRegtestMempoolConfig(chain)
+	mempoolCfg.OnTxAdded = n.NotifyTxAdded
+	mempoolCfg.OnTxRemoved = n.NotifyTxRemoved
+	txMemPool := mempool.New(mempoolCfg)
+
+	publisher := n.publishers[zmqTopicSequence]
+	s, err := zmq.Subscribe("tcp://"+publisher.Addr().String(),
+		zmqTopicSequence)
+	if err != nil {
+		t.Fatalf("Subscribe: unexpected error: %v", err)
+	}
+	defer s.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for publisher.NumSubscribers() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for the subscriber")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// expectEvent receives the next message and ensures it is the sequence
+	// event with the passed hash, label and memory pool sequence, which is
+	// only present for the events of the memory pool.
+	expectEvent := func(hash *chainhash.Hash, label byte, poolSequence *uint64, sequence uint32) {
+		topic, body, gotSequence, err := s.Receive()
+		if err != nil {
+			t.Fatalf("Receive: unexpected error: %v", err)
+		}
+		want := append(zmqHash(hash), label)
+		if poolSequence != nil {
+			var b [8]byte
+			binary.LittleEndian.PutUint64(b[:], *poolSequence)
+			want = append(want, b[:]...)
+		}
+		if topic != zmqTopicSequence || gotSequence != sequence ||
+			!bytes.Equal(body, want) {
+
+			t.Fatalf("got %s message %x with sequence %d, want "+
+				"sequence message %x with sequence %d", topic,
+				body, gotSequence, want, sequence)
+		}
+	}
+
+	// Adding a transaction to the pool publishes an event with its hash
+	// and the memory pool sequence before the addition.
+	prevHash := coinbases[0].TxHash()
+	msgTx := wire.NewMsgTx(1)
+	msgTx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(&prevHash, 0), nil, nil))
+	msgTx.AddTxOut(wire.NewTxOut(coinbases[0].TxOut[0].Value-10000,
+		pkScript))
+	tx := ltcutil.NewTx(msgTx)
+	poolSequence := txMemPool.TransactionsUpdated()
+	_, err = txMemPool.ProcessTransaction(tx, false, false, 0)
+	if err != nil {
+		t.Fatalf("unable to add transaction to the pool: %v", err)
+	}
+	expectEvent(tx.Hash(), 'A', &poolSequence, 0)
+
+	// Removing it publishes an event with the next memory pool sequence.
+	txMemPool.RemoveTransaction(tx, true, mempool.RemovalReasonInvalid)
+	poolSequence++
+	expectEvent(tx.Hash(), 'R', &poolSequence, 1)
+
+	// Connecting a block publishes an event without a memory pool
+	// sequence.
+	addRegtestBlock(t, chain, pkScript)
+	expectEvent(&chain.BestSnapshot().Hash, 'C', nil, 2)
+}